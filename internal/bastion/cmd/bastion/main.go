@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net"
 	"os"
@@ -9,12 +10,24 @@ import (
 	"syscall"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auditlog"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/chainstore"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/dnsfilter"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/flowlog"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/ha"
 	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/metrics"
 	"github.com/metorial/fleet/holopod/internal/bastion/pkg/networkpool"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/ratelimit"
 	"github.com/metorial/fleet/holopod/internal/bastion/pkg/service"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/tlsconfig"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/unixsock"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
 	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
 )
 
@@ -42,6 +55,26 @@ func main() {
 	}
 
 	logger.Info("bastion service starting", "version", version)
+
+	// A standby waits here, before touching the firewall or any state, until
+	// the active bastion exits
+	if lockFile := ha.LockFileFromEnv(); lockFile != "" {
+		waitCtx, stopWaiting := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		lease, err := ha.Acquire(waitCtx, lockFile, func(holder string) {
+			logger.Info("standing by while another bastion is active", "lock_file", lockFile, "holder", holder)
+		})
+		stopWaiting()
+		if errors.Is(err, context.Canceled) {
+			logger.Info("stopped while standing by")
+			return
+		}
+		if err != nil {
+			logger.Error("failed to become the active bastion", "lock_file", lockFile, "error", err)
+			os.Exit(1)
+		}
+		defer lease.Release()
+		logger.Info("active bastion", "lock_file", lockFile)
+	}
 	logger.Info("service runs with elevated privileges to manage iptables")
 
 	if err := iptables.CheckIPTables(ctx); err != nil {
@@ -49,6 +82,14 @@ func main() {
 		logger.Error("ensure iptables is installed and accessible")
 		os.Exit(1)
 	}
+	logger.Info("firewall backend selected", "backend", iptables.Backend())
+
+	ipv6Prefixes, err := validation.ContainerIPv6PrefixesFromEnv()
+	if err != nil {
+		logger.Error("invalid container IPv6 prefixes", "error", err)
+		os.Exit(1)
+	}
+	validation.SetContainerIPv6Prefixes(ipv6Prefixes)
 
 	logger.Info("initializing network pool")
 	stateFile := os.Getenv("BASTION_STATE_FILE")
@@ -65,34 +106,185 @@ func main() {
 	pool.StartCleanup(ctx)
 	logger.Info("network pool initialized and cleanup task started")
 
+	// Flow logging is optional; without a readable kernel log StreamFlowLogs reports Unavailable
+	flowLogs := flowlog.NewCollector(os.Getenv("BASTION_FLOW_LOG_SOURCE"), logger)
+	if err := flowLogs.Start(ctx); err != nil {
+		logger.Warn("flow logging disabled", "error", err)
+		flowLogs = nil
+	} else {
+		logger.Info("flow log collector started")
+	}
+
+	// DNS domain filtering is optional; policies with a domain allowlist are rejected without it
+	dnsFilter, err := dnsfilter.NewServerFromEnv(logger)
+	if err != nil {
+		logger.Error("invalid DNS filter configuration", "error", err)
+		os.Exit(1)
+	}
+	if dnsFilter != nil {
+		if err := dnsFilter.Start(ctx); err != nil {
+			logger.Error("failed to start DNS filter", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("DNS filter started", "address", dnsFilter.IP())
+	}
+
 	listenAddr := os.Getenv("LISTEN_ADDRESS")
 	if listenAddr == "" {
 		listenAddr = "0.0.0.0:50054"
 	}
 
-	lis, err := net.Listen("tcp", listenAddr)
+	// Co-located runners can use a unix socket instead; with LISTEN_ADDRESS=off
+	// it is the only way in
+	socketConfig, err := unixsock.FromEnv()
+	if err != nil {
+		logger.Error("invalid unix socket configuration", "error", err)
+		os.Exit(1)
+	}
+	if listenAddr == "off" && !socketConfig.Enabled() {
+		logger.Error("LISTEN_ADDRESS is off and " + unixsock.PathEnv + " is unset; nothing to listen on")
+		os.Exit(1)
+	}
+
+	var lis net.Listener
+	if listenAddr != "off" {
+		lis, err = net.Listen("tcp", listenAddr)
+		if err != nil {
+			logger.Error("failed to listen", "address", listenAddr, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	var socketLis net.Listener
+	if socketConfig.Enabled() {
+		socketLis, err = unixsock.Listen(socketConfig, logger)
+		if err != nil {
+			logger.Error("failed to listen on unix socket", "path", socketConfig.Path, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Metrics are optional; without BASTION_METRICS_ADDRESS nothing is served
+	if err := metrics.RegisterPool(pool); err != nil {
+		logger.Error("failed to register network pool metrics", "error", err)
+		os.Exit(1)
+	}
+	metricsAddr, err := metrics.StartServerFromEnv(ctx)
+	if err != nil {
+		logger.Error("failed to start metrics endpoint", "error", err)
+		os.Exit(1)
+	}
+	if metricsAddr != "" {
+		logger.Info("metrics endpoint started", "address", metricsAddr, "path", "/metrics")
+	}
+
+	authenticator := auth.FromEnv()
+	if !authenticator.Enabled() {
+		logger.Warn("callers are not authenticated; set " + auth.TokenEnv + " and " + auth.SecretEnv +
+			" to limit each runner to its own chains")
+	}
+
+	limiter := ratelimit.FromEnv()
+	if limiter.Enabled() {
+		logger.Info("per-client rate limit enabled")
+	}
+
+	// Metrics come first so rejected calls are timed too, and the rate limit
+	// after authentication so clients are told apart by run ID
+	interceptors := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor, authenticator.UnaryServerInterceptor, limiter.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor, authenticator.StreamServerInterceptor, limiter.StreamServerInterceptor),
+	}
+
+	serverOpts := append([]grpc.ServerOption(nil), interceptors...)
+
+	tlsSettings := tlsconfig.FromEnv()
+	tlsConfig, err := tlsSettings.Server()
 	if err != nil {
-		logger.Error("failed to listen", "address", listenAddr, "error", err)
+		logger.Error("invalid TLS configuration", "error", err)
 		os.Exit(1)
 	}
+	if tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		logger.Info("gRPC listener uses TLS", "client_certificates_required", tlsSettings.MutualTLS())
+	} else if lis != nil {
+		logger.Warn("gRPC listener is plaintext and unauthenticated; set " +
+			tlsconfig.CertFileEnv + ", " + tlsconfig.KeyFileEnv + " and " + tlsconfig.ClientCAFileEnv + " to require mTLS")
+	}
 
-	grpcServer := grpc.NewServer()
+	// The socket is guarded by its permissions and peer credentials in place of TLS
+	grpcServer := grpc.NewServer(serverOpts...)
+	socketServer := grpc.NewServer(interceptors...)
 	healthServer := health.NewServer()
 	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
-	bastionService := service.New(version, pool, logger)
-	pb.RegisterBastionServiceServer(grpcServer, bastionService)
+	grpc_health_v1.RegisterHealthServer(socketServer, healthServer)
+	bastionService := service.New(version, pool, flowLogs, dnsFilter, logger)
 
-	logger.Info("starting gRPC bastion service", "address", listenAddr)
-	logger.Info("security: all operations are validated and audit logged")
-	logger.Info("network pool: automatic cleanup every 5 minutes, TTL 1 hour")
+	chainState := chainstore.PathFromEnv()
+	chains, err := chainstore.Open(chainState)
+	if err != nil {
+		logger.Error("failed to load chain records", "path", chainState, "error", err)
+		os.Exit(1)
+	}
+	bastionService.RestoreChains(chains)
+	logger.Info("chain records loaded", "path", chainState, "chains", len(chains.All()))
 
-	go func() {
-		if err := grpcServer.Serve(lis); err != nil {
-			logger.Error("gRPC server failed", "error", err)
+	if auditPath := auditlog.PathFromEnv(); auditPath != "" {
+		maxBytes, maxFiles := auditlog.RotationFromEnv()
+		auditLog, err := auditlog.Open(auditPath, maxBytes, maxFiles)
+		if err != nil {
+			logger.Error("failed to open audit log", "path", auditPath, "error", err)
 			os.Exit(1)
 		}
-	}()
+		defer auditLog.Close()
+		bastionService.SetAuditLog(auditLog)
+		logger.Info("audit log opened", "path", auditPath, "max_bytes", maxBytes, "max_files", maxFiles)
+	} else {
+		logger.Info("audit log file disabled")
+	}
+
+	pb.RegisterBastionServiceServer(grpcServer, bastionService)
+	pb.RegisterBastionServiceServer(socketServer, bastionService)
+
+	// Rebuild chains whose rules were flushed or edited behind the bastion's back
+	if interval := service.ReconcileIntervalFromEnv(); interval > 0 {
+		bastionService.StartReconciler(ctx, interval)
+		logger.Info("chain reconciliation started", "interval", interval)
+	} else {
+		logger.Info("chain reconciliation disabled")
+	}
+
+	// Remove chains left behind by containers that are gone, across restarts too
+	if interval := service.ChainGCIntervalFromEnv(); interval > 0 {
+		bastionService.StartChainGC(ctx, interval)
+		logger.Info("orphaned chain collection started", "interval", interval)
+	} else {
+		logger.Info("orphaned chain collection disabled")
+	}
+
+	logger.Info("security: all operations are validated and audit logged")
+	ttlConfig := pool.TTLConfig()
+	logger.Info("network pool: automatic cleanup", "interval", ttlConfig.CleanupInterval, "ttl", ttlConfig.TTL, "max_ttl", ttlConfig.MaxTTL)
+
+	if lis != nil {
+		logger.Info("starting gRPC bastion service", "address", listenAddr)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.Error("gRPC server failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+	if socketLis != nil {
+		logger.Info("starting gRPC bastion service on unix socket", "path", socketConfig.Path)
+		go func() {
+			if err := socketServer.Serve(socketLis); err != nil {
+				logger.Error("gRPC unix socket server failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -100,6 +292,7 @@ func main() {
 
 	logger.Info("shutting down gracefully")
 	grpcServer.GracefulStop()
+	socketServer.GracefulStop()
 	pool.Stop()
 	logger.Info("shutdown complete")
 }
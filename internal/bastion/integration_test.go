@@ -19,7 +19,7 @@ func TestIntegrationHealthCheck(t *testing.T) {
 	}
 
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
-	server := service.New("1.0.0-test", nil, logger)
+	server := service.New("1.0.0-test", nil, nil, nil, logger)
 
 	ctx := context.Background()
 	resp, err := server.Health(ctx, &pb.HealthRequest{})
@@ -56,7 +56,7 @@ func TestIntegrationNetworkPool(t *testing.T) {
 	defer pool.Stop()
 
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
-	server := service.New("1.0.0-test", pool, logger)
+	server := service.New("1.0.0-test", pool, nil, nil, logger)
 
 	_, err = server.GetNetworkStats(ctx, &pb.NetworkStatsRequest{})
 	if err != nil {
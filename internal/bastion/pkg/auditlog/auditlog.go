@@ -0,0 +1,255 @@
+// Package auditlog keeps the bastion's audit entries in an append-only JSONL
+// file, rotated by size, so privileged operations can be traced after the
+// fact. Entries are also logged as before; the file is what outlives the
+// process's stdout.
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPath     = "/var/lib/bastion/audit.jsonl"
+	DefaultMaxBytes = 100 << 20
+	DefaultMaxFiles = 5
+
+	logDirPermissions  = 0700
+	logFilePermissions = 0600
+)
+
+// PathFromEnv reads BASTION_AUDIT_LOG_FILE, falling back to DefaultPath. "off"
+// keeps entries out of a file.
+func PathFromEnv() string {
+	if path := os.Getenv("BASTION_AUDIT_LOG_FILE"); path != "" {
+		if path == "off" {
+			return ""
+		}
+		return path
+	}
+	return DefaultPath
+}
+
+// RotationFromEnv reads BASTION_AUDIT_LOG_MAX_BYTES, the size past which the
+// file is rotated, and BASTION_AUDIT_LOG_MAX_FILES, how many rotated files are
+// kept besides the current one
+func RotationFromEnv() (maxBytes int64, maxFiles int) {
+	maxBytes, maxFiles = DefaultMaxBytes, DefaultMaxFiles
+	if v, err := strconv.ParseInt(os.Getenv("BASTION_AUDIT_LOG_MAX_BYTES"), 10, 64); err == nil && v > 0 {
+		maxBytes = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("BASTION_AUDIT_LOG_MAX_FILES")); err == nil && v >= 0 {
+		maxFiles = v
+	}
+	return maxBytes, maxFiles
+}
+
+// Entry is one audited operation
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Operation   string    `json:"operation"`
+	ChainName   string    `json:"chain_name,omitempty"`
+	ContainerID string    `json:"container_id,omitempty"`
+	RunID       string    `json:"run_id,omitempty"`
+	Principal   string    `json:"principal,omitempty"`
+	Success     bool      `json:"success"`
+}
+
+// Filter selects entries; zero fields match everything. Since is inclusive,
+// Until exclusive.
+type Filter struct {
+	ContainerID string
+	ChainName   string
+	Operation   string
+	Since       time.Time
+	Until       time.Time
+	// Limit keeps only the most recent matches when above zero
+	Limit int
+}
+
+func (f Filter) match(e Entry) bool {
+	return (f.ContainerID == "" || e.ContainerID == f.ContainerID) &&
+		(f.ChainName == "" || e.ChainName == f.ChainName) &&
+		(f.Operation == "" || e.Operation == f.Operation) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until))
+}
+
+// Log appends entries to path, moving it to path.1, path.1 to path.2 and so
+// on once it grows past maxBytes. Only maxFiles rotated files are kept.
+type Log struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens the log at path, which need not exist yet
+func Open(path string, maxBytes int64, maxFiles int) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), logDirPermissions); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	l := &Log{path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) openFile() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, logFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	l.file, l.size = file, info.Size()
+	return nil
+}
+
+// Append writes e as one line, rotating first if the file is full
+func (l *Log) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts the rotated files up by one, dropping the oldest, and starts
+// a new file. If the files cannot be moved, appends carry on in the current one.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	l.file = nil
+
+	shiftErr := l.shift()
+	if err := l.openFile(); err != nil {
+		return err
+	}
+	if shiftErr != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", shiftErr)
+	}
+	return nil
+}
+
+func (l *Log) shift() error {
+	if l.maxFiles == 0 {
+		return os.Remove(l.path)
+	}
+
+	_ = os.Remove(l.rotated(l.maxFiles))
+	for i := l.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(l.rotated(i), l.rotated(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(l.path, l.rotated(1))
+}
+
+func (l *Log) rotated(i int) string {
+	return l.path + "." + strconv.Itoa(i)
+}
+
+// Query returns the entries matching f, oldest first, reading the rotated
+// files before the current one. Lines that do not parse, as one cut short by
+// a crash, are skipped. Appends go on meanwhile, so a rotation during the read
+// may leave a file's entries out.
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	var matches []Entry
+	for i := l.maxFiles; i >= 0; i-- {
+		path := l.path
+		if i > 0 {
+			path = l.rotated(i)
+		}
+
+		err := readEntries(path, func(e Entry) {
+			if !f.match(e) {
+				return
+			}
+			matches = append(matches, e)
+			// Only the most recent are wanted, so older ones can go as we read
+			if f.Limit > 0 && len(matches) > 2*f.Limit {
+				matches = append(matches[:0], matches[len(matches)-f.Limit:]...)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Limit > 0 && len(matches) > f.Limit {
+		matches = matches[len(matches)-f.Limit:]
+	}
+	return matches, nil
+}
+
+func readEntries(path string, fn func(Entry)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			fn(e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log %s: %w", path, err)
+	}
+	return nil
+}
+
+// Close closes the current file; later appends fail
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
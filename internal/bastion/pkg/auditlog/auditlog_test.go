@@ -0,0 +1,106 @@
+package auditlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	log, err := Open(path, DefaultMaxBytes, DefaultMaxFiles)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer log.Close()
+
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []Entry{
+		{Time: base, Operation: "setup_chain", ChainName: "ISO-aaaaaaaaaaaaaaaa", ContainerID: "c1", Success: true},
+		{Time: base.Add(time.Minute), Operation: "apply_rules", ChainName: "ISO-aaaaaaaaaaaaaaaa", ContainerID: "c1", Success: true},
+		{Time: base.Add(2 * time.Minute), Operation: "setup_chain", ChainName: "ISO-bbbbbbbbbbbbbbbb", ContainerID: "c2", Success: false},
+		{Time: base.Add(3 * time.Minute), Operation: "cleanup_chain", ChainName: "ISO-aaaaaaaaaaaaaaaa", ContainerID: "c1", Success: true},
+	}
+	for _, e := range entries {
+		if err := log.Append(e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"setup_chain", "apply_rules", "setup_chain", "cleanup_chain"}},
+		{"container", Filter{ContainerID: "c1"}, []string{"setup_chain", "apply_rules", "cleanup_chain"}},
+		{"chain and operation", Filter{ChainName: "ISO-bbbbbbbbbbbbbbbb", Operation: "setup_chain"}, []string{"setup_chain"}},
+		{"time range", Filter{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)}, []string{"apply_rules", "setup_chain"}},
+		{"limit keeps the latest", Filter{ContainerID: "c1", Limit: 2}, []string{"apply_rules", "cleanup_chain"}},
+		{"no match", Filter{Operation: "expose_ports"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := log.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query() = %+v, want operations %v", got, tt.want)
+			}
+			for i, e := range got {
+				if e.Operation != tt.want[i] {
+					t.Errorf("entry %d operation = %q, want %q", i, e.Operation, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// Small enough that every entry starts a new file
+	log, err := Open(path, 10, 2)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := range 5 {
+		if err := log.Append(Entry{Time: base.Add(time.Duration(i) * time.Second), Operation: "op", ContainerID: string(rune('a' + i))}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	log.Close()
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 rotated files, stat of a third = %v", err)
+	}
+
+	// Reopening carries on from what is on disk
+	log, err = Open(path, 10, 2)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer log.Close()
+
+	got, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	var ids string
+	for _, e := range got {
+		ids += e.ContainerID
+	}
+	if ids != "cde" {
+		t.Errorf("entries kept = %q, want the latest three \"cde\"", ids)
+	}
+
+	if err := os.WriteFile(path, []byte("{\"operation\":\"cut sh"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := log.Query(Filter{}); err != nil || len(got) != 2 {
+		t.Errorf("Query() with a torn line = %d entries, %v; want the 2 rotated", len(got), err)
+	}
+}
@@ -0,0 +1,181 @@
+// Package auth authenticates bastion callers and says what they may touch.
+//
+// Two kinds of credential are accepted, sent as "authorization: Bearer <token>":
+//   - the shared token in BASTION_AUTH_TOKEN, which may act on any chain, for
+//     operators and tools such as cleanup-orphans
+//   - a per-run credential, the hex HMAC-SHA256 of the run ID under the secret
+//     in BASTION_AUTH_SECRET, sent with the run ID the isolation-runner already
+//     puts in x-holopod-run-id. The container-manager derives it for each run,
+//     so a runner only ever holds its own and may only act on its own chains.
+//
+// With neither configured every caller is trusted, as before.
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	TokenEnv  = "BASTION_AUTH_TOKEN"
+	SecretEnv = "BASTION_AUTH_SECRET"
+
+	// RunIDMetadataKey matches the key the isolation-runner sends its run ID in
+	RunIDMetadataKey = "x-holopod-run-id"
+)
+
+// Principal is an authenticated caller. Admin callers may act on any chain;
+// others only on the chains set up with their run ID.
+type Principal struct {
+	RunID string
+	Admin bool
+}
+
+// String names the principal in logs
+func (p Principal) String() string {
+	if p.Admin {
+		return "admin"
+	}
+	return "run:" + p.RunID
+}
+
+// CanAct reports whether p may act on a resource owned by the run owner. A
+// resource without an owner, set up before authentication was configured or
+// by an admin, is left to admins.
+func (p Principal) CanAct(owner string) bool {
+	return p.Admin || (owner != "" && owner == p.RunID)
+}
+
+type principalKey struct{}
+
+// FromContext returns the caller authenticated by the interceptors. Without
+// them, as in tests, the caller is an admin.
+func FromContext(ctx context.Context) Principal {
+	if p, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return p
+	}
+	return Principal{Admin: true}
+}
+
+// WithPrincipal returns ctx carrying p
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// Credential returns the per-run credential for runID under secret
+func Credential(secret, runID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(runID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Authenticator checks the credentials of incoming calls
+type Authenticator struct {
+	token  string
+	secret string
+}
+
+// FromEnv reads the shared token and per-run secret
+func FromEnv() *Authenticator {
+	return New(os.Getenv(TokenEnv), os.Getenv(SecretEnv))
+}
+
+func New(token, secret string) *Authenticator {
+	return &Authenticator{token: token, secret: secret}
+}
+
+// Enabled reports whether callers must authenticate
+func (a *Authenticator) Enabled() bool {
+	return a.token != "" || a.secret != ""
+}
+
+// unauthenticatedMethods may be called without credentials: health checks
+// reveal nothing and must work for probes
+var unauthenticatedMethods = map[string]bool{
+	"/bastion.BastionService/Health": true,
+	"/grpc.health.v1.Health/Check":   true,
+	"/grpc.health.v1.Health/Watch":   true,
+}
+
+// Authenticate returns the principal of the caller in ctx
+func (a *Authenticator) Authenticate(ctx context.Context) (Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var runID string
+	if runIDs := md.Get(RunIDMetadataKey); len(runIDs) == 1 {
+		runID = runIDs[0]
+	}
+
+	// An admin's run ID still names the owner of the chains it sets up
+	if !a.Enabled() {
+		return Principal{RunID: runID, Admin: true}, nil
+	}
+
+	token, ok := bearerToken(md)
+	if !ok {
+		return Principal{}, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	if a.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1 {
+		return Principal{RunID: runID, Admin: true}, nil
+	}
+
+	if a.secret != "" && runID != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(Credential(a.secret, runID))) == 1 {
+			return Principal{RunID: runID}, nil
+		}
+	}
+
+	return Principal{}, status.Error(codes.Unauthenticated, "invalid credentials")
+}
+
+func bearerToken(md metadata.MD) (string, bool) {
+	values := md.Get("authorization")
+	if len(values) != 1 {
+		return "", false
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	return token, ok && token != ""
+}
+
+// UnaryServerInterceptor authenticates unary calls
+func (a *Authenticator) UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if unauthenticatedMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	p, err := a.Authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(WithPrincipal(ctx, p), req)
+}
+
+// StreamServerInterceptor authenticates streaming calls
+func (a *Authenticator) StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if unauthenticatedMethods[info.FullMethod] {
+		return handler(srv, ss)
+	}
+	p, err := a.Authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &principalStream{ServerStream: ss, ctx: WithPrincipal(ss.Context(), p)})
+}
+
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
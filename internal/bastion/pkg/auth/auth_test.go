@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func incoming(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := New("admintoken", "runsecret")
+	credential := Credential("runsecret", "run_a")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want Principal
+		code codes.Code
+	}{
+		{"no metadata", context.Background(), Principal{}, codes.Unauthenticated},
+		{"shared token", incoming("authorization", "Bearer admintoken"), Principal{Admin: true}, codes.OK},
+		{"shared token with run ID", incoming("authorization", "Bearer admintoken", RunIDMetadataKey, "run_a"),
+			Principal{RunID: "run_a", Admin: true}, codes.OK},
+		{"run credential", incoming("authorization", "Bearer "+credential, RunIDMetadataKey, "run_a"),
+			Principal{RunID: "run_a"}, codes.OK},
+		{"run credential for another run", incoming("authorization", "Bearer "+credential, RunIDMetadataKey, "run_b"),
+			Principal{}, codes.Unauthenticated},
+		{"run credential without run ID", incoming("authorization", "Bearer "+credential), Principal{}, codes.Unauthenticated},
+		{"wrong token", incoming("authorization", "Bearer nope"), Principal{}, codes.Unauthenticated},
+		{"not bearer", incoming("authorization", "admintoken"), Principal{}, codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Authenticate(tt.ctx)
+			if status.Code(err) != tt.code {
+				t.Fatalf("Authenticate() error = %v, want code %v", err, tt.code)
+			}
+			if got != tt.want {
+				t.Errorf("Authenticate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateDisabled(t *testing.T) {
+	a := New("", "")
+	if a.Enabled() {
+		t.Fatal("Enabled() = true without token or secret")
+	}
+	got, err := a.Authenticate(incoming(RunIDMetadataKey, "run_a"))
+	if err != nil || got != (Principal{RunID: "run_a", Admin: true}) {
+		t.Errorf("Authenticate() = %+v, %v; want admin for run_a", got, err)
+	}
+}
+
+func TestCanAct(t *testing.T) {
+	run := Principal{RunID: "run_a"}
+	if !run.CanAct("run_a") || run.CanAct("run_b") || run.CanAct("") {
+		t.Error("a run may only act on its own resources")
+	}
+	if !(Principal{Admin: true}).CanAct("") {
+		t.Error("an admin may act on resources without an owner")
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a := New("admintoken", "")
+	var seen Principal
+	handler := func(ctx context.Context, req any) (any, error) {
+		seen = FromContext(ctx)
+		return "ok", nil
+	}
+
+	_, err := a.UnaryServerInterceptor(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/bastion.BastionService/CleanupChain"}, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("unauthenticated CleanupChain: %v, want Unauthenticated", err)
+	}
+
+	// Health probes carry no credentials
+	if _, err := a.UnaryServerInterceptor(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/bastion.BastionService/Health"}, handler); err != nil {
+		t.Errorf("unauthenticated Health: %v", err)
+	}
+
+	_, err = a.UnaryServerInterceptor(incoming("authorization", "Bearer admintoken"), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/bastion.BastionService/CleanupChain"}, handler)
+	if err != nil || !seen.Admin {
+		t.Errorf("authenticated CleanupChain: %v, principal %+v", err, seen)
+	}
+}
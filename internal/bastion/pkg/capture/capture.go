@@ -0,0 +1,149 @@
+// Package capture runs tcpdump against a single container's traffic and
+// streams the resulting pcap data.
+package capture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+)
+
+const (
+	DefaultMaxPackets  = 10000
+	MaxPacketsLimit    = 1000000
+	DefaultMaxDuration = 60 * time.Second
+	MaxDurationLimit   = 600 * time.Second
+	DefaultSnaplen     = 262144
+
+	chunkSize = 32 * 1024
+)
+
+// Options bound a capture session. Zero values select the defaults.
+type Options struct {
+	Filter      string
+	MaxPackets  uint32
+	MaxDuration time.Duration
+	Snaplen     uint32
+}
+
+// normalize applies defaults and rejects out-of-range values
+func (o Options) normalize() (Options, error) {
+	if err := validation.ValidateCaptureFilter(o.Filter); err != nil {
+		return o, err
+	}
+
+	if o.MaxPackets == 0 {
+		o.MaxPackets = DefaultMaxPackets
+	}
+	if o.MaxPackets > MaxPacketsLimit {
+		return o, fmt.Errorf("max_packets too high: %d (maximum: %d)", o.MaxPackets, MaxPacketsLimit)
+	}
+
+	if o.MaxDuration == 0 {
+		o.MaxDuration = DefaultMaxDuration
+	}
+	if o.MaxDuration > MaxDurationLimit {
+		return o, fmt.Errorf("max_duration too high: %s (maximum: %s)", o.MaxDuration, MaxDurationLimit)
+	}
+
+	if o.Snaplen == 0 || o.Snaplen > DefaultSnaplen {
+		o.Snaplen = DefaultSnaplen
+	}
+
+	return o, nil
+}
+
+// BuildArgs returns the tcpdump arguments capturing traffic to or from containerIP.
+// The user filter is ANDed with the host filter so a capture never sees other containers.
+func BuildArgs(containerIP string, opts Options) ([]string, error) {
+	if net.ParseIP(containerIP) == nil {
+		return nil, fmt.Errorf("invalid container IP: %s", containerIP)
+	}
+
+	opts, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"-i", "any",
+		"-n",
+		"-U",
+		"-w", "-",
+		"-s", fmt.Sprintf("%d", opts.Snaplen),
+		"-c", fmt.Sprintf("%d", opts.MaxPackets),
+		"--",
+		"host", containerIP,
+	}
+
+	if filter := strings.Fields(opts.Filter); len(filter) > 0 {
+		args = append(args, "and", "(")
+		args = append(args, filter...)
+		args = append(args, ")")
+	}
+
+	return args, nil
+}
+
+// Run captures packets until the packet or duration limit is reached or ctx is cancelled,
+// passing pcap data to fn as it arrives. Reaching a limit is not an error.
+func Run(ctx context.Context, containerIP string, opts Options, fn func([]byte) error) error {
+	args, err := BuildArgs(containerIP, opts)
+	if err != nil {
+		return err
+	}
+
+	opts, _ = opts.normalize()
+	ctx, cancel := context.WithTimeout(ctx, opts.MaxDuration)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "tcpdump", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create tcpdump pipe: %w", err)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start tcpdump: %w", err)
+	}
+
+	buf := make([]byte, chunkSize)
+	var sendErr error
+	for {
+		n, readErr := stdout.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			if sendErr = fn(chunk); sendErr != nil {
+				cancel()
+				break
+			}
+		}
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) && ctx.Err() == nil {
+				sendErr = fmt.Errorf("failed to read tcpdump output: %w", readErr)
+			}
+			break
+		}
+	}
+
+	waitErr := cmd.Wait()
+	if sendErr != nil {
+		return sendErr
+	}
+	// tcpdump is killed when the duration elapses or the caller stops the capture
+	if waitErr != nil && ctx.Err() == nil {
+		return fmt.Errorf("tcpdump failed: %w: %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
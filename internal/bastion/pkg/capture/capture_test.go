@@ -0,0 +1,61 @@
+package capture
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       string
+		opts     Options
+		wantTail string
+		wantErr  bool
+	}{
+		{
+			name:     "defaults",
+			ip:       "172.17.0.2",
+			opts:     Options{},
+			wantTail: "-s 262144 -c 10000 -- host 172.17.0.2",
+		},
+		{
+			name:     "with filter",
+			ip:       "172.17.0.2",
+			opts:     Options{Filter: "tcp port 443", MaxPackets: 50, Snaplen: 96},
+			wantTail: "-s 96 -c 50 -- host 172.17.0.2 and ( tcp port 443 )",
+		},
+		{
+			name:     "ipv6 container",
+			ip:       "fd00::2",
+			opts:     Options{},
+			wantTail: "-- host fd00::2",
+		},
+		{"invalid ip", "not-an-ip", Options{}, "", true},
+		{"invalid filter", "172.17.0.2", Options{Filter: "port 80 -w /tmp/x"}, "", true},
+		{"filter escaping the host", "172.17.0.2", Options{Filter: "tcp ) or ( host 10.0.0.5"}, "", true},
+		{"too many packets", "172.17.0.2", Options{MaxPackets: MaxPacketsLimit + 1}, "", true},
+		{"too long", "172.17.0.2", Options{MaxDuration: MaxDurationLimit + time.Second}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := BuildArgs(tt.ip, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := strings.Join(args, " ")
+			if !strings.HasPrefix(got, "-i any -n -U -w -") {
+				t.Errorf("BuildArgs() = %q, want tcpdump writing pcap to stdout", got)
+			}
+			if !strings.HasSuffix(got, tt.wantTail) {
+				t.Errorf("BuildArgs() = %q, want suffix %q", got, tt.wantTail)
+			}
+		})
+	}
+}
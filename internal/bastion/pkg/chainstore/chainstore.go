@@ -0,0 +1,178 @@
+// Package chainstore records which container owns each chain, on disk, so the
+// bastion still knows after a restart which chains belong to live containers.
+package chainstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPath          = "/var/lib/bastion/chains.json"
+	stateDirPermissions  = 0700
+	stateFilePermissions = 0600
+)
+
+// PathFromEnv reads BASTION_CHAIN_STATE_FILE, falling back to DefaultPath
+func PathFromEnv() string {
+	if path := os.Getenv("BASTION_CHAIN_STATE_FILE"); path != "" {
+		return path
+	}
+	return DefaultPath
+}
+
+// Record is the owner of a chain as given to SetupChain. Owner is the run ID
+// of the caller, which alone may change the chain when callers authenticate.
+// Policy and Ports follow the chain's later changes, so a bastion taking over
+// from another knows what the chain should hold.
+type Record struct {
+	ContainerID string    `json:"container_id"`
+	ContainerIP string    `json:"container_ip"`
+	Owner       string    `json:"owner,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Policy is the network policy last applied, in protobuf JSON form
+	Policy json.RawMessage `json:"policy,omitempty"`
+	Ports  []Port          `json:"ports,omitempty"`
+}
+
+// Port is a container port published on the host
+type Port struct {
+	ContainerPort uint32 `json:"container_port"`
+	HostPort      uint32 `json:"host_port"`
+	Protocol      string `json:"protocol"`
+}
+
+// Store holds chain records by chain name. Every change is written to its file
+// before it returns; a store without a file only keeps them in memory.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// New returns a store kept in memory only
+func New() *Store {
+	return &Store{records: make(map[string]Record)}
+}
+
+// Open loads the store at path, which need not exist yet
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), stateDirPermissions); err != nil {
+		return nil, fmt.Errorf("failed to create chain state directory: %w", err)
+	}
+
+	s := &Store{path: path, records: make(map[string]Record)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chain state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chain state: %w", err)
+	}
+	if s.records == nil {
+		s.records = make(map[string]Record)
+	}
+	return s, nil
+}
+
+// Put records the owner of chainName, replacing any earlier record
+func (s *Store) Put(chainName string, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.records[chainName]
+	s.records[chainName] = record
+	if err := s.persist(); err != nil {
+		if existed {
+			s.records[chainName] = previous
+		} else {
+			delete(s.records, chainName)
+		}
+		return err
+	}
+	return nil
+}
+
+// Update changes the record of chainName with fn. It does nothing when the
+// chain has no record.
+func (s *Store) Update(chainName string, fn func(*Record)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.records[chainName]
+	if !ok {
+		return nil
+	}
+	record := previous
+	fn(&record)
+	s.records[chainName] = record
+	if err := s.persist(); err != nil {
+		s.records[chainName] = previous
+		return err
+	}
+	return nil
+}
+
+// Delete forgets chainName. Forgetting a chain that has no record is not an error.
+func (s *Store) Delete(chainName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[chainName]; !ok {
+		return nil
+	}
+	delete(s.records, chainName)
+	return s.persist()
+}
+
+// Get returns the record of chainName
+func (s *Store) Get(chainName string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[chainName]
+	return record, ok
+}
+
+// All returns a copy of every record
+func (s *Store) All() map[string]Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make(map[string]Record, len(s.records))
+	for chainName, record := range s.records {
+		records[chainName] = record
+	}
+	return records
+}
+
+// persist must be called with s.mu held
+func (s *Store) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal chain state: %w", err)
+	}
+
+	tmpFile := s.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, stateFilePermissions); err != nil {
+		return fmt.Errorf("failed to write temp chain state file: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.path); err != nil {
+		_ = os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename chain state file: %w", err)
+	}
+	return nil
+}
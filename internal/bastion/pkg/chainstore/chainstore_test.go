@@ -0,0 +1,103 @@
+package chainstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "chains.json")
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(store.All()) != 0 {
+		t.Fatalf("new store has records %v", store.All())
+	}
+
+	record := Record{ContainerID: "abc123", ContainerIP: "10.20.0.2", CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	if err := store.Put("ISO-aaaaaaaaaaaaaaaa", record); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Put("ISO-bbbbbbbbbbbbbbbb", Record{ContainerID: "def456"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Delete("ISO-bbbbbbbbbbbbbbbb"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete("ISO-cccccccccccccccc"); err != nil {
+		t.Errorf("Delete() of a chain with no record error = %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	records := reopened.All()
+	if len(records) != 1 || !reflect.DeepEqual(records["ISO-aaaaaaaaaaaaaaaa"], record) {
+		t.Errorf("reopened records = %v, want only ISO-aaaaaaaaaaaaaaaa -> %v", records, record)
+	}
+}
+
+func TestStorePutRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(filepath.Join(dir, "chains.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The temp file cannot be written over a directory
+	if err := os.Mkdir(filepath.Join(dir, "chains.json.tmp"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("ISO-aaaaaaaaaaaaaaaa", Record{ContainerID: "abc123"}); err == nil {
+		t.Fatal("Put() succeeded without writing the file")
+	}
+	if _, ok := store.Get("ISO-aaaaaaaaaaaaaaaa"); ok {
+		t.Error("a record that was not written stayed in memory")
+	}
+}
+
+func TestStoreUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chains.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("ISO-aaaaaaaaaaaaaaaa", Record{ContainerID: "abc123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.Update("ISO-aaaaaaaaaaaaaaaa", func(record *Record) {
+		record.Policy = []byte(`{"policy":"deny"}`)
+		record.Ports = []Port{{ContainerPort: 80, HostPort: 30080, Protocol: "tcp"}}
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := store.Update("ISO-bbbbbbbbbbbbbbbb", func(record *Record) { record.Owner = "run" }); err != nil {
+		t.Errorf("Update() of a chain with no record error = %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, _ := reopened.Get("ISO-aaaaaaaaaaaaaaaa")
+	var policy bytes.Buffer
+	if err := json.Compact(&policy, record.Policy); err != nil {
+		t.Fatal(err)
+	}
+	if record.ContainerID != "abc123" || policy.String() != `{"policy":"deny"}` || len(record.Ports) != 1 {
+		t.Errorf("updated record = %+v", record)
+	}
+	if _, ok := reopened.Get("ISO-bbbbbbbbbbbbbbbb"); ok {
+		t.Error("Update() created a record")
+	}
+}
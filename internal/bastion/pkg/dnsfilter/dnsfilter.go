@@ -0,0 +1,495 @@
+// Package dnsfilter runs a DNS forwarder that only resolves the domains a
+// container's policy allows, so DNS lookups cannot be used to exfiltrate data
+// through arbitrary names.
+package dnsfilter
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+)
+
+const (
+	// DefaultUpstreams are used when a policy has no DNS servers and
+	// BASTION_DNS_FILTER_UPSTREAMS is unset
+	DefaultUpstreams = "1.1.1.1,8.8.8.8"
+
+	upstreamTimeout = 3 * time.Second
+	tcpIdleTimeout  = 10 * time.Second
+	maxMessageSize  = 65535
+	udpMessageSize  = 4096
+
+	// maxUDPQueries caps the UDP queries handled at once; more are dropped,
+	// and clients retry
+	maxUDPQueries = 256
+	// maxTCPConns caps open TCP connections; more are closed on accept
+	maxTCPConns = 64
+)
+
+// Matcher decides whether a query name is covered by an allowlist
+type Matcher struct {
+	exact    map[string]struct{}
+	suffixes []string
+}
+
+// NewMatcher builds a matcher from validated domain patterns. An exact entry
+// matches only that name; "*.example.com" matches subdomains of example.com.
+func NewMatcher(domains []string) (*Matcher, error) {
+	m := &Matcher{exact: make(map[string]struct{})}
+
+	for _, domain := range domains {
+		if err := validation.ValidateDomainPattern(domain); err != nil {
+			return nil, err
+		}
+
+		domain = strings.ToLower(domain)
+		if suffix, ok := strings.CutPrefix(domain, "*"); ok {
+			m.suffixes = append(m.suffixes, suffix)
+		} else {
+			m.exact[domain] = struct{}{}
+		}
+	}
+
+	return m, nil
+}
+
+// Allowed reports whether name (with or without a trailing dot) is allowed
+func (m *Matcher) Allowed(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" {
+		return false
+	}
+
+	if _, ok := m.exact[name]; ok {
+		return true
+	}
+	for _, suffix := range m.suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type policy struct {
+	matcher *Matcher
+	// upstreams are host:port resolver addresses
+	upstreams []string
+}
+
+// Server answers DNS queries from containers with a registered policy, keyed by
+// the container's source IP. Queries from unknown sources are refused and names
+// outside the allowlist get NXDOMAIN.
+type Server struct {
+	addr      string
+	upstreams []string
+	logger    *slog.Logger
+
+	mu       sync.RWMutex
+	policies map[string]*policy
+
+	// udpSlots and tcpSlots hold a token per query or connection being served,
+	// so a container flooding the resolver cannot exhaust the bastion
+	udpSlots chan struct{}
+	tcpSlots chan struct{}
+	dropped  atomic.Uint64
+}
+
+// NewServer creates a filter listening on addr (host:port). upstreams are the
+// resolvers used for policies that do not name their own DNS servers.
+func NewServer(addr string, upstreams []string, logger *slog.Logger) (*Server, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS filter address %s: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		return nil, fmt.Errorf("DNS filter address must be a specific IP reachable from containers: %s", addr)
+	}
+
+	for _, upstream := range upstreams {
+		if net.ParseIP(upstream) == nil {
+			return nil, fmt.Errorf("invalid DNS filter upstream: %s", upstream)
+		}
+	}
+
+	return &Server{
+		addr:      addr,
+		upstreams: upstreamAddrs(upstreams),
+		logger:    logger,
+		policies:  make(map[string]*policy),
+		udpSlots:  make(chan struct{}, maxUDPQueries),
+		tcpSlots:  make(chan struct{}, maxTCPConns),
+	}, nil
+}
+
+// NewServerFromEnv creates a filter from BASTION_DNS_FILTER_ADDRESS and
+// BASTION_DNS_FILTER_UPSTREAMS. It returns nil when no address is configured.
+func NewServerFromEnv(logger *slog.Logger) (*Server, error) {
+	addr := os.Getenv("BASTION_DNS_FILTER_ADDRESS")
+	if addr == "" {
+		return nil, nil
+	}
+
+	upstreams := os.Getenv("BASTION_DNS_FILTER_UPSTREAMS")
+	if upstreams == "" {
+		upstreams = DefaultUpstreams
+	}
+
+	var list []string
+	for _, upstream := range strings.Split(upstreams, ",") {
+		if upstream = strings.TrimSpace(upstream); upstream != "" {
+			list = append(list, upstream)
+		}
+	}
+
+	return NewServer(addr, list, logger)
+}
+
+// IP returns the address containers should use as their DNS server
+func (s *Server) IP() string {
+	host, _, _ := net.SplitHostPort(s.addr)
+	return host
+}
+
+// SetPolicy registers or replaces the allowlist for a container. dnsServers
+// override the default upstreams when non-empty.
+func (s *Server) SetPolicy(containerIP string, domains []string, dnsServers []string) error {
+	ip := net.ParseIP(containerIP)
+	if ip == nil {
+		return fmt.Errorf("invalid container IP: %s", containerIP)
+	}
+
+	matcher, err := NewMatcher(domains)
+	if err != nil {
+		return err
+	}
+
+	upstreams := s.upstreams
+	if len(dnsServers) > 0 {
+		upstreams = upstreamAddrs(dnsServers)
+	}
+
+	s.mu.Lock()
+	s.policies[ip.String()] = &policy{matcher: matcher, upstreams: upstreams}
+	s.mu.Unlock()
+
+	return nil
+}
+
+func upstreamAddrs(ips []string) []string {
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.JoinHostPort(ip, "53"))
+	}
+	return addrs
+}
+
+// RemovePolicy drops the container's allowlist; later queries are refused
+func (s *Server) RemovePolicy(containerIP string) {
+	ip := net.ParseIP(containerIP)
+	if ip == nil {
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.policies, ip.String())
+	s.mu.Unlock()
+}
+
+func (s *Server) policyFor(ip net.IP) *policy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policies[ip.String()]
+}
+
+// Start listens on UDP and TCP and serves queries until ctx is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	udpConn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp %s: %w", s.addr, err)
+	}
+
+	tcpListener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		udpConn.Close()
+		return fmt.Errorf("failed to listen on tcp %s: %w", s.addr, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		udpConn.Close()
+		tcpListener.Close()
+	}()
+
+	go s.serveUDP(ctx, udpConn)
+	go s.serveTCP(ctx, tcpListener)
+
+	return nil
+}
+
+func (s *Server) serveUDP(ctx context.Context, conn net.PacketConn) {
+	buf := make([]byte, udpMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Warn("dns filter udp listener stopped", "error", err)
+			}
+			return
+		}
+
+		if !s.acquire(s.udpSlots) {
+			continue
+		}
+
+		query := make([]byte, n)
+		copy(query, buf[:n])
+
+		go func() {
+			defer s.release(s.udpSlots)
+
+			udpAddr, ok := addr.(*net.UDPAddr)
+			if !ok {
+				return
+			}
+			if resp := s.handle(ctx, "udp", udpAddr.IP, query); resp != nil {
+				_, _ = conn.WriteTo(resp, addr)
+			}
+		}()
+	}
+}
+
+func (s *Server) serveTCP(ctx context.Context, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Warn("dns filter tcp listener stopped", "error", err)
+			}
+			return
+		}
+
+		if !s.acquire(s.tcpSlots) {
+			conn.Close()
+			continue
+		}
+		go func() {
+			defer s.release(s.tcpSlots)
+			s.serveTCPConn(ctx, conn)
+		}()
+	}
+}
+
+// acquire takes a slot without waiting, or counts the request as dropped
+func (s *Server) acquire(slots chan struct{}) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if dropped := s.dropped.Add(1); dropped%1000 == 1 {
+		s.logger.Warn("dns filter is at capacity, dropping queries", "dropped", dropped)
+	}
+	return false
+}
+
+func (s *Server) release(slots chan struct{}) {
+	<-slots
+}
+
+func (s *Server) serveTCPConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return
+	}
+
+	for {
+		_ = conn.SetDeadline(time.Now().Add(tcpIdleTimeout))
+
+		query, err := readTCPMessage(conn)
+		if err != nil {
+			return
+		}
+
+		resp := s.handle(ctx, "tcp", tcpAddr.IP, query)
+		if resp == nil {
+			return
+		}
+		if err := writeTCPMessage(conn, resp); err != nil {
+			return
+		}
+	}
+}
+
+// handle returns the response to send for query, or nil if it should be dropped
+func (s *Server) handle(ctx context.Context, network string, src net.IP, query []byte) []byte {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil || header.Response {
+		return nil
+	}
+
+	question, err := parser.Question()
+	if err != nil {
+		return reply(header, nil, dnsmessage.RCodeFormatError)
+	}
+	// Only the first question is checked, so a second one could carry a
+	// blocked name through to the upstream
+	if _, err := parser.Question(); !errors.Is(err, dnsmessage.ErrSectionDone) {
+		return reply(header, &question, dnsmessage.RCodeFormatError)
+	}
+
+	p := s.policyFor(src)
+	if p == nil {
+		return reply(header, &question, dnsmessage.RCodeRefused)
+	}
+
+	name := question.Name.String()
+	if !p.matcher.Allowed(name) {
+		s.logger.Info("dns query blocked",
+			"container_ip", src.String(),
+			"name", name,
+			"type", question.Type.String(),
+		)
+		return reply(header, &question, dnsmessage.RCodeNameError)
+	}
+
+	resp, err := s.forward(ctx, network, query, p.upstreams)
+	if err != nil {
+		s.logger.Warn("dns query forwarding failed",
+			"container_ip", src.String(),
+			"name", name,
+			"error", err,
+		)
+		return reply(header, &question, dnsmessage.RCodeServerFailure)
+	}
+
+	return resp
+}
+
+// forward relays query to each upstream in turn over the client's transport
+func (s *Server) forward(ctx context.Context, network string, query []byte, upstreams []string) ([]byte, error) {
+	var lastErr error
+	for _, upstream := range upstreams {
+		resp, err := exchange(ctx, network, upstream, query)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no upstream DNS servers configured")
+	}
+	return nil, lastErr
+}
+
+func exchange(ctx context.Context, network, addr string, query []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		if err := writeTCPMessage(conn, query); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	// Skip stray datagrams until one answers this query's ID
+	buf := make([]byte, udpMessageSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n >= 2 && binary.BigEndian.Uint16(buf) == binary.BigEndian.Uint16(query) {
+			return buf[:n], nil
+		}
+	}
+}
+
+// reply builds an answerless response echoing the query's header and question
+func reply(query dnsmessage.Header, question *dnsmessage.Question, rcode dnsmessage.RCode) []byte {
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 query.ID,
+		Response:           true,
+		OpCode:             query.OpCode,
+		RecursionDesired:   query.RecursionDesired,
+		RecursionAvailable: true,
+		RCode:              rcode,
+	})
+	builder.EnableCompression()
+
+	if question != nil {
+		if err := builder.StartQuestions(); err != nil {
+			return nil
+		}
+		if err := builder.Question(*question); err != nil {
+			return nil
+		}
+	}
+
+	msg, err := builder.Finish()
+	if err != nil {
+		return nil
+	}
+	return msg
+}
+
+func readTCPMessage(r io.Reader) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(w io.Writer, msg []byte) error {
+	if len(msg) > maxMessageSize {
+		return fmt.Errorf("dns message too large: %d bytes", len(msg))
+	}
+
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+
+	_, err := w.Write(buf)
+	return err
+}
@@ -0,0 +1,346 @@
+package dnsfilter
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestMatcher(t *testing.T) {
+	m, err := NewMatcher([]string{"example.com", "*.api.test.io", "Mixed.Case.org"})
+	if err != nil {
+		t.Fatalf("NewMatcher() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"example.com", true},
+		{"example.com.", true},
+		{"EXAMPLE.com.", true},
+		{"www.example.com", false},
+		{"notexample.com", false},
+		{"v1.api.test.io.", true},
+		{"a.b.api.test.io", true},
+		{"api.test.io", false},
+		{"xapi.test.io", false},
+		{"mixed.case.org", true},
+		{"", false},
+		{".", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Allowed(tt.name); got != tt.want {
+				t.Errorf("Allowed(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMatcherRejectsInvalidPatterns(t *testing.T) {
+	for _, domain := range []string{"*", "api.*.com", "bad domain.com"} {
+		if _, err := NewMatcher([]string{domain}); err == nil {
+			t.Errorf("NewMatcher(%q) expected error", domain)
+		}
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		addr      string
+		upstreams []string
+		wantErr   bool
+	}{
+		{"valid", "172.17.0.1:53", []string{"1.1.1.1"}, false},
+		{"missing port", "172.17.0.1", nil, true},
+		{"unspecified address", "0.0.0.0:53", nil, true},
+		{"hostname", "localhost:53", nil, true},
+		{"invalid upstream", "172.17.0.1:53", []string{"dns.example.com"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewServer(tt.addr, tt.upstreams, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func buildQuery(t *testing.T, id uint16, name string) []byte {
+	t.Helper()
+
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: true})
+	if err := builder.StartQuestions(); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName(name),
+		Type:  dnsmessage.TypeA,
+		Class: dnsmessage.ClassINET,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := builder.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func parseHeader(t *testing.T, msg []byte) dnsmessage.Header {
+	t.Helper()
+
+	var parser dnsmessage.Parser
+	header, err := parser.Start(msg)
+	if err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	return header
+}
+
+// startUpstream runs a UDP resolver answering every query with a single A record
+func startUpstream(t *testing.T) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var parser dnsmessage.Parser
+			header, err := parser.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			question, err := parser.Question()
+			if err != nil {
+				continue
+			}
+
+			builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true})
+			_ = builder.StartQuestions()
+			_ = builder.Question(question)
+			_ = builder.StartAnswers()
+			_ = builder.AResource(dnsmessage.ResourceHeader{
+				Name:  question.Name,
+				Class: dnsmessage.ClassINET,
+				TTL:   60,
+			}, dnsmessage.AResource{A: [4]byte{93, 184, 216, 34}})
+			resp, _ := builder.Finish()
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestHandle(t *testing.T) {
+	s, err := NewServer("127.0.0.1:53", nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPolicy("10.0.0.5", []string{"example.com"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Point the policy at the test resolver, which is not on port 53
+	s.policies["10.0.0.5"].upstreams = []string{startUpstream(t)}
+
+	ctx := context.Background()
+
+	t.Run("unknown source is refused", func(t *testing.T) {
+		resp := s.handle(ctx, "udp", net.ParseIP("10.0.0.6"), buildQuery(t, 1, "example.com."))
+		header := parseHeader(t, resp)
+		if header.RCode != dnsmessage.RCodeRefused || header.ID != 1 || !header.Response {
+			t.Errorf("unexpected header %+v", header)
+		}
+	})
+
+	t.Run("blocked name gets NXDOMAIN", func(t *testing.T) {
+		resp := s.handle(ctx, "udp", net.ParseIP("10.0.0.5"), buildQuery(t, 2, "exfil.attacker.net."))
+		header := parseHeader(t, resp)
+		if header.RCode != dnsmessage.RCodeNameError || header.ID != 2 {
+			t.Errorf("unexpected header %+v", header)
+		}
+	})
+
+	t.Run("allowed name is forwarded", func(t *testing.T) {
+		resp := s.handle(ctx, "udp", net.ParseIP("10.0.0.5"), buildQuery(t, 3, "example.com."))
+
+		var parser dnsmessage.Parser
+		header, err := parser.Start(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.RCode != dnsmessage.RCodeSuccess || header.ID != 3 {
+			t.Errorf("unexpected header %+v", header)
+		}
+		if err := parser.SkipAllQuestions(); err != nil {
+			t.Fatal(err)
+		}
+		answers, err := parser.AllAnswers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(answers) != 1 {
+			t.Errorf("expected 1 answer, got %d", len(answers))
+		}
+	})
+
+	t.Run("more than one question gets FORMERR", func(t *testing.T) {
+		builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 6, RecursionDesired: true})
+		if err := builder.StartQuestions(); err != nil {
+			t.Fatal(err)
+		}
+		// A blocked name riding along after an allowed one
+		for _, name := range []string{"example.com.", "exfil.attacker.net."} {
+			if err := builder.Question(dnsmessage.Question{
+				Name:  dnsmessage.MustNewName(name),
+				Type:  dnsmessage.TypeA,
+				Class: dnsmessage.ClassINET,
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		query, err := builder.Finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := s.handle(ctx, "udp", net.ParseIP("10.0.0.5"), query)
+		if header := parseHeader(t, resp); header.RCode != dnsmessage.RCodeFormatError || header.ID != 6 {
+			t.Errorf("unexpected header %+v", header)
+		}
+	})
+
+	t.Run("responses are ignored", func(t *testing.T) {
+		query := buildQuery(t, 4, "example.com.")
+		query[2] |= 0x80
+		if resp := s.handle(ctx, "udp", net.ParseIP("10.0.0.5"), query); resp != nil {
+			t.Error("expected response packets to be dropped")
+		}
+	})
+
+	t.Run("removed policy is refused", func(t *testing.T) {
+		s.RemovePolicy("10.0.0.5")
+		resp := s.handle(ctx, "udp", net.ParseIP("10.0.0.5"), buildQuery(t, 5, "example.com."))
+		if header := parseHeader(t, resp); header.RCode != dnsmessage.RCodeRefused {
+			t.Errorf("expected REFUSED, got %v", header.RCode)
+		}
+	})
+}
+
+func TestHandleUpstreamFailure(t *testing.T) {
+	s, err := NewServer("127.0.0.1:53", nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPolicy("10.0.0.5", []string{"*.example.com"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing listens here, so the exchange fails
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := conn.LocalAddr().String()
+	conn.Close()
+	s.policies["10.0.0.5"].upstreams = []string{addr}
+
+	resp := s.handle(context.Background(), "udp", net.ParseIP("10.0.0.5"), buildQuery(t, 7, "www.example.com."))
+	if header := parseHeader(t, resp); header.RCode != dnsmessage.RCodeServerFailure {
+		t.Errorf("expected SERVFAIL, got %v", header.RCode)
+	}
+}
+
+func TestServeDropsPastCapacity(t *testing.T) {
+	s, err := NewServer("127.0.0.1:53", nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.udpSlots = make(chan struct{}, 1)
+	s.tcpSlots = make(chan struct{}, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udpConn.Close()
+	go s.serveUDP(ctx, udpConn)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go s.serveTCP(ctx, listener)
+
+	client, err := net.Dial("udp", udpConn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	buf := make([]byte, udpMessageSize)
+
+	// With every slot taken, queries are dropped and connections closed
+	s.udpSlots <- struct{}{}
+	s.tcpSlots <- struct{}{}
+
+	if _, err := client.Write(buildQuery(t, 1, "example.com.")); err != nil {
+		t.Fatal(err)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := client.Read(buf); err == nil {
+		t.Error("query past capacity was answered")
+	}
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(buf); err != io.EOF {
+		t.Errorf("connection past capacity: read error = %v, want EOF", err)
+	}
+	conn.Close()
+
+	if got := s.dropped.Load(); got != 2 {
+		t.Errorf("dropped = %d, want 2", got)
+	}
+
+	// Once a slot frees up, queries are served again
+	<-s.udpSlots
+	if _, err := client.Write(buildQuery(t, 2, "example.com.")); err != nil {
+		t.Fatal(err)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := client.Read(buf)
+	if err != nil {
+		t.Fatalf("query after capacity freed up: %v", err)
+	}
+	if header := parseHeader(t, buf[:n]); header.ID != 2 || header.RCode != dnsmessage.RCodeRefused {
+		t.Errorf("unexpected header %+v", header)
+	}
+}
@@ -0,0 +1,214 @@
+// Package flowlog collects per-container egress flow records from kernel LOG
+// entries written by the iptables chains the bastion manages.
+package flowlog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultSource is the kernel log device LOG target entries are read from
+	DefaultSource = "/dev/kmsg"
+
+	VerdictAllow = "allow"
+	VerdictDeny  = "deny"
+
+	// subscriberBuffer bounds records queued per subscriber; excess records are dropped
+	subscriberBuffer = 256
+)
+
+// Record is a single logged connection attempt
+type Record struct {
+	Chain     string
+	Verdict   string
+	Protocol  string
+	Src       string
+	Dst       string
+	SrcPort   uint32
+	DstPort   uint32
+	Timestamp time.Time
+}
+
+// Prefix returns the iptables --log-prefix for a chain and verdict.
+// Chain names are at most 28 chars, keeping the prefix within the kernel's 29 char limit.
+func Prefix(chainName string, verdict string) string {
+	code := "D"
+	if verdict == VerdictAllow {
+		code = "A"
+	}
+	return fmt.Sprintf("%s:%s ", chainName, code)
+}
+
+// ParseLine extracts a Record from a kernel log line produced by a Prefix-tagged LOG rule.
+// Lines from /dev/kmsg carry a "prio,seq,usec,flags;" header which is skipped.
+func ParseLine(line string) (Record, bool) {
+	if _, msg, ok := strings.Cut(line, ";"); ok {
+		line = msg
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Record{}, false
+	}
+
+	chain, code, ok := strings.Cut(fields[0], ":")
+	if !ok || !strings.HasPrefix(chain, "ISO-") {
+		return Record{}, false
+	}
+
+	record := Record{
+		Chain:     chain,
+		Timestamp: time.Now(),
+	}
+	switch code {
+	case "A":
+		record.Verdict = VerdictAllow
+	case "D":
+		record.Verdict = VerdictDeny
+	default:
+		return Record{}, false
+	}
+
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "SRC":
+			record.Src = value
+		case "DST":
+			record.Dst = value
+		case "PROTO":
+			record.Protocol = strings.ToLower(value)
+		case "SPT":
+			if port, err := strconv.ParseUint(value, 10, 16); err == nil {
+				record.SrcPort = uint32(port)
+			}
+		case "DPT":
+			if port, err := strconv.ParseUint(value, 10, 16); err == nil {
+				record.DstPort = uint32(port)
+			}
+		}
+	}
+
+	if record.Src == "" || record.Dst == "" {
+		return Record{}, false
+	}
+
+	return record, true
+}
+
+// Collector tails the kernel log and fans flow records out to per-chain subscribers
+type Collector struct {
+	source string
+	logger *slog.Logger
+
+	mu   sync.RWMutex
+	subs map[string]map[chan Record]struct{}
+}
+
+func NewCollector(source string, logger *slog.Logger) *Collector {
+	if source == "" {
+		source = DefaultSource
+	}
+	return &Collector{
+		source: source,
+		logger: logger,
+		subs:   make(map[string]map[chan Record]struct{}),
+	}
+}
+
+// Start opens the log source and begins dispatching records until ctx is cancelled.
+// Only entries written after Start are delivered.
+func (c *Collector) Start(ctx context.Context) error {
+	f, err := os.Open(c.source)
+	if err != nil {
+		return fmt.Errorf("failed to open flow log source %s: %w", c.source, err)
+	}
+
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to seek flow log source %s: %w", c.source, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	go c.run(ctx, f)
+
+	return nil
+}
+
+func (c *Collector) run(ctx context.Context, r io.Reader) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if record, ok := ParseLine(line); ok {
+				c.dispatch(record)
+			}
+		}
+		if err != nil {
+			// kmsg reports EPIPE when older records were overwritten; keep reading
+			if errors.Is(err, syscall.EPIPE) {
+				continue
+			}
+			if ctx.Err() == nil && err != io.EOF {
+				c.logger.Warn("flow log reader stopped", "error", err)
+			}
+			return
+		}
+	}
+}
+
+func (c *Collector) dispatch(record Record) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for ch := range c.subs[record.Chain] {
+		select {
+		case ch <- record:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving records for chainName and a function that
+// unsubscribes and closes the channel.
+func (c *Collector) Subscribe(chainName string) (<-chan Record, func()) {
+	ch := make(chan Record, subscriberBuffer)
+
+	c.mu.Lock()
+	if c.subs[chainName] == nil {
+		c.subs[chainName] = make(map[chan Record]struct{})
+	}
+	c.subs[chainName][ch] = struct{}{}
+	c.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			delete(c.subs[chainName], ch)
+			if len(c.subs[chainName]) == 0 {
+				delete(c.subs, chainName)
+			}
+			c.mu.Unlock()
+			close(ch)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package flowlog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrefix(t *testing.T) {
+	tests := []struct {
+		verdict string
+		want    string
+	}{
+		{VerdictAllow, "ISO-0123456789abcdef:A "},
+		{VerdictDeny, "ISO-0123456789abcdef:D "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.verdict, func(t *testing.T) {
+			got := Prefix("ISO-0123456789abcdef", tt.verdict)
+			if got != tt.want {
+				t.Errorf("Prefix() = %q, want %q", got, tt.want)
+			}
+			if len(got) > 29 {
+				t.Errorf("Prefix() length = %d, exceeds kernel limit of 29", len(got))
+			}
+		})
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   Record
+		wantOK bool
+	}{
+		{
+			name: "kmsg tcp allow",
+			line: "4,1234,5678901,-;ISO-0123456789abcdef:A IN=docker0 OUT=eth0 SRC=172.17.0.2 DST=8.8.8.8 LEN=60 TTL=63 PROTO=TCP SPT=40000 DPT=443 SYN URGP=0\n",
+			want: Record{
+				Chain:    "ISO-0123456789abcdef",
+				Verdict:  VerdictAllow,
+				Protocol: "tcp",
+				Src:      "172.17.0.2",
+				Dst:      "8.8.8.8",
+				SrcPort:  40000,
+				DstPort:  443,
+			},
+			wantOK: true,
+		},
+		{
+			name: "plain udp deny",
+			line: "ISO-0123456789abcdef:D IN=docker0 OUT=eth0 SRC=172.17.0.2 DST=169.254.169.254 PROTO=UDP SPT=5353 DPT=53",
+			want: Record{
+				Chain:    "ISO-0123456789abcdef",
+				Verdict:  VerdictDeny,
+				Protocol: "udp",
+				Src:      "172.17.0.2",
+				Dst:      "169.254.169.254",
+				SrcPort:  5353,
+				DstPort:  53,
+			},
+			wantOK: true,
+		},
+		{
+			name: "icmp without ports",
+			line: "ISO-0123456789abcdef:D SRC=fd00::2 DST=fe80::1 PROTO=ICMPv6 TYPE=128",
+			want: Record{
+				Chain:    "ISO-0123456789abcdef",
+				Verdict:  VerdictDeny,
+				Protocol: "icmpv6",
+				Src:      "fd00::2",
+				Dst:      "fe80::1",
+			},
+			wantOK: true,
+		},
+		{"unrelated kernel message", "6,99,100,-;eth0: link becomes ready", Record{}, false},
+		{"unknown verdict", "ISO-0123456789abcdef:X SRC=1.1.1.1 DST=2.2.2.2", Record{}, false},
+		{"missing addresses", "ISO-0123456789abcdef:A PROTO=TCP", Record{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseLine() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			got.Timestamp = time.Time{}
+			if got != tt.want {
+				t.Errorf("ParseLine() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectorDispatch(t *testing.T) {
+	c := NewCollector("", slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	records, unsubscribe := c.Subscribe("ISO-0123456789abcdef")
+	defer unsubscribe()
+
+	other, unsubscribeOther := c.Subscribe("ISO-fedcba9876543210")
+	defer unsubscribeOther()
+
+	input := strings.Join([]string{
+		"ISO-0123456789abcdef:A SRC=172.17.0.2 DST=1.1.1.1 PROTO=TCP SPT=1 DPT=443",
+		"not a flow record",
+		"ISO-0123456789abcdef:D SRC=172.17.0.2 DST=10.0.0.1 PROTO=TCP SPT=2 DPT=22",
+		"",
+	}, "\n")
+	c.run(context.Background(), strings.NewReader(input))
+
+	for _, want := range []string{"1.1.1.1", "10.0.0.1"} {
+		select {
+		case record := <-records:
+			if record.Dst != want {
+				t.Errorf("record.Dst = %s, want %s", record.Dst, want)
+			}
+		default:
+			t.Fatalf("expected record for %s", want)
+		}
+	}
+
+	select {
+	case record := <-other:
+		t.Errorf("unexpected record for other chain: %+v", record)
+	default:
+	}
+
+	unsubscribe()
+	if _, ok := <-records; ok {
+		t.Error("channel not closed after unsubscribe")
+	}
+}
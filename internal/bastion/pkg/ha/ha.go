@@ -0,0 +1,146 @@
+// Package ha lets two bastions run side by side on a node, one active and
+// one on standby, so a crash or an upgrade does not leave container launches
+// waiting for a restart.
+//
+// The active bastion holds an exclusive lock on a file next to its state.
+// The standby blocks on the same lock before it touches the firewall or
+// loads any state, then takes over the moment the lock is released, which
+// the kernel does however the active bastion exits. It loads the chain and
+// network pool state the active one left on disk and starts serving on the
+// same addresses; runners retry their calls in the meantime.
+package ha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	LockFileEnv = "BASTION_HA_LOCK_FILE"
+
+	// pollInterval is how often a standby tries the lock
+	pollInterval = time.Second
+
+	lockDirPermissions  = 0700
+	lockFilePermissions = 0600
+)
+
+// LockFileFromEnv reads BASTION_HA_LOCK_FILE, the lock both bastions share.
+// It must be on the same filesystem as their state files; empty turns
+// standby mode off.
+func LockFileFromEnv() string {
+	return os.Getenv(LockFileEnv)
+}
+
+// ErrLocked is returned by TryAcquire when another process holds the lock
+var ErrLocked = errors.New("lock is held by another process")
+
+// Lease is the active bastion's hold on the lock
+type Lease struct {
+	file *os.File
+}
+
+// Acquire blocks until the lock at path is held, calling waiting once if
+// another bastion holds it first. It gives up when ctx is done.
+func Acquire(ctx context.Context, path string, waiting func(holder string)) (*Lease, error) {
+	file, err := openLockFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	notified := false
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		if !notified && waiting != nil {
+			waiting(holder(path))
+			notified = true
+		}
+
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return newLease(file), nil
+}
+
+// TryAcquire takes the lock at path if it is free, and otherwise fails at
+// once with ErrLocked, naming the holder
+func TryAcquire(path string) (*Lease, error) {
+	file, err := openLockFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%w: %s (held by %s)", ErrLocked, path, holder(path))
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return newLease(file), nil
+}
+
+func openLockFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), lockDirPermissions); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, lockFilePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	return file, nil
+}
+
+// newLease names the holder in the locked file for whoever finds it taken
+func newLease(file *os.File) *Lease {
+	hostname, _ := os.Hostname()
+	info := hostname + " pid " + strconv.Itoa(os.Getpid()) + " since " + time.Now().UTC().Format(time.RFC3339) + "\n"
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(info), 0)
+	}
+
+	return &Lease{file: file}
+}
+
+// holder returns what the active bastion wrote into the lock file
+func holder(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Release gives up the lock so a standby can take over. Exiting releases it too.
+func (l *Lease) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
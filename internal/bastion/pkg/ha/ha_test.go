@@ -0,0 +1,89 @@
+package ha
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireWaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ha", "bastion.lock")
+	ctx := context.Background()
+
+	active, err := Acquire(ctx, path, nil)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	waited := make(chan string, 1)
+	acquired := make(chan *Lease, 1)
+	go func() {
+		lease, err := Acquire(ctx, path, func(holder string) { waited <- holder })
+		if err != nil {
+			t.Errorf("standby Acquire() error = %v", err)
+		}
+		acquired <- lease
+	}()
+
+	select {
+	case holder := <-waited:
+		if holder == "" || holder == "unknown" {
+			t.Errorf("standby was told the holder is %q", holder)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("standby did not report waiting")
+	}
+	select {
+	case <-acquired:
+		t.Fatal("standby acquired the lock while it was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := active.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	select {
+	case standby := <-acquired:
+		standby.Release()
+	case <-time.After(5 * time.Second):
+		t.Fatal("standby did not take over after release")
+	}
+}
+
+func TestAcquireGivesUpWithContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bastion.lock")
+
+	active, err := Acquire(context.Background(), path, nil)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer active.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Acquire(ctx, path, nil); err != context.Canceled {
+		t.Errorf("Acquire() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestTryAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	first, err := TryAcquire(path)
+	if err != nil {
+		t.Fatalf("TryAcquire() error = %v", err)
+	}
+
+	if _, err := TryAcquire(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second TryAcquire() = %v, want ErrLocked", err)
+	}
+
+	first.Release()
+	second, err := TryAcquire(path)
+	if err != nil {
+		t.Fatalf("TryAcquire() after release: %v", err)
+	}
+	second.Release()
+}
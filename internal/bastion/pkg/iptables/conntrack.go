@@ -0,0 +1,67 @@
+package iptables
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+)
+
+// ErrConntrackUnavailable is returned when the conntrack tool is not installed
+var ErrConntrackUnavailable = errors.New("conntrack is not installed")
+
+var conntrackDeleted = regexp.MustCompile(`(\d+) flow entries have been deleted`)
+
+// FlushConntrack deletes the connection tracking entries of a container, both
+// the connections it opened and those made to it. Rules accept established
+// traffic before they look at anything else, so without this a connection
+// the container's policy no longer allows keeps flowing until it closes.
+// After the flush the next packet of each connection is checked against the
+// rules as it stands now. It returns how many entries were deleted.
+func FlushConntrack(ctx context.Context, containerIP string) (int, error) {
+	if _, err := validation.ValidateContainerIP(containerIP); err != nil {
+		return 0, err
+	}
+	if _, err := exec.LookPath("conntrack"); err != nil {
+		return 0, ErrConntrackUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	family := "ipv4"
+	if version, err := detectIPVersion(containerIP); err == nil && version == ipv6 {
+		family = "ipv6"
+	}
+
+	deleted := 0
+	for _, direction := range []string{"--orig-src", "--orig-dst"} {
+		n, err := deleteConntrack(ctx, "-f", family, direction, containerIP)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// deleteConntrack runs conntrack -D and returns the entries it deleted.
+// conntrack exits with an error when nothing matched, which is no failure here.
+func deleteConntrack(ctx context.Context, args ...string) (int, error) {
+	output, err := exec.CommandContext(ctx, "conntrack", append([]string{"-D"}, args...)...).CombinedOutput()
+
+	match := conntrackDeleted.FindSubmatch(output)
+	if match == nil {
+		if err != nil {
+			return 0, fmt.Errorf("conntrack -D %s failed: %w (output: %s)", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+		}
+		return 0, nil
+	}
+	n, _ := strconv.Atoi(string(match[1]))
+	return n, nil
+}
@@ -0,0 +1,289 @@
+package iptables
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// ChainState is what the kernel holds for a container chain. Rules counts the
+// rules of both IP versions, or of the single nftables chain.
+type ChainState struct {
+	Exists bool
+	// Jumped reports whether the container's traffic reaches the chain; it is
+	// only checked when a container IP is given
+	Jumped bool
+	Rules  int
+}
+
+// ListChains returns the names of the container chains in the kernel. Staging
+// chains left by an interrupted ReplaceRules are not included.
+func ListChains(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if useNFTables() {
+		return nftListChains(ctx)
+	}
+
+	seen := make(map[string]struct{})
+	for _, version := range []ipVersion{ipv4, ipv6} {
+		output, err := listRules(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if name, ok := strings.CutPrefix(line, "-N "); ok && validation.ValidateChainName(name) == nil {
+				seen[name] = struct{}{}
+			}
+		}
+	}
+	return sortedNames(seen), nil
+}
+
+// InspectChain reports the state of chainName and, when containerIP is not
+// empty, whether the container's traffic is sent to it
+func InspectChain(ctx context.Context, chainName string, containerIP string) (ChainState, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if useNFTables() {
+		return nftInspectChain(ctx, chainName, containerIP)
+	}
+
+	// A chain missing in either IP version must be rebuilt
+	state := ChainState{Exists: true}
+	for _, version := range []ipVersion{ipv4, ipv6} {
+		output, err := listRules(ctx, version, chainName)
+		if err != nil {
+			state.Exists = false
+			continue
+		}
+		state.Rules += bytes.Count(output, []byte("\n-A "))
+	}
+
+	if containerIP != "" && state.Exists {
+		version, err := detectIPVersion(containerIP)
+		if err != nil {
+			return state, err
+		}
+		state.Jumped = runIPTablesForVersion(ctx, version, "-C", "FORWARD", "-s", containerIP, "-j", chainName) == nil
+	}
+
+	return state, nil
+}
+
+// ExpectedRules returns how many rules InspectChain should count for a chain
+// holding policy, the reply rules of ports and the accepts for peers. Nothing
+// is written to the kernel.
+func ExpectedRules(ctx context.Context, policy *pb.NetworkPolicy, ports []*pb.PortMapping, peers []string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	w := &ruleWriter{logAttempts: policy.LogAttempts, dryRun: true}
+	if useNFTables() {
+		w.nft = &nftBatch{}
+	}
+	if err := writePolicy(ctx, w, policy); err != nil {
+		return 0, err
+	}
+	return w.applied + len(ports) + len(peers), nil
+}
+
+// RepairChain recreates chainName and its FORWARD jump if they are gone, then
+// rebuilds its rules with ReplaceRules
+func RepairChain(ctx context.Context, chainName string, containerIP string, policy *pb.NetworkPolicy, ports []*pb.PortMapping, peers []string) (int, error) {
+	ip, err := validation.ValidateContainerIP(containerIP)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ensureChain(ctx, chainName, ip); err != nil {
+		return 0, err
+	}
+	return ReplaceRules(ctx, chainName, containerIP, policy, ports, peers)
+}
+
+// ensureChain is SetupChain for a chain that may already exist in part
+func ensureChain(ctx context.Context, chainName string, containerIP net.IP) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if useNFTables() {
+		// Every statement of the setup transaction is idempotent
+		return nftSetupChain(ctx, chainName, containerIP)
+	}
+
+	version := ipv4
+	if containerIP.To4() == nil {
+		version = ipv6
+	}
+
+	for _, v := range []ipVersion{ipv4, ipv6} {
+		if _, err := listRules(ctx, v, chainName); err != nil {
+			if err := runIPTablesForVersion(ctx, v, "-N", chainName); err != nil {
+				return err
+			}
+		}
+	}
+
+	// ReplaceRules moves the jump, so one must be there
+	if runIPTablesForVersion(ctx, version, "-C", "FORWARD", "-s", containerIP.String(), "-j", chainName) != nil {
+		return runIPTablesForVersion(ctx, version, "-I", "FORWARD", "1", "-s", containerIP.String(), "-j", chainName)
+	}
+	return nil
+}
+
+// listRules returns the filter table rules in iptables -S form, limited to
+// one chain when given
+func listRules(ctx context.Context, version ipVersion, chain ...string) ([]byte, error) {
+	tool := "iptables"
+	if version == ipv6 {
+		tool = "ip6tables"
+	}
+	args := append([]string{"-S"}, chain...)
+	output, err := exec.CommandContext(ctx, tool, append([]string{"-w", xtablesWait}, args...)...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w: %s", tool, strings.Join(args, " "), err, output)
+	}
+	// Lines are matched on a leading newline, so the first needs one too
+	return append([]byte("\n"), output...), nil
+}
+
+func nftListChains(ctx context.Context) ([]string, error) {
+	output, err := exec.CommandContext(ctx, "nft", "list", "tables", "inet").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("nft list tables failed: %w: %s", err, output)
+	}
+	// No chain has been set up since the tables were last flushed
+	if !bytes.Contains(output, []byte("table inet "+nftTable+"\n")) {
+		return nil, nil
+	}
+
+	output, err = exec.CommandContext(ctx, "nft", "list", "table", "inet", nftTable).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("nft list table failed: %w: %s", err, output)
+	}
+
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "chain "); ok {
+			name := strings.TrimSuffix(rest, " {")
+			if validation.ValidateChainName(name) == nil {
+				seen[name] = struct{}{}
+			}
+		}
+	}
+	return sortedNames(seen), nil
+}
+
+func nftInspectChain(ctx context.Context, chainName string, containerIP string) (ChainState, error) {
+	output, err := exec.CommandContext(ctx, "nft", "list", "chain", "inet", nftTable, chainName).Output()
+	if err != nil {
+		// As with iptables, a chain that cannot be listed is taken to be gone
+		return ChainState{}, nil
+	}
+
+	state := ChainState{Exists: true, Rules: nftCountRules(output)}
+	if containerIP != "" {
+		version, err := detectIPVersion(containerIP)
+		if err != nil {
+			return state, err
+		}
+		element, err := exec.CommandContext(ctx, "nft", "get", "element", "inet", nftTable, nftJumpMap(version),
+			"{", containerIP, "}").Output()
+		state.Jumped = err == nil && bytes.Contains(element, []byte("jump "+chainName+" "))
+	}
+	return state, nil
+}
+
+// nftCountRules counts the rules in nft list chain output
+func nftCountRules(output []byte) int {
+	return len(nftRuleLines(output))
+}
+
+// nftRuleLines returns the rules in nft list chain output, every line that
+// neither opens nor closes a block
+func nftRuleLines(output []byte) []string {
+	var rules []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && line != "}" && !strings.HasSuffix(line, "{") {
+			rules = append(rules, line)
+		}
+	}
+	return rules
+}
+
+func sortedNames(set map[string]struct{}) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// removeJumps deletes every FORWARD rule jumping to chainName, in both IP versions
+func removeJumps(ctx context.Context, chainName string) {
+	for _, version := range []ipVersion{ipv4, ipv6} {
+		output, err := listRules(ctx, version, "FORWARD")
+		if err != nil {
+			continue
+		}
+		for _, rule := range jumpRules(output, chainName) {
+			_ = runIPTablesForVersion(ctx, version, append([]string{"-D"}, rule[1:]...)...)
+		}
+	}
+}
+
+// jumpRules returns the rules in iptables -S output that jump to chainName,
+// split into arguments
+func jumpRules(output []byte, chainName string) [][]string {
+	var rules [][]string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "-A ") && strings.HasSuffix(line, " -j "+chainName) {
+			rules = append(rules, strings.Fields(line))
+		}
+	}
+	return rules
+}
+
+// nftRemoveJumps deletes every jump map element leading to chainName
+func nftRemoveJumps(ctx context.Context, chainName string) {
+	var b nftBatch
+	for _, version := range []ipVersion{ipv4, ipv6} {
+		output, err := exec.CommandContext(ctx, "nft", "list", "map", "inet", nftTable, nftJumpMap(version)).Output()
+		if err != nil {
+			continue
+		}
+		for _, ip := range nftJumpKeys(output, chainName) {
+			b.add("delete element inet %s %s { %s }", nftTable, nftJumpMap(version), ip)
+		}
+	}
+	if b.Len() > 0 {
+		_ = runNFT(ctx, b.String())
+	}
+}
+
+// nftJumpKeys returns the IPs a listed jump map sends to chainName
+func nftJumpKeys(output []byte, chainName string) []string {
+	pattern := regexp.MustCompile(`([0-9A-Fa-f.:]+) : jump ` + regexp.QuoteMeta(chainName) + `(?:[,\s]|$)`)
+	var keys []string
+	for _, match := range pattern.FindAllSubmatch(output, -1) {
+		keys = append(keys, string(match[1]))
+	}
+	return keys
+}
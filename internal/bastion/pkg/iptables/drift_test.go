@@ -0,0 +1,94 @@
+package iptables
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestExpectedRules(t *testing.T) {
+	ctx := context.Background()
+	policy := &pb.NetworkPolicy{Policy: "deny", AllowDns: true, BlockMetadata: true}
+
+	base, err := ExpectedRules(ctx, policy, nil, nil)
+	if err != nil {
+		t.Fatalf("ExpectedRules() error = %v", err)
+	}
+
+	// The dry run counts what ApplyRules would write
+	w := &ruleWriter{chainName: "ISO-0123456789abcdef", dryRun: true}
+	if useNFTables() {
+		w.nft = &nftBatch{}
+	}
+	if err := writePolicy(ctx, w, policy); err != nil {
+		t.Fatal(err)
+	}
+	if base != w.applied {
+		t.Errorf("ExpectedRules() = %d, want %d", base, w.applied)
+	}
+
+	ports := []*pb.PortMapping{{ContainerPort: 80, HostPort: 30080, Protocol: "tcp"}}
+	got, err := ExpectedRules(ctx, policy, ports, []string{"10.20.0.2", "10.20.1.2"})
+	if err != nil {
+		t.Fatalf("ExpectedRules() error = %v", err)
+	}
+	if got != base+3 {
+		t.Errorf("ExpectedRules() with a port and two peers = %d, want %d", got, base+3)
+	}
+
+	logged := &pb.NetworkPolicy{Policy: "deny", AllowDns: true, BlockMetadata: true, LogAttempts: true}
+	got, err = ExpectedRules(ctx, logged, nil, nil)
+	if err != nil {
+		t.Fatalf("ExpectedRules() error = %v", err)
+	}
+	if got != 2*base {
+		t.Errorf("ExpectedRules() with flow logs = %d, want %d", got, 2*base)
+	}
+}
+
+func TestNFTCountRules(t *testing.T) {
+	output := []byte(`table inet holopod {
+	chain ISO-0123456789abcdef {
+		ip daddr 172.17.0.0/16 drop
+		meta l4proto udp udp dport 53 accept
+		meta nfproto ipv4 drop
+	}
+}
+`)
+	if got := nftCountRules(output); got != 3 {
+		t.Errorf("nftCountRules() = %d, want 3", got)
+	}
+	if got := nftCountRules([]byte("table inet holopod {\n\tchain ISO-0123456789abcdef {\n\t}\n}\n")); got != 0 {
+		t.Errorf("nftCountRules() of an empty chain = %d, want 0", got)
+	}
+}
+
+func TestJumpRules(t *testing.T) {
+	output := []byte(`-P FORWARD DROP
+-A FORWARD -s 10.20.0.2/32 -j ISO-aaaaaaaaaaaaaaaa
+-A FORWARD -s 10.20.1.2/32 -j ISO-aaaaaaaaaaaaaaaa-next
+-A FORWARD -j DOCKER-USER
+`)
+	rules := jumpRules(output, "ISO-aaaaaaaaaaaaaaaa")
+	want := "-A FORWARD -s 10.20.0.2/32 -j ISO-aaaaaaaaaaaaaaaa"
+	if len(rules) != 1 || strings.Join(rules[0], " ") != want {
+		t.Errorf("jumpRules() = %v, want [%s]", rules, want)
+	}
+}
+
+func TestNFTJumpKeys(t *testing.T) {
+	output := []byte(`table inet holopod {
+	map jumps4 {
+		type ipv4_addr : verdict
+		elements = { 10.20.0.2 : jump ISO-aaaaaaaaaaaaaaaa, 10.20.1.2 : jump ISO-bbbbbbbbbbbbbbbb,
+			     10.20.2.2 : jump ISO-aaaaaaaaaaaaaaaa }
+	}
+}
+`)
+	keys := nftJumpKeys(output, "ISO-aaaaaaaaaaaaaaaa")
+	if strings.Join(keys, " ") != "10.20.0.2 10.20.2.2" {
+		t.Errorf("nftJumpKeys() = %v, want [10.20.0.2 10.20.2.2]", keys)
+	}
+}
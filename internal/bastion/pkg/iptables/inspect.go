@@ -0,0 +1,80 @@
+package iptables
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rule is one rule of a container chain as the kernel holds it
+type Rule struct {
+	// Family is "ipv4" or "ipv6", or "inet" for the nftables backend
+	Family string
+	// Spec is the rule in iptables -S form, or as nft lists it
+	Spec    string
+	Packets uint64
+	Bytes   uint64
+	// Counted is false when the backend keeps no counters for the rule, as
+	// the nftables rules are written without counter statements
+	Counted bool
+}
+
+// ListRules returns the rules of chainName in the order the kernel evaluates
+// them, IPv4 before IPv6, with their packet and byte counters
+func ListRules(ctx context.Context, chainName string) ([]Rule, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if useNFTables() {
+		output, err := exec.CommandContext(ctx, "nft", "list", "chain", "inet", nftTable, chainName).CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("nft list chain %s failed: %w: %s", chainName, err, output)
+		}
+		var rules []Rule
+		for _, line := range nftRuleLines(output) {
+			rules = append(rules, Rule{Family: "inet", Spec: line})
+		}
+		return rules, nil
+	}
+
+	var rules []Rule
+	for _, version := range []ipVersion{ipv4, ipv6} {
+		output, err := listRules(ctx, version, chainName, "-v")
+		if err != nil {
+			return nil, err
+		}
+		family := "ipv4"
+		if version == ipv6 {
+			family = "ipv6"
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if !strings.HasPrefix(line, "-A ") {
+				continue
+			}
+			rule := parseCountedRule(line)
+			rule.Family = family
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// counterPattern matches the "-c packets bytes" iptables -S -v adds to a
+// rule, wherever the iptables version puts it
+var counterPattern = regexp.MustCompile(` -c ([0-9]+) ([0-9]+)(?: |$)`)
+
+// parseCountedRule splits a line of iptables -S -v output into the rule and
+// its counters. The rest of the line is kept as it is, quoting included.
+func parseCountedRule(line string) Rule {
+	match := counterPattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		return Rule{Spec: line}
+	}
+	packets, _ := strconv.ParseUint(line[match[2]:match[3]], 10, 64)
+	bytes, _ := strconv.ParseUint(line[match[4]:match[5]], 10, 64)
+	spec := strings.TrimSpace(line[:match[0]] + " " + line[match[1]:])
+	return Rule{Spec: spec, Packets: packets, Bytes: bytes, Counted: true}
+}
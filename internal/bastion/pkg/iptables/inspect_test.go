@@ -0,0 +1,40 @@
+package iptables
+
+import "testing"
+
+func TestParseCountedRule(t *testing.T) {
+	tests := []struct {
+		line string
+		want Rule
+	}{
+		// iptables-legacy puts the counters after the chain
+		{"-A ISO-abc -c 12 3400 -d 10.0.0.0/8 -j ACCEPT",
+			Rule{Spec: "-A ISO-abc -d 10.0.0.0/8 -j ACCEPT", Packets: 12, Bytes: 3400, Counted: true}},
+		// iptables-nft at the end
+		{"-A ISO-abc -j DROP -c 0 0",
+			Rule{Spec: "-A ISO-abc -j DROP", Counted: true}},
+		{`-A ISO-abc -c 5 60 -m limit --limit 20/second -j LOG --log-prefix "ISO-abc:D "`,
+			Rule{Spec: `-A ISO-abc -m limit --limit 20/second -j LOG --log-prefix "ISO-abc:D "`, Packets: 5, Bytes: 60, Counted: true}},
+		{"-A ISO-abc -j DROP", Rule{Spec: "-A ISO-abc -j DROP"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseCountedRule(tt.line); got != tt.want {
+			t.Errorf("parseCountedRule(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestNFTRuleLines(t *testing.T) {
+	output := []byte(`table inet holopod {
+	chain ISO-abc {
+		ip daddr 10.0.0.0/8 accept
+		drop
+	}
+}
+`)
+	got := nftRuleLines(output)
+	if len(got) != 2 || got[0] != "ip daddr 10.0.0.0/8 accept" || got[1] != "drop" {
+		t.Errorf("nftRuleLines() = %q", got)
+	}
+}
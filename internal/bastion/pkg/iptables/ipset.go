@@ -0,0 +1,223 @@
+package iptables
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// DefaultIPSetThreshold is the number of whitelist or blacklist entries above
+// which they are matched through ipsets rather than one rule per entry
+const DefaultIPSetThreshold = 32
+
+// ipsetThreshold is read once; tests may override it
+var ipsetThreshold = IPSetThresholdFromEnv()
+
+// IPSetThresholdFromEnv reads BASTION_IPSET_THRESHOLD, falling back to the
+// default when it is unset or invalid. 0 disables ipsets.
+func IPSetThresholdFromEnv() int {
+	if v, err := strconv.Atoi(os.Getenv("BASTION_IPSET_THRESHOLD")); err == nil && v >= 0 {
+		return v
+	}
+	return DefaultIPSetThreshold
+}
+
+// ipsetAvailable reports whether the ipset tool is installed. Without it large
+// lists still work, one rule per entry.
+var ipsetAvailable = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("ipset")
+	return err == nil
+})
+
+// ipsetKind is one of the sets a chain may own. Its suffix is appended to the
+// chain name, so a set's name follows its chain through ReplaceRules.
+type ipsetKind struct {
+	suffix  string
+	setType string
+	family  string
+	version ipVersion
+	// match is the --match-set direction for the set's dimensions
+	match string
+}
+
+var ipsetKinds = []ipsetKind{
+	{"-n4", "hash:net", "inet", ipv4, "dst"},
+	{"-n6", "hash:net", "inet6", ipv6, "dst"},
+	{"-p4", "hash:net,port", "inet", ipv4, "dst,dst"},
+	{"-p6", "hash:net,port", "inet6", ipv6, "dst,dst"},
+}
+
+// ipsetEntries holds the members of each kind of set, keyed by suffix
+type ipsetEntries map[string][]string
+
+// groupIPSetEntries sorts rules into set members. Rules a set cannot hold
+// (hash:net rejects a /0 prefix) are returned to be applied one by one.
+func groupIPSetEntries(rules []*pb.NetworkRule) (ipsetEntries, []*pb.NetworkRule, error) {
+	entries := make(ipsetEntries)
+	var individual []*pb.NetworkRule
+
+	for _, rule := range rules {
+		ipNet, err := validation.ValidateCIDR(rule.Cidr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if ones, _ := ipNet.Mask.Size(); ones == 0 {
+			individual = append(individual, rule)
+			continue
+		}
+
+		version, err := detectIPVersion(rule.Cidr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Normalise to the network address, as ipset stores it
+		cidr := ipNet.String()
+
+		if len(rule.Ports) == 0 {
+			suffix := "-n4"
+			if version == ipv6 {
+				suffix = "-n6"
+			}
+			entries[suffix] = append(entries[suffix], cidr)
+			continue
+		}
+
+		suffix := "-p4"
+		if version == ipv6 {
+			suffix = "-p6"
+		}
+		for _, port := range rule.Ports {
+			if err := validation.ValidatePort(port); err != nil {
+				return nil, nil, err
+			}
+			for _, proto := range []string{"tcp", "udp"} {
+				entries[suffix] = append(entries[suffix], fmt.Sprintf("%s,%s:%d", cidr, proto, port))
+			}
+		}
+	}
+
+	return entries, individual, nil
+}
+
+// ipsetScript returns the ipset restore input that (re)creates setName holding
+// members. An existing set of the same name is emptied first.
+func ipsetScript(setName string, kind ipsetKind, members []string) string {
+	var b strings.Builder
+	maxElem := 65536
+	if len(members) > maxElem {
+		maxElem = len(members)
+	}
+	fmt.Fprintf(&b, "create %s %s family %s maxelem %d -exist\n", setName, kind.setType, kind.family, maxElem)
+	fmt.Fprintf(&b, "flush %s\n", setName)
+	for _, member := range members {
+		fmt.Fprintf(&b, "add %s %s -exist\n", setName, member)
+	}
+	return b.String()
+}
+
+// applyNetworkRules applies a whitelist or blacklist to the writer's chain. Past
+// the ipset threshold, entries are loaded into sets owned by the chain in a
+// single ipset call and each set is matched by one rule.
+func applyNetworkRules(ctx context.Context, w *ruleWriter, rules []*pb.NetworkRule, action string) error {
+	// nftables loads the rules in one transaction, so it has no call to save
+	if ipsetThreshold == 0 || len(rules) <= ipsetThreshold || w.nft != nil || !ipsetAvailable() {
+		for _, rule := range rules {
+			if err := applyNetworkRule(ctx, w, rule, action); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	entries, individual, err := groupIPSetEntries(rules)
+	if err != nil {
+		return err
+	}
+
+	var script strings.Builder
+	var kinds []ipsetKind
+	for _, kind := range ipsetKinds {
+		if len(entries[kind.suffix]) == 0 {
+			continue
+		}
+		script.WriteString(ipsetScript(w.chainName+kind.suffix, kind, entries[kind.suffix]))
+		kinds = append(kinds, kind)
+	}
+
+	if len(kinds) > 0 && w.dryRun {
+		// ipset restore reads the same lines ipset takes as arguments
+		for _, line := range strings.Split(strings.TrimSuffix(script.String(), "\n"), "\n") {
+			w.plan = append(w.plan, "ipset "+line)
+		}
+	} else if len(kinds) > 0 {
+		if err := runIPSet(ctx, script.String(), "restore"); err != nil {
+			return err
+		}
+	}
+
+	for _, kind := range kinds {
+		w.sets = append(w.sets, kind)
+		match := []string{"-m", "set", "--match-set", w.chainName + kind.suffix, kind.match}
+		if err := w.add(ctx, kind.version, match, action); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range individual {
+		if err := applyNetworkRule(ctx, w, rule, action); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// takeOverSets gives the sets built for the staging chain the names of the
+// live chain's, after the live chain has been dropped. Sets are swapped rather
+// than renamed because the kernel refuses to rename a set a rule references.
+func takeOverSets(ctx context.Context, staging string, chainName string, kinds []ipsetKind) error {
+	for _, kind := range kinds {
+		// An empty set stands in for one the old policy did not use
+		if err := runIPSet(ctx, ipsetScript(chainName+kind.suffix, kind, nil), "restore"); err != nil {
+			return err
+		}
+		if err := runIPSet(ctx, "", "swap", staging+kind.suffix, chainName+kind.suffix); err != nil {
+			return err
+		}
+		_ = runIPSet(ctx, "", "destroy", staging+kind.suffix)
+	}
+	return nil
+}
+
+// destroySets removes every set owned by chainName. Sets still referenced by a
+// rule, or that do not exist, are left alone.
+func destroySets(ctx context.Context, chainName string) {
+	if !ipsetAvailable() {
+		return
+	}
+	for _, kind := range ipsetKinds {
+		_ = runIPSet(ctx, "", "destroy", chainName+kind.suffix)
+	}
+}
+
+// runIPSet executes an ipset command, feeding it input on stdin when non-empty
+func runIPSet(ctx context.Context, input string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "ipset", args...)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ipset %s failed: %w: %s", strings.Join(args, " "), err, output)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package iptables
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestIPSetThresholdFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"", DefaultIPSetThreshold},
+		{"100", 100},
+		{"0", 0},
+		{"-1", DefaultIPSetThreshold},
+		{"many", DefaultIPSetThreshold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("BASTION_IPSET_THRESHOLD", tt.value)
+			if got := IPSetThresholdFromEnv(); got != tt.want {
+				t.Errorf("IPSetThresholdFromEnv() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupIPSetEntries(t *testing.T) {
+	rules := []*pb.NetworkRule{
+		{Cidr: "8.8.8.8/32"},
+		{Cidr: "1.2.3.4/24"},
+		{Cidr: "2001:db8::/32"},
+		{Cidr: "9.9.9.9/32", Ports: []uint32{443}},
+		{Cidr: "2001:db8::1/128", Ports: []uint32{53}},
+		{Cidr: "0.0.0.0/0", Ports: []uint32{80}},
+	}
+
+	entries, individual, err := groupIPSetEntries(rules)
+	if err != nil {
+		t.Fatalf("groupIPSetEntries() error = %v", err)
+	}
+
+	want := ipsetEntries{
+		"-n4": {"8.8.8.8/32", "1.2.3.0/24"},
+		"-n6": {"2001:db8::/32"},
+		"-p4": {"9.9.9.9/32,tcp:443", "9.9.9.9/32,udp:443"},
+		"-p6": {"2001:db8::1/128,tcp:53", "2001:db8::1/128,udp:53"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("groupIPSetEntries() entries = %v, want %v", entries, want)
+	}
+	if len(individual) != 1 || individual[0].Cidr != "0.0.0.0/0" {
+		t.Errorf("expected the /0 rule to be applied individually, got %v", individual)
+	}
+
+	if _, _, err := groupIPSetEntries([]*pb.NetworkRule{{Cidr: "not-a-cidr"}}); err == nil {
+		t.Error("expected error for invalid CIDR")
+	}
+	if _, _, err := groupIPSetEntries([]*pb.NetworkRule{{Cidr: "8.8.8.8/32", Ports: []uint32{0}}}); err == nil {
+		t.Error("expected error for invalid port")
+	}
+}
+
+func TestIPSetScript(t *testing.T) {
+	script := ipsetScript("ISO-0123456789abcdef-p4", ipsetKinds[2], []string{"9.9.9.9/32,tcp:443"})
+
+	want := "create ISO-0123456789abcdef-p4 hash:net,port family inet maxelem 65536 -exist\n" +
+		"flush ISO-0123456789abcdef-p4\n" +
+		"add ISO-0123456789abcdef-p4 9.9.9.9/32,tcp:443 -exist\n"
+	if script != want {
+		t.Errorf("ipsetScript() =\n%s\nwant\n%s", script, want)
+	}
+}
+
+func TestApplyRulesWithIPSets(t *testing.T) {
+	requireRoot(t)
+	if !ipsetAvailable() {
+		t.Skip("skipping test; requires ipset")
+	}
+
+	ctx := context.Background()
+	chainName := "ISO-test6789012345ab"
+	containerIP := net.ParseIP("172.17.0.8")
+
+	if err := SetupChain(ctx, chainName, containerIP); err != nil {
+		t.Fatalf("SetupChain() error = %v", err)
+	}
+	defer CleanupChain(ctx, chainName, containerIP.String())
+
+	whitelist := make([]*pb.NetworkRule, 0, ipsetThreshold+1)
+	for i := 0; i <= ipsetThreshold; i++ {
+		whitelist = append(whitelist, &pb.NetworkRule{Cidr: fmt.Sprintf("203.0.%d.0/24", i)})
+	}
+	policy := &pb.NetworkPolicy{Policy: "deny", Whitelist: whitelist}
+
+	count, err := ApplyRules(ctx, chainName, policy)
+	if err != nil {
+		t.Fatalf("ApplyRules() error = %v", err)
+	}
+	if count >= len(whitelist) {
+		t.Errorf("expected the whitelist to collapse into a set match, got %d rules", count)
+	}
+
+	output, err := exec.CommandContext(ctx, "ipset", "list", chainName+"-n4").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to list set: %v: %s", err, output)
+	}
+	if !strings.Contains(string(output), "203.0.0.0/24") {
+		t.Errorf("expected whitelist entries in set:\n%s", output)
+	}
+
+	// A replace swaps new entries into the same set name
+	policy.Whitelist[0].Cidr = "198.51.100.0/24"
+	if _, err := ReplaceRules(ctx, chainName, containerIP.String(), policy, nil, nil); err != nil {
+		t.Fatalf("ReplaceRules() error = %v", err)
+	}
+	output, _ = exec.CommandContext(ctx, "ipset", "list", chainName+"-n4").CombinedOutput()
+	if !strings.Contains(string(output), "198.51.100.0/24") || strings.Contains(string(output), "203.0.0.0/24") {
+		t.Errorf("expected replaced entries in set:\n%s", output)
+	}
+	if err := exec.CommandContext(ctx, "ipset", "list", chainName+stagingSuffix+"-n4").Run(); err == nil {
+		t.Error("staging set survived ReplaceRules")
+	}
+
+	CleanupChain(ctx, chainName, containerIP.String())
+	if err := exec.CommandContext(ctx, "ipset", "list", chainName+"-n4").Run(); err == nil {
+		t.Error("set survived CleanupChain")
+	}
+}
@@ -10,12 +10,21 @@ import (
 	"strings"
 	"time"
 
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/flowlog"
 	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
 	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
 )
 
 const defaultTimeout = 30 * time.Second
 
+// xtablesWait is how many seconds iptables waits for the xtables lock held by
+// another process, such as Docker, instead of failing at once
+const xtablesWait = "10"
+
+// stagingSuffix names the chain ReplaceRules builds new rules in before it
+// takes over the live chain
+const stagingSuffix = "-next"
+
 // ipVersion represents the IP version (IPv4 or IPv6) for iptables rules
 type ipVersion int
 
@@ -24,7 +33,8 @@ const (
 	ipv6 ipVersion = 6
 )
 
-// CheckIPTables verifies that both iptables (IPv4) and ip6tables (IPv6) are available
+// CheckIPTables verifies that both iptables (IPv4) and ip6tables (IPv6) are
+// available with their restore tools, and nft too when it is the backend
 func CheckIPTables(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -51,6 +61,18 @@ func CheckIPTables(ctx context.Context) error {
 		return fmt.Errorf("unexpected ip6tables version output: %s", output6)
 	}
 
+	// Policies are loaded in one call per IP version
+	for _, tool := range []string{"iptables-restore", "ip6tables-restore"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("%s not available: %w", tool, err)
+		}
+	}
+
+	// Port publishing stays in iptables, so the checks above apply to both backends
+	if useNFTables() {
+		return checkNFT(ctx)
+	}
+
 	return nil
 }
 
@@ -61,6 +83,10 @@ func SetupChain(ctx context.Context, chainName string, containerIP net.IP) error
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 
+	if useNFTables() {
+		return nftSetupChain(ctx, chainName, containerIP)
+	}
+
 	// Determine container's IP version
 	containerVersion := ipv4
 	if containerIP.To4() == nil {
@@ -98,116 +124,276 @@ func ApplyRules(ctx context.Context, chainName string, policy *pb.NetworkPolicy)
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 
-	if err := validation.ValidatePolicyMode(policy.Policy); err != nil {
+	if err := validatePolicy(policy); err != nil {
+		return 0, err
+	}
+
+	if useNFTables() {
+		return nftApplyRules(ctx, chainName, policy)
+	}
+
+	w := &ruleWriter{chainName: chainName, logAttempts: policy.LogAttempts, restore: &restoreBatch{}}
+	if err := writePolicy(ctx, w, policy); err != nil {
+		return 0, err
+	}
+	if err := w.restore.apply(ctx); err != nil {
+		return 0, err
+	}
+	return w.applied, nil
+}
+
+// PlanRules returns the commands ApplyRules would run to write policy to
+// chainName, without running them. iptables rules are listed one command each,
+// though ApplyRules loads them in one iptables-restore per IP version; ipset
+// commands come first, as the rules match the sets.
+func PlanRules(ctx context.Context, chainName string, policy *pb.NetworkPolicy) ([]string, error) {
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	w := &ruleWriter{chainName: chainName, logAttempts: policy.LogAttempts, dryRun: true}
+	if useNFTables() {
+		w.nft = &nftBatch{}
+	} else {
+		w.restore = &restoreBatch{}
+	}
+	if err := writePolicy(ctx, w, policy); err != nil {
+		return nil, err
+	}
+
+	commands := w.plan
+	if w.nft != nil {
+		for _, line := range strings.Split(strings.TrimSuffix(w.nft.String(), "\n"), "\n") {
+			if line != "" {
+				commands = append(commands, "nft "+line)
+			}
+		}
+		return commands, nil
+	}
+	for _, line := range w.restore.v4 {
+		commands = append(commands, "iptables "+line)
+	}
+	for _, line := range w.restore.v6 {
+		commands = append(commands, "ip6tables "+line)
+	}
+	return commands, nil
+}
+
+// ReplaceRules atomically swaps the rules of a chain created by SetupChain for
+// those of policy. The new rules are built in a staging chain, the container's
+// FORWARD jump is moved to it, and it then takes over the original name, so
+// traffic always meets either the old or the new rule set in full. Reply rules
+// for the published ports and accepts for the pod group peers are carried over.
+// On error the live chain is untouched.
+func ReplaceRules(ctx context.Context, chainName string, containerIP string, policy *pb.NetworkPolicy, ports []*pb.PortMapping, peers []string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if err := validatePolicy(policy); err != nil {
+		return 0, err
+	}
+
+	if useNFTables() {
+		return nftReplaceRules(ctx, chainName, policy, ports, peers)
+	}
+
+	version, err := detectIPVersion(containerIP)
+	if err != nil {
+		return 0, err
+	}
+
+	staging := chainName + stagingSuffix
+
+	// A previous update may have been interrupted before the rename
+	dropChain(ctx, staging)
+
+	if err := runIPTables(ctx, "-N", staging); err != nil {
+		return 0, err
+	}
+	if err := runIP6Tables(ctx, "-N", staging); err != nil {
+		_ = runIPTables(ctx, "-X", staging)
+		return 0, err
+	}
+
+	// Flow logs must keep naming the live chain, which staging becomes
+	w := &ruleWriter{chainName: staging, flowChain: chainName, logAttempts: policy.LogAttempts, restore: &restoreBatch{}}
+	if err := writePolicy(ctx, w, policy); err != nil {
+		dropChain(ctx, staging)
 		return 0, err
 	}
 
-	rulesApplied := 0
+	// Port replies must pass ahead of the policy, as ExposePort inserts them
+	for _, mapping := range ports {
+		rule := replyRule(staging, mapping)
+		w.restore.add(version, append([]string{"-I", rule[2], "1"}, rule[3:]...)...)
+		w.applied++
+	}
+
+	for _, peerIP := range peers {
+		version, err := detectIPVersion(peerIP)
+		if err != nil {
+			dropChain(ctx, staging)
+			return 0, err
+		}
+		rule := peerRule(staging, peerIP)
+		w.restore.add(version, append([]string{"-I", rule[0], "1"}, rule[1:]...)...)
+		w.applied++
+	}
+
+	if err := w.restore.apply(ctx); err != nil {
+		dropChain(ctx, staging)
+		return 0, err
+	}
+
+	// Jump to the new rules ahead of the old, then remove the old jump
+	if err := runIPTablesForVersion(ctx, version, "-I", "FORWARD", "1", "-s", containerIP, "-j", staging); err != nil {
+		dropChain(ctx, staging)
+		return w.applied, err
+	}
+	if err := runIPTablesForVersion(ctx, version, "-D", "FORWARD", "-s", containerIP, "-j", chainName); err != nil {
+		_ = runIPTablesForVersion(ctx, version, "-D", "FORWARD", "-s", containerIP, "-j", staging)
+		dropChain(ctx, staging)
+		return w.applied, err
+	}
+
+	// Nothing references the old chain any more; the FORWARD jump follows the rename
+	dropChain(ctx, chainName)
+	if err := runIPTables(ctx, "-E", staging, chainName); err != nil {
+		return w.applied, err
+	}
+	if err := runIP6Tables(ctx, "-E", staging, chainName); err != nil {
+		return w.applied, err
+	}
+	if err := takeOverSets(ctx, staging, chainName, w.sets); err != nil {
+		return w.applied, err
+	}
+
+	return w.applied, nil
+}
+
+// validatePolicy checks the parts of policy that are not validated as rules are written
+func validatePolicy(policy *pb.NetworkPolicy) error {
+	if err := validation.ValidatePolicyMode(policy.Policy); err != nil {
+		return err
+	}
+
+	if policy.PodGroup != "" {
+		if err := validation.ValidatePodGroup(policy.PodGroup); err != nil {
+			return err
+		}
+	}
+
+	// With a domain allowlist, DNS is only reachable through the bastion's filter
+	if len(policy.DnsAllowedDomains) > 0 {
+		if !policy.AllowDns {
+			return validation.ValidationError{
+				Field:   "dns_allowed_domains",
+				Message: "domain allowlist requires allow_dns",
+			}
+		}
+		for _, domain := range policy.DnsAllowedDomains {
+			if err := validation.ValidateDomainPattern(domain); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writePolicy appends the rules of policy to the writer's chain
+func writePolicy(ctx context.Context, w *ruleWriter, policy *pb.NetworkPolicy) error {
+	dnsFiltered := len(policy.DnsAllowedDomains) > 0
 
 	// Always block cross-container communication on the default Docker bridge subnet(s).
-	// This enforces isolation even when user policy would otherwise allow it.
+	// This enforces isolation even when user policy would otherwise allow it; only
+	// pod group peers, accepted ahead of these rules, are let through.
 	for _, subnet := range dockerBridgeSubnets(ctx) {
 		version, err := detectIPVersion(subnet)
 		if err != nil {
 			continue
 		}
-		if err := runIPTablesForVersion(ctx, version, "-A", chainName, "-d", subnet, "-j", "DROP"); err != nil {
-			return rulesApplied, err
+		if err := w.add(ctx, version, []string{"-d", subnet}, "DROP"); err != nil {
+			return err
 		}
-		rulesApplied++
 	}
 
-	// Apply metadata and security blocking rules for IPv4
-	if policy.BlockMetadata {
-		ipv4Rules := [][]string{}
-		// Allow Docker embedded DNS (127.0.0.11) when DNS is enabled.
-		if policy.AllowDns {
-			for _, proto := range []string{"udp", "tcp"} {
-				ipv4Rules = append(ipv4Rules, []string{"-A", chainName, "-d", "127.0.0.11/32", "-p", proto, "--dport", "53", "-j", "ACCEPT"})
-			}
-		}
-		ipv4Rules = append(ipv4Rules, [][]string{
-			{"-A", chainName, "-d", "169.254.169.254", "-j", "DROP"},         // AWS/GCP/Azure metadata
-			{"-A", chainName, "-d", "168.63.129.16", "-j", "DROP"},           // Azure metadata
-			{"-A", chainName, "-d", "100.100.100.200", "-j", "DROP"},         // Alibaba metadata
-			{"-A", chainName, "-d", "169.254.0.0/16", "-j", "DROP"},          // Link-local
-			{"-A", chainName, "-d", "127.0.0.0/8", "-j", "DROP"},             // Localhost
-			{"-A", chainName, "-p", "udp", "--dport", "67:68", "-j", "DROP"}, // DHCP
-		}...)
-		for _, rule := range ipv4Rules {
-			if err := runIPTables(ctx, rule...); err != nil {
-				return rulesApplied, err
-			}
-			rulesApplied++
+	// Limit concurrent connections before any ACCEPT rule can short-circuit the chain
+	if policy.MaxConnections != nil {
+		if err := applyConnLimit(ctx, w, *policy.MaxConnections); err != nil {
+			return err
 		}
+	}
 
-		// Apply IPv6 security blocking rules
-		ipv6Rules := [][]string{
-			{"-A", chainName, "-d", "::1/128", "-j", "DROP"},   // IPv6 localhost
-			{"-A", chainName, "-d", "fe80::/10", "-j", "DROP"}, // IPv6 link-local
-			{"-A", chainName, "-d", "ff00::/8", "-j", "DROP"},  // IPv6 multicast
+	// Apply metadata and security blocking rules
+	if policy.BlockMetadata {
+		ipv4Rules, ipv6Rules := mandatoryBlockRules(policy.AllowDns)
+		for _, r := range ipv4Rules {
+			if err := w.add(ctx, ipv4, r.match, r.target); err != nil {
+				return err
+			}
 		}
-		for _, rule := range ipv6Rules {
-			if err := runIP6Tables(ctx, rule...); err != nil {
-				return rulesApplied, err
+		for _, r := range ipv6Rules {
+			if err := w.add(ctx, ipv6, r.match, r.target); err != nil {
+				return err
 			}
-			rulesApplied++
 		}
 	}
 
 	// Apply DNS rules for both IPv4 and IPv6
-	if policy.AllowDns {
+	if dnsFiltered {
+		// The filter is on the host, so its traffic never reaches this FORWARD chain.
+		// Drop direct queries to any other resolver, ahead of whitelist rules.
+		for _, proto := range []string{"udp", "tcp"} {
+			if err := w.add(ctx, ipv4, []string{"-p", proto, "--dport", "53"}, "DROP"); err != nil {
+				return err
+			}
+
+			if err := w.add(ctx, ipv6, []string{"-p", proto, "--dport", "53"}, "DROP"); err != nil {
+				return err
+			}
+		}
+	} else if policy.AllowDns {
 		// Allow DNS queries on UDP/TCP port 53 for both IPv4 and IPv6
 		for _, proto := range []string{"udp", "tcp"} {
-			if err := runIPTables(ctx, "-A", chainName, "-p", proto, "--dport", "53", "-j", "ACCEPT"); err != nil {
-				return rulesApplied, err
+			if err := w.add(ctx, ipv4, []string{"-p", proto, "--dport", "53"}, "ACCEPT"); err != nil {
+				return err
 			}
-			rulesApplied++
 
-			if err := runIP6Tables(ctx, "-A", chainName, "-p", proto, "--dport", "53", "-j", "ACCEPT"); err != nil {
-				return rulesApplied, err
+			if err := w.add(ctx, ipv6, []string{"-p", proto, "--dport", "53"}, "ACCEPT"); err != nil {
+				return err
 			}
-			rulesApplied++
 		}
 
 		// Allow specific DNS servers if configured
 		for _, dns := range policy.DnsServers {
 			if _, err := validation.ValidateDNSServer(dns); err != nil {
-				return rulesApplied, err
+				return err
 			}
 
 			// Detect IP version and apply to correct chain
 			version, err := detectIPVersion(dns)
 			if err != nil {
-				return rulesApplied, err
+				return err
 			}
 
 			for _, proto := range []string{"udp", "tcp"} {
-				if err := runIPTablesForVersion(ctx, version, "-A", chainName, "-d", dns, "-p", proto, "--dport", "53", "-j", "ACCEPT"); err != nil {
-					return rulesApplied, err
+				if err := w.add(ctx, version, []string{"-d", dns, "-p", proto, "--dport", "53"}, "ACCEPT"); err != nil {
+					return err
 				}
-				rulesApplied++
 			}
 		}
 	}
 
 	if policy.Policy == "deny" && len(policy.Whitelist) > 0 {
-		for _, rule := range policy.Whitelist {
-			count, err := applyNetworkRule(ctx, chainName, rule, "ACCEPT")
-			if err != nil {
-				return rulesApplied, err
-			}
-			rulesApplied += count
+		if err := applyNetworkRules(ctx, w, policy.Whitelist, "ACCEPT"); err != nil {
+			return err
 		}
 	}
 
 	if policy.Policy == "allow" && len(policy.Blacklist) > 0 {
-		for _, rule := range policy.Blacklist {
-			count, err := applyNetworkRule(ctx, chainName, rule, "DROP")
-			if err != nil {
-				return rulesApplied, err
-			}
-			rulesApplied += count
+		if err := applyNetworkRules(ctx, w, policy.Blacklist, "DROP"); err != nil {
+			return err
 		}
 	}
 
@@ -218,60 +404,202 @@ func ApplyRules(ctx context.Context, chainName string, policy *pb.NetworkPolicy)
 	}
 
 	// Apply default policy to IPv4 chain
-	if err := runIPTables(ctx, "-A", chainName, "-j", action); err != nil {
-		return rulesApplied, err
+	if err := w.add(ctx, ipv4, nil, action); err != nil {
+		return err
 	}
-	rulesApplied++
 
 	// Apply default policy to IPv6 chain
-	if err := runIP6Tables(ctx, "-A", chainName, "-j", action); err != nil {
-		return rulesApplied, err
+	if err := w.add(ctx, ipv6, nil, action); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// mandatoryBlockRules returns the IPv4 and IPv6 rules installed when metadata
+// blocking is on: cloud metadata, host-local ranges, and the link-local
+// discovery and autoconfiguration protocols a container could use to probe or
+// impersonate its neighbours (DHCP, mDNS, LLMNR, router advertisements).
+func mandatoryBlockRules(allowDNS bool) ([]chainRule, []chainRule) {
+	ipv4Rules := []chainRule{}
+	// Allow Docker embedded DNS (127.0.0.11) when DNS is enabled.
+	if allowDNS {
+		for _, proto := range []string{"udp", "tcp"} {
+			ipv4Rules = append(ipv4Rules, chainRule{[]string{"-d", "127.0.0.11/32", "-p", proto, "--dport", "53"}, "ACCEPT"})
+		}
+	}
+	ipv4Rules = append(ipv4Rules, []chainRule{
+		{[]string{"-d", "169.254.169.254"}, "DROP"},         // AWS/GCP/Azure metadata
+		{[]string{"-d", "168.63.129.16"}, "DROP"},           // Azure metadata
+		{[]string{"-d", "100.100.100.200"}, "DROP"},         // Alibaba metadata
+		{[]string{"-d", "169.254.0.0/16"}, "DROP"},          // Link-local
+		{[]string{"-d", "127.0.0.0/8"}, "DROP"},             // Localhost
+		{[]string{"-p", "udp", "--dport", "67:68"}, "DROP"}, // DHCP
+		{[]string{"-d", "224.0.0.251"}, "DROP"},             // mDNS group
+		{[]string{"-p", "udp", "--dport", "5353"}, "DROP"},  // mDNS, including unicast queries
+		{[]string{"-d", "224.0.0.252"}, "DROP"},             // LLMNR group
+		{[]string{"-p", "udp", "--dport", "5355"}, "DROP"},  // LLMNR
+		{[]string{"-p", "tcp", "--dport", "5355"}, "DROP"},  // LLMNR over TCP
+	}...)
+
+	ipv6Rules := []chainRule{
+		{[]string{"-d", "::1/128"}, "DROP"},   // IPv6 localhost
+		{[]string{"-d", "fe80::/10"}, "DROP"}, // IPv6 link-local
+		{[]string{"-d", "ff02::fb"}, "DROP"},  // mDNS group
+		{[]string{"-d", "ff02::1:3"}, "DROP"}, // LLMNR group
+		{[]string{"-d", "ff02::1:2"}, "DROP"}, // DHCPv6 relay agents and servers
+		{[]string{"-d", "ff05::1:3"}, "DROP"}, // DHCPv6 servers
+		{[]string{"-d", "ff00::/8"}, "DROP"},  // IPv6 multicast
+
+		// Unicast discovery and rogue autoconfiguration
+		{[]string{"-p", "udp", "--dport", "5353"}, "DROP"},    // mDNS
+		{[]string{"-p", "udp", "--dport", "5355"}, "DROP"},    // LLMNR
+		{[]string{"-p", "tcp", "--dport", "5355"}, "DROP"},    // LLMNR over TCP
+		{[]string{"-p", "udp", "--dport", "546:547"}, "DROP"}, // DHCPv6
+		{[]string{"-p", "udp", "--sport", "547"}, "DROP"},     // DHCPv6 server replies
+		{[]string{"-p", "ipv6-icmp", "--icmpv6-type", "router-advertisement"}, "DROP"},
+		{[]string{"-p", "ipv6-icmp", "--icmpv6-type", "redirect"}, "DROP"},
+	}
+
+	return ipv4Rules, ipv6Rules
+}
+
+// chainRule is a match plus the target it jumps to
+type chainRule struct {
+	match  []string
+	target string
+}
+
+// ruleWriter appends rules to a chain, counting them and optionally mirroring
+// each verdict with a rate-limited LOG rule for flow collection. Flow logs name
+// flowChain when set, for rules built in a chain that will be renamed. sets
+// records the ipsets created for the chain. With restore or nft set, rules are
+// collected in the batch, translated for nft, rather than run one by one. With
+// dryRun set, nothing is run: rules go only to the batch, or are only counted
+// without one, and ipset commands are kept in plan.
+type ruleWriter struct {
+	chainName   string
+	flowChain   string
+	logAttempts bool
+	applied     int
+	sets        []ipsetKind
+	restore     *restoreBatch
+	nft         *nftBatch
+	dryRun      bool
+	plan        []string
+}
+
+// flowLogLimit caps LOG entries per rule so a busy workload cannot flood the kernel log
+const flowLogLimit = "20/second"
+
+// add appends match -j target (plus any target options) to the chain for the given IP version
+func (w *ruleWriter) add(ctx context.Context, version ipVersion, match []string, target string, targetOpts ...string) error {
+	if w.logAttempts {
+		verdict := flowlog.VerdictDeny
+		if target == "ACCEPT" {
+			verdict = flowlog.VerdictAllow
+		}
+
+		flowChain := w.chainName
+		if w.flowChain != "" {
+			flowChain = w.flowChain
+		}
+
+		logMatch := append(match[:len(match):len(match)],
+			"-m", "conntrack", "--ctstate", "NEW",
+			"-m", "limit", "--limit", flowLogLimit)
+		if err := w.append(ctx, version, logMatch, "LOG", "--log-prefix", flowlog.Prefix(flowChain, verdict)); err != nil {
+			return err
+		}
 	}
-	rulesApplied++
 
-	return rulesApplied, nil
+	return w.append(ctx, version, match, target, targetOpts...)
+}
+
+// append adds a single rule to the end of the chain
+func (w *ruleWriter) append(ctx context.Context, version ipVersion, match []string, target string, targetOpts ...string) error {
+	if w.dryRun && w.nft == nil && w.restore == nil {
+		w.applied++
+		return nil
+	}
+
+	if w.nft != nil {
+		rule, err := nftRule(version, match, target, targetOpts)
+		if err != nil {
+			return err
+		}
+		w.nft.add("add rule inet %s %s %s", nftTable, w.chainName, rule)
+		w.applied++
+		return nil
+	}
+
+	args := append([]string{"-A", w.chainName}, match...)
+	args = append(args, "-j", target)
+	args = append(args, targetOpts...)
+	if w.restore != nil {
+		w.restore.add(version, args...)
+		w.applied++
+		return nil
+	}
+	if err := runIPTablesForVersion(ctx, version, args...); err != nil {
+		return err
+	}
+	w.applied++
+
+	return nil
+}
+
+// applyConnLimit rejects new TCP connections once the container has more than max open.
+// The chain only sees traffic from a single container, so a mask of 0 counts all of it together.
+func applyConnLimit(ctx context.Context, w *ruleWriter, max uint32) error {
+	if err := validation.ValidateMaxConnections(max); err != nil {
+		return err
+	}
+
+	match := []string{"-p", "tcp", "--syn", "-m", "connlimit", "--connlimit-above", fmt.Sprintf("%d", max), "--connlimit-mask", "0"}
+	for _, version := range []ipVersion{ipv4, ipv6} {
+		if err := w.add(ctx, version, match, "REJECT", "--reject-with", "tcp-reset"); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // applyNetworkRule applies a network rule (whitelist/blacklist) to the appropriate iptables chain.
 // It automatically detects IPv4 vs IPv6 and uses the correct iptables command.
-func applyNetworkRule(ctx context.Context, chainName string, rule *pb.NetworkRule, action string) (int, error) {
+func applyNetworkRule(ctx context.Context, w *ruleWriter, rule *pb.NetworkRule, action string) error {
 	if _, err := validation.ValidateCIDR(rule.Cidr); err != nil {
-		return 0, err
+		return err
 	}
 
 	// Detect IP version (IPv4 or IPv6)
 	version, err := detectIPVersion(rule.Cidr)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	rulesApplied := 0
-
 	// Apply rule without port restrictions
 	if len(rule.Ports) == 0 {
-		if err := runIPTablesForVersion(ctx, version, "-A", chainName, "-d", rule.Cidr, "-j", action); err != nil {
-			return rulesApplied, err
+		return w.add(ctx, version, []string{"-d", rule.Cidr}, action)
+	}
+
+	// Apply rule with port restrictions
+	for _, port := range rule.Ports {
+		if err := validation.ValidatePort(port); err != nil {
+			return err
 		}
-		rulesApplied++
-	} else {
-		// Apply rule with port restrictions
-		for _, port := range rule.Ports {
-			if err := validation.ValidatePort(port); err != nil {
-				return rulesApplied, err
-			}
 
-			portStr := fmt.Sprintf("%d", port)
-			// Apply for both TCP and UDP protocols
-			for _, proto := range []string{"tcp", "udp"} {
-				if err := runIPTablesForVersion(ctx, version, "-A", chainName, "-d", rule.Cidr, "-p", proto, "--dport", portStr, "-j", action); err != nil {
-					return rulesApplied, err
-				}
-				rulesApplied++
+		portStr := fmt.Sprintf("%d", port)
+		// Apply for both TCP and UDP protocols
+		for _, proto := range []string{"tcp", "udp"} {
+			if err := w.add(ctx, version, []string{"-d", rule.Cidr, "-p", proto, "--dport", portStr}, action); err != nil {
+				return err
 			}
 		}
 	}
 
-	return rulesApplied, nil
+	return nil
 }
 
 // CleanupChain removes iptables chains for both IPv4 and IPv6.
@@ -280,6 +608,11 @@ func CleanupChain(ctx context.Context, chainName string, containerIP string) err
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 
+	if useNFTables() {
+		nftCleanupChain(ctx, chainName, containerIP)
+		return nil
+	}
+
 	// Determine IP version if containerIP is provided
 	var version ipVersion = ipv4
 	if containerIP != "" {
@@ -288,18 +621,31 @@ func CleanupChain(ctx context.Context, chainName string, containerIP string) err
 			version = detectedVersion
 		}
 
-		// Remove FORWARD rule
+		// Remove FORWARD rules, including one left by an interrupted ReplaceRules
 		_ = runIPTablesForVersion(ctx, version, "-D", "FORWARD", "-s", containerIP, "-j", chainName)
+		_ = runIPTablesForVersion(ctx, version, "-D", "FORWARD", "-s", containerIP, "-j", chainName+stagingSuffix)
+	} else {
+		// A chain whose owner is unknown can only be unhooked by finding its jumps
+		removeJumps(ctx, chainName)
+		removeJumps(ctx, chainName+stagingSuffix)
 	}
 
 	// Cleanup both IPv4 and IPv6 chains (one will likely fail, which is fine)
 	// This ensures we clean up chains even if we don't know the container IP version
+	dropChain(ctx, chainName)
+	dropChain(ctx, chainName+stagingSuffix)
+
+	return nil
+}
+
+// dropChain flushes and deletes an unreferenced chain in both iptables and
+// ip6tables, along with its ipsets, ignoring any that do not exist
+func dropChain(ctx context.Context, chainName string) {
 	_ = runIPTables(ctx, "-F", chainName)
 	_ = runIPTables(ctx, "-X", chainName)
 	_ = runIP6Tables(ctx, "-F", chainName)
 	_ = runIP6Tables(ctx, "-X", chainName)
-
-	return nil
+	destroySets(ctx, chainName)
 }
 
 // detectIPVersion determines if a CIDR or IP address is IPv4 or IPv6
@@ -326,7 +672,7 @@ func detectIPVersion(cidr string) (ipVersion, error) {
 
 // runIPTables executes an iptables command (IPv4)
 func runIPTables(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "iptables", args...)
+	cmd := exec.CommandContext(ctx, "iptables", append([]string{"-w", xtablesWait}, args...)...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("iptables %s failed: %w: %s", strings.Join(args, " "), err, output)
@@ -336,7 +682,7 @@ func runIPTables(ctx context.Context, args ...string) error {
 
 // runIP6Tables executes an ip6tables command (IPv6)
 func runIP6Tables(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "ip6tables", args...)
+	cmd := exec.CommandContext(ctx, "ip6tables", append([]string{"-w", xtablesWait}, args...)...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("ip6tables %s failed: %w: %s", strings.Join(args, " "), err, output)
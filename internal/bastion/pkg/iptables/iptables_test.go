@@ -5,9 +5,11 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 
 	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestCheckIPTables(t *testing.T) {
@@ -77,11 +79,12 @@ func TestApplyNetworkRule(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			runIPTables(ctx, "-F", chainName)
 
-			count, err := applyNetworkRule(ctx, chainName, tt.rule, tt.action)
+			w := &ruleWriter{chainName: chainName}
+			err := applyNetworkRule(ctx, w, tt.rule, tt.action)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("applyNetworkRule() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if !tt.wantErr && count == 0 {
+			if !tt.wantErr && w.applied == 0 {
 				t.Error("applyNetworkRule() returned 0 rules applied")
 			}
 		})
@@ -153,6 +156,42 @@ func TestApplyRules(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "deny with connection limit",
+			policy: &pb.NetworkPolicy{
+				Policy:         "deny",
+				BlockMetadata:  true,
+				MaxConnections: proto.Uint32(64),
+			},
+			wantErr: false,
+		},
+		{
+			name: "deny with dns allowlist",
+			policy: &pb.NetworkPolicy{
+				Policy:            "deny",
+				BlockMetadata:     true,
+				AllowDns:          true,
+				DnsAllowedDomains: []string{"example.com", "*.example.org"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "dns allowlist without allow_dns",
+			policy: &pb.NetworkPolicy{
+				Policy:            "deny",
+				DnsAllowedDomains: []string{"example.com"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid dns allowlist entry",
+			policy: &pb.NetworkPolicy{
+				Policy:            "deny",
+				AllowDns:          true,
+				DnsAllowedDomains: []string{"api.*.example.com"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid policy mode",
 			policy: &pb.NetworkPolicy{
@@ -177,6 +216,120 @@ func TestApplyRules(t *testing.T) {
 	}
 }
 
+func TestMandatoryBlockRules(t *testing.T) {
+	ipv4Rules, ipv6Rules := mandatoryBlockRules(false)
+
+	tests := []struct {
+		name  string
+		rules []chainRule
+		match string
+	}{
+		{"AWS metadata", ipv4Rules, "-d 169.254.169.254"},
+		{"Azure metadata", ipv4Rules, "-d 168.63.129.16"},
+		{"DHCP", ipv4Rules, "-p udp --dport 67:68"},
+		{"mDNS group", ipv4Rules, "-d 224.0.0.251"},
+		{"mDNS port", ipv4Rules, "-p udp --dport 5353"},
+		{"LLMNR group", ipv4Rules, "-d 224.0.0.252"},
+		{"LLMNR port", ipv4Rules, "-p udp --dport 5355"},
+		{"LLMNR over TCP", ipv4Rules, "-p tcp --dport 5355"},
+		{"IPv6 mDNS group", ipv6Rules, "-d ff02::fb"},
+		{"IPv6 mDNS port", ipv6Rules, "-p udp --dport 5353"},
+		{"IPv6 LLMNR group", ipv6Rules, "-d ff02::1:3"},
+		{"DHCPv6 servers", ipv6Rules, "-d ff02::1:2"},
+		{"DHCPv6 ports", ipv6Rules, "-p udp --dport 546:547"},
+		{"DHCPv6 server replies", ipv6Rules, "-p udp --sport 547"},
+		{"router advertisements", ipv6Rules, "-p ipv6-icmp --icmpv6-type router-advertisement"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range tt.rules {
+				if strings.Join(r.match, " ") == tt.match {
+					if r.target != "DROP" {
+						t.Errorf("rule %q jumps to %s, want DROP", tt.match, r.target)
+					}
+					return
+				}
+			}
+			t.Errorf("no mandatory block rule %q", tt.match)
+		})
+	}
+
+	// Docker's embedded resolver is only reachable when DNS is allowed
+	for _, allowDNS := range []bool{false, true} {
+		ipv4Rules, _ := mandatoryBlockRules(allowDNS)
+		accepted := false
+		for _, r := range ipv4Rules {
+			if r.target == "ACCEPT" {
+				accepted = true
+			}
+		}
+		if accepted != allowDNS {
+			t.Errorf("mandatoryBlockRules(%v) accepts embedded DNS = %v", allowDNS, accepted)
+		}
+	}
+}
+
+func TestReplaceRules(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("skipping test; requires root")
+	}
+
+	ctx := context.Background()
+	chainName := "ISO-test5678901234ab"
+	containerIP := net.ParseIP("172.17.0.6")
+
+	if err := SetupChain(ctx, chainName, containerIP); err != nil {
+		t.Fatalf("SetupChain() error = %v", err)
+	}
+	defer CleanupChain(ctx, chainName, containerIP.String())
+
+	if _, err := ApplyRules(ctx, chainName, &pb.NetworkPolicy{Policy: "deny"}); err != nil {
+		t.Fatalf("ApplyRules() error = %v", err)
+	}
+
+	policy := &pb.NetworkPolicy{
+		Policy:    "deny",
+		Whitelist: []*pb.NetworkRule{{Cidr: "1.1.1.1/32", Ports: []uint32{443}}},
+	}
+	ports := []*pb.PortMapping{{ContainerPort: 8080, HostPort: 30080, Protocol: "tcp"}}
+
+	count, err := ReplaceRules(ctx, chainName, containerIP.String(), policy, ports, []string{"172.17.0.7"})
+	if err != nil {
+		t.Fatalf("ReplaceRules() error = %v", err)
+	}
+	if count == 0 {
+		t.Error("ReplaceRules() returned 0 rules applied")
+	}
+
+	output, err := exec.CommandContext(ctx, "iptables", "-S", chainName).CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to list chain: %v", err)
+	}
+	for _, want := range []string{"1.1.1.1/32", "--sport 8080", "-d 172.17.0.7/32"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("expected %q in replaced chain:\n%s", want, output)
+		}
+	}
+
+	forward, err := exec.CommandContext(ctx, "iptables", "-S", "FORWARD").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to list FORWARD: %v", err)
+	}
+	if !strings.Contains(string(forward), "-j "+chainName) || strings.Contains(string(forward), chainName+stagingSuffix) {
+		t.Errorf("expected FORWARD to jump to %s only:\n%s", chainName, forward)
+	}
+
+	// An invalid policy leaves the live rules in place
+	if _, err := ReplaceRules(ctx, chainName, containerIP.String(), &pb.NetworkPolicy{Policy: "invalid"}, nil, nil); err == nil {
+		t.Error("expected error for invalid policy")
+	}
+	after, _ := exec.CommandContext(ctx, "iptables", "-S", chainName).CombinedOutput()
+	if string(after) != string(output) {
+		t.Errorf("chain changed after failed replace:\n%s", after)
+	}
+}
+
 func TestCleanupChain(t *testing.T) {
 	if os.Getuid() != 0 {
 		t.Skip("skipping test; requires root")
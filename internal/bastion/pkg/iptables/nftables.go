@@ -0,0 +1,379 @@
+package iptables
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// Container chains are built with either iptables or nftables. With nftables
+// every chain lives in the inet table nftTable, reached from a forward chain
+// of its own through a verdict map keyed on the container IP, and a policy is
+// loaded as one transaction. Port publishing keeps its DNAT and FORWARD rules
+// in iptables, where Docker's own chains are.
+const (
+	BackendIPTables = "iptables"
+	BackendNFTables = "nftables"
+)
+
+// nftTable holds every chain the nftables backend creates
+const nftTable = "holopod"
+
+// backend is read once; tests may override it
+var backend = sync.OnceValue(BackendFromEnv)
+
+// Backend returns the backend container chains are built with
+func Backend() string {
+	return backend()
+}
+
+func useNFTables() bool {
+	return backend() == BackendNFTables
+}
+
+// BackendFromEnv reads BASTION_FIREWALL_BACKEND. When it is unset or neither
+// "iptables" nor "nftables", nftables is used if nft is installed and
+// iptables is itself the nf_tables shim, whose translation of iptables rules
+// is where the surprises are; otherwise iptables.
+func BackendFromEnv() string {
+	switch v := os.Getenv("BASTION_FIREWALL_BACKEND"); v {
+	case BackendIPTables, BackendNFTables:
+		return v
+	}
+
+	if _, err := exec.LookPath("nft"); err != nil {
+		return BackendIPTables
+	}
+	output, err := exec.Command("iptables", "--version").CombinedOutput()
+	if err == nil && bytes.Contains(output, []byte("nf_tables")) {
+		return BackendNFTables
+	}
+	return BackendIPTables
+}
+
+// checkNFT verifies that the nft tool is available
+func checkNFT(ctx context.Context) error {
+	output, err := exec.CommandContext(ctx, "nft", "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("nft not available: %w", err)
+	}
+	if !bytes.Contains(output, []byte("nftables")) {
+		return fmt.Errorf("unexpected nft version output: %s", output)
+	}
+	return nil
+}
+
+// nftBatch collects nft commands to run as one transaction
+type nftBatch struct {
+	strings.Builder
+}
+
+func (b *nftBatch) add(format string, args ...any) {
+	fmt.Fprintf(b, format+"\n", args...)
+}
+
+// addBase (re)creates the table, the maps of container IPs to their chains
+// and the forward chain that consults them. The forward chain runs ahead of
+// Docker's filter chains, so a drop here is final.
+func (b *nftBatch) addBase() {
+	b.add("add table inet %s", nftTable)
+	b.add("add map inet %s jumps4 { type ipv4_addr : verdict ; }", nftTable)
+	b.add("add map inet %s jumps6 { type ipv6_addr : verdict ; }", nftTable)
+	b.add("add chain inet %s forward { type filter hook forward priority -1 ; policy accept ; }", nftTable)
+	b.add("flush chain inet %s forward", nftTable)
+	b.add("add rule inet %s forward ip saddr vmap @jumps4", nftTable)
+	b.add("add rule inet %s forward ip6 saddr vmap @jumps6", nftTable)
+}
+
+func nftJumpMap(version ipVersion) string {
+	if version == ipv6 {
+		return "jumps6"
+	}
+	return "jumps4"
+}
+
+func nftSetupChain(ctx context.Context, chainName string, containerIP net.IP) error {
+	version := ipv4
+	if containerIP.To4() == nil {
+		version = ipv6
+	}
+
+	var b nftBatch
+	b.addBase()
+	b.add("add chain inet %s %s", nftTable, chainName)
+	b.add("add element inet %s %s { %s : jump %s }", nftTable, nftJumpMap(version), containerIP, chainName)
+	return runNFT(ctx, b.String())
+}
+
+func nftApplyRules(ctx context.Context, chainName string, policy *pb.NetworkPolicy) (int, error) {
+	w := &ruleWriter{chainName: chainName, logAttempts: policy.LogAttempts, nft: &nftBatch{}}
+	if err := writePolicy(ctx, w, policy); err != nil {
+		return 0, err
+	}
+	if err := runNFT(ctx, w.nft.String()); err != nil {
+		return 0, err
+	}
+	return w.applied, nil
+}
+
+// nftReplaceRules needs no staging chain: the flush and the new rules are one
+// transaction, so traffic meets either the old or the new rules in full
+func nftReplaceRules(ctx context.Context, chainName string, policy *pb.NetworkPolicy, ports []*pb.PortMapping, peers []string) (int, error) {
+	w := &ruleWriter{chainName: chainName, logAttempts: policy.LogAttempts, nft: &nftBatch{}}
+	w.nft.add("flush chain inet %s %s", nftTable, chainName)
+
+	// Port replies and pod group peers go ahead of the policy
+	for _, mapping := range ports {
+		rule, err := nftReplyRule(mapping)
+		if err != nil {
+			return 0, err
+		}
+		w.nft.add("add rule inet %s %s %s", nftTable, chainName, rule)
+		w.applied++
+	}
+	for _, peerIP := range peers {
+		rule, err := nftPeerRule(peerIP)
+		if err != nil {
+			return 0, err
+		}
+		w.nft.add("add rule inet %s %s %s", nftTable, chainName, rule)
+		w.applied++
+	}
+
+	if err := writePolicy(ctx, w, policy); err != nil {
+		return 0, err
+	}
+	if err := runNFT(ctx, w.nft.String()); err != nil {
+		return 0, err
+	}
+	return w.applied, nil
+}
+
+func nftCleanupChain(ctx context.Context, chainName string, containerIP string) {
+	if containerIP != "" {
+		if version, err := detectIPVersion(containerIP); err == nil {
+			_ = runNFT(ctx, fmt.Sprintf("delete element inet %s %s { %s }\n", nftTable, nftJumpMap(version), containerIP))
+		}
+	} else {
+		nftRemoveJumps(ctx, chainName)
+	}
+	_ = runNFT(ctx, fmt.Sprintf("flush chain inet %s %s\ndelete chain inet %s %s\n", nftTable, chainName, nftTable, chainName))
+}
+
+// nftReplyRule is replyRule for the nftables chain. The comment names the host
+// port, so unpublishing one of two mappings of a container port leaves the other.
+func nftReplyRule(mapping *pb.PortMapping) (string, error) {
+	rule := replyRule("", mapping)
+	match, target, opts := splitTarget(rule[3:])
+	match = append(match, "-m", "comment", "--comment", nftReplyComment(mapping))
+	return nftRule(ipv4, match, target, opts)
+}
+
+func nftReplyComment(mapping *pb.PortMapping) string {
+	return fmt.Sprintf("port %d/%s", mapping.HostPort, mapping.Protocol)
+}
+
+// nftPeerRule is peerRule for the nftables chain, commented with the peer so
+// RevokePeer can find it
+func nftPeerRule(peerIP string) (string, error) {
+	version, err := detectIPVersion(peerIP)
+	if err != nil {
+		return "", err
+	}
+	return nftRule(version, []string{"-d", peerIP, "-m", "comment", "--comment", nftPeerComment(peerIP)}, "ACCEPT", nil)
+}
+
+func nftPeerComment(peerIP string) string {
+	return "pod-group " + peerIP
+}
+
+// nftInsertRule adds rule at the top of chainName
+func nftInsertRule(ctx context.Context, chainName string, rule string) error {
+	return runNFT(ctx, fmt.Sprintf("insert rule inet %s %s %s\n", nftTable, chainName, rule))
+}
+
+// splitTarget splits iptables rule arguments at -j
+func splitTarget(args []string) (match []string, target string, opts []string) {
+	for i, arg := range args {
+		if arg == "-j" && i+1 < len(args) {
+			return args[:i:i], args[i+1], args[i+2:]
+		}
+	}
+	return args, "", nil
+}
+
+// nftDeleteRules deletes the rules of chainName carrying comment. A missing
+// chain or rule is ignored.
+func nftDeleteRules(ctx context.Context, chainName string, comment string) {
+	output, err := exec.CommandContext(ctx, "nft", "-a", "list", "chain", "inet", nftTable, chainName).Output()
+	if err != nil {
+		return
+	}
+
+	var b nftBatch
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, fmt.Sprintf("comment %q", comment)) {
+			continue
+		}
+		if _, handle, ok := strings.Cut(line, "# handle "); ok {
+			b.add("delete rule inet %s %s handle %s", nftTable, chainName, strings.TrimSpace(handle))
+		}
+	}
+	if b.Len() > 0 {
+		_ = runNFT(ctx, b.String())
+	}
+}
+
+// nftICMPv6Types maps the ip6tables --icmpv6-type names used by the policy to nft's
+var nftICMPv6Types = map[string]string{
+	"router-advertisement": "nd-router-advert",
+	"redirect":             "nd-redirect",
+}
+
+// nftRule translates the iptables match and target the rule writer produces
+// into an nft rule for the given IP version. Only the matches the bastion
+// itself writes are understood; anything else is an error rather than a rule
+// that silently matches more than intended.
+func nftRule(version ipVersion, match []string, target string, targetOpts []string) (string, error) {
+	family := "ip"
+	if version == ipv6 {
+		family = "ip6"
+	}
+
+	var exprs []string
+	var comment string
+	hasAddress := false
+	proto := ""
+	protoIndex := -1
+	protoUsed := false
+
+	next := func(i int) (string, error) {
+		if i+1 >= len(match) {
+			return "", fmt.Errorf("missing value for %s", match[i])
+		}
+		return match[i+1], nil
+	}
+
+	for i := 0; i < len(match); i++ {
+		arg := match[i]
+		switch arg {
+		case "-s", "-d", "-p", "--dport", "--sport", "-m", "--ctstate", "--limit",
+			"--connlimit-above", "--connlimit-mask", "--comment", "--icmpv6-type":
+			value, err := next(i)
+			if err != nil {
+				return "", err
+			}
+			i++
+
+			switch arg {
+			case "-s":
+				exprs = append(exprs, fmt.Sprintf("%s saddr %s", family, value))
+				hasAddress = true
+			case "-d":
+				exprs = append(exprs, fmt.Sprintf("%s daddr %s", family, value))
+				hasAddress = true
+			case "-p":
+				proto = value
+				protoIndex = len(exprs)
+				exprs = append(exprs, "")
+			case "--dport", "--sport":
+				if proto != "tcp" && proto != "udp" {
+					return "", fmt.Errorf("%s needs -p tcp or udp", arg)
+				}
+				exprs = append(exprs, fmt.Sprintf("%s %s %s", proto, strings.TrimPrefix(arg, "--"), strings.ReplaceAll(value, ":", "-")))
+				protoUsed = true
+			case "-m":
+				// Modules are implied by their options
+			case "--ctstate":
+				exprs = append(exprs, "ct state "+strings.ToLower(value))
+			case "--limit":
+				exprs = append(exprs, "limit rate "+value)
+			case "--connlimit-above":
+				exprs = append(exprs, "ct count over "+value)
+			case "--connlimit-mask":
+				if value != "0" {
+					return "", fmt.Errorf("unsupported connlimit mask %s", value)
+				}
+			case "--comment":
+				comment = value
+			case "--icmpv6-type":
+				name, ok := nftICMPv6Types[value]
+				if !ok {
+					return "", fmt.Errorf("unsupported icmpv6 type %s", value)
+				}
+				exprs = append(exprs, "icmpv6 type "+name)
+				protoUsed = true
+			}
+		case "--syn":
+			if proto != "tcp" {
+				return "", fmt.Errorf("--syn needs -p tcp")
+			}
+			exprs = append(exprs, "tcp flags & (fin|syn|rst|ack) == syn")
+			protoUsed = true
+		default:
+			return "", fmt.Errorf("unsupported iptables match %s", arg)
+		}
+	}
+
+	if protoIndex >= 0 && !protoUsed {
+		exprs[protoIndex] = "meta l4proto " + proto
+	}
+	if !hasAddress {
+		exprs = append([]string{"meta nfproto ipv" + fmt.Sprint(int(version))}, exprs...)
+	}
+
+	switch target {
+	case "ACCEPT":
+		exprs = append(exprs, "accept")
+	case "DROP":
+		exprs = append(exprs, "drop")
+	case "REJECT":
+		if len(targetOpts) == 2 && targetOpts[0] == "--reject-with" && targetOpts[1] == "tcp-reset" {
+			exprs = append(exprs, "reject with tcp reset")
+		} else if len(targetOpts) == 0 {
+			exprs = append(exprs, "reject")
+		} else {
+			return "", fmt.Errorf("unsupported REJECT options %v", targetOpts)
+		}
+	case "LOG":
+		if len(targetOpts) != 2 || targetOpts[0] != "--log-prefix" {
+			return "", fmt.Errorf("unsupported LOG options %v", targetOpts)
+		}
+		exprs = append(exprs, fmt.Sprintf("log prefix %q", targetOpts[1]))
+	default:
+		return "", fmt.Errorf("unsupported target %s", target)
+	}
+
+	if comment != "" {
+		exprs = append(exprs, fmt.Sprintf("comment %q", comment))
+	}
+
+	var nonEmpty []string
+	for _, expr := range exprs {
+		if expr != "" {
+			nonEmpty = append(nonEmpty, expr)
+		}
+	}
+	return strings.Join(nonEmpty, " "), nil
+}
+
+// runNFT runs script as a single nft transaction
+func runNFT(ctx context.Context, script string) error {
+	cmd := exec.CommandContext(ctx, "nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(script)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("nft failed: %w: %s", err, output)
+	}
+	return nil
+}
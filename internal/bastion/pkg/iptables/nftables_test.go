@@ -0,0 +1,119 @@
+package iptables
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBackendFromEnv(t *testing.T) {
+	for _, value := range []string{BackendIPTables, BackendNFTables} {
+		t.Setenv("BASTION_FIREWALL_BACKEND", value)
+		if got := BackendFromEnv(); got != value {
+			t.Errorf("BackendFromEnv() = %s, want %s", got, value)
+		}
+	}
+
+	t.Setenv("BASTION_FIREWALL_BACKEND", "auto")
+	if got := BackendFromEnv(); got != BackendIPTables && got != BackendNFTables {
+		t.Errorf("BackendFromEnv() = %q, want a backend", got)
+	}
+}
+
+func TestNFTRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		version ipVersion
+		match   []string
+		target  string
+		opts    []string
+		want    string
+	}{
+		{"default", ipv4, nil, "DROP", nil, "meta nfproto ipv4 drop"},
+		{"cidr", ipv6, []string{"-d", "fe80::/10"}, "DROP", nil, "ip6 daddr fe80::/10 drop"},
+		{"port range", ipv4, []string{"-p", "udp", "--dport", "67:68"}, "DROP", nil, "meta nfproto ipv4 udp dport 67-68 drop"},
+		{"proto only", ipv4, []string{"-d", "10.0.0.0/8", "-p", "tcp"}, "ACCEPT", nil, "ip daddr 10.0.0.0/8 meta l4proto tcp accept"},
+		{"icmpv6", ipv6, []string{"-p", "ipv6-icmp", "--icmpv6-type", "router-advertisement"}, "DROP", nil,
+			"meta nfproto ipv6 icmpv6 type nd-router-advert drop"},
+		{"connlimit", ipv4, []string{"-p", "tcp", "--syn", "-m", "connlimit", "--connlimit-above", "50", "--connlimit-mask", "0"},
+			"REJECT", []string{"--reject-with", "tcp-reset"},
+			"meta nfproto ipv4 tcp flags & (fin|syn|rst|ack) == syn ct count over 50 reject with tcp reset"},
+		{"flow log", ipv4, []string{"-d", "1.1.1.1", "-m", "conntrack", "--ctstate", "NEW", "-m", "limit", "--limit", "20/second"},
+			"LOG", []string{"--log-prefix", "ISO-abc:A "},
+			`ip daddr 1.1.1.1 ct state new limit rate 20/second log prefix "ISO-abc:A "`},
+		{"comment", ipv4, []string{"-d", "10.0.0.3", "-m", "comment", "--comment", "pod-group 10.0.0.3"}, "ACCEPT", nil,
+			`ip daddr 10.0.0.3 accept comment "pod-group 10.0.0.3"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nftRule(tt.version, tt.match, tt.target, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("nftRule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, match := range [][]string{{"-m", "set", "--match-set", "x", "dst"}, {"--dport", "80"}, {"-d"}} {
+		if _, err := nftRule(ipv4, match, "ACCEPT", nil); err == nil {
+			t.Errorf("expected %v to be rejected", match)
+		}
+	}
+}
+
+func TestWritePolicyTranslatesForNFTables(t *testing.T) {
+	policy := &pb.NetworkPolicy{
+		Policy:         "deny",
+		AllowDns:       true,
+		DnsServers:     []string{"8.8.8.8", "2001:4860:4860::8888"},
+		BlockMetadata:  true,
+		LogAttempts:    true,
+		MaxConnections: proto.Uint32(100),
+		Whitelist: []*pb.NetworkRule{
+			{Cidr: "10.0.0.0/8", Ports: []uint32{443}},
+			{Cidr: "2001:db8::/32"},
+		},
+	}
+
+	w := &ruleWriter{chainName: "ISO-nfttest", logAttempts: true, nft: &nftBatch{}}
+	if err := writePolicy(context.Background(), w, policy); err != nil {
+		t.Fatalf("writePolicy() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.nft.String()), "\n")
+	if len(lines) != w.applied {
+		t.Errorf("batch has %d rules, writer counted %d", len(lines), w.applied)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "add rule inet holopod ISO-nfttest ") {
+			t.Errorf("unexpected batch line %q", line)
+		}
+	}
+	if last := lines[len(lines)-1]; !strings.HasSuffix(last, "meta nfproto ipv6 drop") {
+		t.Errorf("expected the IPv6 default drop last, got %q", last)
+	}
+}
+
+func TestNFTReplyAndPeerRules(t *testing.T) {
+	reply, err := nftReplyRule(&pb.PortMapping{ContainerPort: 8080, HostPort: 30080, Protocol: "tcp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `meta nfproto ipv4 tcp sport 8080 ct state established,related accept comment "port 30080/tcp"`; reply != want {
+		t.Errorf("nftReplyRule() = %q, want %q", reply, want)
+	}
+
+	peer, err := nftPeerRule("fd00::5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `ip6 daddr fd00::5 accept comment "pod-group fd00::5"`; peer != want {
+		t.Errorf("nftPeerRule() = %q, want %q", peer, want)
+	}
+}
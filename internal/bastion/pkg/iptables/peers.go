@@ -0,0 +1,62 @@
+package iptables
+
+import (
+	"context"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+)
+
+// peerRule accepts traffic from the container chain to a pod group peer. It is
+// inserted at the top of the chain, ahead of the cross-container DROP.
+func peerRule(chainName string, peerIP string) []string {
+	return []string{chainName, "-d", peerIP, "-m", "comment", "--comment", "pod-group", "-j", "ACCEPT"}
+}
+
+// AllowPeer lets the container behind chainName reach peerIP, a member of the
+// same pod group. The peer's own chain must allow the reverse direction.
+func AllowPeer(ctx context.Context, chainName string, peerIP string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if err := validation.ValidateChainName(chainName); err != nil {
+		return err
+	}
+	if _, err := validation.ValidateContainerIP(peerIP); err != nil {
+		return err
+	}
+
+	if useNFTables() {
+		rule, err := nftPeerRule(peerIP)
+		if err != nil {
+			return err
+		}
+		return nftInsertRule(ctx, chainName, rule)
+	}
+
+	version, err := detectIPVersion(peerIP)
+	if err != nil {
+		return err
+	}
+
+	rule := peerRule(chainName, peerIP)
+	return runIPTablesForVersion(ctx, version, append([]string{"-I", rule[0], "1"}, rule[1:]...)...)
+}
+
+// RevokePeer removes the rule added by AllowPeer. A missing rule is ignored.
+func RevokePeer(ctx context.Context, chainName string, peerIP string) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if useNFTables() {
+		nftDeleteRules(ctx, chainName, nftPeerComment(peerIP))
+		return
+	}
+
+	version, err := detectIPVersion(peerIP)
+	if err != nil {
+		return
+	}
+
+	rule := peerRule(chainName, peerIP)
+	_ = runIPTablesForVersion(ctx, version, append([]string{"-D", rule[0]}, rule[1:]...)...)
+}
@@ -0,0 +1,120 @@
+package iptables
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// portRules returns the rules publishing mapping.HostPort to the container, each
+// as the arguments to insert it at the top of its chain:
+//   - a DNAT in nat/PREROUTING for traffic addressed to the host
+//   - a FORWARD accept for the translated traffic, ahead of Docker's own rules
+//   - a masquerade in nat/POSTROUTING for the container reaching itself
+//     through the host port, whose replies would otherwise skip the DNAT
+//   - an accept in the container chain so replies pass a deny policy
+//
+// The reply rule is always last.
+func portRules(chainName string, containerIP string, mapping *pb.PortMapping) [][]string {
+	hostPort := fmt.Sprintf("%d", mapping.HostPort)
+	containerPort := fmt.Sprintf("%d", mapping.ContainerPort)
+	dest := net.JoinHostPort(containerIP, containerPort)
+
+	return [][]string{
+		{"-t", "nat", "PREROUTING", "-p", mapping.Protocol, "-m", "addrtype", "--dst-type", "LOCAL",
+			"--dport", hostPort, "-m", "comment", "--comment", chainName, "-j", "DNAT", "--to-destination", dest},
+		{"-t", "filter", "FORWARD", "-d", containerIP, "-p", mapping.Protocol, "--dport", containerPort,
+			"-m", "comment", "--comment", chainName, "-j", "ACCEPT"},
+		{"-t", "nat", "POSTROUTING", "-s", containerIP, "-d", containerIP, "-p", mapping.Protocol, "--dport", containerPort,
+			"-m", "comment", "--comment", chainName, "-j", "MASQUERADE"},
+		replyRule(chainName, mapping),
+	}
+}
+
+// replyRule accepts replies from a published port in the container chain
+func replyRule(chainName string, mapping *pb.PortMapping) []string {
+	return []string{"-t", "filter", chainName, "-p", mapping.Protocol, "--sport", fmt.Sprintf("%d", mapping.ContainerPort),
+		"-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"}
+}
+
+// ExposePort publishes a container port on the host with DNAT. The mapping must
+// name a host port; allocation is left to the caller.
+func ExposePort(ctx context.Context, chainName string, containerIP string, mapping *pb.PortMapping) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if err := validation.ValidateChainName(chainName); err != nil {
+		return err
+	}
+	if _, err := validation.ValidateContainerIP(containerIP); err != nil {
+		return err
+	}
+	if err := validation.ValidatePort(mapping.ContainerPort); err != nil {
+		return err
+	}
+	if err := validation.ValidatePort(mapping.HostPort); err != nil {
+		return err
+	}
+	if err := validation.ValidateProtocol(mapping.Protocol); err != nil {
+		return err
+	}
+
+	// An IPv6 container is published through ip6tables' nat table
+	version, err := detectIPVersion(containerIP)
+	if err != nil {
+		return err
+	}
+
+	rules := portRules(chainName, containerIP, mapping)
+	if useNFTables() {
+		// The reply rule goes in the container chain, which nftables holds
+		rules = rules[:len(rules)-1]
+	}
+	for i, rule := range rules {
+		args := append([]string{rule[0], rule[1], "-I", rule[2], "1"}, rule[3:]...)
+		if err := runIPTablesForVersion(ctx, version, args...); err != nil {
+			deletePortRules(ctx, version, rules[:i])
+			return err
+		}
+	}
+
+	if useNFTables() {
+		rule, err := nftReplyRule(mapping)
+		if err == nil {
+			err = nftInsertRule(ctx, chainName, rule)
+		}
+		if err != nil {
+			deletePortRules(ctx, version, rules)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deletePortRules(ctx context.Context, version ipVersion, rules [][]string) {
+	for _, rule := range rules {
+		_ = runIPTablesForVersion(ctx, version, append([]string{rule[0], rule[1], "-D", rule[2]}, rule[3:]...)...)
+	}
+}
+
+// UnexposePort removes the rules added by ExposePort. Missing rules are ignored.
+func UnexposePort(ctx context.Context, chainName string, containerIP string, mapping *pb.PortMapping) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	version, err := detectIPVersion(containerIP)
+	if err != nil {
+		version = ipv4
+	}
+
+	rules := portRules(chainName, containerIP, mapping)
+	if useNFTables() {
+		nftDeleteRules(ctx, chainName, nftReplyComment(mapping))
+		rules = rules[:len(rules)-1]
+	}
+	deletePortRules(ctx, version, rules)
+}
@@ -0,0 +1,84 @@
+package iptables
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// restoreBatch collects filter table rules to load with a single
+// iptables-restore --noflush per IP version, rather than one process per rule.
+// Each call is a single transaction, so a chain is never seen half written by
+// the kernel or by a concurrent iptables call.
+type restoreBatch struct {
+	v4 []string
+	v6 []string
+}
+
+// add queues a rule, given as the arguments iptables would take
+func (b *restoreBatch) add(version ipVersion, args ...string) {
+	b.addLine(version, restoreLine(args))
+}
+
+// addLine queues a line already in iptables-restore form
+func (b *restoreBatch) addLine(version ipVersion, line string) {
+	if version == ipv6 {
+		b.v6 = append(b.v6, line)
+	} else {
+		b.v4 = append(b.v4, line)
+	}
+}
+
+// apply loads the queued rules, IPv4 first. If IPv6 fails the IPv4 rules stay,
+// as they would have rule by rule; callers drop the chain on error.
+func (b *restoreBatch) apply(ctx context.Context) error {
+	if len(b.v4) > 0 {
+		if err := runRestore(ctx, "iptables-restore", b.v4); err != nil {
+			return err
+		}
+	}
+	if len(b.v6) > 0 {
+		if err := runRestore(ctx, "ip6tables-restore", b.v6); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreLine joins args as iptables-restore reads them, quoting those with
+// whitespace such as flow log prefixes
+func restoreLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			arg = `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// restoreInput wraps rules in the filter table section iptables-restore expects
+func restoreInput(rules []string) string {
+	var b strings.Builder
+	b.WriteString("*filter\n")
+	for _, rule := range rules {
+		b.WriteString(rule)
+		b.WriteByte('\n')
+	}
+	b.WriteString("COMMIT\n")
+	return b.String()
+}
+
+// runRestore runs tool --noflush with rules, leaving every chain it does not
+// name as it is. Like the single rule commands it waits for the xtables lock.
+func runRestore(ctx context.Context, tool string, rules []string) error {
+	cmd := exec.CommandContext(ctx, tool, "--noflush", "-w", xtablesWait)
+	cmd.Stdin = strings.NewReader(restoreInput(rules))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s of %d rules failed: %w: %s", tool, len(rules), err, output)
+	}
+	return nil
+}
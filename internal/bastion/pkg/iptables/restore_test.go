@@ -0,0 +1,91 @@
+package iptables
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestRestoreLine(t *testing.T) {
+	got := restoreLine([]string{"-A", "ISO-abc", "-j", "LOG", "--log-prefix", "ISO-abc:A "})
+	if want := `-A ISO-abc -j LOG --log-prefix "ISO-abc:A "`; got != want {
+		t.Errorf("restoreLine() = %q, want %q", got, want)
+	}
+	if got := restoreLine([]string{"--comment", `say "hi"`}); got != `--comment "say \"hi\""` {
+		t.Errorf("restoreLine() = %q", got)
+	}
+}
+
+func TestWritePolicyBatchesRules(t *testing.T) {
+	policy := &pb.NetworkPolicy{
+		Policy:        "deny",
+		AllowDns:      true,
+		BlockMetadata: true,
+		LogAttempts:   true,
+		Whitelist:     []*pb.NetworkRule{{Cidr: "10.0.0.0/8", Ports: []uint32{443}}, {Cidr: "2001:db8::/32"}},
+	}
+
+	w := &ruleWriter{chainName: "ISO-batchtest", logAttempts: true, restore: &restoreBatch{}}
+	if err := writePolicy(context.Background(), w, policy); err != nil {
+		t.Fatalf("writePolicy() error = %v", err)
+	}
+
+	if len(w.restore.v4)+len(w.restore.v6) != w.applied {
+		t.Errorf("batch has %d rules, writer counted %d", len(w.restore.v4)+len(w.restore.v6), w.applied)
+	}
+	for _, line := range append(w.restore.v4, w.restore.v6...) {
+		if !strings.HasPrefix(line, "-A ISO-batchtest ") {
+			t.Errorf("unexpected rule %q", line)
+		}
+	}
+	if last := w.restore.v6[len(w.restore.v6)-1]; last != "-A ISO-batchtest -j DROP" {
+		t.Errorf("expected the IPv6 default drop last, got %q", last)
+	}
+
+	input := restoreInput(w.restore.v4)
+	if !strings.HasPrefix(input, "*filter\n") || !strings.HasSuffix(input, "\nCOMMIT\n") {
+		t.Errorf("unexpected restore input %q", input)
+	}
+}
+
+func TestPlanRules(t *testing.T) {
+	saved := backend
+	backend = func() string { return BackendIPTables }
+	defer func() { backend = saved }()
+
+	policy := &pb.NetworkPolicy{
+		Policy:    "deny",
+		AllowDns:  true,
+		Whitelist: []*pb.NetworkRule{{Cidr: "10.0.0.0/8", Ports: []uint32{443}}, {Cidr: "2001:db8::/32"}},
+	}
+	commands, err := PlanRules(context.Background(), "ISO-plantest", policy)
+	if err != nil {
+		t.Fatalf("PlanRules() error = %v", err)
+	}
+
+	count, err := ExpectedRules(context.Background(), policy, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != count {
+		t.Errorf("PlanRules() returned %d commands, want one per rule (%d)", len(commands), count)
+	}
+	for _, command := range commands {
+		if !strings.HasPrefix(command, "iptables -A ISO-plantest ") && !strings.HasPrefix(command, "ip6tables -A ISO-plantest ") {
+			t.Errorf("unexpected command %q", command)
+		}
+	}
+	if !slices.Contains(commands, "iptables -A ISO-plantest -d 10.0.0.0/8 -p tcp --dport 443 -j ACCEPT") {
+		t.Errorf("whitelisted port missing from %v", commands)
+	}
+	if last := commands[len(commands)-1]; last != "ip6tables -A ISO-plantest -j DROP" {
+		t.Errorf("expected the IPv6 default drop last, got %q", last)
+	}
+
+	if _, err := PlanRules(context.Background(), "ISO-plantest", &pb.NetworkPolicy{Policy: "maybe"}); err == nil {
+		t.Error("PlanRules() should reject an invalid policy mode")
+	}
+}
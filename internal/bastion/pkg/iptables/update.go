@@ -0,0 +1,181 @@
+package iptables
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// maxDiffCells bounds the table diffRules builds; larger policies are replaced
+// whole, which costs less than the diff would
+const maxDiffCells = 1 << 22
+
+// RuleDiff is what UpdateRules changed
+type RuleDiff struct {
+	Added   int
+	Removed int
+	// Replaced is set when the chain was rebuilt with ReplaceRules instead
+	Replaced bool
+	// Rules counts the rules the chain holds afterwards, as ReplaceRules does
+	Rules int
+}
+
+// UpdateRules turns the rules of oldPolicy in a chain created by SetupChain
+// into those of policy by deleting and inserting only the rules that differ,
+// one iptables-restore per IP version. Rules both policies share are never
+// touched, so their counters and the connections they admit carry on. The
+// reply rules of ports and the accepts for peers at the top of the chain are
+// left as they are.
+//
+// Where the kernel's chain does not hold what the policies say it should, or
+// either policy matches through ipsets, or the backend is nftables, whose
+// replacement is a single transaction already, the chain is rebuilt with
+// ReplaceRules instead. A nil oldPolicy always rebuilds.
+func UpdateRules(ctx context.Context, chainName string, containerIP string, oldPolicy, policy *pb.NetworkPolicy, ports []*pb.PortMapping, peers []string) (RuleDiff, error) {
+	if err := validatePolicy(policy); err != nil {
+		return RuleDiff{}, err
+	}
+
+	replace := func() (RuleDiff, error) {
+		count, err := ReplaceRules(ctx, chainName, containerIP, policy, ports, peers)
+		return RuleDiff{Replaced: true, Rules: count}, err
+	}
+
+	if oldPolicy == nil || useNFTables() {
+		return replace()
+	}
+
+	oldRules, err := planPolicy(ctx, chainName, oldPolicy)
+	if err != nil || len(oldRules.sets) > 0 {
+		return replace()
+	}
+	newRules, err := planPolicy(ctx, chainName, policy)
+	if err != nil {
+		return RuleDiff{}, err
+	}
+	if len(newRules.sets) > 0 {
+		return replace()
+	}
+
+	// Ports and peers sit above the policy, ports in the container's IP version
+	containerVersion, err := detectIPVersion(containerIP)
+	if err != nil {
+		return RuleDiff{}, err
+	}
+	top := map[ipVersion]int{containerVersion: len(ports)}
+	for _, peerIP := range peers {
+		version, err := detectIPVersion(peerIP)
+		if err != nil {
+			return RuleDiff{}, err
+		}
+		top[version]++
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	batch := &restoreBatch{}
+	diff := RuleDiff{Rules: newRules.applied + len(ports) + len(peers)}
+	for _, version := range []ipVersion{ipv4, ipv6} {
+		oldLines, newLines := oldRules.restore.v4, newRules.restore.v4
+		if version == ipv6 {
+			oldLines, newLines = oldRules.restore.v6, newRules.restore.v6
+		}
+
+		output, err := listRules(listCtx, version, chainName)
+		if err != nil {
+			return replace()
+		}
+		if installed := strings.Count(string(output), "\n-A "); installed != top[version]+len(oldLines) {
+			return replace()
+		}
+
+		ops, ok := diffRules(chainName, oldLines, newLines, top[version])
+		if !ok {
+			return replace()
+		}
+		for _, op := range ops {
+			if strings.HasPrefix(op, "-D ") {
+				diff.Removed++
+			} else {
+				diff.Added++
+			}
+			batch.addLine(version, op)
+		}
+	}
+
+	if err := batch.apply(listCtx); err != nil {
+		// IPv4 may already hold the new rules; rebuilding leaves both whole
+		return replace()
+	}
+	return diff, nil
+}
+
+// planPolicy writes policy to a batch without running anything
+func planPolicy(ctx context.Context, chainName string, policy *pb.NetworkPolicy) (*ruleWriter, error) {
+	w := &ruleWriter{chainName: chainName, logAttempts: policy.LogAttempts, restore: &restoreBatch{}, dryRun: true}
+	if err := writePolicy(ctx, w, policy); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// diffRules returns the iptables-restore lines that turn oldLines, the rules
+// of chainName below its first top rules, into newLines. Rules outside the
+// longest common subsequence of the two are deleted by number from the bottom
+// up, then the missing ones inserted in order, so every number refers to the
+// chain as the previous line left it. It reports false when the policies are
+// too large to diff.
+func diffRules(chainName string, oldLines, newLines []string, top int) ([]string, bool) {
+	n, m := len(oldLines), len(newLines)
+	if (n+1)*(m+1) > maxDiffCells {
+		return nil, false
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// oldLines[i:] and newLines[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	keepOld := make([]bool, n)
+	keepNew := make([]bool, m)
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case oldLines[i] == newLines[j]:
+			keepOld[i], keepNew[j] = true, true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	var ops []string
+	for i := n - 1; i >= 0; i-- {
+		if !keepOld[i] {
+			ops = append(ops, fmt.Sprintf("-D %s %d", chainName, top+i+1))
+		}
+	}
+	appendPrefix := "-A " + chainName + " "
+	for j, line := range newLines {
+		if !keepNew[j] {
+			ops = append(ops, fmt.Sprintf("-I %s %d %s", chainName, top+j+1, strings.TrimPrefix(line, appendPrefix)))
+		}
+	}
+	return ops, true
+}
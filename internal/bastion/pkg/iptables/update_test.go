@@ -0,0 +1,68 @@
+package iptables
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// applyOps plays the lines diffRules returns against a chain held as a slice
+func applyOps(t *testing.T, chain []string, ops []string, chainName string) []string {
+	t.Helper()
+	for _, op := range ops {
+		fields := strings.SplitN(op, " ", 4)
+		if len(fields) < 3 || fields[1] != chainName {
+			t.Fatalf("unparseable op %q", op)
+		}
+		pos, err := strconv.Atoi(fields[2])
+		if err != nil {
+			t.Fatalf("unparseable op %q: %v", op, err)
+		}
+		switch fields[0] {
+		case "-D":
+			chain = append(chain[:pos-1], chain[pos:]...)
+		case "-I":
+			chain = append(chain[:pos-1], append([]string{"-A " + chainName + " " + fields[3]}, chain[pos-1:]...)...)
+		default:
+			t.Fatalf("unexpected op %q", op)
+		}
+	}
+	return chain
+}
+
+func TestDiffRules(t *testing.T) {
+	const chain = "ISO-difftest"
+	top := []string{"-A ISO-difftest -d 10.20.0.2 -j ACCEPT"}
+	oldLines := []string{
+		"-A ISO-difftest -d 10.0.0.0/8 -j ACCEPT",
+		"-A ISO-difftest -d 192.168.0.0/16 -j ACCEPT",
+		"-A ISO-difftest -d 172.16.0.0/12 -j DROP",
+		"-A ISO-difftest -j DROP",
+	}
+	newLines := []string{
+		"-A ISO-difftest -d 10.0.0.0/8 -j ACCEPT",
+		"-A ISO-difftest -d 100.64.0.0/10 -j ACCEPT",
+		"-A ISO-difftest -d 172.16.0.0/12 -j DROP",
+		"-A ISO-difftest -p tcp --dport 25 -j DROP",
+		"-A ISO-difftest -j DROP",
+	}
+
+	ops, ok := diffRules(chain, oldLines, newLines, len(top))
+	if !ok {
+		t.Fatal("diffRules() refused a small policy")
+	}
+	if len(ops) != 3 {
+		t.Errorf("diffRules() = %q, want one removal and two insertions", ops)
+	}
+
+	got := applyOps(t, append(append([]string{}, top...), oldLines...), ops, chain)
+	want := append(append([]string{}, top...), newLines...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chain after ops = %q, want %q", got, want)
+	}
+
+	if ops, _ := diffRules(chain, newLines, newLines, 0); len(ops) != 0 {
+		t.Errorf("diffRules() of equal policies = %q, want nothing", ops)
+	}
+}
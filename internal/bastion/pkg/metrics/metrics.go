@@ -0,0 +1,252 @@
+// Package metrics exposes the bastion's counters in the Prometheus text format
+// on /metrics: privileged operations, firewall failures, network pool activity
+// and RPC latencies.
+package metrics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/networkpool"
+)
+
+const namespace = "holopod_bastion"
+
+// AddressEnv names the variable holding the address /metrics is served on,
+// e.g. "127.0.0.1:9464". Unset leaves the endpoint off.
+const AddressEnv = "BASTION_METRICS_ADDRESS"
+
+var (
+	// Registry holds every bastion metric; the default registry is left alone
+	Registry = prometheus.NewRegistry()
+
+	operations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "Privileged operations by outcome, as audit logged.",
+	}, []string{"operation", "result"})
+
+	rulesApplied = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rules_applied_total",
+		Help:      "Firewall rules written to container chains.",
+	})
+
+	firewallFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "firewall_failures_total",
+		Help:      "iptables or nftables calls that failed, by operation.",
+	}, []string{"operation"})
+
+	poolAcquisitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pool_acquisitions_total",
+		Help:      "Network pool acquisitions by result: reused, created or failed.",
+	}, []string{"result"})
+
+	poolReleases = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pool_releases_total",
+		Help:      "Network pool releases by result: pooled, cleaned_up or failed.",
+	}, []string{"result"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Calls rejected for exceeding the per-client rate limit, by method.",
+	}, []string{"method"})
+
+	firewallWaits = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "firewall_wait_seconds",
+		Help:      "Time calls waited for a free firewall worker.",
+		Buckets:   []float64{.001, .01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "gRPC handling time by method and status code.",
+		Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		operations, rulesApplied, firewallFailures, poolAcquisitions, poolReleases, rateLimited, firewallWaits, rpcDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Operation counts a privileged operation
+func Operation(operation string, success bool) {
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+	operations.WithLabelValues(operation, result).Inc()
+}
+
+// RulesApplied counts rules written to a chain
+func RulesApplied(count int) {
+	if count > 0 {
+		rulesApplied.Add(float64(count))
+	}
+}
+
+// FirewallFailure counts a failed iptables or nftables call made for operation
+func FirewallFailure(operation string) {
+	firewallFailures.WithLabelValues(operation).Inc()
+}
+
+// RateLimited counts a call to method rejected by the rate limit
+func RateLimited(method string) {
+	rateLimited.WithLabelValues(method).Inc()
+}
+
+// FirewallWait records how long a call waited for a firewall worker
+func FirewallWait(d time.Duration) {
+	firewallWaits.Observe(d.Seconds())
+}
+
+// PoolAcquired counts a network pool acquisition
+func PoolAcquired(reused bool, err error) {
+	switch {
+	case err != nil:
+		poolAcquisitions.WithLabelValues("failed").Inc()
+	case reused:
+		poolAcquisitions.WithLabelValues("reused").Inc()
+	default:
+		poolAcquisitions.WithLabelValues("created").Inc()
+	}
+}
+
+// PoolReleased counts a network pool release
+func PoolReleased(cleanedUp bool, err error) {
+	switch {
+	case err != nil:
+		poolReleases.WithLabelValues("failed").Inc()
+	case cleanedUp:
+		poolReleases.WithLabelValues("cleaned_up").Inc()
+	default:
+		poolReleases.WithLabelValues("pooled").Inc()
+	}
+}
+
+// UnaryServerInterceptor times unary RPCs
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+// StreamServerInterceptor times streaming RPCs from start to end of stream
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observeRPC(info.FullMethod, start, err)
+	return err
+}
+
+func observeRPC(method string, start time.Time, err error) {
+	rpcDuration.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+}
+
+// RegisterPool exports the state of pool as gauges, read at scrape time
+func RegisterPool(pool *networkpool.Pool) error {
+	return Registry.Register(&poolCollector{pool: pool})
+}
+
+var (
+	poolNetworksDesc = prometheus.NewDesc(namespace+"_pool_networks",
+		"Networks in the pool by state: active, pooled or pending_cleanup.", []string{"state"}, nil)
+	poolUtilizationDesc = prometheus.NewDesc(namespace+"_pool_utilization",
+		"Share of pool networks in use, 0 to 1.", nil, nil)
+	subnetUtilizationDesc = prometheus.NewDesc(namespace+"_subnet_utilization",
+		"Share of allocatable subnets in use, 0 to 1.", nil, nil)
+	maxSubnetsDesc = prometheus.NewDesc(namespace+"_max_subnets",
+		"Subnets the pool can allocate.", nil, nil)
+	poolHealthyDesc = prometheus.NewDesc(namespace+"_pool_healthy",
+		"1 when the network pool reports itself healthy.", nil, nil)
+	cleanupQueueDepthDesc = prometheus.NewDesc(namespace+"_cleanup_queue_depth",
+		"Network removals waiting in the cleanup queue.", nil, nil)
+)
+
+type poolCollector struct {
+	pool *networkpool.Pool
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolNetworksDesc
+	ch <- poolUtilizationDesc
+	ch <- subnetUtilizationDesc
+	ch <- maxSubnetsDesc
+	ch <- poolHealthyDesc
+	ch <- cleanupQueueDepthDesc
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.pool.Stats()
+
+	ch <- prometheus.MustNewConstMetric(poolNetworksDesc, prometheus.GaugeValue, float64(stats.ActiveNetworks), "active")
+	ch <- prometheus.MustNewConstMetric(poolNetworksDesc, prometheus.GaugeValue, float64(stats.PooledNetworks), "pooled")
+	ch <- prometheus.MustNewConstMetric(poolNetworksDesc, prometheus.GaugeValue, float64(stats.PendingCleanup), "pending_cleanup")
+	ch <- prometheus.MustNewConstMetric(poolUtilizationDesc, prometheus.GaugeValue, float64(stats.Utilization))
+	ch <- prometheus.MustNewConstMetric(subnetUtilizationDesc, prometheus.GaugeValue, float64(stats.SubnetUtilization))
+	ch <- prometheus.MustNewConstMetric(maxSubnetsDesc, prometheus.GaugeValue, float64(stats.MaxSubnets))
+	healthy := 0.0
+	if stats.Healthy {
+		healthy = 1
+	}
+	ch <- prometheus.MustNewConstMetric(poolHealthyDesc, prometheus.GaugeValue, healthy)
+	ch <- prometheus.MustNewConstMetric(cleanupQueueDepthDesc, prometheus.GaugeValue, float64(stats.CleanupQueue.Depth))
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// StartServerFromEnv serves /metrics on the address in AddressEnv until ctx is
+// done. It returns "" without serving when the variable is unset.
+func StartServerFromEnv(ctx context.Context) (string, error) {
+	addr := os.Getenv(AddressEnv)
+	if addr == "" {
+		return "", nil
+	}
+	return StartServer(ctx, addr)
+}
+
+// StartServer serves /metrics on addr until ctx is done and returns the
+// address it listens on
+func StartServer(ctx context.Context, addr string) (string, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() { _ = server.Serve(lis) }()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	return lis.Addr().String(), nil
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerExposesCounters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := StartServer(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("StartServer() error = %v", err)
+	}
+
+	Operation("setup_chain", true)
+	FirewallFailure("apply_rules")
+	RulesApplied(7)
+	PoolAcquired(true, nil)
+	PoolReleased(false, errors.New("docker unavailable"))
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/bastion.BastionService/SetupChain"}
+	_, _ = UnaryServerInterceptor(ctx, nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.InvalidArgument, "bad chain")
+	})
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`holopod_bastion_operations_total{operation="setup_chain",result="success"} 1`,
+		`holopod_bastion_firewall_failures_total{operation="apply_rules"} 1`,
+		`holopod_bastion_rules_applied_total 7`,
+		`holopod_bastion_pool_acquisitions_total{result="reused"} 1`,
+		`holopod_bastion_pool_releases_total{result="failed"} 1`,
+		`holopod_bastion_rpc_duration_seconds_count{code="InvalidArgument",method="/bastion.BastionService/SetupChain"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics is missing %s", want)
+		}
+	}
+}
+
+func TestStartServerFromEnvUnset(t *testing.T) {
+	t.Setenv(AddressEnv, "")
+	addr, err := StartServerFromEnv(context.Background())
+	if err != nil || addr != "" {
+		t.Errorf("StartServerFromEnv() = %q, %v; want nothing served", addr, err)
+	}
+}
@@ -0,0 +1,246 @@
+package networkpool
+
+import (
+	"fmt"
+	"net"
+)
+
+// subnetBitmap records which /24 subnets of the pool's range are taken, one
+// bit per index into the range. It is persisted with the pool state so an
+// allocation neither lists Docker networks nor scans the whole range.
+type subnetBitmap struct {
+	// Range is the configured range the bits index into; a bitmap saved for
+	// another range is discarded at startup
+	Range string `json:"range"`
+	Size  int    `json:"size"`
+	Bits  []byte `json:"bits"`
+	// Next is where the search for a free subnet resumes, so a subnet just
+	// freed is not handed out again straight away
+	Next int `json:"next"`
+}
+
+func newSubnetBitmap(config SubnetConfig) *subnetBitmap {
+	return &subnetBitmap{
+		Range: subnetRangeKey(config),
+		Size:  config.MaxSubnets,
+		Bits:  make([]byte, (config.MaxSubnets+7)/8),
+	}
+}
+
+func subnetRangeKey(config SubnetConfig) string {
+	return fmt.Sprintf("%s/%d", config.BaseIP, config.SubnetMask)
+}
+
+func (b *subnetBitmap) matches(config SubnetConfig) bool {
+	return b != nil && b.Range == subnetRangeKey(config) && b.Size == config.MaxSubnets && len(b.Bits) == (b.Size+7)/8
+}
+
+func (b *subnetBitmap) isSet(i int) bool {
+	return b != nil && b.Bits[i/8]&(1<<(i%8)) != 0
+}
+
+func (b *subnetBitmap) set(i int) {
+	b.Bits[i/8] |= 1 << (i % 8)
+}
+
+func (b *subnetBitmap) clear(i int) {
+	b.Bits[i/8] &^= 1 << (i % 8)
+}
+
+// take claims the first index from Next on that is neither set nor in skip
+func (b *subnetBitmap) take(skip *subnetBitmap) (int, bool) {
+	for n := 0; n < b.Size; {
+		i := (b.Next + n) % b.Size
+
+		// Whole bytes at once while they are full
+		if i%8 == 0 && i+8 <= b.Size {
+			used := b.Bits[i/8]
+			if skip != nil {
+				used |= skip.Bits[i/8]
+			}
+			if used == 0xff {
+				n += 8
+				continue
+			}
+		}
+
+		if !b.isSet(i) && !skip.isSet(i) {
+			b.set(i)
+			b.Next = (i + 1) % b.Size
+			return i, true
+		}
+		n++
+	}
+
+	return 0, false
+}
+
+// index maps a subnet to its bit, if it is a /24 inside the range
+func (b *subnetBitmap) index(subnet string) (int, bool) {
+	if b == nil {
+		return 0, false
+	}
+
+	_, rng, err := net.ParseCIDR(b.Range)
+	if err != nil || rng.IP.To4() == nil {
+		return 0, false
+	}
+	_, candidate, err := net.ParseCIDR(subnet)
+	if err != nil || candidate.IP.To4() == nil {
+		return 0, false
+	}
+	if ones, _ := candidate.Mask.Size(); ones != 24 {
+		return 0, false
+	}
+
+	base, ip := rng.IP.To4(), candidate.IP.To4()
+	if ip[0] != base[0] {
+		return 0, false
+	}
+
+	i := (int(ip[1])-int(base[1]))*256 + int(ip[2])
+	return i, i >= 0 && i < b.Size
+}
+
+// mark sets the bit of every subnet in the range overlapping ipNet
+func (b *subnetBitmap) mark(ipNet *net.IPNet) {
+	_, rng, err := net.ParseCIDR(b.Range)
+	if err != nil || rng.IP.To4() == nil {
+		return
+	}
+	first := ipNet.IP.To4()
+	if first == nil {
+		return
+	}
+	last := make(net.IP, 4)
+	for i := range last {
+		last[i] = first[i] | ^ipNet.Mask[len(ipNet.Mask)-4+i]
+	}
+
+	// Indices run over the second and third octets under the range's first
+	base := rng.IP.To4()
+	start, end := 0, b.Size-1
+	if first[0] > base[0] || last[0] < base[0] {
+		return
+	}
+	if first[0] == base[0] {
+		start = max(start, (int(first[1])-int(base[1]))*256+int(first[2]))
+	}
+	if last[0] == base[0] {
+		end = min(end, (int(last[1])-int(base[1]))*256+int(last[2]))
+	}
+	for i := start; i <= end; i++ {
+		b.set(i)
+	}
+}
+
+// clone returns a copy of the bitmap, or a new one for config if it is nil
+func (b *subnetBitmap) clone(config SubnetConfig) *subnetBitmap {
+	if b == nil {
+		return newSubnetBitmap(config)
+	}
+	c := *b
+	c.Bits = append([]byte(nil), b.Bits...)
+	return &c
+}
+
+// release frees the bit for subnet, if it has one
+func (b *subnetBitmap) release(subnet string) {
+	if i, ok := b.index(subnet); ok {
+		b.clear(i)
+	}
+}
+
+// initSubnets loads the persisted subnet bitmap, or starts a new one when
+// the configured range has changed, and marks every pooled network's subnet
+func (p *Pool) initSubnets() {
+	if p.subnetConfig.MaxSubnets <= 0 {
+		return
+	}
+
+	if baseIP := net.ParseIP(p.subnetConfig.BaseIP).To4(); baseIP != nil {
+		p.buildAllocationMasks(baseIP)
+	}
+
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+
+	if !p.state.Subnets.matches(p.subnetConfig) {
+		p.state.Subnets = newSubnetBitmap(p.subnetConfig)
+	}
+	for _, entry := range p.state.Networks {
+		if i, ok := p.state.Subnets.index(entry.Subnet); ok && entry.Options.poolSubnet() {
+			p.state.Subnets.set(i)
+		}
+	}
+}
+
+// takeSubnet claims a free subnet outside skip from the bitmap. It reports
+// false when the bitmap is missing, suspected stale or full, for the caller
+// to fall back to scanning Docker's networks.
+func (p *Pool) takeSubnet(baseIP net.IP, skip *subnetBitmap) (string, bool) {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+
+	if p.state.Subnets == nil || p.subnetsStale {
+		return "", false
+	}
+
+	i, ok := p.state.Subnets.take(skip)
+	if !ok {
+		return "", false
+	}
+	return p.generateSubnet(baseIP, i), true
+}
+
+// syncSubnets rebuilds the bitmap from a full scan of the subnets in use
+func (p *Pool) syncSubnets(used map[string]bool) {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+
+	if p.subnetConfig.MaxSubnets <= 0 {
+		return
+	}
+
+	next := 0
+	if p.state.Subnets != nil {
+		next = p.state.Subnets.Next
+	}
+	p.state.Subnets = newSubnetBitmap(p.subnetConfig)
+	p.state.Subnets.Next = next % p.subnetConfig.MaxSubnets
+
+	for subnet := range used {
+		if i, ok := p.state.Subnets.index(subnet); ok {
+			p.state.Subnets.set(i)
+		}
+	}
+	p.subnetsStale = false
+}
+
+// releaseSubnet frees the bit of a subnet whose network creation failed for a
+// reason other than the subnet being taken
+func (p *Pool) releaseSubnet(subnet string) {
+	p.state.mu.Lock()
+	p.state.Subnets.release(subnet)
+	p.state.mu.Unlock()
+}
+
+// markSubnetsStale makes the next allocation scan Docker's networks, after
+// Docker rejected a subnet the bitmap had as free
+func (p *Pool) markSubnetsStale() {
+	p.state.mu.Lock()
+	p.subnetsStale = true
+	p.state.mu.Unlock()
+}
+
+// dropNetwork forgets a removed network and frees its subnet. The caller must
+// hold p.state.mu.
+func (p *Pool) dropNetwork(name string) {
+	if entry, ok := p.state.Networks[name]; ok {
+		if entry.Options.poolSubnet() {
+			p.state.Subnets.release(entry.Subnet)
+		}
+		delete(p.removing, entry.NetworkID)
+	}
+	delete(p.state.Networks, name)
+}
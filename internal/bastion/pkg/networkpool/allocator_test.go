@@ -0,0 +1,160 @@
+package networkpool
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+)
+
+func TestSubnetBitmapTake(t *testing.T) {
+	config := SubnetConfig{BaseIP: "10.20.0.0", SubnetMask: 20, MaxSubnets: 16}
+	bitmap := newSubnetBitmap(config)
+	skip := newSubnetBitmap(config)
+
+	for _, i := range []int{0, 1, 2, 3, 4, 5, 6, 7, 9} {
+		bitmap.set(i)
+	}
+	skip.set(8)
+
+	if i, ok := bitmap.take(skip); !ok || i != 10 {
+		t.Fatalf("take() = %d, %v, want 10", i, ok)
+	}
+	if i, _ := bitmap.take(skip); i != 11 {
+		t.Errorf("take() = %d, want 11", i)
+	}
+
+	// Freed subnets are handed out once the search wraps around
+	bitmap.clear(2)
+	for want := 12; want < 16; want++ {
+		if i, _ := bitmap.take(skip); i != want {
+			t.Errorf("take() = %d, want %d", i, want)
+		}
+	}
+	if i, ok := bitmap.take(skip); !ok || i != 2 {
+		t.Errorf("take() after wrapping = %d, %v, want 2", i, ok)
+	}
+
+	if i, ok := bitmap.take(skip); ok {
+		t.Errorf("take() on a full bitmap = %d, want none", i)
+	}
+}
+
+func TestSubnetBitmapIndex(t *testing.T) {
+	pool := &Pool{subnetConfig: DefaultSubnetConfig()}
+	bitmap := newSubnetBitmap(pool.subnetConfig)
+	baseIP := net.ParseIP(defaultSubnetRangeBase).To4()
+
+	for _, i := range []int{0, 1, 255, 256, 4097, 60415} {
+		subnet := pool.generateSubnet(baseIP, i)
+		if got, ok := bitmap.index(subnet); !ok || got != i {
+			t.Errorf("index(%s) = %d, %v, want %d", subnet, got, ok, i)
+		}
+	}
+
+	for _, subnet := range []string{"10.19.255.0/24", "192.168.1.0/24", "10.20.0.0/16", "10.20.1.128/25", "fd00::/64", "bogus"} {
+		if i, ok := bitmap.index(subnet); ok {
+			t.Errorf("index(%s) = %d, want outside the range", subnet, i)
+		}
+	}
+}
+
+func TestInitSubnets(t *testing.T) {
+	config := SubnetConfig{BaseIP: "10.20.0.0", SubnetMask: 16, MaxSubnets: 256}
+	stale := newSubnetBitmap(SubnetConfig{BaseIP: "10.30.0.0", SubnetMask: 16, MaxSubnets: 256})
+	stale.set(7)
+
+	pool := &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"net-1": {Subnet: "10.20.0.0/24"},
+				"net-2": {Subnet: "10.20.3.0/24"},
+				"net-3": {Subnet: "172.16.0.0/24"},
+			},
+			Subnets: stale,
+		},
+		subnetConfig: config,
+	}
+	excluded, err := ParseExcludedSubnets("10.20.1.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.excluded = excluded
+
+	pool.initSubnets()
+
+	if !pool.state.Subnets.matches(config) || pool.state.Subnets.isSet(7) {
+		t.Fatalf("bitmap for another range was kept: %+v", pool.state.Subnets)
+	}
+
+	baseIP := net.ParseIP(config.BaseIP).To4()
+	for _, want := range []string{"10.20.2.0/24", "10.20.4.0/24"} {
+		if got, ok := pool.takeSubnet(baseIP, pool.allocMasks[""]); !ok || got != want {
+			t.Errorf("takeSubnet() = %s, %v, want %s", got, ok, want)
+		}
+	}
+
+	// The bitmap survives a save and load of the state
+	data, err := json.Marshal(pool.state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded NetworkPoolState
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Subnets.matches(config) || !loaded.Subnets.isSet(4) || loaded.Subnets.Next != 5 {
+		t.Errorf("loaded bitmap = %+v", loaded.Subnets)
+	}
+
+	pool.markSubnetsStale()
+	if _, ok := pool.takeSubnet(baseIP, pool.allocMasks[""]); ok {
+		t.Error("takeSubnet() used a bitmap marked stale")
+	}
+	pool.syncSubnets(map[string]bool{"10.20.0.0/24": true, "10.20.5.0/24": true})
+	if pool.subnetsStale || !pool.state.Subnets.isSet(5) || pool.state.Subnets.isSet(3) {
+		t.Errorf("syncSubnets() left %+v, stale = %v", pool.state.Subnets, pool.subnetsStale)
+	}
+}
+
+// fullPool returns a pool and its used subnets with all but the last subnet
+// of the default range taken, the worst case for finding a free one
+func fullPool() (*Pool, map[string]bool, net.IP) {
+	pool := &Pool{
+		state:        &NetworkPoolState{},
+		subnetConfig: DefaultSubnetConfig(),
+	}
+	pool.state.Subnets = newSubnetBitmap(pool.subnetConfig)
+	baseIP := net.ParseIP(defaultSubnetRangeBase).To4()
+
+	used := make(map[string]bool, pool.subnetConfig.MaxSubnets)
+	for i := 0; i < pool.subnetConfig.MaxSubnets-1; i++ {
+		used[pool.generateSubnet(baseIP, i)] = true
+		pool.state.Subnets.set(i)
+	}
+	return pool, used, baseIP
+}
+
+func BenchmarkSubnetBitmapTake(b *testing.B) {
+	pool, _, _ := fullPool()
+	last := pool.subnetConfig.MaxSubnets - 1
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pool.state.Subnets.Next = 0
+		if got, ok := pool.state.Subnets.take(nil); !ok || got != last {
+			b.Fatalf("take() = %d, %v", got, ok)
+		}
+		pool.state.Subnets.clear(last)
+	}
+}
+
+func BenchmarkSubnetScan(b *testing.B) {
+	pool, used, baseIP := fullPool()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if subnet, _ := pool.firstUnused(baseIP, used, nil); subnet == "" {
+			b.Fatal("firstUnused() found no subnet")
+		}
+	}
+}
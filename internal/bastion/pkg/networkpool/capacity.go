@@ -0,0 +1,134 @@
+package networkpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ErrPoolFull is returned when the pool is at its network cap and no pooled
+// network can be evicted to make room
+var ErrPoolFull = errors.New("network pool is full")
+
+// CapacityConfig caps how many networks the pool holds at once
+type CapacityConfig struct {
+	// MaxNetworks is the most networks the pool holds, active and pooled (0 = no cap)
+	MaxNetworks int
+	// Evict removes the least recently released pooled network when a new one
+	// is needed at the cap; otherwise the request fails with ErrPoolFull
+	Evict bool
+}
+
+// CapacityConfigFromEnv reads BASTION_POOL_MAX_NETWORKS and
+// BASTION_POOL_EVICTION ("lru", the default, or "fail"), ignoring invalid
+// values. Without a cap the pool grows until the subnet range is exhausted.
+func CapacityConfigFromEnv() CapacityConfig {
+	config := CapacityConfig{Evict: true}
+
+	if v, err := strconv.Atoi(os.Getenv("BASTION_POOL_MAX_NETWORKS")); err == nil && v > 0 {
+		config.MaxNetworks = v
+	}
+	if os.Getenv("BASTION_POOL_EVICTION") == "fail" {
+		config.Evict = false
+	}
+
+	return config
+}
+
+// reserve claims room for one new network, evicting a pooled one if the
+// pool is at its cap and evict is set. The returned func gives the room back
+// once the network is in the pool or its creation failed.
+func (p *Pool) reserve(ctx context.Context, evict bool) (func(), error) {
+	release := func() {
+		p.state.mu.Lock()
+		p.creating--
+		p.state.mu.Unlock()
+	}
+
+	p.state.mu.Lock()
+	if p.capacity.MaxNetworks == 0 || len(p.state.Networks)+p.creating < p.capacity.MaxNetworks {
+		p.creating++
+		p.state.mu.Unlock()
+		return release, nil
+	}
+
+	victim := ""
+	if evict && p.capacity.Evict {
+		victim = p.leastRecentlyReleased()
+	}
+	if victim == "" {
+		p.state.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d networks in use or pooled (BASTION_POOL_MAX_NETWORKS)", ErrPoolFull, p.capacity.MaxNetworks)
+	}
+
+	// Out of the reuse index so Acquire cannot claim it, and counted as room
+	// being made so concurrent callers do not pick it too
+	entry := p.state.Networks[victim]
+	p.state.ConfigIndex[entry.ConfigHash] = removeString(p.state.ConfigIndex[entry.ConfigHash], victim)
+	p.creating++
+	p.state.mu.Unlock()
+
+	networkID := entry.NetworkID
+	done := p.cleanupQueue.enqueue(priorityForce, func(ctx context.Context) error {
+		return p.cleanupNetwork(ctx, networkID)
+	})
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	p.state.mu.Lock()
+	if err != nil {
+		p.state.ConfigIndex[entry.ConfigHash] = append(p.state.ConfigIndex[entry.ConfigHash], victim)
+		p.creating--
+		p.state.mu.Unlock()
+		return nil, fmt.Errorf("failed to evict network %s: %w", victim, err)
+	}
+	p.dropNetwork(victim)
+	if len(p.state.ConfigIndex[entry.ConfigHash]) == 0 {
+		delete(p.state.ConfigIndex, entry.ConfigHash)
+	}
+	p.evictions++
+	p.state.mu.Unlock()
+
+	p.logger.Info("evicted pooled network to stay under the cap", "network", victim, "config_hash", entry.ConfigHash)
+
+	if err := p.persist(); err != nil {
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// leastRecentlyReleased picks the pooled network that has been idle longest.
+// The caller must hold p.state.mu.
+func (p *Pool) leastRecentlyReleased() string {
+	victim := ""
+	var oldest time.Time
+
+	for _, names := range p.state.ConfigIndex {
+		for _, name := range names {
+			entry, ok := p.state.Networks[name]
+			if !ok || entry.CurrentContainer != nil {
+				continue
+			}
+			// Prewarmed networks were never released; they count from creation
+			idleSince := entry.CreatedAt
+			if entry.LastReleasedAt != nil {
+				idleSince = *entry.LastReleasedAt
+			}
+			if victim == "" || idleSince.Before(oldest) {
+				victim, oldest = name, idleSince
+			}
+		}
+	}
+
+	return victim
+}
@@ -0,0 +1,90 @@
+package networkpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCapacityConfigFromEnv(t *testing.T) {
+	t.Setenv("BASTION_POOL_MAX_NETWORKS", "")
+	t.Setenv("BASTION_POOL_EVICTION", "")
+	if config := CapacityConfigFromEnv(); config.MaxNetworks != 0 || !config.Evict {
+		t.Errorf("CapacityConfigFromEnv() = %+v, want no cap with LRU eviction", config)
+	}
+
+	t.Setenv("BASTION_POOL_MAX_NETWORKS", "50")
+	t.Setenv("BASTION_POOL_EVICTION", "fail")
+	if config := CapacityConfigFromEnv(); config.MaxNetworks != 50 || config.Evict {
+		t.Errorf("CapacityConfigFromEnv() = %+v", config)
+	}
+
+	t.Setenv("BASTION_POOL_MAX_NETWORKS", "-1")
+	if config := CapacityConfigFromEnv(); config.MaxNetworks != 0 {
+		t.Errorf("max networks = %d, want no cap for an invalid value", config.MaxNetworks)
+	}
+}
+
+func TestReserveAtCap(t *testing.T) {
+	owner := "container-1"
+	pool := &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"net-active": {NetworkName: "net-active", CurrentContainer: &owner},
+			},
+			ConfigIndex: map[string][]string{},
+		},
+		capacity: CapacityConfig{MaxNetworks: 2, Evict: true},
+	}
+
+	release, err := pool.reserve(context.Background(), true)
+	if err != nil {
+		t.Fatalf("reserve() under the cap: %v", err)
+	}
+
+	// The in-flight creation counts towards the cap, and nothing is pooled to evict
+	if _, err := pool.reserve(context.Background(), true); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("reserve() at the cap = %v, want ErrPoolFull", err)
+	}
+
+	release()
+	if pool.creating != 0 {
+		t.Errorf("creating = %d after release", pool.creating)
+	}
+
+	pool.capacity.MaxNetworks = 1
+	pool.state.Networks["net-idle"] = &NetworkEntry{NetworkName: "net-idle"}
+	pool.state.ConfigIndex["hash"] = []string{"net-idle"}
+	if _, err := pool.reserve(context.Background(), false); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("reserve() without eviction = %v, want ErrPoolFull", err)
+	}
+}
+
+func TestLeastRecentlyReleased(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+	owner := "container-1"
+
+	pool := &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"net-recent":    {LastReleasedAt: at(time.Minute), CreatedAt: now.Add(-time.Hour)},
+				"net-old":       {LastReleasedAt: at(30 * time.Minute), CreatedAt: now.Add(-time.Hour)},
+				"net-prewarmed": {CreatedAt: now.Add(-10 * time.Minute)},
+				"net-active":    {LastReleasedAt: at(time.Hour), CurrentContainer: &owner},
+			},
+			ConfigIndex: map[string][]string{
+				"a": {"net-recent", "net-old"},
+				"b": {"net-prewarmed", "net-active"},
+			},
+		},
+	}
+
+	if got := pool.leastRecentlyReleased(); got != "net-old" {
+		t.Errorf("leastRecentlyReleased() = %q, want net-old", got)
+	}
+}
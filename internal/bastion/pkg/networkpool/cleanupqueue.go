@@ -0,0 +1,267 @@
+package networkpool
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCleanupWorkers = 4
+	defaultCleanupRate    = 10.0
+	defaultCleanupJitter  = 0.5
+)
+
+type cleanupPriority int
+
+const (
+	// priorityForce is used for explicit force-cleanup releases, which a caller is waiting on
+	priorityForce cleanupPriority = iota
+	// priorityExpired is used for networks whose TTL has elapsed
+	priorityExpired
+)
+
+// CleanupQueueConfig bounds how fast networks are removed from Docker
+type CleanupQueueConfig struct {
+	Workers       int
+	RatePerSecond float64
+	// Jitter is the fraction (0-1) by which the spacing between removals is randomized
+	Jitter float64
+}
+
+type CleanupQueueStats struct {
+	Depth        uint32
+	Processed    uint64
+	Failed       uint64
+	AvgLatencyMs float32
+	MaxLatencyMs float32
+}
+
+type cleanupJob struct {
+	remove     func(ctx context.Context) error
+	enqueuedAt time.Time
+	done       chan error
+}
+
+// cleanupQueue removes networks with a bounded worker pool, spacing Docker API
+// calls by a jittered interval. Force cleanups are always dequeued first.
+type cleanupQueue struct {
+	config CleanupQueueConfig
+
+	mu       sync.Mutex
+	force    []*cleanupJob
+	expired  []*cleanupJob
+	nextSlot time.Time
+
+	processed    uint64
+	failed       uint64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func DefaultCleanupQueueConfig() CleanupQueueConfig {
+	return CleanupQueueConfig{
+		Workers:       defaultCleanupWorkers,
+		RatePerSecond: defaultCleanupRate,
+		Jitter:        defaultCleanupJitter,
+	}
+}
+
+// CleanupQueueConfigFromEnv reads BASTION_CLEANUP_WORKERS and BASTION_CLEANUP_RATE,
+// ignoring invalid values
+func CleanupQueueConfigFromEnv() CleanupQueueConfig {
+	config := DefaultCleanupQueueConfig()
+
+	if v, err := strconv.Atoi(os.Getenv("BASTION_CLEANUP_WORKERS")); err == nil && v >= 1 && v <= 64 {
+		config.Workers = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("BASTION_CLEANUP_RATE"), 64); err == nil && v > 0 {
+		config.RatePerSecond = v
+	}
+
+	return config
+}
+
+func newCleanupQueue(config CleanupQueueConfig) *cleanupQueue {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.RatePerSecond <= 0 {
+		config.RatePerSecond = defaultCleanupRate
+	}
+	if config.Jitter < 0 || config.Jitter > 1 {
+		config.Jitter = defaultCleanupJitter
+	}
+
+	return &cleanupQueue{
+		config: config,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+}
+
+func (q *cleanupQueue) start(ctx context.Context) {
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker(ctx)
+	}
+}
+
+// close stops the workers. Jobs still queued fail with context.Canceled.
+func (q *cleanupQueue) close() {
+	q.stopOnce.Do(func() { close(q.stop) })
+	q.wg.Wait()
+
+	q.mu.Lock()
+	pending := append(q.force, q.expired...)
+	q.force, q.expired = nil, nil
+	q.mu.Unlock()
+
+	for _, job := range pending {
+		job.done <- context.Canceled
+	}
+}
+
+// enqueue schedules remove and returns a channel receiving its result
+func (q *cleanupQueue) enqueue(priority cleanupPriority, remove func(ctx context.Context) error) <-chan error {
+	job := &cleanupJob{
+		remove:     remove,
+		enqueuedAt: time.Now(),
+		done:       make(chan error, 1),
+	}
+
+	q.mu.Lock()
+	if priority == priorityForce {
+		q.force = append(q.force, job)
+	} else {
+		q.expired = append(q.expired, job)
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return job.done
+}
+
+func (q *cleanupQueue) next() *cleanupJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.force) > 0 {
+		job := q.force[0]
+		q.force = q.force[1:]
+		return job
+	}
+	if len(q.expired) > 0 {
+		job := q.expired[0]
+		q.expired = q.expired[1:]
+		return job
+	}
+	return nil
+}
+
+// reserveSlot returns how long to wait before the next Docker call, advancing
+// the shared schedule by the jittered interval
+func (q *cleanupQueue) reserveSlot(now time.Time) time.Duration {
+	interval := time.Duration(float64(time.Second) / q.config.RatePerSecond)
+	if q.config.Jitter > 0 {
+		factor := 1 + q.config.Jitter*(2*rand.Float64()-1)
+		interval = time.Duration(float64(interval) * factor)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	slot := q.nextSlot
+	if slot.Before(now) {
+		slot = now
+	}
+	q.nextSlot = slot.Add(interval)
+
+	return slot.Sub(now)
+}
+
+func (q *cleanupQueue) worker(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		job := q.next()
+		if job == nil {
+			select {
+			case <-q.wake:
+				continue
+			case <-q.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if wait := q.reserveSlot(time.Now()); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-q.stop:
+				timer.Stop()
+				job.done <- context.Canceled
+				return
+			case <-ctx.Done():
+				timer.Stop()
+				job.done <- ctx.Err()
+				return
+			}
+		}
+
+		err := job.remove(ctx)
+		q.record(time.Since(job.enqueuedAt), err)
+		job.done <- err
+
+		// Another worker may have gone idle while this job held the queue
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (q *cleanupQueue) record(latency time.Duration, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.processed++
+	if err != nil {
+		q.failed++
+	}
+	q.totalLatency += latency
+	if latency > q.maxLatency {
+		q.maxLatency = latency
+	}
+}
+
+func (q *cleanupQueue) stats() CleanupQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := CleanupQueueStats{
+		Depth:        uint32(len(q.force) + len(q.expired)),
+		Processed:    q.processed,
+		Failed:       q.failed,
+		MaxLatencyMs: float32(q.maxLatency.Milliseconds()),
+	}
+	if q.processed > 0 {
+		stats.AvgLatencyMs = float32(q.totalLatency.Milliseconds()) / float32(q.processed)
+	}
+
+	return stats
+}
@@ -0,0 +1,121 @@
+package networkpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCleanupQueueConfigFromEnv(t *testing.T) {
+	t.Setenv("BASTION_CLEANUP_WORKERS", "8")
+	t.Setenv("BASTION_CLEANUP_RATE", "2.5")
+
+	config := CleanupQueueConfigFromEnv()
+	if config.Workers != 8 {
+		t.Errorf("Workers = %d, want 8", config.Workers)
+	}
+	if config.RatePerSecond != 2.5 {
+		t.Errorf("RatePerSecond = %f, want 2.5", config.RatePerSecond)
+	}
+
+	t.Setenv("BASTION_CLEANUP_WORKERS", "0")
+	t.Setenv("BASTION_CLEANUP_RATE", "-1")
+
+	if config := CleanupQueueConfigFromEnv(); config != DefaultCleanupQueueConfig() {
+		t.Errorf("CleanupQueueConfigFromEnv() = %+v, want defaults for invalid values", config)
+	}
+}
+
+func TestCleanupQueuePriority(t *testing.T) {
+	q := newCleanupQueue(CleanupQueueConfig{Workers: 1, RatePerSecond: 1000})
+
+	var mu sync.Mutex
+	var order []string
+	job := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	// Queue before starting so the worker sees every job at once
+	results := []<-chan error{
+		q.enqueue(priorityExpired, job("expired-1")),
+		q.enqueue(priorityExpired, job("expired-2")),
+		q.enqueue(priorityForce, job("force")),
+	}
+
+	q.start(context.Background())
+	defer q.close()
+
+	for _, done := range results {
+		if err := <-done; err != nil {
+			t.Fatalf("job error = %v", err)
+		}
+	}
+
+	want := []string{"force", "expired-1", "expired-2"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestCleanupQueueRateLimit(t *testing.T) {
+	q := newCleanupQueue(CleanupQueueConfig{Workers: 4, RatePerSecond: 10, Jitter: 0.5})
+
+	now := time.Now()
+	var total time.Duration
+	for i := 0; i < 10; i++ {
+		total = q.reserveSlot(now)
+	}
+
+	// Ten slots at 10/s with +/-50% jitter leave the last one 450ms-1350ms out
+	if total < 450*time.Millisecond || total > 1350*time.Millisecond {
+		t.Errorf("tenth slot wait = %s, want between 450ms and 1350ms", total)
+	}
+}
+
+func TestCleanupQueueStats(t *testing.T) {
+	q := newCleanupQueue(CleanupQueueConfig{Workers: 2, RatePerSecond: 1000})
+	q.start(context.Background())
+	defer q.close()
+
+	ok := q.enqueue(priorityExpired, func(context.Context) error { return nil })
+	failed := q.enqueue(priorityForce, func(context.Context) error { return errors.New("network in use") })
+
+	if err := <-ok; err != nil {
+		t.Errorf("job error = %v", err)
+	}
+	if err := <-failed; err == nil {
+		t.Error("expected job error")
+	}
+
+	stats := q.stats()
+	if stats.Processed != 2 || stats.Failed != 1 {
+		t.Errorf("stats = %+v, want 2 processed and 1 failed", stats)
+	}
+	if stats.Depth != 0 {
+		t.Errorf("Depth = %d, want 0", stats.Depth)
+	}
+}
+
+func TestCleanupQueueCloseCancelsPending(t *testing.T) {
+	q := newCleanupQueue(DefaultCleanupQueueConfig())
+
+	done := q.enqueue(priorityExpired, func(context.Context) error { return nil })
+	if depth := q.stats().Depth; depth != 1 {
+		t.Errorf("Depth = %d, want 1", depth)
+	}
+
+	q.close()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("pending job error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,145 @@
+package networkpool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+)
+
+const (
+	eventsMinBackoff = time.Second
+	eventsMaxBackoff = 30 * time.Second
+)
+
+// watchEvents follows Docker's network destroy events and forgets pool
+// networks removed behind the pool's back, such as by docker network prune.
+// Each time the stream is (re)opened the pool is checked against Docker's
+// network list, since events may have been missed while it was down.
+func (p *Pool) watchEvents(ctx context.Context) {
+	defer close(p.eventsDone)
+
+	backoff := eventsMinBackoff
+	for {
+		watchCtx, cancel := context.WithCancel(ctx)
+		messages, errs := p.docker.Events(watchCtx, events.ListOptions{
+			Filters: filters.NewArgs(
+				filters.Arg("type", string(events.NetworkEventType)),
+				filters.Arg("event", string(events.ActionDestroy)),
+			),
+		})
+
+		if err := p.syncWithDocker(ctx); err != nil {
+			p.logger.Warn("failed to check pool networks against Docker", "error", err)
+		} else {
+			backoff = eventsMinBackoff
+		}
+
+		err := p.followEvents(ctx, messages, errs)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		p.logger.Warn("Docker events stream ended, reconnecting", "error", err, "retry_in", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-p.cleanupStop:
+			return
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, eventsMaxBackoff)
+	}
+}
+
+// followEvents handles destroy events until the stream fails, returning nil
+// once the pool is stopping
+func (p *Pool) followEvents(ctx context.Context, messages <-chan events.Message, errs <-chan error) error {
+	for {
+		select {
+		case msg := <-messages:
+			if name, ok := p.forgetNetwork(msg.Actor.ID); ok {
+				p.logger.Warn("pool network removed outside the pool", "network", name, "network_id", msg.Actor.ID)
+				if err := p.persist(); err != nil {
+					p.logger.Error("failed to persist network pool state", "error", err)
+				}
+			}
+		case err := <-errs:
+			if err == nil {
+				err = fmt.Errorf("stream closed")
+			}
+			return err
+		case <-p.cleanupStop:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// syncWithDocker forgets every pool network Docker no longer has
+func (p *Pool) syncWithDocker(ctx context.Context) error {
+	networks, err := p.docker.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list Docker networks: %w", err)
+	}
+
+	existing := make(map[string]bool, len(networks))
+	for _, n := range networks {
+		existing[n.ID] = true
+	}
+
+	p.state.mu.RLock()
+	var missing []string
+	for _, entry := range p.state.Networks {
+		if !existing[entry.NetworkID] {
+			missing = append(missing, entry.NetworkID)
+		}
+	}
+	p.state.mu.RUnlock()
+
+	forgotten := 0
+	for _, id := range missing {
+		if name, ok := p.forgetNetwork(id); ok {
+			p.logger.Warn("pool network removed outside the pool", "network", name, "network_id", id)
+			forgotten++
+		}
+	}
+	if forgotten == 0 {
+		return nil
+	}
+	return p.persist()
+}
+
+// forgetNetwork drops the pool entry for a network Docker has removed,
+// returning its name if the pool had it
+func (p *Pool) forgetNetwork(networkID string) (string, bool) {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+
+	for name, entry := range p.state.Networks {
+		if entry.NetworkID != networkID {
+			continue
+		}
+		if p.removing[networkID] {
+			// The pool's own removal; its caller drops the entry
+			return "", false
+		}
+
+		if networks, ok := p.state.ConfigIndex[entry.ConfigHash]; ok {
+			p.state.ConfigIndex[entry.ConfigHash] = removeString(networks, name)
+			if len(p.state.ConfigIndex[entry.ConfigHash]) == 0 {
+				delete(p.state.ConfigIndex, entry.ConfigHash)
+			}
+		}
+		p.dropNetwork(name)
+		p.removedExternally++
+		return name, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,50 @@
+package networkpool
+
+import "testing"
+
+func TestForgetNetwork(t *testing.T) {
+	config := SubnetConfig{BaseIP: "10.20.0.0", SubnetMask: 16, MaxSubnets: 256}
+	pool := &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"iso-net-a": {NetworkName: "iso-net-a", NetworkID: "id-a", Subnet: "10.20.0.0/24", ConfigHash: "hash"},
+				"iso-net-b": {NetworkName: "iso-net-b", NetworkID: "id-b", Subnet: "10.20.1.0/24", ConfigHash: "hash"},
+			},
+			ConfigIndex: map[string][]string{"hash": {"iso-net-a", "iso-net-b"}},
+		},
+		subnetConfig: config,
+		removing:     map[string]bool{"id-b": true},
+	}
+	pool.initSubnets()
+
+	if name, ok := pool.forgetNetwork("id-a"); !ok || name != "iso-net-a" {
+		t.Fatalf("forgetNetwork(id-a) = %q, %v", name, ok)
+	}
+	if _, ok := pool.state.Networks["iso-net-a"]; ok {
+		t.Error("forgotten network still in the pool")
+	}
+	if got := pool.state.ConfigIndex["hash"]; len(got) != 1 || got[0] != "iso-net-b" {
+		t.Errorf("config index = %v", got)
+	}
+	if pool.state.Subnets.isSet(0) {
+		t.Error("forgotten network's subnet is still taken")
+	}
+	if pool.Stats().RemovedExternally != 1 {
+		t.Errorf("RemovedExternally = %d, want 1", pool.Stats().RemovedExternally)
+	}
+
+	// The pool's own removals and unknown networks are left alone
+	if _, ok := pool.forgetNetwork("id-b"); ok {
+		t.Error("forgetNetwork() took over a removal the pool is making itself")
+	}
+	if _, ok := pool.forgetNetwork("id-unknown"); ok {
+		t.Error("forgetNetwork() reported a network the pool never had")
+	}
+
+	pool.state.mu.Lock()
+	pool.dropNetwork("iso-net-b")
+	pool.state.mu.Unlock()
+	if pool.removing["id-b"] {
+		t.Error("dropNetwork() left the removal marker behind")
+	}
+}
@@ -0,0 +1,179 @@
+package networkpool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+const routeTableFile = "/proc/net/route"
+
+// ParseExcludedSubnets parses a comma-separated list of CIDRs
+func ParseExcludedSubnets(value string) ([]*net.IPNet, error) {
+	var excluded []*net.IPNet
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid excluded subnet %q: %w", entry, err)
+		}
+		if ipNet.IP.To4() == nil {
+			return nil, fmt.Errorf("invalid excluded subnet %q: must be IPv4", entry)
+		}
+		excluded = append(excluded, ipNet)
+	}
+
+	return excluded, nil
+}
+
+// parseRouteTable returns the destinations of every non-default IPv4 route in
+// /proc/net/route format, skipping routes owned by Docker bridges.
+func parseRouteTable(r io.Reader) ([]*net.IPNet, error) {
+	var routes []*net.IPNet
+
+	scanner := bufio.NewScanner(r)
+	header := true
+	for scanner.Scan() {
+		if header {
+			header = false
+			continue
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+
+		iface := fields[0]
+		if iface == "docker0" || strings.HasPrefix(iface, "br-") {
+			continue
+		}
+
+		dst, err := parseRouteHex(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid route destination %q: %w", fields[1], err)
+		}
+		mask, err := parseRouteHex(fields[7])
+		if err != nil {
+			return nil, fmt.Errorf("invalid route mask %q: %w", fields[7], err)
+		}
+
+		ones, _ := net.IPMask(mask).Size()
+		if ones == 0 {
+			// Default route
+			continue
+		}
+
+		routes = append(routes, &net.IPNet{IP: net.IP(dst).Mask(net.IPMask(mask)), Mask: net.IPMask(mask)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return routes, nil
+}
+
+// parseRouteHex decodes a little-endian hex IPv4 address from /proc/net/route
+func parseRouteHex(s string) ([]byte, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != 4 {
+		return nil, fmt.Errorf("expected 4 bytes, got %d", len(raw))
+	}
+
+	ip := make([]byte, 4)
+	binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(raw))
+	return ip, nil
+}
+
+// routedSubnets reads the host routing table for ranges routed elsewhere
+func routedSubnets() ([]*net.IPNet, error) {
+	f, err := os.Open(routeTableFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read route table: %w", err)
+	}
+	defer f.Close()
+
+	return parseRouteTable(f)
+}
+
+// resolveExclusions combines the configured and routed exclusions and verifies
+// that at least one subnet in the pool's range remains allocatable.
+func (p *Pool) resolveExclusions() ([]*net.IPNet, error) {
+	config := p.subnetConfig
+
+	excluded, err := ParseExcludedSubnets(strings.Join(config.ExcludedSubnets, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ExcludeRoutedSubnets {
+		routes, err := routedSubnets()
+		if err != nil {
+			return nil, err
+		}
+		excluded = append(excluded, routes...)
+	}
+
+	if len(excluded) == 0 {
+		return nil, nil
+	}
+
+	baseIP := net.ParseIP(config.BaseIP).To4()
+	if baseIP == nil {
+		return nil, fmt.Errorf("base IP must be IPv4: %s", config.BaseIP)
+	}
+
+	for i := 0; i < config.MaxSubnets; i++ {
+		if overlapsAny(p.generateSubnet(baseIP, i), excluded) == nil {
+			return excluded, nil
+		}
+	}
+
+	return nil, fmt.Errorf("all %d subnets in %s/%d range are excluded (%d exclusions configured)",
+		config.MaxSubnets, config.BaseIP, config.SubnetMask, len(excluded))
+}
+
+// allocationMask is the skip mask for allocating in partition while also
+// avoiding exclude, the exclusions given with a single request
+func (p *Pool) allocationMask(partition string, exclude []*net.IPNet) *subnetBitmap {
+	mask := p.allocMasks[partition]
+	if len(exclude) == 0 {
+		return mask
+	}
+
+	mask = mask.clone(p.subnetConfig)
+	for _, ex := range exclude {
+		mask.mark(ex)
+	}
+	return mask
+}
+
+// overlapsAny returns the first excluded network overlapping subnet, or nil
+func overlapsAny(subnet string, excluded []*net.IPNet) *net.IPNet {
+	_, candidate, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return nil
+	}
+
+	for _, ex := range excluded {
+		if ex.Contains(candidate.IP) || candidate.Contains(ex.IP) {
+			return ex
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package networkpool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+const (
+	// DefaultLease is how long an acquired network is held before the
+	// holder must renew it
+	DefaultLease = 10 * time.Minute
+	// DefaultMaxLease bounds the lease a renewal may ask for
+	DefaultMaxLease = 24 * time.Hour
+	// DefaultSetupGrace is how long a network is kept for a container that
+	// has not been created yet, e.g. while its image is pulled
+	DefaultSetupGrace = time.Hour
+)
+
+// LeaseConfig sets how long acquired networks are held without renewal.
+// A network held past its lease is marked suspect and reclaimed once its
+// container is found to be gone.
+type LeaseConfig struct {
+	// Duration is the lease given on acquisition and on renewals that ask
+	// for none (0 = leases are not enforced)
+	Duration time.Duration
+	// MaxDuration caps the lease a renewal may ask for
+	MaxDuration time.Duration
+	// SetupGrace is how long after acquisition a network whose container
+	// has never been seen is kept rather than reclaimed, since runners take
+	// the network before pulling the image and creating the container
+	SetupGrace time.Duration
+}
+
+func DefaultLeaseConfig() LeaseConfig {
+	return LeaseConfig{
+		Duration:    DefaultLease,
+		MaxDuration: DefaultMaxLease,
+		SetupGrace:  DefaultSetupGrace,
+	}
+}
+
+// LeaseConfigFromEnv reads BASTION_POOL_LEASE, BASTION_POOL_MAX_LEASE and
+// BASTION_POOL_SETUP_GRACE as durations, ignoring invalid values. A lease of
+// zero turns enforcement off.
+func LeaseConfigFromEnv() LeaseConfig {
+	config := DefaultLeaseConfig()
+
+	if v, err := time.ParseDuration(os.Getenv("BASTION_POOL_LEASE")); err == nil && v >= 0 {
+		config.Duration = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("BASTION_POOL_MAX_LEASE")); err == nil && v > 0 {
+		config.MaxDuration = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("BASTION_POOL_SETUP_GRACE")); err == nil && v >= 0 {
+		config.SetupGrace = v
+	}
+
+	if config.Duration > config.MaxDuration {
+		config.MaxDuration = config.Duration
+	}
+	return config
+}
+
+// expiry is when a lease of the given length taken at now runs out, or nil
+// when leases are not enforced
+func (c LeaseConfig) expiry(now time.Time, duration *time.Duration) *time.Time {
+	d := c.Duration
+	if duration != nil && *duration > 0 {
+		d = min(*duration, c.MaxDuration)
+	}
+	if d <= 0 {
+		return nil
+	}
+	expiresAt := now.Add(d)
+	return &expiresAt
+}
+
+// RenewLease extends the lease containerID holds on networkName by duration,
+// or by the pool's lease if nil, and returns when it now expires. Renewing
+// clears a suspect mark.
+func (p *Pool) RenewLease(containerID, networkName string, duration *time.Duration) (time.Time, error) {
+	if p.leaseConfig.Duration <= 0 {
+		return time.Time{}, fmt.Errorf("network leases are not enforced (BASTION_POOL_LEASE=0)")
+	}
+
+	p.state.mu.Lock()
+
+	entry, ok := p.state.Networks[networkName]
+	if !ok {
+		p.state.mu.Unlock()
+		return time.Time{}, fmt.Errorf("network %s not found in pool", networkName)
+	}
+
+	if entry.CurrentContainer == nil || *entry.CurrentContainer != containerID {
+		p.state.mu.Unlock()
+		return time.Time{}, fmt.Errorf("container %s does not own network %s", containerID, networkName)
+	}
+
+	entry.LeaseExpiresAt = p.leaseConfig.expiry(time.Now(), duration)
+	entry.Suspect = false
+	expiresAt := *entry.LeaseExpiresAt
+
+	p.state.mu.Unlock()
+
+	if err := p.persist(); err != nil {
+		return time.Time{}, err
+	}
+
+	return expiresAt, nil
+}
+
+// heldNetwork is a network and the container holding it
+type heldNetwork struct {
+	name        string
+	containerID string
+}
+
+// checkLeases marks networks held past their lease as suspect and reclaims
+// those whose container no longer exists. A suspect network whose container
+// is still there, or has yet to be created within the setup grace, is given a
+// new lease; one whose container cannot be checked stays suspect until the
+// next pass.
+func (p *Pool) checkLeases(ctx context.Context) {
+	if p.leaseConfig.Duration <= 0 {
+		return
+	}
+
+	expired := p.expiredLeases(time.Now())
+	for _, held := range expired {
+		_, err := p.docker.ContainerInspect(ctx, held.containerID)
+		if err == nil {
+			p.confirmLease(held)
+			continue
+		}
+		if !client.IsErrNotFound(err) {
+			p.logger.Warn("failed to check the holder of a suspect network",
+				"network", held.name,
+				"container", held.containerID,
+				"error", err,
+			)
+			continue
+		}
+
+		if p.reclaimLease(held) {
+			p.logger.Warn("reclaimed network whose lease expired and whose container is gone",
+				"network", held.name,
+				"container", held.containerID,
+			)
+		}
+	}
+
+	if err := p.persist(); err != nil {
+		p.logger.Error("failed to persist network pool state", "error", err)
+	}
+}
+
+// expiredLeases marks every network held past its lease at now as suspect
+// and returns them. Networks held without a lease, as by a bastion from
+// before leases were enforced, are given one starting now.
+func (p *Pool) expiredLeases(now time.Time) []heldNetwork {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+
+	var expired []heldNetwork
+	for name, entry := range p.state.Networks {
+		if entry.CurrentContainer == nil {
+			continue
+		}
+		if entry.LeaseExpiresAt == nil {
+			entry.LeaseExpiresAt = p.leaseConfig.expiry(now, nil)
+			continue
+		}
+		if entry.LeaseExpiresAt.After(now) {
+			continue
+		}
+
+		if !entry.Suspect {
+			entry.Suspect = true
+			p.logger.Warn("network held past its lease without renewal",
+				"network", name,
+				"container", *entry.CurrentContainer,
+				"lease_expired_at", *entry.LeaseExpiresAt,
+			)
+		}
+		expired = append(expired, heldNetwork{name: name, containerID: *entry.CurrentContainer})
+	}
+	return expired
+}
+
+// confirmLease gives a suspect network whose container still exists a new
+// lease, unless it changed hands while the container was being checked
+func (p *Pool) confirmLease(held heldNetwork) {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+
+	entry, ok := p.state.Networks[held.name]
+	if !ok || !entry.Suspect || entry.CurrentContainer == nil || *entry.CurrentContainer != held.containerID {
+		return
+	}
+	entry.LeaseExpiresAt = p.leaseConfig.expiry(time.Now(), nil)
+	entry.Suspect = false
+	entry.ContainerSeen = true
+}
+
+// reclaimLease returns a suspect network to the pool for reuse, unless it
+// was renewed or released while its container was being checked. A network
+// whose container has never been seen is given a new lease instead while
+// within the setup grace, as its runner may still be pulling the image.
+func (p *Pool) reclaimLease(held heldNetwork) bool {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+
+	entry, ok := p.state.Networks[held.name]
+	if !ok || !entry.Suspect || entry.CurrentContainer == nil || *entry.CurrentContainer != held.containerID {
+		return false
+	}
+
+	now := time.Now()
+	if !entry.ContainerSeen && entry.AcquiredAt != nil && now.Sub(*entry.AcquiredAt) < p.leaseConfig.SetupGrace {
+		entry.LeaseExpiresAt = p.leaseConfig.expiry(now, nil)
+		entry.Suspect = false
+		return false
+	}
+
+	p.returnToPool(held.name, entry, nil, time.Now())
+	p.leasesReclaimed++
+	return true
+}
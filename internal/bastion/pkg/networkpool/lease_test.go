@@ -0,0 +1,154 @@
+package networkpool
+
+import (
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLeaseConfigFromEnv(t *testing.T) {
+	t.Setenv("BASTION_POOL_LEASE", "")
+	t.Setenv("BASTION_POOL_MAX_LEASE", "")
+	if config := LeaseConfigFromEnv(); config != DefaultLeaseConfig() {
+		t.Errorf("LeaseConfigFromEnv() = %+v, want the defaults", config)
+	}
+
+	t.Setenv("BASTION_POOL_LEASE", "48h")
+	if config := LeaseConfigFromEnv(); config.Duration != 48*time.Hour || config.MaxDuration != 48*time.Hour {
+		t.Errorf("LeaseConfigFromEnv() = %+v, want the max raised to the lease", config)
+	}
+
+	t.Setenv("BASTION_POOL_LEASE", "0")
+	if config := LeaseConfigFromEnv(); config.Duration != 0 || config.expiry(time.Now(), nil) != nil {
+		t.Errorf("LeaseConfigFromEnv() = %+v, want leases off", config)
+	}
+}
+
+func leaseTestPool(t *testing.T) *Pool {
+	owner := "container-1"
+	expired := time.Now().Add(-time.Minute)
+	return &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"iso-net-a": {NetworkName: "iso-net-a", ConfigHash: "hash", CurrentContainer: &owner, LeaseExpiresAt: &expired},
+				"iso-net-b": {NetworkName: "iso-net-b", ConfigHash: "hash", CurrentContainer: &owner},
+			},
+			ConfigIndex: map[string][]string{},
+		},
+		store:       &jsonStore{path: filepath.Join(t.TempDir(), "network_pool.json")},
+		ttlConfig:   DefaultTTLConfig(),
+		leaseConfig: LeaseConfig{Duration: time.Hour, MaxDuration: 2 * time.Hour},
+		logger:      slog.New(slog.DiscardHandler),
+	}
+}
+
+func TestExpiredLeases(t *testing.T) {
+	pool := leaseTestPool(t)
+
+	expired := pool.expiredLeases(time.Now())
+	if len(expired) != 1 || expired[0] != (heldNetwork{name: "iso-net-a", containerID: "container-1"}) {
+		t.Fatalf("expiredLeases() = %+v", expired)
+	}
+	if !pool.state.Networks["iso-net-a"].Suspect {
+		t.Error("network past its lease not marked suspect")
+	}
+	// Networks held from before leases get one rather than being reclaimed
+	if b := pool.state.Networks["iso-net-b"]; b.Suspect || b.LeaseExpiresAt == nil {
+		t.Errorf("network held without a lease = %+v, want a new lease", b)
+	}
+	if got := pool.Stats().SuspectLeases; got != 1 {
+		t.Errorf("SuspectLeases = %d, want 1", got)
+	}
+
+	// Renewing clears the suspect mark, capped at the max lease
+	expiresAt, err := pool.RenewLease("container-1", "iso-net-a", nil)
+	if err != nil {
+		t.Fatalf("RenewLease() error = %v", err)
+	}
+	if pool.state.Networks["iso-net-a"].Suspect || time.Until(expiresAt) < 59*time.Minute {
+		t.Errorf("renewed lease expires at %s, suspect = %v", expiresAt, pool.state.Networks["iso-net-a"].Suspect)
+	}
+	long := 72 * time.Hour
+	if expiresAt, _ := pool.RenewLease("container-1", "iso-net-a", &long); time.Until(expiresAt) > 2*time.Hour {
+		t.Errorf("renewed lease expires at %s, want it capped at 2h", expiresAt)
+	}
+
+	if _, err := pool.RenewLease("container-2", "iso-net-a", nil); err == nil {
+		t.Error("RenewLease() by a container not holding the network succeeded")
+	}
+	if _, err := pool.RenewLease("container-1", "iso-net-z", nil); err == nil {
+		t.Error("RenewLease() of an unknown network succeeded")
+	}
+}
+
+func TestReclaimLease(t *testing.T) {
+	pool := leaseTestPool(t)
+	expired := pool.expiredLeases(time.Now())
+
+	// A renewal while the container was being checked keeps the network held
+	if _, err := pool.RenewLease("container-1", "iso-net-a", nil); err != nil {
+		t.Fatal(err)
+	}
+	if pool.reclaimLease(expired[0]) {
+		t.Fatal("reclaimLease() took back a renewed network")
+	}
+
+	expired = pool.expiredLeases(time.Now().Add(2 * time.Hour))
+	if len(expired) != 2 {
+		t.Fatalf("expiredLeases() = %+v, want both networks", expired)
+	}
+	if !pool.reclaimLease(heldNetwork{name: "iso-net-a", containerID: "container-1"}) {
+		t.Fatal("reclaimLease() left a suspect network with its gone container")
+	}
+
+	entry := pool.state.Networks["iso-net-a"]
+	if entry.CurrentContainer != nil || entry.Suspect || entry.CleanupAt == nil {
+		t.Errorf("reclaimed network = %+v, want it idle and pooled", entry)
+	}
+	if got := pool.state.ConfigIndex["hash"]; len(got) != 1 || got[0] != "iso-net-a" {
+		t.Errorf("config index = %v, want the reclaimed network reusable", got)
+	}
+	if got := pool.Stats().LeasesReclaimed; got != 1 {
+		t.Errorf("LeasesReclaimed = %d, want 1", got)
+	}
+}
+
+func TestReclaimLeaseDuringSetup(t *testing.T) {
+	pool := leaseTestPool(t)
+	pool.leaseConfig.SetupGrace = time.Hour
+
+	// Acquired a while ago, its container not created yet: the image is
+	// still being pulled
+	entry := pool.state.Networks["iso-net-a"]
+	acquired := time.Now().Add(-15 * time.Minute)
+	entry.AcquiredAt = &acquired
+
+	expired := pool.expiredLeases(time.Now())
+	if len(expired) != 1 {
+		t.Fatalf("expiredLeases() = %+v, want the network past its lease", expired)
+	}
+	if pool.reclaimLease(expired[0]) {
+		t.Fatal("reclaimLease() took back a network whose container is still being set up")
+	}
+	if entry.CurrentContainer == nil || entry.Suspect || !entry.LeaseExpiresAt.After(time.Now()) {
+		t.Errorf("network in setup = %+v, want it held with a new lease", entry)
+	}
+
+	// Past the grace, a container that never appeared is given up on
+	acquired = time.Now().Add(-2 * time.Hour)
+	expired = pool.expiredLeases(time.Now().Add(2 * time.Hour))
+	if !pool.reclaimLease(heldNetwork{name: "iso-net-a", containerID: "container-1"}) {
+		t.Fatalf("reclaimLease() kept a network whose container never appeared, expired = %+v", expired)
+	}
+
+	// Once its container has been seen, a gone container is not in setup
+	owner := "container-2"
+	now := time.Now()
+	b := pool.state.Networks["iso-net-b"]
+	b.CurrentContainer, b.AcquiredAt, b.ContainerSeen = &owner, &now, true
+	pool.expiredLeases(time.Now().Add(3 * time.Hour))
+	if !pool.reclaimLease(heldNetwork{name: "iso-net-b", containerID: "container-2"}) {
+		t.Error("reclaimLease() kept a network whose container was seen and is gone")
+	}
+}
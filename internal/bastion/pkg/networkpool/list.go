@@ -0,0 +1,84 @@
+package networkpool
+
+import (
+	"fmt"
+	"sort"
+)
+
+// States a pool network can be listed by
+const (
+	// NetworkStateActive networks are held by a container
+	NetworkStateActive = "active"
+	// NetworkStatePooled networks are idle and waiting for reuse
+	NetworkStatePooled = "pooled"
+	// NetworkStateSuspect networks are held past their lease without renewal
+	NetworkStateSuspect = "suspect"
+)
+
+// NetworkFilter narrows List; unset fields match every network
+type NetworkFilter struct {
+	ContainerID string
+	ConfigHash  string
+	// Partition matches networks of the partition with this key, or of the
+	// unpartitioned range when it points at ""
+	Partition *string
+	Driver    string
+	// State is one of the NetworkState constants
+	State string
+}
+
+func (f NetworkFilter) validate() error {
+	switch f.State {
+	case "", NetworkStateActive, NetworkStatePooled, NetworkStateSuspect:
+		return nil
+	default:
+		return fmt.Errorf("unknown network state %q (want %q, %q or %q)", f.State, NetworkStateActive, NetworkStatePooled, NetworkStateSuspect)
+	}
+}
+
+func (f NetworkFilter) matches(entry *NetworkEntry) bool {
+	if f.ContainerID != "" && (entry.CurrentContainer == nil || *entry.CurrentContainer != f.ContainerID) {
+		return false
+	}
+	if f.ConfigHash != "" && entry.ConfigHash != f.ConfigHash {
+		return false
+	}
+	if f.Partition != nil && entry.Partition != *f.Partition {
+		return false
+	}
+	if f.Driver != "" && entry.Options.driver() != f.Driver {
+		return false
+	}
+
+	switch f.State {
+	case NetworkStateActive:
+		return entry.CurrentContainer != nil
+	case NetworkStatePooled:
+		return entry.CurrentContainer == nil
+	case NetworkStateSuspect:
+		return entry.Suspect
+	}
+	return true
+}
+
+// List returns copies of the pool's networks matching filter, by name
+func (p *Pool) List(filter NetworkFilter) ([]NetworkEntry, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	p.state.mu.RLock()
+	defer p.state.mu.RUnlock()
+
+	var networks []NetworkEntry
+	for _, entry := range p.state.Networks {
+		if filter.matches(entry) {
+			networks = append(networks, *entry)
+		}
+	}
+
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].NetworkName < networks[j].NetworkName
+	})
+	return networks, nil
+}
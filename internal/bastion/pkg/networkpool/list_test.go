@@ -0,0 +1,70 @@
+package networkpool
+
+import "testing"
+
+func TestList(t *testing.T) {
+	owner := "container-1"
+	tenant, unpartitioned := "tenant-a", ""
+	pool := &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"iso-net-c": {NetworkName: "iso-net-c", ConfigHash: "hash", Driver: DriverBridge},
+				"iso-net-a": {NetworkName: "iso-net-a", ConfigHash: "hash", Driver: DriverBridge, CurrentContainer: &owner, Suspect: true},
+				"iso-net-b": {NetworkName: "iso-net-b", ConfigHash: "other", Driver: DriverOverlay, Partition: tenant, Options: NetworkOptions{Driver: DriverOverlay}},
+			},
+		},
+	}
+
+	names := func(filter NetworkFilter) []string {
+		t.Helper()
+		networks, err := pool.List(filter)
+		if err != nil {
+			t.Fatalf("List(%+v) error = %v", filter, err)
+		}
+		var got []string
+		for _, n := range networks {
+			got = append(got, n.NetworkName)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name   string
+		filter NetworkFilter
+		want   []string
+	}{
+		{"all by name", NetworkFilter{}, []string{"iso-net-a", "iso-net-b", "iso-net-c"}},
+		{"container", NetworkFilter{ContainerID: owner}, []string{"iso-net-a"}},
+		{"config hash", NetworkFilter{ConfigHash: "hash"}, []string{"iso-net-a", "iso-net-c"}},
+		{"partition", NetworkFilter{Partition: &tenant}, []string{"iso-net-b"}},
+		{"unpartitioned", NetworkFilter{Partition: &unpartitioned}, []string{"iso-net-a", "iso-net-c"}},
+		{"driver", NetworkFilter{Driver: DriverBridge}, []string{"iso-net-a", "iso-net-c"}},
+		{"pooled", NetworkFilter{State: NetworkStatePooled}, []string{"iso-net-b", "iso-net-c"}},
+		{"suspect", NetworkFilter{State: NetworkStateSuspect}, []string{"iso-net-a"}},
+		{"combined", NetworkFilter{ConfigHash: "hash", State: NetworkStateActive}, []string{"iso-net-a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(tt.filter)
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("List() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := pool.List(NetworkFilter{State: "leaking"}); err == nil {
+		t.Error("List() accepted an unknown state")
+	}
+
+	// Entries are copies; changing one leaves the pool alone
+	networks, _ := pool.List(NetworkFilter{ContainerID: owner})
+	networks[0].ReuseCount = 42
+	if pool.state.Networks["iso-net-a"].ReuseCount != 0 {
+		t.Error("List() returned the pool's own entry")
+	}
+}
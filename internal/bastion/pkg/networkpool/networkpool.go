@@ -2,24 +2,24 @@ package networkpool
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
 	"github.com/google/uuid"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
 )
 
 const (
 	defaultStateFile       = "/var/lib/bastion/network_pool.json"
-	defaultTTL             = 1 * time.Hour
-	cleanupInterval        = 5 * time.Minute
 	stateDirPermissions    = 0700
 	stateFilePermissions   = 0600
 	defaultSubnetRangeBase = "10.20.0.0"
@@ -28,22 +28,38 @@ const (
 )
 
 type NetworkEntry struct {
-	NetworkName      string     `json:"network_name"`
-	NetworkID        string     `json:"network_id"`
-	Subnet           string     `json:"subnet"`
-	ConfigHash       string     `json:"config_hash"`
-	Driver           string     `json:"driver"`
-	CurrentContainer *string    `json:"current_container"`
-	CreatedAt        time.Time  `json:"created_at"`
-	LastReleasedAt   *time.Time `json:"last_released_at"`
-	CleanupAt        *time.Time `json:"cleanup_at"`
-	ReuseCount       int        `json:"reuse_count"`
+	NetworkName string `json:"network_name"`
+	NetworkID   string `json:"network_id"`
+	Subnet      string `json:"subnet"`
+	ConfigHash  string `json:"config_hash"`
+	// Partition is the key of the partition the subnet was allocated from
+	Partition        string         `json:"partition,omitempty"`
+	Driver           string         `json:"driver"`
+	Options          NetworkOptions `json:"options"`
+	CurrentContainer *string        `json:"current_container"`
+	CreatedAt        time.Time      `json:"created_at"`
+	LastReleasedAt   *time.Time     `json:"last_released_at"`
+	CleanupAt        *time.Time     `json:"cleanup_at"`
+	ReuseCount       int            `json:"reuse_count"`
+	// TTL is how long the current holder asked for the network to stay
+	// pooled once released
+	TTL *time.Duration `json:"ttl,omitempty"`
+	// LeaseExpiresAt is when the holder's lease runs out unless renewed
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+	// Suspect is set once the holder let its lease run out
+	Suspect bool `json:"suspect,omitempty"`
+	// AcquiredAt is when the current holder took the network
+	AcquiredAt *time.Time `json:"acquired_at,omitempty"`
+	// ContainerSeen is set once the holder's container has been found in
+	// Docker; until then it may still be pulling its image
+	ContainerSeen bool `json:"container_seen,omitempty"`
 }
 
 type NetworkPoolState struct {
 	Networks    map[string]*NetworkEntry `json:"networks"`
 	ConfigIndex map[string][]string      `json:"config_index"`
 	LastCleanup time.Time                `json:"last_cleanup"`
+	Subnets     *subnetBitmap            `json:"subnets,omitempty"`
 	mu          sync.RWMutex
 }
 
@@ -51,18 +67,54 @@ type SubnetConfig struct {
 	BaseIP     string
 	SubnetMask int
 	MaxSubnets int
+	// ExcludedSubnets are CIDRs the allocator must never hand out
+	ExcludedSubnets []string
+	// ExcludeRoutedSubnets also excludes every range in the host routing table
+	ExcludeRoutedSubnets bool
+	// Partitions reserve ranges for networks acquired with a key, as key=cidr
+	Partitions []string
+	// DefaultMTU applies to networks whose request sets no MTU (0 = Docker default)
+	DefaultMTU uint32
 }
 
 type Pool struct {
 	state          *NetworkPoolState
-	stateFile      string
+	store          Store
 	docker         *client.Client
 	cleanupStop    chan struct{}
 	cleanupDone    chan struct{}
+	eventsDone     chan struct{}
 	cleanupStarted bool
 	subnetConfig   SubnetConfig
-	logger         *slog.Logger
-	mu             sync.Mutex
+	ttlConfig      TTLConfig
+	leaseConfig    LeaseConfig
+	capacity       CapacityConfig
+	excluded       []*net.IPNet
+	partitions     []Partition
+	// allocMasks marks the indices of the range each partition may not
+	// allocate, keyed by partition ("" for the unpartitioned rest); nil
+	// without exclusions or partitions
+	allocMasks   map[string]*subnetBitmap
+	cleanupQueue *cleanupQueue
+	logger       *slog.Logger
+	mu           sync.Mutex
+
+	// prewarm is the number of idle networks kept per config hash
+	prewarm   map[string]int
+	prewarmMu sync.Mutex
+
+	// Guarded by state.mu: why prewarming last failed per config hash, the
+	// networks being created or evicted for, evictions made at the cap,
+	// whether Docker has contradicted the subnet bitmap, the network IDs the
+	// pool is removing itself, networks found removed by someone else, and
+	// networks reclaimed from holders gone past their lease
+	prewarmErrors     map[string]string
+	creating          int
+	evictions         uint64
+	subnetsStale      bool
+	removing          map[string]bool
+	removedExternally uint64
+	leasesReclaimed   uint64
 }
 
 type AcquireResult struct {
@@ -85,6 +137,19 @@ type Stats struct {
 	SubnetUtilization float32
 	MaxSubnets        uint32
 	Healthy           bool
+	CleanupQueue      CleanupQueueStats
+	Prewarm           []PrewarmStatus
+	// MaxNetworks is the pool's network cap (0 = none)
+	MaxNetworks uint32
+	// Evictions counts pooled networks removed to stay under MaxNetworks
+	Evictions  uint64
+	Partitions []PartitionStatus
+	// RemovedExternally counts pool networks deleted outside the pool since startup
+	RemovedExternally uint64
+	// SuspectLeases are networks held past their lease without renewal
+	SuspectLeases uint32
+	// LeasesReclaimed counts suspect networks taken back from gone containers
+	LeasesReclaimed uint64
 }
 
 func DefaultSubnetConfig() SubnetConfig {
@@ -110,6 +175,26 @@ func SubnetConfigFromEnv() SubnetConfig {
 		}
 	}
 
+	// BASTION_SUBNET_EXCLUDE is another name for BASTION_EXCLUDED_SUBNETS; both
+	// lists are excluded
+	for _, key := range []string{"BASTION_EXCLUDED_SUBNETS", "BASTION_SUBNET_EXCLUDE"} {
+		if excluded := os.Getenv(key); excluded != "" {
+			config.ExcludedSubnets = append(config.ExcludedSubnets, strings.Split(excluded, ",")...)
+		}
+	}
+
+	config.ExcludeRoutedSubnets = os.Getenv("BASTION_EXCLUDE_ROUTED_SUBNETS") == "true"
+
+	if partitions := os.Getenv("BASTION_SUBNET_PARTITIONS"); partitions != "" {
+		config.Partitions = strings.Split(partitions, ",")
+	}
+
+	if mtuStr := os.Getenv("BASTION_NETWORK_MTU"); mtuStr != "" {
+		if mtu, err := strconv.ParseUint(mtuStr, 10, 32); err == nil && validation.ValidateMTU(uint32(mtu)) == nil {
+			config.DefaultMTU = uint32(mtu)
+		}
+	}
+
 	return config
 }
 
@@ -130,7 +215,7 @@ func NewWithConfig(ctx context.Context, stateFile string, subnetConfig SubnetCon
 		return nil, err
 	}
 
-	state, err := loadState(stateFile)
+	storeConfig, err := StoreConfigFromEnv()
 	if err != nil {
 		return nil, err
 	}
@@ -146,29 +231,79 @@ func NewWithConfig(ctx context.Context, stateFile string, subnetConfig SubnetCon
 		return nil, fmt.Errorf("failed to ping Docker daemon: %w", err)
 	}
 
+	store, err := OpenStore(stateFile, storeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+
 	if err := validateNetworks(ctx, docker, state); err != nil {
+		_ = store.Close()
 		return nil, err
 	}
 
 	pool := &Pool{
-		state:        state,
-		stateFile:    stateFile,
-		docker:       docker,
-		cleanupStop:  make(chan struct{}),
-		cleanupDone:  make(chan struct{}),
-		subnetConfig: subnetConfig,
-		logger:       logger,
+		state:         state,
+		store:         store,
+		docker:        docker,
+		cleanupStop:   make(chan struct{}),
+		cleanupDone:   make(chan struct{}),
+		eventsDone:    make(chan struct{}),
+		subnetConfig:  subnetConfig,
+		ttlConfig:     TTLConfigFromEnv(),
+		leaseConfig:   LeaseConfigFromEnv(),
+		capacity:      CapacityConfigFromEnv(),
+		prewarm:       PrewarmFromEnv(),
+		prewarmErrors: make(map[string]string),
+		removing:      make(map[string]bool),
+		logger:        logger,
+	}
+
+	excluded, err := pool.resolveExclusions()
+	if err != nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("invalid subnet exclusions: %w", err)
 	}
+	pool.excluded = excluded
+
+	partitions, err := pool.resolvePartitions()
+	if err != nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("invalid subnet partitions: %w", err)
+	}
+	pool.partitions = partitions
+	pool.initSubnets()
+
+	// Workers outlive the init context; they are stopped by Stop
+	pool.cleanupQueue = newCleanupQueue(CleanupQueueConfigFromEnv())
+	pool.cleanupQueue.start(context.Background())
 
 	logger.Info("network pool initialized",
+		"state_store", storeConfig.Backend,
 		"subnet_base", subnetConfig.BaseIP,
 		"subnet_mask", subnetConfig.SubnetMask,
 		"max_subnets", subnetConfig.MaxSubnets,
+		"excluded_subnets", len(excluded),
+		"partitions", len(partitions),
+		"default_mtu", subnetConfig.DefaultMTU,
+		"ttl", pool.ttlConfig.TTL,
+		"cleanup_interval", pool.ttlConfig.CleanupInterval,
+		"lease", pool.leaseConfig.Duration,
+		"prewarmed_configs", len(pool.prewarm),
+		"max_networks", pool.capacity.MaxNetworks,
+		"evict_at_cap", pool.capacity.Evict,
 	)
 
 	return pool, nil
 }
 
+// StartCleanup starts removing expired networks, and following Docker's
+// events to forget networks removed outside the pool
 func (p *Pool) StartCleanup(ctx context.Context) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -176,6 +311,7 @@ func (p *Pool) StartCleanup(ctx context.Context) {
 	if !p.cleanupStarted {
 		p.cleanupStarted = true
 		go p.cleanupLoop(ctx)
+		go p.watchEvents(ctx)
 	}
 }
 
@@ -187,16 +323,77 @@ func (p *Pool) Stop() {
 	if started {
 		close(p.cleanupStop)
 		<-p.cleanupDone
+		<-p.eventsDone
+	}
+
+	if p.cleanupQueue != nil {
+		p.cleanupQueue.close()
+	}
+
+	if err := p.store.Close(); err != nil {
+		p.logger.Warn("failed to close network pool state store", "error", err)
 	}
 }
 
+// TTLConfig returns how long the pool keeps released networks
+func (p *Pool) TTLConfig() TTLConfig {
+	return p.ttlConfig
+}
+
+// Acquire hands containerID a network for configHash, reusing a pooled one
+// when possible. leaseDuration, if set, is how long the network stays pooled
+// once released, in place of the pool's TTL.
 func (p *Pool) Acquire(ctx context.Context, containerID, configHash string, subnetRange *string, leaseDuration *time.Duration) (*AcquireResult, error) {
+	return p.AcquireWithOptions(ctx, containerID, configHash, subnetRange, NetworkOptions{}, leaseDuration)
+}
+
+// AcquireWithOptions is Acquire for networks that need specific bridge driver options
+func (p *Pool) AcquireWithOptions(ctx context.Context, containerID, configHash string, subnetRange *string, options NetworkOptions, leaseDuration *time.Duration) (*AcquireResult, error) {
+	return p.AcquireInPartition(ctx, containerID, configHash, "", subnetRange, options, leaseDuration)
+}
+
+// AcquireInPartition is AcquireWithOptions for a network whose subnet must
+// come from the partition with the given key. Networks are only reused
+// within the partition they were allocated from.
+func (p *Pool) AcquireInPartition(ctx context.Context, containerID, configHash, partitionKey string, subnetRange *string, options NetworkOptions, leaseDuration *time.Duration) (*AcquireResult, error) {
+	return p.AcquireExcluding(ctx, containerID, configHash, partitionKey, subnetRange, nil, options, leaseDuration)
+}
+
+// AcquireExcluding is AcquireInPartition for a network whose subnet must not
+// overlap exclude, on top of the pool's own exclusions. Pooled networks on
+// an excluded subnet are passed over rather than reused.
+func (p *Pool) AcquireExcluding(ctx context.Context, containerID, configHash, partitionKey string, subnetRange *string, exclude []*net.IPNet, options NetworkOptions, leaseDuration *time.Duration) (*AcquireResult, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if !options.poolSubnet() {
+		if subnetRange == nil || *subnetRange == "" {
+			return nil, fmt.Errorf("%s networks need the subnet of their parent network", options.driver())
+		}
+		if partitionKey != "" {
+			return nil, fmt.Errorf("partitions only apply to subnets from the pool's range, not %s networks", options.driver())
+		}
+	}
+
+	partition, err := p.partition(partitionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	options = options.withDefaults(p.subnetConfig)
+
 	p.state.mu.Lock()
 
-	if networkName := p.findAvailableNetwork(configHash); networkName != "" {
+	if networkName := p.findAvailableNetwork(configHash, partitionKey, options, exclude); networkName != "" {
 		entry := p.state.Networks[networkName]
+		now := time.Now()
 		entry.CurrentContainer = &containerID
 		entry.CleanupAt = nil
+		entry.TTL = leaseDuration
+		entry.LeaseExpiresAt = p.leaseConfig.expiry(now, nil)
+		entry.Suspect = false
+		entry.AcquiredAt = &now
+		entry.ContainerSeen = false
 		entry.ReuseCount++
 
 		result := &AcquireResult{
@@ -221,10 +418,16 @@ func (p *Pool) Acquire(ctx context.Context, containerID, configHash string, subn
 
 	p.state.mu.Unlock()
 
-	return p.createNetwork(ctx, containerID, configHash, subnetRange)
+	return p.createNetwork(ctx, containerID, configHash, partition, subnetRange, exclude, options, leaseDuration)
 }
 
 func (p *Pool) Release(ctx context.Context, containerID, networkName string, forceCleanup bool) (*ReleaseResult, error) {
+	return p.ReleaseWithTTL(ctx, containerID, networkName, forceCleanup, nil)
+}
+
+// ReleaseWithTTL is Release keeping the network pooled for ttl rather than
+// the TTL it was acquired with or the pool's
+func (p *Pool) ReleaseWithTTL(ctx context.Context, containerID, networkName string, forceCleanup bool, ttl *time.Duration) (*ReleaseResult, error) {
 	p.state.mu.Lock()
 
 	entry, ok := p.state.Networks[networkName]
@@ -238,21 +441,30 @@ func (p *Pool) Release(ctx context.Context, containerID, networkName string, for
 		return nil, fmt.Errorf("container %s does not own network %s", containerID, networkName)
 	}
 
-	entry.CurrentContainer = nil
 	now := time.Now()
-	entry.LastReleasedAt = &now
 
 	if forceCleanup {
+		entry.CurrentContainer = nil
+		entry.LastReleasedAt = &now
+
 		networkID := entry.NetworkID
 		configHash := entry.ConfigHash
 		p.state.mu.Unlock()
 
-		if err := p.cleanupNetwork(ctx, networkID); err != nil {
-			return nil, err
+		done := p.cleanupQueue.enqueue(priorityForce, func(ctx context.Context) error {
+			return p.cleanupNetwork(ctx, networkID)
+		})
+		select {
+		case err := <-done:
+			if err != nil {
+				return nil, err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
 
 		p.state.mu.Lock()
-		delete(p.state.Networks, networkName)
+		p.dropNetwork(networkName)
 		if networks, ok := p.state.ConfigIndex[configHash]; ok {
 			p.state.ConfigIndex[configHash] = removeString(networks, networkName)
 			if len(p.state.ConfigIndex[configHash]) == 0 {
@@ -268,13 +480,7 @@ func (p *Pool) Release(ctx context.Context, containerID, networkName string, for
 		return &ReleaseResult{CleanedUp: true}, nil
 	}
 
-	cleanupAt := now.Add(defaultTTL)
-	entry.CleanupAt = &cleanupAt
-
-	if _, ok := p.state.ConfigIndex[entry.ConfigHash]; !ok {
-		p.state.ConfigIndex[entry.ConfigHash] = []string{}
-	}
-	p.state.ConfigIndex[entry.ConfigHash] = append(p.state.ConfigIndex[entry.ConfigHash], networkName)
+	p.returnToPool(networkName, entry, ttl, now)
 
 	p.state.mu.Unlock()
 
@@ -285,6 +491,26 @@ func (p *Pool) Release(ctx context.Context, containerID, networkName string, for
 	return &ReleaseResult{CleanedUp: false}, nil
 }
 
+// returnToPool makes a held network idle and reusable until its TTL runs
+// out. The caller holds state.mu.
+func (p *Pool) returnToPool(networkName string, entry *NetworkEntry, ttl *time.Duration, now time.Time) {
+	entry.CurrentContainer = nil
+	entry.LastReleasedAt = &now
+	entry.LeaseExpiresAt = nil
+	entry.Suspect = false
+	entry.AcquiredAt = nil
+	entry.ContainerSeen = false
+
+	cleanupAt := now.Add(p.ttlConfig.releaseTTL(ttl, entry))
+	entry.CleanupAt = &cleanupAt
+	entry.TTL = nil
+
+	if _, ok := p.state.ConfigIndex[entry.ConfigHash]; !ok {
+		p.state.ConfigIndex[entry.ConfigHash] = []string{}
+	}
+	p.state.ConfigIndex[entry.ConfigHash] = append(p.state.ConfigIndex[entry.ConfigHash], networkName)
+}
+
 func (p *Pool) Stats() *Stats {
 	p.state.mu.RLock()
 	defer p.state.mu.RUnlock()
@@ -292,11 +518,15 @@ func (p *Pool) Stats() *Stats {
 	total := len(p.state.Networks)
 	active := 0
 	pendingCleanup := 0
+	suspect := 0
 
 	for _, entry := range p.state.Networks {
 		if entry.CurrentContainer != nil {
 			active++
 		}
+		if entry.Suspect {
+			suspect++
+		}
 		if entry.CleanupAt != nil {
 			pendingCleanup++
 		}
@@ -315,6 +545,11 @@ func (p *Pool) Stats() *Stats {
 
 	healthy := utilization < 0.9 && subnetUtilization < highUtilizationWarning
 
+	var cleanupStats CleanupQueueStats
+	if p.cleanupQueue != nil {
+		cleanupStats = p.cleanupQueue.stats()
+	}
+
 	return &Stats{
 		TotalNetworks:     uint32(total),
 		ActiveNetworks:    uint32(active),
@@ -324,18 +559,30 @@ func (p *Pool) Stats() *Stats {
 		SubnetUtilization: subnetUtilization,
 		MaxSubnets:        uint32(p.subnetConfig.MaxSubnets),
 		Healthy:           healthy,
+		CleanupQueue:      cleanupStats,
+		Prewarm:           p.prewarmStatus(),
+		MaxNetworks:       uint32(p.capacity.MaxNetworks),
+		Evictions:         p.evictions,
+		Partitions:        p.partitionStatus(),
+		RemovedExternally: p.removedExternally,
+		SuspectLeases:     uint32(suspect),
+		LeasesReclaimed:   p.leasesReclaimed,
 	}
 }
 
 func (p *Pool) cleanupLoop(ctx context.Context) {
-	ticker := time.NewTicker(cleanupInterval)
+	ticker := time.NewTicker(p.ttlConfig.CleanupInterval)
 	defer ticker.Stop()
 	defer close(p.cleanupDone)
 
+	p.fillPrewarm(ctx)
+
 	for {
 		select {
 		case <-ticker.C:
+			p.checkLeases(ctx)
 			_ = p.runCleanup(ctx)
+			p.fillPrewarm(ctx)
 		case <-p.cleanupStop:
 			return
 		case <-ctx.Done():
@@ -344,42 +591,43 @@ func (p *Pool) cleanupLoop(ctx context.Context) {
 	}
 }
 
+// runCleanup queues every network whose TTL has elapsed for removal and waits
+// for the queue to process them
 func (p *Pool) runCleanup(ctx context.Context) error {
 	now := time.Now()
 	p.state.mu.Lock()
 
-	var toCleanup []struct {
-		name       string
-		id         string
-		configHash string
-	}
-
+	var expired []string
 	for name, entry := range p.state.Networks {
 		if entry.CleanupAt != nil && entry.CleanupAt.Before(now) && entry.CurrentContainer == nil {
-			toCleanup = append(toCleanup, struct {
-				name       string
-				id         string
-				configHash string
-			}{name, entry.NetworkID, entry.ConfigHash})
+			expired = append(expired, name)
 		}
 	}
+	expired = p.retainPrewarmed(expired)
 
 	p.state.mu.Unlock()
 
-	for _, item := range toCleanup {
-		if err := p.cleanupNetwork(ctx, item.id); err != nil {
-			continue
-		}
+	results := make([]<-chan error, 0, len(expired))
+	for _, name := range expired {
+		name := name
+		results = append(results, p.cleanupQueue.enqueue(priorityExpired, func(ctx context.Context) error {
+			return p.removeExpired(ctx, name)
+		}))
+	}
 
-		p.state.mu.Lock()
-		delete(p.state.Networks, item.name)
-		if networks, ok := p.state.ConfigIndex[item.configHash]; ok {
-			p.state.ConfigIndex[item.configHash] = removeString(networks, item.name)
-			if len(p.state.ConfigIndex[item.configHash]) == 0 {
-				delete(p.state.ConfigIndex, item.configHash)
-			}
+	if len(expired) > 0 {
+		p.logger.Info("queued expired networks for cleanup",
+			"count", len(expired),
+			"queue_depth", p.cleanupQueue.stats().Depth,
+		)
+	}
+
+	for _, done := range results {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		p.state.mu.Unlock()
 	}
 
 	p.state.mu.Lock()
@@ -389,7 +637,56 @@ func (p *Pool) runCleanup(ctx context.Context) error {
 	return p.persist()
 }
 
-func (p *Pool) createNetwork(ctx context.Context, containerID, configHash string, subnetRange *string) (*AcquireResult, error) {
+// removeExpired removes a network queued by runCleanup, unless it was reused
+// while waiting in the queue
+func (p *Pool) removeExpired(ctx context.Context, name string) error {
+	p.state.mu.Lock()
+	entry, ok := p.state.Networks[name]
+	if !ok || entry.CurrentContainer != nil || entry.CleanupAt == nil || entry.CleanupAt.After(time.Now()) {
+		p.state.mu.Unlock()
+		return nil
+	}
+
+	// Take the network out of the reuse index so Acquire cannot claim it mid-removal
+	networkID := entry.NetworkID
+	configHash := entry.ConfigHash
+	if networks, ok := p.state.ConfigIndex[configHash]; ok {
+		p.state.ConfigIndex[configHash] = removeString(networks, name)
+	}
+	p.state.mu.Unlock()
+
+	if err := p.cleanupNetwork(ctx, networkID); err != nil {
+		p.state.mu.Lock()
+		p.state.ConfigIndex[configHash] = append(p.state.ConfigIndex[configHash], name)
+		p.state.mu.Unlock()
+
+		p.logger.Warn("failed to remove expired network", "network", name, "error", err)
+		return err
+	}
+
+	p.state.mu.Lock()
+	p.dropNetwork(name)
+	if len(p.state.ConfigIndex[configHash]) == 0 {
+		delete(p.state.ConfigIndex, configHash)
+	}
+	p.state.mu.Unlock()
+
+	return nil
+}
+
+func (p *Pool) createNetwork(ctx context.Context, containerID, configHash string, partition *Partition, subnetRange *string, exclude []*net.IPNet, options NetworkOptions, leaseDuration *time.Duration) (*AcquireResult, error) {
+	partitionKey := ""
+	if partition != nil {
+		partitionKey = partition.Key
+	}
+
+	// Prewarming only fills spare room; it never evicts
+	release, err := p.reserve(ctx, containerID != "")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	networkName := fmt.Sprintf("iso-net-%s", uuid.New().String()[:8])
 
 	// Retry logic with exponential backoff for handling transient failures and race conditions
@@ -401,44 +698,74 @@ func (p *Pool) createNetwork(ctx context.Context, containerID, configHash string
 		subnet := ""
 		if subnetRange != nil && *subnetRange != "" {
 			subnet = *subnetRange
+			if err := options.checkSubnet(subnet); err != nil {
+				return nil, err
+			}
+			if ex := overlapsAny(subnet, exclude); ex != nil {
+				return nil, fmt.Errorf("subnet %s overlaps excluded range %s", subnet, ex)
+			}
+			// Macvlan and ipvlan subnets belong to the parent's network, not the pool's range
+			if options.poolSubnet() {
+				if ex := overlapsAny(subnet, p.excluded); ex != nil {
+					return nil, fmt.Errorf("subnet %s overlaps excluded range %s", subnet, ex)
+				}
+				if err := p.checkRequestedSubnet(subnet, partition); err != nil {
+					return nil, err
+				}
+			}
 		} else {
 			var err error
-			subnet, err = p.allocateSubnet(ctx)
+			subnet, err = p.allocateSubnet(ctx, partitionKey, exclude)
 			if err != nil {
 				return nil, err
 			}
 		}
 
 		// Attempt to create network
-		resp, err := p.docker.NetworkCreate(ctx, networkName, network.CreateOptions{
-			Driver: "bridge",
-			IPAM: &network.IPAM{
-				Config: []network.IPAMConfig{
-					{Subnet: subnet},
-				},
-			},
-		})
+		resp, err := p.docker.NetworkCreate(ctx, networkName, options.createOptions(subnet))
 
 		if err == nil {
 			// Success - create entry and return
 			p.state.mu.Lock()
 			entry := &NetworkEntry{
-				NetworkName:      networkName,
-				NetworkID:        resp.ID,
-				Subnet:           subnet,
-				ConfigHash:       configHash,
-				Driver:           "bridge",
-				CurrentContainer: &containerID,
-				CreatedAt:        time.Now(),
-				ReuseCount:       0,
+				NetworkName: networkName,
+				NetworkID:   resp.ID,
+				Subnet:      subnet,
+				ConfigHash:  configHash,
+				Partition:   partitionKey,
+				Driver:      options.driver(),
+				Options:     options,
+				CreatedAt:   time.Now(),
+				ReuseCount:  0,
+				TTL:         leaseDuration,
+			}
+			if containerID != "" {
+				entry.CurrentContainer = &containerID
+				entry.LeaseExpiresAt = p.leaseConfig.expiry(entry.CreatedAt, nil)
+				entry.AcquiredAt = &entry.CreatedAt
+			} else {
+				// Prewarmed: idle and in the reuse index straight away
+				p.state.ConfigIndex[configHash] = append(p.state.ConfigIndex[configHash], networkName)
 			}
 			p.state.Networks[networkName] = entry
+			// Requested subnets inside the range are claimed like allocated ones
+			if i, ok := p.state.Subnets.index(subnet); ok && options.poolSubnet() {
+				p.state.Subnets.set(i)
+			}
 			p.state.mu.Unlock()
 
 			if err := p.persist(); err != nil {
 				return nil, err
 			}
 
+			if partitionKey != "" {
+				p.logger.Info("created network in subnet partition",
+					"network", networkName,
+					"subnet", subnet,
+					"partition", partitionKey,
+				)
+			}
+
 			return &AcquireResult{
 				NetworkName: networkName,
 				NetworkID:   resp.ID,
@@ -457,6 +784,14 @@ func (p *Pool) createNetwork(ctx context.Context, containerID, configHash string
 			isRetryable =
 				(containsAny(errMsg, "Pool overlaps", "overlaps with other") ||
 					containsAny(errMsg, "already in use", "address already"))
+
+			// A taken subnet means the bitmap is out of step with Docker;
+			// any other failure leaves the subnet free for the next caller
+			if isRetryable {
+				p.markSubnetsStale()
+			} else {
+				p.releaseSubnet(subnet)
+			}
 		}
 
 		if isRetryable && attempt < maxRetries-1 {
@@ -473,7 +808,13 @@ func (p *Pool) createNetwork(ctx context.Context, containerID, configHash string
 	return nil, fmt.Errorf("failed to create network after %d attempts: %w", maxRetries, lastErr)
 }
 
+// cleanupNetwork removes a network from Docker. Until the caller drops its
+// entry, the event watcher treats the removal as the pool's own.
 func (p *Pool) cleanupNetwork(ctx context.Context, networkID string) error {
+	p.state.mu.Lock()
+	p.removing[networkID] = true
+	p.state.mu.Unlock()
+
 	inspect, err := p.docker.NetworkInspect(ctx, networkID, network.InspectOptions{})
 	if err == nil {
 		for containerID := range inspect.Containers {
@@ -481,13 +822,19 @@ func (p *Pool) cleanupNetwork(ctx context.Context, networkID string) error {
 		}
 	}
 
-	return p.docker.NetworkRemove(ctx, networkID)
+	if err := p.docker.NetworkRemove(ctx, networkID); err != nil {
+		p.state.mu.Lock()
+		delete(p.removing, networkID)
+		p.state.mu.Unlock()
+		return err
+	}
+	return nil
 }
 
-func (p *Pool) findAvailableNetwork(configHash string) string {
+func (p *Pool) findAvailableNetwork(configHash, partition string, options NetworkOptions, exclude []*net.IPNet) string {
 	if networks, ok := p.state.ConfigIndex[configHash]; ok {
 		for _, networkName := range networks {
-			if entry, ok := p.state.Networks[networkName]; ok && entry.CurrentContainer == nil {
+			if entry, ok := p.state.Networks[networkName]; ok && entry.CurrentContainer == nil && entry.Partition == partition && entry.Options.equal(options) && overlapsAny(entry.Subnet, exclude) == nil {
 				return networkName
 			}
 		}
@@ -495,29 +842,13 @@ func (p *Pool) findAvailableNetwork(configHash string) string {
 	return ""
 }
 
-func (p *Pool) allocateSubnet(ctx context.Context) (string, error) {
-	dockerNetworks, err := p.docker.NetworkList(ctx, network.ListOptions{})
-	if err != nil {
-		return "", fmt.Errorf("failed to list Docker networks: %w", err)
-	}
-
-	usedSubnets := make(map[string]bool)
-
+// allocateSubnet picks a free subnet of partition outside exclude from the
+// bitmap, scanning Docker's networks instead when the bitmap is stale or full
+func (p *Pool) allocateSubnet(ctx context.Context, partition string, exclude []*net.IPNet) (string, error) {
 	p.state.mu.RLock()
-	for _, entry := range p.state.Networks {
-		usedSubnets[entry.Subnet] = true
-	}
 	pooledCount := len(p.state.Networks)
 	p.state.mu.RUnlock()
 
-	for _, net := range dockerNetworks {
-		if net.IPAM.Config != nil {
-			for _, config := range net.IPAM.Config {
-				usedSubnets[config.Subnet] = true
-			}
-		}
-	}
-
 	utilization := float32(pooledCount) / float32(p.subnetConfig.MaxSubnets)
 	if utilization > highUtilizationWarning {
 		p.logger.Warn("high subnet utilization",
@@ -536,72 +867,85 @@ func (p *Pool) allocateSubnet(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("base IP must be IPv4: %s", p.subnetConfig.BaseIP)
 	}
 
-	for i := 0; i < p.subnetConfig.MaxSubnets; i++ {
-		subnet := p.generateSubnet(baseIP, i)
-		if !usedSubnets[subnet] {
-			return subnet, nil
-		}
+	skip := p.allocationMask(partition, exclude)
+	if subnet, ok := p.takeSubnet(baseIP, skip); ok {
+		return subnet, nil
 	}
 
-	return "", fmt.Errorf("no available subnets (all %d checked in %s/%d range)",
-		p.subnetConfig.MaxSubnets, p.subnetConfig.BaseIP, p.subnetConfig.SubnetMask)
+	return p.scanSubnets(ctx, baseIP, partition, skip)
 }
 
-func (p *Pool) generateSubnet(baseIP net.IP, index int) string {
-	octet1 := int(baseIP[1]) + (index / 256)
-	octet2 := index % 256
+// scanSubnets finds a free subnet outside skip by checking every candidate
+// in the range against the pool and Docker's networks, and resyncs the
+// bitmap from what it found in use
+func (p *Pool) scanSubnets(ctx context.Context, baseIP net.IP, partition string, skip *subnetBitmap) (string, error) {
+	dockerNetworks, err := p.docker.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list Docker networks: %w", err)
+	}
 
-	return fmt.Sprintf("%d.%d.%d.0/24", baseIP[0], octet1, octet2)
-}
+	usedSubnets := make(map[string]bool)
 
-func (p *Pool) persist() error {
 	p.state.mu.RLock()
-	data, err := json.MarshalIndent(p.state, "", "  ")
+	for _, entry := range p.state.Networks {
+		usedSubnets[entry.Subnet] = true
+	}
 	p.state.mu.RUnlock()
 
-	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
+	for _, net := range dockerNetworks {
+		if net.IPAM.Config != nil {
+			for _, config := range net.IPAM.Config {
+				usedSubnets[config.Subnet] = true
+			}
+		}
 	}
 
-	tmpFile := p.stateFile + ".tmp"
-	if err := os.WriteFile(tmpFile, data, stateFilePermissions); err != nil {
-		return fmt.Errorf("failed to write temp state file: %w", err)
+	subnet, excludedCount := p.firstUnused(baseIP, usedSubnets, skip)
+	if subnet == "" {
+		if partition != "" {
+			return "", fmt.Errorf("no available subnets in partition %q", partition)
+		}
+		return "", fmt.Errorf("no available subnets (all %d checked in %s/%d range, %d excluded)",
+			p.subnetConfig.MaxSubnets, p.subnetConfig.BaseIP, p.subnetConfig.SubnetMask, excludedCount)
 	}
 
-	if err := os.Rename(tmpFile, p.stateFile); err != nil {
-		_ = os.Remove(tmpFile)
-		return fmt.Errorf("failed to rename state file: %w", err)
-	}
+	usedSubnets[subnet] = true
+	p.syncSubnets(usedSubnets)
 
-	return nil
+	return subnet, nil
 }
 
-func loadState(stateFile string) (*NetworkPoolState, error) {
-	data, err := os.ReadFile(stateFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &NetworkPoolState{
-				Networks:    make(map[string]*NetworkEntry),
-				ConfigIndex: make(map[string][]string),
-				LastCleanup: time.Now(),
-			}, nil
+// firstUnused returns the first subnet in the range that is neither used nor
+// in skip, and how many candidates were skipped on the way. Without a skip
+// mask, the exclusions are checked directly.
+func (p *Pool) firstUnused(baseIP net.IP, used map[string]bool, skip *subnetBitmap) (string, int) {
+	excludedCount := 0
+	for i := 0; i < p.subnetConfig.MaxSubnets; i++ {
+		subnet := p.generateSubnet(baseIP, i)
+		if used[subnet] {
+			continue
+		}
+		if skip.isSet(i) || (skip == nil && overlapsAny(subnet, p.excluded) != nil) {
+			excludedCount++
+			continue
 		}
-		return nil, fmt.Errorf("failed to read state file: %w", err)
+		return subnet, excludedCount
 	}
+	return "", excludedCount
+}
 
-	var state NetworkPoolState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
-	}
+func (p *Pool) generateSubnet(baseIP net.IP, index int) string {
+	octet1 := int(baseIP[1]) + (index / 256)
+	octet2 := index % 256
 
-	if state.Networks == nil {
-		state.Networks = make(map[string]*NetworkEntry)
-	}
-	if state.ConfigIndex == nil {
-		state.ConfigIndex = make(map[string][]string)
-	}
+	return fmt.Sprintf("%d.%d.%d.0/24", baseIP[0], octet1, octet2)
+}
+
+func (p *Pool) persist() error {
+	p.state.mu.RLock()
+	defer p.state.mu.RUnlock()
 
-	return &state, nil
+	return p.store.Save(p.state)
 }
 
 func validateNetworks(ctx context.Context, docker *client.Client, state *NetworkPoolState) error {
@@ -620,6 +964,9 @@ func validateNetworks(ctx context.Context, docker *client.Client, state *Network
 
 	for name, entry := range state.Networks {
 		if !dockerNetworkIDs[entry.NetworkID] {
+			if entry.Options.poolSubnet() {
+				state.Subnets.release(entry.Subnet)
+			}
 			delete(state.Networks, name)
 		}
 	}
@@ -215,8 +215,8 @@ func TestAllocateSubnet(t *testing.T) {
 	}
 	defer pool.Stop()
 
-	// Test 1: Without any networks, should return first available subnet consistently
-	subnet1, err := pool.allocateSubnet(ctx)
+	// Test 1: Each allocation claims its subnet, so the next one differs
+	subnet1, err := pool.allocateSubnet(ctx, "", nil)
 	if err != nil {
 		t.Fatalf("allocateSubnet() error = %v", err)
 	}
@@ -224,13 +224,15 @@ func TestAllocateSubnet(t *testing.T) {
 		t.Error("allocated subnet is empty")
 	}
 
-	subnet2, err := pool.allocateSubnet(ctx)
+	subnet2, err := pool.allocateSubnet(ctx, "", nil)
 	if err != nil {
 		t.Fatalf("allocateSubnet() second call error = %v", err)
 	}
-	if subnet1 != subnet2 {
-		t.Errorf("allocateSubnet() should return same subnet when no networks exist, got %s and %s", subnet1, subnet2)
+	if subnet1 == subnet2 {
+		t.Errorf("allocateSubnet() returned %s twice without it being released", subnet1)
 	}
+	pool.releaseSubnet(subnet1)
+	pool.releaseSubnet(subnet2)
 
 	// Test 2: After creating networks via Acquire, should get different subnets
 	containerIDs := []string{"test-1", "test-2", "test-3"}
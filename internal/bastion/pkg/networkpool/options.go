@@ -0,0 +1,219 @@
+package networkpool
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+
+	"github.com/docker/docker/api/types/network"
+)
+
+// Docker network drivers the pool can create networks with
+const (
+	DriverBridge  = "bridge"
+	DriverOverlay = "overlay"
+	DriverMacvlan = "macvlan"
+	DriverIPvlan  = "ipvlan"
+)
+
+// Docker driver options set on pool networks
+const (
+	driverOptMTU          = "com.docker.network.driver.mtu"
+	driverOptICC          = "com.docker.network.bridge.enable_icc"
+	driverOptIPMasquerade = "com.docker.network.bridge.enable_ip_masquerade"
+	driverOptEncrypted    = "encrypted"
+	driverOptParent       = "parent"
+	driverOptMacvlanMode  = "macvlan_mode"
+	driverOptIPvlanMode   = "ipvlan_mode"
+)
+
+// ErrUnsupportedDriver is returned for networks asked of a driver the pool does not manage
+var ErrUnsupportedDriver = errors.New("unsupported network driver")
+
+var (
+	macvlanModes = map[string]bool{"bridge": true, "private": true, "vepa": true, "passthru": true}
+	ipvlanModes  = map[string]bool{"l2": true, "l3": true, "l3s": true}
+
+	// interfaceName matches Linux interface names, including VLAN
+	// subinterfaces such as eth0.100
+	interfaceName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,14}$`)
+)
+
+// NetworkOptions are the driver and driver options a pool network is created
+// with. Unset fields leave Docker's defaults in place. Networks are only
+// reused for requests with identical options.
+//
+// Bridge and overlay networks get their subnet from the pool's range. Macvlan
+// and ipvlan networks sit on a physical network through Parent, so their
+// subnet and gateway must be given with the request and are not partitioned.
+type NetworkOptions struct {
+	// Driver is one of the Driver constants ("" = bridge)
+	Driver string `json:"driver,omitempty"`
+
+	// MTU applies to bridge and overlay networks
+	MTU uint32 `json:"mtu,omitempty"`
+
+	// Bridge only
+	EnableICC          *bool `json:"enable_icc,omitempty"`
+	EnableIPMasquerade *bool `json:"enable_ip_masquerade,omitempty"`
+
+	// Encrypted turns on IPsec between overlay nodes
+	Encrypted bool `json:"encrypted,omitempty"`
+
+	// Parent is the host interface macvlan and ipvlan networks attach to,
+	// Mode their macvlan_mode or ipvlan_mode, and Gateway the physical
+	// network's gateway
+	Parent  string `json:"parent,omitempty"`
+	Mode    string `json:"mode,omitempty"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// driver is the Docker driver, bridge when unset
+func (o NetworkOptions) driver() string {
+	if o.Driver == "" {
+		return DriverBridge
+	}
+	return o.Driver
+}
+
+// poolSubnet reports whether networks of the driver take their subnet from
+// the pool's range
+func (o NetworkOptions) poolSubnet() bool {
+	switch o.driver() {
+	case DriverMacvlan, DriverIPvlan:
+		return false
+	default:
+		return true
+	}
+}
+
+// validate checks the options fit the driver
+func (o NetworkOptions) validate() error {
+	driver := o.driver()
+
+	if driver != DriverBridge && (o.EnableICC != nil || o.EnableIPMasquerade != nil) {
+		return fmt.Errorf("enable_icc and enable_ip_masquerade only apply to bridge networks, not %s", driver)
+	}
+	if driver != DriverOverlay && o.Encrypted {
+		return fmt.Errorf("encryption only applies to overlay networks, not %s", driver)
+	}
+
+	switch driver {
+	case DriverBridge, DriverOverlay:
+		if o.Parent != "" || o.Mode != "" || o.Gateway != "" {
+			return fmt.Errorf("parent, mode and gateway only apply to macvlan and ipvlan networks, not %s", driver)
+		}
+		return nil
+
+	case DriverMacvlan, DriverIPvlan:
+		if o.MTU != 0 {
+			return fmt.Errorf("%s networks take the MTU of their parent interface", driver)
+		}
+		if o.Parent == "" {
+			return fmt.Errorf("%s networks need a parent interface", driver)
+		}
+		if !interfaceName.MatchString(o.Parent) {
+			return fmt.Errorf("invalid parent interface %q", o.Parent)
+		}
+		modes := macvlanModes
+		if driver == DriverIPvlan {
+			modes = ipvlanModes
+		}
+		if o.Mode != "" && !modes[o.Mode] {
+			return fmt.Errorf("invalid %s mode %q", driver, o.Mode)
+		}
+		if o.Gateway != "" && net.ParseIP(o.Gateway) == nil {
+			return fmt.Errorf("invalid gateway %q", o.Gateway)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w %q (want %s, %s, %s or %s)", ErrUnsupportedDriver, driver, DriverBridge, DriverOverlay, DriverMacvlan, DriverIPvlan)
+	}
+}
+
+// checkSubnet checks a subnet given with the request fits the options
+func (o NetworkOptions) checkSubnet(subnet string) error {
+	if o.Gateway == "" {
+		return nil
+	}
+	_, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return fmt.Errorf("invalid subnet %s: %w", subnet, err)
+	}
+	if !ipNet.Contains(net.ParseIP(o.Gateway)) {
+		return fmt.Errorf("gateway %s is outside subnet %s", o.Gateway, subnet)
+	}
+	return nil
+}
+
+func (o NetworkOptions) driverOptions() map[string]string {
+	opts := make(map[string]string)
+	if o.MTU != 0 {
+		opts[driverOptMTU] = strconv.FormatUint(uint64(o.MTU), 10)
+	}
+	if o.EnableICC != nil {
+		opts[driverOptICC] = strconv.FormatBool(*o.EnableICC)
+	}
+	if o.EnableIPMasquerade != nil {
+		opts[driverOptIPMasquerade] = strconv.FormatBool(*o.EnableIPMasquerade)
+	}
+	if o.Encrypted {
+		opts[driverOptEncrypted] = ""
+	}
+	if o.Parent != "" {
+		opts[driverOptParent] = o.Parent
+	}
+	if o.Mode != "" {
+		if o.driver() == DriverIPvlan {
+			opts[driverOptIPvlanMode] = o.Mode
+		} else {
+			opts[driverOptMacvlanMode] = o.Mode
+		}
+	}
+	return opts
+}
+
+// createOptions is the Docker request creating a network on subnet
+func (o NetworkOptions) createOptions(subnet string) network.CreateOptions {
+	return network.CreateOptions{
+		Driver:  o.driver(),
+		Options: o.driverOptions(),
+		// Overlay networks must be attachable for standalone containers to join
+		Attachable: o.driver() == DriverOverlay,
+		IPAM: &network.IPAM{
+			Config: []network.IPAMConfig{
+				{Subnet: subnet, Gateway: o.Gateway},
+			},
+		},
+	}
+}
+
+func (o NetworkOptions) equal(other NetworkOptions) bool {
+	return o.driver() == other.driver() &&
+		o.MTU == other.MTU &&
+		boolPtrEqual(o.EnableICC, other.EnableICC) &&
+		boolPtrEqual(o.EnableIPMasquerade, other.EnableIPMasquerade) &&
+		o.Encrypted == other.Encrypted &&
+		o.Parent == other.Parent &&
+		o.Mode == other.Mode &&
+		o.Gateway == other.Gateway
+}
+
+// withDefaults fills the MTU from the pool configuration when the request
+// has none and the driver takes one
+func (o NetworkOptions) withDefaults(config SubnetConfig) NetworkOptions {
+	if o.MTU == 0 && o.poolSubnet() {
+		o.MTU = config.DefaultMTU
+	}
+	return o
+}
+
+func boolPtrEqual(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
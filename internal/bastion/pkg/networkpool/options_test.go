@@ -0,0 +1,138 @@
+package networkpool
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBridgeDriverOptions(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name    string
+		options NetworkOptions
+		want    map[string]string
+	}{
+		{"defaults", NetworkOptions{}, map[string]string{}},
+		{"mtu", NetworkOptions{MTU: 1400}, map[string]string{driverOptMTU: "1400"}},
+		{"all", NetworkOptions{MTU: 1420, EnableICC: &no, EnableIPMasquerade: &yes}, map[string]string{
+			driverOptMTU:          "1420",
+			driverOptICC:          "false",
+			driverOptIPMasquerade: "true",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.driverOptions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("driverOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAvailableNetworkMatchesOptions(t *testing.T) {
+	no := false
+	p := &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"iso-net-a": {NetworkName: "iso-net-a", ConfigHash: "hash"},
+				"iso-net-b": {NetworkName: "iso-net-b", ConfigHash: "hash", Options: NetworkOptions{MTU: 1400, EnableICC: &no}},
+			},
+			ConfigIndex: map[string][]string{"hash": {"iso-net-a", "iso-net-b"}},
+		},
+	}
+
+	if got := p.findAvailableNetwork("hash", "", NetworkOptions{}, nil); got != "iso-net-a" {
+		t.Errorf("expected default-option network, got %q", got)
+	}
+	if got := p.findAvailableNetwork("hash", "", NetworkOptions{MTU: 1400, EnableICC: &no}, nil); got != "iso-net-b" {
+		t.Errorf("expected matching-option network, got %q", got)
+	}
+	if got := p.findAvailableNetwork("hash", "", NetworkOptions{MTU: 1400}, nil); got != "" {
+		t.Errorf("expected no network for unmatched options, got %q", got)
+	}
+}
+
+func TestNetworkOptionsWithDefaults(t *testing.T) {
+	config := SubnetConfig{DefaultMTU: 1450}
+
+	if got := (NetworkOptions{}).withDefaults(config); got.MTU != 1450 {
+		t.Errorf("expected default MTU 1450, got %d", got.MTU)
+	}
+	if got := (NetworkOptions{MTU: 1300}).withDefaults(config); got.MTU != 1300 {
+		t.Errorf("expected requested MTU to win, got %d", got.MTU)
+	}
+}
+
+func TestNetworkOptionsValidate(t *testing.T) {
+	yes := true
+
+	tests := []struct {
+		name    string
+		options NetworkOptions
+		wantErr bool
+	}{
+		{"bridge default", NetworkOptions{}, false},
+		{"bridge with icc", NetworkOptions{Driver: DriverBridge, EnableICC: &yes}, false},
+		{"bridge with parent", NetworkOptions{Parent: "eth0"}, true},
+		{"overlay encrypted", NetworkOptions{Driver: DriverOverlay, MTU: 1400, Encrypted: true}, false},
+		{"overlay with icc", NetworkOptions{Driver: DriverOverlay, EnableICC: &yes}, true},
+		{"macvlan", NetworkOptions{Driver: DriverMacvlan, Parent: "eth0.100", Mode: "bridge", Gateway: "192.168.1.1"}, false},
+		{"macvlan without parent", NetworkOptions{Driver: DriverMacvlan}, true},
+		{"macvlan bad parent", NetworkOptions{Driver: DriverMacvlan, Parent: "eth0; reboot"}, true},
+		{"macvlan ipvlan mode", NetworkOptions{Driver: DriverMacvlan, Parent: "eth0", Mode: "l3"}, true},
+		{"macvlan with mtu", NetworkOptions{Driver: DriverMacvlan, Parent: "eth0", MTU: 1400}, true},
+		{"macvlan encrypted", NetworkOptions{Driver: DriverMacvlan, Parent: "eth0", Encrypted: true}, true},
+		{"ipvlan l3", NetworkOptions{Driver: DriverIPvlan, Parent: "eth1", Mode: "l3"}, false},
+		{"ipvlan bad gateway", NetworkOptions{Driver: DriverIPvlan, Parent: "eth1", Gateway: "gw"}, true},
+		{"unknown driver", NetworkOptions{Driver: "host"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (NetworkOptions{Driver: "null"}).validate(); !errors.Is(err, ErrUnsupportedDriver) {
+		t.Errorf("validate() = %v, want ErrUnsupportedDriver", err)
+	}
+}
+
+func TestNetworkOptionsCreateOptions(t *testing.T) {
+	ipvlan := NetworkOptions{Driver: DriverIPvlan, Parent: "eth1", Mode: "l2", Gateway: "192.168.10.1"}
+	got := ipvlan.createOptions("192.168.10.0/24")
+	want := map[string]string{driverOptParent: "eth1", driverOptIPvlanMode: "l2"}
+	if got.Driver != DriverIPvlan || !reflect.DeepEqual(got.Options, want) || got.Attachable {
+		t.Errorf("createOptions() = %+v", got)
+	}
+	if ipam := got.IPAM.Config[0]; ipam.Subnet != "192.168.10.0/24" || ipam.Gateway != "192.168.10.1" {
+		t.Errorf("IPAM config = %+v", ipam)
+	}
+	if err := ipvlan.checkSubnet("10.0.0.0/24"); err == nil {
+		t.Error("checkSubnet() accepted a subnet that does not hold the gateway")
+	}
+
+	overlay := NetworkOptions{Driver: DriverOverlay, Encrypted: true}.createOptions("10.20.0.0/24")
+	if overlay.Driver != DriverOverlay || !overlay.Attachable {
+		t.Errorf("overlay createOptions() = %+v, want an attachable overlay", overlay)
+	}
+	if _, ok := overlay.Options[driverOptEncrypted]; !ok {
+		t.Errorf("overlay options = %v, want encryption on", overlay.Options)
+	}
+
+	// Networks from before drivers were recorded are bridges
+	if !(NetworkOptions{}).equal(NetworkOptions{Driver: DriverBridge}) {
+		t.Error("unset driver does not match bridge")
+	}
+	if (NetworkOptions{Driver: DriverOverlay}).equal(NetworkOptions{}) {
+		t.Error("overlay options match bridge ones")
+	}
+	if got := (NetworkOptions{Driver: DriverMacvlan, Parent: "eth0"}).withDefaults(SubnetConfig{DefaultMTU: 1450}); got.MTU != 0 {
+		t.Errorf("macvlan took the default MTU %d", got.MTU)
+	}
+}
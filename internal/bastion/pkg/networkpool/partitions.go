@@ -0,0 +1,204 @@
+package networkpool
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+)
+
+// ErrUnknownPartition is returned for an acquire naming a partition that is
+// not configured
+var ErrUnknownPartition = errors.New("unknown subnet partition")
+
+// Partition reserves part of the pool's range for the networks acquired with
+// its key, so a tenant's networks can be audited and firewalled by range
+type Partition struct {
+	Key    string
+	Subnet *net.IPNet
+}
+
+// PartitionStatus reports a partition and how many networks it holds
+type PartitionStatus struct {
+	Key      string
+	Subnet   string
+	Networks uint32
+}
+
+// ParsePartitions parses key=cidr pairs such as "tenant-a=10.20.0.0/18"
+func ParsePartitions(values []string) ([]Partition, error) {
+	var partitions []Partition
+	seen := make(map[string]bool)
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		key, cidr, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("partition %q must be key=cidr", value)
+		}
+		key = strings.TrimSpace(key)
+		if err := validation.ValidatePartitionKey(key); err != nil {
+			return nil, err
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("partition %q is configured twice", key)
+		}
+		seen[key] = true
+
+		_, subnet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil || subnet.IP.To4() == nil {
+			return nil, fmt.Errorf("partition %q: invalid IPv4 CIDR %q", key, cidr)
+		}
+		if ones, _ := subnet.Mask.Size(); ones > 24 {
+			return nil, fmt.Errorf("partition %q: %s is smaller than one /24 subnet", key, subnet)
+		}
+
+		for _, other := range partitions {
+			if other.Subnet.Contains(subnet.IP) || subnet.Contains(other.Subnet.IP) {
+				return nil, fmt.Errorf("partitions %q and %q overlap", other.Key, key)
+			}
+		}
+
+		partitions = append(partitions, Partition{Key: key, Subnet: subnet})
+	}
+
+	return partitions, nil
+}
+
+// resolvePartitions parses the configured partitions and verifies each lies
+// inside the pool's range
+func (p *Pool) resolvePartitions() ([]Partition, error) {
+	partitions, err := ParsePartitions(p.subnetConfig.Partitions)
+	if err != nil || len(partitions) == 0 {
+		return nil, err
+	}
+
+	bounds := newSubnetBitmap(p.subnetConfig)
+	for _, partition := range partitions {
+		first, last := partitionBounds(partition.Subnet)
+		if _, ok := bounds.index(first); !ok {
+			return nil, fmt.Errorf("partition %q: %s is outside the pool range %s", partition.Key, partition.Subnet, bounds.Range)
+		}
+		if _, ok := bounds.index(last); !ok {
+			return nil, fmt.Errorf("partition %q: %s is outside the pool range %s", partition.Key, partition.Subnet, bounds.Range)
+		}
+	}
+
+	return partitions, nil
+}
+
+// partitionBounds returns the first and last /24 subnets of a partition
+func partitionBounds(subnet *net.IPNet) (string, string) {
+	first := subnet.IP.To4()
+	last := make(net.IP, 4)
+	for i := range last {
+		last[i] = first[i] | ^subnet.Mask[i]
+	}
+	return fmt.Sprintf("%s/24", first), fmt.Sprintf("%d.%d.%d.0/24", last[0], last[1], last[2])
+}
+
+// partition looks up a configured partition; the empty key is the rest of
+// the range
+func (p *Pool) partition(key string) (*Partition, error) {
+	if key == "" {
+		return nil, nil
+	}
+	for i := range p.partitions {
+		if p.partitions[i].Key == key {
+			return &p.partitions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownPartition, key)
+}
+
+// checkRequestedSubnet verifies a caller-chosen subnet stays inside its
+// partition, or outside every partition when it has none
+func (p *Pool) checkRequestedSubnet(subnet string, partition *Partition) error {
+	_, requested, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return fmt.Errorf("invalid subnet %s: %w", subnet, err)
+	}
+
+	if partition != nil {
+		reqOnes, _ := requested.Mask.Size()
+		partOnes, _ := partition.Subnet.Mask.Size()
+		if !partition.Subnet.Contains(requested.IP) || reqOnes < partOnes {
+			return fmt.Errorf("subnet %s is outside partition %q (%s)", subnet, partition.Key, partition.Subnet)
+		}
+		return nil
+	}
+
+	for _, other := range p.partitions {
+		if other.Subnet.Contains(requested.IP) || requested.Contains(other.Subnet.IP) {
+			return fmt.Errorf("subnet %s overlaps partition %q (%s)", subnet, other.Key, other.Subnet)
+		}
+	}
+	return nil
+}
+
+// buildAllocationMasks marks, for the unpartitioned range and each
+// partition, the subnets it may not allocate: excluded ones, and those
+// outside the partition or inside any partition respectively
+func (p *Pool) buildAllocationMasks(baseIP net.IP) {
+	if len(p.excluded) == 0 && len(p.partitions) == 0 {
+		return
+	}
+
+	p.allocMasks = make(map[string]*subnetBitmap, len(p.partitions)+1)
+	unpartitioned := newSubnetBitmap(p.subnetConfig)
+	p.allocMasks[""] = unpartitioned
+	for _, partition := range p.partitions {
+		p.allocMasks[partition.Key] = newSubnetBitmap(p.subnetConfig)
+	}
+
+	for i := 0; i < p.subnetConfig.MaxSubnets; i++ {
+		subnet := p.generateSubnet(baseIP, i)
+		excluded := overlapsAny(subnet, p.excluded) != nil
+		ip, _, _ := net.ParseCIDR(subnet)
+
+		if excluded {
+			unpartitioned.set(i)
+		}
+		for _, partition := range p.partitions {
+			inside := ip != nil && partition.Subnet.Contains(ip)
+			if inside {
+				unpartitioned.set(i)
+			}
+			if excluded || !inside {
+				p.allocMasks[partition.Key].set(i)
+			}
+		}
+	}
+}
+
+// partitionStatus reports each partition. The caller must hold p.state.mu.
+func (p *Pool) partitionStatus() []PartitionStatus {
+	if len(p.partitions) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]uint32)
+	for _, entry := range p.state.Networks {
+		if entry.Partition != "" {
+			counts[entry.Partition]++
+		}
+	}
+
+	status := make([]PartitionStatus, 0, len(p.partitions))
+	for _, partition := range p.partitions {
+		status = append(status, PartitionStatus{
+			Key:      partition.Key,
+			Subnet:   partition.Subnet.String(),
+			Networks: counts[partition.Key],
+		})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Key < status[j].Key })
+	return status
+}
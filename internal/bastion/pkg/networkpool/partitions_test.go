@@ -0,0 +1,134 @@
+package networkpool
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestParsePartitions(t *testing.T) {
+	partitions, err := ParsePartitions([]string{"tenant-a=10.20.0.0/18", " tenant-b = 10.20.64.0/18 ", ""})
+	if err != nil {
+		t.Fatalf("ParsePartitions() error = %v", err)
+	}
+	if len(partitions) != 2 || partitions[1].Key != "tenant-b" || partitions[1].Subnet.String() != "10.20.64.0/18" {
+		t.Errorf("ParsePartitions() = %+v", partitions)
+	}
+
+	for _, values := range [][]string{
+		{"tenant-a"},
+		{"=10.20.0.0/18"},
+		{"tenant-a=10.20.0.0/25"},
+		{"tenant-a=fd00::/64"},
+		{"tenant-a=10.20.0.0/18", "tenant-a=10.20.64.0/18"},
+		{"tenant-a=10.20.0.0/18", "tenant-b=10.20.32.0/20"},
+	} {
+		if _, err := ParsePartitions(values); err == nil {
+			t.Errorf("ParsePartitions(%q) succeeded, want an error", values)
+		}
+	}
+}
+
+func TestResolvePartitions(t *testing.T) {
+	pool := &Pool{subnetConfig: SubnetConfig{
+		BaseIP:     "10.20.0.0",
+		SubnetMask: 16,
+		MaxSubnets: 256,
+		Partitions: []string{"tenant-a=10.20.0.0/18"},
+	}}
+	if partitions, err := pool.resolvePartitions(); err != nil || len(partitions) != 1 {
+		t.Errorf("resolvePartitions() = %v, %v", partitions, err)
+	}
+
+	pool.subnetConfig.Partitions = []string{"tenant-a=10.21.0.0/18"}
+	if _, err := pool.resolvePartitions(); err == nil {
+		t.Error("resolvePartitions() accepted a partition outside the pool range")
+	}
+}
+
+func TestPartitionAllocation(t *testing.T) {
+	config := SubnetConfig{
+		BaseIP:     "10.20.0.0",
+		SubnetMask: 16,
+		MaxSubnets: 256,
+		Partitions: []string{"tenant-a=10.20.0.0/23", "tenant-b=10.20.4.0/24"},
+	}
+	pool := &Pool{state: &NetworkPoolState{Networks: map[string]*NetworkEntry{}}, subnetConfig: config}
+
+	partitions, err := pool.resolvePartitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.partitions = partitions
+	pool.initSubnets()
+
+	baseIP := net.ParseIP(config.BaseIP).To4()
+	take := func(partition string) string {
+		subnet, _ := pool.takeSubnet(baseIP, pool.allocMasks[partition])
+		return subnet
+	}
+
+	if got := take("tenant-a"); got != "10.20.0.0/24" {
+		t.Errorf("tenant-a first subnet = %s", got)
+	}
+	if got := take("tenant-a"); got != "10.20.1.0/24" {
+		t.Errorf("tenant-a second subnet = %s", got)
+	}
+	if got := take("tenant-a"); got != "" {
+		t.Errorf("tenant-a allocated %s past the end of its partition", got)
+	}
+	if got := take("tenant-b"); got != "10.20.4.0/24" {
+		t.Errorf("tenant-b subnet = %s", got)
+	}
+
+	// The unpartitioned range skips every partition
+	pool.state.Subnets.Next = 0
+	for _, want := range []string{"10.20.2.0/24", "10.20.3.0/24", "10.20.5.0/24"} {
+		if got := take(""); got != want {
+			t.Errorf("unpartitioned subnet = %s, want %s", got, want)
+		}
+	}
+
+	if _, err := pool.partition("tenant-c"); !errors.Is(err, ErrUnknownPartition) {
+		t.Errorf("partition(tenant-c) = %v, want ErrUnknownPartition", err)
+	}
+
+	tenantA, _ := pool.partition("tenant-a")
+	if err := pool.checkRequestedSubnet("10.20.1.0/24", tenantA); err != nil {
+		t.Errorf("checkRequestedSubnet() inside the partition: %v", err)
+	}
+	if err := pool.checkRequestedSubnet("10.20.8.0/24", tenantA); err == nil {
+		t.Error("checkRequestedSubnet() accepted a subnet outside the partition")
+	}
+	if err := pool.checkRequestedSubnet("10.20.4.0/24", nil); err == nil {
+		t.Error("checkRequestedSubnet() accepted an unpartitioned subnet inside tenant-b")
+	}
+	if err := pool.checkRequestedSubnet("172.16.0.0/24", nil); err != nil {
+		t.Errorf("checkRequestedSubnet() outside every partition: %v", err)
+	}
+}
+
+func TestFindAvailableNetworkByPartition(t *testing.T) {
+	pool := &Pool{state: &NetworkPoolState{
+		Networks: map[string]*NetworkEntry{
+			"iso-net-a": {NetworkName: "iso-net-a", Partition: "tenant-a"},
+			"iso-net-b": {NetworkName: "iso-net-b"},
+		},
+		ConfigIndex: map[string][]string{"hash": {"iso-net-a", "iso-net-b"}},
+	}}
+
+	if got := pool.findAvailableNetwork("hash", "tenant-a", NetworkOptions{}, nil); got != "iso-net-a" {
+		t.Errorf("findAvailableNetwork(tenant-a) = %q", got)
+	}
+	if got := pool.findAvailableNetwork("hash", "", NetworkOptions{}, nil); got != "iso-net-b" {
+		t.Errorf("findAvailableNetwork() = %q", got)
+	}
+	if got := pool.findAvailableNetwork("hash", "tenant-b", NetworkOptions{}, nil); got != "" {
+		t.Errorf("findAvailableNetwork(tenant-b) = %q, want none", got)
+	}
+
+	status := pool.partitionStatus()
+	if status != nil {
+		t.Errorf("partitionStatus() = %+v without partitions configured", status)
+	}
+}
@@ -0,0 +1,151 @@
+package networkpool
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrewarmStatus reports how many idle networks a config hash is kept at
+type PrewarmStatus struct {
+	ConfigHash string
+	Target     uint32
+	// Ready is the number of idle networks available to Acquire right now
+	Ready uint32
+	// LastError is why the last attempt to top the hash up failed, if it did
+	LastError string
+}
+
+// PrewarmFromEnv reads BASTION_POOL_PREWARM, a comma separated list of
+// config_hash=count pairs, skipping malformed entries. The pool keeps that
+// many idle networks per hash so containers do not wait on Docker network
+// creation at cold start.
+func PrewarmFromEnv() map[string]int {
+	targets := make(map[string]int)
+
+	for _, pair := range strings.Split(os.Getenv("BASTION_POOL_PREWARM"), ",") {
+		hash, countStr, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		hash = strings.ToLower(strings.TrimSpace(hash))
+		if raw, err := hex.DecodeString(hash); err != nil || len(raw) != 32 {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(countStr))
+		if err != nil || count <= 0 {
+			continue
+		}
+		targets[hash] = count
+	}
+
+	return targets
+}
+
+// fillPrewarm creates networks until every prewarmed config hash has its
+// target of idle networks. Prewarmed networks use the default bridge options
+// and an allocated subnet, so they serve requests that set neither.
+func (p *Pool) fillPrewarm(ctx context.Context) {
+	if len(p.prewarm) == 0 || !p.prewarmMu.TryLock() {
+		return
+	}
+	defer p.prewarmMu.Unlock()
+
+	options := NetworkOptions{}.withDefaults(p.subnetConfig)
+
+	for hash, target := range p.prewarm {
+		p.state.mu.RLock()
+		missing := target - p.idleNetworks(hash, options)
+		p.state.mu.RUnlock()
+
+		created := 0
+		var lastErr error
+		for ; created < missing; created++ {
+			if ctx.Err() != nil {
+				return
+			}
+			if _, lastErr = p.createNetwork(ctx, "", hash, nil, nil, nil, options, nil); lastErr != nil {
+				break
+			}
+		}
+
+		p.state.mu.Lock()
+		if lastErr != nil {
+			p.prewarmErrors[hash] = lastErr.Error()
+		} else {
+			delete(p.prewarmErrors, hash)
+		}
+		p.state.mu.Unlock()
+
+		if lastErr != nil {
+			p.logger.Warn("failed to prewarm networks", "config_hash", hash, "created", created, "missing", missing, "error", lastErr)
+		} else if created > 0 {
+			p.logger.Info("prewarmed networks", "config_hash", hash, "created", created)
+		}
+	}
+}
+
+// idleNetworks counts the unpartitioned networks Acquire could hand out for
+// configHash with options. The caller must hold p.state.mu.
+func (p *Pool) idleNetworks(configHash string, options NetworkOptions) int {
+	idle := 0
+	for _, name := range p.state.ConfigIndex[configHash] {
+		if entry, ok := p.state.Networks[name]; ok && entry.CurrentContainer == nil && entry.Partition == "" && entry.Options.equal(options) {
+			idle++
+		}
+	}
+	return idle
+}
+
+// retainPrewarmed drops from expired the networks a prewarmed config hash
+// still needs to stay at its target, and clears their expiry so they remain
+// pooled until reused. The caller must hold p.state.mu for writing.
+func (p *Pool) retainPrewarmed(expired []string) []string {
+	if len(p.prewarm) == 0 {
+		return expired
+	}
+
+	options := NetworkOptions{}.withDefaults(p.subnetConfig)
+	surplus := make(map[string]int, len(p.prewarm))
+	for hash, target := range p.prewarm {
+		surplus[hash] = p.idleNetworks(hash, options) - target
+	}
+
+	kept := expired[:0]
+	for _, name := range expired {
+		entry := p.state.Networks[name]
+		if spare, ok := surplus[entry.ConfigHash]; ok && entry.Partition == "" && entry.Options.equal(options) {
+			if spare <= 0 {
+				entry.CleanupAt = nil
+				continue
+			}
+			surplus[entry.ConfigHash]--
+		}
+		kept = append(kept, name)
+	}
+	return kept
+}
+
+// prewarmStatus reports each prewarmed config hash. The caller must hold
+// p.state.mu.
+func (p *Pool) prewarmStatus() []PrewarmStatus {
+	if len(p.prewarm) == 0 {
+		return nil
+	}
+
+	options := NetworkOptions{}.withDefaults(p.subnetConfig)
+	status := make([]PrewarmStatus, 0, len(p.prewarm))
+	for hash, target := range p.prewarm {
+		status = append(status, PrewarmStatus{
+			ConfigHash: hash,
+			Target:     uint32(target),
+			Ready:      uint32(p.idleNetworks(hash, options)),
+			LastError:  p.prewarmErrors[hash],
+		})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].ConfigHash < status[j].ConfigHash })
+	return status
+}
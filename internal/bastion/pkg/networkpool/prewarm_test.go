@@ -0,0 +1,75 @@
+package networkpool
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrewarmFromEnv(t *testing.T) {
+	hashA := strings.Repeat("a", 64)
+	hashB := strings.Repeat("B", 64)
+
+	t.Setenv("BASTION_POOL_PREWARM", hashA+"=3, "+hashB+" = 1,short=2,"+strings.Repeat("c", 64)+"=0,"+strings.Repeat("d", 64)+"=x")
+
+	targets := PrewarmFromEnv()
+	if len(targets) != 2 || targets[hashA] != 3 || targets[strings.ToLower(hashB)] != 1 {
+		t.Errorf("PrewarmFromEnv() = %v", targets)
+	}
+
+	t.Setenv("BASTION_POOL_PREWARM", "")
+	if targets := PrewarmFromEnv(); len(targets) != 0 {
+		t.Errorf("PrewarmFromEnv() = %v, want none when unset", targets)
+	}
+}
+
+func TestRetainPrewarmed(t *testing.T) {
+	hash := strings.Repeat("a", 64)
+	other := strings.Repeat("b", 64)
+	past := time.Now().Add(-time.Minute)
+	options := NetworkOptions{}.withDefaults(DefaultSubnetConfig())
+
+	idle := func(name, configHash string) *NetworkEntry {
+		at := past
+		return &NetworkEntry{NetworkName: name, ConfigHash: configHash, Options: options, CleanupAt: &at}
+	}
+
+	pool := &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"net-1": idle("net-1", hash),
+				"net-2": idle("net-2", hash),
+				"net-3": idle("net-3", hash),
+				"net-4": idle("net-4", other),
+			},
+			ConfigIndex: map[string][]string{
+				hash:  {"net-1", "net-2", "net-3"},
+				other: {"net-4"},
+			},
+		},
+		subnetConfig:  DefaultSubnetConfig(),
+		prewarm:       map[string]int{hash: 2},
+		prewarmErrors: map[string]string{hash: "no available subnets"},
+	}
+
+	expired := pool.retainPrewarmed([]string{"net-1", "net-2", "net-3", "net-4"})
+
+	removed := map[string]bool{}
+	for _, name := range expired {
+		removed[name] = true
+	}
+	if len(expired) != 2 || !removed["net-4"] {
+		t.Fatalf("retainPrewarmed() = %v, want one surplus network and net-4", expired)
+	}
+
+	for _, name := range []string{"net-1", "net-2", "net-3"} {
+		if kept := pool.state.Networks[name].CleanupAt == nil; kept == removed[name] {
+			t.Errorf("%s: kept = %v, removed = %v", name, kept, removed[name])
+		}
+	}
+
+	status := pool.prewarmStatus()
+	if len(status) != 1 || status[0].Target != 2 || status[0].Ready != 3 || status[0].LastError != "no available subnets" {
+		t.Errorf("prewarmStatus() = %+v", status)
+	}
+}
@@ -0,0 +1,291 @@
+package networkpool
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/ha"
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS networks (
+	name  TEXT PRIMARY KEY,
+	entry TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);`
+
+const (
+	metaLastCleanup  = "last_cleanup"
+	metaSubnets      = "subnets"
+	metaMigratedFrom = "migrated_from"
+)
+
+// sqliteStore keeps one row per network, so a save writes only the networks
+// that changed since the last one. WAL mode and a busy timeout let other
+// processes read the database while the bastion writes to it.
+type sqliteStore struct {
+	db    *sql.DB
+	lease *ha.Lease
+
+	mu sync.Mutex
+	// saved is each row as last written, to diff the next save against
+	saved     map[string][]byte
+	savedMeta map[string]string
+}
+
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	// modernc.org/sqlite is pure Go, so the bastion still builds with CGO_ENABLED=0
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pool database: %w", err)
+	}
+	// One writer at a time; WAL still serves readers in other processes
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create pool database schema: %w", err)
+	}
+
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		_ = db.Close()
+		return nil, fmt.Errorf("pool database is not in WAL mode (journal_mode=%q): %v", mode, err)
+	}
+
+	if err := os.Chmod(path, stateFilePermissions); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to restrict pool database permissions: %w", err)
+	}
+
+	return &sqliteStore{
+		db:        db,
+		saved:     make(map[string][]byte),
+		savedMeta: make(map[string]string),
+	}, nil
+}
+
+func (s *sqliteStore) Load() (*NetworkPoolState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := newState()
+
+	rows, err := s.db.Query("SELECT name, entry FROM networks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pool networks: %w", err)
+	}
+	defer rows.Close()
+
+	saved := make(map[string][]byte)
+	for rows.Next() {
+		var name string
+		var data []byte
+		if err := rows.Scan(&name, &data); err != nil {
+			return nil, fmt.Errorf("failed to read pool network: %w", err)
+		}
+		var entry NetworkEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pool network %s: %w", name, err)
+		}
+		state.Networks[name] = &entry
+		saved[name] = data
+
+		// The reuse index is not stored; it follows from the idle networks
+		if entry.CurrentContainer == nil {
+			state.ConfigIndex[entry.ConfigHash] = append(state.ConfigIndex[entry.ConfigHash], name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pool networks: %w", err)
+	}
+
+	meta, err := s.loadMeta()
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := meta[metaLastCleanup]; ok {
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			state.LastCleanup = t
+		}
+	}
+	if v, ok := meta[metaSubnets]; ok {
+		var subnets subnetBitmap
+		if err := json.Unmarshal([]byte(v), &subnets); err == nil {
+			state.Subnets = &subnets
+		}
+	}
+
+	s.saved = saved
+	s.savedMeta = meta
+	return state, nil
+}
+
+func (s *sqliteStore) loadMeta() (map[string]string, error) {
+	rows, err := s.db.Query("SELECT key, value FROM meta")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pool metadata: %w", err)
+	}
+	defer rows.Close()
+
+	meta := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to read pool metadata: %w", err)
+		}
+		meta[key] = value
+	}
+	return meta, rows.Err()
+}
+
+func (s *sqliteStore) Save(state *NetworkPoolState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := make(map[string][]byte)
+	for name, entry := range state.Networks {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pool network %s: %w", name, err)
+		}
+		if !bytes.Equal(s.saved[name], data) {
+			changed[name] = data
+		}
+	}
+
+	var removed []string
+	for name := range s.saved {
+		if _, ok := state.Networks[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	meta := map[string]string{metaLastCleanup: state.LastCleanup.Format(time.RFC3339Nano)}
+	if state.Subnets != nil {
+		data, err := json.Marshal(state.Subnets)
+		if err != nil {
+			return fmt.Errorf("failed to marshal subnet bitmap: %w", err)
+		}
+		meta[metaSubnets] = string(data)
+	}
+	for key, value := range meta {
+		if s.savedMeta[key] == value {
+			delete(meta, key)
+		}
+	}
+
+	if len(changed) == 0 && len(removed) == 0 && len(meta) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin pool state transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for name, data := range changed {
+		if _, err := tx.Exec("INSERT INTO networks (name, entry) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET entry = excluded.entry", name, data); err != nil {
+			return fmt.Errorf("failed to save pool network %s: %w", name, err)
+		}
+	}
+	for _, name := range removed {
+		if _, err := tx.Exec("DELETE FROM networks WHERE name = ?", name); err != nil {
+			return fmt.Errorf("failed to delete pool network %s: %w", name, err)
+		}
+	}
+	for key, value := range meta {
+		if err := setMeta(tx, key, value); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit pool state: %w", err)
+	}
+
+	for name, data := range changed {
+		s.saved[name] = data
+	}
+	for _, name := range removed {
+		delete(s.saved, name)
+	}
+	for key, value := range meta {
+		s.savedMeta[key] = value
+	}
+
+	return nil
+}
+
+func setMeta(tx *sql.Tx, key, value string) error {
+	if _, err := tx.Exec("INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value", key, value); err != nil {
+		return fmt.Errorf("failed to save pool metadata %s: %w", key, err)
+	}
+	return nil
+}
+
+// migrateJSON imports the JSON state file into a database that has never
+// held any state, and renames the file once it is imported
+func (s *sqliteStore) migrateJSON(stateFile string) error {
+	var networks int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM networks").Scan(&networks); err != nil {
+		return fmt.Errorf("failed to read pool database: %w", err)
+	}
+	var migrated string
+	err := s.db.QueryRow("SELECT value FROM meta WHERE key = ?", metaMigratedFrom).Scan(&migrated)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read pool database: %w", err)
+	}
+	if networks > 0 || migrated != "" {
+		return nil
+	}
+
+	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
+		return nil
+	}
+
+	state, err := loadState(stateFile)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", stateFile, err)
+	}
+	if err := s.Save(state); err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", stateFile, err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", stateFile, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := setMeta(tx, metaMigratedFrom, stateFile); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", stateFile, err)
+	}
+
+	if err := os.Rename(stateFile, stateFile+".migrated"); err != nil {
+		return fmt.Errorf("migrated %s but failed to rename it: %w", stateFile, err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) Close() error {
+	err := s.db.Close()
+	if releaseErr := s.lease.Release(); err == nil {
+		err = releaseErr
+	}
+	return err
+}
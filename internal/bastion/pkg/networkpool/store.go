@@ -0,0 +1,181 @@
+package networkpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/ha"
+)
+
+const (
+	// StoreJSON keeps the pool state in a single JSON file, rewritten on every change
+	StoreJSON = "json"
+	// StoreSQLite keeps the pool state in a SQLite database in WAL mode,
+	// writing only the networks that changed
+	StoreSQLite = "sqlite"
+
+	defaultDatabaseName = "network_pool.db"
+)
+
+// ErrStateLocked is returned when another process has the pool state open
+var ErrStateLocked = errors.New("network pool state is in use by another bastion")
+
+// Store persists the pool's state across restarts
+type Store interface {
+	// Load returns the saved state, or an empty one if nothing was saved yet
+	Load() (*NetworkPoolState, error)
+	// Save records state. It is called with state.mu held for reading.
+	Save(state *NetworkPoolState) error
+	Close() error
+}
+
+// StoreConfig selects the pool's state backend
+type StoreConfig struct {
+	// Backend is StoreJSON or StoreSQLite
+	Backend string
+	// DatabasePath is the SQLite database, by default next to the JSON state file
+	DatabasePath string
+}
+
+// StoreConfigFromEnv reads BASTION_POOL_STORE ("json", the default, or
+// "sqlite") and BASTION_POOL_DATABASE
+func StoreConfigFromEnv() (StoreConfig, error) {
+	config := StoreConfig{
+		Backend:      strings.ToLower(os.Getenv("BASTION_POOL_STORE")),
+		DatabasePath: os.Getenv("BASTION_POOL_DATABASE"),
+	}
+
+	switch config.Backend {
+	case "":
+		config.Backend = StoreJSON
+	case StoreJSON, StoreSQLite:
+	default:
+		return config, fmt.Errorf("unknown pool store %q (want %q or %q)", config.Backend, StoreJSON, StoreSQLite)
+	}
+
+	return config, nil
+}
+
+// OpenStore opens the configured backend. A new SQLite database takes over
+// the networks in an existing JSON state file, which is then renamed with a
+// .migrated suffix so it is not imported twice.
+//
+// The store is locked with an exclusive flock on a ".lock" file beside it
+// until Close, so a second bastion pointed at the same state fails with
+// ErrStateLocked rather than silently overwriting the first one's view.
+func OpenStore(stateFile string, config StoreConfig) (Store, error) {
+	if config.Backend != StoreSQLite {
+		lease, err := lockState(stateFile)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonStore{path: stateFile, lease: lease}, nil
+	}
+
+	path := config.DatabasePath
+	if path == "" {
+		path = filepath.Join(filepath.Dir(stateFile), defaultDatabaseName)
+	}
+
+	if err := ensureStateDir(path); err != nil {
+		return nil, err
+	}
+
+	lease, err := lockState(path)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := openSQLiteStore(path)
+	if err != nil {
+		_ = lease.Release()
+		return nil, err
+	}
+	store.lease = lease
+
+	if err := store.migrateJSON(stateFile); err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// lockState takes the exclusive lock on the state at path
+func lockState(path string) (*ha.Lease, error) {
+	lease, err := ha.TryAcquire(path + ".lock")
+	if errors.Is(err, ha.ErrLocked) {
+		return nil, fmt.Errorf("%w: %w", ErrStateLocked, err)
+	}
+	return lease, err
+}
+
+// jsonStore is the original single-file backend
+type jsonStore struct {
+	path  string
+	lease *ha.Lease
+}
+
+func (s *jsonStore) Load() (*NetworkPoolState, error) {
+	return loadState(s.path)
+}
+
+func (s *jsonStore) Save(state *NetworkPoolState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	tmpFile := s.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, stateFilePermissions); err != nil {
+		return fmt.Errorf("failed to write temp state file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile, s.path); err != nil {
+		_ = os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename state file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *jsonStore) Close() error {
+	return s.lease.Release()
+}
+
+func loadState(stateFile string) (*NetworkPoolState, error) {
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newState(), nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state NetworkPoolState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+
+	if state.Networks == nil {
+		state.Networks = make(map[string]*NetworkEntry)
+	}
+	if state.ConfigIndex == nil {
+		state.ConfigIndex = make(map[string][]string)
+	}
+
+	return &state, nil
+}
+
+func newState() *NetworkPoolState {
+	return &NetworkPoolState{
+		Networks:    make(map[string]*NetworkEntry),
+		ConfigIndex: make(map[string][]string),
+		LastCleanup: time.Now(),
+	}
+}
@@ -0,0 +1,190 @@
+package networkpool
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreConfigFromEnv(t *testing.T) {
+	t.Setenv("BASTION_POOL_STORE", "")
+	if config, err := StoreConfigFromEnv(); err != nil || config.Backend != StoreJSON {
+		t.Errorf("StoreConfigFromEnv() = %+v, %v, want the JSON store", config, err)
+	}
+
+	t.Setenv("BASTION_POOL_STORE", "SQLite")
+	t.Setenv("BASTION_POOL_DATABASE", "/tmp/pool.db")
+	if config, err := StoreConfigFromEnv(); err != nil || config.Backend != StoreSQLite || config.DatabasePath != "/tmp/pool.db" {
+		t.Errorf("StoreConfigFromEnv() = %+v, %v", config, err)
+	}
+
+	t.Setenv("BASTION_POOL_STORE", "etcd")
+	if _, err := StoreConfigFromEnv(); err == nil {
+		t.Error("StoreConfigFromEnv() accepted an unknown backend")
+	}
+}
+
+func testState() *NetworkPoolState {
+	owner := "container-1"
+	state := newState()
+	state.LastCleanup = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	state.Networks["iso-net-a"] = &NetworkEntry{NetworkName: "iso-net-a", NetworkID: "id-a", Subnet: "10.20.0.0/24", ConfigHash: "hash"}
+	state.Networks["iso-net-b"] = &NetworkEntry{NetworkName: "iso-net-b", NetworkID: "id-b", Subnet: "10.20.1.0/24", ConfigHash: "hash", CurrentContainer: &owner}
+	state.ConfigIndex["hash"] = []string{"iso-net-a"}
+	state.Subnets = newSubnetBitmap(DefaultSubnetConfig())
+	state.Subnets.set(0)
+	state.Subnets.set(1)
+	return state
+}
+
+func TestSQLiteStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(filepath.Join(dir, "network_pool.json"), StoreConfig{Backend: StoreSQLite})
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+
+	if info, err := os.Stat(filepath.Join(dir, defaultDatabaseName)); err != nil || info.Mode().Perm() != stateFilePermissions {
+		t.Errorf("database file = %v, %v, want mode %o", info, err, stateFilePermissions)
+	}
+
+	state := testState()
+	if err := store.Save(state); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Only changed networks are written again
+	sqlite := store.(*sqliteStore)
+	before := string(sqlite.saved["iso-net-b"])
+	state.Networks["iso-net-a"].ReuseCount = 3
+	delete(state.Networks, "iso-net-b")
+	if err := store.Save(state); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, ok := sqlite.saved["iso-net-b"]; ok || before == "" {
+		t.Errorf("removed network still recorded as saved")
+	}
+
+	reopened, err := openSQLiteStore(filepath.Join(dir, defaultDatabaseName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	loaded, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Networks) != 1 || loaded.Networks["iso-net-a"].ReuseCount != 3 {
+		t.Errorf("loaded networks = %+v", loaded.Networks)
+	}
+	if got := loaded.ConfigIndex["hash"]; len(got) != 1 || got[0] != "iso-net-a" {
+		t.Errorf("rebuilt config index = %v", loaded.ConfigIndex)
+	}
+	if !loaded.LastCleanup.Equal(state.LastCleanup) {
+		t.Errorf("last cleanup = %s, want %s", loaded.LastCleanup, state.LastCleanup)
+	}
+	if !loaded.Subnets.matches(DefaultSubnetConfig()) || !loaded.Subnets.isSet(1) {
+		t.Errorf("subnet bitmap not restored: %+v", loaded.Subnets.Range)
+	}
+}
+
+func TestSQLiteStoreMigratesJSON(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(dir, "network_pool.json")
+
+	data, err := json.Marshal(testState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stateFile, data, stateFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := OpenStore(stateFile, StoreConfig{Backend: StoreSQLite})
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	loaded, err := store.Load()
+	store.Close()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Networks) != 2 || *loaded.Networks["iso-net-b"].CurrentContainer != "container-1" {
+		t.Errorf("migrated networks = %+v", loaded.Networks)
+	}
+
+	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+		t.Errorf("JSON state file still in place after migration: %v", err)
+	}
+	if _, err := os.Stat(stateFile + ".migrated"); err != nil {
+		t.Errorf("migrated JSON state file missing: %v", err)
+	}
+
+	// A JSON file reappearing later is not imported over the database
+	stale := newState()
+	stale.Networks["iso-net-z"] = &NetworkEntry{NetworkName: "iso-net-z"}
+	data, _ = json.Marshal(stale)
+	if err := os.WriteFile(stateFile, data, stateFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+	store, err = OpenStore(stateFile, StoreConfig{Backend: StoreSQLite})
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	if loaded, err := store.Load(); err != nil || loaded.Networks["iso-net-z"] != nil {
+		t.Errorf("Load() after reopening = %v, %v", loaded, err)
+	}
+}
+
+func TestJSONStore(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "network_pool.json")
+	store, err := OpenStore(stateFile, StoreConfig{Backend: StoreJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded, err := store.Load(); err != nil || len(loaded.Networks) != 0 {
+		t.Fatalf("Load() of a missing file = %v, %v", loaded, err)
+	}
+
+	if err := store.Save(testState()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := store.Load()
+	if err != nil || len(loaded.Networks) != 2 || loaded.ConfigIndex["hash"][0] != "iso-net-a" {
+		t.Errorf("Load() = %+v, %v", loaded, err)
+	}
+}
+
+func TestOpenStoreLocksState(t *testing.T) {
+	for _, backend := range []string{StoreJSON, StoreSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			stateFile := filepath.Join(t.TempDir(), "network_pool.json")
+			config := StoreConfig{Backend: backend}
+
+			store, err := OpenStore(stateFile, config)
+			if err != nil {
+				t.Fatalf("OpenStore() error = %v", err)
+			}
+
+			if _, err := OpenStore(stateFile, config); !errors.Is(err, ErrStateLocked) {
+				t.Fatalf("second OpenStore() = %v, want ErrStateLocked", err)
+			}
+
+			if err := store.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			reopened, err := OpenStore(stateFile, config)
+			if err != nil {
+				t.Fatalf("OpenStore() after Close: %v", err)
+			}
+			reopened.Close()
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -71,6 +73,42 @@ func TestSubnetConfigFromEnv(t *testing.T) {
 		}
 	})
 
+	t.Run("excluded subnets", func(t *testing.T) {
+		os.Unsetenv("BASTION_SUBNET_BASE")
+		os.Unsetenv("BASTION_SUBNET_MASK")
+		t.Setenv("BASTION_EXCLUDED_SUBNETS", "10.20.0.0/24,10.20.128.0/17")
+		t.Setenv("BASTION_EXCLUDE_ROUTED_SUBNETS", "true")
+
+		config := SubnetConfigFromEnv()
+
+		if len(config.ExcludedSubnets) != 2 {
+			t.Errorf("ExcludedSubnets = %v, want 2 entries", config.ExcludedSubnets)
+		}
+		if !config.ExcludeRoutedSubnets {
+			t.Error("ExcludeRoutedSubnets = false, want true")
+		}
+	})
+
+	t.Run("subnet exclude is an alias of excluded subnets", func(t *testing.T) {
+		os.Unsetenv("BASTION_SUBNET_BASE")
+		os.Unsetenv("BASTION_SUBNET_MASK")
+		t.Setenv("BASTION_EXCLUDED_SUBNETS", "10.20.0.0/24")
+		t.Setenv("BASTION_SUBNET_EXCLUDE", "100.64.0.0/10,10.20.8.0/21")
+
+		config := SubnetConfigFromEnv()
+
+		if len(config.ExcludedSubnets) != 3 || config.ExcludedSubnets[2] != "10.20.8.0/21" {
+			t.Errorf("ExcludedSubnets = %v, want both variables combined", config.ExcludedSubnets)
+		}
+
+		t.Setenv("BASTION_EXCLUDED_SUBNETS", "")
+		config = SubnetConfigFromEnv()
+
+		if len(config.ExcludedSubnets) != 2 || config.ExcludedSubnets[0] != "100.64.0.0/10" {
+			t.Errorf("ExcludedSubnets = %v, want the alias alone honored", config.ExcludedSubnets)
+		}
+	})
+
 	t.Run("subnet mask out of range ignored", func(t *testing.T) {
 		os.Unsetenv("BASTION_SUBNET_BASE")
 		os.Setenv("BASTION_SUBNET_MASK", "30")
@@ -83,6 +121,146 @@ func TestSubnetConfigFromEnv(t *testing.T) {
 	})
 }
 
+func TestSubnetConfigDefaultMTU(t *testing.T) {
+	tests := []struct {
+		value string
+		want  uint32
+	}{
+		{"", 0},
+		{"1400", 1400},
+		{"100", 0},
+		{"invalid", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("BASTION_NETWORK_MTU", tt.value)
+			if got := SubnetConfigFromEnv().DefaultMTU; got != tt.want {
+				t.Errorf("DefaultMTU = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExcludedSubnets(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"single", "10.20.5.0/24", []string{"10.20.5.0/24"}, false},
+		{"list with spaces", "10.0.0.0/8, 172.16.0.0/12 ,", []string{"10.0.0.0/8", "172.16.0.0/12"}, false},
+		{"host bits normalized", "10.20.5.7/24", []string{"10.20.5.0/24"}, false},
+		{"missing mask", "10.20.5.0", nil, true},
+		{"ipv6", "fd00::/8", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExcludedSubnets(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExcludedSubnets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseExcludedSubnets() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].String() != tt.want[i] {
+					t.Errorf("ParseExcludedSubnets()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseRouteTable(t *testing.T) {
+	table := strings.Join([]string{
+		"Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT",
+		"eth0\t00000000\t0100000A\t0003\t0\t0\t0\t00000000\t0\t0\t0",
+		"eth0\t0000000A\t00000000\t0001\t0\t0\t0\t0000FFFF\t0\t0\t0",
+		"eth1\t0000140A\t0101000A\t0003\t0\t0\t0\t00FFFFFF\t0\t0\t0",
+		"docker0\t000011AC\t00000000\t0001\t0\t0\t0\t0000FFFF\t0\t0\t0",
+		"br-1a2b3c\t0001140A\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0",
+		"",
+	}, "\n")
+
+	routes, err := parseRouteTable(strings.NewReader(table))
+	if err != nil {
+		t.Fatalf("parseRouteTable() error = %v", err)
+	}
+
+	want := []string{"10.0.0.0/16", "10.20.0.0/24"}
+	if len(routes) != len(want) {
+		t.Fatalf("parseRouteTable() = %v, want %v", routes, want)
+	}
+	for i := range routes {
+		if routes[i].String() != want[i] {
+			t.Errorf("parseRouteTable()[%d] = %s, want %s", i, routes[i], want[i])
+		}
+	}
+}
+
+func TestResolveExclusions(t *testing.T) {
+	tests := []struct {
+		name      string
+		excluded  []string
+		wantCount int
+		wantErr   bool
+	}{
+		{"no exclusions", nil, 0, false},
+		{"partial overlap", []string{"10.240.0.0/22"}, 1, false},
+		{"whole range excluded", []string{"10.240.0.0/20"}, 0, true},
+		{"invalid cidr", []string{"not-a-cidr"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &Pool{subnetConfig: SubnetConfig{
+				BaseIP:          "10.240.0.0",
+				SubnetMask:      20,
+				MaxSubnets:      16,
+				ExcludedSubnets: tt.excluded,
+			}}
+
+			excluded, err := pool.resolveExclusions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveExclusions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(excluded) != tt.wantCount {
+				t.Errorf("resolveExclusions() returned %d exclusions, want %d", len(excluded), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestOverlapsAny(t *testing.T) {
+	excluded, err := ParseExcludedSubnets("10.20.4.0/22,10.20.9.128/25")
+	if err != nil {
+		t.Fatalf("ParseExcludedSubnets() error = %v", err)
+	}
+
+	tests := []struct {
+		subnet string
+		want   bool
+	}{
+		{"10.20.3.0/24", false},
+		{"10.20.4.0/24", true},
+		{"10.20.7.0/24", true},
+		{"10.20.8.0/24", false},
+		{"10.20.9.0/24", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subnet, func(t *testing.T) {
+			if got := overlapsAny(tt.subnet, excluded) != nil; got != tt.want {
+				t.Errorf("overlapsAny(%s) = %v, want %v", tt.subnet, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGenerateSubnet(t *testing.T) {
 	if !dockerAvailable() {
 		t.Skip("Docker not available")
@@ -377,3 +555,57 @@ func parseIP(s string) []byte {
 	fmt.Sscanf(s, "%d.%d.%d.%d", &a, &b, &c, &d)
 	return []byte{a, b, c, d}
 }
+
+func TestAllocationMask(t *testing.T) {
+	config := SubnetConfig{BaseIP: "10.20.0.0", SubnetMask: 16, MaxSubnets: 256}
+	pool := &Pool{subnetConfig: config, state: &NetworkPoolState{}}
+	excluded, _ := ParseExcludedSubnets("10.20.0.0/24")
+	pool.excluded = excluded
+	pool.initSubnets()
+
+	if pool.allocationMask("", nil) != pool.allocMasks[""] {
+		t.Error("allocationMask() without request exclusions copied the pool's mask")
+	}
+
+	exclude, _ := ParseExcludedSubnets("10.20.1.128/25,10.20.4.0/23,10.0.0.0/8,192.168.0.0/16")
+	mask := pool.allocationMask("", exclude)
+
+	// 10.0.0.0/8 covers the whole range; check the narrower ones without it
+	narrow := pool.allocationMask("", exclude[:2])
+	for i, want := range []bool{true, true, false, false, true, true, false} {
+		if got := narrow.isSet(i); got != want {
+			t.Errorf("index %d excluded = %v, want %v", i, got, want)
+		}
+	}
+	if !mask.isSet(255) {
+		t.Error("a range covering the whole pool left subnets allocatable")
+	}
+	if pool.allocMasks[""].isSet(1) {
+		t.Error("request exclusions leaked into the pool's own mask")
+	}
+
+	baseIP := net.ParseIP(config.BaseIP).To4()
+	if subnet, ok := pool.takeSubnet(baseIP, narrow); !ok || subnet != "10.20.2.0/24" {
+		t.Errorf("takeSubnet() = %s, %v, want 10.20.2.0/24", subnet, ok)
+	}
+	if _, ok := pool.takeSubnet(baseIP, mask); ok {
+		t.Error("takeSubnet() allocated inside a request exclusion")
+	}
+}
+
+func TestFindAvailableNetworkSkipsExcluded(t *testing.T) {
+	pool := &Pool{
+		state: &NetworkPoolState{
+			Networks: map[string]*NetworkEntry{
+				"iso-net-a": {NetworkName: "iso-net-a", ConfigHash: "hash", Subnet: "10.20.1.0/24"},
+				"iso-net-b": {NetworkName: "iso-net-b", ConfigHash: "hash", Subnet: "10.20.2.0/24"},
+			},
+			ConfigIndex: map[string][]string{"hash": {"iso-net-a", "iso-net-b"}},
+		},
+	}
+
+	exclude, _ := ParseExcludedSubnets("10.20.1.0/24")
+	if got := pool.findAvailableNetwork("hash", "", NetworkOptions{}, exclude); got != "iso-net-b" {
+		t.Errorf("findAvailableNetwork() = %q, want the network outside the exclusion", got)
+	}
+}
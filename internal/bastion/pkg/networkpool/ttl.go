@@ -0,0 +1,67 @@
+package networkpool
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long a released network stays pooled for reuse
+	DefaultTTL = 1 * time.Hour
+	// DefaultMaxTTL bounds the TTL a caller may ask for
+	DefaultMaxTTL = 24 * time.Hour
+	// DefaultCleanupInterval is how often expired networks are removed
+	DefaultCleanupInterval = 5 * time.Minute
+)
+
+// TTLConfig sets how long released networks are kept for reuse
+type TTLConfig struct {
+	// TTL applies to networks released without one of their own
+	TTL time.Duration
+	// MaxTTL caps the TTLs given in Acquire and Release
+	MaxTTL time.Duration
+	// CleanupInterval is how often networks past their TTL are removed
+	CleanupInterval time.Duration
+}
+
+func DefaultTTLConfig() TTLConfig {
+	return TTLConfig{
+		TTL:             DefaultTTL,
+		MaxTTL:          DefaultMaxTTL,
+		CleanupInterval: DefaultCleanupInterval,
+	}
+}
+
+// TTLConfigFromEnv reads BASTION_POOL_TTL, BASTION_POOL_MAX_TTL and
+// BASTION_POOL_CLEANUP_INTERVAL as durations, ignoring invalid values. Busy
+// fleets can keep networks warm longer; small nodes can reclaim them sooner.
+func TTLConfigFromEnv() TTLConfig {
+	config := DefaultTTLConfig()
+
+	if v, err := time.ParseDuration(os.Getenv("BASTION_POOL_TTL")); err == nil && v >= 0 {
+		config.TTL = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("BASTION_POOL_MAX_TTL")); err == nil && v > 0 {
+		config.MaxTTL = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("BASTION_POOL_CLEANUP_INTERVAL")); err == nil && v > 0 {
+		config.CleanupInterval = v
+	}
+
+	if config.TTL > config.MaxTTL {
+		config.MaxTTL = config.TTL
+	}
+	return config
+}
+
+// releaseTTL picks how long a network released now stays pooled: the TTL
+// given on release, else the one given when it was acquired, else the pool's
+func (c TTLConfig) releaseTTL(override *time.Duration, entry *NetworkEntry) time.Duration {
+	ttl := c.TTL
+	if override != nil {
+		ttl = *override
+	} else if entry.TTL != nil {
+		ttl = *entry.TTL
+	}
+	return min(max(ttl, 0), c.MaxTTL)
+}
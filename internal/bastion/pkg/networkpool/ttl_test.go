@@ -0,0 +1,58 @@
+package networkpool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLConfigFromEnv(t *testing.T) {
+	t.Setenv("BASTION_POOL_TTL", "30m")
+	t.Setenv("BASTION_POOL_MAX_TTL", "2h")
+	t.Setenv("BASTION_POOL_CLEANUP_INTERVAL", "1m")
+
+	config := TTLConfigFromEnv()
+	if config.TTL != 30*time.Minute || config.MaxTTL != 2*time.Hour || config.CleanupInterval != time.Minute {
+		t.Errorf("TTLConfigFromEnv() = %+v", config)
+	}
+
+	t.Setenv("BASTION_POOL_TTL", "48h")
+	t.Setenv("BASTION_POOL_MAX_TTL", "")
+	t.Setenv("BASTION_POOL_CLEANUP_INTERVAL", "0s")
+	config = TTLConfigFromEnv()
+	if config.MaxTTL != 48*time.Hour {
+		t.Errorf("max TTL = %s, want it raised to the TTL", config.MaxTTL)
+	}
+	if config.CleanupInterval != DefaultCleanupInterval {
+		t.Errorf("cleanup interval = %s, want the default for 0s", config.CleanupInterval)
+	}
+
+	t.Setenv("BASTION_POOL_TTL", "soon")
+	if config := TTLConfigFromEnv(); config.TTL != DefaultTTL {
+		t.Errorf("TTL = %s, want the default for an invalid value", config.TTL)
+	}
+}
+
+func TestReleaseTTL(t *testing.T) {
+	config := TTLConfig{TTL: time.Hour, MaxTTL: 4 * time.Hour, CleanupInterval: time.Minute}
+	duration := func(d time.Duration) *time.Duration { return &d }
+
+	tests := []struct {
+		name     string
+		override *time.Duration
+		acquired *time.Duration
+		want     time.Duration
+	}{
+		{"pool default", nil, nil, time.Hour},
+		{"lease from acquire", nil, duration(2 * time.Hour), 2 * time.Hour},
+		{"release overrides acquire", duration(10 * time.Minute), duration(2 * time.Hour), 10 * time.Minute},
+		{"zero reclaims at the next pass", duration(0), nil, 0},
+		{"capped", duration(72 * time.Hour), nil, 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.releaseTTL(tt.override, &NetworkEntry{TTL: tt.acquired}); got != tt.want {
+				t.Errorf("releaseTTL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package podgroup tracks which container chains share a pod group and keeps
+// the accept rules that let group members reach each other in step with it.
+package podgroup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+)
+
+type member struct {
+	group       string
+	containerIP string
+}
+
+// Registry records pod group membership by chain. Traffic between members is
+// accepted in both directions: each member's chain allows every peer's IP.
+type Registry struct {
+	mu      sync.Mutex
+	byChain map[string]member
+	groups  map[string]map[string]struct{}
+
+	// Overridable for tests
+	allow  func(ctx context.Context, chainName, peerIP string) error
+	revoke func(ctx context.Context, chainName, peerIP string)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		byChain: make(map[string]member),
+		groups:  make(map[string]map[string]struct{}),
+		allow:   iptables.AllowPeer,
+		revoke:  iptables.RevokePeer,
+	}
+}
+
+// Join puts the container behind chainName in group, leaving any group it was in,
+// and lets every existing member reach it. It returns the IPs of those members;
+// the caller allows them in the container's own chain. An empty group just
+// leaves. Joining the group the chain is already in changes nothing.
+func (r *Registry) Join(ctx context.Context, group string, chainName string, containerIP string) ([]string, error) {
+	if group != "" {
+		if err := validation.ValidatePodGroup(group); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.byChain[chainName]; ok {
+		if current.group == group && current.containerIP == containerIP {
+			return r.peers(group, chainName), nil
+		}
+		r.leave(ctx, chainName)
+	}
+
+	if group == "" {
+		return nil, nil
+	}
+
+	peers := r.peerChains(group, chainName)
+	for i, peer := range peers {
+		if err := r.allow(ctx, peer, containerIP); err != nil {
+			for _, added := range peers[:i] {
+				r.revoke(ctx, added, containerIP)
+			}
+			return nil, fmt.Errorf("failed to admit %s to pod group %s: %w", chainName, group, err)
+		}
+	}
+
+	r.byChain[chainName] = member{group: group, containerIP: containerIP}
+	if r.groups[group] == nil {
+		r.groups[group] = make(map[string]struct{})
+	}
+	r.groups[group][chainName] = struct{}{}
+
+	return r.peers(group, chainName), nil
+}
+
+// Restore records chainName as a member of group without changing any chain,
+// for membership whose rules a previous bastion already installed
+func (r *Registry) Restore(group string, chainName string, containerIP string) {
+	if group == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byChain[chainName] = member{group: group, containerIP: containerIP}
+	if r.groups[group] == nil {
+		r.groups[group] = make(map[string]struct{})
+	}
+	r.groups[group][chainName] = struct{}{}
+}
+
+// Leave removes chainName from its pod group and revokes the peers' access to it
+func (r *Registry) Leave(ctx context.Context, chainName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.leave(ctx, chainName)
+}
+
+// leave must be called with r.mu held
+func (r *Registry) leave(ctx context.Context, chainName string) {
+	current, ok := r.byChain[chainName]
+	if !ok {
+		return
+	}
+
+	for _, peer := range r.peerChains(current.group, chainName) {
+		r.revoke(ctx, peer, current.containerIP)
+	}
+
+	delete(r.byChain, chainName)
+	delete(r.groups[current.group], chainName)
+	if len(r.groups[current.group]) == 0 {
+		delete(r.groups, current.group)
+	}
+}
+
+// Group returns the pod group of chainName, or "" when it is in none
+func (r *Registry) Group(chainName string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.byChain[chainName].group
+}
+
+// Peers returns the IPs of the other members of chainName's pod group
+func (r *Registry) Peers(chainName string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.byChain[chainName]
+	if !ok {
+		return nil
+	}
+	return r.peers(current.group, chainName)
+}
+
+// peers must be called with r.mu held
+func (r *Registry) peers(group string, chainName string) []string {
+	chains := r.peerChains(group, chainName)
+	ips := make([]string, 0, len(chains))
+	for _, chain := range chains {
+		ips = append(ips, r.byChain[chain].containerIP)
+	}
+	return ips
+}
+
+// peerChains returns the other chains in group in a stable order; must be
+// called with r.mu held
+func (r *Registry) peerChains(group string, chainName string) []string {
+	chains := make([]string, 0, len(r.groups[group]))
+	for chain := range r.groups[group] {
+		if chain != chainName {
+			chains = append(chains, chain)
+		}
+	}
+	sort.Strings(chains)
+	return chains
+}
@@ -0,0 +1,162 @@
+package podgroup
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	chainA = "ISO-aaaaaaaaaaaaaaaa"
+	chainB = "ISO-bbbbbbbbbbbbbbbb"
+	chainC = "ISO-cccccccccccccccc"
+)
+
+// newTestRegistry returns a registry that records rules instead of running
+// iptables, keyed "chain -> peer IP"
+func newTestRegistry() (*Registry, map[string]bool) {
+	installed := make(map[string]bool)
+
+	r := NewRegistry()
+	r.allow = func(_ context.Context, chainName, peerIP string) error {
+		installed[chainName+" -> "+peerIP] = true
+		return nil
+	}
+	r.revoke = func(_ context.Context, chainName, peerIP string) {
+		delete(installed, chainName+" -> "+peerIP)
+	}
+
+	return r, installed
+}
+
+func TestJoin(t *testing.T) {
+	ctx := context.Background()
+	r, installed := newTestRegistry()
+
+	peers, err := r.Join(ctx, "web", chainA, "10.20.0.2")
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if len(peers) != 0 {
+		t.Errorf("first member got peers %v", peers)
+	}
+
+	peers, err = r.Join(ctx, "web", chainB, "10.20.1.2")
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if !reflect.DeepEqual(peers, []string{"10.20.0.2"}) {
+		t.Errorf("Join() peers = %v, want [10.20.0.2]", peers)
+	}
+	if !installed[chainA+" -> 10.20.1.2"] {
+		t.Errorf("existing member was not allowed to reach the new one: %v", installed)
+	}
+
+	// Another group sees neither member
+	peers, err = r.Join(ctx, "db", chainC, "10.20.2.2")
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if len(peers) != 0 || len(installed) != 1 {
+		t.Errorf("separate group got peers %v, rules %v", peers, installed)
+	}
+
+	// Rejoining the same group is a no-op
+	peers, err = r.Join(ctx, "web", chainB, "10.20.1.2")
+	if err != nil || !reflect.DeepEqual(peers, []string{"10.20.0.2"}) {
+		t.Errorf("rejoin = %v, %v", peers, err)
+	}
+	if len(installed) != 1 {
+		t.Errorf("rejoin changed rules: %v", installed)
+	}
+}
+
+func TestJoinSwitchesGroup(t *testing.T) {
+	ctx := context.Background()
+	r, installed := newTestRegistry()
+
+	_, _ = r.Join(ctx, "web", chainA, "10.20.0.2")
+	_, _ = r.Join(ctx, "web", chainB, "10.20.1.2")
+	_, _ = r.Join(ctx, "db", chainC, "10.20.2.2")
+
+	peers, err := r.Join(ctx, "db", chainB, "10.20.1.2")
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if !reflect.DeepEqual(peers, []string{"10.20.2.2"}) {
+		t.Errorf("Join() peers = %v, want [10.20.2.2]", peers)
+	}
+	want := map[string]bool{chainC + " -> 10.20.1.2": true}
+	if !reflect.DeepEqual(installed, want) {
+		t.Errorf("rules = %v, want %v", installed, want)
+	}
+	if got := r.Peers(chainA); len(got) != 0 {
+		t.Errorf("old group still lists %v", got)
+	}
+
+	// An empty group leaves
+	if _, err := r.Join(ctx, "", chainB, "10.20.1.2"); err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if r.Group(chainB) != "" || len(installed) != 0 {
+		t.Errorf("chain still grouped: %q, rules %v", r.Group(chainB), installed)
+	}
+}
+
+func TestJoinRollsBack(t *testing.T) {
+	ctx := context.Background()
+	r, installed := newTestRegistry()
+
+	_, _ = r.Join(ctx, "web", chainA, "10.20.0.2")
+	_, _ = r.Join(ctx, "web", chainB, "10.20.1.2")
+
+	r.allow = func(_ context.Context, chainName, peerIP string) error {
+		if chainName == chainB {
+			return errors.New("iptables failed")
+		}
+		installed[chainName+" -> "+peerIP] = true
+		return nil
+	}
+
+	if _, err := r.Join(ctx, "web", chainC, "10.20.2.2"); err == nil {
+		t.Fatal("expected error")
+	}
+	if installed[chainA+" -> 10.20.2.2"] {
+		t.Errorf("partial rules left behind: %v", installed)
+	}
+	if r.Group(chainC) != "" {
+		t.Error("failed join was recorded")
+	}
+}
+
+func TestJoinValidatesGroup(t *testing.T) {
+	r, _ := newTestRegistry()
+
+	if _, err := r.Join(context.Background(), "web;rm -rf", chainA, "10.20.0.2"); err == nil {
+		t.Error("expected error for invalid group")
+	}
+}
+
+func TestLeave(t *testing.T) {
+	ctx := context.Background()
+	r, installed := newTestRegistry()
+
+	_, _ = r.Join(ctx, "web", chainA, "10.20.0.2")
+	_, _ = r.Join(ctx, "web", chainB, "10.20.1.2")
+	_, _ = r.Join(ctx, "web", chainC, "10.20.2.2")
+
+	r.Leave(ctx, chainB)
+
+	for rule := range installed {
+		if rule == chainA+" -> 10.20.1.2" || rule == chainC+" -> 10.20.1.2" {
+			t.Errorf("rule %q survived leave", rule)
+		}
+	}
+	if !reflect.DeepEqual(r.Peers(chainA), []string{"10.20.2.2"}) {
+		t.Errorf("Peers() = %v", r.Peers(chainA))
+	}
+
+	// Leaving twice is harmless
+	r.Leave(ctx, chainB)
+}
@@ -0,0 +1,296 @@
+// Package portmap publishes container ports on the host, allocating host ports
+// from a fixed range and tracking the DNAT rules installed for each chain.
+package portmap
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+const (
+	DefaultMinPort = 30000
+	DefaultMaxPort = 32767
+
+	// MaxPortsPerContainer bounds the DNAT rules a single container can add
+	MaxPortsPerContainer = 16
+)
+
+type portKey struct {
+	protocol string
+	port     uint32
+}
+
+type exposure struct {
+	containerIP string
+	mappings    []*pb.PortMapping
+}
+
+// Publisher hands out host ports and installs the matching DNAT rules. Host
+// ports are confined to its range so a container can never shadow a host service.
+type Publisher struct {
+	minPort uint32
+	maxPort uint32
+
+	mu      sync.Mutex
+	used    map[portKey]struct{}
+	byChain map[string]*exposure
+
+	// Overridable for tests
+	isFree    func(protocol string, port uint32) bool
+	expose    func(ctx context.Context, chainName, containerIP string, mapping *pb.PortMapping) error
+	unexpose  func(ctx context.Context, chainName, containerIP string, mapping *pb.PortMapping)
+	randomInt func(n int) int
+}
+
+func NewPublisher(minPort, maxPort uint32) *Publisher {
+	return &Publisher{
+		minPort:   minPort,
+		maxPort:   maxPort,
+		used:      make(map[portKey]struct{}),
+		byChain:   make(map[string]*exposure),
+		isFree:    hostPortFree,
+		expose:    iptables.ExposePort,
+		unexpose:  iptables.UnexposePort,
+		randomInt: rand.Intn,
+	}
+}
+
+// ParseRange parses a "min-max" host port range
+func ParseRange(value string) (uint32, uint32, error) {
+	lo, hi, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid port range %q: expected min-max", value)
+	}
+
+	minPort, err := strconv.ParseUint(strings.TrimSpace(lo), 10, 16)
+	if err != nil || minPort == 0 {
+		return 0, 0, fmt.Errorf("invalid port range %q: bad minimum", value)
+	}
+	maxPort, err := strconv.ParseUint(strings.TrimSpace(hi), 10, 16)
+	if err != nil || maxPort < minPort {
+		return 0, 0, fmt.Errorf("invalid port range %q: bad maximum", value)
+	}
+
+	return uint32(minPort), uint32(maxPort), nil
+}
+
+// RangeFromEnv reads BASTION_EXPOSED_PORT_RANGE, falling back to the default
+// range when it is unset or invalid
+func RangeFromEnv() (uint32, uint32) {
+	if minPort, maxPort, err := ParseRange(os.Getenv("BASTION_EXPOSED_PORT_RANGE")); err == nil {
+		return minPort, maxPort
+	}
+	return DefaultMinPort, DefaultMaxPort
+}
+
+// Expose publishes ports for the container behind chainName and returns the
+// mappings with host ports filled in. On failure nothing from this call remains.
+func (p *Publisher) Expose(ctx context.Context, chainName string, containerIP string, ports []*pb.PortMapping) ([]*pb.PortMapping, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := p.byChain[chainName]
+	count := len(ports)
+	if existing != nil {
+		count += len(existing.mappings)
+	}
+	if count > MaxPortsPerContainer {
+		return nil, validation.ValidationError{
+			Field:   "ports",
+			Message: fmt.Sprintf("too many exposed ports: %d (max %d)", count, MaxPortsPerContainer),
+		}
+	}
+
+	var published []*pb.PortMapping
+	rollback := func() {
+		for _, m := range published {
+			p.unexpose(ctx, chainName, containerIP, m)
+			delete(p.used, portKey{m.Protocol, m.HostPort})
+		}
+	}
+
+	for _, requested := range ports {
+		mapping := &pb.PortMapping{
+			ContainerPort: requested.ContainerPort,
+			HostPort:      requested.HostPort,
+			Protocol:      requested.Protocol,
+		}
+		if mapping.Protocol == "" {
+			mapping.Protocol = "tcp"
+		}
+
+		if err := validation.ValidatePort(mapping.ContainerPort); err != nil {
+			rollback()
+			return nil, err
+		}
+		if err := validation.ValidateProtocol(mapping.Protocol); err != nil {
+			rollback()
+			return nil, err
+		}
+
+		hostPort, err := p.reserve(mapping.Protocol, mapping.HostPort)
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+		mapping.HostPort = hostPort
+
+		if err := p.expose(ctx, chainName, containerIP, mapping); err != nil {
+			delete(p.used, portKey{mapping.Protocol, hostPort})
+			rollback()
+			return nil, fmt.Errorf("failed to expose port %d/%s: %w", mapping.ContainerPort, mapping.Protocol, err)
+		}
+
+		published = append(published, mapping)
+	}
+
+	if existing == nil {
+		existing = &exposure{containerIP: containerIP}
+		p.byChain[chainName] = existing
+	}
+	existing.mappings = append(existing.mappings, published...)
+
+	return published, nil
+}
+
+// Restore tracks ports a previous bastion published for chainName, whose
+// rules are already installed, so they are reserved and later released
+func (p *Publisher) Restore(chainName string, containerIP string, mappings []*pb.PortMapping) {
+	if len(mappings) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := &exposure{containerIP: containerIP}
+	for _, m := range mappings {
+		p.used[portKey{m.Protocol, m.HostPort}] = struct{}{}
+		existing.mappings = append(existing.mappings, m)
+	}
+	p.byChain[chainName] = existing
+}
+
+// reserve claims a host port; must be called with p.mu held
+func (p *Publisher) reserve(protocol string, requested uint32) (uint32, error) {
+	if requested != 0 {
+		if requested < p.minPort || requested > p.maxPort {
+			return 0, validation.ValidationError{
+				Field:   "host_port",
+				Message: fmt.Sprintf("host port %d is outside the allowed range %d-%d", requested, p.minPort, p.maxPort),
+			}
+		}
+		if _, taken := p.used[portKey{protocol, requested}]; taken || !p.isFree(protocol, requested) {
+			return 0, fmt.Errorf("host port %d/%s is already in use", requested, protocol)
+		}
+		p.used[portKey{protocol, requested}] = struct{}{}
+		return requested, nil
+	}
+
+	// Start at a random offset so freed ports are not immediately reused
+	size := int(p.maxPort - p.minPort + 1)
+	start := p.randomInt(size)
+	for i := 0; i < size; i++ {
+		port := p.minPort + uint32((start+i)%size)
+		if _, taken := p.used[portKey{protocol, port}]; taken {
+			continue
+		}
+		if !p.isFree(protocol, port) {
+			continue
+		}
+		p.used[portKey{protocol, port}] = struct{}{}
+		return port, nil
+	}
+
+	return 0, fmt.Errorf("no free %s host ports in range %d-%d", protocol, p.minPort, p.maxPort)
+}
+
+// Release removes every port published for chainName
+func (p *Publisher) Release(ctx context.Context, chainName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := p.byChain[chainName]
+	if existing == nil {
+		return
+	}
+
+	for _, m := range existing.mappings {
+		p.unexpose(ctx, chainName, existing.containerIP, m)
+		delete(p.used, portKey{m.Protocol, m.HostPort})
+	}
+	delete(p.byChain, chainName)
+}
+
+// Unexpose removes the single port published for chainName on hostPort and
+// returns its mapping. It fails when the chain publishes no such port.
+func (p *Publisher) Unexpose(ctx context.Context, chainName string, protocol string, hostPort uint32) (*pb.PortMapping, error) {
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := p.byChain[chainName]
+	if existing != nil {
+		for i, m := range existing.mappings {
+			if m.Protocol != protocol || m.HostPort != hostPort {
+				continue
+			}
+
+			p.unexpose(ctx, chainName, existing.containerIP, m)
+			delete(p.used, portKey{m.Protocol, m.HostPort})
+			existing.mappings = append(existing.mappings[:i], existing.mappings[i+1:]...)
+			if len(existing.mappings) == 0 {
+				delete(p.byChain, chainName)
+			}
+			return m, nil
+		}
+	}
+
+	return nil, fmt.Errorf("host port %d/%s is not published for chain %s", hostPort, protocol, chainName)
+}
+
+// Mappings returns the ports currently published for chainName
+func (p *Publisher) Mappings(chainName string) []*pb.PortMapping {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := p.byChain[chainName]
+	if existing == nil {
+		return nil
+	}
+	return append([]*pb.PortMapping(nil), existing.mappings...)
+}
+
+// hostPortFree reports whether nothing on the host is bound to the port
+func hostPortFree(protocol string, port uint32) bool {
+	addr := net.JoinHostPort("", strconv.FormatUint(uint64(port), 10))
+
+	if protocol == "udp" {
+		conn, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
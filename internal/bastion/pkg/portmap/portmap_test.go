@@ -0,0 +1,219 @@
+package portmap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+const testChain = "ISO-0123456789abcdef"
+
+// newTestPublisher returns a publisher that records rules instead of running iptables
+func newTestPublisher(minPort, maxPort uint32) (*Publisher, map[uint32]bool) {
+	installed := make(map[uint32]bool)
+
+	p := NewPublisher(minPort, maxPort)
+	p.isFree = func(string, uint32) bool { return true }
+	p.randomInt = func(int) int { return 0 }
+	p.expose = func(_ context.Context, _, _ string, m *pb.PortMapping) error {
+		installed[m.HostPort] = true
+		return nil
+	}
+	p.unexpose = func(_ context.Context, _, _ string, m *pb.PortMapping) {
+		delete(installed, m.HostPort)
+	}
+
+	return p, installed
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		value   string
+		min     uint32
+		max     uint32
+		wantErr bool
+	}{
+		{"30000-32767", 30000, 32767, false},
+		{" 40000 - 40010 ", 40000, 40010, false},
+		{"8080-8080", 8080, 8080, false},
+		{"", 0, 0, true},
+		{"30000", 0, 0, true},
+		{"0-100", 0, 0, true},
+		{"200-100", 0, 0, true},
+		{"30000-70000", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			minPort, maxPort, err := ParseRange(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (minPort != tt.min || maxPort != tt.max) {
+				t.Errorf("ParseRange() = %d-%d, want %d-%d", minPort, maxPort, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestExposeAllocatesFromRange(t *testing.T) {
+	p, installed := newTestPublisher(30000, 30001)
+	ctx := context.Background()
+
+	published, err := p.Expose(ctx, testChain, "172.17.0.2", []*pb.PortMapping{
+		{ContainerPort: 8080},
+		{ContainerPort: 53, Protocol: "udp"},
+	})
+	if err != nil {
+		t.Fatalf("Expose() error = %v", err)
+	}
+
+	if published[0].HostPort != 30000 || published[0].Protocol != "tcp" {
+		t.Errorf("unexpected first mapping %v", published[0])
+	}
+	// TCP and UDP ports are allocated independently
+	if published[1].HostPort != 30000 || published[1].Protocol != "udp" {
+		t.Errorf("unexpected second mapping %v", published[1])
+	}
+	if len(p.Mappings(testChain)) != 2 {
+		t.Errorf("expected 2 tracked mappings, got %d", len(p.Mappings(testChain)))
+	}
+
+	// Second tcp port takes the remaining slot, third exhausts the range
+	if _, err := p.Expose(ctx, "ISO-fedcba9876543210", "172.17.0.3", []*pb.PortMapping{{ContainerPort: 80}}); err != nil {
+		t.Fatalf("Expose() error = %v", err)
+	}
+	if _, err := p.Expose(ctx, "ISO-fedcba9876543210", "172.17.0.3", []*pb.PortMapping{{ContainerPort: 81}}); err == nil {
+		t.Error("expected exhausted range error")
+	}
+
+	p.Release(ctx, testChain)
+	if len(p.Mappings(testChain)) != 0 {
+		t.Error("expected no mappings after release")
+	}
+	if installed[30000] {
+		t.Error("expected rules for released chain to be removed")
+	}
+
+	// Released ports can be allocated again
+	if _, err := p.Expose(ctx, testChain, "172.17.0.2", []*pb.PortMapping{{ContainerPort: 8080}}); err != nil {
+		t.Errorf("Expose() after release error = %v", err)
+	}
+}
+
+func TestExposeFixedHostPort(t *testing.T) {
+	p, _ := newTestPublisher(30000, 30010)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		mapping *pb.PortMapping
+		wantErr bool
+	}{
+		{"in range", &pb.PortMapping{ContainerPort: 80, HostPort: 30005}, false},
+		{"already published", &pb.PortMapping{ContainerPort: 81, HostPort: 30005}, true},
+		{"host service port", &pb.PortMapping{ContainerPort: 22, HostPort: 22}, true},
+		{"above range", &pb.PortMapping{ContainerPort: 80, HostPort: 30011}, true},
+		{"invalid container port", &pb.PortMapping{ContainerPort: 0}, true},
+		{"invalid protocol", &pb.PortMapping{ContainerPort: 80, Protocol: "icmp"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.Expose(ctx, testChain, "172.17.0.2", []*pb.PortMapping{tt.mapping})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expose() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	p.isFree = func(string, uint32) bool { return false }
+	if _, err := p.Expose(ctx, testChain, "172.17.0.2", []*pb.PortMapping{{ContainerPort: 80, HostPort: 30006}}); err == nil {
+		t.Error("expected error for host port bound by another process")
+	}
+}
+
+func TestExposeRollsBackOnFailure(t *testing.T) {
+	p, installed := newTestPublisher(30000, 30010)
+	ctx := context.Background()
+
+	expose := p.expose
+	p.expose = func(ctx context.Context, chainName, containerIP string, m *pb.PortMapping) error {
+		if m.ContainerPort == 9999 {
+			return errors.New("iptables failed")
+		}
+		return expose(ctx, chainName, containerIP, m)
+	}
+
+	_, err := p.Expose(ctx, testChain, "172.17.0.2", []*pb.PortMapping{
+		{ContainerPort: 80},
+		{ContainerPort: 9999},
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(installed) != 0 {
+		t.Errorf("expected rollback to remove rules, %d remain", len(installed))
+	}
+	if len(p.used) != 0 {
+		t.Errorf("expected rollback to release host ports, %d held", len(p.used))
+	}
+	if len(p.Mappings(testChain)) != 0 {
+		t.Error("expected no tracked mappings after failed expose")
+	}
+}
+
+func TestExposeLimit(t *testing.T) {
+	p, _ := newTestPublisher(30000, 30100)
+
+	ports := make([]*pb.PortMapping, MaxPortsPerContainer+1)
+	for i := range ports {
+		ports[i] = &pb.PortMapping{ContainerPort: uint32(8000 + i)}
+	}
+
+	if _, err := p.Expose(context.Background(), testChain, "172.17.0.2", ports); err == nil {
+		t.Error("expected error when exceeding the per-container limit")
+	}
+}
+
+func TestUnexposeSinglePort(t *testing.T) {
+	p, installed := newTestPublisher(30000, 30010)
+	ctx := context.Background()
+
+	if _, err := p.Expose(ctx, testChain, "172.17.0.2", []*pb.PortMapping{
+		{ContainerPort: 80, HostPort: 30001},
+		{ContainerPort: 53, HostPort: 30003, Protocol: "udp"},
+	}); err != nil {
+		t.Fatalf("Expose() error = %v", err)
+	}
+
+	if _, err := p.Unexpose(ctx, testChain, "tcp", 30002); err == nil {
+		t.Error("expected error for a port that is not published")
+	}
+	if _, err := p.Unexpose(ctx, "ISO-fedcba9876543210", "tcp", 30001); err == nil {
+		t.Error("expected error for a port published by another chain")
+	}
+
+	removed, err := p.Unexpose(ctx, testChain, "", 30001)
+	if err != nil {
+		t.Fatalf("Unexpose() error = %v", err)
+	}
+	if removed.ContainerPort != 80 || removed.Protocol != "tcp" {
+		t.Errorf("Unexpose() removed %v, want the tcp mapping", removed)
+	}
+
+	remaining := p.Mappings(testChain)
+	if len(remaining) != 1 || remaining[0].Protocol != "udp" {
+		t.Errorf("remaining mappings = %v, want the udp mapping", remaining)
+	}
+	if installed[30001] || !installed[30003] {
+		t.Errorf("installed rules = %v, want only the udp one", installed)
+	}
+
+	// The freed host port can be published again
+	if _, err := p.Expose(ctx, testChain, "172.17.0.2", []*pb.PortMapping{{ContainerPort: 8080, HostPort: 30001}}); err != nil {
+		t.Errorf("Expose() of a freed port error = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Package ratelimit bounds how fast each client may call the bastion, so a
+// burst of container launches from one runner cannot crowd out the others.
+//
+// Clients are told apart by their authenticated run ID, or by their address
+// when they send none. Calls over the limit fail with ResourceExhausted, which
+// the isolation-runner retries with backoff. Teardown calls are never limited:
+// holding back a cleanup only leaves more state behind.
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/metrics"
+)
+
+const (
+	// DefaultRate is the calls per second each client may sustain
+	DefaultRate = 20
+	// DefaultBurst is the calls each client may make at once
+	DefaultBurst = 40
+
+	// idleAfter is how long a client goes without calls before it is forgotten
+	idleAfter = 10 * time.Minute
+)
+
+// exemptMethods are never limited: health probes, and teardown that must go
+// through however busy the client is
+var exemptMethods = map[string]bool{
+	"/bastion.BastionService/Health":              true,
+	"/bastion.BastionService/CleanupChain":        true,
+	"/bastion.BastionService/ReleaseNetwork":      true,
+	"/bastion.BastionService/TeardownPortForward": true,
+	"/grpc.health.v1.Health/Check":                true,
+	"/grpc.health.v1.Health/Watch":                true,
+}
+
+// Limiter holds a token bucket per client
+type Limiter struct {
+	rate  rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastSweep time.Time
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// FromEnv reads BASTION_RATE_LIMIT, calls per second per client, and
+// BASTION_RATE_BURST. A rate of 0 turns limiting off.
+func FromEnv() *Limiter {
+	perSecond := DefaultRate
+	if v, err := strconv.Atoi(os.Getenv("BASTION_RATE_LIMIT")); err == nil && v >= 0 {
+		perSecond = v
+	}
+	burst := DefaultBurst
+	if v, err := strconv.Atoi(os.Getenv("BASTION_RATE_BURST")); err == nil && v > 0 {
+		burst = v
+	}
+	return New(float64(perSecond), burst)
+}
+
+// New returns a limiter allowing each client perSecond calls a second with
+// bursts of burst. A perSecond of 0 allows everything.
+func New(perSecond float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate.Limit(perSecond),
+		burst:   burst,
+		now:     time.Now,
+		clients: make(map[string]*client),
+	}
+}
+
+// Enabled reports whether calls are limited
+func (l *Limiter) Enabled() bool {
+	return l.rate > 0
+}
+
+// Allow reports whether the client named key may make a call now
+func (l *Limiter) Allow(key string) bool {
+	if !l.Enabled() {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > idleAfter {
+		for k, c := range l.clients {
+			if now.Sub(c.lastSeen) > idleAfter {
+				delete(l.clients, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(l.rate, l.burst)}
+		l.clients[key] = c
+	}
+	c.lastSeen = now
+	return c.limiter.AllowN(now, 1)
+}
+
+// clientKey names the caller in ctx: its run ID, or else its host
+func clientKey(ctx context.Context) string {
+	if runID := auth.FromContext(ctx).RunID; runID != "" {
+		return "run:" + runID
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "addr:" + host
+	}
+	return ""
+}
+
+func (l *Limiter) check(ctx context.Context, method string) error {
+	if exemptMethods[method] || l.Allow(clientKey(ctx)) {
+		return nil
+	}
+	metrics.RateLimited(method)
+	return status.Errorf(codes.ResourceExhausted, "rate limit of %g calls per second exceeded", float64(l.rate))
+}
+
+// UnaryServerInterceptor limits unary calls. It must run after authentication
+// so callers are told apart by run ID.
+func (l *Limiter) UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := l.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamServerInterceptor limits the opening of streams
+func (l *Limiter) StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+)
+
+func TestAllow(t *testing.T) {
+	l := New(1, 2)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	if !l.Allow("a") || !l.Allow("a") {
+		t.Fatal("the burst should be allowed")
+	}
+	if l.Allow("a") {
+		t.Error("a call over the burst should be refused")
+	}
+	if !l.Allow("b") {
+		t.Error("clients should have buckets of their own")
+	}
+
+	now = now.Add(time.Second)
+	if !l.Allow("a") {
+		t.Error("a token should be back after a second")
+	}
+}
+
+func TestAllowForgetsIdleClients(t *testing.T) {
+	l := New(1, 1)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+	now = now.Add(2 * idleAfter)
+	l.Allow("b")
+
+	if _, ok := l.clients["a"]; ok {
+		t.Error("idle client should have been forgotten")
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	l := New(0, 1)
+	for i := 0; i < 100; i++ {
+		if !l.Allow("a") {
+			t.Fatal("a disabled limiter should allow every call")
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("BASTION_RATE_LIMIT", "")
+	t.Setenv("BASTION_RATE_BURST", "")
+	if l := FromEnv(); l.rate != DefaultRate || l.burst != DefaultBurst {
+		t.Errorf("defaults = %v/%d, want %d/%d", l.rate, l.burst, DefaultRate, DefaultBurst)
+	}
+
+	t.Setenv("BASTION_RATE_LIMIT", "0")
+	if FromEnv().Enabled() {
+		t.Error("a rate of 0 should turn limiting off")
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l := New(1, 1)
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{RunID: "run_a"})
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+	call := func(method string) error {
+		_, err := l.UnaryServerInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	if err := call("/bastion.BastionService/ApplyRules"); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if err := call("/bastion.BastionService/ApplyRules"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second call: %v, want ResourceExhausted", err)
+	}
+	// Teardown gets through however busy the client is
+	if err := call("/bastion.BastionService/CleanupChain"); err != nil {
+		t.Errorf("cleanup: %v", err)
+	}
+}
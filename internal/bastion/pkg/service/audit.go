@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auditlog"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+const (
+	defaultAuditQueryLimit = 1000
+	maxAuditQueryLimit     = 10000
+)
+
+// QueryAuditLog returns the audit entries on disk that match the request,
+// for tracing what was done to a container after the fact
+func (s *Server) QueryAuditLog(ctx context.Context, req *pb.QueryAuditLogRequest) (*pb.QueryAuditLogResponse, error) {
+	// Entries name the containers and chains of every run
+	if !auth.FromContext(ctx).Admin {
+		return nil, status.Error(codes.PermissionDenied, "querying the audit log requires the admin token")
+	}
+	if s.audit == nil {
+		return nil, status.Error(codes.Unavailable, "audit log file is disabled")
+	}
+
+	filter := auditlog.Filter{
+		ContainerID: req.GetContainerId(),
+		ChainName:   req.GetChainName(),
+		Operation:   req.GetOperation(),
+		Limit:       defaultAuditQueryLimit,
+	}
+	if req.Since != nil {
+		filter.Since = time.UnixMilli(req.GetSince())
+	}
+	if req.Until != nil {
+		filter.Until = time.UnixMilli(req.GetUntil())
+	}
+	if req.Limit != nil && req.GetLimit() > 0 {
+		filter.Limit = int(min(req.GetLimit(), maxAuditQueryLimit))
+	}
+
+	entries, err := s.audit.Query(filter)
+	if err != nil {
+		return &pb.QueryAuditLogResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	resp := &pb.QueryAuditLogResponse{Success: true}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &pb.AuditEntry{
+			Timestamp:   e.Time.UnixMilli(),
+			Operation:   e.Operation,
+			ChainName:   e.ChainName,
+			ContainerId: e.ContainerID,
+			RunId:       e.RunID,
+			Principal:   e.Principal,
+			Success:     e.Success,
+		})
+	}
+	return resp, nil
+}
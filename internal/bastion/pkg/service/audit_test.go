@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auditlog"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestQueryAuditLog(t *testing.T) {
+	server, _ := newReconcileServer(map[string]iptables.ChainState{})
+
+	if _, err := server.QueryAuditLog(context.Background(), &pb.QueryAuditLogRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("QueryAuditLog() without a log error = %v, want Unavailable", err)
+	}
+
+	log, err := auditlog.Open(filepath.Join(t.TempDir(), "audit.jsonl"), auditlog.DefaultMaxBytes, auditlog.DefaultMaxFiles)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer log.Close()
+	server.SetAuditLog(log)
+
+	runCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RunIDMetadataKey, "run-1"))
+	runCtx = auth.WithPrincipal(runCtx, auth.Principal{RunID: "run-1"})
+	server.auditLog(runCtx, "setup_chain", chainA, "container-a", true)
+	server.auditLog(runCtx, "apply_rules", chainA, "container-a", false)
+	server.auditLog(context.Background(), "setup_chain", chainB, "container-b", true)
+
+	if _, err := server.QueryAuditLog(runCtx, &pb.QueryAuditLogRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("QueryAuditLog() by a run error = %v, want PermissionDenied", err)
+	}
+
+	resp, err := server.QueryAuditLog(context.Background(), &pb.QueryAuditLogRequest{ContainerId: strPtr("container-a")})
+	if err != nil || !resp.Success {
+		t.Fatalf("QueryAuditLog() = %v, %v", resp, err)
+	}
+	if len(resp.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(resp.Entries))
+	}
+	if e := resp.Entries[1]; e.Operation != "apply_rules" || e.Success || e.RunId != "run-1" || e.Principal != "run:run-1" || e.Timestamp == 0 {
+		t.Errorf("second entry = %+v", e)
+	}
+
+	limit := uint32(1)
+	resp, err = server.QueryAuditLog(context.Background(), &pb.QueryAuditLogRequest{Operation: strPtr("setup_chain"), Limit: &limit})
+	if err != nil || len(resp.Entries) != 1 || resp.Entries[0].ChainName != chainB {
+		t.Errorf("QueryAuditLog() with limit = %v, %v; want the latest setup_chain", resp, err)
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/chainstore"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/networkpool"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestChainAuthorization(t *testing.T) {
+	states := map[string]iptables.ChainState{chainA: {Exists: true}}
+	server, _ := newReconcileServer(states)
+
+	var cleaned []string
+	server.chains.cleanup = func(_ context.Context, chainName, _ string) error {
+		cleaned = append(cleaned, chainName)
+		return nil
+	}
+	if err := server.records.Put(chainA, chainstore.Record{ContainerID: "aaaaaaaaaaaa", ContainerIP: "172.20.1.10", Owner: "run_a"}); err != nil {
+		t.Fatal(err)
+	}
+	server.chainIPs[chainA] = "172.20.1.10"
+
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{RunID: "run_a"})
+	other := auth.WithPrincipal(context.Background(), auth.Principal{RunID: "run_b"})
+	policy := &pb.NetworkPolicy{}
+
+	denied := map[string]func() error{
+		"SetupChain": func() error {
+			_, err := server.SetupChain(other, &pb.SetupChainRequest{ChainName: chainA, ContainerId: "containerb", ContainerIp: "172.20.1.11"})
+			return err
+		},
+		"ApplyRules": func() error {
+			_, err := server.ApplyRules(other, &pb.ApplyRulesRequest{ChainName: chainA, Policy: policy})
+			return err
+		},
+		"UpdateNetworkPolicy": func() error {
+			_, err := server.UpdateNetworkPolicy(other, &pb.UpdateNetworkPolicyRequest{ChainName: chainA, Policy: policy})
+			return err
+		},
+		"ExposePorts": func() error {
+			_, err := server.ExposePorts(other, &pb.ExposePortsRequest{ChainName: chainA})
+			return err
+		},
+		"CleanupChain": func() error {
+			_, err := server.CleanupChain(other, &pb.CleanupChainRequest{ChainName: chainA})
+			return err
+		},
+		"CleanupChain of unrecorded chain": func() error {
+			_, err := server.CleanupChain(other, &pb.CleanupChainRequest{ChainName: chainB})
+			return err
+		},
+		"CollectOrphanedChains": func() error {
+			_, err := server.CollectOrphanedChains(owner, &pb.CollectOrphanedChainsRequest{DryRun: true})
+			return err
+		},
+	}
+	for name, call := range denied {
+		if code := status.Code(call()); code != codes.PermissionDenied {
+			t.Errorf("%s by another run: code = %v, want PermissionDenied", name, code)
+		}
+	}
+	if len(cleaned) != 0 {
+		t.Fatalf("denied calls cleaned up %v", cleaned)
+	}
+	if record, _ := server.records.Get(chainA); record.Owner != "run_a" {
+		t.Errorf("owner = %q after denied SetupChain, want run_a", record.Owner)
+	}
+
+	resp, err := server.CleanupChain(owner, &pb.CleanupChainRequest{ChainName: chainA})
+	if err != nil || !resp.Success {
+		t.Fatalf("CleanupChain by owner = %v, %v", resp, err)
+	}
+	if len(cleaned) != 1 || cleaned[0] != chainA {
+		t.Errorf("cleaned = %v, want [%s]", cleaned, chainA)
+	}
+}
+
+func TestSetupChainContainerIP(t *testing.T) {
+	server, _ := newReconcileServer(nil)
+	server.networkOwners["aaaaaaaaaaaa"] = "run_a"
+	server.networkOwners["bbbbbbbbbbbb"] = "run_b"
+	server.activeNetworks = func() ([]networkpool.NetworkEntry, error) {
+		a, b := "aaaaaaaaaaaa", "bbbbbbbbbbbb"
+		return []networkpool.NetworkEntry{
+			{NetworkName: "iso-net-a", Subnet: "172.20.1.0/24", CurrentContainer: &a},
+			{NetworkName: "iso-net-b", Subnet: "172.20.2.0/24", CurrentContainer: &b},
+		}, nil
+	}
+	if err := server.records.Put(chainB, chainstore.Record{ContainerID: "cccccccccccc", ContainerIP: "172.17.0.5", Owner: "run_b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	runA := auth.WithPrincipal(context.Background(), auth.Principal{RunID: "run_a"})
+	// run_c holds no pool network, as on the default bridge
+	runC := auth.WithPrincipal(context.Background(), auth.Principal{RunID: "run_c"})
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		ip     string
+		denied bool
+	}{
+		{"own network", runA, "172.20.1.10", false},
+		{"network of another run", runA, "172.20.2.10", true},
+		{"outside own networks", runA, "172.17.0.9", true},
+		{"bridge caller in network of another run", runC, "172.20.2.10", true},
+		{"bridge caller on chained address of another run", runC, "172.17.0.5", true},
+		{"bridge caller", runC, "172.17.0.9", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server.SetupChain(tt.ctx, &pb.SetupChainRequest{ChainName: chainA, ContainerId: "aaaaaaaaaaaa", ContainerIp: tt.ip})
+			if denied := status.Code(err) == codes.PermissionDenied; denied != tt.denied {
+				t.Errorf("SetupChain(%s) error = %v, want denied %v", tt.ip, err, tt.denied)
+			}
+			_ = server.records.Delete(chainA)
+		})
+	}
+}
+
+func TestNetworkAuthorization(t *testing.T) {
+	server, _ := newReconcileServer(nil)
+	server.networkOwners["aaaaaaaaaaaa"] = "run_a"
+
+	other := auth.WithPrincipal(context.Background(), auth.Principal{RunID: "run_b"})
+	_, err := server.ReleaseNetwork(other, &pb.ReleaseNetworkRequest{ContainerId: "aaaaaaaaaaaa", NetworkName: "iso-net-abc123"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ReleaseNetwork by another run: %v, want PermissionDenied", err)
+	}
+
+	_, err = server.AcquireNetwork(other, &pb.AcquireNetworkRequest{
+		ContainerId:   "aaaaaaaaaaaa",
+		NetworkConfig: &pb.NetworkConfig{ConfigHash: "abc"},
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("AcquireNetwork by another run: %v, want PermissionDenied", err)
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+)
+
+// flushConnections drops the tracked connections of a container whose chain
+// just changed, so they are checked against the rules anew. The rules are in
+// place either way, so a failure is only logged.
+func (s *Server) flushConnections(ctx context.Context, chainName string, containerIP string) {
+	deleted, err := s.chains.flush(ctx, containerIP)
+	if errors.Is(err, iptables.ErrConntrackUnavailable) {
+		if !s.conntrackMissing.Swap(true) {
+			s.logger.Warn("conntrack is not installed; established connections outlive policy changes until they close")
+		}
+		return
+	}
+	if err != nil {
+		s.logger.Warn("failed to flush container connections", "chain_name", chainName, "container_ip", containerIP, "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.logger.Info("container connections flushed", "chain_name", chainName, "container_ip", containerIP, "connections", deleted)
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestPolicyChangesFlushConnections(t *testing.T) {
+	server, _ := newReconcileServer(map[string]iptables.ChainState{chainA: {Exists: true}})
+	server.chainIPs[chainA] = "172.20.1.10"
+	server.policies[chainA] = &pb.NetworkPolicy{Policy: "allow"}
+
+	var flushed []string
+	server.chains.flush = func(_ context.Context, containerIP string) (int, error) {
+		flushed = append(flushed, containerIP)
+		return 3, nil
+	}
+	server.chains.update = func(context.Context, string, string, *pb.NetworkPolicy, *pb.NetworkPolicy, []*pb.PortMapping, []string) (iptables.RuleDiff, error) {
+		return iptables.RuleDiff{Rules: 2}, nil
+	}
+	server.chains.cleanup = func(context.Context, string, string) error {
+		return nil
+	}
+
+	ctx := context.Background()
+	resp, err := server.UpdateRules(ctx, &pb.UpdateRulesRequest{ChainName: chainA, Policy: &pb.NetworkPolicy{Policy: "deny"}})
+	if err != nil || !resp.Success {
+		t.Fatalf("UpdateRules() = %v, %v", resp, err)
+	}
+	cleanup, err := server.CleanupChain(ctx, &pb.CleanupChainRequest{ChainName: chainA})
+	if err != nil || !cleanup.Success {
+		t.Fatalf("CleanupChain() = %v, %v", cleanup, err)
+	}
+
+	if want := []string{"172.20.1.10", "172.20.1.10"}; !reflect.DeepEqual(flushed, want) {
+		t.Errorf("flushed connections of %v, want %v", flushed, want)
+	}
+}
+
+func TestFlushFailureKeepsPolicy(t *testing.T) {
+	server, _ := newReconcileServer(nil)
+	server.chainIPs[chainA] = "172.20.1.10"
+	server.chains.flush = func(context.Context, string) (int, error) {
+		return 0, iptables.ErrConntrackUnavailable
+	}
+	server.chains.update = func(context.Context, string, string, *pb.NetworkPolicy, *pb.NetworkPolicy, []*pb.PortMapping, []string) (iptables.RuleDiff, error) {
+		return iptables.RuleDiff{}, nil
+	}
+
+	for range 2 {
+		resp, err := server.UpdateRules(context.Background(), &pb.UpdateRulesRequest{ChainName: chainA, Policy: &pb.NetworkPolicy{Policy: "deny"}})
+		if err != nil || !resp.Success {
+			t.Fatalf("UpdateRules() without conntrack = %v, %v", resp, err)
+		}
+	}
+	if !server.conntrackMissing.Load() {
+		t.Error("the missing conntrack tool should be reported")
+	}
+
+	server.chains.flush = func(context.Context, string) (int, error) {
+		return 0, errors.New("conntrack failed")
+	}
+	if resp, err := server.UpdateRules(context.Background(), &pb.UpdateRulesRequest{ChainName: chainA, Policy: &pb.NetworkPolicy{Policy: "deny"}}); err != nil || !resp.Success {
+		t.Errorf("UpdateRules() with a failed flush = %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/metrics"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// DefaultChainGCInterval is how often chains are checked for a running container
+const DefaultChainGCInterval = 10 * time.Minute
+
+// ChainGCIntervalFromEnv reads BASTION_CHAIN_GC_INTERVAL as a duration, falling
+// back to the default when it is unset or invalid. Zero turns the periodic
+// collection off; CollectOrphanedChains still works.
+func ChainGCIntervalFromEnv() time.Duration {
+	return intervalFromEnv("BASTION_CHAIN_GC_INTERVAL", DefaultChainGCInterval)
+}
+
+// StartChainGC removes orphaned chains every interval until ctx is done. An
+// interval of zero leaves it off.
+func (s *Server) StartChainGC(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.collectOrphans(ctx, false); err != nil {
+					s.logger.Warn("orphaned chain collection failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *Server) CollectOrphanedChains(ctx context.Context, req *pb.CollectOrphanedChainsRequest) (*pb.CollectOrphanedChainsResponse, error) {
+	// Collection removes chains of every run
+	if !auth.FromContext(ctx).Admin {
+		s.auditLog(ctx, "collect_orphaned_chains", "", "", false)
+		return nil, status.Error(codes.PermissionDenied, "collecting orphaned chains requires the admin token")
+	}
+
+	orphaned, err := s.collectOrphans(ctx, req.DryRun)
+	if err != nil {
+		s.auditLog(ctx, "collect_orphaned_chains", "", "", false)
+		return &pb.CollectOrphanedChainsResponse{
+			Success:        false,
+			Error:          strPtr(err.Error()),
+			OrphanedChains: orphaned,
+		}, nil
+	}
+
+	s.auditLog(ctx, "collect_orphaned_chains", "", "", true)
+	return &pb.CollectOrphanedChainsResponse{
+		Success:        true,
+		OrphanedChains: orphaned,
+	}, nil
+}
+
+// collectOrphans removes every chain whose container is not running, or that
+// has no record of its container at all, and returns their names. Records
+// whose chain is already gone are checked too, so they do not pile up. With
+// dryRun nothing is removed.
+func (s *Server) collectOrphans(ctx context.Context, dryRun bool) ([]string, error) {
+	listed, err := s.chains.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string]struct{}, len(listed))
+	for _, chainName := range listed {
+		candidates[chainName] = struct{}{}
+	}
+	for chainName := range s.records.All() {
+		candidates[chainName] = struct{}{}
+	}
+	names := make([]string, 0, len(candidates))
+	for chainName := range candidates {
+		names = append(names, chainName)
+	}
+	sort.Strings(names)
+
+	var orphaned []string
+	for _, chainName := range names {
+		if err := ctx.Err(); err != nil {
+			return orphaned, err
+		}
+		orphan, err := s.collectChain(ctx, chainName, dryRun)
+		if err != nil {
+			s.logger.Warn("failed to collect chain", "chain_name", chainName, "error", err)
+			continue
+		}
+		if orphan {
+			orphaned = append(orphaned, chainName)
+		}
+	}
+	return orphaned, nil
+}
+
+// collectChain removes chainName if it is orphaned and reports whether it was
+func (s *Server) collectChain(ctx context.Context, chainName string, dryRun bool) (bool, error) {
+	s.firewallMu.Lock()
+	defer s.firewallMu.Unlock()
+
+	// Read under the lock: the chain may have been set up or cleaned up since it was listed
+	record, ok := s.records.Get(chainName)
+	if ok {
+		// Chains of clients that gave no usable container ID cannot be checked, so they are kept
+		if validation.ValidateContainerID(record.ContainerID) != nil {
+			return false, nil
+		}
+		running, err := s.containerRunning(ctx, record.ContainerID)
+		if err != nil {
+			return false, err
+		}
+		if running {
+			return false, nil
+		}
+	}
+
+	if dryRun {
+		return true, nil
+	}
+
+	if err := s.removeChain(ctx, chainName, record.ContainerIP); err != nil {
+		metrics.FirewallFailure("collect_orphaned_chains")
+		return false, err
+	}
+	s.orphansRemoved.Add(1)
+	s.logger.Info("orphaned chain removed", "chain_name", chainName, "container_id", record.ContainerID)
+	return true, nil
+}
+
+// dockerContainerRunning asks Docker whether containerID is running. A
+// container Docker does not know is not running; any other failure is an error.
+func dockerContainerRunning(ctx context.Context, containerID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "docker", "inspect", "--type", "container", "--format", "{{.State.Running}}", "--", containerID)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if bytes.Contains(output, []byte("No such")) {
+			return false, nil
+		}
+		return false, fmt.Errorf("docker inspect %s failed: %w: %s", containerID, err, output)
+	}
+	return strings.TrimSpace(string(output)) == "true", nil
+}
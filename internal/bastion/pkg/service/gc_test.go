@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/chainstore"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestCollectOrphanedChains(t *testing.T) {
+	states := map[string]iptables.ChainState{
+		chainA:                 {Exists: true},
+		chainB:                 {Exists: true},
+		"ISO-dddddddddddddddd": {Exists: true},
+		"ISO-eeeeeeeeeeeeeeee": {Exists: true},
+	}
+	server, _ := newReconcileServer(states)
+
+	var cleaned []string
+	server.chains.cleanup = func(_ context.Context, chainName, _ string) error {
+		cleaned = append(cleaned, chainName)
+		delete(states, chainName)
+		return nil
+	}
+	server.containerRunning = func(_ context.Context, containerID string) (bool, error) {
+		switch containerID {
+		case "runningcontainer":
+			return true, nil
+		case "brokencontainer":
+			return false, errors.New("docker unavailable")
+		}
+		return false, nil
+	}
+
+	store, err := chainstore.Open(filepath.Join(t.TempDir(), "chains.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := map[string]string{
+		chainA:                 "runningcontainer",
+		chainB:                 "exitedcontainer",
+		"ISO-eeeeeeeeeeeeeeee": "brokencontainer",
+		// The chain is already gone, so only the record is left to clear
+		chainC: "removedcontainer",
+	}
+	for chainName, containerID := range records {
+		if err := store.Put(chainName, chainstore.Record{ContainerID: containerID, ContainerIP: "10.20.0.2"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.RestoreChains(store)
+	if server.chainIPs[chainB] != "10.20.0.2" {
+		t.Errorf("container IP of %s was not restored", chainB)
+	}
+
+	ctx := context.Background()
+	resp, err := server.CollectOrphanedChains(ctx, &pb.CollectOrphanedChainsRequest{DryRun: true})
+	if err != nil || !resp.Success {
+		t.Fatalf("CollectOrphanedChains() = %v, %v", resp, err)
+	}
+	// Chain D has no record, so nothing ties it to a live container
+	want := []string{chainB, chainC, "ISO-dddddddddddddddd"}
+	if !reflect.DeepEqual(resp.OrphanedChains, want) {
+		t.Errorf("dry run orphaned chains = %v, want %v", resp.OrphanedChains, want)
+	}
+	if len(cleaned) != 0 {
+		t.Errorf("dry run removed %v", cleaned)
+	}
+
+	resp, err = server.CollectOrphanedChains(ctx, &pb.CollectOrphanedChainsRequest{})
+	if err != nil || !resp.Success {
+		t.Fatalf("CollectOrphanedChains() = %v, %v", resp, err)
+	}
+	if !reflect.DeepEqual(resp.OrphanedChains, want) || !reflect.DeepEqual(cleaned, want) {
+		t.Errorf("orphaned chains = %v, removed %v, want %v", resp.OrphanedChains, cleaned, want)
+	}
+	if got := server.orphansRemoved.Load(); got != 3 {
+		t.Errorf("orphans removed = %d, want 3", got)
+	}
+
+	for _, chainName := range []string{chainB, chainC} {
+		if _, ok := store.Get(chainName); ok {
+			t.Errorf("record of %s was kept", chainName)
+		}
+		if _, ok := server.chainIPs[chainName]; ok {
+			t.Errorf("container IP of %s was kept", chainName)
+		}
+	}
+	for _, chainName := range []string{chainA, "ISO-eeeeeeeeeeeeeeee"} {
+		if _, ok := store.Get(chainName); !ok {
+			t.Errorf("record of %s was removed", chainName)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// InspectChain lists the rules the kernel holds for a chain, for debugging
+// what a container can reach. Nothing is changed.
+func (s *Server) InspectChain(ctx context.Context, req *pb.InspectChainRequest) (*pb.InspectChainResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
+	if err := validation.ValidateChainName(req.ChainName); err != nil {
+		s.auditLog(ctx, "inspect_chain", req.ChainName, req.ContainerId, false)
+		return &pb.InspectChainResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	if err := s.authorizeChain(ctx, req.ChainName); err != nil {
+		s.auditLog(ctx, "inspect_chain", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
+	rules, err := s.chains.rules(ctx, req.ChainName)
+	if err != nil {
+		s.auditLog(ctx, "inspect_chain", req.ChainName, req.ContainerId, false)
+		return &pb.InspectChainResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	s.chainMu.RLock()
+	containerIP := s.chainIPs[req.ChainName]
+	s.chainMu.RUnlock()
+
+	resp := &pb.InspectChainResponse{Success: true}
+	if containerIP != "" {
+		state, err := s.chains.inspect(ctx, req.ChainName, containerIP)
+		if err != nil {
+			s.logger.Warn("failed to check container chain jump", "chain_name", req.ChainName, "error", err)
+		}
+		resp.Jumped = state.Jumped
+	}
+
+	for _, rule := range rules {
+		r := &pb.ChainRule{Family: rule.Family, Rule: rule.Spec}
+		if rule.Counted {
+			r.Packets = &rule.Packets
+			r.Bytes = &rule.Bytes
+		}
+		resp.Rules = append(resp.Rules, r)
+	}
+
+	s.auditLog(ctx, "inspect_chain", req.ChainName, req.ContainerId, true)
+	return resp, nil
+}
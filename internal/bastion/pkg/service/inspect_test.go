@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestInspectChain(t *testing.T) {
+	server, _ := newReconcileServer(map[string]iptables.ChainState{
+		chainA: {Exists: true, Jumped: true, Rules: 2},
+	})
+	server.chains.rules = func(_ context.Context, chainName string) ([]iptables.Rule, error) {
+		return []iptables.Rule{
+			{Family: "ipv4", Spec: "-A " + chainName + " -d 10.0.0.0/8 -j ACCEPT", Packets: 3, Bytes: 180, Counted: true},
+			{Family: "inet", Spec: "drop"},
+		}, nil
+	}
+	server.chainIPs[chainA] = "172.20.1.10"
+
+	resp, err := server.InspectChain(context.Background(), &pb.InspectChainRequest{ChainName: chainA})
+	if err != nil || !resp.Success {
+		t.Fatalf("InspectChain() = %v, %v", resp, err)
+	}
+	if !resp.Jumped {
+		t.Error("expected the chain to be reported as jumped to")
+	}
+	if len(resp.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(resp.Rules))
+	}
+	if r := resp.Rules[0]; r.GetPackets() != 3 || r.GetBytes() != 180 || r.Family != "ipv4" {
+		t.Errorf("first rule = %+v", r)
+	}
+	if r := resp.Rules[1]; r.Packets != nil || r.Bytes != nil {
+		t.Errorf("uncounted rule should carry no counters, got %+v", r)
+	}
+
+	resp, err = server.InspectChain(context.Background(), &pb.InspectChainRequest{ChainName: "invalid"})
+	if err != nil || resp.Success {
+		t.Errorf("InspectChain() of an invalid chain = %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/networkpool"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func (s *Server) ListNetworks(ctx context.Context, req *pb.ListNetworksRequest) (*pb.ListNetworksResponse, error) {
+	// The pool holds the networks of every run
+	if !auth.FromContext(ctx).Admin {
+		return nil, status.Error(codes.PermissionDenied, "listing pool networks requires the admin token")
+	}
+
+	networks, err := s.networkPool.List(networkpool.NetworkFilter{
+		ContainerID: req.GetContainerId(),
+		ConfigHash:  req.GetConfigHash(),
+		Partition:   req.PartitionKey,
+		Driver:      req.GetDriver(),
+		State:       req.GetState(),
+	})
+	if err != nil {
+		return &pb.ListNetworksResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	resp := &pb.ListNetworksResponse{Success: true}
+	for _, entry := range networks {
+		resp.Networks = append(resp.Networks, &pb.PoolNetwork{
+			NetworkName:      entry.NetworkName,
+			NetworkId:        entry.NetworkID,
+			Subnet:           entry.Subnet,
+			ConfigHash:       entry.ConfigHash,
+			PartitionKey:     entry.Partition,
+			Driver:           entry.Driver,
+			CurrentContainer: entry.CurrentContainer,
+			ReuseCount:       uint32(entry.ReuseCount),
+			Suspect:          entry.Suspect,
+			CreatedAt:        entry.CreatedAt.UnixMilli(),
+			LastReleasedAt:   unixMilliPtr(entry.LastReleasedAt),
+			CleanupAt:        unixMilliPtr(entry.CleanupAt),
+			LeaseExpiresAt:   unixMilliPtr(entry.LeaseExpiresAt),
+		})
+	}
+	return resp, nil
+}
+
+func unixMilliPtr(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	ms := t.UnixMilli()
+	return &ms
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// SetupPortForward publishes one container port on the host, as ExposePorts
+// does for several. The forward is removed by TeardownPortForward or, with the
+// rest of the chain, by CleanupChain.
+func (s *Server) SetupPortForward(ctx context.Context, req *pb.SetupPortForwardRequest) (*pb.SetupPortForwardResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
+	if err := validation.ValidateChainName(req.ChainName); err != nil {
+		s.auditLog(ctx, "setup_port_forward", req.ChainName, req.ContainerId, false)
+		return &pb.SetupPortForwardResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+	if req.Mapping == nil {
+		s.auditLog(ctx, "setup_port_forward", req.ChainName, req.ContainerId, false)
+		return nil, status.Error(codes.InvalidArgument, "mapping is required")
+	}
+
+	if err := s.authorizeChain(ctx, req.ChainName); err != nil {
+		s.auditLog(ctx, "setup_port_forward", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
+	release, err := s.workers.acquire(ctx, false)
+	if err != nil {
+		s.auditLog(ctx, "setup_port_forward", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+	defer release()
+
+	s.chainMu.RLock()
+	containerIP, ok := s.chainIPs[req.ChainName]
+	s.chainMu.RUnlock()
+	if !ok {
+		s.auditLog(ctx, "setup_port_forward", req.ChainName, req.ContainerId, false)
+		return nil, status.Error(codes.NotFound, "no container registered for chain")
+	}
+
+	published, err := s.ports.Expose(ctx, req.ChainName, containerIP, []*pb.PortMapping{req.Mapping})
+	if err != nil {
+		s.auditLog(ctx, "setup_port_forward", req.ChainName, req.ContainerId, false)
+		return &pb.SetupPortForwardResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	s.recordPorts(req.ChainName)
+	mapping := published[0]
+	s.logger.Info("port forward set up",
+		"chain_name", req.ChainName,
+		"container_id", req.ContainerId,
+		"run_id", runID(ctx),
+		"container_ip", containerIP,
+		"container_port", mapping.ContainerPort,
+		"host_port", mapping.HostPort,
+		"protocol", mapping.Protocol,
+	)
+
+	s.auditLog(ctx, "setup_port_forward", req.ChainName, req.ContainerId, true)
+	return &pb.SetupPortForwardResponse{
+		Success: true,
+		Mapping: mapping,
+	}, nil
+}
+
+// TeardownPortForward removes one port published for a chain by
+// SetupPortForward or ExposePorts, leaving the others in place
+func (s *Server) TeardownPortForward(ctx context.Context, req *pb.TeardownPortForwardRequest) (*pb.TeardownPortForwardResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
+	if err := validation.ValidateChainName(req.ChainName); err != nil {
+		s.auditLog(ctx, "teardown_port_forward", req.ChainName, req.ContainerId, false)
+		return &pb.TeardownPortForwardResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+	if err := validation.ValidatePort(req.HostPort); err != nil {
+		s.auditLog(ctx, "teardown_port_forward", req.ChainName, req.ContainerId, false)
+		return &pb.TeardownPortForwardResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+	if req.Protocol != "" {
+		if err := validation.ValidateProtocol(req.Protocol); err != nil {
+			s.auditLog(ctx, "teardown_port_forward", req.ChainName, req.ContainerId, false)
+			return &pb.TeardownPortForwardResponse{
+				Success: false,
+				Error:   strPtr(err.Error()),
+			}, nil
+		}
+	}
+
+	if err := s.authorizeChain(ctx, req.ChainName); err != nil {
+		s.auditLog(ctx, "teardown_port_forward", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
+	release, err := s.workers.acquire(ctx, true)
+	if err != nil {
+		s.auditLog(ctx, "teardown_port_forward", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+	defer release()
+
+	mapping, err := s.ports.Unexpose(ctx, req.ChainName, req.Protocol, req.HostPort)
+	if err != nil {
+		s.auditLog(ctx, "teardown_port_forward", req.ChainName, req.ContainerId, false)
+		return &pb.TeardownPortForwardResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	s.recordPorts(req.ChainName)
+	s.logger.Info("port forward torn down",
+		"chain_name", req.ChainName,
+		"container_id", req.ContainerId,
+		"run_id", runID(ctx),
+		"container_port", mapping.ContainerPort,
+		"host_port", mapping.HostPort,
+		"protocol", mapping.Protocol,
+	)
+
+	s.auditLog(ctx, "teardown_port_forward", req.ChainName, req.ContainerId, true)
+	return &pb.TeardownPortForwardResponse{Success: true}, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/metrics"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// DefaultReconcileInterval is how often container chains are checked for drift
+const DefaultReconcileInterval = time.Minute
+
+// ReconcileIntervalFromEnv reads BASTION_RECONCILE_INTERVAL as a duration,
+// falling back to the default when it is unset or invalid. Zero turns
+// reconciliation off.
+func ReconcileIntervalFromEnv() time.Duration {
+	return intervalFromEnv("BASTION_RECONCILE_INTERVAL", DefaultReconcileInterval)
+}
+
+// intervalFromEnv reads the duration in the environment variable key, or
+// returns def when it is unset, invalid or negative
+func intervalFromEnv(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d >= 0 {
+		return d
+	}
+	return def
+}
+
+// driftStats counts what the reconciler found since startup; unknown is the
+// count from the last pass
+type driftStats struct {
+	drifted       atomic.Uint64
+	repaired      atomic.Uint64
+	repairsFailed atomic.Uint64
+	unknown       atomic.Uint32
+}
+
+// chainOps reads and rebuilds container chains in the kernel
+type chainOps struct {
+	list     func(ctx context.Context) ([]string, error)
+	inspect  func(ctx context.Context, chainName, containerIP string) (iptables.ChainState, error)
+	expected func(ctx context.Context, policy *pb.NetworkPolicy, ports []*pb.PortMapping, peers []string) (int, error)
+	repair   func(ctx context.Context, chainName, containerIP string, policy *pb.NetworkPolicy, ports []*pb.PortMapping, peers []string) (int, error)
+	cleanup  func(ctx context.Context, chainName, containerIP string) error
+	rules    func(ctx context.Context, chainName string) ([]iptables.Rule, error)
+	update   func(ctx context.Context, chainName, containerIP string, oldPolicy, policy *pb.NetworkPolicy, ports []*pb.PortMapping, peers []string) (iptables.RuleDiff, error)
+	flush    func(ctx context.Context, containerIP string) (int, error)
+}
+
+var kernelChainOps = chainOps{
+	list:     iptables.ListChains,
+	inspect:  iptables.InspectChain,
+	expected: iptables.ExpectedRules,
+	repair:   iptables.RepairChain,
+	cleanup:  iptables.CleanupChain,
+	rules:    iptables.ListRules,
+	update:   iptables.UpdateRules,
+	flush:    iptables.FlushConntrack,
+}
+
+// StartReconciler checks container chains for drift every interval until ctx
+// is done. An interval of zero leaves reconciliation off.
+func (s *Server) StartReconciler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Reconcile(ctx)
+			}
+		}
+	}()
+}
+
+// Reconcile compares every container chain with the policy last applied to it.
+// A chain that lost its FORWARD jump or whose rules no longer add up, as when
+// a firewall manager flushes the tables, is rebuilt from the policy. Chains
+// with no policy on record, such as those left by a previous bastion, are only
+// reported: nothing says what they should hold or whether their container is gone.
+func (s *Server) Reconcile(ctx context.Context) {
+	listed, err := s.chains.list(ctx)
+	if err != nil {
+		s.logger.Warn("failed to list container chains for reconciliation", "error", err)
+		return
+	}
+
+	s.chainMu.RLock()
+	withPolicy := make([]string, 0, len(s.policies))
+	for chainName := range s.policies {
+		withPolicy = append(withPolicy, chainName)
+	}
+	var unknown uint32
+	for _, chainName := range listed {
+		// A chain set up but not yet given rules is expected
+		_, hasPolicy := s.policies[chainName]
+		_, registered := s.chainIPs[chainName]
+		if !hasPolicy && !registered {
+			unknown++
+			s.logger.Warn("container chain has no policy on record", "chain_name", chainName)
+		}
+	}
+	s.chainMu.RUnlock()
+	s.drift.unknown.Store(unknown)
+
+	// Chains gone from the kernel drift too, so every chain with a policy is checked
+	for _, chainName := range withPolicy {
+		if ctx.Err() != nil {
+			return
+		}
+		s.reconcileChain(ctx, chainName)
+	}
+}
+
+// reconcileChain checks one chain and rebuilds it if it drifted
+func (s *Server) reconcileChain(ctx context.Context, chainName string) {
+	s.firewallMu.Lock()
+	defer s.firewallMu.Unlock()
+
+	// The chain may have been cleaned up since the pass began
+	s.chainMu.RLock()
+	policy, ok := s.policies[chainName]
+	containerIP := s.chainIPs[chainName]
+	s.chainMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	ports := s.ports.Mappings(chainName)
+	peers := s.groups.Peers(chainName)
+
+	state, err := s.chains.inspect(ctx, chainName, containerIP)
+	if err != nil {
+		s.logger.Warn("failed to inspect container chain", "chain_name", chainName, "error", err)
+		return
+	}
+	expected, err := s.chains.expected(ctx, policy, ports, peers)
+	if err != nil {
+		s.logger.Warn("failed to count expected rules of container chain", "chain_name", chainName, "error", err)
+		return
+	}
+
+	jumpMissing := containerIP != "" && !state.Jumped
+	if state.Exists && !jumpMissing && state.Rules == expected {
+		return
+	}
+
+	s.drift.drifted.Add(1)
+	s.logger.Warn("container chain drifted from its policy",
+		"chain_name", chainName,
+		"chain_exists", state.Exists,
+		"jump_missing", jumpMissing,
+		"rules", state.Rules,
+		"expected_rules", expected,
+	)
+
+	if containerIP == "" {
+		// Without the container IP the jump cannot be placed
+		s.drift.repairsFailed.Add(1)
+		s.logger.Error("cannot repair container chain not created by SetupChain", "chain_name", chainName)
+		return
+	}
+
+	count, err := s.chains.repair(ctx, chainName, containerIP, policy, ports, peers)
+	if err != nil {
+		metrics.FirewallFailure("repair_chain")
+		s.drift.repairsFailed.Add(1)
+		s.logger.Error("failed to repair container chain", "chain_name", chainName, "error", err)
+		return
+	}
+
+	metrics.RulesApplied(count)
+	s.drift.repaired.Add(1)
+	s.logger.Info("container chain repaired", "chain_name", chainName, "rules_applied", count)
+}
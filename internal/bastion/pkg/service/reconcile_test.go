@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+const (
+	chainA = "ISO-aaaaaaaaaaaaaaaa"
+	chainB = "ISO-bbbbbbbbbbbbbbbb"
+	chainC = "ISO-cccccccccccccccc"
+)
+
+// newReconcileServer returns a server whose chains live in states rather than
+// the kernel. Every policy expects 4 rules; a repair restores them.
+func newReconcileServer(states map[string]iptables.ChainState) (*Server, *[]string) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	server := New("1.0.0-test", nil, nil, nil, logger)
+
+	var repaired []string
+	server.chains = chainOps{
+		list: func(context.Context) ([]string, error) {
+			var names []string
+			for name, state := range states {
+				if state.Exists {
+					names = append(names, name)
+				}
+			}
+			return names, nil
+		},
+		inspect: func(_ context.Context, chainName, _ string) (iptables.ChainState, error) {
+			return states[chainName], nil
+		},
+		expected: func(context.Context, *pb.NetworkPolicy, []*pb.PortMapping, []string) (int, error) {
+			return 4, nil
+		},
+		repair: func(_ context.Context, chainName, _ string, _ *pb.NetworkPolicy, _ []*pb.PortMapping, _ []string) (int, error) {
+			if chainName == chainC {
+				return 0, errors.New("iptables unavailable")
+			}
+			repaired = append(repaired, chainName)
+			states[chainName] = iptables.ChainState{Exists: true, Jumped: true, Rules: 4}
+			return 4, nil
+		},
+		flush: func(context.Context, string) (int, error) {
+			return 0, nil
+		},
+	}
+	return server, &repaired
+}
+
+func TestReconcile(t *testing.T) {
+	states := map[string]iptables.ChainState{
+		chainA:                 {Exists: true, Jumped: true, Rules: 4},
+		chainB:                 {Exists: true, Jumped: true, Rules: 0},
+		chainC:                 {Exists: false},
+		"ISO-dddddddddddddddd": {Exists: true, Rules: 2},
+	}
+	server, repaired := newReconcileServer(states)
+
+	policy := &pb.NetworkPolicy{Policy: "deny"}
+	for chainName, ip := range map[string]string{chainA: "10.20.0.2", chainB: "10.20.1.2", chainC: "10.20.2.2"} {
+		server.chainIPs[chainName] = ip
+		server.policies[chainName] = policy
+	}
+
+	ctx := context.Background()
+	server.Reconcile(ctx)
+
+	if len(*repaired) != 1 || (*repaired)[0] != chainB {
+		t.Errorf("repaired %v, want [%s]", *repaired, chainB)
+	}
+	if got := server.drift.drifted.Load(); got != 2 {
+		t.Errorf("drifted = %d, want 2", got)
+	}
+	if got := server.drift.repaired.Load(); got != 1 {
+		t.Errorf("repaired = %d, want 1", got)
+	}
+	if got := server.drift.repairsFailed.Load(); got != 1 {
+		t.Errorf("repairs failed = %d, want 1", got)
+	}
+	if got := server.drift.unknown.Load(); got != 1 {
+		t.Errorf("unknown = %d, want 1", got)
+	}
+	if !states["ISO-dddddddddddddddd"].Exists {
+		t.Error("a chain with no policy on record was touched")
+	}
+
+	// The repaired chain now matches; only the failed one drifts again
+	server.Reconcile(ctx)
+	if got := server.drift.drifted.Load(); got != 3 {
+		t.Errorf("drifted after second pass = %d, want 3", got)
+	}
+}
+
+func TestReconcileSkipsChainsAwaitingRules(t *testing.T) {
+	states := map[string]iptables.ChainState{
+		chainA: {Exists: true, Jumped: true},
+	}
+	server, repaired := newReconcileServer(states)
+	server.chainIPs[chainA] = "10.20.0.2"
+
+	server.Reconcile(context.Background())
+
+	if len(*repaired) != 0 || server.drift.drifted.Load() != 0 || server.drift.unknown.Load() != 0 {
+		t.Errorf("a chain set up but not yet given rules was reported: repaired %v, drifted %d, unknown %d",
+			*repaired, server.drift.drifted.Load(), server.drift.unknown.Load())
+	}
+}
+
+func TestReconcileIntervalFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", DefaultReconcileInterval},
+		{"30s", 30 * time.Second},
+		{"0", 0},
+		{"-1m", DefaultReconcileInterval},
+		{"soon", DefaultReconcileInterval},
+	}
+	for _, tt := range tests {
+		t.Setenv("BASTION_RECONCILE_INTERVAL", tt.value)
+		if got := ReconcileIntervalFromEnv(); got != tt.want {
+			t.Errorf("ReconcileIntervalFromEnv() with %q = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/chainstore"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// recordPolicy keeps policy in the chain's record. The chain is already
+// changed by then, so a failure to write it is only logged: this bastion
+// goes on enforcing the policy, and only one taking over would miss it.
+func (s *Server) recordPolicy(chainName string, policy *pb.NetworkPolicy) {
+	data, err := protojson.Marshal(policy)
+	if err == nil {
+		err = s.records.Update(chainName, func(record *chainstore.Record) {
+			record.Policy = data
+		})
+	}
+	if err != nil {
+		s.logger.Warn("failed to record chain policy", "chain_name", chainName, "error", err)
+	}
+}
+
+// recordPorts keeps the ports published for chainName in its record
+func (s *Server) recordPorts(chainName string) {
+	var ports []chainstore.Port
+	for _, m := range s.ports.Mappings(chainName) {
+		ports = append(ports, chainstore.Port{
+			ContainerPort: m.ContainerPort,
+			HostPort:      m.HostPort,
+			Protocol:      m.Protocol,
+		})
+	}
+
+	err := s.records.Update(chainName, func(record *chainstore.Record) {
+		record.Ports = ports
+	})
+	if err != nil {
+		s.logger.Warn("failed to record published ports", "chain_name", chainName, "error", err)
+	}
+}
+
+func portMappings(ports []chainstore.Port) []*pb.PortMapping {
+	mappings := make([]*pb.PortMapping, 0, len(ports))
+	for _, p := range ports {
+		mappings = append(mappings, &pb.PortMapping{
+			ContainerPort: p.ContainerPort,
+			HostPort:      p.HostPort,
+			Protocol:      p.Protocol,
+		})
+	}
+	return mappings
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/chainstore"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+// TestRestoreChainsTakesOver has one bastion record its chains and another
+// recover them from the same file, as a standby does on taking over
+func TestRestoreChainsTakesOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chains.json")
+	store, err := chainstore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := map[string]chainstore.Record{
+		chainA: {
+			ContainerID: "containera",
+			ContainerIP: "10.20.0.2",
+			Ports:       []chainstore.Port{{ContainerPort: 8080, HostPort: 30080, Protocol: "tcp"}},
+		},
+		chainB: {ContainerID: "containerb", ContainerIP: "10.20.0.3"},
+		chainC: {ContainerID: "containerc", ContainerIP: "10.20.0.4"},
+	}
+	for chainName, record := range records {
+		if err := store.Put(chainName, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active, _ := newReconcileServer(map[string]iptables.ChainState{})
+	active.RestoreChains(store)
+	grouped := &pb.NetworkPolicy{Policy: "deny", AllowDns: true, PodGroup: "pod-1"}
+	active.recordPolicy(chainA, grouped)
+	active.recordPolicy(chainB, grouped)
+	// A chain whose record is gone is not recreated
+	active.recordPolicy("ISO-dddddddddddddddd", grouped)
+
+	reopened, err := chainstore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	standby, _ := newReconcileServer(map[string]iptables.ChainState{})
+	standby.RestoreChains(reopened)
+
+	if len(standby.policies) != 2 || !proto.Equal(standby.policies[chainA], grouped) {
+		t.Errorf("restored policies = %v, want %v for %s and %s", standby.policies, grouped, chainA, chainB)
+	}
+	if _, ok := standby.policies[chainC]; ok {
+		t.Errorf("%s was given a policy it never had", chainC)
+	}
+	if _, ok := reopened.Get("ISO-dddddddddddddddd"); ok {
+		t.Error("recording a policy created a chain record")
+	}
+
+	ports := standby.ports.Mappings(chainA)
+	if len(ports) != 1 || ports[0].HostPort != 30080 || ports[0].ContainerPort != 8080 {
+		t.Errorf("restored ports = %v", ports)
+	}
+	if peers := standby.groups.Peers(chainA); len(peers) != 1 || peers[0] != "10.20.0.3" {
+		t.Errorf("restored pod group peers of %s = %v, want [10.20.0.3]", chainA, peers)
+	}
+}
@@ -2,15 +2,31 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auditlog"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/auth"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/capture"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/chainstore"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/dnsfilter"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/flowlog"
 	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/metrics"
 	"github.com/metorial/fleet/holopod/internal/bastion/pkg/networkpool"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/podgroup"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/portmap"
 	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
 	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
 )
@@ -19,23 +35,114 @@ type Server struct {
 	pb.UnimplementedBastionServiceServer
 	version     string
 	networkPool *networkpool.Pool
+	flowLogs    *flowlog.Collector
+	dnsFilter   *dnsfilter.Server
+	ports       *portmap.Publisher
+	groups      *podgroup.Registry
 	logger      *slog.Logger
 	chainIPs    map[string]string
+	policies    map[string]*pb.NetworkPolicy
 	chainMu     sync.RWMutex
+
+	// firewallMu is held shared by the handlers that change chains and
+	// exclusively by the reconciler while it checks one, so it never sees a
+	// chain halfway through an update
+	firewallMu sync.RWMutex
+	drift      driftStats
+	chains     chainOps
+	workers    *workerPool
+
+	// records survive restarts, so chains can be traced to their container
+	records          *chainstore.Store
+	orphansRemoved   atomic.Uint64
+	containerRunning func(ctx context.Context, containerID string) (bool, error)
+
+	// networkOwners holds the run that acquired each container's network, by
+	// container ID, so no other run can release it
+	networkOwners  map[string]string
+	networkOwnerMu sync.Mutex
+	// activeNetworks lists the pool networks held by a container
+	activeNetworks func() ([]networkpool.NetworkEntry, error)
+
+	// conntrackMissing is set once the missing conntrack tool has been reported
+	conntrackMissing atomic.Bool
+
+	// audit keeps audit entries on disk for QueryAuditLog; nil keeps them
+	// in the service log only
+	audit *auditlog.Log
 }
 
-func New(version string, networkPool *networkpool.Pool, logger *slog.Logger) *Server {
+// New creates the bastion service. flowLogs may be nil, in which case
+// StreamFlowLogs reports Unavailable. dnsFilter may be nil, in which case
+// policies with a DNS domain allowlist are rejected.
+func New(version string, networkPool *networkpool.Pool, flowLogs *flowlog.Collector, dnsFilter *dnsfilter.Server, logger *slog.Logger) *Server {
 	return &Server{
 		version:     version,
 		networkPool: networkPool,
+		flowLogs:    flowLogs,
+		dnsFilter:   dnsFilter,
+		ports:       portmap.NewPublisher(portmap.RangeFromEnv()),
+		groups:      podgroup.NewRegistry(),
 		logger:      logger,
 		chainIPs:    make(map[string]string),
+		policies:    make(map[string]*pb.NetworkPolicy),
+		chains:      kernelChainOps,
+		workers:     newWorkerPool(FirewallWorkersFromEnv()),
+
+		records:          chainstore.New(),
+		containerRunning: dockerContainerRunning,
+		networkOwners:    make(map[string]string),
+		activeNetworks: func() ([]networkpool.NetworkEntry, error) {
+			if networkPool == nil {
+				return nil, nil
+			}
+			return networkPool.List(networkpool.NetworkFilter{State: networkpool.NetworkStateActive})
+		},
 	}
 }
 
+// RestoreChains takes over the chain records in store, from which the chains
+// set up before a restart, or by a bastion this one took over from, are
+// recovered: their container IPs, policies, published ports and pod groups.
+// Nothing in the kernel is changed. It must be called before the service is
+// registered.
+func (s *Server) RestoreChains(store *chainstore.Store) {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	s.records = store
+	for chainName, record := range store.All() {
+		s.chainIPs[chainName] = record.ContainerIP
+		s.ports.Restore(chainName, record.ContainerIP, portMappings(record.Ports))
+
+		if len(record.Policy) == 0 {
+			continue
+		}
+		policy := &pb.NetworkPolicy{}
+		if err := protojson.Unmarshal(record.Policy, policy); err != nil {
+			s.logger.Warn("ignoring unreadable policy of chain record", "chain_name", chainName, "error", err)
+			continue
+		}
+		s.policies[chainName] = policy
+		s.groups.Restore(policy.PodGroup, chainName, record.ContainerIP)
+		if err := s.syncDNSFilter(policy, record.ContainerIP); err != nil {
+			s.logger.Warn("failed to restore DNS filter policy", "chain_name", chainName, "error", err)
+		}
+	}
+}
+
+// SetAuditLog writes audit entries to log as well, and serves QueryAuditLog
+// from it. It must be called before the service is registered.
+func (s *Server) SetAuditLog(log *auditlog.Log) {
+	s.audit = log
+}
+
 func (s *Server) SetupChain(ctx context.Context, req *pb.SetupChainRequest) (*pb.SetupChainResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
 	if err := validation.ValidateChainName(req.ChainName); err != nil {
-		s.auditLog("setup_chain", req.ChainName, req.ContainerId, false)
+		s.auditLog(ctx, "setup_chain", req.ChainName, req.ContainerId, false)
 		return &pb.SetupChainResponse{
 			Success: false,
 			Error:   strPtr(err.Error()),
@@ -44,7 +151,36 @@ func (s *Server) SetupChain(ctx context.Context, req *pb.SetupChainRequest) (*pb
 
 	containerIP, err := validation.ValidateContainerIP(req.ContainerIp)
 	if err != nil {
-		s.auditLog("setup_chain", req.ChainName, req.ContainerId, false)
+		s.auditLog(ctx, "setup_chain", req.ChainName, req.ContainerId, false)
+		return &pb.SetupChainResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	caller := auth.FromContext(ctx)
+	existing, existed := s.records.Get(req.ChainName)
+	if existed && !caller.CanAct(existing.Owner) {
+		s.auditLog(ctx, "setup_chain", req.ChainName, req.ContainerId, false)
+		return nil, status.Errorf(codes.PermissionDenied, "chain %s belongs to another run", req.ChainName)
+	}
+	if err := s.authorizeContainerIP(ctx, req.ChainName, containerIP); err != nil {
+		s.auditLog(ctx, "setup_chain", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
+	release, err := s.workers.acquire(ctx, false)
+	if err != nil {
+		s.auditLog(ctx, "setup_chain", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+	defer release()
+
+	// The owner is recorded first, so a chain is never left that garbage
+	// collection cannot trace to its container
+	record := chainstore.Record{ContainerID: req.ContainerId, ContainerIP: req.ContainerIp, Owner: caller.RunID, CreatedAt: time.Now()}
+	if err := s.records.Put(req.ChainName, record); err != nil {
+		s.auditLog(ctx, "setup_chain", req.ChainName, req.ContainerId, false)
 		return &pb.SetupChainResponse{
 			Success: false,
 			Error:   strPtr(err.Error()),
@@ -52,7 +188,15 @@ func (s *Server) SetupChain(ctx context.Context, req *pb.SetupChainRequest) (*pb
 	}
 
 	if err := iptables.SetupChain(ctx, req.ChainName, containerIP); err != nil {
-		s.auditLog("setup_chain", req.ChainName, req.ContainerId, false)
+		metrics.FirewallFailure("setup_chain")
+		// Only forget a record this call made; one from an earlier setup
+		// still describes the chain in the kernel
+		if existed {
+			_ = s.records.Put(req.ChainName, existing)
+		} else {
+			_ = s.records.Delete(req.ChainName)
+		}
+		s.auditLog(ctx, "setup_chain", req.ChainName, req.ContainerId, false)
 		return &pb.SetupChainResponse{
 			Success: false,
 			Error:   strPtr(err.Error()),
@@ -63,15 +207,18 @@ func (s *Server) SetupChain(ctx context.Context, req *pb.SetupChainRequest) (*pb
 	s.chainIPs[req.ChainName] = req.ContainerIp
 	s.chainMu.Unlock()
 
-	s.auditLog("setup_chain", req.ChainName, req.ContainerId, true)
+	s.auditLog(ctx, "setup_chain", req.ChainName, req.ContainerId, true)
 	return &pb.SetupChainResponse{
 		Success: true,
 	}, nil
 }
 
 func (s *Server) ApplyRules(ctx context.Context, req *pb.ApplyRulesRequest) (*pb.ApplyRulesResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
 	if err := validation.ValidateChainName(req.ChainName); err != nil {
-		s.auditLog("apply_rules", req.ChainName, req.ContainerId, false)
+		s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
 		return &pb.ApplyRulesResponse{
 			Success:      false,
 			Error:        strPtr(err.Error()),
@@ -79,14 +226,53 @@ func (s *Server) ApplyRules(ctx context.Context, req *pb.ApplyRulesRequest) (*pb
 		}, nil
 	}
 
+	if err := s.authorizeChain(ctx, req.ChainName); err != nil {
+		s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
 	if req.Policy == nil {
-		s.auditLog("apply_rules", req.ChainName, req.ContainerId, false)
+		s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
 		return nil, status.Error(codes.InvalidArgument, "network policy is required")
 	}
 
+	s.chainMu.RLock()
+	containerIP := s.chainIPs[req.ChainName]
+	s.chainMu.RUnlock()
+
+	if err := s.checkDNSFilter(req.Policy, containerIP); err != nil {
+		s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
+		return &pb.ApplyRulesResponse{
+			Success:      false,
+			Error:        strPtr(err.Error()),
+			RulesApplied: 0,
+		}, nil
+	}
+
+	if err := checkPodGroup(req.Policy, containerIP); err != nil {
+		s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
+		return &pb.ApplyRulesResponse{
+			Success:      false,
+			Error:        strPtr(err.Error()),
+			RulesApplied: 0,
+		}, nil
+	}
+
+	if req.DryRun {
+		return s.planRules(ctx, req)
+	}
+
+	release, err := s.workers.acquire(ctx, false)
+	if err != nil {
+		s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+	defer release()
+
 	count, err := iptables.ApplyRules(ctx, req.ChainName, req.Policy)
 	if err != nil {
-		s.auditLog("apply_rules", req.ChainName, req.ContainerId, false)
+		metrics.FirewallFailure("apply_rules")
+		s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
 		return &pb.ApplyRulesResponse{
 			Success:      false,
 			Error:        strPtr(err.Error()),
@@ -94,54 +280,569 @@ func (s *Server) ApplyRules(ctx context.Context, req *pb.ApplyRulesRequest) (*pb
 		}, nil
 	}
 
-	s.auditLog("apply_rules", req.ChainName, req.ContainerId, true)
+	if containerIP != "" {
+		peers, err := s.groups.Join(ctx, req.Policy.PodGroup, req.ChainName, containerIP)
+		if err == nil {
+			for _, peerIP := range peers {
+				if err = iptables.AllowPeer(ctx, req.ChainName, peerIP); err != nil {
+					metrics.FirewallFailure("apply_rules")
+					break
+				}
+				count++
+			}
+		}
+		if err != nil {
+			s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
+			return &pb.ApplyRulesResponse{
+				Success:      false,
+				Error:        strPtr(err.Error()),
+				RulesApplied: int32(count),
+			}, nil
+		}
+	}
+
+	if err := s.syncDNSFilter(req.Policy, containerIP); err != nil {
+		s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, false)
+		return &pb.ApplyRulesResponse{
+			Success:      false,
+			Error:        strPtr(err.Error()),
+			RulesApplied: int32(count),
+		}, nil
+	}
+
+	s.chainMu.Lock()
+	s.policies[req.ChainName] = req.Policy
+	s.chainMu.Unlock()
+	s.recordPolicy(req.ChainName, req.Policy)
+
+	metrics.RulesApplied(count)
+	s.auditLog(ctx, "apply_rules", req.ChainName, req.ContainerId, true)
 	return &pb.ApplyRulesResponse{
 		Success:      true,
 		RulesApplied: int32(count),
 	}, nil
 }
 
+// planRules answers a dry run of ApplyRules with the commands it would run.
+// Accepts for pod group peers are left out: which peers there are is only
+// settled when the chain joins the group.
+func (s *Server) planRules(ctx context.Context, req *pb.ApplyRulesRequest) (*pb.ApplyRulesResponse, error) {
+	commands, err := iptables.PlanRules(ctx, req.ChainName, req.Policy)
+	if err != nil {
+		return &pb.ApplyRulesResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	s.logger.Info("apply rules dry run",
+		"chain_name", req.ChainName,
+		"container_id", req.ContainerId,
+		"run_id", runID(ctx),
+		"commands", len(commands),
+	)
+	return &pb.ApplyRulesResponse{
+		Success:  true,
+		Commands: commands,
+	}, nil
+}
+
+// UpdateNetworkPolicy replaces the rules of a running container's chain without
+// interrupting it. Unlike ApplyRules it requires a chain created by SetupChain,
+// since the container IP is needed to move the FORWARD jump.
+func (s *Server) UpdateNetworkPolicy(ctx context.Context, req *pb.UpdateNetworkPolicyRequest) (*pb.UpdateNetworkPolicyResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
+	if err := validation.ValidateChainName(req.ChainName); err != nil {
+		s.auditLog(ctx, "update_network_policy", req.ChainName, req.ContainerId, false)
+		return &pb.UpdateNetworkPolicyResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	if err := s.authorizeChain(ctx, req.ChainName); err != nil {
+		s.auditLog(ctx, "update_network_policy", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
+	release, err := s.workers.acquire(ctx, false)
+	if err != nil {
+		s.auditLog(ctx, "update_network_policy", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+	defer release()
+
+	if req.Policy == nil {
+		s.auditLog(ctx, "update_network_policy", req.ChainName, req.ContainerId, false)
+		return nil, status.Error(codes.InvalidArgument, "network policy is required")
+	}
+
+	diff, err := s.updatePolicy(ctx, "update_network_policy", req.ChainName, req.Policy, false)
+	if err != nil {
+		s.auditLog(ctx, "update_network_policy", req.ChainName, req.ContainerId, false)
+		return &pb.UpdateNetworkPolicyResponse{
+			Success:      false,
+			Error:        strPtr(err.Error()),
+			RulesApplied: int32(diff.Rules),
+		}, nil
+	}
+
+	s.auditLog(ctx, "update_network_policy", req.ChainName, req.ContainerId, true)
+	return &pb.UpdateNetworkPolicyResponse{
+		Success:      true,
+		RulesApplied: int32(diff.Rules),
+	}, nil
+}
+
+// UpdateRules is UpdateNetworkPolicy changing only the rules that differ from
+// the policy last applied, so rules both share keep their counters. Where the
+// chain cannot be diffed it is rebuilt as UpdateNetworkPolicy would.
+func (s *Server) UpdateRules(ctx context.Context, req *pb.UpdateRulesRequest) (*pb.UpdateRulesResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
+	if err := validation.ValidateChainName(req.ChainName); err != nil {
+		s.auditLog(ctx, "update_rules", req.ChainName, req.ContainerId, false)
+		return &pb.UpdateRulesResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	if err := s.authorizeChain(ctx, req.ChainName); err != nil {
+		s.auditLog(ctx, "update_rules", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
+	if req.Policy == nil {
+		s.auditLog(ctx, "update_rules", req.ChainName, req.ContainerId, false)
+		return nil, status.Error(codes.InvalidArgument, "network policy is required")
+	}
+
+	release, err := s.workers.acquire(ctx, false)
+	if err != nil {
+		s.auditLog(ctx, "update_rules", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+	defer release()
+
+	diff, err := s.updatePolicy(ctx, "update_rules", req.ChainName, req.Policy, true)
+	if err != nil {
+		s.auditLog(ctx, "update_rules", req.ChainName, req.ContainerId, false)
+		return &pb.UpdateRulesResponse{
+			Success:      false,
+			Error:        strPtr(err.Error()),
+			RulesApplied: int32(diff.Rules),
+		}, nil
+	}
+
+	s.auditLog(ctx, "update_rules", req.ChainName, req.ContainerId, true)
+	return &pb.UpdateRulesResponse{
+		Success:      true,
+		RulesApplied: int32(diff.Rules),
+		RulesAdded:   int32(diff.Added),
+		RulesRemoved: int32(diff.Removed),
+		Replaced:     diff.Replaced,
+	}, nil
+}
+
+// updatePolicy puts policy in place of the one applied to a chain created by
+// SetupChain, rebuilding the chain or, when incremental, changing only the
+// rules that differ. Its pod group and DNS allowlist follow the new policy.
+func (s *Server) updatePolicy(ctx context.Context, operation string, chainName string, policy *pb.NetworkPolicy, incremental bool) (iptables.RuleDiff, error) {
+	s.chainMu.RLock()
+	containerIP := s.chainIPs[chainName]
+	previous := s.policies[chainName]
+	s.chainMu.RUnlock()
+
+	if containerIP == "" {
+		return iptables.RuleDiff{}, errors.New("updating a network policy requires a chain created by SetupChain")
+	}
+
+	if err := s.checkDNSFilter(policy, containerIP); err != nil {
+		return iptables.RuleDiff{}, err
+	}
+
+	// Peers must be able to reach the container before its chain lets their replies through
+	previousGroup := s.groups.Group(chainName)
+	peers, err := s.groups.Join(ctx, policy.PodGroup, chainName, containerIP)
+	if err != nil {
+		return iptables.RuleDiff{}, err
+	}
+
+	var diff iptables.RuleDiff
+	if incremental {
+		// The peer accepts are only left alone when the group is unchanged
+		if previousGroup != policy.PodGroup {
+			previous = nil
+		}
+		diff, err = s.chains.update(ctx, chainName, containerIP, previous, policy, s.ports.Mappings(chainName), peers)
+	} else {
+		diff.Rules, err = iptables.ReplaceRules(ctx, chainName, containerIP, policy, s.ports.Mappings(chainName), peers)
+	}
+	if err != nil {
+		metrics.FirewallFailure(operation)
+		// The live chain still holds the old policy, so restore its group
+		_, _ = s.groups.Join(ctx, previousGroup, chainName, containerIP)
+		return iptables.RuleDiff{}, err
+	}
+
+	if err := s.syncDNSFilter(policy, containerIP); err != nil {
+		return diff, err
+	}
+
+	// Connections the old policy let in would otherwise outlive it
+	s.flushConnections(ctx, chainName, containerIP)
+
+	s.chainMu.Lock()
+	s.policies[chainName] = policy
+	s.chainMu.Unlock()
+	s.recordPolicy(chainName, policy)
+
+	metrics.RulesApplied(diff.Rules)
+	return diff, nil
+}
+
+// checkDNSFilter reports whether the DNS domain allowlist of policy, if any, can be enforced
+func (s *Server) checkDNSFilter(policy *pb.NetworkPolicy, containerIP string) error {
+	if len(policy.DnsAllowedDomains) == 0 {
+		return nil
+	}
+	if s.dnsFilter == nil {
+		return errors.New("DNS domain filtering is not enabled on this bastion")
+	}
+	if containerIP == "" {
+		return errors.New("DNS domain filtering requires a chain created by SetupChain")
+	}
+	return nil
+}
+
+// checkPodGroup reports whether the pod group of policy, if any, can be joined
+func checkPodGroup(policy *pb.NetworkPolicy, containerIP string) error {
+	if policy.PodGroup != "" && containerIP == "" {
+		return errors.New("pod groups require a chain created by SetupChain")
+	}
+	return nil
+}
+
+// syncDNSFilter points the DNS filter at the allowlist of policy, or removes the
+// container from it when the policy has none
+func (s *Server) syncDNSFilter(policy *pb.NetworkPolicy, containerIP string) error {
+	if s.dnsFilter == nil || containerIP == "" {
+		return nil
+	}
+	if len(policy.DnsAllowedDomains) == 0 {
+		s.dnsFilter.RemovePolicy(containerIP)
+		return nil
+	}
+	return s.dnsFilter.SetPolicy(containerIP, policy.DnsAllowedDomains, policy.DnsServers)
+}
+
 func (s *Server) CleanupChain(ctx context.Context, req *pb.CleanupChainRequest) (*pb.CleanupChainResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
 	if err := validation.ValidateChainName(req.ChainName); err != nil {
-		s.auditLog("cleanup_chain", req.ChainName, req.ContainerId, false)
+		s.auditLog(ctx, "cleanup_chain", req.ChainName, req.ContainerId, false)
 		return &pb.CleanupChainResponse{
 			Success: false,
 			Error:   strPtr(err.Error()),
 		}, nil
 	}
 
+	if err := s.authorizeChain(ctx, req.ChainName); err != nil {
+		s.auditLog(ctx, "cleanup_chain", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
+	release, err := s.workers.acquire(ctx, true)
+	if err != nil {
+		s.auditLog(ctx, "cleanup_chain", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+	defer release()
+
 	s.chainMu.RLock()
 	containerIP := s.chainIPs[req.ChainName]
 	s.chainMu.RUnlock()
 
-	if err := iptables.CleanupChain(ctx, req.ChainName, containerIP); err != nil {
-		s.auditLog("cleanup_chain", req.ChainName, req.ContainerId, false)
+	if err := s.removeChain(ctx, req.ChainName, containerIP); err != nil {
+		metrics.FirewallFailure("cleanup_chain")
+		s.auditLog(ctx, "cleanup_chain", req.ChainName, req.ContainerId, false)
 		return &pb.CleanupChainResponse{
 			Success: false,
 			Error:   strPtr(err.Error()),
 		}, nil
 	}
 
+	s.auditLog(ctx, "cleanup_chain", req.ChainName, req.ContainerId, true)
+	return &pb.CleanupChainResponse{
+		Success: true,
+	}, nil
+}
+
+// authorizeChain returns PermissionDenied unless the caller may change
+// chainName: an admin, or the run that set it up
+func (s *Server) authorizeChain(ctx context.Context, chainName string) error {
+	caller := auth.FromContext(ctx)
+	if caller.Admin {
+		return nil
+	}
+	record, ok := s.records.Get(chainName)
+	if !ok || !caller.CanAct(record.Owner) {
+		return status.Errorf(codes.PermissionDenied, "chain %s does not belong to run %s", chainName, caller.RunID)
+	}
+	return nil
+}
+
+// authorizeContainerIP returns PermissionDenied unless the caller may set up
+// chainName for ip. A run holding pool networks may only use an address in
+// one of them, and no run may use an address in a network another run holds.
+// A run on the default bridge holds none, so it is only kept off addresses
+// other runs already have chains for.
+func (s *Server) authorizeContainerIP(ctx context.Context, chainName string, ip net.IP) error {
+	caller := auth.FromContext(ctx)
+	if caller.Admin {
+		return nil
+	}
+
+	networks, err := s.activeNetworks()
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to list pool networks: %v", err)
+	}
+
+	s.networkOwnerMu.Lock()
+	holdsNetwork := false
+	inOwnNetwork := false
+	heldBy := ""
+	for _, network := range networks {
+		owner, ok := s.networkOwners[*network.CurrentContainer]
+		_, subnet, err := net.ParseCIDR(network.Subnet)
+		if !ok || err != nil {
+			continue
+		}
+		if owner == caller.RunID {
+			holdsNetwork = true
+			inOwnNetwork = inOwnNetwork || subnet.Contains(ip)
+		} else if subnet.Contains(ip) {
+			heldBy = owner
+		}
+	}
+	s.networkOwnerMu.Unlock()
+
+	if heldBy != "" && !inOwnNetwork {
+		return status.Errorf(codes.PermissionDenied, "container IP %s is in a network held by another run", ip)
+	}
+	if holdsNetwork && !inOwnNetwork {
+		return status.Errorf(codes.PermissionDenied, "container IP %s is not in a network acquired by run %s", ip, caller.RunID)
+	}
+
+	for name, record := range s.records.All() {
+		if name != chainName && record.Owner != caller.RunID && ip.Equal(net.ParseIP(record.ContainerIP)) {
+			return status.Errorf(codes.PermissionDenied, "container IP %s has a chain of another run", ip)
+		}
+	}
+	return nil
+}
+
+// removeChain tears down chainName and forgets everything held for it.
+// containerIP may be empty when it is not known.
+func (s *Server) removeChain(ctx context.Context, chainName string, containerIP string) error {
+	s.ports.Release(ctx, chainName)
+	s.groups.Leave(ctx, chainName)
+
+	if err := s.chains.cleanup(ctx, chainName, containerIP); err != nil {
+		return err
+	}
+	if containerIP != "" {
+		s.flushConnections(ctx, chainName, containerIP)
+	}
+
 	s.chainMu.Lock()
-	delete(s.chainIPs, req.ChainName)
+	delete(s.chainIPs, chainName)
+	delete(s.policies, chainName)
 	s.chainMu.Unlock()
 
-	s.auditLog("cleanup_chain", req.ChainName, req.ContainerId, true)
-	return &pb.CleanupChainResponse{
+	if s.dnsFilter != nil && containerIP != "" {
+		s.dnsFilter.RemovePolicy(containerIP)
+	}
+
+	if err := s.records.Delete(chainName); err != nil {
+		s.logger.Warn("failed to remove chain record", "chain_name", chainName, "error", err)
+	}
+	return nil
+}
+
+func (s *Server) ExposePorts(ctx context.Context, req *pb.ExposePortsRequest) (*pb.ExposePortsResponse, error) {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
+	if err := validation.ValidateChainName(req.ChainName); err != nil {
+		s.auditLog(ctx, "expose_ports", req.ChainName, req.ContainerId, false)
+		return &pb.ExposePortsResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	if err := s.authorizeChain(ctx, req.ChainName); err != nil {
+		s.auditLog(ctx, "expose_ports", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+
+	release, err := s.workers.acquire(ctx, false)
+	if err != nil {
+		s.auditLog(ctx, "expose_ports", req.ChainName, req.ContainerId, false)
+		return nil, err
+	}
+	defer release()
+
+	s.chainMu.RLock()
+	containerIP, ok := s.chainIPs[req.ChainName]
+	s.chainMu.RUnlock()
+	if !ok {
+		s.auditLog(ctx, "expose_ports", req.ChainName, req.ContainerId, false)
+		return nil, status.Error(codes.NotFound, "no container registered for chain")
+	}
+
+	published, err := s.ports.Expose(ctx, req.ChainName, containerIP, req.Ports)
+	if err != nil {
+		s.auditLog(ctx, "expose_ports", req.ChainName, req.ContainerId, false)
+		return &pb.ExposePortsResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	s.recordPorts(req.ChainName)
+	for _, m := range published {
+		s.logger.Info("port exposed",
+			"chain_name", req.ChainName,
+			"container_id", req.ContainerId,
+			"run_id", runID(ctx),
+			"container_port", m.ContainerPort,
+			"host_port", m.HostPort,
+			"protocol", m.Protocol,
+		)
+	}
+
+	s.auditLog(ctx, "expose_ports", req.ChainName, req.ContainerId, true)
+	return &pb.ExposePortsResponse{
 		Success: true,
+		Ports:   published,
 	}, nil
 }
 
 func (s *Server) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
 	iptablesAvailable := iptables.CheckIPTables(ctx) == nil
 
-	return &pb.HealthResponse{
+	resp := &pb.HealthResponse{
 		Healthy:           iptablesAvailable,
 		Version:           s.version,
 		IptablesAvailable: iptablesAvailable,
-	}, nil
+	}
+	if s.dnsFilter != nil {
+		resp.DnsFilterAddress = strPtr(s.dnsFilter.IP())
+	}
+
+	return resp, nil
+}
+
+func (s *Server) StreamFlowLogs(req *pb.StreamFlowLogsRequest, stream pb.BastionService_StreamFlowLogsServer) error {
+	if err := validation.ValidateChainName(req.ChainName); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.authorizeChain(stream.Context(), req.ChainName); err != nil {
+		s.auditLog(stream.Context(), "stream_flow_logs", req.ChainName, req.ContainerId, false)
+		return err
+	}
+
+	if s.flowLogs == nil {
+		return status.Error(codes.Unavailable, "flow logging is not available on this bastion")
+	}
+
+	records, unsubscribe := s.flowLogs.Subscribe(req.ChainName)
+	defer unsubscribe()
+
+	s.auditLog(stream.Context(), "stream_flow_logs", req.ChainName, req.ContainerId, true)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case record := <-records:
+			if err := stream.Send(&pb.FlowRecord{
+				ChainName: record.Chain,
+				Verdict:   record.Verdict,
+				Protocol:  record.Protocol,
+				Src:       record.Src,
+				Dst:       record.Dst,
+				SrcPort:   record.SrcPort,
+				DstPort:   record.DstPort,
+				Timestamp: record.Timestamp.UnixMilli(),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) CapturePackets(req *pb.CapturePacketsRequest, stream pb.BastionService_CapturePacketsServer) error {
+	if err := validation.ValidateChainName(req.ChainName); err != nil {
+		s.auditLog(stream.Context(), "capture_packets", req.ChainName, req.ContainerId, false)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.authorizeChain(stream.Context(), req.ChainName); err != nil {
+		s.auditLog(stream.Context(), "capture_packets", req.ChainName, req.ContainerId, false)
+		return err
+	}
+
+	s.chainMu.RLock()
+	containerIP, ok := s.chainIPs[req.ChainName]
+	s.chainMu.RUnlock()
+
+	if !ok {
+		s.auditLog(stream.Context(), "capture_packets", req.ChainName, req.ContainerId, false)
+		return status.Errorf(codes.NotFound, "chain %s is not set up", req.ChainName)
+	}
+
+	opts := capture.Options{
+		Filter:      req.GetFilter(),
+		MaxPackets:  req.GetMaxPackets(),
+		MaxDuration: time.Duration(req.GetMaxDurationSecs()) * time.Second,
+		Snaplen:     req.GetSnaplen(),
+	}
+	if _, err := capture.BuildArgs(containerIP, opts); err != nil {
+		s.auditLog(stream.Context(), "capture_packets", req.ChainName, req.ContainerId, false)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.auditLog(stream.Context(), "capture_packets", req.ChainName, req.ContainerId, true)
+
+	err := capture.Run(stream.Context(), containerIP, opts, func(data []byte) error {
+		return stream.Send(&pb.CaptureChunk{Data: data})
+	})
+	if err != nil {
+		s.logger.Warn("packet capture failed",
+			"chain_name", req.ChainName,
+			"container_id", req.ContainerId,
+			"run_id", runID(stream.Context()),
+			"error", err,
+		)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
 }
 
+// maxExcludeSubnets bounds the exclusions a single AcquireNetwork may give
+const maxExcludeSubnets = 64
+
 func (s *Server) AcquireNetwork(ctx context.Context, req *pb.AcquireNetworkRequest) (*pb.AcquireNetworkResponse, error) {
 	if req.NetworkConfig == nil {
 		return nil, status.Error(codes.InvalidArgument, "network config is required")
@@ -154,6 +855,10 @@ func (s *Server) AcquireNetwork(ctx context.Context, req *pb.AcquireNetworkReque
 		}, nil
 	}
 
+	if err := s.authorizeNetwork(ctx, req.ContainerId); err != nil {
+		return nil, err
+	}
+
 	minIPs := uint32(254)
 	if req.NetworkConfig.MinIps != nil {
 		minIPs = *req.NetworkConfig.MinIps
@@ -171,13 +876,56 @@ func (s *Server) AcquireNetwork(ctx context.Context, req *pb.AcquireNetworkReque
 		}, nil
 	}
 
+	options := networkpool.NetworkOptions{
+		Driver:             driver,
+		MTU:                req.NetworkConfig.GetMtu(),
+		EnableICC:          req.NetworkConfig.EnableIcc,
+		EnableIPMasquerade: req.NetworkConfig.EnableIpMasquerade,
+		Encrypted:          req.NetworkConfig.GetEncrypted(),
+		Parent:             req.NetworkConfig.GetParent(),
+		Mode:               req.NetworkConfig.GetDriverMode(),
+		Gateway:            req.NetworkConfig.GetGateway(),
+	}
+	if req.NetworkConfig.Mtu != nil {
+		if err := validation.ValidateMTU(options.MTU); err != nil {
+			return &pb.AcquireNetworkResponse{
+				Success: false,
+				Error:   strPtr(err.Error()),
+			}, nil
+		}
+	}
+
+	if req.PartitionKey != nil {
+		if err := validation.ValidatePartitionKey(*req.PartitionKey); err != nil {
+			return &pb.AcquireNetworkResponse{
+				Success: false,
+				Error:   strPtr(err.Error()),
+			}, nil
+		}
+	}
+
+	if len(req.ExcludeSubnets) > maxExcludeSubnets {
+		return &pb.AcquireNetworkResponse{
+			Success: false,
+			Error:   strPtr(fmt.Sprintf("too many excluded subnets: %d (max %d)", len(req.ExcludeSubnets), maxExcludeSubnets)),
+		}, nil
+	}
+	exclude, err := networkpool.ParseExcludedSubnets(strings.Join(req.ExcludeSubnets, ","))
+	if err != nil {
+		return &pb.AcquireNetworkResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
 	var leaseDuration *time.Duration
 	if req.LeaseDurationSecs != nil {
 		d := time.Duration(*req.LeaseDurationSecs) * time.Second
 		leaseDuration = &d
 	}
 
-	result, err := s.networkPool.Acquire(ctx, req.ContainerId, req.NetworkConfig.ConfigHash, req.NetworkConfig.SubnetRange, leaseDuration)
+	result, err := s.networkPool.AcquireExcluding(ctx, req.ContainerId, req.NetworkConfig.ConfigHash, req.GetPartitionKey(), req.NetworkConfig.SubnetRange, exclude, options, leaseDuration)
+	metrics.PoolAcquired(result != nil && result.Reused, err)
 	if err != nil {
 		return &pb.AcquireNetworkResponse{
 			Success: false,
@@ -185,6 +933,10 @@ func (s *Server) AcquireNetwork(ctx context.Context, req *pb.AcquireNetworkReque
 		}, nil
 	}
 
+	s.networkOwnerMu.Lock()
+	s.networkOwners[req.ContainerId] = auth.FromContext(ctx).RunID
+	s.networkOwnerMu.Unlock()
+
 	return &pb.AcquireNetworkResponse{
 		Success:     true,
 		NetworkName: &result.NetworkName,
@@ -211,12 +963,23 @@ func (s *Server) ReleaseNetwork(ctx context.Context, req *pb.ReleaseNetworkReque
 		}, nil
 	}
 
+	if err := s.authorizeNetwork(ctx, req.ContainerId); err != nil {
+		return nil, err
+	}
+
 	forceCleanup := false
 	if req.ForceCleanup != nil {
 		forceCleanup = *req.ForceCleanup
 	}
 
-	result, err := s.networkPool.Release(ctx, req.ContainerId, req.NetworkName, forceCleanup)
+	var ttl *time.Duration
+	if req.PoolTtlSecs != nil {
+		d := time.Duration(*req.PoolTtlSecs) * time.Second
+		ttl = &d
+	}
+
+	result, err := s.networkPool.ReleaseWithTTL(ctx, req.ContainerId, req.NetworkName, forceCleanup, ttl)
+	metrics.PoolReleased(result != nil && result.CleanedUp, err)
 	if err != nil {
 		return &pb.ReleaseNetworkResponse{
 			Success:   false,
@@ -225,41 +988,178 @@ func (s *Server) ReleaseNetwork(ctx context.Context, req *pb.ReleaseNetworkReque
 		}, nil
 	}
 
+	s.networkOwnerMu.Lock()
+	delete(s.networkOwners, req.ContainerId)
+	s.networkOwnerMu.Unlock()
+
 	return &pb.ReleaseNetworkResponse{
 		Success:   true,
 		CleanedUp: result.CleanedUp,
 	}, nil
 }
 
+func (s *Server) RenewNetworkLease(ctx context.Context, req *pb.RenewNetworkLeaseRequest) (*pb.RenewNetworkLeaseResponse, error) {
+	if err := validation.ValidateContainerID(req.ContainerId); err != nil {
+		return &pb.RenewNetworkLeaseResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	if err := validation.ValidateNetworkName(req.NetworkName); err != nil {
+		return &pb.RenewNetworkLeaseResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	if err := s.authorizeNetwork(ctx, req.ContainerId); err != nil {
+		return nil, err
+	}
+
+	var duration *time.Duration
+	if req.LeaseSecs != nil {
+		d := time.Duration(*req.LeaseSecs) * time.Second
+		duration = &d
+	}
+
+	expiresAt, err := s.networkPool.RenewLease(req.ContainerId, req.NetworkName, duration)
+	if err != nil {
+		return &pb.RenewNetworkLeaseResponse{
+			Success: false,
+			Error:   strPtr(err.Error()),
+		}, nil
+	}
+
+	return &pb.RenewNetworkLeaseResponse{
+		Success:        true,
+		LeaseExpiresAt: expiresAt.UnixMilli(),
+	}, nil
+}
+
+// authorizeNetwork returns PermissionDenied unless the caller may acquire or
+// release the network of containerID. Networks acquired before a restart have
+// no owner on record and are left to any caller, as the pool itself checks
+// that the container holds the network.
+func (s *Server) authorizeNetwork(ctx context.Context, containerID string) error {
+	s.networkOwnerMu.Lock()
+	owner, ok := s.networkOwners[containerID]
+	s.networkOwnerMu.Unlock()
+
+	caller := auth.FromContext(ctx)
+	if ok && !caller.CanAct(owner) {
+		return status.Errorf(codes.PermissionDenied, "network of container %s does not belong to run %s", containerID, caller.RunID)
+	}
+	return nil
+}
+
 func (s *Server) GetNetworkStats(ctx context.Context, req *pb.NetworkStatsRequest) (*pb.NetworkStatsResponse, error) {
 	stats := s.networkPool.Stats()
 
+	prewarm := make([]*pb.PrewarmStatus, 0, len(stats.Prewarm))
+	for _, status := range stats.Prewarm {
+		entry := &pb.PrewarmStatus{
+			ConfigHash: status.ConfigHash,
+			Target:     status.Target,
+			Ready:      status.Ready,
+		}
+		if status.LastError != "" {
+			entry.LastError = strPtr(status.LastError)
+		}
+		prewarm = append(prewarm, entry)
+	}
+
+	partitions := make([]*pb.SubnetPartition, 0, len(stats.Partitions))
+	for _, partition := range stats.Partitions {
+		partitions = append(partitions, &pb.SubnetPartition{
+			Key:      partition.Key,
+			Subnet:   partition.Subnet,
+			Networks: partition.Networks,
+		})
+	}
+
 	return &pb.NetworkStatsResponse{
-		TotalNetworks:     stats.TotalNetworks,
-		ActiveNetworks:    stats.ActiveNetworks,
-		PooledNetworks:    stats.PooledNetworks,
-		PendingCleanup:    stats.PendingCleanup,
-		Utilization:       stats.Utilization,
-		Healthy:           stats.Healthy,
-		SubnetUtilization: stats.SubnetUtilization,
-		MaxSubnets:        stats.MaxSubnets,
+		TotalNetworks:             stats.TotalNetworks,
+		ActiveNetworks:            stats.ActiveNetworks,
+		PooledNetworks:            stats.PooledNetworks,
+		PendingCleanup:            stats.PendingCleanup,
+		Utilization:               stats.Utilization,
+		Healthy:                   stats.Healthy,
+		SubnetUtilization:         stats.SubnetUtilization,
+		MaxSubnets:                stats.MaxSubnets,
+		CleanupQueueDepth:         stats.CleanupQueue.Depth,
+		CleanupProcessed:          stats.CleanupQueue.Processed,
+		CleanupFailed:             stats.CleanupQueue.Failed,
+		CleanupAvgLatencyMs:       stats.CleanupQueue.AvgLatencyMs,
+		CleanupMaxLatencyMs:       stats.CleanupQueue.MaxLatencyMs,
+		ChainsDrifted:             s.drift.drifted.Load(),
+		ChainsRepaired:            s.drift.repaired.Load(),
+		ChainRepairsFailed:        s.drift.repairsFailed.Load(),
+		UnknownChains:             s.drift.unknown.Load(),
+		OrphanedChainsRemoved:     s.orphansRemoved.Load(),
+		Prewarm:                   prewarm,
+		MaxNetworks:               stats.MaxNetworks,
+		NetworkEvictions:          stats.Evictions,
+		Partitions:                partitions,
+		NetworksRemovedExternally: stats.RemovedExternally,
+		SuspectLeases:             stats.SuspectLeases,
+		LeasesReclaimed:           stats.LeasesReclaimed,
 	}, nil
 }
 
-func (s *Server) auditLog(operation, chainName, containerID string, success bool) {
+func (s *Server) auditLog(ctx context.Context, operation, chainName, containerID string, success bool) {
+	metrics.Operation(operation, success)
+
+	principal := auth.FromContext(ctx).String()
 	if success {
 		s.logger.Info("privileged operation succeeded",
 			"operation", operation,
 			"chain_name", chainName,
 			"container_id", containerID,
+			"run_id", runID(ctx),
+			"principal", principal,
 		)
 	} else {
 		s.logger.Warn("privileged operation failed",
 			"operation", operation,
 			"chain_name", chainName,
 			"container_id", containerID,
+			"run_id", runID(ctx),
+			"principal", principal,
 		)
 	}
+
+	if s.audit == nil {
+		return
+	}
+	err := s.audit.Append(auditlog.Entry{
+		Time:        time.Now(),
+		Operation:   operation,
+		ChainName:   chainName,
+		ContainerID: containerID,
+		RunID:       runID(ctx),
+		Principal:   principal,
+		Success:     success,
+	})
+	if err != nil {
+		s.logger.Error("failed to write audit log", "operation", operation, "error", err)
+	}
+}
+
+// RunIDMetadataKey is the gRPC metadata the isolation-runner sends the
+// container-manager's run ID in
+const RunIDMetadataKey = auth.RunIDMetadataKey
+
+// runID returns the run ID the caller sent, or "" if it sent none
+func runID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(RunIDMetadataKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
 }
 
 func strPtr(s string) *string {
@@ -24,7 +24,7 @@ func TestChainIPTrackingUnit(t *testing.T) {
 	}
 	defer pool.Stop()
 
-	server := New("test", pool, logger)
+	server := New("test", pool, nil, nil, logger)
 
 	t.Run("stores IP mapping", func(t *testing.T) {
 		chainName := "ISO-test123456789ab"
@@ -107,7 +107,7 @@ func TestChainIPTracking(t *testing.T) {
 	}
 	defer pool.Stop()
 
-	server := New("test", pool, logger)
+	server := New("test", pool, nil, nil, logger)
 
 	t.Run("stores and retrieves container IP", func(t *testing.T) {
 		if os.Getuid() != 0 {
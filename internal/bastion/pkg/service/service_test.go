@@ -1,20 +1,26 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
 	"github.com/metorial/fleet/holopod/internal/bastion/pkg/networkpool"
 	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
 )
 
 func TestHealth(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
-	server := New("1.0.0-test", nil, logger)
+	server := New("1.0.0-test", nil, nil, nil, logger)
 
 	ctx := context.Background()
 	resp, err := server.Health(ctx, &pb.HealthRequest{})
@@ -29,7 +35,7 @@ func TestHealth(t *testing.T) {
 
 func TestSetupChainValidation(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
-	server := New("1.0.0-test", nil, logger)
+	server := New("1.0.0-test", nil, nil, nil, logger)
 
 	ctx := context.Background()
 
@@ -76,7 +82,7 @@ func TestSetupChainValidation(t *testing.T) {
 
 func TestApplyRulesValidation(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
-	server := New("1.0.0-test", nil, logger)
+	server := New("1.0.0-test", nil, nil, nil, logger)
 
 	ctx := context.Background()
 
@@ -103,6 +109,124 @@ func TestApplyRulesValidation(t *testing.T) {
 			t.Error("ApplyRules() with invalid chain name should fail")
 		}
 	})
+
+	t.Run("dns allowlist without filter", func(t *testing.T) {
+		resp, err := server.ApplyRules(ctx, &pb.ApplyRulesRequest{
+			ChainName:   "ISO-0123456789abcdef",
+			ContainerId: "abc123def456",
+			Policy: &pb.NetworkPolicy{
+				Policy:            "deny",
+				AllowDns:          true,
+				DnsAllowedDomains: []string{"example.com"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("ApplyRules() error = %v", err)
+		}
+		if resp.Success {
+			t.Error("ApplyRules() with a DNS allowlist should fail when no filter is configured")
+		}
+	})
+}
+
+func TestApplyRulesPodGroupRequiresChain(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	server := New("1.0.0-test", nil, nil, nil, logger)
+
+	resp, err := server.ApplyRules(context.Background(), &pb.ApplyRulesRequest{
+		ChainName:   "ISO-0123456789abcdef",
+		ContainerId: "abc123def456",
+		Policy:      &pb.NetworkPolicy{Policy: "deny", PodGroup: "web"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyRules() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("ApplyRules() with a pod group should fail for a chain not created by SetupChain")
+	}
+}
+
+func TestUpdateNetworkPolicyValidation(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	server := New("1.0.0-test", nil, nil, nil, logger)
+
+	ctx := context.Background()
+
+	t.Run("missing policy", func(t *testing.T) {
+		_, err := server.UpdateNetworkPolicy(ctx, &pb.UpdateNetworkPolicyRequest{
+			ChainName:   "ISO-0123456789abcdef",
+			ContainerId: "abc123def456",
+		})
+		if err == nil {
+			t.Error("UpdateNetworkPolicy() with nil policy should error")
+		}
+	})
+
+	tests := []struct {
+		name      string
+		chainName string
+		policy    *pb.NetworkPolicy
+	}{
+		{"invalid chain name", "invalid", &pb.NetworkPolicy{Policy: "allow"}},
+		{"unknown chain", "ISO-0123456789abcdef", &pb.NetworkPolicy{Policy: "allow"}},
+		{"dns allowlist without filter", "ISO-fedcba9876543210", &pb.NetworkPolicy{
+			Policy:            "deny",
+			AllowDns:          true,
+			DnsAllowedDomains: []string{"example.com"},
+		}},
+		{"invalid pod group", "ISO-fedcba9876543210", &pb.NetworkPolicy{Policy: "deny", PodGroup: "web tier"}},
+	}
+
+	// The last cases have a tracked chain, so only their policy can fail them
+	server.chainIPs["ISO-fedcba9876543210"] = "172.17.0.9"
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.UpdateNetworkPolicy(ctx, &pb.UpdateNetworkPolicyRequest{
+				ChainName:   tt.chainName,
+				ContainerId: "abc123def456",
+				Policy:      tt.policy,
+			})
+			if err != nil {
+				t.Fatalf("UpdateNetworkPolicy() error = %v", err)
+			}
+			if resp.Success {
+				t.Error("UpdateNetworkPolicy() should fail")
+			}
+		})
+	}
+}
+
+func TestExposePortsValidation(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	server := New("1.0.0-test", nil, nil, nil, logger)
+
+	ctx := context.Background()
+
+	t.Run("invalid chain name", func(t *testing.T) {
+		resp, err := server.ExposePorts(ctx, &pb.ExposePortsRequest{
+			ChainName:   "FORWARD",
+			ContainerId: "abc123def456",
+			Ports:       []*pb.PortMapping{{ContainerPort: 80}},
+		})
+		if err != nil {
+			t.Fatalf("ExposePorts() error = %v", err)
+		}
+		if resp.Success {
+			t.Error("ExposePorts() with invalid chain name should fail")
+		}
+	})
+
+	t.Run("unknown chain", func(t *testing.T) {
+		_, err := server.ExposePorts(ctx, &pb.ExposePortsRequest{
+			ChainName:   "ISO-0123456789abcdef",
+			ContainerId: "abc123def456",
+			Ports:       []*pb.PortMapping{{ContainerPort: 80}},
+		})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("ExposePorts() for unknown chain: expected NotFound, got %v", err)
+		}
+	})
 }
 
 func TestAcquireNetworkValidation(t *testing.T) {
@@ -122,7 +246,7 @@ func TestAcquireNetworkValidation(t *testing.T) {
 	}
 	defer pool.Stop()
 
-	server := New("1.0.0-test", pool, logger)
+	server := New("1.0.0-test", pool, nil, nil, logger)
 
 	t.Run("missing network config", func(t *testing.T) {
 		_, err := server.AcquireNetwork(ctx, &pb.AcquireNetworkRequest{
@@ -166,7 +290,7 @@ func TestReleaseNetworkValidation(t *testing.T) {
 	}
 	defer pool.Stop()
 
-	server := New("1.0.0-test", pool, logger)
+	server := New("1.0.0-test", pool, nil, nil, logger)
 
 	t.Run("invalid container ID", func(t *testing.T) {
 		resp, err := server.ReleaseNetwork(ctx, &pb.ReleaseNetworkRequest{
@@ -212,7 +336,7 @@ func TestGetNetworkStats(t *testing.T) {
 	}
 	defer pool.Stop()
 
-	server := New("1.0.0-test", pool, logger)
+	server := New("1.0.0-test", pool, nil, nil, logger)
 
 	_, err = server.GetNetworkStats(ctx, &pb.NetworkStatsRequest{})
 	if err != nil {
@@ -234,3 +358,54 @@ func dockerAvailable() bool {
 	os.Remove(stateFile)
 	return true
 }
+
+func TestAuditLogRunID(t *testing.T) {
+	var buf bytes.Buffer
+	server := New("1.0.0-test", nil, nil, nil, slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RunIDMetadataKey, "run_abc"))
+	if _, err := server.SetupChain(ctx, &pb.SetupChainRequest{ChainName: "bad chain", ContainerId: "c1"}); err != nil {
+		t.Fatalf("SetupChain() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"run_id":"run_abc"`) {
+		t.Errorf("expected the run ID in the audit log, got %s", buf.String())
+	}
+}
+
+func TestApplyRulesDryRun(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	server := New("1.0.0-test", nil, nil, nil, logger)
+
+	resp, err := server.ApplyRules(context.Background(), &pb.ApplyRulesRequest{
+		ChainName:   "ISO-0123456789abcdef",
+		ContainerId: "abc123def456",
+		Policy:      &pb.NetworkPolicy{Policy: "deny", AllowDns: true},
+		DryRun:      true,
+	})
+	if err != nil {
+		t.Fatalf("ApplyRules() error = %v", err)
+	}
+	if !resp.Success || len(resp.Commands) == 0 {
+		t.Fatalf("ApplyRules() dry run = %+v, want the planned commands", resp)
+	}
+	if resp.RulesApplied != 0 {
+		t.Errorf("a dry run applied %d rules", resp.RulesApplied)
+	}
+	if _, ok := server.policies["ISO-0123456789abcdef"]; ok {
+		t.Error("a dry run should not record the policy")
+	}
+
+	resp, err = server.ApplyRules(context.Background(), &pb.ApplyRulesRequest{
+		ChainName:   "ISO-0123456789abcdef",
+		ContainerId: "abc123def456",
+		Policy:      &pb.NetworkPolicy{Policy: "maybe"},
+		DryRun:      true,
+	})
+	if err != nil {
+		t.Fatalf("ApplyRules() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("a dry run of an invalid policy should fail")
+	}
+}
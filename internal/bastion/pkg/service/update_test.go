@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestUpdateRules(t *testing.T) {
+	server, _ := newReconcileServer(nil)
+	previous := &pb.NetworkPolicy{Policy: "deny"}
+	server.chainIPs[chainA] = "172.20.1.10"
+	server.policies[chainA] = previous
+
+	var gotOld *pb.NetworkPolicy
+	server.chains.update = func(_ context.Context, _, _ string, oldPolicy, _ *pb.NetworkPolicy, _ []*pb.PortMapping, _ []string) (iptables.RuleDiff, error) {
+		gotOld = oldPolicy
+		return iptables.RuleDiff{Added: 2, Removed: 1, Rules: 9}, nil
+	}
+
+	policy := &pb.NetworkPolicy{Policy: "deny", Whitelist: []*pb.NetworkRule{{Cidr: "10.0.0.0/8"}}}
+	resp, err := server.UpdateRules(context.Background(), &pb.UpdateRulesRequest{ChainName: chainA, Policy: policy})
+	if err != nil || !resp.Success {
+		t.Fatalf("UpdateRules() = %v, %v", resp, err)
+	}
+	if gotOld != previous {
+		t.Error("the diff should start from the policy last applied")
+	}
+	if resp.RulesAdded != 2 || resp.RulesRemoved != 1 || resp.RulesApplied != 9 || resp.Replaced {
+		t.Errorf("UpdateRules() = %+v", resp)
+	}
+	if server.policies[chainA] != policy {
+		t.Error("the new policy should be recorded")
+	}
+
+	resp, err = server.UpdateRules(context.Background(), &pb.UpdateRulesRequest{ChainName: chainB, Policy: policy})
+	if err != nil || resp.Success {
+		t.Errorf("UpdateRules() of a chain not set up = %v, %v", resp, err)
+	}
+}
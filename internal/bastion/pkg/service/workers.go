@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/metrics"
+)
+
+const (
+	// DefaultFirewallWorkers is how many firewall changes may run at once
+	DefaultFirewallWorkers = 4
+
+	// firewallWaitTimeout bounds how long a call queues for a worker
+	firewallWaitTimeout = 30 * time.Second
+)
+
+// FirewallWorkersFromEnv reads BASTION_FIREWALL_WORKERS, falling back to the
+// default when it is unset or not a positive number
+func FirewallWorkersFromEnv() int {
+	if n, err := strconv.Atoi(os.Getenv("BASTION_FIREWALL_WORKERS")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultFirewallWorkers
+}
+
+// workerPool bounds the firewall changes made at once. Each change runs a
+// handful of iptables processes that contend for the xtables lock, so letting
+// every call in at once only makes them all slow. Cleanups have a slot of
+// their own besides, so a burst of launches cannot hold them up.
+type workerPool struct {
+	slots   chan struct{}
+	cleanup chan struct{}
+}
+
+func newWorkerPool(n int) *workerPool {
+	return &workerPool{
+		slots:   make(chan struct{}, n),
+		cleanup: make(chan struct{}, 1),
+	}
+}
+
+// acquire waits for a worker and returns the function that frees it. It fails
+// with ResourceExhausted when none frees up in time.
+func (p *workerPool) acquire(ctx context.Context, cleanup bool) (func(), error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, firewallWaitTimeout)
+	defer cancel()
+
+	var reserved chan struct{}
+	if cleanup {
+		reserved = p.cleanup
+	}
+
+	// A nil channel never proceeds, so only cleanups can take the reserved slot
+	select {
+	case p.slots <- struct{}{}:
+		metrics.FirewallWait(time.Since(start))
+		return func() { <-p.slots }, nil
+	case reserved <- struct{}{}:
+		metrics.FirewallWait(time.Since(start))
+		return func() { <-reserved }, nil
+	case <-ctx.Done():
+		return nil, status.Error(codes.ResourceExhausted, "all firewall workers are busy")
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWorkerPool(t *testing.T) {
+	p := newWorkerPool(1)
+
+	release, err := p.acquire(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.acquire(ctx, false); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("acquire with every worker busy: %v, want ResourceExhausted", err)
+	}
+
+	// A cleanup still gets the reserved slot
+	releaseCleanup, err := p.acquire(context.Background(), true)
+	if err != nil {
+		t.Fatalf("cleanup acquire: %v", err)
+	}
+	releaseCleanup()
+	release()
+
+	if release, err := p.acquire(context.Background(), false); err != nil {
+		t.Errorf("acquire after release: %v", err)
+	} else {
+		release()
+	}
+}
+
+func TestFirewallWorkersFromEnv(t *testing.T) {
+	t.Setenv("BASTION_FIREWALL_WORKERS", "8")
+	if n := FirewallWorkersFromEnv(); n != 8 {
+		t.Errorf("FirewallWorkersFromEnv() = %d, want 8", n)
+	}
+	t.Setenv("BASTION_FIREWALL_WORKERS", "0")
+	if n := FirewallWorkersFromEnv(); n != DefaultFirewallWorkers {
+		t.Errorf("FirewallWorkersFromEnv() = %d, want default", n)
+	}
+}
@@ -0,0 +1,144 @@
+// Package tlsconfig builds the TLS configuration of the bastion's gRPC listener
+// from the environment. The bastion changes iptables on behalf of its callers,
+// so with a client CA configured only isolation-runners holding a certificate
+// signed by it can connect.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// CertFileEnv and KeyFileEnv name the server certificate and its key, both PEM
+	CertFileEnv = "BASTION_TLS_CERT_FILE"
+	KeyFileEnv  = "BASTION_TLS_KEY_FILE"
+
+	// ClientCAFileEnv names the PEM bundle client certificates must chain to.
+	// Setting it makes a client certificate mandatory.
+	ClientCAFileEnv = "BASTION_TLS_CLIENT_CA_FILE"
+)
+
+// Config is where the listener's certificates are read from
+type Config struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// FromEnv reads the listener's TLS settings
+func FromEnv() Config {
+	return Config{
+		CertFile:     os.Getenv(CertFileEnv),
+		KeyFile:      os.Getenv(KeyFileEnv),
+		ClientCAFile: os.Getenv(ClientCAFileEnv),
+	}
+}
+
+// Enabled reports whether any TLS setting is present
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || c.ClientCAFile != ""
+}
+
+// MutualTLS reports whether clients must present a certificate
+func (c Config) MutualTLS() bool {
+	return c.ClientCAFile != ""
+}
+
+// Server loads the certificates into a server TLS configuration. It returns
+// nil when TLS is not configured, and an error when it is only in part.
+func (c Config) Server() (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New(CertFileEnv + " and " + KeyFileEnv + " must both be set to enable TLS")
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientCAFile != "" {
+		pool, err := LoadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// LoadCertPool reads a PEM bundle of CA certificates
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// ClientConfig is where a bastion client reads its certificates from
+type ClientConfig struct {
+	// CAFile verifies the bastion's certificate; empty uses the system roots
+	CAFile string
+	// CertFile and KeyFile are presented when the bastion requires mTLS
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name the bastion's certificate is checked against
+	ServerName string
+}
+
+// Enabled reports whether any TLS setting is present
+func (c ClientConfig) Enabled() bool {
+	return c.CAFile != "" || c.CertFile != "" || c.KeyFile != "" || c.ServerName != ""
+}
+
+// Client loads the certificates into a client TLS configuration. It returns
+// nil when TLS is not configured, and an error when it is only in part.
+func (c ClientConfig) Client() (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("a client certificate needs both its certificate and key file")
+	}
+
+	config := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.CAFile != "" {
+		pool, err := LoadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
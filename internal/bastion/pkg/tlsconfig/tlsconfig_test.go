@@ -0,0 +1,170 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// issuer signs certificates for tests
+type issuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newIssuer(t *testing.T, dir string) (*issuer, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "holopod test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "ca.pem")
+	writePEM(t, path, "CERTIFICATE", der)
+	return &issuer{cert: cert, key: key}, path
+}
+
+// issue writes a certificate for name and its key, returning their paths
+func (i *issuer) issue(t *testing.T, dir, name string, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, i.cert, &key.PublicKey, i.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := filepath.Join(dir, name+".pem")
+	keyPath := filepath.Join(dir, name+"-key.pem")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+	return certPath, keyPath
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caFile := newIssuer(t, dir)
+	serverCert, serverKey := ca.issue(t, dir, "bastion", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "runner", x509.ExtKeyUsageClientAuth)
+
+	serverTLS, err := Config{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: caFile}.Server()
+	if err != nil {
+		t.Fatalf("Server() error = %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(serverTLS)))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	check := func(config ClientConfig) error {
+		clientTLS, err := config.Client()
+		if err != nil {
+			t.Fatalf("Client() error = %v", err)
+		}
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(clientTLS)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		return err
+	}
+
+	if err := check(ClientConfig{CAFile: caFile, CertFile: clientCert, KeyFile: clientKey, ServerName: "bastion"}); err != nil {
+		t.Errorf("client with a certificate was refused: %v", err)
+	}
+	if err := check(ClientConfig{CAFile: caFile, ServerName: "bastion"}); err == nil {
+		t.Error("client without a certificate was let in")
+	}
+
+	// A certificate from another CA is refused too
+	other, _ := newIssuer(t, t.TempDir())
+	otherDir := t.TempDir()
+	otherCert, otherKey := other.issue(t, otherDir, "runner", x509.ExtKeyUsageClientAuth)
+	if err := check(ClientConfig{CAFile: caFile, CertFile: otherCert, KeyFile: otherKey, ServerName: "bastion"}); err == nil {
+		t.Error("client with a certificate from an untrusted CA was let in")
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	if config, err := (Config{}).Server(); config != nil || err != nil {
+		t.Errorf("Server() without settings = %v, %v; want plaintext", config, err)
+	}
+	if _, err := (Config{CertFile: "/nonexistent/cert.pem"}).Server(); err == nil {
+		t.Error("Server() accepted a certificate without a key")
+	}
+	if _, err := (Config{ClientCAFile: "/nonexistent/ca.pem"}).Server(); err == nil {
+		t.Error("Server() accepted a client CA without a server certificate")
+	}
+
+	if config, err := (ClientConfig{}).Client(); config != nil || err != nil {
+		t.Errorf("Client() without settings = %v, %v; want plaintext", config, err)
+	}
+	if _, err := (ClientConfig{CertFile: "/nonexistent/cert.pem"}).Client(); err == nil {
+		t.Error("Client() accepted a certificate without a key")
+	}
+	if _, err := (ClientConfig{CAFile: "/nonexistent/ca.pem"}).Client(); err == nil {
+		t.Error("Client() accepted a missing CA bundle")
+	}
+}
@@ -0,0 +1,183 @@
+// Package unixsock serves the bastion on a unix domain socket, so runners on
+// the same host can reach it without any network port. Only the socket's
+// owner can connect to it, and every connection is checked against the
+// peer's credentials as the kernel reports them, which cannot be forged.
+package unixsock
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	PathEnv = "BASTION_UNIX_SOCKET"
+	UIDsEnv = "BASTION_UNIX_SOCKET_UIDS"
+	GIDEnv  = "BASTION_UNIX_SOCKET_GID"
+
+	socketDirPermissions = 0755
+)
+
+// Config names the socket and who may use it
+type Config struct {
+	Path string
+	// UIDs may connect besides root
+	UIDs []uint32
+	// GID, when set, owns the socket and may open it, so members of the
+	// group pass the file permissions; UIDs are still checked
+	GID *int
+}
+
+// FromEnv reads BASTION_UNIX_SOCKET, the socket path, which is empty when the
+// socket is off; BASTION_UNIX_SOCKET_UIDS, a comma-separated list of user IDs
+// allowed besides root; and BASTION_UNIX_SOCKET_GID, the group given access
+// to the socket file.
+func FromEnv() (Config, error) {
+	cfg := Config{Path: os.Getenv(PathEnv)}
+
+	for _, field := range strings.Split(os.Getenv(UIDsEnv), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		uid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s entry %q: %w", UIDsEnv, field, err)
+		}
+		cfg.UIDs = append(cfg.UIDs, uint32(uid))
+	}
+
+	if value := os.Getenv(GIDEnv); value != "" {
+		gid, err := strconv.Atoi(value)
+		if err != nil || gid < 0 {
+			return Config{}, fmt.Errorf("invalid %s %q", GIDEnv, value)
+		}
+		cfg.GID = &gid
+	}
+
+	return cfg, nil
+}
+
+// Enabled reports whether a socket path is configured
+func (c Config) Enabled() bool {
+	return c.Path != ""
+}
+
+func (c Config) allowed(uid uint32) bool {
+	if uid == 0 {
+		return true
+	}
+	for _, allowed := range c.UIDs {
+		if uid == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Listen creates the socket, replacing one left by an earlier run, and
+// returns a listener that only hands out connections from allowed peers.
+// The file is owned by root and writable by its owner only, or by the
+// configured group too.
+func Listen(cfg Config, logger *slog.Logger) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), socketDirPermissions); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	// Only ever remove a stale socket, never a file that happens to be there
+	if info, err := os.Lstat(cfg.Path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", cfg.Path)
+		}
+		if err := os.Remove(cfg.Path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	// Created without group or other access, so no one can connect before
+	// the permissions below are in place
+	oldMask := syscall.Umask(0177)
+	lis, err := net.Listen("unix", cfg.Path)
+	syscall.Umask(oldMask)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.GID != nil {
+		if err := os.Chown(cfg.Path, os.Geteuid(), *cfg.GID); err != nil {
+			lis.Close()
+			return nil, fmt.Errorf("failed to set socket group: %w", err)
+		}
+		if err := os.Chmod(cfg.Path, 0660); err != nil {
+			lis.Close()
+			return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+		}
+	}
+
+	return &listener{Listener: lis, cfg: cfg, logger: logger}, nil
+}
+
+type listener struct {
+	net.Listener
+	cfg    Config
+	logger *slog.Logger
+}
+
+// Accept returns the next connection from an allowed peer, closing the others
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		cred, err := peerCredentials(conn)
+		if err != nil {
+			l.logger.Warn("rejected unix socket connection", "error", err)
+			conn.Close()
+			continue
+		}
+		if !l.cfg.allowed(cred.Uid) {
+			l.logger.Warn("rejected unix socket connection from disallowed user",
+				"uid", cred.Uid,
+				"pid", cred.Pid,
+			)
+			conn.Close()
+			continue
+		}
+
+		return conn, nil
+	}
+}
+
+// peerCredentials returns the process credentials of the other end of conn,
+// as they were when it connected
+func peerCredentials(conn net.Conn) (*syscall.Ucred, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, errors.New("not a unix socket connection")
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if credErr != nil {
+		return nil, fmt.Errorf("failed to read peer credentials: %w", credErr)
+	}
+	return cred, nil
+}
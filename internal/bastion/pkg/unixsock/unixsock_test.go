@@ -0,0 +1,101 @@
+package unixsock
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(PathEnv, "/run/bastion/bastion.sock")
+	t.Setenv(UIDsEnv, "1000, 1001")
+	t.Setenv(GIDEnv, "999")
+
+	cfg, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv() error = %v", err)
+	}
+	if !cfg.Enabled() || len(cfg.UIDs) != 2 || cfg.GID == nil || *cfg.GID != 999 {
+		t.Errorf("FromEnv() = %+v", cfg)
+	}
+	if !cfg.allowed(0) || !cfg.allowed(1001) || cfg.allowed(1002) {
+		t.Error("expected root and the listed users only to be allowed")
+	}
+
+	t.Setenv(UIDsEnv, "1000,nobody")
+	if _, err := FromEnv(); err == nil {
+		t.Error("expected error for a non-numeric user ID")
+	}
+
+	t.Setenv(UIDsEnv, "")
+	t.Setenv(GIDEnv, "-1")
+	if _, err := FromEnv(); err == nil {
+		t.Error("expected error for a negative group ID")
+	}
+}
+
+func TestListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "bastion.sock")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := Config{Path: path, UIDs: []uint32{uint32(os.Getuid())}}
+
+	// A socket left behind by an earlier run is replaced
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	lis, err := Listen(cfg, logger)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer lis.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+
+	accepted := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err == nil {
+			conn.Close()
+		}
+		accepted <- err
+	}()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	conn.Close()
+	if err := <-accepted; err != nil {
+		t.Errorf("Accept() error = %v", err)
+	}
+}
+
+func TestListenRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bastion.sock")
+	if err := os.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Listen(Config{Path: path}, slog.Default()); err == nil {
+		t.Error("expected Listen() to refuse to replace a regular file")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "data" {
+		t.Error("regular file was changed")
+	}
+}
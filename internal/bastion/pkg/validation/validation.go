@@ -5,12 +5,18 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net"
+	"os"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 var (
-	chainNameRegex = regexp.MustCompile(`^ISO-[a-f0-9]{16}$`)
+	chainNameRegex     = regexp.MustCompile(`^ISO-[a-f0-9]{16}$`)
+	captureFilterRegex = regexp.MustCompile(`^[a-zA-Z0-9 .:/()!<>=&|\[\]-]*$`)
+	domainLabelRegex   = regexp.MustCompile(`^[a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9])?$`)
+	podGroupRegex      = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,63}$`)
+	partitionKeyRegex  = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,63}$`)
 )
 
 type ValidationError struct {
@@ -40,6 +46,69 @@ func ValidateChainName(chainName string) error {
 	return nil
 }
 
+// DefaultContainerIPv6Prefixes are the IPv6 ranges container addresses may
+// come from unless BASTION_CONTAINER_IPV6_PREFIXES says otherwise: unique
+// local addresses, the IPv6 counterpart of RFC1918
+var DefaultContainerIPv6Prefixes = []string{"fc00::/7"}
+
+var (
+	ipv6PrefixesMu sync.RWMutex
+	ipv6Prefixes   = mustParsePrefixes(DefaultContainerIPv6Prefixes)
+)
+
+func mustParsePrefixes(prefixes []string) []*net.IPNet {
+	parsed, err := ParseIPv6Prefixes(prefixes)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// ParseIPv6Prefixes parses IPv6 CIDRs for SetContainerIPv6Prefixes
+func ParseIPv6Prefixes(prefixes []string) ([]*net.IPNet, error) {
+	var parsed []*net.IPNet
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IPv6 prefix %q: %w", prefix, err)
+		}
+		if ipNet.IP.To4() != nil {
+			return nil, fmt.Errorf("invalid IPv6 prefix %q: not an IPv6 range", prefix)
+		}
+		parsed = append(parsed, ipNet)
+	}
+	return parsed, nil
+}
+
+// ContainerIPv6PrefixesFromEnv reads BASTION_CONTAINER_IPV6_PREFIXES, a
+// comma-separated list of IPv6 CIDRs, falling back to the defaults when it is
+// unset. "none" allows no IPv6 container addresses at all.
+func ContainerIPv6PrefixesFromEnv() ([]*net.IPNet, error) {
+	value := os.Getenv("BASTION_CONTAINER_IPV6_PREFIXES")
+	switch value {
+	case "":
+		return ParseIPv6Prefixes(DefaultContainerIPv6Prefixes)
+	case "none":
+		return nil, nil
+	}
+	return ParseIPv6Prefixes(strings.Split(value, ","))
+}
+
+// SetContainerIPv6Prefixes replaces the IPv6 ranges ValidateContainerIP
+// accepts. Global addresses are only accepted from a range listed here.
+func SetContainerIPv6Prefixes(prefixes []*net.IPNet) {
+	ipv6PrefixesMu.Lock()
+	defer ipv6PrefixesMu.Unlock()
+	ipv6Prefixes = prefixes
+}
+
+// ValidateContainerIP accepts private (RFC1918) IPv4 addresses and IPv6
+// unicast addresses within the allowed container prefixes. IPv4 addresses are
+// returned in their 4-byte form.
 func ValidateContainerIP(ipStr string) (net.IP, error) {
 	ip := net.ParseIP(ipStr)
 	if ip == nil {
@@ -49,22 +118,34 @@ func ValidateContainerIP(ipStr string) (net.IP, error) {
 		}
 	}
 
-	ip = ip.To4()
-	if ip == nil {
-		return nil, ValidationError{
-			Field:   "container_ip",
-			Message: "only IPv4 addresses supported",
+	if ip4 := ip.To4(); ip4 != nil {
+		if !isPrivateIP(ip4) {
+			return nil, ValidationError{
+				Field:   "container_ip",
+				Message: fmt.Sprintf("IP address is not private (RFC1918): %s", ipStr),
+			}
 		}
+		return ip4, nil
 	}
 
-	if !isPrivateIP(ip) {
+	if !ip.IsGlobalUnicast() {
 		return nil, ValidationError{
 			Field:   "container_ip",
-			Message: fmt.Sprintf("IP address is not private (RFC1918): %s", ipStr),
+			Message: fmt.Sprintf("IPv6 address is not a unicast address: %s", ipStr),
 		}
 	}
 
-	return ip, nil
+	ipv6PrefixesMu.RLock()
+	defer ipv6PrefixesMu.RUnlock()
+	for _, prefix := range ipv6Prefixes {
+		if prefix.Contains(ip) {
+			return ip, nil
+		}
+	}
+	return nil, ValidationError{
+		Field:   "container_ip",
+		Message: fmt.Sprintf("IPv6 address is outside the allowed container prefixes: %s", ipStr),
+	}
 }
 
 func isPrivateIP(ip net.IP) bool {
@@ -101,6 +182,16 @@ func ValidatePort(port uint32) error {
 	return nil
 }
 
+func ValidateProtocol(protocol string) error {
+	if protocol != "tcp" && protocol != "udp" {
+		return ValidationError{
+			Field:   "protocol",
+			Message: fmt.Sprintf("protocol must be 'tcp' or 'udp', got: %s", protocol),
+		}
+	}
+	return nil
+}
+
 func ValidateDNSServer(dnsIP string) (net.IP, error) {
 	ip := net.ParseIP(dnsIP)
 	if ip == nil {
@@ -137,6 +228,121 @@ func ValidateDNSServer(dnsIP string) (net.IP, error) {
 	return ip, nil
 }
 
+func ValidateMaxConnections(max uint32) error {
+	if max == 0 || max > 65535 {
+		return ValidationError{
+			Field:   "max_connections",
+			Message: fmt.Sprintf("invalid max connections: %d (must be 1-65535)", max),
+		}
+	}
+	return nil
+}
+
+// ValidateMTU bounds network MTUs to what IPv4 and jumbo-frame links support
+func ValidateMTU(mtu uint32) error {
+	if mtu < 576 || mtu > 9000 {
+		return ValidationError{
+			Field:   "mtu",
+			Message: fmt.Sprintf("invalid MTU: %d (must be 576-9000)", mtu),
+		}
+	}
+	return nil
+}
+
+func ValidateCaptureFilter(filter string) error {
+	if len(filter) > 256 {
+		return ValidationError{
+			Field:   "filter",
+			Message: fmt.Sprintf("capture filter too long (max 256 chars): %d", len(filter)),
+		}
+	}
+
+	if !captureFilterRegex.MatchString(filter) {
+		return ValidationError{
+			Field:   "filter",
+			Message: fmt.Sprintf("capture filter contains invalid characters: %s", filter),
+		}
+	}
+
+	// The filter is wrapped in parentheses after the container's host term, so
+	// an unbalanced one could close that group and match other traffic
+	depth := 0
+	for _, r := range filter {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 {
+		return ValidationError{
+			Field:   "filter",
+			Message: fmt.Sprintf("capture filter has unbalanced parentheses: %s", filter),
+		}
+	}
+
+	// Tokens starting with a dash could be parsed by tcpdump as options
+	for _, token := range strings.Fields(filter) {
+		if strings.HasPrefix(token, "-") {
+			return ValidationError{
+				Field:   "filter",
+				Message: fmt.Sprintf("capture filter token cannot start with '-': %s", token),
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateDomainPattern checks a DNS allowlist entry: either an exact name such as
+// "example.com" or a wildcard "*.example.com" matching only its subdomains.
+func ValidateDomainPattern(domain string) error {
+	name := strings.TrimPrefix(strings.ToLower(domain), "*.")
+
+	if name == "" || len(name) > 253 {
+		return ValidationError{
+			Field:   "dns_allowed_domains",
+			Message: fmt.Sprintf("invalid domain length: %q", domain),
+		}
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if !domainLabelRegex.MatchString(label) {
+			return ValidationError{
+				Field:   "dns_allowed_domains",
+				Message: fmt.Sprintf("invalid domain pattern (wildcards are only allowed as a leading '*.'): %s", domain),
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidatePodGroup checks the ID shared by containers that may reach each other
+func ValidatePodGroup(group string) error {
+	if !podGroupRegex.MatchString(group) {
+		return ValidationError{
+			Field:   "pod_group",
+			Message: fmt.Sprintf("pod group must be 1-64 alphanumeric, '_', '.' or '-' characters, got: %q", group),
+		}
+	}
+	return nil
+}
+
+func ValidatePartitionKey(key string) error {
+	if !partitionKeyRegex.MatchString(key) {
+		return ValidationError{
+			Field:   "partition_key",
+			Message: fmt.Sprintf("partition key must be 1-64 alphanumeric, '_', '.' or '-' characters, got: %q", key),
+		}
+	}
+	return nil
+}
+
 func ValidatePolicyMode(policy string) error {
 	if policy != "allow" && policy != "deny" {
 		return ValidationError{
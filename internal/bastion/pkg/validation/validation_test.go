@@ -3,6 +3,7 @@ package validation
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"strings"
 	"testing"
 )
 
@@ -44,7 +45,12 @@ func TestValidateContainerIP(t *testing.T) {
 		{"public IP", "8.8.8.8", true},
 		{"public IP 2", "1.1.1.1", true},
 		{"not an IP", "not-an-ip", true},
-		{"IPv6", "::1", true},
+		{"IPv6 loopback", "::1", true},
+		{"IPv6 unique local", "fd00:abcd::2", false},
+		{"IPv6 link-local", "fe80::1", true},
+		{"IPv6 multicast", "ff02::1", true},
+		{"IPv6 global outside prefixes", "2001:db8::2", true},
+		{"IPv4-mapped IPv6", "::ffff:10.0.0.1", false},
 		{"172.15.x out of range", "172.15.0.1", true},
 		{"172.32.x out of range", "172.32.0.1", true},
 	}
@@ -62,6 +68,44 @@ func TestValidateContainerIP(t *testing.T) {
 	}
 }
 
+func TestContainerIPv6Prefixes(t *testing.T) {
+	t.Cleanup(func() {
+		SetContainerIPv6Prefixes(mustParsePrefixes(DefaultContainerIPv6Prefixes))
+	})
+
+	t.Setenv("BASTION_CONTAINER_IPV6_PREFIXES", "2001:db8:1::/48, fd12::/16")
+	prefixes, err := ContainerIPv6PrefixesFromEnv()
+	if err != nil {
+		t.Fatalf("ContainerIPv6PrefixesFromEnv() error = %v", err)
+	}
+	SetContainerIPv6Prefixes(prefixes)
+
+	if _, err := ValidateContainerIP("2001:db8:1::5"); err != nil {
+		t.Errorf("address in an allowed global prefix rejected: %v", err)
+	}
+	if _, err := ValidateContainerIP("2001:db8:2::5"); err == nil {
+		t.Error("address outside the allowed prefixes accepted")
+	}
+	if _, err := ValidateContainerIP("fd00::5"); err == nil {
+		t.Error("unique local address outside the configured prefixes accepted")
+	}
+	if _, err := ValidateContainerIP("10.0.0.1"); err != nil {
+		t.Errorf("IPv4 validation changed by the IPv6 prefixes: %v", err)
+	}
+
+	t.Setenv("BASTION_CONTAINER_IPV6_PREFIXES", "none")
+	if prefixes, err := ContainerIPv6PrefixesFromEnv(); err != nil || len(prefixes) != 0 {
+		t.Errorf("ContainerIPv6PrefixesFromEnv() with none = %v, %v", prefixes, err)
+	}
+
+	for _, invalid := range []string{"10.0.0.0/8", "fd00::", "not-a-prefix"} {
+		t.Setenv("BASTION_CONTAINER_IPV6_PREFIXES", invalid)
+		if _, err := ContainerIPv6PrefixesFromEnv(); err == nil {
+			t.Errorf("ContainerIPv6PrefixesFromEnv() accepted %q", invalid)
+		}
+	}
+}
+
 func TestValidateCIDR(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -114,6 +158,29 @@ func TestValidatePort(t *testing.T) {
 	}
 }
 
+func TestValidateProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		wantErr  bool
+	}{
+		{"tcp", "tcp", false},
+		{"udp", "udp", false},
+		{"uppercase", "TCP", true},
+		{"icmp", "icmp", true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProtocol(tt.protocol)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProtocol() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateDNSServer(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -143,6 +210,39 @@ func TestValidateDNSServer(t *testing.T) {
 	}
 }
 
+func TestValidateDomainPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		domain  string
+		wantErr bool
+	}{
+		{"exact", "example.com", false},
+		{"wildcard", "*.example.com", false},
+		{"uppercase", "API.Example.com", false},
+		{"single label", "localhost", false},
+		{"empty", "", true},
+		{"bare wildcard", "*", true},
+		{"wildcard only prefix", "*.", true},
+		{"inner wildcard", "api.*.example.com", true},
+		{"trailing wildcard", "example.*", true},
+		{"empty label", "example..com", true},
+		{"trailing dot", "example.com.", true},
+		{"leading hyphen", "-example.com", true},
+		{"label too long", strings.Repeat("a", 64) + ".com", true},
+		{"name too long", strings.Repeat("a.", 127) + "com", true},
+		{"invalid chars", "exa mple.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDomainPattern(tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDomainPattern(%q) error = %v, wantErr %v", tt.domain, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidatePolicyMode(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -166,6 +266,132 @@ func TestValidatePolicyMode(t *testing.T) {
 	}
 }
 
+func TestValidatePodGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   string
+		wantErr bool
+	}{
+		{"simple", "web", false},
+		{"punctuated", "tenant-1.job_2", false},
+		{"max length", strings.Repeat("a", 64), false},
+		{"empty", "", true},
+		{"too long", strings.Repeat("a", 65), true},
+		{"leading dash", "-web", true},
+		{"whitespace", "web tier", true},
+		{"shell metacharacters", "web;rm", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePodGroup(tt.group)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePodGroup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePartitionKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"simple", "tenant-a", false},
+		{"max length", strings.Repeat("a", 64), false},
+		{"empty", "", true},
+		{"too long", strings.Repeat("a", 65), true},
+		{"separator", "tenant=a", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePartitionKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePartitionKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMaxConnections(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     uint32
+		wantErr bool
+	}{
+		{"one", 1, false},
+		{"typical", 256, false},
+		{"upper bound", 65535, false},
+		{"zero", 0, true},
+		{"too large", 65536, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMaxConnections(tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMaxConnections() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMTU(t *testing.T) {
+	tests := []struct {
+		name    string
+		mtu     uint32
+		wantErr bool
+	}{
+		{"ethernet", 1500, false},
+		{"wireguard", 1420, false},
+		{"lower bound", 576, false},
+		{"jumbo", 9000, false},
+		{"zero", 0, true},
+		{"too small", 575, true},
+		{"too large", 9001, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMTU(tt.mtu)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMTU() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCaptureFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"port", "tcp port 443", false},
+		{"compound", "(port 53 or port 443) and not icmp", false},
+		{"cidr", "net 10.0.0.0/8", false},
+		{"option injection", "port 80 -w /tmp/out", true},
+		{"shell characters", "port 80; rm -rf /", true},
+		{"quotes", "host 'example'", true},
+		{"escaping group", "tcp ) or ( host 10.0.0.5", true},
+		{"unclosed group", "(tcp port 443", true},
+		{"unopened group", "tcp port 443)", true},
+		{"too long", strings.Repeat("a", 257), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCaptureFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCaptureFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateNetworkName(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -134,10 +134,12 @@ func (x *SetupChainResponse) GetError() string {
 }
 
 type ApplyRulesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
-	Policy        *NetworkPolicy         `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
-	ContainerId   string                 `protobuf:"bytes,3,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ChainName   string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Policy      *NetworkPolicy         `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	ContainerId string                 `protobuf:"bytes,3,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Validate the policy and return the commands that would run, changing nothing
+	DryRun        bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -193,11 +195,20 @@ func (x *ApplyRulesRequest) GetContainerId() string {
 	return ""
 }
 
+func (x *ApplyRulesRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
 type ApplyRulesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
-	RulesApplied  int32                  `protobuf:"varint,3,opt,name=rules_applied,json=rulesApplied,proto3" json:"rules_applied,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Success      bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error        *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	RulesApplied int32                  `protobuf:"varint,3,opt,name=rules_applied,json=rulesApplied,proto3" json:"rules_applied,omitempty"`
+	// The commands a dry run would have executed, in order
+	Commands      []string `protobuf:"bytes,4,rep,name=commands,proto3" json:"commands,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -253,28 +264,36 @@ func (x *ApplyRulesResponse) GetRulesApplied() int32 {
 	return 0
 }
 
-type CleanupChainRequest struct {
+func (x *ApplyRulesResponse) GetCommands() []string {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+type UpdateNetworkPolicyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
-	ContainerId   string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Policy        *NetworkPolicy         `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,3,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CleanupChainRequest) Reset() {
-	*x = CleanupChainRequest{}
+func (x *UpdateNetworkPolicyRequest) Reset() {
+	*x = UpdateNetworkPolicyRequest{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CleanupChainRequest) String() string {
+func (x *UpdateNetworkPolicyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CleanupChainRequest) ProtoMessage() {}
+func (*UpdateNetworkPolicyRequest) ProtoMessage() {}
 
-func (x *CleanupChainRequest) ProtoReflect() protoreflect.Message {
+func (x *UpdateNetworkPolicyRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -286,47 +305,55 @@ func (x *CleanupChainRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CleanupChainRequest.ProtoReflect.Descriptor instead.
-func (*CleanupChainRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateNetworkPolicyRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNetworkPolicyRequest) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *CleanupChainRequest) GetChainName() string {
+func (x *UpdateNetworkPolicyRequest) GetChainName() string {
 	if x != nil {
 		return x.ChainName
 	}
 	return ""
 }
 
-func (x *CleanupChainRequest) GetContainerId() string {
+func (x *UpdateNetworkPolicyRequest) GetPolicy() *NetworkPolicy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+func (x *UpdateNetworkPolicyRequest) GetContainerId() string {
 	if x != nil {
 		return x.ContainerId
 	}
 	return ""
 }
 
-type CleanupChainResponse struct {
+type UpdateNetworkPolicyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	RulesApplied  int32                  `protobuf:"varint,3,opt,name=rules_applied,json=rulesApplied,proto3" json:"rules_applied,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CleanupChainResponse) Reset() {
-	*x = CleanupChainResponse{}
+func (x *UpdateNetworkPolicyResponse) Reset() {
+	*x = UpdateNetworkPolicyResponse{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CleanupChainResponse) String() string {
+func (x *UpdateNetworkPolicyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CleanupChainResponse) ProtoMessage() {}
+func (*UpdateNetworkPolicyResponse) ProtoMessage() {}
 
-func (x *CleanupChainResponse) ProtoReflect() protoreflect.Message {
+func (x *UpdateNetworkPolicyResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -338,45 +365,55 @@ func (x *CleanupChainResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CleanupChainResponse.ProtoReflect.Descriptor instead.
-func (*CleanupChainResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateNetworkPolicyResponse.ProtoReflect.Descriptor instead.
+func (*UpdateNetworkPolicyResponse) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *CleanupChainResponse) GetSuccess() bool {
+func (x *UpdateNetworkPolicyResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *CleanupChainResponse) GetError() string {
+func (x *UpdateNetworkPolicyResponse) GetError() string {
 	if x != nil && x.Error != nil {
 		return *x.Error
 	}
 	return ""
 }
 
-type HealthRequest struct {
+func (x *UpdateNetworkPolicyResponse) GetRulesApplied() int32 {
+	if x != nil {
+		return x.RulesApplied
+	}
+	return 0
+}
+
+type UpdateRulesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Policy        *NetworkPolicy         `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,3,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthRequest) Reset() {
-	*x = HealthRequest{}
+func (x *UpdateRulesRequest) Reset() {
+	*x = UpdateRulesRequest{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthRequest) String() string {
+func (x *UpdateRulesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthRequest) ProtoMessage() {}
+func (*UpdateRulesRequest) ProtoMessage() {}
 
-func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+func (x *UpdateRulesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -388,34 +425,59 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
-func (*HealthRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateRulesRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRulesRequest) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{6}
 }
 
-type HealthResponse struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	Healthy           bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
-	Version           string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	IptablesAvailable bool                   `protobuf:"varint,3,opt,name=iptables_available,json=iptablesAvailable,proto3" json:"iptables_available,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+func (x *UpdateRulesRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
 }
 
-func (x *HealthResponse) Reset() {
-	*x = HealthResponse{}
+func (x *UpdateRulesRequest) GetPolicy() *NetworkPolicy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+func (x *UpdateRulesRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+type UpdateRulesResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Success      bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error        *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	RulesApplied int32                  `protobuf:"varint,3,opt,name=rules_applied,json=rulesApplied,proto3" json:"rules_applied,omitempty"`
+	RulesAdded   int32                  `protobuf:"varint,4,opt,name=rules_added,json=rulesAdded,proto3" json:"rules_added,omitempty"`
+	RulesRemoved int32                  `protobuf:"varint,5,opt,name=rules_removed,json=rulesRemoved,proto3" json:"rules_removed,omitempty"`
+	// Set when the chain could not be diffed and was rebuilt in full instead
+	Replaced      bool `protobuf:"varint,6,opt,name=replaced,proto3" json:"replaced,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRulesResponse) Reset() {
+	*x = UpdateRulesResponse{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthResponse) String() string {
+func (x *UpdateRulesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthResponse) ProtoMessage() {}
+func (*UpdateRulesResponse) ProtoMessage() {}
 
-func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+func (x *UpdateRulesResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -427,64 +489,75 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
-func (*HealthResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateRulesResponse.ProtoReflect.Descriptor instead.
+func (*UpdateRulesResponse) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *HealthResponse) GetHealthy() bool {
+func (x *UpdateRulesResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Healthy
+		return x.Success
 	}
 	return false
 }
 
-func (x *HealthResponse) GetVersion() string {
-	if x != nil {
-		return x.Version
+func (x *UpdateRulesResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
 	return ""
 }
 
-func (x *HealthResponse) GetIptablesAvailable() bool {
+func (x *UpdateRulesResponse) GetRulesApplied() int32 {
 	if x != nil {
-		return x.IptablesAvailable
+		return x.RulesApplied
+	}
+	return 0
+}
+
+func (x *UpdateRulesResponse) GetRulesAdded() int32 {
+	if x != nil {
+		return x.RulesAdded
+	}
+	return 0
+}
+
+func (x *UpdateRulesResponse) GetRulesRemoved() int32 {
+	if x != nil {
+		return x.RulesRemoved
+	}
+	return 0
+}
+
+func (x *UpdateRulesResponse) GetReplaced() bool {
+	if x != nil {
+		return x.Replaced
 	}
 	return false
 }
 
-type NetworkPolicy struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Policy mode: "allow" (allowlist) or "deny" (denylist)
-	Policy string `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
-	// Block access to metadata services (169.254.169.254)
-	BlockMetadata bool `protobuf:"varint,2,opt,name=block_metadata,json=blockMetadata,proto3" json:"block_metadata,omitempty"`
-	// Allow DNS queries
-	AllowDns bool `protobuf:"varint,3,opt,name=allow_dns,json=allowDns,proto3" json:"allow_dns,omitempty"`
-	// Custom DNS servers (validated IPs)
-	DnsServers []string `protobuf:"bytes,4,rep,name=dns_servers,json=dnsServers,proto3" json:"dns_servers,omitempty"`
-	// Whitelist rules (when policy = "deny")
-	Whitelist []*NetworkRule `protobuf:"bytes,5,rep,name=whitelist,proto3" json:"whitelist,omitempty"`
-	// Blacklist rules (when policy = "allow")
-	Blacklist     []*NetworkRule `protobuf:"bytes,6,rep,name=blacklist,proto3" json:"blacklist,omitempty"`
+type CleanupChainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkPolicy) Reset() {
-	*x = NetworkPolicy{}
+func (x *CleanupChainRequest) Reset() {
+	*x = CleanupChainRequest{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkPolicy) String() string {
+func (x *CleanupChainRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkPolicy) ProtoMessage() {}
+func (*CleanupChainRequest) ProtoMessage() {}
 
-func (x *NetworkPolicy) ProtoReflect() protoreflect.Message {
+func (x *CleanupChainRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -496,76 +569,47 @@ func (x *NetworkPolicy) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkPolicy.ProtoReflect.Descriptor instead.
-func (*NetworkPolicy) Descriptor() ([]byte, []int) {
+// Deprecated: Use CleanupChainRequest.ProtoReflect.Descriptor instead.
+func (*CleanupChainRequest) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *NetworkPolicy) GetPolicy() string {
+func (x *CleanupChainRequest) GetChainName() string {
 	if x != nil {
-		return x.Policy
+		return x.ChainName
 	}
 	return ""
 }
 
-func (x *NetworkPolicy) GetBlockMetadata() bool {
-	if x != nil {
-		return x.BlockMetadata
-	}
-	return false
-}
-
-func (x *NetworkPolicy) GetAllowDns() bool {
-	if x != nil {
-		return x.AllowDns
-	}
-	return false
-}
-
-func (x *NetworkPolicy) GetDnsServers() []string {
-	if x != nil {
-		return x.DnsServers
-	}
-	return nil
-}
-
-func (x *NetworkPolicy) GetWhitelist() []*NetworkRule {
-	if x != nil {
-		return x.Whitelist
-	}
-	return nil
-}
-
-func (x *NetworkPolicy) GetBlacklist() []*NetworkRule {
+func (x *CleanupChainRequest) GetContainerId() string {
 	if x != nil {
-		return x.Blacklist
+		return x.ContainerId
 	}
-	return nil
+	return ""
 }
 
-type NetworkRule struct {
+type CleanupChainResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Cidr          string                 `protobuf:"bytes,1,opt,name=cidr,proto3" json:"cidr,omitempty"`
-	Description   *string                `protobuf:"bytes,2,opt,name=description,proto3,oneof" json:"description,omitempty"`
-	Ports         []uint32               `protobuf:"varint,3,rep,packed,name=ports,proto3" json:"ports,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkRule) Reset() {
-	*x = NetworkRule{}
+func (x *CleanupChainResponse) Reset() {
+	*x = CleanupChainResponse{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkRule) String() string {
+func (x *CleanupChainResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkRule) ProtoMessage() {}
+func (*CleanupChainResponse) ProtoMessage() {}
 
-func (x *NetworkRule) ProtoReflect() protoreflect.Message {
+func (x *CleanupChainResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -577,60 +621,50 @@ func (x *NetworkRule) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkRule.ProtoReflect.Descriptor instead.
-func (*NetworkRule) Descriptor() ([]byte, []int) {
+// Deprecated: Use CleanupChainResponse.ProtoReflect.Descriptor instead.
+func (*CleanupChainResponse) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *NetworkRule) GetCidr() string {
+func (x *CleanupChainResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Cidr
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *NetworkRule) GetDescription() string {
-	if x != nil && x.Description != nil {
-		return *x.Description
+func (x *CleanupChainResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
 	return ""
 }
 
-func (x *NetworkRule) GetPorts() []uint32 {
-	if x != nil {
-		return x.Ports
-	}
-	return nil
-}
-
-type NetworkConfig struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Subnet requirements (e.g., "172.20.0.0/16" or empty for any)
-	SubnetRange *string `protobuf:"bytes,1,opt,name=subnet_range,json=subnetRange,proto3,oneof" json:"subnet_range,omitempty"`
-	// Minimum number of available IPs needed
-	MinIps *uint32 `protobuf:"varint,2,opt,name=min_ips,json=minIps,proto3,oneof" json:"min_ips,omitempty"`
-	// Network driver (default: "bridge")
-	Driver *string `protobuf:"bytes,3,opt,name=driver,proto3,oneof" json:"driver,omitempty"`
-	// Config hash for matching similar configs
-	ConfigHash    string `protobuf:"bytes,4,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+type PortMapping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerPort uint32                 `protobuf:"varint,1,opt,name=container_port,json=containerPort,proto3" json:"container_port,omitempty"`
+	// Host port to publish on; 0 picks a free port from the bastion's range
+	HostPort uint32 `protobuf:"varint,2,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"`
+	// "tcp" (default) or "udp"
+	Protocol      string `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkConfig) Reset() {
-	*x = NetworkConfig{}
+func (x *PortMapping) Reset() {
+	*x = PortMapping{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkConfig) String() string {
+func (x *PortMapping) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkConfig) ProtoMessage() {}
+func (*PortMapping) ProtoMessage() {}
 
-func (x *NetworkConfig) ProtoReflect() protoreflect.Message {
+func (x *PortMapping) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -642,63 +676,55 @@ func (x *NetworkConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkConfig.ProtoReflect.Descriptor instead.
-func (*NetworkConfig) Descriptor() ([]byte, []int) {
+// Deprecated: Use PortMapping.ProtoReflect.Descriptor instead.
+func (*PortMapping) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *NetworkConfig) GetSubnetRange() string {
-	if x != nil && x.SubnetRange != nil {
-		return *x.SubnetRange
+func (x *PortMapping) GetContainerPort() uint32 {
+	if x != nil {
+		return x.ContainerPort
 	}
-	return ""
+	return 0
 }
 
-func (x *NetworkConfig) GetMinIps() uint32 {
-	if x != nil && x.MinIps != nil {
-		return *x.MinIps
+func (x *PortMapping) GetHostPort() uint32 {
+	if x != nil {
+		return x.HostPort
 	}
 	return 0
 }
 
-func (x *NetworkConfig) GetDriver() string {
-	if x != nil && x.Driver != nil {
-		return *x.Driver
-	}
-	return ""
-}
-
-func (x *NetworkConfig) GetConfigHash() string {
+func (x *PortMapping) GetProtocol() string {
 	if x != nil {
-		return x.ConfigHash
+		return x.Protocol
 	}
 	return ""
 }
 
-type AcquireNetworkRequest struct {
+type ExposePortsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	NetworkConfig *NetworkConfig         `protobuf:"bytes,2,opt,name=network_config,json=networkConfig,proto3" json:"network_config,omitempty"`
-	// How long to hold the network (seconds, default: 3600)
-	LeaseDurationSecs *uint32 `protobuf:"varint,3,opt,name=lease_duration_secs,json=leaseDurationSecs,proto3,oneof" json:"lease_duration_secs,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Ports         []*PortMapping         `protobuf:"bytes,3,rep,name=ports,proto3" json:"ports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AcquireNetworkRequest) Reset() {
-	*x = AcquireNetworkRequest{}
+func (x *ExposePortsRequest) Reset() {
+	*x = ExposePortsRequest{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AcquireNetworkRequest) String() string {
+func (x *ExposePortsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AcquireNetworkRequest) ProtoMessage() {}
+func (*ExposePortsRequest) ProtoMessage() {}
 
-func (x *AcquireNetworkRequest) ProtoReflect() protoreflect.Message {
+func (x *ExposePortsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -710,60 +736,56 @@ func (x *AcquireNetworkRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AcquireNetworkRequest.ProtoReflect.Descriptor instead.
-func (*AcquireNetworkRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExposePortsRequest.ProtoReflect.Descriptor instead.
+func (*ExposePortsRequest) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *AcquireNetworkRequest) GetContainerId() string {
+func (x *ExposePortsRequest) GetChainName() string {
 	if x != nil {
-		return x.ContainerId
+		return x.ChainName
 	}
 	return ""
 }
 
-func (x *AcquireNetworkRequest) GetNetworkConfig() *NetworkConfig {
+func (x *ExposePortsRequest) GetContainerId() string {
 	if x != nil {
-		return x.NetworkConfig
+		return x.ContainerId
 	}
-	return nil
+	return ""
 }
 
-func (x *AcquireNetworkRequest) GetLeaseDurationSecs() uint32 {
-	if x != nil && x.LeaseDurationSecs != nil {
-		return *x.LeaseDurationSecs
+func (x *ExposePortsRequest) GetPorts() []*PortMapping {
+	if x != nil {
+		return x.Ports
 	}
-	return 0
+	return nil
 }
 
-type AcquireNetworkResponse struct {
+type ExposePortsResponse struct {
 	state   protoimpl.MessageState `protogen:"open.v1"`
 	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
-	// Network details if successful
-	NetworkName *string `protobuf:"bytes,3,opt,name=network_name,json=networkName,proto3,oneof" json:"network_name,omitempty"`
-	NetworkId   *string `protobuf:"bytes,4,opt,name=network_id,json=networkId,proto3,oneof" json:"network_id,omitempty"`
-	Subnet      *string `protobuf:"bytes,5,opt,name=subnet,proto3,oneof" json:"subnet,omitempty"`
-	// Whether this is a reused network (vs newly created)
-	Reused        bool `protobuf:"varint,6,opt,name=reused,proto3" json:"reused,omitempty"`
+	// Mappings as published, with host ports filled in
+	Ports         []*PortMapping `protobuf:"bytes,3,rep,name=ports,proto3" json:"ports,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AcquireNetworkResponse) Reset() {
-	*x = AcquireNetworkResponse{}
+func (x *ExposePortsResponse) Reset() {
+	*x = ExposePortsResponse{}
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AcquireNetworkResponse) String() string {
+func (x *ExposePortsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AcquireNetworkResponse) ProtoMessage() {}
+func (*ExposePortsResponse) ProtoMessage() {}
 
-func (x *AcquireNetworkResponse) ProtoReflect() protoreflect.Message {
+func (x *ExposePortsResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -775,78 +797,2307 @@ func (x *AcquireNetworkResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AcquireNetworkResponse.ProtoReflect.Descriptor instead.
-func (*AcquireNetworkResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExposePortsResponse.ProtoReflect.Descriptor instead.
+func (*ExposePortsResponse) Descriptor() ([]byte, []int) {
 	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *AcquireNetworkResponse) GetSuccess() bool {
+func (x *ExposePortsResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *AcquireNetworkResponse) GetError() string {
+func (x *ExposePortsResponse) GetError() string {
 	if x != nil && x.Error != nil {
 		return *x.Error
 	}
 	return ""
 }
 
-func (x *AcquireNetworkResponse) GetNetworkName() string {
-	if x != nil && x.NetworkName != nil {
-		return *x.NetworkName
+func (x *ExposePortsResponse) GetPorts() []*PortMapping {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+type SetupPortForwardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Mapping       *PortMapping           `protobuf:"bytes,3,opt,name=mapping,proto3" json:"mapping,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetupPortForwardRequest) Reset() {
+	*x = SetupPortForwardRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetupPortForwardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetupPortForwardRequest) ProtoMessage() {}
+
+func (x *SetupPortForwardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetupPortForwardRequest.ProtoReflect.Descriptor instead.
+func (*SetupPortForwardRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SetupPortForwardRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *SetupPortForwardRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *SetupPortForwardRequest) GetMapping() *PortMapping {
+	if x != nil {
+		return x.Mapping
+	}
+	return nil
+}
+
+type SetupPortForwardResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// The mapping as published, with the host port filled in
+	Mapping       *PortMapping `protobuf:"bytes,3,opt,name=mapping,proto3" json:"mapping,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetupPortForwardResponse) Reset() {
+	*x = SetupPortForwardResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetupPortForwardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetupPortForwardResponse) ProtoMessage() {}
+
+func (x *SetupPortForwardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetupPortForwardResponse.ProtoReflect.Descriptor instead.
+func (*SetupPortForwardResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SetupPortForwardResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetupPortForwardResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *SetupPortForwardResponse) GetMapping() *PortMapping {
+	if x != nil {
+		return x.Mapping
+	}
+	return nil
+}
+
+type TeardownPortForwardRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ChainName   string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	ContainerId string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	HostPort    uint32                 `protobuf:"varint,3,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"`
+	// "tcp" (default) or "udp"
+	Protocol      string `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TeardownPortForwardRequest) Reset() {
+	*x = TeardownPortForwardRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TeardownPortForwardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeardownPortForwardRequest) ProtoMessage() {}
+
+func (x *TeardownPortForwardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeardownPortForwardRequest.ProtoReflect.Descriptor instead.
+func (*TeardownPortForwardRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TeardownPortForwardRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *TeardownPortForwardRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *TeardownPortForwardRequest) GetHostPort() uint32 {
+	if x != nil {
+		return x.HostPort
+	}
+	return 0
+}
+
+func (x *TeardownPortForwardRequest) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+type TeardownPortForwardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TeardownPortForwardResponse) Reset() {
+	*x = TeardownPortForwardResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TeardownPortForwardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeardownPortForwardResponse) ProtoMessage() {}
+
+func (x *TeardownPortForwardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeardownPortForwardResponse.ProtoReflect.Descriptor instead.
+func (*TeardownPortForwardResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TeardownPortForwardResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TeardownPortForwardResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{17}
+}
+
+type HealthResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Healthy           bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Version           string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	IptablesAvailable bool                   `protobuf:"varint,3,opt,name=iptables_available,json=iptablesAvailable,proto3" json:"iptables_available,omitempty"`
+	// IP of the DNS filter containers should resolve through, if enabled
+	DnsFilterAddress *string `protobuf:"bytes,4,opt,name=dns_filter_address,json=dnsFilterAddress,proto3,oneof" json:"dns_filter_address,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *HealthResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HealthResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetIptablesAvailable() bool {
+	if x != nil {
+		return x.IptablesAvailable
+	}
+	return false
+}
+
+func (x *HealthResponse) GetDnsFilterAddress() string {
+	if x != nil && x.DnsFilterAddress != nil {
+		return *x.DnsFilterAddress
+	}
+	return ""
+}
+
+type StreamFlowLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFlowLogsRequest) Reset() {
+	*x = StreamFlowLogsRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFlowLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFlowLogsRequest) ProtoMessage() {}
+
+func (x *StreamFlowLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFlowLogsRequest.ProtoReflect.Descriptor instead.
+func (*StreamFlowLogsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StreamFlowLogsRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *StreamFlowLogsRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+type FlowRecord struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ChainName string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	// "allow" or "deny"
+	Verdict  string `protobuf:"bytes,2,opt,name=verdict,proto3" json:"verdict,omitempty"`
+	Protocol string `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Src      string `protobuf:"bytes,4,opt,name=src,proto3" json:"src,omitempty"`
+	Dst      string `protobuf:"bytes,5,opt,name=dst,proto3" json:"dst,omitempty"`
+	SrcPort  uint32 `protobuf:"varint,6,opt,name=src_port,json=srcPort,proto3" json:"src_port,omitempty"`
+	DstPort  uint32 `protobuf:"varint,7,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
+	// Unix timestamp in milliseconds
+	Timestamp     int64 `protobuf:"varint,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlowRecord) Reset() {
+	*x = FlowRecord{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowRecord) ProtoMessage() {}
+
+func (x *FlowRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowRecord.ProtoReflect.Descriptor instead.
+func (*FlowRecord) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FlowRecord) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetVerdict() string {
+	if x != nil {
+		return x.Verdict
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetSrc() string {
+	if x != nil {
+		return x.Src
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetDst() string {
+	if x != nil {
+		return x.Dst
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetSrcPort() uint32 {
+	if x != nil {
+		return x.SrcPort
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetDstPort() uint32 {
+	if x != nil {
+		return x.DstPort
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type CapturePacketsRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ChainName   string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	ContainerId string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Additional tcpdump filter expression, ANDed with the container's host filter
+	Filter *string `protobuf:"bytes,3,opt,name=filter,proto3,oneof" json:"filter,omitempty"`
+	// Stop after this many packets (default 10000)
+	MaxPackets *uint32 `protobuf:"varint,4,opt,name=max_packets,json=maxPackets,proto3,oneof" json:"max_packets,omitempty"`
+	// Stop after this many seconds (default 60, max 600)
+	MaxDurationSecs *uint32 `protobuf:"varint,5,opt,name=max_duration_secs,json=maxDurationSecs,proto3,oneof" json:"max_duration_secs,omitempty"`
+	// Bytes captured per packet (default 262144)
+	Snaplen       *uint32 `protobuf:"varint,6,opt,name=snaplen,proto3,oneof" json:"snaplen,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CapturePacketsRequest) Reset() {
+	*x = CapturePacketsRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapturePacketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapturePacketsRequest) ProtoMessage() {}
+
+func (x *CapturePacketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapturePacketsRequest.ProtoReflect.Descriptor instead.
+func (*CapturePacketsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CapturePacketsRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *CapturePacketsRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *CapturePacketsRequest) GetFilter() string {
+	if x != nil && x.Filter != nil {
+		return *x.Filter
+	}
+	return ""
+}
+
+func (x *CapturePacketsRequest) GetMaxPackets() uint32 {
+	if x != nil && x.MaxPackets != nil {
+		return *x.MaxPackets
+	}
+	return 0
+}
+
+func (x *CapturePacketsRequest) GetMaxDurationSecs() uint32 {
+	if x != nil && x.MaxDurationSecs != nil {
+		return *x.MaxDurationSecs
+	}
+	return 0
+}
+
+func (x *CapturePacketsRequest) GetSnaplen() uint32 {
+	if x != nil && x.Snaplen != nil {
+		return *x.Snaplen
+	}
+	return 0
+}
+
+type CaptureChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raw pcap stream bytes; the first chunk begins with the pcap file header
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureChunk) Reset() {
+	*x = CaptureChunk{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureChunk) ProtoMessage() {}
+
+func (x *CaptureChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureChunk.ProtoReflect.Descriptor instead.
+func (*CaptureChunk) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CaptureChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type NetworkPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Policy mode: "allow" (allowlist) or "deny" (denylist)
+	Policy string `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	// Block access to metadata services (169.254.169.254)
+	BlockMetadata bool `protobuf:"varint,2,opt,name=block_metadata,json=blockMetadata,proto3" json:"block_metadata,omitempty"`
+	// Allow DNS queries
+	AllowDns bool `protobuf:"varint,3,opt,name=allow_dns,json=allowDns,proto3" json:"allow_dns,omitempty"`
+	// Custom DNS servers (validated IPs)
+	DnsServers []string `protobuf:"bytes,4,rep,name=dns_servers,json=dnsServers,proto3" json:"dns_servers,omitempty"`
+	// Whitelist rules (when policy = "deny")
+	Whitelist []*NetworkRule `protobuf:"bytes,5,rep,name=whitelist,proto3" json:"whitelist,omitempty"`
+	// Blacklist rules (when policy = "allow")
+	Blacklist []*NetworkRule `protobuf:"bytes,6,rep,name=blacklist,proto3" json:"blacklist,omitempty"`
+	// Maximum concurrent outbound TCP connections (unset = unlimited)
+	MaxConnections *uint32 `protobuf:"varint,7,opt,name=max_connections,json=maxConnections,proto3,oneof" json:"max_connections,omitempty"`
+	// Log new connections with their verdict for StreamFlowLogs
+	LogAttempts bool `protobuf:"varint,8,opt,name=log_attempts,json=logAttempts,proto3" json:"log_attempts,omitempty"`
+	// Domains the container may resolve ("example.com" or "*.example.com").
+	// When set with allow_dns, DNS is only answered by the bastion's filter.
+	DnsAllowedDomains []string `protobuf:"bytes,9,rep,name=dns_allowed_domains,json=dnsAllowedDomains,proto3" json:"dns_allowed_domains,omitempty"`
+	// Containers sharing a pod group may reach each other on the pooled
+	// network; all other cross-container traffic stays blocked. Empty = none.
+	PodGroup      string `protobuf:"bytes,10,opt,name=pod_group,json=podGroup,proto3" json:"pod_group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkPolicy) Reset() {
+	*x = NetworkPolicy{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkPolicy) ProtoMessage() {}
+
+func (x *NetworkPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkPolicy.ProtoReflect.Descriptor instead.
+func (*NetworkPolicy) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *NetworkPolicy) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *NetworkPolicy) GetBlockMetadata() bool {
+	if x != nil {
+		return x.BlockMetadata
+	}
+	return false
+}
+
+func (x *NetworkPolicy) GetAllowDns() bool {
+	if x != nil {
+		return x.AllowDns
+	}
+	return false
+}
+
+func (x *NetworkPolicy) GetDnsServers() []string {
+	if x != nil {
+		return x.DnsServers
+	}
+	return nil
+}
+
+func (x *NetworkPolicy) GetWhitelist() []*NetworkRule {
+	if x != nil {
+		return x.Whitelist
+	}
+	return nil
+}
+
+func (x *NetworkPolicy) GetBlacklist() []*NetworkRule {
+	if x != nil {
+		return x.Blacklist
+	}
+	return nil
+}
+
+func (x *NetworkPolicy) GetMaxConnections() uint32 {
+	if x != nil && x.MaxConnections != nil {
+		return *x.MaxConnections
+	}
+	return 0
+}
+
+func (x *NetworkPolicy) GetLogAttempts() bool {
+	if x != nil {
+		return x.LogAttempts
+	}
+	return false
+}
+
+func (x *NetworkPolicy) GetDnsAllowedDomains() []string {
+	if x != nil {
+		return x.DnsAllowedDomains
+	}
+	return nil
+}
+
+func (x *NetworkPolicy) GetPodGroup() string {
+	if x != nil {
+		return x.PodGroup
+	}
+	return ""
+}
+
+type NetworkRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cidr          string                 `protobuf:"bytes,1,opt,name=cidr,proto3" json:"cidr,omitempty"`
+	Description   *string                `protobuf:"bytes,2,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Ports         []uint32               `protobuf:"varint,3,rep,packed,name=ports,proto3" json:"ports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkRule) Reset() {
+	*x = NetworkRule{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkRule) ProtoMessage() {}
+
+func (x *NetworkRule) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkRule.ProtoReflect.Descriptor instead.
+func (*NetworkRule) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *NetworkRule) GetCidr() string {
+	if x != nil {
+		return x.Cidr
+	}
+	return ""
+}
+
+func (x *NetworkRule) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *NetworkRule) GetPorts() []uint32 {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+type NetworkConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Subnet requirements (e.g., "172.20.0.0/16" or empty for any)
+	SubnetRange *string `protobuf:"bytes,1,opt,name=subnet_range,json=subnetRange,proto3,oneof" json:"subnet_range,omitempty"`
+	// Minimum number of available IPs needed
+	MinIps *uint32 `protobuf:"varint,2,opt,name=min_ips,json=minIps,proto3,oneof" json:"min_ips,omitempty"`
+	// Network driver: "bridge" (default), "overlay" (swarm mode, subnet from
+	// the pool's range), or "macvlan" / "ipvlan" (need subnet_range and parent)
+	Driver *string `protobuf:"bytes,3,opt,name=driver,proto3,oneof" json:"driver,omitempty"`
+	// Config hash for matching similar configs
+	ConfigHash string `protobuf:"bytes,4,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+	// Bridge or overlay MTU (576-9000); unset uses the bastion's
+	// BASTION_NETWORK_MTU or Docker's default
+	Mtu *uint32 `protobuf:"varint,5,opt,name=mtu,proto3,oneof" json:"mtu,omitempty"`
+	// Allow traffic between containers on the network (Docker default: true)
+	EnableIcc *bool `protobuf:"varint,6,opt,name=enable_icc,json=enableIcc,proto3,oneof" json:"enable_icc,omitempty"`
+	// Masquerade outbound traffic from the network (Docker default: true)
+	EnableIpMasquerade *bool `protobuf:"varint,7,opt,name=enable_ip_masquerade,json=enableIpMasquerade,proto3,oneof" json:"enable_ip_masquerade,omitempty"`
+	// Encrypt overlay traffic between nodes
+	Encrypted *bool `protobuf:"varint,8,opt,name=encrypted,proto3,oneof" json:"encrypted,omitempty"`
+	// Host interface a macvlan or ipvlan network attaches to (e.g. "eth0" or
+	// "eth0.100"), its macvlan_mode or ipvlan_mode, and the gateway of its
+	// subnet on the physical network
+	Parent        *string `protobuf:"bytes,9,opt,name=parent,proto3,oneof" json:"parent,omitempty"`
+	DriverMode    *string `protobuf:"bytes,10,opt,name=driver_mode,json=driverMode,proto3,oneof" json:"driver_mode,omitempty"`
+	Gateway       *string `protobuf:"bytes,11,opt,name=gateway,proto3,oneof" json:"gateway,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkConfig) Reset() {
+	*x = NetworkConfig{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkConfig) ProtoMessage() {}
+
+func (x *NetworkConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkConfig.ProtoReflect.Descriptor instead.
+func (*NetworkConfig) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *NetworkConfig) GetSubnetRange() string {
+	if x != nil && x.SubnetRange != nil {
+		return *x.SubnetRange
+	}
+	return ""
+}
+
+func (x *NetworkConfig) GetMinIps() uint32 {
+	if x != nil && x.MinIps != nil {
+		return *x.MinIps
+	}
+	return 0
+}
+
+func (x *NetworkConfig) GetDriver() string {
+	if x != nil && x.Driver != nil {
+		return *x.Driver
+	}
+	return ""
+}
+
+func (x *NetworkConfig) GetConfigHash() string {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return ""
+}
+
+func (x *NetworkConfig) GetMtu() uint32 {
+	if x != nil && x.Mtu != nil {
+		return *x.Mtu
+	}
+	return 0
+}
+
+func (x *NetworkConfig) GetEnableIcc() bool {
+	if x != nil && x.EnableIcc != nil {
+		return *x.EnableIcc
+	}
+	return false
+}
+
+func (x *NetworkConfig) GetEnableIpMasquerade() bool {
+	if x != nil && x.EnableIpMasquerade != nil {
+		return *x.EnableIpMasquerade
+	}
+	return false
+}
+
+func (x *NetworkConfig) GetEncrypted() bool {
+	if x != nil && x.Encrypted != nil {
+		return *x.Encrypted
+	}
+	return false
+}
+
+func (x *NetworkConfig) GetParent() string {
+	if x != nil && x.Parent != nil {
+		return *x.Parent
+	}
+	return ""
+}
+
+func (x *NetworkConfig) GetDriverMode() string {
+	if x != nil && x.DriverMode != nil {
+		return *x.DriverMode
+	}
+	return ""
+}
+
+func (x *NetworkConfig) GetGateway() string {
+	if x != nil && x.Gateway != nil {
+		return *x.Gateway
+	}
+	return ""
+}
+
+type AcquireNetworkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	NetworkConfig *NetworkConfig         `protobuf:"bytes,2,opt,name=network_config,json=networkConfig,proto3" json:"network_config,omitempty"`
+	// How long the network stays pooled for reuse once released (seconds,
+	// default: the bastion's BASTION_POOL_TTL, one hour unless set)
+	LeaseDurationSecs *uint32 `protobuf:"varint,3,opt,name=lease_duration_secs,json=leaseDurationSecs,proto3,oneof" json:"lease_duration_secs,omitempty"`
+	// Allocate the subnet from this partition of the pool's range
+	// (BASTION_SUBNET_PARTITIONS); unset uses the range outside every partition
+	PartitionKey *string `protobuf:"bytes,4,opt,name=partition_key,json=partitionKey,proto3,oneof" json:"partition_key,omitempty"`
+	// IPv4 CIDRs the network's subnet must not overlap, on top of the
+	// bastion's BASTION_EXCLUDED_SUBNETS (e.g. a VPN or pod range reachable from
+	// this container); pooled networks in them are not reused. At most 64.
+	ExcludeSubnets []string `protobuf:"bytes,5,rep,name=exclude_subnets,json=excludeSubnets,proto3" json:"exclude_subnets,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AcquireNetworkRequest) Reset() {
+	*x = AcquireNetworkRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcquireNetworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcquireNetworkRequest) ProtoMessage() {}
+
+func (x *AcquireNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcquireNetworkRequest.ProtoReflect.Descriptor instead.
+func (*AcquireNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *AcquireNetworkRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *AcquireNetworkRequest) GetNetworkConfig() *NetworkConfig {
+	if x != nil {
+		return x.NetworkConfig
+	}
+	return nil
+}
+
+func (x *AcquireNetworkRequest) GetLeaseDurationSecs() uint32 {
+	if x != nil && x.LeaseDurationSecs != nil {
+		return *x.LeaseDurationSecs
+	}
+	return 0
+}
+
+func (x *AcquireNetworkRequest) GetPartitionKey() string {
+	if x != nil && x.PartitionKey != nil {
+		return *x.PartitionKey
+	}
+	return ""
+}
+
+func (x *AcquireNetworkRequest) GetExcludeSubnets() []string {
+	if x != nil {
+		return x.ExcludeSubnets
+	}
+	return nil
+}
+
+type AcquireNetworkResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Network details if successful
+	NetworkName *string `protobuf:"bytes,3,opt,name=network_name,json=networkName,proto3,oneof" json:"network_name,omitempty"`
+	NetworkId   *string `protobuf:"bytes,4,opt,name=network_id,json=networkId,proto3,oneof" json:"network_id,omitempty"`
+	Subnet      *string `protobuf:"bytes,5,opt,name=subnet,proto3,oneof" json:"subnet,omitempty"`
+	// Whether this is a reused network (vs newly created)
+	Reused        bool `protobuf:"varint,6,opt,name=reused,proto3" json:"reused,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcquireNetworkResponse) Reset() {
+	*x = AcquireNetworkResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcquireNetworkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcquireNetworkResponse) ProtoMessage() {}
+
+func (x *AcquireNetworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcquireNetworkResponse.ProtoReflect.Descriptor instead.
+func (*AcquireNetworkResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *AcquireNetworkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AcquireNetworkResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *AcquireNetworkResponse) GetNetworkName() string {
+	if x != nil && x.NetworkName != nil {
+		return *x.NetworkName
+	}
+	return ""
+}
+
+func (x *AcquireNetworkResponse) GetNetworkId() string {
+	if x != nil && x.NetworkId != nil {
+		return *x.NetworkId
+	}
+	return ""
+}
+
+func (x *AcquireNetworkResponse) GetSubnet() string {
+	if x != nil && x.Subnet != nil {
+		return *x.Subnet
+	}
+	return ""
+}
+
+func (x *AcquireNetworkResponse) GetReused() bool {
+	if x != nil {
+		return x.Reused
+	}
+	return false
+}
+
+type ReleaseNetworkRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	NetworkName string                 `protobuf:"bytes,2,opt,name=network_name,json=networkName,proto3" json:"network_name,omitempty"`
+	// Force immediate cleanup (default: false, uses TTL)
+	ForceCleanup *bool `protobuf:"varint,3,opt,name=force_cleanup,json=forceCleanup,proto3,oneof" json:"force_cleanup,omitempty"`
+	// How long the network stays pooled for reuse (seconds), in place of the
+	// lease duration it was acquired with; capped by BASTION_POOL_MAX_TTL
+	PoolTtlSecs   *uint32 `protobuf:"varint,4,opt,name=pool_ttl_secs,json=poolTtlSecs,proto3,oneof" json:"pool_ttl_secs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseNetworkRequest) Reset() {
+	*x = ReleaseNetworkRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseNetworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseNetworkRequest) ProtoMessage() {}
+
+func (x *ReleaseNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseNetworkRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReleaseNetworkRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ReleaseNetworkRequest) GetNetworkName() string {
+	if x != nil {
+		return x.NetworkName
+	}
+	return ""
+}
+
+func (x *ReleaseNetworkRequest) GetForceCleanup() bool {
+	if x != nil && x.ForceCleanup != nil {
+		return *x.ForceCleanup
+	}
+	return false
+}
+
+func (x *ReleaseNetworkRequest) GetPoolTtlSecs() uint32 {
+	if x != nil && x.PoolTtlSecs != nil {
+		return *x.PoolTtlSecs
+	}
+	return 0
+}
+
+type ReleaseNetworkResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Whether network was cleaned up or returned to pool
+	CleanedUp     bool `protobuf:"varint,3,opt,name=cleaned_up,json=cleanedUp,proto3" json:"cleaned_up,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseNetworkResponse) Reset() {
+	*x = ReleaseNetworkResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseNetworkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseNetworkResponse) ProtoMessage() {}
+
+func (x *ReleaseNetworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseNetworkResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseNetworkResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ReleaseNetworkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ReleaseNetworkResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *ReleaseNetworkResponse) GetCleanedUp() bool {
+	if x != nil {
+		return x.CleanedUp
+	}
+	return false
+}
+
+type RenewNetworkLeaseRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	NetworkName string                 `protobuf:"bytes,2,opt,name=network_name,json=networkName,proto3" json:"network_name,omitempty"`
+	// Length of the renewed lease (seconds, default: BASTION_POOL_LEASE);
+	// capped by BASTION_POOL_MAX_LEASE
+	LeaseSecs     *uint32 `protobuf:"varint,3,opt,name=lease_secs,json=leaseSecs,proto3,oneof" json:"lease_secs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewNetworkLeaseRequest) Reset() {
+	*x = RenewNetworkLeaseRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewNetworkLeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewNetworkLeaseRequest) ProtoMessage() {}
+
+func (x *RenewNetworkLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewNetworkLeaseRequest.ProtoReflect.Descriptor instead.
+func (*RenewNetworkLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RenewNetworkLeaseRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *RenewNetworkLeaseRequest) GetNetworkName() string {
+	if x != nil {
+		return x.NetworkName
+	}
+	return ""
+}
+
+func (x *RenewNetworkLeaseRequest) GetLeaseSecs() uint32 {
+	if x != nil && x.LeaseSecs != nil {
+		return *x.LeaseSecs
+	}
+	return 0
+}
+
+type RenewNetworkLeaseResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Unix timestamp in milliseconds at which the lease now runs out
+	LeaseExpiresAt int64 `protobuf:"varint,3,opt,name=lease_expires_at,json=leaseExpiresAt,proto3" json:"lease_expires_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RenewNetworkLeaseResponse) Reset() {
+	*x = RenewNetworkLeaseResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewNetworkLeaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewNetworkLeaseResponse) ProtoMessage() {}
+
+func (x *RenewNetworkLeaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewNetworkLeaseResponse.ProtoReflect.Descriptor instead.
+func (*RenewNetworkLeaseResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RenewNetworkLeaseResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RenewNetworkLeaseResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *RenewNetworkLeaseResponse) GetLeaseExpiresAt() int64 {
+	if x != nil {
+		return x.LeaseExpiresAt
+	}
+	return 0
+}
+
+type NetworkStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkStatsRequest) Reset() {
+	*x = NetworkStatsRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkStatsRequest) ProtoMessage() {}
+
+func (x *NetworkStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkStatsRequest.ProtoReflect.Descriptor instead.
+func (*NetworkStatsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{32}
+}
+
+type NetworkStatsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalNetworks  uint32                 `protobuf:"varint,1,opt,name=total_networks,json=totalNetworks,proto3" json:"total_networks,omitempty"`
+	ActiveNetworks uint32                 `protobuf:"varint,2,opt,name=active_networks,json=activeNetworks,proto3" json:"active_networks,omitempty"`
+	PooledNetworks uint32                 `protobuf:"varint,3,opt,name=pooled_networks,json=pooledNetworks,proto3" json:"pooled_networks,omitempty"`
+	PendingCleanup uint32                 `protobuf:"varint,4,opt,name=pending_cleanup,json=pendingCleanup,proto3" json:"pending_cleanup,omitempty"`
+	// Pool utilization (0.0 - 1.0)
+	Utilization float32 `protobuf:"fixed32,5,opt,name=utilization,proto3" json:"utilization,omitempty"`
+	// Network pool health
+	Healthy bool `protobuf:"varint,6,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// Subnet utilization (0.0 - 1.0)
+	SubnetUtilization float32 `protobuf:"fixed32,7,opt,name=subnet_utilization,json=subnetUtilization,proto3" json:"subnet_utilization,omitempty"`
+	// Maximum available subnets
+	MaxSubnets uint32 `protobuf:"varint,8,opt,name=max_subnets,json=maxSubnets,proto3" json:"max_subnets,omitempty"`
+	// Network removals waiting in the cleanup queue
+	CleanupQueueDepth uint32 `protobuf:"varint,9,opt,name=cleanup_queue_depth,json=cleanupQueueDepth,proto3" json:"cleanup_queue_depth,omitempty"`
+	// Network removals completed and failed since startup
+	CleanupProcessed uint64 `protobuf:"varint,10,opt,name=cleanup_processed,json=cleanupProcessed,proto3" json:"cleanup_processed,omitempty"`
+	CleanupFailed    uint64 `protobuf:"varint,11,opt,name=cleanup_failed,json=cleanupFailed,proto3" json:"cleanup_failed,omitempty"`
+	// Time from queueing to removal, in milliseconds
+	CleanupAvgLatencyMs float32 `protobuf:"fixed32,12,opt,name=cleanup_avg_latency_ms,json=cleanupAvgLatencyMs,proto3" json:"cleanup_avg_latency_ms,omitempty"`
+	CleanupMaxLatencyMs float32 `protobuf:"fixed32,13,opt,name=cleanup_max_latency_ms,json=cleanupMaxLatencyMs,proto3" json:"cleanup_max_latency_ms,omitempty"`
+	// Container chains found drifted from their policy since startup, and how
+	// many of those were rebuilt or failed to be
+	ChainsDrifted      uint64 `protobuf:"varint,14,opt,name=chains_drifted,json=chainsDrifted,proto3" json:"chains_drifted,omitempty"`
+	ChainsRepaired     uint64 `protobuf:"varint,15,opt,name=chains_repaired,json=chainsRepaired,proto3" json:"chains_repaired,omitempty"`
+	ChainRepairsFailed uint64 `protobuf:"varint,16,opt,name=chain_repairs_failed,json=chainRepairsFailed,proto3" json:"chain_repairs_failed,omitempty"`
+	// Container chains in the kernel with no policy on record, as of the last check
+	UnknownChains uint32 `protobuf:"varint,17,opt,name=unknown_chains,json=unknownChains,proto3" json:"unknown_chains,omitempty"`
+	// Chains removed because their container was gone, since startup
+	OrphanedChainsRemoved uint64 `protobuf:"varint,18,opt,name=orphaned_chains_removed,json=orphanedChainsRemoved,proto3" json:"orphaned_chains_removed,omitempty"`
+	// Idle networks kept ready per config hash (BASTION_POOL_PREWARM)
+	Prewarm []*PrewarmStatus `protobuf:"bytes,19,rep,name=prewarm,proto3" json:"prewarm,omitempty"`
+	// Cap on networks held by the pool (0 = none), and pooled networks evicted
+	// to stay under it since startup
+	MaxNetworks      uint32 `protobuf:"varint,20,opt,name=max_networks,json=maxNetworks,proto3" json:"max_networks,omitempty"`
+	NetworkEvictions uint64 `protobuf:"varint,21,opt,name=network_evictions,json=networkEvictions,proto3" json:"network_evictions,omitempty"`
+	// Configured subnet partitions and the networks each holds
+	Partitions []*SubnetPartition `protobuf:"bytes,22,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	// Pool networks found deleted outside the pool (e.g. by docker network
+	// prune) since startup
+	NetworksRemovedExternally uint64 `protobuf:"varint,23,opt,name=networks_removed_externally,json=networksRemovedExternally,proto3" json:"networks_removed_externally,omitempty"`
+	// Networks held past their lease without renewal, and suspect networks
+	// reclaimed because their container was gone since startup
+	SuspectLeases   uint32 `protobuf:"varint,24,opt,name=suspect_leases,json=suspectLeases,proto3" json:"suspect_leases,omitempty"`
+	LeasesReclaimed uint64 `protobuf:"varint,25,opt,name=leases_reclaimed,json=leasesReclaimed,proto3" json:"leases_reclaimed,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *NetworkStatsResponse) Reset() {
+	*x = NetworkStatsResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkStatsResponse) ProtoMessage() {}
+
+func (x *NetworkStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkStatsResponse.ProtoReflect.Descriptor instead.
+func (*NetworkStatsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *NetworkStatsResponse) GetTotalNetworks() uint32 {
+	if x != nil {
+		return x.TotalNetworks
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetActiveNetworks() uint32 {
+	if x != nil {
+		return x.ActiveNetworks
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetPooledNetworks() uint32 {
+	if x != nil {
+		return x.PooledNetworks
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetPendingCleanup() uint32 {
+	if x != nil {
+		return x.PendingCleanup
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetUtilization() float32 {
+	if x != nil {
+		return x.Utilization
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *NetworkStatsResponse) GetSubnetUtilization() float32 {
+	if x != nil {
+		return x.SubnetUtilization
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetMaxSubnets() uint32 {
+	if x != nil {
+		return x.MaxSubnets
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetCleanupQueueDepth() uint32 {
+	if x != nil {
+		return x.CleanupQueueDepth
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetCleanupProcessed() uint64 {
+	if x != nil {
+		return x.CleanupProcessed
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetCleanupFailed() uint64 {
+	if x != nil {
+		return x.CleanupFailed
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetCleanupAvgLatencyMs() float32 {
+	if x != nil {
+		return x.CleanupAvgLatencyMs
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetCleanupMaxLatencyMs() float32 {
+	if x != nil {
+		return x.CleanupMaxLatencyMs
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetChainsDrifted() uint64 {
+	if x != nil {
+		return x.ChainsDrifted
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetChainsRepaired() uint64 {
+	if x != nil {
+		return x.ChainsRepaired
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetChainRepairsFailed() uint64 {
+	if x != nil {
+		return x.ChainRepairsFailed
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetUnknownChains() uint32 {
+	if x != nil {
+		return x.UnknownChains
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetOrphanedChainsRemoved() uint64 {
+	if x != nil {
+		return x.OrphanedChainsRemoved
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetPrewarm() []*PrewarmStatus {
+	if x != nil {
+		return x.Prewarm
+	}
+	return nil
+}
+
+func (x *NetworkStatsResponse) GetMaxNetworks() uint32 {
+	if x != nil {
+		return x.MaxNetworks
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetNetworkEvictions() uint64 {
+	if x != nil {
+		return x.NetworkEvictions
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetPartitions() []*SubnetPartition {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *NetworkStatsResponse) GetNetworksRemovedExternally() uint64 {
+	if x != nil {
+		return x.NetworksRemovedExternally
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetSuspectLeases() uint32 {
+	if x != nil {
+		return x.SuspectLeases
+	}
+	return 0
+}
+
+func (x *NetworkStatsResponse) GetLeasesReclaimed() uint64 {
+	if x != nil {
+		return x.LeasesReclaimed
+	}
+	return 0
+}
+
+type SubnetPartition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Subnet        string                 `protobuf:"bytes,2,opt,name=subnet,proto3" json:"subnet,omitempty"`
+	Networks      uint32                 `protobuf:"varint,3,opt,name=networks,proto3" json:"networks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubnetPartition) Reset() {
+	*x = SubnetPartition{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubnetPartition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubnetPartition) ProtoMessage() {}
+
+func (x *SubnetPartition) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubnetPartition.ProtoReflect.Descriptor instead.
+func (*SubnetPartition) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SubnetPartition) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SubnetPartition) GetSubnet() string {
+	if x != nil {
+		return x.Subnet
+	}
+	return ""
+}
+
+func (x *SubnetPartition) GetNetworks() uint32 {
+	if x != nil {
+		return x.Networks
+	}
+	return 0
+}
+
+type PrewarmStatus struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ConfigHash string                 `protobuf:"bytes,1,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+	Target     uint32                 `protobuf:"varint,2,opt,name=target,proto3" json:"target,omitempty"`
+	// Idle networks available for this config hash right now
+	Ready uint32 `protobuf:"varint,3,opt,name=ready,proto3" json:"ready,omitempty"`
+	// Why the last attempt to create prewarmed networks failed, if it did
+	LastError     *string `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3,oneof" json:"last_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrewarmStatus) Reset() {
+	*x = PrewarmStatus{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrewarmStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrewarmStatus) ProtoMessage() {}
+
+func (x *PrewarmStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrewarmStatus.ProtoReflect.Descriptor instead.
+func (*PrewarmStatus) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *PrewarmStatus) GetConfigHash() string {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return ""
+}
+
+func (x *PrewarmStatus) GetTarget() uint32 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *PrewarmStatus) GetReady() uint32 {
+	if x != nil {
+		return x.Ready
+	}
+	return 0
+}
+
+func (x *PrewarmStatus) GetLastError() string {
+	if x != nil && x.LastError != nil {
+		return *x.LastError
+	}
+	return ""
+}
+
+type ListNetworksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters; unset fields match every network
+	ContainerId *string `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3,oneof" json:"container_id,omitempty"`
+	ConfigHash  *string `protobuf:"bytes,2,opt,name=config_hash,json=configHash,proto3,oneof" json:"config_hash,omitempty"`
+	// "" matches networks outside every partition
+	PartitionKey *string `protobuf:"bytes,3,opt,name=partition_key,json=partitionKey,proto3,oneof" json:"partition_key,omitempty"`
+	Driver       *string `protobuf:"bytes,4,opt,name=driver,proto3,oneof" json:"driver,omitempty"`
+	// "active" (held by a container), "pooled" (idle) or "suspect" (held past
+	// its lease)
+	State         *string `protobuf:"bytes,5,opt,name=state,proto3,oneof" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNetworksRequest) Reset() {
+	*x = ListNetworksRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNetworksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNetworksRequest) ProtoMessage() {}
+
+func (x *ListNetworksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNetworksRequest.ProtoReflect.Descriptor instead.
+func (*ListNetworksRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ListNetworksRequest) GetContainerId() string {
+	if x != nil && x.ContainerId != nil {
+		return *x.ContainerId
+	}
+	return ""
+}
+
+func (x *ListNetworksRequest) GetConfigHash() string {
+	if x != nil && x.ConfigHash != nil {
+		return *x.ConfigHash
+	}
+	return ""
+}
+
+func (x *ListNetworksRequest) GetPartitionKey() string {
+	if x != nil && x.PartitionKey != nil {
+		return *x.PartitionKey
+	}
+	return ""
+}
+
+func (x *ListNetworksRequest) GetDriver() string {
+	if x != nil && x.Driver != nil {
+		return *x.Driver
+	}
+	return ""
+}
+
+func (x *ListNetworksRequest) GetState() string {
+	if x != nil && x.State != nil {
+		return *x.State
+	}
+	return ""
+}
+
+type PoolNetwork struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	NetworkName  string                 `protobuf:"bytes,1,opt,name=network_name,json=networkName,proto3" json:"network_name,omitempty"`
+	NetworkId    string                 `protobuf:"bytes,2,opt,name=network_id,json=networkId,proto3" json:"network_id,omitempty"`
+	Subnet       string                 `protobuf:"bytes,3,opt,name=subnet,proto3" json:"subnet,omitempty"`
+	ConfigHash   string                 `protobuf:"bytes,4,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+	PartitionKey string                 `protobuf:"bytes,5,opt,name=partition_key,json=partitionKey,proto3" json:"partition_key,omitempty"`
+	Driver       string                 `protobuf:"bytes,6,opt,name=driver,proto3" json:"driver,omitempty"`
+	// The container holding the network; unset while it is pooled
+	CurrentContainer *string `protobuf:"bytes,7,opt,name=current_container,json=currentContainer,proto3,oneof" json:"current_container,omitempty"`
+	ReuseCount       uint32  `protobuf:"varint,8,opt,name=reuse_count,json=reuseCount,proto3" json:"reuse_count,omitempty"`
+	Suspect          bool    `protobuf:"varint,9,opt,name=suspect,proto3" json:"suspect,omitempty"`
+	// Unix timestamps in milliseconds
+	CreatedAt      int64  `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastReleasedAt *int64 `protobuf:"varint,11,opt,name=last_released_at,json=lastReleasedAt,proto3,oneof" json:"last_released_at,omitempty"`
+	// When a pooled network is removed unless reused
+	CleanupAt *int64 `protobuf:"varint,12,opt,name=cleanup_at,json=cleanupAt,proto3,oneof" json:"cleanup_at,omitempty"`
+	// When the holder's lease runs out unless renewed
+	LeaseExpiresAt *int64 `protobuf:"varint,13,opt,name=lease_expires_at,json=leaseExpiresAt,proto3,oneof" json:"lease_expires_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PoolNetwork) Reset() {
+	*x = PoolNetwork{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolNetwork) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolNetwork) ProtoMessage() {}
+
+func (x *PoolNetwork) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolNetwork.ProtoReflect.Descriptor instead.
+func (*PoolNetwork) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *PoolNetwork) GetNetworkName() string {
+	if x != nil {
+		return x.NetworkName
+	}
+	return ""
+}
+
+func (x *PoolNetwork) GetNetworkId() string {
+	if x != nil {
+		return x.NetworkId
+	}
+	return ""
+}
+
+func (x *PoolNetwork) GetSubnet() string {
+	if x != nil {
+		return x.Subnet
+	}
+	return ""
+}
+
+func (x *PoolNetwork) GetConfigHash() string {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return ""
+}
+
+func (x *PoolNetwork) GetPartitionKey() string {
+	if x != nil {
+		return x.PartitionKey
+	}
+	return ""
+}
+
+func (x *PoolNetwork) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *PoolNetwork) GetCurrentContainer() string {
+	if x != nil && x.CurrentContainer != nil {
+		return *x.CurrentContainer
+	}
+	return ""
+}
+
+func (x *PoolNetwork) GetReuseCount() uint32 {
+	if x != nil {
+		return x.ReuseCount
+	}
+	return 0
+}
+
+func (x *PoolNetwork) GetSuspect() bool {
+	if x != nil {
+		return x.Suspect
+	}
+	return false
+}
+
+func (x *PoolNetwork) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *PoolNetwork) GetLastReleasedAt() int64 {
+	if x != nil && x.LastReleasedAt != nil {
+		return *x.LastReleasedAt
+	}
+	return 0
+}
+
+func (x *PoolNetwork) GetCleanupAt() int64 {
+	if x != nil && x.CleanupAt != nil {
+		return *x.CleanupAt
+	}
+	return 0
+}
+
+func (x *PoolNetwork) GetLeaseExpiresAt() int64 {
+	if x != nil && x.LeaseExpiresAt != nil {
+		return *x.LeaseExpiresAt
+	}
+	return 0
+}
+
+type ListNetworksResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// By network name
+	Networks      []*PoolNetwork `protobuf:"bytes,3,rep,name=networks,proto3" json:"networks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNetworksResponse) Reset() {
+	*x = ListNetworksResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNetworksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNetworksResponse) ProtoMessage() {}
+
+func (x *ListNetworksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNetworksResponse.ProtoReflect.Descriptor instead.
+func (*ListNetworksResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListNetworksResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ListNetworksResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *ListNetworksResponse) GetNetworks() []*PoolNetwork {
+	if x != nil {
+		return x.Networks
+	}
+	return nil
+}
+
+type CollectOrphanedChainsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Report the orphaned chains without removing them
+	DryRun        bool `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectOrphanedChainsRequest) Reset() {
+	*x = CollectOrphanedChainsRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectOrphanedChainsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectOrphanedChainsRequest) ProtoMessage() {}
+
+func (x *CollectOrphanedChainsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectOrphanedChainsRequest.ProtoReflect.Descriptor instead.
+func (*CollectOrphanedChainsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CollectOrphanedChainsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type CollectOrphanedChainsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Chains removed, or that would be with dry_run
+	OrphanedChains []string `protobuf:"bytes,3,rep,name=orphaned_chains,json=orphanedChains,proto3" json:"orphaned_chains,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CollectOrphanedChainsResponse) Reset() {
+	*x = CollectOrphanedChainsResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectOrphanedChainsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectOrphanedChainsResponse) ProtoMessage() {}
+
+func (x *CollectOrphanedChainsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectOrphanedChainsResponse.ProtoReflect.Descriptor instead.
+func (*CollectOrphanedChainsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CollectOrphanedChainsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CollectOrphanedChainsResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *CollectOrphanedChainsResponse) GetOrphanedChains() []string {
+	if x != nil {
+		return x.OrphanedChains
+	}
+	return nil
+}
+
+type InspectChainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectChainRequest) Reset() {
+	*x = InspectChainRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectChainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectChainRequest) ProtoMessage() {}
+
+func (x *InspectChainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *AcquireNetworkResponse) GetNetworkId() string {
-	if x != nil && x.NetworkId != nil {
-		return *x.NetworkId
-	}
-	return ""
+// Deprecated: Use InspectChainRequest.ProtoReflect.Descriptor instead.
+func (*InspectChainRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *AcquireNetworkResponse) GetSubnet() string {
-	if x != nil && x.Subnet != nil {
-		return *x.Subnet
+func (x *InspectChainRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
 	}
 	return ""
 }
 
-func (x *AcquireNetworkResponse) GetReused() bool {
+func (x *InspectChainRequest) GetContainerId() string {
 	if x != nil {
-		return x.Reused
+		return x.ContainerId
 	}
-	return false
+	return ""
 }
 
-type ReleaseNetworkRequest struct {
-	state       protoimpl.MessageState `protogen:"open.v1"`
-	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	NetworkName string                 `protobuf:"bytes,2,opt,name=network_name,json=networkName,proto3" json:"network_name,omitempty"`
-	// Force immediate cleanup (default: false, uses TTL)
-	ForceCleanup  *bool `protobuf:"varint,3,opt,name=force_cleanup,json=forceCleanup,proto3,oneof" json:"force_cleanup,omitempty"`
+type ChainRule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "ipv4" or "ipv6", or "inet" with the nftables backend
+	Family string `protobuf:"bytes,1,opt,name=family,proto3" json:"family,omitempty"`
+	// The rule in iptables -S form, or as nft lists it
+	Rule string `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	// Counters are only set when the backend keeps them
+	Packets       *uint64 `protobuf:"varint,3,opt,name=packets,proto3,oneof" json:"packets,omitempty"`
+	Bytes         *uint64 `protobuf:"varint,4,opt,name=bytes,proto3,oneof" json:"bytes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReleaseNetworkRequest) Reset() {
-	*x = ReleaseNetworkRequest{}
-	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[13]
+func (x *ChainRule) Reset() {
+	*x = ChainRule{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReleaseNetworkRequest) String() string {
+func (x *ChainRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReleaseNetworkRequest) ProtoMessage() {}
+func (*ChainRule) ProtoMessage() {}
 
-func (x *ReleaseNetworkRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[13]
+func (x *ChainRule) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -857,57 +3108,66 @@ func (x *ReleaseNetworkRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReleaseNetworkRequest.ProtoReflect.Descriptor instead.
-func (*ReleaseNetworkRequest) Descriptor() ([]byte, []int) {
-	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use ChainRule.ProtoReflect.Descriptor instead.
+func (*ChainRule) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *ReleaseNetworkRequest) GetContainerId() string {
+func (x *ChainRule) GetFamily() string {
 	if x != nil {
-		return x.ContainerId
+		return x.Family
 	}
 	return ""
 }
 
-func (x *ReleaseNetworkRequest) GetNetworkName() string {
+func (x *ChainRule) GetRule() string {
 	if x != nil {
-		return x.NetworkName
+		return x.Rule
 	}
 	return ""
 }
 
-func (x *ReleaseNetworkRequest) GetForceCleanup() bool {
-	if x != nil && x.ForceCleanup != nil {
-		return *x.ForceCleanup
+func (x *ChainRule) GetPackets() uint64 {
+	if x != nil && x.Packets != nil {
+		return *x.Packets
 	}
-	return false
+	return 0
 }
 
-type ReleaseNetworkResponse struct {
+func (x *ChainRule) GetBytes() uint64 {
+	if x != nil && x.Bytes != nil {
+		return *x.Bytes
+	}
+	return 0
+}
+
+type InspectChainResponse struct {
 	state   protoimpl.MessageState `protogen:"open.v1"`
 	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
-	// Whether network was cleaned up or returned to pool
-	CleanedUp     bool `protobuf:"varint,3,opt,name=cleaned_up,json=cleanedUp,proto3" json:"cleaned_up,omitempty"`
+	// In evaluation order, IPv4 before IPv6
+	Rules []*ChainRule `protobuf:"bytes,3,rep,name=rules,proto3" json:"rules,omitempty"`
+	// Whether the container's traffic is sent to the chain
+	Jumped        bool `protobuf:"varint,4,opt,name=jumped,proto3" json:"jumped,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReleaseNetworkResponse) Reset() {
-	*x = ReleaseNetworkResponse{}
-	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[14]
+func (x *InspectChainResponse) Reset() {
+	*x = InspectChainResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReleaseNetworkResponse) String() string {
+func (x *InspectChainResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReleaseNetworkResponse) ProtoMessage() {}
+func (*InspectChainResponse) ProtoMessage() {}
 
-func (x *ReleaseNetworkResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[14]
+func (x *InspectChainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -918,53 +3178,69 @@ func (x *ReleaseNetworkResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReleaseNetworkResponse.ProtoReflect.Descriptor instead.
-func (*ReleaseNetworkResponse) Descriptor() ([]byte, []int) {
-	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use InspectChainResponse.ProtoReflect.Descriptor instead.
+func (*InspectChainResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *ReleaseNetworkResponse) GetSuccess() bool {
+func (x *InspectChainResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *ReleaseNetworkResponse) GetError() string {
+func (x *InspectChainResponse) GetError() string {
 	if x != nil && x.Error != nil {
 		return *x.Error
 	}
 	return ""
 }
 
-func (x *ReleaseNetworkResponse) GetCleanedUp() bool {
+func (x *InspectChainResponse) GetRules() []*ChainRule {
 	if x != nil {
-		return x.CleanedUp
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *InspectChainResponse) GetJumped() bool {
+	if x != nil {
+		return x.Jumped
 	}
 	return false
 }
 
-type NetworkStatsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type QueryAuditLogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters; unset fields match every entry
+	ContainerId *string `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3,oneof" json:"container_id,omitempty"`
+	ChainName   *string `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3,oneof" json:"chain_name,omitempty"`
+	Operation   *string `protobuf:"bytes,3,opt,name=operation,proto3,oneof" json:"operation,omitempty"`
+	// Time range as Unix timestamps in milliseconds, since inclusive, until exclusive
+	Since *int64 `protobuf:"varint,4,opt,name=since,proto3,oneof" json:"since,omitempty"`
+	Until *int64 `protobuf:"varint,5,opt,name=until,proto3,oneof" json:"until,omitempty"`
+	// Return only the most recent entries (default 1000, max 10000)
+	Limit         *uint32 `protobuf:"varint,6,opt,name=limit,proto3,oneof" json:"limit,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkStatsRequest) Reset() {
-	*x = NetworkStatsRequest{}
-	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[15]
+func (x *QueryAuditLogRequest) Reset() {
+	*x = QueryAuditLogRequest{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkStatsRequest) String() string {
+func (x *QueryAuditLogRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkStatsRequest) ProtoMessage() {}
+func (*QueryAuditLogRequest) ProtoMessage() {}
 
-func (x *NetworkStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[15]
+func (x *QueryAuditLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -975,44 +3251,83 @@ func (x *NetworkStatsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkStatsRequest.ProtoReflect.Descriptor instead.
-func (*NetworkStatsRequest) Descriptor() ([]byte, []int) {
-	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use QueryAuditLogRequest.ProtoReflect.Descriptor instead.
+func (*QueryAuditLogRequest) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{44}
 }
 
-type NetworkStatsResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	TotalNetworks  uint32                 `protobuf:"varint,1,opt,name=total_networks,json=totalNetworks,proto3" json:"total_networks,omitempty"`
-	ActiveNetworks uint32                 `protobuf:"varint,2,opt,name=active_networks,json=activeNetworks,proto3" json:"active_networks,omitempty"`
-	PooledNetworks uint32                 `protobuf:"varint,3,opt,name=pooled_networks,json=pooledNetworks,proto3" json:"pooled_networks,omitempty"`
-	PendingCleanup uint32                 `protobuf:"varint,4,opt,name=pending_cleanup,json=pendingCleanup,proto3" json:"pending_cleanup,omitempty"`
-	// Pool utilization (0.0 - 1.0)
-	Utilization float32 `protobuf:"fixed32,5,opt,name=utilization,proto3" json:"utilization,omitempty"`
-	// Network pool health
-	Healthy bool `protobuf:"varint,6,opt,name=healthy,proto3" json:"healthy,omitempty"`
-	// Subnet utilization (0.0 - 1.0)
-	SubnetUtilization float32 `protobuf:"fixed32,7,opt,name=subnet_utilization,json=subnetUtilization,proto3" json:"subnet_utilization,omitempty"`
-	// Maximum available subnets
-	MaxSubnets    uint32 `protobuf:"varint,8,opt,name=max_subnets,json=maxSubnets,proto3" json:"max_subnets,omitempty"`
+func (x *QueryAuditLogRequest) GetContainerId() string {
+	if x != nil && x.ContainerId != nil {
+		return *x.ContainerId
+	}
+	return ""
+}
+
+func (x *QueryAuditLogRequest) GetChainName() string {
+	if x != nil && x.ChainName != nil {
+		return *x.ChainName
+	}
+	return ""
+}
+
+func (x *QueryAuditLogRequest) GetOperation() string {
+	if x != nil && x.Operation != nil {
+		return *x.Operation
+	}
+	return ""
+}
+
+func (x *QueryAuditLogRequest) GetSince() int64 {
+	if x != nil && x.Since != nil {
+		return *x.Since
+	}
+	return 0
+}
+
+func (x *QueryAuditLogRequest) GetUntil() int64 {
+	if x != nil && x.Until != nil {
+		return *x.Until
+	}
+	return 0
+}
+
+func (x *QueryAuditLogRequest) GetLimit() uint32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+type AuditEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unix timestamp in milliseconds
+	Timestamp   int64  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Operation   string `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	ChainName   string `protobuf:"bytes,3,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	ContainerId string `protobuf:"bytes,4,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	RunId       string `protobuf:"bytes,5,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	// Who made the call: "admin" or "run:<id>"
+	Principal     string `protobuf:"bytes,6,opt,name=principal,proto3" json:"principal,omitempty"`
+	Success       bool   `protobuf:"varint,7,opt,name=success,proto3" json:"success,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkStatsResponse) Reset() {
-	*x = NetworkStatsResponse{}
-	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[16]
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkStatsResponse) String() string {
+func (x *AuditEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkStatsResponse) ProtoMessage() {}
+func (*AuditEntry) ProtoMessage() {}
 
-func (x *NetworkStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[16]
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1023,65 +3338,119 @@ func (x *NetworkStatsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkStatsResponse.ProtoReflect.Descriptor instead.
-func (*NetworkStatsResponse) Descriptor() ([]byte, []int) {
-	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *NetworkStatsResponse) GetTotalNetworks() uint32 {
+func (x *AuditEntry) GetTimestamp() int64 {
 	if x != nil {
-		return x.TotalNetworks
+		return x.Timestamp
 	}
 	return 0
 }
 
-func (x *NetworkStatsResponse) GetActiveNetworks() uint32 {
+func (x *AuditEntry) GetOperation() string {
 	if x != nil {
-		return x.ActiveNetworks
+		return x.Operation
 	}
-	return 0
+	return ""
 }
 
-func (x *NetworkStatsResponse) GetPooledNetworks() uint32 {
+func (x *AuditEntry) GetChainName() string {
 	if x != nil {
-		return x.PooledNetworks
+		return x.ChainName
 	}
-	return 0
+	return ""
 }
 
-func (x *NetworkStatsResponse) GetPendingCleanup() uint32 {
+func (x *AuditEntry) GetContainerId() string {
 	if x != nil {
-		return x.PendingCleanup
+		return x.ContainerId
 	}
-	return 0
+	return ""
 }
 
-func (x *NetworkStatsResponse) GetUtilization() float32 {
+func (x *AuditEntry) GetRunId() string {
 	if x != nil {
-		return x.Utilization
+		return x.RunId
 	}
-	return 0
+	return ""
 }
 
-func (x *NetworkStatsResponse) GetHealthy() bool {
+func (x *AuditEntry) GetPrincipal() string {
 	if x != nil {
-		return x.Healthy
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetSuccess() bool {
+	if x != nil {
+		return x.Success
 	}
 	return false
 }
 
-func (x *NetworkStatsResponse) GetSubnetUtilization() float32 {
+type QueryAuditLogResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Oldest first
+	Entries       []*AuditEntry `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryAuditLogResponse) Reset() {
+	*x = QueryAuditLogResponse{}
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryAuditLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryAuditLogResponse) ProtoMessage() {}
+
+func (x *QueryAuditLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_bastion_proto_bastion_proto_msgTypes[46]
 	if x != nil {
-		return x.SubnetUtilization
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *NetworkStatsResponse) GetMaxSubnets() uint32 {
+// Deprecated: Use QueryAuditLogResponse.ProtoReflect.Descriptor instead.
+func (*QueryAuditLogResponse) Descriptor() ([]byte, []int) {
+	return file_internal_bastion_proto_bastion_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *QueryAuditLogResponse) GetSuccess() bool {
 	if x != nil {
-		return x.MaxSubnets
+		return x.Success
 	}
-	return 0
+	return false
+}
+
+func (x *QueryAuditLogResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *QueryAuditLogResponse) GetEntries() []*AuditEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
 }
 
 var File_internal_bastion_proto_bastion_proto protoreflect.FileDescriptor
@@ -1097,16 +3466,42 @@ const file_internal_bastion_proto_bastion_proto_rawDesc = "" +
 	"\x12SetupChainResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
-	"\x06_error\"\x85\x01\n" +
+	"\x06_error\"\x9e\x01\n" +
 	"\x11ApplyRulesRequest\x12\x1d\n" +
 	"\n" +
 	"chain_name\x18\x01 \x01(\tR\tchainName\x12.\n" +
 	"\x06policy\x18\x02 \x01(\v2\x16.bastion.NetworkPolicyR\x06policy\x12!\n" +
-	"\fcontainer_id\x18\x03 \x01(\tR\vcontainerId\"x\n" +
+	"\fcontainer_id\x18\x03 \x01(\tR\vcontainerId\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"\x94\x01\n" +
 	"\x12ApplyRulesResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12#\n" +
+	"\rrules_applied\x18\x03 \x01(\x05R\frulesApplied\x12\x1a\n" +
+	"\bcommands\x18\x04 \x03(\tR\bcommandsB\b\n" +
+	"\x06_error\"\x8e\x01\n" +
+	"\x1aUpdateNetworkPolicyRequest\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12.\n" +
+	"\x06policy\x18\x02 \x01(\v2\x16.bastion.NetworkPolicyR\x06policy\x12!\n" +
+	"\fcontainer_id\x18\x03 \x01(\tR\vcontainerId\"\x81\x01\n" +
+	"\x1bUpdateNetworkPolicyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12#\n" +
 	"\rrules_applied\x18\x03 \x01(\x05R\frulesAppliedB\b\n" +
+	"\x06_error\"\x86\x01\n" +
+	"\x12UpdateRulesRequest\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12.\n" +
+	"\x06policy\x18\x02 \x01(\v2\x16.bastion.NetworkPolicyR\x06policy\x12!\n" +
+	"\fcontainer_id\x18\x03 \x01(\tR\vcontainerId\"\xdb\x01\n" +
+	"\x13UpdateRulesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12#\n" +
+	"\rrules_applied\x18\x03 \x01(\x05R\frulesApplied\x12\x1f\n" +
+	"\vrules_added\x18\x04 \x01(\x05R\n" +
+	"rulesAdded\x12#\n" +
+	"\rrules_removed\x18\x05 \x01(\x05R\frulesRemoved\x12\x1a\n" +
+	"\breplaced\x18\x06 \x01(\bR\breplacedB\b\n" +
 	"\x06_error\"W\n" +
 	"\x13CleanupChainRequest\x12\x1d\n" +
 	"\n" +
@@ -1115,12 +3510,79 @@ const file_internal_bastion_proto_bastion_proto_rawDesc = "" +
 	"\x14CleanupChainResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"m\n" +
+	"\vPortMapping\x12%\n" +
+	"\x0econtainer_port\x18\x01 \x01(\rR\rcontainerPort\x12\x1b\n" +
+	"\thost_port\x18\x02 \x01(\rR\bhostPort\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\"\x82\x01\n" +
+	"\x12ExposePortsRequest\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12!\n" +
+	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\x12*\n" +
+	"\x05ports\x18\x03 \x03(\v2\x14.bastion.PortMappingR\x05ports\"\x80\x01\n" +
+	"\x13ExposePortsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12*\n" +
+	"\x05ports\x18\x03 \x03(\v2\x14.bastion.PortMappingR\x05portsB\b\n" +
+	"\x06_error\"\x8b\x01\n" +
+	"\x17SetupPortForwardRequest\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12!\n" +
+	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\x12.\n" +
+	"\amapping\x18\x03 \x01(\v2\x14.bastion.PortMappingR\amapping\"\x89\x01\n" +
+	"\x18SetupPortForwardResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12.\n" +
+	"\amapping\x18\x03 \x01(\v2\x14.bastion.PortMappingR\amappingB\b\n" +
+	"\x06_error\"\x97\x01\n" +
+	"\x1aTeardownPortForwardRequest\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12!\n" +
+	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\x12\x1b\n" +
+	"\thost_port\x18\x03 \x01(\rR\bhostPort\x12\x1a\n" +
+	"\bprotocol\x18\x04 \x01(\tR\bprotocol\"\\\n" +
+	"\x1bTeardownPortForwardResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
 	"\x06_error\"\x0f\n" +
-	"\rHealthRequest\"s\n" +
+	"\rHealthRequest\"\xbd\x01\n" +
 	"\x0eHealthResponse\x12\x18\n" +
 	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12-\n" +
-	"\x12iptables_available\x18\x03 \x01(\bR\x11iptablesAvailable\"\xf4\x01\n" +
+	"\x12iptables_available\x18\x03 \x01(\bR\x11iptablesAvailable\x121\n" +
+	"\x12dns_filter_address\x18\x04 \x01(\tH\x00R\x10dnsFilterAddress\x88\x01\x01B\x15\n" +
+	"\x13_dns_filter_address\"Y\n" +
+	"\x15StreamFlowLogsRequest\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12!\n" +
+	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\"\xd9\x01\n" +
+	"\n" +
+	"FlowRecord\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12\x18\n" +
+	"\averdict\x18\x02 \x01(\tR\averdict\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\x12\x10\n" +
+	"\x03src\x18\x04 \x01(\tR\x03src\x12\x10\n" +
+	"\x03dst\x18\x05 \x01(\tR\x03dst\x12\x19\n" +
+	"\bsrc_port\x18\x06 \x01(\rR\asrcPort\x12\x19\n" +
+	"\bdst_port\x18\a \x01(\rR\adstPort\x12\x1c\n" +
+	"\ttimestamp\x18\b \x01(\x03R\ttimestamp\"\xa9\x02\n" +
+	"\x15CapturePacketsRequest\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12!\n" +
+	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\x12\x1b\n" +
+	"\x06filter\x18\x03 \x01(\tH\x00R\x06filter\x88\x01\x01\x12$\n" +
+	"\vmax_packets\x18\x04 \x01(\rH\x01R\n" +
+	"maxPackets\x88\x01\x01\x12/\n" +
+	"\x11max_duration_secs\x18\x05 \x01(\rH\x02R\x0fmaxDurationSecs\x88\x01\x01\x12\x1d\n" +
+	"\asnaplen\x18\x06 \x01(\rH\x03R\asnaplen\x88\x01\x01B\t\n" +
+	"\a_filterB\x0e\n" +
+	"\f_max_packetsB\x14\n" +
+	"\x12_max_duration_secsB\n" +
+	"\n" +
+	"\b_snaplen\"\"\n" +
+	"\fCaptureChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\xa6\x03\n" +
 	"\rNetworkPolicy\x12\x16\n" +
 	"\x06policy\x18\x01 \x01(\tR\x06policy\x12%\n" +
 	"\x0eblock_metadata\x18\x02 \x01(\bR\rblockMetadata\x12\x1b\n" +
@@ -1128,27 +3590,55 @@ const file_internal_bastion_proto_bastion_proto_rawDesc = "" +
 	"\vdns_servers\x18\x04 \x03(\tR\n" +
 	"dnsServers\x122\n" +
 	"\twhitelist\x18\x05 \x03(\v2\x14.bastion.NetworkRuleR\twhitelist\x122\n" +
-	"\tblacklist\x18\x06 \x03(\v2\x14.bastion.NetworkRuleR\tblacklist\"n\n" +
+	"\tblacklist\x18\x06 \x03(\v2\x14.bastion.NetworkRuleR\tblacklist\x12,\n" +
+	"\x0fmax_connections\x18\a \x01(\rH\x00R\x0emaxConnections\x88\x01\x01\x12!\n" +
+	"\flog_attempts\x18\b \x01(\bR\vlogAttempts\x12.\n" +
+	"\x13dns_allowed_domains\x18\t \x03(\tR\x11dnsAllowedDomains\x12\x1b\n" +
+	"\tpod_group\x18\n" +
+	" \x01(\tR\bpodGroupB\x12\n" +
+	"\x10_max_connections\"n\n" +
 	"\vNetworkRule\x12\x12\n" +
 	"\x04cidr\x18\x01 \x01(\tR\x04cidr\x12%\n" +
 	"\vdescription\x18\x02 \x01(\tH\x00R\vdescription\x88\x01\x01\x12\x14\n" +
 	"\x05ports\x18\x03 \x03(\rR\x05portsB\x0e\n" +
-	"\f_description\"\xbb\x01\n" +
+	"\f_description\"\x97\x04\n" +
 	"\rNetworkConfig\x12&\n" +
 	"\fsubnet_range\x18\x01 \x01(\tH\x00R\vsubnetRange\x88\x01\x01\x12\x1c\n" +
 	"\amin_ips\x18\x02 \x01(\rH\x01R\x06minIps\x88\x01\x01\x12\x1b\n" +
 	"\x06driver\x18\x03 \x01(\tH\x02R\x06driver\x88\x01\x01\x12\x1f\n" +
 	"\vconfig_hash\x18\x04 \x01(\tR\n" +
-	"configHashB\x0f\n" +
+	"configHash\x12\x15\n" +
+	"\x03mtu\x18\x05 \x01(\rH\x03R\x03mtu\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"enable_icc\x18\x06 \x01(\bH\x04R\tenableIcc\x88\x01\x01\x125\n" +
+	"\x14enable_ip_masquerade\x18\a \x01(\bH\x05R\x12enableIpMasquerade\x88\x01\x01\x12!\n" +
+	"\tencrypted\x18\b \x01(\bH\x06R\tencrypted\x88\x01\x01\x12\x1b\n" +
+	"\x06parent\x18\t \x01(\tH\aR\x06parent\x88\x01\x01\x12$\n" +
+	"\vdriver_mode\x18\n" +
+	" \x01(\tH\bR\n" +
+	"driverMode\x88\x01\x01\x12\x1d\n" +
+	"\agateway\x18\v \x01(\tH\tR\agateway\x88\x01\x01B\x0f\n" +
 	"\r_subnet_rangeB\n" +
 	"\n" +
 	"\b_min_ipsB\t\n" +
-	"\a_driver\"\xc6\x01\n" +
+	"\a_driverB\x06\n" +
+	"\x04_mtuB\r\n" +
+	"\v_enable_iccB\x17\n" +
+	"\x15_enable_ip_masqueradeB\f\n" +
+	"\n" +
+	"_encryptedB\t\n" +
+	"\a_parentB\x0e\n" +
+	"\f_driver_modeB\n" +
+	"\n" +
+	"\b_gateway\"\xab\x02\n" +
 	"\x15AcquireNetworkRequest\x12!\n" +
 	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12=\n" +
 	"\x0enetwork_config\x18\x02 \x01(\v2\x16.bastion.NetworkConfigR\rnetworkConfig\x123\n" +
-	"\x13lease_duration_secs\x18\x03 \x01(\rH\x00R\x11leaseDurationSecs\x88\x01\x01B\x16\n" +
-	"\x14_lease_duration_secs\"\x83\x02\n" +
+	"\x13lease_duration_secs\x18\x03 \x01(\rH\x00R\x11leaseDurationSecs\x88\x01\x01\x12(\n" +
+	"\rpartition_key\x18\x04 \x01(\tH\x01R\fpartitionKey\x88\x01\x01\x12'\n" +
+	"\x0fexclude_subnets\x18\x05 \x03(\tR\x0eexcludeSubnetsB\x16\n" +
+	"\x14_lease_duration_secsB\x10\n" +
+	"\x0e_partition_key\"\x83\x02\n" +
 	"\x16AcquireNetworkResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12&\n" +
@@ -1160,19 +3650,32 @@ const file_internal_bastion_proto_bastion_proto_rawDesc = "" +
 	"\x06_errorB\x0f\n" +
 	"\r_network_nameB\r\n" +
 	"\v_network_idB\t\n" +
-	"\a_subnet\"\x99\x01\n" +
+	"\a_subnet\"\xd4\x01\n" +
 	"\x15ReleaseNetworkRequest\x12!\n" +
 	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12!\n" +
 	"\fnetwork_name\x18\x02 \x01(\tR\vnetworkName\x12(\n" +
-	"\rforce_cleanup\x18\x03 \x01(\bH\x00R\fforceCleanup\x88\x01\x01B\x10\n" +
-	"\x0e_force_cleanup\"v\n" +
+	"\rforce_cleanup\x18\x03 \x01(\bH\x00R\fforceCleanup\x88\x01\x01\x12'\n" +
+	"\rpool_ttl_secs\x18\x04 \x01(\rH\x01R\vpoolTtlSecs\x88\x01\x01B\x10\n" +
+	"\x0e_force_cleanupB\x10\n" +
+	"\x0e_pool_ttl_secs\"v\n" +
 	"\x16ReleaseNetworkResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12\x1d\n" +
 	"\n" +
 	"cleaned_up\x18\x03 \x01(\bR\tcleanedUpB\b\n" +
+	"\x06_error\"\x93\x01\n" +
+	"\x18RenewNetworkLeaseRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12!\n" +
+	"\fnetwork_name\x18\x02 \x01(\tR\vnetworkName\x12\"\n" +
+	"\n" +
+	"lease_secs\x18\x03 \x01(\rH\x00R\tleaseSecs\x88\x01\x01B\r\n" +
+	"\v_lease_secs\"\x84\x01\n" +
+	"\x19RenewNetworkLeaseResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12(\n" +
+	"\x10lease_expires_at\x18\x03 \x01(\x03R\x0eleaseExpiresAtB\b\n" +
 	"\x06_error\"\x15\n" +
-	"\x13NetworkStatsRequest\"\xc4\x02\n" +
+	"\x13NetworkStatsRequest\"\xe1\b\n" +
 	"\x14NetworkStatsResponse\x12%\n" +
 	"\x0etotal_networks\x18\x01 \x01(\rR\rtotalNetworks\x12'\n" +
 	"\x0factive_networks\x18\x02 \x01(\rR\x0eactiveNetworks\x12'\n" +
@@ -1182,17 +3685,157 @@ const file_internal_bastion_proto_bastion_proto_rawDesc = "" +
 	"\ahealthy\x18\x06 \x01(\bR\ahealthy\x12-\n" +
 	"\x12subnet_utilization\x18\a \x01(\x02R\x11subnetUtilization\x12\x1f\n" +
 	"\vmax_subnets\x18\b \x01(\rR\n" +
-	"maxSubnets2\x9c\x04\n" +
+	"maxSubnets\x12.\n" +
+	"\x13cleanup_queue_depth\x18\t \x01(\rR\x11cleanupQueueDepth\x12+\n" +
+	"\x11cleanup_processed\x18\n" +
+	" \x01(\x04R\x10cleanupProcessed\x12%\n" +
+	"\x0ecleanup_failed\x18\v \x01(\x04R\rcleanupFailed\x123\n" +
+	"\x16cleanup_avg_latency_ms\x18\f \x01(\x02R\x13cleanupAvgLatencyMs\x123\n" +
+	"\x16cleanup_max_latency_ms\x18\r \x01(\x02R\x13cleanupMaxLatencyMs\x12%\n" +
+	"\x0echains_drifted\x18\x0e \x01(\x04R\rchainsDrifted\x12'\n" +
+	"\x0fchains_repaired\x18\x0f \x01(\x04R\x0echainsRepaired\x120\n" +
+	"\x14chain_repairs_failed\x18\x10 \x01(\x04R\x12chainRepairsFailed\x12%\n" +
+	"\x0eunknown_chains\x18\x11 \x01(\rR\runknownChains\x126\n" +
+	"\x17orphaned_chains_removed\x18\x12 \x01(\x04R\x15orphanedChainsRemoved\x120\n" +
+	"\aprewarm\x18\x13 \x03(\v2\x16.bastion.PrewarmStatusR\aprewarm\x12!\n" +
+	"\fmax_networks\x18\x14 \x01(\rR\vmaxNetworks\x12+\n" +
+	"\x11network_evictions\x18\x15 \x01(\x04R\x10networkEvictions\x128\n" +
+	"\n" +
+	"partitions\x18\x16 \x03(\v2\x18.bastion.SubnetPartitionR\n" +
+	"partitions\x12>\n" +
+	"\x1bnetworks_removed_externally\x18\x17 \x01(\x04R\x19networksRemovedExternally\x12%\n" +
+	"\x0esuspect_leases\x18\x18 \x01(\rR\rsuspectLeases\x12)\n" +
+	"\x10leases_reclaimed\x18\x19 \x01(\x04R\x0fleasesReclaimed\"W\n" +
+	"\x0fSubnetPartition\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06subnet\x18\x02 \x01(\tR\x06subnet\x12\x1a\n" +
+	"\bnetworks\x18\x03 \x01(\rR\bnetworks\"\x91\x01\n" +
+	"\rPrewarmStatus\x12\x1f\n" +
+	"\vconfig_hash\x18\x01 \x01(\tR\n" +
+	"configHash\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\rR\x06target\x12\x14\n" +
+	"\x05ready\x18\x03 \x01(\rR\x05ready\x12\"\n" +
+	"\n" +
+	"last_error\x18\x04 \x01(\tH\x00R\tlastError\x88\x01\x01B\r\n" +
+	"\v_last_error\"\x8d\x02\n" +
+	"\x13ListNetworksRequest\x12&\n" +
+	"\fcontainer_id\x18\x01 \x01(\tH\x00R\vcontainerId\x88\x01\x01\x12$\n" +
+	"\vconfig_hash\x18\x02 \x01(\tH\x01R\n" +
+	"configHash\x88\x01\x01\x12(\n" +
+	"\rpartition_key\x18\x03 \x01(\tH\x02R\fpartitionKey\x88\x01\x01\x12\x1b\n" +
+	"\x06driver\x18\x04 \x01(\tH\x03R\x06driver\x88\x01\x01\x12\x19\n" +
+	"\x05state\x18\x05 \x01(\tH\x04R\x05state\x88\x01\x01B\x0f\n" +
+	"\r_container_idB\x0e\n" +
+	"\f_config_hashB\x10\n" +
+	"\x0e_partition_keyB\t\n" +
+	"\a_driverB\b\n" +
+	"\x06_state\"\xa2\x04\n" +
+	"\vPoolNetwork\x12!\n" +
+	"\fnetwork_name\x18\x01 \x01(\tR\vnetworkName\x12\x1d\n" +
+	"\n" +
+	"network_id\x18\x02 \x01(\tR\tnetworkId\x12\x16\n" +
+	"\x06subnet\x18\x03 \x01(\tR\x06subnet\x12\x1f\n" +
+	"\vconfig_hash\x18\x04 \x01(\tR\n" +
+	"configHash\x12#\n" +
+	"\rpartition_key\x18\x05 \x01(\tR\fpartitionKey\x12\x16\n" +
+	"\x06driver\x18\x06 \x01(\tR\x06driver\x120\n" +
+	"\x11current_container\x18\a \x01(\tH\x00R\x10currentContainer\x88\x01\x01\x12\x1f\n" +
+	"\vreuse_count\x18\b \x01(\rR\n" +
+	"reuseCount\x12\x18\n" +
+	"\asuspect\x18\t \x01(\bR\asuspect\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\x03R\tcreatedAt\x12-\n" +
+	"\x10last_released_at\x18\v \x01(\x03H\x01R\x0elastReleasedAt\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"cleanup_at\x18\f \x01(\x03H\x02R\tcleanupAt\x88\x01\x01\x12-\n" +
+	"\x10lease_expires_at\x18\r \x01(\x03H\x03R\x0eleaseExpiresAt\x88\x01\x01B\x14\n" +
+	"\x12_current_containerB\x13\n" +
+	"\x11_last_released_atB\r\n" +
+	"\v_cleanup_atB\x13\n" +
+	"\x11_lease_expires_at\"\x87\x01\n" +
+	"\x14ListNetworksResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x120\n" +
+	"\bnetworks\x18\x03 \x03(\v2\x14.bastion.PoolNetworkR\bnetworksB\b\n" +
+	"\x06_error\"7\n" +
+	"\x1cCollectOrphanedChainsRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\"\x87\x01\n" +
+	"\x1dCollectOrphanedChainsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12'\n" +
+	"\x0forphaned_chains\x18\x03 \x03(\tR\x0eorphanedChainsB\b\n" +
+	"\x06_error\"W\n" +
+	"\x13InspectChainRequest\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12!\n" +
+	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\"\x87\x01\n" +
+	"\tChainRule\x12\x16\n" +
+	"\x06family\x18\x01 \x01(\tR\x06family\x12\x12\n" +
+	"\x04rule\x18\x02 \x01(\tR\x04rule\x12\x1d\n" +
+	"\apackets\x18\x03 \x01(\x04H\x00R\apackets\x88\x01\x01\x12\x19\n" +
+	"\x05bytes\x18\x04 \x01(\x04H\x01R\x05bytes\x88\x01\x01B\n" +
+	"\n" +
+	"\b_packetsB\b\n" +
+	"\x06_bytes\"\x97\x01\n" +
+	"\x14InspectChainResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12(\n" +
+	"\x05rules\x18\x03 \x03(\v2\x12.bastion.ChainRuleR\x05rules\x12\x16\n" +
+	"\x06jumped\x18\x04 \x01(\bR\x06jumpedB\b\n" +
+	"\x06_error\"\xa2\x02\n" +
+	"\x14QueryAuditLogRequest\x12&\n" +
+	"\fcontainer_id\x18\x01 \x01(\tH\x00R\vcontainerId\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tH\x01R\tchainName\x88\x01\x01\x12!\n" +
+	"\toperation\x18\x03 \x01(\tH\x02R\toperation\x88\x01\x01\x12\x19\n" +
+	"\x05since\x18\x04 \x01(\x03H\x03R\x05since\x88\x01\x01\x12\x19\n" +
+	"\x05until\x18\x05 \x01(\x03H\x04R\x05until\x88\x01\x01\x12\x19\n" +
+	"\x05limit\x18\x06 \x01(\rH\x05R\x05limit\x88\x01\x01B\x0f\n" +
+	"\r_container_idB\r\n" +
+	"\v_chain_nameB\f\n" +
+	"\n" +
+	"_operationB\b\n" +
+	"\x06_sinceB\b\n" +
+	"\x06_untilB\b\n" +
+	"\x06_limit\"\xd9\x01\n" +
+	"\n" +
+	"AuditEntry\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1c\n" +
+	"\toperation\x18\x02 \x01(\tR\toperation\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x03 \x01(\tR\tchainName\x12!\n" +
+	"\fcontainer_id\x18\x04 \x01(\tR\vcontainerId\x12\x15\n" +
+	"\x06run_id\x18\x05 \x01(\tR\x05runId\x12\x1c\n" +
+	"\tprincipal\x18\x06 \x01(\tR\tprincipal\x12\x18\n" +
+	"\asuccess\x18\a \x01(\bR\asuccess\"\x85\x01\n" +
+	"\x15QueryAuditLogResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12-\n" +
+	"\aentries\x18\x03 \x03(\v2\x13.bastion.AuditEntryR\aentriesB\b\n" +
+	"\x06_error2\x8f\f\n" +
 	"\x0eBastionService\x12E\n" +
 	"\n" +
 	"SetupChain\x12\x1a.bastion.SetupChainRequest\x1a\x1b.bastion.SetupChainResponse\x12E\n" +
 	"\n" +
-	"ApplyRules\x12\x1a.bastion.ApplyRulesRequest\x1a\x1b.bastion.ApplyRulesResponse\x12K\n" +
+	"ApplyRules\x12\x1a.bastion.ApplyRulesRequest\x1a\x1b.bastion.ApplyRulesResponse\x12`\n" +
+	"\x13UpdateNetworkPolicy\x12#.bastion.UpdateNetworkPolicyRequest\x1a$.bastion.UpdateNetworkPolicyResponse\x12H\n" +
+	"\vUpdateRules\x12\x1b.bastion.UpdateRulesRequest\x1a\x1c.bastion.UpdateRulesResponse\x12K\n" +
 	"\fCleanupChain\x12\x1c.bastion.CleanupChainRequest\x1a\x1d.bastion.CleanupChainResponse\x129\n" +
-	"\x06Health\x12\x16.bastion.HealthRequest\x1a\x17.bastion.HealthResponse\x12Q\n" +
+	"\x06Health\x12\x16.bastion.HealthRequest\x1a\x17.bastion.HealthResponse\x12G\n" +
+	"\x0eStreamFlowLogs\x12\x1e.bastion.StreamFlowLogsRequest\x1a\x13.bastion.FlowRecord0\x01\x12I\n" +
+	"\x0eCapturePackets\x12\x1e.bastion.CapturePacketsRequest\x1a\x15.bastion.CaptureChunk0\x01\x12H\n" +
+	"\vExposePorts\x12\x1b.bastion.ExposePortsRequest\x1a\x1c.bastion.ExposePortsResponse\x12W\n" +
+	"\x10SetupPortForward\x12 .bastion.SetupPortForwardRequest\x1a!.bastion.SetupPortForwardResponse\x12`\n" +
+	"\x13TeardownPortForward\x12#.bastion.TeardownPortForwardRequest\x1a$.bastion.TeardownPortForwardResponse\x12Q\n" +
 	"\x0eAcquireNetwork\x12\x1e.bastion.AcquireNetworkRequest\x1a\x1f.bastion.AcquireNetworkResponse\x12Q\n" +
-	"\x0eReleaseNetwork\x12\x1e.bastion.ReleaseNetworkRequest\x1a\x1f.bastion.ReleaseNetworkResponse\x12N\n" +
-	"\x0fGetNetworkStats\x12\x1c.bastion.NetworkStatsRequest\x1a\x1d.bastion.NetworkStatsResponseB:Z8github.com/metorial/fleet/holopod/internal/bastion/protob\x06proto3"
+	"\x0eReleaseNetwork\x12\x1e.bastion.ReleaseNetworkRequest\x1a\x1f.bastion.ReleaseNetworkResponse\x12Z\n" +
+	"\x11RenewNetworkLease\x12!.bastion.RenewNetworkLeaseRequest\x1a\".bastion.RenewNetworkLeaseResponse\x12N\n" +
+	"\x0fGetNetworkStats\x12\x1c.bastion.NetworkStatsRequest\x1a\x1d.bastion.NetworkStatsResponse\x12K\n" +
+	"\fListNetworks\x12\x1c.bastion.ListNetworksRequest\x1a\x1d.bastion.ListNetworksResponse\x12f\n" +
+	"\x15CollectOrphanedChains\x12%.bastion.CollectOrphanedChainsRequest\x1a&.bastion.CollectOrphanedChainsResponse\x12K\n" +
+	"\fInspectChain\x12\x1c.bastion.InspectChainRequest\x1a\x1d.bastion.InspectChainResponse\x12N\n" +
+	"\rQueryAuditLog\x12\x1d.bastion.QueryAuditLogRequest\x1a\x1e.bastion.QueryAuditLogResponseB:Z8github.com/metorial/fleet/holopod/internal/bastion/protob\x06proto3"
 
 var (
 	file_internal_bastion_proto_bastion_proto_rawDescOnce sync.Once
@@ -1206,50 +3849,115 @@ func file_internal_bastion_proto_bastion_proto_rawDescGZIP() []byte {
 	return file_internal_bastion_proto_bastion_proto_rawDescData
 }
 
-var file_internal_bastion_proto_bastion_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_internal_bastion_proto_bastion_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_internal_bastion_proto_bastion_proto_goTypes = []any{
-	(*SetupChainRequest)(nil),      // 0: bastion.SetupChainRequest
-	(*SetupChainResponse)(nil),     // 1: bastion.SetupChainResponse
-	(*ApplyRulesRequest)(nil),      // 2: bastion.ApplyRulesRequest
-	(*ApplyRulesResponse)(nil),     // 3: bastion.ApplyRulesResponse
-	(*CleanupChainRequest)(nil),    // 4: bastion.CleanupChainRequest
-	(*CleanupChainResponse)(nil),   // 5: bastion.CleanupChainResponse
-	(*HealthRequest)(nil),          // 6: bastion.HealthRequest
-	(*HealthResponse)(nil),         // 7: bastion.HealthResponse
-	(*NetworkPolicy)(nil),          // 8: bastion.NetworkPolicy
-	(*NetworkRule)(nil),            // 9: bastion.NetworkRule
-	(*NetworkConfig)(nil),          // 10: bastion.NetworkConfig
-	(*AcquireNetworkRequest)(nil),  // 11: bastion.AcquireNetworkRequest
-	(*AcquireNetworkResponse)(nil), // 12: bastion.AcquireNetworkResponse
-	(*ReleaseNetworkRequest)(nil),  // 13: bastion.ReleaseNetworkRequest
-	(*ReleaseNetworkResponse)(nil), // 14: bastion.ReleaseNetworkResponse
-	(*NetworkStatsRequest)(nil),    // 15: bastion.NetworkStatsRequest
-	(*NetworkStatsResponse)(nil),   // 16: bastion.NetworkStatsResponse
+	(*SetupChainRequest)(nil),             // 0: bastion.SetupChainRequest
+	(*SetupChainResponse)(nil),            // 1: bastion.SetupChainResponse
+	(*ApplyRulesRequest)(nil),             // 2: bastion.ApplyRulesRequest
+	(*ApplyRulesResponse)(nil),            // 3: bastion.ApplyRulesResponse
+	(*UpdateNetworkPolicyRequest)(nil),    // 4: bastion.UpdateNetworkPolicyRequest
+	(*UpdateNetworkPolicyResponse)(nil),   // 5: bastion.UpdateNetworkPolicyResponse
+	(*UpdateRulesRequest)(nil),            // 6: bastion.UpdateRulesRequest
+	(*UpdateRulesResponse)(nil),           // 7: bastion.UpdateRulesResponse
+	(*CleanupChainRequest)(nil),           // 8: bastion.CleanupChainRequest
+	(*CleanupChainResponse)(nil),          // 9: bastion.CleanupChainResponse
+	(*PortMapping)(nil),                   // 10: bastion.PortMapping
+	(*ExposePortsRequest)(nil),            // 11: bastion.ExposePortsRequest
+	(*ExposePortsResponse)(nil),           // 12: bastion.ExposePortsResponse
+	(*SetupPortForwardRequest)(nil),       // 13: bastion.SetupPortForwardRequest
+	(*SetupPortForwardResponse)(nil),      // 14: bastion.SetupPortForwardResponse
+	(*TeardownPortForwardRequest)(nil),    // 15: bastion.TeardownPortForwardRequest
+	(*TeardownPortForwardResponse)(nil),   // 16: bastion.TeardownPortForwardResponse
+	(*HealthRequest)(nil),                 // 17: bastion.HealthRequest
+	(*HealthResponse)(nil),                // 18: bastion.HealthResponse
+	(*StreamFlowLogsRequest)(nil),         // 19: bastion.StreamFlowLogsRequest
+	(*FlowRecord)(nil),                    // 20: bastion.FlowRecord
+	(*CapturePacketsRequest)(nil),         // 21: bastion.CapturePacketsRequest
+	(*CaptureChunk)(nil),                  // 22: bastion.CaptureChunk
+	(*NetworkPolicy)(nil),                 // 23: bastion.NetworkPolicy
+	(*NetworkRule)(nil),                   // 24: bastion.NetworkRule
+	(*NetworkConfig)(nil),                 // 25: bastion.NetworkConfig
+	(*AcquireNetworkRequest)(nil),         // 26: bastion.AcquireNetworkRequest
+	(*AcquireNetworkResponse)(nil),        // 27: bastion.AcquireNetworkResponse
+	(*ReleaseNetworkRequest)(nil),         // 28: bastion.ReleaseNetworkRequest
+	(*ReleaseNetworkResponse)(nil),        // 29: bastion.ReleaseNetworkResponse
+	(*RenewNetworkLeaseRequest)(nil),      // 30: bastion.RenewNetworkLeaseRequest
+	(*RenewNetworkLeaseResponse)(nil),     // 31: bastion.RenewNetworkLeaseResponse
+	(*NetworkStatsRequest)(nil),           // 32: bastion.NetworkStatsRequest
+	(*NetworkStatsResponse)(nil),          // 33: bastion.NetworkStatsResponse
+	(*SubnetPartition)(nil),               // 34: bastion.SubnetPartition
+	(*PrewarmStatus)(nil),                 // 35: bastion.PrewarmStatus
+	(*ListNetworksRequest)(nil),           // 36: bastion.ListNetworksRequest
+	(*PoolNetwork)(nil),                   // 37: bastion.PoolNetwork
+	(*ListNetworksResponse)(nil),          // 38: bastion.ListNetworksResponse
+	(*CollectOrphanedChainsRequest)(nil),  // 39: bastion.CollectOrphanedChainsRequest
+	(*CollectOrphanedChainsResponse)(nil), // 40: bastion.CollectOrphanedChainsResponse
+	(*InspectChainRequest)(nil),           // 41: bastion.InspectChainRequest
+	(*ChainRule)(nil),                     // 42: bastion.ChainRule
+	(*InspectChainResponse)(nil),          // 43: bastion.InspectChainResponse
+	(*QueryAuditLogRequest)(nil),          // 44: bastion.QueryAuditLogRequest
+	(*AuditEntry)(nil),                    // 45: bastion.AuditEntry
+	(*QueryAuditLogResponse)(nil),         // 46: bastion.QueryAuditLogResponse
 }
 var file_internal_bastion_proto_bastion_proto_depIdxs = []int32{
-	8,  // 0: bastion.ApplyRulesRequest.policy:type_name -> bastion.NetworkPolicy
-	9,  // 1: bastion.NetworkPolicy.whitelist:type_name -> bastion.NetworkRule
-	9,  // 2: bastion.NetworkPolicy.blacklist:type_name -> bastion.NetworkRule
-	10, // 3: bastion.AcquireNetworkRequest.network_config:type_name -> bastion.NetworkConfig
-	0,  // 4: bastion.BastionService.SetupChain:input_type -> bastion.SetupChainRequest
-	2,  // 5: bastion.BastionService.ApplyRules:input_type -> bastion.ApplyRulesRequest
-	4,  // 6: bastion.BastionService.CleanupChain:input_type -> bastion.CleanupChainRequest
-	6,  // 7: bastion.BastionService.Health:input_type -> bastion.HealthRequest
-	11, // 8: bastion.BastionService.AcquireNetwork:input_type -> bastion.AcquireNetworkRequest
-	13, // 9: bastion.BastionService.ReleaseNetwork:input_type -> bastion.ReleaseNetworkRequest
-	15, // 10: bastion.BastionService.GetNetworkStats:input_type -> bastion.NetworkStatsRequest
-	1,  // 11: bastion.BastionService.SetupChain:output_type -> bastion.SetupChainResponse
-	3,  // 12: bastion.BastionService.ApplyRules:output_type -> bastion.ApplyRulesResponse
-	5,  // 13: bastion.BastionService.CleanupChain:output_type -> bastion.CleanupChainResponse
-	7,  // 14: bastion.BastionService.Health:output_type -> bastion.HealthResponse
-	12, // 15: bastion.BastionService.AcquireNetwork:output_type -> bastion.AcquireNetworkResponse
-	14, // 16: bastion.BastionService.ReleaseNetwork:output_type -> bastion.ReleaseNetworkResponse
-	16, // 17: bastion.BastionService.GetNetworkStats:output_type -> bastion.NetworkStatsResponse
-	11, // [11:18] is the sub-list for method output_type
-	4,  // [4:11] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	23, // 0: bastion.ApplyRulesRequest.policy:type_name -> bastion.NetworkPolicy
+	23, // 1: bastion.UpdateNetworkPolicyRequest.policy:type_name -> bastion.NetworkPolicy
+	23, // 2: bastion.UpdateRulesRequest.policy:type_name -> bastion.NetworkPolicy
+	10, // 3: bastion.ExposePortsRequest.ports:type_name -> bastion.PortMapping
+	10, // 4: bastion.ExposePortsResponse.ports:type_name -> bastion.PortMapping
+	10, // 5: bastion.SetupPortForwardRequest.mapping:type_name -> bastion.PortMapping
+	10, // 6: bastion.SetupPortForwardResponse.mapping:type_name -> bastion.PortMapping
+	24, // 7: bastion.NetworkPolicy.whitelist:type_name -> bastion.NetworkRule
+	24, // 8: bastion.NetworkPolicy.blacklist:type_name -> bastion.NetworkRule
+	25, // 9: bastion.AcquireNetworkRequest.network_config:type_name -> bastion.NetworkConfig
+	35, // 10: bastion.NetworkStatsResponse.prewarm:type_name -> bastion.PrewarmStatus
+	34, // 11: bastion.NetworkStatsResponse.partitions:type_name -> bastion.SubnetPartition
+	37, // 12: bastion.ListNetworksResponse.networks:type_name -> bastion.PoolNetwork
+	42, // 13: bastion.InspectChainResponse.rules:type_name -> bastion.ChainRule
+	45, // 14: bastion.QueryAuditLogResponse.entries:type_name -> bastion.AuditEntry
+	0,  // 15: bastion.BastionService.SetupChain:input_type -> bastion.SetupChainRequest
+	2,  // 16: bastion.BastionService.ApplyRules:input_type -> bastion.ApplyRulesRequest
+	4,  // 17: bastion.BastionService.UpdateNetworkPolicy:input_type -> bastion.UpdateNetworkPolicyRequest
+	6,  // 18: bastion.BastionService.UpdateRules:input_type -> bastion.UpdateRulesRequest
+	8,  // 19: bastion.BastionService.CleanupChain:input_type -> bastion.CleanupChainRequest
+	17, // 20: bastion.BastionService.Health:input_type -> bastion.HealthRequest
+	19, // 21: bastion.BastionService.StreamFlowLogs:input_type -> bastion.StreamFlowLogsRequest
+	21, // 22: bastion.BastionService.CapturePackets:input_type -> bastion.CapturePacketsRequest
+	11, // 23: bastion.BastionService.ExposePorts:input_type -> bastion.ExposePortsRequest
+	13, // 24: bastion.BastionService.SetupPortForward:input_type -> bastion.SetupPortForwardRequest
+	15, // 25: bastion.BastionService.TeardownPortForward:input_type -> bastion.TeardownPortForwardRequest
+	26, // 26: bastion.BastionService.AcquireNetwork:input_type -> bastion.AcquireNetworkRequest
+	28, // 27: bastion.BastionService.ReleaseNetwork:input_type -> bastion.ReleaseNetworkRequest
+	30, // 28: bastion.BastionService.RenewNetworkLease:input_type -> bastion.RenewNetworkLeaseRequest
+	32, // 29: bastion.BastionService.GetNetworkStats:input_type -> bastion.NetworkStatsRequest
+	36, // 30: bastion.BastionService.ListNetworks:input_type -> bastion.ListNetworksRequest
+	39, // 31: bastion.BastionService.CollectOrphanedChains:input_type -> bastion.CollectOrphanedChainsRequest
+	41, // 32: bastion.BastionService.InspectChain:input_type -> bastion.InspectChainRequest
+	44, // 33: bastion.BastionService.QueryAuditLog:input_type -> bastion.QueryAuditLogRequest
+	1,  // 34: bastion.BastionService.SetupChain:output_type -> bastion.SetupChainResponse
+	3,  // 35: bastion.BastionService.ApplyRules:output_type -> bastion.ApplyRulesResponse
+	5,  // 36: bastion.BastionService.UpdateNetworkPolicy:output_type -> bastion.UpdateNetworkPolicyResponse
+	7,  // 37: bastion.BastionService.UpdateRules:output_type -> bastion.UpdateRulesResponse
+	9,  // 38: bastion.BastionService.CleanupChain:output_type -> bastion.CleanupChainResponse
+	18, // 39: bastion.BastionService.Health:output_type -> bastion.HealthResponse
+	20, // 40: bastion.BastionService.StreamFlowLogs:output_type -> bastion.FlowRecord
+	22, // 41: bastion.BastionService.CapturePackets:output_type -> bastion.CaptureChunk
+	12, // 42: bastion.BastionService.ExposePorts:output_type -> bastion.ExposePortsResponse
+	14, // 43: bastion.BastionService.SetupPortForward:output_type -> bastion.SetupPortForwardResponse
+	16, // 44: bastion.BastionService.TeardownPortForward:output_type -> bastion.TeardownPortForwardResponse
+	27, // 45: bastion.BastionService.AcquireNetwork:output_type -> bastion.AcquireNetworkResponse
+	29, // 46: bastion.BastionService.ReleaseNetwork:output_type -> bastion.ReleaseNetworkResponse
+	31, // 47: bastion.BastionService.RenewNetworkLease:output_type -> bastion.RenewNetworkLeaseResponse
+	33, // 48: bastion.BastionService.GetNetworkStats:output_type -> bastion.NetworkStatsResponse
+	38, // 49: bastion.BastionService.ListNetworks:output_type -> bastion.ListNetworksResponse
+	40, // 50: bastion.BastionService.CollectOrphanedChains:output_type -> bastion.CollectOrphanedChainsResponse
+	43, // 51: bastion.BastionService.InspectChain:output_type -> bastion.InspectChainResponse
+	46, // 52: bastion.BastionService.QueryAuditLog:output_type -> bastion.QueryAuditLogResponse
+	34, // [34:53] is the sub-list for method output_type
+	15, // [15:34] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_internal_bastion_proto_bastion_proto_init() }
@@ -1260,19 +3968,38 @@ func file_internal_bastion_proto_bastion_proto_init() {
 	file_internal_bastion_proto_bastion_proto_msgTypes[1].OneofWrappers = []any{}
 	file_internal_bastion_proto_bastion_proto_msgTypes[3].OneofWrappers = []any{}
 	file_internal_bastion_proto_bastion_proto_msgTypes[5].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[7].OneofWrappers = []any{}
 	file_internal_bastion_proto_bastion_proto_msgTypes[9].OneofWrappers = []any{}
-	file_internal_bastion_proto_bastion_proto_msgTypes[10].OneofWrappers = []any{}
-	file_internal_bastion_proto_bastion_proto_msgTypes[11].OneofWrappers = []any{}
 	file_internal_bastion_proto_bastion_proto_msgTypes[12].OneofWrappers = []any{}
-	file_internal_bastion_proto_bastion_proto_msgTypes[13].OneofWrappers = []any{}
 	file_internal_bastion_proto_bastion_proto_msgTypes[14].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[16].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[18].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[21].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[23].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[24].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[25].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[26].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[27].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[28].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[29].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[30].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[31].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[35].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[36].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[37].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[38].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[40].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[42].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[43].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[44].OneofWrappers = []any{}
+	file_internal_bastion_proto_bastion_proto_msgTypes[46].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_bastion_proto_bastion_proto_rawDesc), len(file_internal_bastion_proto_bastion_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   17,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
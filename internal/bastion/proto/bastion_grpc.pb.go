@@ -19,13 +19,25 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	BastionService_SetupChain_FullMethodName      = "/bastion.BastionService/SetupChain"
-	BastionService_ApplyRules_FullMethodName      = "/bastion.BastionService/ApplyRules"
-	BastionService_CleanupChain_FullMethodName    = "/bastion.BastionService/CleanupChain"
-	BastionService_Health_FullMethodName          = "/bastion.BastionService/Health"
-	BastionService_AcquireNetwork_FullMethodName  = "/bastion.BastionService/AcquireNetwork"
-	BastionService_ReleaseNetwork_FullMethodName  = "/bastion.BastionService/ReleaseNetwork"
-	BastionService_GetNetworkStats_FullMethodName = "/bastion.BastionService/GetNetworkStats"
+	BastionService_SetupChain_FullMethodName            = "/bastion.BastionService/SetupChain"
+	BastionService_ApplyRules_FullMethodName            = "/bastion.BastionService/ApplyRules"
+	BastionService_UpdateNetworkPolicy_FullMethodName   = "/bastion.BastionService/UpdateNetworkPolicy"
+	BastionService_UpdateRules_FullMethodName           = "/bastion.BastionService/UpdateRules"
+	BastionService_CleanupChain_FullMethodName          = "/bastion.BastionService/CleanupChain"
+	BastionService_Health_FullMethodName                = "/bastion.BastionService/Health"
+	BastionService_StreamFlowLogs_FullMethodName        = "/bastion.BastionService/StreamFlowLogs"
+	BastionService_CapturePackets_FullMethodName        = "/bastion.BastionService/CapturePackets"
+	BastionService_ExposePorts_FullMethodName           = "/bastion.BastionService/ExposePorts"
+	BastionService_SetupPortForward_FullMethodName      = "/bastion.BastionService/SetupPortForward"
+	BastionService_TeardownPortForward_FullMethodName   = "/bastion.BastionService/TeardownPortForward"
+	BastionService_AcquireNetwork_FullMethodName        = "/bastion.BastionService/AcquireNetwork"
+	BastionService_ReleaseNetwork_FullMethodName        = "/bastion.BastionService/ReleaseNetwork"
+	BastionService_RenewNetworkLease_FullMethodName     = "/bastion.BastionService/RenewNetworkLease"
+	BastionService_GetNetworkStats_FullMethodName       = "/bastion.BastionService/GetNetworkStats"
+	BastionService_ListNetworks_FullMethodName          = "/bastion.BastionService/ListNetworks"
+	BastionService_CollectOrphanedChains_FullMethodName = "/bastion.BastionService/CollectOrphanedChains"
+	BastionService_InspectChain_FullMethodName          = "/bastion.BastionService/InspectChain"
+	BastionService_QueryAuditLog_FullMethodName         = "/bastion.BastionService/QueryAuditLog"
 )
 
 // BastionServiceClient is the client API for BastionService service.
@@ -34,12 +46,38 @@ const (
 type BastionServiceClient interface {
 	SetupChain(ctx context.Context, in *SetupChainRequest, opts ...grpc.CallOption) (*SetupChainResponse, error)
 	ApplyRules(ctx context.Context, in *ApplyRulesRequest, opts ...grpc.CallOption) (*ApplyRulesResponse, error)
+	// Atomically replace the rules of an existing chain, keeping published ports
+	UpdateNetworkPolicy(ctx context.Context, in *UpdateNetworkPolicyRequest, opts ...grpc.CallOption) (*UpdateNetworkPolicyResponse, error)
+	// Change the rules of an existing chain in place, adding and removing only
+	// those that differ from the policy last applied
+	UpdateRules(ctx context.Context, in *UpdateRulesRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error)
 	CleanupChain(ctx context.Context, in *CleanupChainRequest, opts ...grpc.CallOption) (*CleanupChainResponse, error)
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	// Stream logged connection attempts for a chain (requires policy.log_attempts)
+	StreamFlowLogs(ctx context.Context, in *StreamFlowLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FlowRecord], error)
+	// Capture a container's traffic with tcpdump, streamed as pcap bytes
+	CapturePackets(ctx context.Context, in *CapturePacketsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CaptureChunk], error)
+	// Publish container ports on the host through DNAT; removed by CleanupChain
+	ExposePorts(ctx context.Context, in *ExposePortsRequest, opts ...grpc.CallOption) (*ExposePortsResponse, error)
+	// Forward a single host port to a container port, and remove one again
+	// ahead of CleanupChain
+	SetupPortForward(ctx context.Context, in *SetupPortForwardRequest, opts ...grpc.CallOption) (*SetupPortForwardResponse, error)
+	TeardownPortForward(ctx context.Context, in *TeardownPortForwardRequest, opts ...grpc.CallOption) (*TeardownPortForwardResponse, error)
 	// Network pool management
 	AcquireNetwork(ctx context.Context, in *AcquireNetworkRequest, opts ...grpc.CallOption) (*AcquireNetworkResponse, error)
 	ReleaseNetwork(ctx context.Context, in *ReleaseNetworkRequest, opts ...grpc.CallOption) (*ReleaseNetworkResponse, error)
+	// Extend the lease on an acquired network. Networks held past their lease
+	// (BASTION_POOL_LEASE) are reclaimed once their container is gone.
+	RenewNetworkLease(ctx context.Context, in *RenewNetworkLeaseRequest, opts ...grpc.CallOption) (*RenewNetworkLeaseResponse, error)
 	GetNetworkStats(ctx context.Context, in *NetworkStatsRequest, opts ...grpc.CallOption) (*NetworkStatsResponse, error)
+	// List the networks the pool holds, for operators (admin token only)
+	ListNetworks(ctx context.Context, in *ListNetworksRequest, opts ...grpc.CallOption) (*ListNetworksResponse, error)
+	// Remove chains whose container is no longer running; also runs periodically
+	CollectOrphanedChains(ctx context.Context, in *CollectOrphanedChainsRequest, opts ...grpc.CallOption) (*CollectOrphanedChainsResponse, error)
+	// List the rules installed in a chain with their packet and byte counters
+	InspectChain(ctx context.Context, in *InspectChainRequest, opts ...grpc.CallOption) (*InspectChainResponse, error)
+	// Search the audit log kept on disk, for looking back over past operations
+	QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error)
 }
 
 type bastionServiceClient struct {
@@ -70,6 +108,26 @@ func (c *bastionServiceClient) ApplyRules(ctx context.Context, in *ApplyRulesReq
 	return out, nil
 }
 
+func (c *bastionServiceClient) UpdateNetworkPolicy(ctx context.Context, in *UpdateNetworkPolicyRequest, opts ...grpc.CallOption) (*UpdateNetworkPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateNetworkPolicyResponse)
+	err := c.cc.Invoke(ctx, BastionService_UpdateNetworkPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bastionServiceClient) UpdateRules(ctx context.Context, in *UpdateRulesRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, BastionService_UpdateRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *bastionServiceClient) CleanupChain(ctx context.Context, in *CleanupChainRequest, opts ...grpc.CallOption) (*CleanupChainResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CleanupChainResponse)
@@ -90,6 +148,74 @@ func (c *bastionServiceClient) Health(ctx context.Context, in *HealthRequest, op
 	return out, nil
 }
 
+func (c *bastionServiceClient) StreamFlowLogs(ctx context.Context, in *StreamFlowLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FlowRecord], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BastionService_ServiceDesc.Streams[0], BastionService_StreamFlowLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamFlowLogsRequest, FlowRecord]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BastionService_StreamFlowLogsClient = grpc.ServerStreamingClient[FlowRecord]
+
+func (c *bastionServiceClient) CapturePackets(ctx context.Context, in *CapturePacketsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CaptureChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BastionService_ServiceDesc.Streams[1], BastionService_CapturePackets_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CapturePacketsRequest, CaptureChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BastionService_CapturePacketsClient = grpc.ServerStreamingClient[CaptureChunk]
+
+func (c *bastionServiceClient) ExposePorts(ctx context.Context, in *ExposePortsRequest, opts ...grpc.CallOption) (*ExposePortsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExposePortsResponse)
+	err := c.cc.Invoke(ctx, BastionService_ExposePorts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bastionServiceClient) SetupPortForward(ctx context.Context, in *SetupPortForwardRequest, opts ...grpc.CallOption) (*SetupPortForwardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetupPortForwardResponse)
+	err := c.cc.Invoke(ctx, BastionService_SetupPortForward_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bastionServiceClient) TeardownPortForward(ctx context.Context, in *TeardownPortForwardRequest, opts ...grpc.CallOption) (*TeardownPortForwardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TeardownPortForwardResponse)
+	err := c.cc.Invoke(ctx, BastionService_TeardownPortForward_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *bastionServiceClient) AcquireNetwork(ctx context.Context, in *AcquireNetworkRequest, opts ...grpc.CallOption) (*AcquireNetworkResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AcquireNetworkResponse)
@@ -110,6 +236,16 @@ func (c *bastionServiceClient) ReleaseNetwork(ctx context.Context, in *ReleaseNe
 	return out, nil
 }
 
+func (c *bastionServiceClient) RenewNetworkLease(ctx context.Context, in *RenewNetworkLeaseRequest, opts ...grpc.CallOption) (*RenewNetworkLeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenewNetworkLeaseResponse)
+	err := c.cc.Invoke(ctx, BastionService_RenewNetworkLease_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *bastionServiceClient) GetNetworkStats(ctx context.Context, in *NetworkStatsRequest, opts ...grpc.CallOption) (*NetworkStatsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(NetworkStatsResponse)
@@ -120,18 +256,84 @@ func (c *bastionServiceClient) GetNetworkStats(ctx context.Context, in *NetworkS
 	return out, nil
 }
 
+func (c *bastionServiceClient) ListNetworks(ctx context.Context, in *ListNetworksRequest, opts ...grpc.CallOption) (*ListNetworksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNetworksResponse)
+	err := c.cc.Invoke(ctx, BastionService_ListNetworks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bastionServiceClient) CollectOrphanedChains(ctx context.Context, in *CollectOrphanedChainsRequest, opts ...grpc.CallOption) (*CollectOrphanedChainsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectOrphanedChainsResponse)
+	err := c.cc.Invoke(ctx, BastionService_CollectOrphanedChains_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bastionServiceClient) InspectChain(ctx context.Context, in *InspectChainRequest, opts ...grpc.CallOption) (*InspectChainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectChainResponse)
+	err := c.cc.Invoke(ctx, BastionService_InspectChain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bastionServiceClient) QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryAuditLogResponse)
+	err := c.cc.Invoke(ctx, BastionService_QueryAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BastionServiceServer is the server API for BastionService service.
 // All implementations must embed UnimplementedBastionServiceServer
 // for forward compatibility.
 type BastionServiceServer interface {
 	SetupChain(context.Context, *SetupChainRequest) (*SetupChainResponse, error)
 	ApplyRules(context.Context, *ApplyRulesRequest) (*ApplyRulesResponse, error)
+	// Atomically replace the rules of an existing chain, keeping published ports
+	UpdateNetworkPolicy(context.Context, *UpdateNetworkPolicyRequest) (*UpdateNetworkPolicyResponse, error)
+	// Change the rules of an existing chain in place, adding and removing only
+	// those that differ from the policy last applied
+	UpdateRules(context.Context, *UpdateRulesRequest) (*UpdateRulesResponse, error)
 	CleanupChain(context.Context, *CleanupChainRequest) (*CleanupChainResponse, error)
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	// Stream logged connection attempts for a chain (requires policy.log_attempts)
+	StreamFlowLogs(*StreamFlowLogsRequest, grpc.ServerStreamingServer[FlowRecord]) error
+	// Capture a container's traffic with tcpdump, streamed as pcap bytes
+	CapturePackets(*CapturePacketsRequest, grpc.ServerStreamingServer[CaptureChunk]) error
+	// Publish container ports on the host through DNAT; removed by CleanupChain
+	ExposePorts(context.Context, *ExposePortsRequest) (*ExposePortsResponse, error)
+	// Forward a single host port to a container port, and remove one again
+	// ahead of CleanupChain
+	SetupPortForward(context.Context, *SetupPortForwardRequest) (*SetupPortForwardResponse, error)
+	TeardownPortForward(context.Context, *TeardownPortForwardRequest) (*TeardownPortForwardResponse, error)
 	// Network pool management
 	AcquireNetwork(context.Context, *AcquireNetworkRequest) (*AcquireNetworkResponse, error)
 	ReleaseNetwork(context.Context, *ReleaseNetworkRequest) (*ReleaseNetworkResponse, error)
+	// Extend the lease on an acquired network. Networks held past their lease
+	// (BASTION_POOL_LEASE) are reclaimed once their container is gone.
+	RenewNetworkLease(context.Context, *RenewNetworkLeaseRequest) (*RenewNetworkLeaseResponse, error)
 	GetNetworkStats(context.Context, *NetworkStatsRequest) (*NetworkStatsResponse, error)
+	// List the networks the pool holds, for operators (admin token only)
+	ListNetworks(context.Context, *ListNetworksRequest) (*ListNetworksResponse, error)
+	// Remove chains whose container is no longer running; also runs periodically
+	CollectOrphanedChains(context.Context, *CollectOrphanedChainsRequest) (*CollectOrphanedChainsResponse, error)
+	// List the rules installed in a chain with their packet and byte counters
+	InspectChain(context.Context, *InspectChainRequest) (*InspectChainResponse, error)
+	// Search the audit log kept on disk, for looking back over past operations
+	QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error)
 	mustEmbedUnimplementedBastionServiceServer()
 }
 
@@ -148,21 +350,57 @@ func (UnimplementedBastionServiceServer) SetupChain(context.Context, *SetupChain
 func (UnimplementedBastionServiceServer) ApplyRules(context.Context, *ApplyRulesRequest) (*ApplyRulesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ApplyRules not implemented")
 }
+func (UnimplementedBastionServiceServer) UpdateNetworkPolicy(context.Context, *UpdateNetworkPolicyRequest) (*UpdateNetworkPolicyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateNetworkPolicy not implemented")
+}
+func (UnimplementedBastionServiceServer) UpdateRules(context.Context, *UpdateRulesRequest) (*UpdateRulesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateRules not implemented")
+}
 func (UnimplementedBastionServiceServer) CleanupChain(context.Context, *CleanupChainRequest) (*CleanupChainResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CleanupChain not implemented")
 }
 func (UnimplementedBastionServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
 }
+func (UnimplementedBastionServiceServer) StreamFlowLogs(*StreamFlowLogsRequest, grpc.ServerStreamingServer[FlowRecord]) error {
+	return status.Error(codes.Unimplemented, "method StreamFlowLogs not implemented")
+}
+func (UnimplementedBastionServiceServer) CapturePackets(*CapturePacketsRequest, grpc.ServerStreamingServer[CaptureChunk]) error {
+	return status.Error(codes.Unimplemented, "method CapturePackets not implemented")
+}
+func (UnimplementedBastionServiceServer) ExposePorts(context.Context, *ExposePortsRequest) (*ExposePortsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExposePorts not implemented")
+}
+func (UnimplementedBastionServiceServer) SetupPortForward(context.Context, *SetupPortForwardRequest) (*SetupPortForwardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetupPortForward not implemented")
+}
+func (UnimplementedBastionServiceServer) TeardownPortForward(context.Context, *TeardownPortForwardRequest) (*TeardownPortForwardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TeardownPortForward not implemented")
+}
 func (UnimplementedBastionServiceServer) AcquireNetwork(context.Context, *AcquireNetworkRequest) (*AcquireNetworkResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method AcquireNetwork not implemented")
 }
 func (UnimplementedBastionServiceServer) ReleaseNetwork(context.Context, *ReleaseNetworkRequest) (*ReleaseNetworkResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ReleaseNetwork not implemented")
 }
+func (UnimplementedBastionServiceServer) RenewNetworkLease(context.Context, *RenewNetworkLeaseRequest) (*RenewNetworkLeaseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RenewNetworkLease not implemented")
+}
 func (UnimplementedBastionServiceServer) GetNetworkStats(context.Context, *NetworkStatsRequest) (*NetworkStatsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetNetworkStats not implemented")
 }
+func (UnimplementedBastionServiceServer) ListNetworks(context.Context, *ListNetworksRequest) (*ListNetworksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListNetworks not implemented")
+}
+func (UnimplementedBastionServiceServer) CollectOrphanedChains(context.Context, *CollectOrphanedChainsRequest) (*CollectOrphanedChainsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CollectOrphanedChains not implemented")
+}
+func (UnimplementedBastionServiceServer) InspectChain(context.Context, *InspectChainRequest) (*InspectChainResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method InspectChain not implemented")
+}
+func (UnimplementedBastionServiceServer) QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method QueryAuditLog not implemented")
+}
 func (UnimplementedBastionServiceServer) mustEmbedUnimplementedBastionServiceServer() {}
 func (UnimplementedBastionServiceServer) testEmbeddedByValue()                        {}
 
@@ -220,6 +458,42 @@ func _BastionService_ApplyRules_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BastionService_UpdateNetworkPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNetworkPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).UpdateNetworkPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_UpdateNetworkPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).UpdateNetworkPolicy(ctx, req.(*UpdateNetworkPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BastionService_UpdateRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).UpdateRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_UpdateRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).UpdateRules(ctx, req.(*UpdateRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BastionService_CleanupChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CleanupChainRequest)
 	if err := dec(in); err != nil {
@@ -256,6 +530,82 @@ func _BastionService_Health_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BastionService_StreamFlowLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamFlowLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BastionServiceServer).StreamFlowLogs(m, &grpc.GenericServerStream[StreamFlowLogsRequest, FlowRecord]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BastionService_StreamFlowLogsServer = grpc.ServerStreamingServer[FlowRecord]
+
+func _BastionService_CapturePackets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CapturePacketsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BastionServiceServer).CapturePackets(m, &grpc.GenericServerStream[CapturePacketsRequest, CaptureChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BastionService_CapturePacketsServer = grpc.ServerStreamingServer[CaptureChunk]
+
+func _BastionService_ExposePorts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExposePortsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).ExposePorts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_ExposePorts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).ExposePorts(ctx, req.(*ExposePortsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BastionService_SetupPortForward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetupPortForwardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).SetupPortForward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_SetupPortForward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).SetupPortForward(ctx, req.(*SetupPortForwardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BastionService_TeardownPortForward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TeardownPortForwardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).TeardownPortForward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_TeardownPortForward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).TeardownPortForward(ctx, req.(*TeardownPortForwardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BastionService_AcquireNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AcquireNetworkRequest)
 	if err := dec(in); err != nil {
@@ -292,6 +642,24 @@ func _BastionService_ReleaseNetwork_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BastionService_RenewNetworkLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewNetworkLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).RenewNetworkLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_RenewNetworkLease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).RenewNetworkLease(ctx, req.(*RenewNetworkLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BastionService_GetNetworkStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(NetworkStatsRequest)
 	if err := dec(in); err != nil {
@@ -310,6 +678,78 @@ func _BastionService_GetNetworkStats_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BastionService_ListNetworks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNetworksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).ListNetworks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_ListNetworks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).ListNetworks(ctx, req.(*ListNetworksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BastionService_CollectOrphanedChains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectOrphanedChainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).CollectOrphanedChains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_CollectOrphanedChains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).CollectOrphanedChains(ctx, req.(*CollectOrphanedChainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BastionService_InspectChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectChainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).InspectChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_InspectChain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).InspectChain(ctx, req.(*InspectChainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BastionService_QueryAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BastionServiceServer).QueryAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BastionService_QueryAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BastionServiceServer).QueryAuditLog(ctx, req.(*QueryAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BastionService_ServiceDesc is the grpc.ServiceDesc for BastionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -325,6 +765,14 @@ var BastionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ApplyRules",
 			Handler:    _BastionService_ApplyRules_Handler,
 		},
+		{
+			MethodName: "UpdateNetworkPolicy",
+			Handler:    _BastionService_UpdateNetworkPolicy_Handler,
+		},
+		{
+			MethodName: "UpdateRules",
+			Handler:    _BastionService_UpdateRules_Handler,
+		},
 		{
 			MethodName: "CleanupChain",
 			Handler:    _BastionService_CleanupChain_Handler,
@@ -333,6 +781,18 @@ var BastionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Health",
 			Handler:    _BastionService_Health_Handler,
 		},
+		{
+			MethodName: "ExposePorts",
+			Handler:    _BastionService_ExposePorts_Handler,
+		},
+		{
+			MethodName: "SetupPortForward",
+			Handler:    _BastionService_SetupPortForward_Handler,
+		},
+		{
+			MethodName: "TeardownPortForward",
+			Handler:    _BastionService_TeardownPortForward_Handler,
+		},
 		{
 			MethodName: "AcquireNetwork",
 			Handler:    _BastionService_AcquireNetwork_Handler,
@@ -341,11 +801,42 @@ var BastionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReleaseNetwork",
 			Handler:    _BastionService_ReleaseNetwork_Handler,
 		},
+		{
+			MethodName: "RenewNetworkLease",
+			Handler:    _BastionService_RenewNetworkLease_Handler,
+		},
 		{
 			MethodName: "GetNetworkStats",
 			Handler:    _BastionService_GetNetworkStats_Handler,
 		},
+		{
+			MethodName: "ListNetworks",
+			Handler:    _BastionService_ListNetworks_Handler,
+		},
+		{
+			MethodName: "CollectOrphanedChains",
+			Handler:    _BastionService_CollectOrphanedChains_Handler,
+		},
+		{
+			MethodName: "InspectChain",
+			Handler:    _BastionService_InspectChain_Handler,
+		},
+		{
+			MethodName: "QueryAuditLog",
+			Handler:    _BastionService_QueryAuditLog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFlowLogs",
+			Handler:       _BastionService_StreamFlowLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "CapturePackets",
+			Handler:       _BastionService_CapturePackets_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/bastion/proto/bastion.proto",
 }
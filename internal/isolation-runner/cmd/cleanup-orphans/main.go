@@ -11,6 +11,9 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/lifecycle"
 )
 
 const (
@@ -27,6 +30,15 @@ func main() {
 	}
 	defer docker.Close()
 
+	// Finish the cleanup of runners that were killed before they could, which
+	// also covers their networks and chains
+	recovered, err := lifecycle.RecoverFromRecords(ctx, docker, config.GetStateDir())
+	if err != nil {
+		log.Printf("Failed to recover from resource records: %v", err)
+	} else if recovered > 0 {
+		fmt.Printf("Recovered %d interrupted runs from resource records\n", recovered)
+	}
+
 	// Find all containers managed by isolation-runner
 	filterArgs := filters.NewArgs()
 	filterArgs.Add("label", "managed-by=isolation-runner")
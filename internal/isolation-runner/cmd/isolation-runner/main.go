@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
-	"strings"
+	"path/filepath"
 	"syscall"
 	"time"
 
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/bastion"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/container"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/diag"
 	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/heartbeat"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/lifecycle"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/logfile"
 )
 
 var version = "dev"
@@ -21,10 +28,12 @@ func main() {
 	// CRITICAL: Ensure cleanup always runs, even on panic
 	var tracker *lifecycle.ResourceTracker
 	var exitCode int
+	var errorCode string
 
 	defer func() {
 		if r := recover(); r != nil {
-			jsonmsg.Error(fmt.Sprintf("PANIC: isolation-runner crashed: %v", r))
+			errorCode = string(ierrors.KindInternal)
+			jsonmsg.ErrorWithCode(fmt.Sprintf("PANIC: isolation-runner crashed: %v", r), errorCode)
 			exitCode = int(ierrors.ExitRuntimeError)
 		}
 
@@ -37,7 +46,7 @@ func main() {
 		}
 
 		if exitCode != 0 {
-			jsonmsg.ContainerExit(exitCode)
+			jsonmsg.ContainerExit(exitCode, errorCode)
 		}
 	}()
 
@@ -46,14 +55,79 @@ func main() {
 }
 
 func run() (int, *lifecycle.ResourceTracker) {
-	input, err := config.ReadInputFromStdin()
+	// The config normally arrives as the first line of stdin, which then
+	// carries control commands. Read from a file or descriptor instead, stdin
+	// is left to the workload.
+	configFile := flag.String("config-file", "", "read the config from this file instead of stdin")
+	configFD := flag.Int("config-fd", -1, "read the config from this inherited file descriptor instead of stdin")
+	flag.Parse()
+
+	jsonmsg.RunnerHello(version, config.SupportedSchemaVersions)
+	stopHeartbeat := heartbeat.Start(config.GetHeartbeatInterval())
+	defer stopHeartbeat()
+
+	var input *config.ContainerInput
+	var err error
+	switch {
+	case *configFile != "" && *configFD >= 0:
+		err = ierrors.NewConfigError("--config-file and --config-fd are mutually exclusive", nil)
+	case *configFile != "":
+		input, err = config.ReadInputFromFile(*configFile)
+	case *configFD >= 0:
+		input, err = config.ReadInputFromFD(*configFD)
+	default:
+		input, err = config.ReadInputFromStdin()
+	}
 	if err != nil {
-		jsonmsg.Error(fmt.Sprintf("Failed to read input: %v", err))
-		jsonmsg.ContainerExit(1)
-		return 1, nil
+		var schemaErr *config.SchemaVersionError
+		var malformedErr *config.MalformedInputError
+		switch {
+		case errors.As(err, &schemaErr):
+			jsonmsg.ConfigRejected("unsupported_schema_version", err.Error(), schemaErr.Version, config.SupportedSchemaVersions)
+		case errors.As(err, &malformedErr):
+			jsonmsg.ConfigRejected("malformed_config", err.Error(), 0, config.SupportedSchemaVersions)
+		}
+		exitCode := ierrors.GetExitCode(err)
+		errorCode := string(ierrors.KindOf(err))
+		jsonmsg.ErrorWithCode(fmt.Sprintf("Failed to read input: %v", err), errorCode)
+		jsonmsg.ContainerExit(exitCode, errorCode)
+		return exitCode, nil
+	}
+
+	if input.RunID != "" {
+		jsonmsg.SetRunID(input.RunID)
+		bastion.SetRunID(input.RunID)
 	}
 
 	cfg := &input.Config
+	cfg.Execution.RawStdin = *configFile != "" || *configFD >= 0
+
+	if cfg.Execution.Quiet {
+		jsonmsg.SetLevel(jsonmsg.LevelNone)
+	} else if level, err := jsonmsg.ParseLevel(cfg.Logging.LogLevel); err != nil {
+		jsonmsg.Warning(fmt.Sprintf("%v, logging at info", err))
+	} else {
+		jsonmsg.SetLevel(level)
+	}
+
+	stopDiagnostics, err := diag.StartListenerFromEnv()
+	if err != nil {
+		jsonmsg.Warning(fmt.Sprintf("Runner diagnostics disabled: %v", err))
+	} else {
+		defer stopDiagnostics()
+	}
+	defer diag.StartSelfStats(diag.SelfStatsInterval)()
+
+	// The file is left open until the process exits so the final events reach it
+	if cfg.Logging.LogFile != nil {
+		if err := config.ValidateLogFile(&cfg.Logging); err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Event log file disabled: %v", err))
+		} else if file, err := logfile.Open(*cfg.Logging.LogFile, cfg.Logging.LogFileMaxBytes, cfg.Logging.LogFileMaxBackups); err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Event log file disabled: %v", err))
+		} else {
+			jsonmsg.SetMirror(file)
+		}
+	}
 
 	jsonmsg.Info(fmt.Sprintf("Running on Metorial Holopod v%s", version))
 	jsonmsg.Info(fmt.Sprintf("Image: %s", input.GetImageDisplayName()))
@@ -64,16 +138,11 @@ func run() (int, *lifecycle.ResourceTracker) {
 
 	manager, err := lifecycle.SetupContainer(ctx, input, cfg)
 	if err != nil {
-		jsonmsg.Error(fmt.Sprintf("Failed to setup holopod instance: %v", err))
-		exitCode := getExitCode(err)
-		jsonmsg.ContainerExit(exitCode)
-		// Emit structured event even for setup failures
-		duration := time.Since(startTime)
-		jsonmsg.ContainerExitedWithDetails("unknown", exitCode, duration.String())
-		return exitCode, nil
+		return fail("unknown", startTime, "Failed to setup holopod instance", err), nil
 	}
 
 	tracker := lifecycle.NewResourceTracker(manager.Docker())
+	tracker.PersistTo(filepath.Join(config.GetStateDir(), manager.ContainerName()+".json"))
 
 	initialNetwork := input.GetBridgeName()
 	actualNetwork := manager.NetworkName()
@@ -82,16 +151,19 @@ func run() (int, *lifecycle.ResourceTracker) {
 	tracker.TrackNetwork(actualNetwork, viaBastion)
 
 	containerID := manager.ContainerID()
-	tracker.TrackContainer(containerID, input.GetContainerName())
+	tracker.TrackContainer(containerID, manager.ContainerName())
+	if volume := manager.WorkspaceVolume(); volume != "" {
+		tracker.TrackWorkspaceVolume(volume)
+	}
+
+	if len(cfg.Container.InitContainers) > 0 {
+		if err := lifecycle.RunInitContainers(ctx, manager, cfg, tracker); err != nil {
+			return fail(containerID, startTime, "Init containers failed", err), tracker
+		}
+	}
 
 	if err := manager.StartContainer(ctx); err != nil {
-		jsonmsg.Error(fmt.Sprintf("Failed to start holopod instance: %v", err))
-		exitCode := getExitCode(err)
-		jsonmsg.ContainerExit(exitCode)
-		// Emit structured event for start failures
-		duration := time.Since(startTime)
-		jsonmsg.ContainerExitedWithDetails(containerID, exitCode, duration.String())
-		return exitCode, tracker
+		return fail(containerID, startTime, "Failed to start holopod instance", err), tracker
 	}
 
 	if err := manager.AttachStreams(ctx); err != nil {
@@ -104,43 +176,61 @@ func run() (int, *lifecycle.ResourceTracker) {
 
 	containerIP, err := manager.GetContainerIP(ctx)
 	var chainName string
+	stopFlowLogs := func() {}
+	stopProbe := func() {}
 	if err != nil {
 		// Check if container has already exited (common for short-running containers)
-		if strings.Contains(err.Error(), "container completed before network setup") ||
-			strings.Contains(err.Error(), "No such container") {
+		if errors.Is(err, container.ErrExitedEarly) {
 			// Skip network isolation setup, proceed to wait for exit code
 			// Info message already logged by GetContainerIP
 		} else {
 			time.Sleep(150 * time.Millisecond)
-			jsonmsg.Error(fmt.Sprintf("Failed to get holopod instance IP: %v", err))
-			exitCode := getExitCode(err)
-			jsonmsg.ContainerExit(exitCode)
-			// Emit structured event for IP assignment failures
-			duration := time.Since(startTime)
-			jsonmsg.ContainerExitedWithDetails(containerID, exitCode, duration.String())
-			return exitCode, tracker
+			return fail(containerID, startTime, "Failed to get holopod instance IP", err), tracker
 		}
 	} else {
 		// Set up network isolation only if container is still running
 		var setupErr error
 		chainName, setupErr = lifecycle.SetupNetworkIsolation(ctx, containerID, containerIP.String(), cfg)
 		if setupErr != nil {
-			jsonmsg.Error(fmt.Sprintf("Failed to setup network isolation: %v", setupErr))
-			exitCode := getExitCode(setupErr)
-			jsonmsg.ContainerExit(exitCode)
-			// Emit structured event for network isolation failures
-			duration := time.Since(startTime)
-			jsonmsg.ContainerExitedWithDetails(containerID, exitCode, duration.String())
-			return exitCode, tracker
+			return fail(containerID, startTime, "Failed to setup network isolation", setupErr), tracker
 		}
 		tracker.TrackChain(chainName)
+		manager.SetChainName(chainName)
+
+		// Sidecars join the container's network namespace only once its
+		// isolation is in place
+		if len(cfg.Container.Sidecars) > 0 {
+			err := manager.StartSidecars(ctx)
+			tracker.TrackSidecars(manager.SidecarIDs())
+			if err != nil {
+				stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				manager.StopContainer(stopCtx, 5)
+				cancel()
+				err = ierrors.Wrap(ierrors.KindSidecarFailed, ierrors.ExitSetupError, err)
+				return fail(containerID, startTime, "Failed to start sidecars", err), tracker
+			}
+		}
+
+		if cfg.Logging.LogNetworkAttempts {
+			stopFlowLogs = lifecycle.StartFlowLogStream(ctx, containerID, chainName)
+		}
 
-		// Container is now fully ready (started + network isolation configured)
+		// Container is now fully ready (started + network isolation configured),
+		// unless the workload has a readiness probe to pass first
 		if containerIP != nil {
-			jsonmsg.ContainerReady(containerID, containerIP.String())
+			if cfg.Container.ReadinessProbe != nil {
+				stopProbe = manager.StartReadinessProbe(ctx, containerIP)
+			} else {
+				jsonmsg.ContainerReady(containerID, containerIP.String())
+			}
 		}
 	}
 
+	stopStats := func() {}
+	if interval := cfg.Execution.StatsIntervalSecs; interval > 0 {
+		stopStats = manager.StartStatsStream(ctx, time.Duration(interval)*time.Second)
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
@@ -153,19 +243,36 @@ func run() (int, *lifecycle.ResourceTracker) {
 		manager.StopContainer(stopCtx, 5)
 	}()
 
+	heartbeat.SetPhase(heartbeat.PhaseRunning)
 	jsonmsg.Info("Waiting for Holopod instance to exit...")
 	exitCode := 0
+	errorCode := ""
 	code, err := manager.WaitForExit(ctx)
 	if err != nil {
 		jsonmsg.Warning(fmt.Sprintf("Error waiting for container: %v", err))
 		exitCode = 1
+		errorCode = string(ierrors.KindOf(err))
 	} else {
 		exitCode = code
+		if exitCode != 0 && manager.OOMKilled(ctx) {
+			errorCode = string(ierrors.KindOOM)
+		}
 	}
 
+	heartbeat.SetPhase(heartbeat.PhaseCleaningUp)
 	duration := time.Since(startTime)
 	jsonmsg.Info(fmt.Sprintf("Holopod instance exited with code: %d", exitCode))
-	jsonmsg.ContainerExitedWithDetails(containerID, exitCode, duration.String())
+	jsonmsg.ContainerExitedWithDetails(containerID, exitCode, duration.String(), errorCode)
+
+	manager.StopCaptures()
+	manager.AbortUploads()
+	stopFlowLogs()
+	stopStats()
+	stopProbe()
+
+	if len(cfg.Container.PostExitHooks) > 0 {
+		lifecycle.RunPostExitHooks(ctx, manager, cfg, tracker, exitCode, duration)
+	}
 
 	// Only cleanup network isolation if it was set up
 	if chainName != "" {
@@ -176,49 +283,34 @@ func run() (int, *lifecycle.ResourceTracker) {
 	cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	manager.RemoveSidecars(cleanupCtx)
+	tracker.UntrackSidecars()
+
 	if err := manager.RemoveContainer(cleanupCtx); err != nil {
 		jsonmsg.Warning(fmt.Sprintf("Failed to remove container: %v", err))
 	}
 	tracker.UntrackContainer()
+	manager.RemoveWorkspace(cleanupCtx)
+	tracker.UntrackWorkspaceVolume()
 
+	if viaBastion {
+		lifecycle.ReleaseNetwork(cleanupCtx, manager.ContainerName(), actualNetwork)
+	}
 	tracker.UntrackNetwork()
 
 	jsonmsg.Info(fmt.Sprintf("Holopod instance completed with exit code: %d", exitCode))
-	jsonmsg.ContainerExit(exitCode)
+	jsonmsg.ContainerExit(exitCode, errorCode)
 
 	return exitCode, tracker
 }
 
-func getExitCode(err error) int {
-	if err == nil {
-		return 0
-	}
-
-	if ie, ok := err.(*ierrors.IsolationError); ok {
-		return ie.ExitCode()
-	}
-
-	errStr := strings.ToLower(err.Error())
-
-	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "deadline exceeded") {
-		return int(ierrors.ExitTimeout)
-	}
-
-	if strings.Contains(errStr, "docker") || strings.Contains(errStr, "daemon") {
-		return int(ierrors.ExitDockerError)
-	}
-
-	if strings.Contains(errStr, "gvisor") || strings.Contains(errStr, "runtime") {
-		return int(ierrors.ExitSetupError)
-	}
-
-	if strings.Contains(errStr, "iptables") || strings.Contains(errStr, "network") || strings.Contains(errStr, "bastion") {
-		return int(ierrors.ExitRuntimeError)
-	}
-
-	if strings.Contains(errStr, "config") || strings.Contains(errStr, "input") || strings.Contains(errStr, "validation") {
-		return int(ierrors.ExitConfigError)
-	}
-
-	return int(ierrors.ExitConfigError)
+// fail reports err, which ended the run, with its machine-readable cause and
+// returns the exit code to end with
+func fail(containerID string, startTime time.Time, what string, err error) int {
+	exitCode := ierrors.GetExitCode(err)
+	errorCode := string(ierrors.KindOf(err))
+	jsonmsg.ErrorWithCode(fmt.Sprintf("%s: %v", what, err), errorCode)
+	jsonmsg.ContainerExit(exitCode, errorCode)
+	jsonmsg.ContainerExitedWithDetails(containerID, exitCode, time.Since(startTime).String(), errorCode)
+	return exitCode
 }
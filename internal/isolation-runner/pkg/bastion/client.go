@@ -5,15 +5,71 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
 )
 
+// RunIDMetadataKey carries the run ID in the gRPC metadata of every bastion
+// call, for the bastion's audit log
+const RunIDMetadataKey = "x-holopod-run-id"
+
+var (
+	runIDMu sync.Mutex
+	runID   string
+)
+
+// SetRunID sends the run ID the container-manager assigned with every bastion
+// call made from now on
+func SetRunID(id string) {
+	runIDMu.Lock()
+	defer runIDMu.Unlock()
+	runID = id
+}
+
+// withRunID adds the run ID, if set, to the outgoing metadata
+func withRunID(ctx context.Context) context.Context {
+	runIDMu.Lock()
+	id := runID
+	runIDMu.Unlock()
+	if id == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RunIDMetadataKey, id)
+}
+
+// withCredential adds the bastion credential, if configured, to the outgoing
+// metadata. A per-run credential is only accepted alongside its run ID.
+func withCredential(ctx context.Context) context.Context {
+	credential := config.GetBastionCredential()
+	if credential == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+credential)
+}
+
+func runIDUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withCredential(withRunID(ctx)), method, req, reply, cc, opts...)
+}
+
+func runIDStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withCredential(withRunID(ctx)), desc, cc, method, opts...)
+}
+
 type Client struct {
+	address     string
+	retry       RetryConfig
+	mu          sync.Mutex
 	conn        *grpc.ClientConn
 	client      pb.BastionServiceClient
 	containerID string
@@ -26,28 +82,105 @@ type NetworkResult struct {
 	Reused      bool
 }
 
+// Connect dials the bastion, retrying with backoff per RetryConfigFromEnv
 func Connect(address, containerID string) (*Client, error) {
+	return connect(address, containerID, RetryConfigFromEnv())
+}
+
+func connect(address, containerID string, retry RetryConfig) (*Client, error) {
+	var conn *grpc.ClientConn
+	var err error
+	for attempt := 1; ; attempt++ {
+		conn, err = dial(address)
+		if err == nil {
+			break
+		}
+		// Retrying cannot fix a bad TLS configuration
+		if attempt >= retry.MaxAttempts || ierrors.KindOf(err) == ierrors.KindConfigInvalid {
+			return nil, err
+		}
+
+		delay := retry.backoff(attempt)
+		jsonmsg.Warning(fmt.Sprintf("Could not connect to bastion (attempt %d/%d): %v. Retrying in %s",
+			attempt, retry.MaxAttempts, err, delay.Round(time.Millisecond)))
+		time.Sleep(delay)
+	}
+
+	return &Client{
+		address:     address,
+		retry:       retry,
+		conn:        conn,
+		client:      pb.NewBastionServiceClient(conn),
+		containerID: containerID,
+	}, nil
+}
+
+func dial(address string) (*grpc.ClientConn, error) {
+	creds, err := transportCredentials()
+	if err != nil {
+		return nil, ierrors.NewConfigError("invalid bastion TLS configuration", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	conn, err := grpc.DialContext(ctx, address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 		grpc.WithBlock(),
+		grpc.WithUnaryInterceptor(runIDUnaryInterceptor),
+		grpc.WithStreamInterceptor(runIDStreamInterceptor),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to bastion at %s: %w", address, err)
+		return nil, ierrors.Wrap(ierrors.KindBastionUnreachable, ierrors.ExitSetupError,
+			fmt.Errorf("failed to connect to bastion at %s: %w", address, err))
 	}
 
-	client := pb.NewBastionServiceClient(conn)
+	return conn, nil
+}
 
-	return &Client{
-		conn:        conn,
-		client:      client,
-		containerID: containerID,
-	}, nil
+// transportCredentials returns TLS credentials when the bastion TLS settings
+// are present, and plaintext otherwise
+func transportCredentials() (credentials.TransportCredentials, error) {
+	tlsConfig, err := config.GetBastionTLS().Client()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		return insecure.NewCredentials(), nil
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// redial replaces the connection with a fresh one
+func (c *Client) redial() error {
+	conn, err := dial(c.address)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	old := c.conn
+	c.conn = conn
+	c.client = pb.NewBastionServiceClient(conn)
+	c.mu.Unlock()
+
+	if old != nil {
+		_ = old.Close()
+	}
+	return nil
+}
+
+// rpc returns the current gRPC client, which changes after a redial
+func (c *Client) rpc() pb.BastionServiceClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client
 }
 
 func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.conn != nil {
 		return c.conn.Close()
 	}
@@ -69,7 +202,7 @@ func (c *Client) AcquireNetwork(subnet *string, leaseDurationSecs *uint32) (*Net
 	hasher.Write([]byte(driver))
 	configHash := hex.EncodeToString(hasher.Sum(nil))
 
-	resp, err := c.client.AcquireNetwork(ctx, &pb.AcquireNetworkRequest{
+	resp, err := c.rpc().AcquireNetwork(ctx, &pb.AcquireNetworkRequest{
 		ContainerId: c.containerID,
 		NetworkConfig: &pb.NetworkConfig{
 			SubnetRange: subnet,
@@ -103,7 +236,7 @@ func (c *Client) ReleaseNetwork(networkName string, forceCleanup bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	resp, err := c.client.ReleaseNetwork(ctx, &pb.ReleaseNetworkRequest{
+	resp, err := c.rpc().ReleaseNetwork(ctx, &pb.ReleaseNetworkRequest{
 		ContainerId:  c.containerID,
 		NetworkName:  networkName,
 		ForceCleanup: &forceCleanup,
@@ -124,13 +257,15 @@ func (c *Client) ReleaseNetwork(networkName string, forceCleanup bool) error {
 }
 
 func (c *Client) SetupChain(chainName, containerIP string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	resp, err := c.client.SetupChain(ctx, &pb.SetupChainRequest{
-		ChainName:   chainName,
-		ContainerIp: containerIP,
-		ContainerId: c.containerID,
+	var resp *pb.SetupChainResponse
+	err := c.callOnce("setup_chain", func(ctx context.Context) error {
+		var err error
+		resp, err = c.rpc().SetupChain(ctx, &pb.SetupChainRequest{
+			ChainName:   chainName,
+			ContainerIp: containerIP,
+			ContainerId: c.containerID,
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to setup chain: %w", err)
@@ -148,12 +283,14 @@ func (c *Client) SetupChain(chainName, containerIP string) error {
 }
 
 func (c *Client) CleanupChain(chainName string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	resp, err := c.client.CleanupChain(ctx, &pb.CleanupChainRequest{
-		ChainName:   chainName,
-		ContainerId: c.containerID,
+	var resp *pb.CleanupChainResponse
+	err := c.call("cleanup_chain", func(ctx context.Context) error {
+		var err error
+		resp, err = c.rpc().CleanupChain(ctx, &pb.CleanupChainRequest{
+			ChainName:   chainName,
+			ContainerId: c.containerID,
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to cleanup chain: %w", err)
@@ -171,13 +308,15 @@ func (c *Client) CleanupChain(chainName string) error {
 }
 
 func (c *Client) ApplyNetworkPolicy(chainName string, policy *pb.NetworkPolicy) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	resp, err := c.client.ApplyRules(ctx, &pb.ApplyRulesRequest{
-		ChainName:   chainName,
-		Policy:      policy,
-		ContainerId: c.containerID,
+	var resp *pb.ApplyRulesResponse
+	err := c.callOnce("apply_rules", func(ctx context.Context) error {
+		var err error
+		resp, err = c.rpc().ApplyRules(ctx, &pb.ApplyRulesRequest{
+			ChainName:   chainName,
+			Policy:      policy,
+			ContainerId: c.containerID,
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to apply network policy: %w", err)
@@ -193,3 +332,124 @@ func (c *Client) ApplyNetworkPolicy(chainName string, policy *pb.NetworkPolicy)
 
 	return nil
 }
+
+// UpdateNetworkPolicy atomically replaces the rules of a chain set up earlier
+func (c *Client) UpdateNetworkPolicy(chainName string, policy *pb.NetworkPolicy) error {
+	var resp *pb.UpdateNetworkPolicyResponse
+	err := c.call("update_network_policy", func(ctx context.Context) error {
+		var err error
+		resp, err = c.rpc().UpdateNetworkPolicy(ctx, &pb.UpdateNetworkPolicyRequest{
+			ChainName:   chainName,
+			Policy:      policy,
+			ContainerId: c.containerID,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update network policy: %w", err)
+	}
+
+	if !resp.Success {
+		errMsg := "unknown error"
+		if resp.Error != nil {
+			errMsg = *resp.Error
+		}
+		return fmt.Errorf("bastion error: %s", errMsg)
+	}
+
+	return nil
+}
+
+// ExposePorts publishes container ports on the host and returns the mappings
+// with the host ports the bastion assigned
+func (c *Client) ExposePorts(chainName string, ports []*pb.PortMapping) ([]*pb.PortMapping, error) {
+	var resp *pb.ExposePortsResponse
+	err := c.call("expose_ports", func(ctx context.Context) error {
+		var err error
+		resp, err = c.rpc().ExposePorts(ctx, &pb.ExposePortsRequest{
+			ChainName:   chainName,
+			ContainerId: c.containerID,
+			Ports:       ports,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expose ports: %w", err)
+	}
+
+	if !resp.Success {
+		errMsg := "unknown error"
+		if resp.Error != nil {
+			errMsg = *resp.Error
+		}
+		return nil, fmt.Errorf("bastion error: %s", errMsg)
+	}
+
+	return resp.Ports, nil
+}
+
+// DNSFilterAddress returns the IP of the bastion's DNS filter, failing if the
+// bastion does not run one
+func (c *Client) DNSFilterAddress() (string, error) {
+	var resp *pb.HealthResponse
+	err := c.call("health", func(ctx context.Context) error {
+		var err error
+		resp, err = c.rpc().Health(ctx, &pb.HealthRequest{})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query bastion health: %w", err)
+	}
+
+	if resp.GetDnsFilterAddress() == "" {
+		return "", fmt.Errorf("bastion does not have DNS domain filtering enabled")
+	}
+
+	return resp.GetDnsFilterAddress(), nil
+}
+
+// StreamFlowLogs delivers logged connection attempts for chainName to fn until
+// ctx is cancelled or the bastion ends the stream.
+func (c *Client) StreamFlowLogs(ctx context.Context, chainName string, fn func(*pb.FlowRecord)) error {
+	stream, err := c.rpc().StreamFlowLogs(ctx, &pb.StreamFlowLogsRequest{
+		ChainName:   chainName,
+		ContainerId: c.containerID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stream flow logs: %w", err)
+	}
+
+	for {
+		record, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil || err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("flow log stream failed: %w", err)
+		}
+		fn(record)
+	}
+}
+
+// CapturePackets streams pcap data for chainName's container to fn until the
+// capture limits are reached or ctx is cancelled.
+func (c *Client) CapturePackets(ctx context.Context, chainName string, req *pb.CapturePacketsRequest, fn func([]byte)) error {
+	req.ChainName = chainName
+	req.ContainerId = c.containerID
+
+	stream, err := c.rpc().CapturePackets(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to start packet capture: %w", err)
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil || err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("packet capture failed: %w", err)
+		}
+		fn(chunk.Data)
+	}
+}
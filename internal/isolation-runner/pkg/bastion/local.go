@@ -0,0 +1,227 @@
+package bastion
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/iptables"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/portmap"
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// Controller is the set of chain operations needed to isolate a container.
+// It is implemented by the bastion Client and, in standalone mode, by LocalController.
+type Controller interface {
+	SetupChain(chainName, containerIP string) error
+	ApplyNetworkPolicy(chainName string, policy *pb.NetworkPolicy) error
+	UpdateNetworkPolicy(chainName string, policy *pb.NetworkPolicy) error
+	ExposePorts(chainName string, ports []*pb.PortMapping) ([]*pb.PortMapping, error)
+	CleanupChain(chainName string) error
+	Close() error
+}
+
+var (
+	_ Controller = (*Client)(nil)
+	_ Controller = (*LocalController)(nil)
+
+	// Once the bastion has been found unreachable, later calls go straight to local mode
+	standaloneActive atomic.Bool
+
+	// Container IPs for chains created locally, needed to remove the FORWARD jump on cleanup
+	localChainMu  sync.Mutex
+	localChainIPs = make(map[string]string)
+
+	// Host ports published locally, released with their chain
+	localPorts = portmap.NewPublisher(portmap.RangeFromEnv())
+)
+
+// Dial connects to the bastion at address. In standalone mode, if the bastion
+// cannot be reached and the runner is root, it returns a LocalController instead.
+func Dial(address, containerID string) (Controller, error) {
+	if standaloneActive.Load() {
+		return NewLocalController(containerID)
+	}
+
+	if !config.IsStandaloneMode() {
+		return Connect(address, containerID)
+	}
+
+	// A single dial attempt so standalone deployments don't wait out the backoff
+	retry := RetryConfigFromEnv()
+	retry.MaxAttempts = 1
+	client, err := connect(address, containerID, retry)
+	if err == nil {
+		return client, nil
+	}
+
+	local, localErr := NewLocalController(containerID)
+	if localErr != nil {
+		return nil, fmt.Errorf("%w (standalone fallback unavailable: %v)", err, localErr)
+	}
+
+	standaloneActive.Store(true)
+	jsonmsg.Warning("Bastion unreachable; applying iptables rules locally in standalone mode")
+
+	return local, nil
+}
+
+// errLocalPodGroup is returned for policies with a pod group in standalone mode.
+// Each runner only knows its own container, so group members cannot be found.
+var errLocalPodGroup = fmt.Errorf("pod groups require the bastion and are not supported in standalone mode")
+
+// LocalController applies iptables rules in-process using the same rule building
+// and validation as the bastion service. It requires root.
+type LocalController struct {
+	containerID string
+}
+
+func NewLocalController(containerID string) (*LocalController, error) {
+	if os.Geteuid() != 0 {
+		return nil, fmt.Errorf("standalone mode requires root privileges")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := iptables.CheckIPTables(ctx); err != nil {
+		return nil, err
+	}
+
+	return &LocalController{containerID: containerID}, nil
+}
+
+func (l *LocalController) Close() error {
+	return nil
+}
+
+func (l *LocalController) SetupChain(chainName, containerIP string) error {
+	if err := validation.ValidateChainName(chainName); err != nil {
+		return err
+	}
+
+	ip, err := validation.ValidateContainerIP(containerIP)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := iptables.SetupChain(ctx, chainName, ip); err != nil {
+		return fmt.Errorf("failed to setup chain: %w", err)
+	}
+
+	localChainMu.Lock()
+	localChainIPs[chainName] = containerIP
+	localChainMu.Unlock()
+
+	return nil
+}
+
+func (l *LocalController) ApplyNetworkPolicy(chainName string, policy *pb.NetworkPolicy) error {
+	if err := validation.ValidateChainName(chainName); err != nil {
+		return err
+	}
+
+	if policy == nil {
+		return fmt.Errorf("network policy is required")
+	}
+
+	if policy.PodGroup != "" {
+		return errLocalPodGroup
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := iptables.ApplyRules(ctx, chainName, policy); err != nil {
+		return fmt.Errorf("failed to apply network policy: %w", err)
+	}
+
+	return nil
+}
+
+func (l *LocalController) UpdateNetworkPolicy(chainName string, policy *pb.NetworkPolicy) error {
+	if err := validation.ValidateChainName(chainName); err != nil {
+		return err
+	}
+
+	if policy == nil {
+		return fmt.Errorf("network policy is required")
+	}
+
+	if policy.PodGroup != "" {
+		return errLocalPodGroup
+	}
+
+	localChainMu.Lock()
+	containerIP, ok := localChainIPs[chainName]
+	localChainMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no container registered for chain %s", chainName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := iptables.ReplaceRules(ctx, chainName, containerIP, policy, localPorts.Mappings(chainName), nil); err != nil {
+		return fmt.Errorf("failed to update network policy: %w", err)
+	}
+
+	return nil
+}
+
+func (l *LocalController) ExposePorts(chainName string, ports []*pb.PortMapping) ([]*pb.PortMapping, error) {
+	if err := validation.ValidateChainName(chainName); err != nil {
+		return nil, err
+	}
+
+	localChainMu.Lock()
+	containerIP, ok := localChainIPs[chainName]
+	localChainMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no container registered for chain %s", chainName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	published, err := localPorts.Expose(ctx, chainName, containerIP, ports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expose ports: %w", err)
+	}
+
+	return published, nil
+}
+
+func (l *LocalController) CleanupChain(chainName string) error {
+	if err := validation.ValidateChainName(chainName); err != nil {
+		return err
+	}
+
+	localChainMu.Lock()
+	containerIP := localChainIPs[chainName]
+	localChainMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	localPorts.Release(ctx, chainName)
+
+	if err := iptables.CleanupChain(ctx, chainName, containerIP); err != nil {
+		return fmt.Errorf("failed to cleanup chain: %w", err)
+	}
+
+	localChainMu.Lock()
+	delete(localChainIPs, chainName)
+	localChainMu.Unlock()
+
+	return nil
+}
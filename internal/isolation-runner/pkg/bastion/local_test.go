@@ -0,0 +1,47 @@
+package bastion
+
+import (
+	"testing"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+)
+
+func TestLocalControllerValidation(t *testing.T) {
+	l := &LocalController{containerID: "test"}
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{"setup invalid chain", func() error { return l.SetupChain("FORWARD", "172.17.0.2") }},
+		{"setup invalid ip", func() error { return l.SetupChain("ISO-0123456789abcdef", "not-an-ip") }},
+		{"setup loopback ip", func() error { return l.SetupChain("ISO-0123456789abcdef", "127.0.0.1") }},
+		{"apply invalid chain", func() error { return l.ApplyNetworkPolicy("INPUT", &pb.NetworkPolicy{}) }},
+		{"apply nil policy", func() error { return l.ApplyNetworkPolicy("ISO-0123456789abcdef", nil) }},
+		{"apply pod group", func() error {
+			return l.ApplyNetworkPolicy("ISO-0123456789abcdef", &pb.NetworkPolicy{Policy: "deny", PodGroup: "web"})
+		}},
+		{"update invalid chain", func() error { return l.UpdateNetworkPolicy("OUTPUT", &pb.NetworkPolicy{}) }},
+		{"update nil policy", func() error { return l.UpdateNetworkPolicy("ISO-0123456789abcdef", nil) }},
+		{"update unknown chain", func() error {
+			return l.UpdateNetworkPolicy("ISO-0123456789abcdef", &pb.NetworkPolicy{Policy: "deny"})
+		}},
+		{"expose invalid chain", func() error {
+			_, err := l.ExposePorts("PREROUTING", []*pb.PortMapping{{ContainerPort: 80}})
+			return err
+		}},
+		{"expose unknown chain", func() error {
+			_, err := l.ExposePorts("ISO-0123456789abcdef", []*pb.PortMapping{{ContainerPort: 80}})
+			return err
+		}},
+		{"cleanup invalid chain", func() error { return l.CleanupChain("ISO-; rm -rf /") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); err == nil {
+				t.Error("expected validation error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package bastion
+
+import (
+	"fmt"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+)
+
+// PolicyFromConfig converts the runner's network config into the bastion's policy
+func PolicyFromConfig(network *config.NetworkConfig, logAttempts bool) *pb.NetworkPolicy {
+	policy := &pb.NetworkPolicy{
+		Policy:            network.DefaultPolicy,
+		BlockMetadata:     network.BlockMetadata,
+		AllowDns:          network.AllowDNS,
+		DnsServers:        network.DNSServers,
+		Whitelist:         make([]*pb.NetworkRule, 0),
+		Blacklist:         make([]*pb.NetworkRule, 0),
+		MaxConnections:    network.MaxConnections,
+		LogAttempts:       logAttempts,
+		DnsAllowedDomains: network.DNSAllowedDomains,
+		PodGroup:          network.PodGroup,
+	}
+
+	for _, entry := range network.Whitelist {
+		ports := make([]uint32, 0, len(entry.Ports))
+		for _, p := range entry.Ports {
+			var port uint32
+			fmt.Sscanf(p, "%d", &port)
+			ports = append(ports, port)
+		}
+
+		policy.Whitelist = append(policy.Whitelist, &pb.NetworkRule{
+			Cidr:        entry.CIDR,
+			Description: &entry.Description,
+			Ports:       ports,
+		})
+	}
+
+	for _, entry := range network.Blacklist {
+		policy.Blacklist = append(policy.Blacklist, &pb.NetworkRule{
+			Cidr:        entry.CIDR,
+			Description: &entry.Description,
+			Ports:       []uint32{},
+		})
+	}
+
+	return policy
+}
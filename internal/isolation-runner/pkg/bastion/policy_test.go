@@ -0,0 +1,51 @@
+package bastion
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+)
+
+func TestPolicyFromConfig(t *testing.T) {
+	maxConns := uint32(32)
+	network := &config.NetworkConfig{
+		DefaultPolicy: "deny",
+		BlockMetadata: true,
+		AllowDNS:      true,
+		DNSServers:    []string{"1.1.1.1"},
+		Whitelist: []config.WhitelistEntry{
+			{CIDR: "8.8.8.8/32", Description: "dns", Ports: []string{"53", "443"}},
+		},
+		Blacklist: []config.BlacklistEntry{
+			{CIDR: "10.0.0.0/8"},
+		},
+		MaxConnections:    &maxConns,
+		DNSAllowedDomains: []string{"example.com"},
+		PodGroup:          "web",
+	}
+
+	policy := PolicyFromConfig(network, true)
+
+	if policy.Policy != "deny" || !policy.BlockMetadata || !policy.AllowDns || !policy.LogAttempts {
+		t.Errorf("unexpected policy flags: %v", policy)
+	}
+	if policy.GetMaxConnections() != 32 {
+		t.Errorf("expected max connections 32, got %d", policy.GetMaxConnections())
+	}
+	if len(policy.Whitelist) != 1 || !slices.Equal(policy.Whitelist[0].Ports, []uint32{53, 443}) {
+		t.Errorf("unexpected whitelist: %v", policy.Whitelist)
+	}
+	if policy.Whitelist[0].GetDescription() != "dns" {
+		t.Errorf("expected whitelist description dns, got %q", policy.Whitelist[0].GetDescription())
+	}
+	if len(policy.Blacklist) != 1 || policy.Blacklist[0].Cidr != "10.0.0.0/8" {
+		t.Errorf("unexpected blacklist: %v", policy.Blacklist)
+	}
+	if !slices.Equal(policy.DnsAllowedDomains, []string{"example.com"}) {
+		t.Errorf("unexpected DNS allowlist: %v", policy.DnsAllowedDomains)
+	}
+	if policy.PodGroup != "web" {
+		t.Errorf("expected pod group web, got %q", policy.PodGroup)
+	}
+}
@@ -0,0 +1,126 @@
+package bastion
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+const (
+	defaultRetryAttempts  = 4
+	defaultInitialBackoff = 250 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+)
+
+// RetryConfig controls how bastion calls are retried on transient failures
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    defaultRetryAttempts,
+		InitialBackoff: defaultInitialBackoff,
+		MaxBackoff:     defaultMaxBackoff,
+	}
+}
+
+// RetryConfigFromEnv reads BASTION_RETRY_ATTEMPTS, BASTION_RETRY_INITIAL_BACKOFF_MS
+// and BASTION_RETRY_MAX_BACKOFF_MS, ignoring invalid values
+func RetryConfigFromEnv() RetryConfig {
+	cfg := DefaultRetryConfig()
+
+	if v, err := strconv.Atoi(os.Getenv("BASTION_RETRY_ATTEMPTS")); err == nil && v >= 1 && v <= 20 {
+		cfg.MaxAttempts = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("BASTION_RETRY_INITIAL_BACKOFF_MS")); err == nil && v > 0 {
+		cfg.InitialBackoff = time.Duration(v) * time.Millisecond
+	}
+	if v, err := strconv.Atoi(os.Getenv("BASTION_RETRY_MAX_BACKOFF_MS")); err == nil && v > 0 {
+		cfg.MaxBackoff = time.Duration(v) * time.Millisecond
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = cfg.InitialBackoff
+	}
+
+	return cfg
+}
+
+// backoff returns the delay before retry number attempt (starting at 1): exponential
+// growth capped at MaxBackoff, with up to 50% jitter subtracted.
+func (r RetryConfig) backoff(attempt int) time.Duration {
+	delay := r.InitialBackoff
+	for i := 1; i < attempt && delay < r.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(delay/2) + 1))
+	return delay - jitter
+}
+
+// isTransient reports whether err is worth retrying after re-dialing. The
+// bastion answers ResourceExhausted when rate limited or out of firewall workers.
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// isUnsent reports whether err shows the call never reached the bastion, so
+// even a call that is not idempotent may be retried. A deadline or abort may
+// come after the bastion acted on it.
+func isUnsent(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// call runs fn, retrying transient gRPC failures with backoff. The connection is
+// re-established before each retry so a restarted bastion is picked up.
+func (c *Client) call(op string, fn func(ctx context.Context) error) error {
+	return c.retryCall(op, isTransient, fn)
+}
+
+// callOnce is call for operations that must not run twice, such as creating a
+// chain or appending its rules: only failures that never reached the bastion
+// are retried.
+func (c *Client) callOnce(op string, fn func(ctx context.Context) error) error {
+	return c.retryCall(op, isUnsent, fn)
+}
+
+func (c *Client) retryCall(op string, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err = fn(ctx)
+		cancel()
+
+		if err == nil || !retryable(err) || attempt >= c.retry.MaxAttempts {
+			return err
+		}
+
+		delay := c.retry.backoff(attempt)
+		jsonmsg.Warning(fmt.Sprintf("Bastion %s failed (attempt %d/%d): %v. Retrying in %s",
+			op, attempt, c.retry.MaxAttempts, status.Convert(err).Message(), delay.Round(time.Millisecond)))
+		time.Sleep(delay)
+
+		// On failure the existing connection is kept; gRPC keeps reconnecting it
+		if redialErr := c.redial(); redialErr != nil {
+			jsonmsg.Warning(fmt.Sprintf("Could not re-dial bastion: %v", redialErr))
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package bastion
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
+)
+
+func TestRetryConfigFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("BASTION_RETRY_ATTEMPTS", "")
+		t.Setenv("BASTION_RETRY_INITIAL_BACKOFF_MS", "")
+		t.Setenv("BASTION_RETRY_MAX_BACKOFF_MS", "")
+
+		if got := RetryConfigFromEnv(); got != DefaultRetryConfig() {
+			t.Errorf("RetryConfigFromEnv() = %+v, want %+v", got, DefaultRetryConfig())
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Setenv("BASTION_RETRY_ATTEMPTS", "6")
+		t.Setenv("BASTION_RETRY_INITIAL_BACKOFF_MS", "100")
+		t.Setenv("BASTION_RETRY_MAX_BACKOFF_MS", "2000")
+
+		want := RetryConfig{MaxAttempts: 6, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+		if got := RetryConfigFromEnv(); got != want {
+			t.Errorf("RetryConfigFromEnv() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("invalid ignored", func(t *testing.T) {
+		t.Setenv("BASTION_RETRY_ATTEMPTS", "0")
+		t.Setenv("BASTION_RETRY_INITIAL_BACKOFF_MS", "abc")
+		t.Setenv("BASTION_RETRY_MAX_BACKOFF_MS", "-5")
+
+		if got := RetryConfigFromEnv(); got != DefaultRetryConfig() {
+			t.Errorf("RetryConfigFromEnv() = %+v, want %+v", got, DefaultRetryConfig())
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	r := RetryConfig{MaxAttempts: 10, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{5, time.Second},
+		{9, time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			got := r.backoff(tt.attempt)
+			if got > tt.max || got < tt.max/2 {
+				t.Fatalf("backoff(%d) = %s, want within [%s, %s]", tt.attempt, got, tt.max/2, tt.max)
+			}
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{status.Error(codes.Unavailable, "connection refused"), true},
+		{status.Error(codes.DeadlineExceeded, "timeout"), true},
+		{status.Error(codes.ResourceExhausted, "rate limit exceeded"), true},
+		{status.Error(codes.InvalidArgument, "bad chain"), false},
+		{errors.New("plain error"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCallRetriesTransientErrors(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	retry := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	client, err := connect(lis.Addr().String(), "test", retry)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Close()
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		calls := 0
+		err := client.call("test", func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return status.Error(codes.Unavailable, "bastion restarting")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("call() error = %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("calls = %d, want 3", calls)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := client.call("test", func(ctx context.Context) error {
+			calls++
+			return status.Error(codes.Unavailable, "bastion down")
+		})
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("call() error = %v, want Unavailable", err)
+		}
+		if calls != 3 {
+			t.Errorf("calls = %d, want 3", calls)
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		calls := 0
+		err := client.call("test", func(ctx context.Context) error {
+			calls++
+			return status.Error(codes.InvalidArgument, "bad chain")
+		})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("call() error = %v, want InvalidArgument", err)
+		}
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+	})
+}
+
+func TestCallOnceRetriesOnlyUnsent(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	retry := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	client, err := connect(lis.Addr().String(), "test", retry)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Close()
+
+	tests := []struct {
+		code      codes.Code
+		wantCalls int
+	}{
+		{codes.Unavailable, 3},
+		{codes.DeadlineExceeded, 1},
+		{codes.Aborted, 1},
+		{codes.ResourceExhausted, 1},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		err := client.callOnce("test", func(ctx context.Context) error {
+			calls++
+			return status.Error(tt.code, "failed")
+		})
+		if status.Code(err) != tt.code || calls != tt.wantCalls {
+			t.Errorf("callOnce() with %v: error = %v, calls = %d, want %d", tt.code, err, calls, tt.wantCalls)
+		}
+	}
+}
+
+func TestConnectRejectsPartialTLS(t *testing.T) {
+	t.Setenv("BASTION_TLS_CLIENT_CERT_FILE", "/nonexistent/runner.pem")
+
+	retry := RetryConfig{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	_, err := connect("127.0.0.1:1", "test", retry)
+	if err == nil {
+		t.Fatal("connect succeeded with a client certificate but no key")
+	}
+	// A backoff of an hour would time the test out had the error been retried
+	if kind := ierrors.KindOf(err); kind != ierrors.KindConfigInvalid {
+		t.Errorf("KindOf() = %s, want %s", kind, ierrors.KindConfigInvalid)
+	}
+}
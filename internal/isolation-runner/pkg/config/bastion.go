@@ -1,7 +1,14 @@
 package config
 
-import "os"
+import (
+	"os"
+	"time"
 
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/tlsconfig"
+)
+
+// GetBastionAddress returns the gRPC target of the bastion, "host:port" or,
+// for a bastion on a unix socket, "unix:///path/to/socket"
 func GetBastionAddress() string {
 	address := os.Getenv("BASTION_ADDRESS")
 	if address == "" {
@@ -9,3 +16,63 @@ func GetBastionAddress() string {
 	}
 	return address
 }
+
+// GetBastionTLS returns the certificates used to reach the bastion over TLS.
+// Setting any of them turns TLS on; the client pair is needed when the bastion
+// requires mTLS.
+func GetBastionTLS() tlsconfig.ClientConfig {
+	return tlsconfig.ClientConfig{
+		CAFile:     os.Getenv("BASTION_TLS_CA_FILE"),
+		CertFile:   os.Getenv("BASTION_TLS_CLIENT_CERT_FILE"),
+		KeyFile:    os.Getenv("BASTION_TLS_CLIENT_KEY_FILE"),
+		ServerName: os.Getenv("BASTION_TLS_SERVER_NAME"),
+	}
+}
+
+// GetBastionCredential returns the bearer token presented to the bastion: the
+// per-run credential the container-manager derives in BASTION_RUN_CREDENTIAL,
+// or else the shared BASTION_AUTH_TOKEN, as cleanup-orphans is run with
+func GetBastionCredential() string {
+	if credential := os.Getenv("BASTION_RUN_CREDENTIAL"); credential != "" {
+		return credential
+	}
+	return os.Getenv("BASTION_AUTH_TOKEN")
+}
+
+// IsStandaloneMode reports whether the runner may apply iptables rules itself
+// when the bastion cannot be reached
+func IsStandaloneMode() bool {
+	return os.Getenv("ISOLATION_RUNNER_STANDALONE") == "true"
+}
+
+// GetCheckpointDir returns the directory container checkpoints are written to
+// and restored from
+func GetCheckpointDir() string {
+	dir := os.Getenv("HOLOPOD_CHECKPOINT_DIR")
+	if dir == "" {
+		dir = "/var/lib/holopod/checkpoints"
+	}
+	return dir
+}
+
+// GetStateDir returns the directory the runner keeps its resource records in,
+// for cleanup-orphans to finish a run that was killed mid-way
+func GetStateDir() string {
+	dir := os.Getenv("HOLOPOD_STATE_DIR")
+	if dir == "" {
+		dir = "/run/holopod"
+	}
+	return dir
+}
+
+// DefaultHeartbeatInterval is how often the runner emits runner_heartbeat
+const DefaultHeartbeatInterval = 10 * time.Second
+
+// GetHeartbeatInterval returns how often the runner emits runner_heartbeat,
+// taken as a Go duration from HOLOPOD_HEARTBEAT_INTERVAL; "0" disables it
+func GetHeartbeatInterval() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("HOLOPOD_HEARTBEAT_INTERVAL")); err == nil && v >= 0 {
+		return v
+	}
+	return DefaultHeartbeatInterval
+}
@@ -1,8 +1,16 @@
 package config
 
 import (
+	"encoding/binary"
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -20,6 +28,17 @@ type NetworkConfig struct {
 	BlockMetadata bool             `json:"block_metadata"`
 	AllowDNS      bool             `json:"allow_dns"`
 	DNSServers    []string         `json:"dns_servers"`
+
+	// MaxConnections caps concurrent outbound TCP connections; nil means unlimited
+	MaxConnections *uint32 `json:"max_connections"`
+
+	// DNSAllowedDomains restricts name resolution to these domains ("example.com"
+	// or "*.example.com") via the bastion's DNS filter; empty means unrestricted
+	DNSAllowedDomains []string `json:"dns_allowed_domains"`
+
+	// PodGroup lets containers sharing the same ID reach each other on the
+	// pooled network; empty keeps the container isolated from all others
+	PodGroup string `json:"pod_group"`
 }
 
 type WhitelistEntry struct {
@@ -41,6 +60,116 @@ type ContainerConfig struct {
 	Tmpfs          []string          `json:"tmpfs"`
 	Environment    map[string]string `json:"environment"`
 	WorkingDir     *string           `json:"working_dir"`
+	Ports          []PortMapping     `json:"ports"`
+	Workspace      *WorkspaceConfig  `json:"workspace,omitempty"`
+	// RestoreFrom names a checkpoint the container resumes from instead of
+	// starting its command afresh
+	RestoreFrom string `json:"restore_from,omitempty"`
+	// ReadinessProbe holds back container_ready until the workload passes it
+	ReadinessProbe *ProbeConfig `json:"readiness_probe,omitempty"`
+	// RuntimeOptions sets gVisor flags for this container alone
+	RuntimeOptions *RuntimeOptions `json:"runtime_options,omitempty"`
+	// Sidecars run alongside the container in its network namespace
+	Sidecars []AuxContainerConfig `json:"sidecars,omitempty"`
+	// InitContainers run one after another, each to a zero exit, before the
+	// container starts
+	InitContainers []AuxContainerConfig `json:"init_containers,omitempty"`
+	// PostExitHooks run one after another once the workload has exited,
+	// before its resources are cleaned up
+	PostExitHooks []HookConfig `json:"post_exit_hooks,omitempty"`
+	// Labels are merged onto the container's Docker labels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// HookConfig runs either a helper container, which shares the container's
+// workspace and network policy and takes the hook's name, or a webhook call.
+// A hook's failure is reported but does not change the workload's exit code.
+type HookConfig struct {
+	Name        string              `json:"name"`
+	Container   *AuxContainerConfig `json:"container,omitempty"`
+	WebhookURL  string              `json:"webhook_url,omitempty"`
+	TimeoutSecs uint32              `json:"timeout_secs,omitempty"`
+}
+
+// Timeout is how long the hook may run
+func (h *HookConfig) Timeout() time.Duration {
+	if h.TimeoutSecs == 0 {
+		return DefaultHookTimeout
+	}
+	return time.Duration(h.TimeoutSecs) * time.Second
+}
+
+// AuxContainerConfig is a sidecar or init container: an auxiliary container
+// with an image of its own. A sidecar, such as a database the workload talks
+// to over localhost, joins the main container's network namespace, and with it
+// its network policy, and is removed together with it. An init container runs
+// to completion on the container's network, under its policy, before it starts.
+type AuxContainerConfig struct {
+	Name        string            `json:"name"`
+	ImageSpec   *ImageSpec        `json:"image_spec"`
+	Command     []string          `json:"command,omitempty"`
+	Args        []string          `json:"args,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+	MemoryLimit *string           `json:"memory_limit,omitempty"`
+	CPULimit    *string           `json:"cpu_limit,omitempty"`
+}
+
+// RuntimeOptions are runsc flags applied per container through
+// dev.gvisor.flag annotations, which runsc honours when registered with
+// --allow-flag-override
+type RuntimeOptions struct {
+	Platform string `json:"platform,omitempty"`
+	Network  string `json:"network,omitempty"`
+	DirectFS *bool  `json:"directfs,omitempty"`
+}
+
+// Annotations returns the OCI annotations that set the options' flags
+func (o *RuntimeOptions) Annotations() map[string]string {
+	annotations := map[string]string{}
+	if o.Platform != "" {
+		annotations["dev.gvisor.flag.platform"] = o.Platform
+	}
+	if o.Network != "" {
+		annotations["dev.gvisor.flag.network"] = o.Network
+	}
+	if o.DirectFS != nil {
+		annotations["dev.gvisor.flag.directfs"] = strconv.FormatBool(*o.DirectFS)
+	}
+	return annotations
+}
+
+// ProbeConfig checks that the workload is ready. Command runs in the container
+// and must exit 0; otherwise Port must accept a TCP connection or, with
+// HTTPPath, answer an HTTP GET with a 2xx or 3xx status.
+type ProbeConfig struct {
+	Port     uint32   `json:"port,omitempty"`
+	HTTPPath string   `json:"http_path,omitempty"`
+	Command  []string `json:"command,omitempty"`
+	// Seconds before the first attempt, between attempts and per attempt
+	InitialDelaySecs uint32 `json:"initial_delay_secs,omitempty"`
+	PeriodSecs       uint32 `json:"period_secs,omitempty"`
+	TimeoutSecs      uint32 `json:"timeout_secs,omitempty"`
+}
+
+// MaxWorkspaceBytes bounds a workspace archive, inline or fetched
+const MaxWorkspaceBytes = 256 << 20
+
+// WorkspaceConfig seeds a directory of the container with a gzipped tar archive
+// before its command starts. The archive is given inline or fetched from URL.
+type WorkspaceConfig struct {
+	Archive []byte `json:"archive,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Path    string `json:"path"`
+	// Mount is "volume" (default), backed by disk, or "tmpfs", backed by memory
+	Mount string `json:"mount"`
+}
+
+// PortMapping publishes a container port on the host. HostPort 0 lets the
+// bastion pick a free port; Protocol defaults to tcp.
+type PortMapping struct {
+	ContainerPort uint32 `json:"container_port"`
+	HostPort      uint32 `json:"host_port"`
+	Protocol      string `json:"protocol"`
 }
 
 type ExecutionConfig struct {
@@ -51,13 +180,31 @@ type ExecutionConfig struct {
 	AttachStdout   bool   `json:"attach_stdout"`
 	AttachStderr   bool   `json:"attach_stderr"`
 	TTY            bool   `json:"tty"`
+	// BinaryStdio moves workload stdin, stdout and stderr onto the framed
+	// descriptors of package stdio instead of JSON messages
+	BinaryStdio bool `json:"binary_stdio"`
+	// RawStdin is set when the config was read from a file or descriptor
+	// rather than stdin; stdin then carries only workload input, copied
+	// through as is, and no control commands
+	RawStdin bool `json:"-"`
+	// StatsIntervalSecs is how often a container_stats event is emitted; 0
+	// disables them
+	StatsIntervalSecs uint32 `json:"stats_interval_secs"`
+	// Quiet drops every debug, info, warning and error message, for consumers
+	// that only want structured events and container output
+	Quiet bool `json:"quiet"`
 }
 
 type LoggingConfig struct {
-	Enabled            bool    `json:"enabled"`
-	LogNetworkAttempts bool    `json:"log_network_attempts"`
-	LogFile            *string `json:"log_file"`
-	LogLevel           string  `json:"log_level"`
+	Enabled            bool `json:"enabled"`
+	LogNetworkAttempts bool `json:"log_network_attempts"`
+	// LogFile, if set, receives a copy of every event written to stdout,
+	// rotated once it reaches LogFileMaxBytes with LogFileMaxBackups older
+	// files kept beside it
+	LogFile           *string `json:"log_file"`
+	LogFileMaxBytes   int64   `json:"log_file_max_bytes,omitempty"`
+	LogFileMaxBackups int     `json:"log_file_max_backups,omitempty"`
+	LogLevel          string  `json:"log_level"`
 }
 
 func DefaultConfig() *Config {
@@ -110,10 +257,50 @@ func DefaultLoggingConfig() LoggingConfig {
 		Enabled:            true,
 		LogNetworkAttempts: true,
 		LogFile:            nil,
+		LogFileMaxBytes:    DefaultLogFileMaxBytes,
+		LogFileMaxBackups:  DefaultLogFileMaxBackups,
 		LogLevel:           "info",
 	}
 }
 
+// Bounds of the event log file and its rotated copies
+const (
+	DefaultLogFileMaxBytes   = 10 << 20
+	MaxLogFileMaxBytes       = 1 << 30
+	DefaultLogFileMaxBackups = 3
+	MaxLogFileMaxBackups     = 20
+)
+
+// ValidateLogFile checks the event log file settings, filling in defaults for
+// unset limits
+func ValidateLogFile(logging *LoggingConfig) error {
+	if logging.LogFile == nil {
+		return nil
+	}
+
+	file := *logging.LogFile
+	if !path.IsAbs(file) || path.Clean(file) != file || strings.Contains(file, "\x00") {
+		return fmt.Errorf("log file must be a clean absolute path: '%s'", file)
+	}
+	for _, protected := range []string{"/proc", "/sys", "/dev"} {
+		if file == protected || strings.HasPrefix(file, protected+"/") {
+			return fmt.Errorf("log file cannot be under %s", protected)
+		}
+	}
+
+	if logging.LogFileMaxBytes == 0 {
+		logging.LogFileMaxBytes = DefaultLogFileMaxBytes
+	}
+	if logging.LogFileMaxBytes < 1024 || logging.LogFileMaxBytes > MaxLogFileMaxBytes {
+		return fmt.Errorf("log file max bytes must be between 1024 and %d", MaxLogFileMaxBytes)
+	}
+	if logging.LogFileMaxBackups < 0 || logging.LogFileMaxBackups > MaxLogFileMaxBackups {
+		return fmt.Errorf("log file max backups must be between 0 and %d", MaxLogFileMaxBackups)
+	}
+
+	return nil
+}
+
 func ValidateImageReference(image string) error {
 	if strings.TrimSpace(image) == "" {
 		return fmt.Errorf("image name cannot be empty")
@@ -247,3 +434,294 @@ func ValidateEnvironmentVariables(env map[string]string) error {
 
 	return nil
 }
+
+// MaxLabels bounds the labels of one container
+const MaxLabels = 32
+
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_./-]{0,62}$`)
+
+// reservedLabels are set by the runner to track its containers and cannot be
+// overridden
+var reservedLabels = []string{
+	"managed-by", "isolation-runner", "container-name", "creation-timestamp",
+	"aux-container", "aux-container-name",
+}
+
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return fmt.Errorf("too many labels: %d (max: %d)", len(labels), MaxLabels)
+	}
+
+	for key, value := range labels {
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid label key '%s'", key)
+		}
+		if slices.Contains(reservedLabels, key) {
+			return fmt.Errorf("label '%s' is reserved", key)
+		}
+		if len(value) > 256 {
+			return fmt.Errorf("label '%s' value too large: %d bytes (max: 256)", key, len(value))
+		}
+		if strings.Contains(value, "\x00") {
+			return fmt.Errorf("label '%s' contains null byte", key)
+		}
+	}
+
+	return nil
+}
+
+func ValidatePorts(ports []PortMapping) error {
+	seen := make(map[string]bool)
+
+	for i, p := range ports {
+		if p.ContainerPort == 0 || p.ContainerPort > 65535 {
+			return fmt.Errorf("port %d has invalid container port: %d (must be 1-65535)", i, p.ContainerPort)
+		}
+		if p.HostPort > 65535 {
+			return fmt.Errorf("port %d has invalid host port: %d (must be 0-65535)", i, p.HostPort)
+		}
+
+		protocol := p.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		if protocol != "tcp" && protocol != "udp" {
+			return fmt.Errorf("port %d has invalid protocol '%s' (must be 'tcp' or 'udp')", i, p.Protocol)
+		}
+
+		key := fmt.Sprintf("%d/%s", p.ContainerPort, protocol)
+		if seen[key] {
+			return fmt.Errorf("container port %s is exposed more than once", key)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+var checkpointIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// ValidateCheckpointID checks that a checkpoint ID is safe to use as a
+// directory name under the checkpoint directory
+func ValidateCheckpointID(id string) error {
+	if !checkpointIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid checkpoint id '%s'", id)
+	}
+	return nil
+}
+
+// ValidateProbe checks that a probe names exactly one kind of check
+func ValidateProbe(probe *ProbeConfig) error {
+	if len(probe.Command) > 0 {
+		if probe.Port != 0 || probe.HTTPPath != "" {
+			return fmt.Errorf("probe must set either command or port, not both")
+		}
+		return nil
+	}
+
+	if probe.Port == 0 || probe.Port > 65535 {
+		return fmt.Errorf("probe must set command or a port between 1 and 65535")
+	}
+	if probe.HTTPPath != "" && !strings.HasPrefix(probe.HTTPPath, "/") {
+		return fmt.Errorf("probe http_path '%s' must start with /", probe.HTTPPath)
+	}
+	return nil
+}
+
+// ValidateRuntimeOptions checks that options are only given to a gVisor
+// runtime and only with values known to keep the sandbox usable
+func ValidateRuntimeOptions(runtime string, opts *RuntimeOptions) error {
+	if !strings.HasPrefix(runtime, "runsc") {
+		return fmt.Errorf("runtime options require a runsc runtime, not '%s'", runtime)
+	}
+
+	switch opts.Platform {
+	case "", "systrap", "ptrace", "kvm":
+	default:
+		return fmt.Errorf("invalid platform '%s' (must be systrap, ptrace or kvm)", opts.Platform)
+	}
+
+	switch opts.Network {
+	case "", "sandbox", "host":
+	default:
+		return fmt.Errorf("invalid network '%s' (must be sandbox or host)", opts.Network)
+	}
+
+	return nil
+}
+
+// MaxAuxContainers bounds the sidecars, and separately the init containers, of
+// one container
+const MaxAuxContainers = 4
+
+var auxContainerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// ValidateAuxContainers checks that the sidecars or init containers, as named
+// by kind, have unique, DNS-safe names and valid images and environments
+func ValidateAuxContainers(kind string, containers []AuxContainerConfig) error {
+	if len(containers) > MaxAuxContainers {
+		return fmt.Errorf("too many %ss: %d (max: %d)", kind, len(containers), MaxAuxContainers)
+	}
+
+	names := make(map[string]bool, len(containers))
+	for i := range containers {
+		aux := &containers[i]
+		if !auxContainerNamePattern.MatchString(aux.Name) {
+			return fmt.Errorf("invalid %s name '%s'", kind, aux.Name)
+		}
+		if names[aux.Name] {
+			return fmt.Errorf("duplicate %s name '%s'", kind, aux.Name)
+		}
+		names[aux.Name] = true
+
+		if err := ValidateImageSpec(aux.ImageSpec); err != nil {
+			return fmt.Errorf("%s '%s': %w", kind, aux.Name, err)
+		}
+		if err := ValidateEnvironmentVariables(aux.Environment); err != nil {
+			return fmt.Errorf("%s '%s': %w", kind, aux.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Hooks are bounded in number and each in how long it may hold up cleanup
+const (
+	MaxPostExitHooks   = 4
+	DefaultHookTimeout = 30 * time.Second
+	MaxHookTimeoutSecs = 600
+)
+
+// ValidateHooks checks that each hook has a unique name, exactly one action
+// and a bounded timeout. Webhooks must use https.
+func ValidateHooks(hooks []HookConfig) error {
+	if len(hooks) > MaxPostExitHooks {
+		return fmt.Errorf("too many post-exit hooks: %d (max: %d)", len(hooks), MaxPostExitHooks)
+	}
+
+	var containers []AuxContainerConfig
+	names := make(map[string]bool, len(hooks))
+	for i := range hooks {
+		hook := &hooks[i]
+		if !auxContainerNamePattern.MatchString(hook.Name) {
+			return fmt.Errorf("invalid hook name '%s'", hook.Name)
+		}
+		if names[hook.Name] {
+			return fmt.Errorf("duplicate hook name '%s'", hook.Name)
+		}
+		names[hook.Name] = true
+
+		if (hook.Container == nil) == (hook.WebhookURL == "") {
+			return fmt.Errorf("hook '%s' must set exactly one of container or webhook_url", hook.Name)
+		}
+		if hook.TimeoutSecs > MaxHookTimeoutSecs {
+			return fmt.Errorf("hook '%s' timeout too long: %ds (max: %ds)", hook.Name, hook.TimeoutSecs, MaxHookTimeoutSecs)
+		}
+
+		if hook.Container != nil {
+			hook.Container.Name = hook.Name
+			containers = append(containers, *hook.Container)
+			continue
+		}
+		u, err := url.Parse(hook.WebhookURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("hook '%s' has an invalid webhook url", hook.Name)
+		}
+		if u.Scheme != "https" {
+			return fmt.Errorf("hook '%s' webhook url must use https", hook.Name)
+		}
+	}
+
+	return ValidateAuxContainers("hook", containers)
+}
+
+// ValidateWorkspace checks that a workspace names exactly one archive source, a
+// usable target directory and a known mount type
+func ValidateWorkspace(ws *WorkspaceConfig) error {
+	if (len(ws.Archive) == 0) == (ws.URL == "") {
+		return fmt.Errorf("workspace must set exactly one of archive or url")
+	}
+	if len(ws.Archive) > MaxWorkspaceBytes {
+		return fmt.Errorf("workspace archive too large: %d bytes (max: %d)", len(ws.Archive), MaxWorkspaceBytes)
+	}
+
+	if ws.URL != "" {
+		u, err := url.Parse(ws.URL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid workspace url")
+		}
+		if u.Scheme != "https" {
+			return fmt.Errorf("workspace url must use https")
+		}
+	}
+
+	if !path.IsAbs(ws.Path) {
+		return fmt.Errorf("workspace path '%s' must be absolute", ws.Path)
+	}
+	if strings.ContainsRune(ws.Path, 0) {
+		return fmt.Errorf("workspace path contains null byte")
+	}
+	for _, segment := range strings.Split(ws.Path, "/") {
+		if segment == ".." {
+			return fmt.Errorf("workspace path '%s' must not contain .. segments", ws.Path)
+		}
+	}
+	cleaned := path.Clean(ws.Path)
+	if cleaned == "/" {
+		return fmt.Errorf("workspace cannot be mounted over the root directory")
+	}
+	for _, reserved := range []string{"/proc", "/sys", "/dev", "/etc"} {
+		if cleaned == reserved || strings.HasPrefix(cleaned, reserved+"/") {
+			return fmt.Errorf("workspace path '%s' is not allowed", ws.Path)
+		}
+	}
+
+	switch ws.Mount {
+	case "", "volume", "tmpfs":
+	default:
+		return fmt.Errorf("invalid workspace mount '%s' (must be 'volume' or 'tmpfs')", ws.Mount)
+	}
+
+	return nil
+}
+
+// ValidateStaticIP checks that ip can be assigned to a container inside subnet and
+// returns the subnet in CIDR form. Without a subnet the /24 containing ip is used,
+// matching the size of networks handed out by the bastion pool. The network,
+// gateway (first host) and broadcast addresses are rejected.
+func ValidateStaticIP(ip string, subnet *string) (string, error) {
+	addr := net.ParseIP(ip).To4()
+	if addr == nil {
+		return "", fmt.Errorf("static IP '%s' must be an IPv4 address", ip)
+	}
+
+	cidr := fmt.Sprintf("%s/24", addr.Mask(net.CIDRMask(24, 32)))
+	if subnet != nil && *subnet != "" {
+		cidr = *subnet
+	}
+
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil || ipNet.IP.To4() == nil {
+		return "", fmt.Errorf("invalid subnet '%s': must be an IPv4 CIDR", cidr)
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones < 2 {
+		return "", fmt.Errorf("subnet '%s' is too small to hold a container address", cidr)
+	}
+	if !ipNet.Contains(addr) {
+		return "", fmt.Errorf("static IP %s is outside subnet %s", addr, ipNet)
+	}
+
+	network := binary.BigEndian.Uint32(ipNet.IP.To4())
+	broadcast := network | ^binary.BigEndian.Uint32(ipNet.Mask)
+	switch binary.BigEndian.Uint32(addr) {
+	case network:
+		return "", fmt.Errorf("static IP %s is the network address of %s", addr, ipNet)
+	case network + 1:
+		return "", fmt.Errorf("static IP %s is reserved for the gateway of %s", addr, ipNet)
+	case broadcast:
+		return "", fmt.Errorf("static IP %s is the broadcast address of %s", addr, ipNet)
+	}
+
+	return ipNet.String(), nil
+}
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
 )
 
 func TestValidateImageReference(t *testing.T) {
@@ -104,6 +111,392 @@ func TestValidateEnvironmentVariables(t *testing.T) {
 	}
 }
 
+func TestValidateStaticIP(t *testing.T) {
+	subnet := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		ip         string
+		subnet     *string
+		wantSubnet string
+		wantErr    bool
+	}{
+		{"derived /24", "10.20.5.10", nil, "10.20.5.0/24", false},
+		{"explicit subnet", "10.20.5.10", subnet("10.20.0.0/16"), "10.20.0.0/16", false},
+		{"non-canonical subnet", "10.20.5.10", subnet("10.20.5.7/24"), "10.20.5.0/24", false},
+		{"outside subnet", "10.20.6.10", subnet("10.20.5.0/24"), "", true},
+		{"network address", "10.20.5.0", nil, "", true},
+		{"gateway", "10.20.5.1", nil, "", true},
+		{"broadcast", "10.20.5.255", nil, "", true},
+		{"ipv6", "fd00::10", nil, "", true},
+		{"invalid ip", "not-an-ip", nil, "", true},
+		{"invalid subnet", "10.20.5.10", subnet("10.20.5.0"), "", true},
+		{"subnet too small", "10.20.5.10", subnet("10.20.5.10/32"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateStaticIP(tt.ip, tt.subnet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateStaticIP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantSubnet {
+				t.Errorf("ValidateStaticIP() = %s, want %s", got, tt.wantSubnet)
+			}
+		})
+	}
+}
+
+func TestValidatePorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		ports   []PortMapping
+		wantErr bool
+	}{
+		{"random host port", []PortMapping{{ContainerPort: 8080}}, false},
+		{"fixed host port", []PortMapping{{ContainerPort: 8080, HostPort: 30080, Protocol: "tcp"}}, false},
+		{"same port on both protocols", []PortMapping{{ContainerPort: 53}, {ContainerPort: 53, Protocol: "udp"}}, false},
+		{"zero container port", []PortMapping{{ContainerPort: 0}}, true},
+		{"container port too high", []PortMapping{{ContainerPort: 70000}}, true},
+		{"host port too high", []PortMapping{{ContainerPort: 80, HostPort: 70000}}, true},
+		{"invalid protocol", []PortMapping{{ContainerPort: 80, Protocol: "sctp"}}, true},
+		{"duplicate", []PortMapping{{ContainerPort: 80}, {ContainerPort: 80, Protocol: "tcp"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePorts(tt.ports)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePorts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWorkspace(t *testing.T) {
+	archive := []byte{0x1f, 0x8b}
+
+	tests := []struct {
+		name    string
+		ws      WorkspaceConfig
+		wantErr bool
+	}{
+		{"inline archive", WorkspaceConfig{Archive: archive, Path: "/workspace"}, false},
+		{"url on tmpfs", WorkspaceConfig{URL: "https://bucket.example.com/ws.tar.gz?sig=abc", Path: "/code", Mount: "tmpfs"}, false},
+		{"explicit volume", WorkspaceConfig{Archive: archive, Path: "/home/app/src", Mount: "volume"}, false},
+		{"no source", WorkspaceConfig{Path: "/workspace"}, true},
+		{"both sources", WorkspaceConfig{Archive: archive, URL: "https://example.com/ws.tar.gz", Path: "/workspace"}, true},
+		{"plain http url", WorkspaceConfig{URL: "http://example.com/ws.tar.gz", Path: "/workspace"}, true},
+		{"file url", WorkspaceConfig{URL: "file:///etc/shadow", Path: "/workspace"}, true},
+		{"relative path", WorkspaceConfig{Archive: archive, Path: "workspace"}, true},
+		{"root path", WorkspaceConfig{Archive: archive, Path: "/"}, true},
+		{"parent segment", WorkspaceConfig{Archive: archive, Path: "/workspace/../etc"}, true},
+		{"reserved path", WorkspaceConfig{Archive: archive, Path: "/etc/app"}, true},
+		{"unknown mount", WorkspaceConfig{Archive: archive, Path: "/workspace", Mount: "bind"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWorkspace(&tt.ws)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCheckpointID(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"warm-python", false},
+		{"py3.12_numpy", false},
+		{"a", false},
+		{"", true},
+		{".hidden", true},
+		{"../escape", true},
+		{"nested/dir", true},
+		{"with space", true},
+		{string(make([]byte, 65)), true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateCheckpointID(tt.id); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateCheckpointID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		probe   ProbeConfig
+		wantErr bool
+	}{
+		{"tcp", ProbeConfig{Port: 8080}, false},
+		{"http", ProbeConfig{Port: 8080, HTTPPath: "/healthz"}, false},
+		{"command", ProbeConfig{Command: []string{"pg_isready"}}, false},
+		{"empty", ProbeConfig{}, true},
+		{"command and port", ProbeConfig{Command: []string{"true"}, Port: 80}, true},
+		{"port out of range", ProbeConfig{Port: 70000}, true},
+		{"relative http path", ProbeConfig{Port: 80, HTTPPath: "healthz"}, true},
+		{"http path without port", ProbeConfig{HTTPPath: "/healthz"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateProbe(&tt.probe); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProbe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRuntimeOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		runtime string
+		opts    RuntimeOptions
+		wantErr bool
+	}{
+		{"empty", "runsc", RuntimeOptions{}, false},
+		{"kvm platform", "runsc", RuntimeOptions{Platform: "kvm"}, false},
+		{"host network", "runsc-kvm", RuntimeOptions{Network: "host"}, false},
+		{"unknown platform", "runsc", RuntimeOptions{Platform: "xen"}, true},
+		{"no network", "runsc", RuntimeOptions{Network: "none"}, true},
+		{"not gvisor", "runc", RuntimeOptions{Platform: "kvm"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRuntimeOptions(tt.runtime, &tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRuntimeOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuntimeOptionsAnnotations(t *testing.T) {
+	directFS := false
+	opts := RuntimeOptions{Platform: "kvm", DirectFS: &directFS}
+
+	annotations := opts.Annotations()
+	if len(annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %v", annotations)
+	}
+	if annotations["dev.gvisor.flag.platform"] != "kvm" {
+		t.Errorf("unexpected platform annotation %q", annotations["dev.gvisor.flag.platform"])
+	}
+	if annotations["dev.gvisor.flag.directfs"] != "false" {
+		t.Errorf("unexpected directfs annotation %q", annotations["dev.gvisor.flag.directfs"])
+	}
+}
+
+func TestValidateAuxContainers(t *testing.T) {
+	db := AuxContainerConfig{Name: "db", ImageSpec: &ImageSpec{Image: "postgres:16"}}
+
+	tests := []struct {
+		name       string
+		containers []AuxContainerConfig
+		wantErr    bool
+	}{
+		{"none", nil, false},
+		{"one", []AuxContainerConfig{db}, false},
+		{"duplicate name", []AuxContainerConfig{db, db}, true},
+		{"uppercase name", []AuxContainerConfig{{Name: "DB", ImageSpec: db.ImageSpec}}, true},
+		{"no image", []AuxContainerConfig{{Name: "db"}}, true},
+		{"bad image", []AuxContainerConfig{{Name: "db", ImageSpec: &ImageSpec{Image: "a;b"}}}, true},
+		{"too many", []AuxContainerConfig{
+			{Name: "a", ImageSpec: db.ImageSpec}, {Name: "b", ImageSpec: db.ImageSpec},
+			{Name: "c", ImageSpec: db.ImageSpec}, {Name: "d", ImageSpec: db.ImageSpec},
+			{Name: "e", ImageSpec: db.ImageSpec},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateAuxContainers("sidecar", tt.containers); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAuxContainers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateHooks(t *testing.T) {
+	helper := &AuxContainerConfig{ImageSpec: &ImageSpec{Image: "busybox"}}
+
+	tests := []struct {
+		name    string
+		hooks   []HookConfig
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"webhook", []HookConfig{{Name: "notify", WebhookURL: "https://example.com/done"}}, false},
+		{"container", []HookConfig{{Name: "collect", Container: helper}}, false},
+		{"both", []HookConfig{{Name: "x", Container: helper, WebhookURL: "https://example.com"}}, true},
+		{"neither", []HookConfig{{Name: "x"}}, true},
+		{"http webhook", []HookConfig{{Name: "x", WebhookURL: "http://example.com"}}, true},
+		{"bad name", []HookConfig{{Name: "X Y", WebhookURL: "https://example.com"}}, true},
+		{"duplicate", []HookConfig{
+			{Name: "x", WebhookURL: "https://example.com"},
+			{Name: "x", WebhookURL: "https://example.org"},
+		}, true},
+		{"timeout too long", []HookConfig{{Name: "x", WebhookURL: "https://example.com", TimeoutSecs: MaxHookTimeoutSecs + 1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateHooks(tt.hooks); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHooks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if helper.Name != "collect" {
+		t.Errorf("expected the hook container to take the hook's name, got %q", helper.Name)
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"valid", map[string]string{"team": "ml", "app.example.com/tier": "batch"}, false},
+		{"reserved", map[string]string{"container-name": "other"}, true},
+		{"bad key", map[string]string{"team=ml": "x"}, true},
+		{"empty key", map[string]string{"": "x"}, true},
+		{"long value", map[string]string{"team": strings.Repeat("a", 257)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateLabels(tt.labels); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLogFile(t *testing.T) {
+	path := func(p string) *string { return &p }
+	tests := []struct {
+		name    string
+		logging LoggingConfig
+		wantErr bool
+	}{
+		{"none", LoggingConfig{}, false},
+		{"defaults", LoggingConfig{LogFile: path("/var/log/holopod/run.log")}, false},
+		{"relative", LoggingConfig{LogFile: path("run.log")}, true},
+		{"unclean", LoggingConfig{LogFile: path("/var/log/../run.log")}, true},
+		{"proc", LoggingConfig{LogFile: path("/proc/self/fd/1")}, true},
+		{"tiny", LoggingConfig{LogFile: path("/tmp/run.log"), LogFileMaxBytes: 10}, true},
+		{"too many backups", LoggingConfig{LogFile: path("/tmp/run.log"), LogFileMaxBackups: MaxLogFileMaxBackups + 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateLogFile(&tt.logging); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLogFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && tt.logging.LogFile != nil && tt.logging.LogFileMaxBytes != DefaultLogFileMaxBytes {
+				t.Errorf("expected the default max bytes, got %d", tt.logging.LogFileMaxBytes)
+			}
+		})
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	input, err := ParseInput([]byte(`{"type":"config","schema_version":1,"config":{"image_spec":{"image":"alpine"}}}`))
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	if input.ImageSpec.Image != "alpine" {
+		t.Errorf("expected image alpine, got %q", input.ImageSpec.Image)
+	}
+
+	if _, err := ParseInput([]byte(`{"type":"config","config":{}}`)); err != nil {
+		t.Errorf("expected a config without a schema version to parse as version 1, got %v", err)
+	}
+
+	// A future schema is rejected on its version, not on its fields
+	_, err = ParseInput([]byte(`{"type":"config","schema_version":2,"config":{"command":"not a list"}}`))
+	var schemaErr *SchemaVersionError
+	if !errors.As(err, &schemaErr) || schemaErr.Version != 2 {
+		t.Errorf("expected SchemaVersionError for version 2, got %v", err)
+	}
+
+	var malformedErr *MalformedInputError
+	if _, err := ParseInput([]byte(`{"type":`)); !errors.As(err, &malformedErr) {
+		t.Errorf("expected MalformedInputError, got %v", err)
+	}
+
+	input, err = ParseInput([]byte(`{"type":"config","config":{"run_id":"run_0123abcd"}}`))
+	if err != nil || input.RunID != "run_0123abcd" {
+		t.Errorf("expected run ID run_0123abcd, got %+v, %v", input, err)
+	}
+	if _, err := ParseInput([]byte(`{"type":"config","config":{"run_id":"bad\nid"}}`)); err == nil {
+		t.Error("expected an error for a run ID with a newline")
+	}
+}
+
+func TestInputErrorsAreConfigErrors(t *testing.T) {
+	inputs := map[string]string{
+		"malformed":      `{"type":`,
+		"future schema":  `{"type":"config","schema_version":2}`,
+		"wrong type":     `{"type":"start"}`,
+		"missing config": `{"type":"config"}`,
+		"bad run id":     `{"type":"config","config":{"run_id":"-"}}`,
+	}
+	for name, line := range inputs {
+		_, err := ParseInput([]byte(line))
+		if ierrors.KindOf(err) != ierrors.KindConfigInvalid || ierrors.GetExitCode(err) != int(ierrors.ExitConfigError) {
+			t.Errorf("%s: expected a config error, got %v", name, err)
+		}
+	}
+
+	if _, err := ReadInputFromFile("/nonexistent/config.json"); ierrors.KindOf(err) != ierrors.KindConfigInvalid {
+		t.Errorf("expected a config error for a missing file, got %v", err)
+	}
+	if _, err := ReadInputFromFD(1); ierrors.KindOf(err) != ierrors.KindConfigInvalid {
+		t.Errorf("expected a config error for fd 1, got %v", err)
+	}
+}
+
+func TestReadInputFromFileAndFD(t *testing.T) {
+	// Unlike stdin, a file may hold pretty-printed JSON
+	doc := "{\n  \"type\": \"config\",\n  \"config\": {\"image_spec\": {\"image\": \"alpine\"}}\n}\n"
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input, err := ReadInputFromFile(path)
+	if err != nil || input.ImageSpec.Image != "alpine" {
+		t.Fatalf("ReadInputFromFile() = %v, %v", input, err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		w.WriteString(doc)
+		w.Close()
+	}()
+	input, err = ReadInputFromFD(int(r.Fd()))
+	if err != nil || input.ImageSpec.Image != "alpine" {
+		t.Fatalf("ReadInputFromFD() = %v, %v", input, err)
+	}
+
+	if _, err := ReadInputFromFD(0); err == nil {
+		t.Error("expected an error for reading the config from stdin's descriptor")
+	}
+}
+
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -123,3 +516,31 @@ func TestDefaultConfig(t *testing.T) {
 		t.Error("expected Logging.Enabled to be true")
 	}
 }
+
+func TestIsStandaloneMode(t *testing.T) {
+	t.Setenv("ISOLATION_RUNNER_STANDALONE", "")
+	if IsStandaloneMode() {
+		t.Error("IsStandaloneMode() = true with env unset")
+	}
+
+	t.Setenv("ISOLATION_RUNNER_STANDALONE", "true")
+	if !IsStandaloneMode() {
+		t.Error("IsStandaloneMode() = false with ISOLATION_RUNNER_STANDALONE=true")
+	}
+}
+
+func TestGetHeartbeatInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":      DefaultHeartbeatInterval,
+		"30s":   30 * time.Second,
+		"0":     0,
+		"-1s":   DefaultHeartbeatInterval,
+		"bogus": DefaultHeartbeatInterval,
+	}
+	for value, want := range tests {
+		t.Setenv("HOLOPOD_HEARTBEAT_INTERVAL", value)
+		if got := GetHeartbeatInterval(); got != want {
+			t.Errorf("GetHeartbeatInterval() with %q = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -4,18 +4,57 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"slices"
 
 	"github.com/google/uuid"
+	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
 )
 
+// runIDPattern bounds the run ID, which is sent on as gRPC metadata
+var runIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+// SupportedSchemaVersions lists the config schema versions this runner
+// accepts, as advertised in its runner_hello event
+var SupportedSchemaVersions = []int{1}
+
+// SchemaVersionError is returned for a config whose schema version this
+// runner does not support
+type SchemaVersionError struct {
+	Version int
+}
+
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf("unsupported config schema version %d (supported: %v)", e.Version, SupportedSchemaVersions)
+}
+
+// MalformedInputError is returned for a config message that is not valid JSON
+// of the expected shape
+type MalformedInputError struct {
+	Err error
+}
+
+func (e *MalformedInputError) Error() string {
+	return fmt.Sprintf("failed to parse input JSON: %v", e.Err)
+}
+
+func (e *MalformedInputError) Unwrap() error {
+	return e.Err
+}
+
 type ContainerInput struct {
+	// RunID is the container-manager's ID for this run, stamped on every
+	// event and bastion call
+	RunID         string     `json:"run_id,omitempty"`
 	ImageSpec     *ImageSpec `json:"image_spec"`
 	Command       []string   `json:"command"`
 	Args          []string   `json:"args"`
 	ContainerName *string    `json:"container_name"`
 	BridgeName    *string    `json:"bridge_name"`
 	Subnet        *string    `json:"subnet"`
+	StaticIP      *string    `json:"static_ip"`
 	Config        Config     `json:"config"`
 }
 
@@ -71,12 +110,16 @@ func (c *ContainerInput) GetFullImageReference() string {
 		return "library/alpine:latest"
 	}
 
-	registry := c.ImageSpec.Registry
-	if registry == "" || registry == "registry-1.docker.io" {
-		return c.ImageSpec.Image
+	return c.ImageSpec.Reference()
+}
+
+// Reference returns the image reference for the Docker API
+func (s *ImageSpec) Reference() string {
+	if s.Registry == "" || s.Registry == "registry-1.docker.io" {
+		return s.Image
 	}
 
-	return fmt.Sprintf("%s/%s", registry, c.ImageSpec.Image)
+	return fmt.Sprintf("%s/%s", s.Registry, s.Image)
 }
 
 // GetImageDisplayName returns sanitized image name for logging (no credentials)
@@ -97,11 +140,64 @@ func ReadInputFromStdin() (*ContainerInput, error) {
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadString('\n')
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from stdin: %w", err)
+		return nil, ierrors.NewConfigError("failed to read from stdin", err)
 	}
 
 	if len(line) == 0 {
-		return nil, fmt.Errorf("no input provided on stdin")
+		return nil, ierrors.NewConfigError("no input provided on stdin", nil)
+	}
+
+	return ParseInput([]byte(line))
+}
+
+// ReadInputFromFile reads the config message from a file, which may span
+// several lines, leaving stdin to the workload
+func ReadInputFromFile(path string) (*ContainerInput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ierrors.NewConfigError("failed to read config file", err)
+	}
+	return ParseInput(data)
+}
+
+// ReadInputFromFD reads the config message from an inherited file descriptor
+// until its writer closes it, leaving stdin to the workload
+func ReadInputFromFD(fd int) (*ContainerInput, error) {
+	if fd < 3 {
+		return nil, ierrors.NewConfigError(fmt.Sprintf("config fd must not be stdin, stdout or stderr: %d", fd), nil)
+	}
+
+	file := os.NewFile(uintptr(fd), "config")
+	if file == nil {
+		return nil, ierrors.NewConfigError(fmt.Sprintf("invalid config fd: %d", fd), nil)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, ierrors.NewConfigError(fmt.Sprintf("failed to read config fd %d", fd), err)
+	}
+	return ParseInput(data)
+}
+
+// ParseInput parses the config message the container-manager writes. The
+// schema version is checked before anything else is decoded, so a config this
+// runner cannot understand fails with a SchemaVersionError rather than
+// whatever field happens to mismatch first. Every error is a config error.
+func ParseInput(line []byte) (*ContainerInput, error) {
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, ierrors.Wrap(ierrors.KindConfigInvalid, ierrors.ExitConfigError, &MalformedInputError{Err: err})
+	}
+	schemaVersion := header.SchemaVersion
+	if schemaVersion == 0 {
+		// Managers predating the handshake send no version
+		schemaVersion = 1
+	}
+	if !slices.Contains(SupportedSchemaVersions, schemaVersion) {
+		return nil, ierrors.Wrap(ierrors.KindConfigInvalid, ierrors.ExitConfigError, &SchemaVersionError{Version: schemaVersion})
 	}
 
 	var msg struct {
@@ -109,16 +205,20 @@ func ReadInputFromStdin() (*ContainerInput, error) {
 		Config *ContainerInput `json:"config"`
 	}
 
-	if err := json.Unmarshal([]byte(line), &msg); err != nil {
-		return nil, fmt.Errorf("failed to parse input JSON: %w", err)
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return nil, ierrors.Wrap(ierrors.KindConfigInvalid, ierrors.ExitConfigError, &MalformedInputError{Err: err})
 	}
 
 	if msg.Type != "config" {
-		return nil, fmt.Errorf("expected config message, got: %s", msg.Type)
+		return nil, ierrors.NewConfigError(fmt.Sprintf("expected config message, got: %s", msg.Type), nil)
 	}
 
 	if msg.Config == nil {
-		return nil, fmt.Errorf("config field is missing")
+		return nil, ierrors.NewConfigError("config field is missing", nil)
+	}
+
+	if id := msg.Config.RunID; id != "" && !runIDPattern.MatchString(id) {
+		return nil, ierrors.NewConfigError(fmt.Sprintf("invalid run ID %q", id), nil)
 	}
 
 	return msg.Config, nil
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net"
 	"strings"
+
+	"github.com/metorial/fleet/holopod/internal/bastion/pkg/validation"
 )
 
 // Hardcoded security rules that CANNOT be disabled or bypassed
@@ -26,6 +28,16 @@ const (
 	Reserved240 = "240.0.0.0/4"        // Reserved
 	Broadcast   = "255.255.255.255/32" // Broadcast
 	ZeroConf    = "0.0.0.0/8"          // This network
+
+	// Link-local discovery and autoconfiguration - ALWAYS blocked so a container
+	// can neither probe its neighbours nor answer for them
+	MDNSIPv4       = "224.0.0.251/32" // Multicast DNS
+	MDNSIPv6       = "ff02::fb/128"   // Multicast DNS
+	LLMNRIPv4      = "224.0.0.252/32" // Link-Local Multicast Name Resolution
+	LLMNRIPv6      = "ff02::1:3/128"  // Link-Local Multicast Name Resolution
+	AllNodesIPv6   = "ff02::1/128"    // Router advertisements
+	AllRoutersIPv6 = "ff02::2/128"    // Router solicitations
+	DHCPv6Servers  = "ff02::1:2/128"  // DHCPv6 relay agents and servers
 )
 
 // MandatoryBlockedRanges are ALWAYS blocked and cannot be whitelisted
@@ -38,6 +50,13 @@ var MandatoryBlockedRanges = []string{
 	Reserved240,
 	Broadcast,
 	ZeroConf,
+	MDNSIPv4,
+	MDNSIPv6,
+	LLMNRIPv4,
+	LLMNRIPv6,
+	AllNodesIPv6,
+	AllRoutersIPv6,
+	DHCPv6Servers,
 }
 
 // PrivateRanges are blocked by default but can be whitelisted
@@ -150,14 +169,21 @@ func createMandatoryBlacklist() []BlacklistEntry {
 	entries := make([]BlacklistEntry, 0, len(MandatoryBlockedRanges))
 
 	descriptions := map[string]string{
-		LocalhostIPv4: "Localhost (MANDATORY BLOCK)",
-		LocalhostIPv6: "Localhost IPv6 (MANDATORY BLOCK)",
-		CloudMetadata: "Cloud provider metadata service (MANDATORY BLOCK)",
-		LinkLocal:     "Link-local addresses (MANDATORY BLOCK)",
-		Multicast:     "Multicast addresses (MANDATORY BLOCK)",
-		Reserved240:   "Reserved addresses (MANDATORY BLOCK)",
-		Broadcast:     "Broadcast address (MANDATORY BLOCK)",
-		ZeroConf:      "Zero configuration network (MANDATORY BLOCK)",
+		LocalhostIPv4:  "Localhost (MANDATORY BLOCK)",
+		LocalhostIPv6:  "Localhost IPv6 (MANDATORY BLOCK)",
+		CloudMetadata:  "Cloud provider metadata service (MANDATORY BLOCK)",
+		LinkLocal:      "Link-local addresses (MANDATORY BLOCK)",
+		Multicast:      "Multicast addresses (MANDATORY BLOCK)",
+		Reserved240:    "Reserved addresses (MANDATORY BLOCK)",
+		Broadcast:      "Broadcast address (MANDATORY BLOCK)",
+		ZeroConf:       "Zero configuration network (MANDATORY BLOCK)",
+		MDNSIPv4:       "Multicast DNS (MANDATORY BLOCK)",
+		MDNSIPv6:       "Multicast DNS IPv6 (MANDATORY BLOCK)",
+		LLMNRIPv4:      "LLMNR (MANDATORY BLOCK)",
+		LLMNRIPv6:      "LLMNR IPv6 (MANDATORY BLOCK)",
+		AllNodesIPv6:   "IPv6 router advertisements (MANDATORY BLOCK)",
+		AllRoutersIPv6: "IPv6 router solicitations (MANDATORY BLOCK)",
+		DHCPv6Servers:  "DHCPv6 servers (MANDATORY BLOCK)",
 	}
 
 	for _, cidr := range MandatoryBlockedRanges {
@@ -283,5 +309,21 @@ func ValidateNetworkConfig(cfg *NetworkConfig) error {
 		}
 	}
 
+	// Validate DNS domain allowlist
+	if len(cfg.DNSAllowedDomains) > 0 && !cfg.AllowDNS {
+		return fmt.Errorf("dns_allowed_domains requires allow_dns")
+	}
+	for i, domain := range cfg.DNSAllowedDomains {
+		if err := validation.ValidateDomainPattern(domain); err != nil {
+			return fmt.Errorf("DNS allowed domain %d is invalid: %w", i, err)
+		}
+	}
+
+	if cfg.PodGroup != "" {
+		if err := validation.ValidatePodGroup(cfg.PodGroup); err != nil {
+			return fmt.Errorf("invalid pod group: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -43,6 +43,30 @@ func TestValidateWhitelistEntry_LocalhostBlocked(t *testing.T) {
 			wantErr: true,
 			errMsg:  "forbidden",
 		},
+		{
+			name:    "mDNS group",
+			cidr:    "224.0.0.251/32",
+			wantErr: true,
+			errMsg:  "forbidden",
+		},
+		{
+			name:    "mDNS IPv6 group",
+			cidr:    "ff02::fb/128",
+			wantErr: true,
+			errMsg:  "forbidden",
+		},
+		{
+			name:    "LLMNR IPv6 group",
+			cidr:    "ff02::1:3/128",
+			wantErr: true,
+			errMsg:  "forbidden",
+		},
+		{
+			name:    "DHCPv6 servers",
+			cidr:    "ff02::1:2/128",
+			wantErr: true,
+			errMsg:  "forbidden",
+		},
 		{
 			name:    "Valid public IP",
 			cidr:    "8.8.8.8/32",
@@ -170,6 +194,43 @@ func TestEnforceSecurityRules_MandatoryBlocks(t *testing.T) {
 	}
 }
 
+func TestEnforceSecurityRules_DiscoveryBlocks(t *testing.T) {
+	cfg := &NetworkConfig{
+		Whitelist:     []WhitelistEntry{{CIDR: "::/0"}},
+		Blacklist:     []BlacklistEntry{},
+		DefaultPolicy: "allow",
+		BlockMetadata: true,
+	}
+
+	err := EnforceSecurityRules(cfg)
+	if err != nil {
+		t.Fatalf("EnforceSecurityRules failed: %v", err)
+	}
+
+	// Check that discovery and autoconfiguration groups are in blacklist
+	mustHave := []string{
+		"224.0.0.251/32", // mDNS
+		"ff02::fb/128",   // mDNS IPv6
+		"224.0.0.252/32", // LLMNR
+		"ff02::1:3/128",  // LLMNR IPv6
+		"ff02::1/128",    // Router advertisements
+		"ff02::1:2/128",  // DHCPv6
+	}
+
+	for _, cidr := range mustHave {
+		found := false
+		for _, entry := range cfg.Blacklist {
+			if entry.CIDR == cidr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Mandatory block %s not found in blacklist", cidr)
+		}
+	}
+}
+
 func TestEnforceSecurityRules_PrivateRangesBlocked(t *testing.T) {
 	cfg := &NetworkConfig{
 		Whitelist:     []WhitelistEntry{},
@@ -345,6 +406,91 @@ func TestValidateNetworkConfig_InvalidDNS(t *testing.T) {
 	}
 }
 
+func TestValidateNetworkConfig_DNSAllowedDomains(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowDNS bool
+		domains  []string
+		wantErr  bool
+	}{
+		{
+			name:     "Exact and wildcard domains",
+			allowDNS: true,
+			domains:  []string{"example.com", "*.example.org"},
+			wantErr:  false,
+		},
+		{
+			name:     "Allowlist without DNS",
+			allowDNS: false,
+			domains:  []string{"example.com"},
+			wantErr:  true,
+		},
+		{
+			name:     "Inner wildcard rejected",
+			allowDNS: true,
+			domains:  []string{"api.*.example.com"},
+			wantErr:  true,
+		},
+		{
+			name:     "Empty entry rejected",
+			allowDNS: true,
+			domains:  []string{""},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &NetworkConfig{
+				DefaultPolicy:     "deny",
+				BlockMetadata:     true,
+				AllowDNS:          tt.allowDNS,
+				DNSAllowedDomains: tt.domains,
+			}
+
+			err := ValidateNetworkConfig(cfg)
+
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected error for domains %v, got none", tt.domains)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error for domains %v: %v", tt.domains, err)
+			}
+		})
+	}
+}
+
+func TestValidateNetworkConfig_PodGroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		podGroup string
+		wantErr  bool
+	}{
+		{name: "No group", podGroup: "", wantErr: false},
+		{name: "Valid group", podGroup: "tenant-1.web", wantErr: false},
+		{name: "Invalid characters", podGroup: "web tier", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &NetworkConfig{
+				DefaultPolicy: "deny",
+				BlockMetadata: true,
+				PodGroup:      tt.podGroup,
+			}
+
+			err := ValidateNetworkConfig(cfg)
+
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected error for pod group %q, got none", tt.podGroup)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error for pod group %q: %v", tt.podGroup, err)
+			}
+		})
+	}
+}
+
 func TestValidateNetworkConfig_DefaultPolicy(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,144 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+)
+
+// Sidecars and init containers are auxiliary containers: they have images of
+// their own but the main container's runtime and hardening. kind names which
+// one a container is in errors and labels.
+
+// createAuxContainer pulls the image of an auxiliary container and creates it
+// in networkMode with its own resource limits and the given mounts
+func (m *Manager) createAuxContainer(ctx context.Context, kind string, aux *config.AuxContainerConfig, networkMode string, mounts []mount.Mount) (string, error) {
+	imageRef := aux.ImageSpec.Reference()
+	if err := m.PullImage(ctx, imageRef, aux.ImageSpec.Auth); err != nil {
+		return "", fmt.Errorf("%s '%s': %w", kind, aux.Name, err)
+	}
+
+	// SECURITY: Credentials are not needed once the image is pulled
+	if aux.ImageSpec.Auth != nil {
+		aux.ImageSpec.Auth.Username = ""
+		aux.ImageSpec.Auth.Password = ""
+	}
+
+	hostConfig := &container.HostConfig{
+		Runtime:     m.config.Container.Runtime,
+		NetworkMode: container.NetworkMode(networkMode),
+		CapDrop:     []string{"ALL"},
+		SecurityOpt: []string{"no-new-privileges:true"},
+		Mounts:      mounts,
+	}
+	if opts := m.config.Container.RuntimeOptions; opts != nil {
+		hostConfig.Annotations = opts.Annotations()
+	}
+
+	if aux.MemoryLimit != nil {
+		mem, err := parseMemoryLimit(*aux.MemoryLimit)
+		if err != nil {
+			return "", fmt.Errorf("%s '%s': %w", kind, aux.Name, err)
+		}
+		hostConfig.Memory = mem
+	}
+	if aux.CPULimit != nil {
+		nano, err := parseCPULimit(*aux.CPULimit)
+		if err != nil {
+			return "", fmt.Errorf("%s '%s': %w", kind, aux.Name, err)
+		}
+		hostConfig.NanoCPUs = nano
+	}
+
+	// DNS servers can only be set on a container with a network of its own
+	if !hostConfig.NetworkMode.IsContainer() {
+		if m.dnsFilterIP != "" {
+			hostConfig.DNS = []string{m.dnsFilterIP}
+		} else {
+			hostConfig.DNS = m.config.Network.DNSServers
+		}
+	}
+
+	containerConfig := &container.Config{
+		Image:        imageRef,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          execEnv(aux.Environment),
+		Labels: map[string]string{
+			"managed-by":         "isolation-runner",
+			"isolation-runner":   "true",
+			"container-name":     m.containerName,
+			"aux-container":      kind,
+			"aux-container-name": aux.Name,
+			"creation-timestamp": fmt.Sprintf("%d", time.Now().Unix()),
+		},
+	}
+	if len(aux.Command) > 0 {
+		containerConfig.Entrypoint = aux.Command
+	}
+	if len(aux.Args) > 0 {
+		containerConfig.Cmd = aux.Args
+	}
+
+	name := fmt.Sprintf("%s-%s-%s", m.containerName, strings.ReplaceAll(kind, " ", "-"), aux.Name)
+	resp, err := m.docker.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s '%s': %s", kind, aux.Name, sanitizeDockerError(err.Error()))
+	}
+
+	return resp.ID, nil
+}
+
+// startAuxContainer starts an auxiliary container, calling started, if set,
+// once it has and then passing each chunk of its output to emit. The returned channel
+// is closed once its output has ended.
+func (m *Manager) startAuxContainer(ctx context.Context, kind, name, id string, started func(), emit func(stream string, data []byte)) (<-chan struct{}, error) {
+	// Attach before starting so no early output is lost
+	attach, err := m.docker.ContainerAttach(ctx, id, container.AttachOptions{
+		Stream: true,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to %s '%s': %s", kind, name, sanitizeDockerError(err.Error()))
+	}
+
+	if err := m.docker.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+		attach.Close()
+		return nil, fmt.Errorf("failed to start %s '%s': %s", kind, name, sanitizeDockerError(err.Error()))
+	}
+	if started != nil {
+		started()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer attach.Close()
+		_, _ = stdcopy.StdCopy(auxStreamWriter{stream: "stdout", emit: emit},
+			auxStreamWriter{stream: "stderr", emit: emit}, attach.Reader)
+	}()
+
+	return done, nil
+}
+
+// auxStreamWriter passes each write of an auxiliary container to emit
+type auxStreamWriter struct {
+	stream string
+	emit   func(stream string, data []byte)
+}
+
+func (w auxStreamWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.emit(w.stream, p)
+	return len(p), nil
+}
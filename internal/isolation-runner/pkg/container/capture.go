@@ -0,0 +1,122 @@
+package container
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/metorial/fleet/holopod/internal/bastion/proto"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/bastion"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// maxConcurrentCaptures limits captures per container so tcpdump output cannot swamp stdout
+const maxConcurrentCaptures = 1
+
+// StartCaptureMessage requests a packet capture of the container's traffic
+type StartCaptureMessage struct {
+	CaptureID       string  `json:"capture_id"`
+	Filter          *string `json:"filter,omitempty"`
+	MaxPackets      *uint32 `json:"max_packets,omitempty"`
+	MaxDurationSecs *uint32 `json:"max_duration_secs,omitempty"`
+}
+
+// StopCaptureMessage stops a running packet capture
+type StopCaptureMessage struct {
+	CaptureID string `json:"capture_id"`
+}
+
+// SetChainName records the bastion chain for this container, enabling packet capture
+func (m *Manager) SetChainName(chainName string) {
+	m.captureMu.Lock()
+	defer m.captureMu.Unlock()
+	m.chainName = chainName
+}
+
+func (m *Manager) handleStartCapture(ctx context.Context, msg StartCaptureMessage) {
+	if msg.CaptureID == "" {
+		jsonmsg.Warning("Ignoring start_capture without capture_id")
+		return
+	}
+
+	m.captureMu.Lock()
+	chainName := m.chainName
+	if chainName == "" {
+		m.captureMu.Unlock()
+		jsonmsg.CaptureStopped(m.containerID, msg.CaptureID, 0, "network isolation is not configured")
+		return
+	}
+	if _, exists := m.captures[msg.CaptureID]; exists {
+		m.captureMu.Unlock()
+		jsonmsg.CaptureStopped(m.containerID, msg.CaptureID, 0, "capture already running")
+		return
+	}
+	if len(m.captures) >= maxConcurrentCaptures {
+		m.captureMu.Unlock()
+		jsonmsg.CaptureStopped(m.containerID, msg.CaptureID, 0, "another capture is already running")
+		return
+	}
+	captureCtx, cancel := context.WithCancel(ctx)
+	m.captures[msg.CaptureID] = cancel
+	m.captureMu.Unlock()
+
+	go func() {
+		defer func() {
+			cancel()
+			m.captureMu.Lock()
+			delete(m.captures, msg.CaptureID)
+			m.captureMu.Unlock()
+		}()
+
+		var total int64
+		err := m.runCapture(captureCtx, chainName, msg, func(data []byte) {
+			total += int64(len(data))
+			jsonmsg.CaptureData(msg.CaptureID, data)
+		})
+
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		jsonmsg.CaptureStopped(m.containerID, msg.CaptureID, total, errMsg)
+	}()
+}
+
+func (m *Manager) runCapture(ctx context.Context, chainName string, msg StartCaptureMessage, fn func([]byte)) error {
+	bastionClient, err := bastion.Connect(config.GetBastionAddress(), m.containerID)
+	if err != nil {
+		return fmt.Errorf("could not connect to bastion: %w", err)
+	}
+	defer bastionClient.Close()
+
+	jsonmsg.CaptureStarted(m.containerID, msg.CaptureID)
+
+	return bastionClient.CapturePackets(ctx, chainName, &pb.CapturePacketsRequest{
+		Filter:          msg.Filter,
+		MaxPackets:      msg.MaxPackets,
+		MaxDurationSecs: msg.MaxDurationSecs,
+	}, fn)
+}
+
+// StopCaptures cancels every running capture, e.g. before the chain is removed
+func (m *Manager) StopCaptures() {
+	m.captureMu.Lock()
+	defer m.captureMu.Unlock()
+
+	for _, cancel := range m.captures {
+		cancel()
+	}
+}
+
+func (m *Manager) handleStopCapture(msg StopCaptureMessage) {
+	m.captureMu.Lock()
+	cancel, exists := m.captures[msg.CaptureID]
+	m.captureMu.Unlock()
+
+	if !exists {
+		jsonmsg.Warning(fmt.Sprintf("No running capture with id %s", msg.CaptureID))
+		return
+	}
+
+	cancel()
+}
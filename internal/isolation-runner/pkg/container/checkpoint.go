@@ -0,0 +1,69 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/docker/api/types/checkpoint"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// checkpointTimeout bounds saving a container's state, which for a large
+// interpreter heap means writing out most of its memory
+const checkpointTimeout = 5 * time.Minute
+
+// CheckpointMessage saves the container's state under the checkpoint directory
+// so a later container can be restored from it. This relies on the runtime's
+// checkpoint support (gVisor's runsc) and a Docker daemon with experimental
+// features enabled.
+type CheckpointMessage struct {
+	CheckpointID string `json:"checkpoint_id"`
+	// LeaveRunning keeps the container running once its state is saved;
+	// otherwise it stops as part of the checkpoint
+	LeaveRunning bool `json:"leave_running"`
+}
+
+func (m *Manager) handleCheckpoint(ctx context.Context, msg CheckpointMessage) {
+	if err := config.ValidateCheckpointID(msg.CheckpointID); err != nil {
+		jsonmsg.ContainerCheckpointFailed(m.containerID, msg.CheckpointID, err.Error())
+		return
+	}
+
+	// Saving state can take a while, so keep reading commands meanwhile
+	go func() {
+		if err := m.checkpointContainer(ctx, msg); err != nil {
+			jsonmsg.ContainerCheckpointFailed(m.containerID, msg.CheckpointID, err.Error())
+			return
+		}
+		jsonmsg.ContainerCheckpointed(m.containerID, msg.CheckpointID)
+	}()
+}
+
+func (m *Manager) checkpointContainer(ctx context.Context, msg CheckpointMessage) error {
+	if m.containerID == "" {
+		return errNotCreated
+	}
+
+	dir := config.GetCheckpointDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkpointTimeout)
+	defer cancel()
+
+	err := m.docker.CheckpointCreate(ctx, m.containerID, checkpoint.CreateOptions{
+		CheckpointID:  msg.CheckpointID,
+		CheckpointDir: dir,
+		Exit:          !msg.LeaveRunning,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint container: %s", sanitizeDockerError(err.Error()))
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// maxConcurrentExecs bounds the extra processes run through exec at once
+const maxConcurrentExecs = 4
+
+// ExecMessage runs an additional process inside the running container
+type ExecMessage struct {
+	ExecID      string            `json:"exec_id"`
+	Command     []string          `json:"command"`
+	Env         map[string]string `json:"env,omitempty"`
+	Workdir     *string           `json:"workdir,omitempty"`
+	TimeoutSecs *uint32           `json:"timeout_secs,omitempty"`
+}
+
+func (m *Manager) handleExec(ctx context.Context, msg ExecMessage) {
+	if msg.ExecID == "" {
+		jsonmsg.Warning("Ignoring exec without exec_id")
+		return
+	}
+	if len(msg.Command) == 0 {
+		jsonmsg.ExecExited(m.containerID, msg.ExecID, -1, "exec requires a command")
+		return
+	}
+
+	m.execMu.Lock()
+	if _, exists := m.execs[msg.ExecID]; exists {
+		m.execMu.Unlock()
+		jsonmsg.ExecExited(m.containerID, msg.ExecID, -1, "exec already running")
+		return
+	}
+	if len(m.execs) >= maxConcurrentExecs {
+		m.execMu.Unlock()
+		jsonmsg.ExecExited(m.containerID, msg.ExecID, -1, fmt.Sprintf("too many running execs (max %d)", maxConcurrentExecs))
+		return
+	}
+	m.execs[msg.ExecID] = struct{}{}
+	m.execMu.Unlock()
+
+	go func() {
+		defer func() {
+			m.execMu.Lock()
+			delete(m.execs, msg.ExecID)
+			m.execMu.Unlock()
+		}()
+
+		exitCode, err := m.runExec(ctx, msg)
+
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		jsonmsg.ExecExited(m.containerID, msg.ExecID, exitCode, errMsg)
+	}()
+}
+
+// runExec runs msg.Command in the container, streaming its output until it
+// exits. Docker cannot signal an exec'd process, so on timeout the output stops
+// and the process is left to end with the container.
+func (m *Manager) runExec(ctx context.Context, msg ExecMessage) (int, error) {
+	if msg.TimeoutSecs != nil && *msg.TimeoutSecs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*msg.TimeoutSecs)*time.Second)
+		defer cancel()
+	}
+
+	options := container.ExecOptions{
+		Cmd:          msg.Command,
+		Env:          execEnv(msg.Env),
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+	if msg.Workdir != nil {
+		options.WorkingDir = *msg.Workdir
+	}
+
+	created, err := m.docker.ContainerExecCreate(ctx, m.containerID, options)
+	if err != nil {
+		return -1, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	resp, err := m.docker.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return -1, fmt.Errorf("failed to start exec: %w", err)
+	}
+	defer resp.Close()
+
+	jsonmsg.ExecStarted(m.containerID, msg.ExecID)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(&execStreamWriter{execID: msg.ExecID, stream: "stdout"},
+			&execStreamWriter{execID: msg.ExecID, stream: "stderr"}, resp.Reader)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return -1, fmt.Errorf("failed to read exec output: %w", err)
+		}
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return -1, fmt.Errorf("exec timed out after %d seconds", *msg.TimeoutSecs)
+		}
+		return -1, fmt.Errorf("exec cancelled: container is stopping")
+	}
+
+	inspectCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	inspect, err := m.docker.ContainerExecInspect(inspectCtx, created.ID)
+	if err != nil {
+		return -1, fmt.Errorf("failed to read exec exit code: %w", err)
+	}
+
+	return inspect.ExitCode, nil
+}
+
+// execEnv converts env to Docker's KEY=value form in a stable order
+func execEnv(env map[string]string) []string {
+	result := make([]string, 0, len(env))
+	for key, value := range env {
+		result = append(result, key+"="+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// execStreamWriter emits each write of an exec'd process as an exec_output event
+type execStreamWriter struct {
+	execID string
+	stream string
+}
+
+func (w *execStreamWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	jsonmsg.ExecOutput(w.execID, w.stream, p)
+	return len(p), nil
+}
@@ -0,0 +1,263 @@
+package container
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// MaxFileTransferBytes bounds the tar archive of a single upload or download
+const MaxFileTransferBytes = 100 << 20
+
+// fileChunkSize keeps each download event well inside the container-manager's line limit
+const fileChunkSize = 256 << 10
+
+// protectedPaths are kernel filesystems that files are never copied into or out of
+var protectedPaths = []string{"/proc", "/sys", "/dev"}
+
+// UploadFileMessage begins an upload of a tar archive that is extracted into the
+// directory Path. The archive follows in upload_file_data messages and is
+// copied into the container on upload_file_end.
+type UploadFileMessage struct {
+	TransferID string `json:"transfer_id"`
+	Path       string `json:"path"`
+}
+
+// UploadFileDataMessage carries the next base64 chunk of an upload's archive
+type UploadFileDataMessage struct {
+	TransferID string `json:"transfer_id"`
+	Data       string `json:"data"`
+}
+
+// UploadFileEndMessage completes an upload, or discards it when Abort is set
+type UploadFileEndMessage struct {
+	TransferID string `json:"transfer_id"`
+	Abort      bool   `json:"abort,omitempty"`
+}
+
+// DownloadFileMessage requests a tar archive of Path
+type DownloadFileMessage struct {
+	TransferID string `json:"transfer_id"`
+	Path       string `json:"path"`
+}
+
+// upload spools an archive to disk until it is complete, so a slow Docker
+// daemon never holds up the stdin command loop
+type upload struct {
+	path string
+	file *os.File
+	size int64
+}
+
+// ValidateTransferPath checks a path inside the container that files are copied
+// to or from: it must be absolute, free of .. segments and outside kernel filesystems
+func ValidateTransferPath(p string) error {
+	if p == "" {
+		return fmt.Errorf("path is required")
+	}
+	if !path.IsAbs(p) {
+		return fmt.Errorf("path %q must be absolute", p)
+	}
+	if strings.ContainsRune(p, 0) {
+		return fmt.Errorf("path must not contain NUL bytes")
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return fmt.Errorf("path %q must not contain .. segments", p)
+		}
+	}
+
+	cleaned := path.Clean(p)
+	for _, protected := range protectedPaths {
+		if cleaned == protected || strings.HasPrefix(cleaned, protected+"/") {
+			return fmt.Errorf("path %q is not allowed", p)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) handleUploadFile(msg UploadFileMessage) {
+	if msg.TransferID == "" {
+		jsonmsg.Warning("Ignoring upload_file without transfer_id")
+		return
+	}
+	if err := ValidateTransferPath(msg.Path); err != nil {
+		jsonmsg.FileTransferFailed(msg.TransferID, err.Error())
+		return
+	}
+
+	m.transferMu.Lock()
+	defer m.transferMu.Unlock()
+
+	if _, exists := m.uploads[msg.TransferID]; exists {
+		jsonmsg.FileTransferFailed(msg.TransferID, "upload already in progress")
+		return
+	}
+
+	file, err := os.CreateTemp("", "holopod-upload-*.tar")
+	if err != nil {
+		jsonmsg.FileTransferFailed(msg.TransferID, fmt.Sprintf("failed to spool upload: %v", err))
+		return
+	}
+
+	m.uploads[msg.TransferID] = &upload{path: msg.Path, file: file}
+}
+
+func (m *Manager) handleUploadFileData(msg UploadFileDataMessage) {
+	m.transferMu.Lock()
+	defer m.transferMu.Unlock()
+
+	// Data for an upload that already failed is dropped silently
+	u, exists := m.uploads[msg.TransferID]
+	if !exists {
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(msg.Data)
+	if err != nil {
+		m.discardUpload(msg.TransferID, u)
+		jsonmsg.FileTransferFailed(msg.TransferID, fmt.Sprintf("invalid upload data: %v", err))
+		return
+	}
+
+	if u.size+int64(len(data)) > MaxFileTransferBytes {
+		m.discardUpload(msg.TransferID, u)
+		jsonmsg.FileTransferFailed(msg.TransferID, fmt.Sprintf("upload exceeds %d bytes", MaxFileTransferBytes))
+		return
+	}
+
+	if _, err := u.file.Write(data); err != nil {
+		m.discardUpload(msg.TransferID, u)
+		jsonmsg.FileTransferFailed(msg.TransferID, fmt.Sprintf("failed to spool upload: %v", err))
+		return
+	}
+	u.size += int64(len(data))
+}
+
+func (m *Manager) handleUploadFileEnd(ctx context.Context, msg UploadFileEndMessage) {
+	m.transferMu.Lock()
+	u, exists := m.uploads[msg.TransferID]
+	if exists {
+		delete(m.uploads, msg.TransferID)
+	}
+	m.transferMu.Unlock()
+
+	if !exists {
+		return
+	}
+
+	if msg.Abort {
+		closeUpload(u)
+		return
+	}
+
+	go func() {
+		defer closeUpload(u)
+
+		if err := m.copyUpload(ctx, u); err != nil {
+			jsonmsg.FileTransferFailed(msg.TransferID, err.Error())
+			return
+		}
+
+		jsonmsg.FileUploaded(m.containerID, msg.TransferID, u.path, u.size)
+	}()
+}
+
+func (m *Manager) copyUpload(ctx context.Context, u *upload) error {
+	if _, err := u.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read spooled upload: %w", err)
+	}
+
+	copyCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	if err := m.docker.CopyToContainer(copyCtx, m.containerID, u.path, u.file, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy files into container: %w", err)
+	}
+
+	return nil
+}
+
+// discardUpload must be called with m.transferMu held
+func (m *Manager) discardUpload(transferID string, u *upload) {
+	delete(m.uploads, transferID)
+	closeUpload(u)
+}
+
+func closeUpload(u *upload) {
+	u.file.Close()
+	os.Remove(u.file.Name())
+}
+
+// AbortUploads discards every incomplete upload and its spooled archive
+func (m *Manager) AbortUploads() {
+	m.transferMu.Lock()
+	defer m.transferMu.Unlock()
+
+	for transferID, u := range m.uploads {
+		m.discardUpload(transferID, u)
+	}
+}
+
+func (m *Manager) handleDownloadFile(ctx context.Context, msg DownloadFileMessage) {
+	if msg.TransferID == "" {
+		jsonmsg.Warning("Ignoring download_file without transfer_id")
+		return
+	}
+	if err := ValidateTransferPath(msg.Path); err != nil {
+		jsonmsg.FileTransferFailed(msg.TransferID, err.Error())
+		return
+	}
+
+	go func() {
+		size, err := m.runDownload(ctx, msg)
+		if err != nil {
+			jsonmsg.FileTransferFailed(msg.TransferID, err.Error())
+			return
+		}
+
+		jsonmsg.FileDownloaded(m.containerID, msg.TransferID, msg.Path, size)
+	}()
+}
+
+// runDownload streams the tar archive of msg.Path as file_download_data events
+func (m *Manager) runDownload(ctx context.Context, msg DownloadFileMessage) (int64, error) {
+	copyCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	reader, _, err := m.docker.CopyFromContainer(copyCtx, m.containerID, msg.Path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy files from container: %w", err)
+	}
+	defer reader.Close()
+
+	var total int64
+	buf := make([]byte, fileChunkSize)
+	for {
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			total += int64(n)
+			if total > MaxFileTransferBytes {
+				return total, fmt.Errorf("download exceeds %d bytes", MaxFileTransferBytes)
+			}
+			jsonmsg.FileDownloadData(msg.TransferID, buf[:n])
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return total, nil
+		}
+		if err != nil {
+			return total, fmt.Errorf("failed to read files from container: %w", err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+)
+
+// hookClient calls post-exit webhooks over publicTransport. Redirects are not
+// followed; the hook's timeout bounds each call.
+var hookClient = &http.Client{
+	Transport: publicTransport,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// CallWebhook posts the workload's outcome to the hook's webhook url as JSON.
+// Any 2xx response counts as success.
+func (m *Manager) CallWebhook(ctx context.Context, hook *config.HookConfig, exitCode int, duration time.Duration) error {
+	body, err := json.Marshal(map[string]any{
+		"hook":           hook.Name,
+		"container_id":   m.containerID,
+		"container_name": m.containerName,
+		"exit_code":      exitCode,
+		"duration":       duration.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook url")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hookClient.Do(req)
+	if err != nil {
+		// SECURITY: The url, which may carry a token, stays out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("webhook call failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
@@ -5,24 +5,43 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net"
+	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
 	registryTypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/bastion"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/heartbeat"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/stdio"
 )
 
+// ErrExitedEarly is returned by GetContainerIP when the container completed
+// before its network could be set up, which is normal for short-running
+// commands
+var ErrExitedEarly = errors.New("container completed before network setup")
+
+var errNotCreated = ierrors.NewRuntimeError("container not created", nil)
+
 type Manager struct {
 	docker            *client.Client
 	containerID       string
@@ -30,20 +49,49 @@ type Manager struct {
 	networkName       string
 	config            *config.Config
 	networkViaBastion bool
-	earlyExitCode     *int // Set if container exits before network setup
+	dnsFilterIP       string // Overrides configured DNS servers when domain filtering is on
+	staticIP          string // Requested address on the pool network, if any
+	earlyExitCode     *int   // Set if container exits before network setup
+	oomKilled         bool   // Set with earlyExitCode when the kernel killed it
+
+	// Packet captures are keyed by capture ID; chainName is set once isolation is ready
+	captureMu sync.Mutex
+	chainName string
+	captures  map[string]context.CancelFunc
+
+	// Processes started through exec, keyed by exec ID
+	execMu sync.Mutex
+	execs  map[string]struct{}
+
+	// Volume holding the workspace, once created
+	workspaceVolume string
+
+	// Mounts of the container, which its init containers share
+	mounts []mount.Mount
+
+	// Set while the container's processes are frozen by PauseContainer
+	paused atomic.Bool
+
+	// Uploads still being received, keyed by transfer ID
+	transferMu sync.Mutex
+	uploads    map[string]*upload
+
+	// Auxiliary containers sharing the container's network namespace
+	sidecarMu sync.Mutex
+	sidecars  []sidecar
 }
 
 func NewManager(containerName, networkName string, cfg *config.Config) (*Manager, error) {
 	docker, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
-		return nil, fmt.Errorf("docker not available: %w", err)
+		return nil, ierrors.NewDockerError("docker not available", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if _, err := docker.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("docker not available: %w", err)
+		return nil, ierrors.NewDockerError("docker not available", err)
 	}
 
 	return &Manager{
@@ -52,6 +100,9 @@ func NewManager(containerName, networkName string, cfg *config.Config) (*Manager
 		networkName:       networkName,
 		config:            cfg,
 		networkViaBastion: false,
+		captures:          make(map[string]context.CancelFunc),
+		uploads:           make(map[string]*upload),
+		execs:             make(map[string]struct{}),
 	}, nil
 }
 
@@ -63,22 +114,47 @@ func (m *Manager) ContainerID() string {
 	return m.containerID
 }
 
+// WorkspaceVolume returns the volume holding the container's workspace, if any
+func (m *Manager) WorkspaceVolume() string {
+	return m.workspaceVolume
+}
+
 func (m *Manager) NetworkName() string {
 	return m.networkName
 }
 
-func (m *Manager) CheckGVisor(ctx context.Context) error {
+// ContainerName is the name the container was created with, also used as its
+// identity towards the bastion network pool
+func (m *Manager) ContainerName() string {
+	return m.containerName
+}
+
+// CheckRuntime verifies the requested OCI runtime is registered with the Docker
+// daemon. The container-manager only forwards runtimes its operator allowed.
+func (m *Manager) CheckRuntime(ctx context.Context) error {
 	info, err := m.docker.Info(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get Docker info: %w", err)
+		return ierrors.NewDockerError("failed to get Docker info", err)
 	}
 
 	if info.Runtimes == nil {
-		return fmt.Errorf("no runtimes available in Docker daemon")
+		return ierrors.Wrap(ierrors.KindRuntimeUnavailable, ierrors.ExitSetupError,
+			fmt.Errorf("no runtimes available in Docker daemon"))
+	}
+
+	runtime := m.config.Container.Runtime
+	if _, ok := info.Runtimes[runtime]; !ok {
+		available := make([]string, 0, len(info.Runtimes))
+		for name := range info.Runtimes {
+			available = append(available, name)
+		}
+		sort.Strings(available)
+		return ierrors.Wrap(ierrors.KindRuntimeUnavailable, ierrors.ExitSetupError,
+			fmt.Errorf("runtime '%s' not found in Docker daemon (available: %s)", runtime, strings.Join(available, ", ")))
 	}
 
-	if _, ok := info.Runtimes[m.config.Container.Runtime]; !ok {
-		return fmt.Errorf("runtime '%s' not found in Docker daemon", m.config.Container.Runtime)
+	if !strings.HasPrefix(runtime, "runsc") {
+		jsonmsg.Warning(fmt.Sprintf("Runtime '%s' does not sandbox the container with gVisor", runtime))
 	}
 
 	// jsonmsg.Info(fmt.Sprintf("gVisor runtime '%s' is available", m.config.Container.Runtime))
@@ -87,10 +163,21 @@ func (m *Manager) CheckGVisor(ctx context.Context) error {
 	return nil
 }
 
-func (m *Manager) SetupNetworkViaBastion(ctx context.Context, subnet *string, bastionClient *bastion.Client) error {
+// SetDNSFilter makes the container resolve names only through the bastion's DNS filter
+func (m *Manager) SetDNSFilter(ip string) {
+	m.dnsFilterIP = ip
+}
+
+func (m *Manager) SetupNetworkViaBastion(ctx context.Context, subnet *string, staticIP *string, bastionClient *bastion.Client) error {
 	// jsonmsg.Info(fmt.Sprintf("Setting up network via bastion pool: %s", m.networkName))
 	jsonmsg.Info("Setting up Holopod networking")
 
+	// Docker only assigns fixed addresses on user-defined networks, so a static
+	// IP always goes through a pool network
+	if staticIP != nil && *staticIP != "" {
+		return m.setupStaticIPNetwork(subnet, *staticIP, bastionClient)
+	}
+
 	if m.networkName == "" || m.networkName == "bridge" {
 		m.networkName = "bridge"
 		m.networkViaBastion = false
@@ -104,8 +191,39 @@ func (m *Manager) SetupNetworkViaBastion(ctx context.Context, subnet *string, ba
 	return nil
 }
 
+// setupStaticIPNetwork acquires a pool network whose subnet contains ip and
+// pins the container to that address
+func (m *Manager) setupStaticIPNetwork(subnet *string, ip string, bastionClient *bastion.Client) error {
+	if bastionClient == nil {
+		return fmt.Errorf("static IP assignment requires the bastion network pool and is not available in standalone mode")
+	}
+
+	cidr, err := config.ValidateStaticIP(ip, subnet)
+	if err != nil {
+		return fmt.Errorf("network validation failed: %w", err)
+	}
+
+	result, err := bastionClient.AcquireNetwork(&cidr, nil)
+	if err != nil {
+		return err
+	}
+
+	_, poolNet, err := net.ParseCIDR(result.Subnet)
+	if err != nil || !poolNet.Contains(net.ParseIP(ip)) {
+		_ = bastionClient.ReleaseNetwork(result.NetworkName, false)
+		return fmt.Errorf("acquired network subnet %s does not contain static IP %s", result.Subnet, ip)
+	}
+
+	m.networkName = result.NetworkName
+	m.networkViaBastion = true
+	m.staticIP = ip
+	jsonmsg.Info(fmt.Sprintf("Using pool network %s (%s) with static IP %s", result.NetworkName, result.Subnet, ip))
+
+	return nil
+}
+
 func (m *Manager) CleanupNetwork(ctx context.Context, bastionClient *bastion.Client) error {
-	if !m.networkViaBastion {
+	if !m.networkViaBastion || bastionClient == nil {
 		return nil
 	}
 
@@ -231,22 +349,78 @@ func (m *Manager) PullImage(ctx context.Context, imageRef string, auth *config.I
 	return nil
 }
 
-func (m *Manager) CreateContainer(ctx context.Context, imageRef string, cmd []string, args []string, auth *config.ImageAuth) error {
-	jsonmsg.Info(fmt.Sprintf("Creating Holopod instance: %s", m.containerName))
-
+// validateContainerConfig rejects a config the container cannot be created
+// from, before anything is pulled
+func (m *Manager) validateContainerConfig(imageRef string) error {
 	if err := config.ValidateImageReference(imageRef); err != nil {
 		return err
 	}
 
-	// Pull image with authentication
-	if err := m.PullImage(ctx, imageRef, auth); err != nil {
+	if err := config.ValidateEnvironmentVariables(m.config.Container.Environment); err != nil {
 		return err
 	}
 
-	if err := config.ValidateEnvironmentVariables(m.config.Container.Environment); err != nil {
+	if err := config.ValidateAuxContainers("sidecar", m.config.Container.Sidecars); err != nil {
+		return err
+	}
+	if err := config.ValidateAuxContainers("init container", m.config.Container.InitContainers); err != nil {
+		return err
+	}
+	if err := config.ValidateHooks(m.config.Container.PostExitHooks); err != nil {
+		return err
+	}
+	if err := config.ValidateLabels(m.config.Container.Labels); err != nil {
 		return err
 	}
 
+	if ws := m.config.Container.Workspace; ws != nil {
+		if ws.Path == "" {
+			ws.Path = "/workspace"
+		}
+		if err := config.ValidateWorkspace(ws); err != nil {
+			return fmt.Errorf("invalid workspace: %w", err)
+		}
+	}
+
+	if restoreFrom := m.config.Container.RestoreFrom; restoreFrom != "" {
+		if err := config.ValidateCheckpointID(restoreFrom); err != nil {
+			return err
+		}
+	}
+
+	if probe := m.config.Container.ReadinessProbe; probe != nil {
+		if err := config.ValidateProbe(probe); err != nil {
+			return fmt.Errorf("invalid readiness probe: %w", err)
+		}
+	}
+
+	if opts := m.config.Container.RuntimeOptions; opts != nil {
+		if err := config.ValidateRuntimeOptions(m.config.Container.Runtime, opts); err != nil {
+			return fmt.Errorf("invalid runtime options: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) CreateContainer(ctx context.Context, imageRef string, cmd []string, args []string, auth *config.ImageAuth) error {
+	jsonmsg.Info(fmt.Sprintf("Creating Holopod instance: %s", m.containerName))
+
+	if err := m.validateContainerConfig(imageRef); err != nil {
+		return ierrors.Wrap(ierrors.KindConfigInvalid, ierrors.ExitConfigError, err)
+	}
+
+	// Pull image with authentication
+	heartbeat.SetPhase(heartbeat.PhasePulling)
+	err := m.PullImage(ctx, imageRef, auth)
+	heartbeat.SetPhase(heartbeat.PhaseStarting)
+	if err != nil {
+		return ierrors.Wrap(ierrors.KindImagePullFailed, ierrors.ExitDockerError, err)
+	}
+
+	ws := m.config.Container.Workspace
+	opts := m.config.Container.RuntimeOptions
+
 	hostConfig := &container.HostConfig{
 		Runtime:     m.config.Container.Runtime,
 		NetworkMode: container.NetworkMode(m.networkName),
@@ -255,10 +429,14 @@ func (m *Manager) CreateContainer(ctx context.Context, imageRef string, cmd []st
 		SecurityOpt: []string{"no-new-privileges:true"},
 	}
 
+	if opts != nil {
+		hostConfig.Annotations = opts.Annotations()
+	}
+
 	if m.config.Container.MemoryLimit != nil {
 		mem, err := parseMemoryLimit(*m.config.Container.MemoryLimit)
 		if err != nil {
-			return err
+			return ierrors.Wrap(ierrors.KindConfigInvalid, ierrors.ExitConfigError, err)
 		}
 		hostConfig.Memory = mem
 	}
@@ -266,7 +444,7 @@ func (m *Manager) CreateContainer(ctx context.Context, imageRef string, cmd []st
 	if m.config.Container.CPULimit != nil {
 		nano, err := parseCPULimit(*m.config.Container.CPULimit)
 		if err != nil {
-			return err
+			return ierrors.Wrap(ierrors.KindConfigInvalid, ierrors.ExitConfigError, err)
 		}
 		hostConfig.NanoCPUs = nano
 	}
@@ -289,17 +467,31 @@ func (m *Manager) CreateContainer(ctx context.Context, imageRef string, cmd []st
 
 	// Configure DNS servers if provided
 	// If empty, Docker will use its default DNS (127.0.0.11 or host's /etc/resolv.conf)
-	if len(m.config.Network.DNSServers) > 0 {
+	// With a domain allowlist, only the bastion's DNS filter is used; it forwards to DNSServers
+	if m.dnsFilterIP != "" {
+		hostConfig.DNS = []string{m.dnsFilterIP}
+		jsonmsg.Info(fmt.Sprintf("Using bastion DNS filter at %s for %d allowed domains", m.dnsFilterIP, len(m.config.Network.DNSAllowedDomains)))
+	} else if len(m.config.Network.DNSServers) > 0 {
 		hostConfig.DNS = m.config.Network.DNSServers
 		jsonmsg.Info(fmt.Sprintf("Using custom DNS servers: %v", m.config.Network.DNSServers))
 	}
 
 	// Add labels for container tracking and orphan cleanup
-	labels := map[string]string{
-		"managed-by":         "isolation-runner",
-		"isolation-runner":   "true",
-		"container-name":     m.containerName,
-		"creation-timestamp": fmt.Sprintf("%d", time.Now().Unix()),
+	labels := maps.Clone(m.config.Container.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels["managed-by"] = "isolation-runner"
+	labels["isolation-runner"] = "true"
+	labels["container-name"] = m.containerName
+	labels["creation-timestamp"] = fmt.Sprintf("%d", time.Now().Unix())
+
+	if ws != nil {
+		workspaceMount, err := m.createWorkspaceVolume(ctx, ws, labels)
+		if err != nil {
+			return ierrors.Wrap(ierrors.KindWorkspaceFailed, ierrors.ExitSetupError, err)
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, workspaceMount)
 	}
 
 	containerConfig := &container.Config{
@@ -338,13 +530,36 @@ func (m *Manager) CreateContainer(ctx context.Context, imageRef string, cmd []st
 		containerConfig.WorkingDir = *m.config.Container.WorkingDir
 	}
 
-	resp, err := m.docker.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, m.containerName)
+	var networkingConfig *network.NetworkingConfig
+	if m.staticIP != "" {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				m.networkName: {
+					IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: m.staticIP},
+				},
+			},
+		}
+	}
+
+	resp, err := m.docker.ContainerCreate(ctx, containerConfig, hostConfig, networkingConfig, nil, m.containerName)
 	if err != nil {
+		m.RemoveWorkspace(ctx)
 		errMsg := sanitizeDockerError(err.Error())
-		return fmt.Errorf("failed to create container: %s", errMsg)
+		return ierrors.NewDockerError("failed to create container", fmt.Errorf("%s", errMsg))
 	}
 
 	m.containerID = resp.ID
+	m.mounts = hostConfig.Mounts
+
+	// The workspace must be in place before the command starts
+	if ws != nil {
+		if err := m.populateWorkspace(ctx, ws); err != nil {
+			_ = m.RemoveContainer(ctx)
+			m.RemoveWorkspace(ctx)
+			return ierrors.Wrap(ierrors.KindWorkspaceFailed, ierrors.ExitSetupError, err)
+		}
+		ws.Archive = nil
+	}
 	// jsonmsg.Info(fmt.Sprintf("Container created with ID: %s", resp.ID))
 	jsonmsg.Info("Holopod instance created successfully")
 	jsonmsg.ContainerCreated(resp.ID, m.containerName, imageRef)
@@ -358,14 +573,21 @@ func (m *Manager) CreateContainer(ctx context.Context, imageRef string, cmd []st
 
 func (m *Manager) StartContainer(ctx context.Context) error {
 	if m.containerID == "" {
-		return fmt.Errorf("container not created")
+		return errNotCreated
 	}
 
 	// jsonmsg.Info(fmt.Sprintf("Starting container: %s", m.containerID))
 	jsonmsg.Info("Starting Holopod instance")
 
-	if err := m.docker.ContainerStart(ctx, m.containerID, container.StartOptions{}); err != nil {
-		return fmt.Errorf("failed to start container: %w", err)
+	var startOptions container.StartOptions
+	if restoreFrom := m.config.Container.RestoreFrom; restoreFrom != "" {
+		jsonmsg.Info(fmt.Sprintf("Restoring Holopod instance from checkpoint %s", restoreFrom))
+		startOptions.CheckpointID = restoreFrom
+		startOptions.CheckpointDir = config.GetCheckpointDir()
+	}
+
+	if err := m.docker.ContainerStart(ctx, m.containerID, startOptions); err != nil {
+		return ierrors.NewDockerError("failed to start container", err)
 	}
 
 	// Inspect to get PID
@@ -380,10 +602,23 @@ func (m *Manager) StartContainer(ctx context.Context) error {
 
 func (m *Manager) AttachStreams(ctx context.Context) error {
 	if m.containerID == "" {
-		return fmt.Errorf("container not created")
+		return errNotCreated
+	}
+
+	// Closing the framed output tells the container-manager that the
+	// workload's output is complete
+	var output *os.File
+	if m.config.Execution.BinaryStdio {
+		output = stdio.OpenOutput()
+	}
+	closeOutput := func() {
+		if output != nil {
+			output.Close()
+		}
 	}
 
 	if !m.config.Execution.AttachStdout && !m.config.Execution.AttachStderr {
+		closeOutput()
 		return nil
 	}
 
@@ -394,16 +629,27 @@ func (m *Manager) AttachStreams(ctx context.Context) error {
 		Logs:   true,
 	})
 	if err != nil {
+		closeOutput()
 		return fmt.Errorf("failed to attach to container: %w", err)
 	}
 
-	// Create custom writers that emit JSON immediately for each write
-	stdoutWriter := &jsonStreamWriter{streamType: "stdout"}
-	stderrWriter := &jsonStreamWriter{streamType: "stderr"}
+	// Output is emitted as JSON messages unless it has its own framed descriptor
+	var stdoutWriter, stderrWriter io.Writer = &jsonStreamWriter{streamType: "stdout"}, &jsonStreamWriter{streamType: "stderr"}
+	if output != nil {
+		frames := stdio.NewWriter(output)
+		stdoutWriter = frames.Stream(stdio.StreamStdout)
+		stderrWriter = frames.Stream(stdio.StreamStderr)
+	}
 
 	go func() {
 		defer resp.Close()
-		_, _ = stdcopy.StdCopy(stdoutWriter, stderrWriter, resp.Reader)
+		// A tty merges stdout and stderr into one unmultiplexed stream
+		if m.config.Execution.TTY {
+			_, _ = io.Copy(stdoutWriter, resp.Reader)
+		} else {
+			_, _ = stdcopy.StdCopy(stdoutWriter, stderrWriter, resp.Reader)
+		}
+		closeOutput()
 	}()
 
 	return nil
@@ -431,7 +677,7 @@ func (w *jsonStreamWriter) Write(p []byte) (n int, err error) {
 
 func (m *Manager) RetrieveContainerLogs(ctx context.Context) (string, error) {
 	if m.containerID == "" {
-		return "", fmt.Errorf("container not created")
+		return "", errNotCreated
 	}
 
 	options := container.LogsOptions{
@@ -447,7 +693,11 @@ func (m *Manager) RetrieveContainerLogs(ctx context.Context) (string, error) {
 	defer logs.Close()
 
 	var stdout, stderr strings.Builder
-	_, _ = stdcopy.StdCopy(&stdout, &stderr, logs)
+	if m.config.Execution.TTY {
+		_, _ = io.Copy(&stdout, logs)
+	} else {
+		_, _ = stdcopy.StdCopy(&stdout, &stderr, logs)
+	}
 
 	combined := stdout.String()
 	if stderr.Len() > 0 {
@@ -462,13 +712,16 @@ func (m *Manager) RetrieveContainerLogs(ctx context.Context) (string, error) {
 
 func (m *Manager) GetContainerIP(ctx context.Context) (net.IP, error) {
 	if m.containerID == "" {
-		return nil, fmt.Errorf("container not created")
+		return nil, errNotCreated
 	}
 
 	for attempt := 1; attempt <= 10; attempt++ {
 		inspect, err := m.docker.ContainerInspect(ctx, m.containerID)
+		if client.IsErrNotFound(err) {
+			return nil, fmt.Errorf("%w: container already removed", ErrExitedEarly)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to inspect container: %w", err)
+			return nil, ierrors.NewDockerError("failed to inspect container", err)
 		}
 
 		if inspect.State != nil && !inspect.State.Running {
@@ -477,11 +730,12 @@ func (m *Manager) GetContainerIP(ctx context.Context) (net.IP, error) {
 
 			// Store exit code so WaitForExit can return it
 			m.earlyExitCode = &exitCode
+			m.oomKilled = inspect.State.OOMKilled
 
 			// For containers that complete successfully before network setup, this is normal
 			if exitCode == 0 {
 				// jsonmsg.Info("Container completed successfully before network isolation could be configured (short-running container)")
-				return nil, fmt.Errorf("container completed before network setup (exit code: 0)")
+				return nil, fmt.Errorf("%w (exit code: 0)", ErrExitedEarly)
 			}
 
 			// For failed containers, log errors
@@ -495,23 +749,27 @@ func (m *Manager) GetContainerIP(ctx context.Context) (net.IP, error) {
 				jsonmsg.Error(fmt.Sprintf("Holopod instance error: %s", errMsg))
 			}
 
-			return nil, fmt.Errorf("container exited before IP assignment (exit code: %d)",
-				exitCode)
+			kind := ierrors.KindContainerFailed
+			if m.oomKilled {
+				kind = ierrors.KindOOM
+			}
+			return nil, ierrors.Wrap(kind, ierrors.ExitContainerFailed,
+				fmt.Errorf("container exited before IP assignment (exit code: %d)", exitCode))
 		}
 
 		if inspect.NetworkSettings == nil || inspect.NetworkSettings.Networks == nil {
-			return nil, fmt.Errorf("no network settings found")
+			return nil, ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, fmt.Errorf("no network settings found"))
 		}
 
 		netInfo, ok := inspect.NetworkSettings.Networks[m.networkName]
 		if !ok {
-			return nil, fmt.Errorf("network %s not found", m.networkName)
+			return nil, ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, fmt.Errorf("network %s not found", m.networkName))
 		}
 
 		if netInfo.IPAddress != "" {
 			ip := net.ParseIP(netInfo.IPAddress)
 			if ip == nil {
-				return nil, fmt.Errorf("invalid IP address: %s", netInfo.IPAddress)
+				return nil, ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, fmt.Errorf("invalid IP address: %s", netInfo.IPAddress))
 			}
 			// jsonmsg.Info(fmt.Sprintf("Container IP address: %s", ip.String()))
 			jsonmsg.ContainerIPReady(m.containerID, ip.String(), m.networkName)
@@ -523,12 +781,12 @@ func (m *Manager) GetContainerIP(ctx context.Context) (net.IP, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("no IP address assigned after 10 attempts")
+	return nil, ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, fmt.Errorf("no IP address assigned after 10 attempts"))
 }
 
 func (m *Manager) WaitForExit(ctx context.Context) (int, error) {
 	if m.containerID == "" {
-		return -1, fmt.Errorf("container not created")
+		return -1, errNotCreated
 	}
 
 	// If we already captured the exit code during early exit detection, return it
@@ -541,7 +799,7 @@ func (m *Manager) WaitForExit(ctx context.Context) (int, error) {
 	select {
 	case err := <-errCh:
 		if err != nil {
-			return -1, fmt.Errorf("error waiting for container: %w", err)
+			return -1, ierrors.NewDockerError("error waiting for container", err)
 		}
 	case status := <-statusCh:
 		return int(status.StatusCode), nil
@@ -549,7 +807,23 @@ func (m *Manager) WaitForExit(ctx context.Context) (int, error) {
 		return -1, ctx.Err()
 	}
 
-	return -1, fmt.Errorf("unexpected wait exit")
+	return -1, ierrors.NewDockerError("unexpected wait exit", nil)
+}
+
+// OOMKilled reports whether the kernel killed the container for exceeding its
+// memory limit. It must be called before the container is removed.
+func (m *Manager) OOMKilled(ctx context.Context) bool {
+	if m.oomKilled {
+		return true
+	}
+	if m.containerID == "" {
+		return false
+	}
+	inspect, err := m.docker.ContainerInspect(ctx, m.containerID)
+	if err != nil || inspect.State == nil {
+		return false
+	}
+	return inspect.State.OOMKilled
 }
 
 func (m *Manager) StopContainer(ctx context.Context, timeout int) error {
@@ -560,6 +834,13 @@ func (m *Manager) StopContainer(ctx context.Context, timeout int) error {
 	// jsonmsg.Info(fmt.Sprintf("Stopping container: %s", m.containerID))
 	jsonmsg.ContainerTerminating(m.containerID, "stop_requested", false)
 
+	// A frozen container cannot handle the stop signal
+	if m.paused.Load() {
+		if err := m.UnpauseContainer(ctx); err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Failed to unpause container before stopping: %v", err))
+		}
+	}
+
 	stopTimeout := timeout
 	if err := m.docker.ContainerStop(ctx, m.containerID, container.StopOptions{
 		Timeout: &stopTimeout,
@@ -570,6 +851,90 @@ func (m *Manager) StopContainer(ctx context.Context, timeout int) error {
 	return nil
 }
 
+// PauseContainer freezes every process of the container. Memory and
+// filesystem state are kept until UnpauseContainer.
+func (m *Manager) PauseContainer(ctx context.Context) error {
+	if m.containerID == "" {
+		return errNotCreated
+	}
+
+	if err := m.docker.ContainerPause(ctx, m.containerID); err != nil {
+		return fmt.Errorf("failed to pause container: %s", sanitizeDockerError(err.Error()))
+	}
+	m.paused.Store(true)
+	m.setSidecarsPaused(ctx, true)
+
+	return nil
+}
+
+// UnpauseContainer resumes a container frozen by PauseContainer
+func (m *Manager) UnpauseContainer(ctx context.Context) error {
+	if m.containerID == "" {
+		return errNotCreated
+	}
+
+	if err := m.docker.ContainerUnpause(ctx, m.containerID); err != nil {
+		return fmt.Errorf("failed to unpause container: %s", sanitizeDockerError(err.Error()))
+	}
+	m.paused.Store(false)
+	m.setSidecarsPaused(ctx, false)
+
+	return nil
+}
+
+// UpdateResources applies new CPU and/or memory limits to the running container.
+// Limits that are nil are left unchanged.
+func (m *Manager) UpdateResources(ctx context.Context, cpuLimit, memoryLimit *string) error {
+	if m.containerID == "" {
+		return errNotCreated
+	}
+
+	if cpuLimit == nil && memoryLimit == nil {
+		return ierrors.NewConfigError("no resource limits provided", nil)
+	}
+
+	var resources container.Resources
+
+	if memoryLimit != nil {
+		mem, err := parseMemoryLimit(*memoryLimit)
+		if err != nil {
+			return err
+		}
+		resources.Memory = mem
+		// Keep Docker's default swap allowance (2x memory) so raising the limit
+		// never conflicts with the swap limit set at create time
+		resources.MemorySwap = mem * 2
+	}
+
+	if cpuLimit != nil {
+		nano, err := parseCPULimit(*cpuLimit)
+		if err != nil {
+			return err
+		}
+		resources.NanoCPUs = nano
+	}
+
+	resp, err := m.docker.ContainerUpdate(ctx, m.containerID, container.UpdateConfig{
+		Resources: resources,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update container resources: %w", err)
+	}
+
+	for _, warning := range resp.Warnings {
+		jsonmsg.Warning(fmt.Sprintf("Resource update warning: %s", warning))
+	}
+
+	if memoryLimit != nil {
+		m.config.Container.MemoryLimit = memoryLimit
+	}
+	if cpuLimit != nil {
+		m.config.Container.CPULimit = cpuLimit
+	}
+
+	return nil
+}
+
 func (m *Manager) RemoveContainer(ctx context.Context) error {
 	if m.containerID == "" {
 		return nil
@@ -610,7 +975,7 @@ func parseMemoryLimit(limit string) (int64, error) {
 
 	value, err := strconv.ParseInt(limit, 10, 64)
 	if err != nil {
-		return 0, fmt.Errorf("invalid memory limit: %s", limit)
+		return 0, ierrors.NewConfigError(fmt.Sprintf("invalid memory limit: %s", limit), nil)
 	}
 
 	bytes := value * multiplier
@@ -619,10 +984,10 @@ func parseMemoryLimit(limit string) (int64, error) {
 	const maxMemory = 128 * 1024 * 1024 * 1024
 
 	if bytes < minMemory {
-		return 0, fmt.Errorf("memory limit too low: %d bytes (minimum: 4MB)", bytes)
+		return 0, ierrors.NewConfigError(fmt.Sprintf("memory limit too low: %d bytes (minimum: 4MB)", bytes), nil)
 	}
 	if bytes > maxMemory {
-		return 0, fmt.Errorf("memory limit too high: %d bytes (maximum: 128GB)", bytes)
+		return 0, ierrors.NewConfigError(fmt.Sprintf("memory limit too high: %d bytes (maximum: 128GB)", bytes), nil)
 	}
 
 	return bytes, nil
@@ -633,17 +998,17 @@ func parseCPULimit(limit string) (int64, error) {
 
 	value, err := strconv.ParseFloat(limit, 64)
 	if err != nil {
-		return 0, fmt.Errorf("invalid CPU limit: %s", limit)
+		return 0, ierrors.NewConfigError(fmt.Sprintf("invalid CPU limit: %s", limit), nil)
 	}
 
 	const minCPU = 0.01
 	const maxCPU = 256.0
 
 	if value < minCPU {
-		return 0, fmt.Errorf("CPU limit too low: %.2f (minimum: 0.01)", value)
+		return 0, ierrors.NewConfigError(fmt.Sprintf("CPU limit too low: %.2f (minimum: 0.01)", value), nil)
 	}
 	if value > maxCPU {
-		return 0, fmt.Errorf("CPU limit too high: %.2f (maximum: 256)", value)
+		return 0, ierrors.NewConfigError(fmt.Sprintf("CPU limit too high: %.2f (maximum: 256)", value), nil)
 	}
 
 	return int64(value * 1e9), nil
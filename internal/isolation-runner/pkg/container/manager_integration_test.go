@@ -33,7 +33,7 @@ func TestNewManagerIntegration(t *testing.T) {
 	}
 }
 
-func TestCheckGVisorIntegration(t *testing.T) {
+func TestCheckRuntimeIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
 	}
@@ -47,7 +47,7 @@ func TestCheckGVisorIntegration(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err = manager.CheckGVisor(ctx)
+	err = manager.CheckRuntime(ctx)
 	if err != nil {
 		t.Logf("gVisor not available (expected in many environments): %v", err)
 	}
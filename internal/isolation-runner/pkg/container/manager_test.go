@@ -1,7 +1,22 @@
 package container
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
 )
 
 func TestParseMemoryLimit(t *testing.T) {
@@ -68,3 +83,267 @@ func TestParseCPULimit(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorsAreClassified(t *testing.T) {
+	ctx := context.Background()
+	m := &Manager{}
+
+	_, ipErr := m.GetContainerIP(ctx)
+	_, waitErr := m.WaitForExit(ctx)
+	_, memErr := parseMemoryLimit("lots")
+	_, cpuErr := parseCPULimit("1000")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantKind ierrors.Kind
+		wantCode ierrors.ErrorCode
+	}{
+		{"start before create", m.StartContainer(ctx), ierrors.KindInternal, ierrors.ExitRuntimeError},
+		{"ip before create", ipErr, ierrors.KindInternal, ierrors.ExitRuntimeError},
+		{"wait before create", waitErr, ierrors.KindInternal, ierrors.ExitRuntimeError},
+		{"memory limit", memErr, ierrors.KindConfigInvalid, ierrors.ExitConfigError},
+		{"cpu limit", cpuErr, ierrors.KindConfigInvalid, ierrors.ExitConfigError},
+		{"no resources", m.UpdateResources(ctx, nil, nil), ierrors.KindInternal, ierrors.ExitRuntimeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ierrors.KindOf(tt.err); got != tt.wantKind {
+				t.Errorf("KindOf(%v) = %s, want %s", tt.err, got, tt.wantKind)
+			}
+			if got := ierrors.GetExitCode(tt.err); got != int(tt.wantCode) {
+				t.Errorf("GetExitCode(%v) = %d, want %d", tt.err, got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestExecEnv(t *testing.T) {
+	got := execEnv(map[string]string{"PATH": "/bin", "DEBUG": "1", "EMPTY": ""})
+	want := []string{"DEBUG=1", "EMPTY=", "PATH=/bin"}
+
+	if len(got) != len(want) {
+		t.Fatalf("execEnv() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("execEnv()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestValidateTransferPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"/app", false},
+		{"/app/data/", false},
+		{"/tmp/out/.", false},
+		{"/", false},
+		{"", true},
+		{"app/data", true},
+		{"/app/../etc", true},
+		{"/proc", true},
+		{"/proc/self/environ", true},
+		{"/sys/kernel", true},
+		{"/dev/", true},
+		{"/devices", false},
+		{"/app\x00", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateTransferPath(tt.path); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateTransferPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUploadSpooling(t *testing.T) {
+	m := &Manager{uploads: make(map[string]*upload)}
+
+	m.handleUploadFile(UploadFileMessage{TransferID: "t1", Path: "/app"})
+	m.handleUploadFileData(UploadFileDataMessage{TransferID: "t1", Data: "aGVsbG8="})
+
+	u := m.uploads["t1"]
+	if u == nil || u.size != 5 {
+		t.Fatalf("expected 5 spooled bytes, got %+v", u)
+	}
+	spool := u.file.Name()
+
+	m.handleUploadFileEnd(context.Background(), UploadFileEndMessage{TransferID: "t1", Abort: true})
+	if _, exists := m.uploads["t1"]; exists {
+		t.Error("aborted upload is still registered")
+	}
+	if _, err := os.Stat(spool); !os.IsNotExist(err) {
+		t.Errorf("spooled archive survived abort: %v", err)
+	}
+
+	// Invalid data fails the upload and drops its spool
+	m.handleUploadFile(UploadFileMessage{TransferID: "t2", Path: "/app"})
+	spool = m.uploads["t2"].file.Name()
+	m.handleUploadFileData(UploadFileDataMessage{TransferID: "t2", Data: "not base64!"})
+	if _, exists := m.uploads["t2"]; exists {
+		t.Error("failed upload is still registered")
+	}
+	if _, err := os.Stat(spool); !os.IsNotExist(err) {
+		t.Errorf("spooled archive survived failure: %v", err)
+	}
+
+	m.handleUploadFile(UploadFileMessage{TransferID: "t3", Path: "/proc"})
+	if _, exists := m.uploads["t3"]; exists {
+		t.Error("upload to a protected path was accepted")
+	}
+}
+
+func TestOpenWorkspaceArchiveRejectsPrivateAddresses(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("archive"))
+	}))
+	defer server.Close()
+
+	_, _, err := openWorkspaceArchive(context.Background(), &config.WorkspaceConfig{URL: server.URL + "/ws.tar.gz?sig=secret"})
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("expected the loopback server to be refused, got %v", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the url query: %v", err)
+	}
+}
+
+func TestCallWebhookRejectsPrivateAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	m := &Manager{containerName: "test"}
+	hook := &config.HookConfig{Name: "notify", WebhookURL: server.URL + "/done?token=secret"}
+	err := m.CallWebhook(context.Background(), hook, 0, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("expected the loopback server to be refused, got %v", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the url query: %v", err)
+	}
+	if called {
+		t.Error("webhook reached a private address")
+	}
+}
+
+func TestLimitedArchive(t *testing.T) {
+	archive := &limitedArchive{r: io.LimitReader(zeroReader{}, config.MaxWorkspaceBytes+1)}
+
+	if _, err := io.Copy(io.Discard, archive); !errors.Is(err, errWorkspaceTooLarge) {
+		t.Errorf("expected errWorkspaceTooLarge, got %v", err)
+	}
+	if !archive.exceeded {
+		t.Error("expected the limit to be recorded as exceeded")
+	}
+}
+
+// zeroReader is an endless stream of zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestResourceUsage(t *testing.T) {
+	stats := &dockercontainer.StatsResponse{
+		CPUStats: dockercontainer.CPUStats{
+			CPUUsage:    dockercontainer.CPUUsage{TotalUsage: 3_000_000_000},
+			SystemUsage: 20_000_000_000,
+			OnlineCPUs:  4,
+		},
+		PreCPUStats: dockercontainer.CPUStats{
+			CPUUsage:    dockercontainer.CPUUsage{TotalUsage: 1_000_000_000},
+			SystemUsage: 12_000_000_000,
+		},
+		MemoryStats: dockercontainer.MemoryStats{
+			Usage: 300 << 20,
+			Limit: 512 << 20,
+			Stats: map[string]uint64{"inactive_file": 100 << 20},
+		},
+		PidsStats: dockercontainer.PidsStats{Current: 7},
+		Networks: map[string]dockercontainer.NetworkStats{
+			"eth0": {RxBytes: 1000, TxBytes: 200},
+			"eth1": {RxBytes: 24, TxBytes: 56},
+		},
+		BlkioStats: dockercontainer.BlkioStats{
+			IoServiceBytesRecursive: []dockercontainer.BlkioStatEntry{
+				{Op: "read", Value: 4096},
+				{Op: "Write", Value: 8192},
+				{Op: "Total", Value: 12288},
+			},
+		},
+	}
+
+	usage := resourceUsage(stats)
+
+	// 2s of CPU over 8s of system time on 4 CPUs is one CPU's worth
+	if usage.CPUPercent != 100 {
+		t.Errorf("CPUPercent = %v, want 100", usage.CPUPercent)
+	}
+	if usage.CPUTimeSecs != 3 {
+		t.Errorf("CPUTimeSecs = %v, want 3", usage.CPUTimeSecs)
+	}
+	if usage.MemoryUsageBytes != 200<<20 || usage.MemoryLimitBytes != 512<<20 {
+		t.Errorf("memory = %d/%d, want %d/%d", usage.MemoryUsageBytes, usage.MemoryLimitBytes, 200<<20, 512<<20)
+	}
+	if usage.NetRxBytes != 1024 || usage.NetTxBytes != 256 {
+		t.Errorf("net = %d/%d, want 1024/256", usage.NetRxBytes, usage.NetTxBytes)
+	}
+	if usage.BlockReadBytes != 4096 || usage.BlockWriteBytes != 8192 {
+		t.Errorf("block = %d/%d, want 4096/8192", usage.BlockReadBytes, usage.BlockWriteBytes)
+	}
+	if usage.Pids != 7 {
+		t.Errorf("Pids = %d, want 7", usage.Pids)
+	}
+}
+
+func TestCheckProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			w.WriteHeader(http.StatusNoContent)
+		case "/moved":
+			http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		probe   config.ProbeConfig
+		wantErr bool
+	}{
+		{"tcp open", config.ProbeConfig{Port: uint32(port)}, false},
+		{"tcp closed", config.ProbeConfig{Port: uint32(closedPort)}, true},
+		{"http ok", config.ProbeConfig{Port: uint32(port), HTTPPath: "/healthz"}, false},
+		{"http redirect counts without being followed", config.ProbeConfig{Port: uint32(port), HTTPPath: "/moved"}, false},
+		{"http unavailable", config.ProbeConfig{Port: uint32(port), HTTPPath: "/starting"}, true},
+	}
+
+	m := &Manager{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.checkProbe(context.Background(), &tt.probe, host)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkProbe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
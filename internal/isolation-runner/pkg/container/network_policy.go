@@ -0,0 +1,47 @@
+package container
+
+import (
+	"fmt"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/bastion"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// UpdateNetworkPolicyMessage replaces the network policy of the running container
+type UpdateNetworkPolicyMessage struct {
+	Network config.NetworkConfig `json:"network"`
+}
+
+func (m *Manager) handleUpdateNetworkPolicy(msg UpdateNetworkPolicyMessage) {
+	m.captureMu.Lock()
+	chainName := m.chainName
+	m.captureMu.Unlock()
+	if chainName == "" {
+		jsonmsg.NetworkPolicyUpdateFailed(m.containerID, "network isolation is not configured")
+		return
+	}
+
+	// The new policy gets the same security enforcement as the initial one
+	network := msg.Network
+	if err := config.ValidateNetworkConfig(&network); err != nil {
+		jsonmsg.NetworkPolicyUpdateFailed(m.containerID, fmt.Sprintf("network security validation failed: %v", err))
+		return
+	}
+
+	bastionClient, err := bastion.Dial(config.GetBastionAddress(), m.containerID)
+	if err != nil {
+		jsonmsg.NetworkPolicyUpdateFailed(m.containerID, fmt.Sprintf("could not connect to bastion: %v", err))
+		return
+	}
+	defer bastionClient.Close()
+
+	policy := bastion.PolicyFromConfig(&network, m.config.Logging.LogNetworkAttempts)
+	if err := bastionClient.UpdateNetworkPolicy(chainName, policy); err != nil {
+		jsonmsg.NetworkPolicyUpdateFailed(m.containerID, err.Error())
+		return
+	}
+
+	m.config.Network = network
+	jsonmsg.NetworkPolicyUpdated(m.containerID, chainName, network.DefaultPolicy)
+}
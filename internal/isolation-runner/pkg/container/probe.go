@@ -0,0 +1,168 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+const (
+	defaultProbePeriod  = 1 * time.Second
+	defaultProbeTimeout = 1 * time.Second
+)
+
+// StartReadinessProbe runs the configured readiness probe against the container
+// at ip until it passes, then emits container_ready. The returned function
+// stops probing and waits for it.
+func (m *Manager) StartReadinessProbe(ctx context.Context, ip net.IP) func() {
+	probe := m.config.Container.ReadinessProbe
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		jsonmsg.Info("Waiting for Holopod instance to pass its readiness probe")
+
+		period := defaultProbePeriod
+		if probe.PeriodSecs > 0 {
+			period = time.Duration(probe.PeriodSecs) * time.Second
+		}
+
+		wait := time.Duration(probe.InitialDelaySecs) * time.Second
+		var lastErr string
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			wait = period
+
+			err := m.checkProbe(ctx, probe, ip.String())
+			if err == nil {
+				jsonmsg.ContainerReady(m.containerID, ip.String())
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			// Report each new reason once rather than on every attempt
+			if err.Error() != lastErr {
+				lastErr = err.Error()
+				jsonmsg.ReadinessProbeFailed(m.containerID, lastErr)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// checkProbe makes a single probe attempt against the container at host
+func (m *Manager) checkProbe(ctx context.Context, probe *config.ProbeConfig, host string) error {
+	timeout := defaultProbeTimeout
+	if probe.TimeoutSecs > 0 {
+		timeout = time.Duration(probe.TimeoutSecs) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if len(probe.Command) > 0 {
+		return m.execProbe(ctx, probe.Command)
+	}
+
+	addr := net.JoinHostPort(host, strconv.FormatUint(uint64(probe.Port), 10))
+	if probe.HTTPPath != "" {
+		return httpProbe(ctx, "http://"+addr+probe.HTTPPath)
+	}
+	return tcpProbe(ctx, addr)
+}
+
+func tcpProbe(ctx context.Context, addr string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("tcp probe: %w", err)
+	}
+	conn.Close()
+	return nil
+}
+
+// probeClient does not follow redirects: a 3xx already counts as ready, and the
+// workload must not be able to point the runner at other hosts
+var probeClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func httpProbe(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("http probe: %w", err)
+	}
+
+	resp, err := probeClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http probe: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("http probe: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// execProbe runs command in the container, discarding its output, and fails
+// unless it exits 0
+func (m *Manager) execProbe(ctx context.Context, command []string) error {
+	created, err := m.docker.ContainerExecCreate(ctx, m.containerID, container.ExecOptions{
+		Cmd:          command,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("command probe: %s", sanitizeDockerError(err.Error()))
+	}
+
+	resp, err := m.docker.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return fmt.Errorf("command probe: %s", sanitizeDockerError(err.Error()))
+	}
+	defer resp.Close()
+
+	// Like exec, a timed out probe command is left to end with the container
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, resp.Reader)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("command probe: timed out")
+	}
+
+	inspect, err := m.docker.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return fmt.Errorf("command probe: %s", sanitizeDockerError(err.Error()))
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("command probe: exited with code %d", inspect.ExitCode)
+	}
+	return nil
+}
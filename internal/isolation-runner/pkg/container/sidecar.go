@@ -0,0 +1,119 @@
+package container
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// sidecarStopTimeout is how long a sidecar may take to stop once the main
+// container has exited
+const sidecarStopTimeout = 5
+
+type sidecar struct {
+	name        string
+	containerID string
+}
+
+// StartSidecars pulls, creates and starts the configured sidecars in the main
+// container's network namespace, so they reach each other over localhost and
+// share its network policy. A sidecar that fails to start fails them all; the
+// ones already created are returned by SidecarIDs for cleanup either way.
+func (m *Manager) StartSidecars(ctx context.Context) error {
+	for i := range m.config.Container.Sidecars {
+		if err := m.startSidecar(ctx, &m.config.Container.Sidecars[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) startSidecar(ctx context.Context, sc *config.AuxContainerConfig) error {
+	id, err := m.createAuxContainer(ctx, "sidecar", sc, "container:"+m.containerID, nil)
+	if err != nil {
+		return err
+	}
+
+	m.sidecarMu.Lock()
+	m.sidecars = append(m.sidecars, sidecar{name: sc.Name, containerID: id})
+	m.sidecarMu.Unlock()
+
+	name := sc.Name
+	started := func() { jsonmsg.SidecarStarted(m.containerID, name, id) }
+	if _, err := m.startAuxContainer(ctx, "sidecar", name, id, started, func(stream string, data []byte) {
+		jsonmsg.SidecarOutput(name, stream, data)
+	}); err != nil {
+		return err
+	}
+
+	go m.watchSidecar(name, id)
+
+	return nil
+}
+
+// watchSidecar reports the sidecar's exit, whenever it comes
+func (m *Manager) watchSidecar(name, sidecarID string) {
+	statusCh, errCh := m.docker.ContainerWait(context.Background(), sidecarID, container.WaitConditionNotRunning)
+
+	select {
+	case status := <-statusCh:
+		jsonmsg.SidecarExited(m.containerID, name, int(status.StatusCode))
+	case err := <-errCh:
+		if err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Error waiting for sidecar '%s': %v", name, err))
+		}
+	}
+}
+
+// SidecarIDs returns the Docker IDs of the sidecars created so far
+func (m *Manager) SidecarIDs() []string {
+	m.sidecarMu.Lock()
+	defer m.sidecarMu.Unlock()
+
+	ids := make([]string, 0, len(m.sidecars))
+	for _, sc := range m.sidecars {
+		ids = append(ids, sc.containerID)
+	}
+	return ids
+}
+
+// RemoveSidecars stops and removes every sidecar
+func (m *Manager) RemoveSidecars(ctx context.Context) {
+	m.sidecarMu.Lock()
+	sidecars := m.sidecars
+	m.sidecars = nil
+	m.sidecarMu.Unlock()
+
+	for _, sc := range sidecars {
+		timeout := sidecarStopTimeout
+		_ = m.docker.ContainerStop(ctx, sc.containerID, container.StopOptions{Timeout: &timeout})
+
+		err := m.docker.ContainerRemove(ctx, sc.containerID, container.RemoveOptions{Force: true})
+		if err != nil && !client.IsErrNotFound(err) {
+			jsonmsg.Warning(fmt.Sprintf("Failed to remove sidecar '%s': %s", sc.name, sanitizeDockerError(err.Error())))
+		}
+	}
+}
+
+// setSidecarsPaused freezes or resumes the sidecars along with the main container
+func (m *Manager) setSidecarsPaused(ctx context.Context, paused bool) {
+	m.sidecarMu.Lock()
+	sidecars := m.sidecars
+	m.sidecarMu.Unlock()
+
+	action, apply := "pause", m.docker.ContainerPause
+	if !paused {
+		action, apply = "unpause", m.docker.ContainerUnpause
+	}
+
+	for _, sc := range sidecars {
+		if err := apply(ctx, sc.containerID); err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Failed to %s sidecar '%s': %s", action, sc.name, sanitizeDockerError(err.Error())))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// StartStatsStream emits a container_stats event every interval until the
+// container exits. The returned function stops the stream and waits for it.
+func (m *Manager) StartStatsStream(ctx context.Context, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		warned := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			usage, err := m.sampleStats(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				// Keep trying, but say so only once
+				if !warned {
+					jsonmsg.Warning(fmt.Sprintf("Failed to read container stats: %s", sanitizeDockerError(err.Error())))
+					warned = true
+				}
+				continue
+			}
+			jsonmsg.ContainerStats(m.containerID, usage)
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (m *Manager) sampleStats(ctx context.Context) (jsonmsg.ResourceUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// A non-streaming read waits for a second sample, so precpu_stats is set
+	resp, err := m.docker.ContainerStats(ctx, m.containerID, false)
+	if err != nil {
+		return jsonmsg.ResourceUsage{}, err
+	}
+	defer resp.Body.Close()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return jsonmsg.ResourceUsage{}, fmt.Errorf("failed to decode stats: %w", err)
+	}
+
+	return resourceUsage(&stats), nil
+}
+
+// resourceUsage condenses a Docker stats sample the way `docker stats` does
+func resourceUsage(stats *container.StatsResponse) jsonmsg.ResourceUsage {
+	usage := jsonmsg.ResourceUsage{
+		CPUPercent:       cpuPercent(stats),
+		CPUTimeSecs:      float64(stats.CPUStats.CPUUsage.TotalUsage) / float64(time.Second),
+		MemoryUsageBytes: memoryUsage(&stats.MemoryStats),
+		MemoryLimitBytes: stats.MemoryStats.Limit,
+		Pids:             stats.PidsStats.Current,
+	}
+
+	for _, network := range stats.Networks {
+		usage.NetRxBytes += network.RxBytes
+		usage.NetTxBytes += network.TxBytes
+	}
+
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			usage.BlockReadBytes += entry.Value
+		case "write":
+			usage.BlockWriteBytes += entry.Value
+		}
+	}
+
+	return usage
+}
+
+// cpuPercent is the container's CPU time over the sample window as a share of
+// one CPU, so a container saturating two CPUs reports 200
+func cpuPercent(stats *container.StatsResponse) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	cpus := float64(stats.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpus == 0 {
+		cpus = 1
+	}
+
+	return cpuDelta / systemDelta * cpus * 100
+}
+
+// memoryUsage excludes the page cache the kernel can reclaim, which would
+// otherwise make a container that reads many files look close to its limit
+func memoryUsage(mem *container.MemoryStats) uint64 {
+	cache, ok := mem.Stats["inactive_file"] // cgroup v2
+	if !ok {
+		cache = mem.Stats["total_inactive_file"] // cgroup v1
+	}
+	if cache > mem.Usage {
+		return 0
+	}
+	return mem.Usage - cache
+}
@@ -7,37 +7,74 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/stdio"
 )
 
+// StdinMessage is a single newline-delimited command sent by the container-manager.
+// Type selects the command; "stdin" carries base64 workload input in Data.
 type StdinMessage struct {
 	Type string `json:"type"`
 	Data string `json:"data"`
 }
 
+// ResizeMessage sets the size of the container's tty
+type ResizeMessage struct {
+	Rows uint `json:"rows"`
+	Cols uint `json:"cols"`
+}
+
+// SignalMessage sends a signal, e.g. "SIGHUP", to the container's main process
+type SignalMessage struct {
+	Signal string `json:"signal"`
+}
+
+// UpdateResourcesMessage requests new CPU/memory limits for the running container
+type UpdateResourcesMessage struct {
+	CPULimit    *string `json:"cpu_limit,omitempty"`
+	MemoryLimit *string `json:"memory_limit,omitempty"`
+}
+
 func (m *Manager) StartStdinForwarder(ctx context.Context) error {
 	if m.containerID == "" {
-		return fmt.Errorf("container not created")
+		return errNotCreated
 	}
 
-	if !m.config.Execution.AttachStdin {
+	// Control commands arrive on stdin even when workload stdin is not attached
+	var conn net.Conn
+	var closeConn func()
+	var closeWrite func() error
+	if m.config.Execution.AttachStdin {
+		resp, err := m.docker.ContainerAttach(ctx, m.containerID, container.AttachOptions{
+			Stream: true,
+			Stdin:  true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to attach stdin to container: %w", err)
+		}
+		conn = resp.Conn
+		closeConn = resp.Close
+		closeWrite = resp.CloseWrite
+	}
+
+	if m.config.Execution.RawStdin {
+		go forwardRawStdin(os.Stdin, conn, closeConn, closeWrite)
 		return nil
 	}
 
-	resp, err := m.docker.ContainerAttach(ctx, m.containerID, container.AttachOptions{
-		Stream: true,
-		Stdin:  true,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to attach stdin to container: %w", err)
+	if m.config.Execution.BinaryStdio {
+		go forwardStdinFrames(stdio.OpenInput(), conn, closeWrite)
 	}
 
 	go func() {
-		defer resp.Close()
+		if closeConn != nil {
+			defer closeConn()
+		}
 
 		scanner := bufio.NewScanner(os.Stdin)
 		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
@@ -50,29 +87,134 @@ func (m *Manager) StartStdinForwarder(ctx context.Context) error {
 				continue
 			}
 
-			if msg.Type != "stdin" {
-				continue
-			}
+			switch msg.Type {
+			case "stdin":
+				if conn == nil {
+					continue
+				}
 
-			data, err := base64.StdEncoding.DecodeString(msg.Data)
-			if err != nil {
-				jsonmsg.Warning(fmt.Sprintf("Failed to decode stdin data: %v", err))
-				continue
-			}
+				data, err := base64.StdEncoding.DecodeString(msg.Data)
+				if err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Failed to decode stdin data: %v", err))
+					continue
+				}
 
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(30 * time.Second):
-				jsonmsg.Warning("Stdin write timeout")
-				return
-			default:
-				if _, err := resp.Conn.Write(data); err != nil {
-					if err != io.EOF {
-						jsonmsg.Warning(fmt.Sprintf("Failed to write to container stdin: %v", err))
-					}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(30 * time.Second):
+					jsonmsg.Warning("Stdin write timeout")
 					return
+				default:
+					if _, err := conn.Write(data); err != nil {
+						if err != io.EOF {
+							jsonmsg.Warning(fmt.Sprintf("Failed to write to container stdin: %v", err))
+						}
+						return
+					}
+				}
+
+			case "update_resources":
+				var update UpdateResourcesMessage
+				if err := json.Unmarshal(line, &update); err != nil {
+					jsonmsg.ContainerResourcesUpdateFailed(m.containerID, fmt.Sprintf("invalid update_resources message: %v", err))
+					continue
+				}
+				m.handleUpdateResources(ctx, update)
+
+			case "resize":
+				var resize ResizeMessage
+				if err := json.Unmarshal(line, &resize); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid resize message: %v", err))
+					continue
+				}
+				m.handleResize(ctx, resize)
+
+			case "signal":
+				var signal SignalMessage
+				if err := json.Unmarshal(line, &signal); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid signal message: %v", err))
+					continue
+				}
+				m.handleSignal(ctx, signal)
+
+			case "pause":
+				m.handlePause(ctx)
+
+			case "unpause":
+				m.handleUnpause(ctx)
+
+			case "checkpoint":
+				var checkpoint CheckpointMessage
+				if err := json.Unmarshal(line, &checkpoint); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid checkpoint message: %v", err))
+					continue
+				}
+				m.handleCheckpoint(ctx, checkpoint)
+
+			case "update_network_policy":
+				var update UpdateNetworkPolicyMessage
+				if err := json.Unmarshal(line, &update); err != nil {
+					jsonmsg.NetworkPolicyUpdateFailed(m.containerID, fmt.Sprintf("invalid update_network_policy message: %v", err))
+					continue
+				}
+				m.handleUpdateNetworkPolicy(update)
+
+			case "exec":
+				var exec ExecMessage
+				if err := json.Unmarshal(line, &exec); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid exec message: %v", err))
+					continue
+				}
+				m.handleExec(ctx, exec)
+
+			case "upload_file":
+				var upload UploadFileMessage
+				if err := json.Unmarshal(line, &upload); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid upload_file message: %v", err))
+					continue
+				}
+				m.handleUploadFile(upload)
+
+			case "upload_file_data":
+				var data UploadFileDataMessage
+				if err := json.Unmarshal(line, &data); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid upload_file_data message: %v", err))
+					continue
+				}
+				m.handleUploadFileData(data)
+
+			case "upload_file_end":
+				var end UploadFileEndMessage
+				if err := json.Unmarshal(line, &end); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid upload_file_end message: %v", err))
+					continue
+				}
+				m.handleUploadFileEnd(ctx, end)
+
+			case "download_file":
+				var download DownloadFileMessage
+				if err := json.Unmarshal(line, &download); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid download_file message: %v", err))
+					continue
+				}
+				m.handleDownloadFile(ctx, download)
+
+			case "start_capture":
+				var start StartCaptureMessage
+				if err := json.Unmarshal(line, &start); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid start_capture message: %v", err))
+					continue
+				}
+				m.handleStartCapture(ctx, start)
+
+			case "stop_capture":
+				var stop StopCaptureMessage
+				if err := json.Unmarshal(line, &stop); err != nil {
+					jsonmsg.Warning(fmt.Sprintf("Invalid stop_capture message: %v", err))
+					continue
 				}
+				m.handleStopCapture(stop)
 			}
 		}
 
@@ -83,3 +225,119 @@ func (m *Manager) StartStdinForwarder(ctx context.Context) error {
 
 	return nil
 }
+
+// forwardRawStdin copies stdin to the workload unchanged, closing the
+// workload's stdin at the end of input
+func forwardRawStdin(input io.Reader, conn net.Conn, closeConn func(), closeWrite func() error) {
+	if conn == nil {
+		return
+	}
+	defer closeConn()
+
+	if _, err := io.Copy(conn, input); err != nil {
+		jsonmsg.Warning(fmt.Sprintf("Failed to write to container stdin: %v", err))
+		return
+	}
+	_ = closeWrite()
+}
+
+// forwardStdinFrames copies stdin frames from the container-manager to the
+// workload. An empty frame, or the end of input, closes the workload's stdin.
+func forwardStdinFrames(input io.ReadCloser, conn net.Conn, closeWrite func() error) {
+	defer input.Close()
+
+	for {
+		stream, data, err := stdio.ReadFrame(input)
+		if err != nil {
+			if err != io.EOF {
+				jsonmsg.Warning(fmt.Sprintf("Failed to read stdin frame: %v", err))
+			}
+			break
+		}
+		if stream != stdio.StreamStdin {
+			continue
+		}
+		if len(data) == 0 {
+			break
+		}
+		if conn == nil {
+			continue
+		}
+
+		if _, err := conn.Write(data); err != nil {
+			if err != io.EOF {
+				jsonmsg.Warning(fmt.Sprintf("Failed to write to container stdin: %v", err))
+			}
+			return
+		}
+	}
+
+	if closeWrite != nil {
+		_ = closeWrite()
+	}
+}
+
+func (m *Manager) handleResize(ctx context.Context, resize ResizeMessage) {
+	if !m.config.Execution.TTY {
+		jsonmsg.Warning("Ignoring resize: container has no tty")
+		return
+	}
+
+	resizeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := m.docker.ContainerResize(resizeCtx, m.containerID, container.ResizeOptions{
+		Height: resize.Rows,
+		Width:  resize.Cols,
+	}); err != nil {
+		jsonmsg.Warning(fmt.Sprintf("Failed to resize tty: %s", sanitizeDockerError(err.Error())))
+	}
+}
+
+func (m *Manager) handleSignal(ctx context.Context, signal SignalMessage) {
+	signalCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := m.docker.ContainerKill(signalCtx, m.containerID, signal.Signal); err != nil {
+		jsonmsg.ContainerSignalFailed(m.containerID, signal.Signal, sanitizeDockerError(err.Error()))
+		return
+	}
+
+	jsonmsg.ContainerSignaled(m.containerID, signal.Signal)
+}
+
+func (m *Manager) handlePause(ctx context.Context) {
+	pauseCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := m.PauseContainer(pauseCtx); err != nil {
+		jsonmsg.ContainerPauseFailed(m.containerID, err.Error())
+		return
+	}
+
+	jsonmsg.ContainerPaused(m.containerID)
+}
+
+func (m *Manager) handleUnpause(ctx context.Context) {
+	unpauseCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := m.UnpauseContainer(unpauseCtx); err != nil {
+		jsonmsg.ContainerResumeFailed(m.containerID, err.Error())
+		return
+	}
+
+	jsonmsg.ContainerResumed(m.containerID)
+}
+
+func (m *Manager) handleUpdateResources(ctx context.Context, update UpdateResourcesMessage) {
+	updateCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := m.UpdateResources(updateCtx, update.CPULimit, update.MemoryLimit); err != nil {
+		jsonmsg.ContainerResourcesUpdateFailed(m.containerID, err.Error())
+		return
+	}
+
+	jsonmsg.ContainerResourcesUpdated(m.containerID, update.CPULimit, update.MemoryLimit)
+}
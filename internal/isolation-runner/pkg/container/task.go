@@ -0,0 +1,136 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// TaskContainer is an auxiliary container run to completion on the
+// container's network, sharing its workspace: an init container or a
+// post-exit hook
+type TaskContainer struct {
+	Name        string
+	ContainerID string
+	kind        string
+	outputDone  <-chan struct{}
+
+	// Events reporting the task; started and exited may be nil
+	started func()
+	output  func(stream string, data []byte)
+	exited  func(exitCode int)
+}
+
+// CreateInitContainer creates the i-th configured init container
+func (m *Manager) CreateInitContainer(ctx context.Context, i int) (*TaskContainer, error) {
+	ic := &m.config.Container.InitContainers[i]
+
+	task := &TaskContainer{
+		Name: ic.Name,
+		kind: "init container",
+		output: func(stream string, data []byte) {
+			jsonmsg.InitContainerOutput(ic.Name, stream, data)
+		},
+		exited: func(exitCode int) {
+			jsonmsg.InitContainerExited(m.containerID, ic.Name, exitCode)
+		},
+	}
+	task.started = func() { jsonmsg.InitContainerStarted(m.containerID, ic.Name, task.ContainerID) }
+
+	return task, m.createTaskContainer(ctx, task, ic)
+}
+
+// CreateHookContainer creates the container of a post-exit hook
+func (m *Manager) CreateHookContainer(ctx context.Context, hook *config.HookConfig) (*TaskContainer, error) {
+	task := &TaskContainer{
+		Name: hook.Name,
+		kind: "hook",
+		output: func(stream string, data []byte) {
+			jsonmsg.HookOutput(hook.Name, stream, data)
+		},
+	}
+
+	return task, m.createTaskContainer(ctx, task, hook.Container)
+}
+
+func (m *Manager) createTaskContainer(ctx context.Context, task *TaskContainer, aux *config.AuxContainerConfig) error {
+	id, err := m.createAuxContainer(ctx, task.kind, aux, m.networkName, m.mounts)
+	if err != nil {
+		return err
+	}
+	task.ContainerID = id
+	return nil
+}
+
+// StartTaskContainer starts a task container and returns its address on the
+// container's network, or nil if it has already exited
+func (m *Manager) StartTaskContainer(ctx context.Context, task *TaskContainer) (net.IP, error) {
+	outputDone, err := m.startAuxContainer(ctx, task.kind, task.Name, task.ContainerID, task.started, task.output)
+	if err != nil {
+		return nil, err
+	}
+	task.outputDone = outputDone
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		inspect, err := m.docker.ContainerInspect(ctx, task.ContainerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s '%s': %s", task.kind, task.Name, sanitizeDockerError(err.Error()))
+		}
+		if inspect.State != nil && !inspect.State.Running {
+			return nil, nil
+		}
+		if inspect.NetworkSettings != nil {
+			if netInfo, ok := inspect.NetworkSettings.Networks[m.networkName]; ok && netInfo.IPAddress != "" {
+				if ip := net.ParseIP(netInfo.IPAddress); ip != nil {
+					return ip, nil
+				}
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	return nil, fmt.Errorf("no IP address assigned to %s '%s' after 10 attempts", task.kind, task.Name)
+}
+
+// WaitTaskContainer waits for a task container to exit and for the rest of its
+// output, then reports its exit code
+func (m *Manager) WaitTaskContainer(ctx context.Context, task *TaskContainer) (int, error) {
+	statusCh, errCh := m.docker.ContainerWait(ctx, task.ContainerID, container.WaitConditionNotRunning)
+
+	var exitCode int
+	select {
+	case status := <-statusCh:
+		exitCode = int(status.StatusCode)
+	case err := <-errCh:
+		return -1, fmt.Errorf("error waiting for %s '%s': %w", task.kind, task.Name, err)
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+
+	if task.outputDone != nil {
+		select {
+		case <-task.outputDone:
+		case <-time.After(2 * time.Second):
+		}
+	}
+
+	if task.exited != nil {
+		task.exited(exitCode)
+	}
+	return exitCode, nil
+}
+
+// RemoveTaskContainer removes a task container, whether or not it has exited
+func (m *Manager) RemoveTaskContainer(ctx context.Context, task *TaskContainer) {
+	err := m.docker.ContainerRemove(ctx, task.ContainerID, container.RemoveOptions{Force: true})
+	if err != nil && !client.IsErrNotFound(err) {
+		jsonmsg.Warning(fmt.Sprintf("Failed to remove %s '%s': %s", task.kind, task.Name, sanitizeDockerError(err.Error())))
+	}
+}
@@ -0,0 +1,205 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// workspaceTmpfsRoot holds the directories behind tmpfs workspaces. A Docker
+// tmpfs mount only exists while the container runs, so it cannot be filled
+// before the command starts; a bind volume on the host's /dev/shm can.
+const workspaceTmpfsRoot = "/dev/shm/holopod-workspaces"
+
+// errWorkspaceTooLarge is returned once a fetched archive passes MaxWorkspaceBytes
+var errWorkspaceTooLarge = fmt.Errorf("workspace archive exceeds %d bytes", config.MaxWorkspaceBytes)
+
+// publicTransport connects from the host's network rather than the
+// container's, so it only dials public addresses: a URL must not reach the
+// node's own services or cloud metadata.
+var publicTransport = &http.Transport{
+	Proxy: nil,
+	DialContext: (&net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !config.IsPublicIP(ip) {
+				return fmt.Errorf("url resolves to non-public address %s", host)
+			}
+			return nil
+		},
+	}).DialContext,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 30 * time.Second,
+}
+
+// workspaceClient fetches workspace archives over publicTransport
+var workspaceClient = &http.Client{
+	Timeout:   5 * time.Minute,
+	Transport: publicTransport,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return fmt.Errorf("too many redirects fetching workspace")
+		}
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("workspace url redirected to non-https location")
+		}
+		return nil
+	},
+}
+
+// workspaceVolumeName is the Docker volume holding the container's workspace
+func (m *Manager) workspaceVolumeName() string {
+	return m.containerName + "-workspace"
+}
+
+// createWorkspaceVolume creates the volume the workspace is extracted into and
+// returns its mount. It is removed again by RemoveWorkspace.
+func (m *Manager) createWorkspaceVolume(ctx context.Context, ws *config.WorkspaceConfig, labels map[string]string) (mount.Mount, error) {
+	options := volume.CreateOptions{
+		Name:   m.workspaceVolumeName(),
+		Driver: "local",
+		Labels: labels,
+	}
+
+	if ws.Mount == "tmpfs" {
+		dir := filepath.Join(workspaceTmpfsRoot, m.containerName)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return mount.Mount{}, fmt.Errorf("failed to create tmpfs workspace: %w", err)
+		}
+		options.DriverOpts = map[string]string{
+			"type":   "none",
+			"o":      "bind",
+			"device": dir,
+		}
+	}
+
+	if _, err := m.docker.VolumeCreate(ctx, options); err != nil {
+		m.RemoveWorkspace(ctx)
+		return mount.Mount{}, fmt.Errorf("failed to create workspace volume: %s", sanitizeDockerError(err.Error()))
+	}
+	m.workspaceVolume = options.Name
+
+	return mount.Mount{
+		Type:   mount.TypeVolume,
+		Source: options.Name,
+		Target: ws.Path,
+	}, nil
+}
+
+// populateWorkspace extracts the workspace archive into the created container.
+// Docker mounts the container's volumes for the copy and decompresses the
+// archive itself.
+func (m *Manager) populateWorkspace(ctx context.Context, ws *config.WorkspaceConfig) error {
+	archive, size, err := openWorkspaceArchive(ctx, ws)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	counted := &limitedArchive{r: archive}
+	if err := m.docker.CopyToContainer(ctx, m.containerID, ws.Path, counted, container.CopyToContainerOptions{}); err != nil {
+		if counted.exceeded {
+			return errWorkspaceTooLarge
+		}
+		return fmt.Errorf("failed to extract workspace: %s", sanitizeDockerError(err.Error()))
+	}
+
+	if size < 0 {
+		size = counted.n
+	}
+	jsonmsg.Info(fmt.Sprintf("Workspace extracted into %s (%d bytes)", ws.Path, size))
+	return nil
+}
+
+// openWorkspaceArchive returns the gzipped archive of ws and its size, or -1
+// when the size is not known up front
+func openWorkspaceArchive(ctx context.Context, ws *config.WorkspaceConfig) (io.ReadCloser, int64, error) {
+	if len(ws.Archive) > 0 {
+		return io.NopCloser(bytes.NewReader(ws.Archive)), int64(len(ws.Archive)), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ws.URL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid workspace url: %w", err)
+	}
+
+	resp, err := workspaceClient.Do(req)
+	if err != nil {
+		// The URL may be presigned; keep its query string out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, 0, fmt.Errorf("failed to fetch workspace: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("failed to fetch workspace: %s", resp.Status)
+	}
+	if resp.ContentLength > config.MaxWorkspaceBytes {
+		resp.Body.Close()
+		return nil, 0, errWorkspaceTooLarge
+	}
+
+	return resp.Body, -1, nil
+}
+
+// limitedArchive fails the copy once more than MaxWorkspaceBytes have been read
+type limitedArchive struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedArchive) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > config.MaxWorkspaceBytes {
+		l.exceeded = true
+		return 0, errWorkspaceTooLarge
+	}
+	return n, err
+}
+
+// RemoveWorkspace removes the workspace volume and, for a tmpfs workspace, the
+// memory it used. The container must already be removed.
+func (m *Manager) RemoveWorkspace(ctx context.Context) {
+	ws := m.config.Container.Workspace
+	if ws == nil {
+		return
+	}
+
+	if m.workspaceVolume != "" {
+		if err := m.docker.VolumeRemove(ctx, m.workspaceVolume, true); err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Failed to remove workspace volume: %v", err))
+		} else {
+			m.workspaceVolume = ""
+		}
+	}
+
+	if ws.Mount == "tmpfs" {
+		if err := os.RemoveAll(filepath.Join(workspaceTmpfsRoot, m.containerName)); err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Failed to remove tmpfs workspace: %v", err))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package diag helps diagnose the runner itself during long interactive runs:
+// an opt-in loopback pprof/expvar listener, and runner_self_stats events on
+// the runner's goroutines and heap.
+package diag
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// ListenAddrEnv names the environment variable holding the address of the
+// pprof/expvar listener, e.g. "127.0.0.1:6060". Unset disables it.
+const ListenAddrEnv = "HOLOPOD_RUNNER_DEBUG_ADDR"
+
+// SelfStatsInterval is how often runner_self_stats is emitted at debug level
+const SelfStatsInterval = 30 * time.Second
+
+// StartListenerFromEnv serves pprof and expvar on the address in
+// ListenAddrEnv until the returned function is called. It returns a no-op
+// when the variable is unset.
+func StartListenerFromEnv() (stop func(), err error) {
+	addr := os.Getenv(ListenAddrEnv)
+	if addr == "" {
+		return func() {}, nil
+	}
+	return StartListener(addr)
+}
+
+// StartListener serves pprof under /debug/pprof/ and expvar under /debug/vars
+// on addr, which must be a loopback address: profiles expose memory contents.
+func StartListener(addr string) (stop func(), err error) {
+	if err := validateLoopback(addr); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+
+	jsonmsg.Debug(fmt.Sprintf("Runner diagnostics listening on %s", listener.Addr()))
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+func validateLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid diagnostics address '%s': %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("diagnostics address '%s' must be on loopback", addr)
+}
+
+// StartSelfStats emits runner_self_stats every interval until the returned
+// function is called. Nothing is emitted unless debug messages are enabled.
+func StartSelfStats(interval time.Duration) (stop func()) {
+	if interval <= 0 || !jsonmsg.Enabled(jsonmsg.LevelDebug) {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				emitSelfStats()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func emitSelfStats() {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	jsonmsg.RunnerSelfStats(runtime.NumGoroutine(), mem.HeapAlloc, mem.HeapInuse, mem.Sys, mem.NumGC)
+}
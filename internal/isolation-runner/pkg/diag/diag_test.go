@@ -0,0 +1,62 @@
+package diag
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+func TestListenerRequiresLoopback(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:6060", ":6060", "10.0.0.1:6060", "example.com:6060", "6060"} {
+		if _, err := StartListener(addr); err == nil {
+			t.Errorf("expected %q to be rejected", addr)
+		}
+	}
+	for _, addr := range []string{"127.0.0.1:6060", "[::1]:6060", "localhost:6060"} {
+		if err := validateLoopback(addr); err != nil {
+			t.Errorf("expected %q to be accepted: %v", addr, err)
+		}
+	}
+}
+
+func TestListenerServesVars(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := probe.Addr().String()
+	probe.Close()
+
+	stop, err := StartListener(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	resp, err := http.Get("http://" + addr + "/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var vars map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vars["memstats"]; !ok {
+		t.Errorf("expected memstats in /debug/vars, got %v", vars)
+	}
+}
+
+func TestSelfStatsOffBelowDebug(t *testing.T) {
+	jsonmsg.SetLevel(jsonmsg.LevelInfo)
+	stop := StartSelfStats(time.Millisecond)
+	defer stop()
+
+	// A no-op stop can be called twice; a running loop would panic closing done twice
+	stop()
+}
@@ -1,6 +1,10 @@
 package errors
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 type ErrorCode int
 
@@ -14,13 +18,38 @@ const (
 	ExitContainerFailed ErrorCode = 126
 )
 
+// Kind is the machine-readable cause of a failure, reported as error_code in
+// error and exit events. Values are stable; add new ones rather than
+// renaming.
+type Kind string
+
+const (
+	KindConfigInvalid       Kind = "CONFIG_INVALID"
+	KindImagePullFailed     Kind = "IMAGE_PULL_FAILED"
+	KindRuntimeUnavailable  Kind = "RUNTIME_UNAVAILABLE"
+	KindDockerError         Kind = "DOCKER_ERROR"
+	KindBastionUnreachable  Kind = "BASTION_UNREACHABLE"
+	KindNetworkSetupFailed  Kind = "NETWORK_SETUP_FAILED"
+	KindWorkspaceFailed     Kind = "WORKSPACE_FAILED"
+	KindInitContainerFailed Kind = "INIT_CONTAINER_FAILED"
+	KindSidecarFailed       Kind = "SIDECAR_FAILED"
+	KindContainerFailed     Kind = "CONTAINER_FAILED"
+	KindTimeout             Kind = "TIMEOUT"
+	KindOOM                 Kind = "OOM"
+	KindInternal            Kind = "INTERNAL"
+)
+
 type IsolationError struct {
 	Code    ErrorCode
+	Kind    Kind
 	Message string
 	Err     error
 }
 
 func (e *IsolationError) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
 	if e.Err != nil {
 		return fmt.Sprintf("%s: %v", e.Message, e.Err)
 	}
@@ -35,9 +64,19 @@ func (e *IsolationError) ExitCode() int {
 	return int(e.Code)
 }
 
+// Wrap classifies err, keeping its message as is
+func Wrap(kind Kind, code ErrorCode, err error) *IsolationError {
+	return &IsolationError{
+		Code: code,
+		Kind: kind,
+		Err:  err,
+	}
+}
+
 func NewConfigError(message string, err error) *IsolationError {
 	return &IsolationError{
 		Code:    ExitConfigError,
+		Kind:    KindConfigInvalid,
 		Message: message,
 		Err:     err,
 	}
@@ -46,6 +85,7 @@ func NewConfigError(message string, err error) *IsolationError {
 func NewSetupError(message string, err error) *IsolationError {
 	return &IsolationError{
 		Code:    ExitSetupError,
+		Kind:    KindInternal,
 		Message: message,
 		Err:     err,
 	}
@@ -54,6 +94,7 @@ func NewSetupError(message string, err error) *IsolationError {
 func NewRuntimeError(message string, err error) *IsolationError {
 	return &IsolationError{
 		Code:    ExitRuntimeError,
+		Kind:    KindInternal,
 		Message: message,
 		Err:     err,
 	}
@@ -62,6 +103,7 @@ func NewRuntimeError(message string, err error) *IsolationError {
 func NewTimeoutError(message string, err error) *IsolationError {
 	return &IsolationError{
 		Code:    ExitTimeout,
+		Kind:    KindTimeout,
 		Message: message,
 		Err:     err,
 	}
@@ -70,6 +112,7 @@ func NewTimeoutError(message string, err error) *IsolationError {
 func NewDockerError(message string, err error) *IsolationError {
 	return &IsolationError{
 		Code:    ExitDockerError,
+		Kind:    KindDockerError,
 		Message: message,
 		Err:     err,
 	}
@@ -78,19 +121,40 @@ func NewDockerError(message string, err error) *IsolationError {
 func NewContainerFailedError(exitCode int, message string) *IsolationError {
 	return &IsolationError{
 		Code:    ErrorCode(exitCode),
+		Kind:    KindContainerFailed,
 		Message: message,
 		Err:     nil,
 	}
 }
 
+// GetExitCode is the exit code the runner ends with for err: its own for a
+// classified error, ExitTimeout for a missed deadline and ExitConfigError
+// otherwise
 func GetExitCode(err error) int {
 	if err == nil {
 		return int(ExitSuccess)
 	}
 
-	if ie, ok := err.(*IsolationError); ok {
+	var ie *IsolationError
+	if errors.As(err, &ie) {
 		return ie.ExitCode()
 	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return int(ExitTimeout)
+	}
 
 	return int(ExitConfigError)
 }
+
+// KindOf is the machine-readable cause of err, KindInternal when it was never
+// classified
+func KindOf(err error) Kind {
+	var ie *IsolationError
+	if errors.As(err, &ie) && ie.Kind != "" {
+		return ie.Kind
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	return KindInternal
+}
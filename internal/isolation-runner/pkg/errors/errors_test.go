@@ -0,0 +1,45 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKindOfAndExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind Kind
+		wantCode int
+	}{
+		{"config", NewConfigError("bad", nil), KindConfigInvalid, int(ExitConfigError)},
+		{"setup", NewSetupError("bad", nil), KindInternal, int(ExitSetupError)},
+		{"runtime", NewRuntimeError("bad", nil), KindInternal, int(ExitRuntimeError)},
+		{"timeout", NewTimeoutError("slow", nil), KindTimeout, int(ExitTimeout)},
+		{"container failed", NewContainerFailedError(137, "killed"), KindContainerFailed, 137},
+		{"wrapped", fmt.Errorf("setup: %w", Wrap(KindImagePullFailed, ExitDockerError, errors.New("denied"))), KindImagePullFailed, int(ExitDockerError)},
+		{"docker", NewDockerError("create", errors.New("conflict")), KindDockerError, int(ExitDockerError)},
+		{"deadline", fmt.Errorf("pull: %w", context.DeadlineExceeded), KindTimeout, int(ExitTimeout)},
+		{"unclassified", errors.New("boom"), KindInternal, int(ExitConfigError)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.wantKind {
+				t.Errorf("KindOf() = %s, want %s", got, tt.wantKind)
+			}
+			if got := GetExitCode(tt.err); got != tt.wantCode {
+				t.Errorf("GetExitCode() = %d, want %d", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestWrapKeepsMessage(t *testing.T) {
+	err := Wrap(KindSidecarFailed, ExitSetupError, errors.New("sidecar 'proxy' failed"))
+	if err.Error() != "sidecar 'proxy' failed" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
@@ -0,0 +1,87 @@
+// Package heartbeat emits the runner's periodic runner_heartbeat event, which
+// tells the container-manager the runner is alive and which phase of the run
+// it is in. A long image pull shows up as a steady "pulling" heartbeat rather
+// than silence.
+package heartbeat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// Phase is the part of the run the runner is in
+type Phase string
+
+const (
+	PhaseStarting   Phase = "starting"
+	PhasePulling    Phase = "pulling"
+	PhaseRunning    Phase = "running"
+	PhaseCleaningUp Phase = "cleaning_up"
+)
+
+var (
+	mu        sync.Mutex
+	phase     = PhaseStarting
+	startedAt = time.Now()
+	running   bool
+)
+
+// SetPhase records the runner's phase, announcing the change right away when
+// heartbeats are running
+func SetPhase(p Phase) {
+	mu.Lock()
+	changed := phase != p
+	phase = p
+	announce := changed && running
+	mu.Unlock()
+
+	if announce {
+		emit()
+	}
+}
+
+// Start emits a heartbeat now and then every interval until the returned
+// function is called. A non-positive interval disables heartbeats.
+func Start(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	mu.Lock()
+	running = true
+	mu.Unlock()
+	emit()
+
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				emit()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			running = false
+			mu.Unlock()
+			close(done)
+		})
+	}
+}
+
+func emit() {
+	mu.Lock()
+	p := phase
+	mu.Unlock()
+	jsonmsg.RunnerHeartbeat(string(p), time.Since(startedAt))
+}
@@ -1,27 +1,136 @@
 package jsonmsg
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 	"time"
 )
 
+// Every line written carries a seq one greater than the line before it,
+// starting at 1, so consumers can tell when events were dropped or reordered
+// on their way through. Once the run ID is known every line carries it too.
+var (
+	output  = os.Stdout
+	emitMu  sync.Mutex
+	lastSeq uint64
+	runID   string
+	mirror  io.Writer
+	level   = LevelInfo
+)
+
+// Level is the severity of a log message. Only debug, info, warning and error
+// messages are filtered by it; lifecycle events always pass through.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+	// LevelNone drops every log message
+	LevelNone
+)
+
+// messageLevels maps the message types that are subject to filtering to
+// their level
+var messageLevels = map[string]Level{
+	"debug":   LevelDebug,
+	"info":    LevelInfo,
+	"warning": LevelWarning,
+	"error":   LevelError,
+}
+
+// ParseLevel parses "debug", "info", "warning" or "error"; "" is info
+func ParseLevel(name string) (Level, error) {
+	if name == "" {
+		return LevelInfo, nil
+	}
+	if l, ok := messageLevels[name]; ok {
+		return l, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level '%s'", name)
+}
+
+// SetLevel drops log messages below l from now on
+func SetLevel(l Level) {
+	emitMu.Lock()
+	defer emitMu.Unlock()
+	level = l
+}
+
+// SetMirror copies every line written from now on to w as well, such as the
+// event log file. Errors writing to w never hold up stdout.
+func SetMirror(w io.Writer) {
+	emitMu.Lock()
+	defer emitMu.Unlock()
+	mirror = w
+}
+
+// SetRunID stamps every line written from now on with the run ID the
+// container-manager assigned, which ties them to its own logs and the
+// bastion's audit log
+func SetRunID(id string) {
+	emitMu.Lock()
+	defer emitMu.Unlock()
+	runID = id
+}
+
 type OutputMessage struct {
 	Type      string  `json:"type"`
+	Seq       uint64  `json:"seq"`
+	RunID     string  `json:"run_id,omitempty"`
 	Message   *string `json:"message,omitempty"`
 	ExitCode  *int    `json:"exit_code,omitempty"`
 	Container *string `json:"container,omitempty"`
+	// ErrorCode is the machine-readable cause of an error or a failed exit,
+	// one of the Kind values of the errors package
+	ErrorCode *string `json:"error_code,omitempty"`
 	Timestamp string  `json:"timestamp"`
 }
 
 // StructuredEvent is a flexible event structure for lifecycle events
 type StructuredEvent struct {
 	Type      string         `json:"type"`
+	Seq       uint64         `json:"seq"`
+	RunID     string         `json:"run_id,omitempty"`
 	Timestamp string         `json:"timestamp"`
 	Data      map[string]any `json:"data,omitempty"`
 }
 
+// emit numbers msg through stamp and writes it as one line. Numbering and
+// writing under one lock keeps seq in the order lines appear.
+func emit(what string, msg any, stamp func(seq uint64, runID string)) {
+	emitMu.Lock()
+	defer emitMu.Unlock()
+
+	stamp(lastSeq+1, runID)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to marshal %s: %v\n", what, err)
+		return
+	}
+	lastSeq++
+
+	data = append(data, '\n')
+	output.Write(data)
+	output.Sync() // Flush immediately
+	if mirror != nil {
+		mirror.Write(data)
+	}
+}
+
+func Debug(message string) {
+	Emit(OutputMessage{
+		Type:      "debug",
+		Message:   &message,
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+	})
+}
+
 func Info(message string) {
 	Emit(OutputMessage{
 		Type:      "info",
@@ -46,12 +155,28 @@ func Error(message string) {
 	})
 }
 
-func ContainerExit(exitCode int) {
+// ErrorWithCode emits an error with its machine-readable cause
+func ErrorWithCode(message string, errorCode string) {
 	Emit(OutputMessage{
+		Type:      "error",
+		Message:   &message,
+		ErrorCode: &errorCode,
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+	})
+}
+
+// ContainerExit emits the runner's final exit; errorCode is the cause of a
+// failure, or empty
+func ContainerExit(exitCode int, errorCode string) {
+	msg := OutputMessage{
 		Type:      "container_exited",
 		ExitCode:  &exitCode,
 		Timestamp: time.Now().Format(time.RFC3339Nano),
-	})
+	}
+	if errorCode != "" {
+		msg.ErrorCode = &errorCode
+	}
+	Emit(msg)
 }
 
 func ContainerName(name string) {
@@ -70,13 +195,7 @@ func ContainerStdout(data string) {
 			"data": data,
 		},
 	}
-	jsonData, err := json.Marshal(msg)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to marshal output message: %v\n", err)
-		return
-	}
-	fmt.Println(string(jsonData))
-	os.Stdout.Sync() // Flush immediately
+	emit("output message", msg, stampMap(msg))
 }
 
 func ContainerStderr(data string) {
@@ -87,34 +206,40 @@ func ContainerStderr(data string) {
 			"data": data,
 		},
 	}
-	jsonData, err := json.Marshal(msg)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to marshal output message: %v\n", err)
-		return
-	}
-	fmt.Println(string(jsonData))
-	os.Stdout.Sync() // Flush immediately
+	emit("output message", msg, stampMap(msg))
 }
 
 func Emit(msg OutputMessage) {
-	data, err := json.Marshal(msg)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to marshal output message: %v\n", err)
+	if l, ok := messageLevels[msg.Type]; ok && l < currentLevel() {
 		return
 	}
-	fmt.Println(string(data))
-	os.Stdout.Sync() // Flush immediately
+	emit("output message", &msg, func(seq uint64, runID string) { msg.Seq, msg.RunID = seq, runID })
 }
 
 // EmitEvent emits a structured event
 func EmitEvent(event StructuredEvent) {
-	data, err := json.Marshal(event)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to marshal event: %v\n", err)
-		return
+	emit("event", &event, func(seq uint64, runID string) { event.Seq, event.RunID = seq, runID })
+}
+
+// Enabled reports whether log messages at l are emitted
+func Enabled(l Level) bool {
+	return l >= currentLevel()
+}
+
+func currentLevel() Level {
+	emitMu.Lock()
+	defer emitMu.Unlock()
+	return level
+}
+
+// stampMap stamps a message built as a map
+func stampMap(msg map[string]any) func(seq uint64, runID string) {
+	return func(seq uint64, runID string) {
+		msg["seq"] = seq
+		if runID != "" {
+			msg["run_id"] = runID
+		}
 	}
-	fmt.Println(string(data))
-	os.Stdout.Sync() // Flush immediately
 }
 
 // Lifecycle Events - structured JSON output for important events
@@ -197,6 +322,39 @@ func NetworkIsolationReady(containerID string, chainName string, defaultPolicy s
 	})
 }
 
+// NetworkAttempt emits for each outbound connection logged by the bastion
+func ContainerPortReady(containerID string, containerPort uint32, hostPort uint32, protocol string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_port_ready",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id":   containerID,
+			"container_port": containerPort,
+			"host_port":      hostPort,
+			"protocol":       protocol,
+		},
+	})
+}
+
+func NetworkAttempt(containerID string, verdict string, protocol string, src string, dst string, dstPort uint32) {
+	data := map[string]any{
+		"container_id": containerID,
+		"verdict":      verdict,
+		"protocol":     protocol,
+		"src":          src,
+		"dst":          dst,
+	}
+	if dstPort != 0 {
+		data["dst_port"] = dstPort
+	}
+
+	EmitEvent(StructuredEvent{
+		Type:      "network_attempt",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data:      data,
+	})
+}
+
 // ContainerTerminating emits when a container is being terminated
 func ContainerTerminating(containerID string, reason string, force bool) {
 	EmitEvent(StructuredEvent{
@@ -210,16 +368,21 @@ func ContainerTerminating(containerID string, reason string, force bool) {
 	})
 }
 
-// ContainerExited emits when a container exits (enhanced version with more details)
-func ContainerExitedWithDetails(containerID string, exitCode int, duration string) {
+// ContainerExited emits when a container exits (enhanced version with more
+// details); errorCode is the cause of a failure, or empty
+func ContainerExitedWithDetails(containerID string, exitCode int, duration string, errorCode string) {
+	data := map[string]any{
+		"container_id": containerID,
+		"exit_code":    exitCode,
+		"duration":     duration,
+	}
+	if errorCode != "" {
+		data["error_code"] = errorCode
+	}
 	EmitEvent(StructuredEvent{
 		Type:      "container_exited",
 		Timestamp: time.Now().Format(time.RFC3339Nano),
-		Data: map[string]any{
-			"container_id": containerID,
-			"exit_code":    exitCode,
-			"duration":     duration,
-		},
+		Data:      data,
 	})
 }
 
@@ -234,3 +397,525 @@ func ContainerReady(containerID string, ipAddress string) {
 		},
 	})
 }
+
+// ReadinessProbeFailed emits when the readiness probe fails for a new reason;
+// it keeps being retried until it passes or the container exits
+func ReadinessProbeFailed(containerID string, errMsg string) {
+	EmitEvent(StructuredEvent{
+		Type:      "readiness_probe_failed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"error":        errMsg,
+		},
+	})
+}
+
+// ContainerResourcesUpdated emits when CPU/memory limits were changed on a running container
+func ContainerResourcesUpdated(containerID string, cpuLimit *string, memoryLimit *string) {
+	data := map[string]any{
+		"container_id": containerID,
+	}
+	if cpuLimit != nil {
+		data["cpu_limit"] = *cpuLimit
+	}
+	if memoryLimit != nil {
+		data["memory_limit"] = *memoryLimit
+	}
+
+	EmitEvent(StructuredEvent{
+		Type:      "container_resources_updated",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data:      data,
+	})
+}
+
+// ContainerResourcesUpdateFailed emits when a resource update could not be applied
+func ContainerResourcesUpdateFailed(containerID string, errMsg string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_resources_update_failed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"error":        errMsg,
+		},
+	})
+}
+
+// ContainerSignaled emits when a signal was delivered to the container's main process
+func ContainerSignaled(containerID string, signal string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_signaled",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"signal":       signal,
+		},
+	})
+}
+
+// ContainerSignalFailed emits when a signal could not be delivered
+func ContainerSignalFailed(containerID string, signal string, errMsg string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_signal_failed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"signal":       signal,
+			"error":        errMsg,
+		},
+	})
+}
+
+// ContainerPaused emits when every process of the container has been frozen
+func ContainerPaused(containerID string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_paused",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+		},
+	})
+}
+
+// ContainerPauseFailed emits when the container could not be paused
+func ContainerPauseFailed(containerID string, errMsg string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_pause_failed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"error":        errMsg,
+		},
+	})
+}
+
+// ResourceUsage is one sample of a container's resource consumption. CPU time,
+// network and block IO are totals since the container started.
+type ResourceUsage struct {
+	CPUPercent       float64
+	CPUTimeSecs      float64
+	MemoryUsageBytes uint64
+	MemoryLimitBytes uint64
+	NetRxBytes       uint64
+	NetTxBytes       uint64
+	BlockReadBytes   uint64
+	BlockWriteBytes  uint64
+	Pids             uint64
+}
+
+// ContainerStats emits a periodic sample of the container's resource usage
+func ContainerStats(containerID string, usage ResourceUsage) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_stats",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id":       containerID,
+			"cpu_percent":        usage.CPUPercent,
+			"cpu_time_secs":      usage.CPUTimeSecs,
+			"memory_usage_bytes": usage.MemoryUsageBytes,
+			"memory_limit_bytes": usage.MemoryLimitBytes,
+			"net_rx_bytes":       usage.NetRxBytes,
+			"net_tx_bytes":       usage.NetTxBytes,
+			"block_read_bytes":   usage.BlockReadBytes,
+			"block_write_bytes":  usage.BlockWriteBytes,
+			"pids":               usage.Pids,
+		},
+	})
+}
+
+// ContainerCheckpointed emits when the container's state has been saved
+func ContainerCheckpointed(containerID string, checkpointID string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_checkpointed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id":  containerID,
+			"checkpoint_id": checkpointID,
+		},
+	})
+}
+
+// ContainerCheckpointFailed emits when the container's state could not be saved
+func ContainerCheckpointFailed(containerID string, checkpointID string, errMsg string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_checkpoint_failed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id":  containerID,
+			"checkpoint_id": checkpointID,
+			"error":         errMsg,
+		},
+	})
+}
+
+// ContainerResumed emits when a paused container is running again
+func ContainerResumed(containerID string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_resumed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+		},
+	})
+}
+
+// ContainerResumeFailed emits when a paused container could not be resumed
+func ContainerResumeFailed(containerID string, errMsg string) {
+	EmitEvent(StructuredEvent{
+		Type:      "container_resume_failed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"error":        errMsg,
+		},
+	})
+}
+
+// NetworkPolicyUpdated emits when the network policy of a running container was replaced
+func NetworkPolicyUpdated(containerID string, chainName string, defaultPolicy string) {
+	EmitEvent(StructuredEvent{
+		Type:      "network_policy_updated",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id":   containerID,
+			"chain_name":     chainName,
+			"default_policy": defaultPolicy,
+		},
+	})
+}
+
+// NetworkPolicyUpdateFailed emits when a network policy update could not be applied
+func NetworkPolicyUpdateFailed(containerID string, errMsg string) {
+	EmitEvent(StructuredEvent{
+		Type:      "network_policy_update_failed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"error":        errMsg,
+		},
+	})
+}
+
+// CaptureStarted emits when a packet capture begins
+func CaptureStarted(containerID string, captureID string) {
+	EmitEvent(StructuredEvent{
+		Type:      "capture_started",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"capture_id":   captureID,
+		},
+	})
+}
+
+// CaptureData emits a chunk of pcap data for a running capture
+func CaptureData(captureID string, data []byte) {
+	EmitEvent(StructuredEvent{
+		Type:      "capture_data",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"capture_id": captureID,
+			"data":       base64.StdEncoding.EncodeToString(data),
+		},
+	})
+}
+
+// CaptureStopped emits when a packet capture ends, with errMsg set if it failed
+func CaptureStopped(containerID string, captureID string, bytes int64, errMsg string) {
+	data := map[string]any{
+		"container_id": containerID,
+		"capture_id":   captureID,
+		"bytes":        bytes,
+	}
+	if errMsg != "" {
+		data["error"] = errMsg
+	}
+
+	EmitEvent(StructuredEvent{
+		Type:      "capture_stopped",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data:      data,
+	})
+}
+
+// ExecStarted emits when an additional process starts in the container
+func ExecStarted(containerID string, execID string) {
+	EmitEvent(StructuredEvent{
+		Type:      "exec_started",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"exec_id":      execID,
+		},
+	})
+}
+
+// ExecOutput emits a chunk of an exec'd process's stdout or stderr
+func ExecOutput(execID string, stream string, data []byte) {
+	EmitEvent(StructuredEvent{
+		Type:      "exec_output",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"exec_id": execID,
+			"stream":  stream,
+			"data":    base64.StdEncoding.EncodeToString(data),
+		},
+	})
+}
+
+// ExecExited emits when an exec'd process ends, with errMsg set if it could
+// not be run or its exit code is unknown
+func ExecExited(containerID string, execID string, exitCode int, errMsg string) {
+	data := map[string]any{
+		"container_id": containerID,
+		"exec_id":      execID,
+		"exit_code":    exitCode,
+	}
+	if errMsg != "" {
+		data["error"] = errMsg
+	}
+
+	EmitEvent(StructuredEvent{
+		Type:      "exec_exited",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data:      data,
+	})
+}
+
+// SidecarStarted emits when an auxiliary container has started in the main
+// container's network namespace
+func SidecarStarted(containerID string, name string, sidecarID string) {
+	EmitEvent(StructuredEvent{
+		Type:      "sidecar_started",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"sidecar":      name,
+			"sidecar_id":   sidecarID,
+		},
+	})
+}
+
+// SidecarOutput emits a chunk of a sidecar's stdout or stderr
+func SidecarOutput(name string, stream string, data []byte) {
+	EmitEvent(StructuredEvent{
+		Type:      "sidecar_output",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"sidecar": name,
+			"stream":  stream,
+			"data":    base64.StdEncoding.EncodeToString(data),
+		},
+	})
+}
+
+// SidecarExited emits when a sidecar stops, whether on its own or because the
+// main container exited
+func SidecarExited(containerID string, name string, exitCode int) {
+	EmitEvent(StructuredEvent{
+		Type:      "sidecar_exited",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"sidecar":      name,
+			"exit_code":    exitCode,
+		},
+	})
+}
+
+// InitContainerStarted emits when an init container has started, ahead of
+// the main container
+func InitContainerStarted(containerID string, name string, initContainerID string) {
+	EmitEvent(StructuredEvent{
+		Type:      "init_container_started",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id":      containerID,
+			"init_container":    name,
+			"init_container_id": initContainerID,
+		},
+	})
+}
+
+// InitContainerOutput emits a chunk of an init container's stdout or stderr
+func InitContainerOutput(name string, stream string, data []byte) {
+	EmitEvent(StructuredEvent{
+		Type:      "init_container_output",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"init_container": name,
+			"stream":         stream,
+			"data":           base64.StdEncoding.EncodeToString(data),
+		},
+	})
+}
+
+// InitContainerExited emits when an init container has run to completion;
+// the main container only starts if every one exits 0
+func InitContainerExited(containerID string, name string, exitCode int) {
+	EmitEvent(StructuredEvent{
+		Type:      "init_container_exited",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id":   containerID,
+			"init_container": name,
+			"exit_code":      exitCode,
+		},
+	})
+}
+
+// HookStarted emits when a post-exit hook begins; kind is container or webhook
+func HookStarted(containerID string, hook string, kind string) {
+	EmitEvent(StructuredEvent{
+		Type:      "hook_started",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"hook":         hook,
+			"kind":         kind,
+		},
+	})
+}
+
+// HookOutput emits a chunk of a hook container's stdout or stderr
+func HookOutput(hook string, stream string, data []byte) {
+	EmitEvent(StructuredEvent{
+		Type:      "hook_output",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"hook":   hook,
+			"stream": stream,
+			"data":   base64.StdEncoding.EncodeToString(data),
+		},
+	})
+}
+
+// HookCompleted emits when a post-exit hook has finished, with errMsg set if
+// it failed, timed out or its container exited non-zero
+func HookCompleted(containerID string, hook string, exitCode int, errMsg string, duration string) {
+	data := map[string]any{
+		"container_id": containerID,
+		"hook":         hook,
+		"exit_code":    exitCode,
+		"duration":     duration,
+	}
+	if errMsg != "" {
+		data["error"] = errMsg
+	}
+
+	EmitEvent(StructuredEvent{
+		Type:      "hook_completed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data:      data,
+	})
+}
+
+// FileDownloadData emits the next chunk of a download's tar archive
+func FileDownloadData(transferID string, data []byte) {
+	EmitEvent(StructuredEvent{
+		Type:      "file_download_data",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"transfer_id": transferID,
+			"data":        base64.StdEncoding.EncodeToString(data),
+		},
+	})
+}
+
+// FileDownloaded emits when a download's archive has been sent in full
+func FileDownloaded(containerID string, transferID string, path string, bytes int64) {
+	EmitEvent(StructuredEvent{
+		Type:      "file_downloaded",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"transfer_id":  transferID,
+			"path":         path,
+			"bytes":        bytes,
+		},
+	})
+}
+
+// FileUploaded emits when an uploaded archive has been extracted into the container
+func FileUploaded(containerID string, transferID string, path string, bytes int64) {
+	EmitEvent(StructuredEvent{
+		Type:      "file_uploaded",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"container_id": containerID,
+			"transfer_id":  transferID,
+			"path":         path,
+			"bytes":        bytes,
+		},
+	})
+}
+
+// FileTransferFailed emits when an upload or download cannot be completed
+func FileTransferFailed(transferID string, errMsg string) {
+	EmitEvent(StructuredEvent{
+		Type:      "file_transfer_failed",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"transfer_id": transferID,
+			"error":       errMsg,
+		},
+	})
+}
+
+// RunnerHello emits on startup, before the config is read, with the config
+// schema versions this runner accepts
+func RunnerHello(version string, schemaVersions []int) {
+	EmitEvent(StructuredEvent{
+		Type:      "runner_hello",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"version":         version,
+			"schema_versions": schemaVersions,
+		},
+	})
+}
+
+// RunnerHeartbeat emits periodically while the runner is alive, with the phase
+// of the run it is in
+func RunnerHeartbeat(phase string, uptime time.Duration) {
+	EmitEvent(StructuredEvent{
+		Type:      "runner_heartbeat",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"phase":       phase,
+			"uptime_secs": int64(uptime.Seconds()),
+		},
+	})
+}
+
+// RunnerSelfStats emits the runner's own goroutine count and memory use, for
+// diagnosing leaks in long runs
+func RunnerSelfStats(goroutines int, heapAlloc, heapInuse, sys uint64, numGC uint32) {
+	EmitEvent(StructuredEvent{
+		Type:      "runner_self_stats",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"goroutines":       goroutines,
+			"heap_alloc_bytes": heapAlloc,
+			"heap_inuse_bytes": heapInuse,
+			"sys_bytes":        sys,
+			"num_gc":           numGC,
+		},
+	})
+}
+
+// ConfigRejected emits when the config cannot be used at all; code is a
+// stable machine-readable reason such as "unsupported_schema_version"
+func ConfigRejected(code string, errMsg string, schemaVersion int, supportedVersions []int) {
+	EmitEvent(StructuredEvent{
+		Type:      "config_rejected",
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Data: map[string]any{
+			"code":                      code,
+			"error":                     errMsg,
+			"schema_version":            schemaVersion,
+			"supported_schema_versions": supportedVersions,
+		},
+	})
+}
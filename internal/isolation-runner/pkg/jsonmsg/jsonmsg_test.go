@@ -0,0 +1,209 @@
+package jsonmsg
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestSeqIsContiguousInOutputOrder(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	saved := output
+	output = w
+	defer func() { output = saved }()
+
+	var lines [][]byte
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+		}
+	}()
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWriter {
+				switch i % 3 {
+				case 0:
+					Info("hello")
+				case 1:
+					ContainerStdout("out")
+				default:
+					EmitEvent(StructuredEvent{Type: "test_event"})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	w.Close()
+	<-done
+
+	var seqs []uint64
+	for _, line := range lines {
+		var msg struct {
+			Seq uint64 `json:"seq"`
+		}
+		if err := json.Unmarshal(line, &msg); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		seqs = append(seqs, msg.Seq)
+	}
+
+	if len(seqs) != writers*perWriter {
+		t.Fatalf("expected %d lines, got %d", writers*perWriter, len(seqs))
+	}
+	if !sort.SliceIsSorted(seqs, func(i, j int) bool { return seqs[i] < seqs[j] }) {
+		t.Error("seq is not in output order")
+	}
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i] != seqs[i-1]+1 {
+			t.Fatalf("gap between seq %d and %d", seqs[i-1], seqs[i])
+		}
+	}
+}
+
+func TestRunIDIsStamped(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	saved := output
+	output = w
+	defer func() { output = saved }()
+	SetRunID("run_test")
+	defer SetRunID("")
+
+	Info("hello")
+	ContainerStdout("out")
+	EmitEvent(StructuredEvent{Type: "test_event"})
+	w.Close()
+
+	scanner := bufio.NewScanner(r)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		var msg struct {
+			RunID string `json:"run_id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Bytes(), err)
+		}
+		if msg.RunID != "run_test" {
+			t.Errorf("expected run_id run_test in %s", scanner.Bytes())
+		}
+	}
+	if lines != 3 {
+		t.Errorf("expected 3 lines, got %d", lines)
+	}
+}
+
+func TestLevelFiltersLogMessagesOnly(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	saved := output
+	output = w
+	defer func() { output = saved }()
+	SetLevel(LevelWarning)
+	defer SetLevel(LevelInfo)
+
+	Debug("debug")
+	Info("info")
+	Warning("warning")
+	Error("error")
+	ContainerExit(1, "")
+	EmitEvent(StructuredEvent{Type: "container_started"})
+	w.Close()
+
+	var types []string
+	var seqs []uint64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var msg struct {
+			Type string `json:"type"`
+			Seq  uint64 `json:"seq"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Bytes(), err)
+		}
+		types = append(types, msg.Type)
+		seqs = append(seqs, msg.Seq)
+	}
+
+	want := []string{"warning", "error", "container_exited", "container_started"}
+	if !slices.Equal(types, want) {
+		t.Errorf("emitted %v, want %v", types, want)
+	}
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i] != seqs[i-1]+1 {
+			t.Errorf("filtered messages left a gap between seq %d and %d", seqs[i-1], seqs[i])
+		}
+	}
+}
+
+func TestLevelNoneKeepsEventsAndOutput(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	saved := output
+	output = w
+	defer func() { output = saved }()
+	SetLevel(LevelNone)
+	defer SetLevel(LevelInfo)
+
+	Info("Running on Metorial Holopod")
+	Error("failed")
+	ContainerStdout("out")
+	EmitEvent(StructuredEvent{Type: "container_started"})
+	w.Close()
+
+	var types []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var msg struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Bytes(), err)
+		}
+		types = append(types, msg.Type)
+	}
+
+	if want := []string{"container:stdout", "container_started"}; !slices.Equal(types, want) {
+		t.Errorf("emitted %v, want %v", types, want)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{"": LevelInfo, "debug": LevelDebug, "warning": LevelWarning, "error": LevelError} {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/bastion"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/container"
+	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
 	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
 )
 
@@ -20,26 +21,44 @@ func SetupContainer(ctx context.Context, input *config.ContainerInput, cfg *conf
 		return nil, err
 	}
 
-	if err := manager.CheckGVisor(ctx); err != nil {
+	if err := manager.CheckRuntime(ctx); err != nil {
 		return nil, err
 	}
 
 	bastionAddress := config.GetBastionAddress()
 	// jsonmsg.Info(fmt.Sprintf("Using bastion address: %s", bastionAddress))
-	bastionClient, err := bastion.Connect(bastionAddress, containerName)
+	controller, err := bastion.Dial(bastionAddress, containerName)
 	if err != nil {
 		jsonmsg.Warning(fmt.Sprintf("Could not connect to bastion at %s: %v. Proceeding without bastion.", bastionAddress, err))
 		return nil, fmt.Errorf("bastion connection failed: %w", err)
 	}
-	defer bastionClient.Close()
+	defer controller.Close()
 
-	if err := manager.SetupNetworkViaBastion(ctx, input.Subnet, bastionClient); err != nil {
-		return nil, err
+	// nil in standalone mode, where there is no bastion network pool
+	bastionClient, _ := controller.(*bastion.Client)
+
+	if err := manager.SetupNetworkViaBastion(ctx, input.Subnet, input.StaticIP, bastionClient); err != nil {
+		return nil, ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, err)
+	}
+
+	// Point the container's resolver at the bastion's DNS filter
+	if len(cfg.Network.DNSAllowedDomains) > 0 {
+		if bastionClient == nil {
+			_ = manager.CleanupNetwork(ctx, bastionClient)
+			return nil, ierrors.NewConfigError("DNS domain filtering is not available in standalone mode", nil)
+		}
+
+		filterIP, err := bastionClient.DNSFilterAddress()
+		if err != nil {
+			_ = manager.CleanupNetwork(ctx, bastionClient)
+			return nil, ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, err)
+		}
+		manager.SetDNSFilter(filterIP)
 	}
 
 	// Validate image spec
 	if err := config.ValidateImageSpec(input.ImageSpec); err != nil {
-		return nil, fmt.Errorf("invalid image spec: %w", err)
+		return nil, ierrors.NewConfigError("invalid image spec", err)
 	}
 
 	imageRef := input.GetFullImageReference()
@@ -75,13 +94,43 @@ func SetupContainer(ctx context.Context, input *config.ContainerInput, cfg *conf
 }
 
 func SetupNetworkIsolation(ctx context.Context, containerID string, containerIP string, cfg *config.Config) (string, error) {
+	if err := config.ValidatePorts(cfg.Container.Ports); err != nil {
+		return "", ierrors.NewConfigError("port validation failed", err)
+	}
+
+	bastionClient, chainName, err := setupChain(containerID, containerIP, cfg)
+	if err != nil {
+		return "", err
+	}
+	defer bastionClient.Close()
+
+	if len(cfg.Container.Ports) > 0 {
+		published, err := bastionClient.ExposePorts(chainName, buildPortMappings(cfg.Container.Ports))
+		if err != nil {
+			return "", ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, err)
+		}
+		for _, m := range published {
+			jsonmsg.ContainerPortReady(containerID, m.ContainerPort, m.HostPort, m.Protocol)
+		}
+	}
+
+	// jsonmsg.Info(fmt.Sprintf("Network isolation configured: chain %s created via bastion", chainName))
+	jsonmsg.NetworkIsolationReady(containerID, chainName, cfg.Network.DefaultPolicy)
+
+	return chainName, nil
+}
+
+// setupChain applies the configured network policy to the container at
+// containerIP through a chain of its own, returning the bastion connection for
+// further rules
+func setupChain(containerID string, containerIP string, cfg *config.Config) (bastion.Controller, string, error) {
 	// CRITICAL SECURITY: Validate and enforce network security rules
 	// These rules CANNOT be bypassed and include mandatory blocks for:
 	// - Localhost (127.0.0.0/8, ::1/128)
 	// - Cloud metadata services (169.254.169.254/32)
 	// - Private IPs (unless explicitly whitelisted)
 	if err := config.ValidateNetworkConfig(&cfg.Network); err != nil {
-		return "", fmt.Errorf("network security validation failed: %w", err)
+		return nil, "", ierrors.NewConfigError("network security validation failed", err)
 	}
 
 	// jsonmsg.Info("Network security rules validated and enforced (localhost, metadata, and private IPs blocked)")
@@ -91,29 +140,57 @@ func SetupNetworkIsolation(ctx context.Context, containerID string, containerIP
 
 	// jsonmsg.Info(fmt.Sprintf("Connecting to Network Bastion at %s for iptables operations", bastionAddress))
 
-	bastionClient, err := bastion.Connect(bastionAddress, containerID)
+	bastionClient, err := bastion.Dial(bastionAddress, containerID)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to Network Bastion: %w. Ensure the bastion service is running", err)
+		return nil, "", fmt.Errorf("failed to connect to Network Bastion: %w. Ensure the bastion service is running", err)
 	}
-	defer bastionClient.Close()
 
 	// jsonmsg.Info("Connected to Network Bastion - all iptables operations will be validated")
 
 	chainName := GenerateChainName(containerID)
 
 	if err := bastionClient.SetupChain(chainName, containerIP); err != nil {
-		return "", err
+		bastionClient.Close()
+		return nil, "", ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, err)
 	}
 
-	policy := buildNetworkPolicy(cfg)
+	policy := bastion.PolicyFromConfig(&cfg.Network, cfg.Logging.LogNetworkAttempts)
 	if err := bastionClient.ApplyNetworkPolicy(chainName, policy); err != nil {
-		return "", err
+		bastionClient.Close()
+		return nil, "", ierrors.Wrap(ierrors.KindNetworkSetupFailed, ierrors.ExitSetupError, err)
 	}
 
-	// jsonmsg.Info(fmt.Sprintf("Network isolation configured: chain %s created via bastion", chainName))
-	jsonmsg.NetworkIsolationReady(containerID, chainName, cfg.Network.DefaultPolicy)
+	return bastionClient, chainName, nil
+}
 
-	return chainName, nil
+// StartFlowLogStream forwards connection attempts logged for chainName as
+// network_attempt events. The returned function stops the stream and waits for it.
+func StartFlowLogStream(ctx context.Context, containerID string, chainName string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		bastionClient, err := bastion.Connect(config.GetBastionAddress(), containerID)
+		if err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Could not connect to bastion for flow logs: %v", err))
+			return
+		}
+		defer bastionClient.Close()
+
+		err = bastionClient.StreamFlowLogs(ctx, chainName, func(record *pb.FlowRecord) {
+			jsonmsg.NetworkAttempt(containerID, record.Verdict, record.Protocol, record.Src, record.Dst, record.DstPort)
+		})
+		if err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Network attempt logging unavailable: %v", err))
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
 func CleanupNetworkIsolation(ctx context.Context, chainName string) {
@@ -121,7 +198,7 @@ func CleanupNetworkIsolation(ctx context.Context, chainName string) {
 
 	bastionAddress := config.GetBastionAddress()
 
-	bastionClient, err := bastion.Connect(bastionAddress, "cleanup")
+	bastionClient, err := bastion.Dial(bastionAddress, "cleanup")
 	if err != nil {
 		jsonmsg.Warning(fmt.Sprintf("Could not connect to bastion for cleanup: %v", err))
 		return
@@ -135,6 +212,21 @@ func CleanupNetworkIsolation(ctx context.Context, chainName string) {
 	}
 }
 
+// ReleaseNetwork returns a pool network to the bastion once the container is gone.
+// containerName must be the name the network was acquired under.
+func ReleaseNetwork(ctx context.Context, containerName string, networkName string) {
+	bastionClient, err := bastion.Connect(config.GetBastionAddress(), containerName)
+	if err != nil {
+		jsonmsg.Warning(fmt.Sprintf("Could not connect to bastion for network release: %v", err))
+		return
+	}
+	defer bastionClient.Close()
+
+	if err := bastionClient.ReleaseNetwork(networkName, false); err != nil {
+		jsonmsg.Warning(fmt.Sprintf("Failed to release network via bastion: %v", err))
+	}
+}
+
 func GenerateChainName(containerID string) string {
 	hexPart := ""
 	for _, ch := range containerID {
@@ -148,38 +240,14 @@ func GenerateChainName(containerID string) string {
 	return fmt.Sprintf("ISO-%s", hexPart)
 }
 
-func buildNetworkPolicy(cfg *config.Config) *pb.NetworkPolicy {
-	policy := &pb.NetworkPolicy{
-		Policy:        cfg.Network.DefaultPolicy,
-		BlockMetadata: cfg.Network.BlockMetadata,
-		AllowDns:      cfg.Network.AllowDNS,
-		DnsServers:    cfg.Network.DNSServers,
-		Whitelist:     make([]*pb.NetworkRule, 0),
-		Blacklist:     make([]*pb.NetworkRule, 0),
-	}
-
-	for _, entry := range cfg.Network.Whitelist {
-		ports := make([]uint32, 0, len(entry.Ports))
-		for _, p := range entry.Ports {
-			var port uint32
-			fmt.Sscanf(p, "%d", &port)
-			ports = append(ports, port)
-		}
-
-		policy.Whitelist = append(policy.Whitelist, &pb.NetworkRule{
-			Cidr:        entry.CIDR,
-			Description: &entry.Description,
-			Ports:       ports,
-		})
-	}
-
-	for _, entry := range cfg.Network.Blacklist {
-		policy.Blacklist = append(policy.Blacklist, &pb.NetworkRule{
-			Cidr:        entry.CIDR,
-			Description: &entry.Description,
-			Ports:       []uint32{},
+func buildPortMappings(ports []config.PortMapping) []*pb.PortMapping {
+	mappings := make([]*pb.PortMapping, 0, len(ports))
+	for _, p := range ports {
+		mappings = append(mappings, &pb.PortMapping{
+			ContainerPort: p.ContainerPort,
+			HostPort:      p.HostPort,
+			Protocol:      p.Protocol,
 		})
 	}
-
-	return policy
+	return mappings
 }
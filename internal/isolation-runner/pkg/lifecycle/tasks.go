@@ -0,0 +1,123 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/config"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/container"
+	ierrors "github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/errors"
+	"github.com/metorial/fleet/holopod/internal/isolation-runner/pkg/jsonmsg"
+)
+
+// RunInitContainers runs the configured init containers one after another
+// before the container starts. The first that does not exit 0 stops the rest
+// and fails the container.
+func RunInitContainers(ctx context.Context, manager *container.Manager, cfg *config.Config, tracker *ResourceTracker) error {
+	for i := range cfg.Container.InitContainers {
+		task, err := manager.CreateInitContainer(ctx, i)
+		if err != nil {
+			return ierrors.Wrap(ierrors.KindInitContainerFailed, ierrors.ExitSetupError, err)
+		}
+
+		jsonmsg.Info(fmt.Sprintf("Running init container %s", task.Name))
+		exitCode, err := runTask(ctx, manager, cfg, tracker, task)
+		if err != nil {
+			return ierrors.Wrap(ierrors.KindInitContainerFailed, ierrors.ExitSetupError, err)
+		}
+		if exitCode != 0 {
+			err := ierrors.NewContainerFailedError(int(ierrors.ExitContainerFailed),
+				fmt.Sprintf("init container '%s' exited with code %d", task.Name, exitCode))
+			err.Kind = ierrors.KindInitContainerFailed
+			return err
+		}
+	}
+	return nil
+}
+
+// RunPostExitHooks runs the configured hooks one after another once the
+// workload has exited with exitCode, each within its own timeout. Failures are
+// reported as events only.
+func RunPostExitHooks(ctx context.Context, manager *container.Manager, cfg *config.Config, tracker *ResourceTracker, exitCode int, duration time.Duration) {
+	for i := range cfg.Container.PostExitHooks {
+		hook := &cfg.Container.PostExitHooks[i]
+
+		kind := "webhook"
+		if hook.Container != nil {
+			kind = "container"
+		}
+		jsonmsg.HookStarted(manager.ContainerID(), hook.Name, kind)
+
+		start := time.Now()
+		hookExitCode, err := runHook(ctx, manager, cfg, tracker, hook, exitCode, duration)
+
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		} else if hookExitCode != 0 {
+			errMsg = fmt.Sprintf("hook exited with code %d", hookExitCode)
+		}
+		jsonmsg.HookCompleted(manager.ContainerID(), hook.Name, hookExitCode, errMsg, time.Since(start).String())
+	}
+}
+
+func runHook(ctx context.Context, manager *container.Manager, cfg *config.Config, tracker *ResourceTracker, hook *config.HookConfig, exitCode int, duration time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout())
+	defer cancel()
+
+	if hook.Container == nil {
+		if err := manager.CallWebhook(ctx, hook, exitCode, duration); err != nil {
+			return 1, err
+		}
+		return 0, nil
+	}
+
+	task, err := manager.CreateHookContainer(ctx, hook)
+	if err != nil {
+		return -1, err
+	}
+
+	hookExitCode, err := runTask(ctx, manager, cfg, tracker, task)
+	if ctx.Err() == context.DeadlineExceeded {
+		return -1, fmt.Errorf("hook timed out after %s", hook.Timeout())
+	}
+	return hookExitCode, err
+}
+
+// runTask runs a created task container to completion on the container's
+// network under its network policy, removing it and its rules afterwards
+func runTask(ctx context.Context, manager *container.Manager, cfg *config.Config, tracker *ResourceTracker, task *container.TaskContainer) (int, error) {
+	tracker.TrackTaskContainer(task.ContainerID)
+
+	var chainName string
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		manager.RemoveTaskContainer(cleanupCtx, task)
+		tracker.UntrackTaskContainer()
+		if chainName != "" {
+			CleanupNetworkIsolation(cleanupCtx, chainName)
+			tracker.UntrackTaskChain()
+		}
+	}()
+
+	ip, err := manager.StartTaskContainer(ctx, task)
+	if err != nil {
+		return -1, err
+	}
+
+	// A task that exits before getting an address needs no rules
+	if ip != nil {
+		bastionClient, chain, err := setupChain(task.ContainerID, ip.String(), cfg)
+		if err != nil {
+			return -1, fmt.Errorf("failed to isolate %s: %w", task.Name, err)
+		}
+		bastionClient.Close()
+		chainName = chain
+		tracker.TrackTaskChain(chainName)
+	}
+
+	return manager.WaitTaskContainer(ctx, task)
+}
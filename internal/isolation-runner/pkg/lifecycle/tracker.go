@@ -2,7 +2,13 @@ package lifecycle
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
@@ -14,17 +20,26 @@ import (
 )
 
 type ResourceTracker struct {
-	docker    *client.Client
-	mu        sync.Mutex
-	resources trackedResources
+	docker     *client.Client
+	mu         sync.Mutex
+	resources  trackedResources
+	recordPath string
+	recordErr  bool
 }
 
+// trackedResources is also the on-disk record, so its fields are exported for
+// encoding/json
 type trackedResources struct {
-	containerID       string
-	containerName     string
-	networkName       string
-	networkViaBastion bool
-	chainName         string
+	PID               int      `json:"pid"`
+	ContainerID       string   `json:"container_id,omitempty"`
+	ContainerName     string   `json:"container_name,omitempty"`
+	NetworkName       string   `json:"network_name,omitempty"`
+	NetworkViaBastion bool     `json:"network_via_bastion,omitempty"`
+	ChainName         string   `json:"chain_name,omitempty"`
+	WorkspaceVolume   string   `json:"workspace_volume,omitempty"`
+	SidecarIDs        []string `json:"sidecar_ids,omitempty"`
+	TaskContainerID   string   `json:"task_container_id,omitempty"`
+	TaskChainName     string   `json:"task_chain_name,omitempty"`
 }
 
 func NewResourceTracker(docker *client.Client) *ResourceTracker {
@@ -33,43 +48,116 @@ func NewResourceTracker(docker *client.Client) *ResourceTracker {
 	}
 }
 
+// PersistTo keeps a record of the tracked resources at path, rewritten on
+// every change and removed once nothing is tracked, so RecoverFromRecords can
+// finish the cleanup if the runner is killed before it could
+func (t *ResourceTracker) PersistTo(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recordPath = path
+	t.persistLocked()
+}
+
 func (t *ResourceTracker) TrackContainer(containerID, containerName string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.resources.containerID = containerID
-	t.resources.containerName = containerName
+	t.resources.ContainerID = containerID
+	t.resources.ContainerName = containerName
+	t.persistLocked()
 }
 
 func (t *ResourceTracker) TrackNetwork(networkName string, viaBastion bool) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.resources.networkName = networkName
-	t.resources.networkViaBastion = viaBastion
+	t.resources.NetworkName = networkName
+	t.resources.NetworkViaBastion = viaBastion
+	t.persistLocked()
 }
 
 func (t *ResourceTracker) TrackChain(chainName string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.resources.chainName = chainName
+	t.resources.ChainName = chainName
+	t.persistLocked()
+}
+
+func (t *ResourceTracker) TrackWorkspaceVolume(volumeName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resources.WorkspaceVolume = volumeName
+	t.persistLocked()
+}
+
+func (t *ResourceTracker) TrackSidecars(sidecarIDs []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resources.SidecarIDs = sidecarIDs
+	t.persistLocked()
+}
+
+// TrackTaskContainer tracks the init container or hook container running now
+func (t *ResourceTracker) TrackTaskContainer(containerID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resources.TaskContainerID = containerID
+	t.persistLocked()
+}
+
+func (t *ResourceTracker) TrackTaskChain(chainName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resources.TaskChainName = chainName
+	t.persistLocked()
 }
 
 func (t *ResourceTracker) UntrackContainer() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.resources.containerID = ""
-	t.resources.containerName = ""
+	t.resources.ContainerID = ""
+	t.resources.ContainerName = ""
+	t.persistLocked()
+}
+
+func (t *ResourceTracker) UntrackSidecars() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resources.SidecarIDs = nil
+	t.persistLocked()
+}
+
+func (t *ResourceTracker) UntrackTaskContainer() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resources.TaskContainerID = ""
+	t.persistLocked()
+}
+
+func (t *ResourceTracker) UntrackTaskChain() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resources.TaskChainName = ""
+	t.persistLocked()
 }
 
 func (t *ResourceTracker) UntrackNetwork() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.resources.networkName = ""
+	t.resources.NetworkName = ""
+	t.persistLocked()
 }
 
 func (t *ResourceTracker) UntrackChain() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.resources.chainName = ""
+	t.resources.ChainName = ""
+	t.persistLocked()
+}
+
+func (t *ResourceTracker) UntrackWorkspaceVolume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resources.WorkspaceVolume = ""
+	t.persistLocked()
 }
 
 func (t *ResourceTracker) CleanupAll(ctx context.Context) {
@@ -77,16 +165,33 @@ func (t *ResourceTracker) CleanupAll(ctx context.Context) {
 	resources := t.resources
 	t.mu.Unlock()
 
-	if resources.containerID != "" {
-		t.cleanupContainer(ctx, resources.containerID)
+	if resources.TaskContainerID != "" {
+		t.cleanupContainer(ctx, resources.TaskContainerID)
+	}
+	if resources.TaskChainName != "" {
+		t.cleanupChain(ctx, resources.TaskChainName)
 	}
 
-	if resources.networkName != "" {
-		t.cleanupNetwork(ctx, resources.networkName, resources.networkViaBastion, resources.containerName)
+	// Sidecars live in the container's network namespace, so go first
+	for _, sidecarID := range resources.SidecarIDs {
+		t.cleanupContainer(ctx, sidecarID)
 	}
 
-	if resources.chainName != "" {
-		t.cleanupChain(ctx, resources.chainName)
+	if resources.ContainerID != "" {
+		t.cleanupContainer(ctx, resources.ContainerID)
+	}
+
+	// The volume can only go once the container using it has
+	if resources.WorkspaceVolume != "" {
+		_ = t.docker.VolumeRemove(ctx, resources.WorkspaceVolume, true)
+	}
+
+	if resources.NetworkName != "" {
+		t.cleanupNetwork(ctx, resources.NetworkName, resources.NetworkViaBastion, resources.ContainerName)
+	}
+
+	if resources.ChainName != "" {
+		t.cleanupChain(ctx, resources.ChainName)
 	}
 }
 
@@ -135,7 +240,7 @@ func (t *ResourceTracker) cleanupChain(ctx context.Context, chainName string) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	bastionClient, err := bastion.Connect(bastionAddress, "cleanup")
+	bastionClient, err := bastion.Dial(bastionAddress, "cleanup")
 	if err != nil {
 		jsonmsg.Warning("Could not connect to bastion for chain cleanup: " + err.Error())
 		return
@@ -146,3 +251,94 @@ func (t *ResourceTracker) cleanupChain(ctx context.Context, chainName string) {
 		jsonmsg.Warning("Failed to cleanup chain via bastion: " + err.Error())
 	}
 }
+
+// persistLocked writes the record, or removes it when nothing is tracked; must
+// be called with t.mu held. Only the first failure is reported: the record is
+// a fallback and must not get in the way of the run.
+func (t *ResourceTracker) persistLocked() {
+	if t.recordPath == "" {
+		return
+	}
+
+	var err error
+	if t.resources.empty() {
+		if err = os.Remove(t.recordPath); os.IsNotExist(err) {
+			err = nil
+		}
+	} else {
+		t.resources.PID = os.Getpid()
+		err = writeRecord(t.recordPath, &t.resources)
+	}
+
+	if err != nil && !t.recordErr {
+		t.recordErr = true
+		jsonmsg.Warning(fmt.Sprintf("Failed to persist resource record: %v", err))
+	}
+}
+
+func (r *trackedResources) empty() bool {
+	return r.ContainerID == "" && r.NetworkName == "" && r.ChainName == "" && r.WorkspaceVolume == "" &&
+		len(r.SidecarIDs) == 0 && r.TaskContainerID == "" && r.TaskChainName == ""
+}
+
+// writeRecord replaces the record at path atomically, so a crash mid-write
+// leaves the previous one
+func writeRecord(path string, resources *trackedResources) error {
+	data, err := json.Marshal(resources)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RecoverFromRecords cleans up after runners that died with resources still
+// recorded in dir, removing each record once its cleanup has run. Records of
+// runners that are still alive are left alone. It returns the number of
+// records recovered.
+func RecoverFromRecords(ctx context.Context, docker *client.Client, dir string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var resources trackedResources
+		if err := json.Unmarshal(data, &resources); err != nil {
+			jsonmsg.Warning(fmt.Sprintf("Removing unreadable resource record %s: %v", path, err))
+			_ = os.Remove(path)
+			continue
+		}
+		if processAlive(resources.PID) {
+			continue
+		}
+
+		tracker := &ResourceTracker{docker: docker, resources: resources}
+		tracker.CleanupAll(ctx)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return recovered, err
+		}
+		recovered++
+	}
+	return recovered, nil
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,73 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrackerRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "hpod-test.json")
+	tracker := NewResourceTracker(nil)
+	tracker.PersistTo(path)
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no record while nothing is tracked, got %v", err)
+	}
+
+	tracker.TrackNetwork("iso-br-test", true)
+	tracker.TrackContainer("abc123", "hpod-test")
+	tracker.TrackChain("HOLOPOD-test")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record trackedResources
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.ContainerID != "abc123" || record.NetworkName != "iso-br-test" || !record.NetworkViaBastion ||
+		record.ChainName != "HOLOPOD-test" || record.PID != os.Getpid() {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	tracker.UntrackChain()
+	tracker.UntrackContainer()
+	tracker.UntrackNetwork()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the record to be removed once everything is untracked, got %v", err)
+	}
+}
+
+func TestRecoverFromRecords(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, record trackedResources) string {
+		path := filepath.Join(dir, name)
+		if err := writeRecord(path, &record); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// Nothing left to clean up, so no docker client is needed
+	dead := write("dead.json", trackedResources{PID: 1 << 30})
+	alive := write("alive.json", trackedResources{PID: os.Getpid(), ContainerID: "abc123"})
+	garbage := filepath.Join(dir, "garbage.json")
+	os.WriteFile(garbage, []byte("{"), 0o600)
+
+	recovered, err := RecoverFromRecords(context.Background(), nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != 1 {
+		t.Errorf("expected 1 record recovered, got %d", recovered)
+	}
+	for path, wantExists := range map[string]bool{dead: false, alive: true, garbage: false} {
+		if _, err := os.Stat(path); (err == nil) != wantExists {
+			t.Errorf("%s: expected exists=%v, got %v", filepath.Base(path), wantExists, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "events.log")
+	f, err := Open(path, 20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Each line is 10 bytes, so every file holds two
+	for _, line := range []string{"aaaaaaaaa\n", "bbbbbbbbb\n", "ccccccccc\n", "ddddddddd\n", "eeeeeeeee\n", "fffffffff\n", "ggggggggg\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "ggggggggg\n",
+		path + ".1": "eeeeeeeee\nfffffffff\n",
+		path + ".2": "ccccccccc\nddddddddd\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), data, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept, got %v", err)
+	}
+}
+
+func TestOversizedWriteIsKeptWhole(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	f, err := Open(path, 8, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	long := strings.Repeat("x", 20) + "\n"
+	for _, line := range []string{"short\n", long} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != long {
+		t.Errorf("expected only the oversized line after rotation, got %q", data)
+	}
+}
+
+func TestReopenAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	for _, line := range []string{"one\n", "two\n"} {
+		f, err := Open(path, 1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(line))
+		f.Close()
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "one\ntwo\n" {
+		t.Errorf("expected the second run to append, got %q", data)
+	}
+}
@@ -0,0 +1,126 @@
+// Package stdio carries the workload's stdin, stdout and stderr between the
+// container-manager and the isolation-runner as length-prefixed binary frames.
+// The frames travel on their own pair of file descriptors, apart from the
+// newline-delimited JSON control channel, so any byte sequence and any line
+// length passes through unmodified.
+//
+// Each frame is a one byte stream ID, a four byte big-endian payload length and
+// the payload. An empty stdin frame closes the workload's stdin.
+package stdio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Stream IDs match Docker's multiplexed stream numbering
+const (
+	StreamStdin  byte = 0
+	StreamStdout byte = 1
+	StreamStderr byte = 2
+)
+
+// File descriptors the container-manager passes to the isolation-runner when
+// binary stdio is enabled: frames are written to OutputFD and read from InputFD
+const (
+	OutputFD = 3
+	InputFD  = 4
+)
+
+// MaxFrameSize bounds a frame's payload; longer writes are split
+const MaxFrameSize = 1 << 20
+
+const headerSize = 5
+
+// OpenOutput returns the descriptor output frames are written to
+func OpenOutput() *os.File {
+	return os.NewFile(OutputFD, "stdio-output")
+}
+
+// OpenInput returns the descriptor input frames are read from
+func OpenInput() *os.File {
+	return os.NewFile(InputFD, "stdio-input")
+}
+
+// Writer writes frames to an underlying writer. It is safe for concurrent use.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteFrame writes data as frames of stream, splitting it at MaxFrameSize.
+// Empty data writes a single empty frame.
+func (w *Writer) WriteFrame(stream byte, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for {
+		n := min(len(data), MaxFrameSize)
+
+		var header [headerSize]byte
+		header[0] = stream
+		binary.BigEndian.PutUint32(header[1:], uint32(n))
+		if _, err := w.w.Write(header[:]); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(data[:n]); err != nil {
+			return err
+		}
+
+		data = data[n:]
+		if len(data) == 0 {
+			return nil
+		}
+	}
+}
+
+// Stream returns an io.Writer that writes each non-empty write as frames of stream
+func (w *Writer) Stream(stream byte) io.Writer {
+	return streamWriter{w: w, stream: stream}
+}
+
+type streamWriter struct {
+	w      *Writer
+	stream byte
+}
+
+func (s streamWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := s.w.WriteFrame(s.stream, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ReadFrame reads the next frame from r. It returns io.EOF only when r ends
+// cleanly between frames.
+func ReadFrame(r io.Reader) (byte, []byte, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, nil, fmt.Errorf("truncated frame header")
+		}
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxFrameSize {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds %d", size, MaxFrameSize)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, fmt.Errorf("truncated frame: %w", err)
+	}
+
+	return header[0], data, nil
+}
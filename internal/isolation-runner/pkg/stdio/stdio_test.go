@@ -0,0 +1,63 @@
+package stdio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	binaryData := []byte{0x00, 0xff, 0xfe, '\n', 0x80, 0x00}
+	longLine := bytes.Repeat([]byte("x"), MaxFrameSize+10)
+
+	if _, err := w.Stream(StreamStdout).Write(binaryData); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := w.Stream(StreamStderr).Write(longLine); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := w.WriteFrame(StreamStdin, nil); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	stream, data, err := ReadFrame(&buf)
+	if err != nil || stream != StreamStdout || !bytes.Equal(data, binaryData) {
+		t.Fatalf("expected binary stdout frame, got stream %d data %v err %v", stream, data, err)
+	}
+
+	var stderr []byte
+	for range 2 {
+		stream, data, err = ReadFrame(&buf)
+		if err != nil || stream != StreamStderr {
+			t.Fatalf("expected stderr frame, got stream %d err %v", stream, err)
+		}
+		stderr = append(stderr, data...)
+	}
+	if !bytes.Equal(stderr, longLine) {
+		t.Errorf("long write was not reassembled: got %d bytes", len(stderr))
+	}
+
+	stream, data, err = ReadFrame(&buf)
+	if err != nil || stream != StreamStdin || len(data) != 0 {
+		t.Fatalf("expected empty stdin frame, got stream %d data %v err %v", stream, data, err)
+	}
+
+	if _, _, err := ReadFrame(&buf); err != io.EOF {
+		t.Errorf("expected io.EOF at end of input, got %v", err)
+	}
+}
+
+func TestReadFrameRejectsBadInput(t *testing.T) {
+	if _, _, err := ReadFrame(bytes.NewReader([]byte{StreamStdout, 0, 0})); err == nil || err == io.EOF {
+		t.Errorf("expected truncated header error, got %v", err)
+	}
+	if _, _, err := ReadFrame(bytes.NewReader([]byte{StreamStdout, 0, 0, 0, 4, 'a'})); err == nil {
+		t.Error("expected truncated payload error")
+	}
+	if _, _, err := ReadFrame(bytes.NewReader([]byte{StreamStdout, 0xff, 0xff, 0xff, 0xff})); err == nil {
+		t.Error("expected oversized frame error")
+	}
+}
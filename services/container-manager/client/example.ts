@@ -58,7 +58,8 @@ stream.write({
 // });
 
 // CRITICAL: Start heartbeat immediately after create
-// Client MUST send heartbeat every 30 seconds or container will be terminated
+// Client MUST send heartbeat within the heartbeat timeout (30 seconds unless
+// heartbeat_timeout_secs says otherwise; see created) or container will be terminated
 const heartbeatInterval = setInterval(() => {
   try {
     stream.write({ heartbeat: true });
@@ -9,6 +9,7 @@ import (
 	"syscall"
 
 	"github.com/metorial/fleet/holopod/services/container-manager/pkg/api"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/spool"
 )
 
 const version = "1.0.0"
@@ -28,16 +29,25 @@ func main() {
 	log.Printf("Listen address: %s", listenAddr)
 	log.Printf("gRPC address: %s", grpcAddr)
 
+	outputBuffering, err := spool.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid output buffering: %v", err)
+	}
+
 	server, err := api.NewServer(grpcAddr)
 	if err != nil {
 		log.Fatalf("Failed to create API server: %v", err)
 	}
+	server.SetOutputBuffering(outputBuffering)
 
 	mux := http.NewServeMux()
 
 	// Health check
 	mux.HandleFunc("/api/health", server.HandleHealth)
 
+	// Cached stream metrics
+	mux.HandleFunc("/api/metrics", server.HandleMetrics)
+
 	// Container operations
 	mux.HandleFunc("/api/containers", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
@@ -82,6 +92,9 @@ func main() {
 			case "logs":
 				// GET /api/containers/{id}/logs - Get container logs
 				server.HandleGetLogs(w, r, containerID)
+			case "exec":
+				// POST /api/containers/{id}/exec - Run a process in the container
+				server.HandleExec(w, r, containerID)
 			case "stdio":
 				// WebSocket /api/containers/{id}/stdio - Interactive I/O
 				server.HandleWebSocket(w, r, containerID)
@@ -94,6 +107,9 @@ func main() {
 		http.NotFound(w, r)
 	})
 
+	// GET /api/events - Server-sent events of every container matching a filter
+	mux.HandleFunc("/api/events", server.HandleWatchEvents)
+
 	// WebSocket endpoint for creating new containers with I/O streaming
 	mux.HandleFunc("/api/run", server.HandleWebSocketRun)
 
@@ -114,6 +130,10 @@ func main() {
 	go func() {
 		<-sigChan
 		log.Println("Received shutdown signal, stopping...")
+		// Cancel cached streams before the listener so main cannot exit first
+		if err := server.Close(); err != nil {
+			log.Printf("Failed to close API server: %v", err)
+		}
 		httpServer.Close()
 	}()
 
@@ -12,6 +12,7 @@ import (
 	"github.com/metorial/fleet/holopod/services/container-manager/pkg/manager"
 	"github.com/metorial/fleet/holopod/services/container-manager/pkg/publicapi"
 	"github.com/metorial/fleet/holopod/services/container-manager/pkg/service"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/shim"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
@@ -21,6 +22,11 @@ import (
 var version = "dev"
 
 func main() {
+	// Runner shims are this binary re-executed
+	if len(os.Args) > 1 && os.Args[1] == shim.Command {
+		os.Exit(shim.Main(os.Args[2:]))
+	}
+
 	listenAddr := os.Getenv("LISTEN_ADDRESS")
 	if listenAddr == "" {
 		listenAddr = "0.0.0.0:50051"
@@ -66,6 +72,15 @@ func main() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/v1/health", publicServer.HandleHealth)
 	mux.HandleFunc("/v1/run", publicServer.HandleRun)
+	mux.HandleFunc("/v1/schema/run-request", publicServer.HandleRunRequestSchema)
+	mux.HandleFunc("/v1/containers/{id}/files", publicServer.HandleFiles)
+	mux.HandleFunc("/v1/containers/{id}/pause", publicServer.HandlePause)
+	mux.HandleFunc("/v1/containers/{id}/unpause", publicServer.HandleUnpause)
+	mux.HandleFunc("/v1/containers/{id}/restart", publicServer.HandleRestart)
+	mux.HandleFunc("/v1/containers/{id}/resources", publicServer.HandleUpdateResources)
+	mux.HandleFunc("/v1/containers/{id}/timeout", publicServer.HandleExtendTimeout)
+	mux.HandleFunc("/v1/containers/{id}/checkpoint", publicServer.HandleCheckpoint)
+	mux.HandleFunc("/v1/containers/{id}/ready", publicServer.HandleWaitReady)
 	httpServer := &http.Server{
 		Addr:    httpListenAddr,
 		Handler: mux,
@@ -3,45 +3,93 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/spool"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
 // containerStream manages a persistent Run() stream for a container
 type containerStream struct {
-	containerID      string
-	stream           pb.ContainerManager_RunClient
-	cancel           context.CancelFunc
-	stdout           []string
-	stderr           []string
-	messages         []string
-	exitCode         *int32
-	exitCh           chan int32
-	stdoutBroadcast  chan string
-	stderrBroadcast  chan string
-	messageBroadcast chan string
-	mu               sync.RWMutex
+	containerID  string
+	ctx          context.Context
+	stream       pb.ContainerManager_RunClient
+	cancel       context.CancelFunc
+	exitCode     *int32
+	exitCh       chan int32
+	subscribers  map[*spool.Buffer]struct{} // One per attached WebSocket
+	outputClosed bool
+	execs        map[string]*execResult
+	tty          bool
+	mu           sync.RWMutex
+}
+
+// maxExecOutputBytes bounds the stdout and stderr collected for one exec
+const maxExecOutputBytes = 1 << 20
+
+// execResult collects the output of an exec until it exits; done is closed
+// once exitCode and err are final
+type execResult struct {
+	stdout    []byte
+	stderr    []byte
+	truncated bool
+	exitCode  int32
+	err       *string
+	done      chan struct{}
+}
+
+// appendOutput appends data to buf without growing it past maxExecOutputBytes
+func (e *execResult) appendOutput(buf []byte, data []byte) []byte {
+	if room := maxExecOutputBytes - len(buf); len(data) > room {
+		data = data[:max(room, 0)]
+		e.truncated = true
+	}
+	return append(buf, data...)
 }
 
 type Server struct {
 	grpcAddr string
+	conn     *grpc.ClientConn
 	client   pb.ContainerManagerClient
 	upgrader websocket.Upgrader
 
+	// Bounds the output buffered for each attached WebSocket
+	outputBuffering spool.Config
+
+	// Root context for all Run streams; cancelled by Close
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	// Connection management
 	streams   map[string]*containerStream
 	streamsMu sync.RWMutex
+	streamsWg sync.WaitGroup
+	closed    bool
+
+	streamsOpened atomic.Int64
+	streamsClosed atomic.Int64
 }
 
+// NewServer connects to the container-manager gRPC API
 func NewServer(grpcAddr string) (*Server, error) {
 	conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -50,9 +98,14 @@ func NewServer(grpcAddr string) (*Server, error) {
 
 	client := pb.NewContainerManagerClient(conn)
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Server{
 		grpcAddr: grpcAddr,
+		conn:     conn,
 		client:   client,
+		ctx:      ctx,
+		cancel:   cancel,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
@@ -62,6 +115,35 @@ func NewServer(grpcAddr string) (*Server, error) {
 	}, nil
 }
 
+// Close cancels every cached stream, waits for their goroutines to exit and
+// closes the gRPC connection. Handlers reject new containers afterwards.
+func (s *Server) Close() error {
+	s.streamsMu.Lock()
+	if s.closed {
+		s.streamsMu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for _, cs := range s.streams {
+		cs.cancel()
+	}
+	s.streamsMu.Unlock()
+
+	s.cancel()
+	s.streamsWg.Wait()
+
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// SetOutputBuffering sets the memory and disk caps of the output buffered for
+// each WebSocket attached to a container. It must be called before serving.
+func (s *Server) SetOutputBuffering(config spool.Config) {
+	s.outputBuffering = config
+}
+
 type Response struct {
 	Success     bool    `json:"success"`
 	ContainerID *string `json:"container_id,omitempty"`
@@ -78,6 +160,7 @@ type CreateContainerRequest struct {
 	MemoryLimit *string           `json:"memory_limit,omitempty"`
 	TimeoutSecs *uint32           `json:"timeout_secs,omitempty"`
 	Cleanup     *bool             `json:"cleanup,omitempty"`
+	TTY         *bool             `json:"tty,omitempty"`
 }
 
 // HandleCreateContainer creates a container and maintains the stream connection
@@ -105,7 +188,7 @@ func (s *Server) HandleCreateContainer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Open Run stream
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(s.ctx)
 	stream, err := s.client.Run(ctx)
 	if err != nil {
 		cancel()
@@ -139,6 +222,7 @@ func (s *Server) HandleCreateContainer(w http.ResponseWriter, r *http.Request) {
 					},
 					TimeoutSecs: req.TimeoutSecs,
 					Cleanup:     &cleanup,
+					Tty:         req.TTY,
 				},
 			},
 		},
@@ -157,6 +241,8 @@ func (s *Server) HandleCreateContainer(w http.ResponseWriter, r *http.Request) {
 	resp, err := stream.Recv()
 	if err != nil {
 		cancel()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(httpstatus.FromError(err))
 		json.NewEncoder(w).Encode(Response{
 			Success: false,
 			Error:   proto.String(fmt.Sprintf("failed to receive created event: %v", err)),
@@ -178,19 +264,30 @@ func (s *Server) HandleCreateContainer(w http.ResponseWriter, r *http.Request) {
 
 	// Create stream manager
 	cs := &containerStream{
-		containerID:      containerID,
-		stream:           stream,
-		cancel:           cancel,
-		exitCh:           make(chan int32, 1),
-		stdoutBroadcast:  make(chan string, 100),
-		stderrBroadcast:  make(chan string, 100),
-		messageBroadcast: make(chan string, 100),
+		containerID: containerID,
+		stream:      stream,
+		cancel:      cancel,
+		ctx:         ctx,
+		exitCh:      make(chan int32, 1),
+		subscribers: make(map[*spool.Buffer]struct{}),
+		tty:         req.TTY != nil && *req.TTY,
 	}
 
 	// Store stream
 	s.streamsMu.Lock()
+	if s.closed {
+		s.streamsMu.Unlock()
+		cancel()
+		json.NewEncoder(w).Encode(Response{
+			Success: false,
+			Error:   proto.String("server is shutting down"),
+		})
+		return
+	}
 	s.streams[containerID] = cs
+	s.streamsWg.Add(1)
 	s.streamsMu.Unlock()
+	s.streamsOpened.Add(1)
 
 	// Start background goroutine to manage stream
 	go s.manageStream(cs)
@@ -207,12 +304,23 @@ func (s *Server) HandleCreateContainer(w http.ResponseWriter, r *http.Request) {
 func (s *Server) manageStream(cs *containerStream) {
 	defer func() {
 		cs.cancel()
-		close(cs.stdoutBroadcast)
-		close(cs.stderrBroadcast)
-		close(cs.messageBroadcast)
+		cs.mu.Lock()
+		cs.outputClosed = true
+		for sub := range cs.subscribers {
+			sub.Close()
+		}
+		for id, result := range cs.execs {
+			result.exitCode = -1
+			result.err = proto.String("container stream closed")
+			close(result.done)
+			delete(cs.execs, id)
+		}
+		cs.mu.Unlock()
 		s.streamsMu.Lock()
 		delete(s.streams, cs.containerID)
 		s.streamsMu.Unlock()
+		s.streamsClosed.Add(1)
+		s.streamsWg.Done()
 	}()
 
 	for {
@@ -224,30 +332,30 @@ func (s *Server) manageStream(cs *containerStream) {
 			return
 		}
 
+		// Output for attached WebSockets, passed on once the lock is released
+		var output *spool.Entry
+		var subscribers []*spool.Buffer
+
 		cs.mu.Lock()
 		switch event := resp.Event.(type) {
 		case *pb.RunResponse_Stdout:
-			data := string(event.Stdout)
-			cs.stdout = append(cs.stdout, data)
-			// Broadcast to subscribers
-			select {
-			case cs.stdoutBroadcast <- data:
-			default:
-			}
+			output = &spool.Entry{Kind: byte(pb.OutputStream_STDOUT), Data: event.Stdout}
 		case *pb.RunResponse_Stderr:
-			data := string(event.Stderr)
-			cs.stderr = append(cs.stderr, data)
-			// Broadcast to subscribers
-			select {
-			case cs.stderrBroadcast <- data:
-			default:
-			}
+			output = &spool.Entry{Kind: byte(pb.OutputStream_STDERR), Data: event.Stderr}
 		case *pb.RunResponse_Message:
-			cs.messages = append(cs.messages, event.Message)
-			// Broadcast to subscribers
-			select {
-			case cs.messageBroadcast <- event.Message:
-			default:
+			output = &spool.Entry{Kind: byte(pb.OutputStream_EVENTS), Data: []byte(event.Message)}
+		case *pb.RunResponse_Exec:
+			result, ok := cs.execs[event.Exec.ExecId]
+			if !ok {
+				break
+			}
+			result.stdout = result.appendOutput(result.stdout, event.Exec.Stdout)
+			result.stderr = result.appendOutput(result.stderr, event.Exec.Stderr)
+			if event.Exec.Exited {
+				result.exitCode = event.Exec.ExitCode
+				result.err = event.Exec.Error
+				close(result.done)
+				delete(cs.execs, event.Exec.ExecId)
 			}
 		case *pb.RunResponse_Exit:
 			cs.exitCode = &event.Exit.ExitCode
@@ -258,10 +366,46 @@ func (s *Server) manageStream(cs *containerStream) {
 			cs.mu.Unlock()
 			return
 		}
+		if output != nil {
+			subscribers = slices.Collect(maps.Keys(cs.subscribers))
+		}
 		cs.mu.Unlock()
+
+		// A WebSocket that falls behind holds up this stream, and with it the
+		// container's output, rather than missing any of it; one that has
+		// gone away no longer takes writes
+		for _, sub := range subscribers {
+			if err := sub.Write(cs.ctx, *output); errors.Is(err, context.Canceled) {
+				return
+			}
+		}
 	}
 }
 
+// subscribe returns a buffer receiving the stream's stdout, stderr and
+// message output from now on; it is closed once the stream ends
+func (cs *containerStream) subscribe(config spool.Config) *spool.Buffer {
+	sub := spool.New(config)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.outputClosed {
+		sub.Close()
+	} else {
+		cs.subscribers[sub] = struct{}{}
+	}
+	return sub
+}
+
+// unsubscribe stops a subscriber's output and drops what it has buffered
+func (cs *containerStream) unsubscribe(sub *spool.Buffer) {
+	sub.Discard()
+
+	cs.mu.Lock()
+	delete(cs.subscribers, sub)
+	cs.mu.Unlock()
+}
+
 // HandleTerminateContainer terminates a container by sending terminate message on stream
 func (s *Server) HandleTerminateContainer(w http.ResponseWriter, r *http.Request, containerID string) {
 	if r.Method != http.MethodDelete {
@@ -316,6 +460,131 @@ func (s *Server) HandleTerminateContainer(w http.ResponseWriter, r *http.Request
 	})
 }
 
+type ExecRequest struct {
+	Command     []string          `json:"command"`
+	Env         map[string]string `json:"env,omitempty"`
+	Workdir     *string           `json:"workdir,omitempty"`
+	TimeoutSecs *uint32           `json:"timeout_secs,omitempty"`
+}
+
+// HandleExec runs an additional process in a running container and responds
+// with its output and exit code once it exits
+func (s *Server) HandleExec(w http.ResponseWriter, r *http.Request, containerID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ExecRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		json.NewEncoder(w).Encode(Response{
+			Success: false,
+			Error:   proto.String("invalid request body"),
+		})
+		return
+	}
+
+	if len(req.Command) == 0 {
+		json.NewEncoder(w).Encode(Response{
+			Success: false,
+			Error:   proto.String("command is required"),
+		})
+		return
+	}
+
+	s.streamsMu.RLock()
+	cs, exists := s.streams[containerID]
+	s.streamsMu.RUnlock()
+
+	if !exists {
+		json.NewEncoder(w).Encode(Response{
+			Success: false,
+			Error:   proto.String("container not found"),
+		})
+		return
+	}
+
+	// Register before sending so no output is missed
+	execID := strings.ReplaceAll(uuid.New().String(), "-", "")
+	result := &execResult{done: make(chan struct{})}
+	cs.mu.Lock()
+	if cs.exitCode != nil {
+		cs.mu.Unlock()
+		json.NewEncoder(w).Encode(Response{
+			Success: false,
+			Error:   proto.String("container has exited"),
+		})
+		return
+	}
+	if cs.execs == nil {
+		cs.execs = make(map[string]*execResult)
+	}
+	cs.execs[execID] = result
+	cs.mu.Unlock()
+
+	forget := func() {
+		cs.mu.Lock()
+		delete(cs.execs, execID)
+		cs.mu.Unlock()
+	}
+
+	execReq := &pb.RunRequest{
+		Request: &pb.RunRequest_Exec{
+			Exec: &pb.ExecRequest{
+				ExecId:      proto.String(execID),
+				Command:     req.Command,
+				Env:         req.Env,
+				Workdir:     req.Workdir,
+				TimeoutSecs: req.TimeoutSecs,
+			},
+		},
+	}
+
+	if err := cs.stream.Send(execReq); err != nil {
+		forget()
+		json.NewEncoder(w).Encode(Response{
+			Success: false,
+			Error:   proto.String(fmt.Sprintf("failed to send exec: %v", err)),
+		})
+		return
+	}
+
+	// The runner enforces the exec's own timeout; allow a little longer for the
+	// exit to arrive
+	timeout := 120 * time.Second
+	if req.TimeoutSecs != nil && *req.TimeoutSecs > 0 {
+		timeout = time.Duration(*req.TimeoutSecs)*time.Second + 10*time.Second
+	}
+
+	select {
+	case <-result.done:
+	case <-time.After(timeout):
+		forget()
+		json.NewEncoder(w).Encode(Response{
+			Success: false,
+			Error:   proto.String("timeout waiting for exec"),
+		})
+		return
+	case <-r.Context().Done():
+		forget()
+		return
+	}
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"success":   result.err == nil,
+		"exec_id":   execID,
+		"exit_code": result.exitCode,
+		"stdout":    string(result.stdout),
+		"stderr":    string(result.stderr),
+		"truncated": result.truncated,
+		"error":     result.err,
+	})
+}
+
 // HandleWaitContainer waits for container to exit
 func (s *Server) HandleWaitContainer(w http.ResponseWriter, r *http.Request, containerID string) {
 	if r.Method != http.MethodGet {
@@ -366,7 +635,67 @@ func (s *Server) HandleWaitContainer(w http.ResponseWriter, r *http.Request, con
 	}
 }
 
-// HandleGetLogs returns buffered logs
+// logLine is a line of a container's logs as the REST API returns it
+type logLine struct {
+	Timestamp string `json:"timestamp"`
+	Stream    string `json:"stream"`
+	Data      string `json:"data"`
+}
+
+func newLogLine(line *pb.LogLine) logLine {
+	return logLine{
+		Timestamp: time.UnixMilli(line.TimestampUnixMs).UTC().Format(time.RFC3339Nano),
+		Stream:    strings.ToLower(line.Stream.String()),
+		Data:      string(line.Data),
+	}
+}
+
+// logsRequest builds a GetLogs request from the query parameters of a logs
+// request: since (an RFC 3339 time, or a duration such as 10m meaning that long
+// ago), tail, follow and streams (e.g. stdout,events)
+func logsRequest(containerID string, query url.Values, now time.Time) (*pb.GetLogsRequest, error) {
+	req := &pb.GetLogsRequest{ContainerId: containerID}
+
+	if since := query.Get("since"); since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			req.SinceUnixMs = proto.Int64(t.UnixMilli())
+		} else if d, err := time.ParseDuration(since); err == nil && d >= 0 {
+			req.SinceUnixMs = proto.Int64(now.Add(-d).UnixMilli())
+		} else {
+			return nil, fmt.Errorf("invalid since %q: expected an RFC 3339 time or a duration", since)
+		}
+	}
+
+	if tail := query.Get("tail"); tail != "" {
+		n, err := strconv.ParseUint(tail, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tail %q", tail)
+		}
+		req.Tail = proto.Uint32(uint32(n))
+	}
+
+	if follow := query.Get("follow"); follow != "" {
+		f, err := strconv.ParseBool(follow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid follow %q", follow)
+		}
+		req.Follow = f
+	}
+
+	if value := query.Get("streams"); value != "" {
+		streams, err := parseStreams(strings.Split(value, ","))
+		if err != nil {
+			return nil, err
+		}
+		req.Streams = streams
+	}
+
+	return req, nil
+}
+
+// HandleGetLogs returns the logs the container manager keeps for a container.
+// With follow=true the response is newline-delimited JSON, one line object per
+// log line, that goes on until the container's output ends.
 func (s *Server) HandleGetLogs(w http.ResponseWriter, r *http.Request, containerID string) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -378,31 +707,179 @@ func (s *Server) HandleGetLogs(w http.ResponseWriter, r *http.Request, container
 		return
 	}
 
+	req, err := logsRequest(containerID, r.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stream, err := s.client.GetLogs(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), httpstatus.FromError(err))
+		return
+	}
+
+	if !req.Follow {
+		lines := []logLine{}
+		for {
+			line, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), httpstatus.FromError(err))
+				return
+			}
+			lines = append(lines, newLogLine(line))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"success": true,
+			"lines":   lines,
+		})
+		return
+	}
+
+	// The status is only known once the first line or the end arrives
+	line, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		http.Error(w, err.Error(), httpstatus.FromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	for err == nil {
+		if encoder.Encode(newLogLine(line)) != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		line, err = stream.Recv()
+	}
+}
+
+// watchEventsRequest builds a WatchEvents request from the query parameters of
+// an events request: label (key=value, repeatable), tenant, state (e.g.
+// running,exited) and type (e.g. container_ready,container_exited)
+func watchEventsRequest(query url.Values) (*pb.WatchEventsRequest, error) {
+	labels, err := parseLabelFilters(query["label"])
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.WatchEventsRequest{Labels: labels}
+
+	if tenant := query.Get("tenant"); tenant != "" {
+		req.Tenant = proto.String(tenant)
+	}
+
+	if value := query.Get("state"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			state, ok := pb.ContainerState_value[strings.ToUpper(strings.TrimSpace(name))]
+			if !ok {
+				return nil, fmt.Errorf("unknown state %q", name)
+			}
+			req.States = append(req.States, pb.ContainerState(state))
+		}
+	}
+
+	if value := query.Get("type"); value != "" {
+		for _, eventType := range strings.Split(value, ",") {
+			req.Types = append(req.Types, strings.TrimSpace(eventType))
+		}
+	}
+
+	return req, nil
+}
+
+// HandleWatchEvents streams the events of every container the query selects as
+// server-sent events, named after the event type, with the container's ID,
+// state and labels and the event itself as JSON data
+func (s *Server) HandleWatchEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := watchEventsRequest(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stream, err := s.client.WatchEvents(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), httpstatus.FromError(err))
+		return
+	}
+
+	// Events may be a long time coming, so the stream starts right away and
+	// errors arrive as error events
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	marshal := protojson.MarshalOptions{UseProtoNames: true}
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF || r.Context().Err() != nil {
+			return
+		}
+		if err != nil {
+			data, _ := json.Marshal(map[string]string{"error": status.Convert(err).Message()})
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+			return
+		}
+
+		data, err := marshal.Marshal(event)
+		if err != nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event.GetType(), data); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// StreamStats summarises the cached Run streams held by the server
+type StreamStats struct {
+	ActiveStreams int   `json:"active_streams"`
+	StreamsOpened int64 `json:"streams_opened_total"`
+	StreamsClosed int64 `json:"streams_closed_total"`
+}
+
+// Stats returns a snapshot of cached stream usage
+func (s *Server) Stats() StreamStats {
 	s.streamsMu.RLock()
-	cs, exists := s.streams[containerID]
+	active := len(s.streams)
 	s.streamsMu.RUnlock()
 
-	if !exists {
-		http.Error(w, "container not found", http.StatusNotFound)
-		return
+	return StreamStats{
+		ActiveStreams: active,
+		StreamsOpened: s.streamsOpened.Load(),
+		StreamsClosed: s.streamsClosed.Load(),
 	}
+}
 
-	cs.mu.RLock()
-	stdout := make([]string, len(cs.stdout))
-	stderr := make([]string, len(cs.stderr))
-	messages := make([]string, len(cs.messages))
-	copy(stdout, cs.stdout)
-	copy(stderr, cs.stderr)
-	copy(messages, cs.messages)
-	cs.mu.RUnlock()
+// HandleMetrics reports cached stream statistics
+func (s *Server) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{
-		"success":  true,
-		"stdout":   stdout,
-		"stderr":   stderr,
-		"messages": messages,
-	})
+	json.NewEncoder(w).Encode(s.Stats())
 }
 
 func (s *Server) HandleListContainers(w http.ResponseWriter, r *http.Request) {
@@ -411,16 +888,18 @@ func (s *Server) HandleListContainers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	filter := r.URL.Query().Get("filter")
+	req, err := listContainersRequest(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	resp, err := s.client.ListContainers(ctx, &pb.ListContainersRequest{
-		Filter: proto.String(filter),
-	})
+	resp, err := s.client.ListContainers(ctx, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), httpstatus.FromError(err))
 		return
 	}
 
@@ -428,6 +907,77 @@ func (s *Server) HandleListContainers(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// listContainersRequest builds a ListContainers request from the query
+// parameters of a list request: filter (running, exited or all), label
+// (key=value, repeatable), selector (e.g. tenant=acme,!debug), tenant, image,
+// exit_code, sort (created_at or state), order (asc or desc), limit and
+// page_token
+func listContainersRequest(query url.Values) (*pb.ListContainersRequest, error) {
+	labels, err := parseLabelFilters(query["label"])
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.ListContainersRequest{
+		Filter: proto.String(query.Get("filter")),
+		Labels: labels,
+	}
+
+	for name, field := range map[string]**string{
+		"selector":   &req.LabelSelector,
+		"tenant":     &req.Tenant,
+		"image":      &req.Image,
+		"sort":       &req.SortBy,
+		"page_token": &req.PageToken,
+	} {
+		if value := query.Get(name); value != "" {
+			*field = proto.String(value)
+		}
+	}
+
+	if value := query.Get("exit_code"); value != "" {
+		code, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exit_code %q", value)
+		}
+		req.ExitCode = proto.Int32(int32(code))
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		req.Descending = true
+	default:
+		return nil, fmt.Errorf("invalid order %q: expected asc or desc", order)
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit %q", value)
+		}
+		req.PageSize = proto.Uint32(uint32(limit))
+	}
+
+	return req, nil
+}
+
+// parseLabelFilters parses key=value label filters
+func parseLabelFilters(filters []string) (map[string]string, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(filters))
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label filter %q: expected key=value", filter)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
 func (s *Server) HandleGetContainer(w http.ResponseWriter, r *http.Request, containerID string) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -441,7 +991,7 @@ func (s *Server) HandleGetContainer(w http.ResponseWriter, r *http.Request, cont
 		ContainerId: containerID,
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), httpstatus.FromError(err))
 		return
 	}
 
@@ -483,9 +1033,75 @@ type ContainerConfig struct {
 	MemoryLimit *string           `json:"memory_limit,omitempty"`
 	TimeoutSecs *uint32           `json:"timeout_secs,omitempty"`
 	Cleanup     *bool             `json:"cleanup,omitempty"`
+	TTY         *bool             `json:"tty,omitempty"`
+}
+
+// parseStreams parses a list of stream names (stdout, stderr, events). An empty
+// list selects every stream.
+func parseStreams(names []string) ([]pb.OutputStream, error) {
+	streams := make([]pb.OutputStream, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		value, ok := pb.OutputStream_value[strings.ToUpper(name)]
+		if !ok || value == int32(pb.OutputStream_OUTPUT_STREAM_UNSPECIFIED) {
+			return nil, fmt.Errorf("unknown stream %q (expected stdout, stderr or events)", name)
+		}
+		streams = append(streams, pb.OutputStream(value))
+	}
+	return streams, nil
+}
+
+// streamSelected reports whether stream is in the selection; an empty selection
+// includes every stream
+func streamSelected(selected []pb.OutputStream, stream pb.OutputStream) bool {
+	return len(selected) == 0 || slices.Contains(selected, stream)
+}
+
+// HandleWebSocket handles interactive WebSocket sessions (existing containers or new ones).
+// The optional streams query parameter (e.g. ?streams=stderr,events) limits the
+// forwarded output; the exit message is always sent.
+// stdinRequest forwards input sent over a WebSocket. Line input gets back the
+// newline the UI's input box strips; a tty gets keystrokes exactly as a
+// terminal emulator such as xterm.js sends them.
+func stdinRequest(stdin string, tty bool) *pb.RunRequest {
+	data := []byte(stdin)
+	if !tty {
+		data = append(data, '\n')
+	}
+
+	return &pb.RunRequest{
+		Request: &pb.RunRequest_Stdin{
+			Stdin: data,
+		},
+	}
+}
+
+// resizeRequest reads the terminal size from resize message data of the form
+// {"rows": n, "cols": n}; it returns nil if either is missing
+func resizeRequest(data any) *pb.RunRequest {
+	size, ok := data.(map[string]any)
+	if !ok {
+		return nil
+	}
+	rows, rowsOK := size["rows"].(float64)
+	cols, colsOK := size["cols"].(float64)
+	if !rowsOK || !colsOK || rows < 1 || cols < 1 {
+		return nil
+	}
+
+	return &pb.RunRequest{
+		Request: &pb.RunRequest_Resize{
+			Resize: &pb.Resize{
+				Rows: uint32(rows),
+				Cols: uint32(cols),
+			},
+		},
+	}
 }
 
-// HandleWebSocket handles interactive WebSocket sessions (existing containers or new ones)
 func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request, containerID string) {
 	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -494,6 +1110,21 @@ func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request, contain
 	}
 	defer conn.Close()
 
+	var selected []pb.OutputStream
+	if value := r.URL.Query().Get("streams"); value != "" {
+		selected, err = parseStreams(strings.Split(value, ","))
+		if err != nil {
+			conn.WriteJSON(WebSocketMessage{
+				Type: "error",
+				Data: map[string]string{"message": err.Error()},
+			})
+			return
+		}
+	}
+	sendStdout := streamSelected(selected, pb.OutputStream_STDOUT)
+	sendStderr := streamSelected(selected, pb.OutputStream_STDERR)
+	sendEvents := streamSelected(selected, pb.OutputStream_EVENTS)
+
 	// Check if container already exists
 	s.streamsMu.RLock()
 	cs, exists := s.streams[containerID]
@@ -519,15 +1150,17 @@ func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request, contain
 			}
 
 			if msg.Stdin != nil {
-				stdinReq := &pb.RunRequest{
-					Request: &pb.RunRequest_Stdin{
-						Stdin: []byte(*msg.Stdin + "\n"),
-					},
-				}
-				if err := cs.stream.Send(stdinReq); err != nil {
+				if err := cs.stream.Send(stdinRequest(*msg.Stdin, cs.tty)); err != nil {
 					errCh <- err
 					return
 				}
+			} else if msg.Type == "resize" {
+				if resizeReq := resizeRequest(msg.Data); resizeReq != nil {
+					if err := cs.stream.Send(resizeReq); err != nil {
+						errCh <- err
+						return
+					}
+				}
 			} else if msg.Type == "close_stdin" {
 				closeReq := &pb.RunRequest{
 					Request: &pb.RunRequest_CloseStdin{
@@ -563,60 +1196,71 @@ func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request, contain
 
 	// Goroutine to forward real-time output to WebSocket
 	// Note: Buffered/historical output is available via GET /logs endpoint
+	sub := cs.subscribe(s.outputBuffering)
+	defer cs.unsubscribe(sub)
 	go func() {
-		// Stream real-time output only
+		// Stream real-time output only. Every line is delivered in order; a
+		// slow client is buffered for, up to the configured caps.
 		for {
-			select {
-			case line, ok := <-cs.stdoutBroadcast:
-				if !ok {
-					// Channel closed, stream ended
-					return
+			entry, err := sub.Read(context.Background())
+			if err == io.EOF {
+				// The stream ended and everything was delivered
+				break
+			}
+			if err != nil {
+				// This WebSocket is going away
+				return
+			}
+
+			var msg WebSocketMessage
+			switch pb.OutputStream(entry.Kind) {
+			case pb.OutputStream_STDOUT:
+				if !sendStdout {
+					continue
 				}
-				if err := conn.WriteJSON(WebSocketMessage{
+				msg = WebSocketMessage{
 					Type: "container:stdout",
-					Data: map[string]string{"data": line},
-				}); err != nil {
-					errCh <- err
-					return
+					Data: map[string]string{"data": string(entry.Data)},
 				}
-			case line, ok := <-cs.stderrBroadcast:
-				if !ok {
-					// Channel closed, stream ended
-					return
+			case pb.OutputStream_STDERR:
+				if !sendStderr {
+					continue
 				}
-				if err := conn.WriteJSON(WebSocketMessage{
+				msg = WebSocketMessage{
 					Type: "container:stderr",
-					Data: map[string]string{"data": line},
-				}); err != nil {
-					errCh <- err
-					return
+					Data: map[string]string{"data": string(entry.Data)},
 				}
-			case msg, ok := <-cs.messageBroadcast:
-				if !ok {
-					// Channel closed, stream ended
-					return
+			case pb.OutputStream_EVENTS:
+				if !sendEvents {
+					continue
 				}
 				var rawData map[string]any
-				if err := json.Unmarshal([]byte(msg), &rawData); err == nil {
-					if err := conn.WriteJSON(WebSocketMessage{
-						Type: "message",
-						Data: rawData,
-					}); err != nil {
-						errCh <- err
-						return
-					}
+				if err := json.Unmarshal(entry.Data, &rawData); err != nil {
+					continue
 				}
-			case exitCode := <-cs.exitCh:
-				conn.WriteJSON(WebSocketMessage{
-					Type: "container:exit",
-					Data: map[string]any{
-						"exit_code": exitCode,
-					},
-				})
-				errCh <- nil
+				msg = WebSocketMessage{
+					Type: "message",
+					Data: rawData,
+				}
+			}
+			if err := conn.WriteJSON(msg); err != nil {
+				errCh <- err
 				return
 			}
 		}
+
+		cs.mu.RLock()
+		exitCode := cs.exitCode
+		cs.mu.RUnlock()
+		if exitCode != nil {
+			conn.WriteJSON(WebSocketMessage{
+				Type: "container:exit",
+				Data: map[string]any{
+					"exit_code": *exitCode,
+				},
+			})
+		}
+		errCh <- nil
 	}()
 
 	<-errCh
@@ -633,8 +1277,9 @@ func (s *Server) HandleWebSocketRun(w http.ResponseWriter, r *http.Request) {
 
 	// Read container config from first websocket message
 	var firstMsg struct {
-		Type   string          `json:"type"`
-		Config ContainerConfig `json:"config"`
+		Type    string          `json:"type"`
+		Config  ContainerConfig `json:"config"`
+		Streams []string        `json:"streams,omitempty"`
 	}
 	if err := conn.ReadJSON(&firstMsg); err != nil {
 		conn.WriteJSON(WebSocketMessage{
@@ -660,7 +1305,16 @@ func (s *Server) HandleWebSocketRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	streams, err := parseStreams(firstMsg.Streams)
+	if err != nil {
+		conn.WriteJSON(WebSocketMessage{
+			Type: "error",
+			Data: map[string]string{"message": err.Error()},
+		})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
 	defer cancel()
 
 	// Open unified Run stream
@@ -695,7 +1349,9 @@ func (s *Server) HandleWebSocketRun(w http.ResponseWriter, r *http.Request) {
 					},
 					TimeoutSecs: firstMsg.Config.TimeoutSecs,
 					Cleanup:     &cleanup,
+					Tty:         firstMsg.Config.TTY,
 				},
+				Streams: streams,
 			},
 		},
 	}
@@ -720,15 +1376,18 @@ func (s *Server) HandleWebSocketRun(w http.ResponseWriter, r *http.Request) {
 			}
 
 			if msg.Stdin != nil {
-				stdinReq := &pb.RunRequest{
-					Request: &pb.RunRequest_Stdin{
-						Stdin: []byte(*msg.Stdin + "\n"),
-					},
-				}
-				if err := stream.Send(stdinReq); err != nil {
+				tty := firstMsg.Config.TTY != nil && *firstMsg.Config.TTY
+				if err := stream.Send(stdinRequest(*msg.Stdin, tty)); err != nil {
 					errCh <- err
 					return
 				}
+			} else if msg.Type == "resize" {
+				if resizeReq := resizeRequest(msg.Data); resizeReq != nil {
+					if err := stream.Send(resizeReq); err != nil {
+						errCh <- err
+						return
+					}
+				}
 			} else if msg.Type == "close_stdin" {
 				closeReq := &pb.RunRequest{
 					Request: &pb.RunRequest_CloseStdin{
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+func TestServerClose(t *testing.T) {
+	s, err := NewServer("localhost:0")
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.streams["test"] = &containerStream{
+		containerID: "test",
+		cancel:      cancel,
+	}
+
+	if got := s.Stats(); got.ActiveStreams != 1 {
+		t.Errorf("Stats() = %+v, want 1 active stream", got)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if ctx.Err() == nil {
+		t.Error("Close() did not cancel cached stream")
+	}
+	if s.ctx.Err() == nil {
+		t.Error("Close() did not cancel server context")
+	}
+
+	// Closing twice is a no-op
+	if err := s.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/containers", strings.NewReader(`{"image":"alpine"}`))
+	s.HandleCreateContainer(rec, req)
+	if !strings.Contains(rec.Body.String(), `"success":false`) {
+		t.Errorf("HandleCreateContainer after Close = %s, want failure", rec.Body.String())
+	}
+}
+
+func TestParseStreams(t *testing.T) {
+	tests := []struct {
+		names   []string
+		want    []pb.OutputStream
+		wantErr bool
+	}{
+		{nil, []pb.OutputStream{}, false},
+		{[]string{"stdout"}, []pb.OutputStream{pb.OutputStream_STDOUT}, false},
+		{[]string{"STDERR", " events "}, []pb.OutputStream{pb.OutputStream_STDERR, pb.OutputStream_EVENTS}, false},
+		{[]string{"output_stream_unspecified"}, nil, true},
+		{[]string{"stdin"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.names, ","), func(t *testing.T) {
+			got, err := parseStreams(tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStreams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("parseStreams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !streamSelected(nil, pb.OutputStream_STDOUT) {
+		t.Error("expected empty selection to include stdout")
+	}
+	if streamSelected([]pb.OutputStream{pb.OutputStream_STDERR}, pb.OutputStream_STDOUT) {
+		t.Error("expected stdout to be excluded")
+	}
+}
+
+func TestLogsRequest(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	req, err := logsRequest("c1", url.Values{
+		"since":   {"10m"},
+		"tail":    {"50"},
+		"follow":  {"true"},
+		"streams": {"stderr,events"},
+	}, now)
+	if err != nil {
+		t.Fatalf("logsRequest() error = %v", err)
+	}
+	if req.ContainerId != "c1" || req.GetSinceUnixMs() != now.Add(-10*time.Minute).UnixMilli() ||
+		req.GetTail() != 50 || !req.Follow ||
+		!slices.Equal(req.Streams, []pb.OutputStream{pb.OutputStream_STDERR, pb.OutputStream_EVENTS}) {
+		t.Errorf("logsRequest() = %v", req)
+	}
+
+	req, err = logsRequest("c1", url.Values{"since": {"2025-06-01T11:00:00Z"}}, now)
+	if err != nil || req.GetSinceUnixMs() != now.Add(-time.Hour).UnixMilli() || req.Tail != nil || req.Follow {
+		t.Errorf("logsRequest() with a time = %v, %v", req, err)
+	}
+
+	for _, query := range []url.Values{
+		{"since": {"yesterday"}},
+		{"tail": {"-1"}},
+		{"follow": {"maybe"}},
+		{"streams": {"stdin"}},
+	} {
+		if _, err := logsRequest("c1", query, now); err == nil {
+			t.Errorf("logsRequest(%v) accepted invalid parameters", query)
+		}
+	}
+}
+
+func TestWatchEventsRequest(t *testing.T) {
+	req, err := watchEventsRequest(url.Values{
+		"label":  {"team=ml"},
+		"tenant": {"acme"},
+		"state":  {"running,exited"},
+		"type":   {"container_ready, container_exited"},
+	})
+	if err != nil {
+		t.Fatalf("watchEventsRequest() error = %v", err)
+	}
+	if req.Labels["team"] != "ml" || req.GetTenant() != "acme" ||
+		!slices.Equal(req.States, []pb.ContainerState{pb.ContainerState_RUNNING, pb.ContainerState_EXITED}) ||
+		!slices.Equal(req.Types, []string{"container_ready", "container_exited"}) {
+		t.Errorf("watchEventsRequest() = %v", req)
+	}
+
+	for _, query := range []url.Values{
+		{"label": {"team"}},
+		{"state": {"sleeping"}},
+	} {
+		if _, err := watchEventsRequest(query); err == nil {
+			t.Errorf("watchEventsRequest(%v) accepted invalid parameters", query)
+		}
+	}
+}
+
+func TestListContainersRequest(t *testing.T) {
+	req, err := listContainersRequest(url.Values{
+		"filter":     {"exited"},
+		"label":      {"team=ml"},
+		"selector":   {"tier!=web"},
+		"tenant":     {"acme"},
+		"image":      {"alpine"},
+		"exit_code":  {"-1"},
+		"sort":       {"state"},
+		"order":      {"desc"},
+		"limit":      {"50"},
+		"page_token": {"abc"},
+	})
+	if err != nil {
+		t.Fatalf("listContainersRequest() error = %v", err)
+	}
+	if req.GetFilter() != "exited" || req.Labels["team"] != "ml" || req.GetLabelSelector() != "tier!=web" ||
+		req.GetTenant() != "acme" || req.GetImage() != "alpine" || req.ExitCode == nil || *req.ExitCode != -1 ||
+		req.GetSortBy() != "state" || !req.Descending || req.GetPageSize() != 50 || req.GetPageToken() != "abc" {
+		t.Errorf("listContainersRequest() = %v", req)
+	}
+
+	if req, err := listContainersRequest(url.Values{}); err != nil || req.Tenant != nil || req.ExitCode != nil || req.PageSize != nil {
+		t.Errorf("listContainersRequest() = %v, %v; want no optional fields", req, err)
+	}
+
+	for _, query := range []url.Values{
+		{"label": {"team"}},
+		{"exit_code": {"zero"}},
+		{"order": {"newest"}},
+		{"limit": {"-1"}},
+	} {
+		if _, err := listContainersRequest(query); err == nil {
+			t.Errorf("listContainersRequest(%v) accepted invalid parameters", query)
+		}
+	}
+}
+
+func TestParseLabelFilters(t *testing.T) {
+	labels, err := parseLabelFilters([]string{"team=ml", "env=", "url=a=b"})
+	if err != nil {
+		t.Fatalf("parseLabelFilters() error = %v", err)
+	}
+	want := map[string]string{"team": "ml", "env": "", "url": "a=b"}
+	if !maps.Equal(labels, want) {
+		t.Errorf("parseLabelFilters() = %v, want %v", labels, want)
+	}
+
+	for _, filter := range []string{"team", "=ml"} {
+		if _, err := parseLabelFilters([]string{filter}); err == nil {
+			t.Errorf("expected an error for label filter %q", filter)
+		}
+	}
+}
+
+func TestStdinRequest(t *testing.T) {
+	if got := stdinRequest("ls", false).GetStdin(); string(got) != "ls\n" {
+		t.Errorf("line input should end in a newline, got %q", got)
+	}
+	if got := stdinRequest("\x1b[A", true).GetStdin(); string(got) != "\x1b[A" {
+		t.Errorf("tty input should pass through unchanged, got %q", got)
+	}
+}
+
+func TestResizeRequest(t *testing.T) {
+	req := resizeRequest(map[string]any{"rows": float64(30), "cols": float64(100)})
+	if req == nil || req.GetResize().Rows != 30 || req.GetResize().Cols != 100 {
+		t.Fatalf("unexpected resize request: %v", req)
+	}
+
+	for _, data := range []any{
+		nil,
+		"30x100",
+		map[string]any{"rows": float64(30)},
+		map[string]any{"rows": float64(0), "cols": float64(100)},
+	} {
+		if req := resizeRequest(data); req != nil {
+			t.Errorf("expected no request for %v, got %v", data, req)
+		}
+	}
+}
@@ -3,39 +3,168 @@ package container
 import (
 	"bufio"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"maps"
+	"net/url"
+	"os"
 	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/hub"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/lifecycle"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/logs"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/shim"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/spool"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/webhook"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
 	"google.golang.org/protobuf/proto"
 )
 
+var (
+	// ErrAlreadyStarted is returned by Start when the container has already been started
+	ErrAlreadyStarted = errors.New("container already started")
+	// ErrNotRunning is returned by operations that require a running container
+	ErrNotRunning = errors.New("container is not running")
+	// ErrNoTTY is returned when resizing a container that was created without a tty
+	ErrNoTTY = errors.New("container has no tty")
+	// ErrInvalidSignal is returned for a signal that cannot be sent to a container
+	ErrInvalidSignal = errors.New("invalid signal")
+	// ErrInvalidCheckpoint is returned for a checkpoint ID that is not allowed
+	ErrInvalidCheckpoint = errors.New("invalid checkpoint id")
+	// ErrNotReady is returned by WaitReady when the container is not ready in time
+	ErrNotReady = errors.New("container not ready")
+)
+
+// checkpointIDPattern mirrors the isolation-runner's rule: checkpoint IDs name
+// directories under its checkpoint directory
+var checkpointIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// ValidateCheckpointID checks that id can name a checkpoint
+func ValidateCheckpointID(id string) error {
+	if !checkpointIDPattern.MatchString(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidCheckpoint, id)
+	}
+	return nil
+}
+
+// forwardableSignals are the signals Signal accepts
+var forwardableSignals = map[string]bool{
+	"SIGHUP":   true,
+	"SIGINT":   true,
+	"SIGQUIT":  true,
+	"SIGKILL":  true,
+	"SIGUSR1":  true,
+	"SIGUSR2":  true,
+	"SIGPIPE":  true,
+	"SIGALRM":  true,
+	"SIGTERM":  true,
+	"SIGCONT":  true,
+	"SIGSTOP":  true,
+	"SIGTSTP":  true,
+	"SIGWINCH": true,
+}
+
+// DefaultRuntime is the OCI runtime containers use unless their config names another
+const DefaultRuntime = "runsc"
+
+// DefaultCleanupDelay is how long an exited container is kept before removal
+const DefaultCleanupDelay = 60 * time.Second
+
+// ReasonTerminated is the stop reason recorded for a plain Terminate call
+const ReasonTerminated = "terminated"
+
+// ReasonConfigRejected is recorded when the isolation-runner refuses the
+// container's config, e.g. for a schema version it does not support; the
+// config_rejected event carries the details
+const ReasonConfigRejected = "config_rejected"
+
+// ConfigSchemaVersion is the version of the config the manager writes to the
+// isolation-runner. Bump it for changes an older runner would misread, and
+// keep the runner's SupportedSchemaVersions in step.
+const ConfigSchemaVersion = 1
+
 type Container struct {
 	ID               string
 	Config           *pb.ContainerConfig
-	cmd              *exec.Cmd
+	process          *os.Process
+	wait             func() (int32, bool) // Waits for the runner to exit and returns its exit code, or false once let go of
+	processDone      chan struct{}
+	runner           *shim.Runner // Set when the runner was started through a shim
+	shimSocket       string
 	state            *pb.ContainerStatus
 	stateMu          sync.RWMutex
-	stdoutBroadcast  chan []byte
-	stderrBroadcast  chan []byte
-	messageBroadcast chan string
+	messages         *hub.Hub[string]
+	captures         *hub.Hub[*pb.CaptureChunk]
+	captureDrops     map[string]int // Chunks of each capture a subscriber fell too far behind to get
+	captureDropsMu   sync.Mutex
+	execs            *hub.Hub[*pb.ExecOutput]
+	output           *outputLog // Recent stdout and stderr, for readers that start earlier
+	outputReaders    map[*OutputReader]struct{}
+	outputClosed     bool
+	outputBuffering  spool.Config
+	outputMu         sync.Mutex // Guards output and its readers
+	outputWriteMu    sync.Mutex
+	logs             *logs.Ring // Recent output and events as timestamped lines
+	clients          int        // Run streams attached to the container
+	clientsMu        sync.Mutex
 	stdinWriter      io.WriteCloser
+	stdioWriter      io.WriteCloser
+	outputDone       chan struct{}
 	exitCh           chan int32
+	resourceUpdateCh chan error
+	resourceUpdateMu sync.Mutex // Runs one resource update at a time
+	networkUpdateCh  chan error
+	networkUpdateMu  sync.Mutex // Runs one network policy update at a time
+	pauseCh          chan pauseResult
+	pauseMu          sync.Mutex // Runs one pause or unpause at a time
+	checkpointCh     chan checkpointResult
+	checkpointMu     sync.Mutex // Runs one checkpoint at a time
+	ready            chan struct{}
+	readyOnce        sync.Once
+	cpuLimitOnce     sync.Once
+	transfers        map[string]*transfer
+	transferMu       sync.Mutex
+	stdinMu          sync.Mutex
+	stdioMu          sync.Mutex
+	secretEnv        map[string]string
+	runnerLogFile    string
+	runnerLogLevel   string
 	ctx              context.Context
 	cancel           context.CancelFunc
 	closeOnce        sync.Once
+	lifecycle        *lifecycle.Machine
+	webhooks         []webhook.Endpoint // Notified of the container's lifecycle, besides the manager's own
 }
 
+// New creates a container whose only timers are the run timeout from its config
+// and the default cleanup delay
 func New(id string, config *pb.ContainerConfig) *Container {
+	return NewWithTimeouts(id, config, lifecycle.Timeouts{
+		Run:     time.Duration(config.GetTimeoutSecs()) * time.Second,
+		Cleanup: DefaultCleanupDelay,
+	})
+}
+
+// NewWithTimeouts creates a container whose lifecycle timers are armed from timeouts
+func NewWithTimeouts(id string, config *pb.ContainerConfig, timeouts lifecycle.Timeouts) *Container {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	now := fmt.Sprintf("%d", time.Now().Unix())
+	created := time.Now()
+	now := fmt.Sprintf("%d", created.Unix())
 	return &Container{
 		ID:     id,
 		Config: config,
@@ -45,66 +174,169 @@ func New(id string, config *pb.ContainerConfig) *Container {
 			CreatedAt:   now,
 			Config:      config,
 			IoStats:     &pb.IOStats{},
+			RunId:       newRunID(),
+			Attempt:     1,
 		},
-		stdoutBroadcast:  make(chan []byte, 100),
-		stderrBroadcast:  make(chan []byte, 100),
-		messageBroadcast: make(chan string, 100),
+		messages:         hub.New[string](0, messageHistory),
+		captures:         hub.New[*pb.CaptureChunk](0, 0),
+		captureDrops:     make(map[string]int),
+		execs:            hub.New[*pb.ExecOutput](0, 0),
+		output:           newOutputLog(OutputReplayBytes),
+		outputReaders:    make(map[*OutputReader]struct{}),
+		logs:             logs.NewRing(0, 0),
 		exitCh:           make(chan int32, 1),
+		processDone:      make(chan struct{}),
+		resourceUpdateCh: make(chan error, 1),
+		networkUpdateCh:  make(chan error, 1),
+		pauseCh:          make(chan pauseResult, 1),
+		checkpointCh:     make(chan checkpointResult, 1),
+		ready:            make(chan struct{}),
+		transfers:        make(map[string]*transfer),
 		ctx:              ctx,
 		cancel:           cancel,
+		lifecycle:        lifecycle.New(timeouts, created),
 	}
 }
 
-func (c *Container) Start(isolationRunnerPath string) error {
-	c.stateMu.Lock()
-	if c.state.State != pb.ContainerState_CREATED {
-		c.stateMu.Unlock()
-		return fmt.Errorf("container already started")
+// newRunID returns an ID for one run of a container, which the
+// isolation-runner stamps on its events and sends with its bastion calls so a
+// run can be traced across all three services
+func newRunID() string {
+	return "run_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+// RunID returns the ID of this run of the container
+func (c *Container) RunID() string {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	return c.state.RunId
+}
+
+// recordErrorCode keeps the machine-readable cause a container_exited event
+// carries, at its top level or in its data, for the container's status
+func (c *Container) recordErrorCode(msg map[string]any) {
+	errorCode, _ := msg["error_code"].(string)
+	if data, ok := msg["data"].(map[string]any); ok && errorCode == "" {
+		errorCode, _ = data["error_code"].(string)
+	}
+	if errorCode == "" {
+		return
 	}
+
+	c.stateMu.Lock()
+	c.state.ErrorCode = &errorCode
 	c.stateMu.Unlock()
+}
 
-	cmd := exec.CommandContext(c.ctx, isolationRunnerPath)
-	cmd.Env = append(cmd.Env, "BASTION_ADDRESS=localhost:50054")
+// SetRunnerLogFile has the isolation-runner keep a copy of its events in path
+// on the host. It must be called before Start.
+func (c *Container) SetRunnerLogFile(path string) {
+	c.runnerLogFile = path
+}
 
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdin pipe: %w", err)
-	}
-	c.stdinWriter = stdin
+// SetShimSocket has the isolation-runner started through a shim listening on
+// path, so it keeps running when the manager goes away. It must be called
+// before Start.
+func (c *Container) SetShimSocket(path string) {
+	c.shimSocket = path
+}
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
-	}
+// SetRunnerLogLevel sets the lowest level of log message the isolation-runner
+// emits: "debug", "info" (the default), "warning" or "error". Lifecycle events
+// are never dropped. It must be called before Start.
+func (c *Container) SetRunnerLogLevel(level string) {
+	c.runnerLogLevel = level
+}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+// SetWebhooks sets the container's own webhooks, with their secrets resolved
+func (c *Container) SetWebhooks(endpoints []webhook.Endpoint) {
+	c.webhooks = endpoints
+}
+
+// Webhooks returns the container's own webhooks
+func (c *Container) Webhooks() []webhook.Endpoint {
+	return c.webhooks
+}
+
+// SetAttempt numbers the run, for a container restarted in place of an
+// earlier run. It must be called before Start.
+func (c *Container) SetAttempt(attempt uint32) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	c.state.Attempt = attempt
+}
+
+// Attempt returns which run of the container this is, from 1
+func (c *Container) Attempt() uint32 {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	return c.state.Attempt
+}
+
+// bastionTLSEnv is passed from the manager's environment to the
+// isolation-runner, which reaches the bastion with the same certificates
+var bastionTLSEnv = []string{
+	"BASTION_TLS_CA_FILE",
+	"BASTION_TLS_CLIENT_CERT_FILE",
+	"BASTION_TLS_CLIENT_KEY_FILE",
+	"BASTION_TLS_SERVER_NAME",
+}
+
+// bastionAuthEnv returns the credential the isolation-runner presents to the
+// bastion for runID. With BASTION_AUTH_SECRET set it is the run's own,
+// derived as the bastion does, so a runner may only change the chains of its
+// run; otherwise the shared BASTION_AUTH_TOKEN, if any, is passed on.
+func bastionAuthEnv(runID string) []string {
+	if secret := os.Getenv("BASTION_AUTH_SECRET"); secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(runID))
+		return []string{"BASTION_RUN_CREDENTIAL=" + hex.EncodeToString(mac.Sum(nil))}
+	}
+	if token := os.Getenv("BASTION_AUTH_TOKEN"); token != "" {
+		return []string{"BASTION_AUTH_TOKEN=" + token}
 	}
+	return nil
+}
 
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start process: %w", err)
+// Start runs the isolation-runner and hands it the container's config, with
+// secretEnv, the resolved values of its secret references, merged into its
+// environment
+func (c *Container) Start(isolationRunnerPath string, secretEnv map[string]string) error {
+	c.stateMu.Lock()
+	if c.state.State != pb.ContainerState_CREATED {
+		c.stateMu.Unlock()
+		return ErrAlreadyStarted
 	}
+	c.stateMu.Unlock()
 
-	c.cmd = cmd
+	env := c.runnerEnv()
+	if c.shimSocket != "" {
+		if err := c.startShim(isolationRunnerPath, env); err != nil {
+			return err
+		}
+	} else if err := c.startRunner(isolationRunnerPath, env); err != nil {
+		return err
+	}
 
 	c.stateMu.Lock()
 	c.state.State = pb.ContainerState_RUNNING
 	now := fmt.Sprintf("%d", time.Now().Unix())
 	c.state.StartedAt = &now
-	c.state.Pid = proto.Int32(int32(cmd.Process.Pid))
+	c.state.Pid = proto.Int32(int32(c.process.Pid))
 	c.stateMu.Unlock()
 
+	c.secretEnv = secretEnv
 	config := c.buildConfig()
+	c.secretEnv = nil
 	configJSON, err := json.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if _, err := stdin.Write(configJSON); err != nil {
+	if _, err := c.stdinWriter.Write(configJSON); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
-	if _, err := stdin.Write([]byte("\n")); err != nil {
+	if _, err := c.stdinWriter.Write([]byte("\n")); err != nil {
 		return fmt.Errorf("failed to write newline: %w", err)
 	}
 
@@ -112,19 +344,142 @@ func (c *Container) Start(isolationRunnerPath string) error {
 	for i := range configJSON {
 		configJSON[i] = 0
 	}
+	// The workspace archive can be large and is not needed once sent
+	if c.Config.Workspace != nil {
+		c.stateMu.Lock()
+		c.Config.Workspace.Archive = nil
+		c.stateMu.Unlock()
+	}
 	if configMap, ok := config["config"].(map[string]any); ok {
 		if imageSpec, ok := configMap["image_spec"].(map[string]any); ok {
-			if auth, ok := imageSpec["auth"].(map[string]any); ok {
-				auth["password"] = ""
-				auth["username"] = ""
+			clearImageAuth(imageSpec)
+		}
+		if runnerConfig, ok := configMap["config"].(map[string]any); ok {
+			if containerConfig, ok := runnerConfig["container"].(map[string]any); ok {
+				sidecars, _ := containerConfig["sidecars"].([]map[string]any)
+				initContainers, _ := containerConfig["init_containers"].([]map[string]any)
+				for _, aux := range slices.Concat(sidecars, initContainers) {
+					clearImageAuth(aux["image_spec"].(map[string]any))
+				}
+				hooks, _ := containerConfig["post_exit_hooks"].([]map[string]any)
+				for _, hook := range hooks {
+					if aux, ok := hook["container"].(map[string]any); ok {
+						clearImageAuth(aux["image_spec"].(map[string]any))
+					}
+				}
+			}
+		}
+	}
+
+	go c.monitor()
+
+	return nil
+}
+
+// runnerEnv is the isolation-runner's environment
+func (c *Container) runnerEnv() []string {
+	// The bastion may be on a unix socket, as "unix:///run/bastion/bastion.sock"
+	bastionAddress := os.Getenv("BASTION_ADDRESS")
+	if bastionAddress == "" {
+		bastionAddress = "localhost:50054"
+	}
+	env := []string{"BASTION_ADDRESS=" + bastionAddress}
+	for _, key := range bastionTLSEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return append(env, bastionAuthEnv(c.RunID())...)
+}
+
+// startRunner runs the isolation-runner as a child of the manager, which it
+// does not outlive
+func (c *Container) startRunner(isolationRunnerPath string, env []string) error {
+	cmd := exec.CommandContext(c.ctx, isolationRunnerPath)
+	cmd.Env = env
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	c.stdinWriter = stdin
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	stdioStarted, err := c.attachStdioPipes(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		stdioStarted(false)
+		return fmt.Errorf("failed to start process: %w", err)
+	}
+	stdioStarted(true)
+
+	c.process = cmd.Process
+	c.wait = func() (int32, bool) {
+		if err := cmd.Wait(); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				return int32(exitErr.ExitCode()), true
 			}
+			return 1, true
 		}
+		return 0, true
 	}
 
 	go c.readOutput(stdout, true)
 	go c.readOutput(stderr, false)
-	go c.monitor()
+	return nil
+}
+
+// startShim runs the isolation-runner through a shim, which keeps it running
+// for a restarted manager to reattach to
+func (c *Container) startShim(isolationRunnerPath string, env []string) error {
+	runner, err := shim.Spawn(c.shimSocket, isolationRunnerPath, env)
+	if err != nil {
+		return fmt.Errorf("failed to start process: %w", err)
+	}
+	return c.attachRunner(runner)
+}
+
+// attachRunner takes over the pipes of a runner started through a shim
+func (c *Container) attachRunner(runner *shim.Runner) error {
+	process, err := os.FindProcess(runner.Pid)
+	if err != nil {
+		runner.Close()
+		return fmt.Errorf("failed to find isolation-runner %d: %w", runner.Pid, err)
+	}
+
+	c.runner = runner
+	c.process = process
+	c.wait = func() (int32, bool) {
+		exitCode, err := runner.Wait()
+		if err != nil {
+			if c.ctx.Err() != nil {
+				// Closed by the manager, which leaves the runner to its shim
+				return 0, false
+			}
+			log.Printf("Container %s lost its isolation-runner: %v", c.ID, err)
+			return 1, true
+		}
+		return exitCode, true
+	}
+	c.stdinWriter = runner.Stdin
+	c.stdioWriter = runner.Input
+	c.outputDone = make(chan struct{})
 
+	go c.readFrames(runner.Output)
+	go c.readOutput(runner.Stdout, true)
+	go c.readOutput(runner.Stderr, false)
 	return nil
 }
 
@@ -134,9 +489,146 @@ func (c *Container) buildConfig() map[string]any {
 		hexID = hexID[:16]
 	}
 
-	networkRules := []map[string]any{}
+	var subnet, staticIP *string
 	if c.Config.Network != nil {
-		for _, rule := range c.Config.Network.Rules {
+		subnet = c.Config.Network.Subnet
+		staticIP = c.Config.Network.StaticIp
+	}
+
+	// Build container config, only include resource limits if they're set
+	containerConfig := map[string]any{
+		"runtime":         c.runtime(),
+		"readonly_rootfs": false,
+		"tmpfs":           []string{},
+		"environment":     c.environment(),
+		"working_dir":     c.Config.Workdir,
+	}
+
+	if len(c.Config.Ports) > 0 {
+		ports := make([]map[string]any, 0, len(c.Config.Ports))
+		for _, p := range c.Config.Ports {
+			ports = append(ports, map[string]any{
+				"container_port": p.ContainerPort,
+				"host_port":      p.GetHostPort(),
+				"protocol":       p.GetProtocol(),
+			})
+		}
+		containerConfig["ports"] = ports
+	}
+
+	if ws := c.Config.Workspace; ws != nil {
+		containerConfig["workspace"] = map[string]any{
+			"archive": ws.Archive,
+			"url":     ws.GetUrl(),
+			"path":    ws.GetPath(),
+			"mount":   ws.GetMount(),
+		}
+	}
+
+	if probe := c.Config.ReadinessProbe; probe != nil {
+		containerConfig["readiness_probe"] = map[string]any{
+			"port":               probe.GetPort(),
+			"http_path":          probe.GetHttpPath(),
+			"command":            probe.Command,
+			"initial_delay_secs": probe.GetInitialDelaySecs(),
+			"period_secs":        probe.GetPeriodSecs(),
+			"timeout_secs":       probe.GetTimeoutSecs(),
+		}
+	}
+
+	if opts := c.Config.RuntimeOptions; opts != nil {
+		runtimeOptions := map[string]any{}
+		if opts.Platform != nil {
+			runtimeOptions["platform"] = opts.GetPlatform()
+		}
+		if opts.Network != nil {
+			runtimeOptions["network"] = opts.GetNetwork()
+		}
+		if opts.Directfs != nil {
+			runtimeOptions["directfs"] = opts.GetDirectfs()
+		}
+		containerConfig["runtime_options"] = runtimeOptions
+	}
+
+	if len(c.Config.Sidecars) > 0 {
+		containerConfig["sidecars"] = buildAuxContainers(c.Config.Sidecars)
+	}
+	if len(c.Config.InitContainers) > 0 {
+		containerConfig["init_containers"] = buildAuxContainers(c.Config.InitContainers)
+	}
+	if len(c.Config.Labels) > 0 {
+		containerConfig["labels"] = c.Config.Labels
+	}
+	if len(c.Config.PostExitHooks) > 0 {
+		containerConfig["post_exit_hooks"] = buildHooks(c.Config.PostExitHooks)
+	}
+
+	if restoreFrom := c.Config.GetRestoreFrom(); restoreFrom != "" {
+		containerConfig["restore_from"] = restoreFrom
+	}
+
+	// Only include memory_limit if it's non-empty
+	if memLimit := c.Config.Resources.GetMemoryLimit(); memLimit != "" {
+		containerConfig["memory_limit"] = memLimit
+	}
+
+	// Only include cpu_limit if it's non-empty
+	if cpuLimit := c.Config.Resources.GetCpuLimit(); cpuLimit != "" {
+		containerConfig["cpu_limit"] = cpuLimit
+	}
+
+	logLevel := c.runnerLogLevel
+	if logLevel == "" {
+		logLevel = "info"
+	}
+	logging := map[string]any{
+		"enabled":              true,
+		"log_level":            logLevel,
+		"log_network_attempts": c.Config.Network.GetLogNetworkAttempts(),
+	}
+	if c.runnerLogFile != "" {
+		logging["log_file"] = c.runnerLogFile
+	}
+
+	return map[string]any{
+		"type":           "config",
+		"schema_version": ConfigSchemaVersion,
+		"config": map[string]any{
+			"run_id":         c.RunID(),
+			"image_spec":     c.buildImageSpec(),
+			"command":        c.Config.Command,
+			"args":           c.Config.Args,
+			"container_name": hexID,
+			"bridge_name":    "bridge",
+			"subnet":         subnet,
+			"static_ip":      staticIP,
+			"config": map[string]any{
+				"version":   "1.0.0",
+				"network":   buildNetworkConfig(c.Config.Network),
+				"container": containerConfig,
+				"execution": map[string]any{
+					"attach_stdin":        true,
+					"attach_stdout":       true,
+					"attach_stderr":       true,
+					"tty":                 c.Config.GetTty(),
+					"interactive":         true,
+					"binary_stdio":        true,
+					"stats_interval_secs": c.statsInterval(),
+					"quiet":               c.Config.GetQuietOutput(),
+					"auto_cleanup":        c.Config.Cleanup,
+					"timeout_seconds":     c.Config.TimeoutSecs,
+				},
+				"logging": logging,
+			},
+		},
+	}
+}
+
+// buildNetworkConfig converts NetworkConfig proto to the isolation-runner's network policy JSON
+func buildNetworkConfig(network *pb.NetworkConfig) map[string]any {
+	networkRules := []map[string]any{}
+	if network != nil {
+		for _, rule := range network.Rules {
 			if rule.Action == "allow" {
 				dest := "0.0.0.0/0"
 				if rule.Destination != nil {
@@ -165,76 +657,45 @@ func (c *Container) buildConfig() map[string]any {
 	defaultPolicy := "deny"
 	allowDNS := false
 	dnsServers := []string{}
-	if c.Config.Network != nil && c.Config.Network.DefaultPolicy != nil {
-		defaultPolicy = *c.Config.Network.DefaultPolicy
+	dnsAllowedDomains := []string{}
+	var maxConnections *uint32
+	if network != nil && network.DefaultPolicy != nil {
+		defaultPolicy = *network.DefaultPolicy
 	}
-	if c.Config.Network != nil && len(c.Config.Network.DnsServers) > 0 {
+	if network != nil && len(network.DnsServers) > 0 {
 		allowDNS = true
-		dnsServers = c.Config.Network.DnsServers
-	}
-
-	// Build container config, only include resource limits if they're set
-	containerConfig := map[string]any{
-		"runtime":         "runsc",
-		"readonly_rootfs": false,
-		"tmpfs":           []string{},
-		"environment":     c.Config.Env,
-		"working_dir":     c.Config.Workdir,
+		dnsServers = network.DnsServers
 	}
-
-	// Only include memory_limit if it's non-empty
-	if memLimit := c.Config.Resources.GetMemoryLimit(); memLimit != "" {
-		containerConfig["memory_limit"] = memLimit
+	if network != nil && len(network.DnsAllowedDomains) > 0 {
+		allowDNS = true
+		dnsAllowedDomains = network.DnsAllowedDomains
 	}
-
-	// Only include cpu_limit if it's non-empty
-	if cpuLimit := c.Config.Resources.GetCpuLimit(); cpuLimit != "" {
-		containerConfig["cpu_limit"] = cpuLimit
+	if network != nil {
+		maxConnections = network.MaxConnections
 	}
 
 	return map[string]any{
-		"type": "config",
-		"config": map[string]any{
-			"image_spec":     c.buildImageSpec(),
-			"command":        c.Config.Command,
-			"args":           c.Config.Args,
-			"container_name": hexID,
-			"bridge_name":    "bridge",
-			"subnet":         nil,
-			"config": map[string]any{
-				"version": "1.0.0",
-				"network": map[string]any{
-					"default_policy":       defaultPolicy,
-					"block_metadata":       true,
-					"allow_dns":            allowDNS,
-					"dns_servers":          dnsServers,
-					"allowed_destinations": []string{},
-					"whitelist":            networkRules,
-					"blacklist":            []map[string]any{},
-				},
-				"container": containerConfig,
-				"execution": map[string]any{
-					"attach_stdin":    true,
-					"attach_stdout":   true,
-					"attach_stderr":   true,
-					"tty":             false,
-					"interactive":     true,
-					"auto_cleanup":    c.Config.Cleanup,
-					"timeout_seconds": c.Config.TimeoutSecs,
-				},
-				"logging": map[string]any{
-					"enabled": true,
-					"level":   "info",
-				},
-			},
-		},
+		"default_policy":       defaultPolicy,
+		"block_metadata":       true,
+		"allow_dns":            allowDNS,
+		"dns_servers":          dnsServers,
+		"allowed_destinations": []string{},
+		"whitelist":            networkRules,
+		"blacklist":            []map[string]any{},
+		"max_connections":      maxConnections,
+		"dns_allowed_domains":  dnsAllowedDomains,
+		"pod_group":            network.GetPodGroup(),
 	}
 }
 
 // buildImageSpec converts ImageSpec proto to JSON map for isolation-runner
 // SECURITY: Credentials included here will be cleared after serialization
 func (c *Container) buildImageSpec() map[string]any {
-	spec := c.Config.ImageSpec
+	return imageSpecConfig(c.Config.ImageSpec)
+}
+
+// imageSpecConfig converts an ImageSpec proto to the isolation-runner's JSON form
+func imageSpecConfig(spec *pb.ImageSpec) map[string]any {
 	if spec == nil {
 		return map[string]any{
 			"registry": "registry-1.docker.io",
@@ -263,6 +724,59 @@ func (c *Container) buildImageSpec() map[string]any {
 	return imageSpec
 }
 
+// clearImageAuth blanks the credentials of an image spec built by imageSpecConfig
+func clearImageAuth(imageSpec map[string]any) {
+	if auth, ok := imageSpec["auth"].(map[string]any); ok {
+		auth["password"] = ""
+		auth["username"] = ""
+	}
+}
+
+// buildAuxContainers converts sidecars or init containers to the
+// isolation-runner's JSON form
+func buildAuxContainers(containers []*pb.AuxContainer) []map[string]any {
+	result := make([]map[string]any, 0, len(containers))
+	for _, aux := range containers {
+		config := map[string]any{
+			"name":        aux.Name,
+			"image_spec":  imageSpecConfig(aux.ImageSpec),
+			"command":     aux.Command,
+			"args":        aux.Args,
+			"environment": aux.Env,
+		}
+		if memLimit := aux.Resources.GetMemoryLimit(); memLimit != "" {
+			config["memory_limit"] = memLimit
+		}
+		if cpuLimit := aux.Resources.GetCpuLimit(); cpuLimit != "" {
+			config["cpu_limit"] = cpuLimit
+		}
+		result = append(result, config)
+	}
+	return result
+}
+
+// buildHooks converts post-exit hooks to the isolation-runner's JSON form; a
+// hook container takes the hook's name
+func buildHooks(hooks []*pb.PostExitHook) []map[string]any {
+	result := make([]map[string]any, 0, len(hooks))
+	for _, hook := range hooks {
+		config := map[string]any{
+			"name":         hook.Name,
+			"timeout_secs": hook.GetTimeoutSecs(),
+		}
+		if hook.Container != nil {
+			aux := buildAuxContainers([]*pb.AuxContainer{hook.Container})[0]
+			aux["name"] = hook.Name
+			config["container"] = aux
+		}
+		if webhookURL := hook.GetWebhookUrl(); webhookURL != "" {
+			config["webhook_url"] = webhookURL
+		}
+		result = append(result, config)
+	}
+	return result
+}
+
 // getImageDisplayName returns sanitized image name for logging (no credentials)
 func (c *Container) getImageDisplayName() string {
 	spec := c.Config.ImageSpec
@@ -293,20 +807,16 @@ func (c *Container) readOutput(reader io.Reader, isStdout bool) {
 			continue
 		}
 
+		c.lifecycle.Activity(time.Now())
+
 		data := make([]byte, lineLen+1)
 		copy(data, line)
 		data[lineLen] = '\n'
 
 		if isStdout {
-			select {
-			case c.stdoutBroadcast <- data:
-			default:
-			}
+			c.writeOutput(pb.OutputStream_STDOUT, data)
 		} else {
-			select {
-			case c.stderrBroadcast <- data:
-			default:
-			}
+			c.writeOutput(pb.OutputStream_STDERR, data)
 		}
 	}
 }
@@ -319,30 +829,25 @@ func (c *Container) handleJSONMessage(msg map[string]any) {
 
 	switch msgType {
 	case "container:stdout":
+		c.lifecycle.Activity(time.Now())
 		if data, ok := msg["data"].(map[string]any); ok {
 			if text, ok := data["data"].(string); ok {
-				output := []byte(text)
-				select {
-				case c.stdoutBroadcast <- output:
-				default:
-				}
+				c.writeOutput(pb.OutputStream_STDOUT, []byte(text))
 			}
 		}
 
 	case "container:stderr":
+		c.lifecycle.Activity(time.Now())
 		if data, ok := msg["data"].(map[string]any); ok {
 			if text, ok := data["data"].(string); ok {
-				output := []byte(text)
-				select {
-				case c.stderrBroadcast <- output:
-				default:
-				}
+				c.writeOutput(pb.OutputStream_STDERR, []byte(text))
 			}
 		}
 
 	case "container:exit":
 		if data, ok := msg["data"].(map[string]any); ok {
 			if code, ok := data["code"].(float64); ok {
+				c.waitForOutput()
 				select {
 				case c.exitCh <- int32(code):
 				default:
@@ -353,59 +858,251 @@ func (c *Container) handleJSONMessage(msg map[string]any) {
 	case "container_exit":
 		// Handle container_exit from isolation-runner
 		if code, ok := msg["exit_code"].(float64); ok {
+			c.waitForOutput()
 			select {
 			case c.exitCh <- int32(code):
 			default:
 			}
 		}
 
-	case "info", "debug", "warning", "error":
+	case "runner_hello", "config_rejected":
+		c.stateMu.Lock()
+		if data, ok := msg["data"].(map[string]any); ok && msgType == "runner_hello" {
+			if version, ok := data["version"].(string); ok {
+				c.state.RunnerVersion = &version
+			}
+		}
+		if msgType == "config_rejected" && c.state.TerminationReason == nil {
+			c.state.TerminationReason = proto.String(ReasonConfigRejected)
+		}
+		c.stateMu.Unlock()
+
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
+	case "runner_heartbeat":
+		c.lifecycle.RunnerHeartbeat(time.Now())
+		if data, ok := msg["data"].(map[string]any); ok {
+			if phase, ok := data["phase"].(string); ok {
+				c.stateMu.Lock()
+				c.state.RunnerPhase = &phase
+				c.stateMu.Unlock()
+			}
+		}
+
+		// Heartbeats go to subscribers only; they would crowd everything
+		// else out of the logs
+		msgBytes, _ := json.Marshal(msg)
+		c.messages.Publish(string(msgBytes))
+
+	case "info", "debug", "warning", "error", "runner_self_stats":
 		msgBytes, _ := json.Marshal(msg)
 		msgStr := string(msgBytes)
+		c.publishMessage(msgStr)
+
+	case "container_resources_updated", "container_resources_update_failed":
+		var result error
+		if msgType == "container_resources_update_failed" {
+			result = fmt.Errorf("isolation-runner failed to update resources")
+			if data, ok := msg["data"].(map[string]any); ok {
+				if errMsg, ok := data["error"].(string); ok {
+					result = fmt.Errorf("isolation-runner failed to update resources: %s", errMsg)
+				}
+			}
+		}
+		select {
+		case c.resourceUpdateCh <- result:
+		default:
+		}
+
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
+	case "container_paused", "container_pause_failed", "container_resumed", "container_resume_failed":
+		var result error
+		if msgType == "container_pause_failed" || msgType == "container_resume_failed" {
+			action := "pause"
+			if msgType == "container_resume_failed" {
+				action = "unpause"
+			}
+			result = fmt.Errorf("isolation-runner failed to %s container", action)
+			if data, ok := msg["data"].(map[string]any); ok {
+				if errMsg, ok := data["error"].(string); ok {
+					result = fmt.Errorf("%w: %s", result, errMsg)
+				}
+			}
+		}
+		paused := msgType == "container_paused" || msgType == "container_pause_failed"
+		select {
+		case c.pauseCh <- pauseResult{paused: paused, err: result}:
+		default:
+		}
+
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
+	case "container_checkpointed", "container_checkpoint_failed":
+		data, _ := msg["data"].(map[string]any)
+		checkpointID, _ := data["checkpoint_id"].(string)
+		var result error
+		if msgType == "container_checkpoint_failed" {
+			result = fmt.Errorf("isolation-runner failed to checkpoint container")
+			if errMsg, ok := data["error"].(string); ok {
+				result = fmt.Errorf("%w: %s", result, errMsg)
+			}
+		}
+		select {
+		case c.checkpointCh <- checkpointResult{id: checkpointID, err: result}:
+		default:
+		}
+
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
+	case "container_stats":
+		if data, ok := msg["data"].(map[string]any); ok {
+			c.recordStats(data, msg["timestamp"])
+		}
+
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
+	case "network_policy_updated", "network_policy_update_failed":
+		var result error
+		if msgType == "network_policy_update_failed" {
+			result = fmt.Errorf("isolation-runner failed to update network policy")
+			if data, ok := msg["data"].(map[string]any); ok {
+				if errMsg, ok := data["error"].(string); ok {
+					result = fmt.Errorf("isolation-runner failed to update network policy: %s", errMsg)
+				}
+			}
+		}
 		select {
-		case c.messageBroadcast <- msgStr:
+		case c.networkUpdateCh <- result:
 		default:
 		}
 
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
+	case "capture_data":
+		data, ok := msg["data"].(map[string]any)
+		if !ok {
+			return
+		}
+		captureID, _ := data["capture_id"].(string)
+		encoded, _ := data["data"].(string)
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return
+		}
+		c.sendCaptureChunk(&pb.CaptureChunk{CaptureId: captureID, Data: decoded})
+
+	case "capture_stopped":
+		if data, ok := msg["data"].(map[string]any); ok {
+			chunk := &pb.CaptureChunk{Done: true}
+			chunk.CaptureId, _ = data["capture_id"].(string)
+			if errMsg, ok := data["error"].(string); ok {
+				chunk.Error = proto.String(errMsg)
+			}
+			c.sendCaptureChunk(chunk)
+		}
+
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
+	case "exec_output":
+		data, ok := msg["data"].(map[string]any)
+		if !ok {
+			return
+		}
+		encoded, _ := data["data"].(string)
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return
+		}
+		output := &pb.ExecOutput{}
+		output.ExecId, _ = data["exec_id"].(string)
+		if stream, _ := data["stream"].(string); stream == "stderr" {
+			output.Stderr = decoded
+		} else {
+			output.Stdout = decoded
+		}
+		c.sendExecOutput(output)
+
+	case "exec_exited":
+		if data, ok := msg["data"].(map[string]any); ok {
+			output := &pb.ExecOutput{Exited: true}
+			output.ExecId, _ = data["exec_id"].(string)
+			if exitCode, ok := data["exit_code"].(float64); ok {
+				output.ExitCode = int32(exitCode)
+			}
+			if errMsg, ok := data["error"].(string); ok {
+				output.Error = proto.String(errMsg)
+			}
+			c.sendExecOutput(output)
+		}
+
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
+	case "file_download_data", "file_uploaded", "file_downloaded", "file_transfer_failed":
+		if data, ok := msg["data"].(map[string]any); ok {
+			c.handleTransferMessage(msgType, data)
+		}
+		if msgType == "file_download_data" {
+			return
+		}
+
+		msgBytes, _ := json.Marshal(msg)
+		c.publishMessage(string(msgBytes))
+
 	// Handle structured lifecycle events
 	case "container_created", "container_started", "image_pull_started",
 		"image_pull_completed", "container_ip_ready", "network_isolation_ready",
-		"container_terminating", "container_exited", "container_ready":
+		"container_terminating", "container_exited", "container_ready",
+		"network_attempt", "capture_started", "container_port_ready",
+		"exec_started", "container_signaled", "container_signal_failed",
+		"readiness_probe_failed", "sidecar_started", "sidecar_output", "sidecar_exited",
+		"init_container_started", "init_container_output", "init_container_exited",
+		"hook_started", "hook_output", "hook_completed":
+		if msgType == "container_exited" {
+			c.recordErrorCode(msg)
+		}
 		msgBytes, _ := json.Marshal(msg)
 		msgStr := string(msgBytes)
-		select {
-		case c.messageBroadcast <- msgStr:
-		default:
+		c.publishMessage(msgStr)
+
+		if msgType == "container_started" {
+			if tr, err := c.lifecycle.Started(time.Now()); err == nil {
+				c.broadcastTransition(tr)
+			}
+		}
+		if msgType == "container_ready" {
+			c.readyOnce.Do(func() { close(c.ready) })
 		}
 	}
 }
 
 func (c *Container) monitor() {
-	if c.cmd == nil || c.cmd.Process == nil {
+	if c.wait == nil {
 		return
 	}
 
-	err := c.cmd.Wait()
-	exitCode := int32(0)
-
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = int32(exitErr.ExitCode())
-		} else {
-			exitCode = 1
-		}
+	exitCode, ok := c.wait()
+	if !ok {
+		return
 	}
+	close(c.processDone)
 
 	// Brief sleep to allow readOutput goroutines to finish reading final data from pipes
 	time.Sleep(50 * time.Millisecond)
 
+	now := time.Now()
 	c.stateMu.Lock()
-	nowUnix := time.Now().Unix()
-	nowStr := fmt.Sprintf("%d", nowUnix)
+	nowStr := fmt.Sprintf("%d", now.Unix())
 	c.state.FinishedAt = &nowStr
 	c.state.ExitCode = &exitCode
-	cleanupAfter := nowUnix + 60
-	c.state.CleanupAfter = &cleanupAfter
 
 	if exitCode == 0 {
 		c.state.State = pb.ContainerState_EXITED
@@ -414,6 +1111,9 @@ func (c *Container) monitor() {
 	}
 	c.stateMu.Unlock()
 
+	// Announced once the state says how it ended
+	c.exited(now)
+
 	select {
 	case c.exitCh <- exitCode:
 	default:
@@ -423,6 +1123,12 @@ func (c *Container) monitor() {
 }
 
 func (c *Container) Terminate(force bool, timeoutSecs uint32) error {
+	return c.terminate(ReasonTerminated, force, timeoutSecs)
+}
+
+func (c *Container) terminate(reason string, force bool, timeoutSecs uint32) error {
+	c.stop(reason)
+
 	c.stateMu.Lock()
 	state := c.state.State
 
@@ -435,15 +1141,20 @@ func (c *Container) Terminate(force bool, timeoutSecs uint32) error {
 			c.state.FinishedAt = &finishedAt
 		}
 		c.stateMu.Unlock()
+
+		// No process will report the exit, so record it here
+		if state == pb.ContainerState_CREATED {
+			c.exited(time.Now())
+		}
 		return nil
 	}
 	c.stateMu.Unlock()
 
-	if c.cmd == nil || c.cmd.Process == nil {
+	if c.process == nil {
 		return fmt.Errorf("no process to terminate")
 	}
 
-	if err := c.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+	if err := c.process.Signal(syscall.SIGTERM); err != nil {
 		return fmt.Errorf("failed to send SIGTERM: %w", err)
 	}
 
@@ -456,21 +1167,15 @@ func (c *Container) Terminate(force bool, timeoutSecs uint32) error {
 		}
 	}
 
-	done := make(chan struct{})
-	go func() {
-		c.cmd.Wait()
-		close(done)
-	}()
-
 	select {
-	case <-done:
+	case <-c.processDone:
 		c.stateMu.Lock()
 		c.state.State = pb.ContainerState_TERMINATED
 		c.stateMu.Unlock()
 		return nil
 
 	case <-time.After(timeout):
-		if err := c.cmd.Process.Kill(); err != nil {
+		if err := c.process.Kill(); err != nil {
 			return fmt.Errorf("failed to kill process: %w", err)
 		}
 		c.stateMu.Lock()
@@ -480,6 +1185,201 @@ func (c *Container) Terminate(force bool, timeoutSecs uint32) error {
 	}
 }
 
+// TerminateWithReason terminates the container on the manager's behalf, recording
+// reason in its status and announcing it to subscribers as container_terminating
+func (c *Container) TerminateWithReason(reason string, force bool, timeoutSecs uint32) error {
+	c.stateMu.Lock()
+	if c.state.TerminationReason == nil {
+		c.state.TerminationReason = &reason
+	}
+	c.stateMu.Unlock()
+
+	msgBytes, _ := json.Marshal(map[string]any{
+		"type":      "container_terminating",
+		"timestamp": time.Now().Format(time.RFC3339Nano),
+		"data": map[string]any{
+			"container_id": c.ID,
+			"reason":       reason,
+			"force":        force,
+		},
+	})
+	c.publishMessage(string(msgBytes))
+
+	return c.terminate(reason, force, timeoutSecs)
+}
+
+// Heartbeat records a client heartbeat, deferring the heartbeat timeout
+func (c *Container) Heartbeat() {
+	c.lifecycle.Heartbeat(time.Now())
+}
+
+// Disconnected records that the client went away, starting the disconnect
+// grace period in place of the heartbeat timeout
+func (c *Container) Disconnected() {
+	c.lifecycle.Disconnected(time.Now())
+}
+
+// ClientAttached records a Run stream bound to the container and counts as its
+// heartbeat, ending any disconnect grace period
+func (c *Container) ClientAttached() {
+	c.clientsMu.Lock()
+	c.clients++
+	c.clientsMu.Unlock()
+
+	c.Heartbeat()
+}
+
+// ClientDetached records that a Run stream bound to the container ended and
+// returns how many are still attached
+func (c *Container) ClientDetached() int {
+	c.clientsMu.Lock()
+	defer c.clientsMu.Unlock()
+
+	if c.clients > 0 {
+		c.clients--
+	}
+	return c.clients
+}
+
+// AdvanceLifecycle fires the earliest lifecycle timer that expired at now, if
+// any, and broadcasts the resulting transition
+func (c *Container) AdvanceLifecycle(now time.Time) (lifecycle.Transition, bool) {
+	tr, ok := c.lifecycle.Advance(now)
+	if !ok {
+		return tr, false
+	}
+
+	switch tr.Reason {
+	case lifecycle.ReasonIdleTimeout:
+		c.broadcastIdleTimeout(tr.At, "terminate")
+	case lifecycle.ReasonIdleSuspend:
+		c.broadcastIdleTimeout(tr.At, "pause")
+	}
+	if tr.From != tr.To {
+		c.broadcastTransition(tr)
+	}
+	return tr, true
+}
+
+// SuspendIdle pauses a container whose idle timer fired with IdleSuspend. If it
+// cannot be paused, its idle timer starts over.
+func (c *Container) SuspendIdle(timeout time.Duration) error {
+	if err := c.Pause(timeout); err != nil {
+		c.lifecycle.Resume(time.Now())
+		return err
+	}
+	return nil
+}
+
+// Phase returns the container's lifecycle phase
+func (c *Container) Phase() lifecycle.Phase {
+	return c.lifecycle.Phase()
+}
+
+// Stopping is closed once termination of the container has begun
+func (c *Container) Stopping() <-chan struct{} {
+	return c.lifecycle.Stopping()
+}
+
+// StopReason returns why termination began, or "" if it has not
+func (c *Container) StopReason() string {
+	return c.lifecycle.StopReason()
+}
+
+// stop moves the lifecycle to stopping; it is a no-op if the container is
+// already stopping or gone
+func (c *Container) stop(reason string) {
+	if tr, err := c.lifecycle.Stop(time.Now(), reason); err == nil {
+		c.broadcastTransition(tr)
+	}
+}
+
+// ExtendTimeout pushes back the run timeout of a running container by d, as
+// far as its lifecycle's MaxRunExtension allows, and announces it as a
+// timeout_extended event. It returns the new deadline, or the zero time if
+// the run timer is only armed once the container is up, and the extension in
+// total.
+func (c *Container) ExtendTimeout(d time.Duration) (time.Time, time.Duration, error) {
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		return time.Time{}, 0, ErrNotRunning
+	}
+
+	deadline, err := c.lifecycle.ExtendRun(d)
+	if errors.Is(err, lifecycle.ErrInvalidTransition) {
+		// Already stopping
+		return time.Time{}, 0, fmt.Errorf("%w: %v", ErrNotRunning, err)
+	} else if err != nil {
+		return time.Time{}, 0, err
+	}
+	total := c.lifecycle.Extended()
+
+	data := map[string]any{
+		"container_id":         c.ID,
+		"extended_by_secs":     int64(d / time.Second),
+		"total_extension_secs": int64(total / time.Second),
+	}
+	if !deadline.IsZero() {
+		data["run_deadline"] = deadline.Format(time.RFC3339)
+	}
+	msgBytes, _ := json.Marshal(map[string]any{
+		"type":      "timeout_extended",
+		"timestamp": time.Now().Format(time.RFC3339Nano),
+		"data":      data,
+	})
+	c.publishMessage(string(msgBytes))
+
+	return deadline, total, nil
+}
+
+// exited moves the lifecycle to exited and schedules cleanup from its deadline
+func (c *Container) exited(now time.Time) {
+	tr, err := c.lifecycle.Exited(now)
+	if err != nil {
+		return
+	}
+
+	if deadline, ok := c.lifecycle.Deadline(lifecycle.TimerCleanup); ok {
+		cleanupAfter := deadline.Unix()
+		c.stateMu.Lock()
+		c.state.CleanupAfter = &cleanupAfter
+		c.stateMu.Unlock()
+	}
+
+	c.broadcastTransition(tr)
+}
+
+// broadcastIdleTimeout announces that the idle timeout fired and what is done about it
+func (c *Container) broadcastIdleTimeout(at time.Time, action string) {
+	msgBytes, _ := json.Marshal(map[string]any{
+		"type":      "container_idle_timeout",
+		"timestamp": at.Format(time.RFC3339Nano),
+		"data": map[string]any{
+			"container_id": c.ID,
+			"action":       action,
+		},
+	})
+	c.publishMessage(string(msgBytes))
+}
+
+// broadcastTransition announces a lifecycle transition to subscribers as container_lifecycle
+func (c *Container) broadcastTransition(tr lifecycle.Transition) {
+	msgBytes, _ := json.Marshal(map[string]any{
+		"type":      "container_lifecycle",
+		"timestamp": tr.At.Format(time.RFC3339Nano),
+		"data": map[string]any{
+			"container_id": c.ID,
+			"from":         tr.From.String(),
+			"to":           tr.To.String(),
+			"reason":       tr.Reason,
+		},
+	})
+	c.publishMessage(string(msgBytes))
+}
+
 func (c *Container) Wait(timeoutSecs uint32) (int32, error) {
 	if timeoutSecs == 0 {
 		exitCode := <-c.exitCh
@@ -507,39 +1407,615 @@ func (c *Container) GetState() *pb.ContainerStatus {
 			// Auth intentionally omitted
 		}
 	}
+	for _, aux := range slices.Concat(safeConfig.Sidecars, safeConfig.InitContainers) {
+		if aux.ImageSpec != nil {
+			aux.ImageSpec = &pb.ImageSpec{Registry: aux.ImageSpec.Registry, Image: aux.ImageSpec.Image}
+		}
+	}
+	for _, hook := range safeConfig.PostExitHooks {
+		if hook.Container != nil && hook.Container.ImageSpec != nil {
+			hook.Container.ImageSpec = &pb.ImageSpec{Registry: hook.Container.ImageSpec.Registry, Image: hook.Container.ImageSpec.Image}
+		}
+		// SECURITY: A webhook URL may carry a token in its query
+		if hook.WebhookUrl != nil {
+			hook.WebhookUrl = redactURL(*hook.WebhookUrl)
+		}
+	}
+	for _, hook := range safeConfig.Webhooks {
+		if redacted := redactURL(hook.Url); redacted != nil {
+			hook.Url = *redacted
+		} else {
+			hook.Url = ""
+		}
+	}
+	// SECURITY: A presigned workspace URL carries credentials in its query
+	if ws := safeConfig.Workspace; ws != nil && ws.Url != nil {
+		ws.Url = redactURL(*ws.Url)
+	}
 
 	state := &pb.ContainerStatus{
-		ContainerId:  c.state.ContainerId,
-		State:        c.state.State,
-		CreatedAt:    c.state.CreatedAt,
-		StartedAt:    c.state.StartedAt,
-		FinishedAt:   c.state.FinishedAt,
-		ExitCode:     c.state.ExitCode,
-		Pid:          c.state.Pid,
-		Config:       safeConfig,
-		IoStats:      c.state.IoStats,
-		CleanupAfter: c.state.CleanupAfter,
+		ContainerId:       c.state.ContainerId,
+		State:             c.state.State,
+		CreatedAt:         c.state.CreatedAt,
+		StartedAt:         c.state.StartedAt,
+		FinishedAt:        c.state.FinishedAt,
+		ExitCode:          c.state.ExitCode,
+		Pid:               c.state.Pid,
+		Config:            safeConfig,
+		IoStats:           proto.Clone(c.state.IoStats).(*pb.IOStats),
+		CleanupAfter:      c.state.CleanupAfter,
+		TerminationReason: c.state.TerminationReason,
+		Paused:            c.state.Paused,
+		Ready:             c.isReady(),
+		RunnerVersion:     c.state.RunnerVersion,
+		ErrorCode:         c.state.ErrorCode,
+		RunnerPhase:       c.state.RunnerPhase,
+		RunId:             c.state.RunId,
+		Attempt:           c.state.Attempt,
 	}
 	return state
 }
 
 func (c *Container) WriteStdin(data []byte) error {
-	if c.stdinWriter == nil {
-		return fmt.Errorf("stdin not available")
+	c.lifecycle.Activity(time.Now())
+
+	// An empty frame would close stdin
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := c.writeStdinFrames(data); err != nil {
+		return err
+	}
+	c.countIO(streamStdin, len(data))
+	return nil
+}
+
+// Signal sends a signal, named like "SIGHUP" or "HUP", to the container's main
+// process. The isolation-runner reports the outcome as a message event.
+func (c *Container) Signal(name string) error {
+	signal := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(signal, "SIG") {
+		signal = "SIG" + signal
+	}
+	if !forwardableSignals[signal] {
+		return fmt.Errorf("%w: %q", ErrInvalidSignal, name)
+	}
+
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		return ErrNotRunning
+	}
+
+	return c.sendRunnerCommand(map[string]any{
+		"type":   "signal",
+		"signal": signal,
+	})
+}
+
+// Resize sets the size of the container's terminal
+func (c *Container) Resize(rows, cols uint32) error {
+	if !c.Config.GetTty() {
+		return ErrNoTTY
+	}
+
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		return ErrNotRunning
+	}
+	if rows == 0 || cols == 0 {
+		return fmt.Errorf("terminal size must be at least 1x1, got %dx%d", cols, rows)
+	}
+
+	return c.sendRunnerCommand(map[string]any{
+		"type": "resize",
+		"rows": rows,
+		"cols": cols,
+	})
+}
+
+// Pause asks the isolation-runner to freeze the container's processes and waits
+// for it to confirm. Pausing a paused container does nothing.
+func (c *Container) Pause(timeout time.Duration) error {
+	return c.setPaused(true, timeout)
+}
+
+// Unpause resumes a paused container. Unpausing a running one does nothing.
+func (c *Container) Unpause(timeout time.Duration) error {
+	return c.setPaused(false, timeout)
+}
+
+func (c *Container) setPaused(paused bool, timeout time.Duration) error {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+
+	c.stateMu.RLock()
+	state := c.state.State
+	current := c.state.Paused
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		return ErrNotRunning
+	}
+	if current == paused {
+		return nil
+	}
+
+	// Drop any stale result from an earlier request that timed out
+	select {
+	case <-c.pauseCh:
+	default:
+	}
+
+	cmdType := "unpause"
+	if paused {
+		cmdType = "pause"
+	}
+	if err := c.sendRunnerCommand(map[string]any{"type": cmdType}); err != nil {
+		return fmt.Errorf("failed to send %s to isolation-runner: %w", cmdType, err)
+	}
+
+	if err := c.awaitPause(paused, cmdType, timeout); err != nil {
+		return err
+	}
+
+	c.stateMu.Lock()
+	c.state.Paused = paused
+	c.stateMu.Unlock()
+
+	if paused {
+		c.lifecycle.Pause()
+	} else {
+		c.lifecycle.Resume(time.Now())
+	}
+
+	return nil
+}
+
+// pauseResult is the isolation-runner's answer to a pause (paused) or unpause
+type pauseResult struct {
+	paused bool
+	err    error
+}
+
+// awaitPause waits for the runner's answer to a pause or unpause. An answer
+// for the other action is to an earlier request that timed out, and is dropped.
+func (c *Container) awaitPause(paused bool, cmdType string, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case result := <-c.pauseCh:
+			if result.paused == paused {
+				return result.err
+			}
+		case <-deadline:
+			return fmt.Errorf("timeout waiting for isolation-runner to %s container", cmdType)
+		}
+	}
+}
+
+// environment is the workload's environment: its config's variables and the
+// values of its secrets
+func (c *Container) environment() map[string]string {
+	if len(c.secretEnv) == 0 {
+		return c.Config.Env
+	}
+	env := maps.Clone(c.Config.Env)
+	if env == nil {
+		env = make(map[string]string, len(c.secretEnv))
+	}
+	maps.Copy(env, c.secretEnv)
+	return env
+}
+
+// redactURL strips the query and fragment from a URL, or returns nil if it
+// does not parse
+func redactURL(raw string) *string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return proto.String(u.String())
+}
+
+// runtime is the OCI runtime the container is run with
+func (c *Container) runtime() string {
+	if runtime := c.Config.GetRuntime(); runtime != "" {
+		return runtime
+	}
+	return DefaultRuntime
+}
+
+// WaitReady blocks until the isolation-runner reports the container ready. It
+// returns ErrNotRunning if the container exits first and ErrNotReady if ctx
+// ends first.
+func (c *Container) WaitReady(ctx context.Context) error {
+	select {
+	case <-c.ready:
+		return nil
+	case <-c.ctx.Done():
+		// Readiness and exit can be reported together
+		if c.isReady() {
+			return nil
+		}
+		return ErrNotRunning
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrNotReady, ctx.Err())
+	}
+}
+
+func (c *Container) isReady() bool {
+	select {
+	case <-c.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Checkpoint asks the isolation-runner to save the container's state as
+// checkpointID and waits for it to confirm. Unless leaveRunning is set the
+// container stops once its state is saved.
+func (c *Container) Checkpoint(checkpointID string, leaveRunning bool, timeout time.Duration) error {
+	if err := ValidateCheckpointID(checkpointID); err != nil {
+		return err
+	}
+
+	c.checkpointMu.Lock()
+	defer c.checkpointMu.Unlock()
+
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		return ErrNotRunning
+	}
+
+	// Drop any stale result from an earlier request that timed out
+	select {
+	case <-c.checkpointCh:
+	default:
+	}
+
+	if err := c.sendRunnerCommand(map[string]any{
+		"type":          "checkpoint",
+		"checkpoint_id": checkpointID,
+		"leave_running": leaveRunning,
+	}); err != nil {
+		return fmt.Errorf("failed to send checkpoint to isolation-runner: %w", err)
+	}
+
+	// An answer for another checkpoint is to an earlier request that timed out
+	deadline := time.After(timeout)
+	for {
+		select {
+		case result := <-c.checkpointCh:
+			if result.id == checkpointID {
+				return result.err
+			}
+		case <-deadline:
+			return fmt.Errorf("timeout waiting for isolation-runner to checkpoint container")
+		}
+	}
+}
+
+// checkpointResult is the isolation-runner's answer to the checkpoint with id
+type checkpointResult struct {
+	id  string
+	err error
+}
+
+// UpdateResources asks the isolation-runner to apply new CPU/memory limits to the
+// running container and waits for it to confirm. Unset fields keep their current value.
+// Subscribers see the outcome as a container_resources_updated or
+// container_resources_update_failed event, whether the runner or the container fails it.
+func (c *Container) UpdateResources(limits *pb.ResourceLimits, timeout time.Duration) (*pb.ResourceLimits, error) {
+	c.resourceUpdateMu.Lock()
+	defer c.resourceUpdateMu.Unlock()
+
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		c.BroadcastResourceUpdateFailure(ErrNotRunning)
+		return nil, ErrNotRunning
+	}
+
+	// Drop any stale result from an earlier request that timed out
+	select {
+	case <-c.resourceUpdateCh:
+	default:
+	}
+
+	cmd := map[string]any{"type": "update_resources"}
+	if limits.CpuLimit != nil {
+		cmd["cpu_limit"] = *limits.CpuLimit
+	}
+	if limits.MemoryLimit != nil {
+		cmd["memory_limit"] = *limits.MemoryLimit
+	}
+
+	if err := c.sendRunnerCommand(cmd); err != nil {
+		err = fmt.Errorf("failed to send update to isolation-runner: %w", err)
+		c.BroadcastResourceUpdateFailure(err)
+		return nil, err
+	}
+
+	select {
+	case err := <-c.resourceUpdateCh:
+		// The runner has already announced its result
+		if err != nil {
+			return nil, err
+		}
+	case <-time.After(timeout):
+		err := fmt.Errorf("timeout waiting for isolation-runner to apply resource update")
+		c.BroadcastResourceUpdateFailure(err)
+		return nil, err
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	current := c.state.Config.Resources
+	if current == nil {
+		current = &pb.ResourceLimits{}
+		c.state.Config.Resources = current
+	}
+	if limits.CpuLimit != nil {
+		current.CpuLimit = proto.String(*limits.CpuLimit)
+	}
+	if limits.MemoryLimit != nil {
+		current.MemoryLimit = proto.String(*limits.MemoryLimit)
+	}
+
+	return proto.Clone(current).(*pb.ResourceLimits), nil
+}
+
+// BroadcastResourceUpdateFailure announces a resource update that failed
+// before the isolation-runner could report on it, including one refused
+// before it was sent
+func (c *Container) BroadcastResourceUpdateFailure(err error) {
+	msgBytes, _ := json.Marshal(map[string]any{
+		"type":      "container_resources_update_failed",
+		"timestamp": time.Now().Format(time.RFC3339Nano),
+		"data": map[string]any{
+			"container_id": c.ID,
+			"error":        err.Error(),
+		},
+	})
+	c.publishMessage(string(msgBytes))
+}
+
+// UpdateNetworkPolicy asks the isolation-runner to replace the network policy of
+// the running container and waits for it to confirm. The pool network settings
+// (subnet, static IP) and flow logging are fixed at creation and are kept.
+// Subscribers see the outcome as a network_policy_updated or
+// network_policy_update_failed event, whether the runner or the container fails it.
+func (c *Container) UpdateNetworkPolicy(network *pb.NetworkConfig, timeout time.Duration) error {
+	c.networkUpdateMu.Lock()
+	defer c.networkUpdateMu.Unlock()
+
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		c.broadcastNetworkPolicyFailure(ErrNotRunning)
+		return ErrNotRunning
+	}
+
+	// Drop any stale result from an earlier request that timed out
+	select {
+	case <-c.networkUpdateCh:
+	default:
+	}
+
+	cmd := map[string]any{
+		"type":    "update_network_policy",
+		"network": buildNetworkConfig(network),
+	}
+	if err := c.sendRunnerCommand(cmd); err != nil {
+		err = fmt.Errorf("failed to send network policy to isolation-runner: %w", err)
+		c.broadcastNetworkPolicyFailure(err)
+		return err
+	}
+
+	select {
+	case err := <-c.networkUpdateCh:
+		// The runner has already announced its result
+		if err != nil {
+			return err
+		}
+	case <-time.After(timeout):
+		err := fmt.Errorf("timeout waiting for isolation-runner to apply network policy")
+		c.broadcastNetworkPolicyFailure(err)
+		return err
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	updated := proto.Clone(network).(*pb.NetworkConfig)
+	if current := c.state.Config.Network; current != nil {
+		updated.Subnet = current.Subnet
+		updated.StaticIp = current.StaticIp
+		updated.LogNetworkAttempts = current.LogNetworkAttempts
+	} else {
+		updated.Subnet, updated.StaticIp, updated.LogNetworkAttempts = nil, nil, nil
+	}
+	c.state.Config.Network = updated
+
+	return nil
+}
+
+// broadcastNetworkPolicyFailure announces a network policy update that failed
+// before the isolation-runner could report on it
+func (c *Container) broadcastNetworkPolicyFailure(err error) {
+	msgBytes, _ := json.Marshal(map[string]any{
+		"type":      "network_policy_update_failed",
+		"timestamp": time.Now().Format(time.RFC3339Nano),
+		"data": map[string]any{
+			"container_id": c.ID,
+			"error":        err.Error(),
+		},
+	})
+	c.publishMessage(string(msgBytes))
+}
+
+// sendCaptureChunk publishes chunk without waiting on subscribers, as it runs
+// on the goroutine reading the runner's output. Chunks a subscriber fell too
+// far behind to get are counted, and the capture's final chunk reports them,
+// since the pcap stream it received is missing data.
+func (c *Container) sendCaptureChunk(chunk *pb.CaptureChunk) {
+	if chunk.Done {
+		c.captureDropsMu.Lock()
+		dropped := c.captureDrops[chunk.CaptureId]
+		delete(c.captureDrops, chunk.CaptureId)
+		c.captureDropsMu.Unlock()
+
+		if dropped > 0 {
+			log.Printf("Container %s capture %s: dropped %d chunks for a subscriber that fell behind", c.ID, chunk.CaptureId, dropped)
+			if chunk.Error == nil {
+				chunk.Error = proto.String(fmt.Sprintf("%d capture chunks were dropped because the reader fell behind", dropped))
+			}
+		}
+		c.captures.Publish(chunk)
+		return
+	}
+
+	if missed := c.captures.Publish(chunk); missed > 0 {
+		c.captureDropsMu.Lock()
+		c.captureDrops[chunk.CaptureId]++
+		c.captureDropsMu.Unlock()
+	}
+}
+
+// StartCapture asks the isolation-runner to capture the container's packets.
+// Data is delivered on SubscribeCapture under the returned capture ID.
+func (c *Container) StartCapture(filter *string, maxPackets, maxDurationSecs *uint32) (string, error) {
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		return "", ErrNotRunning
+	}
+
+	captureID := strings.ReplaceAll(uuid.New().String(), "-", "")
+
+	cmd := map[string]any{
+		"type":       "start_capture",
+		"capture_id": captureID,
+	}
+	if filter != nil {
+		cmd["filter"] = *filter
+	}
+	if maxPackets != nil {
+		cmd["max_packets"] = *maxPackets
+	}
+	if maxDurationSecs != nil {
+		cmd["max_duration_secs"] = *maxDurationSecs
+	}
+
+	if err := c.sendRunnerCommand(cmd); err != nil {
+		return "", fmt.Errorf("failed to send capture request to isolation-runner: %w", err)
+	}
+
+	return captureID, nil
+}
+
+// StopCapture ends a capture started with StartCapture
+func (c *Container) StopCapture(captureID string) error {
+	if err := c.sendRunnerCommand(map[string]string{
+		"type":       "stop_capture",
+		"capture_id": captureID,
+	}); err != nil {
+		return fmt.Errorf("failed to send stop request to isolation-runner: %w", err)
+	}
+	return nil
+}
+
+func (c *Container) sendExecOutput(output *pb.ExecOutput) {
+	c.execs.Publish(output)
+}
+
+// Exec asks the isolation-runner to run an additional process in the container.
+// Its output is delivered on SubscribeExec under the returned exec ID. If the
+// request cannot be sent, a final ExecOutput carrying the error is delivered too.
+func (c *Container) Exec(req *pb.ExecRequest) (string, error) {
+	execID := req.GetExecId()
+	if execID == "" {
+		execID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+
+	fail := func(err error) (string, error) {
+		c.sendExecOutput(&pb.ExecOutput{
+			ExecId:   execID,
+			Exited:   true,
+			ExitCode: -1,
+			Error:    proto.String(err.Error()),
+		})
+		return execID, err
+	}
+
+	if len(req.Command) == 0 {
+		return fail(fmt.Errorf("exec requires a command"))
+	}
+
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		return fail(ErrNotRunning)
+	}
+
+	cmd := map[string]any{
+		"type":    "exec",
+		"exec_id": execID,
+		"command": req.Command,
+	}
+	if len(req.Env) > 0 {
+		cmd["env"] = req.Env
+	}
+	if req.Workdir != nil {
+		cmd["workdir"] = *req.Workdir
+	}
+	if req.TimeoutSecs != nil {
+		cmd["timeout_secs"] = *req.TimeoutSecs
 	}
 
-	// Encode stdin data as JSON message for isolation-runner
-	// Format: {"type":"stdin","data":"<base64-encoded-data>"}
-	msg := map[string]string{
-		"type": "stdin",
-		"data": base64.StdEncoding.EncodeToString(data),
+	if err := c.sendRunnerCommand(cmd); err != nil {
+		return fail(fmt.Errorf("failed to send exec request to isolation-runner: %w", err))
 	}
 
-	jsonData, err := json.Marshal(msg)
+	return execID, nil
+}
+
+// sendRunnerCommand writes a single newline-delimited JSON command to the isolation-runner's stdin
+func (c *Container) sendRunnerCommand(cmd any) error {
+	if c.stdinWriter == nil {
+		return fmt.Errorf("stdin not available")
+	}
+
+	jsonData, err := json.Marshal(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to marshal stdin message: %w", err)
+		return fmt.Errorf("failed to marshal runner command: %w", err)
 	}
 
+	c.stdinMu.Lock()
+	defer c.stdinMu.Unlock()
+
 	// Write JSON message followed by newline
 	if _, err := c.stdinWriter.Write(jsonData); err != nil {
 		return err
@@ -551,16 +2027,40 @@ func (c *Container) WriteStdin(data []byte) error {
 	return nil
 }
 
-func (c *Container) SubscribeStdout() <-chan []byte {
-	return c.stdoutBroadcast
+// messageHistory is how many of the most recent messages a subscriber can ask
+// for, so the stream that created a container sees those sent before it
+// subscribed
+const messageHistory = 256
+
+// SubscribeMessages returns a channel receiving every runner message and
+// lifecycle event from now on, preceded by the most recent ones when
+// withHistory is set, and a function that unsubscribes. Any number of
+// subscribers each get their own copy; the channel closes with the container.
+func (c *Container) SubscribeMessages(withHistory bool) (<-chan string, func()) {
+	return c.messages.Subscribe(withHistory)
 }
 
-func (c *Container) SubscribeStderr() <-chan []byte {
-	return c.stderrBroadcast
+// publishMessage passes a runner message or lifecycle event to subscribers and
+// records it in the logs
+func (c *Container) publishMessage(msg string) {
+	c.logs.Append(pb.OutputStream_EVENTS, []byte(msg))
+	c.messages.Publish(msg)
 }
 
-func (c *Container) SubscribeMessages() <-chan string {
-	return c.messageBroadcast
+// Logs returns the container's recent output and events
+func (c *Container) Logs() *logs.Ring {
+	return c.logs
+}
+
+// SubscribeCapture is SubscribeMessages for packet capture chunks, from now on
+func (c *Container) SubscribeCapture() (<-chan *pb.CaptureChunk, func()) {
+	return c.captures.Subscribe(false)
+}
+
+// SubscribeExec is SubscribeMessages for the output of exec'd processes, from
+// now on
+func (c *Container) SubscribeExec() (<-chan *pb.ExecOutput, func()) {
+	return c.execs.Subscribe(false)
 }
 
 func (c *Container) Close() {
@@ -569,8 +2069,17 @@ func (c *Container) Close() {
 		if c.stdinWriter != nil {
 			c.stdinWriter.Close()
 		}
-		close(c.stdoutBroadcast)
-		close(c.stderrBroadcast)
-		close(c.messageBroadcast)
+		if c.stdioWriter != nil {
+			c.stdioWriter.Close()
+		}
+		if c.runner != nil {
+			// A runner still running stays with its shim
+			c.runner.Close()
+		}
+		c.closeOutput()
+		c.logs.Close()
+		c.messages.Close()
+		c.captures.Close()
+		c.execs.Close()
 	})
 }
@@ -1,10 +1,26 @@
 package container
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/hub"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/lifecycle"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/logs"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/spool"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestNewContainer(t *testing.T) {
@@ -85,6 +101,39 @@ func TestTerminateWithoutProcess(t *testing.T) {
 	}
 }
 
+func TestTerminateWithReason(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	messages, _ := c.SubscribeMessages(false)
+
+	if err := c.TerminateWithReason("max_lifetime_exceeded", true, 0); err != nil {
+		t.Fatalf("TerminateWithReason failed: %v", err)
+	}
+
+	state := c.GetState()
+	if state.State != pb.ContainerState_TERMINATED {
+		t.Errorf("Expected TERMINATED state, got %v", state.State)
+	}
+	if state.GetTerminationReason() != "max_lifetime_exceeded" {
+		t.Errorf("Expected termination reason max_lifetime_exceeded, got %q", state.GetTerminationReason())
+	}
+
+	select {
+	case msg := <-messages:
+		if !strings.Contains(msg, `"type":"container_terminating"`) || !strings.Contains(msg, `"reason":"max_lifetime_exceeded"`) {
+			t.Errorf("Unexpected terminating event: %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected a container_terminating event")
+	}
+
+	// The first reason sticks
+	c.TerminateWithReason("other", true, 0)
+	if reason := c.GetState().GetTerminationReason(); reason != "max_lifetime_exceeded" {
+		t.Errorf("Expected first reason to be kept, got %q", reason)
+	}
+}
+
 func TestWaitWithoutProcess(t *testing.T) {
 	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
 	c := New("test", config)
@@ -230,33 +279,31 @@ func TestSubscribeChannels(t *testing.T) {
 	c := New("test", config)
 
 	// Subscribe to channels
-	stdoutCh := c.SubscribeStdout()
-	stderrCh := c.SubscribeStderr()
-	msgCh := c.SubscribeMessages()
+	output := c.ReadOutput(0)
+	defer output.Close()
+	msgCh, _ := c.SubscribeMessages(false)
 
-	// Channels should not be nil
-	if stdoutCh == nil {
-		t.Error("Stdout channel is nil")
-	}
-	if stderrCh == nil {
-		t.Error("Stderr channel is nil")
-	}
 	if msgCh == nil {
 		t.Error("Message channel is nil")
 	}
 
-	// Close container - channels should close
+	// Close container - channels should close and output end
 	c.Close()
 
-	// Try to receive - should get closed channel
 	select {
-	case _, ok := <-stdoutCh:
+	case _, ok := <-msgCh:
 		if ok {
-			t.Error("Expected stdout channel to be closed")
+			t.Error("Expected message channel to be closed")
 		}
 	case <-time.After(100 * time.Millisecond):
 		t.Error("Timeout waiting for channel close")
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := output.Next(ctx); err != io.EOF {
+		t.Errorf("Expected output to end with io.EOF, got %v", err)
+	}
 }
 
 func TestWriteStdinWithoutProcess(t *testing.T) {
@@ -270,6 +317,69 @@ func TestWriteStdinWithoutProcess(t *testing.T) {
 	}
 }
 
+// nopWriteCloser lets a buffer stand in for the isolation-runner's stdin frame pipe
+type nopWriteCloser struct{ *bytes.Buffer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestBinaryStdio(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+
+	input := &bytes.Buffer{}
+	c.stdioWriter = nopWriteCloser{input}
+
+	binaryData := []byte{0x00, 0xff, '\n', 0x80}
+	if err := c.WriteStdin(binaryData); err != nil {
+		t.Fatalf("WriteStdin failed: %v", err)
+	}
+	if err := c.CloseStdin(); err != nil {
+		t.Fatalf("CloseStdin failed: %v", err)
+	}
+
+	stream, data, err := readFrame(input)
+	if err != nil || stream != streamStdin || !bytes.Equal(data, binaryData) {
+		t.Fatalf("expected stdin frame with the raw bytes, got stream %d data %v err %v", stream, data, err)
+	}
+	stream, data, err = readFrame(input)
+	if err != nil || stream != streamStdin || len(data) != 0 {
+		t.Fatalf("expected empty stdin frame for close, got stream %d data %v err %v", stream, data, err)
+	}
+
+	// Output frames reach the subscribers unmodified, however long the line
+	output := &bytes.Buffer{}
+	longLine := bytes.Repeat([]byte("x"), maxFrameSize+1)
+	if err := writeFrames(output, streamStdout, binaryData); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFrames(output, streamStderr, longLine); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := c.ReadOutput(0)
+	defer reader.Close()
+	c.outputDone = make(chan struct{})
+	c.readFrames(io.NopCloser(output))
+
+	select {
+	case <-c.outputDone:
+	default:
+		t.Error("outputDone should be closed once the output ends")
+	}
+	ctx := context.Background()
+	if got, _ := reader.Next(ctx); got.Stream != pb.OutputStream_STDOUT || !bytes.Equal(got.Data, binaryData) {
+		t.Errorf("stdout mismatch: %v", got)
+	}
+	var stderr []byte
+	for range 2 {
+		got, _ := reader.Next(ctx)
+		stderr = append(stderr, got.Data...)
+	}
+	if !bytes.Equal(stderr, longLine) {
+		t.Errorf("stderr was not delivered intact: got %d bytes", len(stderr))
+	}
+}
+
 func TestCleanupFlag(t *testing.T) {
 	cleanup := true
 	config := &pb.ContainerConfig{
@@ -326,3 +436,1301 @@ func TestNetworkConfig(t *testing.T) {
 		t.Error("DNS servers mismatch")
 	}
 }
+
+func TestWorkspaceConfig(t *testing.T) {
+	workspaceURL := "https://bucket.example.com/src.tar.gz?X-Amz-Signature=secret"
+	config := &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{
+			Image: "test",
+		},
+		Workspace: &pb.Workspace{Url: &workspaceURL},
+	}
+	c := New("test", config)
+
+	runnerConfig := c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["container"].(map[string]any)
+	ws, ok := runnerConfig["workspace"].(map[string]any)
+	if !ok {
+		t.Fatal("workspace missing from runner config")
+	}
+	if ws["url"] != workspaceURL {
+		t.Errorf("expected the runner to get the full url, got %v", ws["url"])
+	}
+
+	state := c.GetState()
+	if state.Config.Workspace == nil || state.Config.Workspace.Url == nil {
+		t.Fatal("Workspace should be set")
+	}
+	if strings.Contains(*state.Config.Workspace.Url, "secret") {
+		t.Errorf("workspace url query leaked into state: %s", *state.Config.Workspace.Url)
+	}
+	if *config.Workspace.Url != workspaceURL {
+		t.Error("GetState modified the container's config")
+	}
+}
+
+func TestSecretEnvConfig(t *testing.T) {
+	config := &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "test"},
+		Env:       map[string]string{"MODE": "prod"},
+		SecretEnv: map[string]*pb.SecretRef{"DB_PASSWORD": {Name: "db/password"}},
+	}
+	c := New("test", config)
+
+	c.secretEnv = map[string]string{"DB_PASSWORD": "hunter2"}
+	runnerConfig := c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["container"].(map[string]any)
+	env := runnerConfig["environment"].(map[string]string)
+	if env["DB_PASSWORD"] != "hunter2" || env["MODE"] != "prod" {
+		t.Errorf("expected the runner to get the secret and plain env, got %v", env)
+	}
+	if _, ok := config.Env["DB_PASSWORD"]; ok {
+		t.Error("buildConfig wrote the secret into the container's config")
+	}
+
+	state := c.GetState()
+	if state.Config.Env["DB_PASSWORD"] != "" || state.Config.SecretEnv["DB_PASSWORD"].GetName() != "db/password" {
+		t.Errorf("expected state to show only the secret reference, got env %v", state.Config.Env)
+	}
+}
+
+func TestAuxContainerConfig(t *testing.T) {
+	config := &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "test"},
+		Sidecars: []*pb.AuxContainer{{
+			Name: "db",
+			ImageSpec: &pb.ImageSpec{
+				Image: "postgres:16",
+				Auth:  &pb.ImageSpec_BasicAuth{BasicAuth: &pb.BasicAuth{Username: "user", Password: "secret"}},
+			},
+			Env:       map[string]string{"POSTGRES_PASSWORD": "test"},
+			Resources: &pb.ResourceLimits{MemoryLimit: proto.String("256m")},
+		}},
+		InitContainers: []*pb.AuxContainer{{
+			Name:      "migrate",
+			ImageSpec: &pb.ImageSpec{Image: "migrate/migrate"},
+			Args:      []string{"up"},
+		}},
+	}
+	c := New("test", config)
+
+	runnerConfig := c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["container"].(map[string]any)
+	sidecars, ok := runnerConfig["sidecars"].([]map[string]any)
+	if !ok || len(sidecars) != 1 {
+		t.Fatalf("expected one sidecar in runner config, got %v", runnerConfig["sidecars"])
+	}
+	if sidecars[0]["name"] != "db" || sidecars[0]["memory_limit"] != "256m" {
+		t.Errorf("unexpected sidecar config %v", sidecars[0])
+	}
+	auth := sidecars[0]["image_spec"].(map[string]any)["auth"].(map[string]any)
+	if auth["password"] != "secret" {
+		t.Errorf("expected the runner to get the sidecar's credentials, got %v", auth)
+	}
+
+	initContainers, ok := runnerConfig["init_containers"].([]map[string]any)
+	if !ok || len(initContainers) != 1 || initContainers[0]["name"] != "migrate" {
+		t.Errorf("expected the migrate init container in runner config, got %v", runnerConfig["init_containers"])
+	}
+
+	state := c.GetState()
+	if state.Config.Sidecars[0].ImageSpec.GetBasicAuth() != nil {
+		t.Error("sidecar credentials leaked into state")
+	}
+	if config.Sidecars[0].ImageSpec.GetBasicAuth() == nil {
+		t.Error("GetState modified the container's config")
+	}
+}
+
+func TestPostExitHookConfig(t *testing.T) {
+	webhookURL := "https://hooks.example.com/exit?token=secret"
+	config := &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "test"},
+		PostExitHooks: []*pb.PostExitHook{
+			{
+				Name: "collect-logs",
+				Container: &pb.AuxContainer{
+					ImageSpec: &pb.ImageSpec{
+						Image: "collector",
+						Auth:  &pb.ImageSpec_BasicAuth{BasicAuth: &pb.BasicAuth{Username: "user", Password: "secret"}},
+					},
+				},
+				TimeoutSecs: proto.Uint32(60),
+			},
+			{Name: "notify", WebhookUrl: proto.String(webhookURL)},
+		},
+		Webhooks: []*pb.Webhook{{Url: webhookURL}},
+	}
+	c := New("test", config)
+
+	runnerConfig := c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["container"].(map[string]any)
+	hooks, ok := runnerConfig["post_exit_hooks"].([]map[string]any)
+	if !ok || len(hooks) != 2 {
+		t.Fatalf("expected two hooks in runner config, got %v", runnerConfig["post_exit_hooks"])
+	}
+	container := hooks[0]["container"].(map[string]any)
+	if container["name"] != "collect-logs" || hooks[0]["timeout_secs"] != uint32(60) {
+		t.Errorf("unexpected container hook config %v", hooks[0])
+	}
+	if hooks[1]["webhook_url"] != webhookURL || hooks[1]["container"] != nil {
+		t.Errorf("unexpected webhook hook config %v", hooks[1])
+	}
+
+	state := c.GetState()
+	if state.Config.PostExitHooks[0].Container.ImageSpec.GetBasicAuth() != nil {
+		t.Error("hook container credentials leaked into state")
+	}
+	if strings.Contains(state.Config.PostExitHooks[1].GetWebhookUrl(), "secret") {
+		t.Errorf("webhook url query leaked into state: %s", state.Config.PostExitHooks[1].GetWebhookUrl())
+	}
+	if url := state.Config.Webhooks[0].Url; url != "https://hooks.example.com/exit" {
+		t.Errorf("webhook url query leaked into state: %s", url)
+	}
+	if config.PostExitHooks[1].GetWebhookUrl() != webhookURL || config.Webhooks[0].Url != webhookURL {
+		t.Error("GetState modified the container's config")
+	}
+}
+
+// runnerStub answers each command the container sends to the isolation-runner
+type runnerStub func(cmd map[string]any)
+
+func (r runnerStub) Write(p []byte) (int, error) {
+	var cmd map[string]any
+	if json.Unmarshal(p, &cmd) == nil {
+		r(cmd)
+	}
+	return len(p), nil
+}
+
+func (r runnerStub) Close() error { return nil }
+
+// slowRunnerStub answers each command with reply after a short delay, as a
+// runner applying it would, and fails t if a command arrives while another
+// is still being answered
+func slowRunnerStub(t *testing.T, c *Container, reply func(cmd map[string]any) map[string]any) runnerStub {
+	var inFlight atomic.Bool
+	return func(cmd map[string]any) {
+		if !inFlight.CompareAndSwap(false, true) {
+			t.Errorf("%v sent while another command was waiting on the runner", cmd["type"])
+		}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Store(false)
+			c.handleJSONMessage(reply(cmd))
+		}()
+	}
+}
+
+func TestResize(t *testing.T) {
+	c := New("test", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+	c.state.State = pb.ContainerState_RUNNING
+	if err := c.Resize(24, 80); !errors.Is(err, ErrNoTTY) {
+		t.Fatalf("Expected ErrNoTTY without a tty, got %v", err)
+	}
+
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}, Tty: proto.Bool(true)}
+	c = New("test", config)
+
+	tty := c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["execution"].(map[string]any)["tty"]
+	if tty != true {
+		t.Errorf("expected tty in runner config, got %v", tty)
+	}
+
+	if err := c.Resize(24, 80); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	var sent map[string]any
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		sent = cmd
+	})
+
+	if err := c.Resize(0, 80); err == nil {
+		t.Error("Expected an error for a zero-row terminal")
+	}
+	if err := c.Resize(40, 120); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	if sent["type"] != "resize" || sent["rows"] != float64(40) || sent["cols"] != float64(120) {
+		t.Errorf("unexpected runner command: %v", sent)
+	}
+}
+
+func TestUpdateNetworkPolicy(t *testing.T) {
+	subnet := "10.20.0.0/24"
+	config := &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "test"},
+		Network: &pb.NetworkConfig{
+			DefaultPolicy: proto.String("deny"),
+			Subnet:        &subnet,
+		},
+	}
+	c := New("test", config)
+
+	update := &pb.NetworkConfig{
+		DefaultPolicy: proto.String("deny"),
+		Rules:         []*pb.NetworkRule{{Action: "allow", Destination: proto.String("1.1.1.1/32")}},
+		Subnet:        proto.String("10.99.0.0/24"),
+	}
+
+	if err := c.UpdateNetworkPolicy(update, time.Second); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	var sent map[string]any
+	reply := map[string]any{"type": "network_policy_updated"}
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		sent = cmd
+		c.handleJSONMessage(reply)
+	})
+
+	if err := c.UpdateNetworkPolicy(update, time.Second); err != nil {
+		t.Fatalf("UpdateNetworkPolicy failed: %v", err)
+	}
+	if sent["type"] != "update_network_policy" {
+		t.Errorf("Expected update_network_policy command, got %v", sent["type"])
+	}
+	whitelist := sent["network"].(map[string]any)["whitelist"].([]any)
+	if len(whitelist) != 1 || whitelist[0].(map[string]any)["cidr"] != "1.1.1.1/32" {
+		t.Errorf("Unexpected whitelist sent to runner: %v", whitelist)
+	}
+
+	network := c.GetState().Config.Network
+	if len(network.Rules) != 1 {
+		t.Errorf("Expected the new rules in state, got %v", network.Rules)
+	}
+	if network.GetSubnet() != subnet {
+		t.Errorf("Expected subnet %s to be kept, got %s", subnet, network.GetSubnet())
+	}
+
+	reply = map[string]any{
+		"type": "network_policy_update_failed",
+		"data": map[string]any{"error": "bastion error: invalid CIDR"},
+	}
+	err := c.UpdateNetworkPolicy(&pb.NetworkConfig{}, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "invalid CIDR") {
+		t.Errorf("Expected runner failure to be returned, got %v", err)
+	}
+	if len(c.GetState().Config.Network.Rules) != 1 {
+		t.Error("Expected state to be unchanged after a failed update")
+	}
+}
+
+func TestUpdateNetworkPolicyOneAtATime(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	c.state.State = pb.ContainerState_RUNNING
+
+	var last string
+	c.stdinWriter = slowRunnerStub(t, c, func(cmd map[string]any) map[string]any {
+		last = cmd["network"].(map[string]any)["default_policy"].(string)
+		return map[string]any{"type": "network_policy_updated"}
+	})
+
+	var wg sync.WaitGroup
+	for _, policy := range []string{"allow", "deny", "allow", "deny"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.UpdateNetworkPolicy(&pb.NetworkConfig{DefaultPolicy: proto.String(policy)}, time.Second); err != nil {
+				t.Errorf("UpdateNetworkPolicy(%s) failed: %v", policy, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The state ends with the policy the runner applied last
+	if got := c.GetState().Config.Network.GetDefaultPolicy(); got != last {
+		t.Errorf("default policy = %s, want %s as last applied", got, last)
+	}
+}
+
+func TestUpdateResources(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	messages, _ := c.SubscribeMessages(false)
+	limits := &pb.ResourceLimits{MemoryLimit: proto.String("4g")}
+
+	// A Run stream learns of failures the runner never saw
+	if _, err := c.UpdateResources(limits, time.Second); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+	if msg := <-messages; !strings.Contains(msg, `"type":"container_resources_update_failed"`) {
+		t.Errorf("Expected a failure event, got %s", msg)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	var sent map[string]any
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		sent = cmd
+		c.handleJSONMessage(map[string]any{"type": "container_resources_updated"})
+	})
+
+	updated, err := c.UpdateResources(limits, time.Second)
+	if err != nil {
+		t.Fatalf("UpdateResources failed: %v", err)
+	}
+	if sent["type"] != "update_resources" || sent["memory_limit"] != "4g" || sent["cpu_limit"] != nil {
+		t.Errorf("Unexpected runner command: %v", sent)
+	}
+	if updated.GetMemoryLimit() != "4g" || c.GetState().Config.Resources.GetMemoryLimit() != "4g" {
+		t.Errorf("Expected the new memory limit, got %v", updated)
+	}
+	if msg := <-messages; !strings.Contains(msg, `"type":"container_resources_updated"`) {
+		t.Errorf("Expected the runner's event, got %s", msg)
+	}
+
+	c.stdinWriter = runnerStub(func(map[string]any) {})
+	if _, err := c.UpdateResources(limits, 10*time.Millisecond); err == nil {
+		t.Error("Expected a timeout without an answer from the runner")
+	}
+	if msg := <-messages; !strings.Contains(msg, `"type":"container_resources_update_failed"`) {
+		t.Errorf("Expected a failure event after the timeout, got %s", msg)
+	}
+}
+
+func TestUpdateResourcesOneAtATime(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	c.state.State = pb.ContainerState_RUNNING
+
+	var last string
+	c.stdinWriter = slowRunnerStub(t, c, func(cmd map[string]any) map[string]any {
+		last = cmd["memory_limit"].(string)
+		return map[string]any{"type": "container_resources_updated"}
+	})
+
+	var wg sync.WaitGroup
+	for _, memory := range []string{"1g", "2g", "3g"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.UpdateResources(&pb.ResourceLimits{MemoryLimit: proto.String(memory)}, time.Second); err != nil {
+				t.Errorf("UpdateResources(%s) failed: %v", memory, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The state ends with the limit the runner applied last
+	if got := c.GetState().Config.Resources.GetMemoryLimit(); got != last {
+		t.Errorf("memory limit = %s, want %s as last applied", got, last)
+	}
+}
+
+func TestExtendTimeout(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := NewWithTimeouts("test", config, lifecycle.Timeouts{Run: time.Hour, MaxRunExtension: time.Hour})
+	messages, _ := c.SubscribeMessages(false)
+
+	if _, _, err := c.ExtendTimeout(time.Minute); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	started := time.Now()
+	if _, err := c.lifecycle.Started(started); err != nil {
+		t.Fatal(err)
+	}
+	deadline, total, err := c.ExtendTimeout(30 * time.Minute)
+	if err != nil {
+		t.Fatalf("ExtendTimeout failed: %v", err)
+	}
+	if !deadline.Equal(started.Add(90*time.Minute)) || total != 30*time.Minute {
+		t.Errorf("ExtendTimeout() = %v, %v; want 90m after the start and 30m in total", deadline, total)
+	}
+	if msg := <-messages; !strings.Contains(msg, `"type":"timeout_extended"`) || !strings.Contains(msg, `"extended_by_secs":1800`) {
+		t.Errorf("Unexpected timeout_extended event: %s", msg)
+	}
+
+	if _, _, err := c.ExtendTimeout(time.Hour); !errors.Is(err, lifecycle.ErrExtensionLimit) {
+		t.Errorf("Expected ErrExtensionLimit, got %v", err)
+	}
+}
+
+func TestSignal(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+
+	if err := c.Signal("SIGHUP"); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	var sent map[string]any
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		sent = cmd
+	})
+
+	for _, name := range []string{"SIGSEGV", "FOO", "", "9"} {
+		if err := c.Signal(name); !errors.Is(err, ErrInvalidSignal) {
+			t.Errorf("Signal(%q): expected ErrInvalidSignal, got %v", name, err)
+		}
+	}
+
+	if err := c.Signal(" usr1"); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	if sent["type"] != "signal" || sent["signal"] != "SIGUSR1" {
+		t.Errorf("unexpected runner command: %v", sent)
+	}
+}
+
+func TestPause(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+
+	if err := c.Pause(time.Second); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	var sent []string
+	reply := map[string]any{"type": "container_paused"}
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		sent = append(sent, cmd["type"].(string))
+		c.handleJSONMessage(reply)
+	})
+
+	if err := c.Pause(time.Second); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if !c.GetState().Paused {
+		t.Error("Expected state to be paused")
+	}
+	if err := c.Pause(time.Second); err != nil {
+		t.Fatalf("Pausing a paused container failed: %v", err)
+	}
+	if len(sent) != 1 || sent[0] != "pause" {
+		t.Errorf("Expected a single pause command, got %v", sent)
+	}
+
+	reply = map[string]any{
+		"type": "container_resume_failed",
+		"data": map[string]any{"error": "container is not paused"},
+	}
+	if err := c.Unpause(time.Second); err == nil || !strings.Contains(err.Error(), "container is not paused") {
+		t.Errorf("Expected runner failure to be returned, got %v", err)
+	}
+	if !c.GetState().Paused {
+		t.Error("Expected state to stay paused after a failed unpause")
+	}
+
+	reply = map[string]any{"type": "container_resumed"}
+	if err := c.Unpause(time.Second); err != nil {
+		t.Fatalf("Unpause failed: %v", err)
+	}
+	if c.GetState().Paused {
+		t.Error("Expected state to be running again")
+	}
+}
+
+func TestPauseOneAtATime(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	c.state.State = pb.ContainerState_RUNNING
+
+	var frozen atomic.Bool
+	c.stdinWriter = slowRunnerStub(t, c, func(cmd map[string]any) map[string]any {
+		if cmd["type"] == "pause" {
+			frozen.Store(true)
+			return map[string]any{"type": "container_paused"}
+		}
+		frozen.Store(false)
+		return map[string]any{"type": "container_resumed"}
+	})
+
+	var wg sync.WaitGroup
+	for i := range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				err = c.Pause(time.Second)
+			} else {
+				err = c.Unpause(time.Second)
+			}
+			if err != nil {
+				t.Errorf("call %d failed: %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.GetState().Paused != frozen.Load() {
+		t.Errorf("Paused = %v, but the runner left the container paused = %v", c.GetState().Paused, frozen.Load())
+	}
+
+	// The answer to a pause that timed out, arriving late, is not taken for
+	// an unpause's
+	c.state.Paused = true
+	c.stdinWriter = runnerStub(func(map[string]any) {
+		go func() {
+			c.handleJSONMessage(map[string]any{
+				"type": "container_pause_failed",
+				"data": map[string]any{"error": "late"},
+			})
+			time.Sleep(10 * time.Millisecond)
+			c.handleJSONMessage(map[string]any{"type": "container_resumed"})
+		}()
+	})
+	if err := c.Unpause(time.Second); err != nil || c.GetState().Paused {
+		t.Errorf("Unpause() = %v, paused = %v; want it resumed", err, c.GetState().Paused)
+	}
+}
+
+func TestContainerStats(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+
+	execution := func() map[string]any {
+		return c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["execution"].(map[string]any)
+	}
+	if got := execution()["stats_interval_secs"]; got != uint32(10) {
+		t.Errorf("Expected the default stats interval, got %v", got)
+	}
+	config.StatsIntervalSecs = proto.Uint32(0)
+	if got := execution()["stats_interval_secs"]; got != uint32(0) {
+		t.Errorf("Expected stats to be disabled, got %v", got)
+	}
+
+	if c.GetState().IoStats.CpuPercent != nil {
+		t.Error("Expected no CPU figure before the first sample")
+	}
+
+	c.handleJSONMessage(map[string]any{
+		"type":      "container_stats",
+		"timestamp": "2026-01-02T03:04:05Z",
+		"data": map[string]any{
+			"cpu_percent":        42.5,
+			"memory_usage_bytes": float64(200 << 20),
+			"memory_limit_bytes": float64(512 << 20),
+			"net_rx_bytes":       float64(1024),
+			"net_tx_bytes":       float64(256),
+			"block_read_bytes":   float64(4096),
+			"block_write_bytes":  float64(8192),
+		},
+	})
+
+	c.stdioWriter = nopWriteCloser{&bytes.Buffer{}}
+	if err := c.WriteStdin([]byte("hello")); err != nil {
+		t.Fatalf("WriteStdin failed: %v", err)
+	}
+	output := &bytes.Buffer{}
+	if err := writeFrames(output, streamStdout, []byte("out")); err != nil {
+		t.Fatal(err)
+	}
+	c.outputDone = make(chan struct{})
+	c.readFrames(io.NopCloser(output))
+
+	stats := c.GetState().IoStats
+	if stats.GetCpuPercent() != 42.5 || stats.MemoryUsageBytes != 200<<20 || stats.MemoryLimitBytes != 512<<20 {
+		t.Errorf("Unexpected CPU/memory stats: %v", stats)
+	}
+	if stats.NetRxBytes != 1024 || stats.NetTxBytes != 256 || stats.BlockReadBytes != 4096 || stats.BlockWriteBytes != 8192 {
+		t.Errorf("Unexpected IO stats: %v", stats)
+	}
+	if stats.GetSampledAt() != "2026-01-02T03:04:05Z" {
+		t.Errorf("Expected the sample time, got %q", stats.GetSampledAt())
+	}
+	if stats.StdinBytes != 5 || stats.StdoutBytes != 3 || stats.StderrBytes != 0 {
+		t.Errorf("Unexpected stdio byte counts: %v", stats)
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	config := &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "test"},
+		ReadinessProbe: &pb.ReadinessProbe{
+			Port:     proto.Uint32(8080),
+			HttpPath: proto.String("/healthz"),
+		},
+	}
+	c := New("test", config)
+
+	runnerConfig := c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["container"].(map[string]any)
+	probe, ok := runnerConfig["readiness_probe"].(map[string]any)
+	if !ok || probe["port"] != uint32(8080) || probe["http_path"] != "/healthz" {
+		t.Errorf("Expected the readiness probe in the runner config, got %v", runnerConfig["readiness_probe"])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.WaitReady(ctx); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("Expected ErrNotReady before container_ready, got %v", err)
+	}
+	if c.GetState().Ready {
+		t.Error("Expected state not to be ready")
+	}
+
+	// A failing probe is forwarded but does not make the container ready
+	messages, _ := c.SubscribeMessages(false)
+	c.handleJSONMessage(map[string]any{"type": "readiness_probe_failed", "data": map[string]any{"error": "http probe: status 503"}})
+	if msg := <-messages; !strings.Contains(msg, "readiness_probe_failed") {
+		t.Errorf("Expected the probe failure to be forwarded, got %s", msg)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.WaitReady(context.Background()) }()
+	c.handleJSONMessage(map[string]any{"type": "container_ready"})
+	if err := <-done; err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+	if !c.GetState().Ready {
+		t.Error("Expected state to be ready")
+	}
+	// Readiness is reported once but may be waited on any number of times
+	c.handleJSONMessage(map[string]any{"type": "container_ready"})
+	if err := c.WaitReady(context.Background()); err != nil {
+		t.Fatalf("Second WaitReady failed: %v", err)
+	}
+
+	exited := New("exited", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+	exited.cancel()
+	if err := exited.WaitReady(context.Background()); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning for a container that exited first, got %v", err)
+	}
+}
+
+func TestSchemaHandshake(t *testing.T) {
+	c := New("test", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+
+	if v := c.buildConfig()["schema_version"]; v != ConfigSchemaVersion {
+		t.Errorf("expected schema_version %d in runner config, got %v", ConfigSchemaVersion, v)
+	}
+
+	messages, _ := c.SubscribeMessages(false)
+	c.handleJSONMessage(map[string]any{
+		"type": "runner_hello",
+		"data": map[string]any{"version": "1.2.3", "schema_versions": []any{float64(1)}},
+	})
+	if msg := <-messages; !strings.Contains(msg, "runner_hello") {
+		t.Errorf("Expected runner_hello to be forwarded, got %s", msg)
+	}
+	if v := c.GetState().GetRunnerVersion(); v != "1.2.3" {
+		t.Errorf("Expected runner version 1.2.3, got %q", v)
+	}
+
+	c.handleJSONMessage(map[string]any{
+		"type": "config_rejected",
+		"data": map[string]any{"code": "unsupported_schema_version", "schema_version": float64(2)},
+	})
+	if msg := <-messages; !strings.Contains(msg, "unsupported_schema_version") {
+		t.Errorf("Expected config_rejected to be forwarded, got %s", msg)
+	}
+	if reason := c.GetState().GetTerminationReason(); reason != ReasonConfigRejected {
+		t.Errorf("Expected reason %s, got %q", ReasonConfigRejected, reason)
+	}
+}
+
+func TestRunID(t *testing.T) {
+	c := New("test", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+	other := New("other", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+
+	runID := c.RunID()
+	if !strings.HasPrefix(runID, "run_") || runID == other.RunID() {
+		t.Errorf("Expected distinct run IDs, got %q and %q", runID, other.RunID())
+	}
+	if got := c.buildConfig()["config"].(map[string]any)["run_id"]; got != runID {
+		t.Errorf("Expected run_id %s in runner config, got %v", runID, got)
+	}
+	if got := c.GetState().GetRunId(); got != runID {
+		t.Errorf("Expected run ID %s in status, got %q", runID, got)
+	}
+}
+
+func TestRunnerLogging(t *testing.T) {
+	c := New("test", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+	logging := func() map[string]any {
+		return c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["logging"].(map[string]any)
+	}
+
+	if _, ok := logging()["log_file"]; ok {
+		t.Error("Expected no log_file unless the manager sets one")
+	}
+	c.SetRunnerLogFile("/var/log/holopod/test.log")
+	if got := logging()["log_file"]; got != "/var/log/holopod/test.log" {
+		t.Errorf("Expected log_file in runner config, got %v", got)
+	}
+
+	if got := logging()["log_level"]; got != "info" {
+		t.Errorf("Expected log_level info by default, got %v", got)
+	}
+	c.SetRunnerLogLevel("warning")
+	if got := logging()["log_level"]; got != "warning" {
+		t.Errorf("Expected log_level warning, got %v", got)
+	}
+}
+
+func TestQuietOutputConfig(t *testing.T) {
+	for _, quiet := range []bool{false, true} {
+		c := New("test", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}, QuietOutput: quiet})
+		execution := c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["execution"].(map[string]any)
+		if execution["quiet"] != quiet {
+			t.Errorf("Expected quiet %v in runner config, got %v", quiet, execution["quiet"])
+		}
+	}
+}
+
+func TestExitErrorCode(t *testing.T) {
+	c := New("test", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+
+	c.handleJSONMessage(map[string]any{
+		"type":       "container_exited",
+		"exit_code":  float64(125),
+		"error_code": "IMAGE_PULL_FAILED",
+	})
+	if code := c.GetState().GetErrorCode(); code != "IMAGE_PULL_FAILED" {
+		t.Errorf("Expected error code IMAGE_PULL_FAILED, got %q", code)
+	}
+
+	oom := New("oom", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+	oom.handleJSONMessage(map[string]any{
+		"type": "container_exited",
+		"data": map[string]any{"container_id": "abc", "exit_code": float64(137), "error_code": "OOM"},
+	})
+	if code := oom.GetState().GetErrorCode(); code != "OOM" {
+		t.Errorf("Expected error code OOM from the event data, got %q", code)
+	}
+
+	clean := New("clean", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}})
+	clean.handleJSONMessage(map[string]any{"type": "container_exited", "exit_code": float64(0)})
+	if clean.GetState().ErrorCode != nil {
+		t.Errorf("Expected no error code for a clean exit, got %q", clean.GetState().GetErrorCode())
+	}
+}
+
+func TestRunnerHeartbeat(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := NewWithTimeouts("test", config, lifecycle.Timeouts{Runner: time.Minute})
+	messages, _ := c.SubscribeMessages(false)
+
+	if _, ok := c.AdvanceLifecycle(time.Now().Add(time.Hour)); ok {
+		t.Fatal("expected no runner timeout before the first heartbeat")
+	}
+
+	c.handleJSONMessage(map[string]any{
+		"type": "runner_heartbeat",
+		"data": map[string]any{"phase": "pulling", "uptime_secs": float64(12)},
+	})
+	if msg := <-messages; !strings.Contains(msg, "runner_heartbeat") {
+		t.Errorf("Expected runner_heartbeat to be forwarded, got %s", msg)
+	}
+	if phase := c.GetState().GetRunnerPhase(); phase != "pulling" {
+		t.Errorf("Expected runner phase pulling, got %q", phase)
+	}
+
+	tr, ok := c.AdvanceLifecycle(time.Now().Add(2 * time.Minute))
+	if !ok || tr.Reason != lifecycle.ReasonRunnerUnresponsive {
+		t.Errorf("Expected %s once heartbeats stop, got %+v", lifecycle.ReasonRunnerUnresponsive, tr)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := NewWithTimeouts("test", config, lifecycle.Timeouts{Idle: time.Minute, IdleSuspend: true})
+	messages, _ := c.SubscribeMessages(false)
+
+	// container_started is forwarded, followed by the lifecycle transition
+	c.handleJSONMessage(map[string]any{"type": "container_started"})
+	<-messages
+	<-messages
+	c.state.State = pb.ContainerState_RUNNING
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		c.handleJSONMessage(map[string]any{"type": "container_paused"})
+	})
+
+	// Busy CPU counts as activity; a quiet sample does not
+	start, _ := c.lifecycle.Deadline(lifecycle.TimerIdle)
+	time.Sleep(10 * time.Millisecond)
+	c.recordStats(map[string]any{"cpu_percent": 0.2}, nil)
+	if deadline, _ := c.lifecycle.Deadline(lifecycle.TimerIdle); !deadline.Equal(start) {
+		t.Error("Expected a quiet stats sample not to re-arm the idle timer")
+	}
+	c.recordStats(map[string]any{"cpu_percent": 85.0}, nil)
+	deadline, _ := c.lifecycle.Deadline(lifecycle.TimerIdle)
+	if !deadline.After(start) {
+		t.Error("Expected a busy stats sample to re-arm the idle timer")
+	}
+
+	tr, ok := c.AdvanceLifecycle(deadline)
+	if !ok || tr.Reason != lifecycle.ReasonIdleSuspend || tr.To != lifecycle.PhaseRunning {
+		t.Fatalf("Expected an idle suspend, got %+v", tr)
+	}
+	if msg := <-messages; !strings.Contains(msg, `"container_idle_timeout"`) || !strings.Contains(msg, `"action":"pause"`) {
+		t.Errorf("Expected a container_idle_timeout event, got %s", msg)
+	}
+
+	if err := c.SuspendIdle(time.Second); err != nil {
+		t.Fatalf("SuspendIdle failed: %v", err)
+	}
+	if !c.GetState().Paused {
+		t.Error("Expected the idle container to be paused")
+	}
+}
+
+func TestCPUTimeLimit(t *testing.T) {
+	config := &pb.ContainerConfig{
+		ImageSpec:         &pb.ImageSpec{Image: "test"},
+		StatsIntervalSecs: proto.Uint32(0),
+		CpuTimeLimitSecs:  proto.Uint32(30),
+	}
+	c := New("test", config)
+
+	// The limit is enforced from stats samples, so they cannot be turned off
+	if got := c.statsInterval(); got != uint32(DefaultStatsInterval/time.Second) {
+		t.Errorf("Expected stats to stay on under a CPU time limit, got interval %d", got)
+	}
+
+	c.recordStats(map[string]any{"cpu_time_secs": 29.5}, nil)
+	if c.GetState().TerminationReason != nil {
+		t.Fatal("Expected the container to keep running under its limit")
+	}
+
+	c.recordStats(map[string]any{"cpu_time_secs": 30.2}, nil)
+	deadline := time.Now().Add(2 * time.Second)
+	for c.GetState().State != pb.ContainerState_TERMINATED && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	state := c.GetState()
+	if state.GetTerminationReason() != ReasonCPUTimeLimitExceeded {
+		t.Errorf("Expected reason %s, got %q", ReasonCPUTimeLimitExceeded, state.GetTerminationReason())
+	}
+	if state.IoStats.GetCpuTimeSecs() != 30.2 {
+		t.Errorf("Expected CPU time in IoStats, got %v", state.IoStats.GetCpuTimeSecs())
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	config := &pb.ContainerConfig{
+		ImageSpec:   &pb.ImageSpec{Image: "test"},
+		RestoreFrom: proto.String("warm-python"),
+	}
+	c := New("test", config)
+
+	runnerConfig := c.buildConfig()["config"].(map[string]any)["config"].(map[string]any)["container"].(map[string]any)
+	if runnerConfig["restore_from"] != "warm-python" {
+		t.Errorf("Expected restore_from in runner config, got %v", runnerConfig["restore_from"])
+	}
+
+	if err := c.Checkpoint("warm-python", false, time.Second); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	var sent map[string]any
+	reply := map[string]any{"type": "container_checkpointed", "data": map[string]any{"checkpoint_id": "warm-python"}}
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		sent = cmd
+		c.handleJSONMessage(reply)
+	})
+
+	for _, id := range []string{"", "../escape", ".hidden", "a/b"} {
+		if err := c.Checkpoint(id, false, time.Second); !errors.Is(err, ErrInvalidCheckpoint) {
+			t.Errorf("Checkpoint(%q): expected ErrInvalidCheckpoint, got %v", id, err)
+		}
+	}
+	if sent != nil {
+		t.Fatalf("Expected invalid checkpoint IDs not to reach the runner, got %v", sent)
+	}
+
+	if err := c.Checkpoint("warm-python", true, time.Second); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	if sent["type"] != "checkpoint" || sent["checkpoint_id"] != "warm-python" || sent["leave_running"] != true {
+		t.Errorf("Unexpected checkpoint command: %v", sent)
+	}
+
+	reply = map[string]any{
+		"type": "container_checkpoint_failed",
+		"data": map[string]any{"checkpoint_id": "warm-python", "error": "checkpoint already exists"},
+	}
+	if err := c.Checkpoint("warm-python", false, time.Second); err == nil || !strings.Contains(err.Error(), "checkpoint already exists") {
+		t.Errorf("Expected runner failure to be returned, got %v", err)
+	}
+
+	// The answer to another checkpoint that timed out, arriving late, is not
+	// taken for this one's
+	c.stdinWriter = runnerStub(func(map[string]any) {
+		go func() {
+			c.handleJSONMessage(map[string]any{
+				"type": "container_checkpoint_failed",
+				"data": map[string]any{"checkpoint_id": "warm-node", "error": "late"},
+			})
+			time.Sleep(10 * time.Millisecond)
+			c.handleJSONMessage(map[string]any{"type": "container_checkpointed", "data": map[string]any{"checkpoint_id": "warm-go"}})
+		}()
+	})
+	if err := c.Checkpoint("warm-go", true, time.Second); err != nil {
+		t.Errorf("Checkpoint(warm-go) = %v, want the answer for warm-go", err)
+	}
+
+	// Concurrent checkpoints each get their own answer
+	c.stdinWriter = slowRunnerStub(t, c, func(cmd map[string]any) map[string]any {
+		return map[string]any{"type": "container_checkpointed", "data": map[string]any{"checkpoint_id": cmd["checkpoint_id"]}}
+	})
+	var wg sync.WaitGroup
+	for _, id := range []string{"warm-a", "warm-b", "warm-c"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Checkpoint(id, true, time.Second); err != nil {
+				t.Errorf("Checkpoint(%s) failed: %v", id, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCaptureChunksDroppedForSlowReader(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	chunks, unsubscribe := c.SubscribeCapture()
+	defer unsubscribe()
+
+	// Nobody reads while the runner sends more than a subscriber may fall
+	// behind by; handling its output never waits on the reader
+	sent := hub.DefaultQueueLimit + 5
+	start := time.Now()
+	for range sent {
+		c.handleJSONMessage(map[string]any{
+			"type": "capture_data",
+			"data": map[string]any{"capture_id": "cap1", "data": base64.StdEncoding.EncodeToString([]byte("pcap"))},
+		})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("handling capture data took %s with a stalled reader", elapsed)
+	}
+
+	received := 0
+	for caughtUp := false; !caughtUp; {
+		select {
+		case <-chunks:
+			received++
+		case <-time.After(50 * time.Millisecond):
+			caughtUp = true
+		}
+	}
+	if received == sent {
+		t.Fatal("received every chunk, want some dropped")
+	}
+
+	c.handleJSONMessage(map[string]any{"type": "capture_stopped", "data": map[string]any{"capture_id": "cap1"}})
+	final := <-chunks
+	want := fmt.Sprintf("%d capture chunks were dropped", sent-received)
+	if !final.Done || !strings.Contains(final.GetError(), want) {
+		t.Errorf("final chunk = %v, want it to report %q", final, want)
+	}
+}
+
+func TestExec(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	execCh, _ := c.SubscribeExec()
+
+	// Failures to start are delivered like any other exit
+	if _, err := c.Exec(&pb.ExecRequest{Command: []string{"ls"}}); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+	if output := <-execCh; !output.Exited || output.ExitCode != -1 || output.Error == nil {
+		t.Errorf("Expected a failed exit, got %v", output)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	var sent map[string]any
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		sent = cmd
+	})
+
+	execID, err := c.Exec(&pb.ExecRequest{
+		Command:     []string{"sh", "-c", "echo hi"},
+		Env:         map[string]string{"A": "1"},
+		TimeoutSecs: proto.Uint32(5),
+	})
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if sent["type"] != "exec" || sent["exec_id"] != execID {
+		t.Errorf("Unexpected command sent to runner: %v", sent)
+	}
+	if len(sent["command"].([]any)) != 3 || sent["timeout_secs"] != float64(5) {
+		t.Errorf("Unexpected exec arguments: %v", sent)
+	}
+	if _, ok := sent["workdir"]; ok {
+		t.Error("Expected unset workdir to be omitted")
+	}
+
+	c.handleJSONMessage(map[string]any{
+		"type": "exec_output",
+		"data": map[string]any{"exec_id": execID, "stream": "stderr", "data": "aGkK"},
+	})
+	c.handleJSONMessage(map[string]any{
+		"type": "exec_exited",
+		"data": map[string]any{"exec_id": execID, "exit_code": float64(3)},
+	})
+
+	output := <-execCh
+	if output.ExecId != execID || string(output.Stderr) != "hi\n" || len(output.Stdout) != 0 {
+		t.Errorf("Unexpected output: %v", output)
+	}
+	output = <-execCh
+	if !output.Exited || output.ExitCode != 3 || output.Error != nil {
+		t.Errorf("Unexpected exit: %v", output)
+	}
+}
+
+func TestUploadFile(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	ctx := context.Background()
+
+	if _, err := c.UploadFile(ctx, "/app", strings.NewReader("archive")); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before start, got %v", err)
+	}
+
+	c.state.State = pb.ContainerState_RUNNING
+	if _, err := c.UploadFile(ctx, "/proc/self", strings.NewReader("archive")); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("Expected ErrInvalidPath, got %v", err)
+	}
+
+	var types []string
+	var received []byte
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		types = append(types, cmd["type"].(string))
+		switch cmd["type"] {
+		case "upload_file_data":
+			data, _ := base64.StdEncoding.DecodeString(cmd["data"].(string))
+			received = append(received, data...)
+		case "upload_file_end":
+			c.handleJSONMessage(map[string]any{
+				"type": "file_uploaded",
+				"data": map[string]any{"transfer_id": cmd["transfer_id"]},
+			})
+		}
+	})
+
+	archive := strings.Repeat("x", fileChunkSize+1)
+	size, err := c.UploadFile(ctx, "/app", strings.NewReader(archive))
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if size != int64(len(archive)) || string(received) != archive {
+		t.Errorf("Expected %d bytes to reach the runner, got %d (reported %d)", len(archive), len(received), size)
+	}
+	want := []string{"upload_file", "upload_file_data", "upload_file_data", "upload_file_end"}
+	if !slices.Equal(types, want) {
+		t.Errorf("Commands = %v, want %v", types, want)
+	}
+
+	// A runner failure is returned to the caller
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		if cmd["type"] == "upload_file_end" {
+			c.handleJSONMessage(map[string]any{
+				"type": "file_transfer_failed",
+				"data": map[string]any{"transfer_id": cmd["transfer_id"], "error": "no such directory"},
+			})
+		}
+	})
+	if _, err := c.UploadFile(ctx, "/missing", strings.NewReader("archive")); err == nil || err.Error() != "no such directory" {
+		t.Errorf("Expected runner error, got %v", err)
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	c := New("test", config)
+	c.state.State = pb.ContainerState_RUNNING
+
+	c.stdinWriter = runnerStub(func(cmd map[string]any) {
+		if cmd["type"] != "download_file" || cmd["path"] != "/app/out" {
+			t.Errorf("Unexpected command: %v", cmd)
+			return
+		}
+		for _, chunk := range []string{"aGVs", "bG8="} {
+			c.handleJSONMessage(map[string]any{
+				"type": "file_download_data",
+				"data": map[string]any{"transfer_id": cmd["transfer_id"], "data": chunk},
+			})
+		}
+		c.handleJSONMessage(map[string]any{
+			"type": "file_downloaded",
+			"data": map[string]any{"transfer_id": cmd["transfer_id"]},
+		})
+	})
+
+	var buf bytes.Buffer
+	size, err := c.DownloadFile(context.Background(), "/app/out", &buf)
+	if err != nil {
+		t.Fatalf("DownloadFile failed: %v", err)
+	}
+	if size != 5 || buf.String() != "hello" {
+		t.Errorf("Expected hello, got %q (%d bytes)", buf.String(), size)
+	}
+	if len(c.transfers) != 0 {
+		t.Error("Expected the transfer to be unregistered")
+	}
+}
+
+func TestValidateTransferPath(t *testing.T) {
+	valid := []string{"/", "/app", "/app/data/", "/tmp/out/.", "/devices"}
+	invalid := []string{"", "app", "/app/../etc", "/proc", "/sys/kernel", "/dev/shm", "/a\x00"}
+
+	for _, p := range valid {
+		if err := validateTransferPath(p); err != nil {
+			t.Errorf("validateTransferPath(%q) = %v, want nil", p, err)
+		}
+	}
+	for _, p := range invalid {
+		if err := validateTransferPath(p); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("validateTransferPath(%q) = %v, want ErrInvalidPath", p, err)
+		}
+	}
+}
+
+func TestBastionAuthEnv(t *testing.T) {
+	t.Setenv("BASTION_AUTH_TOKEN", "shared")
+	t.Setenv("BASTION_AUTH_SECRET", "")
+	if env := bastionAuthEnv("run_abc"); !slices.Equal(env, []string{"BASTION_AUTH_TOKEN=shared"}) {
+		t.Errorf("Expected the shared token without a secret, got %v", env)
+	}
+
+	// The runner must never receive the shared token once per-run credentials
+	// are in use; the value matches the bastion's auth.Credential
+	t.Setenv("BASTION_AUTH_SECRET", "s3cret")
+	want := []string{"BASTION_RUN_CREDENTIAL=0c18ca8a0c4646d6c9e2a564ce427d5f0f70578484c962298c6fcce5b77d61a8"}
+	if env := bastionAuthEnv("run_abc"); !slices.Equal(env, want) {
+		t.Errorf("Expected %v, got %v", want, env)
+	}
+
+	t.Setenv("BASTION_AUTH_TOKEN", "")
+	t.Setenv("BASTION_AUTH_SECRET", "")
+	if env := bastionAuthEnv("run_abc"); len(env) != 0 {
+		t.Errorf("Expected no credential, got %v", env)
+	}
+}
+
+func TestReadOutput(t *testing.T) {
+	c := New("test-id", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "alpine"}})
+	c.output = newOutputLog(8)
+
+	c.writeOutput(pb.OutputStream_STDOUT, []byte("abc"))
+	c.writeOutput(pb.OutputStream_STDERR, []byte("de"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// A reader resumes mid-chunk, counting stdout and stderr together
+	r := c.ReadOutput(1)
+	defer r.Close()
+	if chunk, err := r.Next(ctx); err != nil || string(chunk.Data) != "bc" || chunk.Offset != 1 {
+		t.Fatalf("Next() = %+v, %v", chunk, err)
+	}
+	if chunk, err := r.Next(ctx); err != nil || chunk.Stream != pb.OutputStream_STDERR || string(chunk.Data) != "de" {
+		t.Fatalf("Next() = %+v, %v", chunk, err)
+	}
+	if r.Offset() != 5 {
+		t.Errorf("Offset() = %d, want 5", r.Offset())
+	}
+
+	// It then gets everything written later, however far beyond the replay
+	// buffer, without dropping any
+	c.writeOutput(pb.OutputStream_STDOUT, []byte("fgh"))
+	c.writeOutput(pb.OutputStream_STDOUT, []byte("ijklmnop"))
+	for _, want := range []string{"fgh", "ijklmnop"} {
+		if chunk, err := r.Next(ctx); err != nil || string(chunk.Data) != want {
+			t.Fatalf("Next() = %+v, %v, want %q", chunk, err, want)
+		}
+	}
+	if r.Offset() != 16 {
+		t.Errorf("Offset() = %d, want 16", r.Offset())
+	}
+
+	// A new reader asking for output already dropped from the replay buffer
+	// starts at the oldest output still held
+	late := c.ReadOutput(0)
+	defer late.Close()
+	if late.Offset() != 8 {
+		t.Errorf("ReadOutput(0).Offset() = %d, want 8 after trimming", late.Offset())
+	}
+	if beyond := c.ReadOutput(100); beyond.Offset() != 16 {
+		t.Errorf("ReadOutput(100).Offset() = %d, want the end of the output", beyond.Offset())
+	} else {
+		beyond.Close()
+	}
+
+	// Once the container is closed, readers end after the rest of the output
+	c.writeOutput(pb.OutputStream_STDERR, []byte("q"))
+	c.Close()
+	if chunk, err := r.Next(ctx); err != nil || string(chunk.Data) != "q" {
+		t.Fatalf("Next() = %+v, %v", chunk, err)
+	}
+	if _, err := r.Next(ctx); err != io.EOF {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
+
+func TestReadOutputBackpressure(t *testing.T) {
+	c := New("test-id", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "alpine"}})
+	c.SetOutputBuffering(spool.Config{MemoryBytes: 4})
+
+	r := c.ReadOutput(0)
+	c.writeOutput(pb.OutputStream_STDOUT, []byte("abcd"))
+
+	// The reader's buffer is full, so the next write waits for it
+	written := make(chan struct{})
+	go func() {
+		c.writeOutput(pb.OutputStream_STDOUT, []byte("ef"))
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("write did not wait for a full reader")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ctx := context.Background()
+	if chunk, err := r.Next(ctx); err != nil || string(chunk.Data) != "abcd" {
+		t.Fatalf("Next() = %+v, %v", chunk, err)
+	}
+	<-written
+	if chunk, err := r.Next(ctx); err != nil || string(chunk.Data) != "ef" {
+		t.Fatalf("Next() = %+v, %v", chunk, err)
+	}
+
+	// A reader that goes away releases a write waiting on it
+	c.writeOutput(pb.OutputStream_STDOUT, []byte("ghij"))
+	written = make(chan struct{})
+	go func() {
+		c.writeOutput(pb.OutputStream_STDOUT, []byte("kl"))
+		close(written)
+	}()
+	r.Close()
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("write still waiting on a closed reader")
+	}
+	c.Close()
+}
+
+func TestLogs(t *testing.T) {
+	c := New("test-id", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "alpine"}})
+
+	c.writeOutput(pb.OutputStream_STDOUT, []byte("hello\nwor"))
+	c.publishMessage(`{"type":"container_ready"}`)
+	c.writeOutput(pb.OutputStream_STDOUT, []byte("ld\n"))
+	c.Close()
+
+	var got []string
+	err := c.Logs().Read(context.Background(), logs.Query{Follow: true}, func(line logs.Line) error {
+		got = append(got, line.Stream.String()+" "+string(line.Data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := []string{"STDOUT hello\n", `EVENTS {"type":"container_ready"}`, "STDOUT world\n"}
+	if !slices.Equal(got, want) {
+		t.Errorf("logs = %q, want %q", got, want)
+	}
+}
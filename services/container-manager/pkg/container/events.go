@@ -0,0 +1,91 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// payloadFields names the fields of Event's payload that differ from the type
+// of the events they carry; the others are named after it
+var payloadFields = map[string]protoreflect.Name{
+	"container_stats":     "stats",
+	"container_lifecycle": "lifecycle",
+}
+
+// payloadField returns the field of Event's payload holding events of a type,
+// or nil for types without a typed payload
+func payloadField(eventType string) protoreflect.FieldDescriptor {
+	name, ok := payloadFields[eventType]
+	if !ok {
+		name = protoreflect.Name(eventType)
+	}
+	field := (&pb.Event{}).ProtoReflect().Descriptor().Fields().ByName(name)
+	if field == nil || field.ContainingOneof() == nil || field.Message() == nil {
+		return nil
+	}
+	return field
+}
+
+// rawEvent is a runner message or lifecycle event as the runner writes it.
+// Log messages and the runner's own exit carry their fields at the top level
+// rather than in data.
+type rawEvent struct {
+	Type      string          `json:"type"`
+	Seq       uint64          `json:"seq"`
+	Timestamp string          `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+	Message   string          `json:"message"`
+	ExitCode  *int32          `json:"exit_code"`
+	ErrorCode *string         `json:"error_code"`
+}
+
+// ParseEvent parses a message as delivered by SubscribeMessages into a typed
+// event. Types without a typed payload keep their data as JSON.
+func ParseEvent(message string) (*pb.Event, error) {
+	var raw rawEvent
+	if err := json.Unmarshal([]byte(message), &raw); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+	if raw.Type == "" {
+		return nil, fmt.Errorf("invalid event: no type")
+	}
+
+	event := &pb.Event{
+		Type:      raw.Type,
+		Seq:       raw.Seq,
+		Timestamp: raw.Timestamp,
+	}
+
+	switch {
+	case raw.Type == "debug" || raw.Type == "info" || raw.Type == "warning" || raw.Type == "error":
+		event.Payload = &pb.Event_Log{Log: &pb.LogMessage{
+			Message:   raw.Message,
+			ErrorCode: raw.ErrorCode,
+		}}
+
+	case raw.Type == "container_exited" && len(raw.Data) == 0:
+		exited := &pb.ContainerExited{ErrorCode: raw.ErrorCode}
+		if raw.ExitCode != nil {
+			exited.ExitCode = *raw.ExitCode
+		}
+		event.Payload = &pb.Event_ContainerExited{ContainerExited: exited}
+
+	case payloadField(raw.Type) != nil:
+		// The keys of the event's data are the payload's field names
+		payload := event.ProtoReflect().Mutable(payloadField(raw.Type)).Message().Interface()
+		if len(raw.Data) > 0 {
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(raw.Data, payload); err != nil {
+				return nil, fmt.Errorf("invalid %s event: %w", raw.Type, err)
+			}
+		}
+
+	case len(raw.Data) > 0:
+		event.Payload = &pb.Event_DataJson{DataJson: string(raw.Data)}
+	}
+
+	return event, nil
+}
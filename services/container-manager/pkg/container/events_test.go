@@ -0,0 +1,90 @@
+package container
+
+import (
+	"testing"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseEvent(t *testing.T) {
+	errorCode := "image_pull_failed"
+
+	tests := []struct {
+		name    string
+		message string
+		want    *pb.Event
+	}{
+		{
+			"log",
+			`{"type":"error","seq":3,"timestamp":"2025-06-01T12:00:00Z","message":"pull failed","error_code":"image_pull_failed"}`,
+			&pb.Event{Type: "error", Seq: 3, Timestamp: "2025-06-01T12:00:00Z", Payload: &pb.Event_Log{Log: &pb.LogMessage{
+				Message: "pull failed", ErrorCode: &errorCode,
+			}}},
+		},
+		{
+			"image pull",
+			`{"type":"image_pull_completed","seq":4,"data":{"image":"alpine","registry":"docker.io","already_present":true}}`,
+			&pb.Event{Type: "image_pull_completed", Seq: 4, Payload: &pb.Event_ImagePullCompleted{ImagePullCompleted: &pb.ImagePullCompleted{
+				Image: "alpine", Registry: "docker.io", AlreadyPresent: true,
+			}}},
+		},
+		{
+			"stats",
+			`{"type":"container_stats","data":{"cpu_percent":12.5,"memory_usage_bytes":1024,"pids":3,"extra":1}}`,
+			&pb.Event{Type: "container_stats", Payload: &pb.Event_Stats{Stats: &pb.ResourceStats{
+				CpuPercent: 12.5, MemoryUsageBytes: 1024, Pids: 3,
+			}}},
+		},
+		{
+			"runner hello",
+			`{"type":"runner_hello","data":{"version":"1.2.0","schema_versions":[1,2]}}`,
+			&pb.Event{Type: "runner_hello", Payload: &pb.Event_RunnerHello{RunnerHello: &pb.RunnerHello{
+				Version: "1.2.0", SchemaVersions: []uint32{1, 2},
+			}}},
+		},
+		{
+			"exit with data",
+			`{"type":"container_exited","data":{"exit_code":1,"duration":"3s"}}`,
+			&pb.Event{Type: "container_exited", Payload: &pb.Event_ContainerExited{ContainerExited: &pb.ContainerExited{
+				ExitCode: 1, Duration: "3s",
+			}}},
+		},
+		{
+			"runner exit",
+			`{"type":"container_exited","exit_code":2,"error_code":"image_pull_failed"}`,
+			&pb.Event{Type: "container_exited", Payload: &pb.Event_ContainerExited{ContainerExited: &pb.ContainerExited{
+				ExitCode: 2, ErrorCode: &errorCode,
+			}}},
+		},
+		{
+			"lifecycle",
+			`{"type":"container_lifecycle","timestamp":"2025-06-01T12:00:00Z","data":{"container_id":"c1","from":"starting","to":"running","reason":"ready"}}`,
+			&pb.Event{Type: "container_lifecycle", Timestamp: "2025-06-01T12:00:00Z", Payload: &pb.Event_Lifecycle{Lifecycle: &pb.LifecycleTransition{
+				From: "starting", To: "running", Reason: "ready",
+			}}},
+		},
+		{
+			"untyped",
+			`{"type":"network_policy_update_failed","data":{"error":"boom"}}`,
+			&pb.Event{Type: "network_policy_update_failed", Payload: &pb.Event_DataJson{DataJson: `{"error":"boom"}`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEvent(tt.message)
+			if err != nil {
+				t.Fatalf("ParseEvent() error = %v", err)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("ParseEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, message := range []string{`not json`, `{"seq":1}`, `{"type":"container_stats","data":{"pids":"many"}}`} {
+		if _, err := ParseEvent(message); err == nil {
+			t.Errorf("ParseEvent(%s) succeeded, want an error", message)
+		}
+	}
+}
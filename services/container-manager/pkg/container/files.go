@@ -0,0 +1,296 @@
+package container
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+// MaxFileTransferBytes bounds the tar archive of a single upload or download.
+// The isolation-runner enforces the same limit.
+const MaxFileTransferBytes = 100 << 20
+
+// fileChunkSize keeps each upload command well inside the isolation-runner's line limit
+const fileChunkSize = 256 << 10
+
+var (
+	// ErrInvalidPath is returned for a transfer path that is not allowed
+	ErrInvalidPath = errors.New("invalid path")
+	// ErrTransferTooLarge is returned when an archive exceeds MaxFileTransferBytes
+	ErrTransferTooLarge = fmt.Errorf("file transfer exceeds %d bytes", MaxFileTransferBytes)
+)
+
+// protectedPaths are kernel filesystems that files are never copied into or out of
+var protectedPaths = []string{"/proc", "/sys", "/dev"}
+
+// transferEvent is what the isolation-runner reports for an upload or download:
+// a chunk of archive data, completion, or failure
+type transferEvent struct {
+	data []byte
+	done bool
+	err  error
+}
+
+// transfer routes the isolation-runner's events to the caller waiting on them.
+// gone is closed once the caller stops listening.
+type transfer struct {
+	events chan transferEvent
+	gone   chan struct{}
+}
+
+// validateTransferPath mirrors the isolation-runner's check so bad paths are
+// rejected before anything is sent
+func validateTransferPath(p string) error {
+	if p == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidPath)
+	}
+	if !path.IsAbs(p) {
+		return fmt.Errorf("%w: %q must be absolute", ErrInvalidPath, p)
+	}
+	if strings.ContainsRune(p, 0) {
+		return fmt.Errorf("%w: path must not contain NUL bytes", ErrInvalidPath)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return fmt.Errorf("%w: %q must not contain .. segments", ErrInvalidPath, p)
+		}
+	}
+
+	cleaned := path.Clean(p)
+	for _, protected := range protectedPaths {
+		if cleaned == protected || strings.HasPrefix(cleaned, protected+"/") {
+			return fmt.Errorf("%w: %q is not allowed", ErrInvalidPath, p)
+		}
+	}
+
+	return nil
+}
+
+// UploadFile copies the tar archive read from archive into the directory dir of
+// the running container and returns the archive's size. The archive is streamed
+// to the isolation-runner as it is read.
+func (c *Container) UploadFile(ctx context.Context, dir string, archive io.Reader) (int64, error) {
+	if err := c.checkTransfer(dir); err != nil {
+		return 0, err
+	}
+
+	transferID, t := c.registerTransfer()
+	defer c.unregisterTransfer(transferID, t)
+
+	if err := c.sendRunnerCommand(map[string]any{
+		"type":        "upload_file",
+		"transfer_id": transferID,
+		"path":        dir,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to send upload to isolation-runner: %w", err)
+	}
+
+	abort := func(err error) (int64, error) {
+		_ = c.sendRunnerCommand(map[string]any{
+			"type":        "upload_file_end",
+			"transfer_id": transferID,
+			"abort":       true,
+		})
+		return 0, err
+	}
+
+	var total int64
+	buf := make([]byte, fileChunkSize)
+	for {
+		n, err := io.ReadFull(archive, buf)
+		if n > 0 {
+			total += int64(n)
+			if total > MaxFileTransferBytes {
+				return abort(ErrTransferTooLarge)
+			}
+			if err := c.sendRunnerCommand(map[string]any{
+				"type":        "upload_file_data",
+				"transfer_id": transferID,
+				"data":        base64.StdEncoding.EncodeToString(buf[:n]),
+			}); err != nil {
+				return abort(fmt.Errorf("failed to send upload to isolation-runner: %w", err))
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return abort(fmt.Errorf("failed to read upload: %w", err))
+		}
+
+		// Stop early if the isolation-runner has already given up on the upload
+		select {
+		case event := <-t.events:
+			if event.err != nil {
+				return 0, event.err
+			}
+		default:
+		}
+	}
+
+	if err := c.sendRunnerCommand(map[string]any{
+		"type":        "upload_file_end",
+		"transfer_id": transferID,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to send upload to isolation-runner: %w", err)
+	}
+
+	for {
+		select {
+		case event, ok := <-t.events:
+			if !ok {
+				return 0, fmt.Errorf("upload interrupted")
+			}
+			if event.err != nil {
+				return 0, event.err
+			}
+			if event.done {
+				return total, nil
+			}
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-c.ctx.Done():
+			return 0, ErrNotRunning
+		}
+	}
+}
+
+// DownloadFile writes a tar archive of path in the running container to w and
+// returns its size. A failure after data has been written leaves w holding a
+// truncated archive.
+func (c *Container) DownloadFile(ctx context.Context, p string, w io.Writer) (int64, error) {
+	if err := c.checkTransfer(p); err != nil {
+		return 0, err
+	}
+
+	transferID, t := c.registerTransfer()
+	defer c.unregisterTransfer(transferID, t)
+
+	if err := c.sendRunnerCommand(map[string]any{
+		"type":        "download_file",
+		"transfer_id": transferID,
+		"path":        p,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to send download to isolation-runner: %w", err)
+	}
+
+	var total int64
+	for {
+		select {
+		case event, ok := <-t.events:
+			if !ok {
+				return total, fmt.Errorf("download interrupted: reader too slow")
+			}
+			if event.err != nil {
+				return total, event.err
+			}
+			if event.done {
+				return total, nil
+			}
+			total += int64(len(event.data))
+			if total > MaxFileTransferBytes {
+				return total, ErrTransferTooLarge
+			}
+			if _, err := w.Write(event.data); err != nil {
+				return total, err
+			}
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-c.ctx.Done():
+			return total, ErrNotRunning
+		}
+	}
+}
+
+func (c *Container) checkTransfer(p string) error {
+	c.stateMu.RLock()
+	state := c.state.State
+	c.stateMu.RUnlock()
+
+	if state != pb.ContainerState_RUNNING {
+		return ErrNotRunning
+	}
+
+	return validateTransferPath(p)
+}
+
+func (c *Container) registerTransfer() (string, *transfer) {
+	transferID := strings.ReplaceAll(uuid.New().String(), "-", "")
+	t := &transfer{
+		events: make(chan transferEvent, 64),
+		gone:   make(chan struct{}),
+	}
+
+	c.transferMu.Lock()
+	c.transfers[transferID] = t
+	c.transferMu.Unlock()
+
+	return transferID, t
+}
+
+func (c *Container) unregisterTransfer(transferID string, t *transfer) {
+	c.transferMu.Lock()
+	delete(c.transfers, transferID)
+	c.transferMu.Unlock()
+
+	close(t.gone)
+}
+
+// transferSendTimeout bounds how long output processing waits on a slow
+// transfer reader
+const transferSendTimeout = 5 * time.Second
+
+// deliverTransfer hands an isolation-runner event to the transfer it belongs to.
+// Data waits briefly for a slow reader; past that the transfer is cut off,
+// since a gap would corrupt the archive.
+func (c *Container) deliverTransfer(transferID string, event transferEvent) {
+	c.transferMu.Lock()
+	t, ok := c.transfers[transferID]
+	c.transferMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	select {
+	case t.events <- event:
+	case <-t.gone:
+	case <-c.ctx.Done():
+	case <-time.After(transferSendTimeout):
+		c.transferMu.Lock()
+		delete(c.transfers, transferID)
+		c.transferMu.Unlock()
+		close(t.events)
+	}
+}
+
+// handleTransferMessage routes file_* events from the isolation-runner
+func (c *Container) handleTransferMessage(msgType string, data map[string]any) {
+	transferID, _ := data["transfer_id"].(string)
+
+	switch msgType {
+	case "file_download_data":
+		encoded, _ := data["data"].(string)
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			c.deliverTransfer(transferID, transferEvent{err: fmt.Errorf("invalid download data: %w", err)})
+			return
+		}
+		c.deliverTransfer(transferID, transferEvent{data: decoded})
+
+	case "file_uploaded", "file_downloaded":
+		c.deliverTransfer(transferID, transferEvent{done: true})
+
+	case "file_transfer_failed":
+		errMsg, _ := data["error"].(string)
+		c.deliverTransfer(transferID, transferEvent{err: errors.New(errMsg)})
+	}
+}
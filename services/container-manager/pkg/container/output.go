@@ -0,0 +1,204 @@
+package container
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/spool"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+// OutputReplayBytes is how much of a container's most recent stdout and stderr
+// is kept for Run streams that attach after it was written
+const OutputReplayBytes = 1 << 20
+
+// OutputChunk is a piece of a container's output. Offsets count the bytes of
+// stdout and stderr together from the start of the run, so a client that has
+// seen everything up to an offset can ask for what follows it.
+type OutputChunk struct {
+	Offset uint64 // Offset of the first byte of Data
+	Stream pb.OutputStream
+	Data   []byte
+}
+
+// End is the offset just past the chunk
+func (c OutputChunk) End() uint64 {
+	return c.Offset + uint64(len(c.Data))
+}
+
+// outputLog keeps the most recent output of a container, up to a byte limit,
+// for readers that start at an earlier offset
+type outputLog struct {
+	chunks []OutputChunk
+	size   int
+	limit  int
+	end    uint64 // Offset just past the last byte written
+}
+
+func newOutputLog(limit int) *outputLog {
+	return &outputLog{limit: limit}
+}
+
+// append adds data to the log, dropping the oldest chunks beyond the limit;
+// the latest chunk is always kept
+func (l *outputLog) append(stream pb.OutputStream, data []byte) {
+	l.chunks = append(l.chunks, OutputChunk{Offset: l.end, Stream: stream, Data: data})
+	l.size += len(data)
+	l.end += uint64(len(data))
+
+	drop := 0
+	for l.size > l.limit && drop < len(l.chunks)-1 {
+		l.size -= len(l.chunks[drop].Data)
+		drop++
+	}
+	if drop > 0 {
+		l.chunks = append([]OutputChunk(nil), l.chunks[drop:]...)
+	}
+}
+
+// start returns where a reader asking for offset starts: offset itself when the
+// log still holds it, else the nearest offset it can serve
+func (l *outputLog) start(offset uint64) uint64 {
+	first := l.end
+	if len(l.chunks) > 0 {
+		first = l.chunks[0].Offset
+	}
+	return min(max(offset, first), l.end)
+}
+
+// read returns the chunks from offset on, the first cut to start there
+func (l *outputLog) read(offset uint64) []OutputChunk {
+	var chunks []OutputChunk
+	for _, chunk := range l.chunks {
+		if chunk.End() <= offset {
+			continue
+		}
+		if chunk.Offset < offset {
+			chunk.Data = chunk.Data[offset-chunk.Offset:]
+			chunk.Offset = offset
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// OutputReader follows a container's output from an offset. Every reader has
+// a buffer of its own, so a slow reader neither loses output nor holds up
+// others until its buffer is full; then it holds up the workload's output
+// until it catches up. Readers must be closed.
+type OutputReader struct {
+	c      *Container
+	replay []OutputChunk // Output written before the reader was opened
+	buffer *spool.Buffer // Output written since
+	offset uint64
+}
+
+// ReadOutput returns a reader of the container's output from offset on. When
+// output from offset has already been dropped from the replay buffer, or
+// offset is beyond what was written, the reader starts at the nearest offset
+// it can serve; Offset reports where that is.
+func (c *Container) ReadOutput(offset uint64) *OutputReader {
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+
+	start := c.output.start(offset)
+	r := &OutputReader{
+		c:      c,
+		replay: c.output.read(start),
+		buffer: spool.New(c.outputBuffering),
+		offset: start,
+	}
+	if c.outputClosed {
+		r.buffer.Close()
+	} else {
+		c.outputReaders[r] = struct{}{}
+	}
+	return r
+}
+
+// Offset is where the next chunk Next returns starts
+func (r *OutputReader) Offset() uint64 {
+	return r.offset
+}
+
+// Next waits for the next chunk of output and returns it. It returns io.EOF
+// once the container's output is complete and everything was read.
+func (r *OutputReader) Next(ctx context.Context) (OutputChunk, error) {
+	if len(r.replay) > 0 {
+		chunk := r.replay[0]
+		r.replay = r.replay[1:]
+		r.offset = chunk.End()
+		return chunk, nil
+	}
+
+	entry, err := r.buffer.Read(ctx)
+	if err != nil {
+		return OutputChunk{}, err
+	}
+	chunk := OutputChunk{
+		Offset: r.offset,
+		Stream: pb.OutputStream(entry.Kind),
+		Data:   entry.Data,
+	}
+	r.offset = chunk.End()
+	return chunk, nil
+}
+
+// Close stops the reader and drops whatever it has buffered
+func (r *OutputReader) Close() {
+	// Discarding first releases a write blocked on this reader
+	r.buffer.Discard()
+
+	r.c.outputMu.Lock()
+	delete(r.c.outputReaders, r)
+	r.c.outputMu.Unlock()
+}
+
+// writeOutput records a piece of the workload's output and passes it to every
+// reader, waiting for those whose buffers are full
+func (c *Container) writeOutput(stream pb.OutputStream, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	// Writers take turns so every reader gets the output in the same order
+	c.outputWriteMu.Lock()
+	defer c.outputWriteMu.Unlock()
+
+	c.outputMu.Lock()
+	if c.outputClosed {
+		c.outputMu.Unlock()
+		return
+	}
+	c.output.append(stream, data)
+	c.logs.Write(stream, data)
+	readers := slices.Collect(maps.Keys(c.outputReaders))
+	c.outputMu.Unlock()
+
+	for _, r := range readers {
+		// Fails only for a reader that has gone away
+		_ = r.buffer.Write(context.Background(), spool.Entry{Kind: byte(stream), Data: data})
+	}
+}
+
+// closeOutput marks the output complete; readers get what they have buffered,
+// then io.EOF. A write still waiting on a full reader is dropped for it.
+func (c *Container) closeOutput() {
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+
+	c.outputClosed = true
+	for r := range c.outputReaders {
+		r.buffer.Close()
+	}
+	clear(c.outputReaders)
+}
+
+// SetOutputBuffering sets the memory and disk caps of each output reader's
+// buffer
+func (c *Container) SetOutputBuffering(config spool.Config) {
+	c.outputMu.Lock()
+	c.outputBuffering = config
+	c.outputMu.Unlock()
+}
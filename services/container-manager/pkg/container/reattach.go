@@ -0,0 +1,65 @@
+package container
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/lifecycle"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/shim"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// Reattach rebuilds a container that a previous manager process started
+// through the shim listening on socketPath from its last recorded status, and
+// takes over its isolation-runner. Timers run from the container's creation as
+// before, except the client heartbeat, which restarts so clients have its
+// timeout, or the disconnect grace period if longer, to come back.
+func Reattach(status *pb.ContainerStatus, timeouts lifecycle.Timeouts, socketPath string) (*Container, error) {
+	if status.State != pb.ContainerState_RUNNING {
+		return nil, fmt.Errorf("%w: container %s is %s", ErrNotRunning, status.ContainerId, status.State)
+	}
+
+	runner, err := shim.Attach(socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to shim: %w", err)
+	}
+
+	now := time.Now()
+	created := now
+	if secs, err := strconv.ParseInt(status.CreatedAt, 10, 64); err == nil {
+		created = time.Unix(secs, 0)
+	}
+
+	c := NewWithTimeouts(status.ContainerId, status.Config, timeouts)
+	c.state = proto.Clone(status).(*pb.ContainerStatus)
+	c.state.Config = c.Config
+	if c.state.IoStats == nil {
+		c.state.IoStats = &pb.IOStats{}
+	}
+	c.state.Pid = proto.Int32(int32(runner.Pid))
+	c.shimSocket = socketPath
+
+	c.lifecycle = lifecycle.New(timeouts, created)
+	c.lifecycle.Heartbeat(now)
+	if timeouts.Disconnect > timeouts.Heartbeat {
+		c.lifecycle.Disconnected(now)
+	}
+	if status.Ready || status.GetRunnerPhase() == "running" {
+		_, _ = c.lifecycle.Started(now)
+	}
+	if status.Paused {
+		c.lifecycle.Pause()
+	}
+	if status.Ready {
+		c.readyOnce.Do(func() { close(c.ready) })
+	}
+
+	if err := c.attachRunner(runner); err != nil {
+		return nil, err
+	}
+	go c.monitor()
+
+	return c, nil
+}
@@ -0,0 +1,86 @@
+package container
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultStatsInterval is how often the isolation-runner samples resource usage
+// when the config does not say
+const DefaultStatsInterval = 10 * time.Second
+
+// idleCPUPercent is the CPU use, relative to one CPU, below which a sample
+// does not count as activity for the idle timeout
+const idleCPUPercent = 1.0
+
+// ReasonCPUTimeLimitExceeded is recorded on containers terminated for using up
+// their cpu_time_limit_secs
+const ReasonCPUTimeLimitExceeded = "cpu_time_limit_exceeded"
+
+// statsInterval is the stats_interval_secs passed to the isolation-runner. A
+// CPU time limit needs the samples, so it keeps them on.
+func (c *Container) statsInterval() uint32 {
+	if c.Config.StatsIntervalSecs != nil {
+		if interval := c.Config.GetStatsIntervalSecs(); interval > 0 || c.Config.GetCpuTimeLimitSecs() == 0 {
+			return interval
+		}
+	}
+	return uint32(DefaultStatsInterval / time.Second)
+}
+
+// recordStats keeps the latest container_stats sample in the container's IoStats
+func (c *Container) recordStats(data map[string]any, timestamp any) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	stats := c.state.IoStats
+	if cpu, ok := data["cpu_percent"].(float64); ok {
+		stats.CpuPercent = proto.Float64(cpu)
+		// A workload busy computing is not idle, even when it is quiet
+		if cpu >= idleCPUPercent {
+			c.lifecycle.Activity(time.Now())
+		}
+	}
+	stats.MemoryUsageBytes = uint64Field(data, "memory_usage_bytes")
+	stats.MemoryLimitBytes = uint64Field(data, "memory_limit_bytes")
+	stats.NetRxBytes = uint64Field(data, "net_rx_bytes")
+	stats.NetTxBytes = uint64Field(data, "net_tx_bytes")
+	stats.BlockReadBytes = uint64Field(data, "block_read_bytes")
+	stats.BlockWriteBytes = uint64Field(data, "block_write_bytes")
+	if ts, ok := timestamp.(string); ok {
+		stats.SampledAt = proto.String(ts)
+	}
+
+	if cpuTime, ok := data["cpu_time_secs"].(float64); ok {
+		stats.CpuTimeSecs = proto.Float64(cpuTime)
+		if limit := c.Config.GetCpuTimeLimitSecs(); limit > 0 && cpuTime >= float64(limit) {
+			c.cpuLimitOnce.Do(func() {
+				go c.TerminateWithReason(ReasonCPUTimeLimitExceeded, true, 0)
+			})
+		}
+	}
+}
+
+// countIO adds n bytes to the stream's total in the container's IoStats
+func (c *Container) countIO(stream byte, n int) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	switch stream {
+	case streamStdin:
+		c.state.IoStats.StdinBytes += uint64(n)
+	case streamStdout:
+		c.state.IoStats.StdoutBytes += uint64(n)
+	case streamStderr:
+		c.state.IoStats.StderrBytes += uint64(n)
+	}
+}
+
+// uint64Field reads a JSON number, which decodes as float64, as a uint64
+func uint64Field(data map[string]any, key string) uint64 {
+	if v, ok := data[key].(float64); ok && v > 0 {
+		return uint64(v)
+	}
+	return 0
+}
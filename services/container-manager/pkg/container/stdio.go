@@ -0,0 +1,167 @@
+package container
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+// Workload stdin, stdout and stderr travel to and from the isolation-runner as
+// length-prefixed frames on a pair of pipes of their own, apart from the JSON
+// control channel, so binary data and long lines pass through unmodified.
+// Each frame is a stream ID byte, a big-endian uint32 payload length and the
+// payload; this mirrors the isolation-runner's stdio package.
+const (
+	streamStdin  byte = 0
+	streamStdout byte = 1
+	streamStderr byte = 2
+)
+
+// maxFrameSize bounds a frame's payload; longer writes are split
+const maxFrameSize = 1 << 20
+
+const frameHeaderSize = 5
+
+// outputDrainTimeout bounds how long an exit waits for the rest of the
+// workload's output, which arrives on a different pipe than the exit event
+const outputDrainTimeout = 2 * time.Second
+
+// writeFrames writes data to w as frames of stream. Empty data writes a single
+// empty frame.
+func writeFrames(w io.Writer, stream byte, data []byte) error {
+	for {
+		n := min(len(data), maxFrameSize)
+
+		frame := make([]byte, frameHeaderSize+n)
+		frame[0] = stream
+		binary.BigEndian.PutUint32(frame[1:frameHeaderSize], uint32(n))
+		copy(frame[frameHeaderSize:], data[:n])
+		if _, err := w.Write(frame); err != nil {
+			return err
+		}
+
+		data = data[n:]
+		if len(data) == 0 {
+			return nil
+		}
+	}
+}
+
+// readFrame reads the next frame from r. It returns io.EOF only when r ends
+// cleanly between frames.
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, nil, fmt.Errorf("truncated frame header")
+		}
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxFrameSize {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds %d", size, maxFrameSize)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, fmt.Errorf("truncated frame: %w", err)
+	}
+
+	return header[0], data, nil
+}
+
+// attachStdioPipes hands the isolation-runner its frame pipes as file
+// descriptors 3 (output) and 4 (input). The returned function closes the
+// child's ends once the process has started, or all ends if it failed to.
+func (c *Container) attachStdioPipes(cmd *exec.Cmd) (func(started bool), error) {
+	outputReader, outputWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output pipe: %w", err)
+	}
+	inputReader, inputWriter, err := os.Pipe()
+	if err != nil {
+		outputReader.Close()
+		outputWriter.Close()
+		return nil, fmt.Errorf("failed to create input pipe: %w", err)
+	}
+
+	cmd.ExtraFiles = []*os.File{outputWriter, inputReader}
+	c.stdioWriter = inputWriter
+	c.outputDone = make(chan struct{})
+
+	return func(started bool) {
+		outputWriter.Close()
+		inputReader.Close()
+		if !started {
+			outputReader.Close()
+			inputWriter.Close()
+			return
+		}
+		go c.readFrames(outputReader)
+	}, nil
+}
+
+// readFrames forwards the workload's output frames until the isolation-runner
+// closes its end, which it does once the workload's output is complete
+func (c *Container) readFrames(r io.ReadCloser) {
+	defer close(c.outputDone)
+	defer r.Close()
+
+	for {
+		stream, data, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		c.lifecycle.Activity(time.Now())
+		c.countIO(stream, len(data))
+
+		switch stream {
+		case streamStdout:
+			c.writeOutput(pb.OutputStream_STDOUT, data)
+		case streamStderr:
+			c.writeOutput(pb.OutputStream_STDERR, data)
+		}
+	}
+}
+
+// waitForOutput waits, up to outputDrainTimeout, until all of the workload's
+// output has been read, so an exit is never reported ahead of its last output
+func (c *Container) waitForOutput() {
+	if c.outputDone == nil {
+		return
+	}
+
+	select {
+	case <-c.outputDone:
+	case <-c.ctx.Done():
+	case <-time.After(outputDrainTimeout):
+	}
+}
+
+// writeStdinFrames sends data to the workload's stdin
+func (c *Container) writeStdinFrames(data []byte) error {
+	if c.stdioWriter == nil {
+		return fmt.Errorf("stdin not available")
+	}
+
+	c.stdioMu.Lock()
+	defer c.stdioMu.Unlock()
+
+	return writeFrames(c.stdioWriter, streamStdin, data)
+}
+
+// CloseStdin closes the workload's stdin, so it reads end of file once
+// everything written so far has been consumed
+func (c *Container) CloseStdin() error {
+	return c.writeStdinFrames(nil)
+}
@@ -0,0 +1,180 @@
+// Package hub fans a stream of values out to any number of subscribers, each
+// of which receives its own complete copy in publish order. A subscriber that
+// falls behind is queued for, up to a limit, without holding up the publisher
+// or other subscribers; past the limit it misses values until it catches up.
+package hub
+
+import "sync"
+
+// DefaultQueueLimit is how many values a subscriber may fall behind by
+const DefaultQueueLimit = 4096
+
+// Hub delivers every published value to every current subscriber
+type Hub[T any] struct {
+	limit        int
+	historyLimit int
+
+	mu      sync.Mutex
+	subs    map[*subscriber[T]]struct{}
+	history []T // The most recent values, for subscribers that ask for them
+	closed  bool
+}
+
+// New returns a hub whose subscribers may each fall behind by up to limit
+// values, where values <= 0 use DefaultQueueLimit, and that keeps the last
+// history values for subscribers that join late
+func New[T any](limit, history int) *Hub[T] {
+	if limit <= 0 {
+		limit = DefaultQueueLimit
+	}
+	return &Hub[T]{
+		limit:        limit,
+		historyLimit: history,
+		subs:         make(map[*subscriber[T]]struct{}),
+	}
+}
+
+type subscriber[T any] struct {
+	ch   chan T
+	wake chan struct{} // Signalled when the queue gains a value or ends
+	done chan struct{} // Closed on unsubscribe
+
+	mu    sync.Mutex
+	queue []T
+	ended bool // The hub closed; the queue drains, then ch closes
+}
+
+// Subscribe returns a channel receiving every value published from now on,
+// preceded by the hub's history when withHistory is set, and a function that
+// unsubscribes. The channel is closed once the hub is closed and everything
+// published before was received, or on unsubscribe.
+func (h *Hub[T]) Subscribe(withHistory bool) (<-chan T, func()) {
+	s := &subscriber[T]{
+		ch:   make(chan T),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	if withHistory {
+		s.queue = append(s.queue, h.history...)
+	}
+	if h.closed {
+		s.ended = true
+	} else {
+		h.subs[s] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	go s.deliver()
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, s)
+			h.mu.Unlock()
+			close(s.done)
+		})
+	}
+}
+
+// Publish queues v for every subscriber. It never blocks on a subscriber, and
+// returns how many were too far behind to be queued v.
+func (h *Hub[T]) Publish(v T) (missed int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return 0
+	}
+	for s := range h.subs {
+		if !s.push(v, h.limit) {
+			missed++
+		}
+	}
+
+	if h.historyLimit > 0 {
+		if len(h.history) == h.historyLimit {
+			var zero T
+			h.history[0] = zero
+			h.history = h.history[1:]
+		}
+		h.history = append(h.history, v)
+	}
+	return missed
+}
+
+// Close ends the hub: subscribers receive what was already published, then
+// their channels close. Later publishes are ignored.
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for s := range h.subs {
+		s.end()
+	}
+	clear(h.subs)
+}
+
+func (s *subscriber[T]) push(v T, limit int) bool {
+	s.mu.Lock()
+	queued := len(s.queue) < limit
+	if queued {
+		s.queue = append(s.queue, v)
+	}
+	s.mu.Unlock()
+	s.signal()
+	return queued
+}
+
+func (s *subscriber[T]) end() {
+	s.mu.Lock()
+	s.ended = true
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *subscriber[T]) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver feeds the queue to the subscriber's channel in order
+func (s *subscriber[T]) deliver() {
+	defer close(s.ch)
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			ended := s.ended
+			s.mu.Unlock()
+			if ended {
+				return
+			}
+			select {
+			case <-s.wake:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		v := s.queue[0]
+		var zero T
+		s.queue[0] = zero
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.ch <- v:
+		case <-s.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package hub
+
+import (
+	"testing"
+	"time"
+)
+
+// receive reads n values from ch, failing the test if they do not arrive
+func receive(t *testing.T, ch <-chan int, n int) []int {
+	t.Helper()
+
+	var got []int
+	for range n {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				t.Fatalf("channel closed after %v", got)
+			}
+			got = append(got, v)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %v", got)
+		}
+	}
+	return got
+}
+
+func waitClosed(t *testing.T, ch <-chan int) {
+	t.Helper()
+
+	select {
+	case v, ok := <-ch:
+		if ok {
+			t.Fatalf("received %d, want the channel closed", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel was not closed")
+	}
+}
+
+func TestEverySubscriberGetsEverything(t *testing.T) {
+	h := New[int](0, 0)
+	first, _ := h.Subscribe(false)
+	second, _ := h.Subscribe(false)
+
+	for i := range 100 {
+		h.Publish(i)
+	}
+
+	// Neither subscriber is reading yet, and neither holds up the other
+	for _, ch := range []<-chan int{second, first} {
+		got := receive(t, ch, 100)
+		for i, v := range got {
+			if v != i {
+				t.Fatalf("received %v, want 0..99 in order", got)
+			}
+		}
+	}
+}
+
+func TestCloseDrainsThenCloses(t *testing.T) {
+	h := New[int](0, 0)
+	ch, _ := h.Subscribe(false)
+
+	h.Publish(1)
+	h.Publish(2)
+	h.Close()
+	h.Publish(3)
+
+	if got := receive(t, ch, 2); got[0] != 1 || got[1] != 2 {
+		t.Errorf("received %v, want [1 2]", got)
+	}
+	waitClosed(t, ch)
+
+	// Subscribing to a closed hub gets a closed channel
+	late, _ := h.Subscribe(false)
+	waitClosed(t, late)
+}
+
+func TestUnsubscribe(t *testing.T) {
+	h := New[int](0, 0)
+	ch, unsubscribe := h.Subscribe(false)
+	other, _ := h.Subscribe(false)
+
+	h.Publish(1)
+	unsubscribe()
+	unsubscribe()
+	// A value already queued may or may not be received first
+	for range ch {
+	}
+
+	h.Publish(2)
+	if got := receive(t, other, 2); got[0] != 1 || got[1] != 2 {
+		t.Errorf("received %v, want [1 2]", got)
+	}
+}
+
+func TestQueueLimit(t *testing.T) {
+	h := New[int](2, 0)
+	ch, _ := h.Subscribe(false)
+
+	// Publishing never blocks; past the limit the subscriber misses values
+	done := make(chan struct{})
+	missed := 0
+	go func() {
+		for i := range 10 {
+			missed += h.Publish(i)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a subscriber that is not reading")
+	}
+
+	h.Close()
+	var got []int
+	for v := range ch {
+		got = append(got, v)
+	}
+	if len(got) > 3 || got[0] != 0 {
+		t.Errorf("received %v, want at most the first few values", got)
+	}
+	if missed != 10-len(got) {
+		t.Errorf("Publish() reported %d missed, want %d", missed, 10-len(got))
+	}
+}
+
+func TestHistory(t *testing.T) {
+	h := New[int](0, 3)
+	for i := range 5 {
+		h.Publish(i)
+	}
+
+	withHistory, _ := h.Subscribe(true)
+	without, _ := h.Subscribe(false)
+	h.Publish(5)
+
+	got := receive(t, withHistory, 4)
+	for i, v := range got {
+		if v != i+2 {
+			t.Fatalf("received %v, want [2 3 4 5]", got)
+		}
+	}
+	if got := receive(t, without, 1); got[0] != 5 {
+		t.Errorf("received %v, want [5]", got)
+	}
+}
@@ -0,0 +1,484 @@
+// Package lifecycle holds the state machine that owns every timer of a
+// container: startup deadline, client heartbeat, runner heartbeat, run timeout,
+// idle timeout, maximum lifetime and the cleanup delay after exit.
+//
+// The machine is passive. Callers report what happened (Started, Heartbeat,
+// Disconnected, RunnerHeartbeat, Activity, ExtendRun, Stop, Exited) and periodically call Advance, which fires at most one
+// expired timer and returns the resulting transition. It never starts goroutines
+// or reads the clock itself, so its behaviour is fully determined by its inputs.
+//
+// Phases and the transitions between them:
+//
+//	Starting ──started──▶ Running
+//	Starting, Running ──stop / startup, heartbeat, disconnect, runner, run, idle, max lifetime timer──▶ Stopping
+//	Starting, Running, Stopping ──exited──▶ Exited
+//	Exited ──cleanup timer / remove──▶ Removed
+//
+// Timers only run in the phases where they matter: the startup deadline while
+// Starting; run and idle timeouts while Running; heartbeat, disconnect, runner
+// heartbeat and maximum lifetime while Starting or Running; cleanup while
+// Exited. Once the client disconnects, the disconnect grace period replaces
+// the heartbeat timer until a heartbeat shows the client is back. The runner
+// heartbeat timer is only armed by the runner's first heartbeat, so a runner
+// that never sends any is not taken for a wedged one. Entering Stopping disarms
+// everything, since termination has its own kill timeout. A paused container
+// stays Running but its idle timer is suspended until it resumes. With
+// IdleSuspend the idle timer pauses the container instead of stopping it:
+//
+//	Running ──idle timer (IdleSuspend)──▶ Running, paused
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Phase is the lifecycle position of a container
+type Phase int
+
+const (
+	PhaseStarting Phase = iota
+	PhaseRunning
+	PhaseStopping
+	PhaseExited
+	PhaseRemoved
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseStarting:
+		return "starting"
+	case PhaseRunning:
+		return "running"
+	case PhaseStopping:
+		return "stopping"
+	case PhaseExited:
+		return "exited"
+	case PhaseRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// Timer identifies one of the deadlines owned by the machine
+type Timer int
+
+const (
+	TimerStartup Timer = iota
+	TimerHeartbeat
+	TimerRunner
+	TimerRun
+	TimerIdle
+	TimerMaxLifetime
+	TimerCleanup
+	TimerDisconnect
+)
+
+// Reasons recorded on transitions
+const (
+	ReasonStarted             = "started"
+	ReasonExited              = "exited"
+	ReasonStartupTimeout      = "startup_timeout"
+	ReasonHeartbeatTimeout    = "heartbeat_timeout"
+	ReasonRunnerUnresponsive  = "runner_unresponsive"
+	ReasonRunTimeout          = "run_timeout"
+	ReasonIdleTimeout         = "idle_timeout"
+	ReasonIdleSuspend         = "idle_suspend"
+	ReasonMaxLifetimeExceeded = "max_lifetime_exceeded"
+	ReasonCleanup             = "cleanup"
+	ReasonClientDisconnected  = "client_disconnected"
+)
+
+// Reason is the transition reason used when the timer fires
+func (t Timer) Reason() string {
+	switch t {
+	case TimerStartup:
+		return ReasonStartupTimeout
+	case TimerHeartbeat:
+		return ReasonHeartbeatTimeout
+	case TimerRunner:
+		return ReasonRunnerUnresponsive
+	case TimerRun:
+		return ReasonRunTimeout
+	case TimerIdle:
+		return ReasonIdleTimeout
+	case TimerMaxLifetime:
+		return ReasonMaxLifetimeExceeded
+	case TimerCleanup:
+		return ReasonCleanup
+	case TimerDisconnect:
+		return ReasonClientDisconnected
+	default:
+		return fmt.Sprintf("timer(%d)", int(t))
+	}
+}
+
+// timers lists every timer in firing priority order for equal deadlines
+var timers = []Timer{TimerStartup, TimerHeartbeat, TimerDisconnect, TimerRunner, TimerRun, TimerIdle, TimerMaxLifetime, TimerCleanup}
+
+// Timeouts configures the machine's timers. A zero duration disables the timer.
+type Timeouts struct {
+	Startup     time.Duration
+	Heartbeat   time.Duration
+	Runner      time.Duration
+	Run         time.Duration
+	Idle        time.Duration
+	MaxLifetime time.Duration
+	Cleanup     time.Duration
+	// Disconnect is how long the container may run after its client went
+	// away without a heartbeat bringing it back
+	Disconnect time.Duration
+	// MaxRunExtension bounds how far ExtendRun may push back the run timeout
+	// in total; zero allows no extension
+	MaxRunExtension time.Duration
+
+	// IdleSuspend makes the idle timer pause the container rather than stop it
+	IdleSuspend bool
+}
+
+func (t Timeouts) duration(timer Timer) time.Duration {
+	switch timer {
+	case TimerStartup:
+		return t.Startup
+	case TimerHeartbeat:
+		return t.Heartbeat
+	case TimerRunner:
+		return t.Runner
+	case TimerRun:
+		return t.Run
+	case TimerIdle:
+		return t.Idle
+	case TimerMaxLifetime:
+		return t.MaxLifetime
+	case TimerCleanup:
+		return t.Cleanup
+	case TimerDisconnect:
+		return t.Disconnect
+	default:
+		return 0
+	}
+}
+
+// Transition records a phase change
+type Transition struct {
+	From   Phase
+	To     Phase
+	Reason string
+	At     time.Time
+}
+
+var (
+	// ErrInvalidTransition is returned when an event does not apply to the current phase
+	ErrInvalidTransition = errors.New("invalid lifecycle transition")
+	// ErrNoRunTimeout is returned when extending the run timeout of a
+	// container that has none
+	ErrNoRunTimeout = errors.New("container has no run timeout")
+	// ErrExtensionLimit is returned when an extension would take the run
+	// timeout past Timeouts.MaxRunExtension
+	ErrExtensionLimit = errors.New("run timeout extension limit reached")
+)
+
+// allowed lists the legal targets of each phase
+var allowed = map[Phase][]Phase{
+	PhaseStarting: {PhaseRunning, PhaseStopping, PhaseExited},
+	PhaseRunning:  {PhaseStopping, PhaseExited},
+	PhaseStopping: {PhaseExited},
+	PhaseExited:   {PhaseRemoved},
+}
+
+// Machine is the lifecycle state machine of one container. It is safe for
+// concurrent use.
+type Machine struct {
+	mu         sync.Mutex
+	timeouts   Timeouts
+	phase      Phase
+	stopReason string
+	deadlines  map[Timer]time.Time
+	stopping   chan struct{}
+	paused     bool
+	extended   time.Duration // Added to the run timeout by ExtendRun
+}
+
+// New returns a machine in PhaseStarting, with the startup, heartbeat and
+// maximum lifetime timers armed from now
+func New(timeouts Timeouts, now time.Time) *Machine {
+	m := &Machine{
+		timeouts:  timeouts,
+		phase:     PhaseStarting,
+		deadlines: make(map[Timer]time.Time),
+		stopping:  make(chan struct{}),
+	}
+	m.arm(TimerStartup, now)
+	m.arm(TimerHeartbeat, now)
+	m.arm(TimerMaxLifetime, now)
+	return m
+}
+
+// Phase returns the current phase
+func (m *Machine) Phase() Phase {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.phase
+}
+
+// StopReason returns why termination began, or "" if the container was never
+// stopped (it is still running or exited on its own)
+func (m *Machine) StopReason() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stopReason
+}
+
+// Deadline reports when timer fires, if it is armed
+func (m *Machine) Deadline(timer Timer) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deadline, ok := m.deadlines[timer]
+	return deadline, ok
+}
+
+// Stopping is closed when the machine enters PhaseStopping
+func (m *Machine) Stopping() <-chan struct{} {
+	return m.stopping
+}
+
+// Started moves a starting container to running and arms the run and idle timers
+func (m *Machine) Started(now time.Time) (Transition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tr, err := m.transition(PhaseRunning, ReasonStarted, now)
+	if err != nil {
+		return tr, err
+	}
+	m.arm(TimerRun, now)
+	m.arm(TimerIdle, now)
+	return tr, nil
+}
+
+// Heartbeat re-arms the heartbeat timer and disarms the disconnect timer of a
+// client that has come back. It is ignored once the container is stopping.
+func (m *Machine) Heartbeat(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase == PhaseStarting || m.phase == PhaseRunning {
+		m.arm(TimerHeartbeat, now)
+		delete(m.deadlines, TimerDisconnect)
+	}
+}
+
+// Disconnected records that the client went away: the heartbeat timer gives
+// way to the disconnect timer. It is ignored once the container is stopping.
+func (m *Machine) Disconnected(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase == PhaseStarting || m.phase == PhaseRunning {
+		delete(m.deadlines, TimerHeartbeat)
+		m.arm(TimerDisconnect, now)
+	}
+}
+
+// RunnerHeartbeat arms or re-arms the runner heartbeat timer. It is ignored
+// once the container is stopping.
+func (m *Machine) RunnerHeartbeat(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase == PhaseStarting || m.phase == PhaseRunning {
+		m.arm(TimerRunner, now)
+	}
+}
+
+// Activity re-arms the idle timer; call it for any stdin or output traffic
+func (m *Machine) Activity(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase == PhaseRunning && !m.paused {
+		m.arm(TimerIdle, now)
+	}
+}
+
+// Pause suspends the idle timer of a running container: a frozen container is
+// idle on purpose
+func (m *Machine) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase == PhaseRunning {
+		m.paused = true
+		delete(m.deadlines, TimerIdle)
+	}
+}
+
+// Resume re-arms the idle timer suspended by Pause
+func (m *Machine) Resume(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase == PhaseRunning && m.paused {
+		m.paused = false
+		m.arm(TimerIdle, now)
+	}
+}
+
+// ExtendRun pushes back the run timeout by d and returns its new deadline, or
+// the zero time while the container is starting and the run timer is not
+// armed yet. The extensions of a container add up to at most
+// Timeouts.MaxRunExtension.
+func (m *Machine) ExtendRun(d time.Duration) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase != PhaseStarting && m.phase != PhaseRunning {
+		return time.Time{}, fmt.Errorf("%w: cannot extend the run timeout while %s", ErrInvalidTransition, m.phase)
+	}
+	if m.timeouts.Run <= 0 {
+		return time.Time{}, ErrNoRunTimeout
+	}
+	if m.extended+d > m.timeouts.MaxRunExtension {
+		return time.Time{}, fmt.Errorf("%w: %s of at most %s already used", ErrExtensionLimit, m.extended, m.timeouts.MaxRunExtension)
+	}
+
+	m.extended += d
+	deadline, ok := m.deadlines[TimerRun]
+	if !ok {
+		// Armed once the container starts
+		m.timeouts.Run += d
+		return time.Time{}, nil
+	}
+	deadline = deadline.Add(d)
+	m.deadlines[TimerRun] = deadline
+	return deadline, nil
+}
+
+// Extended returns how far the run timeout has been pushed back in total
+func (m *Machine) Extended() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.extended
+}
+
+// Stop records that termination of the container has begun
+func (m *Machine) Stop(now time.Time, reason string) (Transition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.transition(PhaseStopping, reason, now)
+}
+
+// Exited records that the container process is gone and arms the cleanup timer
+func (m *Machine) Exited(now time.Time) (Transition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tr, err := m.transition(PhaseExited, ReasonExited, now)
+	if err != nil {
+		return tr, err
+	}
+	m.arm(TimerCleanup, now)
+	return tr, nil
+}
+
+// Remove moves an exited container to removed ahead of its cleanup timer
+func (m *Machine) Remove(now time.Time, reason string) (Transition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.transition(PhaseRemoved, reason, now)
+}
+
+// Advance fires the earliest timer that has expired at now, if any, and returns
+// the transition it caused. Call it repeatedly to fire several expired timers.
+func (m *Machine) Advance(now time.Time) (Transition, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fired, ok := m.expired(now)
+	if !ok {
+		return Transition{}, false
+	}
+
+	if fired == TimerIdle && m.timeouts.IdleSuspend && m.phase == PhaseRunning {
+		// The phase is unchanged; the caller pauses the container, and Resume
+		// re-arms the timer once it is unpaused
+		m.paused = true
+		delete(m.deadlines, TimerIdle)
+		return Transition{From: m.phase, To: m.phase, Reason: ReasonIdleSuspend, At: now}, true
+	}
+
+	target := PhaseStopping
+	if fired == TimerCleanup {
+		target = PhaseRemoved
+	}
+
+	tr, err := m.transition(target, fired.Reason(), now)
+	if err != nil {
+		// A timer armed for a phase we have left; drop it
+		delete(m.deadlines, fired)
+		return Transition{}, false
+	}
+	return tr, true
+}
+
+// expired returns the armed timer with the earliest deadline at or before now
+func (m *Machine) expired(now time.Time) (Timer, bool) {
+	var fired Timer
+	var earliest time.Time
+	found := false
+
+	for _, timer := range timers {
+		deadline, ok := m.deadlines[timer]
+		if !ok || deadline.After(now) {
+			continue
+		}
+		if !found || deadline.Before(earliest) {
+			fired, earliest, found = timer, deadline, true
+		}
+	}
+
+	return fired, found
+}
+
+func (m *Machine) arm(timer Timer, now time.Time) {
+	if d := m.timeouts.duration(timer); d > 0 {
+		m.deadlines[timer] = now.Add(d)
+	}
+}
+
+// transition moves to phase, disarming every timer; must be called with m.mu held
+func (m *Machine) transition(to Phase, reason string, now time.Time) (Transition, error) {
+	legal := false
+	for _, target := range allowed[m.phase] {
+		if target == to {
+			legal = true
+			break
+		}
+	}
+	if !legal {
+		return Transition{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, m.phase, to)
+	}
+
+	tr := Transition{From: m.phase, To: to, Reason: reason, At: now}
+	m.phase = to
+
+	if to == PhaseStopping {
+		m.stopReason = reason
+		close(m.stopping)
+	}
+	// Timers are per phase; callers re-arm the ones the new phase needs
+	keep := map[Timer]time.Time{}
+	if to == PhaseRunning {
+		for _, timer := range []Timer{TimerHeartbeat, TimerRunner, TimerMaxLifetime} {
+			if deadline, ok := m.deadlines[timer]; ok {
+				keep[timer] = deadline
+			}
+		}
+	}
+	m.deadlines = keep
+
+	return tr, nil
+}
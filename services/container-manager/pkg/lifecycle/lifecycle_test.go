@@ -0,0 +1,664 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var epoch = time.Unix(1_700_000_000, 0)
+
+var testTimeouts = Timeouts{
+	Startup:     10 * time.Minute,
+	Heartbeat:   30 * time.Second,
+	Runner:      time.Minute,
+	Run:         time.Hour,
+	Idle:        5 * time.Minute,
+	MaxLifetime: 24 * time.Hour,
+	Cleanup:     time.Minute,
+	Disconnect:  2 * time.Minute,
+}
+
+// event is one input to the machine, applied at epoch+at
+type event struct {
+	at     time.Duration
+	action string // started, heartbeat, disconnected, runner, activity, pause, resume, stop, exited, remove, advance
+}
+
+func apply(m *Machine, e event) (Transition, bool, error) {
+	now := epoch.Add(e.at)
+	switch e.action {
+	case "started":
+		tr, err := m.Started(now)
+		return tr, err == nil, err
+	case "heartbeat":
+		m.Heartbeat(now)
+		return Transition{}, false, nil
+	case "disconnected":
+		m.Disconnected(now)
+		return Transition{}, false, nil
+	case "runner":
+		m.RunnerHeartbeat(now)
+		return Transition{}, false, nil
+	case "activity":
+		m.Activity(now)
+		return Transition{}, false, nil
+	case "pause":
+		m.Pause()
+		return Transition{}, false, nil
+	case "resume":
+		m.Resume(now)
+		return Transition{}, false, nil
+	case "stop":
+		tr, err := m.Stop(now, "client_requested")
+		return tr, err == nil, err
+	case "exited":
+		tr, err := m.Exited(now)
+		return tr, err == nil, err
+	case "remove":
+		tr, err := m.Remove(now, "manual")
+		return tr, err == nil, err
+	case "advance":
+		tr, ok := m.Advance(now)
+		return tr, ok, nil
+	default:
+		panic("unknown action " + e.action)
+	}
+}
+
+func TestMachineScenarios(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeouts   Timeouts
+		events     []event
+		wantPhase  Phase
+		wantReason string // reason of the last transition
+	}{
+		{
+			name:      "nothing expires before the first deadline",
+			timeouts:  testTimeouts,
+			events:    []event{{29 * time.Second, "advance"}},
+			wantPhase: PhaseStarting,
+		},
+		{
+			name:       "startup deadline",
+			timeouts:   Timeouts{Startup: 10 * time.Minute},
+			events:     []event{{10 * time.Minute, "advance"}},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonStartupTimeout,
+		},
+		{
+			name:     "startup deadline disarmed once running",
+			timeouts: Timeouts{Startup: 10 * time.Minute},
+			events: []event{
+				{time.Minute, "started"},
+				{time.Hour, "advance"},
+			},
+			wantPhase:  PhaseRunning,
+			wantReason: ReasonStarted,
+		},
+		{
+			name:       "heartbeat missed while starting",
+			timeouts:   testTimeouts,
+			events:     []event{{30 * time.Second, "advance"}},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonHeartbeatTimeout,
+		},
+		{
+			name:     "heartbeats keep the container alive",
+			timeouts: Timeouts{Heartbeat: 30 * time.Second},
+			events: []event{
+				{5 * time.Second, "started"},
+				{25 * time.Second, "heartbeat"},
+				{50 * time.Second, "heartbeat"},
+				{75 * time.Second, "advance"},
+			},
+			wantPhase:  PhaseRunning,
+			wantReason: ReasonStarted,
+		},
+		{
+			name:     "heartbeat missed while running",
+			timeouts: Timeouts{Heartbeat: 30 * time.Second},
+			events: []event{
+				{5 * time.Second, "started"},
+				{25 * time.Second, "heartbeat"},
+				{55 * time.Second, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonHeartbeatTimeout,
+		},
+		{
+			name:     "disconnect grace replaces the heartbeat",
+			timeouts: Timeouts{Heartbeat: 30 * time.Second, Disconnect: 5 * time.Minute},
+			events: []event{
+				{5 * time.Second, "started"},
+				{10 * time.Second, "disconnected"},
+				{time.Minute, "advance"},
+				{10*time.Second + 5*time.Minute, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonClientDisconnected,
+		},
+		{
+			name:     "heartbeat after a disconnect ends the grace period",
+			timeouts: Timeouts{Heartbeat: 30 * time.Second, Disconnect: 5 * time.Minute},
+			events: []event{
+				{5 * time.Second, "started"},
+				{10 * time.Second, "disconnected"},
+				{time.Minute, "heartbeat"},
+				{time.Minute + 20*time.Second, "advance"},
+				{time.Minute + 30*time.Second, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonHeartbeatTimeout,
+		},
+		{
+			name:     "detached container without a heartbeat or grace",
+			timeouts: Timeouts{Run: time.Hour},
+			events: []event{
+				{5 * time.Second, "started"},
+				{10 * time.Second, "disconnected"},
+				{59 * time.Minute, "advance"},
+			},
+			wantPhase:  PhaseRunning,
+			wantReason: ReasonStarted,
+		},
+		{
+			name:     "runner heartbeat is not required before the first one",
+			timeouts: Timeouts{Runner: time.Minute},
+			events: []event{
+				{5 * time.Second, "started"},
+				{time.Hour, "advance"},
+			},
+			wantPhase:  PhaseRunning,
+			wantReason: ReasonStarted,
+		},
+		{
+			name:     "runner heartbeats keep the container alive",
+			timeouts: Timeouts{Runner: time.Minute},
+			events: []event{
+				{time.Second, "runner"},
+				{5 * time.Second, "started"},
+				{50 * time.Second, "runner"},
+				{100 * time.Second, "runner"},
+				{150 * time.Second, "advance"},
+			},
+			wantPhase:  PhaseRunning,
+			wantReason: ReasonStarted,
+		},
+		{
+			name:     "runner goes silent while starting",
+			timeouts: Timeouts{Runner: time.Minute},
+			events: []event{
+				{time.Second, "runner"},
+				{61 * time.Second, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonRunnerUnresponsive,
+		},
+		{
+			name:     "runner goes silent while running",
+			timeouts: Timeouts{Runner: time.Minute},
+			events: []event{
+				{time.Second, "runner"},
+				{5 * time.Second, "started"},
+				{40 * time.Second, "runner"},
+				{100 * time.Second, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonRunnerUnresponsive,
+		},
+		{
+			name:     "run timeout counts from start",
+			timeouts: Timeouts{Run: time.Hour},
+			events: []event{
+				{10 * time.Minute, "started"},
+				{time.Hour, "advance"},
+				{70 * time.Minute, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonRunTimeout,
+		},
+		{
+			name:     "activity defers idle timeout",
+			timeouts: Timeouts{Idle: 5 * time.Minute},
+			events: []event{
+				{0, "started"},
+				{4 * time.Minute, "activity"},
+				{8 * time.Minute, "advance"},
+			},
+			wantPhase:  PhaseRunning,
+			wantReason: ReasonStarted,
+		},
+		{
+			name:     "idle timeout",
+			timeouts: Timeouts{Idle: 5 * time.Minute},
+			events: []event{
+				{0, "started"},
+				{4 * time.Minute, "activity"},
+				{9 * time.Minute, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonIdleTimeout,
+		},
+		{
+			name:     "idle timeout suspended while paused",
+			timeouts: Timeouts{Idle: 5 * time.Minute},
+			events: []event{
+				{0, "started"},
+				{time.Minute, "pause"},
+				{30 * time.Minute, "activity"},
+				{time.Hour, "advance"},
+			},
+			wantPhase:  PhaseRunning,
+			wantReason: ReasonStarted,
+		},
+		{
+			name:     "idle timeout counts again from resume",
+			timeouts: Timeouts{Idle: 5 * time.Minute},
+			events: []event{
+				{0, "started"},
+				{time.Minute, "pause"},
+				{time.Hour, "resume"},
+				{64 * time.Minute, "advance"},
+				{65 * time.Minute, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonIdleTimeout,
+		},
+		{
+			name:     "idle suspend keeps the container running",
+			timeouts: Timeouts{Idle: 5 * time.Minute, IdleSuspend: true},
+			events: []event{
+				{0, "started"},
+				{5 * time.Minute, "advance"},
+			},
+			wantPhase:  PhaseRunning,
+			wantReason: ReasonIdleSuspend,
+		},
+		{
+			name:     "idle suspend lets other timers stop the container",
+			timeouts: Timeouts{Run: time.Hour, Idle: 5 * time.Minute, IdleSuspend: true},
+			events: []event{
+				{0, "started"},
+				{5 * time.Minute, "advance"},
+				{30 * time.Minute, "activity"},
+				{time.Hour, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonRunTimeout,
+		},
+		{
+			name:     "activity before start does not arm idle",
+			timeouts: Timeouts{Idle: 5 * time.Minute},
+			events: []event{
+				{0, "activity"},
+				{time.Hour, "advance"},
+			},
+			wantPhase: PhaseStarting,
+		},
+		{
+			name:     "max lifetime ignores heartbeats and activity",
+			timeouts: Timeouts{Heartbeat: time.Hour, Idle: time.Hour, MaxLifetime: 2 * time.Hour},
+			events: []event{
+				{0, "started"},
+				{50 * time.Minute, "heartbeat"},
+				{50 * time.Minute, "activity"},
+				{100 * time.Minute, "heartbeat"},
+				{100 * time.Minute, "activity"},
+				{2 * time.Hour, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonMaxLifetimeExceeded,
+		},
+		{
+			name:     "earliest expired timer wins",
+			timeouts: Timeouts{Run: 10 * time.Minute, Idle: 5 * time.Minute},
+			events: []event{
+				{0, "started"},
+				{time.Hour, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: ReasonIdleTimeout,
+		},
+		{
+			name:     "client stop",
+			timeouts: testTimeouts,
+			events: []event{
+				{0, "started"},
+				{time.Second, "stop"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: "client_requested",
+		},
+		{
+			name:     "stopping disarms timers",
+			timeouts: testTimeouts,
+			events: []event{
+				{0, "started"},
+				{time.Second, "stop"},
+				{48 * time.Hour, "advance"},
+			},
+			wantPhase:  PhaseStopping,
+			wantReason: "client_requested",
+		},
+		{
+			name:     "natural exit arms cleanup",
+			timeouts: testTimeouts,
+			events: []event{
+				{0, "started"},
+				{10 * time.Second, "exited"},
+				{69 * time.Second, "advance"},
+			},
+			wantPhase:  PhaseExited,
+			wantReason: ReasonExited,
+		},
+		{
+			name:     "cleanup removes exited container",
+			timeouts: testTimeouts,
+			events: []event{
+				{0, "started"},
+				{10 * time.Second, "exited"},
+				{70 * time.Second, "advance"},
+			},
+			wantPhase:  PhaseRemoved,
+			wantReason: ReasonCleanup,
+		},
+		{
+			name:     "exit after timer-initiated stop",
+			timeouts: testTimeouts,
+			events: []event{
+				{30 * time.Second, "advance"},
+				{33 * time.Second, "exited"},
+			},
+			wantPhase:  PhaseExited,
+			wantReason: ReasonExited,
+		},
+		{
+			name:     "exit before start",
+			timeouts: testTimeouts,
+			events: []event{
+				{time.Second, "exited"},
+			},
+			wantPhase:  PhaseExited,
+			wantReason: ReasonExited,
+		},
+		{
+			name:     "manual removal",
+			timeouts: testTimeouts,
+			events: []event{
+				{0, "exited"},
+				{time.Second, "remove"},
+			},
+			wantPhase:  PhaseRemoved,
+			wantReason: "manual",
+		},
+		{
+			name:     "no cleanup timer leaves exited container",
+			timeouts: Timeouts{},
+			events: []event{
+				{0, "exited"},
+				{48 * time.Hour, "advance"},
+			},
+			wantPhase:  PhaseExited,
+			wantReason: ReasonExited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.timeouts, epoch)
+			var last Transition
+
+			for _, e := range tt.events {
+				tr, ok, err := apply(m, e)
+				if err != nil {
+					t.Fatalf("%s at %s: %v", e.action, e.at, err)
+				}
+				if ok {
+					if tr.To != m.Phase() {
+						t.Fatalf("%s returned transition to %s but phase is %s", e.action, tr.To, m.Phase())
+					}
+					last = tr
+				}
+			}
+
+			if got := m.Phase(); got != tt.wantPhase {
+				t.Errorf("phase = %s, want %s", got, tt.wantPhase)
+			}
+			if last.Reason != tt.wantReason {
+				t.Errorf("last reason = %q, want %q", last.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+// TestMachineTransitions checks every event in every phase against the table
+// of legal transitions
+func TestMachineTransitions(t *testing.T) {
+	// Events that produce a transition, and the phase each targets
+	events := map[string]Phase{
+		"started": PhaseRunning,
+		"stop":    PhaseStopping,
+		"exited":  PhaseExited,
+		"remove":  PhaseRemoved,
+	}
+
+	// Steps that bring a fresh machine into each phase
+	reach := map[Phase][]string{
+		PhaseStarting: nil,
+		PhaseRunning:  {"started"},
+		PhaseStopping: {"stop"},
+		PhaseExited:   {"exited"},
+		PhaseRemoved:  {"exited", "remove"},
+	}
+
+	for from, steps := range reach {
+		for action, to := range events {
+			t.Run(from.String()+"/"+action, func(t *testing.T) {
+				m := New(testTimeouts, epoch)
+				for _, step := range steps {
+					if _, _, err := apply(m, event{action: step}); err != nil {
+						t.Fatalf("setup step %s: %v", step, err)
+					}
+				}
+
+				legal := false
+				for _, target := range allowed[from] {
+					legal = legal || target == to
+				}
+
+				tr, _, err := apply(m, event{at: time.Second, action: action})
+				if legal {
+					if err != nil {
+						t.Fatalf("expected %s -> %s to be legal: %v", from, to, err)
+					}
+					if tr.From != from || tr.To != to || !tr.At.Equal(epoch.Add(time.Second)) {
+						t.Errorf("unexpected transition %+v", tr)
+					}
+				} else {
+					if !errors.Is(err, ErrInvalidTransition) {
+						t.Fatalf("expected ErrInvalidTransition for %s -> %s, got %v", from, to, err)
+					}
+					if m.Phase() != from {
+						t.Errorf("phase changed to %s after rejected event", m.Phase())
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestMachineArmedTimers(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []string
+		armed []Timer
+	}{
+		{"starting", nil, []Timer{TimerStartup, TimerHeartbeat, TimerMaxLifetime}},
+		{"running", []string{"started"}, []Timer{TimerHeartbeat, TimerRun, TimerIdle, TimerMaxLifetime}},
+		{"paused", []string{"started", "pause"}, []Timer{TimerHeartbeat, TimerRun, TimerMaxLifetime}},
+		{"resumed", []string{"started", "pause", "resume"}, []Timer{TimerHeartbeat, TimerRun, TimerIdle, TimerMaxLifetime}},
+		{"runner heartbeat", []string{"runner", "started"}, []Timer{TimerHeartbeat, TimerRunner, TimerRun, TimerIdle, TimerMaxLifetime}},
+		{"runner heartbeat while stopping", []string{"stop", "runner"}, nil},
+		{"disconnected", []string{"started", "disconnected"}, []Timer{TimerDisconnect, TimerRun, TimerIdle, TimerMaxLifetime}},
+		{"reconnected", []string{"started", "disconnected", "heartbeat"}, []Timer{TimerHeartbeat, TimerRun, TimerIdle, TimerMaxLifetime}},
+		{"stopping", []string{"stop"}, nil},
+		{"exited", []string{"exited"}, []Timer{TimerCleanup}},
+		{"removed", []string{"exited", "remove"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(testTimeouts, epoch)
+			for _, step := range tt.steps {
+				if _, _, err := apply(m, event{action: step}); err != nil {
+					t.Fatalf("step %s: %v", step, err)
+				}
+			}
+
+			want := make(map[Timer]bool)
+			for _, timer := range tt.armed {
+				want[timer] = true
+			}
+			for _, timer := range timers {
+				if _, ok := m.Deadline(timer); ok != want[timer] {
+					t.Errorf("%s armed = %v, want %v", timer.Reason(), ok, want[timer])
+				}
+			}
+		})
+	}
+}
+
+func TestMachineDisabledTimers(t *testing.T) {
+	m := New(Timeouts{}, epoch)
+	if _, err := m.Started(epoch); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, timer := range timers {
+		if _, ok := m.Deadline(timer); ok {
+			t.Errorf("%s armed with zero timeout", timer.Reason())
+		}
+	}
+	if _, ok := m.Advance(epoch.Add(1000 * time.Hour)); ok {
+		t.Error("expected nothing to fire with every timer disabled")
+	}
+}
+
+func TestMachineExtendRun(t *testing.T) {
+	timeouts := Timeouts{Run: time.Hour, MaxRunExtension: 30 * time.Minute}
+
+	// Before the container starts, the run timeout itself grows
+	m := New(timeouts, epoch)
+	if deadline, err := m.ExtendRun(10 * time.Minute); err != nil || !deadline.IsZero() {
+		t.Fatalf("ExtendRun() while starting = %v, %v", deadline, err)
+	}
+	started := epoch.Add(time.Minute)
+	if _, err := m.Started(started); err != nil {
+		t.Fatal(err)
+	}
+	if deadline, _ := m.Deadline(TimerRun); !deadline.Equal(started.Add(70 * time.Minute)) {
+		t.Errorf("run deadline = %v, want 70m after the start", deadline)
+	}
+
+	deadline, err := m.ExtendRun(20 * time.Minute)
+	if want := started.Add(90 * time.Minute); err != nil || !deadline.Equal(want) {
+		t.Errorf("ExtendRun() = %v, %v; want %v", deadline, err, want)
+	}
+	if _, err := m.ExtendRun(time.Second); !errors.Is(err, ErrExtensionLimit) {
+		t.Errorf("ExtendRun() past the limit error = %v, want ErrExtensionLimit", err)
+	}
+	if m.Extended() != 30*time.Minute {
+		t.Errorf("Extended() = %v, want 30m", m.Extended())
+	}
+	if tr, ok := m.Advance(started.Add(90 * time.Minute)); !ok || tr.Reason != ReasonRunTimeout {
+		t.Errorf("Advance() at the extended deadline = %v, %v", tr, ok)
+	}
+	if _, err := m.ExtendRun(time.Second); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("ExtendRun() while stopping error = %v, want ErrInvalidTransition", err)
+	}
+
+	timeouts.Run = 0
+	if _, err := New(timeouts, epoch).ExtendRun(time.Minute); !errors.Is(err, ErrNoRunTimeout) {
+		t.Errorf("ExtendRun() without a run timeout error = %v, want ErrNoRunTimeout", err)
+	}
+}
+
+func TestMachineStopping(t *testing.T) {
+	m := New(testTimeouts, epoch)
+
+	select {
+	case <-m.Stopping():
+		t.Fatal("Stopping closed before stop")
+	default:
+	}
+
+	if _, ok := m.Advance(epoch.Add(30 * time.Second)); !ok {
+		t.Fatal("expected heartbeat timer to fire")
+	}
+
+	select {
+	case <-m.Stopping():
+	default:
+		t.Fatal("Stopping not closed after stop")
+	}
+	if got := m.StopReason(); got != ReasonHeartbeatTimeout {
+		t.Errorf("StopReason() = %q, want %q", got, ReasonHeartbeatTimeout)
+	}
+
+	// The stop reason survives the exit that follows
+	if _, err := m.Exited(epoch.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.StopReason(); got != ReasonHeartbeatTimeout {
+		t.Errorf("StopReason() after exit = %q, want %q", got, ReasonHeartbeatTimeout)
+	}
+}
+
+func TestMachineNaturalExitHasNoStopReason(t *testing.T) {
+	m := New(testTimeouts, epoch)
+	m.Started(epoch)
+	m.Exited(epoch.Add(time.Second))
+
+	if got := m.StopReason(); got != "" {
+		t.Errorf("StopReason() = %q, want empty", got)
+	}
+	select {
+	case <-m.Stopping():
+		t.Error("Stopping closed for a natural exit")
+	default:
+	}
+}
+
+func TestStringers(t *testing.T) {
+	phases := map[Phase]string{
+		PhaseStarting: "starting",
+		PhaseRunning:  "running",
+		PhaseStopping: "stopping",
+		PhaseExited:   "exited",
+		PhaseRemoved:  "removed",
+		Phase(99):     "phase(99)",
+	}
+	for phase, want := range phases {
+		if got := phase.String(); got != want {
+			t.Errorf("Phase(%d).String() = %q, want %q", int(phase), got, want)
+		}
+	}
+
+	reasons := map[Timer]string{
+		TimerStartup:     ReasonStartupTimeout,
+		TimerHeartbeat:   ReasonHeartbeatTimeout,
+		TimerRunner:      ReasonRunnerUnresponsive,
+		TimerRun:         ReasonRunTimeout,
+		TimerIdle:        ReasonIdleTimeout,
+		TimerMaxLifetime: ReasonMaxLifetimeExceeded,
+		TimerCleanup:     ReasonCleanup,
+		TimerDisconnect:  ReasonClientDisconnected,
+	}
+	for timer, want := range reasons {
+		if got := timer.Reason(); got != want {
+			t.Errorf("Timer(%d).Reason() = %q, want %q", int(timer), got, want)
+		}
+	}
+}
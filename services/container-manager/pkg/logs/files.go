@@ -0,0 +1,404 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+const (
+	// DefaultMaxFileBytes is the size past which a container's log file is
+	// rotated
+	DefaultMaxFileBytes = 16 << 20
+	// DefaultMaxFiles is how many rotated files are kept per container
+	DefaultMaxFiles = 4
+	// DefaultRetention is how long the logs of a container are kept after it
+	// last wrote to them
+	DefaultRetention = 7 * 24 * time.Hour
+
+	// logFileName is the file a container's logs are written to; rotated
+	// files get a suffix counting up from the most recent, as output.log.1
+	logFileName = "output.log"
+)
+
+// ErrNotFound is returned when no logs are stored for a container
+var ErrNotFound = errors.New("no logs stored")
+
+// FilesConfig sets where logs are stored and how much of them is kept
+type FilesConfig struct {
+	Dir string
+	// MaxFileBytes is the size past which a log file is rotated; values <= 0
+	// use DefaultMaxFileBytes
+	MaxFileBytes int64
+	// MaxFiles is how many rotated files are kept besides the current one
+	MaxFiles int
+	// Retention is how long logs are kept after they were last written to;
+	// 0 keeps them until the container's ID is reused
+	Retention time.Duration
+}
+
+// FilesConfigFromEnv reads HOLOPOD_LOG_DIR, LOG_FILE_MAX_BYTES,
+// LOG_FILE_MAX_FILES and LOG_RETENTION, falling back to the defaults for unset
+// variables. Logs are stored only when HOLOPOD_LOG_DIR is set.
+func FilesConfigFromEnv() (FilesConfig, error) {
+	config := FilesConfig{
+		Dir:          os.Getenv("HOLOPOD_LOG_DIR"),
+		MaxFileBytes: DefaultMaxFileBytes,
+		MaxFiles:     DefaultMaxFiles,
+		Retention:    DefaultRetention,
+	}
+
+	if value := os.Getenv("LOG_FILE_MAX_BYTES"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			return FilesConfig{}, fmt.Errorf("invalid LOG_FILE_MAX_BYTES %q", value)
+		}
+		config.MaxFileBytes = n
+	}
+	if value := os.Getenv("LOG_FILE_MAX_FILES"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return FilesConfig{}, fmt.Errorf("invalid LOG_FILE_MAX_FILES %q", value)
+		}
+		config.MaxFiles = n
+	}
+	if value := os.Getenv("LOG_RETENTION"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return FilesConfig{}, fmt.Errorf("invalid LOG_RETENTION %q", value)
+		}
+		config.Retention = d
+	}
+
+	return config, nil
+}
+
+// Files stores the logs of containers on disk, in a directory per container,
+// so they can still be read once the container is gone
+type Files struct {
+	config FilesConfig
+
+	mu   sync.Mutex
+	open map[string]*File // By directory; never pruned while open
+}
+
+// OpenFiles stores logs under config.Dir, creating it if needed
+func OpenFiles(config FilesConfig) (*Files, error) {
+	if config.MaxFileBytes <= 0 {
+		config.MaxFileBytes = DefaultMaxFileBytes
+	}
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	return &Files{
+		config: config,
+		open:   make(map[string]*File),
+	}, nil
+}
+
+// containerDir is where the logs of a container are. Container IDs are hashed
+// so that any ID makes a short, safe file name.
+func (f *Files) containerDir(containerID string) string {
+	sum := sha256.Sum256([]byte(containerID))
+	return filepath.Join(f.config.Dir, hex.EncodeToString(sum[:12]))
+}
+
+// Create starts the logs of a new container, removing any left by an earlier
+// container with the same ID
+func (f *Files) Create(containerID string) (*File, error) {
+	dir := f.containerDir(containerID)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to remove old logs: %w", err)
+	}
+	return f.Open(containerID)
+}
+
+// Open appends to the logs of a container, as one a restarted manager has
+// reattached to
+func (f *Files) Open(containerID string) (*File, error) {
+	dir := f.containerDir(containerID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	lf := &File{files: f, dir: dir, file: file, size: info.Size()}
+	f.mu.Lock()
+	f.open[dir] = lf
+	f.mu.Unlock()
+	return lf, nil
+}
+
+// Load adds the most recent lines stored for a container to r, as many as it
+// keeps, so a reattached container's logs go back further than the restart
+func (f *Files) Load(r *Ring, containerID string) error {
+	r.mu.Lock()
+	tail := r.maxLines
+	r.mu.Unlock()
+
+	var lines []Line
+	err := f.Read(context.Background(), containerID, Query{Tail: tail}, func(line Line) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	r.restore(lines)
+	return nil
+}
+
+// record is a line as stored, one JSON object per line of the file
+type record struct {
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"`
+	Data   string    `json:"data"`
+}
+
+// Read passes the stored lines of a container that q selects to fn, oldest
+// first. Nothing is followed: a container whose logs are read from disk has
+// no more to write.
+func (f *Files) Read(ctx context.Context, containerID string, q Query, fn func(Line) error) error {
+	names, err := logFiles(f.containerDir(containerID))
+	if err != nil {
+		return err
+	}
+
+	var lines []Line
+	for _, name := range names {
+		if err := readFile(name, func(line Line) {
+			if !q.matches(line) {
+				return
+			}
+			lines = append(lines, line)
+			// Keep no more than twice the tail around
+			if q.Tail > 0 && len(lines) >= 2*q.Tail {
+				lines = append(lines[:0], lines[len(lines)-q.Tail:]...)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+	if q.Tail > 0 && len(lines) > q.Tail {
+		lines = lines[len(lines)-q.Tail:]
+	}
+
+	for _, line := range lines {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logFiles returns the log files in dir, oldest first
+func logFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	type logFile struct {
+		name  string
+		index int // 0 for the current file, higher for older ones
+	}
+	var files []logFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == logFileName {
+			files = append(files, logFile{name, 0})
+		} else if suffix, ok := strings.CutPrefix(name, logFileName+"."); ok {
+			if index, err := strconv.Atoi(suffix); err == nil && index > 0 {
+				files = append(files, logFile{name, index})
+			}
+		}
+	}
+	slices.SortFunc(files, func(a, b logFile) int { return b.index - a.index })
+
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = filepath.Join(dir, file.name)
+	}
+	return names, nil
+}
+
+// readFile passes every line stored in a log file to fn, skipping any it
+// cannot parse, such as one cut short by a crash
+func readFile(name string, fn func(Line)) error {
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		// Rotated away since it was listed
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		data, err := reader.ReadBytes('\n')
+		if len(data) > 0 {
+			var rec record
+			if json.Unmarshal(data, &rec) == nil {
+				fn(Line{
+					Time:   rec.Time,
+					Stream: pb.OutputStream(pb.OutputStream_value[strings.ToUpper(rec.Stream)]),
+					Data:   []byte(rec.Data),
+				})
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+	}
+}
+
+// Prune removes the logs of containers that were last written to before the
+// retention period, except those still open, and returns how many it removed
+func (f *Files) Prune(now time.Time) (int, error) {
+	if f.config.Retention <= 0 {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(f.config.Dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	cutoff := now.Add(-f.config.Retention)
+	pruned := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(f.config.Dir, entry.Name())
+
+		f.mu.Lock()
+		_, open := f.open[dir]
+		f.mu.Unlock()
+		if open || lastWritten(dir).After(cutoff) {
+			continue
+		}
+
+		if err := os.RemoveAll(dir); err != nil {
+			return pruned, fmt.Errorf("failed to remove logs: %w", err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// lastWritten returns when a log file in dir was last modified
+func lastWritten(dir string) time.Time {
+	var last time.Time
+	names, _ := logFiles(dir)
+	for _, name := range names {
+		if info, err := os.Stat(name); err == nil && info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last
+}
+
+// File is the log file of one container, rotated as it grows
+type File struct {
+	files *Files
+	dir   string
+	file  *os.File
+	size  int64
+}
+
+// write appends a line, rotating the file first when the line would take it
+// past its size limit
+func (lf *File) write(line Line) error {
+	data, err := json.Marshal(record{
+		Time:   line.Time,
+		Stream: strings.ToLower(line.Stream.String()),
+		Data:   string(line.Data),
+	})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if lf.size > 0 && lf.size+int64(len(data)) > lf.files.config.MaxFileBytes {
+		if err := lf.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := lf.file.Write(data)
+	lf.size += int64(n)
+	return err
+}
+
+// rotate shifts every file one suffix up, dropping the oldest past MaxFiles,
+// and starts a new current file
+func (lf *File) rotate() error {
+	lf.file.Close()
+
+	name := func(index int) string {
+		if index == 0 {
+			return filepath.Join(lf.dir, logFileName)
+		}
+		return filepath.Join(lf.dir, logFileName+"."+strconv.Itoa(index))
+	}
+
+	maxFiles := lf.files.config.MaxFiles
+	os.Remove(name(maxFiles))
+	for index := maxFiles - 1; index >= 0; index-- {
+		if err := os.Rename(name(index), name(index+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(name(0), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	lf.file = file
+	lf.size = 0
+	return nil
+}
+
+// Close closes the file; its logs are kept until pruned
+func (lf *File) Close() error {
+	lf.files.mu.Lock()
+	delete(lf.files.open, lf.dir)
+	lf.files.mu.Unlock()
+
+	return lf.file.Close()
+}
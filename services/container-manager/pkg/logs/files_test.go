@@ -0,0 +1,173 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+func readStored(t *testing.T, f *Files, containerID string, q Query) []string {
+	t.Helper()
+
+	var got []string
+	err := f.Read(context.Background(), containerID, q, func(line Line) error {
+		got = append(got, strings.ToLower(line.Stream.String())+":"+string(line.Data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return got
+}
+
+func TestFilesRotate(t *testing.T) {
+	f, err := OpenFiles(FilesConfig{Dir: t.TempDir(), MaxFileBytes: 200, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := f.Create("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRing(0, 0)
+	r.Persist(file)
+	for i := range 20 {
+		r.Write(pb.OutputStream_STDOUT, []byte(strings.Repeat("x", i)+"\n"))
+	}
+	r.Append(pb.OutputStream_EVENTS, []byte(`{"type":"container_exited"}`))
+	r.Close()
+
+	names, err := logFiles(f.containerDir("c1"))
+	if err != nil || len(names) != 3 {
+		t.Fatalf("logFiles() = %v, %v, want the current file and 2 rotated ones", names, err)
+	}
+
+	// Rotation dropped the oldest lines; the rest read back in order
+	got := readStored(t, f, "c1", Query{})
+	if len(got) == 0 || len(got) >= 21 {
+		t.Fatalf("Read() = %d lines, want some but not all of 21", len(got))
+	}
+	for i := 1; i < len(got)-1; i++ {
+		if len(got[i]) != len(got[i-1])+1 {
+			t.Fatalf("Read() = %q, want lines in the order written", got)
+		}
+	}
+	if got[len(got)-1] != `events:{"type":"container_exited"}` {
+		t.Errorf("last line = %q, want the event", got[len(got)-1])
+	}
+
+	got = readStored(t, f, "c1", Query{Tail: 2, Streams: []pb.OutputStream{pb.OutputStream_STDOUT}})
+	want := []string{"stdout:" + strings.Repeat("x", 18) + "\n", "stdout:" + strings.Repeat("x", 19) + "\n"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Read() tail = %q, want %q", got, want)
+	}
+
+	if err := f.Read(context.Background(), "c2", Query{}, func(Line) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Read() of unknown container error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFilesCreateAndLoad(t *testing.T) {
+	f, err := OpenFiles(FilesConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(open func(string) (*File, error), lines ...string) {
+		file, err := open("c1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewRing(0, 0)
+		r.Persist(file)
+		for _, line := range lines {
+			r.Write(pb.OutputStream_STDOUT, []byte(line))
+		}
+		r.Close()
+	}
+
+	// Open appends, as for a reattached container; Create starts over
+	write(f.Create, "old\n")
+	write(f.Create, "one\n")
+	write(f.Open, "two\n")
+	if got := readStored(t, f, "c1", Query{}); strings.Join(got, "") != "stdout:one\nstdout:two\n" {
+		t.Errorf("Read() = %q", got)
+	}
+
+	// Loading fills a ring with as many lines as it keeps, times and all
+	r := NewRing(1, 0)
+	if err := f.Load(r, "c1"); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	r.Read(context.Background(), Query{}, func(line Line) error {
+		if line.Time.IsZero() {
+			t.Errorf("line %q lost its time", line.Data)
+		}
+		got = append(got, string(line.Data))
+		return nil
+	})
+	if strings.Join(got, "") != "two\n" {
+		t.Errorf("ring = %q, want the last stored line", got)
+	}
+
+	if err := f.Load(NewRing(0, 0), "unknown"); err != nil {
+		t.Errorf("Load() of a container without logs error = %v", err)
+	}
+}
+
+func TestFilesPrune(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenFiles(FilesConfig{Dir: dir, Retention: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed, _ := f.Create("closed")
+	closed.Close()
+	open, _ := f.Create("open")
+	defer open.Close()
+
+	old := time.Now().Add(-2 * time.Hour)
+	for _, id := range []string{"closed", "open"} {
+		os.Chtimes(filepath.Join(f.containerDir(id), logFileName), old, old)
+	}
+
+	if pruned, err := f.Prune(time.Now()); err != nil || pruned != 1 {
+		t.Fatalf("Prune() = %d, %v, want 1", pruned, err)
+	}
+	if _, err := os.Stat(f.containerDir("closed")); !os.IsNotExist(err) {
+		t.Error("logs past retention were kept")
+	}
+	if _, err := os.Stat(f.containerDir("open")); err != nil {
+		t.Error("logs still being written were pruned")
+	}
+}
+
+func TestFilesConfigFromEnv(t *testing.T) {
+	config, err := FilesConfigFromEnv()
+	if err != nil || config.Dir != "" || config.MaxFileBytes != DefaultMaxFileBytes || config.Retention != DefaultRetention {
+		t.Errorf("FilesConfigFromEnv() = %+v, %v, want the defaults", config, err)
+	}
+
+	t.Setenv("HOLOPOD_LOG_DIR", "/var/log/holopod")
+	t.Setenv("LOG_FILE_MAX_BYTES", "1024")
+	t.Setenv("LOG_FILE_MAX_FILES", "0")
+	t.Setenv("LOG_RETENTION", "24h")
+	config, err = FilesConfigFromEnv()
+	if err != nil || config != (FilesConfig{Dir: "/var/log/holopod", MaxFileBytes: 1024, Retention: 24 * time.Hour}) {
+		t.Errorf("FilesConfigFromEnv() = %+v, %v", config, err)
+	}
+
+	t.Setenv("LOG_RETENTION", "a week")
+	if _, err := FilesConfigFromEnv(); err == nil {
+		t.Error("FilesConfigFromEnv() accepted an invalid retention")
+	}
+}
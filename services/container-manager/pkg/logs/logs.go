@@ -0,0 +1,323 @@
+// Package logs keeps a container's recent stdout, stderr and events as
+// timestamped lines, bounded by a line count and a byte size, for clients that
+// ask for its logs and for those that follow them as they are written. Lines
+// can also be stored in rotated files, so they outlive the container.
+package logs
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+const (
+	// DefaultMaxLines bounds the lines a ring keeps
+	DefaultMaxLines = 10000
+	// DefaultMaxBytes bounds the data of the lines a ring keeps
+	DefaultMaxBytes = 8 << 20
+	// MaxLineBytes is where a line without a newline is cut, so output that
+	// never ends a line still shows up
+	MaxLineBytes = 16 << 10
+)
+
+// Line is one line of a container's output, or one event. Data keeps the
+// line's newline, if it had one.
+type Line struct {
+	Seq    uint64 // Position among every line written to the ring, from 1
+	Time   time.Time
+	Stream pb.OutputStream
+	Data   []byte
+}
+
+// Query selects lines from a ring
+type Query struct {
+	// Since skips lines written before it; zero means from the start
+	Since time.Time
+	// Tail keeps only the last Tail lines selected; <= 0 means all of them
+	Tail int
+	// Streams selects outputs; empty means all
+	Streams []pb.OutputStream
+	// Follow keeps reading lines as they are written until the ring closes
+	Follow bool
+}
+
+func (q Query) matches(line Line) bool {
+	if !q.Since.IsZero() && line.Time.Before(q.Since) {
+		return false
+	}
+	if len(q.Streams) == 0 {
+		return true
+	}
+	for _, stream := range q.Streams {
+		if stream == line.Stream {
+			return true
+		}
+	}
+	return false
+}
+
+// Ring holds the most recent lines of a container's output, dropping the
+// oldest past its limits
+type Ring struct {
+	mu       sync.Mutex
+	lines    []Line
+	size     int // Bytes of data in lines
+	maxLines int
+	maxBytes int
+	nextSeq  uint64
+	dropped  uint64
+	partial  map[pb.OutputStream][]byte // Output not yet ended by a newline
+	file     *File                      // Where lines are also stored, if set
+	now      func() time.Time
+	changed  chan struct{} // Closed and replaced on every write
+	closed   bool
+}
+
+// NewRing returns an empty ring keeping up to maxLines lines and maxBytes of
+// data; values <= 0 use the defaults
+func NewRing(maxLines, maxBytes int) *Ring {
+	r := &Ring{
+		nextSeq: 1,
+		partial: make(map[pb.OutputStream][]byte),
+		now:     time.Now,
+		changed: make(chan struct{}),
+	}
+	r.SetLimits(maxLines, maxBytes)
+	return r
+}
+
+// SetLimits changes how many lines and bytes the ring keeps, as NewRing
+func (r *Ring) SetLimits(maxLines, maxBytes int) {
+	if maxLines <= 0 {
+		maxLines = DefaultMaxLines
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxLines, r.maxBytes = maxLines, maxBytes
+	r.trim()
+}
+
+// Write adds a piece of stdout or stderr, splitting it into lines. The end of
+// data not followed by a newline waits for the rest of its line.
+func (r *Ring) Write(stream pb.OutputStream, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed || len(data) == 0 {
+		return
+	}
+
+	buf := append(r.partial[stream], data...)
+	for {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 && len(buf) < MaxLineBytes {
+			break
+		}
+		end := MaxLineBytes
+		if i >= 0 && i < MaxLineBytes {
+			end = i + 1
+		}
+		r.add(stream, bytes.Clone(buf[:end]))
+		buf = buf[end:]
+	}
+
+	if len(buf) > 0 {
+		r.partial[stream] = bytes.Clone(buf)
+	} else {
+		delete(r.partial, stream)
+	}
+	r.notify()
+}
+
+// Append adds a whole line, such as an event
+func (r *Ring) Append(stream pb.OutputStream, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+	r.add(stream, data)
+	r.notify()
+}
+
+// Close ends the ring: partial lines are added as they are and followers stop
+// once they have read everything. Later writes are ignored.
+func (r *Ring) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+	for _, stream := range []pb.OutputStream{pb.OutputStream_STDOUT, pb.OutputStream_STDERR} {
+		if data := r.partial[stream]; len(data) > 0 {
+			r.add(stream, data)
+		}
+	}
+	clear(r.partial)
+	r.closed = true
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			log.Printf("Failed to close log file: %v", err)
+		}
+		r.file = nil
+	}
+	r.notify()
+}
+
+// Persist stores every line written from now on in f as well, until the ring
+// is closed, which closes f
+func (r *Ring) Persist(f *File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		f.Close()
+		return
+	}
+	r.file = f
+}
+
+// Stats returns how many lines and bytes the ring holds and how many lines it
+// has dropped to stay within its limits
+func (r *Ring) Stats() (lines, size int, dropped uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.lines), r.size, r.dropped
+}
+
+// add appends a line and trims the ring. The caller must hold r.mu.
+func (r *Ring) add(stream pb.OutputStream, data []byte) {
+	r.lines = append(r.lines, Line{
+		Seq:    r.nextSeq,
+		Time:   r.now(),
+		Stream: stream,
+		Data:   data,
+	})
+	r.nextSeq++
+	r.size += len(data)
+	r.trim()
+
+	if r.file != nil {
+		if err := r.file.write(r.lines[len(r.lines)-1]); err != nil {
+			// Keep the lines in memory rather than fail the output
+			log.Printf("Failed to write log file, no longer storing logs: %v", err)
+			r.file.Close()
+			r.file = nil
+		}
+	}
+}
+
+// restore adds lines read back from a log file, keeping their times, to a ring
+// nothing was written to yet. They are not stored again.
+func (r *Ring) restore(lines []Line) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, line := range lines {
+		line.Seq = r.nextSeq
+		r.nextSeq++
+		r.lines = append(r.lines, line)
+		r.size += len(line.Data)
+	}
+	r.trim()
+}
+
+// Inherit adds the lines prev holds, keeping their times, to a ring nothing
+// was written to yet, so the logs of a restarted container go back to its
+// earlier runs. They are not stored again.
+func (r *Ring) Inherit(prev *Ring) {
+	prev.mu.Lock()
+	lines := slices.Clone(prev.lines)
+	prev.mu.Unlock()
+
+	r.restore(lines)
+}
+
+// trim drops the oldest lines beyond the limits, always keeping the newest.
+// The caller must hold r.mu.
+func (r *Ring) trim() {
+	drop := 0
+	for len(r.lines)-drop > 1 && (len(r.lines)-drop > r.maxLines || r.size > r.maxBytes) {
+		r.size -= len(r.lines[drop].Data)
+		drop++
+	}
+	if drop == 0 {
+		return
+	}
+	clear(r.lines[:drop])
+	r.lines = r.lines[drop:]
+	r.dropped += uint64(drop)
+}
+
+// notify wakes followers. The caller must hold r.mu.
+func (r *Ring) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Read passes the lines q selects to fn, oldest first. With q.Follow it then
+// passes every matching line written afterwards, until the ring is closed or
+// ctx ends. It stops early with fn's error.
+func (r *Ring) Read(ctx context.Context, q Query, fn func(Line) error) error {
+	r.mu.Lock()
+	var lines []Line
+	for _, line := range r.lines {
+		if q.matches(line) {
+			lines = append(lines, line)
+		}
+	}
+	if q.Tail > 0 && len(lines) > q.Tail {
+		lines = lines[len(lines)-q.Tail:]
+	}
+	next := r.nextSeq
+	r.mu.Unlock()
+
+	for {
+		for _, line := range lines {
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+		if !q.Follow {
+			return nil
+		}
+
+		r.mu.Lock()
+		for next == r.nextSeq && !r.closed {
+			changed := r.changed
+			r.mu.Unlock()
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			r.mu.Lock()
+		}
+		if next == r.nextSeq {
+			// Closed, and everything was read
+			r.mu.Unlock()
+			return nil
+		}
+
+		// Lines written since; those already dropped are missed
+		lines = lines[:0]
+		for _, line := range r.lines {
+			if line.Seq >= next && q.matches(line) {
+				lines = append(lines, line)
+			}
+		}
+		next = r.nextSeq
+		r.mu.Unlock()
+	}
+}
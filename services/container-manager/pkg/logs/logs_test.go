@@ -0,0 +1,170 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+// readAll returns the data of the lines q selects from r
+func readAll(t *testing.T, r *Ring, q Query) []string {
+	t.Helper()
+
+	var got []string
+	err := r.Read(context.Background(), q, func(line Line) error {
+		got = append(got, string(line.Data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return got
+}
+
+func TestWriteSplitsLines(t *testing.T) {
+	r := NewRing(0, 0)
+
+	r.Write(pb.OutputStream_STDOUT, []byte("one\ntw"))
+	r.Write(pb.OutputStream_STDERR, []byte("err\n"))
+	r.Write(pb.OutputStream_STDOUT, []byte("o\nthree"))
+	r.Append(pb.OutputStream_EVENTS, []byte(`{"type":"ready"}`))
+
+	want := []string{"one\n", "err\n", "two\n", `{"type":"ready"}`}
+	if got := readAll(t, r, Query{}); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+
+	// Closing adds the unfinished line as it is
+	r.Close()
+	r.Write(pb.OutputStream_STDOUT, []byte("late\n"))
+	got := readAll(t, r, Query{Tail: 1})
+	if len(got) != 1 || got[0] != "three" {
+		t.Errorf("Read() after Close = %q, want the partial line", got)
+	}
+
+	// A line that never ends is cut
+	r = NewRing(0, 0)
+	r.Write(pb.OutputStream_STDOUT, []byte(strings.Repeat("x", MaxLineBytes+10)))
+	if got := readAll(t, r, Query{}); len(got) != 1 || len(got[0]) != MaxLineBytes {
+		t.Errorf("Read() of a long line = %d lines, want one of %d bytes", len(got), MaxLineBytes)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	r := NewRing(3, 0)
+	for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		r.Write(pb.OutputStream_STDOUT, []byte(line))
+	}
+	if got := readAll(t, r, Query{}); strings.Join(got, "") != "b\nc\nd\n" {
+		t.Errorf("Read() = %q, want the last three lines", got)
+	}
+	if lines, size, dropped := r.Stats(); lines != 3 || size != 6 || dropped != 1 {
+		t.Errorf("Stats() = %d, %d, %d, want 3, 6, 1", lines, size, dropped)
+	}
+
+	// Lowering the byte limit drops more, but always keeps the newest line
+	r.SetLimits(3, 1)
+	if got := readAll(t, r, Query{}); strings.Join(got, "") != "d\n" {
+		t.Errorf("Read() = %q, want only the newest line", got)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	r := NewRing(0, 0)
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	r.now = func() time.Time { return now }
+
+	for i, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		now = start.Add(time.Duration(i) * time.Minute)
+		stream := pb.OutputStream_STDOUT
+		if i%2 == 1 {
+			stream = pb.OutputStream_STDERR
+		}
+		r.Write(stream, []byte(line))
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{"all", Query{}, "a\nb\nc\nd\n"},
+		{"since", Query{Since: start.Add(2 * time.Minute)}, "c\nd\n"},
+		{"tail", Query{Tail: 2}, "c\nd\n"},
+		{"streams", Query{Streams: []pb.OutputStream{pb.OutputStream_STDERR}}, "b\nd\n"},
+		{"tail of a stream", Query{Tail: 1, Streams: []pb.OutputStream{pb.OutputStream_STDOUT}}, "c\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(readAll(t, r, tt.q), ""); got != tt.want {
+				t.Errorf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollow(t *testing.T) {
+	r := NewRing(0, 0)
+	r.Write(pb.OutputStream_STDOUT, []byte("before\n"))
+
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Read(context.Background(), Query{Follow: true}, func(line Line) error {
+			lines <- string(line.Data)
+			return nil
+		})
+	}()
+
+	r.Write(pb.OutputStream_STDOUT, []byte("after\n"))
+	r.Append(pb.OutputStream_EVENTS, []byte("event"))
+	r.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Read() error = %v, want it to end with the ring", err)
+	}
+	close(lines)
+	var got []string
+	for line := range lines {
+		got = append(got, line)
+	}
+	if strings.Join(got, "|") != "before\n|after\n|event" {
+		t.Errorf("followed %q", got)
+	}
+
+	// Following ends with the context, and with the callback's error
+	r = NewRing(0, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Read(ctx, Query{Follow: true}, func(Line) error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Read() error = %v, want the context's", err)
+	}
+
+	stop := errors.New("stop")
+	r.Write(pb.OutputStream_STDOUT, []byte("x\n"))
+	if err := r.Read(context.Background(), Query{Follow: true}, func(Line) error { return stop }); err != stop {
+		t.Errorf("Read() error = %v, want the callback's", err)
+	}
+}
+
+func TestInherit(t *testing.T) {
+	prev := NewRing(0, 0)
+	prev.Write(pb.OutputStream_STDOUT, []byte("first run\n"))
+	prev.Write(pb.OutputStream_STDERR, []byte("crashed"))
+	prev.Close()
+
+	// The new run's lines follow the previous run's, limits included
+	r := NewRing(2, 0)
+	r.Inherit(prev)
+	r.Write(pb.OutputStream_STDOUT, []byte("second run\n"))
+
+	want := []string{"crashed", "second run\n"}
+	if got := readAll(t, r, Query{}); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/resources"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+// DefaultOvercommitFactor lets the limits of containers add up to the node's
+// CPU and memory, and no further
+const DefaultOvercommitFactor = 1.0
+
+// AdmissionError is returned when the limits of a container do not fit in
+// what the node has left. It wraps ErrInsufficientResources.
+type AdmissionError struct {
+	// Resource is "cpu", in cores, or "memory", in bytes
+	Resource  string
+	Requested float64
+	// Committed is the sum of the limits of the containers already admitted
+	Committed float64
+	// Capacity is what the node has, scaled by the overcommit factor
+	Capacity float64
+}
+
+func (e *AdmissionError) Error() string {
+	if e.Resource == "cpu" {
+		return fmt.Sprintf("%v: cpu: requested %.2f cores, %.2f of %.2f already committed",
+			ErrInsufficientResources, e.Requested, e.Committed, e.Capacity)
+	}
+	return fmt.Sprintf("%v: %s: requested %.0f bytes, %.0f of %.0f already committed",
+		ErrInsufficientResources, e.Resource, e.Requested, e.Committed, e.Capacity)
+}
+
+func (e *AdmissionError) Unwrap() error {
+	return ErrInsufficientResources
+}
+
+// reservation is the CPU and memory a container holds on the node by its
+// limits. A resource without a limit reserves nothing.
+type reservation struct {
+	cpu    float64
+	memory int64
+}
+
+func (r reservation) add(other reservation) reservation {
+	return reservation{cpu: r.cpu + other.cpu, memory: r.memory + other.memory}
+}
+
+func limitsReservation(limits *pb.ResourceLimits) (reservation, error) {
+	var r reservation
+	if cpu := limits.GetCpuLimit(); cpu != "" {
+		value, err := resources.ParseCPULimit(cpu)
+		if err != nil {
+			return reservation{}, fmt.Errorf("%w: %v", ErrInvalidResources, err)
+		}
+		r.cpu = value
+	}
+	if memory := limits.GetMemoryLimit(); memory != "" {
+		value, err := resources.ParseMemoryLimit(memory)
+		if err != nil {
+			return reservation{}, fmt.Errorf("%w: %v", ErrInvalidResources, err)
+		}
+		r.memory = value
+	}
+	return r, nil
+}
+
+// sidecarsReservation sums the limits of the sidecars of config, which run
+// alongside the workload for as long as it does
+func sidecarsReservation(config *pb.ContainerConfig) (reservation, error) {
+	var r reservation
+	for _, sidecar := range config.GetSidecars() {
+		sr, err := limitsReservation(sidecar.GetResources())
+		if err != nil {
+			return reservation{}, fmt.Errorf("sidecar %s: %w", sidecar.GetName(), err)
+		}
+		r = r.add(sr)
+	}
+	return r, nil
+}
+
+// configReservation is what a container created with config reserves: the
+// limits of its workload and of its sidecars
+func configReservation(config *pb.ContainerConfig) (reservation, error) {
+	r, err := limitsReservation(config.GetResources())
+	if err != nil {
+		return reservation{}, err
+	}
+	sidecars, err := sidecarsReservation(config)
+	if err != nil {
+		return reservation{}, err
+	}
+	return r.add(sidecars), nil
+}
+
+// committed sums the reservations of the containers that have not finished,
+// but for exclude. The caller holds m.mu.
+func (m *Manager) committed(exclude string) reservation {
+	var total reservation
+	for id, c := range m.containers {
+		if id == exclude {
+			continue
+		}
+		state := c.GetState()
+		if isFinished(state.State) || state.Config == nil {
+			continue
+		}
+		// Limits were validated when the container was created or updated
+		r, _ := configReservation(state.Config)
+		total = total.add(r)
+	}
+	return total
+}
+
+// admit checks that requested fits on the node on top of committed, within
+// its CPU and memory scaled by the overcommit factor. A resource requested
+// leaves at zero is not checked; a factor of zero admits everything.
+func (m *Manager) admit(requested, committed reservation) error {
+	if m.overcommitFactor <= 0 {
+		return nil
+	}
+
+	if requested.cpu > 0 {
+		capacity := resources.HostCPUCores() * m.overcommitFactor
+		if committed.cpu+requested.cpu > capacity {
+			return &AdmissionError{Resource: "cpu", Requested: requested.cpu, Committed: committed.cpu, Capacity: capacity}
+		}
+	}
+
+	if requested.memory > 0 {
+		hostMemory, err := resources.HostMemoryBytes()
+		if err != nil {
+			return fmt.Errorf("failed to determine host memory: %w", err)
+		}
+		capacity := float64(hostMemory) * m.overcommitFactor
+		if float64(committed.memory+requested.memory) > capacity {
+			return &AdmissionError{
+				Resource:  "memory",
+				Requested: float64(requested.memory),
+				Committed: float64(committed.memory),
+				Capacity:  capacity,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/container"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/resources"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAdmissionControl(t *testing.T) {
+	dir := t.TempDir()
+	// A stand-in runner that reads its config and exits
+	runner := filepath.Join(dir, "isolation-runner")
+	if err := os.WriteFile(runner, []byte("#!/bin/sh\nread line\nexit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ISOLATION_RUNNER_PATH", runner)
+
+	m, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(m.Stop)
+	if m.overcommitFactor != DefaultOvercommitFactor {
+		t.Errorf("overcommitFactor = %f, want %f", m.overcommitFactor, DefaultOvercommitFactor)
+	}
+
+	cores := resources.HostCPUCores()
+	withCPU := func(cpu float64) *pb.ContainerConfig {
+		return &pb.ContainerConfig{
+			ImageSpec: &pb.ImageSpec{Image: "alpine"},
+			Resources: &pb.ResourceLimits{CpuLimit: proto.String(fmt.Sprintf("%.2f", cpu))},
+		}
+	}
+
+	// One container holding all but half a core of the node
+	m.mu.Lock()
+	m.containers["busy"] = container.New("busy", withCPU(cores-0.5))
+	m.mu.Unlock()
+
+	_, err = m.CreateContainer(context.Background(), "greedy", withCPU(1))
+	var admission *AdmissionError
+	if !errors.As(err, &admission) || !errors.Is(err, ErrInsufficientResources) {
+		t.Fatalf("CreateContainer() error = %v, want an AdmissionError", err)
+	}
+	if admission.Resource != "cpu" || admission.Requested != 1 || admission.Capacity != cores {
+		t.Errorf("AdmissionError = %+v", admission)
+	}
+	if _, err := m.GetContainer("greedy"); !errors.Is(err, ErrNotFound) {
+		t.Error("Expected the refused container to be left out")
+	}
+
+	// Sidecars hold resources alongside the workload
+	config := withCPU(0.25)
+	config.Sidecars = []*pb.AuxContainer{{
+		Name:      "db",
+		ImageSpec: &pb.ImageSpec{Image: "postgres"},
+		Resources: &pb.ResourceLimits{CpuLimit: proto.String("0.5")},
+	}}
+	if _, err := m.CreateContainer(context.Background(), "sidecars", config); !errors.Is(err, ErrInsufficientResources) {
+		t.Errorf("CreateContainer() with sidecars error = %v, want ErrInsufficientResources", err)
+	}
+
+	// Unparsable limits are refused before anything starts
+	bad := &pb.ContainerConfig{Resources: &pb.ResourceLimits{MemoryLimit: proto.String("lots")}}
+	if _, err := m.CreateContainer(context.Background(), "bad", bad); !errors.Is(err, ErrInvalidResources) {
+		t.Errorf("CreateContainer() with a bad limit error = %v, want ErrInvalidResources", err)
+	}
+
+	// What fits is admitted
+	id, err := m.CreateContainer(context.Background(), "small", withCPU(0.25))
+	if err != nil {
+		t.Fatalf("CreateContainer() of a container that fits error = %v", err)
+	}
+	c, _ := m.GetContainer(id)
+	c.Wait(5)
+
+	// Finished containers hold nothing
+	m.mu.RLock()
+	busy := m.containers["busy"]
+	m.mu.RUnlock()
+	busy.Terminate(false, 0)
+	if err := m.admit(reservation{cpu: 1}, m.committed("")); err != nil {
+		t.Errorf("admit() with the node free error = %v", err)
+	}
+
+	// Overcommitting scales the capacity, and a factor of zero admits anything
+	m.overcommitFactor = 2
+	if err := m.admit(reservation{cpu: cores}, reservation{cpu: cores}); err != nil {
+		t.Errorf("admit() within twice the node error = %v", err)
+	}
+	if err := m.admit(reservation{cpu: cores + 1}, reservation{cpu: cores}); !errors.Is(err, ErrInsufficientResources) {
+		t.Errorf("admit() beyond twice the node error = %v, want ErrInsufficientResources", err)
+	}
+	m.overcommitFactor = 0
+	if err := m.admit(reservation{cpu: 10 * cores, memory: 1 << 60}, reservation{}); err != nil {
+		t.Errorf("admit() with admission control off error = %v", err)
+	}
+}
+
+func TestOvercommitFactorConfiguration(t *testing.T) {
+	t.Setenv("ISOLATION_RUNNER_PATH", "/bin/true")
+
+	t.Setenv("RESOURCE_OVERCOMMIT_FACTOR", "1.5")
+	m, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	m.Stop()
+	if m.overcommitFactor != 1.5 {
+		t.Errorf("overcommitFactor = %f, want 1.5", m.overcommitFactor)
+	}
+
+	for _, value := range []string{"-1", "lots"} {
+		t.Setenv("RESOURCE_OVERCOMMIT_FACTOR", value)
+		if m, err := New(); err == nil {
+			m.Stop()
+			t.Errorf("New() with RESOURCE_OVERCOMMIT_FACTOR=%q succeeded, want an error", value)
+		}
+	}
+}
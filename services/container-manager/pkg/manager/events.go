@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/container"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/lifecycle"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/webhook"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+// TenantLabel is the label naming the tenant a container belongs to
+const TenantLabel = "tenant"
+
+// quietEventTypes are left out of watched events unless asked for by type:
+// runner log messages and periodic samples, which would drown out the rest
+var quietEventTypes = []string{"debug", "info", "warning", "error", "container_stats", "runner_heartbeat"}
+
+// EventFilter selects the events WatchEvents delivers
+type EventFilter struct {
+	// Labels the container must carry; empty means any container
+	Labels map[string]string
+	// States the container must be in when the event is raised; empty means any
+	States []pb.ContainerState
+	// Types of events; empty means all but quietEventTypes
+	Types []string
+}
+
+func (f EventFilter) matches(event *pb.ContainerEvent) bool {
+	for key, value := range f.Labels {
+		if v, ok := event.Labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	if len(f.States) > 0 && !slices.Contains(f.States, event.State) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return !slices.Contains(quietEventTypes, event.Event.Type)
+	}
+	return slices.Contains(f.Types, event.Event.Type)
+}
+
+// forwardEvents publishes the events of c to the manager's watchers and
+// notifies webhooks of it becoming ready and exiting, until c is closed. It is
+// started before c is, so none of them are missed.
+func (m *Manager) forwardEvents(c *container.Container) {
+	messages, _ := c.SubscribeMessages(true)
+	go func() {
+		for msg := range messages {
+			event, err := container.ParseEvent(msg)
+			if err != nil {
+				continue
+			}
+			state := c.GetState().State
+			m.events.Publish(&pb.ContainerEvent{
+				ContainerId: c.ID,
+				State:       state,
+				Labels:      c.Config.GetLabels(),
+				Event:       event,
+			})
+
+			switch {
+			case event.Type == "container_ready":
+				m.notifyWebhooks(c, webhook.EventReady)
+			case event.GetLifecycle().GetTo() == lifecycle.PhaseExited.String():
+				if state == pb.ContainerState_FAILED {
+					m.notifyWebhooks(c, webhook.EventFailed)
+				} else {
+					m.notifyWebhooks(c, webhook.EventExited)
+				}
+			}
+		}
+	}()
+}
+
+// notifyWebhooks notifies the manager's webhooks and those of c of an event
+func (m *Manager) notifyWebhooks(c *container.Container, event string) {
+	state := c.GetState()
+	m.webhooks.Send(c.Webhooks(), webhook.Notification{
+		Event:             event,
+		ContainerID:       c.ID,
+		Timestamp:         m.now().UTC().Format(time.RFC3339),
+		State:             strings.ToLower(state.State.String()),
+		Labels:            c.Config.GetLabels(),
+		ExitCode:          state.ExitCode,
+		TerminationReason: state.TerminationReason,
+		ErrorCode:         state.ErrorCode,
+	})
+}
+
+// resolveWebhooks looks up the secrets of a container's own webhooks
+func (m *Manager) resolveWebhooks(ctx context.Context, hooks []*pb.Webhook) ([]webhook.Endpoint, error) {
+	refs := make(map[string]*pb.SecretRef)
+	for i, hook := range hooks {
+		if hook.Secret != nil {
+			refs[strconv.Itoa(i)] = hook.Secret
+		}
+	}
+	secrets, err := m.resolveSecrets(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]webhook.Endpoint, len(hooks))
+	for i, hook := range hooks {
+		endpoints[i] = webhook.Endpoint{
+			URL:    hook.Url,
+			Secret: secrets[strconv.Itoa(i)],
+			Events: hook.Events,
+			Public: true,
+		}
+	}
+	return endpoints, nil
+}
+
+// WatchEvents passes the events of every container that filter selects to fn
+// as they are raised, until ctx ends or the manager stops. It stops early with
+// fn's error. A watcher that falls far behind misses events.
+func (m *Manager) WatchEvents(ctx context.Context, filter EventFilter, fn func(*pb.ContainerEvent) error) error {
+	events, unsubscribe := m.events.Subscribe(false)
+	defer unsubscribe()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !filter.matches(event) {
+				continue
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package manager
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+const (
+	// SortByCreatedAt orders containers by creation time, the default
+	SortByCreatedAt = "created_at"
+	// SortByState orders containers in the order of ContainerState
+	SortByState = "state"
+
+	// MaxPageSize bounds the containers returned by one ListContainers call
+	MaxPageSize = 1000
+)
+
+// ErrInvalidQuery is returned for a list query that cannot be parsed, such as
+// a malformed label selector or page token
+var ErrInvalidQuery = errors.New("invalid list query")
+
+// ContainerQuery selects, orders and pages the containers ListContainers
+// returns
+type ContainerQuery struct {
+	// Filter is the state filter: "running", "exited" or "all"
+	Filter string
+	// Labels the container must carry
+	Labels map[string]string
+	// LabelSelector holds comma-separated requirements on the labels:
+	// "key=value", "key!=value", "key" or "!key"
+	LabelSelector string
+	// Image the container runs, with or without a tag; empty means any
+	Image string
+	// ExitCode the container exited with; nil means any
+	ExitCode *int32
+
+	// SortBy is SortByCreatedAt (or empty) or SortByState
+	SortBy     string
+	Descending bool
+
+	// PageSize bounds the containers returned, up to MaxPageSize; 0 returns
+	// all of them
+	PageSize int
+	// PageToken is the token returned with the previous page
+	PageToken string
+}
+
+// requirement is one term of a label selector
+type requirement struct {
+	key    string
+	value  string
+	negate bool // Label must not have value, or must be missing without one
+	exists bool // Only the presence of the label is checked
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	if r.exists {
+		return ok != r.negate
+	}
+	return (ok && value == r.value) != r.negate
+}
+
+// parseLabelSelector parses comma-separated "key=value", "key!=value", "key"
+// and "!key" requirements
+func parseLabelSelector(selector string) ([]requirement, error) {
+	var requirements []requirement
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		var r requirement
+		if key, value, ok := strings.Cut(term, "!="); ok {
+			r = requirement{key: key, value: value, negate: true}
+		} else if key, value, ok := strings.Cut(term, "="); ok {
+			r = requirement{key: key, value: value}
+		} else if key, ok := strings.CutPrefix(term, "!"); ok {
+			r = requirement{key: key, negate: true, exists: true}
+		} else {
+			r = requirement{key: term, exists: true}
+		}
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if r.key == "" {
+			return nil, fmt.Errorf("%w: label selector term %q has no key", ErrInvalidQuery, term)
+		}
+		requirements = append(requirements, r)
+	}
+	return requirements, nil
+}
+
+// sortKey is what containers are ordered by; page tokens carry that of the
+// last container of a page
+type sortKey struct {
+	CreatedAt int64             `json:"c"`
+	State     pb.ContainerState `json:"s"`
+	ID        string            `json:"i"`
+	SortBy    string            `json:"b"`
+	Desc      bool              `json:"d"`
+}
+
+func keyOf(info *pb.ContainerInfo) sortKey {
+	created, _ := strconv.ParseInt(info.CreatedAt, 10, 64)
+	return sortKey{CreatedAt: created, State: info.State, ID: info.ContainerId}
+}
+
+// compareKeys orders a and b by sortBy, breaking ties by creation time, then
+// ID, so that every container has a stable place
+func compareKeys(sortBy string, descending bool, a, b sortKey) int {
+	c := 0
+	if sortBy == SortByState {
+		c = cmp.Compare(a.State, b.State)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.CreatedAt, b.CreatedAt)
+	}
+	if c == 0 {
+		c = strings.Compare(a.ID, b.ID)
+	}
+	if descending {
+		return -c
+	}
+	return c
+}
+
+func encodePageToken(key sortKey) string {
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(token string) (sortKey, error) {
+	var key sortKey
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &key)
+	}
+	if err != nil {
+		return sortKey{}, fmt.Errorf("%w: malformed page token", ErrInvalidQuery)
+	}
+	return key, nil
+}
+
+// imageMatches reports whether image names the image of info, as shown or as
+// in its spec, with or without its tag or digest
+func imageMatches(info *pb.ContainerInfo, spec *pb.ImageSpec, image string) bool {
+	names := []string{info.Image, spec.GetImage()}
+	for _, name := range names {
+		if name != "" && (name == image || untagged(name) == image) {
+			return true
+		}
+	}
+	return false
+}
+
+// untagged strips the tag or digest off an image name
+func untagged(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// ListContainers returns the containers query selects, including the
+// persisted records of containers no longer held in memory, in its order. The
+// returned token is set when more containers follow the page.
+func (m *Manager) ListContainers(query ContainerQuery) ([]*pb.ContainerInfo, string, error) {
+	switch query.SortBy {
+	case "":
+		query.SortBy = SortByCreatedAt
+	case SortByCreatedAt, SortByState:
+	default:
+		return nil, "", fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, query.SortBy)
+	}
+	if query.PageSize < 0 || query.PageSize > MaxPageSize {
+		return nil, "", fmt.Errorf("%w: page size must be at most %d", ErrInvalidQuery, MaxPageSize)
+	}
+	selector, err := parseLabelSelector(query.LabelSelector)
+	if err != nil {
+		return nil, "", err
+	}
+	var after *sortKey
+	if query.PageToken != "" {
+		key, err := decodePageToken(query.PageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		if key.SortBy != query.SortBy || key.Desc != query.Descending {
+			return nil, "", fmt.Errorf("%w: page token is for a different sort order", ErrInvalidQuery)
+		}
+		after = &key
+	}
+
+	m.mu.RLock()
+	states := make([]*pb.ContainerStatus, 0, len(m.containers))
+	for _, c := range m.containers {
+		states = append(states, c.GetState())
+	}
+	m.mu.RUnlock()
+
+	states = append(states, m.storedRecords()...)
+
+	containers := make([]*pb.ContainerInfo, 0, len(states))
+
+	for _, state := range states {
+		include := false
+		switch query.Filter {
+		case "running":
+			include = state.State == pb.ContainerState_RUNNING
+		case "exited":
+			include = isFinished(state.State)
+		case "all", "":
+			include = true
+		default:
+			include = true
+		}
+		for key, value := range query.Labels {
+			if v, ok := state.Config.GetLabels()[key]; !ok || v != value {
+				include = false
+			}
+		}
+		for _, r := range selector {
+			if !r.matches(state.Config.GetLabels()) {
+				include = false
+			}
+		}
+		if query.ExitCode != nil && (state.ExitCode == nil || *state.ExitCode != *query.ExitCode) {
+			include = false
+		}
+
+		if include {
+			info := &pb.ContainerInfo{
+				ContainerId: state.ContainerId,
+				State:       state.State,
+				CreatedAt:   state.CreatedAt,
+				FinishedAt:  state.FinishedAt,
+				ExitCode:    state.ExitCode,
+				Attempt:     state.Attempt,
+			}
+			if state.Config != nil {
+				// Extract image display name from ImageSpec
+				if state.Config.ImageSpec != nil {
+					registry := state.Config.ImageSpec.GetRegistry()
+					if registry == "" || registry == "registry-1.docker.io" {
+						info.Image = state.Config.ImageSpec.Image
+					} else {
+						info.Image = fmt.Sprintf("%s/%s", registry, state.Config.ImageSpec.Image)
+					}
+				}
+				info.Command = state.Config.Command
+				info.Labels = state.Config.Labels
+			}
+			if query.Image != "" && !imageMatches(info, state.Config.GetImageSpec(), query.Image) {
+				continue
+			}
+			containers = append(containers, info)
+		}
+	}
+
+	slices.SortFunc(containers, func(a, b *pb.ContainerInfo) int {
+		return compareKeys(query.SortBy, query.Descending, keyOf(a), keyOf(b))
+	})
+
+	// Resume past the last container of the previous page, wherever it went
+	if after != nil {
+		start, found := slices.BinarySearchFunc(containers, *after, func(info *pb.ContainerInfo, key sortKey) int {
+			return compareKeys(query.SortBy, query.Descending, keyOf(info), key)
+		})
+		if found {
+			start++
+		}
+		containers = containers[start:]
+	}
+
+	if query.PageSize == 0 || len(containers) <= query.PageSize {
+		return containers, "", nil
+	}
+	containers = containers[:query.PageSize]
+	last := keyOf(containers[len(containers)-1])
+	last.SortBy, last.Desc = query.SortBy, query.Descending
+	return containers, encodePageToken(last), nil
+}
@@ -2,22 +2,117 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/metorial/fleet/holopod/services/container-manager/pkg/container"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/hub"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/lifecycle"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/logs"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/resources"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/secrets"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/spool"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/store"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/webhook"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
 )
 
 const (
-	CleanupIntervalSecs  = 300 // 5 minutes
-	DefaultMaxContainers = 1000
+	// LifecycleTickInterval is how often container lifecycle timers are checked
+	LifecycleTickInterval = time.Second
+	DefaultMaxContainers  = 1000
+
+	// DefaultMaxLifetime is the absolute lifetime after which any container is
+	// terminated, regardless of what the client asked for
+	DefaultMaxLifetime = 24 * time.Hour
+	// DefaultStartupTimeout bounds image pull and container creation
+	DefaultStartupTimeout = 10 * time.Minute
+	// DefaultHeartbeatTimeout is how long a Run client may go without sending a
+	// heartbeat, unless the manager or the container's config says otherwise
+	DefaultHeartbeatTimeout = 30 * time.Second
+	// DefaultRunnerHeartbeatTimeout is how long an isolation-runner that has
+	// sent a runner_heartbeat may go without another before it is taken for
+	// wedged and its container terminated
+	DefaultRunnerHeartbeatTimeout = time.Minute
+	// DefaultReadyTimeout is how long WaitReady waits when the caller does not say
+	DefaultReadyTimeout = 60 * time.Second
+	// MaxReadyTimeout caps the timeout a WaitReady caller may ask for
+	MaxReadyTimeout = 10 * time.Minute
+	// DefaultMaxTimeoutExtension bounds how far ExtendTimeout may push back
+	// the run timeout of a container in total
+	DefaultMaxTimeoutExtension = time.Hour
+
+	// ReasonMaxLifetimeExceeded is recorded on containers killed for exceeding the maximum lifetime
+	ReasonMaxLifetimeExceeded = lifecycle.ReasonMaxLifetimeExceeded
+	// ReasonCPUTimeLimitExceeded is recorded on containers killed for using up their CPU time limit
+	ReasonCPUTimeLimitExceeded = container.ReasonCPUTimeLimitExceeded
+
+	// How long to wait for the isolation-runner to confirm a resource update
+	resourceUpdateTimeout = 10 * time.Second
+	// How long to wait for the isolation-runner to swap in a new network policy;
+	// longer than a resource update since the bastion call may be retried
+	networkPolicyUpdateTimeout = 30 * time.Second
+	// How long a file upload or download may take end to end
+	fileTransferTimeout = 5 * time.Minute
+	// How long to wait for the isolation-runner to freeze or thaw a container
+	pauseTimeout = 10 * time.Second
+	// How long to wait for the isolation-runner to save a container's state
+	checkpointTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrNotFound is returned when no container exists with the given ID
+	ErrNotFound = errors.New("container not found")
+	// ErrAlreadyExists is returned when creating a container with an ID that is in use
+	ErrAlreadyExists = errors.New("container already exists")
+	// ErrLimitReached is returned when the manager is at its maximum container count
+	ErrLimitReached = errors.New("maximum container limit reached")
+	// ErrInsufficientResources is returned when a container or resource update
+	// does not fit on the node; see AdmissionError
+	ErrInsufficientResources = errors.New("insufficient resources")
+	// ErrInvalidResources is returned for a CPU or memory limit that cannot be parsed
+	ErrInvalidResources = errors.New("invalid resource limits")
+	// ErrNotRunning is returned by operations that require a running container
+	ErrNotRunning = container.ErrNotRunning
+	// ErrInvalidPath is returned for a file transfer path that is not allowed
+	ErrInvalidPath = container.ErrInvalidPath
+	// ErrTransferTooLarge is returned when a file transfer exceeds container.MaxFileTransferBytes
+	ErrTransferTooLarge = container.ErrTransferTooLarge
+	// ErrNoTTY is returned when resizing a container that was created without a tty
+	ErrNoTTY = container.ErrNoTTY
+	// ErrInvalidSignal is returned for a signal that cannot be sent to a container
+	ErrInvalidSignal = container.ErrInvalidSignal
+	// ErrInvalidCheckpoint is returned for a checkpoint ID that is not allowed
+	ErrInvalidCheckpoint = container.ErrInvalidCheckpoint
+	// ErrNotReady is returned by WaitReady when the container is not ready in time
+	ErrNotReady = container.ErrNotReady
+	// ErrRuntimeNotAllowed is returned for a runtime missing from the allowlist
+	ErrRuntimeNotAllowed = errors.New("runtime not allowed")
+	// ErrRuntimeOptionNotAllowed is returned for a runsc flag missing from the allowlist
+	ErrRuntimeOptionNotAllowed = errors.New("runtime option not allowed")
+	// ErrSecretsUnavailable is returned for secret references when no secrets backend is configured
+	ErrSecretsUnavailable = errors.New("no secrets backend configured")
+	// ErrSecretNotFound is returned for a reference to a secret the backend does not have
+	ErrSecretNotFound = secrets.ErrNotFound
+	// ErrInvalidSecretRef is returned for a secret reference the backend cannot look up
+	ErrInvalidSecretRef = secrets.ErrInvalidReference
+	// ErrNoRunTimeout is returned when extending the timeout of a container without one
+	ErrNoRunTimeout = lifecycle.ErrNoRunTimeout
+	// ErrExtensionLimit is returned when a timeout extension would go past MAX_TIMEOUT_EXTENSION
+	ErrExtensionLimit = lifecycle.ErrExtensionLimit
+	// ErrNotRestartable is returned when restarting a container that has not
+	// exited or whose config is no longer held
+	ErrNotRestartable = errors.New("container cannot be restarted")
 )
 
 type Manager struct {
@@ -25,8 +120,36 @@ type Manager struct {
 	mu                  sync.RWMutex
 	isolationRunnerPath string
 	maxContainers       int
-	cleanupStop         chan struct{}
-	cleanupDone         chan struct{}
+	allowedRuntimes     map[string]bool
+	allowedRunscFlags   map[string]bool
+	secrets             secrets.Backend
+	runnerLogDir        string // Where runners keep a copy of their events, if set
+	runnerLogLevel      string
+	timeouts            lifecycle.Timeouts
+	overcommitFactor    float64      // Scales the node's CPU and memory for admission; 0 admits everything
+	outputBuffering     spool.Config // Bounds each Run stream's unread output
+	maxLogLines         int          // Bound each container's log ring
+	maxLogBytes         int
+	logFiles            *logs.Files // Where logs are stored, if HOLOPOD_LOG_DIR is set
+	logsPrunedAt        time.Time
+	events              *hub.Hub[*pb.ContainerEvent] // Events of every container, for WatchEvents
+	webhooks            *webhook.Sender
+	now                 func() time.Time
+
+	dockerDataRoot string // Measured for the disk figures of NodeResources
+	cpuMu          sync.Mutex
+	cpuSample      resources.CPUSample // Taken by the previous NodeResources call
+
+	// Container records persisted across restarts, if HOLOPOD_STATE_DB is set
+	store           *store.Store
+	shimDir         string // Where the sockets of runner shims are, with a store
+	recordRetention time.Duration
+	recordsMu       sync.Mutex
+	saved           map[string][]byte // Record last written per container
+	prunedAt        time.Time
+
+	cleanupStop chan struct{}
+	cleanupDone chan struct{}
 }
 
 func New() (*Manager, error) {
@@ -40,19 +163,164 @@ func New() (*Manager, error) {
 		fmt.Sscanf(envVal, "%d", &maxContainers)
 	}
 
+	// Zero or unset keeps the log ring defaults
+	var maxLogLines, maxLogBytes int
+	if envVal := os.Getenv("MAX_LOG_LINES"); envVal != "" {
+		fmt.Sscanf(envVal, "%d", &maxLogLines)
+	}
+	if envVal := os.Getenv("MAX_LOG_BYTES"); envVal != "" {
+		fmt.Sscanf(envVal, "%d", &maxLogBytes)
+	}
+
+	secretsBackend, err := secrets.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	// Runners drop log messages below this level; lifecycle events always pass
+	runnerLogLevel := os.Getenv("HOLOPOD_RUNNER_LOG_LEVEL")
+	switch runnerLogLevel {
+	case "", "debug", "info", "warning", "error":
+	default:
+		return nil, fmt.Errorf("invalid HOLOPOD_RUNNER_LOG_LEVEL %q", runnerLogLevel)
+	}
+
+	// The limits of containers may add up to this multiple of the node's CPU
+	// and memory; "0" turns admission control off
+	overcommitFactor := DefaultOvercommitFactor
+	if envVal := os.Getenv("RESOURCE_OVERCOMMIT_FACTOR"); envVal != "" {
+		overcommitFactor, err = strconv.ParseFloat(envVal, 64)
+		if err != nil || overcommitFactor < 0 {
+			return nil, fmt.Errorf("invalid RESOURCE_OVERCOMMIT_FACTOR %q", envVal)
+		}
+	}
+
+	// Lifecycle timeouts take Go durations such as "24h"; "0" disables a timer
+	timeouts := lifecycle.Timeouts{
+		Startup:     durationFromEnv("CONTAINER_STARTUP_TIMEOUT", DefaultStartupTimeout),
+		Heartbeat:   durationFromEnv("HEARTBEAT_TIMEOUT", DefaultHeartbeatTimeout),
+		Runner:      durationFromEnv("RUNNER_HEARTBEAT_TIMEOUT", DefaultRunnerHeartbeatTimeout),
+		Idle:        durationFromEnv("CONTAINER_IDLE_TIMEOUT", 0),
+		MaxLifetime: durationFromEnv("MAX_CONTAINER_LIFETIME", DefaultMaxLifetime),
+		Cleanup:     container.DefaultCleanupDelay,
+		// "0" allows no extensions
+		MaxRunExtension: durationFromEnv("MAX_TIMEOUT_EXTENSION", DefaultMaxTimeoutExtension),
+	}
+
+	outputBuffering, err := spool.ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	webhookConfig, err := webhook.ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	logFilesConfig, err := logs.FilesConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	var logFiles *logs.Files
+	if logFilesConfig.Dir != "" {
+		if logFiles, err = logs.OpenFiles(logFilesConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	recordStore, shimDir, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+
 	m := &Manager{
 		containers:          make(map[string]*container.Container),
 		isolationRunnerPath: isolationRunnerPath,
 		maxContainers:       maxContainers,
+		allowedRuntimes:     allowedRuntimesFromEnv(),
+		allowedRunscFlags:   setFromEnv("HOLOPOD_ALLOWED_RUNSC_FLAGS"),
+		secrets:             secretsBackend,
+		runnerLogDir:        os.Getenv("HOLOPOD_RUNNER_LOG_DIR"),
+		runnerLogLevel:      runnerLogLevel,
+		timeouts:            timeouts,
+		overcommitFactor:    overcommitFactor,
+		outputBuffering:     outputBuffering,
+		maxLogLines:         maxLogLines,
+		maxLogBytes:         maxLogBytes,
+		logFiles:            logFiles,
+		events:              hub.New[*pb.ContainerEvent](0, 0),
+		webhooks:            webhook.NewSender(webhookConfig),
+		now:                 time.Now,
+		dockerDataRoot:      os.Getenv("DOCKER_DATA_ROOT"),
+		store:               recordStore,
+		shimDir:             shimDir,
+		recordRetention:     durationFromEnv("CONTAINER_RECORD_RETENTION", DefaultRecordRetention),
+		saved:               make(map[string][]byte),
 		cleanupStop:         make(chan struct{}),
 		cleanupDone:         make(chan struct{}),
 	}
 
+	if m.store != nil {
+		if err := m.recoverRecords(); err != nil {
+			m.webhooks.Close()
+			m.store.Close()
+			return nil, err
+		}
+		m.prunedAt = m.now()
+	}
+
 	go m.cleanupTask()
 
 	return m, nil
 }
 
+// allowedRuntimesFromEnv reads the comma-separated OCI runtimes containers may
+// ask for from HOLOPOD_ALLOWED_RUNTIMES; only the default runtime when unset
+func allowedRuntimesFromEnv() map[string]bool {
+	allowed := setFromEnv("HOLOPOD_ALLOWED_RUNTIMES")
+	if len(allowed) == 0 {
+		allowed[container.DefaultRuntime] = true
+	}
+	return allowed
+}
+
+// setFromEnv parses the comma-separated names in the named variable
+func setFromEnv(name string) map[string]bool {
+	set := map[string]bool{}
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
+
+// runscFlags names the runsc flags opts sets
+func runscFlags(opts *pb.RuntimeOptions) []string {
+	var flags []string
+	if opts.Platform != nil {
+		flags = append(flags, "platform")
+	}
+	if opts.Network != nil {
+		flags = append(flags, "network")
+	}
+	if opts.Directfs != nil {
+		flags = append(flags, "directfs")
+	}
+	return flags
+}
+
+// durationFromEnv parses the Go duration in the named variable, falling back to
+// def when it is unset, invalid or negative
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	if envVal := os.Getenv(name); envVal != "" {
+		if d, err := time.ParseDuration(envVal); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return def
+}
+
 func findIsolationRunner() (string, error) {
 	if path := os.Getenv("ISOLATION_RUNNER_PATH"); path != "" {
 		if _, err := os.Stat(path); err == nil {
@@ -87,6 +355,33 @@ func findIsolationRunner() (string, error) {
 }
 
 func (m *Manager) CreateContainer(ctx context.Context, containerID string, config *pb.ContainerConfig) (string, error) {
+	if runtime := config.GetRuntime(); runtime != "" && !m.allowedRuntimes[runtime] {
+		return "", fmt.Errorf("%w: %q", ErrRuntimeNotAllowed, runtime)
+	}
+
+	if opts := config.GetRuntimeOptions(); opts != nil {
+		for _, flag := range runscFlags(opts) {
+			if !m.allowedRunscFlags[flag] {
+				return "", fmt.Errorf("%w: %q", ErrRuntimeOptionNotAllowed, flag)
+			}
+		}
+	}
+
+	if restoreFrom := config.GetRestoreFrom(); restoreFrom != "" {
+		if err := container.ValidateCheckpointID(restoreFrom); err != nil {
+			return "", err
+		}
+	}
+
+	if len(config.SecretEnv) > 0 && m.secrets == nil {
+		return "", ErrSecretsUnavailable
+	}
+
+	requested, err := configReservation(config)
+	if err != nil {
+		return "", err
+	}
+
 	if containerID == "" {
 		// Generate UUID without dashes (bastion requires hex-only)
 		containerID = strings.ReplaceAll(uuid.New().String(), "-", "")
@@ -95,97 +390,264 @@ func (m *Manager) CreateContainer(ctx context.Context, containerID string, confi
 	m.mu.Lock()
 	if len(m.containers) >= m.maxContainers {
 		m.mu.Unlock()
-		return "", fmt.Errorf("maximum container limit reached (%d)", m.maxContainers)
+		return "", fmt.Errorf("%w (%d)", ErrLimitReached, m.maxContainers)
 	}
 
 	if _, exists := m.containers[containerID]; exists {
 		m.mu.Unlock()
-		return "", fmt.Errorf("container with ID %s already exists", containerID)
+		return "", fmt.Errorf("%w: %s", ErrAlreadyExists, containerID)
 	}
 
-	c := container.New(containerID, config)
+	// Checked under the lock, so that containers created at once cannot
+	// oversubscribe the node between them
+	if err := m.admit(requested, m.committed(containerID)); err != nil {
+		m.mu.Unlock()
+		return "", err
+	}
+
+	c := m.newContainer(containerID, config, 1)
 	m.containers[containerID] = c
 	m.mu.Unlock()
 
-	if err := c.Start(m.isolationRunnerPath); err != nil {
+	m.forwardEvents(c)
+
+	if m.logFiles != nil {
+		if file, err := m.logFiles.Create(containerID); err != nil {
+			log.Printf("Failed to store logs of container %s: %v", containerID, err)
+		} else {
+			c.Logs().Persist(file)
+		}
+	}
+
+	secretEnv, err := m.resolveSecrets(ctx, config.SecretEnv)
+	if err == nil {
+		var endpoints []webhook.Endpoint
+		if endpoints, err = m.resolveWebhooks(ctx, config.Webhooks); err == nil {
+			c.SetWebhooks(endpoints)
+		}
+	}
+	if err != nil {
 		m.mu.Lock()
 		delete(m.containers, containerID)
 		m.mu.Unlock()
+		c.Close()
+		return "", err
+	}
+
+	if err := c.Start(m.isolationRunnerPath, secretEnv); err != nil {
+		m.mu.Lock()
+		delete(m.containers, containerID)
+		m.mu.Unlock()
+		c.Close()
 		return "", fmt.Errorf("failed to start container: %w", err)
 	}
 
+	m.saveRecords([]*container.Container{c})
+	m.notifyWebhooks(c, webhook.EventCreated)
+
 	return containerID, nil
 }
 
+// newContainer sets up a container for the given run of config, to be started
+func (m *Manager) newContainer(containerID string, config *pb.ContainerConfig, attempt uint32) *container.Container {
+	c := container.NewWithTimeouts(containerID, config, m.containerTimeouts(config))
+	c.SetAttempt(attempt)
+	if m.shimDir != "" {
+		c.SetShimSocket(m.shimSocket(containerID, attempt))
+	}
+	if m.runnerLogDir != "" {
+		c.SetRunnerLogFile(filepath.Join(m.runnerLogDir, containerID+".log"))
+	}
+	c.SetRunnerLogLevel(m.runnerLogLevel)
+	c.SetOutputBuffering(m.outputBuffering)
+	c.Logs().SetLimits(m.maxLogLines, m.maxLogBytes)
+	return c
+}
+
+// RestartContainer runs an exited container again with the same config under
+// the same ID, as the next attempt. Its logs carry on from the previous run.
+func (m *Manager) RestartContainer(ctx context.Context, containerID string) (*container.Container, error) {
+	prev, err := m.GetContainer(containerID)
+	if err != nil {
+		if _, recordErr := m.storedRecord(containerID); recordErr == nil {
+			return nil, fmt.Errorf("%w: container %s has been cleaned up", ErrNotRestartable, containerID)
+		}
+		return nil, err
+	}
+	if state := prev.GetState().State; !isFinished(state) {
+		return nil, fmt.Errorf("%w: container %s is %s", ErrNotRestartable, containerID, state)
+	}
+	config := prev.Config
+	if ws := config.GetWorkspace(); ws != nil && ws.Url == nil {
+		// An inline archive is dropped once sent to the runner
+		return nil, fmt.Errorf("%w: the workspace archive of container %s is no longer held", ErrNotRestartable, containerID)
+	}
+
+	requested, err := configReservation(config)
+	if err != nil {
+		return nil, err
+	}
+
+	secretEnv, err := m.resolveSecrets(ctx, config.SecretEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	if m.containers[containerID] != prev {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: container %s was restarted or removed meanwhile", ErrNotRestartable, containerID)
+	}
+	if err := m.admit(requested, m.committed(containerID)); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	c := m.newContainer(containerID, config, prev.Attempt()+1)
+	c.SetWebhooks(prev.Webhooks())
+	m.containers[containerID] = c
+	m.mu.Unlock()
+
+	// The previous run's logs are complete once it is closed
+	prev.Close()
+	c.Logs().Inherit(prev.Logs())
+	if m.logFiles != nil {
+		if file, err := m.logFiles.Open(containerID); err != nil {
+			log.Printf("Failed to store logs of container %s: %v", containerID, err)
+		} else {
+			c.Logs().Persist(file)
+		}
+	}
+
+	m.forwardEvents(c)
+
+	if err := c.Start(m.isolationRunnerPath, secretEnv); err != nil {
+		// The previous run stays, finished, and can be restarted again
+		m.mu.Lock()
+		m.containers[containerID] = prev
+		m.mu.Unlock()
+		c.Close()
+		return nil, fmt.Errorf("failed to start container: %w", err)
+	}
+
+	m.saveRecords([]*container.Container{c})
+	m.notifyWebhooks(c, webhook.EventCreated)
+
+	log.Printf("Restarted container %s (attempt %d, run %s)", containerID, c.Attempt(), c.RunID())
+	return c, nil
+}
+
+// containerTimeouts are the manager's timeouts with a container's own run,
+// idle and heartbeat timeouts
+func (m *Manager) containerTimeouts(config *pb.ContainerConfig) lifecycle.Timeouts {
+	timeouts := m.timeouts
+	timeouts.Run = time.Duration(config.GetTimeoutSecs()) * time.Second
+	if config.IdleTimeoutSecs != nil {
+		timeouts.Idle = time.Duration(config.GetIdleTimeoutSecs()) * time.Second
+	}
+	if config.HeartbeatTimeoutSecs != nil {
+		timeouts.Heartbeat = time.Duration(config.GetHeartbeatTimeoutSecs()) * time.Second
+	}
+	timeouts.IdleSuspend = config.GetIdleAction() == pb.IdleAction_PAUSE
+	timeouts.Disconnect = time.Duration(config.GetDisconnectGraceSecs()) * time.Second
+	if config.GetDetached() {
+		// Nobody is expected to stay connected
+		timeouts.Heartbeat = 0
+	}
+	return timeouts
+}
+
+// HeartbeatTimeout returns how long the Run client of a container with config
+// may go without sending a heartbeat; 0 means it need not send any
+func (m *Manager) HeartbeatTimeout(config *pb.ContainerConfig) time.Duration {
+	return m.containerTimeouts(config).Heartbeat
+}
+
+// resolveSecrets looks up the values of a container's secret environment
+// variables
+func (m *Manager) resolveSecrets(ctx context.Context, refs map[string]*pb.SecretRef) (map[string]string, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	lookups := make(map[string]secrets.Ref, len(refs))
+	for name, ref := range refs {
+		lookups[name] = secrets.Ref{Name: ref.GetName(), Key: ref.GetKey()}
+	}
+
+	values, err := secrets.Resolve(ctx, m.secrets, lookups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	return values, nil
+}
+
 func (m *Manager) GetContainer(containerID string) (*container.Container, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	c, exists := m.containers[containerID]
 	if !exists {
-		return nil, fmt.Errorf("container not found: %s", containerID)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, containerID)
 	}
 
 	return c, nil
 }
 
-func (m *Manager) ListContainers(filter string) []*pb.ContainerInfo {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+func (m *Manager) TerminateContainer(containerID string, force bool, timeoutSecs uint32) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
 
-	containers := make([]*pb.ContainerInfo, 0, len(m.containers))
+	return c.Terminate(force, timeoutSecs)
+}
 
-	for id, c := range m.containers {
-		state := c.GetState()
+// Heartbeat records a client heartbeat for the container
+func (m *Manager) Heartbeat(containerID string) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
 
-		include := false
-		switch filter {
-		case "running":
-			include = state.State == pb.ContainerState_RUNNING
-		case "exited":
-			include = state.State == pb.ContainerState_EXITED ||
-				state.State == pb.ContainerState_FAILED ||
-				state.State == pb.ContainerState_TERMINATED
-		case "all", "":
-			include = true
-		default:
-			include = true
-		}
+	c.Heartbeat()
+	return nil
+}
 
-		if include {
-			info := &pb.ContainerInfo{
-				ContainerId: id,
-				State:       state.State,
-				CreatedAt:   state.CreatedAt,
-				FinishedAt:  state.FinishedAt,
-				ExitCode:    state.ExitCode,
-			}
-			if state.Config != nil {
-				// Extract image display name from ImageSpec
-				if state.Config.ImageSpec != nil {
-					registry := state.Config.ImageSpec.GetRegistry()
-					if registry == "" || registry == "registry-1.docker.io" {
-						info.Image = state.Config.ImageSpec.Image
-					} else {
-						info.Image = fmt.Sprintf("%s/%s", registry, state.Config.ImageSpec.Image)
-					}
-				}
-				info.Command = state.Config.Command
-			}
-			containers = append(containers, info)
-		}
+// Attach binds a Run stream to a container, which counts as a heartbeat. Every
+// Attach is paired with a Disconnect when the stream ends.
+func (m *Manager) Attach(containerID string) (*container.Container, error) {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return nil, err
 	}
 
-	return containers
+	c.ClientAttached()
+	return c, nil
 }
 
-func (m *Manager) TerminateContainer(containerID string, force bool, timeoutSecs uint32) error {
+// Disconnect handles a Run stream that ended without terminating its
+// container. While other streams are attached nothing changes. Otherwise a
+// detached container keeps running, one with a disconnect grace period gets
+// that long for its client to come back, and any other is terminated at once.
+func (m *Manager) Disconnect(containerID string) error {
 	c, err := m.GetContainer(containerID)
 	if err != nil {
 		return err
 	}
+	if c.ClientDetached() > 0 {
+		return nil
+	}
 
-	return c.Terminate(force, timeoutSecs)
+	switch {
+	case c.Config.GetDetached():
+		log.Printf("Client of detached container %s (run %s) disconnected, leaving it running", c.ID, c.RunID())
+		return nil
+	case c.Config.GetDisconnectGraceSecs() > 0:
+		c.Disconnected()
+		return nil
+	default:
+		return c.Terminate(true, 5)
+	}
 }
 
 func (m *Manager) WaitContainer(containerID string, timeoutSecs uint32) (int32, error) {
@@ -197,46 +659,140 @@ func (m *Manager) WaitContainer(containerID string, timeoutSecs uint32) (int32,
 	return c.Wait(timeoutSecs)
 }
 
-func (m *Manager) GetContainerStatus(containerID string) (*pb.ContainerStatus, error) {
+// UpdateContainerResources changes the CPU/memory limits of a running container.
+// The new limits, combined with the limits of every other container holding
+// resources, must fit within the node's capacity scaled by the overcommit factor.
+func (m *Manager) UpdateContainerResources(containerID string, limits *pb.ResourceLimits) (*pb.ResourceLimits, error) {
 	c, err := m.GetContainer(containerID)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.GetState(), nil
+	if limits == nil || (limits.CpuLimit == nil && limits.MemoryLimit == nil) {
+		err = fmt.Errorf("at least one of cpu_limit or memory_limit is required")
+	} else {
+		err = m.checkResourceHeadroom(c, limits)
+	}
+	if err != nil {
+		// A Run stream that asked for it learns why it was refused
+		c.BroadcastResourceUpdateFailure(err)
+		return nil, err
+	}
+
+	return c.UpdateResources(limits, resourceUpdateTimeout)
+}
+
+// UpdateNetworkPolicy replaces the network policy of a running container without restarting it
+func (m *Manager) UpdateNetworkPolicy(containerID string, network *pb.NetworkConfig) error {
+	if network == nil {
+		return fmt.Errorf("network config is required")
+	}
+
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	return c.UpdateNetworkPolicy(network, networkPolicyUpdateTimeout)
 }
 
-func (m *Manager) SubscribeStdout(containerID string) <-chan []byte {
+// ExtendTimeout pushes back the run timeout of a running container by d and
+// returns its new deadline, zero while the container is starting, and the
+// extension in total
+func (m *Manager) ExtendTimeout(containerID string, d time.Duration) (time.Time, time.Duration, error) {
 	c, err := m.GetContainer(containerID)
 	if err != nil {
-		ch := make(chan []byte)
-		close(ch)
-		return ch
+		return time.Time{}, 0, err
 	}
 
-	return c.SubscribeStdout()
+	return c.ExtendTimeout(d)
 }
 
-func (m *Manager) SubscribeStderr(containerID string) <-chan []byte {
+// StartCapture begins a packet capture of a running container and returns its ID
+func (m *Manager) StartCapture(containerID string, filter *string, maxPackets, maxDurationSecs *uint32) (string, error) {
 	c, err := m.GetContainer(containerID)
 	if err != nil {
-		ch := make(chan []byte)
-		close(ch)
-		return ch
+		return "", err
+	}
+
+	return c.StartCapture(filter, maxPackets, maxDurationSecs)
+}
+
+// StopCapture stops a running packet capture
+func (m *Manager) StopCapture(containerID, captureID string) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
 	}
 
-	return c.SubscribeStderr()
+	return c.StopCapture(captureID)
 }
 
-func (m *Manager) SubscribeMessages(containerID string) <-chan string {
+// Exec runs an additional process in a running container and returns its exec ID
+func (m *Manager) Exec(containerID string, req *pb.ExecRequest) (string, error) {
 	c, err := m.GetContainer(containerID)
 	if err != nil {
-		ch := make(chan string)
-		close(ch)
-		return ch
+		return "", err
 	}
 
-	return c.SubscribeMessages()
+	return c.Exec(req)
+}
+
+// UploadFile extracts a tar archive into a directory of a running container
+func (m *Manager) UploadFile(ctx context.Context, containerID, dir string, archive io.Reader) (int64, error) {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fileTransferTimeout)
+	defer cancel()
+
+	return c.UploadFile(ctx, dir, archive)
+}
+
+// DownloadFile writes a tar archive of a path in a running container to w
+func (m *Manager) DownloadFile(ctx context.Context, containerID, path string, w io.Writer) (int64, error) {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fileTransferTimeout)
+	defer cancel()
+
+	return c.DownloadFile(ctx, path, w)
+}
+
+// checkResourceHeadroom verifies that the requested limits, together with the
+// sidecars of the container and every other container holding resources, fit
+// on the node
+func (m *Manager) checkResourceHeadroom(c *container.Container, limits *pb.ResourceLimits) error {
+	requested, err := limitsReservation(limits)
+	if err != nil {
+		return err
+	}
+	sidecars, err := sidecarsReservation(c.Config)
+	if err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	committed := m.committed(c.ID).add(sidecars)
+	m.mu.RUnlock()
+
+	return m.admit(requested, committed)
+}
+
+// GetContainerStatus returns the status of a container, falling back to its
+// persisted record once it is no longer held in memory
+func (m *Manager) GetContainerStatus(containerID string) (*pb.ContainerStatus, error) {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return m.storedRecord(containerID)
+	}
+
+	return c.GetState(), nil
 }
 
 func (m *Manager) WriteStdin(containerID string, data []byte) error {
@@ -248,55 +804,226 @@ func (m *Manager) WriteStdin(containerID string, data []byte) error {
 	return c.WriteStdin(data)
 }
 
+// Pause freezes every process of a running container until Unpause
+func (m *Manager) Pause(containerID string) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Pause(pauseTimeout)
+}
+
+// Unpause resumes a container frozen by Pause
+func (m *Manager) Unpause(containerID string) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Unpause(pauseTimeout)
+}
+
+// WaitReady blocks until the container is ready, it exits, or timeout passes
+func (m *Manager) WaitReady(ctx context.Context, containerID string, timeout time.Duration) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return c.WaitReady(ctx)
+}
+
+// GetLogs passes the container's log lines that q selects to fn, following
+// new ones if q asks to. The logs of a container no longer held in memory are
+// read from where they were stored, if anywhere.
+func (m *Manager) GetLogs(ctx context.Context, containerID string, q logs.Query, fn func(logs.Line) error) error {
+	c, err := m.GetContainer(containerID)
+	if err == nil {
+		return c.Logs().Read(ctx, q, fn)
+	}
+	if m.logFiles == nil {
+		return err
+	}
+
+	if err := m.logFiles.Read(ctx, containerID, q, fn); errors.Is(err, logs.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, containerID)
+	} else if err != nil {
+		return fmt.Errorf("failed to read stored logs: %w", err)
+	}
+	return nil
+}
+
+// pruneLogs removes the stored logs of containers past their retention
+func (m *Manager) pruneLogs() {
+	pruned, err := m.logFiles.Prune(m.now())
+	if err != nil {
+		log.Printf("Failed to prune container logs: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("Pruned the logs of %d containers past retention", pruned)
+	}
+}
+
+// Checkpoint saves a running container's state so later containers can be
+// restored from it
+func (m *Manager) Checkpoint(containerID, checkpointID string, leaveRunning bool) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Checkpoint(checkpointID, leaveRunning, checkpointTimeout)
+}
+
+// Signal sends a signal to the container's main process
+func (m *Manager) Signal(containerID, name string) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Signal(name)
+}
+
+// Resize sets the size of the container's terminal
+func (m *Manager) Resize(containerID string, rows, cols uint32) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Resize(rows, cols)
+}
+
+// CloseStdin closes the container's stdin once the data already written is consumed
+func (m *Manager) CloseStdin(containerID string) error {
+	c, err := m.GetContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	return c.CloseStdin()
+}
+
 func (m *Manager) cleanupTask() {
-	ticker := time.NewTicker(time.Duration(CleanupIntervalSecs) * time.Second)
+	ticker := time.NewTicker(LifecycleTickInterval)
 	defer ticker.Stop()
 	defer close(m.cleanupDone)
 
 	for {
 		select {
 		case <-ticker.C:
-			m.cleanupExitedContainers()
+			m.advanceLifecycles()
+			m.saveAllRecords()
+			if m.store != nil && m.now().Sub(m.prunedAt) >= recordPruneInterval {
+				m.pruneRecords()
+				m.prunedAt = m.now()
+			}
+			if m.logFiles != nil && m.now().Sub(m.logsPrunedAt) >= recordPruneInterval {
+				m.pruneLogs()
+				m.logsPrunedAt = m.now()
+			}
 		case <-m.cleanupStop:
 			return
 		}
 	}
 }
 
-func (m *Manager) cleanupExitedContainers() {
-	now := time.Now().Unix()
+// advanceLifecycles fires every expired lifecycle timer and acts on the
+// resulting transitions: containers entering stopping are terminated and idle
+// ones set to suspend are paused, both in the background so one slow container
+// cannot hold up the tick, and removed containers are closed and forgotten. It
+// returns the transitions it applied.
+func (m *Manager) advanceLifecycles() []lifecycle.Transition {
+	now := m.now()
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	var transitions []lifecycle.Transition
+	var stopping, suspended, removed []*container.Container
 
-	for id, c := range m.containers {
-		state := c.GetState()
+	m.mu.RLock()
+	for _, c := range m.containers {
+		for {
+			tr, ok := c.AdvanceLifecycle(now)
+			if !ok {
+				break
+			}
+			transitions = append(transitions, tr)
+
+			switch tr.To {
+			case lifecycle.PhaseRunning:
+				if tr.Reason == lifecycle.ReasonIdleSuspend {
+					suspended = append(suspended, c)
+				}
+			case lifecycle.PhaseStopping:
+				stopping = append(stopping, c)
+			case lifecycle.PhaseRemoved:
+				removed = append(removed, c)
+			}
+		}
+	}
+	m.mu.RUnlock()
 
-		if state.CleanupAfter != nil && now >= *state.CleanupAfter {
+	for _, c := range stopping {
+		reason := c.StopReason()
+		log.Printf("Container %s (run %s) hit %s, terminating", c.ID, c.RunID(), reason)
+		go func(c *container.Container) {
+			if err := c.TerminateWithReason(reason, true, 0); err != nil {
+				log.Printf("Failed to terminate container %s (run %s) after %s: %v", c.ID, c.RunID(), reason, err)
+			}
+		}(c)
+	}
+
+	for _, c := range suspended {
+		log.Printf("Container %s (run %s) is idle, pausing", c.ID, c.RunID())
+		go func(c *container.Container) {
+			if err := c.SuspendIdle(pauseTimeout); err != nil {
+				log.Printf("Failed to pause idle container %s (run %s): %v", c.ID, c.RunID(), err)
+			}
+		}(c)
+	}
+
+	if len(removed) > 0 {
+		// Keep the final status of removed containers queryable
+		m.saveRecords(removed)
+
+		m.mu.Lock()
+		for _, c := range removed {
 			c.Close()
-			delete(m.containers, id)
+			delete(m.containers, c.ID)
 		}
+		m.mu.Unlock()
+
+		m.forgetRecords(removed)
 	}
+
+	return transitions
 }
 
 func (m *Manager) CleanupExitedContainersNow() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	count := 0
-	for id, c := range m.containers {
-		state := c.GetState()
-
-		if state.State == pb.ContainerState_EXITED ||
-			state.State == pb.ContainerState_FAILED ||
-			state.State == pb.ContainerState_TERMINATED {
-			c.Close()
-			delete(m.containers, id)
-			count++
+	var exited []*container.Container
+	for _, c := range m.containers {
+		if isFinished(c.GetState().State) {
+			exited = append(exited, c)
 		}
 	}
 
-	return count
+	// Keep the final status of removed containers queryable
+	m.saveRecords(exited)
+	for _, c := range exited {
+		c.Close()
+		delete(m.containers, c.ID)
+	}
+	m.forgetRecords(exited)
+
+	return len(exited)
 }
 
 func (m *Manager) GetStats() (int, int) {
@@ -320,10 +1047,20 @@ func (m *Manager) Stop() {
 	close(m.cleanupStop)
 	<-m.cleanupDone
 
+	m.saveAllRecords()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for _, c := range m.containers {
 		c.Close()
 	}
+	m.events.Close()
+	m.webhooks.Close()
+
+	if m.store != nil {
+		if err := m.store.Close(); err != nil {
+			log.Printf("Failed to close state database: %v", err)
+		}
+	}
 }
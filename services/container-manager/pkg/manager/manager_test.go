@@ -2,13 +2,35 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/container"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/lifecycle"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/logs"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/shim"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/webhook"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/protobuf/proto"
 )
 
+// The test binary stands in for the manager's binary when runner shims are
+// started
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == shim.Command {
+		os.Exit(shim.Main(os.Args[2:]))
+	}
+	os.Exit(m.Run())
+}
+
 func setupTestManager(t *testing.T) *Manager {
 	// Set a fake isolation runner path to avoid search
 	os.Setenv("ISOLATION_RUNNER_PATH", "/tmp/fake-runner")
@@ -63,8 +85,8 @@ func TestListContainersEmpty(t *testing.T) {
 		return
 	}
 
-	containers := m.ListContainers("all")
-	if len(containers) != 0 {
+	containers, next, err := m.ListContainers(ContainerQuery{Filter: "all"})
+	if err != nil || next != "" || len(containers) != 0 {
 		t.Errorf("Expected 0 containers, got %d", len(containers))
 	}
 }
@@ -76,8 +98,8 @@ func TestGetContainerNotFound(t *testing.T) {
 	}
 
 	_, err := m.GetContainer("nonexistent")
-	if err == nil {
-		t.Error("Expected error for nonexistent container")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
 	}
 }
 
@@ -88,8 +110,8 @@ func TestTerminateContainerNotFound(t *testing.T) {
 	}
 
 	err := m.TerminateContainer("nonexistent", false, 5)
-	if err == nil {
-		t.Error("Expected error for nonexistent container")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
 	}
 }
 
@@ -100,8 +122,8 @@ func TestWaitContainerNotFound(t *testing.T) {
 	}
 
 	_, err := m.WaitContainer("nonexistent", 5)
-	if err == nil {
-		t.Error("Expected error for nonexistent container")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
 	}
 }
 
@@ -112,8 +134,77 @@ func TestGetContainerStatusNotFound(t *testing.T) {
 	}
 
 	_, err := m.GetContainerStatus("nonexistent")
-	if err == nil {
-		t.Error("Expected error for nonexistent container")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCreateContainerSentinelErrors(t *testing.T) {
+	m := setupTestManager(t)
+	if m == nil {
+		return
+	}
+
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "alpine"}}
+
+	m.mu.Lock()
+	m.containers["existing"] = container.New("existing", config)
+	m.mu.Unlock()
+
+	_, err := m.CreateContainer(context.Background(), "existing", config)
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists, got %v", err)
+	}
+
+	m.maxContainers = 1
+	_, err = m.CreateContainer(context.Background(), "another", config)
+	if !errors.Is(err, ErrLimitReached) {
+		t.Errorf("Expected ErrLimitReached, got %v", err)
+	}
+}
+
+func TestAllowedRuntimes(t *testing.T) {
+	t.Setenv("HOLOPOD_ALLOWED_RUNTIMES", "")
+	if allowed := allowedRuntimesFromEnv(); len(allowed) != 1 || !allowed["runsc"] {
+		t.Errorf("Expected only runsc by default, got %v", allowed)
+	}
+
+	t.Setenv("HOLOPOD_ALLOWED_RUNTIMES", "runsc, runsc-kvm,,kata")
+	allowed := allowedRuntimesFromEnv()
+	if len(allowed) != 3 || !allowed["runsc-kvm"] || !allowed["kata"] || allowed["runc"] {
+		t.Errorf("Unexpected allowlist %v", allowed)
+	}
+
+	m := setupTestManager(t)
+	if m == nil {
+		return
+	}
+	m.allowedRuntimes = allowed
+
+	runtime := "runc"
+	_, err := m.CreateContainer(context.Background(), "runc", &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "alpine"},
+		Runtime:   &runtime,
+	})
+	if !errors.Is(err, ErrRuntimeNotAllowed) {
+		t.Errorf("Expected ErrRuntimeNotAllowed, got %v", err)
+	}
+}
+
+func TestAllowedRunscFlags(t *testing.T) {
+	m := setupTestManager(t)
+	if m == nil {
+		return
+	}
+	m.allowedRunscFlags = map[string]bool{"platform": true}
+
+	network := "host"
+	_, err := m.CreateContainer(context.Background(), "host-network", &pb.ContainerConfig{
+		ImageSpec:      &pb.ImageSpec{Image: "alpine"},
+		RuntimeOptions: &pb.RuntimeOptions{Network: &network},
+	})
+	if !errors.Is(err, ErrRuntimeOptionNotAllowed) {
+		t.Errorf("Expected ErrRuntimeOptionNotAllowed, got %v", err)
 	}
 }
 
@@ -148,6 +239,110 @@ func TestMaxContainersConfiguration(t *testing.T) {
 	}
 }
 
+func TestLifecycleTimeoutConfiguration(t *testing.T) {
+	tests := []struct {
+		env   string
+		value string
+		get   func(lifecycle.Timeouts) time.Duration
+		want  time.Duration
+	}{
+		{"MAX_CONTAINER_LIFETIME", "", func(t lifecycle.Timeouts) time.Duration { return t.MaxLifetime }, DefaultMaxLifetime},
+		{"MAX_CONTAINER_LIFETIME", "2h", func(t lifecycle.Timeouts) time.Duration { return t.MaxLifetime }, 2 * time.Hour},
+		{"MAX_CONTAINER_LIFETIME", "0", func(t lifecycle.Timeouts) time.Duration { return t.MaxLifetime }, 0},
+		{"MAX_CONTAINER_LIFETIME", "invalid", func(t lifecycle.Timeouts) time.Duration { return t.MaxLifetime }, DefaultMaxLifetime},
+		{"MAX_CONTAINER_LIFETIME", "-1h", func(t lifecycle.Timeouts) time.Duration { return t.MaxLifetime }, DefaultMaxLifetime},
+		{"CONTAINER_STARTUP_TIMEOUT", "", func(t lifecycle.Timeouts) time.Duration { return t.Startup }, DefaultStartupTimeout},
+		{"CONTAINER_STARTUP_TIMEOUT", "90s", func(t lifecycle.Timeouts) time.Duration { return t.Startup }, 90 * time.Second},
+		{"CONTAINER_IDLE_TIMEOUT", "", func(t lifecycle.Timeouts) time.Duration { return t.Idle }, 0},
+		{"CONTAINER_IDLE_TIMEOUT", "15m", func(t lifecycle.Timeouts) time.Duration { return t.Idle }, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env+"="+tt.value, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			m := setupTestManager(t)
+			if m == nil {
+				return
+			}
+
+			if got := tt.get(m.timeouts); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+			if m.timeouts.Heartbeat != DefaultHeartbeatTimeout {
+				t.Errorf("Expected heartbeat timeout %s, got %s", DefaultHeartbeatTimeout, m.timeouts.Heartbeat)
+			}
+		})
+	}
+}
+
+func TestAdvanceLifecycles(t *testing.T) {
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "test"}}
+	timeouts := lifecycle.Timeouts{MaxLifetime: time.Hour, Cleanup: time.Minute}
+
+	stale := container.NewWithTimeouts("stale", config, timeouts)
+	exited := container.NewWithTimeouts("exited", config, timeouts)
+	exited.Terminate(false, 0)
+
+	now := time.Now()
+	m := &Manager{
+		containers: map[string]*container.Container{
+			"stale":  stale,
+			"exited": exited,
+		},
+		now: func() time.Time { return now },
+	}
+
+	if transitions := m.advanceLifecycles(); len(transitions) != 0 {
+		t.Errorf("Expected no transitions, got %v", transitions)
+	}
+
+	// Move the clock past both the maximum lifetime and the cleanup delay
+	now = now.Add(2 * time.Hour)
+
+	transitions := m.advanceLifecycles()
+	if len(transitions) != 2 {
+		t.Fatalf("Expected 2 transitions, got %v", transitions)
+	}
+	for _, tr := range transitions {
+		switch tr.To {
+		case lifecycle.PhaseStopping:
+			if tr.Reason != ReasonMaxLifetimeExceeded {
+				t.Errorf("Expected stop reason %s, got %s", ReasonMaxLifetimeExceeded, tr.Reason)
+			}
+		case lifecycle.PhaseRemoved:
+			if tr.Reason != lifecycle.ReasonCleanup {
+				t.Errorf("Expected removal reason %s, got %s", lifecycle.ReasonCleanup, tr.Reason)
+			}
+		default:
+			t.Errorf("Unexpected transition %+v", tr)
+		}
+	}
+
+	if _, err := m.GetContainer("exited"); !errors.Is(err, ErrNotFound) {
+		t.Error("Expected the exited container to be removed")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for stale.GetState().State != pb.ContainerState_TERMINATED && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	state := stale.GetState()
+	if state.State != pb.ContainerState_TERMINATED {
+		t.Errorf("Expected stale container TERMINATED, got %v", state.State)
+	}
+	if state.GetTerminationReason() != ReasonMaxLifetimeExceeded {
+		t.Errorf("Expected reason %s, got %q", ReasonMaxLifetimeExceeded, state.GetTerminationReason())
+	}
+
+	// Once terminated, the stale container is removed after its cleanup delay
+	if transitions := m.advanceLifecycles(); len(transitions) != 1 || transitions[0].To != lifecycle.PhaseRemoved {
+		t.Errorf("Expected the stale container to be removed, got %v", transitions)
+	}
+	if len(m.containers) != 0 {
+		t.Errorf("Expected no containers left, got %d", len(m.containers))
+	}
+}
+
 func TestFindIsolationRunner(t *testing.T) {
 	// Test env var takes precedence
 	os.Setenv("ISOLATION_RUNNER_PATH", "/tmp/test-runner")
@@ -220,13 +415,142 @@ func TestListContainersFilters(t *testing.T) {
 	// Test different filter values don't crash
 	filters := []string{"all", "running", "exited", "", "invalid"}
 	for _, filter := range filters {
-		containers := m.ListContainers(filter)
-		if containers == nil {
+		containers, _, err := m.ListContainers(ContainerQuery{Filter: filter})
+		if err != nil || containers == nil {
 			t.Errorf("ListContainers returned nil for filter '%s'", filter)
 		}
 	}
 }
 
+func TestListContainersByLabel(t *testing.T) {
+	m := setupTestManager(t)
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.containers["ml"] = container.New("ml", &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "alpine"},
+		Labels:    map[string]string{"team": "ml", "tier": "batch"},
+	})
+	m.containers["web"] = container.New("web", &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "alpine"},
+		Labels:    map[string]string{"team": "web"},
+	})
+	m.mu.Unlock()
+
+	containers, _, _ := m.ListContainers(ContainerQuery{Filter: "all", Labels: map[string]string{"team": "ml"}})
+	if len(containers) != 1 || containers[0].ContainerId != "ml" {
+		t.Fatalf("expected only the ml container, got %v", containers)
+	}
+	if containers[0].Labels["tier"] != "batch" {
+		t.Errorf("expected labels in container info, got %v", containers[0].Labels)
+	}
+
+	if containers, _, _ := m.ListContainers(ContainerQuery{Filter: "all", Labels: map[string]string{"team": "ml", "tier": "web"}}); len(containers) != 0 {
+		t.Errorf("expected no container to match every label, got %v", containers)
+	}
+	if containers, _, _ := m.ListContainers(ContainerQuery{Filter: "all"}); len(containers) != 2 {
+		t.Errorf("expected both containers without a label filter, got %d", len(containers))
+	}
+}
+
+func TestListContainersQuery(t *testing.T) {
+	dir := t.TempDir()
+	runner := filepath.Join(dir, "isolation-runner")
+	if err := os.WriteFile(runner, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ISOLATION_RUNNER_PATH", runner)
+	t.Setenv("HOLOPOD_STATE_DB", filepath.Join(dir, "state.db"))
+
+	m, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(m.Stop)
+
+	record := func(id string, created int, state pb.ContainerState, image string, exitCode int32, labels map[string]string) *pb.ContainerStatus {
+		return &pb.ContainerStatus{
+			ContainerId: id,
+			State:       state,
+			CreatedAt:   strconv.Itoa(created),
+			ExitCode:    proto.Int32(exitCode),
+			Config: &pb.ContainerConfig{
+				ImageSpec: &pb.ImageSpec{Image: image},
+				Labels:    labels,
+			},
+		}
+	}
+	if err := m.store.Put(
+		record("a", 300, pb.ContainerState_EXITED, "alpine:3.20", 0, map[string]string{"tenant": "acme", "team": "ml"}),
+		record("b", 100, pb.ContainerState_FAILED, "python:3.12", 1, map[string]string{"tenant": "acme"}),
+		record("c", 200, pb.ContainerState_EXITED, "alpine", 0, map[string]string{"tenant": "other", "team": "web"}),
+		record("d", 200, pb.ContainerState_TERMINATED, "alpine@sha256:abc", 137, nil),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := func(containers []*pb.ContainerInfo) string {
+		var ids []string
+		for _, c := range containers {
+			ids = append(ids, c.ContainerId)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	tests := []struct {
+		name  string
+		query ContainerQuery
+		want  string
+	}{
+		{"created_at", ContainerQuery{}, "b,c,d,a"},
+		{"newest first", ContainerQuery{Descending: true}, "a,d,c,b"},
+		{"state", ContainerQuery{SortBy: SortByState}, "c,a,b,d"},
+		{"image without tag", ContainerQuery{Image: "alpine"}, "c,d,a"},
+		{"image with tag", ContainerQuery{Image: "alpine:3.20"}, "a"},
+		{"exit code", ContainerQuery{ExitCode: proto.Int32(0)}, "c,a"},
+		{"selector", ContainerQuery{LabelSelector: "tenant=acme,team"}, "a"},
+		{"negated selector", ContainerQuery{LabelSelector: "tenant!=acme, !team"}, "d"},
+	}
+	for _, tt := range tests {
+		containers, next, err := m.ListContainers(tt.query)
+		if err != nil || next != "" || ids(containers) != tt.want {
+			t.Errorf("%s: got %s, %q, %v; want %s", tt.name, ids(containers), next, err, tt.want)
+		}
+	}
+
+	// Pages pick up where the previous one stopped
+	var pages []string
+	query := ContainerQuery{SortBy: SortByState, Descending: true, PageSize: 3}
+	for {
+		containers, next, err := m.ListContainers(query)
+		if err != nil {
+			t.Fatalf("ListContainers(%+v) error = %v", query, err)
+		}
+		pages = append(pages, ids(containers))
+		if next == "" {
+			break
+		}
+		query.PageToken = next
+	}
+	if got := strings.Join(pages, "|"); got != "d,b,a|c" {
+		t.Errorf("pages = %s, want d,b,a|c", got)
+	}
+
+	for _, bad := range []ContainerQuery{
+		{SortBy: "image"},
+		{PageSize: MaxPageSize + 1},
+		{LabelSelector: "=acme"},
+		{PageToken: "not a token"},
+		{PageToken: query.PageToken},
+	} {
+		if _, _, err := m.ListContainers(bad); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("ListContainers(%+v) error = %v, want ErrInvalidQuery", bad, err)
+		}
+	}
+}
+
 func TestCreateContainerFailsWithoutRunner(t *testing.T) {
 	m := setupTestManager(t)
 	if m == nil {
@@ -288,8 +612,11 @@ func TestBufferTracking(t *testing.T) {
 }
 
 func TestDefaultConstants(t *testing.T) {
-	if CleanupIntervalSecs != 300 {
-		t.Errorf("Expected CleanupIntervalSecs 300, got %d", CleanupIntervalSecs)
+	if LifecycleTickInterval != time.Second {
+		t.Errorf("Expected LifecycleTickInterval 1s, got %s", LifecycleTickInterval)
+	}
+	if DefaultHeartbeatTimeout != 30*time.Second {
+		t.Errorf("Expected DefaultHeartbeatTimeout 30s, got %s", DefaultHeartbeatTimeout)
 	}
 	if DefaultMaxContainers != 1000 {
 		t.Errorf("Expected DefaultMaxContainers 1000, got %d", DefaultMaxContainers)
@@ -318,3 +645,456 @@ func TestCleanupTaskStartsAutomatically(t *testing.T) {
 		t.Error("Cleanup task didn't stop properly")
 	}
 }
+
+func TestContainerRecordsSurviveRestart(t *testing.T) {
+	dir := t.TempDir()
+	runner := filepath.Join(dir, "isolation-runner")
+	if err := os.WriteFile(runner, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ISOLATION_RUNNER_PATH", runner)
+	t.Setenv("HOLOPOD_STATE_DB", filepath.Join(dir, "state.db"))
+
+	first, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	config := &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{
+			Image: "alpine",
+			Auth:  &pb.ImageSpec_BasicAuth{BasicAuth: &pb.BasicAuth{Username: "user", Password: "secret"}},
+		},
+		Labels: map[string]string{"team": "ml"},
+	}
+	exited := container.New("exited", config)
+	exited.Terminate(false, 0)
+	pending := container.New("pending", config)
+
+	first.mu.Lock()
+	first.containers["exited"] = exited
+	first.containers["pending"] = pending
+	first.mu.Unlock()
+
+	// Exited containers leave memory but stay queryable
+	if n := first.CleanupExitedContainersNow(); n != 1 {
+		t.Fatalf("Expected 1 container cleaned up, got %d", n)
+	}
+	if state, err := first.GetContainerStatus("exited"); err != nil || state.State != pb.ContainerState_TERMINATED {
+		t.Fatalf("Expected the record of the cleaned up container, got %v, %v", state, err)
+	}
+	first.Stop()
+
+	second, err := New()
+	if err != nil {
+		t.Fatalf("New() after restart error = %v", err)
+	}
+	t.Cleanup(second.Stop)
+
+	state, err := second.GetContainerStatus("exited")
+	if err != nil {
+		t.Fatalf("Expected the exited container after a restart: %v", err)
+	}
+	if state.State != pb.ContainerState_TERMINATED {
+		t.Errorf("Expected TERMINATED, got %v", state.State)
+	}
+	if state.Config.ImageSpec.GetBasicAuth() != nil {
+		t.Error("Expected registry credentials to be left out of the record")
+	}
+
+	// A container the previous manager left unfinished is marked failed
+	state, err = second.GetContainerStatus("pending")
+	if err != nil {
+		t.Fatalf("Expected the pending container after a restart: %v", err)
+	}
+	if state.State != pb.ContainerState_FAILED || state.GetTerminationReason() != ReasonManagerRestarted {
+		t.Errorf("Expected FAILED with reason %s, got %v with %q", ReasonManagerRestarted, state.State, state.GetTerminationReason())
+	}
+	if state.FinishedAt == nil {
+		t.Error("Expected a finish time on the abandoned container")
+	}
+
+	if containers, _, _ := second.ListContainers(ContainerQuery{Filter: "exited", Labels: map[string]string{"team": "ml"}}); len(containers) != 2 {
+		t.Errorf("Expected both records listed, got %v", containers)
+	}
+	if _, err := second.GetContainerStatus("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLogsSurviveCleanup(t *testing.T) {
+	dir := t.TempDir()
+	runner := filepath.Join(dir, "isolation-runner")
+	if err := os.WriteFile(runner, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ISOLATION_RUNNER_PATH", runner)
+	t.Setenv("HOLOPOD_LOG_DIR", filepath.Join(dir, "logs"))
+
+	m, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(m.Stop)
+
+	c := container.New("exited", &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "alpine"}})
+	file, err := m.logFiles.Create(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Logs().Persist(file)
+	c.Logs().Write(pb.OutputStream_STDOUT, []byte("hello\n"))
+	c.Terminate(false, 0)
+
+	m.mu.Lock()
+	m.containers[c.ID] = c
+	m.mu.Unlock()
+	if n := m.CleanupExitedContainersNow(); n != 1 {
+		t.Fatalf("Expected 1 container cleaned up, got %d", n)
+	}
+
+	// The logs are read back from disk once the container has left memory
+	var lines []string
+	err = m.GetLogs(context.Background(), c.ID, logs.Query{}, func(line logs.Line) error {
+		lines = append(lines, string(line.Data))
+		return nil
+	})
+	if err != nil || len(lines) == 0 || lines[0] != "hello\n" {
+		t.Errorf("GetLogs() = %q, %v, want the stored lines", lines, err)
+	}
+
+	err = m.GetLogs(context.Background(), "unknown", logs.Query{}, func(logs.Line) error { return nil })
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestReattachAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	// A stand-in runner that reads its config and then waits for signals
+	runner := filepath.Join(dir, "isolation-runner")
+	if err := os.WriteFile(runner, []byte("#!/bin/sh\nwhile read line; do :; done\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ISOLATION_RUNNER_PATH", runner)
+	t.Setenv("HOLOPOD_STATE_DB", filepath.Join(dir, "state.db"))
+
+	first, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	id, err := first.CreateContainer(context.Background(), "sandbox", &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "alpine"},
+	})
+	if err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+	before, _ := first.GetContainerStatus(id)
+
+	// The runner outlives the manager
+	first.Stop()
+
+	second, err := New()
+	if err != nil {
+		t.Fatalf("New() after restart error = %v", err)
+	}
+	t.Cleanup(second.Stop)
+
+	c, err := second.GetContainer(id)
+	if err != nil {
+		t.Fatalf("Expected the container to be reattached: %v", err)
+	}
+	state := c.GetState()
+	if state.State != pb.ContainerState_RUNNING {
+		t.Errorf("Expected RUNNING after reattaching, got %v", state.State)
+	}
+	if state.RunId != before.RunId || state.GetPid() != before.GetPid() {
+		t.Errorf("Expected run %s pid %d, got run %s pid %d", before.RunId, before.GetPid(), state.RunId, state.GetPid())
+	}
+
+	// The reattached manager controls the runner and sees it exit
+	if err := second.TerminateContainer(id, true, 5); err != nil {
+		t.Fatalf("TerminateContainer() error = %v", err)
+	}
+	if _, err := second.WaitContainer(id, 5); err != nil {
+		t.Fatalf("WaitContainer() error = %v", err)
+	}
+	if state := c.GetState(); state.FinishedAt == nil {
+		t.Errorf("Expected the runner's exit to be recorded, got %v", state)
+	}
+}
+
+func TestRestartContainer(t *testing.T) {
+	dir := t.TempDir()
+	// A stand-in runner that reads its config, says so and fails
+	runner := filepath.Join(dir, "isolation-runner")
+	script := "#!/bin/sh\nread line\necho '{\"type\":\"info\",\"message\":\"started\"}'\nexit 3\n"
+	if err := os.WriteFile(runner, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ISOLATION_RUNNER_PATH", runner)
+	t.Setenv("HOLOPOD_STATE_DB", filepath.Join(dir, "state.db"))
+
+	m, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(m.Stop)
+
+	id, err := m.CreateContainer(context.Background(), "flaky", &pb.ContainerConfig{
+		ImageSpec: &pb.ImageSpec{Image: "alpine"},
+	})
+	if err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+	first, _ := m.GetContainer(id)
+
+	// Only an exited container can be restarted
+	if _, err := m.RestartContainer(context.Background(), "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RestartContainer() of an unknown container error = %v, want ErrNotFound", err)
+	}
+	m.mu.Lock()
+	m.containers["pending"] = container.New("pending", &pb.ContainerConfig{})
+	m.mu.Unlock()
+	if _, err := m.RestartContainer(context.Background(), "pending"); !errors.Is(err, ErrNotRestartable) {
+		t.Errorf("RestartContainer() of a container yet to exit error = %v, want ErrNotRestartable", err)
+	}
+	if _, err := m.WaitContainer(id, 5); err != nil {
+		t.Fatalf("WaitContainer() error = %v", err)
+	}
+	firstLines, _, _ := first.Logs().Stats()
+
+	c, err := m.RestartContainer(context.Background(), id)
+	if err != nil {
+		t.Fatalf("RestartContainer() error = %v", err)
+	}
+	if _, err := m.WaitContainer(id, 5); err != nil {
+		t.Fatalf("WaitContainer() after restart error = %v", err)
+	}
+
+	state, err := m.GetContainerStatus(id)
+	if err != nil {
+		t.Fatalf("GetContainerStatus() error = %v", err)
+	}
+	if state.Attempt != 2 || state.RunId == first.RunID() || state.GetExitCode() != 3 {
+		t.Errorf("Expected attempt 2 of a new run exiting with 3, got %v", state)
+	}
+	if lines, _, _ := c.Logs().Stats(); lines <= firstLines {
+		t.Errorf("Expected the logs of both runs, got %d lines after %d", lines, firstLines)
+	}
+	if containers, _, _ := m.ListContainers(ContainerQuery{Image: "alpine"}); len(containers) != 1 || containers[0].Attempt != 2 {
+		t.Errorf("Expected one container on attempt 2, got %v", containers)
+	}
+}
+
+func TestHeartbeatTimeout(t *testing.T) {
+	m := &Manager{timeouts: lifecycle.Timeouts{Heartbeat: DefaultHeartbeatTimeout}}
+
+	tests := []struct {
+		name   string
+		config *pb.ContainerConfig
+		want   time.Duration
+	}{
+		{"default", &pb.ContainerConfig{}, DefaultHeartbeatTimeout},
+		{"longer", &pb.ContainerConfig{HeartbeatTimeoutSecs: proto.Uint32(600)}, 10 * time.Minute},
+		{"disabled", &pb.ContainerConfig{HeartbeatTimeoutSecs: proto.Uint32(0)}, 0},
+		{"detached", &pb.ContainerConfig{HeartbeatTimeoutSecs: proto.Uint32(600), Detached: true}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.HeartbeatTimeout(tt.config); got != tt.want {
+				t.Errorf("HeartbeatTimeout() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	m := &Manager{
+		containers: make(map[string]*container.Container),
+		timeouts:   lifecycle.Timeouts{Heartbeat: DefaultHeartbeatTimeout},
+		now:        time.Now,
+	}
+
+	configs := map[string]*pb.ContainerConfig{
+		"detached": {ImageSpec: &pb.ImageSpec{Image: "alpine"}, Detached: true},
+		"grace":    {ImageSpec: &pb.ImageSpec{Image: "alpine"}, DisconnectGraceSecs: proto.Uint32(300)},
+		"attached": {ImageSpec: &pb.ImageSpec{Image: "alpine"}},
+	}
+	for id, config := range configs {
+		m.containers[id] = container.NewWithTimeouts(id, config, m.containerTimeouts(config))
+	}
+
+	if timeouts := m.containerTimeouts(configs["detached"]); timeouts.Heartbeat != 0 {
+		t.Errorf("Expected no heartbeat timeout for a detached container, got %s", timeouts.Heartbeat)
+	}
+	if timeouts := m.containerTimeouts(configs["grace"]); timeouts.Disconnect != 5*time.Minute || timeouts.Heartbeat != DefaultHeartbeatTimeout {
+		t.Errorf("Expected a 5m disconnect grace and the heartbeat timeout, got %+v", timeouts)
+	}
+
+	for id := range configs {
+		if err := m.Disconnect(id); err != nil {
+			t.Fatalf("Disconnect(%s) error = %v", id, err)
+		}
+	}
+
+	for id, want := range map[string]pb.ContainerState{
+		"detached": pb.ContainerState_CREATED,
+		"grace":    pb.ContainerState_CREATED,
+		"attached": pb.ContainerState_TERMINATED,
+	} {
+		if got := m.containers[id].GetState().State; got != want {
+			t.Errorf("Expected %s %v after disconnecting, got %v", id, want, got)
+		}
+	}
+
+	// The grace period is up long before the detached container's limits
+	m.now = func() time.Time { return time.Now().Add(time.Hour) }
+	m.advanceLifecycles()
+	if reason := m.containers["grace"].StopReason(); reason != lifecycle.ReasonClientDisconnected {
+		t.Errorf("Expected the grace container stopped for %s, got %q", lifecycle.ReasonClientDisconnected, reason)
+	}
+	if reason := m.containers["detached"].StopReason(); reason != "" {
+		t.Errorf("Expected the detached container left running, got stop reason %q", reason)
+	}
+}
+
+func TestDisconnectWithOtherStreamsAttached(t *testing.T) {
+	m := &Manager{
+		containers: make(map[string]*container.Container),
+		timeouts:   lifecycle.Timeouts{Heartbeat: DefaultHeartbeatTimeout},
+		now:        time.Now,
+	}
+	config := &pb.ContainerConfig{ImageSpec: &pb.ImageSpec{Image: "alpine"}}
+	m.containers["c"] = container.NewWithTimeouts("c", config, m.containerTimeouts(config))
+
+	if _, err := m.Attach("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound attaching to a missing container, got %v", err)
+	}
+
+	// The stream that created the container and one that reattached after a
+	// network blip; the first noticing its connection is gone must not end it
+	for range 2 {
+		if _, err := m.Attach("c"); err != nil {
+			t.Fatalf("Attach() error = %v", err)
+		}
+	}
+	if err := m.Disconnect("c"); err != nil {
+		t.Fatalf("Disconnect() error = %v", err)
+	}
+	if got := m.containers["c"].GetState().State; got != pb.ContainerState_CREATED {
+		t.Fatalf("Expected the container left alone while a stream is attached, got %v", got)
+	}
+
+	if err := m.Disconnect("c"); err != nil {
+		t.Fatalf("Disconnect() error = %v", err)
+	}
+	if got := m.containers["c"].GetState().State; got != pb.ContainerState_TERMINATED {
+		t.Errorf("Expected the container terminated once its last stream ended, got %v", got)
+	}
+}
+
+func TestForwardEvents(t *testing.T) {
+	runner := filepath.Join(t.TempDir(), "isolation-runner")
+	if err := os.WriteFile(runner, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ISOLATION_RUNNER_PATH", runner)
+
+	m, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(m.Stop)
+
+	events, unsubscribe := m.events.Subscribe(false)
+	defer unsubscribe()
+
+	c := container.New("c1", &pb.ContainerConfig{Labels: map[string]string{TenantLabel: "acme"}})
+	m.forwardEvents(c)
+	c.TerminateWithReason("shutdown", true, 0)
+
+	select {
+	case event := <-events:
+		if event.ContainerId != "c1" || event.Labels[TenantLabel] != "acme" {
+			t.Errorf("event = %v, want one of c1 with its labels", event)
+		}
+		terminating := event.Event.GetContainerTerminating()
+		if terminating == nil || terminating.Reason != "shutdown" || !terminating.Force {
+			t.Errorf("event = %v, want container_terminating", event.Event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event forwarded")
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	event := &pb.ContainerEvent{
+		ContainerId: "c1",
+		State:       pb.ContainerState_RUNNING,
+		Labels:      map[string]string{TenantLabel: "acme", "team": "ml"},
+		Event:       &pb.Event{Type: "container_ready"},
+	}
+	stats := &pb.ContainerEvent{State: pb.ContainerState_RUNNING, Event: &pb.Event{Type: "container_stats"}}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		event  *pb.ContainerEvent
+		want   bool
+	}{
+		{"no filter", EventFilter{}, event, true},
+		{"labels", EventFilter{Labels: map[string]string{TenantLabel: "acme"}}, event, true},
+		{"other labels", EventFilter{Labels: map[string]string{TenantLabel: "other"}}, event, false},
+		{"state", EventFilter{States: []pb.ContainerState{pb.ContainerState_RUNNING}}, event, true},
+		{"other state", EventFilter{States: []pb.ContainerState{pb.ContainerState_EXITED}}, event, false},
+		{"type", EventFilter{Types: []string{"container_ready"}}, event, true},
+		{"other type", EventFilter{Types: []string{"container_exited"}}, event, false},
+		{"quiet type", EventFilter{}, stats, false},
+		{"quiet type asked for", EventFilter{Types: []string{"container_stats"}}, stats, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(tt.event); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhooksNotified(t *testing.T) {
+	notified := make(chan webhook.Notification, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n webhook.Notification
+		json.NewDecoder(r.Body).Decode(&n)
+		notified <- n
+	}))
+	defer server.Close()
+
+	runner := filepath.Join(t.TempDir(), "isolation-runner")
+	if err := os.WriteFile(runner, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ISOLATION_RUNNER_PATH", runner)
+	t.Setenv("WEBHOOK_URLS", server.URL)
+
+	m, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(m.Stop)
+
+	c := container.New("c1", &pb.ContainerConfig{Labels: map[string]string{"team": "ml"}})
+	m.forwardEvents(c)
+	c.TerminateWithReason("shutdown", false, 0)
+
+	select {
+	case n := <-notified:
+		if n.Event != webhook.EventExited || n.ContainerID != "c1" || n.State != "terminated" ||
+			n.TerminationReason == nil || *n.TerminationReason != "shutdown" || n.Labels["team"] != "ml" {
+			t.Errorf("notification = %+v", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook not notified of the exit")
+	}
+}
@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"log"
+	"runtime"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/resources"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+// NodeResources reports the capacity and usage of this node, which schedulers
+// use to place containers. Memory and disk are required; CPU usage, load and
+// container memory are left zero when the host does not expose them.
+func (m *Manager) NodeResources() (*pb.NodeResources, error) {
+	memory, err := resources.HostMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	dataRoot := m.dockerDataRoot
+	if dataRoot == "" {
+		dataRoot = resources.DefaultDockerDataRoot
+	}
+	disk, err := resources.DiskUsage(dataRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	totalContainers, runningContainers := m.GetStats()
+
+	node := &pb.NodeResources{
+		CpuCores:             uint32(runtime.NumCPU()),
+		MemoryTotalBytes:     memory.Total,
+		MemoryAvailableBytes: memory.Available,
+		MemoryUsedBytes:      memory.Used,
+		MemoryUsagePercent:   percent(memory.Used, memory.Total),
+		DiskTotalBytes:       disk.Total,
+		DiskAvailableBytes:   disk.Available,
+		DiskUsedBytes:        disk.Used,
+		DiskUsagePercent:     percent(disk.Used, disk.Used+disk.Available),
+		RunningContainers:    uint32(runningContainers),
+		TotalContainers:      uint32(totalContainers),
+	}
+
+	if sample, err := resources.HostCPUSample(); err == nil {
+		m.cpuMu.Lock()
+		node.CpuUsagePercent = float32(sample.UsagePercent(m.cpuSample))
+		m.cpuSample = sample
+		m.cpuMu.Unlock()
+	}
+
+	if load, err := resources.LoadAverage(); err == nil {
+		node.Load_1Min, node.Load_5Min, node.Load_15Min = float32(load[0]), float32(load[1]), float32(load[2])
+	}
+
+	if used, err := resources.ContainerMemoryBytes(); err == nil {
+		node.ContainerMemoryUsedBytes = used
+	} else {
+		log.Printf("Failed to read container memory usage: %v", err)
+	}
+
+	return node, nil
+}
+
+func percent(part, whole uint64) float32 {
+	if whole == 0 {
+		return 0
+	}
+	return float32(float64(part) / float64(whole) * 100)
+}
@@ -0,0 +1,268 @@
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/container"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/store"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+const (
+	// DefaultRecordRetention is how long the record of a finished container is
+	// kept in the state database
+	DefaultRecordRetention = 7 * 24 * time.Hour
+
+	// ReasonManagerRestarted is recorded on containers that were still running
+	// when a previous manager process went away
+	ReasonManagerRestarted = "manager_restarted"
+
+	// How often records past their retention are pruned
+	recordPruneInterval = time.Hour
+)
+
+// openStore opens the state database named by HOLOPOD_STATE_DB, or returns nil
+// when container records are not persisted. Runners are then started through
+// shims whose sockets are in the returned directory next to the database, so
+// a restarted manager can reattach to them.
+func openStore() (*store.Store, string, error) {
+	path := os.Getenv("HOLOPOD_STATE_DB")
+	if path == "" {
+		return nil, "", nil
+	}
+
+	s, err := store.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	shimDir := filepath.Join(filepath.Dir(path), "shims")
+	if err := os.MkdirAll(shimDir, 0o700); err != nil {
+		s.Close()
+		return nil, "", fmt.Errorf("failed to create shim directory: %w", err)
+	}
+	return s, shimDir, nil
+}
+
+// shimSocket is where the shim of a container's runner listens. Container IDs
+// are hashed so that any ID makes a short, safe file name. Every restart gets
+// its own socket, since the shim of the previous run removes its own on the
+// way out.
+func (m *Manager) shimSocket(containerID string, attempt uint32) string {
+	name := containerID
+	if attempt > 1 {
+		name += "/" + strconv.FormatUint(uint64(attempt), 10)
+	}
+	sum := sha256.Sum256([]byte(name))
+	return filepath.Join(m.shimDir, hex.EncodeToString(sum[:12])+".sock")
+}
+
+// recoverRecords reattaches to the runners of containers a previous manager
+// process left running, marks the records of those it cannot reattach to as
+// failed, since nothing is watching them any more, and prunes records past
+// their retention
+func (m *Manager) recoverRecords() error {
+	records, err := m.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to load container records: %w", err)
+	}
+
+	now := strconv.FormatInt(m.now().Unix(), 10)
+	var abandoned []*pb.ContainerStatus
+	for _, record := range records {
+		if isFinished(record.State) {
+			continue
+		}
+		if c, err := m.reattach(record); err == nil {
+			m.containers[c.ID] = c
+			m.forwardEvents(c)
+			log.Printf("Reattached to container %s (run %s)", c.ID, c.RunID())
+			continue
+		} else if record.State == pb.ContainerState_RUNNING {
+			log.Printf("Failed to reattach to container %s: %v", record.ContainerId, err)
+		}
+		record.State = pb.ContainerState_FAILED
+		record.FinishedAt = &now
+		record.TerminationReason = proto.String(ReasonManagerRestarted)
+		record.Paused = false
+		record.Ready = false
+		record.Pid = nil
+		abandoned = append(abandoned, record)
+	}
+	if len(abandoned) > 0 {
+		log.Printf("Marking %d containers left by a previous manager as failed", len(abandoned))
+		if err := m.store.Put(abandoned...); err != nil {
+			return fmt.Errorf("failed to update container records: %w", err)
+		}
+	}
+
+	m.pruneRecords()
+	return nil
+}
+
+// reattach takes over the runner of a container a previous manager process
+// started through a shim
+func (m *Manager) reattach(record *pb.ContainerStatus) (*container.Container, error) {
+	if m.shimDir == "" {
+		return nil, errors.New("runners are not started through shims")
+	}
+	c, err := container.Reattach(record, m.containerTimeouts(record.Config), m.shimSocket(record.ContainerId, record.Attempt))
+	if err != nil {
+		return nil, err
+	}
+	c.SetOutputBuffering(m.outputBuffering)
+	c.Logs().SetLimits(m.maxLogLines, m.maxLogBytes)
+
+	// Their URLs lost any query with the record, but secrets still resolve
+	if endpoints, err := m.resolveWebhooks(context.Background(), record.Config.GetWebhooks()); err != nil {
+		log.Printf("Failed to resolve webhooks of container %s: %v", c.ID, err)
+	} else {
+		c.SetWebhooks(endpoints)
+	}
+
+	// The logs pick up where the previous manager left them
+	if m.logFiles != nil {
+		if err := m.logFiles.Load(c.Logs(), c.ID); err != nil {
+			log.Printf("Failed to load stored logs of container %s: %v", c.ID, err)
+		}
+		if file, err := m.logFiles.Open(c.ID); err != nil {
+			log.Printf("Failed to store logs of container %s: %v", c.ID, err)
+		} else {
+			c.Logs().Persist(file)
+		}
+	}
+	return c, nil
+}
+
+// saveRecords writes the current status of the given containers to the state
+// database, skipping those unchanged since they were last written
+func (m *Manager) saveRecords(containers []*container.Container) {
+	if m.store == nil {
+		return
+	}
+
+	m.recordsMu.Lock()
+	defer m.recordsMu.Unlock()
+
+	var changed []*pb.ContainerStatus
+	encoded := make(map[string][]byte)
+	for _, c := range containers {
+		state := c.GetState()
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(state)
+		if err != nil {
+			log.Printf("Failed to encode record of container %s: %v", c.ID, err)
+			continue
+		}
+		if string(data) == string(m.saved[c.ID]) {
+			continue
+		}
+		changed = append(changed, state)
+		encoded[c.ID] = data
+	}
+
+	if err := m.store.Put(changed...); err != nil {
+		log.Printf("Failed to save %d container records: %v", len(changed), err)
+		return
+	}
+	for id, data := range encoded {
+		m.saved[id] = data
+	}
+}
+
+// saveAllRecords writes the status of every container held in memory
+func (m *Manager) saveAllRecords() {
+	m.mu.RLock()
+	containers := make([]*container.Container, 0, len(m.containers))
+	for _, c := range m.containers {
+		containers = append(containers, c)
+	}
+	m.mu.RUnlock()
+
+	m.saveRecords(containers)
+}
+
+// forgetRecords drops what was last written for containers no longer held in
+// memory; their records stay in the state database
+func (m *Manager) forgetRecords(containers []*container.Container) {
+	if m.store == nil {
+		return
+	}
+
+	m.recordsMu.Lock()
+	defer m.recordsMu.Unlock()
+	for _, c := range containers {
+		delete(m.saved, c.ID)
+	}
+}
+
+// pruneRecords removes the records of containers that finished longer than
+// the retention ago
+func (m *Manager) pruneRecords() {
+	if m.store == nil || m.recordRetention <= 0 {
+		return
+	}
+
+	pruned, err := m.store.Prune(m.now().Add(-m.recordRetention))
+	if err != nil {
+		log.Printf("Failed to prune container records: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("Pruned %d container records past retention", pruned)
+	}
+}
+
+// storedRecord returns the persisted status of a container no longer held in
+// memory
+func (m *Manager) storedRecord(containerID string) (*pb.ContainerStatus, error) {
+	if m.store == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, containerID)
+	}
+
+	record, err := m.store.Get(containerID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, containerID)
+	}
+	return record, err
+}
+
+// storedRecords returns the persisted status of every container not held in
+// memory
+func (m *Manager) storedRecords() []*pb.ContainerStatus {
+	if m.store == nil {
+		return nil
+	}
+
+	records, err := m.store.List()
+	if err != nil {
+		log.Printf("Failed to load container records: %v", err)
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stored []*pb.ContainerStatus
+	for _, record := range records {
+		if _, live := m.containers[record.ContainerId]; !live {
+			stored = append(stored, record)
+		}
+	}
+	return stored
+}
+
+func isFinished(state pb.ContainerState) bool {
+	return state == pb.ContainerState_EXITED ||
+		state == pb.ContainerState_FAILED ||
+		state == pb.ContainerState_TERMINATED
+}
@@ -0,0 +1,44 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/grpc/status"
+)
+
+// CheckpointRequest is the body of POST /v1/containers/{id}/checkpoint
+type CheckpointRequest struct {
+	CheckpointID string `json:"checkpointId"`
+	// LeaveRunning keeps the container running once its state is saved
+	LeaveRunning bool `json:"leaveRunning,omitempty"`
+}
+
+// HandleCheckpoint saves a running container's state so later containers can
+// start from it with restoreFrom (experimental)
+func (s *Server) HandleCheckpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CheckpointRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.client.Checkpoint(r.Context(), &pb.CheckpointRequest{
+		ContainerId:  r.PathValue("id"),
+		CheckpointId: req.CheckpointID,
+		LeaveRunning: req.LeaveRunning,
+	})
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpstatus.FromError(err))
+		return
+	}
+
+	writeResult(w, resp.Success, resp.Error)
+}
@@ -0,0 +1,137 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/container"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadChunkSize is the archive data sent per UploadFile message
+const uploadChunkSize = 256 << 10
+
+// HandleFiles copies files in and out of a running container as tar archives.
+// PUT extracts the request body into the directory named by the path query
+// parameter; GET responds with an archive of path.
+func (s *Server) HandleFiles(w http.ResponseWriter, r *http.Request) {
+	containerID := r.PathValue("id")
+	filePath := r.URL.Query().Get("path")
+	if filePath == "" {
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		s.uploadFile(w, r, containerID, filePath)
+	case http.MethodGet:
+		s.downloadFile(w, r, containerID, filePath)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) uploadFile(w http.ResponseWriter, r *http.Request, containerID, dir string) {
+	stream, err := s.client.UploadFile(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), httpstatus.FromError(err))
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, container.MaxFileTransferBytes)
+	msg := &pb.UploadFileRequest{ContainerId: containerID, Path: dir}
+	buf := make([]byte, uploadChunkSize)
+
+	for {
+		n, readErr := io.ReadFull(body, buf)
+		msg.Data = append([]byte(nil), buf[:n]...)
+		if err := stream.Send(msg); err != nil {
+			// The server ended the stream; its status follows from CloseAndRecv
+			break
+		}
+		msg = &pb.UploadFileRequest{}
+
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(readErr, &maxErr) {
+				http.Error(w, container.ErrTransferTooLarge.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, readErr.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), fileTransferStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !resp.Success {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success": resp.Success,
+		"bytes":   resp.Bytes,
+		"error":   resp.Error,
+	})
+}
+
+func (s *Server) downloadFile(w http.ResponseWriter, r *http.Request, containerID, filePath string) {
+	stream, err := s.client.DownloadFile(r.Context(), &pb.DownloadFileRequest{
+		ContainerId: containerID,
+		Path:        filePath,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), httpstatus.FromError(err))
+		return
+	}
+
+	// Wait for the first chunk so failures can still set the status code
+	chunk, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		http.Error(w, status.Convert(err).Message(), fileTransferStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": archiveName(filePath)}))
+
+	for err == nil {
+		if _, writeErr := w.Write(chunk.Data); writeErr != nil {
+			return
+		}
+		chunk, err = stream.Recv()
+	}
+	// A failure past this point can only truncate the archive already being sent
+}
+
+// fileTransferStatus is httpStatus, except an oversized archive is reported as such
+func fileTransferStatus(err error) int {
+	if status.Code(err) == codes.ResourceExhausted {
+		return http.StatusRequestEntityTooLarge
+	}
+	return httpstatus.FromError(err)
+}
+
+// archiveName names the downloaded archive after the last element of filePath
+func archiveName(filePath string) string {
+	name := path.Base(path.Clean(filePath))
+	if name == "/" || name == "." {
+		name = "root"
+	}
+	return name + ".tar"
+}
@@ -0,0 +1,58 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/grpc/status"
+)
+
+// HandlePause freezes every process of a running container, keeping its state,
+// until it is unpaused
+func (s *Server) HandlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := s.client.PauseContainer(r.Context(), &pb.PauseContainerRequest{
+		ContainerId: r.PathValue("id"),
+	})
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpstatus.FromError(err))
+		return
+	}
+
+	writeResult(w, resp.Success, resp.Error)
+}
+
+// HandleUnpause resumes a paused container
+func (s *Server) HandleUnpause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := s.client.UnpauseContainer(r.Context(), &pb.UnpauseContainerRequest{
+		ContainerId: r.PathValue("id"),
+	})
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpstatus.FromError(err))
+		return
+	}
+
+	writeResult(w, resp.Success, resp.Error)
+}
+
+func writeResult(w http.ResponseWriter, success bool, errMsg *string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !success {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success": success,
+		"error":   errMsg,
+	})
+}
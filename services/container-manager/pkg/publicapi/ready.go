@@ -0,0 +1,46 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/grpc/status"
+)
+
+// HandleWaitReady blocks until the container is ready, answering 504 if the
+// optional ?timeout= seconds pass first and 409 if it exits first
+func (s *Server) HandleWaitReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := &pb.WaitReadyRequest{ContainerId: r.PathValue("id")}
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		secs, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			http.Error(w, "timeout must be a number of seconds", http.StatusBadRequest)
+			return
+		}
+		timeout := uint32(secs)
+		req.TimeoutSecs = &timeout
+	}
+
+	resp, err := s.client.WaitReady(r.Context(), req)
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpstatus.FromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !resp.Ready {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ready": resp.Ready,
+		"error": resp.Error,
+	})
+}
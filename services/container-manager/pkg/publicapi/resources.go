@@ -0,0 +1,48 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/grpc/status"
+)
+
+// HandleUpdateResources changes the CPU and/or memory limits of a running
+// container without restarting it; the body is a ResourceLimits
+func (s *Server) HandleUpdateResources(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var limits ResourceLimits
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&limits); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.client.UpdateContainerResources(r.Context(), &pb.UpdateContainerResourcesRequest{
+		ContainerId: r.PathValue("id"),
+		Resources:   limits.toProto(),
+	})
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpstatus.FromError(err))
+		return
+	}
+	if !resp.Success {
+		writeResult(w, resp.Success, resp.Error)
+		return
+	}
+
+	var updated ResourceLimits
+	if resp.Resources != nil {
+		updated = ResourceLimits{CPULimit: resp.Resources.CpuLimit, MemoryLimit: resp.Resources.MemoryLimit}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success":   true,
+		"resources": updated,
+	})
+}
@@ -0,0 +1,38 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/grpc/status"
+)
+
+// HandleRestart runs an exited container again with the same config, as its
+// next attempt
+func (s *Server) HandleRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := s.client.RestartContainer(r.Context(), &pb.RestartContainerRequest{
+		ContainerId: r.PathValue("id"),
+	})
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpstatus.FromError(err))
+		return
+	}
+	if !resp.Success {
+		writeResult(w, resp.Success, resp.Error)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success": true,
+		"attempt": resp.Attempt,
+		"runId":   resp.RunId,
+	})
+}
@@ -0,0 +1,133 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// runRequestSchema is generated once from the request structs so it cannot drift from them
+var runRequestSchema = sync.OnceValue(func() []byte {
+	g := &schemaGenerator{defs: make(map[string]any)}
+	create := g.schemaFor(reflect.TypeOf(CreateEnvelope{}))
+
+	schema := map[string]any{
+		"$schema":     schemaDialect,
+		"$id":         "/v1/schema/run-request",
+		"title":       "RunRequest",
+		"description": "First message sent on the /v1/run WebSocket, creating the container",
+		"type":        "object",
+		"properties": map[string]any{
+			"type":   map[string]any{"const": "create"},
+			"create": create,
+		},
+		"required": []string{"type", "create"},
+		"$defs":    g.defs,
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return data
+})
+
+// schemaGenerator builds JSON Schema from Go types using their json tags. Named
+// structs are emitted once under $defs and referenced elsewhere.
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema := map[string]any{"type": "integer", "minimum": 0}
+		if t.Bits() <= 32 {
+			schema["maximum"] = uint64(1)<<t.Bits() - 1
+		}
+		return schema
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json carries []byte as a base64 string
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			// Reserve the name first so recursive types terminate
+			g.defs[name] = nil
+			g.defs[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := g.schemaFor(field.Type)
+		if enum := field.Tag.Get("enum"); enum != "" {
+			target := schema
+			if items, ok := schema["items"].(map[string]any); ok {
+				target = items
+			}
+			target["enum"] = strings.Split(enum, "|")
+		}
+		properties[name] = schema
+
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// HandleRunRequestSchema serves the JSON Schema of the /v1/run create message
+func (s *Server) HandleRunRequestSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	_, _ = w.Write(runRequestSchema())
+}
@@ -0,0 +1,97 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestRunRequestSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Required   []string                  `json:"required"`
+		Defs       map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(runRequestSchema(), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	if schema.Properties["type"]["const"] != "create" {
+		t.Errorf("expected type const create, got %v", schema.Properties["type"])
+	}
+	if schema.Properties["create"]["$ref"] != "#/$defs/CreateEnvelope" {
+		t.Errorf("expected create to reference CreateEnvelope, got %v", schema.Properties["create"])
+	}
+
+	for _, name := range []string{"CreateEnvelope", "ContainerConfig", "ImageSpec", "BasicAuth", "ResourceLimits", "NetworkConfig", "NetworkRule"} {
+		if _, ok := schema.Defs[name]; !ok {
+			t.Errorf("missing $defs entry %s", name)
+		}
+	}
+
+	// Every json field of the Go structs must appear, so the schema tracks the structs
+	for _, v := range []any{ContainerConfig{}, NetworkConfig{}, ImageSpec{}, Workspace{}, ReadinessProbe{}, RuntimeOptions{}, AuxContainer{}, PostExitHook{}, SecretRef{}} {
+		typ := reflect.TypeOf(v)
+		def := schema.Defs[typ.Name()]
+		if len(def.Properties) != typ.NumField() {
+			t.Errorf("%s: expected %d properties, got %d", typ.Name(), typ.NumField(), len(def.Properties))
+		}
+	}
+
+	tests := []struct {
+		def      string
+		required []string
+	}{
+		{"CreateEnvelope", []string{"config"}},
+		{"ContainerConfig", []string{"imageSpec"}},
+		{"ImageSpec", []string{"image"}},
+		{"NetworkRule", []string{"action"}},
+		{"NetworkConfig", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.def, func(t *testing.T) {
+			if got := schema.Defs[tt.def].Required; !slices.Equal(got, tt.required) {
+				t.Errorf("required = %v, want %v", got, tt.required)
+			}
+		})
+	}
+
+	streams := schema.Defs["CreateEnvelope"].Properties["streams"]["items"].(map[string]any)
+	if !reflect.DeepEqual(streams["enum"], []any{"stdout", "stderr", "events"}) {
+		t.Errorf("expected streams item enum, got %v", streams)
+	}
+	if enum := schema.Defs["NetworkRule"].Properties["action"]["enum"]; !reflect.DeepEqual(enum, []any{"allow", "deny"}) {
+		t.Errorf("expected action enum [allow deny], got %v", enum)
+	}
+	if archive := schema.Defs["Workspace"].Properties["archive"]; archive["type"] != "string" || archive["contentEncoding"] != "base64" {
+		t.Errorf("expected archive to be a base64 string, got %v", archive)
+	}
+	if maxValue := schema.Defs["ContainerConfig"].Properties["timeoutSecs"]["maximum"]; maxValue != float64(4294967295) {
+		t.Errorf("expected uint32 maximum on timeoutSecs, got %v", maxValue)
+	}
+}
+
+func TestHandleRunRequestSchema(t *testing.T) {
+	s := &Server{}
+
+	rec := httptest.NewRecorder()
+	s.HandleRunRequestSchema(rec, httptest.NewRequest(http.MethodGet, "/v1/schema/run-request", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/schema+json" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	rec = httptest.NewRecorder()
+	s.HandleRunRequestSchema(rec, httptest.NewRequest(http.MethodPost, "/v1/schema/run-request", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rec.Code)
+	}
+}
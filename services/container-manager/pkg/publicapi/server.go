@@ -2,16 +2,21 @@ package publicapi
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/websocket"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
@@ -36,16 +41,53 @@ func NewServer(grpcAddr string) (*Server, error) {
 }
 
 type IncomingMessage struct {
-	Type        string          `json:"type"`
-	Create      *CreateEnvelope `json:"create,omitempty"`
-	Stdin       *string         `json:"stdin,omitempty"`
-	Force       *bool           `json:"force,omitempty"`
-	TimeoutSecs *uint32         `json:"timeoutSecs,omitempty"`
+	Type   string          `json:"type"`
+	Create *CreateEnvelope `json:"create,omitempty"`
+	Stdin  *string         `json:"stdin,omitempty"`
+	// Encoding is "base64" when Stdin carries base64-encoded bytes
+	Encoding    *string `json:"encoding,omitempty" enum:"base64"`
+	Force       *bool   `json:"force,omitempty"`
+	TimeoutSecs *uint32 `json:"timeoutSecs,omitempty"`
+	// Network carries the replacement policy for update_network_policy
+	Network *NetworkConfig `json:"network,omitempty"`
+	// Resources carries the new limits for update_resources
+	Resources *ResourceLimits `json:"resources,omitempty"`
+	Exec      *ExecEnvelope   `json:"exec,omitempty"`
+	// Rows and Cols carry the terminal size for resize
+	Rows *uint32 `json:"rows,omitempty"`
+	Cols *uint32 `json:"cols,omitempty"`
+	// Signal names the signal to send, e.g. "SIGHUP"
+	Signal *string `json:"signal,omitempty"`
+}
+
+// ExecEnvelope runs an additional process in the running container. Its output
+// and exit code arrive as exec messages carrying the same execId.
+type ExecEnvelope struct {
+	ExecID      *string           `json:"execId,omitempty"`
+	Command     []string          `json:"command"`
+	Env         map[string]string `json:"env,omitempty"`
+	Workdir     *string           `json:"workdir,omitempty"`
+	TimeoutSecs *uint32           `json:"timeoutSecs,omitempty"`
 }
 
 type CreateEnvelope struct {
 	ContainerID *string         `json:"containerId,omitempty"`
 	Config      ContainerConfig `json:"config"`
+	// Streams limits the outputs delivered on the socket; empty means all
+	Streams []string `json:"streams,omitempty" enum:"stdout|stderr|events"`
+}
+
+// parseStreams converts stream names to their proto values
+func parseStreams(names []string) ([]pb.OutputStream, error) {
+	streams := make([]pb.OutputStream, 0, len(names))
+	for _, name := range names {
+		value, ok := pb.OutputStream_value[strings.ToUpper(name)]
+		if !ok || value == int32(pb.OutputStream_OUTPUT_STREAM_UNSPECIFIED) {
+			return nil, fmt.Errorf("unknown stream %q (expected stdout, stderr or events)", name)
+		}
+		streams = append(streams, pb.OutputStream(value))
+	}
+	return streams, nil
 }
 
 type BasicAuth struct {
@@ -65,7 +107,7 @@ type ResourceLimits struct {
 }
 
 type NetworkRule struct {
-	Action         string  `json:"action"`
+	Action         string  `json:"action" enum:"allow|deny"`
 	Protocol       *string `json:"protocol,omitempty"`
 	Destination    *string `json:"destination,omitempty"`
 	PortRangeStart *uint32 `json:"portRangeStart,omitempty"`
@@ -73,9 +115,71 @@ type NetworkRule struct {
 }
 
 type NetworkConfig struct {
-	Rules         []NetworkRule `json:"rules,omitempty"`
-	DefaultPolicy *string       `json:"defaultPolicy,omitempty"`
-	DNSServers    []string      `json:"dnsServers,omitempty"`
+	Rules              []NetworkRule `json:"rules,omitempty"`
+	DefaultPolicy      *string       `json:"defaultPolicy,omitempty" enum:"allow|deny"`
+	DNSServers         []string      `json:"dnsServers,omitempty"`
+	MaxConnections     *uint32       `json:"maxConnections,omitempty"`
+	LogNetworkAttempts *bool         `json:"logNetworkAttempts,omitempty"`
+	DNSAllowedDomains  []string      `json:"dnsAllowedDomains,omitempty"`
+	Subnet             *string       `json:"subnet,omitempty"`
+	StaticIP           *string       `json:"staticIp,omitempty"`
+	PodGroup           *string       `json:"podGroup,omitempty"`
+}
+
+func (s ImageSpec) toProto() *pb.ImageSpec {
+	imageSpec := &pb.ImageSpec{
+		Registry: s.Registry,
+		Image:    s.Image,
+	}
+	if s.BasicAuth != nil {
+		imageSpec.Auth = &pb.ImageSpec_BasicAuth{
+			BasicAuth: &pb.BasicAuth{
+				Username: s.BasicAuth.Username,
+				Password: s.BasicAuth.Password,
+			},
+		}
+	}
+	return imageSpec
+}
+
+func (r *ResourceLimits) toProto() *pb.ResourceLimits {
+	if r == nil {
+		return nil
+	}
+	return &pb.ResourceLimits{
+		CpuLimit:    r.CPULimit,
+		MemoryLimit: r.MemoryLimit,
+	}
+}
+
+func (n NetworkConfig) toProto() *pb.NetworkConfig {
+	rules := make([]*pb.NetworkRule, 0, len(n.Rules))
+	for _, rule := range n.Rules {
+		rules = append(rules, &pb.NetworkRule{
+			Action:         rule.Action,
+			Protocol:       rule.Protocol,
+			Destination:    rule.Destination,
+			PortRangeStart: rule.PortRangeStart,
+			PortRangeEnd:   rule.PortRangeEnd,
+		})
+	}
+	return &pb.NetworkConfig{
+		Rules:              rules,
+		DefaultPolicy:      n.DefaultPolicy,
+		DnsServers:         n.DNSServers,
+		MaxConnections:     n.MaxConnections,
+		LogNetworkAttempts: n.LogNetworkAttempts,
+		DnsAllowedDomains:  n.DNSAllowedDomains,
+		Subnet:             n.Subnet,
+		StaticIp:           n.StaticIP,
+		PodGroup:           n.PodGroup,
+	}
+}
+
+type PortMapping struct {
+	ContainerPort uint32  `json:"containerPort"`
+	HostPort      *uint32 `json:"hostPort,omitempty"`
+	Protocol      *string `json:"protocol,omitempty" enum:"tcp|udp"`
 }
 
 type ContainerConfig struct {
@@ -88,6 +192,122 @@ type ContainerConfig struct {
 	Network     *NetworkConfig    `json:"network,omitempty"`
 	TimeoutSecs *uint32           `json:"timeoutSecs,omitempty"`
 	Cleanup     *bool             `json:"cleanup,omitempty"`
+	Ports       []PortMapping     `json:"ports,omitempty"`
+	Workspace   *Workspace        `json:"workspace,omitempty"`
+	// Tty runs the command on a pseudo-terminal, e.g. for xterm.js; its
+	// output arrives as stdout and resize messages set its size
+	Tty *bool `json:"tty,omitempty"`
+	// RestoreFrom resumes from a checkpoint instead of starting the command
+	// afresh (experimental)
+	RestoreFrom *string `json:"restoreFrom,omitempty"`
+	// StatsIntervalSecs is how often container_stats events report resource
+	// usage (default 10, 0 disables them)
+	StatsIntervalSecs *uint32 `json:"statsIntervalSecs,omitempty"`
+	// ReadinessProbe holds back the container_ready event until it passes
+	ReadinessProbe *ReadinessProbe `json:"readinessProbe,omitempty"`
+	// IdleTimeoutSecs acts on the container after this long without stdio
+	// traffic or CPU use, by idleAction (default terminate)
+	IdleTimeoutSecs *uint32 `json:"idleTimeoutSecs,omitempty"`
+	IdleAction      *string `json:"idleAction,omitempty" enum:"terminate|pause"`
+	// CPUTimeLimitSecs terminates the container once it has used this much
+	// CPU time, however long timeoutSecs is
+	CPUTimeLimitSecs *uint32 `json:"cpuTimeLimitSecs,omitempty"`
+	// Runtime is the OCI runtime, e.g. runsc-kvm; it must be allowed by the operator
+	Runtime *string `json:"runtime,omitempty"`
+	// RuntimeOptions sets gVisor flags the operator allows for this container alone
+	RuntimeOptions *RuntimeOptions `json:"runtimeOptions,omitempty"`
+	// Sidecars run alongside the container in its network namespace
+	Sidecars []AuxContainer `json:"sidecars,omitempty"`
+	// InitContainers run one after another, each to a zero exit, before the
+	// container starts
+	InitContainers []AuxContainer `json:"initContainers,omitempty"`
+	// PostExitHooks run one after another once the workload has exited and
+	// before it is cleaned up
+	PostExitHooks []PostExitHook `json:"postExitHooks,omitempty"`
+	// Labels are set on the Docker container and can be filtered on when
+	// listing containers
+	Labels map[string]string `json:"labels,omitempty"`
+	// SecretEnv sets environment variables from the operator's secrets
+	// backend, so their values never appear in the request
+	SecretEnv map[string]SecretRef `json:"secretEnv,omitempty"`
+	// QuietOutput drops the runner's human-oriented log messages, leaving
+	// only structured events and container output
+	QuietOutput bool `json:"quietOutput,omitempty"`
+	// Detached keeps the container running when the websocket closes, for
+	// fire-and-forget jobs
+	Detached bool `json:"detached,omitempty"`
+	// DisconnectGraceSecs keeps a container that is not detached running this
+	// long after the websocket drops, for the client to come back
+	DisconnectGraceSecs *uint32 `json:"disconnectGraceSecs,omitempty"`
+	// HeartbeatTimeoutSecs is how long the websocket may go without a
+	// heartbeat message before the container is terminated; 0 requires none.
+	// The manager's default applies when unset.
+	HeartbeatTimeoutSecs *uint32 `json:"heartbeatTimeoutSecs,omitempty"`
+	// Webhooks are POSTed the container's created, ready, exited and failed
+	// events
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+}
+
+// SecretRef names a secret; key selects a field of a Vault secret
+type SecretRef struct {
+	Name string  `json:"name"`
+	Key  *string `json:"key,omitempty"`
+}
+
+// Webhook is an https endpoint notified of the container's lifecycle. With a
+// secret, deliveries carry an HMAC-SHA256 X-Holopod-Signature header.
+type Webhook struct {
+	URL    string     `json:"url"`
+	Secret *SecretRef `json:"secret,omitempty"`
+	Events []string   `json:"events,omitempty" enum:"created|ready|exited|failed"`
+}
+
+// PostExitHook runs a helper container or POSTs the exit to an https webhook;
+// set exactly one of container and webhookURL. A container hook's own name is
+// ignored.
+type PostExitHook struct {
+	Name        string        `json:"name"`
+	Container   *AuxContainer `json:"container,omitempty"`
+	WebhookURL  *string       `json:"webhookUrl,omitempty"`
+	TimeoutSecs *uint32       `json:"timeoutSecs,omitempty"`
+}
+
+// AuxContainer is a sidecar, e.g. a database reachable from the container over
+// localhost and removed with it, or an init container
+type AuxContainer struct {
+	Name      string            `json:"name"`
+	ImageSpec ImageSpec         `json:"imageSpec"`
+	Command   []string          `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	Resources *ResourceLimits   `json:"resources,omitempty"`
+}
+
+// RuntimeOptions are per-container runsc flags
+type RuntimeOptions struct {
+	Platform *string `json:"platform,omitempty" enum:"systrap|ptrace|kvm"`
+	Network  *string `json:"network,omitempty" enum:"sandbox|host"`
+	DirectFS *bool   `json:"directfs,omitempty"`
+}
+
+// ReadinessProbe checks that the workload is ready: set command, or port for a
+// TCP connect, or port and httpPath for an HTTP GET answering 2xx or 3xx
+type ReadinessProbe struct {
+	Port             *uint32  `json:"port,omitempty"`
+	HTTPPath         *string  `json:"httpPath,omitempty"`
+	Command          []string `json:"command,omitempty"`
+	InitialDelaySecs *uint32  `json:"initialDelaySecs,omitempty"`
+	PeriodSecs       *uint32  `json:"periodSecs,omitempty"`
+	TimeoutSecs      *uint32  `json:"timeoutSecs,omitempty"`
+}
+
+// Workspace is a gzipped tar archive extracted into the container before its
+// command starts, given inline (base64) or as an https URL
+type Workspace struct {
+	Archive []byte  `json:"archive,omitempty"`
+	URL     *string `json:"url,omitempty"`
+	Path    *string `json:"path,omitempty"`
+	Mount   *string `json:"mount,omitempty" enum:"volume|tmpfs"`
 }
 
 func (c ContainerConfig) toProto() (*pb.ContainerConfig, error) {
@@ -100,59 +320,207 @@ func (c ContainerConfig) toProto() (*pb.ContainerConfig, error) {
 		cleanup = *c.Cleanup
 	}
 
-	imageSpec := &pb.ImageSpec{
-		Registry: c.ImageSpec.Registry,
-		Image:    c.ImageSpec.Image,
+	sidecars, err := auxContainersToProto("sidecars", c.Sidecars)
+	if err != nil {
+		return nil, err
 	}
-	if c.ImageSpec.BasicAuth != nil {
-		imageSpec.Auth = &pb.ImageSpec_BasicAuth{
-			BasicAuth: &pb.BasicAuth{
-				Username: c.ImageSpec.BasicAuth.Username,
-				Password: c.ImageSpec.BasicAuth.Password,
-			},
+	initContainers, err := auxContainersToProto("initContainers", c.InitContainers)
+	if err != nil {
+		return nil, err
+	}
+	postExitHooks, err := hooksToProto(c.PostExitHooks)
+	if err != nil {
+		return nil, err
+	}
+
+	var secretEnv map[string]*pb.SecretRef
+	for name, ref := range c.SecretEnv {
+		if ref.Name == "" {
+			return nil, fmt.Errorf("config.secretEnv.%s.name is required", name)
+		}
+		if secretEnv == nil {
+			secretEnv = make(map[string]*pb.SecretRef, len(c.SecretEnv))
 		}
+		secretEnv[name] = &pb.SecretRef{Name: ref.Name, Key: ref.Key}
 	}
 
-	var resources *pb.ResourceLimits
-	if c.Resources != nil {
-		resources = &pb.ResourceLimits{
-			CpuLimit:    c.Resources.CPULimit,
-			MemoryLimit: c.Resources.MemoryLimit,
+	var webhooks []*pb.Webhook
+	for i, hook := range c.Webhooks {
+		if hook.URL == "" {
+			return nil, fmt.Errorf("config.webhooks[%d].url is required", i)
 		}
+		var secret *pb.SecretRef
+		if hook.Secret != nil {
+			if hook.Secret.Name == "" {
+				return nil, fmt.Errorf("config.webhooks[%d].secret.name is required", i)
+			}
+			secret = &pb.SecretRef{Name: hook.Secret.Name, Key: hook.Secret.Key}
+		}
+		webhooks = append(webhooks, &pb.Webhook{Url: hook.URL, Secret: secret, Events: hook.Events})
 	}
 
 	var network *pb.NetworkConfig
 	if c.Network != nil {
-		rules := make([]*pb.NetworkRule, 0, len(c.Network.Rules))
-		for _, rule := range c.Network.Rules {
-			rules = append(rules, &pb.NetworkRule{
-				Action:         rule.Action,
-				Protocol:       rule.Protocol,
-				Destination:    rule.Destination,
-				PortRangeStart: rule.PortRangeStart,
-				PortRangeEnd:   rule.PortRangeEnd,
-			})
+		network = c.Network.toProto()
+	}
+
+	ports := make([]*pb.PortMapping, 0, len(c.Ports))
+	for _, port := range c.Ports {
+		ports = append(ports, &pb.PortMapping{
+			ContainerPort: port.ContainerPort,
+			HostPort:      port.HostPort,
+			Protocol:      port.Protocol,
+		})
+	}
+
+	var workspace *pb.Workspace
+	if c.Workspace != nil {
+		workspace = &pb.Workspace{
+			Archive: c.Workspace.Archive,
+			Url:     c.Workspace.URL,
+			Path:    c.Workspace.Path,
+			Mount:   c.Workspace.Mount,
+		}
+	}
+
+	var readinessProbe *pb.ReadinessProbe
+	if p := c.ReadinessProbe; p != nil {
+		readinessProbe = &pb.ReadinessProbe{
+			Port:             p.Port,
+			HttpPath:         p.HTTPPath,
+			Command:          p.Command,
+			InitialDelaySecs: p.InitialDelaySecs,
+			PeriodSecs:       p.PeriodSecs,
+			TimeoutSecs:      p.TimeoutSecs,
 		}
-		network = &pb.NetworkConfig{
-			Rules:         rules,
-			DefaultPolicy: c.Network.DefaultPolicy,
-			DnsServers:    c.Network.DNSServers,
+	}
+
+	var runtimeOptions *pb.RuntimeOptions
+	if o := c.RuntimeOptions; o != nil {
+		runtimeOptions = &pb.RuntimeOptions{
+			Platform: o.Platform,
+			Network:  o.Network,
+			Directfs: o.DirectFS,
+		}
+	}
+
+	var idleAction *pb.IdleAction
+	if c.IdleAction != nil {
+		switch *c.IdleAction {
+		case "terminate":
+			idleAction = pb.IdleAction_TERMINATE.Enum()
+		case "pause":
+			idleAction = pb.IdleAction_PAUSE.Enum()
+		default:
+			return nil, fmt.Errorf("config.idleAction must be terminate or pause")
 		}
 	}
 
 	return &pb.ContainerConfig{
-		ImageSpec:   imageSpec,
-		Command:     c.Command,
-		Args:        c.Args,
-		Workdir:     c.Workdir,
-		Env:         c.Env,
-		Resources:   resources,
-		Network:     network,
-		TimeoutSecs: c.TimeoutSecs,
-		Cleanup:     &cleanup,
+		ImageSpec:            c.ImageSpec.toProto(),
+		Command:              c.Command,
+		Args:                 c.Args,
+		Workdir:              c.Workdir,
+		Env:                  c.Env,
+		Resources:            c.Resources.toProto(),
+		Network:              network,
+		TimeoutSecs:          c.TimeoutSecs,
+		Cleanup:              &cleanup,
+		Ports:                ports,
+		Workspace:            workspace,
+		Tty:                  c.Tty,
+		RestoreFrom:          c.RestoreFrom,
+		StatsIntervalSecs:    c.StatsIntervalSecs,
+		ReadinessProbe:       readinessProbe,
+		IdleTimeoutSecs:      c.IdleTimeoutSecs,
+		IdleAction:           idleAction,
+		CpuTimeLimitSecs:     c.CPUTimeLimitSecs,
+		Runtime:              c.Runtime,
+		RuntimeOptions:       runtimeOptions,
+		Sidecars:             sidecars,
+		InitContainers:       initContainers,
+		PostExitHooks:        postExitHooks,
+		Labels:               c.Labels,
+		SecretEnv:            secretEnv,
+		QuietOutput:          c.QuietOutput,
+		Detached:             c.Detached,
+		DisconnectGraceSecs:  c.DisconnectGraceSecs,
+		HeartbeatTimeoutSecs: c.HeartbeatTimeoutSecs,
+		Webhooks:             webhooks,
 	}, nil
 }
 
+func auxContainersToProto(field string, containers []AuxContainer) ([]*pb.AuxContainer, error) {
+	var result []*pb.AuxContainer
+	for i, aux := range containers {
+		if aux.Name == "" || aux.ImageSpec.Image == "" {
+			return nil, fmt.Errorf("config.%s[%d] requires a name and imageSpec.image", field, i)
+		}
+		result = append(result, &pb.AuxContainer{
+			Name:      aux.Name,
+			ImageSpec: aux.ImageSpec.toProto(),
+			Command:   aux.Command,
+			Args:      aux.Args,
+			Env:       aux.Env,
+			Resources: aux.Resources.toProto(),
+		})
+	}
+	return result, nil
+}
+
+func hooksToProto(hooks []PostExitHook) ([]*pb.PostExitHook, error) {
+	var result []*pb.PostExitHook
+	for i, hook := range hooks {
+		if hook.Name == "" || (hook.Container != nil) == (hook.WebhookURL != nil) {
+			return nil, fmt.Errorf("config.postExitHooks[%d] requires a name and exactly one of container or webhookUrl", i)
+		}
+		var container *pb.AuxContainer
+		if hook.Container != nil {
+			if hook.Container.ImageSpec.Image == "" {
+				return nil, fmt.Errorf("config.postExitHooks[%d].container requires imageSpec.image", i)
+			}
+			container = &pb.AuxContainer{
+				Name:      hook.Name,
+				ImageSpec: hook.Container.ImageSpec.toProto(),
+				Command:   hook.Container.Command,
+				Args:      hook.Container.Args,
+				Env:       hook.Container.Env,
+				Resources: hook.Container.Resources.toProto(),
+			}
+		}
+		result = append(result, &pb.PostExitHook{
+			Name:        hook.Name,
+			Container:   container,
+			WebhookUrl:  hook.WebhookURL,
+			TimeoutSecs: hook.TimeoutSecs,
+		})
+	}
+	return result, nil
+}
+
+// setOutput adds workload output to message. Output that is not valid UTF-8
+// would be mangled as a JSON string, so then every field is base64-encoded and
+// the message carries "encoding": "base64".
+func setOutput(message map[string]any, output map[string][]byte) {
+	binary := false
+	for _, data := range output {
+		if !utf8.Valid(data) {
+			binary = true
+		}
+	}
+
+	for key, data := range output {
+		if binary {
+			message[key] = base64.StdEncoding.EncodeToString(data)
+		} else {
+			message[key] = string(data)
+		}
+	}
+	if binary {
+		message["encoding"] = "base64"
+	}
+}
+
 func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -164,7 +532,7 @@ func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := s.client.Health(ctx, &pb.HealthRequest{})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), httpstatus.FromError(err))
 		return
 	}
 
@@ -196,6 +564,12 @@ func (s *Server) HandleRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	streams, err := parseStreams(first.Create.Streams)
+	if err != nil {
+		_ = conn.WriteJSON(map[string]any{"type": "error", "error": err.Error()})
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -210,6 +584,7 @@ func (s *Server) HandleRun(w http.ResponseWriter, r *http.Request) {
 			Create: &pb.CreateContainer{
 				ContainerId: first.Create.ContainerID,
 				Config:      config,
+				Streams:     streams,
 			},
 		},
 	}); err != nil {
@@ -232,8 +607,20 @@ func (s *Server) HandleRun(w http.ResponseWriter, r *http.Request) {
 				if msg.Stdin == nil {
 					continue
 				}
+				data := []byte(*msg.Stdin)
+				if msg.Encoding != nil && *msg.Encoding == "base64" {
+					decoded, decodeErr := base64.StdEncoding.DecodeString(*msg.Stdin)
+					if decodeErr != nil {
+						_ = conn.WriteJSON(map[string]any{
+							"type":  "error",
+							"error": "invalid base64 stdin: " + decodeErr.Error(),
+						})
+						continue
+					}
+					data = decoded
+				}
 				if err := stream.Send(&pb.RunRequest{
-					Request: &pb.RunRequest_Stdin{Stdin: []byte(*msg.Stdin)},
+					Request: &pb.RunRequest_Stdin{Stdin: data},
 				}); err != nil {
 					errCh <- err
 					return
@@ -245,6 +632,35 @@ func (s *Server) HandleRun(w http.ResponseWriter, r *http.Request) {
 					errCh <- err
 					return
 				}
+			case "signal":
+				if msg.Signal == nil {
+					continue
+				}
+				if err := stream.Send(&pb.RunRequest{
+					Request: &pb.RunRequest_Signal{Signal: &pb.Signal{Name: *msg.Signal}},
+				}); err != nil {
+					errCh <- err
+					return
+				}
+			case "pause", "unpause":
+				req := &pb.RunRequest{Request: &pb.RunRequest_Pause{Pause: true}}
+				if msg.Type == "unpause" {
+					req = &pb.RunRequest{Request: &pb.RunRequest_Unpause{Unpause: true}}
+				}
+				if err := stream.Send(req); err != nil {
+					errCh <- err
+					return
+				}
+			case "resize":
+				if msg.Rows == nil || msg.Cols == nil {
+					continue
+				}
+				if err := stream.Send(&pb.RunRequest{
+					Request: &pb.RunRequest_Resize{Resize: &pb.Resize{Rows: *msg.Rows, Cols: *msg.Cols}},
+				}); err != nil {
+					errCh <- err
+					return
+				}
 			case "terminate":
 				force := false
 				if msg.Force != nil {
@@ -272,6 +688,48 @@ func (s *Server) HandleRun(w http.ResponseWriter, r *http.Request) {
 					errCh <- err
 					return
 				}
+			case "update_network_policy":
+				if msg.Network == nil {
+					continue
+				}
+				if err := stream.Send(&pb.RunRequest{
+					Request: &pb.RunRequest_UpdateNetworkPolicy{
+						UpdateNetworkPolicy: &pb.UpdateNetworkPolicy{Network: msg.Network.toProto()},
+					},
+				}); err != nil {
+					errCh <- err
+					return
+				}
+			case "update_resources":
+				if msg.Resources == nil {
+					continue
+				}
+				if err := stream.Send(&pb.RunRequest{
+					Request: &pb.RunRequest_UpdateResources{
+						UpdateResources: &pb.UpdateResources{Resources: msg.Resources.toProto()},
+					},
+				}); err != nil {
+					errCh <- err
+					return
+				}
+			case "exec":
+				if msg.Exec == nil {
+					continue
+				}
+				if err := stream.Send(&pb.RunRequest{
+					Request: &pb.RunRequest_Exec{
+						Exec: &pb.ExecRequest{
+							ExecId:      msg.Exec.ExecID,
+							Command:     msg.Exec.Command,
+							Env:         msg.Exec.Env,
+							Workdir:     msg.Exec.Workdir,
+							TimeoutSecs: msg.Exec.TimeoutSecs,
+						},
+					},
+				}); err != nil {
+					errCh <- err
+					return
+				}
 			}
 		}
 	}()
@@ -284,6 +742,11 @@ func (s *Server) HandleRun(w http.ResponseWriter, r *http.Request) {
 					errCh <- nil
 					return
 				}
+				_ = conn.WriteJSON(map[string]any{
+					"type":   "error",
+					"error":  status.Convert(err).Message(),
+					"status": httpstatus.FromError(err),
+				})
 				errCh <- err
 				return
 			}
@@ -291,20 +754,19 @@ func (s *Server) HandleRun(w http.ResponseWriter, r *http.Request) {
 			switch event := resp.Event.(type) {
 			case *pb.RunResponse_Created:
 				err = conn.WriteJSON(map[string]any{
-					"type":        "created",
-					"containerId": resp.ContainerId,
-					"state":       event.Created.State.String(),
+					"type":                 "created",
+					"containerId":          resp.ContainerId,
+					"state":                event.Created.State.String(),
+					"heartbeatTimeoutSecs": event.Created.HeartbeatTimeoutSecs,
 				})
 			case *pb.RunResponse_Stdout:
-				err = conn.WriteJSON(map[string]any{
-					"type": "stdout",
-					"data": string(event.Stdout),
-				})
+				message := map[string]any{"type": "stdout"}
+				setOutput(message, map[string][]byte{"data": event.Stdout})
+				err = conn.WriteJSON(message)
 			case *pb.RunResponse_Stderr:
-				err = conn.WriteJSON(map[string]any{
-					"type": "stderr",
-					"data": string(event.Stderr),
-				})
+				message := map[string]any{"type": "stderr"}
+				setOutput(message, map[string][]byte{"data": event.Stderr})
+				err = conn.WriteJSON(message)
 			case *pb.RunResponse_Message:
 				var message any
 				if unmarshalErr := json.Unmarshal([]byte(event.Message), &message); unmarshalErr != nil {
@@ -314,17 +776,53 @@ func (s *Server) HandleRun(w http.ResponseWriter, r *http.Request) {
 					"type": "message",
 					"data": message,
 				})
+			case *pb.RunResponse_Capture:
+				message := map[string]any{
+					"type":      "capture",
+					"captureId": event.Capture.CaptureId,
+					"data":      event.Capture.Data,
+					"done":      event.Capture.Done,
+				}
+				if event.Capture.Error != nil {
+					message["error"] = *event.Capture.Error
+				}
+				err = conn.WriteJSON(message)
+			case *pb.RunResponse_Exec:
+				message := map[string]any{
+					"type":   "exec",
+					"execId": event.Exec.ExecId,
+				}
+				output := map[string][]byte{}
+				if len(event.Exec.Stdout) > 0 {
+					output["stdout"] = event.Exec.Stdout
+				}
+				if len(event.Exec.Stderr) > 0 {
+					output["stderr"] = event.Exec.Stderr
+				}
+				setOutput(message, output)
+				if event.Exec.Exited {
+					message["exited"] = true
+					message["exitCode"] = event.Exec.ExitCode
+				}
+				if event.Exec.Error != nil {
+					message["error"] = *event.Exec.Error
+				}
+				err = conn.WriteJSON(message)
 			case *pb.RunResponse_Error:
 				err = conn.WriteJSON(map[string]any{
 					"type":  "error",
 					"error": event.Error,
 				})
 			case *pb.RunResponse_Exit:
-				err = conn.WriteJSON(map[string]any{
+				message := map[string]any{
 					"type":      "exit",
 					"exitCode":  event.Exit.ExitCode,
 					"timestamp": event.Exit.Timestamp,
-				})
+				}
+				if event.Exit.TerminationReason != nil {
+					message["terminationReason"] = *event.Exit.TerminationReason
+				}
+				err = conn.WriteJSON(message)
 				if err == nil {
 					errCh <- nil
 					return
@@ -0,0 +1,50 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/httpstatus"
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/grpc/status"
+)
+
+// extendTimeoutRequest is the body of a timeout extension
+type extendTimeoutRequest struct {
+	ExtendSecs uint32 `json:"extendSecs"`
+}
+
+// HandleExtendTimeout pushes back the run timeout of a running container, up
+// to the extension the operator allows
+func (s *Server) HandleExtendTimeout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req extendTimeoutRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.client.ExtendTimeout(r.Context(), &pb.ExtendTimeoutRequest{
+		ContainerId: r.PathValue("id"),
+		ExtendSecs:  req.ExtendSecs,
+	})
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpstatus.FromError(err))
+		return
+	}
+	if !resp.Success {
+		writeResult(w, resp.Success, resp.Error)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success":            true,
+		"runDeadline":        resp.RunDeadline,
+		"totalExtensionSecs": resp.TotalExtensionSecs,
+	})
+}
@@ -0,0 +1,246 @@
+package resources
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DefaultDockerDataRoot is where Docker keeps images, containers and volumes
+// unless its daemon is configured with another data-root
+const DefaultDockerDataRoot = "/var/lib/docker"
+
+// Memory is the physical memory of this node, in bytes
+type Memory struct {
+	Total     uint64
+	Available uint64
+	Used      uint64
+}
+
+// Disk is the capacity of a filesystem, in bytes. Available is what
+// unprivileged users may still write, so Used+Available falls short of Total
+// by the blocks reserved for root.
+type Disk struct {
+	Total     uint64
+	Available uint64
+	Used      uint64
+}
+
+// CPUSample is the cumulative CPU time of this node from /proc/stat, in
+// clock ticks
+type CPUSample struct {
+	Busy  uint64
+	Total uint64
+}
+
+// HostMemory returns total, available and used physical memory from
+// /proc/meminfo. Available is the kernel's estimate of what can be allocated
+// without swapping, so reclaimable page cache is not counted as used.
+func HostMemory() (Memory, error) {
+	return hostMemory("/proc/meminfo")
+}
+
+func hostMemory(path string) (Memory, error) {
+	info, err := readMeminfo(path)
+	if err != nil {
+		return Memory{}, err
+	}
+
+	total, ok := info["MemTotal"]
+	if !ok {
+		return Memory{}, fmt.Errorf("MemTotal not found in %s", path)
+	}
+	available, ok := info["MemAvailable"]
+	if !ok {
+		// Kernels before 3.14 have no estimate; free memory is the floor
+		available = info["MemFree"] + info["Buffers"] + info["Cached"]
+	}
+	available = min(available, total)
+
+	return Memory{Total: total, Available: available, Used: total - available}, nil
+}
+
+// DiskUsage returns the capacity of the filesystem holding path. A path that
+// does not exist yet is measured on the filesystem it would be created on.
+func DiskUsage(path string) (Disk, error) {
+	path = filepath.Clean(path)
+	for {
+		var st syscall.Statfs_t
+		err := syscall.Statfs(path, &st)
+		if err == nil {
+			blockSize := uint64(st.Bsize)
+			total := st.Blocks * blockSize
+			free := st.Bfree * blockSize
+			return Disk{
+				Total:     total,
+				Available: st.Bavail * blockSize,
+				Used:      total - free,
+			}, nil
+		}
+
+		parent := filepath.Dir(path)
+		if !errors.Is(err, syscall.ENOENT) || parent == path {
+			return Disk{}, fmt.Errorf("failed to stat filesystem of %s: %w", path, err)
+		}
+		path = parent
+	}
+}
+
+// LoadAverage returns the 1, 5 and 15 minute load averages from /proc/loadavg
+func LoadAverage() ([3]float64, error) {
+	return loadAverage("/proc/loadavg")
+}
+
+func loadAverage(path string) ([3]float64, error) {
+	var load [3]float64
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return load, fmt.Errorf("failed to read load average: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < len(load) {
+		return load, fmt.Errorf("malformed %s", path)
+	}
+	for i := range load {
+		if load[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return load, fmt.Errorf("malformed %s: %w", path, err)
+		}
+	}
+	return load, nil
+}
+
+// HostCPUSample reads the cumulative CPU time of this node from /proc/stat
+func HostCPUSample() (CPUSample, error) {
+	return cpuSample("/proc/stat")
+}
+
+func cpuSample(path string) (CPUSample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return CPUSample{}, fmt.Errorf("failed to read CPU times: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+
+		// user nice system idle iowait irq softirq steal; guest time is
+		// already counted in user and nice
+		var sample CPUSample
+		for i, field := range fields[1:min(len(fields), 9)] {
+			ticks, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return CPUSample{}, fmt.Errorf("malformed %s: %w", path, err)
+			}
+			sample.Total += ticks
+			if i != 3 && i != 4 { // idle, iowait
+				sample.Busy += ticks
+			}
+		}
+		return sample, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return CPUSample{}, fmt.Errorf("failed to read CPU times: %w", err)
+	}
+	return CPUSample{}, fmt.Errorf("cpu line not found in %s", path)
+}
+
+// UsagePercent is the share of CPU time spent busy between prev and s, as a
+// percentage of all CPUs
+func (s CPUSample) UsagePercent(prev CPUSample) float64 {
+	if s.Total <= prev.Total || s.Busy < prev.Busy {
+		return 0
+	}
+	return float64(s.Busy-prev.Busy) / float64(s.Total-prev.Total) * 100
+}
+
+// containerCgroups are where Docker places container cgroups under the
+// cgroup root, for the systemd and cgroupfs drivers on cgroup v2 and v1
+var containerCgroups = []struct {
+	pattern string
+	usage   string
+	cache   string
+}{
+	{"system.slice/docker-*.scope", "memory.current", "inactive_file"},
+	{"docker/*", "memory.current", "inactive_file"},
+	{"memory/system.slice/docker-*.scope", "memory.usage_in_bytes", "total_inactive_file"},
+	{"memory/docker/*", "memory.usage_in_bytes", "total_inactive_file"},
+}
+
+// ContainerMemoryBytes sums the memory charged to the cgroups of Docker
+// containers on this node, less their inactive page cache, the way `docker
+// stats` reports it
+func ContainerMemoryBytes() (uint64, error) {
+	return containerMemory("/sys/fs/cgroup")
+}
+
+func containerMemory(root string) (uint64, error) {
+	var total uint64
+	for _, layout := range containerCgroups {
+		dirs, err := filepath.Glob(filepath.Join(root, layout.pattern))
+		if err != nil {
+			return 0, err
+		}
+
+		for _, dir := range dirs {
+			usage, err := readCgroupValue(filepath.Join(dir, layout.usage))
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+				// Not a container cgroup, or the container just went away
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+
+			stat, err := readCgroupStat(filepath.Join(dir, "memory.stat"))
+			if err == nil && stat[layout.cache] < usage {
+				usage -= stat[layout.cache]
+			}
+			total += usage
+		}
+	}
+	return total, nil
+}
+
+func readCgroupValue(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed %s: %w", path, err)
+	}
+	return value, nil
+}
+
+// readCgroupStat parses a flat-keyed cgroup file such as memory.stat
+func readCgroupStat(path string) (map[string]uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stat := make(map[string]uint64)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err == nil {
+			stat[key] = v
+		}
+	}
+	return stat, nil
+}
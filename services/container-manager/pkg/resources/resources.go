@@ -0,0 +1,124 @@
+package resources
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	minMemory = 4 * 1024 * 1024
+	maxMemory = 128 * 1024 * 1024 * 1024
+	minCPU    = 0.01
+	maxCPU    = 256.0
+)
+
+// ParseMemoryLimit converts a limit such as "512m" or "1g" to bytes.
+// Accepts the same syntax and bounds as the isolation-runner.
+func ParseMemoryLimit(limit string) (int64, error) {
+	limit = strings.TrimSpace(strings.ToLower(limit))
+
+	multiplier := int64(1)
+	if strings.HasSuffix(limit, "k") {
+		multiplier = 1024
+		limit = limit[:len(limit)-1]
+	} else if strings.HasSuffix(limit, "m") {
+		multiplier = 1024 * 1024
+		limit = limit[:len(limit)-1]
+	} else if strings.HasSuffix(limit, "g") {
+		multiplier = 1024 * 1024 * 1024
+		limit = limit[:len(limit)-1]
+	}
+
+	value, err := strconv.ParseInt(limit, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit: %s", limit)
+	}
+
+	bytes := value * multiplier
+	if bytes < minMemory {
+		return 0, fmt.Errorf("memory limit too low: %d bytes (minimum: 4MB)", bytes)
+	}
+	if bytes > maxMemory {
+		return 0, fmt.Errorf("memory limit too high: %d bytes (maximum: 128GB)", bytes)
+	}
+
+	return bytes, nil
+}
+
+// ParseCPULimit converts a limit such as "1.5" to a number of CPUs.
+func ParseCPULimit(limit string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(limit), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid CPU limit: %s", limit)
+	}
+
+	if value < minCPU {
+		return 0, fmt.Errorf("CPU limit too low: %.2f (minimum: 0.01)", value)
+	}
+	if value > maxCPU {
+		return 0, fmt.Errorf("CPU limit too high: %.2f (maximum: 256)", value)
+	}
+
+	return value, nil
+}
+
+// HostCPUCores returns the number of CPUs available on this node
+func HostCPUCores() float64 {
+	return float64(runtime.NumCPU())
+}
+
+// HostMemoryBytes returns total physical memory from /proc/meminfo
+func HostMemoryBytes() (uint64, error) {
+	info, err := readMeminfo("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+
+	total, ok := info["MemTotal"]
+	if !ok {
+		return 0, fmt.Errorf("MemTotal not found in /proc/meminfo")
+	}
+	return total, nil
+}
+
+// readMeminfo parses a meminfo-formatted file into byte values keyed by field name
+func readMeminfo(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+	defer f.Close()
+
+	info := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+
+		value, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			value *= 1024
+		}
+		info[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,183 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMemoryLimit(t *testing.T) {
+	tests := []struct {
+		limit   string
+		want    int64
+		wantErr bool
+	}{
+		{"512m", 512 * 1024 * 1024, false},
+		{"1g", 1024 * 1024 * 1024, false},
+		{"8192k", 8192 * 1024, false},
+		{" 2G ", 2 * 1024 * 1024 * 1024, false},
+		{"1k", 0, true},
+		{"256g", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.limit, func(t *testing.T) {
+			got, err := ParseMemoryLimit(tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMemoryLimit(%q) error = %v, wantErr %v", tt.limit, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMemoryLimit(%q) = %d, want %d", tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCPULimit(t *testing.T) {
+	tests := []struct {
+		limit   string
+		want    float64
+		wantErr bool
+	}{
+		{"1.0", 1.0, false},
+		{"0.5", 0.5, false},
+		{"0.001", 0, true},
+		{"512", 0, true},
+		{"two", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.limit, func(t *testing.T) {
+			got, err := ParseCPULimit(tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCPULimit(%q) error = %v, wantErr %v", tt.limit, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCPULimit(%q) = %f, want %f", tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMeminfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meminfo")
+	content := "MemTotal:       16384000 kB\nMemFree:         1024000 kB\nHugePages_Total:       0\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := readMeminfo(path)
+	if err != nil {
+		t.Fatalf("readMeminfo() error = %v", err)
+	}
+
+	if info["MemTotal"] != 16384000*1024 {
+		t.Errorf("MemTotal = %d, want %d", info["MemTotal"], 16384000*1024)
+	}
+	if info["MemFree"] != 1024000*1024 {
+		t.Errorf("MemFree = %d, want %d", info["MemFree"], 1024000*1024)
+	}
+	if info["HugePages_Total"] != 0 {
+		t.Errorf("HugePages_Total = %d, want 0", info["HugePages_Total"])
+	}
+}
+
+func TestHostMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meminfo")
+	content := "MemTotal:       16384000 kB\nMemFree:         1024000 kB\nMemAvailable:    4096000 kB\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mem, err := hostMemory(path)
+	if err != nil {
+		t.Fatalf("hostMemory() error = %v", err)
+	}
+	if mem.Total != 16384000*1024 || mem.Available != 4096000*1024 {
+		t.Errorf("hostMemory() = %+v, want total %d available %d", mem, 16384000*1024, 4096000*1024)
+	}
+	if mem.Used != mem.Total-mem.Available {
+		t.Errorf("Used = %d, want %d", mem.Used, mem.Total-mem.Available)
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	// A data-root that does not exist yet is measured where it would live
+	disk, err := DiskUsage(filepath.Join(t.TempDir(), "docker", "missing"))
+	if err != nil {
+		t.Fatalf("DiskUsage() error = %v", err)
+	}
+	if disk.Total == 0 || disk.Used > disk.Total || disk.Available > disk.Total {
+		t.Errorf("DiskUsage() = %+v", disk)
+	}
+}
+
+func TestLoadAverage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loadavg")
+	if err := os.WriteFile(path, []byte("0.52 1.25 2.00 3/512 12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	load, err := loadAverage(path)
+	if err != nil {
+		t.Fatalf("loadAverage() error = %v", err)
+	}
+	if load != [3]float64{0.52, 1.25, 2.00} {
+		t.Errorf("loadAverage() = %v", load)
+	}
+}
+
+func TestCPUSample(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stat")
+	content := "cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 100 0 50 800 50 0 0 0 0 0\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sample, err := cpuSample(path)
+	if err != nil {
+		t.Fatalf("cpuSample() error = %v", err)
+	}
+	if sample != (CPUSample{Busy: 150, Total: 1000}) {
+		t.Errorf("cpuSample() = %+v", sample)
+	}
+
+	next := CPUSample{Busy: 200, Total: 1100}
+	if got := next.UsagePercent(sample); got != 50 {
+		t.Errorf("UsagePercent() = %f, want 50", got)
+	}
+	if got := sample.UsagePercent(sample); got != 0 {
+		t.Errorf("UsagePercent() with no time passed = %f, want 0", got)
+	}
+}
+
+func TestContainerMemory(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// systemd driver, cgroup v2
+	write("system.slice/docker-aaa.scope/memory.current", "1000\n")
+	write("system.slice/docker-aaa.scope/memory.stat", "anon 800\ninactive_file 200\n")
+	// cgroupfs driver, cgroup v2
+	write("docker/bbb/memory.current", "500\n")
+	// Not containers
+	write("docker/cgroup.procs", "")
+	write("system.slice/sshd.service/memory.current", "9999\n")
+
+	got, err := containerMemory(root)
+	if err != nil {
+		t.Fatalf("containerMemory() error = %v", err)
+	}
+	if got != 1300 {
+		t.Errorf("containerMemory() = %d, want 1300", got)
+	}
+}
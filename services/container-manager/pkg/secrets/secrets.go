@@ -0,0 +1,217 @@
+// Package secrets resolves the secret references in a container's config to
+// their values, so a secret's plaintext only ever travels from the operator's
+// secrets backend to the isolation-runner and never through the API.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for a reference to a secret the backend does not have
+	ErrNotFound = errors.New("secret not found")
+	// ErrInvalidReference is returned for a reference the backend cannot look up
+	ErrInvalidReference = errors.New("invalid secret reference")
+)
+
+// lookupTimeout bounds one lookup against a remote backend
+const lookupTimeout = 10 * time.Second
+
+// maxSecretBytes bounds a secret's value, matching the isolation-runner's
+// limit on an environment variable
+const maxSecretBytes = 64 << 10
+
+// Backend looks up a secret by name and, for backends that store several
+// values under one name, key
+type Backend interface {
+	Lookup(ctx context.Context, name, key string) (string, error)
+}
+
+// FromEnv returns the backend the operator configured in
+// HOLOPOD_SECRETS_BACKEND ("file", "env" or "vault"), or nil when unset
+func FromEnv() (Backend, error) {
+	switch backend := os.Getenv("HOLOPOD_SECRETS_BACKEND"); backend {
+	case "":
+		return nil, nil
+	case "file":
+		dir := os.Getenv("HOLOPOD_SECRETS_DIR")
+		if dir == "" {
+			return nil, fmt.Errorf("HOLOPOD_SECRETS_DIR is required for the file secrets backend")
+		}
+		return &FileBackend{Dir: dir}, nil
+	case "env":
+		prefix := os.Getenv("HOLOPOD_SECRETS_ENV_PREFIX")
+		if prefix == "" {
+			prefix = "HOLOPOD_SECRET_"
+		}
+		return &EnvBackend{Prefix: prefix}, nil
+	case "vault":
+		address, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+		if address == "" || token == "" {
+			return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required for the vault secrets backend")
+		}
+		mount := os.Getenv("HOLOPOD_VAULT_MOUNT")
+		if mount == "" {
+			mount = "secret"
+		}
+		return &VaultBackend{Address: address, Token: token, Mount: mount}, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %q", backend)
+	}
+}
+
+// Resolve looks up each environment variable's secret
+func Resolve(ctx context.Context, backend Backend, refs map[string]Ref) (map[string]string, error) {
+	values := make(map[string]string, len(refs))
+	for name, ref := range refs {
+		value, err := backend.Lookup(ctx, ref.Name, ref.Key)
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", name, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// Ref names a secret in the backend
+type Ref struct {
+	Name string
+	Key  string
+}
+
+// validName rejects names that could escape the backend's namespace
+func validName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\x00") {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// FileBackend reads each secret from a file named after it under Dir, such as
+// a mounted Kubernetes secret. A single trailing newline is dropped.
+type FileBackend struct {
+	Dir string
+}
+
+func (b *FileBackend) Lookup(ctx context.Context, name, key string) (string, error) {
+	if !validName(name) || key != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidReference, name)
+	}
+
+	file, err := os.Open(filepath.Join(b.Dir, filepath.FromSlash(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %q: %w", name, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSecretBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %q: %w", name, err)
+	}
+	if len(data) > maxSecretBytes {
+		return "", fmt.Errorf("secret %q exceeds %d bytes", name, maxSecretBytes)
+	}
+
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// EnvBackend reads each secret from the manager's own environment variable
+// named Prefix followed by the secret's name. The prefix keeps the rest of the
+// manager's environment out of reach.
+type EnvBackend struct {
+	Prefix string
+}
+
+func (b *EnvBackend) Lookup(ctx context.Context, name, key string) (string, error) {
+	if !validName(name) || strings.Contains(name, "/") || key != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidReference, name)
+	}
+
+	value, ok := os.LookupEnv(b.Prefix + name)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// VaultBackend reads each secret from a Vault KV version 2 engine mounted at
+// Mount. The name is the secret's path and the key the field within it,
+// "value" by default.
+type VaultBackend struct {
+	Address string
+	Token   string
+	Mount   string
+	Client  *http.Client
+}
+
+func (b *VaultBackend) Lookup(ctx context.Context, name, key string) (string, error) {
+	if !validName(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidReference, name)
+	}
+	if key == "" {
+		key = "value"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	endpoint, err := url.JoinPath(b.Address, "v1", b.Mount, "data", name)
+	if err != nil {
+		return "", fmt.Errorf("invalid vault address: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", b.Token)
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %q", resp.Status, name)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4*maxSecretBytes)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid vault response for %q: %w", name, err)
+	}
+
+	value, ok := body.Data.Data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %q has no string key %q", ErrNotFound, name, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,131 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileBackend(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "db"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "db", "password"), []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	backend := &FileBackend{Dir: dir}
+
+	value, err := backend.Lookup(context.Background(), "db/password", "")
+	if err != nil || value != "hunter2" {
+		t.Errorf("Lookup() = %q, %v, want hunter2", value, err)
+	}
+
+	if _, err := backend.Lookup(context.Background(), "missing", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	for _, name := range []string{"../etc/passwd", "/etc/passwd", "db/../db/password", ""} {
+		if _, err := backend.Lookup(context.Background(), name, ""); !errors.Is(err, ErrInvalidReference) {
+			t.Errorf("Lookup(%q): expected ErrInvalidReference, got %v", name, err)
+		}
+	}
+}
+
+func TestEnvBackend(t *testing.T) {
+	t.Setenv("HOLOPOD_SECRET_API_KEY", "abc")
+	t.Setenv("UNPREFIXED", "xyz")
+	backend := &EnvBackend{Prefix: "HOLOPOD_SECRET_"}
+
+	value, err := backend.Lookup(context.Background(), "API_KEY", "")
+	if err != nil || value != "abc" {
+		t.Errorf("Lookup() = %q, %v, want abc", value, err)
+	}
+	if _, err := backend.Lookup(context.Background(), "UNPREFIXED", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the prefix to hide other variables, got %v", err)
+	}
+}
+
+func TestVaultBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/app/db" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"value":"v","password":"p"}}}`))
+	}))
+	defer server.Close()
+
+	backend := &VaultBackend{Address: server.URL, Token: "token", Mount: "kv"}
+
+	tests := []struct {
+		name, key, want string
+		wantErr         error
+	}{
+		{"app/db", "", "v", nil},
+		{"app/db", "password", "p", nil},
+		{"app/db", "missing", "", ErrNotFound},
+		{"app/other", "", "", ErrNotFound},
+		{"../sys/seal", "", "", ErrInvalidReference},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"#"+tt.key, func(t *testing.T) {
+			value, err := backend.Lookup(context.Background(), tt.name, tt.key)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || value != tt.want {
+				t.Errorf("Lookup() = %q, %v, want %q", value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("HOLOPOD_SECRETS_BACKEND", "")
+	if backend, err := FromEnv(); backend != nil || err != nil {
+		t.Errorf("expected no backend when unset, got %v, %v", backend, err)
+	}
+
+	t.Setenv("HOLOPOD_SECRETS_BACKEND", "file")
+	t.Setenv("HOLOPOD_SECRETS_DIR", "")
+	if _, err := FromEnv(); err == nil {
+		t.Error("expected an error for the file backend without a directory")
+	}
+
+	t.Setenv("HOLOPOD_SECRETS_BACKEND", "env")
+	t.Setenv("HOLOPOD_SECRETS_ENV_PREFIX", "")
+	backend, err := FromEnv()
+	if env, ok := backend.(*EnvBackend); err != nil || !ok || env.Prefix != "HOLOPOD_SECRET_" {
+		t.Errorf("expected the env backend with the default prefix, got %#v, %v", backend, err)
+	}
+
+	t.Setenv("HOLOPOD_SECRETS_BACKEND", "aws")
+	if _, err := FromEnv(); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("HOLOPOD_SECRET_TOKEN", "t")
+	backend := &EnvBackend{Prefix: "HOLOPOD_SECRET_"}
+
+	values, err := Resolve(context.Background(), backend, map[string]Ref{"API_TOKEN": {Name: "TOKEN"}})
+	if err != nil || values["API_TOKEN"] != "t" {
+		t.Errorf("Resolve() = %v, %v", values, err)
+	}
+
+	if _, err := Resolve(context.Background(), backend, map[string]Ref{"X": {Name: "MISSING"}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
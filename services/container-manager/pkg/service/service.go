@@ -2,15 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os/exec"
-	"runtime"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/container"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/lifecycle"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/logs"
 	"github.com/metorial/fleet/holopod/services/container-manager/pkg/manager"
+	"github.com/metorial/fleet/holopod/services/container-manager/pkg/webhook"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -27,107 +34,289 @@ func New(mgr *manager.Manager) *Service {
 	}
 }
 
+// errorCode maps manager errors to gRPC status codes. Errors that are not
+// one of the manager's sentinel values are treated as internal failures.
+func errorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, manager.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, manager.ErrAlreadyExists):
+		return codes.AlreadyExists
+	case errors.Is(err, manager.ErrLimitReached), errors.Is(err, manager.ErrInsufficientResources):
+		return codes.ResourceExhausted
+	case errors.Is(err, manager.ErrNotRunning):
+		return codes.FailedPrecondition
+	case errors.Is(err, manager.ErrInvalidPath), errors.Is(err, manager.ErrInvalidSignal),
+		errors.Is(err, manager.ErrInvalidCheckpoint), errors.Is(err, manager.ErrRuntimeNotAllowed),
+		errors.Is(err, manager.ErrRuntimeOptionNotAllowed), errors.Is(err, manager.ErrSecretNotFound),
+		errors.Is(err, manager.ErrInvalidSecretRef), errors.Is(err, manager.ErrInvalidQuery),
+		errors.Is(err, manager.ErrInvalidResources):
+		return codes.InvalidArgument
+	case errors.Is(err, manager.ErrSecretsUnavailable), errors.Is(err, manager.ErrNotRestartable),
+		errors.Is(err, manager.ErrNoRunTimeout):
+		return codes.FailedPrecondition
+	case errors.Is(err, manager.ErrExtensionLimit):
+		return codes.ResourceExhausted
+	case errors.Is(err, manager.ErrTransferTooLarge):
+		return codes.ResourceExhausted
+	case errors.Is(err, manager.ErrNotReady):
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// statusError converts a manager error to a status error with message msg.
+// Admission refusals carry a QuotaFailure naming the resource that ran short.
+func statusError(err error, msg string) error {
+	st := status.New(errorCode(err), msg)
+
+	var admission *manager.AdmissionError
+	if errors.As(err, &admission) {
+		if detailed, detailErr := st.WithDetails(&errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{
+				Subject:     admission.Resource,
+				Description: admission.Error(),
+			}},
+		}); detailErr == nil {
+			st = detailed
+		}
+	}
+
+	return st.Err()
+}
+
+// selectedStreams returns the outputs a Run stream asked for; none means all
+func selectedStreams(requested []pb.OutputStream) map[pb.OutputStream]bool {
+	selected := make(map[pb.OutputStream]bool)
+	for _, stream := range requested {
+		selected[stream] = true
+	}
+
+	if len(selected) == 0 || (len(selected) == 1 && selected[pb.OutputStream_OUTPUT_STREAM_UNSPECIFIED]) {
+		return map[pb.OutputStream]bool{
+			pb.OutputStream_STDOUT: true,
+			pb.OutputStream_STDERR: true,
+			pb.OutputStream_EVENTS: true,
+		}
+	}
+
+	return selected
+}
+
+// validateConfig checks the parts of a container config the manager cannot
+// fix up itself
+func validateConfig(config *pb.ContainerConfig) error {
+	if config == nil {
+		return status.Errorf(codes.InvalidArgument, "config is required")
+	}
+
+	if config.ImageSpec == nil {
+		return status.Errorf(codes.InvalidArgument, "image_spec is required")
+	}
+
+	if config.ImageSpec.Image == "" {
+		return status.Errorf(codes.InvalidArgument, "image is required")
+	}
+
+	if ws := config.Workspace; ws != nil && (len(ws.Archive) == 0) == (ws.GetUrl() == "") {
+		return status.Errorf(codes.InvalidArgument, "workspace must set exactly one of archive or url")
+	}
+
+	if probe := config.ReadinessProbe; probe != nil && (len(probe.Command) > 0) == (probe.GetPort() != 0) {
+		return status.Errorf(codes.InvalidArgument, "readiness_probe must set exactly one of command or port")
+	}
+
+	for _, aux := range slices.Concat(config.Sidecars, config.InitContainers) {
+		if aux.Name == "" || aux.ImageSpec.GetImage() == "" {
+			return status.Errorf(codes.InvalidArgument, "sidecars and init containers require a name and an image")
+		}
+	}
+
+	for name, ref := range config.SecretEnv {
+		if ref.GetName() == "" {
+			return status.Errorf(codes.InvalidArgument, "secret_env %s requires a secret name", name)
+		}
+		if _, ok := config.Env[name]; ok {
+			return status.Errorf(codes.InvalidArgument, "%s is set in both env and secret_env", name)
+		}
+	}
+
+	for key := range config.Labels {
+		if key == "" || strings.Contains(key, "=") {
+			return status.Errorf(codes.InvalidArgument, "label keys must be non-empty and cannot contain '='")
+		}
+	}
+
+	for _, hook := range config.Webhooks {
+		if err := webhook.ValidateURL(hook.Url); err != nil {
+			return status.Errorf(codes.InvalidArgument, "webhooks: %v", err)
+		}
+		if hook.Secret != nil && hook.Secret.GetName() == "" {
+			return status.Errorf(codes.InvalidArgument, "webhooks secret requires a secret name")
+		}
+		for _, event := range hook.Events {
+			if !slices.Contains(webhook.Events, event) {
+				return status.Errorf(codes.InvalidArgument, "webhooks events must be of %s", strings.Join(webhook.Events, ", "))
+			}
+		}
+	}
+
+	for _, hook := range config.PostExitHooks {
+		if hook.Name == "" || (hook.Container != nil) == (hook.GetWebhookUrl() != "") {
+			return status.Errorf(codes.InvalidArgument, "post_exit_hooks require a name and exactly one of container or webhook_url")
+		}
+		if hook.Container != nil && hook.Container.ImageSpec.GetImage() == "" {
+			return status.Errorf(codes.InvalidArgument, "post_exit_hooks container requires an image")
+		}
+	}
+
+	return nil
+}
+
 // Run implements the unified bidirectional stream for container lifecycle
 // CRITICAL: Connection close/interrupt automatically terminates container
-// CRITICAL: Client MUST send heartbeat every 30 seconds or container will be terminated
+// CRITICAL: Client MUST send heartbeats within the container's heartbeat timeout or container will be terminated
 func (s *Service) Run(stream pb.ContainerManager_RunServer) error {
 	var containerID string
 	var cleanupDone bool
 
-	// CRITICAL: Ensure container is ALWAYS terminated when stream ends
+	// CRITICAL: Ensure the container is terminated when the stream ends,
+	// unless it was created detached or with a disconnect grace period, or
+	// another stream is attached to it
 	defer func() {
 		if containerID != "" && !cleanupDone {
-			// Force terminate on connection drop
-			_ = s.manager.TerminateContainer(containerID, true, 5)
+			_ = s.manager.Disconnect(containerID)
 			cleanupDone = true
 		}
 	}()
 
-	// First message MUST be create request
+	// First message MUST be create or attach request
 	firstMsg, err := stream.Recv()
 	if err != nil {
 		return status.Errorf(codes.InvalidArgument, "failed to receive initial message: %v", err)
 	}
 
-	createReq := firstMsg.GetCreate()
-	if createReq == nil {
-		return status.Errorf(codes.InvalidArgument, "first message must be CreateContainer request")
-	}
+	var c *container.Container
+	var output *container.OutputReader
+	var requested []pb.OutputStream
+	var typedEvents bool
 
-	// Validate config
-	if createReq.Config == nil {
-		return status.Errorf(codes.InvalidArgument, "config is required")
-	}
+	if createReq := firstMsg.GetCreate(); createReq != nil {
+		if err := validateConfig(createReq.Config); err != nil {
+			return err
+		}
 
-	if createReq.Config.ImageSpec == nil {
-		return status.Errorf(codes.InvalidArgument, "image_spec is required")
-	}
+		// Create and start container, with the provided ID or a generated one
+		id, err := s.manager.CreateContainer(stream.Context(), createReq.GetContainerId(), createReq.Config)
+		if err != nil {
+			return statusError(err, fmt.Sprintf("failed to create container: %v", err))
+		}
+		if c, err = s.manager.Attach(id); err != nil {
+			return status.Errorf(errorCode(err), "failed to attach to container: %v", err)
+		}
+		containerID = id
+		// Unread output is held for this stream alone, and holds up the
+		// workload once its buffer is full, until the client catches up or
+		// goes away
+		output = c.ReadOutput(0)
+		defer output.Close()
+		requested = createReq.Streams
+		typedEvents = createReq.TypedEvents
+
+		// Send created event
+		if err := stream.Send(&pb.RunResponse{
+			ContainerId: containerID,
+			Event: &pb.RunResponse_Created{
+				Created: &pb.ContainerCreated{
+					ContainerId:          containerID,
+					State:                pb.ContainerState_RUNNING,
+					HeartbeatTimeoutSecs: uint32(s.manager.HeartbeatTimeout(c.Config).Seconds()),
+				},
+			},
+		}); err != nil {
+			return err
+		}
+	} else if attachReq := firstMsg.GetAttach(); attachReq != nil {
+		if attachReq.ContainerId == "" {
+			return status.Errorf(codes.InvalidArgument, "container_id is required")
+		}
 
-	if createReq.Config.ImageSpec.Image == "" {
-		return status.Errorf(codes.InvalidArgument, "image is required")
-	}
+		if c, err = s.manager.Attach(attachReq.ContainerId); err != nil {
+			return status.Errorf(errorCode(err), "failed to attach to container: %v", err)
+		}
+		containerID = attachReq.ContainerId
+		output = c.ReadOutput(attachReq.OutputOffset)
+		defer output.Close()
+		requested = attachReq.Streams
+		typedEvents = attachReq.TypedEvents
 
-	// Generate or use provided container ID
-	if createReq.ContainerId != nil {
-		containerID = *createReq.ContainerId
+		if err := stream.Send(&pb.RunResponse{
+			ContainerId: containerID,
+			Event: &pb.RunResponse_Attached{
+				Attached: &pb.ContainerAttached{
+					ContainerId:          containerID,
+					State:                c.GetState().State,
+					OutputOffset:         output.Offset(),
+					HeartbeatTimeoutSecs: uint32(s.manager.HeartbeatTimeout(c.Config).Seconds()),
+				},
+			},
+		}); err != nil {
+			return err
+		}
+	} else {
+		return status.Errorf(codes.InvalidArgument, "first message must be CreateContainer or AttachContainer request")
 	}
 
-	// Create and start container
-	id, err := s.manager.CreateContainer(stream.Context(), containerID, createReq.Config)
-	if err != nil {
-		return status.Errorf(codes.Internal, "failed to create container: %v", err)
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// Subscribe to container output. Unselected outputs are left nil so the loop
+	// never reads them; the capture channel still signals exit when it closes.
+	streams := selectedStreams(requested)
+	var outputCh chan container.OutputChunk
+	var msgCh <-chan string
+	if streams[pb.OutputStream_STDOUT] || streams[pb.OutputStream_STDERR] {
+		outputCh = make(chan container.OutputChunk)
+		go func() {
+			defer close(outputCh)
+			for {
+				chunk, err := output.Next(ctx)
+				if err != nil {
+					return
+				}
+				select {
+				case outputCh <- chunk:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	} else {
+		output.Close()
 	}
-	containerID = id
-
-	// Send created event
-	if err := stream.Send(&pb.RunResponse{
-		ContainerId: containerID,
-		Event: &pb.RunResponse_Created{
-			Created: &pb.ContainerCreated{
-				ContainerId: containerID,
-				State:       pb.ContainerState_RUNNING,
-			},
-		},
-	}); err != nil {
-		return err
+	if streams[pb.OutputStream_EVENTS] {
+		var unsubscribe func()
+		// The creating stream also gets what the runner sent before now
+		msgCh, unsubscribe = c.SubscribeMessages(firstMsg.GetCreate() != nil)
+		defer unsubscribe()
 	}
-
-	// Subscribe to container output
-	stdoutCh := s.manager.SubscribeStdout(containerID)
-	stderrCh := s.manager.SubscribeStderr(containerID)
-	msgCh := s.manager.SubscribeMessages(containerID)
+	captureCh, unsubscribeCapture := c.SubscribeCapture()
+	defer unsubscribeCapture()
+	execCh, unsubscribeExec := c.SubscribeExec()
+	defer unsubscribeExec()
 
 	// Channel for receiving stdin from client
 	stdinCh := make(chan []byte, 10)
 	errCh := make(chan error, 2)
 
-	// Heartbeat tracking - client MUST send heartbeat every 30 seconds
-	lastHeartbeat := time.Now()
-	heartbeatCh := make(chan struct{}, 1)
-
-	// Goroutine to monitor heartbeat timeout
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				// Check if heartbeat timeout exceeded
-				if time.Since(lastHeartbeat) > 30*time.Second {
-					errCh <- status.Errorf(codes.DeadlineExceeded, "heartbeat timeout: no heartbeat received for 30 seconds")
-					return
-				}
-			case <-heartbeatCh:
-				lastHeartbeat = time.Now()
-			case <-stream.Context().Done():
-				return
-			}
-		}
-	}()
+	// The manager terminates the container if the client stops sending
+	// heartbeats; stoppingCh tells us when that (or any other stop) begins
+	stoppingCh := c.Stopping()
 
 	// Goroutine to receive messages from client
 	go func() {
+		stdinClosed := false
 		for {
 			msg, err := stream.Recv()
 			if err != nil {
@@ -140,17 +329,49 @@ func (s *Service) Run(stream pb.ContainerManager_RunServer) error {
 				return
 			}
 
-			// Handle different request types
+			// Handle different request types; stdin sent after close_stdin is dropped
 			if stdin := msg.GetStdin(); stdin != nil {
-				stdinCh <- stdin
+				if !stdinClosed {
+					stdinCh <- stdin
+				}
 			} else if msg.GetCloseStdin() {
-				close(stdinCh)
-			} else if msg.GetHeartbeat() {
-				// Update heartbeat timestamp
-				select {
-				case heartbeatCh <- struct{}{}:
-				default:
+				if !stdinClosed {
+					stdinClosed = true
+					close(stdinCh)
 				}
+			} else if msg.GetHeartbeat() {
+				_ = s.manager.Heartbeat(containerID)
+			} else if signal := msg.GetSignal(); signal != nil {
+				_ = s.manager.Signal(containerID, signal.Name)
+			} else if resize := msg.GetResize(); resize != nil {
+				_ = s.manager.Resize(containerID, resize.Rows, resize.Cols)
+			} else if msg.GetPause() {
+				// The outcome reaches the client as a message event
+				go func() {
+					_ = s.manager.Pause(containerID)
+				}()
+			} else if msg.GetUnpause() {
+				go func() {
+					_ = s.manager.Unpause(containerID)
+				}()
+			} else if update := msg.GetUpdateNetworkPolicy(); update != nil {
+				// Applied in the background so heartbeats keep flowing; the
+				// outcome reaches the client as a message event
+				go func() {
+					_ = s.manager.UpdateNetworkPolicy(containerID, update.Network)
+				}()
+			} else if update := msg.GetUpdateResources(); update != nil {
+				// Applied in the background so heartbeats keep flowing; the
+				// outcome reaches the client as a message event
+				go func() {
+					_, _ = s.manager.UpdateContainerResources(containerID, update.Resources)
+				}()
+			} else if execReq := msg.GetExec(); execReq != nil {
+				// Output and the exit code, or the failure to start, arrive
+				// as exec events under the exec ID
+				go func() {
+					_, _ = s.manager.Exec(containerID, execReq)
+				}()
 			} else if terminate := msg.GetTerminate(); terminate != nil {
 				// Client requested termination
 				force := terminate.Force
@@ -159,7 +380,7 @@ func (s *Service) Run(stream pb.ContainerManager_RunServer) error {
 					timeout = 5
 				}
 				if err := s.manager.TerminateContainer(containerID, force, timeout); err != nil {
-					errCh <- err
+					errCh <- status.Error(errorCode(err), err.Error())
 					return
 				}
 				cleanupDone = true
@@ -177,51 +398,87 @@ func (s *Service) Run(stream pb.ContainerManager_RunServer) error {
 				continue
 			}
 		}
+		// The client sent close_stdin
+		_ = s.manager.CloseStdin(containerID)
 	}()
 
 	// Main event loop - forward container output to client
 	for {
 		select {
-		case data, ok := <-stdoutCh:
+		case chunk, ok := <-outputCh:
 			if !ok {
-				// Channel closed, container exited
+				// Output complete, container exited
 				goto done
 			}
-			if err := stream.Send(&pb.RunResponse{
+			if !streams[chunk.Stream] {
+				continue
+			}
+			resp := &pb.RunResponse{
+				ContainerId:  containerID,
+				OutputOffset: chunk.End(),
+			}
+			if chunk.Stream == pb.OutputStream_STDERR {
+				resp.Event = &pb.RunResponse_Stderr{Stderr: chunk.Data}
+			} else {
+				resp.Event = &pb.RunResponse_Stdout{Stdout: chunk.Data}
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+
+		case msg, ok := <-msgCh:
+			if !ok {
+				goto done
+			}
+			resp := &pb.RunResponse{
 				ContainerId: containerID,
-				Event: &pb.RunResponse_Stdout{
-					Stdout: data,
+				Event: &pb.RunResponse_Message{
+					Message: msg,
 				},
-			}); err != nil {
+			}
+			if typedEvents {
+				// Messages that do not parse still reach the client as they are
+				if event, err := container.ParseEvent(msg); err == nil {
+					resp.Event = &pb.RunResponse_TypedEvent{TypedEvent: event}
+				}
+			}
+			if err := stream.Send(resp); err != nil {
 				return err
 			}
 
-		case data, ok := <-stderrCh:
+		case chunk, ok := <-captureCh:
 			if !ok {
 				goto done
 			}
 			if err := stream.Send(&pb.RunResponse{
 				ContainerId: containerID,
-				Event: &pb.RunResponse_Stderr{
-					Stderr: data,
+				Event: &pb.RunResponse_Capture{
+					Capture: chunk,
 				},
 			}); err != nil {
 				return err
 			}
 
-		case msg, ok := <-msgCh:
+		case output, ok := <-execCh:
 			if !ok {
 				goto done
 			}
 			if err := stream.Send(&pb.RunResponse{
 				ContainerId: containerID,
-				Event: &pb.RunResponse_Message{
-					Message: msg,
+				Event: &pb.RunResponse_Exec{
+					Exec: output,
 				},
 			}); err != nil {
 				return err
 			}
 
+		case <-stoppingCh:
+			if c.StopReason() == lifecycle.ReasonHeartbeatTimeout {
+				return status.Errorf(codes.DeadlineExceeded, "heartbeat timeout: no heartbeat received for %d seconds", int(s.manager.HeartbeatTimeout(c.Config).Seconds()))
+			}
+			// Stopped for another reason; keep streaming until the container exits
+			stoppingCh = nil
+
 		case err := <-errCh:
 			if err != nil {
 				return err
@@ -239,18 +496,20 @@ done:
 	// Wait for container exit and send exit event
 	exitCode, err := s.manager.WaitContainer(containerID, 10)
 	if err == nil {
+		exit := &pb.ContainerExit{
+			ExitCode:  exitCode,
+			Timestamp: fmt.Sprintf("%d", time.Now().Unix()),
+		}
+		if status, err := s.manager.GetContainerStatus(containerID); err == nil {
+			exit.TerminationReason = status.TerminationReason
+		}
 		_ = stream.Send(&pb.RunResponse{
 			ContainerId: containerID,
-			Event: &pb.RunResponse_Exit{
-				Exit: &pb.ContainerExit{
-					ExitCode:  exitCode,
-					Timestamp: fmt.Sprintf("%d", time.Now().Unix()),
-				},
-			},
+			Event:       &pb.RunResponse_Exit{Exit: exit},
 		})
+		cleanupDone = true
 	}
 
-	cleanupDone = true
 	return nil
 }
 
@@ -259,12 +518,49 @@ func (s *Service) ListContainers(ctx context.Context, req *pb.ListContainersRequ
 	if req.Filter != nil {
 		filter = *req.Filter
 	}
+	labels, err := tenantLabels(req.Labels, req.Tenant)
+	if err != nil {
+		return nil, err
+	}
 
-	containers := s.manager.ListContainers(filter)
+	containers, next, err := s.manager.ListContainers(manager.ContainerQuery{
+		Filter:        filter,
+		Labels:        labels,
+		LabelSelector: req.GetLabelSelector(),
+		Image:         req.GetImage(),
+		ExitCode:      req.ExitCode,
+		SortBy:        req.GetSortBy(),
+		Descending:    req.Descending,
+		PageSize:      int(req.GetPageSize()),
+		PageToken:     req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, status.Error(errorCode(err), err.Error())
+	}
 
-	return &pb.ListContainersResponse{
+	resp := &pb.ListContainersResponse{
 		Containers: containers,
-	}, nil
+	}
+	if next != "" {
+		resp.NextPageToken = &next
+	}
+	return resp, nil
+}
+
+// tenantLabels adds the tenant label to labels when a tenant is given
+func tenantLabels(labels map[string]string, tenant *string) (map[string]string, error) {
+	if tenant == nil {
+		return labels, nil
+	}
+	if value, ok := labels[manager.TenantLabel]; ok && value != *tenant {
+		return nil, status.Errorf(codes.InvalidArgument, "tenant %q conflicts with label %s=%s", *tenant, manager.TenantLabel, value)
+	}
+	labels = maps.Clone(labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[manager.TenantLabel] = *tenant
+	return labels, nil
 }
 
 func (s *Service) GetContainerStatus(ctx context.Context, req *pb.GetContainerStatusRequest) (*pb.GetContainerStatusResponse, error) {
@@ -274,7 +570,7 @@ func (s *Service) GetContainerStatus(ctx context.Context, req *pb.GetContainerSt
 
 	containerStatus, err := s.manager.GetContainerStatus(req.ContainerId)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "container not found: %v", err)
+		return nil, status.Error(errorCode(err), err.Error())
 	}
 
 	return &pb.GetContainerStatusResponse{
@@ -283,6 +579,333 @@ func (s *Service) GetContainerStatus(ctx context.Context, req *pb.GetContainerSt
 	}, nil
 }
 
+func (s *Service) UpdateContainerResources(ctx context.Context, req *pb.UpdateContainerResourcesRequest) (*pb.UpdateContainerResourcesResponse, error) {
+	if req.ContainerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id is required")
+	}
+
+	if req.Resources == nil || (req.Resources.CpuLimit == nil && req.Resources.MemoryLimit == nil) {
+		return nil, status.Errorf(codes.InvalidArgument, "resources must set cpu_limit and/or memory_limit")
+	}
+
+	limits, err := s.manager.UpdateContainerResources(req.ContainerId, req.Resources)
+	if err != nil {
+		if errorCode(err) != codes.Internal {
+			return nil, statusError(err, err.Error())
+		}
+		return &pb.UpdateContainerResourcesResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		}, nil
+	}
+
+	return &pb.UpdateContainerResourcesResponse{
+		Success:   true,
+		Resources: limits,
+	}, nil
+}
+
+func (s *Service) PauseContainer(ctx context.Context, req *pb.PauseContainerRequest) (*pb.PauseContainerResponse, error) {
+	if req.ContainerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id is required")
+	}
+
+	if err := s.manager.Pause(req.ContainerId); err != nil {
+		if code := errorCode(err); code != codes.Internal {
+			return nil, status.Error(code, err.Error())
+		}
+		return &pb.PauseContainerResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		}, nil
+	}
+
+	return &pb.PauseContainerResponse{Success: true}, nil
+}
+
+func (s *Service) UnpauseContainer(ctx context.Context, req *pb.UnpauseContainerRequest) (*pb.UnpauseContainerResponse, error) {
+	if req.ContainerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id is required")
+	}
+
+	if err := s.manager.Unpause(req.ContainerId); err != nil {
+		if code := errorCode(err); code != codes.Internal {
+			return nil, status.Error(code, err.Error())
+		}
+		return &pb.UnpauseContainerResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		}, nil
+	}
+
+	return &pb.UnpauseContainerResponse{Success: true}, nil
+}
+
+func (s *Service) RestartContainer(ctx context.Context, req *pb.RestartContainerRequest) (*pb.RestartContainerResponse, error) {
+	if req.ContainerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id is required")
+	}
+
+	c, err := s.manager.RestartContainer(ctx, req.ContainerId)
+	if err != nil {
+		if errorCode(err) != codes.Internal {
+			return nil, statusError(err, err.Error())
+		}
+		return &pb.RestartContainerResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		}, nil
+	}
+
+	return &pb.RestartContainerResponse{
+		Success: true,
+		Attempt: c.Attempt(),
+		RunId:   c.RunID(),
+	}, nil
+}
+
+func (s *Service) ExtendTimeout(ctx context.Context, req *pb.ExtendTimeoutRequest) (*pb.ExtendTimeoutResponse, error) {
+	if req.ContainerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id is required")
+	}
+	if req.ExtendSecs == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "extend_secs must be positive")
+	}
+
+	deadline, total, err := s.manager.ExtendTimeout(req.ContainerId, time.Duration(req.ExtendSecs)*time.Second)
+	if err != nil {
+		if code := errorCode(err); code != codes.Internal {
+			return nil, status.Error(code, err.Error())
+		}
+		return &pb.ExtendTimeoutResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		}, nil
+	}
+
+	resp := &pb.ExtendTimeoutResponse{
+		Success:            true,
+		TotalExtensionSecs: uint32(total / time.Second),
+	}
+	if !deadline.IsZero() {
+		resp.RunDeadline = proto.Int64(deadline.Unix())
+	}
+	return resp, nil
+}
+
+func (s *Service) WaitReady(ctx context.Context, req *pb.WaitReadyRequest) (*pb.WaitReadyResponse, error) {
+	if req.ContainerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id is required")
+	}
+
+	timeout := manager.DefaultReadyTimeout
+	if req.TimeoutSecs != nil {
+		timeout = min(time.Duration(*req.TimeoutSecs)*time.Second, manager.MaxReadyTimeout)
+	}
+
+	if err := s.manager.WaitReady(ctx, req.ContainerId, timeout); err != nil {
+		if code := errorCode(err); code != codes.Internal {
+			return nil, status.Error(code, err.Error())
+		}
+		return &pb.WaitReadyResponse{
+			Ready: false,
+			Error: proto.String(err.Error()),
+		}, nil
+	}
+
+	return &pb.WaitReadyResponse{Ready: true}, nil
+}
+
+func (s *Service) Checkpoint(ctx context.Context, req *pb.CheckpointRequest) (*pb.CheckpointResponse, error) {
+	if req.ContainerId == "" || req.CheckpointId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id and checkpoint_id are required")
+	}
+
+	if err := s.manager.Checkpoint(req.ContainerId, req.CheckpointId, req.LeaveRunning); err != nil {
+		if code := errorCode(err); code != codes.Internal {
+			return nil, status.Error(code, err.Error())
+		}
+		return &pb.CheckpointResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		}, nil
+	}
+
+	return &pb.CheckpointResponse{Success: true}, nil
+}
+
+func (s *Service) StartCapture(ctx context.Context, req *pb.StartCaptureRequest) (*pb.StartCaptureResponse, error) {
+	if req.ContainerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id is required")
+	}
+
+	captureID, err := s.manager.StartCapture(req.ContainerId, req.Filter, req.MaxPackets, req.MaxDurationSecs)
+	if err != nil {
+		if code := errorCode(err); code != codes.Internal {
+			return nil, status.Error(code, err.Error())
+		}
+		return &pb.StartCaptureResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		}, nil
+	}
+
+	return &pb.StartCaptureResponse{
+		Success:   true,
+		CaptureId: proto.String(captureID),
+	}, nil
+}
+
+func (s *Service) StopCapture(ctx context.Context, req *pb.StopCaptureRequest) (*pb.StopCaptureResponse, error) {
+	if req.ContainerId == "" || req.CaptureId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "container_id and capture_id are required")
+	}
+
+	if err := s.manager.StopCapture(req.ContainerId, req.CaptureId); err != nil {
+		if code := errorCode(err); code != codes.Internal {
+			return nil, status.Error(code, err.Error())
+		}
+		return &pb.StopCaptureResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		}, nil
+	}
+
+	return &pb.StopCaptureResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *Service) UploadFile(stream pb.ContainerManager_UploadFileServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Errorf(codes.InvalidArgument, "container_id and path are required")
+	}
+	if err != nil {
+		return err
+	}
+
+	if first.ContainerId == "" || first.Path == "" {
+		return status.Errorf(codes.InvalidArgument, "container_id and path are required")
+	}
+
+	archive := &uploadReader{stream: stream, buf: first.Data}
+	size, err := s.manager.UploadFile(stream.Context(), first.ContainerId, first.Path, archive)
+	if err != nil {
+		if code := errorCode(err); code != codes.Internal {
+			return status.Error(code, err.Error())
+		}
+		return stream.SendAndClose(&pb.UploadFileResponse{
+			Success: false,
+			Error:   proto.String(err.Error()),
+		})
+	}
+
+	return stream.SendAndClose(&pb.UploadFileResponse{
+		Success: true,
+		Bytes:   uint64(size),
+	})
+}
+
+// uploadReader reads the tar archive carried by an UploadFile stream
+type uploadReader struct {
+	stream pb.ContainerManager_UploadFileServer
+	buf    []byte
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = msg.Data
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (s *Service) DownloadFile(req *pb.DownloadFileRequest, stream pb.ContainerManager_DownloadFileServer) error {
+	if req.ContainerId == "" || req.Path == "" {
+		return status.Errorf(codes.InvalidArgument, "container_id and path are required")
+	}
+
+	if _, err := s.manager.DownloadFile(stream.Context(), req.ContainerId, req.Path, &downloadWriter{stream: stream}); err != nil {
+		return status.Error(errorCode(err), err.Error())
+	}
+
+	return nil
+}
+
+// downloadWriter sends each write as the next chunk of a DownloadFile stream
+type downloadWriter struct {
+	stream pb.ContainerManager_DownloadFileServer
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&pb.DownloadFileResponse{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *Service) GetLogs(req *pb.GetLogsRequest, stream pb.ContainerManager_GetLogsServer) error {
+	if req.ContainerId == "" {
+		return status.Errorf(codes.InvalidArgument, "container_id is required")
+	}
+
+	q := logs.Query{
+		Tail:    int(req.GetTail()),
+		Streams: req.Streams,
+		Follow:  req.Follow,
+	}
+	if req.SinceUnixMs != nil {
+		q.Since = time.UnixMilli(*req.SinceUnixMs)
+	}
+
+	var sendErr error
+	err := s.manager.GetLogs(stream.Context(), req.ContainerId, q, func(line logs.Line) error {
+		sendErr = stream.Send(&pb.LogLine{
+			TimestampUnixMs: line.Time.UnixMilli(),
+			Stream:          line.Stream,
+			Data:            line.Data,
+		})
+		return sendErr
+	})
+	switch {
+	case sendErr != nil:
+		return sendErr
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// The client went away while following
+		return status.FromContextError(err).Err()
+	case err != nil:
+		return status.Error(errorCode(err), err.Error())
+	}
+
+	return nil
+}
+
+// WatchEvents streams the events of every container the request selects
+func (s *Service) WatchEvents(req *pb.WatchEventsRequest, stream pb.ContainerManager_WatchEventsServer) error {
+	labels, err := tenantLabels(req.Labels, req.Tenant)
+	if err != nil {
+		return err
+	}
+	filter := manager.EventFilter{
+		Labels: labels,
+		States: req.States,
+		Types:  req.Types,
+	}
+
+	err = s.manager.WatchEvents(stream.Context(), filter, stream.Send)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return err
+}
+
 func (s *Service) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
 	totalContainers, runningContainers := s.manager.GetStats()
 
@@ -296,29 +919,17 @@ func (s *Service) Health(ctx context.Context, req *pb.HealthRequest) (*pb.Health
 }
 
 func (s *Service) GetNodeResources(ctx context.Context, req *pb.GetNodeResourcesRequest) (*pb.GetNodeResourcesResponse, error) {
-	totalContainers, runningContainers := s.manager.GetStats()
-
-	cpuCount := runtime.NumCPU()
-
-	// Try to get memory info
-	var totalMemory, availableMemory, usedMemory uint64
-
-	// Get disk info from /tmp
-	var diskTotal, diskAvailable, diskUsed uint64
+	resources, err := s.manager.NodeResources()
+	if err != nil {
+		return &pb.GetNodeResourcesResponse{
+			Success: false,
+			Error:   proto.String(fmt.Sprintf("failed to read node resources: %v", err)),
+		}, nil
+	}
 
 	return &pb.GetNodeResourcesResponse{
-		Success: true,
-		Resources: &pb.NodeResources{
-			CpuCores:             uint32(cpuCount),
-			MemoryTotalBytes:     totalMemory,
-			MemoryAvailableBytes: availableMemory,
-			MemoryUsedBytes:      usedMemory,
-			DiskTotalBytes:       diskTotal,
-			DiskAvailableBytes:   diskAvailable,
-			DiskUsedBytes:        diskUsed,
-			RunningContainers:    uint32(runningContainers),
-			TotalContainers:      uint32(totalContainers),
-		},
+		Success:   true,
+		Resources: resources,
 	}, nil
 }
 
@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"testing"
 
 	"github.com/metorial/fleet/holopod/services/container-manager/pkg/manager"
 	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -133,6 +136,261 @@ func TestGetContainerStatusNotFound(t *testing.T) {
 	}
 }
 
+func TestUpdateContainerResourcesValidation(t *testing.T) {
+	svc, _ := setupTestService(t)
+	if svc == nil {
+		return
+	}
+
+	memory := "1g"
+	tests := []struct {
+		name     string
+		req      *pb.UpdateContainerResourcesRequest
+		wantCode codes.Code
+	}{
+		{
+			name:     "missing container_id",
+			req:      &pb.UpdateContainerResourcesRequest{Resources: &pb.ResourceLimits{MemoryLimit: &memory}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing resources",
+			req:      &pb.UpdateContainerResourcesRequest{ContainerId: "abc"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown container",
+			req:      &pb.UpdateContainerResourcesRequest{ContainerId: "nonexistent", Resources: &pb.ResourceLimits{MemoryLimit: &memory}},
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.UpdateContainerResources(context.Background(), tt.req)
+			st, ok := status.FromError(err)
+			if !ok || err == nil {
+				t.Fatalf("Expected gRPC status error, got %v", err)
+			}
+			if st.Code() != tt.wantCode {
+				t.Errorf("Expected %v, got %v", tt.wantCode, st.Code())
+			}
+		})
+	}
+}
+
+func TestExtendTimeoutValidation(t *testing.T) {
+	svc, _ := setupTestService(t)
+	if svc == nil {
+		return
+	}
+
+	tests := []struct {
+		name     string
+		req      *pb.ExtendTimeoutRequest
+		wantCode codes.Code
+	}{
+		{"missing container_id", &pb.ExtendTimeoutRequest{ExtendSecs: 60}, codes.InvalidArgument},
+		{"missing extend_secs", &pb.ExtendTimeoutRequest{ContainerId: "abc"}, codes.InvalidArgument},
+		{"unknown container", &pb.ExtendTimeoutRequest{ContainerId: "nonexistent", ExtendSecs: 60}, codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ExtendTimeout(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Expected %v, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestPauseValidation(t *testing.T) {
+	svc, _ := setupTestService(t)
+	if svc == nil {
+		return
+	}
+
+	if _, err := svc.PauseContainer(context.Background(), &pb.PauseContainerRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected %v, got %v", codes.InvalidArgument, err)
+	}
+	if _, err := svc.UnpauseContainer(context.Background(), &pb.UnpauseContainerRequest{ContainerId: "nonexistent"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected %v, got %v", codes.NotFound, err)
+	}
+}
+
+func TestWaitReadyValidation(t *testing.T) {
+	svc, _ := setupTestService(t)
+	if svc == nil {
+		return
+	}
+
+	if _, err := svc.WaitReady(context.Background(), &pb.WaitReadyRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected %v, got %v", codes.InvalidArgument, err)
+	}
+	if _, err := svc.WaitReady(context.Background(), &pb.WaitReadyRequest{ContainerId: "nonexistent"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected %v, got %v", codes.NotFound, err)
+	}
+}
+
+func TestCaptureValidation(t *testing.T) {
+	svc, _ := setupTestService(t)
+	if svc == nil {
+		return
+	}
+
+	t.Run("start missing container_id", func(t *testing.T) {
+		_, err := svc.StartCapture(context.Background(), &pb.StartCaptureRequest{})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected %v, got %v", codes.InvalidArgument, err)
+		}
+	})
+
+	t.Run("start unknown container", func(t *testing.T) {
+		_, err := svc.StartCapture(context.Background(), &pb.StartCaptureRequest{ContainerId: "nonexistent"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected %v, got %v", codes.NotFound, err)
+		}
+	})
+
+	t.Run("stop missing capture_id", func(t *testing.T) {
+		_, err := svc.StopCapture(context.Background(), &pb.StopCaptureRequest{ContainerId: "abc"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected %v, got %v", codes.InvalidArgument, err)
+		}
+	})
+
+	t.Run("stop unknown container", func(t *testing.T) {
+		_, err := svc.StopCapture(context.Background(), &pb.StopCaptureRequest{ContainerId: "nonexistent", CaptureId: "abc"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected %v, got %v", codes.NotFound, err)
+		}
+	})
+}
+
+func TestDownloadFileValidation(t *testing.T) {
+	svc, _ := setupTestService(t)
+	if svc == nil {
+		return
+	}
+
+	err := svc.DownloadFile(&pb.DownloadFileRequest{ContainerId: "abc"}, nil)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected %v, got %v", codes.InvalidArgument, err)
+	}
+}
+
+func TestGetLogsValidation(t *testing.T) {
+	svc, _ := setupTestService(t)
+	if svc == nil {
+		return
+	}
+
+	err := svc.GetLogs(&pb.GetLogsRequest{}, nil)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected %v, got %v", codes.InvalidArgument, err)
+	}
+}
+
+func TestWatchEventsValidation(t *testing.T) {
+	svc, _ := setupTestService(t)
+	if svc == nil {
+		return
+	}
+
+	tenant := "other"
+	err := svc.WatchEvents(&pb.WatchEventsRequest{
+		Labels: map[string]string{manager.TenantLabel: "acme"},
+		Tenant: &tenant,
+	}, nil)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected %v, got %v", codes.InvalidArgument, err)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"not found", fmt.Errorf("%w: abc", manager.ErrNotFound), codes.NotFound},
+		{"already exists", fmt.Errorf("%w: abc", manager.ErrAlreadyExists), codes.AlreadyExists},
+		{"limit reached", fmt.Errorf("%w (10)", manager.ErrLimitReached), codes.ResourceExhausted},
+		{"insufficient resources", fmt.Errorf("%w: CPU headroom", manager.ErrInsufficientResources), codes.ResourceExhausted},
+		{"not admitted", &manager.AdmissionError{Resource: "memory", Requested: 1 << 30}, codes.ResourceExhausted},
+		{"invalid resources", fmt.Errorf("%w: invalid CPU limit: two", manager.ErrInvalidResources), codes.InvalidArgument},
+		{"not running", manager.ErrNotRunning, codes.FailedPrecondition},
+		{"invalid path", fmt.Errorf("%w: path is required", manager.ErrInvalidPath), codes.InvalidArgument},
+		{"transfer too large", manager.ErrTransferTooLarge, codes.ResourceExhausted},
+		{"invalid query", fmt.Errorf("%w: malformed page token", manager.ErrInvalidQuery), codes.InvalidArgument},
+		{"not restartable", fmt.Errorf("%w: container abc is RUNNING", manager.ErrNotRestartable), codes.FailedPrecondition},
+		{"extension limit", fmt.Errorf("%w: 1h0m0s of at most 1h0m0s already used", manager.ErrExtensionLimit), codes.ResourceExhausted},
+		{"wrapped twice", fmt.Errorf("failed to start container: %w", fmt.Errorf("%w: abc", manager.ErrNotFound)), codes.NotFound},
+		{"other", errors.New("boom"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCode(tt.err); got != tt.want {
+				t.Errorf("errorCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusErrorDetails(t *testing.T) {
+	err := &manager.AdmissionError{Resource: "cpu", Requested: 2, Committed: 3, Capacity: 4}
+	st := status.Convert(statusError(err, err.Error()))
+	if st.Code() != codes.ResourceExhausted {
+		t.Errorf("Expected %v, got %v", codes.ResourceExhausted, st.Code())
+	}
+
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("Expected one detail, got %v", details)
+	}
+	quota, ok := details[0].(*errdetails.QuotaFailure)
+	if !ok || len(quota.Violations) != 1 || quota.Violations[0].Subject != "cpu" {
+		t.Errorf("Expected a QuotaFailure for cpu, got %v", details[0])
+	}
+
+	// Other errors carry no details
+	if st := status.Convert(statusError(manager.ErrNotFound, "not found")); len(st.Details()) != 0 {
+		t.Errorf("Expected no details, got %v", st.Details())
+	}
+}
+
+func TestSelectedStreams(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []pb.OutputStream
+		want      []pb.OutputStream
+	}{
+		{"default all", nil, []pb.OutputStream{pb.OutputStream_STDOUT, pb.OutputStream_STDERR, pb.OutputStream_EVENTS}},
+		{"unspecified means all", []pb.OutputStream{pb.OutputStream_OUTPUT_STREAM_UNSPECIFIED}, []pb.OutputStream{pb.OutputStream_STDOUT, pb.OutputStream_STDERR, pb.OutputStream_EVENTS}},
+		{"stderr only", []pb.OutputStream{pb.OutputStream_STDERR}, []pb.OutputStream{pb.OutputStream_STDERR}},
+		{"stdout and events", []pb.OutputStream{pb.OutputStream_STDOUT, pb.OutputStream_EVENTS}, []pb.OutputStream{pb.OutputStream_STDOUT, pb.OutputStream_EVENTS}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectedStreams(tt.requested)
+			for _, stream := range []pb.OutputStream{pb.OutputStream_STDOUT, pb.OutputStream_STDERR, pb.OutputStream_EVENTS} {
+				want := false
+				for _, w := range tt.want {
+					if w == stream {
+						want = true
+					}
+				}
+				if got[stream] != want {
+					t.Errorf("selectedStreams()[%v] = %v, want %v", stream, got[stream], want)
+				}
+			}
+		})
+	}
+}
+
 func TestHealth(t *testing.T) {
 	svc, _ := setupTestService(t)
 	if svc == nil {
@@ -186,6 +444,12 @@ func TestGetNodeResources(t *testing.T) {
 	if resp.Resources.CpuCores == 0 {
 		t.Error("CPU count should be > 0")
 	}
+	if resp.Resources.MemoryTotalBytes == 0 || resp.Resources.MemoryUsedBytes > resp.Resources.MemoryTotalBytes {
+		t.Errorf("Unexpected memory figures: total %d, used %d", resp.Resources.MemoryTotalBytes, resp.Resources.MemoryUsedBytes)
+	}
+	if resp.Resources.DiskTotalBytes == 0 {
+		t.Error("Disk total should be > 0")
+	}
 }
 
 func TestGetAvailableImages(t *testing.T) {
@@ -0,0 +1,426 @@
+// Package shim keeps isolation-runners alive across container-manager
+// restarts. Instead of starting a runner itself, the manager starts a shim,
+// its own binary re-executed with the Command argument in a session of its
+// own, which starts the runner and stays its parent. The shim holds the far
+// ends of the runner's stdin, stdout, stderr and frame pipes and hands them to
+// whichever manager connects to its unix socket, then reports the runner's
+// exit status over that connection.
+//
+// When the manager goes away, the runner keeps running: its pipes stay open
+// through the shim, and what it writes meanwhile waits in them. A restarted
+// manager reattaches by connecting to the socket again. The service manager
+// must leave the shims running when it stops the container-manager (for
+// systemd, KillMode=process).
+package shim
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Command is the first argument that makes the container-manager binary run as
+// a shim, followed by the socket path and the isolation-runner's path
+const Command = "runner-shim"
+
+const (
+	// How long Spawn waits for a new shim to listen on its socket
+	spawnTimeout = 10 * time.Second
+	// How long a shim whose runner has exited waits for a manager to collect
+	// the exit status before giving up
+	orphanTimeout = time.Hour
+)
+
+// The runner's pipes, in the order they are passed over the socket
+const (
+	fdStdin = iota
+	fdStdout
+	fdStderr
+	fdOutput // Frame pipe the runner writes on its fd 3
+	fdInput  // Frame pipe the runner reads on its fd 4
+	fdCount
+)
+
+// hello is the first message on a connection, sent with the runner's pipes
+type hello struct {
+	Pid int `json:"pid"`
+}
+
+// exitStatus is sent once the runner has exited
+type exitStatus struct {
+	ExitCode int32 `json:"exit_code"`
+}
+
+// Runner is a manager's connection to a runner started through a shim
+type Runner struct {
+	// Pid is the runner's process ID
+	Pid int
+
+	Stdin  *os.File
+	Stdout *os.File
+	Stderr *os.File
+	// Output carries the workload's output frames and Input its input frames
+	Output *os.File
+	Input  *os.File
+
+	conn   *net.UnixConn
+	reader io.Reader
+}
+
+// Spawn starts a shim listening on socketPath that runs the isolation-runner
+// at runnerPath with env, and attaches to it
+func Spawn(socketPath, runnerPath string, env []string) (*Runner, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the manager's binary: %w", err)
+	}
+
+	// The shim only writes to stderr before it serves its socket, so it is
+	// never left writing to the pipe of a manager that has gone away
+	var stderr bytes.Buffer
+	cmd := exec.Command(self, Command, socketPath, runnerPath)
+	cmd.Env = env
+	cmd.Stderr = &stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start shim: %w", err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	deadline := time.Now().Add(spawnTimeout)
+	for {
+		runner, err := Attach(socketPath)
+		if err == nil {
+			return runner, nil
+		}
+
+		select {
+		case <-exited:
+			return nil, fmt.Errorf("shim exited: %s", strings.TrimSpace(stderr.String()))
+		case <-time.After(10 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			_ = cmd.Process.Kill()
+			return nil, fmt.Errorf("shim did not listen on %s: %w", socketPath, err)
+		}
+	}
+}
+
+// Attach connects to the shim listening on socketPath and takes over its
+// runner's pipes
+func Attach(socketPath string) (*Runner, error) {
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 512)
+	oob := make([]byte, syscall.CmsgSpace(fdCount*4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read from shim: %w", err)
+	}
+
+	fds, err := parseRights(oob[:oobn])
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	files := make([]*os.File, len(fds))
+	for i, fd := range fds {
+		syscall.CloseOnExec(fd)
+		files[i] = os.NewFile(uintptr(fd), fmt.Sprintf("runner-pipe-%d", i))
+	}
+	if len(files) != fdCount {
+		closeFiles(files)
+		conn.Close()
+		return nil, fmt.Errorf("shim sent %d pipes, expected %d", len(files), fdCount)
+	}
+
+	// The exit status may have come in with the hello
+	line, rest, _ := bytes.Cut(buf[:n], []byte("\n"))
+	var h hello
+	if err := json.Unmarshal(line, &h); err != nil {
+		closeFiles(files)
+		conn.Close()
+		return nil, fmt.Errorf("invalid hello from shim: %w", err)
+	}
+
+	return &Runner{
+		Pid:    h.Pid,
+		Stdin:  files[fdStdin],
+		Stdout: files[fdStdout],
+		Stderr: files[fdStderr],
+		Output: files[fdOutput],
+		Input:  files[fdInput],
+		conn:   conn,
+		reader: io.MultiReader(bytes.NewReader(rest), conn),
+	}, nil
+}
+
+func parseRights(oob []byte) ([]int, error) {
+	messages, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse shim control message: %w", err)
+	}
+	var fds []int
+	for _, msg := range messages {
+		rights, err := syscall.ParseUnixRights(&msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse shim control message: %w", err)
+		}
+		fds = append(fds, rights...)
+	}
+	return fds, nil
+}
+
+// Wait blocks until the runner exits and returns its exit code. The shim exits
+// once the status is collected; an error means the shim went away without
+// reporting one.
+func (r *Runner) Wait() (int32, error) {
+	defer r.conn.Close()
+
+	line, err := bufio.NewReader(r.reader).ReadBytes('\n')
+	if err != nil {
+		return 0, fmt.Errorf("lost connection to shim: %w", err)
+	}
+	var status exitStatus
+	if err := json.Unmarshal(line, &status); err != nil {
+		return 0, fmt.Errorf("invalid exit status from shim: %w", err)
+	}
+
+	// Let the shim exit
+	_, _ = r.conn.Write([]byte{'\n'})
+	return status.ExitCode, nil
+}
+
+// Close lets go of the runner, which keeps running for another manager to
+// attach to. The pipes are the caller's to close.
+func (r *Runner) Close() error {
+	return r.conn.Close()
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// Main runs the shim with the arguments following Command and returns its
+// exit code
+func Main(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s <socket> <isolation-runner>\n", Command)
+		return 2
+	}
+	socketPath, runnerPath := args[0], args[1]
+
+	s, err := start(socketPath, runnerPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	s.serve()
+	return 0
+}
+
+// server is the shim's side
+type server struct {
+	runner   *exec.Cmd
+	pipes    []*os.File // The manager's ends, indexed by the fd constants
+	listener *net.UnixListener
+
+	exited   chan struct{}
+	exitCode int32
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+func start(socketPath, runnerPath string) (*server, error) {
+	// The manager signals the runner itself; a hangup from the terminal the
+	// manager was started from must not reach the shim
+	signal.Ignore(syscall.SIGHUP, syscall.SIGPIPE)
+
+	pipes := make([]*os.File, fdCount)
+	child := make([]*os.File, fdCount)
+	for i := range fdCount {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeFiles(pipes[:i])
+			closeFiles(child[:i])
+			return nil, fmt.Errorf("failed to create pipe: %w", err)
+		}
+		// The runner reads stdin and its input frames, and writes the rest
+		if i == fdStdin || i == fdInput {
+			pipes[i], child[i] = w, r
+		} else {
+			pipes[i], child[i] = r, w
+		}
+	}
+
+	cmd := exec.Command(runnerPath)
+	cmd.Env = os.Environ()
+	cmd.Stdin = child[fdStdin]
+	cmd.Stdout = child[fdStdout]
+	cmd.Stderr = child[fdStderr]
+	cmd.ExtraFiles = []*os.File{child[fdOutput], child[fdInput]}
+
+	_ = os.Remove(socketPath)
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		closeFiles(pipes)
+		closeFiles(child)
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	_ = os.Chmod(socketPath, 0o600)
+
+	err = cmd.Start()
+	closeFiles(child)
+	if err != nil {
+		listener.Close()
+		closeFiles(pipes)
+		return nil, fmt.Errorf("failed to start isolation-runner: %w", err)
+	}
+
+	s := &server{
+		runner:   cmd,
+		pipes:    pipes,
+		listener: listener,
+		exited:   make(chan struct{}),
+	}
+
+	// Termination requests for the shim are meant for the runner
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		for sig := range sigs {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	go s.wait()
+	return s, nil
+}
+
+// wait records the runner's exit and reports it to the attached manager
+func (s *server) wait() {
+	err := s.runner.Wait()
+	exitCode := int32(0)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = int32(exitErr.ExitCode())
+		} else {
+			exitCode = 1
+		}
+	}
+
+	s.mu.Lock()
+	s.exitCode = exitCode
+	close(s.exited)
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn != nil {
+		s.sendExit(conn)
+	}
+}
+
+// serve hands the runner's pipes to each manager that connects, until one has
+// acknowledged the runner's exit status or none came for it in time
+func (s *server) serve() {
+	defer os.Remove(s.listener.Addr().String())
+
+	collected := make(chan struct{})
+	var collectOnce sync.Once
+	go func() {
+		select {
+		case <-collected:
+		case <-s.exited:
+			select {
+			case <-collected:
+			case <-time.After(orphanTimeout):
+			}
+		}
+		s.listener.Close()
+	}()
+
+	for {
+		conn, err := s.listener.AcceptUnix()
+		if err != nil {
+			return
+		}
+		if err := s.sendHello(conn); err != nil {
+			conn.Close()
+			continue
+		}
+
+		// A new manager replaces one that has gone away
+		s.mu.Lock()
+		if s.conn != nil {
+			s.conn.Close()
+		}
+		s.conn = conn
+		exited := isClosed(s.exited)
+		s.mu.Unlock()
+
+		if exited {
+			s.sendExit(conn)
+		}
+
+		go func() {
+			// The manager writes back once it has the exit status, and
+			// otherwise only closes the connection when it goes away
+			ack := make([]byte, 1)
+			if n, _ := conn.Read(ack); n > 0 {
+				collectOnce.Do(func() { close(collected) })
+			}
+		}()
+	}
+}
+
+func (s *server) sendHello(conn *net.UnixConn) error {
+	data, _ := json.Marshal(hello{Pid: s.runner.Process.Pid})
+	fds := make([]int, len(s.pipes))
+	for i, f := range s.pipes {
+		fds[i] = int(f.Fd())
+	}
+	_, _, err := conn.WriteMsgUnix(append(data, '\n'), syscall.UnixRights(fds...), nil)
+	return err
+}
+
+func (s *server) sendExit(conn *net.UnixConn) {
+	s.mu.Lock()
+	exitCode := s.exitCode
+	s.mu.Unlock()
+
+	data, _ := json.Marshal(exitStatus{ExitCode: exitCode})
+	_, _ = conn.Write(append(data, '\n'))
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package shim
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// The test binary stands in for the manager's binary when Spawn re-executes it
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == Command {
+		os.Exit(Main(os.Args[2:]))
+	}
+	os.Exit(m.Run())
+}
+
+// writeRunner writes a stand-in isolation-runner that echoes stdin lines to
+// stdout until it reads "exit"
+func writeRunner(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "runner")
+	script := "#!/bin/sh\nwhile read line; do\n  [ \"$line\" = exit ] && exit 7\n  echo \"$line\"\ndone\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReattach(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "shim.sock")
+
+	runner, err := Spawn(socket, writeRunner(t, dir), nil)
+	if err != nil {
+		t.Fatalf("Spawn() error = %v", err)
+	}
+	if runner.Pid <= 0 {
+		t.Errorf("Spawn() pid = %d", runner.Pid)
+	}
+
+	stdout := bufio.NewReader(runner.Stdout)
+	if _, err := runner.Stdin.WriteString("first\n"); err != nil {
+		t.Fatal(err)
+	}
+	if line, _ := stdout.ReadString('\n'); line != "first\n" {
+		t.Errorf("read %q, want first", line)
+	}
+
+	// The manager goes away; the runner keeps running and buffers its output
+	if _, err := runner.Stdin.WriteString("second\n"); err != nil {
+		t.Fatal(err)
+	}
+	closeFiles([]*os.File{runner.Stdin, runner.Stdout, runner.Stderr, runner.Output, runner.Input})
+	runner.Close()
+
+	runner, err = Attach(socket)
+	if err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+	stdout = bufio.NewReader(runner.Stdout)
+	if line, _ := stdout.ReadString('\n'); line != "second\n" {
+		t.Errorf("read %q after reattaching, want second", line)
+	}
+
+	if _, err := runner.Stdin.WriteString("exit\n"); err != nil {
+		t.Fatal(err)
+	}
+	exitCode, err := runner.Wait()
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if exitCode != 7 {
+		t.Errorf("Wait() = %d, want 7", exitCode)
+	}
+
+	// With the exit status collected the shim goes away with its socket
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(socket); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("shim kept its socket after its exit status was collected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSpawnMissingRunner(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Spawn(filepath.Join(dir, "shim.sock"), filepath.Join(dir, "missing"), nil); err == nil {
+		t.Error("Spawn() succeeded without a runner")
+	}
+}
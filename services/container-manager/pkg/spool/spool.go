@@ -0,0 +1,289 @@
+// Package spool buffers a stream of output for one consumer without losing any
+// of it. Entries are held in memory up to a cap, then spill to a temporary
+// file up to a cap of their own; once both are full, writers block until the
+// consumer catches up, which pushes back on whatever produces the output.
+package spool
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+const (
+	// DefaultMemoryBytes is how much a buffer holds in memory before spilling
+	DefaultMemoryBytes = 4 << 20
+	// DefaultDiskBytes is how much a buffer spills to disk before writers block
+	DefaultDiskBytes = 256 << 20
+)
+
+// ErrClosed is returned by writes to a closed buffer and by reads from a
+// discarded one
+var ErrClosed = errors.New("spool closed")
+
+// entryHeaderSize is the kind byte and big-endian uint32 length ahead of each
+// spilled entry's data
+const entryHeaderSize = 5
+
+// Entry is one piece of output; Kind tells the consumer which stream it is from
+type Entry struct {
+	Kind byte
+	Data []byte
+}
+
+// Config bounds a buffer
+type Config struct {
+	// MemoryBytes caps the data held in memory; values <= 0 use
+	// DefaultMemoryBytes
+	MemoryBytes int
+	// DiskBytes caps the spill file; 0 disables spilling, so writers block as
+	// soon as memory is full
+	DiskBytes int64
+	// Dir is where spill files are created; empty means the system's
+	// temporary directory
+	Dir string
+}
+
+// ConfigFromEnv reads OUTPUT_BUFFER_MEMORY_BYTES, OUTPUT_BUFFER_DISK_BYTES and
+// OUTPUT_BUFFER_DIR, falling back to the defaults for unset variables
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		MemoryBytes: DefaultMemoryBytes,
+		DiskBytes:   DefaultDiskBytes,
+		Dir:         os.Getenv("OUTPUT_BUFFER_DIR"),
+	}
+
+	if value := os.Getenv("OUTPUT_BUFFER_MEMORY_BYTES"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return Config{}, fmt.Errorf("invalid OUTPUT_BUFFER_MEMORY_BYTES %q", value)
+		}
+		config.MemoryBytes = n
+	}
+	if value := os.Getenv("OUTPUT_BUFFER_DISK_BYTES"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return Config{}, fmt.Errorf("invalid OUTPUT_BUFFER_DISK_BYTES %q", value)
+		}
+		config.DiskBytes = n
+	}
+
+	return config, nil
+}
+
+// Buffer is a FIFO of entries for a single consumer. Entries in memory always
+// precede those on disk: once anything has spilled, later writes go to disk
+// too until the consumer has drained it.
+type Buffer struct {
+	config Config
+
+	mu       sync.Mutex
+	memory   []Entry
+	memBytes int
+
+	file      *os.File // Spill file, created on first use
+	fileRead  int64
+	fileWrite int64
+	spilled   int // Entries on disk
+
+	changed   chan struct{} // Closed and replaced on every change
+	closed    bool          // No more writes; reads drain what is left
+	discarded bool          // The consumer is gone
+}
+
+// New returns an empty buffer
+func New(config Config) *Buffer {
+	if config.MemoryBytes <= 0 {
+		config.MemoryBytes = DefaultMemoryBytes
+	}
+	return &Buffer{
+		config:  config,
+		changed: make(chan struct{}),
+	}
+}
+
+// Write adds an entry, waiting while the buffer is full. An entry larger than
+// both caps is still taken once the buffer is empty. It fails with ErrClosed
+// once the buffer is closed or discarded, and with ctx's error when ctx ends
+// first.
+func (b *Buffer) Write(ctx context.Context, entry Entry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for {
+		if b.closed || b.discarded {
+			return ErrClosed
+		}
+
+		empty := len(b.memory) == 0 && b.spilled == 0
+		switch {
+		case b.spilled == 0 && (empty || b.memBytes+len(entry.Data) <= b.config.MemoryBytes):
+			b.memory = append(b.memory, entry)
+			b.memBytes += len(entry.Data)
+			b.notify()
+			return nil
+
+		case b.config.DiskBytes > 0 && b.fileWrite+int64(entryHeaderSize+len(entry.Data)) <= b.config.DiskBytes:
+			if err := b.spill(entry); err != nil {
+				return err
+			}
+			b.notify()
+			return nil
+		}
+
+		changed := b.changed
+		b.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			b.mu.Lock()
+			return ctx.Err()
+		}
+		b.mu.Lock()
+	}
+}
+
+// spill appends an entry to the spill file. The caller must hold b.mu.
+func (b *Buffer) spill(entry Entry) error {
+	if b.file == nil {
+		file, err := os.CreateTemp(b.config.Dir, "holopod-spool-*")
+		if err != nil {
+			return fmt.Errorf("failed to create spill file: %w", err)
+		}
+		// Unlinked at once, so it goes away with the process whatever happens
+		os.Remove(file.Name())
+		b.file = file
+	}
+
+	record := make([]byte, entryHeaderSize+len(entry.Data))
+	record[0] = entry.Kind
+	binary.BigEndian.PutUint32(record[1:entryHeaderSize], uint32(len(entry.Data)))
+	copy(record[entryHeaderSize:], entry.Data)
+	if _, err := b.file.WriteAt(record, b.fileWrite); err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+
+	b.fileWrite += int64(len(record))
+	b.spilled++
+	return nil
+}
+
+// unspill reads the oldest entry from the spill file, emptying the file once
+// it has all been read. The caller must hold b.mu.
+func (b *Buffer) unspill() (Entry, error) {
+	var header [entryHeaderSize]byte
+	if _, err := b.file.ReadAt(header[:], b.fileRead); err != nil {
+		return Entry{}, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	data := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := b.file.ReadAt(data, b.fileRead+entryHeaderSize); err != nil {
+		return Entry{}, fmt.Errorf("failed to read spill file: %w", err)
+	}
+
+	b.fileRead += int64(entryHeaderSize + len(data))
+	b.spilled--
+	if b.spilled == 0 {
+		b.fileRead, b.fileWrite = 0, 0
+		_ = b.file.Truncate(0)
+	}
+	return Entry{Kind: header[0], Data: data}, nil
+}
+
+// Read returns the oldest entry, waiting for one if the buffer is empty. It
+// returns io.EOF once the buffer is closed and drained, ErrClosed once it is
+// discarded, and ctx's error when ctx ends first.
+func (b *Buffer) Read(ctx context.Context) (Entry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for {
+		if b.discarded {
+			return Entry{}, ErrClosed
+		}
+
+		if len(b.memory) > 0 {
+			entry := b.memory[0]
+			b.memory[0] = Entry{}
+			b.memory = b.memory[1:]
+			b.memBytes -= len(entry.Data)
+			if len(b.memory) == 0 {
+				b.memory = nil
+			}
+			b.notify()
+			return entry, nil
+		}
+		if b.spilled > 0 {
+			entry, err := b.unspill()
+			if err != nil {
+				return Entry{}, err
+			}
+			b.notify()
+			return entry, nil
+		}
+		if b.closed {
+			return Entry{}, io.EOF
+		}
+
+		changed := b.changed
+		b.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			b.mu.Lock()
+			return Entry{}, ctx.Err()
+		}
+		b.mu.Lock()
+	}
+}
+
+// Buffered returns how many bytes of entry data are waiting in memory and on
+// disk
+func (b *Buffer) Buffered() (memory int, disk int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.memBytes, b.fileWrite - b.fileRead
+}
+
+// Close ends the writes; the consumer reads what is left, then io.EOF
+func (b *Buffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		b.notify()
+	}
+}
+
+// Discard drops everything buffered and releases the spill file, for a
+// consumer that has gone away. Blocked writers and readers fail with
+// ErrClosed.
+func (b *Buffer) Discard() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.discarded {
+		return
+	}
+	b.discarded = true
+	b.closed = true
+	b.memory = nil
+	b.memBytes = 0
+	if b.file != nil {
+		b.file.Close()
+		b.file = nil
+	}
+	b.fileRead, b.fileWrite, b.spilled = 0, 0, 0
+	b.notify()
+}
+
+// notify wakes everyone waiting on the buffer. The caller must hold b.mu.
+func (b *Buffer) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
@@ -0,0 +1,129 @@
+package spool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestSpillKeepsOrder(t *testing.T) {
+	b := New(Config{MemoryBytes: 8, DiskBytes: 1 << 20, Dir: t.TempDir()})
+	ctx := context.Background()
+
+	// Past the memory cap entries go to disk, and keep going there until it is
+	// drained, so they come back in the order written
+	for i := range 10 {
+		if err := b.Write(ctx, Entry{Kind: byte(i % 3), Data: []byte(fmt.Sprintf("entry-%d", i))}); err != nil {
+			t.Fatalf("Write(%d) error = %v", i, err)
+		}
+	}
+	if memory, disk := b.Buffered(); memory != 7 || disk == 0 {
+		t.Errorf("Buffered() = %d, %d, want one entry in memory and the rest on disk", memory, disk)
+	}
+
+	for i := range 10 {
+		entry, err := b.Read(ctx)
+		if err != nil {
+			t.Fatalf("Read(%d) error = %v", i, err)
+		}
+		if want := fmt.Sprintf("entry-%d", i); string(entry.Data) != want || entry.Kind != byte(i%3) {
+			t.Fatalf("Read(%d) = %d %q, want %d %q", i, entry.Kind, entry.Data, i%3, want)
+		}
+	}
+	if memory, disk := b.Buffered(); memory != 0 || disk != 0 {
+		t.Errorf("Buffered() = %d, %d after draining", memory, disk)
+	}
+
+	// Once drained, writes go to memory again
+	if err := b.Write(ctx, Entry{Data: []byte("again")}); err != nil {
+		t.Fatal(err)
+	}
+	if memory, _ := b.Buffered(); memory != 5 {
+		t.Errorf("Buffered() memory = %d, want 5", memory)
+	}
+}
+
+func TestWriteWaitsWhenFull(t *testing.T) {
+	b := New(Config{MemoryBytes: 4})
+	ctx := context.Background()
+
+	// An entry larger than the caps still fits an empty buffer
+	if err := b.Write(ctx, Entry{Data: []byte("too large")}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := b.Write(short, Entry{Data: []byte("x")}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Write() to a full buffer error = %v, want it to wait", err)
+	}
+
+	written := make(chan error, 1)
+	go func() {
+		written <- b.Write(ctx, Entry{Data: []byte("next")})
+	}()
+	if entry, err := b.Read(ctx); err != nil || string(entry.Data) != "too large" {
+		t.Fatalf("Read() = %q, %v", entry.Data, err)
+	}
+	if err := <-written; err != nil {
+		t.Fatalf("Write() error = %v once there was room", err)
+	}
+}
+
+func TestCloseAndDiscard(t *testing.T) {
+	ctx := context.Background()
+
+	b := New(Config{})
+	if err := b.Write(ctx, Entry{Data: []byte("last")}); err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+	if err := b.Write(ctx, Entry{Data: []byte("late")}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write() after Close error = %v, want ErrClosed", err)
+	}
+	if entry, err := b.Read(ctx); err != nil || string(entry.Data) != "last" {
+		t.Fatalf("Read() = %q, %v, want what was left", entry.Data, err)
+	}
+	if _, err := b.Read(ctx); err != io.EOF {
+		t.Errorf("Read() error = %v, want io.EOF", err)
+	}
+
+	// Discarding releases a blocked writer and drops the buffered entries
+	b = New(Config{MemoryBytes: 1, DiskBytes: 8, Dir: t.TempDir()})
+	_ = b.Write(ctx, Entry{Data: []byte("a")})
+	_ = b.Write(ctx, Entry{Data: []byte("b")})
+	written := make(chan error, 1)
+	go func() {
+		written <- b.Write(ctx, Entry{Data: []byte("c")})
+	}()
+	b.Discard()
+	if err := <-written; !errors.Is(err, ErrClosed) {
+		t.Errorf("blocked Write() error = %v, want ErrClosed", err)
+	}
+	if _, err := b.Read(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("Read() after Discard error = %v, want ErrClosed", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	config, err := ConfigFromEnv()
+	if err != nil || config.MemoryBytes != DefaultMemoryBytes || config.DiskBytes != DefaultDiskBytes {
+		t.Errorf("ConfigFromEnv() = %+v, %v, want the defaults", config, err)
+	}
+
+	t.Setenv("OUTPUT_BUFFER_MEMORY_BYTES", "1024")
+	t.Setenv("OUTPUT_BUFFER_DISK_BYTES", "0")
+	t.Setenv("OUTPUT_BUFFER_DIR", "/var/spool/holopod")
+	config, err = ConfigFromEnv()
+	if err != nil || config != (Config{MemoryBytes: 1024, Dir: "/var/spool/holopod"}) {
+		t.Errorf("ConfigFromEnv() = %+v, %v", config, err)
+	}
+
+	t.Setenv("OUTPUT_BUFFER_MEMORY_BYTES", "lots")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("ConfigFromEnv() accepted an invalid memory cap")
+	}
+}
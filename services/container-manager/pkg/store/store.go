@@ -0,0 +1,172 @@
+// Package store keeps a record of every container the manager has run in an
+// embedded bbolt database, so container status and exit codes outlive the
+// manager process. Records are the container's status as reported by the API,
+// which already has registry credentials and URL secrets stripped.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+// ErrNotFound is returned for a container the store has no record of
+var ErrNotFound = errors.New("container record not found")
+
+var containersBucket = []byte("containers")
+
+// openTimeout bounds waiting for another process holding the database
+const openTimeout = 5 * time.Second
+
+// Store holds container records keyed by container ID
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the database at path, creating it and its directory if needed
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(containersBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize state database: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put writes the records of the given containers in one transaction,
+// replacing any earlier record of the same container
+func (s *Store) Put(records ...*pb.ContainerStatus) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(containersBucket)
+		for _, record := range records {
+			data, err := proto.Marshal(record)
+			if err != nil {
+				return fmt.Errorf("failed to encode record of container %s: %w", record.ContainerId, err)
+			}
+			if err := bucket.Put([]byte(record.ContainerId), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get returns the record of a container
+func (s *Store) Get(containerID string) (*pb.ContainerStatus, error) {
+	var record *pb.ContainerStatus
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(containersBucket).Get([]byte(containerID))
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, containerID)
+		}
+		record = &pb.ContainerStatus{}
+		return proto.Unmarshal(data, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List returns every record, ordered by container ID
+func (s *Store) List() ([]*pb.ContainerStatus, error) {
+	var records []*pb.ContainerStatus
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(containersBucket).ForEach(func(key, data []byte) error {
+			record := &pb.ContainerStatus{}
+			if err := proto.Unmarshal(data, record); err != nil {
+				return fmt.Errorf("failed to decode record of container %s: %w", key, err)
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Delete removes the record of a container, if there is one
+func (s *Store) Delete(containerID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(containersBucket).Delete([]byte(containerID))
+	})
+}
+
+// Prune removes the records of containers that finished before cutoff and
+// returns how many it removed. Records of unfinished containers are kept.
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	pruned := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(containersBucket)
+
+		var expired [][]byte
+		err := bucket.ForEach(func(key, data []byte) error {
+			record := &pb.ContainerStatus{}
+			if err := proto.Unmarshal(data, record); err != nil {
+				// An unreadable record is of no use to anyone
+				expired = append(expired, append([]byte(nil), key...))
+				return nil
+			}
+			if finishedAt, ok := unixTime(record.FinishedAt); ok && finishedAt.Before(cutoff) {
+				expired = append(expired, append([]byte(nil), key...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		pruned = len(expired)
+		return nil
+	})
+	return pruned, err
+}
+
+// unixTime parses the unix seconds timestamps of a container status
+func unixTime(value *string) (time.Time, bool) {
+	if value == nil {
+		return time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(*value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/metorial/fleet/holopod/services/container-manager/proto"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "state", "holopod.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return s, path
+}
+
+func finishedAt(t time.Time) *string {
+	return proto.String(strconv.FormatInt(t.Unix(), 10))
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	s, path := openTestStore(t)
+
+	err := s.Put(
+		&pb.ContainerStatus{ContainerId: "b", State: pb.ContainerState_RUNNING},
+		&pb.ContainerStatus{ContainerId: "a", State: pb.ContainerState_EXITED, ExitCode: proto.Int32(3)},
+	)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Records outlive the process that wrote them
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	record, err := s.Get("a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if record.State != pb.ContainerState_EXITED || record.GetExitCode() != 3 {
+		t.Errorf("Get() = %v", record)
+	}
+
+	records, err := s.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(records) != 2 || records[0].ContainerId != "a" || records[1].ContainerId != "b" {
+		t.Errorf("List() = %v, want a and b", records)
+	}
+
+	if err := s.Delete("a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	s, _ := openTestStore(t)
+	defer s.Close()
+
+	now := time.Now()
+	err := s.Put(
+		&pb.ContainerStatus{ContainerId: "old", State: pb.ContainerState_EXITED, FinishedAt: finishedAt(now.Add(-48 * time.Hour))},
+		&pb.ContainerStatus{ContainerId: "recent", State: pb.ContainerState_EXITED, FinishedAt: finishedAt(now.Add(-time.Hour))},
+		&pb.ContainerStatus{ContainerId: "running", State: pb.ContainerState_RUNNING},
+	)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	pruned, err := s.Prune(now.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("Prune() = %d, want 1", pruned)
+	}
+	if _, err := s.Get("old"); !errors.Is(err, ErrNotFound) {
+		t.Error("Prune() kept a record past the cutoff")
+	}
+	for _, id := range []string{"recent", "running"} {
+		if _, err := s.Get(id); err != nil {
+			t.Errorf("Prune() removed %s: %v", id, err)
+		}
+	}
+}
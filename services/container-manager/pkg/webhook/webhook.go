@@ -0,0 +1,323 @@
+// Package webhook notifies HTTP endpoints of container lifecycle events. Each
+// notification is POSTed as JSON, signed with the endpoint's secret if it has
+// one, and retried with backoff until the endpoint accepts it or the attempts
+// run out.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Events a webhook can be notified of
+const (
+	EventCreated = "created"
+	EventReady   = "ready"
+	EventExited  = "exited"
+	EventFailed  = "failed"
+)
+
+// Events lists every event, in the order a container goes through them
+var Events = []string{EventCreated, EventReady, EventExited, EventFailed}
+
+const (
+	// DefaultMaxAttempts bounds the deliveries of a notification to an endpoint
+	DefaultMaxAttempts = 5
+	// DefaultTimeout bounds each delivery
+	DefaultTimeout = 10 * time.Second
+	// DefaultRetryDelay is the wait before the first retry; it doubles after
+	// every failed attempt
+	DefaultRetryDelay = time.Second
+)
+
+// Config sets the endpoints notified of every container and how deliveries
+// are retried
+type Config struct {
+	// Endpoints notified of every container, as set by the operator. Unlike
+	// those of a container, they may use http and private addresses.
+	Endpoints   []Endpoint
+	MaxAttempts int
+	Timeout     time.Duration
+	RetryDelay  time.Duration
+}
+
+// ConfigFromEnv reads the comma-separated WEBHOOK_URLS, signed with
+// WEBHOOK_SECRET if set, and WEBHOOK_MAX_ATTEMPTS and WEBHOOK_TIMEOUT, falling
+// back to the defaults for unset variables
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		MaxAttempts: DefaultMaxAttempts,
+		Timeout:     DefaultTimeout,
+		RetryDelay:  DefaultRetryDelay,
+	}
+
+	for _, raw := range strings.Split(os.Getenv("WEBHOOK_URLS"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Config{}, fmt.Errorf("invalid WEBHOOK_URLS entry %q", raw)
+		}
+		config.Endpoints = append(config.Endpoints, Endpoint{
+			URL:    raw,
+			Secret: os.Getenv("WEBHOOK_SECRET"),
+		})
+	}
+
+	if value := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return Config{}, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS %q", value)
+		}
+		config.MaxAttempts = n
+	}
+	if value := os.Getenv("WEBHOOK_TIMEOUT"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return Config{}, fmt.Errorf("invalid WEBHOOK_TIMEOUT %q", value)
+		}
+		config.Timeout = d
+	}
+
+	return config, nil
+}
+
+// Endpoint is a URL to notify
+type Endpoint struct {
+	URL string
+	// Secret signs deliveries; empty sends them unsigned
+	Secret string
+	// Events to deliver; empty means all
+	Events []string
+	// Public restricts deliveries to https and public addresses, for
+	// endpoints given by clients rather than the operator
+	Public bool
+}
+
+func (e Endpoint) wants(event string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, event)
+}
+
+// ValidateURL checks that a client's webhook URL is an absolute https URL.
+// Whether it resolves to a public address is only known when it is called.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute https url")
+	}
+	return nil
+}
+
+// Notification is the JSON body POSTed to endpoints
+type Notification struct {
+	Event             string            `json:"event"`
+	ContainerID       string            `json:"container_id"`
+	Timestamp         string            `json:"timestamp"`
+	State             string            `json:"state"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	ExitCode          *int32            `json:"exit_code,omitempty"`
+	TerminationReason *string           `json:"termination_reason,omitempty"`
+	ErrorCode         *string           `json:"error_code,omitempty"`
+}
+
+// Sign returns the X-Holopod-Signature header of a body delivered at t
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// isPublic reports whether addr may be reached by a client's webhook: not
+// loopback, private, link-local, shared (100.64.0.0/10) or otherwise reserved
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() &&
+		!netip.MustParsePrefix("100.64.0.0/10").Contains(addr)
+}
+
+// publicTransport only connects to public addresses, checked after resolution
+// so a name cannot be pointed at the host's network
+var publicTransport = &http.Transport{
+	Proxy: nil,
+	DialContext: (&net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublic(addrPort.Addr()) {
+				return fmt.Errorf("webhook url resolves to non-public address %s", addrPort.Addr())
+			}
+			return nil
+		},
+	}).DialContext,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 30 * time.Second,
+}
+
+// Sender delivers notifications in the background
+type Sender struct {
+	config Config
+	client *http.Client // For the operator's endpoints
+	public *http.Client // For clients' endpoints
+	now    func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex // Guards adding to wg against Close
+	wg     sync.WaitGroup
+}
+
+// NewSender returns a sender delivering to config.Endpoints, besides those
+// passed to Send; values <= 0 use the defaults
+func NewSender(config Config) *Sender {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultRetryDelay
+	}
+
+	// Redirects are not followed: a 3xx counts as a failed delivery
+	noRedirects := func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Sender{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout, CheckRedirect: noRedirects},
+		public: &http.Client{Timeout: config.Timeout, CheckRedirect: noRedirects, Transport: publicTransport},
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Send delivers n to the sender's endpoints and to endpoints that want its
+// event. It does not wait for the deliveries, and does nothing once the sender
+// is closed.
+func (s *Sender) Send(endpoints []Endpoint, n Notification) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return
+	}
+	for _, endpoint := range slices.Concat(s.config.Endpoints, endpoints) {
+		if !endpoint.wants(n.Event) {
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if err := s.deliver(endpoint, n.Event, body); err != nil {
+				log.Printf("Failed to notify webhook of %s of container %s: %v", n.Event, n.ContainerID, err)
+			}
+		}()
+	}
+}
+
+// deliver POSTs body to endpoint until it is accepted, it is refused for good
+// or the attempts run out
+func (s *Sender) deliver(endpoint Endpoint, event string, body []byte) error {
+	client := s.client
+	if endpoint.Public {
+		if err := ValidateURL(endpoint.URL); err != nil {
+			return err
+		}
+		client = s.public
+	}
+	delivery := uuid.NewString()
+	delay := s.config.RetryDelay
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		if retry, err = s.post(client, endpoint, event, delivery, body); err == nil || !retry {
+			return err
+		}
+		if attempt == s.config.MaxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-s.ctx.Done():
+			return err
+		}
+	}
+}
+
+// post makes one delivery and reports whether a failure is worth retrying
+func (s *Sender) post(client *http.Client, endpoint Endpoint, event, delivery string, body []byte) (bool, error) {
+	// Not cancelled by Close: an attempt under way gets its timeout to finish
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook url")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Holopod-Event", event)
+	req.Header.Set("X-Holopod-Delivery", delivery)
+	if endpoint.Secret != "" {
+		req.Header.Set("X-Holopod-Signature", Sign(endpoint.Secret, s.now(), body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// SECURITY: The url, which may carry a token, stays out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return true, fmt.Errorf("webhook call failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook answered %s", resp.Status)
+}
+
+// Close abandons pending retries and waits for deliveries in flight to end
+func (s *Sender) Close() {
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
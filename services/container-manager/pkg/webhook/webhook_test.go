@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is an endpoint answering with the given statuses in turn, then 200
+type recorder struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	if len(r.statuses) > 0 {
+		w.WriteHeader(r.statuses[0])
+		r.statuses = r.statuses[1:]
+	}
+}
+
+func TestSendSignsAndRetries(t *testing.T) {
+	rec := &recorder{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	s := NewSender(Config{RetryDelay: time.Millisecond})
+	s.Send([]Endpoint{{URL: server.URL, Secret: "s3cret"}}, Notification{Event: EventExited, ContainerID: "c1", State: "exited"})
+	s.wg.Wait()
+	s.Close()
+
+	if len(rec.requests) != 3 {
+		t.Fatalf("got %d deliveries, want 3", len(rec.requests))
+	}
+	req, body := rec.requests[2], rec.bodies[2]
+	if req.Header.Get("X-Holopod-Event") != EventExited || req.Header.Get("X-Holopod-Delivery") != rec.requests[0].Header.Get("X-Holopod-Delivery") {
+		t.Errorf("headers = %v, want the event and the same delivery on every attempt", req.Header)
+	}
+
+	var n Notification
+	if err := json.Unmarshal(body, &n); err != nil || n.ContainerID != "c1" || n.Event != EventExited {
+		t.Errorf("body = %s, %v", body, err)
+	}
+
+	// The signature covers the timestamp and the body
+	timestamp, mac, ok := strings.Cut(strings.TrimPrefix(req.Header.Get("X-Holopod-Signature"), "t="), ",v1=")
+	if !ok {
+		t.Fatalf("X-Holopod-Signature = %q", req.Header.Get("X-Holopod-Signature"))
+	}
+	h := hmac.New(sha256.New, []byte("s3cret"))
+	h.Write([]byte(timestamp + "." + string(body)))
+	if mac != hex.EncodeToString(h.Sum(nil)) {
+		t.Error("signature does not match the body")
+	}
+}
+
+func TestDeliverKeepsURLOutOfErrors(t *testing.T) {
+	// Nothing listens on the closed server's address, so the call fails
+	server := httptest.NewServer(&recorder{})
+	server.Close()
+
+	s := NewSender(Config{MaxAttempts: 1})
+	defer s.Close()
+
+	err := s.deliver(Endpoint{URL: server.URL + "/ci?token=t0ken"}, EventExited, []byte("{}"))
+	if err == nil {
+		t.Fatal("deliver() to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "t0ken") || strings.Contains(err.Error(), server.URL) {
+		t.Errorf("deliver() error = %q, want the url left out", err)
+	}
+}
+
+func TestSendFilters(t *testing.T) {
+	rec := &recorder{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	s := NewSender(Config{Endpoints: []Endpoint{{URL: server.URL}}, RetryDelay: time.Millisecond})
+	s.Send([]Endpoint{{URL: server.URL, Events: []string{EventFailed}}}, Notification{Event: EventReady})
+	s.wg.Wait()
+	s.Close()
+
+	// Only the operator's endpoint wants it, refuses it and is not asked again
+	if len(rec.requests) != 1 {
+		t.Errorf("got %d deliveries, want 1", len(rec.requests))
+	}
+	if rec.requests[0].Header.Get("X-Holopod-Signature") != "" {
+		t.Error("delivery without a secret was signed")
+	}
+
+	// Nothing is sent once closed
+	s.Send(nil, Notification{Event: EventReady})
+	if len(rec.requests) != 1 {
+		t.Errorf("got %d deliveries after Close, want 1", len(rec.requests))
+	}
+}
+
+func TestPublicEndpoints(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	// A client's endpoint cannot reach the host's network
+	s := NewSender(Config{MaxAttempts: 1})
+	s.Send([]Endpoint{{URL: server.URL, Public: true}}, Notification{Event: EventCreated})
+	s.Close()
+	if len(rec.requests) != 0 {
+		t.Error("delivered to a loopback address")
+	}
+
+	for addr, want := range map[string]bool{
+		"8.8.8.8":          true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"192.168.0.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"::1":              false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		if got := isPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+
+	for raw, valid := range map[string]bool{
+		"https://hooks.example.com/ci": true,
+		"http://hooks.example.com/ci":  false,
+		"/ci":                          false,
+		"https://":                     false,
+	} {
+		if err := ValidateURL(raw); (err == nil) != valid {
+			t.Errorf("ValidateURL(%q) = %v", raw, err)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_URLS", "https://ci.example.com/hook, http://10.0.0.5/hook")
+	t.Setenv("WEBHOOK_SECRET", "s3cret")
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+	t.Setenv("WEBHOOK_TIMEOUT", "5s")
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv() error = %v", err)
+	}
+	if len(config.Endpoints) != 2 || config.Endpoints[1].URL != "http://10.0.0.5/hook" || config.Endpoints[1].Secret != "s3cret" ||
+		config.MaxAttempts != 3 || config.Timeout != 5*time.Second {
+		t.Errorf("ConfigFromEnv() = %+v", config)
+	}
+
+	t.Setenv("WEBHOOK_URLS", "ftp://example.com")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("ConfigFromEnv() accepted a non-http url")
+	}
+}
@@ -21,6 +21,110 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type OutputStream int32
+
+const (
+	OutputStream_OUTPUT_STREAM_UNSPECIFIED OutputStream = 0
+	OutputStream_STDOUT                    OutputStream = 1
+	OutputStream_STDERR                    OutputStream = 2
+	// Runner messages and structured lifecycle events
+	OutputStream_EVENTS OutputStream = 3
+)
+
+// Enum value maps for OutputStream.
+var (
+	OutputStream_name = map[int32]string{
+		0: "OUTPUT_STREAM_UNSPECIFIED",
+		1: "STDOUT",
+		2: "STDERR",
+		3: "EVENTS",
+	}
+	OutputStream_value = map[string]int32{
+		"OUTPUT_STREAM_UNSPECIFIED": 0,
+		"STDOUT":                    1,
+		"STDERR":                    2,
+		"EVENTS":                    3,
+	}
+)
+
+func (x OutputStream) Enum() *OutputStream {
+	p := new(OutputStream)
+	*p = x
+	return p
+}
+
+func (x OutputStream) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OutputStream) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_container_manager_proto_enumTypes[0].Descriptor()
+}
+
+func (OutputStream) Type() protoreflect.EnumType {
+	return &file_proto_container_manager_proto_enumTypes[0]
+}
+
+func (x OutputStream) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OutputStream.Descriptor instead.
+func (OutputStream) EnumDescriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{0}
+}
+
+type IdleAction int32
+
+const (
+	// Same as TERMINATE
+	IdleAction_IDLE_ACTION_UNSPECIFIED IdleAction = 0
+	IdleAction_TERMINATE               IdleAction = 1
+	// Pause the container; it stays until unpaused or another timeout ends it
+	IdleAction_PAUSE IdleAction = 2
+)
+
+// Enum value maps for IdleAction.
+var (
+	IdleAction_name = map[int32]string{
+		0: "IDLE_ACTION_UNSPECIFIED",
+		1: "TERMINATE",
+		2: "PAUSE",
+	}
+	IdleAction_value = map[string]int32{
+		"IDLE_ACTION_UNSPECIFIED": 0,
+		"TERMINATE":               1,
+		"PAUSE":                   2,
+	}
+)
+
+func (x IdleAction) Enum() *IdleAction {
+	p := new(IdleAction)
+	*p = x
+	return p
+}
+
+func (x IdleAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IdleAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_container_manager_proto_enumTypes[1].Descriptor()
+}
+
+func (IdleAction) Type() protoreflect.EnumType {
+	return &file_proto_container_manager_proto_enumTypes[1]
+}
+
+func (x IdleAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IdleAction.Descriptor instead.
+func (IdleAction) EnumDescriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{1}
+}
+
 type ContainerState int32
 
 const (
@@ -60,11 +164,11 @@ func (x ContainerState) String() string {
 }
 
 func (ContainerState) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_container_manager_proto_enumTypes[0].Descriptor()
+	return file_proto_container_manager_proto_enumTypes[2].Descriptor()
 }
 
 func (ContainerState) Type() protoreflect.EnumType {
-	return &file_proto_container_manager_proto_enumTypes[0]
+	return &file_proto_container_manager_proto_enumTypes[2]
 }
 
 func (x ContainerState) Number() protoreflect.EnumNumber {
@@ -73,7 +177,7 @@ func (x ContainerState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ContainerState.Descriptor instead.
 func (ContainerState) EnumDescriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{0}
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{2}
 }
 
 type RunRequest struct {
@@ -85,6 +189,14 @@ type RunRequest struct {
 	//	*RunRequest_CloseStdin
 	//	*RunRequest_Terminate
 	//	*RunRequest_Heartbeat
+	//	*RunRequest_UpdateNetworkPolicy
+	//	*RunRequest_Exec
+	//	*RunRequest_Resize
+	//	*RunRequest_Pause
+	//	*RunRequest_Unpause
+	//	*RunRequest_Signal
+	//	*RunRequest_Attach
+	//	*RunRequest_UpdateResources
 	Request       isRunRequest_Request `protobuf_oneof:"request"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -172,12 +284,84 @@ func (x *RunRequest) GetHeartbeat() bool {
 	return false
 }
 
+func (x *RunRequest) GetUpdateNetworkPolicy() *UpdateNetworkPolicy {
+	if x != nil {
+		if x, ok := x.Request.(*RunRequest_UpdateNetworkPolicy); ok {
+			return x.UpdateNetworkPolicy
+		}
+	}
+	return nil
+}
+
+func (x *RunRequest) GetExec() *ExecRequest {
+	if x != nil {
+		if x, ok := x.Request.(*RunRequest_Exec); ok {
+			return x.Exec
+		}
+	}
+	return nil
+}
+
+func (x *RunRequest) GetResize() *Resize {
+	if x != nil {
+		if x, ok := x.Request.(*RunRequest_Resize); ok {
+			return x.Resize
+		}
+	}
+	return nil
+}
+
+func (x *RunRequest) GetPause() bool {
+	if x != nil {
+		if x, ok := x.Request.(*RunRequest_Pause); ok {
+			return x.Pause
+		}
+	}
+	return false
+}
+
+func (x *RunRequest) GetUnpause() bool {
+	if x != nil {
+		if x, ok := x.Request.(*RunRequest_Unpause); ok {
+			return x.Unpause
+		}
+	}
+	return false
+}
+
+func (x *RunRequest) GetSignal() *Signal {
+	if x != nil {
+		if x, ok := x.Request.(*RunRequest_Signal); ok {
+			return x.Signal
+		}
+	}
+	return nil
+}
+
+func (x *RunRequest) GetAttach() *AttachContainer {
+	if x != nil {
+		if x, ok := x.Request.(*RunRequest_Attach); ok {
+			return x.Attach
+		}
+	}
+	return nil
+}
+
+func (x *RunRequest) GetUpdateResources() *UpdateResources {
+	if x != nil {
+		if x, ok := x.Request.(*RunRequest_UpdateResources); ok {
+			return x.UpdateResources
+		}
+	}
+	return nil
+}
+
 type isRunRequest_Request interface {
 	isRunRequest_Request()
 }
 
 type RunRequest_Create struct {
-	// MUST be sent as first message - creates and starts container
+	// MUST be sent as first message, unless attach is - creates and starts container
 	Create *CreateContainer `protobuf:"bytes,1,opt,name=create,proto3,oneof"`
 }
 
@@ -197,10 +381,61 @@ type RunRequest_Terminate struct {
 }
 
 type RunRequest_Heartbeat struct {
-	// Heartbeat - MUST be sent at least every 30 seconds or container will be terminated
+	// Heartbeat - MUST be sent within the container's heartbeat timeout (30
+	// seconds by default) of the last one or container will be terminated
 	Heartbeat bool `protobuf:"varint,5,opt,name=heartbeat,proto3,oneof"`
 }
 
+type RunRequest_UpdateNetworkPolicy struct {
+	// Replace the network policy of the running container without restarting it.
+	// The outcome arrives as a network_policy_updated or
+	// network_policy_update_failed message event.
+	UpdateNetworkPolicy *UpdateNetworkPolicy `protobuf:"bytes,6,opt,name=update_network_policy,json=updateNetworkPolicy,proto3,oneof"`
+}
+
+type RunRequest_Exec struct {
+	// Run an additional process in the running container, e.g. for debugging.
+	// Its output and exit code arrive as exec events on this stream.
+	Exec *ExecRequest `protobuf:"bytes,7,opt,name=exec,proto3,oneof"`
+}
+
+type RunRequest_Resize struct {
+	// Resize the container's terminal; only valid when it was created with tty
+	Resize *Resize `protobuf:"bytes,8,opt,name=resize,proto3,oneof"`
+}
+
+type RunRequest_Pause struct {
+	// Pause or unpause the container. The outcome arrives as a container_paused,
+	// container_resumed, container_pause_failed or container_resume_failed message event.
+	Pause bool `protobuf:"varint,9,opt,name=pause,proto3,oneof"`
+}
+
+type RunRequest_Unpause struct {
+	Unpause bool `protobuf:"varint,10,opt,name=unpause,proto3,oneof"`
+}
+
+type RunRequest_Signal struct {
+	// Send a signal to the container's main process. The outcome arrives as a
+	// container_signaled or container_signal_failed message event.
+	Signal *Signal `protobuf:"bytes,11,opt,name=signal,proto3,oneof"`
+}
+
+type RunRequest_Attach struct {
+	// MUST be sent as first message, unless create is - binds the stream to a
+	// container created by an earlier stream, replaying its output from
+	// output_offset. Stdin and every other request then apply to it as if
+	// this stream had created it.
+	Attach *AttachContainer `protobuf:"bytes,12,opt,name=attach,proto3,oneof"`
+}
+
+type RunRequest_UpdateResources struct {
+	// Change the CPU and/or memory limits of the running container without
+	// restarting it, as UpdateContainerResources. The outcome arrives as a
+	// container_resources_updated or container_resources_update_failed
+	// message event.
+	UpdateResources *UpdateResources `protobuf:"bytes,13,opt,name=update_resources,json=updateResources,proto3,oneof"`
+}
+
 func (*RunRequest_Create) isRunRequest_Request() {}
 
 func (*RunRequest_Stdin) isRunRequest_Request() {}
@@ -211,30 +446,44 @@ func (*RunRequest_Terminate) isRunRequest_Request() {}
 
 func (*RunRequest_Heartbeat) isRunRequest_Request() {}
 
-type CreateContainer struct {
+func (*RunRequest_UpdateNetworkPolicy) isRunRequest_Request() {}
+
+func (*RunRequest_Exec) isRunRequest_Request() {}
+
+func (*RunRequest_Resize) isRunRequest_Request() {}
+
+func (*RunRequest_Pause) isRunRequest_Request() {}
+
+func (*RunRequest_Unpause) isRunRequest_Request() {}
+
+func (*RunRequest_Signal) isRunRequest_Request() {}
+
+func (*RunRequest_Attach) isRunRequest_Request() {}
+
+func (*RunRequest_UpdateResources) isRunRequest_Request() {}
+
+type UpdateResources struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Unique ID for this container (if not provided, one will be generated)
-	ContainerId *string `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3,oneof" json:"container_id,omitempty"`
-	// Container configuration
-	Config        *ContainerConfig `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	// New limits; unset fields keep their current value
+	Resources     *ResourceLimits `protobuf:"bytes,1,opt,name=resources,proto3" json:"resources,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateContainer) Reset() {
-	*x = CreateContainer{}
+func (x *UpdateResources) Reset() {
+	*x = UpdateResources{}
 	mi := &file_proto_container_manager_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateContainer) String() string {
+func (x *UpdateResources) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateContainer) ProtoMessage() {}
+func (*UpdateResources) ProtoMessage() {}
 
-func (x *CreateContainer) ProtoReflect() protoreflect.Message {
+func (x *UpdateResources) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_container_manager_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -246,49 +495,40 @@ func (x *CreateContainer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateContainer.ProtoReflect.Descriptor instead.
-func (*CreateContainer) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateResources.ProtoReflect.Descriptor instead.
+func (*UpdateResources) Descriptor() ([]byte, []int) {
 	return file_proto_container_manager_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *CreateContainer) GetContainerId() string {
-	if x != nil && x.ContainerId != nil {
-		return *x.ContainerId
-	}
-	return ""
-}
-
-func (x *CreateContainer) GetConfig() *ContainerConfig {
+func (x *UpdateResources) GetResources() *ResourceLimits {
 	if x != nil {
-		return x.Config
+		return x.Resources
 	}
 	return nil
 }
 
-type TerminateContainer struct {
+type Signal struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Force kill (SIGKILL) instead of graceful termination (SIGTERM)
-	Force bool `protobuf:"varint,1,opt,name=force,proto3" json:"force,omitempty"`
-	// Timeout for graceful termination before force kill (seconds)
-	TimeoutSecs   uint32 `protobuf:"varint,2,opt,name=timeout_secs,json=timeoutSecs,proto3" json:"timeout_secs,omitempty"`
+	// Signal name such as "SIGHUP" or "USR1"
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TerminateContainer) Reset() {
-	*x = TerminateContainer{}
+func (x *Signal) Reset() {
+	*x = Signal{}
 	mi := &file_proto_container_manager_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TerminateContainer) String() string {
+func (x *Signal) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TerminateContainer) ProtoMessage() {}
+func (*Signal) ProtoMessage() {}
 
-func (x *TerminateContainer) ProtoReflect() protoreflect.Message {
+func (x *Signal) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_container_manager_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -300,56 +540,40 @@ func (x *TerminateContainer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TerminateContainer.ProtoReflect.Descriptor instead.
-func (*TerminateContainer) Descriptor() ([]byte, []int) {
+// Deprecated: Use Signal.ProtoReflect.Descriptor instead.
+func (*Signal) Descriptor() ([]byte, []int) {
 	return file_proto_container_manager_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *TerminateContainer) GetForce() bool {
-	if x != nil {
-		return x.Force
-	}
-	return false
-}
-
-func (x *TerminateContainer) GetTimeoutSecs() uint32 {
+func (x *Signal) GetName() string {
 	if x != nil {
-		return x.TimeoutSecs
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-type RunResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Container ID for this stream
-	ContainerId string `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	// Types that are valid to be assigned to Event:
-	//
-	//	*RunResponse_Created
-	//	*RunResponse_Stdout
-	//	*RunResponse_Stderr
-	//	*RunResponse_Exit
-	//	*RunResponse_Error
-	//	*RunResponse_Message
-	Event         isRunResponse_Event `protobuf_oneof:"event"`
+type Resize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          uint32                 `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols          uint32                 `protobuf:"varint,2,opt,name=cols,proto3" json:"cols,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RunResponse) Reset() {
-	*x = RunResponse{}
+func (x *Resize) Reset() {
+	*x = Resize{}
 	mi := &file_proto_container_manager_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RunResponse) String() string {
+func (x *Resize) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RunResponse) ProtoMessage() {}
+func (*Resize) ProtoMessage() {}
 
-func (x *RunResponse) ProtoReflect() protoreflect.Message {
+func (x *Resize) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_container_manager_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -361,148 +585,257 @@ func (x *RunResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RunResponse.ProtoReflect.Descriptor instead.
-func (*RunResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Resize.ProtoReflect.Descriptor instead.
+func (*Resize) Descriptor() ([]byte, []int) {
 	return file_proto_container_manager_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *RunResponse) GetContainerId() string {
+func (x *Resize) GetRows() uint32 {
 	if x != nil {
-		return x.ContainerId
+		return x.Rows
 	}
-	return ""
+	return 0
 }
 
-func (x *RunResponse) GetEvent() isRunResponse_Event {
+func (x *Resize) GetCols() uint32 {
 	if x != nil {
-		return x.Event
+		return x.Cols
 	}
-	return nil
+	return 0
 }
 
-func (x *RunResponse) GetCreated() *ContainerCreated {
+type ExecRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tags the process's exec events; generated when unset
+	ExecId *string `protobuf:"bytes,1,opt,name=exec_id,json=execId,proto3,oneof" json:"exec_id,omitempty"`
+	// Command and arguments to run (required)
+	Command []string `protobuf:"bytes,2,rep,name=command,proto3" json:"command,omitempty"`
+	// Environment variables added to the container's own
+	Env map[string]string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Working directory (defaults to the container's)
+	Workdir *string `protobuf:"bytes,4,opt,name=workdir,proto3,oneof" json:"workdir,omitempty"`
+	// Stop streaming the process after this many seconds (0 = no limit)
+	TimeoutSecs   *uint32 `protobuf:"varint,5,opt,name=timeout_secs,json=timeoutSecs,proto3,oneof" json:"timeout_secs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecRequest) Reset() {
+	*x = ExecRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecRequest) ProtoMessage() {}
+
+func (x *ExecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[4]
 	if x != nil {
-		if x, ok := x.Event.(*RunResponse_Created); ok {
-			return x.Created
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *RunResponse) GetStdout() []byte {
-	if x != nil {
-		if x, ok := x.Event.(*RunResponse_Stdout); ok {
-			return x.Stdout
-		}
+// Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
+func (*ExecRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ExecRequest) GetExecId() string {
+	if x != nil && x.ExecId != nil {
+		return *x.ExecId
 	}
-	return nil
+	return ""
 }
 
-func (x *RunResponse) GetStderr() []byte {
+func (x *ExecRequest) GetCommand() []string {
 	if x != nil {
-		if x, ok := x.Event.(*RunResponse_Stderr); ok {
-			return x.Stderr
-		}
+		return x.Command
 	}
 	return nil
 }
 
-func (x *RunResponse) GetExit() *ContainerExit {
+func (x *ExecRequest) GetEnv() map[string]string {
 	if x != nil {
-		if x, ok := x.Event.(*RunResponse_Exit); ok {
-			return x.Exit
-		}
+		return x.Env
 	}
 	return nil
 }
 
-func (x *RunResponse) GetError() string {
-	if x != nil {
-		if x, ok := x.Event.(*RunResponse_Error); ok {
-			return x.Error
-		}
+func (x *ExecRequest) GetWorkdir() string {
+	if x != nil && x.Workdir != nil {
+		return *x.Workdir
 	}
 	return ""
 }
 
-func (x *RunResponse) GetMessage() string {
-	if x != nil {
-		if x, ok := x.Event.(*RunResponse_Message); ok {
-			return x.Message
-		}
+func (x *ExecRequest) GetTimeoutSecs() uint32 {
+	if x != nil && x.TimeoutSecs != nil {
+		return *x.TimeoutSecs
 	}
-	return ""
+	return 0
 }
 
-type isRunResponse_Event interface {
-	isRunResponse_Event()
+type UpdateNetworkPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// New policy; rules, default_policy, DNS and connection limit settings are
+	// replaced. subnet, static_ip and log_network_attempts cannot change mid-run.
+	Network       *NetworkConfig `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type RunResponse_Created struct {
-	// Container was created successfully
-	Created *ContainerCreated `protobuf:"bytes,2,opt,name=created,proto3,oneof"`
+func (x *UpdateNetworkPolicy) Reset() {
+	*x = UpdateNetworkPolicy{}
+	mi := &file_proto_container_manager_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type RunResponse_Stdout struct {
-	// Stdout data from container
-	Stdout []byte `protobuf:"bytes,3,opt,name=stdout,proto3,oneof"`
+func (x *UpdateNetworkPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type RunResponse_Stderr struct {
-	// Stderr data from container
-	Stderr []byte `protobuf:"bytes,4,opt,name=stderr,proto3,oneof"`
+func (*UpdateNetworkPolicy) ProtoMessage() {}
+
+func (x *UpdateNetworkPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-type RunResponse_Exit struct {
-	// Container exited
-	Exit *ContainerExit `protobuf:"bytes,5,opt,name=exit,proto3,oneof"`
+// Deprecated: Use UpdateNetworkPolicy.ProtoReflect.Descriptor instead.
+func (*UpdateNetworkPolicy) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{5}
 }
 
-type RunResponse_Error struct {
-	// Error occurred
-	Error string `protobuf:"bytes,6,opt,name=error,proto3,oneof"`
+func (x *UpdateNetworkPolicy) GetNetwork() *NetworkConfig {
+	if x != nil {
+		return x.Network
+	}
+	return nil
 }
 
-type RunResponse_Message struct {
-	// Raw JSON message from isolation-runner (info, debug, warning, etc.)
-	Message string `protobuf:"bytes,7,opt,name=message,proto3,oneof"`
+type CreateContainer struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique ID for this container (if not provided, one will be generated)
+	ContainerId *string `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3,oneof" json:"container_id,omitempty"`
+	// Container configuration
+	Config *ContainerConfig `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	// Outputs to deliver on this stream; empty means all. Created, exit, error,
+	// capture and exec events are always delivered.
+	Streams []OutputStream `protobuf:"varint,3,rep,packed,name=streams,proto3,enum=container_manager.OutputStream" json:"streams,omitempty"`
+	// Deliver runner messages and lifecycle events as typed_event instead of
+	// JSON in message
+	TypedEvents   bool `protobuf:"varint,4,opt,name=typed_events,json=typedEvents,proto3" json:"typed_events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (*RunResponse_Created) isRunResponse_Event() {}
+func (x *CreateContainer) Reset() {
+	*x = CreateContainer{}
+	mi := &file_proto_container_manager_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-func (*RunResponse_Stdout) isRunResponse_Event() {}
+func (x *CreateContainer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func (*RunResponse_Stderr) isRunResponse_Event() {}
+func (*CreateContainer) ProtoMessage() {}
 
-func (*RunResponse_Exit) isRunResponse_Event() {}
+func (x *CreateContainer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
 
-func (*RunResponse_Error) isRunResponse_Event() {}
+// Deprecated: Use CreateContainer.ProtoReflect.Descriptor instead.
+func (*CreateContainer) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{6}
+}
 
-func (*RunResponse_Message) isRunResponse_Event() {}
+func (x *CreateContainer) GetContainerId() string {
+	if x != nil && x.ContainerId != nil {
+		return *x.ContainerId
+	}
+	return ""
+}
 
-type ContainerCreated struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	State         ContainerState         `protobuf:"varint,2,opt,name=state,proto3,enum=container_manager.ContainerState" json:"state,omitempty"`
+func (x *CreateContainer) GetConfig() *ContainerConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *CreateContainer) GetStreams() []OutputStream {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+func (x *CreateContainer) GetTypedEvents() bool {
+	if x != nil {
+		return x.TypedEvents
+	}
+	return false
+}
+
+type AttachContainer struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Where to resume the container's output: the output_offset of the last
+	// stdout or stderr event the client received, or 0 to replay all of it.
+	// Only the most recent output is kept for replay; ContainerAttached reports
+	// where replay actually starts.
+	OutputOffset uint64 `protobuf:"varint,2,opt,name=output_offset,json=outputOffset,proto3" json:"output_offset,omitempty"`
+	// Outputs to deliver on this stream; empty means all
+	Streams []OutputStream `protobuf:"varint,3,rep,packed,name=streams,proto3,enum=container_manager.OutputStream" json:"streams,omitempty"`
+	// As in CreateContainer
+	TypedEvents   bool `protobuf:"varint,4,opt,name=typed_events,json=typedEvents,proto3" json:"typed_events,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ContainerCreated) Reset() {
-	*x = ContainerCreated{}
-	mi := &file_proto_container_manager_proto_msgTypes[4]
+func (x *AttachContainer) Reset() {
+	*x = AttachContainer{}
+	mi := &file_proto_container_manager_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContainerCreated) String() string {
+func (x *AttachContainer) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerCreated) ProtoMessage() {}
+func (*AttachContainer) ProtoMessage() {}
 
-func (x *ContainerCreated) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[4]
+func (x *AttachContainer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -513,48 +846,64 @@ func (x *ContainerCreated) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerCreated.ProtoReflect.Descriptor instead.
-func (*ContainerCreated) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use AttachContainer.ProtoReflect.Descriptor instead.
+func (*AttachContainer) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ContainerCreated) GetContainerId() string {
+func (x *AttachContainer) GetContainerId() string {
 	if x != nil {
 		return x.ContainerId
 	}
 	return ""
 }
 
-func (x *ContainerCreated) GetState() ContainerState {
+func (x *AttachContainer) GetOutputOffset() uint64 {
 	if x != nil {
-		return x.State
+		return x.OutputOffset
 	}
-	return ContainerState_CREATED
+	return 0
 }
 
-type ContainerExit struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ExitCode      int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
-	Timestamp     string                 `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+func (x *AttachContainer) GetStreams() []OutputStream {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+func (x *AttachContainer) GetTypedEvents() bool {
+	if x != nil {
+		return x.TypedEvents
+	}
+	return false
+}
+
+type TerminateContainer struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Force kill (SIGKILL) instead of graceful termination (SIGTERM)
+	Force bool `protobuf:"varint,1,opt,name=force,proto3" json:"force,omitempty"`
+	// Timeout for graceful termination before force kill (seconds)
+	TimeoutSecs   uint32 `protobuf:"varint,2,opt,name=timeout_secs,json=timeoutSecs,proto3" json:"timeout_secs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ContainerExit) Reset() {
-	*x = ContainerExit{}
-	mi := &file_proto_container_manager_proto_msgTypes[5]
+func (x *TerminateContainer) Reset() {
+	*x = TerminateContainer{}
+	mi := &file_proto_container_manager_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContainerExit) String() string {
+func (x *TerminateContainer) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerExit) ProtoMessage() {}
+func (*TerminateContainer) ProtoMessage() {}
 
-func (x *ContainerExit) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[5]
+func (x *TerminateContainer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -565,67 +914,65 @@ func (x *ContainerExit) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerExit.ProtoReflect.Descriptor instead.
-func (*ContainerExit) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use TerminateContainer.ProtoReflect.Descriptor instead.
+func (*TerminateContainer) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ContainerExit) GetExitCode() int32 {
+func (x *TerminateContainer) GetForce() bool {
 	if x != nil {
-		return x.ExitCode
+		return x.Force
 	}
-	return 0
+	return false
 }
 
-func (x *ContainerExit) GetTimestamp() string {
+func (x *TerminateContainer) GetTimeoutSecs() uint32 {
 	if x != nil {
-		return x.Timestamp
+		return x.TimeoutSecs
 	}
-	return ""
+	return 0
 }
 
-type ContainerConfig struct {
+type RunResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Docker image specification with optional authentication
-	ImageSpec *ImageSpec `protobuf:"bytes,1,opt,name=image_spec,json=imageSpec,proto3" json:"image_spec,omitempty"`
-	// Command to execute in container (overrides image's default ENTRYPOINT)
-	// If not specified, uses the image's default ENTRYPOINT
-	Command []string `protobuf:"bytes,2,rep,name=command,proto3" json:"command,omitempty"`
-	// Working directory
-	Workdir *string `protobuf:"bytes,3,opt,name=workdir,proto3,oneof" json:"workdir,omitempty"`
-	// Environment variables
-	Env map[string]string `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	// Resource limits
-	Resources *ResourceLimits `protobuf:"bytes,5,opt,name=resources,proto3,oneof" json:"resources,omitempty"`
-	// Network configuration
-	Network *NetworkConfig `protobuf:"bytes,6,opt,name=network,proto3,oneof" json:"network,omitempty"`
-	// Timeout in seconds (0 = no timeout)
-	TimeoutSecs *uint32 `protobuf:"varint,7,opt,name=timeout_secs,json=timeoutSecs,proto3,oneof" json:"timeout_secs,omitempty"`
-	// Whether to cleanup container after exit
-	Cleanup *bool `protobuf:"varint,8,opt,name=cleanup,proto3,oneof" json:"cleanup,omitempty"`
-	// Arguments to pass to the command (overrides image's default CMD)
-	// If command is not specified, these are passed to the image's default ENTRYPOINT
-	// If command is specified, these are appended as arguments to that command
-	Args          []string `protobuf:"bytes,9,rep,name=args,proto3" json:"args,omitempty"`
+	// Container ID for this stream
+	ContainerId string `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*RunResponse_Created
+	//	*RunResponse_Stdout
+	//	*RunResponse_Stderr
+	//	*RunResponse_Exit
+	//	*RunResponse_Error
+	//	*RunResponse_Message
+	//	*RunResponse_Capture
+	//	*RunResponse_Exec
+	//	*RunResponse_Attached
+	//	*RunResponse_TypedEvent
+	Event isRunResponse_Event `protobuf_oneof:"event"`
+	// On stdout and stderr events, the offset just past this chunk in the
+	// container's output, counting stdout and stderr together. Pass the last
+	// one received to RunRequest.attach to resume after it.
+	OutputOffset  uint64 `protobuf:"varint,11,opt,name=output_offset,json=outputOffset,proto3" json:"output_offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ContainerConfig) Reset() {
-	*x = ContainerConfig{}
-	mi := &file_proto_container_manager_proto_msgTypes[6]
+func (x *RunResponse) Reset() {
+	*x = RunResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContainerConfig) String() string {
+func (x *RunResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerConfig) ProtoMessage() {}
+func (*RunResponse) ProtoMessage() {}
 
-func (x *ContainerConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[6]
+func (x *RunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -636,108 +983,4423 @@ func (x *ContainerConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerConfig.ProtoReflect.Descriptor instead.
-func (*ContainerConfig) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use RunResponse.ProtoReflect.Descriptor instead.
+func (*RunResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *ContainerConfig) GetImageSpec() *ImageSpec {
+func (x *RunResponse) GetContainerId() string {
 	if x != nil {
-		return x.ImageSpec
+		return x.ContainerId
 	}
-	return nil
+	return ""
 }
 
-func (x *ContainerConfig) GetCommand() []string {
+func (x *RunResponse) GetEvent() isRunResponse_Event {
 	if x != nil {
-		return x.Command
+		return x.Event
 	}
 	return nil
 }
 
-func (x *ContainerConfig) GetWorkdir() string {
-	if x != nil && x.Workdir != nil {
-		return *x.Workdir
-	}
-	return ""
-}
-
-func (x *ContainerConfig) GetEnv() map[string]string {
+func (x *RunResponse) GetCreated() *ContainerCreated {
 	if x != nil {
-		return x.Env
+		if x, ok := x.Event.(*RunResponse_Created); ok {
+			return x.Created
+		}
 	}
 	return nil
 }
 
-func (x *ContainerConfig) GetResources() *ResourceLimits {
+func (x *RunResponse) GetStdout() []byte {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_Stdout); ok {
+			return x.Stdout
+		}
+	}
+	return nil
+}
+
+func (x *RunResponse) GetStderr() []byte {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_Stderr); ok {
+			return x.Stderr
+		}
+	}
+	return nil
+}
+
+func (x *RunResponse) GetExit() *ContainerExit {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_Exit); ok {
+			return x.Exit
+		}
+	}
+	return nil
+}
+
+func (x *RunResponse) GetError() string {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+func (x *RunResponse) GetMessage() string {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_Message); ok {
+			return x.Message
+		}
+	}
+	return ""
+}
+
+func (x *RunResponse) GetCapture() *CaptureChunk {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_Capture); ok {
+			return x.Capture
+		}
+	}
+	return nil
+}
+
+func (x *RunResponse) GetExec() *ExecOutput {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_Exec); ok {
+			return x.Exec
+		}
+	}
+	return nil
+}
+
+func (x *RunResponse) GetAttached() *ContainerAttached {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_Attached); ok {
+			return x.Attached
+		}
+	}
+	return nil
+}
+
+func (x *RunResponse) GetTypedEvent() *Event {
+	if x != nil {
+		if x, ok := x.Event.(*RunResponse_TypedEvent); ok {
+			return x.TypedEvent
+		}
+	}
+	return nil
+}
+
+func (x *RunResponse) GetOutputOffset() uint64 {
+	if x != nil {
+		return x.OutputOffset
+	}
+	return 0
+}
+
+type isRunResponse_Event interface {
+	isRunResponse_Event()
+}
+
+type RunResponse_Created struct {
+	// Container was created successfully
+	Created *ContainerCreated `protobuf:"bytes,2,opt,name=created,proto3,oneof"`
+}
+
+type RunResponse_Stdout struct {
+	// Stdout data from container
+	Stdout []byte `protobuf:"bytes,3,opt,name=stdout,proto3,oneof"`
+}
+
+type RunResponse_Stderr struct {
+	// Stderr data from container
+	Stderr []byte `protobuf:"bytes,4,opt,name=stderr,proto3,oneof"`
+}
+
+type RunResponse_Exit struct {
+	// Container exited
+	Exit *ContainerExit `protobuf:"bytes,5,opt,name=exit,proto3,oneof"`
+}
+
+type RunResponse_Error struct {
+	// Error occurred
+	Error string `protobuf:"bytes,6,opt,name=error,proto3,oneof"`
+}
+
+type RunResponse_Message struct {
+	// Raw JSON message from isolation-runner (info, debug, warning, etc.).
+	// Its seq increases by one with every message the runner writes, so a
+	// gap means messages were dropped on the way, e.g. to a slow subscriber.
+	Message string `protobuf:"bytes,7,opt,name=message,proto3,oneof"`
+}
+
+type RunResponse_Capture struct {
+	// Packet capture data (see StartCapture)
+	Capture *CaptureChunk `protobuf:"bytes,8,opt,name=capture,proto3,oneof"`
+}
+
+type RunResponse_Exec struct {
+	// Output of a process started with RunRequest.exec
+	Exec *ExecOutput `protobuf:"bytes,9,opt,name=exec,proto3,oneof"`
+}
+
+type RunResponse_Attached struct {
+	// The stream was bound to the container named in RunRequest.attach
+	Attached *ContainerAttached `protobuf:"bytes,10,opt,name=attached,proto3,oneof"`
+}
+
+type RunResponse_TypedEvent struct {
+	// A runner message or lifecycle event, on streams that asked for
+	// typed_events instead of message
+	TypedEvent *Event `protobuf:"bytes,12,opt,name=typed_event,json=typedEvent,proto3,oneof"`
+}
+
+func (*RunResponse_Created) isRunResponse_Event() {}
+
+func (*RunResponse_Stdout) isRunResponse_Event() {}
+
+func (*RunResponse_Stderr) isRunResponse_Event() {}
+
+func (*RunResponse_Exit) isRunResponse_Event() {}
+
+func (*RunResponse_Error) isRunResponse_Event() {}
+
+func (*RunResponse_Message) isRunResponse_Event() {}
+
+func (*RunResponse_Capture) isRunResponse_Event() {}
+
+func (*RunResponse_Exec) isRunResponse_Event() {}
+
+func (*RunResponse_Attached) isRunResponse_Event() {}
+
+func (*RunResponse_TypedEvent) isRunResponse_Event() {}
+
+type ExecOutput struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ExecId string                 `protobuf:"bytes,1,opt,name=exec_id,json=execId,proto3" json:"exec_id,omitempty"`
+	// Output chunks; at most one of stdout and stderr is set per event
+	Stdout []byte `protobuf:"bytes,2,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr []byte `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	// Set on the final event of a process, with its exit code
+	Exited   bool  `protobuf:"varint,4,opt,name=exited,proto3" json:"exited,omitempty"`
+	ExitCode int32 `protobuf:"varint,5,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	// Why the process could not be run or followed to exit (only on the final
+	// event; exit_code is then -1)
+	Error         *string `protobuf:"bytes,6,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecOutput) Reset() {
+	*x = ExecOutput{}
+	mi := &file_proto_container_manager_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecOutput) ProtoMessage() {}
+
+func (x *ExecOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecOutput.ProtoReflect.Descriptor instead.
+func (*ExecOutput) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExecOutput) GetExecId() string {
+	if x != nil {
+		return x.ExecId
+	}
+	return ""
+}
+
+func (x *ExecOutput) GetStdout() []byte {
+	if x != nil {
+		return x.Stdout
+	}
+	return nil
+}
+
+func (x *ExecOutput) GetStderr() []byte {
+	if x != nil {
+		return x.Stderr
+	}
+	return nil
+}
+
+func (x *ExecOutput) GetExited() bool {
+	if x != nil {
+		return x.Exited
+	}
+	return false
+}
+
+func (x *ExecOutput) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ExecOutput) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+type CaptureChunk struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	CaptureId string                 `protobuf:"bytes,1,opt,name=capture_id,json=captureId,proto3" json:"capture_id,omitempty"`
+	// Raw pcap stream bytes; concatenating all chunks of a capture yields a pcap file
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// Set on the final chunk of a capture
+	Done bool `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+	// Why the capture failed, if it did (only on the final chunk)
+	Error         *string `protobuf:"bytes,4,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureChunk) Reset() {
+	*x = CaptureChunk{}
+	mi := &file_proto_container_manager_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureChunk) ProtoMessage() {}
+
+func (x *CaptureChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureChunk.ProtoReflect.Descriptor instead.
+func (*CaptureChunk) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CaptureChunk) GetCaptureId() string {
+	if x != nil {
+		return x.CaptureId
+	}
+	return ""
+}
+
+func (x *CaptureChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CaptureChunk) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *CaptureChunk) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+type ContainerCreated struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	State       ContainerState         `protobuf:"varint,2,opt,name=state,proto3,enum=container_manager.ContainerState" json:"state,omitempty"`
+	// How long the stream may go without a heartbeat before the container is
+	// terminated; 0 when it needs none. Sending one every third of it leaves
+	// room for delays.
+	HeartbeatTimeoutSecs uint32 `protobuf:"varint,3,opt,name=heartbeat_timeout_secs,json=heartbeatTimeoutSecs,proto3" json:"heartbeat_timeout_secs,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ContainerCreated) Reset() {
+	*x = ContainerCreated{}
+	mi := &file_proto_container_manager_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerCreated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerCreated) ProtoMessage() {}
+
+func (x *ContainerCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerCreated.ProtoReflect.Descriptor instead.
+func (*ContainerCreated) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ContainerCreated) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerCreated) GetState() ContainerState {
+	if x != nil {
+		return x.State
+	}
+	return ContainerState_CREATED
+}
+
+func (x *ContainerCreated) GetHeartbeatTimeoutSecs() uint32 {
+	if x != nil {
+		return x.HeartbeatTimeoutSecs
+	}
+	return 0
+}
+
+type ContainerAttached struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	State       ContainerState         `protobuf:"varint,2,opt,name=state,proto3,enum=container_manager.ContainerState" json:"state,omitempty"`
+	// Where replay of the container's output starts. It is past the requested
+	// offset when that output is no longer kept, and before it when the
+	// container's output offsets restarted, as they do when a restarted manager
+	// reattaches to the container.
+	OutputOffset uint64 `protobuf:"varint,3,opt,name=output_offset,json=outputOffset,proto3" json:"output_offset,omitempty"`
+	// As in ContainerCreated
+	HeartbeatTimeoutSecs uint32 `protobuf:"varint,4,opt,name=heartbeat_timeout_secs,json=heartbeatTimeoutSecs,proto3" json:"heartbeat_timeout_secs,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ContainerAttached) Reset() {
+	*x = ContainerAttached{}
+	mi := &file_proto_container_manager_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerAttached) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerAttached) ProtoMessage() {}
+
+func (x *ContainerAttached) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerAttached.ProtoReflect.Descriptor instead.
+func (*ContainerAttached) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ContainerAttached) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerAttached) GetState() ContainerState {
+	if x != nil {
+		return x.State
+	}
+	return ContainerState_CREATED
+}
+
+func (x *ContainerAttached) GetOutputOffset() uint64 {
+	if x != nil {
+		return x.OutputOffset
+	}
+	return 0
+}
+
+func (x *ContainerAttached) GetHeartbeatTimeoutSecs() uint32 {
+	if x != nil {
+		return x.HeartbeatTimeoutSecs
+	}
+	return 0
+}
+
+type ContainerExit struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ExitCode  int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Timestamp string                 `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Why the manager ended the container, e.g. "cpu_time_limit_exceeded";
+	// unset when it exited on its own or the client terminated it
+	TerminationReason *string `protobuf:"bytes,3,opt,name=termination_reason,json=terminationReason,proto3,oneof" json:"termination_reason,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ContainerExit) Reset() {
+	*x = ContainerExit{}
+	mi := &file_proto_container_manager_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerExit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerExit) ProtoMessage() {}
+
+func (x *ContainerExit) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerExit.ProtoReflect.Descriptor instead.
+func (*ContainerExit) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ContainerExit) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ContainerExit) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *ContainerExit) GetTerminationReason() string {
+	if x != nil && x.TerminationReason != nil {
+		return *x.TerminationReason
+	}
+	return ""
+}
+
+// A runner message or lifecycle event, as carried in RunResponse.message but
+// parsed. Events of types without a payload of their own carry their data as
+// JSON in data_json.
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The message type, e.g. "container_ready"
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// The runner's sequence number, as on message; 0 on events raised by the
+	// manager itself
+	Seq uint64 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	// RFC 3339 time the event was raised
+	Timestamp string `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_Log
+	//	*Event_ImagePullStarted
+	//	*Event_ImagePullCompleted
+	//	*Event_ContainerStarted
+	//	*Event_ContainerIpReady
+	//	*Event_NetworkIsolationReady
+	//	*Event_ContainerPortReady
+	//	*Event_ContainerReady
+	//	*Event_NetworkAttempt
+	//	*Event_ContainerTerminating
+	//	*Event_ContainerExited
+	//	*Event_Stats
+	//	*Event_Lifecycle
+	//	*Event_ContainerIdleTimeout
+	//	*Event_RunnerHello
+	//	*Event_RunnerHeartbeat
+	//	*Event_DataJson
+	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_proto_container_manager_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Event) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *Event) GetPayload() isEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetLog() *LogMessage {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_Log); ok {
+			return x.Log
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetImagePullStarted() *ImagePullStarted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ImagePullStarted); ok {
+			return x.ImagePullStarted
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetImagePullCompleted() *ImagePullCompleted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ImagePullCompleted); ok {
+			return x.ImagePullCompleted
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetContainerStarted() *ContainerStarted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ContainerStarted); ok {
+			return x.ContainerStarted
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetContainerIpReady() *ContainerIPReady {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ContainerIpReady); ok {
+			return x.ContainerIpReady
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetNetworkIsolationReady() *NetworkIsolationReady {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_NetworkIsolationReady); ok {
+			return x.NetworkIsolationReady
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetContainerPortReady() *ContainerPortReady {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ContainerPortReady); ok {
+			return x.ContainerPortReady
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetContainerReady() *ContainerReady {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ContainerReady); ok {
+			return x.ContainerReady
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetNetworkAttempt() *NetworkAttempt {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_NetworkAttempt); ok {
+			return x.NetworkAttempt
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetContainerTerminating() *ContainerTerminating {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ContainerTerminating); ok {
+			return x.ContainerTerminating
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetContainerExited() *ContainerExited {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ContainerExited); ok {
+			return x.ContainerExited
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetStats() *ResourceStats {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_Stats); ok {
+			return x.Stats
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetLifecycle() *LifecycleTransition {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_Lifecycle); ok {
+			return x.Lifecycle
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetContainerIdleTimeout() *ContainerIdleTimeout {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ContainerIdleTimeout); ok {
+			return x.ContainerIdleTimeout
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRunnerHello() *RunnerHello {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_RunnerHello); ok {
+			return x.RunnerHello
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRunnerHeartbeat() *RunnerHeartbeat {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_RunnerHeartbeat); ok {
+			return x.RunnerHeartbeat
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetDataJson() string {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_DataJson); ok {
+			return x.DataJson
+		}
+	}
+	return ""
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_Log struct {
+	// debug, info, warning and error
+	Log *LogMessage `protobuf:"bytes,10,opt,name=log,proto3,oneof"`
+}
+
+type Event_ImagePullStarted struct {
+	ImagePullStarted *ImagePullStarted `protobuf:"bytes,11,opt,name=image_pull_started,json=imagePullStarted,proto3,oneof"`
+}
+
+type Event_ImagePullCompleted struct {
+	ImagePullCompleted *ImagePullCompleted `protobuf:"bytes,12,opt,name=image_pull_completed,json=imagePullCompleted,proto3,oneof"`
+}
+
+type Event_ContainerStarted struct {
+	ContainerStarted *ContainerStarted `protobuf:"bytes,13,opt,name=container_started,json=containerStarted,proto3,oneof"`
+}
+
+type Event_ContainerIpReady struct {
+	ContainerIpReady *ContainerIPReady `protobuf:"bytes,14,opt,name=container_ip_ready,json=containerIpReady,proto3,oneof"`
+}
+
+type Event_NetworkIsolationReady struct {
+	NetworkIsolationReady *NetworkIsolationReady `protobuf:"bytes,15,opt,name=network_isolation_ready,json=networkIsolationReady,proto3,oneof"`
+}
+
+type Event_ContainerPortReady struct {
+	ContainerPortReady *ContainerPortReady `protobuf:"bytes,16,opt,name=container_port_ready,json=containerPortReady,proto3,oneof"`
+}
+
+type Event_ContainerReady struct {
+	ContainerReady *ContainerReady `protobuf:"bytes,17,opt,name=container_ready,json=containerReady,proto3,oneof"`
+}
+
+type Event_NetworkAttempt struct {
+	NetworkAttempt *NetworkAttempt `protobuf:"bytes,18,opt,name=network_attempt,json=networkAttempt,proto3,oneof"`
+}
+
+type Event_ContainerTerminating struct {
+	ContainerTerminating *ContainerTerminating `protobuf:"bytes,19,opt,name=container_terminating,json=containerTerminating,proto3,oneof"`
+}
+
+type Event_ContainerExited struct {
+	ContainerExited *ContainerExited `protobuf:"bytes,20,opt,name=container_exited,json=containerExited,proto3,oneof"`
+}
+
+type Event_Stats struct {
+	// container_stats, sampled periodically while the container runs
+	Stats *ResourceStats `protobuf:"bytes,21,opt,name=stats,proto3,oneof"`
+}
+
+type Event_Lifecycle struct {
+	// container_lifecycle, a transition of the manager's lifecycle state
+	Lifecycle *LifecycleTransition `protobuf:"bytes,22,opt,name=lifecycle,proto3,oneof"`
+}
+
+type Event_ContainerIdleTimeout struct {
+	ContainerIdleTimeout *ContainerIdleTimeout `protobuf:"bytes,23,opt,name=container_idle_timeout,json=containerIdleTimeout,proto3,oneof"`
+}
+
+type Event_RunnerHello struct {
+	RunnerHello *RunnerHello `protobuf:"bytes,24,opt,name=runner_hello,json=runnerHello,proto3,oneof"`
+}
+
+type Event_RunnerHeartbeat struct {
+	RunnerHeartbeat *RunnerHeartbeat `protobuf:"bytes,25,opt,name=runner_heartbeat,json=runnerHeartbeat,proto3,oneof"`
+}
+
+type Event_DataJson struct {
+	DataJson string `protobuf:"bytes,30,opt,name=data_json,json=dataJson,proto3,oneof"`
+}
+
+func (*Event_Log) isEvent_Payload() {}
+
+func (*Event_ImagePullStarted) isEvent_Payload() {}
+
+func (*Event_ImagePullCompleted) isEvent_Payload() {}
+
+func (*Event_ContainerStarted) isEvent_Payload() {}
+
+func (*Event_ContainerIpReady) isEvent_Payload() {}
+
+func (*Event_NetworkIsolationReady) isEvent_Payload() {}
+
+func (*Event_ContainerPortReady) isEvent_Payload() {}
+
+func (*Event_ContainerReady) isEvent_Payload() {}
+
+func (*Event_NetworkAttempt) isEvent_Payload() {}
+
+func (*Event_ContainerTerminating) isEvent_Payload() {}
+
+func (*Event_ContainerExited) isEvent_Payload() {}
+
+func (*Event_Stats) isEvent_Payload() {}
+
+func (*Event_Lifecycle) isEvent_Payload() {}
+
+func (*Event_ContainerIdleTimeout) isEvent_Payload() {}
+
+func (*Event_RunnerHello) isEvent_Payload() {}
+
+func (*Event_RunnerHeartbeat) isEvent_Payload() {}
+
+func (*Event_DataJson) isEvent_Payload() {}
+
+type LogMessage struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// The machine-readable cause of an error, if known
+	ErrorCode     *string `protobuf:"bytes,2,opt,name=error_code,json=errorCode,proto3,oneof" json:"error_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogMessage) Reset() {
+	*x = LogMessage{}
+	mi := &file_proto_container_manager_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogMessage) ProtoMessage() {}
+
+func (x *LogMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogMessage.ProtoReflect.Descriptor instead.
+func (*LogMessage) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LogMessage) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogMessage) GetErrorCode() string {
+	if x != nil && x.ErrorCode != nil {
+		return *x.ErrorCode
+	}
+	return ""
+}
+
+type ImagePullStarted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Image         string                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Registry      string                 `protobuf:"bytes,2,opt,name=registry,proto3" json:"registry,omitempty"`
+	Authenticated bool                   `protobuf:"varint,3,opt,name=authenticated,proto3" json:"authenticated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImagePullStarted) Reset() {
+	*x = ImagePullStarted{}
+	mi := &file_proto_container_manager_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImagePullStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImagePullStarted) ProtoMessage() {}
+
+func (x *ImagePullStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImagePullStarted.ProtoReflect.Descriptor instead.
+func (*ImagePullStarted) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ImagePullStarted) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ImagePullStarted) GetRegistry() string {
+	if x != nil {
+		return x.Registry
+	}
+	return ""
+}
+
+func (x *ImagePullStarted) GetAuthenticated() bool {
+	if x != nil {
+		return x.Authenticated
+	}
+	return false
+}
+
+type ImagePullCompleted struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Image    string                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Registry string                 `protobuf:"bytes,2,opt,name=registry,proto3" json:"registry,omitempty"`
+	// The image was already on the node and was not pulled
+	AlreadyPresent bool `protobuf:"varint,3,opt,name=already_present,json=alreadyPresent,proto3" json:"already_present,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ImagePullCompleted) Reset() {
+	*x = ImagePullCompleted{}
+	mi := &file_proto_container_manager_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImagePullCompleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImagePullCompleted) ProtoMessage() {}
+
+func (x *ImagePullCompleted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImagePullCompleted.ProtoReflect.Descriptor instead.
+func (*ImagePullCompleted) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ImagePullCompleted) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ImagePullCompleted) GetRegistry() string {
+	if x != nil {
+		return x.Registry
+	}
+	return ""
+}
+
+func (x *ImagePullCompleted) GetAlreadyPresent() bool {
+	if x != nil {
+		return x.AlreadyPresent
+	}
+	return false
+}
+
+type ContainerStarted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerName string                 `protobuf:"bytes,1,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	Pid           int64                  `protobuf:"varint,2,opt,name=pid,proto3" json:"pid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerStarted) Reset() {
+	*x = ContainerStarted{}
+	mi := &file_proto_container_manager_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerStarted) ProtoMessage() {}
+
+func (x *ContainerStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerStarted.ProtoReflect.Descriptor instead.
+func (*ContainerStarted) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ContainerStarted) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *ContainerStarted) GetPid() int64 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+type ContainerIPReady struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IpAddress     string                 `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Network       string                 `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerIPReady) Reset() {
+	*x = ContainerIPReady{}
+	mi := &file_proto_container_manager_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerIPReady) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerIPReady) ProtoMessage() {}
+
+func (x *ContainerIPReady) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerIPReady.ProtoReflect.Descriptor instead.
+func (*ContainerIPReady) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ContainerIPReady) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *ContainerIPReady) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+type NetworkIsolationReady struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainName     string                 `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	DefaultPolicy string                 `protobuf:"bytes,2,opt,name=default_policy,json=defaultPolicy,proto3" json:"default_policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkIsolationReady) Reset() {
+	*x = NetworkIsolationReady{}
+	mi := &file_proto_container_manager_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkIsolationReady) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkIsolationReady) ProtoMessage() {}
+
+func (x *NetworkIsolationReady) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkIsolationReady.ProtoReflect.Descriptor instead.
+func (*NetworkIsolationReady) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *NetworkIsolationReady) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *NetworkIsolationReady) GetDefaultPolicy() string {
+	if x != nil {
+		return x.DefaultPolicy
+	}
+	return ""
+}
+
+type ContainerPortReady struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerPort uint32                 `protobuf:"varint,1,opt,name=container_port,json=containerPort,proto3" json:"container_port,omitempty"`
+	HostPort      uint32                 `protobuf:"varint,2,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerPortReady) Reset() {
+	*x = ContainerPortReady{}
+	mi := &file_proto_container_manager_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerPortReady) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerPortReady) ProtoMessage() {}
+
+func (x *ContainerPortReady) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerPortReady.ProtoReflect.Descriptor instead.
+func (*ContainerPortReady) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ContainerPortReady) GetContainerPort() uint32 {
+	if x != nil {
+		return x.ContainerPort
+	}
+	return 0
+}
+
+func (x *ContainerPortReady) GetHostPort() uint32 {
+	if x != nil {
+		return x.HostPort
+	}
+	return 0
+}
+
+func (x *ContainerPortReady) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+// The container has started, its network is configured and its readiness
+// probe, if any, has passed
+type ContainerReady struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IpAddress     string                 `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerReady) Reset() {
+	*x = ContainerReady{}
+	mi := &file_proto_container_manager_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerReady) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerReady) ProtoMessage() {}
+
+func (x *ContainerReady) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerReady.ProtoReflect.Descriptor instead.
+func (*ContainerReady) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ContainerReady) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+type NetworkAttempt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "allow" or "deny"
+	Verdict       string `protobuf:"bytes,1,opt,name=verdict,proto3" json:"verdict,omitempty"`
+	Protocol      string `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Src           string `protobuf:"bytes,3,opt,name=src,proto3" json:"src,omitempty"`
+	Dst           string `protobuf:"bytes,4,opt,name=dst,proto3" json:"dst,omitempty"`
+	DstPort       uint32 `protobuf:"varint,5,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkAttempt) Reset() {
+	*x = NetworkAttempt{}
+	mi := &file_proto_container_manager_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkAttempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkAttempt) ProtoMessage() {}
+
+func (x *NetworkAttempt) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkAttempt.ProtoReflect.Descriptor instead.
+func (*NetworkAttempt) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *NetworkAttempt) GetVerdict() string {
+	if x != nil {
+		return x.Verdict
+	}
+	return ""
+}
+
+func (x *NetworkAttempt) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *NetworkAttempt) GetSrc() string {
+	if x != nil {
+		return x.Src
+	}
+	return ""
+}
+
+func (x *NetworkAttempt) GetDst() string {
+	if x != nil {
+		return x.Dst
+	}
+	return ""
+}
+
+func (x *NetworkAttempt) GetDstPort() uint32 {
+	if x != nil {
+		return x.DstPort
+	}
+	return 0
+}
+
+type ContainerTerminating struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	Force         bool                   `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerTerminating) Reset() {
+	*x = ContainerTerminating{}
+	mi := &file_proto_container_manager_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerTerminating) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerTerminating) ProtoMessage() {}
+
+func (x *ContainerTerminating) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerTerminating.ProtoReflect.Descriptor instead.
+func (*ContainerTerminating) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ContainerTerminating) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ContainerTerminating) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type ContainerExited struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ExitCode int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	// How long the container ran, as a Go duration
+	Duration string `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	// The machine-readable cause of a failed exit, if known
+	ErrorCode     *string `protobuf:"bytes,3,opt,name=error_code,json=errorCode,proto3,oneof" json:"error_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerExited) Reset() {
+	*x = ContainerExited{}
+	mi := &file_proto_container_manager_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerExited) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerExited) ProtoMessage() {}
+
+func (x *ContainerExited) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerExited.ProtoReflect.Descriptor instead.
+func (*ContainerExited) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ContainerExited) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ContainerExited) GetDuration() string {
+	if x != nil {
+		return x.Duration
+	}
+	return ""
+}
+
+func (x *ContainerExited) GetErrorCode() string {
+	if x != nil && x.ErrorCode != nil {
+		return *x.ErrorCode
+	}
+	return ""
+}
+
+type ResourceStats struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	CpuPercent       float64                `protobuf:"fixed64,1,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	CpuTimeSecs      float64                `protobuf:"fixed64,2,opt,name=cpu_time_secs,json=cpuTimeSecs,proto3" json:"cpu_time_secs,omitempty"`
+	MemoryUsageBytes uint64                 `protobuf:"varint,3,opt,name=memory_usage_bytes,json=memoryUsageBytes,proto3" json:"memory_usage_bytes,omitempty"`
+	MemoryLimitBytes uint64                 `protobuf:"varint,4,opt,name=memory_limit_bytes,json=memoryLimitBytes,proto3" json:"memory_limit_bytes,omitempty"`
+	NetRxBytes       uint64                 `protobuf:"varint,5,opt,name=net_rx_bytes,json=netRxBytes,proto3" json:"net_rx_bytes,omitempty"`
+	NetTxBytes       uint64                 `protobuf:"varint,6,opt,name=net_tx_bytes,json=netTxBytes,proto3" json:"net_tx_bytes,omitempty"`
+	BlockReadBytes   uint64                 `protobuf:"varint,7,opt,name=block_read_bytes,json=blockReadBytes,proto3" json:"block_read_bytes,omitempty"`
+	BlockWriteBytes  uint64                 `protobuf:"varint,8,opt,name=block_write_bytes,json=blockWriteBytes,proto3" json:"block_write_bytes,omitempty"`
+	Pids             uint64                 `protobuf:"varint,9,opt,name=pids,proto3" json:"pids,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ResourceStats) Reset() {
+	*x = ResourceStats{}
+	mi := &file_proto_container_manager_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceStats) ProtoMessage() {}
+
+func (x *ResourceStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceStats.ProtoReflect.Descriptor instead.
+func (*ResourceStats) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ResourceStats) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *ResourceStats) GetCpuTimeSecs() float64 {
+	if x != nil {
+		return x.CpuTimeSecs
+	}
+	return 0
+}
+
+func (x *ResourceStats) GetMemoryUsageBytes() uint64 {
+	if x != nil {
+		return x.MemoryUsageBytes
+	}
+	return 0
+}
+
+func (x *ResourceStats) GetMemoryLimitBytes() uint64 {
+	if x != nil {
+		return x.MemoryLimitBytes
+	}
+	return 0
+}
+
+func (x *ResourceStats) GetNetRxBytes() uint64 {
+	if x != nil {
+		return x.NetRxBytes
+	}
+	return 0
+}
+
+func (x *ResourceStats) GetNetTxBytes() uint64 {
+	if x != nil {
+		return x.NetTxBytes
+	}
+	return 0
+}
+
+func (x *ResourceStats) GetBlockReadBytes() uint64 {
+	if x != nil {
+		return x.BlockReadBytes
+	}
+	return 0
+}
+
+func (x *ResourceStats) GetBlockWriteBytes() uint64 {
+	if x != nil {
+		return x.BlockWriteBytes
+	}
+	return 0
+}
+
+func (x *ResourceStats) GetPids() uint64 {
+	if x != nil {
+		return x.Pids
+	}
+	return 0
+}
+
+type LifecycleTransition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LifecycleTransition) Reset() {
+	*x = LifecycleTransition{}
+	mi := &file_proto_container_manager_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LifecycleTransition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LifecycleTransition) ProtoMessage() {}
+
+func (x *LifecycleTransition) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LifecycleTransition.ProtoReflect.Descriptor instead.
+func (*LifecycleTransition) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *LifecycleTransition) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *LifecycleTransition) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *LifecycleTransition) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ContainerIdleTimeout struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What was done about it, e.g. "pause" or "terminate"
+	Action        string `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerIdleTimeout) Reset() {
+	*x = ContainerIdleTimeout{}
+	mi := &file_proto_container_manager_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerIdleTimeout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerIdleTimeout) ProtoMessage() {}
+
+func (x *ContainerIdleTimeout) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerIdleTimeout.ProtoReflect.Descriptor instead.
+func (*ContainerIdleTimeout) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ContainerIdleTimeout) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type RunnerHello struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Version string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Config schema versions the runner accepts
+	SchemaVersions []uint32 `protobuf:"varint,2,rep,packed,name=schema_versions,json=schemaVersions,proto3" json:"schema_versions,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RunnerHello) Reset() {
+	*x = RunnerHello{}
+	mi := &file_proto_container_manager_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunnerHello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunnerHello) ProtoMessage() {}
+
+func (x *RunnerHello) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunnerHello.ProtoReflect.Descriptor instead.
+func (*RunnerHello) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RunnerHello) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RunnerHello) GetSchemaVersions() []uint32 {
+	if x != nil {
+		return x.SchemaVersions
+	}
+	return nil
+}
+
+type RunnerHeartbeat struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What the runner is doing, e.g. "pulling" or "running"
+	Phase         string `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	UptimeSecs    int64  `protobuf:"varint,2,opt,name=uptime_secs,json=uptimeSecs,proto3" json:"uptime_secs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunnerHeartbeat) Reset() {
+	*x = RunnerHeartbeat{}
+	mi := &file_proto_container_manager_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunnerHeartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunnerHeartbeat) ProtoMessage() {}
+
+func (x *RunnerHeartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunnerHeartbeat.ProtoReflect.Descriptor instead.
+func (*RunnerHeartbeat) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RunnerHeartbeat) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *RunnerHeartbeat) GetUptimeSecs() int64 {
+	if x != nil {
+		return x.UptimeSecs
+	}
+	return 0
+}
+
+type ContainerConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Docker image specification with optional authentication
+	ImageSpec *ImageSpec `protobuf:"bytes,1,opt,name=image_spec,json=imageSpec,proto3" json:"image_spec,omitempty"`
+	// Command to execute in container (overrides image's default ENTRYPOINT)
+	// If not specified, uses the image's default ENTRYPOINT
+	Command []string `protobuf:"bytes,2,rep,name=command,proto3" json:"command,omitempty"`
+	// Working directory
+	Workdir *string `protobuf:"bytes,3,opt,name=workdir,proto3,oneof" json:"workdir,omitempty"`
+	// Environment variables
+	Env map[string]string `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Resource limits
+	Resources *ResourceLimits `protobuf:"bytes,5,opt,name=resources,proto3,oneof" json:"resources,omitempty"`
+	// Network configuration
+	Network *NetworkConfig `protobuf:"bytes,6,opt,name=network,proto3,oneof" json:"network,omitempty"`
+	// Timeout in seconds (0 = no timeout); ExtendTimeout can push it back
+	TimeoutSecs *uint32 `protobuf:"varint,7,opt,name=timeout_secs,json=timeoutSecs,proto3,oneof" json:"timeout_secs,omitempty"`
+	// Whether to cleanup container after exit
+	Cleanup *bool `protobuf:"varint,8,opt,name=cleanup,proto3,oneof" json:"cleanup,omitempty"`
+	// Arguments to pass to the command (overrides image's default CMD)
+	// If command is not specified, these are passed to the image's default ENTRYPOINT
+	// If command is specified, these are appended as arguments to that command
+	Args []string `protobuf:"bytes,9,rep,name=args,proto3" json:"args,omitempty"`
+	// Container ports to publish on the host; each is reported in a
+	// container_port_ready event once reachable
+	Ports []*PortMapping `protobuf:"bytes,10,rep,name=ports,proto3" json:"ports,omitempty"`
+	// Files extracted into the container before its command starts
+	Workspace *Workspace `protobuf:"bytes,11,opt,name=workspace,proto3,oneof" json:"workspace,omitempty"`
+	// Run the command on a pseudo-terminal. Its output, stdout and stderr
+	// merged, arrives as stdout; size it with Resize on the Run stream.
+	Tty *bool `protobuf:"varint,12,opt,name=tty,proto3,oneof" json:"tty,omitempty"`
+	// Experimental: resume from a checkpoint saved by Checkpoint instead of
+	// starting the command afresh. The image and runtime must match the
+	// checkpointed container's.
+	RestoreFrom *string `protobuf:"bytes,13,opt,name=restore_from,json=restoreFrom,proto3,oneof" json:"restore_from,omitempty"`
+	// How often a container_stats event reports resource usage (default 10,
+	// 0 disables them); the latest sample is also kept in io_stats
+	StatsIntervalSecs *uint32 `protobuf:"varint,14,opt,name=stats_interval_secs,json=statsIntervalSecs,proto3,oneof" json:"stats_interval_secs,omitempty"`
+	// Hold back the container_ready event until the workload passes this probe
+	ReadinessProbe *ReadinessProbe `protobuf:"bytes,15,opt,name=readiness_probe,json=readinessProbe,proto3,oneof" json:"readiness_probe,omitempty"`
+	// Act on the container once it has had no stdin or output traffic and
+	// negligible CPU use for this long (default: the manager's
+	// CONTAINER_IDLE_TIMEOUT; 0 disables). A container_idle_timeout event
+	// reports it. CPU use is sampled every stats_interval_secs.
+	IdleTimeoutSecs *uint32     `protobuf:"varint,16,opt,name=idle_timeout_secs,json=idleTimeoutSecs,proto3,oneof" json:"idle_timeout_secs,omitempty"`
+	IdleAction      *IdleAction `protobuf:"varint,17,opt,name=idle_action,json=idleAction,proto3,enum=container_manager.IdleAction,oneof" json:"idle_action,omitempty"`
+	// Terminate the container, with termination_reason
+	// "cpu_time_limit_exceeded", once it has used this much CPU time in total,
+	// however generous timeout_secs is. Usage is sampled every
+	// stats_interval_secs (at the default interval if that is 0), so it may
+	// overshoot by up to one interval's worth.
+	CpuTimeLimitSecs *uint32 `protobuf:"varint,18,opt,name=cpu_time_limit_secs,json=cpuTimeLimitSecs,proto3,oneof" json:"cpu_time_limit_secs,omitempty"`
+	// OCI runtime to run the container with, e.g. "runsc" (default),
+	// "runsc-kvm", "runc" or "kata". It must be on the manager's
+	// HOLOPOD_ALLOWED_RUNTIMES list and registered with the Docker daemon.
+	Runtime *string `protobuf:"bytes,19,opt,name=runtime,proto3,oneof" json:"runtime,omitempty"`
+	// gVisor flags for this container alone. Only valid with a runsc runtime,
+	// and each flag set must be on the manager's HOLOPOD_ALLOWED_RUNSC_FLAGS list.
+	RuntimeOptions *RuntimeOptions `protobuf:"bytes,20,opt,name=runtime_options,json=runtimeOptions,proto3,oneof" json:"runtime_options,omitempty"`
+	// Auxiliary containers, e.g. a database or mock API, run in this
+	// container's network namespace: they reach each other over localhost and
+	// share its network policy. They start once its network is isolated and are
+	// removed when it exits. Their sidecar_started, sidecar_output and
+	// sidecar_exited message events name the sidecar they come from.
+	Sidecars []*AuxContainer `protobuf:"bytes,21,rep,name=sidecars,proto3" json:"sidecars,omitempty"`
+	// Containers run one after another before this one starts, e.g. to run
+	// migrations or fill the workspace, which they share. Each runs on this
+	// container's network under its policy and must exit 0, or the container
+	// fails without starting. They count towards the startup timeout. Their
+	// init_container_started, init_container_output and init_container_exited
+	// message events name the init container they come from.
+	InitContainers []*AuxContainer `protobuf:"bytes,22,rep,name=init_containers,json=initContainers,proto3" json:"init_containers,omitempty"`
+	// Run one after another once the workload has exited and before its
+	// resources are cleaned up, e.g. to collect diagnostics. Each reports
+	// hook_started, hook_completed and, for containers, hook_output message
+	// events; a failed hook does not change the container's exit code.
+	PostExitHooks []*PostExitHook `protobuf:"bytes,23,rep,name=post_exit_hooks,json=postExitHooks,proto3" json:"post_exit_hooks,omitempty"`
+	// Free-form metadata set on the Docker container and returned by
+	// ListContainers, which can filter on it. Keys are up to 63 letters, digits
+	// and "_./-"; the runner's own tracking labels are reserved.
+	Labels map[string]string `protobuf:"bytes,24,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Environment variables whose values the manager looks up in its secrets
+	// backend just before handing the config to the isolation-runner, so the
+	// plaintext never passes through the API or comes back in status output.
+	// A name may not also be set in env.
+	SecretEnv map[string]*SecretRef `protobuf:"bytes,25,rep,name=secret_env,json=secretEnv,proto3" json:"secret_env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Emit only structured events and container output: the isolation-runner's
+	// human-oriented debug, info, warning and error messages are dropped. A
+	// failure is still reported by the error_code of container_exited.
+	QuietOutput bool `protobuf:"varint,26,opt,name=quiet_output,json=quietOutput,proto3" json:"quiet_output,omitempty"`
+	// Keep the container running when its Run stream ends without terminating
+	// it, for fire-and-forget jobs. A detached container needs no heartbeats
+	// and runs until it exits or hits its run timeout or the maximum lifetime.
+	Detached bool `protobuf:"varint,27,opt,name=detached,proto3" json:"detached,omitempty"`
+	// How long a container that is not detached keeps running after its Run
+	// stream drops, for the client to come back, before it is terminated with
+	// termination_reason "client_disconnected". Unset or 0 terminates it at once.
+	DisconnectGraceSecs *uint32 `protobuf:"varint,28,opt,name=disconnect_grace_secs,json=disconnectGraceSecs,proto3,oneof" json:"disconnect_grace_secs,omitempty"`
+	// How long the Run stream may go without a heartbeat before the container
+	// is terminated with termination_reason "heartbeat_timeout", for clients
+	// that legitimately go quiet for longer. Unset uses the manager's
+	// HEARTBEAT_TIMEOUT (30 seconds by default); 0 requires no heartbeats, as
+	// does detached.
+	HeartbeatTimeoutSecs *uint32 `protobuf:"varint,30,opt,name=heartbeat_timeout_secs,json=heartbeatTimeoutSecs,proto3,oneof" json:"heartbeat_timeout_secs,omitempty"`
+	// Notified of this container's lifecycle, besides the manager's own
+	// WEBHOOK_URLS
+	Webhooks      []*Webhook `protobuf:"bytes,29,rep,name=webhooks,proto3" json:"webhooks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerConfig) Reset() {
+	*x = ContainerConfig{}
+	mi := &file_proto_container_manager_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerConfig) ProtoMessage() {}
+
+func (x *ContainerConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerConfig.ProtoReflect.Descriptor instead.
+func (*ContainerConfig) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ContainerConfig) GetImageSpec() *ImageSpec {
+	if x != nil {
+		return x.ImageSpec
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetWorkdir() string {
+	if x != nil && x.Workdir != nil {
+		return *x.Workdir
+	}
+	return ""
+}
+
+func (x *ContainerConfig) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetResources() *ResourceLimits {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetNetwork() *NetworkConfig {
+	if x != nil {
+		return x.Network
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetTimeoutSecs() uint32 {
+	if x != nil && x.TimeoutSecs != nil {
+		return *x.TimeoutSecs
+	}
+	return 0
+}
+
+func (x *ContainerConfig) GetCleanup() bool {
+	if x != nil && x.Cleanup != nil {
+		return *x.Cleanup
+	}
+	return false
+}
+
+func (x *ContainerConfig) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetPorts() []*PortMapping {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetWorkspace() *Workspace {
+	if x != nil {
+		return x.Workspace
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetTty() bool {
+	if x != nil && x.Tty != nil {
+		return *x.Tty
+	}
+	return false
+}
+
+func (x *ContainerConfig) GetRestoreFrom() string {
+	if x != nil && x.RestoreFrom != nil {
+		return *x.RestoreFrom
+	}
+	return ""
+}
+
+func (x *ContainerConfig) GetStatsIntervalSecs() uint32 {
+	if x != nil && x.StatsIntervalSecs != nil {
+		return *x.StatsIntervalSecs
+	}
+	return 0
+}
+
+func (x *ContainerConfig) GetReadinessProbe() *ReadinessProbe {
+	if x != nil {
+		return x.ReadinessProbe
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetIdleTimeoutSecs() uint32 {
+	if x != nil && x.IdleTimeoutSecs != nil {
+		return *x.IdleTimeoutSecs
+	}
+	return 0
+}
+
+func (x *ContainerConfig) GetIdleAction() IdleAction {
+	if x != nil && x.IdleAction != nil {
+		return *x.IdleAction
+	}
+	return IdleAction_IDLE_ACTION_UNSPECIFIED
+}
+
+func (x *ContainerConfig) GetCpuTimeLimitSecs() uint32 {
+	if x != nil && x.CpuTimeLimitSecs != nil {
+		return *x.CpuTimeLimitSecs
+	}
+	return 0
+}
+
+func (x *ContainerConfig) GetRuntime() string {
+	if x != nil && x.Runtime != nil {
+		return *x.Runtime
+	}
+	return ""
+}
+
+func (x *ContainerConfig) GetRuntimeOptions() *RuntimeOptions {
+	if x != nil {
+		return x.RuntimeOptions
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetSidecars() []*AuxContainer {
+	if x != nil {
+		return x.Sidecars
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetInitContainers() []*AuxContainer {
+	if x != nil {
+		return x.InitContainers
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetPostExitHooks() []*PostExitHook {
+	if x != nil {
+		return x.PostExitHooks
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetSecretEnv() map[string]*SecretRef {
+	if x != nil {
+		return x.SecretEnv
+	}
+	return nil
+}
+
+func (x *ContainerConfig) GetQuietOutput() bool {
+	if x != nil {
+		return x.QuietOutput
+	}
+	return false
+}
+
+func (x *ContainerConfig) GetDetached() bool {
+	if x != nil {
+		return x.Detached
+	}
+	return false
+}
+
+func (x *ContainerConfig) GetDisconnectGraceSecs() uint32 {
+	if x != nil && x.DisconnectGraceSecs != nil {
+		return *x.DisconnectGraceSecs
+	}
+	return 0
+}
+
+func (x *ContainerConfig) GetHeartbeatTimeoutSecs() uint32 {
+	if x != nil && x.HeartbeatTimeoutSecs != nil {
+		return *x.HeartbeatTimeoutSecs
+	}
+	return 0
+}
+
+func (x *ContainerConfig) GetWebhooks() []*Webhook {
+	if x != nil {
+		return x.Webhooks
+	}
+	return nil
+}
+
+// Webhook is an endpoint the manager POSTs a JSON notification to when the
+// container is created, becomes ready, exits or fails. Deliveries are retried
+// with backoff on network errors, 429 and 5xx responses.
+type Webhook struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Must be https and resolve to a public address. Its query is left out of
+	// status output and is not kept across manager restarts; use secret to
+	// authenticate deliveries instead.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Signs each delivery: the X-Holopod-Signature header is
+	// "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">"
+	Secret *SecretRef `protobuf:"bytes,2,opt,name=secret,proto3,oneof" json:"secret,omitempty"`
+	// Events to deliver, of created, ready, exited and failed; empty means all
+	Events        []string `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Webhook) Reset() {
+	*x = Webhook{}
+	mi := &file_proto_container_manager_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Webhook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Webhook) ProtoMessage() {}
+
+func (x *Webhook) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Webhook.ProtoReflect.Descriptor instead.
+func (*Webhook) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Webhook) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Webhook) GetSecret() *SecretRef {
+	if x != nil {
+		return x.Secret
+	}
+	return nil
+}
+
+func (x *Webhook) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// SecretRef names a secret in the operator's secrets backend: a file under
+// the secrets directory, a prefixed environment variable of the manager, or a
+// Vault KV path
+type SecretRef struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Field within a Vault secret (default "value"); unused by other backends
+	Key           *string `protobuf:"bytes,2,opt,name=key,proto3,oneof" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecretRef) Reset() {
+	*x = SecretRef{}
+	mi := &file_proto_container_manager_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecretRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecretRef) ProtoMessage() {}
+
+func (x *SecretRef) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecretRef.ProtoReflect.Descriptor instead.
+func (*SecretRef) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SecretRef) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SecretRef) GetKey() string {
+	if x != nil && x.Key != nil {
+		return *x.Key
+	}
+	return ""
+}
+
+// Set exactly one of container and webhook_url
+type PostExitHook struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique among the container's hooks: lowercase letters, digits and dashes
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Run in a helper container sharing the container's workspace and network
+	// policy. Its name is ignored; the hook's is used.
+	Container *AuxContainer `protobuf:"bytes,2,opt,name=container,proto3,oneof" json:"container,omitempty"`
+	// POSTed a JSON object with the hook, container_id, container_name,
+	// exit_code and duration of the workload. Must be https and resolve to a
+	// public address; any 2xx response counts as success.
+	WebhookUrl *string `protobuf:"bytes,3,opt,name=webhook_url,json=webhookUrl,proto3,oneof" json:"webhook_url,omitempty"`
+	// Seconds the hook may take (default 30, max 600)
+	TimeoutSecs   *uint32 `protobuf:"varint,4,opt,name=timeout_secs,json=timeoutSecs,proto3,oneof" json:"timeout_secs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PostExitHook) Reset() {
+	*x = PostExitHook{}
+	mi := &file_proto_container_manager_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PostExitHook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostExitHook) ProtoMessage() {}
+
+func (x *PostExitHook) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostExitHook.ProtoReflect.Descriptor instead.
+func (*PostExitHook) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *PostExitHook) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PostExitHook) GetContainer() *AuxContainer {
+	if x != nil {
+		return x.Container
+	}
+	return nil
+}
+
+func (x *PostExitHook) GetWebhookUrl() string {
+	if x != nil && x.WebhookUrl != nil {
+		return *x.WebhookUrl
+	}
+	return ""
+}
+
+func (x *PostExitHook) GetTimeoutSecs() uint32 {
+	if x != nil && x.TimeoutSecs != nil {
+		return *x.TimeoutSecs
+	}
+	return 0
+}
+
+// A sidecar or init container
+type AuxContainer struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique among the container's sidecars, or its init containers: lowercase
+	// letters, digits and dashes, at most 32 characters
+	Name          string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ImageSpec     *ImageSpec        `protobuf:"bytes,2,opt,name=image_spec,json=imageSpec,proto3" json:"image_spec,omitempty"`
+	Command       []string          `protobuf:"bytes,3,rep,name=command,proto3" json:"command,omitempty"`
+	Args          []string          `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty"`
+	Env           map[string]string `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Resources     *ResourceLimits   `protobuf:"bytes,6,opt,name=resources,proto3,oneof" json:"resources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuxContainer) Reset() {
+	*x = AuxContainer{}
+	mi := &file_proto_container_manager_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuxContainer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuxContainer) ProtoMessage() {}
+
+func (x *AuxContainer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuxContainer.ProtoReflect.Descriptor instead.
+func (*AuxContainer) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *AuxContainer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AuxContainer) GetImageSpec() *ImageSpec {
+	if x != nil {
+		return x.ImageSpec
+	}
+	return nil
+}
+
+func (x *AuxContainer) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *AuxContainer) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *AuxContainer) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *AuxContainer) GetResources() *ResourceLimits {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+// Per-container runsc flags, applied as dev.gvisor.flag annotations. The
+// runtime must be registered with --allow-flag-override for them to apply.
+type RuntimeOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// runsc --platform: "systrap", "ptrace" or "kvm"
+	Platform *string `protobuf:"bytes,1,opt,name=platform,proto3,oneof" json:"platform,omitempty"`
+	// runsc --network: "sandbox" (gVisor netstack) or "host" (host kernel stack)
+	Network *string `protobuf:"bytes,2,opt,name=network,proto3,oneof" json:"network,omitempty"`
+	// runsc --directfs: let the sandbox open host files directly
+	Directfs      *bool `protobuf:"varint,3,opt,name=directfs,proto3,oneof" json:"directfs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuntimeOptions) Reset() {
+	*x = RuntimeOptions{}
+	mi := &file_proto_container_manager_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuntimeOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuntimeOptions) ProtoMessage() {}
+
+func (x *RuntimeOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuntimeOptions.ProtoReflect.Descriptor instead.
+func (*RuntimeOptions) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RuntimeOptions) GetPlatform() string {
+	if x != nil && x.Platform != nil {
+		return *x.Platform
+	}
+	return ""
+}
+
+func (x *RuntimeOptions) GetNetwork() string {
+	if x != nil && x.Network != nil {
+		return *x.Network
+	}
+	return ""
+}
+
+func (x *RuntimeOptions) GetDirectfs() bool {
+	if x != nil && x.Directfs != nil {
+		return *x.Directfs
+	}
+	return false
+}
+
+// Checks that the workload is ready. Set command, or port alone for a TCP
+// connect, or port and http_path for an HTTP GET that must answer 2xx or 3xx.
+// The probe is retried until it passes or the container exits.
+type ReadinessProbe struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Port     *uint32                `protobuf:"varint,1,opt,name=port,proto3,oneof" json:"port,omitempty"`
+	HttpPath *string                `protobuf:"bytes,2,opt,name=http_path,json=httpPath,proto3,oneof" json:"http_path,omitempty"`
+	// Run in the container; ready once it exits 0
+	Command []string `protobuf:"bytes,3,rep,name=command,proto3" json:"command,omitempty"`
+	// Seconds before the first attempt (default 0)
+	InitialDelaySecs *uint32 `protobuf:"varint,4,opt,name=initial_delay_secs,json=initialDelaySecs,proto3,oneof" json:"initial_delay_secs,omitempty"`
+	// Seconds between attempts (default 1)
+	PeriodSecs *uint32 `protobuf:"varint,5,opt,name=period_secs,json=periodSecs,proto3,oneof" json:"period_secs,omitempty"`
+	// Seconds each attempt may take (default 1)
+	TimeoutSecs   *uint32 `protobuf:"varint,6,opt,name=timeout_secs,json=timeoutSecs,proto3,oneof" json:"timeout_secs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadinessProbe) Reset() {
+	*x = ReadinessProbe{}
+	mi := &file_proto_container_manager_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadinessProbe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadinessProbe) ProtoMessage() {}
+
+func (x *ReadinessProbe) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadinessProbe.ProtoReflect.Descriptor instead.
+func (*ReadinessProbe) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ReadinessProbe) GetPort() uint32 {
+	if x != nil && x.Port != nil {
+		return *x.Port
+	}
+	return 0
+}
+
+func (x *ReadinessProbe) GetHttpPath() string {
+	if x != nil && x.HttpPath != nil {
+		return *x.HttpPath
+	}
+	return ""
+}
+
+func (x *ReadinessProbe) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *ReadinessProbe) GetInitialDelaySecs() uint32 {
+	if x != nil && x.InitialDelaySecs != nil {
+		return *x.InitialDelaySecs
+	}
+	return 0
+}
+
+func (x *ReadinessProbe) GetPeriodSecs() uint32 {
+	if x != nil && x.PeriodSecs != nil {
+		return *x.PeriodSecs
+	}
+	return 0
+}
+
+func (x *ReadinessProbe) GetTimeoutSecs() uint32 {
+	if x != nil && x.TimeoutSecs != nil {
+		return *x.TimeoutSecs
+	}
+	return 0
+}
+
+// A gzipped tar archive seeding a directory of the container, so per-run code
+// does not need its own image. Set exactly one of archive and url.
+type Workspace struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The archive itself; limited by the gRPC message size
+	Archive []byte `protobuf:"bytes,1,opt,name=archive,proto3" json:"archive,omitempty"`
+	// https URL to fetch the archive from instead, e.g. a presigned object-store URL
+	Url *string `protobuf:"bytes,2,opt,name=url,proto3,oneof" json:"url,omitempty"`
+	// Directory the archive is extracted into (default /workspace)
+	Path *string `protobuf:"bytes,3,opt,name=path,proto3,oneof" json:"path,omitempty"`
+	// "volume" (default) keeps the files on disk, "tmpfs" in memory
+	Mount         *string `protobuf:"bytes,4,opt,name=mount,proto3,oneof" json:"mount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Workspace) Reset() {
+	*x = Workspace{}
+	mi := &file_proto_container_manager_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Workspace) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Workspace) ProtoMessage() {}
+
+func (x *Workspace) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Workspace.ProtoReflect.Descriptor instead.
+func (*Workspace) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *Workspace) GetArchive() []byte {
+	if x != nil {
+		return x.Archive
+	}
+	return nil
+}
+
+func (x *Workspace) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *Workspace) GetPath() string {
+	if x != nil && x.Path != nil {
+		return *x.Path
+	}
+	return ""
+}
+
+func (x *Workspace) GetMount() string {
+	if x != nil && x.Mount != nil {
+		return *x.Mount
+	}
+	return ""
+}
+
+type PortMapping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerPort uint32                 `protobuf:"varint,1,opt,name=container_port,json=containerPort,proto3" json:"container_port,omitempty"`
+	// Fixed host port (must be within the bastion's exposed range); unset picks a free one
+	HostPort *uint32 `protobuf:"varint,2,opt,name=host_port,json=hostPort,proto3,oneof" json:"host_port,omitempty"`
+	// "tcp" (default) or "udp"
+	Protocol      *string `protobuf:"bytes,3,opt,name=protocol,proto3,oneof" json:"protocol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortMapping) Reset() {
+	*x = PortMapping{}
+	mi := &file_proto_container_manager_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortMapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortMapping) ProtoMessage() {}
+
+func (x *PortMapping) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortMapping.ProtoReflect.Descriptor instead.
+func (*PortMapping) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PortMapping) GetContainerPort() uint32 {
+	if x != nil {
+		return x.ContainerPort
+	}
+	return 0
+}
+
+func (x *PortMapping) GetHostPort() uint32 {
+	if x != nil && x.HostPort != nil {
+		return *x.HostPort
+	}
+	return 0
+}
+
+func (x *PortMapping) GetProtocol() string {
+	if x != nil && x.Protocol != nil {
+		return *x.Protocol
+	}
+	return ""
+}
+
+// Image specification with registry and authentication
+type ImageSpec struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Registry hostname (defaults to "registry-1.docker.io" if not provided)
+	// Examples: "gcr.io", "ghcr.io", "my-registry.local:5000"
+	Registry *string `protobuf:"bytes,1,opt,name=registry,proto3,oneof" json:"registry,omitempty"`
+	// Image name with tag or digest (without registry prefix)
+	// Examples: "library/nginx:latest", "myorg/app@sha256:abc123..."
+	Image string `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
+	// Optional authentication credentials
+	//
+	// Types that are valid to be assigned to Auth:
+	//
+	//	*ImageSpec_BasicAuth
+	Auth          isImageSpec_Auth `protobuf_oneof:"auth"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageSpec) Reset() {
+	*x = ImageSpec{}
+	mi := &file_proto_container_manager_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageSpec) ProtoMessage() {}
+
+func (x *ImageSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageSpec.ProtoReflect.Descriptor instead.
+func (*ImageSpec) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ImageSpec) GetRegistry() string {
+	if x != nil && x.Registry != nil {
+		return *x.Registry
+	}
+	return ""
+}
+
+func (x *ImageSpec) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ImageSpec) GetAuth() isImageSpec_Auth {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+func (x *ImageSpec) GetBasicAuth() *BasicAuth {
+	if x != nil {
+		if x, ok := x.Auth.(*ImageSpec_BasicAuth); ok {
+			return x.BasicAuth
+		}
+	}
+	return nil
+}
+
+type isImageSpec_Auth interface {
+	isImageSpec_Auth()
+}
+
+type ImageSpec_BasicAuth struct {
+	BasicAuth *BasicAuth `protobuf:"bytes,3,opt,name=basic_auth,json=basicAuth,proto3,oneof"`
+}
+
+func (*ImageSpec_BasicAuth) isImageSpec_Auth() {}
+
+// Basic authentication for private registries
+type BasicAuth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BasicAuth) Reset() {
+	*x = BasicAuth{}
+	mi := &file_proto_container_manager_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BasicAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BasicAuth) ProtoMessage() {}
+
+func (x *BasicAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BasicAuth.ProtoReflect.Descriptor instead.
+func (*BasicAuth) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *BasicAuth) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *BasicAuth) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type ResourceLimits struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CPU limit (e.g., "1.0" for 1 CPU)
+	CpuLimit *string `protobuf:"bytes,1,opt,name=cpu_limit,json=cpuLimit,proto3,oneof" json:"cpu_limit,omitempty"`
+	// Memory limit (e.g., "512m", "1g")
+	MemoryLimit   *string `protobuf:"bytes,2,opt,name=memory_limit,json=memoryLimit,proto3,oneof" json:"memory_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceLimits) Reset() {
+	*x = ResourceLimits{}
+	mi := &file_proto_container_manager_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceLimits) ProtoMessage() {}
+
+func (x *ResourceLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceLimits.ProtoReflect.Descriptor instead.
+func (*ResourceLimits) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ResourceLimits) GetCpuLimit() string {
+	if x != nil && x.CpuLimit != nil {
+		return *x.CpuLimit
+	}
+	return ""
+}
+
+func (x *ResourceLimits) GetMemoryLimit() string {
+	if x != nil && x.MemoryLimit != nil {
+		return *x.MemoryLimit
+	}
+	return ""
+}
+
+type NetworkConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Network policy rules
+	Rules []*NetworkRule `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	// Default policy (allow/deny)
+	DefaultPolicy *string `protobuf:"bytes,2,opt,name=default_policy,json=defaultPolicy,proto3,oneof" json:"default_policy,omitempty"`
+	// Custom DNS servers
+	DnsServers []string `protobuf:"bytes,3,rep,name=dns_servers,json=dnsServers,proto3" json:"dns_servers,omitempty"`
+	// Maximum concurrent outbound TCP connections (unset = unlimited)
+	MaxConnections *uint32 `protobuf:"varint,4,opt,name=max_connections,json=maxConnections,proto3,oneof" json:"max_connections,omitempty"`
+	// Emit network_attempt events for outbound connections and their verdict
+	LogNetworkAttempts *bool `protobuf:"varint,5,opt,name=log_network_attempts,json=logNetworkAttempts,proto3,oneof" json:"log_network_attempts,omitempty"`
+	// Only resolve these domains ("example.com" or "*.example.com"); enables DNS
+	DnsAllowedDomains []string `protobuf:"bytes,6,rep,name=dns_allowed_domains,json=dnsAllowedDomains,proto3" json:"dns_allowed_domains,omitempty"`
+	// Subnet (CIDR) of the pool network to attach to; used with static_ip
+	Subnet *string `protobuf:"bytes,7,opt,name=subnet,proto3,oneof" json:"subnet,omitempty"`
+	// Fixed IPv4 address for the container. It must lie inside subnet, or inside
+	// its own /24 when subnet is unset. Requires the bastion network pool.
+	StaticIp *string `protobuf:"bytes,8,opt,name=static_ip,json=staticIp,proto3,oneof" json:"static_ip,omitempty"`
+	// Containers sharing a pod group may reach each other; all other
+	// cross-container traffic stays blocked. Requires the bastion.
+	PodGroup      *string `protobuf:"bytes,9,opt,name=pod_group,json=podGroup,proto3,oneof" json:"pod_group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkConfig) Reset() {
+	*x = NetworkConfig{}
+	mi := &file_proto_container_manager_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkConfig) ProtoMessage() {}
+
+func (x *NetworkConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkConfig.ProtoReflect.Descriptor instead.
+func (*NetworkConfig) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *NetworkConfig) GetRules() []*NetworkRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *NetworkConfig) GetDefaultPolicy() string {
+	if x != nil && x.DefaultPolicy != nil {
+		return *x.DefaultPolicy
+	}
+	return ""
+}
+
+func (x *NetworkConfig) GetDnsServers() []string {
+	if x != nil {
+		return x.DnsServers
+	}
+	return nil
+}
+
+func (x *NetworkConfig) GetMaxConnections() uint32 {
+	if x != nil && x.MaxConnections != nil {
+		return *x.MaxConnections
+	}
+	return 0
+}
+
+func (x *NetworkConfig) GetLogNetworkAttempts() bool {
+	if x != nil && x.LogNetworkAttempts != nil {
+		return *x.LogNetworkAttempts
+	}
+	return false
+}
+
+func (x *NetworkConfig) GetDnsAllowedDomains() []string {
+	if x != nil {
+		return x.DnsAllowedDomains
+	}
+	return nil
+}
+
+func (x *NetworkConfig) GetSubnet() string {
+	if x != nil && x.Subnet != nil {
+		return *x.Subnet
+	}
+	return ""
+}
+
+func (x *NetworkConfig) GetStaticIp() string {
+	if x != nil && x.StaticIp != nil {
+		return *x.StaticIp
+	}
+	return ""
+}
+
+func (x *NetworkConfig) GetPodGroup() string {
+	if x != nil && x.PodGroup != nil {
+		return *x.PodGroup
+	}
+	return ""
+}
+
+type NetworkRule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Rule type (allow/deny)
+	Action string `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	// Protocol (tcp/udp/icmp)
+	Protocol *string `protobuf:"bytes,2,opt,name=protocol,proto3,oneof" json:"protocol,omitempty"`
+	// Destination CIDR
+	Destination *string `protobuf:"bytes,3,opt,name=destination,proto3,oneof" json:"destination,omitempty"`
+	// Port range start (inclusive)
+	PortRangeStart *uint32 `protobuf:"varint,4,opt,name=port_range_start,json=portRangeStart,proto3,oneof" json:"port_range_start,omitempty"`
+	// Port range end (inclusive)
+	PortRangeEnd  *uint32 `protobuf:"varint,5,opt,name=port_range_end,json=portRangeEnd,proto3,oneof" json:"port_range_end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkRule) Reset() {
+	*x = NetworkRule{}
+	mi := &file_proto_container_manager_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkRule) ProtoMessage() {}
+
+func (x *NetworkRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkRule.ProtoReflect.Descriptor instead.
+func (*NetworkRule) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *NetworkRule) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *NetworkRule) GetProtocol() string {
+	if x != nil && x.Protocol != nil {
+		return *x.Protocol
+	}
+	return ""
+}
+
+func (x *NetworkRule) GetDestination() string {
+	if x != nil && x.Destination != nil {
+		return *x.Destination
+	}
+	return ""
+}
+
+func (x *NetworkRule) GetPortRangeStart() uint32 {
+	if x != nil && x.PortRangeStart != nil {
+		return *x.PortRangeStart
+	}
+	return 0
+}
+
+func (x *NetworkRule) GetPortRangeEnd() uint32 {
+	if x != nil && x.PortRangeEnd != nil {
+		return *x.PortRangeEnd
+	}
+	return 0
+}
+
+type ListContainersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filter by state (running, exited, all)
+	Filter *string `protobuf:"bytes,1,opt,name=filter,proto3,oneof" json:"filter,omitempty"`
+	// Only containers carrying all of these labels
+	Labels map[string]string `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Only containers whose labels match every comma-separated requirement:
+	// "key=value", "key!=value", "key" (has the label) or "!key" (lacks it)
+	LabelSelector *string `protobuf:"bytes,3,opt,name=label_selector,json=labelSelector,proto3,oneof" json:"label_selector,omitempty"`
+	// Only containers of this tenant, i.e. whose "tenant" label has this value
+	Tenant *string `protobuf:"bytes,4,opt,name=tenant,proto3,oneof" json:"tenant,omitempty"`
+	// Only containers of this image, as shown in ContainerInfo.image or as
+	// named in its image spec, with or without a tag
+	Image *string `protobuf:"bytes,5,opt,name=image,proto3,oneof" json:"image,omitempty"`
+	// Only containers that exited with this code
+	ExitCode *int32 `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	// "created_at" (the default) or "state", in the order of ContainerState;
+	// ties are broken by created_at, then container_id
+	SortBy *string `protobuf:"bytes,7,opt,name=sort_by,json=sortBy,proto3,oneof" json:"sort_by,omitempty"`
+	// Sort newest first, or in reverse state order
+	Descending bool `protobuf:"varint,8,opt,name=descending,proto3" json:"descending,omitempty"`
+	// At most this many containers, up to 1000; unset or 0 returns all of them
+	PageSize *uint32 `protobuf:"varint,9,opt,name=page_size,json=pageSize,proto3,oneof" json:"page_size,omitempty"`
+	// The next_page_token of the previous page, with the same sort order
+	PageToken     *string `protobuf:"bytes,10,opt,name=page_token,json=pageToken,proto3,oneof" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContainersRequest) Reset() {
+	*x = ListContainersRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContainersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContainersRequest) ProtoMessage() {}
+
+func (x *ListContainersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContainersRequest.ProtoReflect.Descriptor instead.
+func (*ListContainersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ListContainersRequest) GetFilter() string {
+	if x != nil && x.Filter != nil {
+		return *x.Filter
+	}
+	return ""
+}
+
+func (x *ListContainersRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ListContainersRequest) GetLabelSelector() string {
+	if x != nil && x.LabelSelector != nil {
+		return *x.LabelSelector
+	}
+	return ""
+}
+
+func (x *ListContainersRequest) GetTenant() string {
+	if x != nil && x.Tenant != nil {
+		return *x.Tenant
+	}
+	return ""
+}
+
+func (x *ListContainersRequest) GetImage() string {
+	if x != nil && x.Image != nil {
+		return *x.Image
+	}
+	return ""
+}
+
+func (x *ListContainersRequest) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *ListContainersRequest) GetSortBy() string {
+	if x != nil && x.SortBy != nil {
+		return *x.SortBy
+	}
+	return ""
+}
+
+func (x *ListContainersRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *ListContainersRequest) GetPageSize() uint32 {
+	if x != nil && x.PageSize != nil {
+		return *x.PageSize
+	}
+	return 0
+}
+
+func (x *ListContainersRequest) GetPageToken() string {
+	if x != nil && x.PageToken != nil {
+		return *x.PageToken
+	}
+	return ""
+}
+
+type ListContainersResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Containers []*ContainerInfo       `protobuf:"bytes,1,rep,name=containers,proto3" json:"containers,omitempty"`
+	// Set when more containers follow; pass it as page_token for the next page
+	NextPageToken *string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3,oneof" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContainersResponse) Reset() {
+	*x = ListContainersResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContainersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContainersResponse) ProtoMessage() {}
+
+func (x *ListContainersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContainersResponse.ProtoReflect.Descriptor instead.
+func (*ListContainersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ListContainersResponse) GetContainers() []*ContainerInfo {
+	if x != nil {
+		return x.Containers
+	}
+	return nil
+}
+
+func (x *ListContainersResponse) GetNextPageToken() string {
+	if x != nil && x.NextPageToken != nil {
+		return *x.NextPageToken
+	}
+	return ""
+}
+
+type ContainerInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Image         string                 `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
+	State         ContainerState         `protobuf:"varint,3,opt,name=state,proto3,enum=container_manager.ContainerState" json:"state,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	FinishedAt    *string                `protobuf:"bytes,5,opt,name=finished_at,json=finishedAt,proto3,oneof" json:"finished_at,omitempty"`
+	ExitCode      *int32                 `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	Command       []string               `protobuf:"bytes,7,rep,name=command,proto3" json:"command,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Attempt       uint32                 `protobuf:"varint,9,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerInfo) Reset() {
+	*x = ContainerInfo{}
+	mi := &file_proto_container_manager_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerInfo) ProtoMessage() {}
+
+func (x *ContainerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerInfo.ProtoReflect.Descriptor instead.
+func (*ContainerInfo) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ContainerInfo) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerInfo) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ContainerInfo) GetState() ContainerState {
+	if x != nil {
+		return x.State
+	}
+	return ContainerState_CREATED
+}
+
+func (x *ContainerInfo) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *ContainerInfo) GetFinishedAt() string {
+	if x != nil && x.FinishedAt != nil {
+		return *x.FinishedAt
+	}
+	return ""
+}
+
+func (x *ContainerInfo) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *ContainerInfo) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *ContainerInfo) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ContainerInfo) GetAttempt() uint32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+type GetContainerStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetContainerStatusRequest) Reset() {
+	*x = GetContainerStatusRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetContainerStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContainerStatusRequest) ProtoMessage() {}
+
+func (x *GetContainerStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetContainerStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetContainerStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *GetContainerStatusRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+type GetContainerStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	Status        *ContainerStatus       `protobuf:"bytes,3,opt,name=status,proto3,oneof" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetContainerStatusResponse) Reset() {
+	*x = GetContainerStatusResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetContainerStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContainerStatusResponse) ProtoMessage() {}
+
+func (x *GetContainerStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetContainerStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetContainerStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GetContainerStatusResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetContainerStatusResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *GetContainerStatusResponse) GetStatus() *ContainerStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type ContainerStatus struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	State       ContainerState         `protobuf:"varint,2,opt,name=state,proto3,enum=container_manager.ContainerState" json:"state,omitempty"`
+	CreatedAt   string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt   *string                `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3,oneof" json:"started_at,omitempty"`
+	FinishedAt  *string                `protobuf:"bytes,5,opt,name=finished_at,json=finishedAt,proto3,oneof" json:"finished_at,omitempty"`
+	ExitCode    *int32                 `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	Pid         *int32                 `protobuf:"varint,7,opt,name=pid,proto3,oneof" json:"pid,omitempty"`
+	Config      *ContainerConfig       `protobuf:"bytes,8,opt,name=config,proto3" json:"config,omitempty"`
+	// I/O statistics
+	IoStats *IOStats `protobuf:"bytes,9,opt,name=io_stats,json=ioStats,proto3" json:"io_stats,omitempty"`
+	// Unix timestamp when container should be cleaned up (if cleanup enabled)
+	CleanupAfter *int64 `protobuf:"varint,10,opt,name=cleanup_after,json=cleanupAfter,proto3,oneof" json:"cleanup_after,omitempty"`
+	// Why the manager terminated the container, e.g. "max_lifetime_exceeded",
+	// "config_rejected" when the isolation-runner refused its config, or
+	// "runner_unresponsive" when it stopped sending heartbeats. Unset
+	// for client-requested terminations and normal exits.
+	TerminationReason *string `protobuf:"bytes,11,opt,name=termination_reason,json=terminationReason,proto3,oneof" json:"termination_reason,omitempty"`
+	// Set while the container is paused; its state stays RUNNING
+	Paused bool `protobuf:"varint,12,opt,name=paused,proto3" json:"paused,omitempty"`
+	// Set once container_ready has been emitted
+	Ready bool `protobuf:"varint,13,opt,name=ready,proto3" json:"ready,omitempty"`
+	// Version the isolation-runner announced in its runner_hello event
+	RunnerVersion *string `protobuf:"bytes,14,opt,name=runner_version,json=runnerVersion,proto3,oneof" json:"runner_version,omitempty"`
+	// Machine-readable cause the isolation-runner reported with its exit, e.g.
+	// "IMAGE_PULL_FAILED" or "OOM". Unset for an exit the runner did not
+	// attribute to a failure.
+	ErrorCode *string `protobuf:"bytes,15,opt,name=error_code,json=errorCode,proto3,oneof" json:"error_code,omitempty"`
+	// Phase the isolation-runner reported in its latest runner_heartbeat:
+	// "starting", "pulling", "running" or "cleaning_up"
+	RunnerPhase *string `protobuf:"bytes,16,opt,name=runner_phase,json=runnerPhase,proto3,oneof" json:"runner_phase,omitempty"`
+	// ID of this run, generated by the manager. The isolation-runner stamps it
+	// on its events and sends it with its bastion calls, whose audit log records
+	// it, so one run can be traced across the services.
+	RunId string `protobuf:"bytes,17,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	// Which run of the container this is: 1, plus one per RestartContainer.
+	// Unset on records written before attempts were counted.
+	Attempt       uint32 `protobuf:"varint,18,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerStatus) Reset() {
+	*x = ContainerStatus{}
+	mi := &file_proto_container_manager_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerStatus) ProtoMessage() {}
+
+func (x *ContainerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerStatus.ProtoReflect.Descriptor instead.
+func (*ContainerStatus) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ContainerStatus) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetState() ContainerState {
+	if x != nil {
+		return x.State
+	}
+	return ContainerState_CREATED
+}
+
+func (x *ContainerStatus) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetStartedAt() string {
+	if x != nil && x.StartedAt != nil {
+		return *x.StartedAt
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetFinishedAt() string {
+	if x != nil && x.FinishedAt != nil {
+		return *x.FinishedAt
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *ContainerStatus) GetPid() int32 {
+	if x != nil && x.Pid != nil {
+		return *x.Pid
+	}
+	return 0
+}
+
+func (x *ContainerStatus) GetConfig() *ContainerConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *ContainerStatus) GetIoStats() *IOStats {
+	if x != nil {
+		return x.IoStats
+	}
+	return nil
+}
+
+func (x *ContainerStatus) GetCleanupAfter() int64 {
+	if x != nil && x.CleanupAfter != nil {
+		return *x.CleanupAfter
+	}
+	return 0
+}
+
+func (x *ContainerStatus) GetTerminationReason() string {
+	if x != nil && x.TerminationReason != nil {
+		return *x.TerminationReason
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *ContainerStatus) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
+
+func (x *ContainerStatus) GetRunnerVersion() string {
+	if x != nil && x.RunnerVersion != nil {
+		return *x.RunnerVersion
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetErrorCode() string {
+	if x != nil && x.ErrorCode != nil {
+		return *x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetRunnerPhase() string {
+	if x != nil && x.RunnerPhase != nil {
+		return *x.RunnerPhase
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *ContainerStatus) GetAttempt() uint32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+type IOStats struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	StdinBytes  uint64                 `protobuf:"varint,1,opt,name=stdin_bytes,json=stdinBytes,proto3" json:"stdin_bytes,omitempty"`
+	StdoutBytes uint64                 `protobuf:"varint,2,opt,name=stdout_bytes,json=stdoutBytes,proto3" json:"stdout_bytes,omitempty"`
+	StderrBytes uint64                 `protobuf:"varint,3,opt,name=stderr_bytes,json=stderrBytes,proto3" json:"stderr_bytes,omitempty"`
+	// From the latest container_stats sample; unset until the first arrives.
+	// CPU is relative to one CPU, so a container using two reports 200.
+	CpuPercent       *float64 `protobuf:"fixed64,4,opt,name=cpu_percent,json=cpuPercent,proto3,oneof" json:"cpu_percent,omitempty"`
+	MemoryUsageBytes uint64   `protobuf:"varint,5,opt,name=memory_usage_bytes,json=memoryUsageBytes,proto3" json:"memory_usage_bytes,omitempty"`
+	MemoryLimitBytes uint64   `protobuf:"varint,6,opt,name=memory_limit_bytes,json=memoryLimitBytes,proto3" json:"memory_limit_bytes,omitempty"`
+	// Totals since the container started
+	NetRxBytes      uint64 `protobuf:"varint,7,opt,name=net_rx_bytes,json=netRxBytes,proto3" json:"net_rx_bytes,omitempty"`
+	NetTxBytes      uint64 `protobuf:"varint,8,opt,name=net_tx_bytes,json=netTxBytes,proto3" json:"net_tx_bytes,omitempty"`
+	BlockReadBytes  uint64 `protobuf:"varint,9,opt,name=block_read_bytes,json=blockReadBytes,proto3" json:"block_read_bytes,omitempty"`
+	BlockWriteBytes uint64 `protobuf:"varint,10,opt,name=block_write_bytes,json=blockWriteBytes,proto3" json:"block_write_bytes,omitempty"`
+	// RFC 3339 time of the latest container_stats sample
+	SampledAt *string `protobuf:"bytes,11,opt,name=sampled_at,json=sampledAt,proto3,oneof" json:"sampled_at,omitempty"`
+	// CPU time used since the container started, in seconds
+	CpuTimeSecs   *float64 `protobuf:"fixed64,12,opt,name=cpu_time_secs,json=cpuTimeSecs,proto3,oneof" json:"cpu_time_secs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IOStats) Reset() {
+	*x = IOStats{}
+	mi := &file_proto_container_manager_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IOStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IOStats) ProtoMessage() {}
+
+func (x *IOStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IOStats.ProtoReflect.Descriptor instead.
+func (*IOStats) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *IOStats) GetStdinBytes() uint64 {
+	if x != nil {
+		return x.StdinBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetStdoutBytes() uint64 {
+	if x != nil {
+		return x.StdoutBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetStderrBytes() uint64 {
+	if x != nil {
+		return x.StderrBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetCpuPercent() float64 {
+	if x != nil && x.CpuPercent != nil {
+		return *x.CpuPercent
+	}
+	return 0
+}
+
+func (x *IOStats) GetMemoryUsageBytes() uint64 {
+	if x != nil {
+		return x.MemoryUsageBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetMemoryLimitBytes() uint64 {
+	if x != nil {
+		return x.MemoryLimitBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetNetRxBytes() uint64 {
+	if x != nil {
+		return x.NetRxBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetNetTxBytes() uint64 {
+	if x != nil {
+		return x.NetTxBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetBlockReadBytes() uint64 {
+	if x != nil {
+		return x.BlockReadBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetBlockWriteBytes() uint64 {
+	if x != nil {
+		return x.BlockWriteBytes
+	}
+	return 0
+}
+
+func (x *IOStats) GetSampledAt() string {
+	if x != nil && x.SampledAt != nil {
+		return *x.SampledAt
+	}
+	return ""
+}
+
+func (x *IOStats) GetCpuTimeSecs() float64 {
+	if x != nil && x.CpuTimeSecs != nil {
+		return *x.CpuTimeSecs
+	}
+	return 0
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{53}
+}
+
+type HealthResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Healthy             bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Version             string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	RunningContainers   uint32                 `protobuf:"varint,3,opt,name=running_containers,json=runningContainers,proto3" json:"running_containers,omitempty"`
+	TotalContainers     uint32                 `protobuf:"varint,4,opt,name=total_containers,json=totalContainers,proto3" json:"total_containers,omitempty"`
+	IsolationRunnerPath *string                `protobuf:"bytes,5,opt,name=isolation_runner_path,json=isolationRunnerPath,proto3,oneof" json:"isolation_runner_path,omitempty"`
+	HealthIssues        []string               `protobuf:"bytes,6,rep,name=health_issues,json=healthIssues,proto3" json:"health_issues,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *HealthResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HealthResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetRunningContainers() uint32 {
+	if x != nil {
+		return x.RunningContainers
+	}
+	return 0
+}
+
+func (x *HealthResponse) GetTotalContainers() uint32 {
+	if x != nil {
+		return x.TotalContainers
+	}
+	return 0
+}
+
+func (x *HealthResponse) GetIsolationRunnerPath() string {
+	if x != nil && x.IsolationRunnerPath != nil {
+		return *x.IsolationRunnerPath
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetHealthIssues() []string {
+	if x != nil {
+		return x.HealthIssues
+	}
+	return nil
+}
+
+type GetNodeResourcesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeResourcesRequest) Reset() {
+	*x = GetNodeResourcesRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeResourcesRequest) ProtoMessage() {}
+
+func (x *GetNodeResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeResourcesRequest.ProtoReflect.Descriptor instead.
+func (*GetNodeResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{55}
+}
+
+type GetNodeResourcesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	Resources     *NodeResources         `protobuf:"bytes,3,opt,name=resources,proto3,oneof" json:"resources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeResourcesResponse) Reset() {
+	*x = GetNodeResourcesResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeResourcesResponse) ProtoMessage() {}
+
+func (x *GetNodeResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeResourcesResponse.ProtoReflect.Descriptor instead.
+func (*GetNodeResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *GetNodeResourcesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetNodeResourcesResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *GetNodeResourcesResponse) GetResources() *NodeResources {
 	if x != nil {
 		return x.Resources
 	}
 	return nil
 }
 
-func (x *ContainerConfig) GetNetwork() *NetworkConfig {
+type NodeResources struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CPU; usage is over the time since the previous call, or since boot
+	CpuCores        uint32  `protobuf:"varint,1,opt,name=cpu_cores,json=cpuCores,proto3" json:"cpu_cores,omitempty"`
+	CpuUsagePercent float32 `protobuf:"fixed32,2,opt,name=cpu_usage_percent,json=cpuUsagePercent,proto3" json:"cpu_usage_percent,omitempty"`
+	// Memory (bytes); available is what can be allocated without swapping
+	MemoryTotalBytes     uint64  `protobuf:"varint,3,opt,name=memory_total_bytes,json=memoryTotalBytes,proto3" json:"memory_total_bytes,omitempty"`
+	MemoryAvailableBytes uint64  `protobuf:"varint,4,opt,name=memory_available_bytes,json=memoryAvailableBytes,proto3" json:"memory_available_bytes,omitempty"`
+	MemoryUsedBytes      uint64  `protobuf:"varint,5,opt,name=memory_used_bytes,json=memoryUsedBytes,proto3" json:"memory_used_bytes,omitempty"`
+	MemoryUsagePercent   float32 `protobuf:"fixed32,6,opt,name=memory_usage_percent,json=memoryUsagePercent,proto3" json:"memory_usage_percent,omitempty"`
+	// Disk (bytes) of the filesystem holding the Docker data-root
+	DiskTotalBytes     uint64  `protobuf:"varint,7,opt,name=disk_total_bytes,json=diskTotalBytes,proto3" json:"disk_total_bytes,omitempty"`
+	DiskAvailableBytes uint64  `protobuf:"varint,8,opt,name=disk_available_bytes,json=diskAvailableBytes,proto3" json:"disk_available_bytes,omitempty"`
+	DiskUsedBytes      uint64  `protobuf:"varint,9,opt,name=disk_used_bytes,json=diskUsedBytes,proto3" json:"disk_used_bytes,omitempty"`
+	DiskUsagePercent   float32 `protobuf:"fixed32,10,opt,name=disk_usage_percent,json=diskUsagePercent,proto3" json:"disk_usage_percent,omitempty"`
+	// Container stats
+	RunningContainers uint32 `protobuf:"varint,11,opt,name=running_containers,json=runningContainers,proto3" json:"running_containers,omitempty"`
+	TotalContainers   uint32 `protobuf:"varint,12,opt,name=total_containers,json=totalContainers,proto3" json:"total_containers,omitempty"`
+	// Load averages
+	Load_1Min  float32 `protobuf:"fixed32,13,opt,name=load_1min,json=load1min,proto3" json:"load_1min,omitempty"`
+	Load_5Min  float32 `protobuf:"fixed32,14,opt,name=load_5min,json=load5min,proto3" json:"load_5min,omitempty"`
+	Load_15Min float32 `protobuf:"fixed32,15,opt,name=load_15min,json=load15min,proto3" json:"load_15min,omitempty"`
+	// Memory charged to the cgroups of Docker containers on the node, less
+	// their inactive page cache (bytes)
+	ContainerMemoryUsedBytes uint64 `protobuf:"varint,16,opt,name=container_memory_used_bytes,json=containerMemoryUsedBytes,proto3" json:"container_memory_used_bytes,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *NodeResources) Reset() {
+	*x = NodeResources{}
+	mi := &file_proto_container_manager_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeResources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeResources) ProtoMessage() {}
+
+func (x *NodeResources) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[57]
 	if x != nil {
-		return x.Network
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *ContainerConfig) GetTimeoutSecs() uint32 {
-	if x != nil && x.TimeoutSecs != nil {
-		return *x.TimeoutSecs
+// Deprecated: Use NodeResources.ProtoReflect.Descriptor instead.
+func (*NodeResources) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *NodeResources) GetCpuCores() uint32 {
+	if x != nil {
+		return x.CpuCores
 	}
 	return 0
 }
 
-func (x *ContainerConfig) GetCleanup() bool {
-	if x != nil && x.Cleanup != nil {
-		return *x.Cleanup
+func (x *NodeResources) GetCpuUsagePercent() float32 {
+	if x != nil {
+		return x.CpuUsagePercent
+	}
+	return 0
+}
+
+func (x *NodeResources) GetMemoryTotalBytes() uint64 {
+	if x != nil {
+		return x.MemoryTotalBytes
+	}
+	return 0
+}
+
+func (x *NodeResources) GetMemoryAvailableBytes() uint64 {
+	if x != nil {
+		return x.MemoryAvailableBytes
+	}
+	return 0
+}
+
+func (x *NodeResources) GetMemoryUsedBytes() uint64 {
+	if x != nil {
+		return x.MemoryUsedBytes
+	}
+	return 0
+}
+
+func (x *NodeResources) GetMemoryUsagePercent() float32 {
+	if x != nil {
+		return x.MemoryUsagePercent
+	}
+	return 0
+}
+
+func (x *NodeResources) GetDiskTotalBytes() uint64 {
+	if x != nil {
+		return x.DiskTotalBytes
+	}
+	return 0
+}
+
+func (x *NodeResources) GetDiskAvailableBytes() uint64 {
+	if x != nil {
+		return x.DiskAvailableBytes
+	}
+	return 0
+}
+
+func (x *NodeResources) GetDiskUsedBytes() uint64 {
+	if x != nil {
+		return x.DiskUsedBytes
+	}
+	return 0
+}
+
+func (x *NodeResources) GetDiskUsagePercent() float32 {
+	if x != nil {
+		return x.DiskUsagePercent
+	}
+	return 0
+}
+
+func (x *NodeResources) GetRunningContainers() uint32 {
+	if x != nil {
+		return x.RunningContainers
+	}
+	return 0
+}
+
+func (x *NodeResources) GetTotalContainers() uint32 {
+	if x != nil {
+		return x.TotalContainers
+	}
+	return 0
+}
+
+func (x *NodeResources) GetLoad_1Min() float32 {
+	if x != nil {
+		return x.Load_1Min
+	}
+	return 0
+}
+
+func (x *NodeResources) GetLoad_5Min() float32 {
+	if x != nil {
+		return x.Load_5Min
+	}
+	return 0
+}
+
+func (x *NodeResources) GetLoad_15Min() float32 {
+	if x != nil {
+		return x.Load_15Min
+	}
+	return 0
+}
+
+func (x *NodeResources) GetContainerMemoryUsedBytes() uint64 {
+	if x != nil {
+		return x.ContainerMemoryUsedBytes
+	}
+	return 0
+}
+
+type GetAvailableImagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAvailableImagesRequest) Reset() {
+	*x = GetAvailableImagesRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAvailableImagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAvailableImagesRequest) ProtoMessage() {}
+
+func (x *GetAvailableImagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAvailableImagesRequest.ProtoReflect.Descriptor instead.
+func (*GetAvailableImagesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{58}
+}
+
+type GetAvailableImagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	Images        []*ImageInfo           `protobuf:"bytes,3,rep,name=images,proto3" json:"images,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAvailableImagesResponse) Reset() {
+	*x = GetAvailableImagesResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAvailableImagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAvailableImagesResponse) ProtoMessage() {}
+
+func (x *GetAvailableImagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAvailableImagesResponse.ProtoReflect.Descriptor instead.
+func (*GetAvailableImagesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *GetAvailableImagesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetAvailableImagesResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
-	return false
+	return ""
 }
 
-func (x *ContainerConfig) GetArgs() []string {
+func (x *GetAvailableImagesResponse) GetImages() []*ImageInfo {
 	if x != nil {
-		return x.Args
+		return x.Images
 	}
 	return nil
 }
 
-// Image specification with registry and authentication
-type ImageSpec struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Registry hostname (defaults to "registry-1.docker.io" if not provided)
-	// Examples: "gcr.io", "ghcr.io", "my-registry.local:5000"
-	Registry *string `protobuf:"bytes,1,opt,name=registry,proto3,oneof" json:"registry,omitempty"`
-	// Image name with tag or digest (without registry prefix)
-	// Examples: "library/nginx:latest", "myorg/app@sha256:abc123..."
-	Image string `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
-	// Optional authentication credentials
-	//
-	// Types that are valid to be assigned to Auth:
-	//
-	//	*ImageSpec_BasicAuth
-	Auth          isImageSpec_Auth `protobuf_oneof:"auth"`
+type ImageInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RepoTags      []string               `protobuf:"bytes,2,rep,name=repo_tags,json=repoTags,proto3" json:"repo_tags,omitempty"`
+	SizeBytes     uint64                 `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Created       string                 `protobuf:"bytes,4,opt,name=created,proto3" json:"created,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ImageSpec) Reset() {
-	*x = ImageSpec{}
-	mi := &file_proto_container_manager_proto_msgTypes[7]
+func (x *ImageInfo) Reset() {
+	*x = ImageInfo{}
+	mi := &file_proto_container_manager_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ImageSpec) String() string {
+func (x *ImageInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ImageSpec) ProtoMessage() {}
+func (*ImageInfo) ProtoMessage() {}
 
-func (x *ImageSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[7]
+func (x *ImageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -748,75 +5410,63 @@ func (x *ImageSpec) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ImageSpec.ProtoReflect.Descriptor instead.
-func (*ImageSpec) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *ImageSpec) GetRegistry() string {
-	if x != nil && x.Registry != nil {
-		return *x.Registry
-	}
-	return ""
+// Deprecated: Use ImageInfo.ProtoReflect.Descriptor instead.
+func (*ImageInfo) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{60}
 }
 
-func (x *ImageSpec) GetImage() string {
+func (x *ImageInfo) GetId() string {
 	if x != nil {
-		return x.Image
+		return x.Id
 	}
 	return ""
 }
 
-func (x *ImageSpec) GetAuth() isImageSpec_Auth {
+func (x *ImageInfo) GetRepoTags() []string {
 	if x != nil {
-		return x.Auth
+		return x.RepoTags
 	}
 	return nil
 }
 
-func (x *ImageSpec) GetBasicAuth() *BasicAuth {
+func (x *ImageInfo) GetSizeBytes() uint64 {
 	if x != nil {
-		if x, ok := x.Auth.(*ImageSpec_BasicAuth); ok {
-			return x.BasicAuth
-		}
+		return x.SizeBytes
 	}
-	return nil
-}
-
-type isImageSpec_Auth interface {
-	isImageSpec_Auth()
+	return 0
 }
 
-type ImageSpec_BasicAuth struct {
-	BasicAuth *BasicAuth `protobuf:"bytes,3,opt,name=basic_auth,json=basicAuth,proto3,oneof"`
+func (x *ImageInfo) GetCreated() string {
+	if x != nil {
+		return x.Created
+	}
+	return ""
 }
 
-func (*ImageSpec_BasicAuth) isImageSpec_Auth() {}
-
-// Basic authentication for private registries
-type BasicAuth struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
-	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+type UpdateContainerResourcesRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// New limits; unset fields keep their current value
+	Resources     *ResourceLimits `protobuf:"bytes,2,opt,name=resources,proto3" json:"resources,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BasicAuth) Reset() {
-	*x = BasicAuth{}
-	mi := &file_proto_container_manager_proto_msgTypes[8]
+func (x *UpdateContainerResourcesRequest) Reset() {
+	*x = UpdateContainerResourcesRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BasicAuth) String() string {
+func (x *UpdateContainerResourcesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BasicAuth) ProtoMessage() {}
+func (*UpdateContainerResourcesRequest) ProtoMessage() {}
 
-func (x *BasicAuth) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[8]
+func (x *UpdateContainerResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -827,50 +5477,50 @@ func (x *BasicAuth) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BasicAuth.ProtoReflect.Descriptor instead.
-func (*BasicAuth) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use UpdateContainerResourcesRequest.ProtoReflect.Descriptor instead.
+func (*UpdateContainerResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *BasicAuth) GetUsername() string {
+func (x *UpdateContainerResourcesRequest) GetContainerId() string {
 	if x != nil {
-		return x.Username
+		return x.ContainerId
 	}
 	return ""
 }
 
-func (x *BasicAuth) GetPassword() string {
+func (x *UpdateContainerResourcesRequest) GetResources() *ResourceLimits {
 	if x != nil {
-		return x.Password
+		return x.Resources
 	}
-	return ""
+	return nil
 }
 
-type ResourceLimits struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// CPU limit (e.g., "1.0" for 1 CPU)
-	CpuLimit *string `protobuf:"bytes,1,opt,name=cpu_limit,json=cpuLimit,proto3,oneof" json:"cpu_limit,omitempty"`
-	// Memory limit (e.g., "512m", "1g")
-	MemoryLimit   *string `protobuf:"bytes,2,opt,name=memory_limit,json=memoryLimit,proto3,oneof" json:"memory_limit,omitempty"`
+type UpdateContainerResourcesResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Limits in effect after the update
+	Resources     *ResourceLimits `protobuf:"bytes,3,opt,name=resources,proto3,oneof" json:"resources,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResourceLimits) Reset() {
-	*x = ResourceLimits{}
-	mi := &file_proto_container_manager_proto_msgTypes[9]
+func (x *UpdateContainerResourcesResponse) Reset() {
+	*x = UpdateContainerResourcesResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResourceLimits) String() string {
+func (x *UpdateContainerResourcesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResourceLimits) ProtoMessage() {}
+func (*UpdateContainerResourcesResponse) ProtoMessage() {}
 
-func (x *ResourceLimits) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[9]
+func (x *UpdateContainerResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -881,52 +5531,54 @@ func (x *ResourceLimits) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResourceLimits.ProtoReflect.Descriptor instead.
-func (*ResourceLimits) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use UpdateContainerResourcesResponse.ProtoReflect.Descriptor instead.
+func (*UpdateContainerResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{62}
 }
 
-func (x *ResourceLimits) GetCpuLimit() string {
-	if x != nil && x.CpuLimit != nil {
-		return *x.CpuLimit
+func (x *UpdateContainerResourcesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *ResourceLimits) GetMemoryLimit() string {
-	if x != nil && x.MemoryLimit != nil {
-		return *x.MemoryLimit
+func (x *UpdateContainerResourcesResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
 	return ""
 }
 
-type NetworkConfig struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Network policy rules
-	Rules []*NetworkRule `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
-	// Default policy (allow/deny)
-	DefaultPolicy *string `protobuf:"bytes,2,opt,name=default_policy,json=defaultPolicy,proto3,oneof" json:"default_policy,omitempty"`
-	// Custom DNS servers
-	DnsServers    []string `protobuf:"bytes,3,rep,name=dns_servers,json=dnsServers,proto3" json:"dns_servers,omitempty"`
+func (x *UpdateContainerResourcesResponse) GetResources() *ResourceLimits {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+type PauseContainerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkConfig) Reset() {
-	*x = NetworkConfig{}
-	mi := &file_proto_container_manager_proto_msgTypes[10]
+func (x *PauseContainerRequest) Reset() {
+	*x = PauseContainerRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkConfig) String() string {
+func (x *PauseContainerRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkConfig) ProtoMessage() {}
+func (*PauseContainerRequest) ProtoMessage() {}
 
-func (x *NetworkConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[10]
+func (x *PauseContainerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -937,63 +5589,41 @@ func (x *NetworkConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkConfig.ProtoReflect.Descriptor instead.
-func (*NetworkConfig) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use PauseContainerRequest.ProtoReflect.Descriptor instead.
+func (*PauseContainerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{63}
 }
 
-func (x *NetworkConfig) GetRules() []*NetworkRule {
+func (x *PauseContainerRequest) GetContainerId() string {
 	if x != nil {
-		return x.Rules
-	}
-	return nil
-}
-
-func (x *NetworkConfig) GetDefaultPolicy() string {
-	if x != nil && x.DefaultPolicy != nil {
-		return *x.DefaultPolicy
+		return x.ContainerId
 	}
 	return ""
 }
 
-func (x *NetworkConfig) GetDnsServers() []string {
-	if x != nil {
-		return x.DnsServers
-	}
-	return nil
-}
-
-type NetworkRule struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Rule type (allow/deny)
-	Action string `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
-	// Protocol (tcp/udp/icmp)
-	Protocol *string `protobuf:"bytes,2,opt,name=protocol,proto3,oneof" json:"protocol,omitempty"`
-	// Destination CIDR
-	Destination *string `protobuf:"bytes,3,opt,name=destination,proto3,oneof" json:"destination,omitempty"`
-	// Port range start (inclusive)
-	PortRangeStart *uint32 `protobuf:"varint,4,opt,name=port_range_start,json=portRangeStart,proto3,oneof" json:"port_range_start,omitempty"`
-	// Port range end (inclusive)
-	PortRangeEnd  *uint32 `protobuf:"varint,5,opt,name=port_range_end,json=portRangeEnd,proto3,oneof" json:"port_range_end,omitempty"`
+type PauseContainerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkRule) Reset() {
-	*x = NetworkRule{}
-	mi := &file_proto_container_manager_proto_msgTypes[11]
+func (x *PauseContainerResponse) Reset() {
+	*x = PauseContainerResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkRule) String() string {
+func (x *PauseContainerResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkRule) ProtoMessage() {}
+func (*PauseContainerResponse) ProtoMessage() {}
 
-func (x *NetworkRule) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[11]
+func (x *PauseContainerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1004,69 +5634,92 @@ func (x *NetworkRule) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkRule.ProtoReflect.Descriptor instead.
-func (*NetworkRule) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use PauseContainerResponse.ProtoReflect.Descriptor instead.
+func (*PauseContainerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *NetworkRule) GetAction() string {
+func (x *PauseContainerResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Action
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *NetworkRule) GetProtocol() string {
-	if x != nil && x.Protocol != nil {
-		return *x.Protocol
+func (x *PauseContainerResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
 	return ""
 }
 
-func (x *NetworkRule) GetDestination() string {
-	if x != nil && x.Destination != nil {
-		return *x.Destination
-	}
-	return ""
+type UnpauseContainerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkRule) GetPortRangeStart() uint32 {
-	if x != nil && x.PortRangeStart != nil {
-		return *x.PortRangeStart
+func (x *UnpauseContainerRequest) Reset() {
+	*x = UnpauseContainerRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpauseContainerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpauseContainerRequest) ProtoMessage() {}
+
+func (x *UnpauseContainerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *NetworkRule) GetPortRangeEnd() uint32 {
-	if x != nil && x.PortRangeEnd != nil {
-		return *x.PortRangeEnd
+// Deprecated: Use UnpauseContainerRequest.ProtoReflect.Descriptor instead.
+func (*UnpauseContainerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *UnpauseContainerRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
 	}
-	return 0
+	return ""
 }
 
-type ListContainersRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Filter by state (running, exited, all)
-	Filter        *string `protobuf:"bytes,1,opt,name=filter,proto3,oneof" json:"filter,omitempty"`
+type UnpauseContainerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListContainersRequest) Reset() {
-	*x = ListContainersRequest{}
-	mi := &file_proto_container_manager_proto_msgTypes[12]
+func (x *UnpauseContainerResponse) Reset() {
+	*x = UnpauseContainerResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListContainersRequest) String() string {
+func (x *UnpauseContainerResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListContainersRequest) ProtoMessage() {}
+func (*UnpauseContainerResponse) ProtoMessage() {}
 
-func (x *ListContainersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[12]
+func (x *UnpauseContainerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1077,40 +5730,52 @@ func (x *ListContainersRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListContainersRequest.ProtoReflect.Descriptor instead.
-func (*ListContainersRequest) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use UnpauseContainerResponse.ProtoReflect.Descriptor instead.
+func (*UnpauseContainerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *ListContainersRequest) GetFilter() string {
-	if x != nil && x.Filter != nil {
-		return *x.Filter
+func (x *UnpauseContainerResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UnpauseContainerResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
 	return ""
 }
 
-type ListContainersResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Containers    []*ContainerInfo       `protobuf:"bytes,1,rep,name=containers,proto3" json:"containers,omitempty"`
+type CheckpointRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Name of the checkpoint; letters, digits, '.', '_' and '-', at most 64
+	// characters. Must not name an existing checkpoint.
+	CheckpointId string `protobuf:"bytes,2,opt,name=checkpoint_id,json=checkpointId,proto3" json:"checkpoint_id,omitempty"`
+	// Keep the container running once its state is saved; by default it stops
+	LeaveRunning  bool `protobuf:"varint,3,opt,name=leave_running,json=leaveRunning,proto3" json:"leave_running,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListContainersResponse) Reset() {
-	*x = ListContainersResponse{}
-	mi := &file_proto_container_manager_proto_msgTypes[13]
+func (x *CheckpointRequest) Reset() {
+	*x = CheckpointRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListContainersResponse) String() string {
+func (x *CheckpointRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListContainersResponse) ProtoMessage() {}
+func (*CheckpointRequest) ProtoMessage() {}
 
-func (x *ListContainersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[13]
+func (x *CheckpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1121,46 +5786,55 @@ func (x *ListContainersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListContainersResponse.ProtoReflect.Descriptor instead.
-func (*ListContainersResponse) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use CheckpointRequest.ProtoReflect.Descriptor instead.
+func (*CheckpointRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{67}
 }
 
-func (x *ListContainersResponse) GetContainers() []*ContainerInfo {
+func (x *CheckpointRequest) GetContainerId() string {
 	if x != nil {
-		return x.Containers
+		return x.ContainerId
 	}
-	return nil
+	return ""
 }
 
-type ContainerInfo struct {
+func (x *CheckpointRequest) GetCheckpointId() string {
+	if x != nil {
+		return x.CheckpointId
+	}
+	return ""
+}
+
+func (x *CheckpointRequest) GetLeaveRunning() bool {
+	if x != nil {
+		return x.LeaveRunning
+	}
+	return false
+}
+
+type CheckpointResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	Image         string                 `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
-	State         ContainerState         `protobuf:"varint,3,opt,name=state,proto3,enum=container_manager.ContainerState" json:"state,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	FinishedAt    *string                `protobuf:"bytes,5,opt,name=finished_at,json=finishedAt,proto3,oneof" json:"finished_at,omitempty"`
-	ExitCode      *int32                 `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
-	Command       []string               `protobuf:"bytes,7,rep,name=command,proto3" json:"command,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ContainerInfo) Reset() {
-	*x = ContainerInfo{}
-	mi := &file_proto_container_manager_proto_msgTypes[14]
+func (x *CheckpointResponse) Reset() {
+	*x = CheckpointResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContainerInfo) String() string {
+func (x *CheckpointResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerInfo) ProtoMessage() {}
+func (*CheckpointResponse) ProtoMessage() {}
 
-func (x *ContainerInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[14]
+func (x *CheckpointResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1171,82 +5845,101 @@ func (x *ContainerInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerInfo.ProtoReflect.Descriptor instead.
-func (*ContainerInfo) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use CheckpointResponse.ProtoReflect.Descriptor instead.
+func (*CheckpointResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *ContainerInfo) GetContainerId() string {
+func (x *CheckpointResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ContainerId
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *ContainerInfo) GetImage() string {
-	if x != nil {
-		return x.Image
+func (x *CheckpointResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
 	return ""
 }
 
-func (x *ContainerInfo) GetState() ContainerState {
-	if x != nil {
-		return x.State
-	}
-	return ContainerState_CREATED
+type WaitReadyRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// How long to wait (default 60, max 600)
+	TimeoutSecs   *uint32 `protobuf:"varint,2,opt,name=timeout_secs,json=timeoutSecs,proto3,oneof" json:"timeout_secs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ContainerInfo) GetCreatedAt() string {
+func (x *WaitReadyRequest) Reset() {
+	*x = WaitReadyRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitReadyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitReadyRequest) ProtoMessage() {}
+
+func (x *WaitReadyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[69]
 	if x != nil {
-		return x.CreatedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *ContainerInfo) GetFinishedAt() string {
-	if x != nil && x.FinishedAt != nil {
-		return *x.FinishedAt
-	}
-	return ""
+// Deprecated: Use WaitReadyRequest.ProtoReflect.Descriptor instead.
+func (*WaitReadyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{69}
 }
 
-func (x *ContainerInfo) GetExitCode() int32 {
-	if x != nil && x.ExitCode != nil {
-		return *x.ExitCode
+func (x *WaitReadyRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
 	}
-	return 0
+	return ""
 }
 
-func (x *ContainerInfo) GetCommand() []string {
-	if x != nil {
-		return x.Command
+func (x *WaitReadyRequest) GetTimeoutSecs() uint32 {
+	if x != nil && x.TimeoutSecs != nil {
+		return *x.TimeoutSecs
 	}
-	return nil
+	return 0
 }
 
-type GetContainerStatusRequest struct {
+type WaitReadyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Ready         bool                   `protobuf:"varint,1,opt,name=ready,proto3" json:"ready,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetContainerStatusRequest) Reset() {
-	*x = GetContainerStatusRequest{}
-	mi := &file_proto_container_manager_proto_msgTypes[15]
+func (x *WaitReadyResponse) Reset() {
+	*x = WaitReadyResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetContainerStatusRequest) String() string {
+func (x *WaitReadyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetContainerStatusRequest) ProtoMessage() {}
+func (*WaitReadyResponse) ProtoMessage() {}
 
-func (x *GetContainerStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[15]
+func (x *WaitReadyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1257,42 +5950,55 @@ func (x *GetContainerStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetContainerStatusRequest.ProtoReflect.Descriptor instead.
-func (*GetContainerStatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use WaitReadyResponse.ProtoReflect.Descriptor instead.
+func (*WaitReadyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *GetContainerStatusRequest) GetContainerId() string {
+func (x *WaitReadyResponse) GetReady() bool {
 	if x != nil {
-		return x.ContainerId
+		return x.Ready
+	}
+	return false
+}
+
+func (x *WaitReadyResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
 	return ""
 }
 
-type GetContainerStatusResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
-	Status        *ContainerStatus       `protobuf:"bytes,3,opt,name=status,proto3,oneof" json:"status,omitempty"`
+type GetLogsRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Only lines written at or after this Unix time in milliseconds
+	SinceUnixMs *int64 `protobuf:"varint,2,opt,name=since_unix_ms,json=sinceUnixMs,proto3,oneof" json:"since_unix_ms,omitempty"`
+	// Only the last tail lines; unset means every line kept
+	Tail *uint32 `protobuf:"varint,3,opt,name=tail,proto3,oneof" json:"tail,omitempty"`
+	// Keep the stream open for lines written later
+	Follow bool `protobuf:"varint,4,opt,name=follow,proto3" json:"follow,omitempty"`
+	// Outputs to read; empty means all
+	Streams       []OutputStream `protobuf:"varint,5,rep,packed,name=streams,proto3,enum=container_manager.OutputStream" json:"streams,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetContainerStatusResponse) Reset() {
-	*x = GetContainerStatusResponse{}
-	mi := &file_proto_container_manager_proto_msgTypes[16]
+func (x *GetLogsRequest) Reset() {
+	*x = GetLogsRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetContainerStatusResponse) String() string {
+func (x *GetLogsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetContainerStatusResponse) ProtoMessage() {}
+func (*GetLogsRequest) ProtoMessage() {}
 
-func (x *GetContainerStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[16]
+func (x *GetLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1303,65 +6009,72 @@ func (x *GetContainerStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetContainerStatusResponse.ProtoReflect.Descriptor instead.
-func (*GetContainerStatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use GetLogsRequest.ProtoReflect.Descriptor instead.
+func (*GetLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{71}
 }
 
-func (x *GetContainerStatusResponse) GetSuccess() bool {
+func (x *GetLogsRequest) GetContainerId() string {
 	if x != nil {
-		return x.Success
+		return x.ContainerId
 	}
-	return false
+	return ""
 }
 
-func (x *GetContainerStatusResponse) GetError() string {
-	if x != nil && x.Error != nil {
-		return *x.Error
+func (x *GetLogsRequest) GetSinceUnixMs() int64 {
+	if x != nil && x.SinceUnixMs != nil {
+		return *x.SinceUnixMs
 	}
-	return ""
+	return 0
 }
 
-func (x *GetContainerStatusResponse) GetStatus() *ContainerStatus {
+func (x *GetLogsRequest) GetTail() uint32 {
+	if x != nil && x.Tail != nil {
+		return *x.Tail
+	}
+	return 0
+}
+
+func (x *GetLogsRequest) GetFollow() bool {
 	if x != nil {
-		return x.Status
+		return x.Follow
+	}
+	return false
+}
+
+func (x *GetLogsRequest) GetStreams() []OutputStream {
+	if x != nil {
+		return x.Streams
 	}
 	return nil
 }
 
-type ContainerStatus struct {
-	state       protoimpl.MessageState `protogen:"open.v1"`
-	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	State       ContainerState         `protobuf:"varint,2,opt,name=state,proto3,enum=container_manager.ContainerState" json:"state,omitempty"`
-	CreatedAt   string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	StartedAt   *string                `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3,oneof" json:"started_at,omitempty"`
-	FinishedAt  *string                `protobuf:"bytes,5,opt,name=finished_at,json=finishedAt,proto3,oneof" json:"finished_at,omitempty"`
-	ExitCode    *int32                 `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
-	Pid         *int32                 `protobuf:"varint,7,opt,name=pid,proto3,oneof" json:"pid,omitempty"`
-	Config      *ContainerConfig       `protobuf:"bytes,8,opt,name=config,proto3" json:"config,omitempty"`
-	// I/O statistics
-	IoStats *IOStats `protobuf:"bytes,9,opt,name=io_stats,json=ioStats,proto3" json:"io_stats,omitempty"`
-	// Unix timestamp when container should be cleaned up (if cleanup enabled)
-	CleanupAfter  *int64 `protobuf:"varint,10,opt,name=cleanup_after,json=cleanupAfter,proto3,oneof" json:"cleanup_after,omitempty"`
+type LogLine struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unix time in milliseconds the line was written
+	TimestampUnixMs int64        `protobuf:"varint,1,opt,name=timestamp_unix_ms,json=timestampUnixMs,proto3" json:"timestamp_unix_ms,omitempty"`
+	Stream          OutputStream `protobuf:"varint,2,opt,name=stream,proto3,enum=container_manager.OutputStream" json:"stream,omitempty"`
+	// The line, with its newline if it had one; a whole event for EVENTS
+	Data          []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ContainerStatus) Reset() {
-	*x = ContainerStatus{}
-	mi := &file_proto_container_manager_proto_msgTypes[17]
+func (x *LogLine) Reset() {
+	*x = LogLine{}
+	mi := &file_proto_container_manager_proto_msgTypes[72]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContainerStatus) String() string {
+func (x *LogLine) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerStatus) ProtoMessage() {}
+func (*LogLine) ProtoMessage() {}
 
-func (x *ContainerStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[17]
+func (x *LogLine) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[72]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1372,105 +6085,133 @@ func (x *ContainerStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerStatus.ProtoReflect.Descriptor instead.
-func (*ContainerStatus) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{72}
 }
 
-func (x *ContainerStatus) GetContainerId() string {
+func (x *LogLine) GetTimestampUnixMs() int64 {
 	if x != nil {
-		return x.ContainerId
+		return x.TimestampUnixMs
 	}
-	return ""
+	return 0
 }
 
-func (x *ContainerStatus) GetState() ContainerState {
+func (x *LogLine) GetStream() OutputStream {
 	if x != nil {
-		return x.State
+		return x.Stream
 	}
-	return ContainerState_CREATED
+	return OutputStream_OUTPUT_STREAM_UNSPECIFIED
 }
 
-func (x *ContainerStatus) GetCreatedAt() string {
+func (x *LogLine) GetData() []byte {
 	if x != nil {
-		return x.CreatedAt
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-func (x *ContainerStatus) GetStartedAt() string {
-	if x != nil && x.StartedAt != nil {
-		return *x.StartedAt
-	}
-	return ""
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only containers carrying all of these labels
+	Labels map[string]string `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Only containers of this tenant, i.e. whose "tenant" label has this value
+	Tenant *string `protobuf:"bytes,2,opt,name=tenant,proto3,oneof" json:"tenant,omitempty"`
+	// Only containers in one of these states when the event is raised; empty
+	// means any
+	States []ContainerState `protobuf:"varint,3,rep,packed,name=states,proto3,enum=container_manager.ContainerState" json:"states,omitempty"`
+	// Only events of these types, e.g. "container_ready"; empty means every
+	// type except runner log messages (debug, info, warning and error),
+	// container_stats and runner_heartbeat
+	Types         []string `protobuf:"bytes,4,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ContainerStatus) GetFinishedAt() string {
-	if x != nil && x.FinishedAt != nil {
-		return *x.FinishedAt
-	}
-	return ""
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContainerStatus) GetExitCode() int32 {
-	if x != nil && x.ExitCode != nil {
-		return *x.ExitCode
-	}
-	return 0
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *ContainerStatus) GetPid() int32 {
-	if x != nil && x.Pid != nil {
-		return *x.Pid
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *ContainerStatus) GetConfig() *ContainerConfig {
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *WatchEventsRequest) GetLabels() map[string]string {
 	if x != nil {
-		return x.Config
+		return x.Labels
 	}
 	return nil
 }
 
-func (x *ContainerStatus) GetIoStats() *IOStats {
+func (x *WatchEventsRequest) GetTenant() string {
+	if x != nil && x.Tenant != nil {
+		return *x.Tenant
+	}
+	return ""
+}
+
+func (x *WatchEventsRequest) GetStates() []ContainerState {
 	if x != nil {
-		return x.IoStats
+		return x.States
 	}
 	return nil
 }
 
-func (x *ContainerStatus) GetCleanupAfter() int64 {
-	if x != nil && x.CleanupAfter != nil {
-		return *x.CleanupAfter
+func (x *WatchEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
 	}
-	return 0
+	return nil
 }
 
-type IOStats struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	StdinBytes    uint64                 `protobuf:"varint,1,opt,name=stdin_bytes,json=stdinBytes,proto3" json:"stdin_bytes,omitempty"`
-	StdoutBytes   uint64                 `protobuf:"varint,2,opt,name=stdout_bytes,json=stdoutBytes,proto3" json:"stdout_bytes,omitempty"`
-	StderrBytes   uint64                 `protobuf:"varint,3,opt,name=stderr_bytes,json=stderrBytes,proto3" json:"stderr_bytes,omitempty"`
+type ContainerEvent struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// The container's state when the event was raised
+	State         ContainerState    `protobuf:"varint,2,opt,name=state,proto3,enum=container_manager.ContainerState" json:"state,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Event         *Event            `protobuf:"bytes,4,opt,name=event,proto3" json:"event,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IOStats) Reset() {
-	*x = IOStats{}
-	mi := &file_proto_container_manager_proto_msgTypes[18]
+func (x *ContainerEvent) Reset() {
+	*x = ContainerEvent{}
+	mi := &file_proto_container_manager_proto_msgTypes[74]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IOStats) String() string {
+func (x *ContainerEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IOStats) ProtoMessage() {}
+func (*ContainerEvent) ProtoMessage() {}
 
-func (x *IOStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[18]
+func (x *ContainerEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[74]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1481,53 +6222,61 @@ func (x *IOStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IOStats.ProtoReflect.Descriptor instead.
-func (*IOStats) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use ContainerEvent.ProtoReflect.Descriptor instead.
+func (*ContainerEvent) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{74}
 }
 
-func (x *IOStats) GetStdinBytes() uint64 {
+func (x *ContainerEvent) GetContainerId() string {
 	if x != nil {
-		return x.StdinBytes
+		return x.ContainerId
 	}
-	return 0
+	return ""
 }
 
-func (x *IOStats) GetStdoutBytes() uint64 {
+func (x *ContainerEvent) GetState() ContainerState {
 	if x != nil {
-		return x.StdoutBytes
+		return x.State
 	}
-	return 0
+	return ContainerState_CREATED
 }
 
-func (x *IOStats) GetStderrBytes() uint64 {
+func (x *ContainerEvent) GetLabels() map[string]string {
 	if x != nil {
-		return x.StderrBytes
+		return x.Labels
 	}
-	return 0
+	return nil
 }
 
-type HealthRequest struct {
+func (x *ContainerEvent) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type RestartContainerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthRequest) Reset() {
-	*x = HealthRequest{}
-	mi := &file_proto_container_manager_proto_msgTypes[19]
+func (x *RestartContainerRequest) Reset() {
+	*x = RestartContainerRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[75]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthRequest) String() string {
+func (x *RestartContainerRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthRequest) ProtoMessage() {}
+func (*RestartContainerRequest) ProtoMessage() {}
 
-func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[19]
+func (x *RestartContainerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[75]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1538,38 +6287,44 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
-func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use RestartContainerRequest.ProtoReflect.Descriptor instead.
+func (*RestartContainerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{75}
 }
 
-type HealthResponse struct {
-	state               protoimpl.MessageState `protogen:"open.v1"`
-	Healthy             bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
-	Version             string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	RunningContainers   uint32                 `protobuf:"varint,3,opt,name=running_containers,json=runningContainers,proto3" json:"running_containers,omitempty"`
-	TotalContainers     uint32                 `protobuf:"varint,4,opt,name=total_containers,json=totalContainers,proto3" json:"total_containers,omitempty"`
-	IsolationRunnerPath *string                `protobuf:"bytes,5,opt,name=isolation_runner_path,json=isolationRunnerPath,proto3,oneof" json:"isolation_runner_path,omitempty"`
-	HealthIssues        []string               `protobuf:"bytes,6,rep,name=health_issues,json=healthIssues,proto3" json:"health_issues,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+func (x *RestartContainerRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
 }
 
-func (x *HealthResponse) Reset() {
-	*x = HealthResponse{}
-	mi := &file_proto_container_manager_proto_msgTypes[20]
+type RestartContainerResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Attempt and run ID of the new run
+	Attempt       uint32 `protobuf:"varint,3,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	RunId         string `protobuf:"bytes,4,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartContainerResponse) Reset() {
+	*x = RestartContainerResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[76]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthResponse) String() string {
+func (x *RestartContainerResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthResponse) ProtoMessage() {}
+func (*RestartContainerResponse) ProtoMessage() {}
 
-func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[20]
+func (x *RestartContainerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[76]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1580,74 +6335,63 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
-func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use RestartContainerResponse.ProtoReflect.Descriptor instead.
+func (*RestartContainerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{76}
 }
 
-func (x *HealthResponse) GetHealthy() bool {
+func (x *RestartContainerResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Healthy
+		return x.Success
 	}
 	return false
 }
 
-func (x *HealthResponse) GetVersion() string {
-	if x != nil {
-		return x.Version
+func (x *RestartContainerResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
 	return ""
 }
 
-func (x *HealthResponse) GetRunningContainers() uint32 {
+func (x *RestartContainerResponse) GetAttempt() uint32 {
 	if x != nil {
-		return x.RunningContainers
+		return x.Attempt
 	}
 	return 0
 }
 
-func (x *HealthResponse) GetTotalContainers() uint32 {
+func (x *RestartContainerResponse) GetRunId() string {
 	if x != nil {
-		return x.TotalContainers
-	}
-	return 0
-}
-
-func (x *HealthResponse) GetIsolationRunnerPath() string {
-	if x != nil && x.IsolationRunnerPath != nil {
-		return *x.IsolationRunnerPath
+		return x.RunId
 	}
 	return ""
 }
 
-func (x *HealthResponse) GetHealthIssues() []string {
-	if x != nil {
-		return x.HealthIssues
-	}
-	return nil
-}
-
-type GetNodeResourcesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type ExtendTimeoutRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Seconds added to the remaining run time (required)
+	ExtendSecs    uint32 `protobuf:"varint,2,opt,name=extend_secs,json=extendSecs,proto3" json:"extend_secs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetNodeResourcesRequest) Reset() {
-	*x = GetNodeResourcesRequest{}
-	mi := &file_proto_container_manager_proto_msgTypes[21]
+func (x *ExtendTimeoutRequest) Reset() {
+	*x = ExtendTimeoutRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[77]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetNodeResourcesRequest) String() string {
+func (x *ExtendTimeoutRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetNodeResourcesRequest) ProtoMessage() {}
+func (*ExtendTimeoutRequest) ProtoMessage() {}
 
-func (x *GetNodeResourcesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[21]
+func (x *ExtendTimeoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[77]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1658,35 +6402,53 @@ func (x *GetNodeResourcesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetNodeResourcesRequest.ProtoReflect.Descriptor instead.
-func (*GetNodeResourcesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use ExtendTimeoutRequest.ProtoReflect.Descriptor instead.
+func (*ExtendTimeoutRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{77}
 }
 
-type GetNodeResourcesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
-	Resources     *NodeResources         `protobuf:"bytes,3,opt,name=resources,proto3,oneof" json:"resources,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ExtendTimeoutRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
 }
 
-func (x *GetNodeResourcesResponse) Reset() {
-	*x = GetNodeResourcesResponse{}
-	mi := &file_proto_container_manager_proto_msgTypes[22]
+func (x *ExtendTimeoutRequest) GetExtendSecs() uint32 {
+	if x != nil {
+		return x.ExtendSecs
+	}
+	return 0
+}
+
+type ExtendTimeoutResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Unix timestamp the container is now terminated at for its run timeout;
+	// unset while it is still starting, the run time counting from its start
+	RunDeadline *int64 `protobuf:"varint,3,opt,name=run_deadline,json=runDeadline,proto3,oneof" json:"run_deadline,omitempty"`
+	// Seconds the run timeout has been extended by in total
+	TotalExtensionSecs uint32 `protobuf:"varint,4,opt,name=total_extension_secs,json=totalExtensionSecs,proto3" json:"total_extension_secs,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ExtendTimeoutResponse) Reset() {
+	*x = ExtendTimeoutResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[78]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetNodeResourcesResponse) String() string {
+func (x *ExtendTimeoutResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetNodeResourcesResponse) ProtoMessage() {}
+func (*ExtendTimeoutResponse) ProtoMessage() {}
 
-func (x *GetNodeResourcesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[22]
+func (x *ExtendTimeoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[78]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1697,73 +6459,67 @@ func (x *GetNodeResourcesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetNodeResourcesResponse.ProtoReflect.Descriptor instead.
-func (*GetNodeResourcesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use ExtendTimeoutResponse.ProtoReflect.Descriptor instead.
+func (*ExtendTimeoutResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{78}
 }
 
-func (x *GetNodeResourcesResponse) GetSuccess() bool {
+func (x *ExtendTimeoutResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *GetNodeResourcesResponse) GetError() string {
+func (x *ExtendTimeoutResponse) GetError() string {
 	if x != nil && x.Error != nil {
 		return *x.Error
 	}
 	return ""
 }
 
-func (x *GetNodeResourcesResponse) GetResources() *NodeResources {
-	if x != nil {
-		return x.Resources
+func (x *ExtendTimeoutResponse) GetRunDeadline() int64 {
+	if x != nil && x.RunDeadline != nil {
+		return *x.RunDeadline
 	}
-	return nil
+	return 0
 }
 
-type NodeResources struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// CPU
-	CpuCores        uint32  `protobuf:"varint,1,opt,name=cpu_cores,json=cpuCores,proto3" json:"cpu_cores,omitempty"`
-	CpuUsagePercent float32 `protobuf:"fixed32,2,opt,name=cpu_usage_percent,json=cpuUsagePercent,proto3" json:"cpu_usage_percent,omitempty"`
-	// Memory (bytes)
-	MemoryTotalBytes     uint64  `protobuf:"varint,3,opt,name=memory_total_bytes,json=memoryTotalBytes,proto3" json:"memory_total_bytes,omitempty"`
-	MemoryAvailableBytes uint64  `protobuf:"varint,4,opt,name=memory_available_bytes,json=memoryAvailableBytes,proto3" json:"memory_available_bytes,omitempty"`
-	MemoryUsedBytes      uint64  `protobuf:"varint,5,opt,name=memory_used_bytes,json=memoryUsedBytes,proto3" json:"memory_used_bytes,omitempty"`
-	MemoryUsagePercent   float32 `protobuf:"fixed32,6,opt,name=memory_usage_percent,json=memoryUsagePercent,proto3" json:"memory_usage_percent,omitempty"`
-	// Disk (bytes)
-	DiskTotalBytes     uint64  `protobuf:"varint,7,opt,name=disk_total_bytes,json=diskTotalBytes,proto3" json:"disk_total_bytes,omitempty"`
-	DiskAvailableBytes uint64  `protobuf:"varint,8,opt,name=disk_available_bytes,json=diskAvailableBytes,proto3" json:"disk_available_bytes,omitempty"`
-	DiskUsedBytes      uint64  `protobuf:"varint,9,opt,name=disk_used_bytes,json=diskUsedBytes,proto3" json:"disk_used_bytes,omitempty"`
-	DiskUsagePercent   float32 `protobuf:"fixed32,10,opt,name=disk_usage_percent,json=diskUsagePercent,proto3" json:"disk_usage_percent,omitempty"`
-	// Container stats
-	RunningContainers uint32 `protobuf:"varint,11,opt,name=running_containers,json=runningContainers,proto3" json:"running_containers,omitempty"`
-	TotalContainers   uint32 `protobuf:"varint,12,opt,name=total_containers,json=totalContainers,proto3" json:"total_containers,omitempty"`
-	// Load averages
-	Load_1Min     float32 `protobuf:"fixed32,13,opt,name=load_1min,json=load1min,proto3" json:"load_1min,omitempty"`
-	Load_5Min     float32 `protobuf:"fixed32,14,opt,name=load_5min,json=load5min,proto3" json:"load_5min,omitempty"`
-	Load_15Min    float32 `protobuf:"fixed32,15,opt,name=load_15min,json=load15min,proto3" json:"load_15min,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ExtendTimeoutResponse) GetTotalExtensionSecs() uint32 {
+	if x != nil {
+		return x.TotalExtensionSecs
+	}
+	return 0
 }
 
-func (x *NodeResources) Reset() {
-	*x = NodeResources{}
-	mi := &file_proto_container_manager_proto_msgTypes[23]
+type StartCaptureRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// tcpdump filter expression, ANDed with the container's own traffic
+	Filter *string `protobuf:"bytes,2,opt,name=filter,proto3,oneof" json:"filter,omitempty"`
+	// Stop after this many packets (default 10000)
+	MaxPackets *uint32 `protobuf:"varint,3,opt,name=max_packets,json=maxPackets,proto3,oneof" json:"max_packets,omitempty"`
+	// Stop after this many seconds (default 60, max 600)
+	MaxDurationSecs *uint32 `protobuf:"varint,4,opt,name=max_duration_secs,json=maxDurationSecs,proto3,oneof" json:"max_duration_secs,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StartCaptureRequest) Reset() {
+	*x = StartCaptureRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[79]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NodeResources) String() string {
+func (x *StartCaptureRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NodeResources) ProtoMessage() {}
+func (*StartCaptureRequest) ProtoMessage() {}
 
-func (x *NodeResources) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[23]
+func (x *StartCaptureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[79]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1774,137 +6530,231 @@ func (x *NodeResources) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NodeResources.ProtoReflect.Descriptor instead.
-func (*NodeResources) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use StartCaptureRequest.ProtoReflect.Descriptor instead.
+func (*StartCaptureRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{79}
 }
 
-func (x *NodeResources) GetCpuCores() uint32 {
+func (x *StartCaptureRequest) GetContainerId() string {
 	if x != nil {
-		return x.CpuCores
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *StartCaptureRequest) GetFilter() string {
+	if x != nil && x.Filter != nil {
+		return *x.Filter
+	}
+	return ""
+}
+
+func (x *StartCaptureRequest) GetMaxPackets() uint32 {
+	if x != nil && x.MaxPackets != nil {
+		return *x.MaxPackets
 	}
 	return 0
 }
 
-func (x *NodeResources) GetCpuUsagePercent() float32 {
-	if x != nil {
-		return x.CpuUsagePercent
+func (x *StartCaptureRequest) GetMaxDurationSecs() uint32 {
+	if x != nil && x.MaxDurationSecs != nil {
+		return *x.MaxDurationSecs
 	}
 	return 0
 }
 
-func (x *NodeResources) GetMemoryTotalBytes() uint64 {
+type StartCaptureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	CaptureId     *string                `protobuf:"bytes,3,opt,name=capture_id,json=captureId,proto3,oneof" json:"capture_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartCaptureResponse) Reset() {
+	*x = StartCaptureResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartCaptureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartCaptureResponse) ProtoMessage() {}
+
+func (x *StartCaptureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[80]
 	if x != nil {
-		return x.MemoryTotalBytes
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartCaptureResponse.ProtoReflect.Descriptor instead.
+func (*StartCaptureResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *StartCaptureResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StartCaptureResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *StartCaptureResponse) GetCaptureId() string {
+	if x != nil && x.CaptureId != nil {
+		return *x.CaptureId
+	}
+	return ""
+}
+
+type StopCaptureRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	CaptureId     string                 `protobuf:"bytes,2,opt,name=capture_id,json=captureId,proto3" json:"capture_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopCaptureRequest) Reset() {
+	*x = StopCaptureRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *NodeResources) GetMemoryAvailableBytes() uint64 {
-	if x != nil {
-		return x.MemoryAvailableBytes
-	}
-	return 0
+func (x *StopCaptureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *NodeResources) GetMemoryUsedBytes() uint64 {
+func (*StopCaptureRequest) ProtoMessage() {}
+
+func (x *StopCaptureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[81]
 	if x != nil {
-		return x.MemoryUsedBytes
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *NodeResources) GetMemoryUsagePercent() float32 {
-	if x != nil {
-		return x.MemoryUsagePercent
-	}
-	return 0
+// Deprecated: Use StopCaptureRequest.ProtoReflect.Descriptor instead.
+func (*StopCaptureRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{81}
 }
 
-func (x *NodeResources) GetDiskTotalBytes() uint64 {
+func (x *StopCaptureRequest) GetContainerId() string {
 	if x != nil {
-		return x.DiskTotalBytes
+		return x.ContainerId
 	}
-	return 0
+	return ""
 }
 
-func (x *NodeResources) GetDiskAvailableBytes() uint64 {
+func (x *StopCaptureRequest) GetCaptureId() string {
 	if x != nil {
-		return x.DiskAvailableBytes
+		return x.CaptureId
 	}
-	return 0
+	return ""
 }
 
-func (x *NodeResources) GetDiskUsedBytes() uint64 {
-	if x != nil {
-		return x.DiskUsedBytes
-	}
-	return 0
+type StopCaptureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NodeResources) GetDiskUsagePercent() float32 {
-	if x != nil {
-		return x.DiskUsagePercent
-	}
-	return 0
+func (x *StopCaptureResponse) Reset() {
+	*x = StopCaptureResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *NodeResources) GetRunningContainers() uint32 {
-	if x != nil {
-		return x.RunningContainers
-	}
-	return 0
+func (x *StopCaptureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *NodeResources) GetTotalContainers() uint32 {
+func (*StopCaptureResponse) ProtoMessage() {}
+
+func (x *StopCaptureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[82]
 	if x != nil {
-		return x.TotalContainers
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *NodeResources) GetLoad_1Min() float32 {
-	if x != nil {
-		return x.Load_1Min
-	}
-	return 0
+// Deprecated: Use StopCaptureResponse.ProtoReflect.Descriptor instead.
+func (*StopCaptureResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{82}
 }
 
-func (x *NodeResources) GetLoad_5Min() float32 {
+func (x *StopCaptureResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Load_5Min
+		return x.Success
 	}
-	return 0
+	return false
 }
 
-func (x *NodeResources) GetLoad_15Min() float32 {
-	if x != nil {
-		return x.Load_15Min
+func (x *StopCaptureResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
 	}
-	return 0
+	return ""
 }
 
-type GetAvailableImagesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type UploadFileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required on the first message, ignored afterwards
+	ContainerId string `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Absolute directory the archive is extracted into; must already exist.
+	// Required on the first message, ignored afterwards
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Next chunk of the tar archive
+	Data          []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAvailableImagesRequest) Reset() {
-	*x = GetAvailableImagesRequest{}
-	mi := &file_proto_container_manager_proto_msgTypes[24]
+func (x *UploadFileRequest) Reset() {
+	*x = UploadFileRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[83]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAvailableImagesRequest) String() string {
+func (x *UploadFileRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAvailableImagesRequest) ProtoMessage() {}
+func (*UploadFileRequest) ProtoMessage() {}
 
-func (x *GetAvailableImagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[24]
+func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[83]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1915,35 +6765,57 @@ func (x *GetAvailableImagesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAvailableImagesRequest.ProtoReflect.Descriptor instead.
-func (*GetAvailableImagesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
+func (*UploadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{83}
 }
 
-type GetAvailableImagesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
-	Images        []*ImageInfo           `protobuf:"bytes,3,rep,name=images,proto3" json:"images,omitempty"`
+func (x *UploadFileRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type UploadFileResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Size of the archive received
+	Bytes         uint64 `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAvailableImagesResponse) Reset() {
-	*x = GetAvailableImagesResponse{}
-	mi := &file_proto_container_manager_proto_msgTypes[25]
+func (x *UploadFileResponse) Reset() {
+	*x = UploadFileResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[84]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAvailableImagesResponse) String() string {
+func (x *UploadFileResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAvailableImagesResponse) ProtoMessage() {}
+func (*UploadFileResponse) ProtoMessage() {}
 
-func (x *GetAvailableImagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[25]
+func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[84]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1954,57 +6826,56 @@ func (x *GetAvailableImagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAvailableImagesResponse.ProtoReflect.Descriptor instead.
-func (*GetAvailableImagesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
+func (*UploadFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{84}
 }
 
-func (x *GetAvailableImagesResponse) GetSuccess() bool {
+func (x *UploadFileResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *GetAvailableImagesResponse) GetError() string {
+func (x *UploadFileResponse) GetError() string {
 	if x != nil && x.Error != nil {
 		return *x.Error
 	}
 	return ""
 }
 
-func (x *GetAvailableImagesResponse) GetImages() []*ImageInfo {
+func (x *UploadFileResponse) GetBytes() uint64 {
 	if x != nil {
-		return x.Images
+		return x.Bytes
 	}
-	return nil
+	return 0
 }
 
-type ImageInfo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	RepoTags      []string               `protobuf:"bytes,2,rep,name=repo_tags,json=repoTags,proto3" json:"repo_tags,omitempty"`
-	SizeBytes     uint64                 `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
-	Created       string                 `protobuf:"bytes,4,opt,name=created,proto3" json:"created,omitempty"`
+type DownloadFileRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Absolute path of the file or directory to copy
+	Path          string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ImageInfo) Reset() {
-	*x = ImageInfo{}
-	mi := &file_proto_container_manager_proto_msgTypes[26]
+func (x *DownloadFileRequest) Reset() {
+	*x = DownloadFileRequest{}
+	mi := &file_proto_container_manager_proto_msgTypes[85]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ImageInfo) String() string {
+func (x *DownloadFileRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ImageInfo) ProtoMessage() {}
+func (*DownloadFileRequest) ProtoMessage() {}
 
-func (x *ImageInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_container_manager_proto_msgTypes[26]
+func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[85]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2015,44 +6886,75 @@ func (x *ImageInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ImageInfo.ProtoReflect.Descriptor instead.
-func (*ImageInfo) Descriptor() ([]byte, []int) {
-	return file_proto_container_manager_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{85}
 }
 
-func (x *ImageInfo) GetId() string {
+func (x *DownloadFileRequest) GetContainerId() string {
 	if x != nil {
-		return x.Id
+		return x.ContainerId
 	}
 	return ""
 }
 
-func (x *ImageInfo) GetRepoTags() []string {
+func (x *DownloadFileRequest) GetPath() string {
 	if x != nil {
-		return x.RepoTags
+		return x.Path
 	}
-	return nil
+	return ""
 }
 
-func (x *ImageInfo) GetSizeBytes() uint64 {
+type DownloadFileResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Next chunk of the tar archive
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileResponse) Reset() {
+	*x = DownloadFileResponse{}
+	mi := &file_proto_container_manager_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileResponse) ProtoMessage() {}
+
+func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_container_manager_proto_msgTypes[86]
 	if x != nil {
-		return x.SizeBytes
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *ImageInfo) GetCreated() string {
+// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
+func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_container_manager_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *DownloadFileResponse) GetData() []byte {
 	if x != nil {
-		return x.Created
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
 var File_proto_container_manager_proto protoreflect.FileDescriptor
 
 const file_proto_container_manager_proto_rawDesc = "" +
 	"\n" +
-	"\x1dproto/container_manager.proto\x12\x11container_manager\"\xf7\x01\n" +
+	"\x1dproto/container_manager.proto\x12\x11container_manager\"\xb8\x05\n" +
 	"\n" +
 	"RunRequest\x12<\n" +
 	"\x06create\x18\x01 \x01(\v2\".container_manager.CreateContainerH\x00R\x06create\x12\x16\n" +
@@ -2060,15 +6962,54 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\vclose_stdin\x18\x03 \x01(\bH\x00R\n" +
 	"closeStdin\x12E\n" +
 	"\tterminate\x18\x04 \x01(\v2%.container_manager.TerminateContainerH\x00R\tterminate\x12\x1e\n" +
-	"\theartbeat\x18\x05 \x01(\bH\x00R\theartbeatB\t\n" +
-	"\arequest\"\x86\x01\n" +
+	"\theartbeat\x18\x05 \x01(\bH\x00R\theartbeat\x12\\\n" +
+	"\x15update_network_policy\x18\x06 \x01(\v2&.container_manager.UpdateNetworkPolicyH\x00R\x13updateNetworkPolicy\x124\n" +
+	"\x04exec\x18\a \x01(\v2\x1e.container_manager.ExecRequestH\x00R\x04exec\x123\n" +
+	"\x06resize\x18\b \x01(\v2\x19.container_manager.ResizeH\x00R\x06resize\x12\x16\n" +
+	"\x05pause\x18\t \x01(\bH\x00R\x05pause\x12\x1a\n" +
+	"\aunpause\x18\n" +
+	" \x01(\bH\x00R\aunpause\x123\n" +
+	"\x06signal\x18\v \x01(\v2\x19.container_manager.SignalH\x00R\x06signal\x12<\n" +
+	"\x06attach\x18\f \x01(\v2\".container_manager.AttachContainerH\x00R\x06attach\x12O\n" +
+	"\x10update_resources\x18\r \x01(\v2\".container_manager.UpdateResourcesH\x00R\x0fupdateResourcesB\t\n" +
+	"\arequest\"R\n" +
+	"\x0fUpdateResources\x12?\n" +
+	"\tresources\x18\x01 \x01(\v2!.container_manager.ResourceLimitsR\tresources\"\x1c\n" +
+	"\x06Signal\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"0\n" +
+	"\x06Resize\x12\x12\n" +
+	"\x04rows\x18\x01 \x01(\rR\x04rows\x12\x12\n" +
+	"\x04cols\x18\x02 \x01(\rR\x04cols\"\xa8\x02\n" +
+	"\vExecRequest\x12\x1c\n" +
+	"\aexec_id\x18\x01 \x01(\tH\x00R\x06execId\x88\x01\x01\x12\x18\n" +
+	"\acommand\x18\x02 \x03(\tR\acommand\x129\n" +
+	"\x03env\x18\x03 \x03(\v2'.container_manager.ExecRequest.EnvEntryR\x03env\x12\x1d\n" +
+	"\aworkdir\x18\x04 \x01(\tH\x01R\aworkdir\x88\x01\x01\x12&\n" +
+	"\ftimeout_secs\x18\x05 \x01(\rH\x02R\vtimeoutSecs\x88\x01\x01\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\n" +
+	"\n" +
+	"\b_exec_idB\n" +
+	"\n" +
+	"\b_workdirB\x0f\n" +
+	"\r_timeout_secs\"Q\n" +
+	"\x13UpdateNetworkPolicy\x12:\n" +
+	"\anetwork\x18\x01 \x01(\v2 .container_manager.NetworkConfigR\anetwork\"\xe4\x01\n" +
 	"\x0fCreateContainer\x12&\n" +
 	"\fcontainer_id\x18\x01 \x01(\tH\x00R\vcontainerId\x88\x01\x01\x12:\n" +
-	"\x06config\x18\x02 \x01(\v2\".container_manager.ContainerConfigR\x06configB\x0f\n" +
-	"\r_container_id\"M\n" +
+	"\x06config\x18\x02 \x01(\v2\".container_manager.ContainerConfigR\x06config\x129\n" +
+	"\astreams\x18\x03 \x03(\x0e2\x1f.container_manager.OutputStreamR\astreams\x12!\n" +
+	"\ftyped_events\x18\x04 \x01(\bR\vtypedEventsB\x0f\n" +
+	"\r_container_id\"\xb7\x01\n" +
+	"\x0fAttachContainer\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12#\n" +
+	"\routput_offset\x18\x02 \x01(\x04R\foutputOffset\x129\n" +
+	"\astreams\x18\x03 \x03(\x0e2\x1f.container_manager.OutputStreamR\astreams\x12!\n" +
+	"\ftyped_events\x18\x04 \x01(\bR\vtypedEvents\"M\n" +
 	"\x12TerminateContainer\x12\x14\n" +
 	"\x05force\x18\x01 \x01(\bR\x05force\x12!\n" +
-	"\ftimeout_secs\x18\x02 \x01(\rR\vtimeoutSecs\"\x9a\x02\n" +
+	"\ftimeout_secs\x18\x02 \x01(\rR\vtimeoutSecs\"\xb2\x04\n" +
 	"\vRunResponse\x12!\n" +
 	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12?\n" +
 	"\acreated\x18\x02 \x01(\v2#.container_manager.ContainerCreatedH\x00R\acreated\x12\x18\n" +
@@ -2076,14 +7017,141 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\x06stderr\x18\x04 \x01(\fH\x00R\x06stderr\x126\n" +
 	"\x04exit\x18\x05 \x01(\v2 .container_manager.ContainerExitH\x00R\x04exit\x12\x16\n" +
 	"\x05error\x18\x06 \x01(\tH\x00R\x05error\x12\x1a\n" +
-	"\amessage\x18\a \x01(\tH\x00R\amessageB\a\n" +
-	"\x05event\"n\n" +
+	"\amessage\x18\a \x01(\tH\x00R\amessage\x12;\n" +
+	"\acapture\x18\b \x01(\v2\x1f.container_manager.CaptureChunkH\x00R\acapture\x123\n" +
+	"\x04exec\x18\t \x01(\v2\x1d.container_manager.ExecOutputH\x00R\x04exec\x12B\n" +
+	"\battached\x18\n" +
+	" \x01(\v2$.container_manager.ContainerAttachedH\x00R\battached\x12;\n" +
+	"\vtyped_event\x18\f \x01(\v2\x18.container_manager.EventH\x00R\n" +
+	"typedEvent\x12#\n" +
+	"\routput_offset\x18\v \x01(\x04R\foutputOffsetB\a\n" +
+	"\x05event\"\xaf\x01\n" +
+	"\n" +
+	"ExecOutput\x12\x17\n" +
+	"\aexec_id\x18\x01 \x01(\tR\x06execId\x12\x16\n" +
+	"\x06stdout\x18\x02 \x01(\fR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x03 \x01(\fR\x06stderr\x12\x16\n" +
+	"\x06exited\x18\x04 \x01(\bR\x06exited\x12\x1b\n" +
+	"\texit_code\x18\x05 \x01(\x05R\bexitCode\x12\x19\n" +
+	"\x05error\x18\x06 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"z\n" +
+	"\fCaptureChunk\x12\x1d\n" +
+	"\n" +
+	"capture_id\x18\x01 \x01(\tR\tcaptureId\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x12\n" +
+	"\x04done\x18\x03 \x01(\bR\x04done\x12\x19\n" +
+	"\x05error\x18\x04 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"\xa4\x01\n" +
 	"\x10ContainerCreated\x12!\n" +
 	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x127\n" +
-	"\x05state\x18\x02 \x01(\x0e2!.container_manager.ContainerStateR\x05state\"J\n" +
+	"\x05state\x18\x02 \x01(\x0e2!.container_manager.ContainerStateR\x05state\x124\n" +
+	"\x16heartbeat_timeout_secs\x18\x03 \x01(\rR\x14heartbeatTimeoutSecs\"\xca\x01\n" +
+	"\x11ContainerAttached\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x127\n" +
+	"\x05state\x18\x02 \x01(\x0e2!.container_manager.ContainerStateR\x05state\x12#\n" +
+	"\routput_offset\x18\x03 \x01(\x04R\foutputOffset\x124\n" +
+	"\x16heartbeat_timeout_secs\x18\x04 \x01(\rR\x14heartbeatTimeoutSecs\"\x95\x01\n" +
 	"\rContainerExit\x12\x1b\n" +
 	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x1c\n" +
-	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\"\xa3\x04\n" +
+	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\x122\n" +
+	"\x12termination_reason\x18\x03 \x01(\tH\x00R\x11terminationReason\x88\x01\x01B\x15\n" +
+	"\x13_termination_reason\"\x86\v\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x04R\x03seq\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\tR\ttimestamp\x121\n" +
+	"\x03log\x18\n" +
+	" \x01(\v2\x1d.container_manager.LogMessageH\x00R\x03log\x12S\n" +
+	"\x12image_pull_started\x18\v \x01(\v2#.container_manager.ImagePullStartedH\x00R\x10imagePullStarted\x12Y\n" +
+	"\x14image_pull_completed\x18\f \x01(\v2%.container_manager.ImagePullCompletedH\x00R\x12imagePullCompleted\x12R\n" +
+	"\x11container_started\x18\r \x01(\v2#.container_manager.ContainerStartedH\x00R\x10containerStarted\x12S\n" +
+	"\x12container_ip_ready\x18\x0e \x01(\v2#.container_manager.ContainerIPReadyH\x00R\x10containerIpReady\x12b\n" +
+	"\x17network_isolation_ready\x18\x0f \x01(\v2(.container_manager.NetworkIsolationReadyH\x00R\x15networkIsolationReady\x12Y\n" +
+	"\x14container_port_ready\x18\x10 \x01(\v2%.container_manager.ContainerPortReadyH\x00R\x12containerPortReady\x12L\n" +
+	"\x0fcontainer_ready\x18\x11 \x01(\v2!.container_manager.ContainerReadyH\x00R\x0econtainerReady\x12L\n" +
+	"\x0fnetwork_attempt\x18\x12 \x01(\v2!.container_manager.NetworkAttemptH\x00R\x0enetworkAttempt\x12^\n" +
+	"\x15container_terminating\x18\x13 \x01(\v2'.container_manager.ContainerTerminatingH\x00R\x14containerTerminating\x12O\n" +
+	"\x10container_exited\x18\x14 \x01(\v2\".container_manager.ContainerExitedH\x00R\x0fcontainerExited\x128\n" +
+	"\x05stats\x18\x15 \x01(\v2 .container_manager.ResourceStatsH\x00R\x05stats\x12F\n" +
+	"\tlifecycle\x18\x16 \x01(\v2&.container_manager.LifecycleTransitionH\x00R\tlifecycle\x12_\n" +
+	"\x16container_idle_timeout\x18\x17 \x01(\v2'.container_manager.ContainerIdleTimeoutH\x00R\x14containerIdleTimeout\x12C\n" +
+	"\frunner_hello\x18\x18 \x01(\v2\x1e.container_manager.RunnerHelloH\x00R\vrunnerHello\x12O\n" +
+	"\x10runner_heartbeat\x18\x19 \x01(\v2\".container_manager.RunnerHeartbeatH\x00R\x0frunnerHeartbeat\x12\x1d\n" +
+	"\tdata_json\x18\x1e \x01(\tH\x00R\bdataJsonB\t\n" +
+	"\apayload\"Y\n" +
+	"\n" +
+	"LogMessage\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\"\n" +
+	"\n" +
+	"error_code\x18\x02 \x01(\tH\x00R\terrorCode\x88\x01\x01B\r\n" +
+	"\v_error_code\"j\n" +
+	"\x10ImagePullStarted\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\tR\x05image\x12\x1a\n" +
+	"\bregistry\x18\x02 \x01(\tR\bregistry\x12$\n" +
+	"\rauthenticated\x18\x03 \x01(\bR\rauthenticated\"o\n" +
+	"\x12ImagePullCompleted\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\tR\x05image\x12\x1a\n" +
+	"\bregistry\x18\x02 \x01(\tR\bregistry\x12'\n" +
+	"\x0falready_present\x18\x03 \x01(\bR\x0ealreadyPresent\"K\n" +
+	"\x10ContainerStarted\x12%\n" +
+	"\x0econtainer_name\x18\x01 \x01(\tR\rcontainerName\x12\x10\n" +
+	"\x03pid\x18\x02 \x01(\x03R\x03pid\"K\n" +
+	"\x10ContainerIPReady\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x01 \x01(\tR\tipAddress\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\tR\anetwork\"]\n" +
+	"\x15NetworkIsolationReady\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x01 \x01(\tR\tchainName\x12%\n" +
+	"\x0edefault_policy\x18\x02 \x01(\tR\rdefaultPolicy\"t\n" +
+	"\x12ContainerPortReady\x12%\n" +
+	"\x0econtainer_port\x18\x01 \x01(\rR\rcontainerPort\x12\x1b\n" +
+	"\thost_port\x18\x02 \x01(\rR\bhostPort\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\"/\n" +
+	"\x0eContainerReady\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x01 \x01(\tR\tipAddress\"\x85\x01\n" +
+	"\x0eNetworkAttempt\x12\x18\n" +
+	"\averdict\x18\x01 \x01(\tR\averdict\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x10\n" +
+	"\x03src\x18\x03 \x01(\tR\x03src\x12\x10\n" +
+	"\x03dst\x18\x04 \x01(\tR\x03dst\x12\x19\n" +
+	"\bdst_port\x18\x05 \x01(\rR\adstPort\"D\n" +
+	"\x14ContainerTerminating\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"}\n" +
+	"\x0fContainerExited\x12\x1b\n" +
+	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x1a\n" +
+	"\bduration\x18\x02 \x01(\tR\bduration\x12\"\n" +
+	"\n" +
+	"error_code\x18\x03 \x01(\tH\x00R\terrorCode\x88\x01\x01B\r\n" +
+	"\v_error_code\"\xde\x02\n" +
+	"\rResourceStats\x12\x1f\n" +
+	"\vcpu_percent\x18\x01 \x01(\x01R\n" +
+	"cpuPercent\x12\"\n" +
+	"\rcpu_time_secs\x18\x02 \x01(\x01R\vcpuTimeSecs\x12,\n" +
+	"\x12memory_usage_bytes\x18\x03 \x01(\x04R\x10memoryUsageBytes\x12,\n" +
+	"\x12memory_limit_bytes\x18\x04 \x01(\x04R\x10memoryLimitBytes\x12 \n" +
+	"\fnet_rx_bytes\x18\x05 \x01(\x04R\n" +
+	"netRxBytes\x12 \n" +
+	"\fnet_tx_bytes\x18\x06 \x01(\x04R\n" +
+	"netTxBytes\x12(\n" +
+	"\x10block_read_bytes\x18\a \x01(\x04R\x0eblockReadBytes\x12*\n" +
+	"\x11block_write_bytes\x18\b \x01(\x04R\x0fblockWriteBytes\x12\x12\n" +
+	"\x04pids\x18\t \x01(\x04R\x04pids\"Q\n" +
+	"\x13LifecycleTransition\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\".\n" +
+	"\x14ContainerIdleTimeout\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\"P\n" +
+	"\vRunnerHello\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12'\n" +
+	"\x0fschema_versions\x18\x02 \x03(\rR\x0eschemaVersions\"H\n" +
+	"\x0fRunnerHeartbeat\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\tR\x05phase\x12\x1f\n" +
+	"\vuptime_secs\x18\x02 \x01(\x03R\n" +
+	"uptimeSecs\"\xcb\x10\n" +
 	"\x0fContainerConfig\x12;\n" +
 	"\n" +
 	"image_spec\x18\x01 \x01(\v2\x1c.container_manager.ImageSpecR\timageSpec\x12\x18\n" +
@@ -2094,10 +7162,41 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\anetwork\x18\x06 \x01(\v2 .container_manager.NetworkConfigH\x02R\anetwork\x88\x01\x01\x12&\n" +
 	"\ftimeout_secs\x18\a \x01(\rH\x03R\vtimeoutSecs\x88\x01\x01\x12\x1d\n" +
 	"\acleanup\x18\b \x01(\bH\x04R\acleanup\x88\x01\x01\x12\x12\n" +
-	"\x04args\x18\t \x03(\tR\x04args\x1a6\n" +
+	"\x04args\x18\t \x03(\tR\x04args\x124\n" +
+	"\x05ports\x18\n" +
+	" \x03(\v2\x1e.container_manager.PortMappingR\x05ports\x12?\n" +
+	"\tworkspace\x18\v \x01(\v2\x1c.container_manager.WorkspaceH\x05R\tworkspace\x88\x01\x01\x12\x15\n" +
+	"\x03tty\x18\f \x01(\bH\x06R\x03tty\x88\x01\x01\x12&\n" +
+	"\frestore_from\x18\r \x01(\tH\aR\vrestoreFrom\x88\x01\x01\x123\n" +
+	"\x13stats_interval_secs\x18\x0e \x01(\rH\bR\x11statsIntervalSecs\x88\x01\x01\x12O\n" +
+	"\x0freadiness_probe\x18\x0f \x01(\v2!.container_manager.ReadinessProbeH\tR\x0ereadinessProbe\x88\x01\x01\x12/\n" +
+	"\x11idle_timeout_secs\x18\x10 \x01(\rH\n" +
+	"R\x0fidleTimeoutSecs\x88\x01\x01\x12C\n" +
+	"\vidle_action\x18\x11 \x01(\x0e2\x1d.container_manager.IdleActionH\vR\n" +
+	"idleAction\x88\x01\x01\x122\n" +
+	"\x13cpu_time_limit_secs\x18\x12 \x01(\rH\fR\x10cpuTimeLimitSecs\x88\x01\x01\x12\x1d\n" +
+	"\aruntime\x18\x13 \x01(\tH\rR\aruntime\x88\x01\x01\x12O\n" +
+	"\x0fruntime_options\x18\x14 \x01(\v2!.container_manager.RuntimeOptionsH\x0eR\x0eruntimeOptions\x88\x01\x01\x12;\n" +
+	"\bsidecars\x18\x15 \x03(\v2\x1f.container_manager.AuxContainerR\bsidecars\x12H\n" +
+	"\x0finit_containers\x18\x16 \x03(\v2\x1f.container_manager.AuxContainerR\x0einitContainers\x12G\n" +
+	"\x0fpost_exit_hooks\x18\x17 \x03(\v2\x1f.container_manager.PostExitHookR\rpostExitHooks\x12F\n" +
+	"\x06labels\x18\x18 \x03(\v2..container_manager.ContainerConfig.LabelsEntryR\x06labels\x12P\n" +
+	"\n" +
+	"secret_env\x18\x19 \x03(\v21.container_manager.ContainerConfig.SecretEnvEntryR\tsecretEnv\x12!\n" +
+	"\fquiet_output\x18\x1a \x01(\bR\vquietOutput\x12\x1a\n" +
+	"\bdetached\x18\x1b \x01(\bR\bdetached\x127\n" +
+	"\x15disconnect_grace_secs\x18\x1c \x01(\rH\x0fR\x13disconnectGraceSecs\x88\x01\x01\x129\n" +
+	"\x16heartbeat_timeout_secs\x18\x1e \x01(\rH\x10R\x14heartbeatTimeoutSecs\x88\x01\x01\x126\n" +
+	"\bwebhooks\x18\x1d \x03(\v2\x1a.container_manager.WebhookR\bwebhooks\x1a6\n" +
 	"\bEnvEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aZ\n" +
+	"\x0eSecretEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.container_manager.SecretRefR\x05value:\x028\x01B\n" +
 	"\n" +
 	"\b_workdirB\f\n" +
 	"\n" +
@@ -2106,7 +7205,90 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\b_networkB\x0f\n" +
 	"\r_timeout_secsB\n" +
 	"\n" +
-	"\b_cleanup\"\x96\x01\n" +
+	"\b_cleanupB\f\n" +
+	"\n" +
+	"_workspaceB\x06\n" +
+	"\x04_ttyB\x0f\n" +
+	"\r_restore_fromB\x16\n" +
+	"\x14_stats_interval_secsB\x12\n" +
+	"\x10_readiness_probeB\x14\n" +
+	"\x12_idle_timeout_secsB\x0e\n" +
+	"\f_idle_actionB\x16\n" +
+	"\x14_cpu_time_limit_secsB\n" +
+	"\n" +
+	"\b_runtimeB\x12\n" +
+	"\x10_runtime_optionsB\x18\n" +
+	"\x16_disconnect_grace_secsB\x19\n" +
+	"\x17_heartbeat_timeout_secs\"y\n" +
+	"\aWebhook\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x129\n" +
+	"\x06secret\x18\x02 \x01(\v2\x1c.container_manager.SecretRefH\x00R\x06secret\x88\x01\x01\x12\x16\n" +
+	"\x06events\x18\x03 \x03(\tR\x06eventsB\t\n" +
+	"\a_secret\">\n" +
+	"\tSecretRef\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x15\n" +
+	"\x03key\x18\x02 \x01(\tH\x00R\x03key\x88\x01\x01B\x06\n" +
+	"\x04_key\"\xe3\x01\n" +
+	"\fPostExitHook\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12B\n" +
+	"\tcontainer\x18\x02 \x01(\v2\x1f.container_manager.AuxContainerH\x00R\tcontainer\x88\x01\x01\x12$\n" +
+	"\vwebhook_url\x18\x03 \x01(\tH\x01R\n" +
+	"webhookUrl\x88\x01\x01\x12&\n" +
+	"\ftimeout_secs\x18\x04 \x01(\rH\x02R\vtimeoutSecs\x88\x01\x01B\f\n" +
+	"\n" +
+	"_containerB\x0e\n" +
+	"\f_webhook_urlB\x0f\n" +
+	"\r_timeout_secs\"\xd5\x02\n" +
+	"\fAuxContainer\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12;\n" +
+	"\n" +
+	"image_spec\x18\x02 \x01(\v2\x1c.container_manager.ImageSpecR\timageSpec\x12\x18\n" +
+	"\acommand\x18\x03 \x03(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x04 \x03(\tR\x04args\x12:\n" +
+	"\x03env\x18\x05 \x03(\v2(.container_manager.AuxContainer.EnvEntryR\x03env\x12D\n" +
+	"\tresources\x18\x06 \x01(\v2!.container_manager.ResourceLimitsH\x00R\tresources\x88\x01\x01\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
+	"\n" +
+	"_resources\"\x97\x01\n" +
+	"\x0eRuntimeOptions\x12\x1f\n" +
+	"\bplatform\x18\x01 \x01(\tH\x00R\bplatform\x88\x01\x01\x12\x1d\n" +
+	"\anetwork\x18\x02 \x01(\tH\x01R\anetwork\x88\x01\x01\x12\x1f\n" +
+	"\bdirectfs\x18\x03 \x01(\bH\x02R\bdirectfs\x88\x01\x01B\v\n" +
+	"\t_platformB\n" +
+	"\n" +
+	"\b_networkB\v\n" +
+	"\t_directfs\"\xb5\x02\n" +
+	"\x0eReadinessProbe\x12\x17\n" +
+	"\x04port\x18\x01 \x01(\rH\x00R\x04port\x88\x01\x01\x12 \n" +
+	"\thttp_path\x18\x02 \x01(\tH\x01R\bhttpPath\x88\x01\x01\x12\x18\n" +
+	"\acommand\x18\x03 \x03(\tR\acommand\x121\n" +
+	"\x12initial_delay_secs\x18\x04 \x01(\rH\x02R\x10initialDelaySecs\x88\x01\x01\x12$\n" +
+	"\vperiod_secs\x18\x05 \x01(\rH\x03R\n" +
+	"periodSecs\x88\x01\x01\x12&\n" +
+	"\ftimeout_secs\x18\x06 \x01(\rH\x04R\vtimeoutSecs\x88\x01\x01B\a\n" +
+	"\x05_portB\f\n" +
+	"\n" +
+	"_http_pathB\x15\n" +
+	"\x13_initial_delay_secsB\x0e\n" +
+	"\f_period_secsB\x0f\n" +
+	"\r_timeout_secs\"\x8b\x01\n" +
+	"\tWorkspace\x12\x18\n" +
+	"\aarchive\x18\x01 \x01(\fR\aarchive\x12\x15\n" +
+	"\x03url\x18\x02 \x01(\tH\x00R\x03url\x88\x01\x01\x12\x17\n" +
+	"\x04path\x18\x03 \x01(\tH\x01R\x04path\x88\x01\x01\x12\x19\n" +
+	"\x05mount\x18\x04 \x01(\tH\x02R\x05mount\x88\x01\x01B\x06\n" +
+	"\x04_urlB\a\n" +
+	"\x05_pathB\b\n" +
+	"\x06_mount\"\x92\x01\n" +
+	"\vPortMapping\x12%\n" +
+	"\x0econtainer_port\x18\x01 \x01(\rR\rcontainerPort\x12 \n" +
+	"\thost_port\x18\x02 \x01(\rH\x00R\bhostPort\x88\x01\x01\x12\x1f\n" +
+	"\bprotocol\x18\x03 \x01(\tH\x01R\bprotocol\x88\x01\x01B\f\n" +
+	"\n" +
+	"_host_portB\v\n" +
+	"\t_protocol\"\x96\x01\n" +
 	"\tImageSpec\x12\x1f\n" +
 	"\bregistry\x18\x01 \x01(\tH\x01R\bregistry\x88\x01\x01\x12\x14\n" +
 	"\x05image\x18\x02 \x01(\tR\x05image\x12=\n" +
@@ -2122,13 +7304,26 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\fmemory_limit\x18\x02 \x01(\tH\x01R\vmemoryLimit\x88\x01\x01B\f\n" +
 	"\n" +
 	"_cpu_limitB\x0f\n" +
-	"\r_memory_limit\"\xa5\x01\n" +
+	"\r_memory_limit\"\xef\x03\n" +
 	"\rNetworkConfig\x124\n" +
 	"\x05rules\x18\x01 \x03(\v2\x1e.container_manager.NetworkRuleR\x05rules\x12*\n" +
 	"\x0edefault_policy\x18\x02 \x01(\tH\x00R\rdefaultPolicy\x88\x01\x01\x12\x1f\n" +
 	"\vdns_servers\x18\x03 \x03(\tR\n" +
-	"dnsServersB\x11\n" +
-	"\x0f_default_policy\"\x8c\x02\n" +
+	"dnsServers\x12,\n" +
+	"\x0fmax_connections\x18\x04 \x01(\rH\x01R\x0emaxConnections\x88\x01\x01\x125\n" +
+	"\x14log_network_attempts\x18\x05 \x01(\bH\x02R\x12logNetworkAttempts\x88\x01\x01\x12.\n" +
+	"\x13dns_allowed_domains\x18\x06 \x03(\tR\x11dnsAllowedDomains\x12\x1b\n" +
+	"\x06subnet\x18\a \x01(\tH\x03R\x06subnet\x88\x01\x01\x12 \n" +
+	"\tstatic_ip\x18\b \x01(\tH\x04R\bstaticIp\x88\x01\x01\x12 \n" +
+	"\tpod_group\x18\t \x01(\tH\x05R\bpodGroup\x88\x01\x01B\x11\n" +
+	"\x0f_default_policyB\x12\n" +
+	"\x10_max_connectionsB\x17\n" +
+	"\x15_log_network_attemptsB\t\n" +
+	"\a_subnetB\f\n" +
+	"\n" +
+	"_static_ipB\f\n" +
+	"\n" +
+	"_pod_group\"\x8c\x02\n" +
 	"\vNetworkRule\x12\x16\n" +
 	"\x06action\x18\x01 \x01(\tR\x06action\x12\x1f\n" +
 	"\bprotocol\x18\x02 \x01(\tH\x00R\bprotocol\x88\x01\x01\x12%\n" +
@@ -2138,14 +7333,42 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\t_protocolB\x0e\n" +
 	"\f_destinationB\x13\n" +
 	"\x11_port_range_startB\x11\n" +
-	"\x0f_port_range_end\"?\n" +
+	"\x0f_port_range_end\"\xb1\x04\n" +
 	"\x15ListContainersRequest\x12\x1b\n" +
-	"\x06filter\x18\x01 \x01(\tH\x00R\x06filter\x88\x01\x01B\t\n" +
-	"\a_filter\"Z\n" +
+	"\x06filter\x18\x01 \x01(\tH\x00R\x06filter\x88\x01\x01\x12L\n" +
+	"\x06labels\x18\x02 \x03(\v24.container_manager.ListContainersRequest.LabelsEntryR\x06labels\x12*\n" +
+	"\x0elabel_selector\x18\x03 \x01(\tH\x01R\rlabelSelector\x88\x01\x01\x12\x1b\n" +
+	"\x06tenant\x18\x04 \x01(\tH\x02R\x06tenant\x88\x01\x01\x12\x19\n" +
+	"\x05image\x18\x05 \x01(\tH\x03R\x05image\x88\x01\x01\x12 \n" +
+	"\texit_code\x18\x06 \x01(\x05H\x04R\bexitCode\x88\x01\x01\x12\x1c\n" +
+	"\asort_by\x18\a \x01(\tH\x05R\x06sortBy\x88\x01\x01\x12\x1e\n" +
+	"\n" +
+	"descending\x18\b \x01(\bR\n" +
+	"descending\x12 \n" +
+	"\tpage_size\x18\t \x01(\rH\x06R\bpageSize\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"page_token\x18\n" +
+	" \x01(\tH\aR\tpageToken\x88\x01\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\t\n" +
+	"\a_filterB\x11\n" +
+	"\x0f_label_selectorB\t\n" +
+	"\a_tenantB\b\n" +
+	"\x06_imageB\f\n" +
+	"\n" +
+	"_exit_codeB\n" +
+	"\n" +
+	"\b_sort_byB\f\n" +
+	"\n" +
+	"_page_sizeB\r\n" +
+	"\v_page_token\"\x9b\x01\n" +
 	"\x16ListContainersResponse\x12@\n" +
 	"\n" +
 	"containers\x18\x01 \x03(\v2 .container_manager.ContainerInfoR\n" +
-	"containers\"\xa0\x02\n" +
+	"containers\x12+\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tH\x00R\rnextPageToken\x88\x01\x01B\x12\n" +
+	"\x10_next_page_token\"\xbb\x03\n" +
 	"\rContainerInfo\x12!\n" +
 	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12\x14\n" +
 	"\x05image\x18\x02 \x01(\tR\x05image\x127\n" +
@@ -2155,7 +7378,12 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\vfinished_at\x18\x05 \x01(\tH\x00R\n" +
 	"finishedAt\x88\x01\x01\x12 \n" +
 	"\texit_code\x18\x06 \x01(\x05H\x01R\bexitCode\x88\x01\x01\x12\x18\n" +
-	"\acommand\x18\a \x03(\tR\acommandB\x0e\n" +
+	"\acommand\x18\a \x03(\tR\acommand\x12D\n" +
+	"\x06labels\x18\b \x03(\v2,.container_manager.ContainerInfo.LabelsEntryR\x06labels\x12\x18\n" +
+	"\aattempt\x18\t \x01(\rR\aattempt\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0e\n" +
 	"\f_finished_atB\f\n" +
 	"\n" +
 	"_exit_code\">\n" +
@@ -2166,7 +7394,7 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12?\n" +
 	"\x06status\x18\x03 \x01(\v2\".container_manager.ContainerStatusH\x01R\x06status\x88\x01\x01B\b\n" +
 	"\x06_errorB\t\n" +
-	"\a_status\"\xf3\x03\n" +
+	"\a_status\"\xc8\x06\n" +
 	"\x0fContainerStatus\x12!\n" +
 	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x127\n" +
 	"\x05state\x18\x02 \x01(\x0e2!.container_manager.ContainerStateR\x05state\x12\x1d\n" +
@@ -2181,18 +7409,48 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\x06config\x18\b \x01(\v2\".container_manager.ContainerConfigR\x06config\x125\n" +
 	"\bio_stats\x18\t \x01(\v2\x1a.container_manager.IOStatsR\aioStats\x12(\n" +
 	"\rcleanup_after\x18\n" +
-	" \x01(\x03H\x04R\fcleanupAfter\x88\x01\x01B\r\n" +
+	" \x01(\x03H\x04R\fcleanupAfter\x88\x01\x01\x122\n" +
+	"\x12termination_reason\x18\v \x01(\tH\x05R\x11terminationReason\x88\x01\x01\x12\x16\n" +
+	"\x06paused\x18\f \x01(\bR\x06paused\x12\x14\n" +
+	"\x05ready\x18\r \x01(\bR\x05ready\x12*\n" +
+	"\x0erunner_version\x18\x0e \x01(\tH\x06R\rrunnerVersion\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"error_code\x18\x0f \x01(\tH\aR\terrorCode\x88\x01\x01\x12&\n" +
+	"\frunner_phase\x18\x10 \x01(\tH\bR\vrunnerPhase\x88\x01\x01\x12\x15\n" +
+	"\x06run_id\x18\x11 \x01(\tR\x05runId\x12\x18\n" +
+	"\aattempt\x18\x12 \x01(\rR\aattemptB\r\n" +
 	"\v_started_atB\x0e\n" +
 	"\f_finished_atB\f\n" +
 	"\n" +
 	"_exit_codeB\x06\n" +
 	"\x04_pidB\x10\n" +
-	"\x0e_cleanup_after\"p\n" +
+	"\x0e_cleanup_afterB\x15\n" +
+	"\x13_termination_reasonB\x11\n" +
+	"\x0f_runner_versionB\r\n" +
+	"\v_error_codeB\x0f\n" +
+	"\r_runner_phase\"\x8a\x04\n" +
 	"\aIOStats\x12\x1f\n" +
 	"\vstdin_bytes\x18\x01 \x01(\x04R\n" +
 	"stdinBytes\x12!\n" +
 	"\fstdout_bytes\x18\x02 \x01(\x04R\vstdoutBytes\x12!\n" +
-	"\fstderr_bytes\x18\x03 \x01(\x04R\vstderrBytes\"\x0f\n" +
+	"\fstderr_bytes\x18\x03 \x01(\x04R\vstderrBytes\x12$\n" +
+	"\vcpu_percent\x18\x04 \x01(\x01H\x00R\n" +
+	"cpuPercent\x88\x01\x01\x12,\n" +
+	"\x12memory_usage_bytes\x18\x05 \x01(\x04R\x10memoryUsageBytes\x12,\n" +
+	"\x12memory_limit_bytes\x18\x06 \x01(\x04R\x10memoryLimitBytes\x12 \n" +
+	"\fnet_rx_bytes\x18\a \x01(\x04R\n" +
+	"netRxBytes\x12 \n" +
+	"\fnet_tx_bytes\x18\b \x01(\x04R\n" +
+	"netTxBytes\x12(\n" +
+	"\x10block_read_bytes\x18\t \x01(\x04R\x0eblockReadBytes\x12*\n" +
+	"\x11block_write_bytes\x18\n" +
+	" \x01(\x04R\x0fblockWriteBytes\x12\"\n" +
+	"\n" +
+	"sampled_at\x18\v \x01(\tH\x01R\tsampledAt\x88\x01\x01\x12'\n" +
+	"\rcpu_time_secs\x18\f \x01(\x01H\x02R\vcpuTimeSecs\x88\x01\x01B\x0e\n" +
+	"\f_cpu_percentB\r\n" +
+	"\v_sampled_atB\x10\n" +
+	"\x0e_cpu_time_secs\"\x0f\n" +
 	"\rHealthRequest\"\x96\x02\n" +
 	"\x0eHealthResponse\x12\x18\n" +
 	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12\x18\n" +
@@ -2209,7 +7467,7 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\tresources\x18\x03 \x01(\v2 .container_manager.NodeResourcesH\x01R\tresources\x88\x01\x01B\b\n" +
 	"\x06_errorB\f\n" +
 	"\n" +
-	"_resources\"\xff\x04\n" +
+	"_resources\"\xbe\x05\n" +
 	"\rNodeResources\x12\x1b\n" +
 	"\tcpu_cores\x18\x01 \x01(\rR\bcpuCores\x12*\n" +
 	"\x11cpu_usage_percent\x18\x02 \x01(\x02R\x0fcpuUsagePercent\x12,\n" +
@@ -2227,7 +7485,8 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\tload_1min\x18\r \x01(\x02R\bload1min\x12\x1b\n" +
 	"\tload_5min\x18\x0e \x01(\x02R\bload5min\x12\x1d\n" +
 	"\n" +
-	"load_15min\x18\x0f \x01(\x02R\tload15min\"\x1b\n" +
+	"load_15min\x18\x0f \x01(\x02R\tload15min\x12=\n" +
+	"\x1bcontainer_memory_used_bytes\x18\x10 \x01(\x04R\x18containerMemoryUsedBytes\"\x1b\n" +
 	"\x19GetAvailableImagesRequest\"\x91\x01\n" +
 	"\x1aGetAvailableImagesResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
@@ -2239,7 +7498,144 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\trepo_tags\x18\x02 \x03(\tR\brepoTags\x12\x1d\n" +
 	"\n" +
 	"size_bytes\x18\x03 \x01(\x04R\tsizeBytes\x12\x18\n" +
-	"\acreated\x18\x04 \x01(\tR\acreated*R\n" +
+	"\acreated\x18\x04 \x01(\tR\acreated\"\x85\x01\n" +
+	"\x1fUpdateContainerResourcesRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12?\n" +
+	"\tresources\x18\x02 \x01(\v2!.container_manager.ResourceLimitsR\tresources\"\xb5\x01\n" +
+	" UpdateContainerResourcesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12D\n" +
+	"\tresources\x18\x03 \x01(\v2!.container_manager.ResourceLimitsH\x01R\tresources\x88\x01\x01B\b\n" +
+	"\x06_errorB\f\n" +
+	"\n" +
+	"_resources\":\n" +
+	"\x15PauseContainerRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\"W\n" +
+	"\x16PauseContainerResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"<\n" +
+	"\x17UnpauseContainerRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\"Y\n" +
+	"\x18UnpauseContainerResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"\x80\x01\n" +
+	"\x11CheckpointRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12#\n" +
+	"\rcheckpoint_id\x18\x02 \x01(\tR\fcheckpointId\x12#\n" +
+	"\rleave_running\x18\x03 \x01(\bR\fleaveRunning\"S\n" +
+	"\x12CheckpointResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"n\n" +
+	"\x10WaitReadyRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12&\n" +
+	"\ftimeout_secs\x18\x02 \x01(\rH\x00R\vtimeoutSecs\x88\x01\x01B\x0f\n" +
+	"\r_timeout_secs\"N\n" +
+	"\x11WaitReadyResponse\x12\x14\n" +
+	"\x05ready\x18\x01 \x01(\bR\x05ready\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"\xe3\x01\n" +
+	"\x0eGetLogsRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12'\n" +
+	"\rsince_unix_ms\x18\x02 \x01(\x03H\x00R\vsinceUnixMs\x88\x01\x01\x12\x17\n" +
+	"\x04tail\x18\x03 \x01(\rH\x01R\x04tail\x88\x01\x01\x12\x16\n" +
+	"\x06follow\x18\x04 \x01(\bR\x06follow\x129\n" +
+	"\astreams\x18\x05 \x03(\x0e2\x1f.container_manager.OutputStreamR\astreamsB\x10\n" +
+	"\x0e_since_unix_msB\a\n" +
+	"\x05_tail\"\x82\x01\n" +
+	"\aLogLine\x12*\n" +
+	"\x11timestamp_unix_ms\x18\x01 \x01(\x03R\x0ftimestampUnixMs\x127\n" +
+	"\x06stream\x18\x02 \x01(\x0e2\x1f.container_manager.OutputStreamR\x06stream\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"\x93\x02\n" +
+	"\x12WatchEventsRequest\x12I\n" +
+	"\x06labels\x18\x01 \x03(\v21.container_manager.WatchEventsRequest.LabelsEntryR\x06labels\x12\x1b\n" +
+	"\x06tenant\x18\x02 \x01(\tH\x00R\x06tenant\x88\x01\x01\x129\n" +
+	"\x06states\x18\x03 \x03(\x0e2!.container_manager.ContainerStateR\x06states\x12\x14\n" +
+	"\x05types\x18\x04 \x03(\tR\x05types\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\t\n" +
+	"\a_tenant\"\x9e\x02\n" +
+	"\x0eContainerEvent\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x127\n" +
+	"\x05state\x18\x02 \x01(\x0e2!.container_manager.ContainerStateR\x05state\x12E\n" +
+	"\x06labels\x18\x03 \x03(\v2-.container_manager.ContainerEvent.LabelsEntryR\x06labels\x12.\n" +
+	"\x05event\x18\x04 \x01(\v2\x18.container_manager.EventR\x05event\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"<\n" +
+	"\x17RestartContainerRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\"\x8a\x01\n" +
+	"\x18RestartContainerResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12\x18\n" +
+	"\aattempt\x18\x03 \x01(\rR\aattempt\x12\x15\n" +
+	"\x06run_id\x18\x04 \x01(\tR\x05runIdB\b\n" +
+	"\x06_error\"Z\n" +
+	"\x14ExtendTimeoutRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12\x1f\n" +
+	"\vextend_secs\x18\x02 \x01(\rR\n" +
+	"extendSecs\"\xc1\x01\n" +
+	"\x15ExtendTimeoutResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12&\n" +
+	"\frun_deadline\x18\x03 \x01(\x03H\x01R\vrunDeadline\x88\x01\x01\x120\n" +
+	"\x14total_extension_secs\x18\x04 \x01(\rR\x12totalExtensionSecsB\b\n" +
+	"\x06_errorB\x0f\n" +
+	"\r_run_deadline\"\xdd\x01\n" +
+	"\x13StartCaptureRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12\x1b\n" +
+	"\x06filter\x18\x02 \x01(\tH\x00R\x06filter\x88\x01\x01\x12$\n" +
+	"\vmax_packets\x18\x03 \x01(\rH\x01R\n" +
+	"maxPackets\x88\x01\x01\x12/\n" +
+	"\x11max_duration_secs\x18\x04 \x01(\rH\x02R\x0fmaxDurationSecs\x88\x01\x01B\t\n" +
+	"\a_filterB\x0e\n" +
+	"\f_max_packetsB\x14\n" +
+	"\x12_max_duration_secs\"\x88\x01\n" +
+	"\x14StartCaptureResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"capture_id\x18\x03 \x01(\tH\x01R\tcaptureId\x88\x01\x01B\b\n" +
+	"\x06_errorB\r\n" +
+	"\v_capture_id\"V\n" +
+	"\x12StopCaptureRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12\x1d\n" +
+	"\n" +
+	"capture_id\x18\x02 \x01(\tR\tcaptureId\"T\n" +
+	"\x13StopCaptureResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"^\n" +
+	"\x11UploadFileRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"i\n" +
+	"\x12UploadFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x04R\x05bytesB\b\n" +
+	"\x06_error\"L\n" +
+	"\x13DownloadFileRequest\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"*\n" +
+	"\x14DownloadFileResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data*Q\n" +
+	"\fOutputStream\x12\x1d\n" +
+	"\x19OUTPUT_STREAM_UNSPECIFIED\x10\x00\x12\n" +
+	"\n" +
+	"\x06STDOUT\x10\x01\x12\n" +
+	"\n" +
+	"\x06STDERR\x10\x02\x12\n" +
+	"\n" +
+	"\x06EVENTS\x10\x03*C\n" +
+	"\n" +
+	"IdleAction\x12\x1b\n" +
+	"\x17IDLE_ACTION_UNSPECIFIED\x10\x00\x12\r\n" +
+	"\tTERMINATE\x10\x01\x12\t\n" +
+	"\x05PAUSE\x10\x02*R\n" +
 	"\x0eContainerState\x12\v\n" +
 	"\aCREATED\x10\x00\x12\v\n" +
 	"\aRUNNING\x10\x01\x12\n" +
@@ -2248,14 +7644,29 @@ const file_proto_container_manager_proto_rawDesc = "" +
 	"\n" +
 	"\x06FAILED\x10\x03\x12\x0e\n" +
 	"\n" +
-	"TERMINATED\x10\x042\xe5\x04\n" +
+	"TERMINATED\x10\x042\xe9\x0e\n" +
 	"\x10ContainerManager\x12H\n" +
 	"\x03Run\x12\x1d.container_manager.RunRequest\x1a\x1e.container_manager.RunResponse(\x010\x01\x12e\n" +
 	"\x0eListContainers\x12(.container_manager.ListContainersRequest\x1a).container_manager.ListContainersResponse\x12q\n" +
 	"\x12GetContainerStatus\x12,.container_manager.GetContainerStatusRequest\x1a-.container_manager.GetContainerStatusResponse\x12M\n" +
 	"\x06Health\x12 .container_manager.HealthRequest\x1a!.container_manager.HealthResponse\x12k\n" +
 	"\x10GetNodeResources\x12*.container_manager.GetNodeResourcesRequest\x1a+.container_manager.GetNodeResourcesResponse\x12q\n" +
-	"\x12GetAvailableImages\x12,.container_manager.GetAvailableImagesRequest\x1a-.container_manager.GetAvailableImagesResponseBDZBgithub.com/metorial/fleet/holopod/services/container-manager/protob\x06proto3"
+	"\x12GetAvailableImages\x12,.container_manager.GetAvailableImagesRequest\x1a-.container_manager.GetAvailableImagesResponse\x12\x83\x01\n" +
+	"\x18UpdateContainerResources\x122.container_manager.UpdateContainerResourcesRequest\x1a3.container_manager.UpdateContainerResourcesResponse\x12_\n" +
+	"\fStartCapture\x12&.container_manager.StartCaptureRequest\x1a'.container_manager.StartCaptureResponse\x12\\\n" +
+	"\vStopCapture\x12%.container_manager.StopCaptureRequest\x1a&.container_manager.StopCaptureResponse\x12[\n" +
+	"\n" +
+	"UploadFile\x12$.container_manager.UploadFileRequest\x1a%.container_manager.UploadFileResponse(\x01\x12a\n" +
+	"\fDownloadFile\x12&.container_manager.DownloadFileRequest\x1a'.container_manager.DownloadFileResponse0\x01\x12e\n" +
+	"\x0ePauseContainer\x12(.container_manager.PauseContainerRequest\x1a).container_manager.PauseContainerResponse\x12k\n" +
+	"\x10UnpauseContainer\x12*.container_manager.UnpauseContainerRequest\x1a+.container_manager.UnpauseContainerResponse\x12Y\n" +
+	"\n" +
+	"Checkpoint\x12$.container_manager.CheckpointRequest\x1a%.container_manager.CheckpointResponse\x12V\n" +
+	"\tWaitReady\x12#.container_manager.WaitReadyRequest\x1a$.container_manager.WaitReadyResponse\x12J\n" +
+	"\aGetLogs\x12!.container_manager.GetLogsRequest\x1a\x1a.container_manager.LogLine0\x01\x12Y\n" +
+	"\vWatchEvents\x12%.container_manager.WatchEventsRequest\x1a!.container_manager.ContainerEvent0\x01\x12k\n" +
+	"\x10RestartContainer\x12*.container_manager.RestartContainerRequest\x1a+.container_manager.RestartContainerResponse\x12b\n" +
+	"\rExtendTimeout\x12'.container_manager.ExtendTimeoutRequest\x1a(.container_manager.ExtendTimeoutResponseBDZBgithub.com/metorial/fleet/holopod/services/container-manager/protob\x06proto3"
 
 var (
 	file_proto_container_manager_proto_rawDescOnce sync.Once
@@ -2269,77 +7680,233 @@ func file_proto_container_manager_proto_rawDescGZIP() []byte {
 	return file_proto_container_manager_proto_rawDescData
 }
 
-var file_proto_container_manager_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_container_manager_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
+var file_proto_container_manager_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_proto_container_manager_proto_msgTypes = make([]protoimpl.MessageInfo, 96)
 var file_proto_container_manager_proto_goTypes = []any{
-	(ContainerState)(0),                // 0: container_manager.ContainerState
-	(*RunRequest)(nil),                 // 1: container_manager.RunRequest
-	(*CreateContainer)(nil),            // 2: container_manager.CreateContainer
-	(*TerminateContainer)(nil),         // 3: container_manager.TerminateContainer
-	(*RunResponse)(nil),                // 4: container_manager.RunResponse
-	(*ContainerCreated)(nil),           // 5: container_manager.ContainerCreated
-	(*ContainerExit)(nil),              // 6: container_manager.ContainerExit
-	(*ContainerConfig)(nil),            // 7: container_manager.ContainerConfig
-	(*ImageSpec)(nil),                  // 8: container_manager.ImageSpec
-	(*BasicAuth)(nil),                  // 9: container_manager.BasicAuth
-	(*ResourceLimits)(nil),             // 10: container_manager.ResourceLimits
-	(*NetworkConfig)(nil),              // 11: container_manager.NetworkConfig
-	(*NetworkRule)(nil),                // 12: container_manager.NetworkRule
-	(*ListContainersRequest)(nil),      // 13: container_manager.ListContainersRequest
-	(*ListContainersResponse)(nil),     // 14: container_manager.ListContainersResponse
-	(*ContainerInfo)(nil),              // 15: container_manager.ContainerInfo
-	(*GetContainerStatusRequest)(nil),  // 16: container_manager.GetContainerStatusRequest
-	(*GetContainerStatusResponse)(nil), // 17: container_manager.GetContainerStatusResponse
-	(*ContainerStatus)(nil),            // 18: container_manager.ContainerStatus
-	(*IOStats)(nil),                    // 19: container_manager.IOStats
-	(*HealthRequest)(nil),              // 20: container_manager.HealthRequest
-	(*HealthResponse)(nil),             // 21: container_manager.HealthResponse
-	(*GetNodeResourcesRequest)(nil),    // 22: container_manager.GetNodeResourcesRequest
-	(*GetNodeResourcesResponse)(nil),   // 23: container_manager.GetNodeResourcesResponse
-	(*NodeResources)(nil),              // 24: container_manager.NodeResources
-	(*GetAvailableImagesRequest)(nil),  // 25: container_manager.GetAvailableImagesRequest
-	(*GetAvailableImagesResponse)(nil), // 26: container_manager.GetAvailableImagesResponse
-	(*ImageInfo)(nil),                  // 27: container_manager.ImageInfo
-	nil,                                // 28: container_manager.ContainerConfig.EnvEntry
+	(OutputStream)(0),                        // 0: container_manager.OutputStream
+	(IdleAction)(0),                          // 1: container_manager.IdleAction
+	(ContainerState)(0),                      // 2: container_manager.ContainerState
+	(*RunRequest)(nil),                       // 3: container_manager.RunRequest
+	(*UpdateResources)(nil),                  // 4: container_manager.UpdateResources
+	(*Signal)(nil),                           // 5: container_manager.Signal
+	(*Resize)(nil),                           // 6: container_manager.Resize
+	(*ExecRequest)(nil),                      // 7: container_manager.ExecRequest
+	(*UpdateNetworkPolicy)(nil),              // 8: container_manager.UpdateNetworkPolicy
+	(*CreateContainer)(nil),                  // 9: container_manager.CreateContainer
+	(*AttachContainer)(nil),                  // 10: container_manager.AttachContainer
+	(*TerminateContainer)(nil),               // 11: container_manager.TerminateContainer
+	(*RunResponse)(nil),                      // 12: container_manager.RunResponse
+	(*ExecOutput)(nil),                       // 13: container_manager.ExecOutput
+	(*CaptureChunk)(nil),                     // 14: container_manager.CaptureChunk
+	(*ContainerCreated)(nil),                 // 15: container_manager.ContainerCreated
+	(*ContainerAttached)(nil),                // 16: container_manager.ContainerAttached
+	(*ContainerExit)(nil),                    // 17: container_manager.ContainerExit
+	(*Event)(nil),                            // 18: container_manager.Event
+	(*LogMessage)(nil),                       // 19: container_manager.LogMessage
+	(*ImagePullStarted)(nil),                 // 20: container_manager.ImagePullStarted
+	(*ImagePullCompleted)(nil),               // 21: container_manager.ImagePullCompleted
+	(*ContainerStarted)(nil),                 // 22: container_manager.ContainerStarted
+	(*ContainerIPReady)(nil),                 // 23: container_manager.ContainerIPReady
+	(*NetworkIsolationReady)(nil),            // 24: container_manager.NetworkIsolationReady
+	(*ContainerPortReady)(nil),               // 25: container_manager.ContainerPortReady
+	(*ContainerReady)(nil),                   // 26: container_manager.ContainerReady
+	(*NetworkAttempt)(nil),                   // 27: container_manager.NetworkAttempt
+	(*ContainerTerminating)(nil),             // 28: container_manager.ContainerTerminating
+	(*ContainerExited)(nil),                  // 29: container_manager.ContainerExited
+	(*ResourceStats)(nil),                    // 30: container_manager.ResourceStats
+	(*LifecycleTransition)(nil),              // 31: container_manager.LifecycleTransition
+	(*ContainerIdleTimeout)(nil),             // 32: container_manager.ContainerIdleTimeout
+	(*RunnerHello)(nil),                      // 33: container_manager.RunnerHello
+	(*RunnerHeartbeat)(nil),                  // 34: container_manager.RunnerHeartbeat
+	(*ContainerConfig)(nil),                  // 35: container_manager.ContainerConfig
+	(*Webhook)(nil),                          // 36: container_manager.Webhook
+	(*SecretRef)(nil),                        // 37: container_manager.SecretRef
+	(*PostExitHook)(nil),                     // 38: container_manager.PostExitHook
+	(*AuxContainer)(nil),                     // 39: container_manager.AuxContainer
+	(*RuntimeOptions)(nil),                   // 40: container_manager.RuntimeOptions
+	(*ReadinessProbe)(nil),                   // 41: container_manager.ReadinessProbe
+	(*Workspace)(nil),                        // 42: container_manager.Workspace
+	(*PortMapping)(nil),                      // 43: container_manager.PortMapping
+	(*ImageSpec)(nil),                        // 44: container_manager.ImageSpec
+	(*BasicAuth)(nil),                        // 45: container_manager.BasicAuth
+	(*ResourceLimits)(nil),                   // 46: container_manager.ResourceLimits
+	(*NetworkConfig)(nil),                    // 47: container_manager.NetworkConfig
+	(*NetworkRule)(nil),                      // 48: container_manager.NetworkRule
+	(*ListContainersRequest)(nil),            // 49: container_manager.ListContainersRequest
+	(*ListContainersResponse)(nil),           // 50: container_manager.ListContainersResponse
+	(*ContainerInfo)(nil),                    // 51: container_manager.ContainerInfo
+	(*GetContainerStatusRequest)(nil),        // 52: container_manager.GetContainerStatusRequest
+	(*GetContainerStatusResponse)(nil),       // 53: container_manager.GetContainerStatusResponse
+	(*ContainerStatus)(nil),                  // 54: container_manager.ContainerStatus
+	(*IOStats)(nil),                          // 55: container_manager.IOStats
+	(*HealthRequest)(nil),                    // 56: container_manager.HealthRequest
+	(*HealthResponse)(nil),                   // 57: container_manager.HealthResponse
+	(*GetNodeResourcesRequest)(nil),          // 58: container_manager.GetNodeResourcesRequest
+	(*GetNodeResourcesResponse)(nil),         // 59: container_manager.GetNodeResourcesResponse
+	(*NodeResources)(nil),                    // 60: container_manager.NodeResources
+	(*GetAvailableImagesRequest)(nil),        // 61: container_manager.GetAvailableImagesRequest
+	(*GetAvailableImagesResponse)(nil),       // 62: container_manager.GetAvailableImagesResponse
+	(*ImageInfo)(nil),                        // 63: container_manager.ImageInfo
+	(*UpdateContainerResourcesRequest)(nil),  // 64: container_manager.UpdateContainerResourcesRequest
+	(*UpdateContainerResourcesResponse)(nil), // 65: container_manager.UpdateContainerResourcesResponse
+	(*PauseContainerRequest)(nil),            // 66: container_manager.PauseContainerRequest
+	(*PauseContainerResponse)(nil),           // 67: container_manager.PauseContainerResponse
+	(*UnpauseContainerRequest)(nil),          // 68: container_manager.UnpauseContainerRequest
+	(*UnpauseContainerResponse)(nil),         // 69: container_manager.UnpauseContainerResponse
+	(*CheckpointRequest)(nil),                // 70: container_manager.CheckpointRequest
+	(*CheckpointResponse)(nil),               // 71: container_manager.CheckpointResponse
+	(*WaitReadyRequest)(nil),                 // 72: container_manager.WaitReadyRequest
+	(*WaitReadyResponse)(nil),                // 73: container_manager.WaitReadyResponse
+	(*GetLogsRequest)(nil),                   // 74: container_manager.GetLogsRequest
+	(*LogLine)(nil),                          // 75: container_manager.LogLine
+	(*WatchEventsRequest)(nil),               // 76: container_manager.WatchEventsRequest
+	(*ContainerEvent)(nil),                   // 77: container_manager.ContainerEvent
+	(*RestartContainerRequest)(nil),          // 78: container_manager.RestartContainerRequest
+	(*RestartContainerResponse)(nil),         // 79: container_manager.RestartContainerResponse
+	(*ExtendTimeoutRequest)(nil),             // 80: container_manager.ExtendTimeoutRequest
+	(*ExtendTimeoutResponse)(nil),            // 81: container_manager.ExtendTimeoutResponse
+	(*StartCaptureRequest)(nil),              // 82: container_manager.StartCaptureRequest
+	(*StartCaptureResponse)(nil),             // 83: container_manager.StartCaptureResponse
+	(*StopCaptureRequest)(nil),               // 84: container_manager.StopCaptureRequest
+	(*StopCaptureResponse)(nil),              // 85: container_manager.StopCaptureResponse
+	(*UploadFileRequest)(nil),                // 86: container_manager.UploadFileRequest
+	(*UploadFileResponse)(nil),               // 87: container_manager.UploadFileResponse
+	(*DownloadFileRequest)(nil),              // 88: container_manager.DownloadFileRequest
+	(*DownloadFileResponse)(nil),             // 89: container_manager.DownloadFileResponse
+	nil,                                      // 90: container_manager.ExecRequest.EnvEntry
+	nil,                                      // 91: container_manager.ContainerConfig.EnvEntry
+	nil,                                      // 92: container_manager.ContainerConfig.LabelsEntry
+	nil,                                      // 93: container_manager.ContainerConfig.SecretEnvEntry
+	nil,                                      // 94: container_manager.AuxContainer.EnvEntry
+	nil,                                      // 95: container_manager.ListContainersRequest.LabelsEntry
+	nil,                                      // 96: container_manager.ContainerInfo.LabelsEntry
+	nil,                                      // 97: container_manager.WatchEventsRequest.LabelsEntry
+	nil,                                      // 98: container_manager.ContainerEvent.LabelsEntry
 }
 var file_proto_container_manager_proto_depIdxs = []int32{
-	2,  // 0: container_manager.RunRequest.create:type_name -> container_manager.CreateContainer
-	3,  // 1: container_manager.RunRequest.terminate:type_name -> container_manager.TerminateContainer
-	7,  // 2: container_manager.CreateContainer.config:type_name -> container_manager.ContainerConfig
-	5,  // 3: container_manager.RunResponse.created:type_name -> container_manager.ContainerCreated
-	6,  // 4: container_manager.RunResponse.exit:type_name -> container_manager.ContainerExit
-	0,  // 5: container_manager.ContainerCreated.state:type_name -> container_manager.ContainerState
-	8,  // 6: container_manager.ContainerConfig.image_spec:type_name -> container_manager.ImageSpec
-	28, // 7: container_manager.ContainerConfig.env:type_name -> container_manager.ContainerConfig.EnvEntry
-	10, // 8: container_manager.ContainerConfig.resources:type_name -> container_manager.ResourceLimits
-	11, // 9: container_manager.ContainerConfig.network:type_name -> container_manager.NetworkConfig
-	9,  // 10: container_manager.ImageSpec.basic_auth:type_name -> container_manager.BasicAuth
-	12, // 11: container_manager.NetworkConfig.rules:type_name -> container_manager.NetworkRule
-	15, // 12: container_manager.ListContainersResponse.containers:type_name -> container_manager.ContainerInfo
-	0,  // 13: container_manager.ContainerInfo.state:type_name -> container_manager.ContainerState
-	18, // 14: container_manager.GetContainerStatusResponse.status:type_name -> container_manager.ContainerStatus
-	0,  // 15: container_manager.ContainerStatus.state:type_name -> container_manager.ContainerState
-	7,  // 16: container_manager.ContainerStatus.config:type_name -> container_manager.ContainerConfig
-	19, // 17: container_manager.ContainerStatus.io_stats:type_name -> container_manager.IOStats
-	24, // 18: container_manager.GetNodeResourcesResponse.resources:type_name -> container_manager.NodeResources
-	27, // 19: container_manager.GetAvailableImagesResponse.images:type_name -> container_manager.ImageInfo
-	1,  // 20: container_manager.ContainerManager.Run:input_type -> container_manager.RunRequest
-	13, // 21: container_manager.ContainerManager.ListContainers:input_type -> container_manager.ListContainersRequest
-	16, // 22: container_manager.ContainerManager.GetContainerStatus:input_type -> container_manager.GetContainerStatusRequest
-	20, // 23: container_manager.ContainerManager.Health:input_type -> container_manager.HealthRequest
-	22, // 24: container_manager.ContainerManager.GetNodeResources:input_type -> container_manager.GetNodeResourcesRequest
-	25, // 25: container_manager.ContainerManager.GetAvailableImages:input_type -> container_manager.GetAvailableImagesRequest
-	4,  // 26: container_manager.ContainerManager.Run:output_type -> container_manager.RunResponse
-	14, // 27: container_manager.ContainerManager.ListContainers:output_type -> container_manager.ListContainersResponse
-	17, // 28: container_manager.ContainerManager.GetContainerStatus:output_type -> container_manager.GetContainerStatusResponse
-	21, // 29: container_manager.ContainerManager.Health:output_type -> container_manager.HealthResponse
-	23, // 30: container_manager.ContainerManager.GetNodeResources:output_type -> container_manager.GetNodeResourcesResponse
-	26, // 31: container_manager.ContainerManager.GetAvailableImages:output_type -> container_manager.GetAvailableImagesResponse
-	26, // [26:32] is the sub-list for method output_type
-	20, // [20:26] is the sub-list for method input_type
-	20, // [20:20] is the sub-list for extension type_name
-	20, // [20:20] is the sub-list for extension extendee
-	0,  // [0:20] is the sub-list for field type_name
+	9,  // 0: container_manager.RunRequest.create:type_name -> container_manager.CreateContainer
+	11, // 1: container_manager.RunRequest.terminate:type_name -> container_manager.TerminateContainer
+	8,  // 2: container_manager.RunRequest.update_network_policy:type_name -> container_manager.UpdateNetworkPolicy
+	7,  // 3: container_manager.RunRequest.exec:type_name -> container_manager.ExecRequest
+	6,  // 4: container_manager.RunRequest.resize:type_name -> container_manager.Resize
+	5,  // 5: container_manager.RunRequest.signal:type_name -> container_manager.Signal
+	10, // 6: container_manager.RunRequest.attach:type_name -> container_manager.AttachContainer
+	4,  // 7: container_manager.RunRequest.update_resources:type_name -> container_manager.UpdateResources
+	46, // 8: container_manager.UpdateResources.resources:type_name -> container_manager.ResourceLimits
+	90, // 9: container_manager.ExecRequest.env:type_name -> container_manager.ExecRequest.EnvEntry
+	47, // 10: container_manager.UpdateNetworkPolicy.network:type_name -> container_manager.NetworkConfig
+	35, // 11: container_manager.CreateContainer.config:type_name -> container_manager.ContainerConfig
+	0,  // 12: container_manager.CreateContainer.streams:type_name -> container_manager.OutputStream
+	0,  // 13: container_manager.AttachContainer.streams:type_name -> container_manager.OutputStream
+	15, // 14: container_manager.RunResponse.created:type_name -> container_manager.ContainerCreated
+	17, // 15: container_manager.RunResponse.exit:type_name -> container_manager.ContainerExit
+	14, // 16: container_manager.RunResponse.capture:type_name -> container_manager.CaptureChunk
+	13, // 17: container_manager.RunResponse.exec:type_name -> container_manager.ExecOutput
+	16, // 18: container_manager.RunResponse.attached:type_name -> container_manager.ContainerAttached
+	18, // 19: container_manager.RunResponse.typed_event:type_name -> container_manager.Event
+	2,  // 20: container_manager.ContainerCreated.state:type_name -> container_manager.ContainerState
+	2,  // 21: container_manager.ContainerAttached.state:type_name -> container_manager.ContainerState
+	19, // 22: container_manager.Event.log:type_name -> container_manager.LogMessage
+	20, // 23: container_manager.Event.image_pull_started:type_name -> container_manager.ImagePullStarted
+	21, // 24: container_manager.Event.image_pull_completed:type_name -> container_manager.ImagePullCompleted
+	22, // 25: container_manager.Event.container_started:type_name -> container_manager.ContainerStarted
+	23, // 26: container_manager.Event.container_ip_ready:type_name -> container_manager.ContainerIPReady
+	24, // 27: container_manager.Event.network_isolation_ready:type_name -> container_manager.NetworkIsolationReady
+	25, // 28: container_manager.Event.container_port_ready:type_name -> container_manager.ContainerPortReady
+	26, // 29: container_manager.Event.container_ready:type_name -> container_manager.ContainerReady
+	27, // 30: container_manager.Event.network_attempt:type_name -> container_manager.NetworkAttempt
+	28, // 31: container_manager.Event.container_terminating:type_name -> container_manager.ContainerTerminating
+	29, // 32: container_manager.Event.container_exited:type_name -> container_manager.ContainerExited
+	30, // 33: container_manager.Event.stats:type_name -> container_manager.ResourceStats
+	31, // 34: container_manager.Event.lifecycle:type_name -> container_manager.LifecycleTransition
+	32, // 35: container_manager.Event.container_idle_timeout:type_name -> container_manager.ContainerIdleTimeout
+	33, // 36: container_manager.Event.runner_hello:type_name -> container_manager.RunnerHello
+	34, // 37: container_manager.Event.runner_heartbeat:type_name -> container_manager.RunnerHeartbeat
+	44, // 38: container_manager.ContainerConfig.image_spec:type_name -> container_manager.ImageSpec
+	91, // 39: container_manager.ContainerConfig.env:type_name -> container_manager.ContainerConfig.EnvEntry
+	46, // 40: container_manager.ContainerConfig.resources:type_name -> container_manager.ResourceLimits
+	47, // 41: container_manager.ContainerConfig.network:type_name -> container_manager.NetworkConfig
+	43, // 42: container_manager.ContainerConfig.ports:type_name -> container_manager.PortMapping
+	42, // 43: container_manager.ContainerConfig.workspace:type_name -> container_manager.Workspace
+	41, // 44: container_manager.ContainerConfig.readiness_probe:type_name -> container_manager.ReadinessProbe
+	1,  // 45: container_manager.ContainerConfig.idle_action:type_name -> container_manager.IdleAction
+	40, // 46: container_manager.ContainerConfig.runtime_options:type_name -> container_manager.RuntimeOptions
+	39, // 47: container_manager.ContainerConfig.sidecars:type_name -> container_manager.AuxContainer
+	39, // 48: container_manager.ContainerConfig.init_containers:type_name -> container_manager.AuxContainer
+	38, // 49: container_manager.ContainerConfig.post_exit_hooks:type_name -> container_manager.PostExitHook
+	92, // 50: container_manager.ContainerConfig.labels:type_name -> container_manager.ContainerConfig.LabelsEntry
+	93, // 51: container_manager.ContainerConfig.secret_env:type_name -> container_manager.ContainerConfig.SecretEnvEntry
+	36, // 52: container_manager.ContainerConfig.webhooks:type_name -> container_manager.Webhook
+	37, // 53: container_manager.Webhook.secret:type_name -> container_manager.SecretRef
+	39, // 54: container_manager.PostExitHook.container:type_name -> container_manager.AuxContainer
+	44, // 55: container_manager.AuxContainer.image_spec:type_name -> container_manager.ImageSpec
+	94, // 56: container_manager.AuxContainer.env:type_name -> container_manager.AuxContainer.EnvEntry
+	46, // 57: container_manager.AuxContainer.resources:type_name -> container_manager.ResourceLimits
+	45, // 58: container_manager.ImageSpec.basic_auth:type_name -> container_manager.BasicAuth
+	48, // 59: container_manager.NetworkConfig.rules:type_name -> container_manager.NetworkRule
+	95, // 60: container_manager.ListContainersRequest.labels:type_name -> container_manager.ListContainersRequest.LabelsEntry
+	51, // 61: container_manager.ListContainersResponse.containers:type_name -> container_manager.ContainerInfo
+	2,  // 62: container_manager.ContainerInfo.state:type_name -> container_manager.ContainerState
+	96, // 63: container_manager.ContainerInfo.labels:type_name -> container_manager.ContainerInfo.LabelsEntry
+	54, // 64: container_manager.GetContainerStatusResponse.status:type_name -> container_manager.ContainerStatus
+	2,  // 65: container_manager.ContainerStatus.state:type_name -> container_manager.ContainerState
+	35, // 66: container_manager.ContainerStatus.config:type_name -> container_manager.ContainerConfig
+	55, // 67: container_manager.ContainerStatus.io_stats:type_name -> container_manager.IOStats
+	60, // 68: container_manager.GetNodeResourcesResponse.resources:type_name -> container_manager.NodeResources
+	63, // 69: container_manager.GetAvailableImagesResponse.images:type_name -> container_manager.ImageInfo
+	46, // 70: container_manager.UpdateContainerResourcesRequest.resources:type_name -> container_manager.ResourceLimits
+	46, // 71: container_manager.UpdateContainerResourcesResponse.resources:type_name -> container_manager.ResourceLimits
+	0,  // 72: container_manager.GetLogsRequest.streams:type_name -> container_manager.OutputStream
+	0,  // 73: container_manager.LogLine.stream:type_name -> container_manager.OutputStream
+	97, // 74: container_manager.WatchEventsRequest.labels:type_name -> container_manager.WatchEventsRequest.LabelsEntry
+	2,  // 75: container_manager.WatchEventsRequest.states:type_name -> container_manager.ContainerState
+	2,  // 76: container_manager.ContainerEvent.state:type_name -> container_manager.ContainerState
+	98, // 77: container_manager.ContainerEvent.labels:type_name -> container_manager.ContainerEvent.LabelsEntry
+	18, // 78: container_manager.ContainerEvent.event:type_name -> container_manager.Event
+	37, // 79: container_manager.ContainerConfig.SecretEnvEntry.value:type_name -> container_manager.SecretRef
+	3,  // 80: container_manager.ContainerManager.Run:input_type -> container_manager.RunRequest
+	49, // 81: container_manager.ContainerManager.ListContainers:input_type -> container_manager.ListContainersRequest
+	52, // 82: container_manager.ContainerManager.GetContainerStatus:input_type -> container_manager.GetContainerStatusRequest
+	56, // 83: container_manager.ContainerManager.Health:input_type -> container_manager.HealthRequest
+	58, // 84: container_manager.ContainerManager.GetNodeResources:input_type -> container_manager.GetNodeResourcesRequest
+	61, // 85: container_manager.ContainerManager.GetAvailableImages:input_type -> container_manager.GetAvailableImagesRequest
+	64, // 86: container_manager.ContainerManager.UpdateContainerResources:input_type -> container_manager.UpdateContainerResourcesRequest
+	82, // 87: container_manager.ContainerManager.StartCapture:input_type -> container_manager.StartCaptureRequest
+	84, // 88: container_manager.ContainerManager.StopCapture:input_type -> container_manager.StopCaptureRequest
+	86, // 89: container_manager.ContainerManager.UploadFile:input_type -> container_manager.UploadFileRequest
+	88, // 90: container_manager.ContainerManager.DownloadFile:input_type -> container_manager.DownloadFileRequest
+	66, // 91: container_manager.ContainerManager.PauseContainer:input_type -> container_manager.PauseContainerRequest
+	68, // 92: container_manager.ContainerManager.UnpauseContainer:input_type -> container_manager.UnpauseContainerRequest
+	70, // 93: container_manager.ContainerManager.Checkpoint:input_type -> container_manager.CheckpointRequest
+	72, // 94: container_manager.ContainerManager.WaitReady:input_type -> container_manager.WaitReadyRequest
+	74, // 95: container_manager.ContainerManager.GetLogs:input_type -> container_manager.GetLogsRequest
+	76, // 96: container_manager.ContainerManager.WatchEvents:input_type -> container_manager.WatchEventsRequest
+	78, // 97: container_manager.ContainerManager.RestartContainer:input_type -> container_manager.RestartContainerRequest
+	80, // 98: container_manager.ContainerManager.ExtendTimeout:input_type -> container_manager.ExtendTimeoutRequest
+	12, // 99: container_manager.ContainerManager.Run:output_type -> container_manager.RunResponse
+	50, // 100: container_manager.ContainerManager.ListContainers:output_type -> container_manager.ListContainersResponse
+	53, // 101: container_manager.ContainerManager.GetContainerStatus:output_type -> container_manager.GetContainerStatusResponse
+	57, // 102: container_manager.ContainerManager.Health:output_type -> container_manager.HealthResponse
+	59, // 103: container_manager.ContainerManager.GetNodeResources:output_type -> container_manager.GetNodeResourcesResponse
+	62, // 104: container_manager.ContainerManager.GetAvailableImages:output_type -> container_manager.GetAvailableImagesResponse
+	65, // 105: container_manager.ContainerManager.UpdateContainerResources:output_type -> container_manager.UpdateContainerResourcesResponse
+	83, // 106: container_manager.ContainerManager.StartCapture:output_type -> container_manager.StartCaptureResponse
+	85, // 107: container_manager.ContainerManager.StopCapture:output_type -> container_manager.StopCaptureResponse
+	87, // 108: container_manager.ContainerManager.UploadFile:output_type -> container_manager.UploadFileResponse
+	89, // 109: container_manager.ContainerManager.DownloadFile:output_type -> container_manager.DownloadFileResponse
+	67, // 110: container_manager.ContainerManager.PauseContainer:output_type -> container_manager.PauseContainerResponse
+	69, // 111: container_manager.ContainerManager.UnpauseContainer:output_type -> container_manager.UnpauseContainerResponse
+	71, // 112: container_manager.ContainerManager.Checkpoint:output_type -> container_manager.CheckpointResponse
+	73, // 113: container_manager.ContainerManager.WaitReady:output_type -> container_manager.WaitReadyResponse
+	75, // 114: container_manager.ContainerManager.GetLogs:output_type -> container_manager.LogLine
+	77, // 115: container_manager.ContainerManager.WatchEvents:output_type -> container_manager.ContainerEvent
+	79, // 116: container_manager.ContainerManager.RestartContainer:output_type -> container_manager.RestartContainerResponse
+	81, // 117: container_manager.ContainerManager.ExtendTimeout:output_type -> container_manager.ExtendTimeoutResponse
+	99, // [99:118] is the sub-list for method output_type
+	80, // [80:99] is the sub-list for method input_type
+	80, // [80:80] is the sub-list for extension type_name
+	80, // [80:80] is the sub-list for extension extendee
+	0,  // [0:80] is the sub-list for field type_name
 }
 
 func init() { file_proto_container_manager_proto_init() }
@@ -2353,37 +7920,98 @@ func file_proto_container_manager_proto_init() {
 		(*RunRequest_CloseStdin)(nil),
 		(*RunRequest_Terminate)(nil),
 		(*RunRequest_Heartbeat)(nil),
-	}
-	file_proto_container_manager_proto_msgTypes[1].OneofWrappers = []any{}
-	file_proto_container_manager_proto_msgTypes[3].OneofWrappers = []any{
+		(*RunRequest_UpdateNetworkPolicy)(nil),
+		(*RunRequest_Exec)(nil),
+		(*RunRequest_Resize)(nil),
+		(*RunRequest_Pause)(nil),
+		(*RunRequest_Unpause)(nil),
+		(*RunRequest_Signal)(nil),
+		(*RunRequest_Attach)(nil),
+		(*RunRequest_UpdateResources)(nil),
+	}
+	file_proto_container_manager_proto_msgTypes[4].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[6].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[9].OneofWrappers = []any{
 		(*RunResponse_Created)(nil),
 		(*RunResponse_Stdout)(nil),
 		(*RunResponse_Stderr)(nil),
 		(*RunResponse_Exit)(nil),
 		(*RunResponse_Error)(nil),
 		(*RunResponse_Message)(nil),
+		(*RunResponse_Capture)(nil),
+		(*RunResponse_Exec)(nil),
+		(*RunResponse_Attached)(nil),
+		(*RunResponse_TypedEvent)(nil),
 	}
-	file_proto_container_manager_proto_msgTypes[6].OneofWrappers = []any{}
-	file_proto_container_manager_proto_msgTypes[7].OneofWrappers = []any{
-		(*ImageSpec_BasicAuth)(nil),
-	}
-	file_proto_container_manager_proto_msgTypes[9].OneofWrappers = []any{}
 	file_proto_container_manager_proto_msgTypes[10].OneofWrappers = []any{}
 	file_proto_container_manager_proto_msgTypes[11].OneofWrappers = []any{}
-	file_proto_container_manager_proto_msgTypes[12].OneofWrappers = []any{}
 	file_proto_container_manager_proto_msgTypes[14].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[15].OneofWrappers = []any{
+		(*Event_Log)(nil),
+		(*Event_ImagePullStarted)(nil),
+		(*Event_ImagePullCompleted)(nil),
+		(*Event_ContainerStarted)(nil),
+		(*Event_ContainerIpReady)(nil),
+		(*Event_NetworkIsolationReady)(nil),
+		(*Event_ContainerPortReady)(nil),
+		(*Event_ContainerReady)(nil),
+		(*Event_NetworkAttempt)(nil),
+		(*Event_ContainerTerminating)(nil),
+		(*Event_ContainerExited)(nil),
+		(*Event_Stats)(nil),
+		(*Event_Lifecycle)(nil),
+		(*Event_ContainerIdleTimeout)(nil),
+		(*Event_RunnerHello)(nil),
+		(*Event_RunnerHeartbeat)(nil),
+		(*Event_DataJson)(nil),
+	}
 	file_proto_container_manager_proto_msgTypes[16].OneofWrappers = []any{}
-	file_proto_container_manager_proto_msgTypes[17].OneofWrappers = []any{}
-	file_proto_container_manager_proto_msgTypes[20].OneofWrappers = []any{}
-	file_proto_container_manager_proto_msgTypes[22].OneofWrappers = []any{}
-	file_proto_container_manager_proto_msgTypes[25].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[26].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[32].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[33].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[34].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[35].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[36].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[37].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[38].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[39].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[40].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[41].OneofWrappers = []any{
+		(*ImageSpec_BasicAuth)(nil),
+	}
+	file_proto_container_manager_proto_msgTypes[43].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[44].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[45].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[46].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[47].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[48].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[50].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[51].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[52].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[54].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[56].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[59].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[62].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[64].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[66].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[68].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[69].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[70].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[71].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[73].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[76].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[78].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[79].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[80].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[82].OneofWrappers = []any{}
+	file_proto_container_manager_proto_msgTypes[84].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_container_manager_proto_rawDesc), len(file_proto_container_manager_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   28,
+			NumEnums:      3,
+			NumMessages:   96,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,12 +19,25 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ContainerManager_Run_FullMethodName                = "/container_manager.ContainerManager/Run"
-	ContainerManager_ListContainers_FullMethodName     = "/container_manager.ContainerManager/ListContainers"
-	ContainerManager_GetContainerStatus_FullMethodName = "/container_manager.ContainerManager/GetContainerStatus"
-	ContainerManager_Health_FullMethodName             = "/container_manager.ContainerManager/Health"
-	ContainerManager_GetNodeResources_FullMethodName   = "/container_manager.ContainerManager/GetNodeResources"
-	ContainerManager_GetAvailableImages_FullMethodName = "/container_manager.ContainerManager/GetAvailableImages"
+	ContainerManager_Run_FullMethodName                      = "/container_manager.ContainerManager/Run"
+	ContainerManager_ListContainers_FullMethodName           = "/container_manager.ContainerManager/ListContainers"
+	ContainerManager_GetContainerStatus_FullMethodName       = "/container_manager.ContainerManager/GetContainerStatus"
+	ContainerManager_Health_FullMethodName                   = "/container_manager.ContainerManager/Health"
+	ContainerManager_GetNodeResources_FullMethodName         = "/container_manager.ContainerManager/GetNodeResources"
+	ContainerManager_GetAvailableImages_FullMethodName       = "/container_manager.ContainerManager/GetAvailableImages"
+	ContainerManager_UpdateContainerResources_FullMethodName = "/container_manager.ContainerManager/UpdateContainerResources"
+	ContainerManager_StartCapture_FullMethodName             = "/container_manager.ContainerManager/StartCapture"
+	ContainerManager_StopCapture_FullMethodName              = "/container_manager.ContainerManager/StopCapture"
+	ContainerManager_UploadFile_FullMethodName               = "/container_manager.ContainerManager/UploadFile"
+	ContainerManager_DownloadFile_FullMethodName             = "/container_manager.ContainerManager/DownloadFile"
+	ContainerManager_PauseContainer_FullMethodName           = "/container_manager.ContainerManager/PauseContainer"
+	ContainerManager_UnpauseContainer_FullMethodName         = "/container_manager.ContainerManager/UnpauseContainer"
+	ContainerManager_Checkpoint_FullMethodName               = "/container_manager.ContainerManager/Checkpoint"
+	ContainerManager_WaitReady_FullMethodName                = "/container_manager.ContainerManager/WaitReady"
+	ContainerManager_GetLogs_FullMethodName                  = "/container_manager.ContainerManager/GetLogs"
+	ContainerManager_WatchEvents_FullMethodName              = "/container_manager.ContainerManager/WatchEvents"
+	ContainerManager_RestartContainer_FullMethodName         = "/container_manager.ContainerManager/RestartContainer"
+	ContainerManager_ExtendTimeout_FullMethodName            = "/container_manager.ContainerManager/ExtendTimeout"
 )
 
 // ContainerManagerClient is the client API for ContainerManager service.
@@ -35,10 +48,15 @@ const (
 // Manages isolation-runner subprocesses for container lifecycle
 type ContainerManagerClient interface {
 	// Unified bidirectional stream for container lifecycle
-	// First message MUST be RunRequest with create field set
+	// First message MUST be RunRequest with create or attach field set; attach
+	// binds the stream to a running container, e.g. after a network blip
+	// Create fails with RESOURCE_EXHAUSTED, and a QuotaFailure detail, when the
+	// CPU or memory limits of the container and its sidecars do not fit in what
+	// the node has left after the limits of its other containers
 	// Server sends stdout/stderr/messages/exit events
 	// Client can send stdin
-	// Client MUST send heartbeat every 30 seconds or container will be terminated
+	// Client MUST send a heartbeat within heartbeat_timeout_secs of the last one,
+	// as reported in created and attached, or container will be terminated
 	// Connection close/interrupt automatically terminates container
 	Run(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RunRequest, RunResponse], error)
 	// List all containers (running and recent)
@@ -51,6 +69,53 @@ type ContainerManagerClient interface {
 	GetNodeResources(ctx context.Context, in *GetNodeResourcesRequest, opts ...grpc.CallOption) (*GetNodeResourcesResponse, error)
 	// Get available Docker images on this node
 	GetAvailableImages(ctx context.Context, in *GetAvailableImagesRequest, opts ...grpc.CallOption) (*GetAvailableImagesResponse, error)
+	// Adjust CPU/memory limits of a running container without restarting it. The
+	// new limits must fit on the node with those of every other container. Run
+	// streams of the container see the outcome as a message event.
+	UpdateContainerResources(ctx context.Context, in *UpdateContainerResourcesRequest, opts ...grpc.CallOption) (*UpdateContainerResourcesResponse, error)
+	// Debug: capture a running container's packets; pcap data is delivered as capture events on its Run stream
+	StartCapture(ctx context.Context, in *StartCaptureRequest, opts ...grpc.CallOption) (*StartCaptureResponse, error)
+	StopCapture(ctx context.Context, in *StopCaptureRequest, opts ...grpc.CallOption) (*StopCaptureResponse, error)
+	// Copy files into a running container as a tar archive extracted into a directory.
+	// The first message names the container and directory; every message may carry archive data.
+	UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileRequest, UploadFileResponse], error)
+	// Copy a file or directory out of a running container as a tar archive
+	DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadFileResponse], error)
+	// Freeze every process of a running container, keeping its memory and
+	// filesystem state, until UnpauseContainer. The idle timeout is suspended
+	// while paused; heartbeats and the run timeout still apply.
+	PauseContainer(ctx context.Context, in *PauseContainerRequest, opts ...grpc.CallOption) (*PauseContainerResponse, error)
+	UnpauseContainer(ctx context.Context, in *UnpauseContainerRequest, opts ...grpc.CallOption) (*UnpauseContainerResponse, error)
+	// Experimental: save a running container's processes and memory as a named
+	// checkpoint, so later containers can start from it with restore_from
+	// instead of warming up again. Requires the gVisor runtime and a Docker
+	// daemon with experimental features enabled.
+	Checkpoint(ctx context.Context, in *CheckpointRequest, opts ...grpc.CallOption) (*CheckpointResponse, error)
+	// Block until the container is ready: started, its network configured and,
+	// if it has one, its readiness probe passed. Fails if it exits first.
+	WaitReady(ctx context.Context, in *WaitReadyRequest, opts ...grpc.CallOption) (*WaitReadyResponse, error)
+	// Read a container's recent stdout, stderr and events, one line per message.
+	// With follow set the stream stays open and delivers new lines as they are
+	// written, until the container's output ends. With HOLOPOD_LOG_DIR set on
+	// the manager, logs stay readable after the container is cleaned up.
+	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error)
+	// Observe the events of every container matching a filter as they are
+	// raised, including containers created later, without a Run stream per
+	// container. The stream stays open until the client closes it.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ContainerEvent], error)
+	// Run an exited container again with the same config, as a fresh
+	// isolation-runner invocation under the same container ID, so a flaky job
+	// can be retried without sending its config again. Its attempt counter goes
+	// up, its logs carry on from the previous attempt and its Run stream is
+	// attached to again. Only containers the manager still holds can be
+	// restarted, i.e. before their cleanup delay is up.
+	RestartContainer(ctx context.Context, in *RestartContainerRequest, opts ...grpc.CallOption) (*RestartContainerResponse, error)
+	// Push back the run timeout (timeout_secs) of a running container, so a
+	// long but legitimate run need not be killed and restarted. Extensions of
+	// one container add up to at most the manager's MAX_TIMEOUT_EXTENSION, and
+	// the maximum lifetime still applies. Run streams of the container see a
+	// timeout_extended message event.
+	ExtendTimeout(ctx context.Context, in *ExtendTimeoutRequest, opts ...grpc.CallOption) (*ExtendTimeoutResponse, error)
 }
 
 type containerManagerClient struct {
@@ -124,6 +189,166 @@ func (c *containerManagerClient) GetAvailableImages(ctx context.Context, in *Get
 	return out, nil
 }
 
+func (c *containerManagerClient) UpdateContainerResources(ctx context.Context, in *UpdateContainerResourcesRequest, opts ...grpc.CallOption) (*UpdateContainerResourcesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateContainerResourcesResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_UpdateContainerResources_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerManagerClient) StartCapture(ctx context.Context, in *StartCaptureRequest, opts ...grpc.CallOption) (*StartCaptureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartCaptureResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_StartCapture_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerManagerClient) StopCapture(ctx context.Context, in *StopCaptureRequest, opts ...grpc.CallOption) (*StopCaptureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopCaptureResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_StopCapture_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerManagerClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileRequest, UploadFileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContainerManager_ServiceDesc.Streams[1], ContainerManager_UploadFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadFileRequest, UploadFileResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerManager_UploadFileClient = grpc.ClientStreamingClient[UploadFileRequest, UploadFileResponse]
+
+func (c *containerManagerClient) DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadFileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContainerManager_ServiceDesc.Streams[2], ContainerManager_DownloadFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadFileRequest, DownloadFileResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerManager_DownloadFileClient = grpc.ServerStreamingClient[DownloadFileResponse]
+
+func (c *containerManagerClient) PauseContainer(ctx context.Context, in *PauseContainerRequest, opts ...grpc.CallOption) (*PauseContainerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseContainerResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_PauseContainer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerManagerClient) UnpauseContainer(ctx context.Context, in *UnpauseContainerRequest, opts ...grpc.CallOption) (*UnpauseContainerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnpauseContainerResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_UnpauseContainer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerManagerClient) Checkpoint(ctx context.Context, in *CheckpointRequest, opts ...grpc.CallOption) (*CheckpointResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckpointResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_Checkpoint_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerManagerClient) WaitReady(ctx context.Context, in *WaitReadyRequest, opts ...grpc.CallOption) (*WaitReadyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaitReadyResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_WaitReady_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerManagerClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContainerManager_ServiceDesc.Streams[3], ContainerManager_GetLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetLogsRequest, LogLine]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerManager_GetLogsClient = grpc.ServerStreamingClient[LogLine]
+
+func (c *containerManagerClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ContainerEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContainerManager_ServiceDesc.Streams[4], ContainerManager_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, ContainerEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerManager_WatchEventsClient = grpc.ServerStreamingClient[ContainerEvent]
+
+func (c *containerManagerClient) RestartContainer(ctx context.Context, in *RestartContainerRequest, opts ...grpc.CallOption) (*RestartContainerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestartContainerResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_RestartContainer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerManagerClient) ExtendTimeout(ctx context.Context, in *ExtendTimeoutRequest, opts ...grpc.CallOption) (*ExtendTimeoutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExtendTimeoutResponse)
+	err := c.cc.Invoke(ctx, ContainerManager_ExtendTimeout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ContainerManagerServer is the server API for ContainerManager service.
 // All implementations must embed UnimplementedContainerManagerServer
 // for forward compatibility.
@@ -132,10 +357,15 @@ func (c *containerManagerClient) GetAvailableImages(ctx context.Context, in *Get
 // Manages isolation-runner subprocesses for container lifecycle
 type ContainerManagerServer interface {
 	// Unified bidirectional stream for container lifecycle
-	// First message MUST be RunRequest with create field set
+	// First message MUST be RunRequest with create or attach field set; attach
+	// binds the stream to a running container, e.g. after a network blip
+	// Create fails with RESOURCE_EXHAUSTED, and a QuotaFailure detail, when the
+	// CPU or memory limits of the container and its sidecars do not fit in what
+	// the node has left after the limits of its other containers
 	// Server sends stdout/stderr/messages/exit events
 	// Client can send stdin
-	// Client MUST send heartbeat every 30 seconds or container will be terminated
+	// Client MUST send a heartbeat within heartbeat_timeout_secs of the last one,
+	// as reported in created and attached, or container will be terminated
 	// Connection close/interrupt automatically terminates container
 	Run(grpc.BidiStreamingServer[RunRequest, RunResponse]) error
 	// List all containers (running and recent)
@@ -148,6 +378,53 @@ type ContainerManagerServer interface {
 	GetNodeResources(context.Context, *GetNodeResourcesRequest) (*GetNodeResourcesResponse, error)
 	// Get available Docker images on this node
 	GetAvailableImages(context.Context, *GetAvailableImagesRequest) (*GetAvailableImagesResponse, error)
+	// Adjust CPU/memory limits of a running container without restarting it. The
+	// new limits must fit on the node with those of every other container. Run
+	// streams of the container see the outcome as a message event.
+	UpdateContainerResources(context.Context, *UpdateContainerResourcesRequest) (*UpdateContainerResourcesResponse, error)
+	// Debug: capture a running container's packets; pcap data is delivered as capture events on its Run stream
+	StartCapture(context.Context, *StartCaptureRequest) (*StartCaptureResponse, error)
+	StopCapture(context.Context, *StopCaptureRequest) (*StopCaptureResponse, error)
+	// Copy files into a running container as a tar archive extracted into a directory.
+	// The first message names the container and directory; every message may carry archive data.
+	UploadFile(grpc.ClientStreamingServer[UploadFileRequest, UploadFileResponse]) error
+	// Copy a file or directory out of a running container as a tar archive
+	DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[DownloadFileResponse]) error
+	// Freeze every process of a running container, keeping its memory and
+	// filesystem state, until UnpauseContainer. The idle timeout is suspended
+	// while paused; heartbeats and the run timeout still apply.
+	PauseContainer(context.Context, *PauseContainerRequest) (*PauseContainerResponse, error)
+	UnpauseContainer(context.Context, *UnpauseContainerRequest) (*UnpauseContainerResponse, error)
+	// Experimental: save a running container's processes and memory as a named
+	// checkpoint, so later containers can start from it with restore_from
+	// instead of warming up again. Requires the gVisor runtime and a Docker
+	// daemon with experimental features enabled.
+	Checkpoint(context.Context, *CheckpointRequest) (*CheckpointResponse, error)
+	// Block until the container is ready: started, its network configured and,
+	// if it has one, its readiness probe passed. Fails if it exits first.
+	WaitReady(context.Context, *WaitReadyRequest) (*WaitReadyResponse, error)
+	// Read a container's recent stdout, stderr and events, one line per message.
+	// With follow set the stream stays open and delivers new lines as they are
+	// written, until the container's output ends. With HOLOPOD_LOG_DIR set on
+	// the manager, logs stay readable after the container is cleaned up.
+	GetLogs(*GetLogsRequest, grpc.ServerStreamingServer[LogLine]) error
+	// Observe the events of every container matching a filter as they are
+	// raised, including containers created later, without a Run stream per
+	// container. The stream stays open until the client closes it.
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[ContainerEvent]) error
+	// Run an exited container again with the same config, as a fresh
+	// isolation-runner invocation under the same container ID, so a flaky job
+	// can be retried without sending its config again. Its attempt counter goes
+	// up, its logs carry on from the previous attempt and its Run stream is
+	// attached to again. Only containers the manager still holds can be
+	// restarted, i.e. before their cleanup delay is up.
+	RestartContainer(context.Context, *RestartContainerRequest) (*RestartContainerResponse, error)
+	// Push back the run timeout (timeout_secs) of a running container, so a
+	// long but legitimate run need not be killed and restarted. Extensions of
+	// one container add up to at most the manager's MAX_TIMEOUT_EXTENSION, and
+	// the maximum lifetime still applies. Run streams of the container see a
+	// timeout_extended message event.
+	ExtendTimeout(context.Context, *ExtendTimeoutRequest) (*ExtendTimeoutResponse, error)
 	mustEmbedUnimplementedContainerManagerServer()
 }
 
@@ -176,6 +453,45 @@ func (UnimplementedContainerManagerServer) GetNodeResources(context.Context, *Ge
 func (UnimplementedContainerManagerServer) GetAvailableImages(context.Context, *GetAvailableImagesRequest) (*GetAvailableImagesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetAvailableImages not implemented")
 }
+func (UnimplementedContainerManagerServer) UpdateContainerResources(context.Context, *UpdateContainerResourcesRequest) (*UpdateContainerResourcesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateContainerResources not implemented")
+}
+func (UnimplementedContainerManagerServer) StartCapture(context.Context, *StartCaptureRequest) (*StartCaptureResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartCapture not implemented")
+}
+func (UnimplementedContainerManagerServer) StopCapture(context.Context, *StopCaptureRequest) (*StopCaptureResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopCapture not implemented")
+}
+func (UnimplementedContainerManagerServer) UploadFile(grpc.ClientStreamingServer[UploadFileRequest, UploadFileResponse]) error {
+	return status.Error(codes.Unimplemented, "method UploadFile not implemented")
+}
+func (UnimplementedContainerManagerServer) DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[DownloadFileResponse]) error {
+	return status.Error(codes.Unimplemented, "method DownloadFile not implemented")
+}
+func (UnimplementedContainerManagerServer) PauseContainer(context.Context, *PauseContainerRequest) (*PauseContainerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PauseContainer not implemented")
+}
+func (UnimplementedContainerManagerServer) UnpauseContainer(context.Context, *UnpauseContainerRequest) (*UnpauseContainerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnpauseContainer not implemented")
+}
+func (UnimplementedContainerManagerServer) Checkpoint(context.Context, *CheckpointRequest) (*CheckpointResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Checkpoint not implemented")
+}
+func (UnimplementedContainerManagerServer) WaitReady(context.Context, *WaitReadyRequest) (*WaitReadyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method WaitReady not implemented")
+}
+func (UnimplementedContainerManagerServer) GetLogs(*GetLogsRequest, grpc.ServerStreamingServer[LogLine]) error {
+	return status.Error(codes.Unimplemented, "method GetLogs not implemented")
+}
+func (UnimplementedContainerManagerServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[ContainerEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedContainerManagerServer) RestartContainer(context.Context, *RestartContainerRequest) (*RestartContainerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestartContainer not implemented")
+}
+func (UnimplementedContainerManagerServer) ExtendTimeout(context.Context, *ExtendTimeoutRequest) (*ExtendTimeoutResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExtendTimeout not implemented")
+}
 func (UnimplementedContainerManagerServer) mustEmbedUnimplementedContainerManagerServer() {}
 func (UnimplementedContainerManagerServer) testEmbeddedByValue()                          {}
 
@@ -294,6 +610,208 @@ func _ContainerManager_GetAvailableImages_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ContainerManager_UpdateContainerResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateContainerResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).UpdateContainerResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_UpdateContainerResources_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).UpdateContainerResources(ctx, req.(*UpdateContainerResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerManager_StartCapture_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartCaptureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).StartCapture(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_StartCapture_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).StartCapture(ctx, req.(*StartCaptureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerManager_StopCapture_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopCaptureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).StopCapture(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_StopCapture_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).StopCapture(ctx, req.(*StopCaptureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerManager_UploadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContainerManagerServer).UploadFile(&grpc.GenericServerStream[UploadFileRequest, UploadFileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerManager_UploadFileServer = grpc.ClientStreamingServer[UploadFileRequest, UploadFileResponse]
+
+func _ContainerManager_DownloadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContainerManagerServer).DownloadFile(m, &grpc.GenericServerStream[DownloadFileRequest, DownloadFileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerManager_DownloadFileServer = grpc.ServerStreamingServer[DownloadFileResponse]
+
+func _ContainerManager_PauseContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).PauseContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_PauseContainer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).PauseContainer(ctx, req.(*PauseContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerManager_UnpauseContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnpauseContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).UnpauseContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_UnpauseContainer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).UnpauseContainer(ctx, req.(*UnpauseContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerManager_Checkpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).Checkpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_Checkpoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).Checkpoint(ctx, req.(*CheckpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerManager_WaitReady_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitReadyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).WaitReady(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_WaitReady_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).WaitReady(ctx, req.(*WaitReadyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerManager_GetLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContainerManagerServer).GetLogs(m, &grpc.GenericServerStream[GetLogsRequest, LogLine]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerManager_GetLogsServer = grpc.ServerStreamingServer[LogLine]
+
+func _ContainerManager_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContainerManagerServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, ContainerEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerManager_WatchEventsServer = grpc.ServerStreamingServer[ContainerEvent]
+
+func _ContainerManager_RestartContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestartContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).RestartContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_RestartContainer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).RestartContainer(ctx, req.(*RestartContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerManager_ExtendTimeout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendTimeoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerManagerServer).ExtendTimeout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerManager_ExtendTimeout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerManagerServer).ExtendTimeout(ctx, req.(*ExtendTimeoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ContainerManager_ServiceDesc is the grpc.ServiceDesc for ContainerManager service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -321,6 +839,42 @@ var ContainerManager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAvailableImages",
 			Handler:    _ContainerManager_GetAvailableImages_Handler,
 		},
+		{
+			MethodName: "UpdateContainerResources",
+			Handler:    _ContainerManager_UpdateContainerResources_Handler,
+		},
+		{
+			MethodName: "StartCapture",
+			Handler:    _ContainerManager_StartCapture_Handler,
+		},
+		{
+			MethodName: "StopCapture",
+			Handler:    _ContainerManager_StopCapture_Handler,
+		},
+		{
+			MethodName: "PauseContainer",
+			Handler:    _ContainerManager_PauseContainer_Handler,
+		},
+		{
+			MethodName: "UnpauseContainer",
+			Handler:    _ContainerManager_UnpauseContainer_Handler,
+		},
+		{
+			MethodName: "Checkpoint",
+			Handler:    _ContainerManager_Checkpoint_Handler,
+		},
+		{
+			MethodName: "WaitReady",
+			Handler:    _ContainerManager_WaitReady_Handler,
+		},
+		{
+			MethodName: "RestartContainer",
+			Handler:    _ContainerManager_RestartContainer_Handler,
+		},
+		{
+			MethodName: "ExtendTimeout",
+			Handler:    _ContainerManager_ExtendTimeout_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -329,6 +883,26 @@ var ContainerManager_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "UploadFile",
+			Handler:       _ContainerManager_UploadFile_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadFile",
+			Handler:       _ContainerManager_DownloadFile_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetLogs",
+			Handler:       _ContainerManager_GetLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _ContainerManager_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "proto/container_manager.proto",
 }